@@ -0,0 +1,90 @@
+"""Python client for the gcqd daemon of go-context-query.
+
+    from gcq_client import Client
+
+    with Client() as gcq:
+        for r in gcq.search("parse config", limit=5).results:
+            print(r.file_path, r.line_number, r.name, r.score)
+"""
+
+from .client import (
+    DEFAULT_SOCKET_PATH,
+    DEFAULT_TCP_PORT,
+    Client,
+    DaemonError,
+    DaemonUnavailableError,
+    GCQError,
+)
+from .models import (
+    BatchQueryResult,
+    BatchResult,
+    BlameHunk,
+    Caller,
+    CallersResult,
+    CallsResult,
+    CallTreeNode,
+    CalledFunction,
+    ContextResult,
+    DaemonStatus,
+    Degradation,
+    ExtractResult,
+    HoverResult,
+    JobStatus,
+    LoadIndexResult,
+    NotifyResult,
+    ProgressEvent,
+    ProjectInfo,
+    ProjectQuota,
+    ResultGroup,
+    SearchResponse,
+    SearchResult,
+    SliceLine,
+    SliceResult,
+    SymbolMatch,
+    SymbolsResult,
+    TextMatch,
+    UnitRef,
+    WarmResult,
+    WarmupStatus,
+)
+
+__version__ = "0.1.0"
+
+__all__ = [
+    "DEFAULT_SOCKET_PATH",
+    "DEFAULT_TCP_PORT",
+    "BatchQueryResult",
+    "BatchResult",
+    "BlameHunk",
+    "CallTreeNode",
+    "CalledFunction",
+    "Caller",
+    "CallersResult",
+    "CallsResult",
+    "Client",
+    "ContextResult",
+    "DaemonError",
+    "DaemonStatus",
+    "DaemonUnavailableError",
+    "Degradation",
+    "ExtractResult",
+    "GCQError",
+    "HoverResult",
+    "JobStatus",
+    "LoadIndexResult",
+    "NotifyResult",
+    "ProgressEvent",
+    "ProjectInfo",
+    "ProjectQuota",
+    "ResultGroup",
+    "SearchResponse",
+    "SearchResult",
+    "SliceLine",
+    "SliceResult",
+    "SymbolMatch",
+    "SymbolsResult",
+    "TextMatch",
+    "UnitRef",
+    "WarmResult",
+    "WarmupStatus",
+]
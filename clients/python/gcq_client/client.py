@@ -0,0 +1,593 @@
+"""Socket client for the gcqd daemon.
+
+The daemon speaks newline-delimited JSON over a Unix socket (TCP on
+Windows). Each request is ``{"type", "id", "params"}`` and is answered by one
+``{"id", "type", "result"|"error"}`` frame; ``search_stream``, and
+``extract``/``warm`` with progress, send ``*_batch`` frames before their
+final response.
+"""
+
+from __future__ import annotations
+
+import itertools
+import json
+import os
+import socket
+import sys
+import threading
+import time
+from typing import Any, Callable, Dict, Iterator, List, Optional, Sequence, Union
+
+from .models import (
+    BatchResult,
+    CallersResult,
+    CallsResult,
+    ContextResult,
+    DaemonStatus,
+    ExtractResult,
+    HoverResult,
+    JobStatus,
+    LoadIndexResult,
+    NotifyResult,
+    ProgressEvent,
+    ProjectInfo,
+    SearchResponse,
+    SliceResult,
+    SymbolsResult,
+    TextMatch,
+    WarmResult,
+)
+
+DEFAULT_SOCKET_PATH = "/tmp/gcq.sock"
+DEFAULT_TCP_PORT = "9847"
+DEFAULT_TIMEOUT = 5.0
+
+# gcqd answers an idle connection with a decode error after 30s, so
+# connections idle for longer than this are replaced before reuse
+IDLE_TIMEOUT = 20.0
+
+_Timeout = Union[float, None, object]
+_DEFAULT = object()
+
+
+class GCQError(Exception):
+    """Base class for client errors."""
+
+
+class DaemonUnavailableError(GCQError):
+    """The daemon could not be reached or closed the connection."""
+
+
+class DaemonError(GCQError):
+    """The daemon answered a request with an error."""
+
+    def __init__(self, command: str, message: str):
+        super().__init__(f"daemon error: {message}")
+        self.command = command
+        self.message = message
+
+
+def _use_tcp(socket_path: str) -> bool:
+    # Mirrors pkg/client: TCP on Windows or when the socket path is not absolute
+    return sys.platform == "win32" or not socket_path.startswith("/")
+
+
+class _Connection:
+    """One socket to the daemon with a buffered reader for response frames."""
+
+    def __init__(self, sock: socket.socket):
+        self.sock = sock
+        self.reader = sock.makefile("rb")
+        self.last_used = time.monotonic()
+
+    def send(self, frame: Dict[str, Any]) -> None:
+        self.sock.sendall(json.dumps(frame).encode() + b"\n")
+
+    def read(self) -> Dict[str, Any]:
+        line = self.reader.readline()
+        if not line:
+            raise DaemonUnavailableError("daemon closed the connection")
+        return json.loads(line)
+
+    def close(self) -> None:
+        try:
+            self.reader.close()
+        finally:
+            self.sock.close()
+
+
+class Client:
+    """Client for a running gcqd daemon.
+
+    The client keeps one connection open and reuses it across requests; it
+    is safe to share between threads, which take turns on the connection.
+    Use it as a context manager or call ``close`` when done.
+
+    The address defaults to ``GCQ_SOCKET_PATH`` (or /tmp/gcq.sock), or to
+    ``GCQ_TCP_PORT`` on localhost on Windows, as for the gcq CLI.
+    """
+
+    def __init__(
+        self,
+        socket_path: Optional[str] = None,
+        tcp_port: Optional[str] = None,
+        timeout: Optional[float] = DEFAULT_TIMEOUT,
+        project: Optional[str] = None,
+    ):
+        self.socket_path = socket_path or os.environ.get("GCQ_SOCKET_PATH") or DEFAULT_SOCKET_PATH
+        self.tcp_port = str(tcp_port or os.environ.get("GCQ_TCP_PORT") or DEFAULT_TCP_PORT)
+        self.timeout = timeout
+        # project is sent with commands that accept one, unless overridden
+        self.project = project
+        self._conn: Optional[_Connection] = None
+        self._lock = threading.Lock()
+        self._ids = itertools.count(1)
+
+    def __enter__(self) -> "Client":
+        return self
+
+    def __exit__(self, *exc: Any) -> None:
+        self.close()
+
+    def close(self) -> None:
+        """Closes the connection to the daemon. The client reconnects if used again."""
+        with self._lock:
+            self._drop()
+
+    def is_running(self) -> bool:
+        """Reports whether the daemon answers a status request."""
+        try:
+            self.request("status", timeout=self.timeout or DEFAULT_TIMEOUT)
+            return True
+        except GCQError:
+            return False
+
+    # Connection management
+
+    def _dial(self, timeout: Optional[float]) -> _Connection:
+        try:
+            if _use_tcp(self.socket_path):
+                sock = socket.create_connection(("localhost", int(self.tcp_port)), timeout=timeout)
+            else:
+                sock = socket.socket(socket.AF_UNIX, socket.SOCK_STREAM)
+                sock.settimeout(timeout)
+                try:
+                    sock.connect(self.socket_path)
+                except OSError:
+                    sock.close()
+                    raise
+        except OSError as e:
+            raise DaemonUnavailableError(f"connecting to daemon: {e}") from e
+        return _Connection(sock)
+
+    def _acquire(self, timeout: Optional[float]) -> tuple:
+        """Returns the open connection, or a new one, and whether it was reused."""
+        conn = self._conn
+        if conn is not None and time.monotonic() - conn.last_used > IDLE_TIMEOUT:
+            self._drop()
+            conn = None
+        reused = conn is not None
+        if conn is None:
+            conn = self._conn = self._dial(timeout)
+        conn.sock.settimeout(timeout)
+        return conn, reused
+
+    def _drop(self) -> None:
+        if self._conn is not None:
+            self._conn.close()
+            self._conn = None
+
+    def _exchange(self, conn: _Connection, frame: Dict[str, Any]) -> Dict[str, Any]:
+        """Sends frame and returns the response carrying its id, skipping
+        frames left over from earlier requests."""
+        conn.send(frame)
+        while True:
+            resp = conn.read()
+            if resp.get("id") == frame["id"]:
+                conn.last_used = time.monotonic()
+                return resp
+
+    def _frame(
+        self, cmd_type: str, params: Optional[Dict[str, Any]], timeout: Optional[float] = None
+    ) -> Dict[str, Any]:
+        frame: Dict[str, Any] = {"type": cmd_type, "id": f"py-{os.getpid()}-{next(self._ids)}"}
+        if params is not None:
+            frame["params"] = params
+        if timeout:
+            # The daemon abandons provider requests the client no longer waits for
+            frame["timeout_ms"] = max(int(timeout * 1000), 1)
+        return frame
+
+    def request(
+        self, cmd_type: str, params: Optional[Dict[str, Any]] = None, timeout: _Timeout = _DEFAULT
+    ) -> Dict[str, Any]:
+        """Sends a raw command and returns its decoded result.
+
+        ``timeout`` (seconds, ``None`` to wait indefinitely) defaults to the
+        client timeout. Raises DaemonError when the daemon reports an error
+        and DaemonUnavailableError when it cannot be reached.
+        """
+        if timeout is _DEFAULT:
+            timeout = self.timeout
+        frame = self._frame(cmd_type, params, timeout)  # type: ignore[arg-type]
+
+        with self._lock:
+            for attempt in range(2):
+                conn, reused = self._acquire(timeout)  # type: ignore[arg-type]
+                try:
+                    resp = self._exchange(conn, frame)
+                    break
+                except (OSError, ValueError, DaemonUnavailableError) as e:
+                    self._drop()
+                    # A reused connection may have been closed by the daemon
+                    # while idle; retry once on a fresh one
+                    if reused and attempt == 0 and not isinstance(e, socket.timeout):
+                        continue
+                    if isinstance(e, DaemonUnavailableError):
+                        raise
+                    raise DaemonUnavailableError(f"{cmd_type}: {e}") from e
+
+        if resp.get("error"):
+            raise DaemonError(cmd_type, resp["error"])
+        result = resp.get("result")
+        if not isinstance(result, dict):
+            raise GCQError(f"{cmd_type}: invalid response format")
+        return result
+
+    def _request_progress(
+        self, cmd_type: str, params: Dict[str, Any], progress: Callable[[ProgressEvent], None]
+    ) -> Dict[str, Any]:
+        """Sends an indexing command with progress on, calling progress with
+        each event until the final result arrives. Waits indefinitely."""
+        frame = self._frame(cmd_type, dict(params, progress=True))
+
+        with self._lock:
+            conn, _ = self._acquire(None)
+            done = False
+            try:
+                conn.send(frame)
+                while True:
+                    resp = conn.read()
+                    if resp.get("id") != frame["id"]:
+                        continue
+                    if resp.get("error"):
+                        done = True
+                        raise DaemonError(cmd_type, resp["error"])
+                    result = resp.get("result")
+                    if not isinstance(result, dict):
+                        done = True
+                        raise GCQError(f"{cmd_type}: invalid response format")
+                    if not str(resp.get("type", "")).endswith("_batch"):
+                        done = True
+                        conn.last_used = time.monotonic()
+                        return result
+                    progress(ProgressEvent.from_dict(result))
+            except (OSError, ValueError) as e:
+                raise DaemonUnavailableError(f"{cmd_type}: {e}") from e
+            finally:
+                if not done:
+                    # Unread frames may still be pending on the connection
+                    self._drop()
+
+    def _params(self, project: Optional[str] = None, **params: Any) -> Dict[str, Any]:
+        """Builds command params, dropping unset values like omitempty does."""
+        params["project"] = project or self.project
+        return {k: v for k, v in params.items() if v not in (None, "", 0, False, [])}
+
+    # Commands
+
+    def status(self) -> DaemonStatus:
+        """Returns the daemon status."""
+        return DaemonStatus.from_dict(self.request("status"))
+
+    def search(
+        self,
+        query: Union[str, Sequence[str]],
+        limit: int = 0,
+        threshold: float = 0.0,
+        mode: str = "semantic",
+        root: Optional[str] = None,
+        files: int = 0,
+        include_tests: bool = False,
+        group_by: Optional[str] = None,
+        project: Optional[str] = None,
+        exclude_terms: Optional[Sequence[str]] = None,
+        metadata: Optional[Dict[str, str]] = None,
+        budget_ms: int = 0,
+    ) -> SearchResponse:
+        """Searches the code index.
+
+        ``mode`` is "semantic", "hybrid" or "keyword" (use ``text_search``
+        for regex search). ``limit`` 0 uses the daemon's limits.search_results.
+        ``group_by`` ("file" or "package") also fills ``SearchResponse.groups``.
+        A list of queries, such as a question and candidate identifier names,
+        is searched as one request with the rankings fused.
+        ``exclude_terms`` (or ``-term`` words in the query) penalizes results
+        whose path or text contains a term, such as "mock" or "fixtures/".
+        ``metadata`` keeps only units whose enricher metadata has every key
+        and, for non-empty values, that value.
+        ``budget_ms`` bounds the index scan (0 uses the daemon's
+        daemon.search_budget); a search that runs out returns the best
+        results so far with a "search_budget" degradation.
+        """
+        queries: List[str] = []
+        if not isinstance(query, str):
+            query, *queries = query
+        params = self._params(
+            project,
+            query=query,
+            queries=queries,
+            limit=limit,
+            threshold=threshold,
+            mode=mode,
+            root=root,
+            files=files,
+            include_tests=include_tests,
+            group_by=group_by,
+            exclude_terms=list(exclude_terms or []),
+            metadata=dict(metadata) if metadata else None,
+            budget_ms=budget_ms,
+        )
+        return SearchResponse.from_dict(self.request("search", params))
+
+    def text_search(
+        self,
+        query: str,
+        root: str,
+        limit: int = 0,
+        context_lines: Optional[int] = None,
+        extensions: Optional[Sequence[str]] = None,
+        max_file_size: int = 0,
+        skip_binary: Optional[bool] = None,
+    ) -> List[TextMatch]:
+        """Runs a regex search over the files under root. Unset options use
+        the daemon's text_search config."""
+        params = self._text_params(query, root, limit, context_lines, extensions, max_file_size, skip_binary)
+        params["mode"] = "text"
+        result = self.request("search", params, timeout=None)
+        return [TextMatch.from_dict(m) for m in result.get("matches") or []]
+
+    def text_search_stream(
+        self,
+        query: str,
+        root: str,
+        limit: int = 0,
+        batch_size: int = 0,
+        context_lines: Optional[int] = None,
+        extensions: Optional[Sequence[str]] = None,
+        max_file_size: int = 0,
+        skip_binary: Optional[bool] = None,
+    ) -> Iterator[List[TextMatch]]:
+        """Like ``text_search``, but yields batches of matches as the daemon
+        finds them. The connection is held until the generator finishes;
+        closing it early discards the connection."""
+        params = self._text_params(query, root, limit, context_lines, extensions, max_file_size, skip_binary)
+        if batch_size:
+            params["batch_size"] = batch_size
+        frame = self._frame("search_stream", params)
+
+        with self._lock:
+            conn, _ = self._acquire(None)
+            done = False
+            try:
+                conn.send(frame)
+                while True:
+                    resp = conn.read()
+                    if resp.get("id") != frame["id"]:
+                        continue
+                    if resp.get("error"):
+                        done = True
+                        raise DaemonError("search_stream", resp["error"])
+                    result = resp.get("result") or {}
+                    if not str(resp.get("type", "")).endswith("_batch"):
+                        done = True
+                        conn.last_used = time.monotonic()
+                        return
+                    yield [TextMatch.from_dict(m) for m in result.get("matches") or []]
+            except (OSError, ValueError) as e:
+                raise DaemonUnavailableError(f"search_stream: {e}") from e
+            finally:
+                if not done:
+                    # Unread frames may still be pending on the connection
+                    self._drop()
+
+    def _text_params(
+        self,
+        query: str,
+        root: str,
+        limit: int,
+        context_lines: Optional[int],
+        extensions: Optional[Sequence[str]],
+        max_file_size: int,
+        skip_binary: Optional[bool],
+    ) -> Dict[str, Any]:
+        params: Dict[str, Any] = {"query": query, "root": root}
+        if limit:
+            params["limit"] = limit
+        if context_lines is not None:
+            params["context_lines"] = context_lines
+        if extensions:
+            params["extensions"] = list(extensions)
+        if max_file_size:
+            params["max_file_size"] = max_file_size
+        if skip_binary is not None:
+            params["skip_binary"] = skip_binary
+        return params
+
+    def context(
+        self, query: str, limit: int = 0, project: Optional[str] = None, blame: bool = False
+    ) -> ContextResult:
+        """Returns the units most relevant to query, for use as LLM context.
+        With ``blame``, each unit lists the commits that last changed its
+        lines."""
+        params = self._params(project, query=query, limit=limit, blame=blame)
+        return ContextResult.from_dict(self.request("context", params))
+
+    def batch(
+        self,
+        queries: Sequence[Union[str, Dict[str, Any]]],
+        dedupe: Optional[bool] = None,
+        project: Optional[str] = None,
+    ) -> BatchResult:
+        """Runs several context queries in one request. Queries are strings or
+        ``{"query": ..., "limit": ...}`` dicts. Use ``BatchResult.resolve``
+        to get each query's units when deduplication is on (the default)."""
+        items = [{"query": q} if isinstance(q, str) else dict(q) for q in queries]
+        params = self._params(project, queries=items)
+        if dedupe is not None:
+            params["dedupe"] = dedupe
+        return BatchResult.from_dict(self.request("batch", params))
+
+    def extract(
+        self,
+        path: str,
+        project: Optional[str] = None,
+        progress: Optional[Callable[[ProgressEvent], None]] = None,
+    ) -> ExtractResult:
+        """Extracts code units from a file or directory into the daemon's index.
+        ``progress`` is called with each file's ProgressEvent as it happens."""
+        params = self._params(project, path=path)
+        if progress is not None:
+            return ExtractResult.from_dict(self._request_progress("extract", params, progress))
+        return ExtractResult.from_dict(self.request("extract", params, timeout=None))
+
+    def calls(
+        self,
+        file: str,
+        func: str,
+        type: Optional[str] = None,
+        depth: int = 0,
+        reverse: bool = False,
+        project: Optional[str] = None,
+    ) -> CallsResult:
+        """Returns the calls made by func in file. ``type`` filters them to
+        "local", "external" or "method"; ``depth`` or ``reverse`` also build a
+        cross-file call tree (of callers when reversed)."""
+        params = self._params(project, file=file, func=func, type=type, depth=depth, reverse=reverse)
+        return CallsResult.from_dict(self.request("calls", params))
+
+    def callers(
+        self,
+        func: str,
+        file: Optional[str] = None,
+        depth: int = 1,
+        root: Optional[str] = None,
+        project: Optional[str] = None,
+    ) -> CallersResult:
+        """Returns the functions calling func (a name, Class.method or unit
+        URI), following callers of callers up to depth levels."""
+        params = self._params(project, func=func, file=file, depth=depth, root=root)
+        return CallersResult.from_dict(self.request("callers", params))
+
+    def symbols(
+        self,
+        pattern: str,
+        limit: Optional[int] = None,
+        root: Optional[str] = None,
+        project: Optional[str] = None,
+    ) -> SymbolsResult:
+        """Fuzzy matches pattern, fzf-style, against the function, class and
+        method names of the project. Needs no embeddings."""
+        params = self._params(project, pattern=pattern, limit=limit, root=root)
+        return SymbolsResult.from_dict(self.request("symbols", params))
+
+    def hover(
+        self,
+        file: str,
+        line: int,
+        col: int = 1,
+        root: Optional[str] = None,
+        project: Optional[str] = None,
+    ) -> HoverResult:
+        """Describes the symbol at file:line:col (counted from 1) for an
+        editor hover: signature, docs, type details, complexity and top
+        callers, with a Markdown rendering. Needs the semantic index."""
+        params = self._params(project, file=file, line=line, col=col, root=root)
+        return HoverResult.from_dict(self.request("hover", params))
+
+    def slice(
+        self,
+        file: str,
+        line: int,
+        func: Optional[str] = None,
+        var: Optional[str] = None,
+        forward: bool = False,
+        project: Optional[str] = None,
+    ) -> SliceResult:
+        """Returns the lines affecting line (or affected by it when forward),
+        with their source. Without func, the function enclosing line is used.
+        Relative files are resolved against the daemon's project root."""
+        params = self._params(project, file=file, line=line, func=func)
+        if var is not None:
+            params["var"] = var
+        if forward:
+            params["direction"] = "forward"
+        return SliceResult.from_dict(self.request("slice", params))
+
+    def warm(
+        self,
+        paths: Union[str, Sequence[str]],
+        project: Optional[str] = None,
+        progress: Optional[Callable[[ProgressEvent], None]] = None,
+    ) -> WarmResult:
+        """Builds the semantic index for paths. Waits until the build finishes;
+        ``progress`` is called with each file's ProgressEvent meanwhile."""
+        if isinstance(paths, str):
+            paths = [paths]
+        params = self._params(project, paths=list(paths))
+        if progress is not None:
+            return WarmResult.from_dict(self._request_progress("warm", params, progress))
+        return WarmResult.from_dict(self.request("warm", params, timeout=None))
+
+    def extract_async(self, path: str, project: Optional[str] = None) -> JobStatus:
+        """Queues an extract as a background job and returns it at once."""
+        params = self._params(project, path=path)
+        params["async"] = True
+        return JobStatus.from_dict(self.request("extract", params))
+
+    def warm_async(self, paths: Union[str, Sequence[str]], project: Optional[str] = None) -> JobStatus:
+        """Queues a warm as a background job and returns it at once. Poll it
+        with ``job_status``; its ``result`` is the usual warm result."""
+        if isinstance(paths, str):
+            paths = [paths]
+        params = self._params(project, paths=list(paths))
+        params["async"] = True
+        return JobStatus.from_dict(self.request("warm", params))
+
+    def job_status(self, job_id: str) -> JobStatus:
+        """Returns the status of a background job."""
+        return JobStatus.from_dict(self.request("job_status", {"job_id": job_id}))
+
+    def jobs(self) -> List[JobStatus]:
+        """Lists the daemon's queued, running and recently finished jobs."""
+        return [JobStatus.from_dict(j) for j in self.request("job_status").get("jobs") or []]
+
+    def cancel_job(self, job_id: str) -> JobStatus:
+        """Cancels a background job. A running job stops after its current
+        file and keeps the files already indexed."""
+        return JobStatus.from_dict(self.request("job_cancel", {"job_id": job_id}))
+
+    def load(self, root: Optional[str] = None) -> LoadIndexResult:
+        """Loads (or reloads) the semantic index built for a project root."""
+        params = {"root": root} if root else {}
+        return LoadIndexResult.from_dict(self.request("load", params))
+
+    def notify(self, path: str, project: Optional[str] = None) -> NotifyResult:
+        """Marks a file as changed so the daemon re-indexes it."""
+        return NotifyResult.from_dict(self.request("notify", self._params(project, path=path)))
+
+    def projects(self) -> List[ProjectInfo]:
+        """Lists the projects open in the daemon."""
+        result = self.request("projects", {"action": "list"})
+        return [ProjectInfo.from_dict(p) for p in result.get("projects") or []]
+
+    def evict_project(self, project: str) -> str:
+        """Saves a project's index and drops it from daemon memory, returning
+        the evicted root. The daemon's default project cannot be evicted."""
+        return self.request("projects", {"action": "evict", "project": project}).get("evicted", "")
+
+    def stop(self) -> None:
+        """Asks the daemon to shut down."""
+        self.request("stop")
+        self.close()
+
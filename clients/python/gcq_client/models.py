@@ -0,0 +1,748 @@
+"""Typed results returned by the gcqd daemon.
+
+Each model mirrors a response type in pkg/client (or the daemon handler that
+produces it) and is built with ``from_dict`` from the decoded JSON result.
+Unknown fields are ignored so newer daemons stay compatible.
+"""
+
+from __future__ import annotations
+
+from dataclasses import dataclass, field
+from datetime import datetime
+from typing import Any, Dict, List, Optional, Tuple
+
+
+def _parse_time(value: Any) -> Optional[datetime]:
+    """Parses an RFC 3339 timestamp as written by Go's encoding/json."""
+    if not value or not isinstance(value, str):
+        return None
+    # Go writes nanoseconds and "Z"; fromisoformat accepts microseconds only
+    text = value.replace("Z", "+00:00")
+    if "." in text:
+        head, rest = text.split(".", 1)
+        digits = ""
+        while rest and rest[0].isdigit():
+            digits, rest = digits + rest[0], rest[1:]
+        text = f"{head}.{digits[:6].ljust(6, '0')}{rest}"
+    try:
+        return datetime.fromisoformat(text)
+    except ValueError:
+        return None
+
+
+@dataclass
+class Degradation:
+    """A feature skipped or fallen back on while a result was produced."""
+
+    feature: str
+    reason: str = ""
+    count: int = 0
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "Degradation":
+        return cls(feature=d.get("feature", ""), reason=d.get("reason", ""), count=d.get("count", 0))
+
+
+def _parse_degradations(d: Dict[str, Any]) -> List[Degradation]:
+    return [Degradation.from_dict(x) for x in d.get("degradations") or []]
+
+
+@dataclass
+class WarmupStatus:
+    """Startup warm-up state of the daemon."""
+
+    state: str = ""
+    indexes_loaded: int = 0
+    queries: int = 0
+    duration_ms: int = 0
+    errors: List[str] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "WarmupStatus":
+        return cls(
+            state=d.get("state", ""),
+            indexes_loaded=d.get("indexes_loaded", 0),
+            queries=d.get("queries", 0),
+            duration_ms=d.get("duration_ms", 0),
+            errors=list(d.get("errors") or []),
+        )
+
+
+@dataclass
+class ProjectQuota:
+    """A project's usage against the daemon's per-project quotas; 0 limits are unlimited."""
+
+    root: str = ""
+    index_mb: float = 0.0
+    max_index_mb: int = 0
+    embed_calls_last_hour: int = 0
+    embed_calls_per_hour: int = 0
+    jobs: int = 0
+    max_jobs: int = 0
+    exceeded: List[str] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "ProjectQuota":
+        return cls(
+            root=d.get("root", ""),
+            index_mb=d.get("index_mb", 0.0),
+            max_index_mb=d.get("max_index_mb", 0),
+            embed_calls_last_hour=d.get("embed_calls_last_hour", 0),
+            embed_calls_per_hour=d.get("embed_calls_per_hour", 0),
+            jobs=d.get("jobs", 0),
+            max_jobs=d.get("max_jobs", 0),
+            exceeded=list(d.get("exceeded") or []),
+        )
+
+
+@dataclass
+class DaemonStatus:
+    """Result of the ``status`` command."""
+
+    version: str = ""
+    status: str = ""
+    provider: str = ""
+    model: str = ""
+    index_count: int = 0
+    dimension: int = 0
+    dirty_count: int = 0
+    reindex_in_progress: bool = False
+    projects: int = 0
+    semantic_indexes: Dict[str, int] = field(default_factory=dict)
+    watching: bool = False
+    warmup: Optional[WarmupStatus] = None
+    quotas: List[ProjectQuota] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "DaemonStatus":
+        warmup = d.get("warmup")
+        return cls(
+            version=d.get("version", ""),
+            status=d.get("status", ""),
+            provider=d.get("provider", ""),
+            model=d.get("model", ""),
+            index_count=d.get("index_count", 0),
+            dimension=d.get("dimension", 0),
+            dirty_count=d.get("dirty_count", 0),
+            reindex_in_progress=d.get("reindex_in_progress", False),
+            projects=d.get("projects", 0),
+            semantic_indexes=dict(d.get("semantic_indexes") or {}),
+            watching=d.get("watching", False),
+            warmup=WarmupStatus.from_dict(warmup) if isinstance(warmup, dict) else None,
+            quotas=[ProjectQuota.from_dict(q) for q in d.get("quotas") or []],
+        )
+
+
+@dataclass
+class BlameHunk:
+    """A run of a unit's lines last changed by one commit."""
+
+    start_line: int
+    end_line: int
+    commit: str = ""
+    author: str = ""
+    date: str = ""
+    subject: str = ""
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "BlameHunk":
+        return cls(
+            start_line=d.get("start_line", 0),
+            end_line=d.get("end_line", 0),
+            commit=d.get("commit", ""),
+            author=d.get("author", ""),
+            date=d.get("date", ""),
+            subject=d.get("subject", ""),
+        )
+
+
+@dataclass
+class SearchResult:
+    """A code unit matched by a semantic, hybrid or keyword search."""
+
+    file_path: str
+    line_number: int
+    name: str
+    type: str
+    score: float
+    signature: str = ""
+    docstring: str = ""
+    uri: str = ""
+    language: str = ""
+    end_line: int = 0
+    metadata: Dict[str, str] = field(default_factory=dict)
+    # context results asked for with blame=True
+    blame: List[BlameHunk] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "SearchResult":
+        # search results use file_path/line_number, context results file/line
+        return cls(
+            file_path=d.get("file_path", d.get("file", "")),
+            line_number=d.get("line_number", d.get("line", 0)),
+            name=d.get("name", ""),
+            type=d.get("type", ""),
+            score=float(d.get("score", 0.0)),
+            signature=d.get("signature", ""),
+            docstring=d.get("docstring", ""),
+            uri=d.get("uri", ""),
+            language=d.get("language", ""),
+            end_line=d.get("end_line", 0),
+            metadata=d.get("metadata") or {},
+            blame=[BlameHunk.from_dict(h) for h in d.get("blame") or []],
+        )
+
+
+@dataclass
+class ResultGroup:
+    """Search results collapsed by file or package."""
+
+    key: str
+    count: int
+    score: float
+    results: List[SearchResult] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "ResultGroup":
+        return cls(
+            key=d.get("key", ""),
+            count=d.get("count", 0),
+            score=float(d.get("score", 0.0)),
+            results=[SearchResult.from_dict(r) for r in d.get("results") or []],
+        )
+
+
+@dataclass
+class SearchResponse:
+    """Result of the ``search`` command in semantic, hybrid or keyword mode."""
+
+    query: str
+    mode: str
+    root: str = ""
+    results: List[SearchResult] = field(default_factory=list)
+    groups: List[ResultGroup] = field(default_factory=list)
+    group_by: str = ""
+    queries: List[str] = field(default_factory=list)
+    degradations: List[Degradation] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "SearchResponse":
+        return cls(
+            query=d.get("query", ""),
+            queries=list(d.get("queries") or []),
+            mode=d.get("mode", ""),
+            root=d.get("root", ""),
+            results=[SearchResult.from_dict(r) for r in d.get("results") or []],
+            groups=[ResultGroup.from_dict(g) for g in d.get("groups") or []],
+            group_by=d.get("group_by", ""),
+            degradations=_parse_degradations(d),
+        )
+
+
+@dataclass
+class TextMatch:
+    """A regex text search match. Columns are 0-based, lines 1-based."""
+
+    file_path: str
+    line_number: int
+    line_content: str
+    column: int = 0
+    end_column: int = 0
+    match: str = ""
+    highlights: List[Tuple[int, int]] = field(default_factory=list)
+    context_before: List[str] = field(default_factory=list)
+    context_after: List[str] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "TextMatch":
+        # The daemon encodes text matches with Go field names
+        return cls(
+            file_path=d.get("FilePath", ""),
+            line_number=d.get("LineNumber", 0),
+            line_content=d.get("LineContent", ""),
+            column=d.get("Column", 0),
+            end_column=d.get("EndColumn", 0),
+            match=d.get("Match", ""),
+            highlights=[(h.get("Start", 0), h.get("End", 0)) for h in d.get("Highlights") or []],
+            context_before=list(d.get("ContextBefore") or []),
+            context_after=list(d.get("ContextAfter") or []),
+        )
+
+
+@dataclass
+class ExtractResult:
+    """Result of the ``extract`` command."""
+
+    extracted: int
+    total: int
+    degradations: List[Degradation] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "ExtractResult":
+        return cls(
+            extracted=d.get("extracted", 0),
+            total=d.get("total", 0),
+            degradations=_parse_degradations(d),
+        )
+
+
+@dataclass
+class ProgressEvent:
+    """One file's progress through ``extract`` or ``warm``. ``event`` is
+    "extracted", "embedded" or "error"; ``done`` counts the files finished
+    so far, embedded or failed, out of ``total``."""
+
+    event: str
+    file: str = ""
+    error: str = ""
+    done: int = 0
+    total: int = 0
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "ProgressEvent":
+        return cls(
+            event=d.get("event", ""),
+            file=d.get("file", ""),
+            error=d.get("error", ""),
+            done=d.get("done", 0),
+            total=d.get("total", 0),
+        )
+
+
+@dataclass
+class JobStatus:
+    """A background ``extract`` or ``warm`` job. ``state`` is "queued",
+    "running", "done", "failed" or "cancelled"; ``result`` holds the
+    command's usual result once the job is done."""
+
+    job_id: str
+    type: str = ""
+    project: str = ""
+    state: str = ""
+    done: int = 0
+    total: int = 0
+    errors: int = 0
+    error: str = ""
+    result: Dict[str, Any] = field(default_factory=dict)
+    created_at: Optional[datetime] = None
+    started_at: Optional[datetime] = None
+    finished_at: Optional[datetime] = None
+
+    @property
+    def finished(self) -> bool:
+        return self.state in ("done", "failed", "cancelled")
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "JobStatus":
+        return cls(
+            job_id=d.get("job_id", ""),
+            type=d.get("type", ""),
+            project=d.get("project", ""),
+            state=d.get("state", ""),
+            done=d.get("done", 0),
+            total=d.get("total", 0),
+            errors=d.get("errors", 0),
+            error=d.get("error", ""),
+            result=dict(d.get("result") or {}),
+            created_at=_parse_time(d.get("created_at")),
+            started_at=_parse_time(d.get("started_at")),
+            finished_at=_parse_time(d.get("finished_at")),
+        )
+
+
+@dataclass
+class ContextResult:
+    """Result of the ``context`` command: the units most relevant to a query."""
+
+    query: str
+    context: List[SearchResult] = field(default_factory=list)
+    degradations: List[Degradation] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "ContextResult":
+        return cls(
+            query=d.get("query", ""),
+            context=[SearchResult.from_dict(u) for u in d.get("context") or []],
+            degradations=_parse_degradations(d),
+        )
+
+
+@dataclass
+class UnitRef:
+    """A reference into BatchResult.units with the score it had for one query."""
+
+    ref: str
+    score: float
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "UnitRef":
+        return cls(ref=d.get("ref", ""), score=float(d.get("score", 0.0)))
+
+
+@dataclass
+class BatchQueryResult:
+    """Results of one query in a batch. With deduplication ``context`` holds
+    references into BatchResult.units; without it ``results`` holds the units."""
+
+    query: str
+    context: List[UnitRef] = field(default_factory=list)
+    results: List[SearchResult] = field(default_factory=list)
+    error: str = ""
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "BatchQueryResult":
+        return cls(
+            query=d.get("query", ""),
+            context=[UnitRef.from_dict(r) for r in d.get("context") or []],
+            results=[SearchResult.from_dict(r) for r in d.get("results") or []],
+            error=d.get("error", ""),
+        )
+
+
+@dataclass
+class BatchResult:
+    """Result of the ``batch`` command."""
+
+    results: List[BatchQueryResult] = field(default_factory=list)
+    units: Dict[str, SearchResult] = field(default_factory=dict)
+    total_hits: int = 0
+    unique_units: int = 0
+    degradations: List[Degradation] = field(default_factory=list)
+
+    def resolve(self, query: BatchQueryResult) -> List[SearchResult]:
+        """Returns the units of one query, following references when deduplicated."""
+        if not query.context:
+            return list(query.results)
+        return [self.units[r.ref] for r in query.context if r.ref in self.units]
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "BatchResult":
+        return cls(
+            results=[BatchQueryResult.from_dict(r) for r in d.get("results") or []],
+            units={k: SearchResult.from_dict(v) for k, v in (d.get("units") or {}).items()},
+            total_hits=d.get("total_hits", 0),
+            unique_units=d.get("unique_units", 0),
+            degradations=_parse_degradations(d),
+        )
+
+
+@dataclass
+class CalledFunction:
+    """A call made by the function a ``calls`` query asked about."""
+
+    name: str
+    type: str
+    line_number: int = 0
+    base: str = ""
+    method: str = ""
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "CalledFunction":
+        return cls(
+            name=d.get("name", ""),
+            type=d.get("type", ""),
+            line_number=d.get("line_number", 0),
+            base=d.get("base", ""),
+            method=d.get("method", ""),
+        )
+
+
+@dataclass
+class CallTreeNode:
+    """A node of a cross-file call tree. ``recursive`` nodes are not expanded."""
+
+    file: str
+    func: str
+    recursive: bool = False
+    children: List["CallTreeNode"] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "CallTreeNode":
+        return cls(
+            file=d.get("file", ""),
+            func=d.get("func", ""),
+            recursive=d.get("recursive", False),
+            children=[CallTreeNode.from_dict(c) for c in d.get("children") or []],
+        )
+
+
+@dataclass
+class CallsResult:
+    """Result of the ``calls`` command."""
+
+    function: str
+    file: str
+    calls: List[CalledFunction] = field(default_factory=list)
+    tree: Optional[CallTreeNode] = None
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "CallsResult":
+        tree = d.get("tree")
+        return cls(
+            function=d.get("function", ""),
+            file=d.get("file", ""),
+            calls=[CalledFunction.from_dict(c) for c in d.get("calls") or []],
+            tree=CallTreeNode.from_dict(tree) if isinstance(tree, dict) else None,
+        )
+
+
+@dataclass
+class Caller:
+    """A unit calling the target of a ``callers`` query, directly (depth 1) or
+    through other callers. ``calls`` is the unit it calls on that path."""
+
+    id: str
+    name: str
+    type: str
+    file: str
+    line: int
+    depth: int
+    calls: str
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "Caller":
+        return cls(
+            id=d.get("id", ""),
+            name=d.get("name", ""),
+            type=d.get("type", ""),
+            file=d.get("file", ""),
+            line=d.get("line", 0),
+            depth=d.get("depth", 0),
+            calls=d.get("calls", ""),
+        )
+
+
+@dataclass
+class CallersResult:
+    """Result of the ``callers`` command."""
+
+    func: str
+    root: str
+    depth: int
+    targets: List[str] = field(default_factory=list)
+    callers: List[Caller] = field(default_factory=list)
+    degradations: List[Degradation] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "CallersResult":
+        return cls(
+            func=d.get("func", ""),
+            root=d.get("root", ""),
+            depth=d.get("depth", 0),
+            targets=list(d.get("targets") or []),
+            callers=[Caller.from_dict(c) for c in d.get("callers") or []],
+            degradations=_parse_degradations(d),
+        )
+
+
+@dataclass
+class SymbolMatch:
+    """A function, class or method whose name matches a ``symbols`` pattern.
+    ``positions`` are the offsets in ``name`` of the matched characters."""
+
+    name: str
+    kind: str
+    file: str
+    line: int
+    score: int
+    positions: List[int] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "SymbolMatch":
+        return cls(
+            name=d.get("name", ""),
+            kind=d.get("kind", ""),
+            file=d.get("file", ""),
+            line=d.get("line", 0),
+            score=d.get("score", 0),
+            positions=list(d.get("positions") or []),
+        )
+
+
+@dataclass
+class SymbolsResult:
+    """Result of the ``symbols`` command, best match first. ``total`` is the
+    number of symbols searched."""
+
+    pattern: str
+    root: str
+    total: int
+    matches: List[SymbolMatch] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "SymbolsResult":
+        return cls(
+            pattern=d.get("pattern", ""),
+            root=d.get("root", ""),
+            total=d.get("total", 0),
+            matches=[SymbolMatch.from_dict(m) for m in d.get("matches") or []],
+        )
+
+
+@dataclass
+class HoverResult:
+    """Result of the ``hover`` command: the symbol at a source position.
+    ``type`` holds what extraction knows of its types (params, returns,
+    owner, bases, decorators, async), ``callers`` the first few direct
+    callers out of ``caller_count``, and ``markdown`` a rendering for
+    editor hovers."""
+
+    id: str
+    name: str
+    kind: str
+    file: str
+    line: int
+    markdown: str
+    signature: str = ""
+    docs: str = ""
+    end_line: int = 0
+    complexity: int = 0
+    caller_count: int = 0
+    type: Dict[str, Any] = field(default_factory=dict)
+    callers: List[Caller] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "HoverResult":
+        return cls(
+            id=d.get("id", ""),
+            name=d.get("name", ""),
+            kind=d.get("kind", ""),
+            file=d.get("file", ""),
+            line=d.get("line", 0),
+            markdown=d.get("markdown", ""),
+            signature=d.get("signature", ""),
+            docs=d.get("docs", ""),
+            end_line=d.get("end_line", 0),
+            complexity=d.get("complexity", 0),
+            caller_count=d.get("caller_count", 0),
+            type=dict(d.get("type") or {}),
+            callers=[Caller.from_dict(c) for c in d.get("callers") or []],
+        )
+
+
+@dataclass
+class SliceLine:
+    """A line of a program slice with its source code."""
+
+    line: int
+    code: str
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "SliceLine":
+        return cls(line=d.get("line", 0), code=d.get("code", ""))
+
+
+@dataclass
+class SliceResult:
+    """Result of the ``slice`` command."""
+
+    file: str
+    function_name: str
+    line: int
+    direction: str
+    variable: str = ""
+    slice_lines: List[int] = field(default_factory=list)
+    lines: List[SliceLine] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "SliceResult":
+        return cls(
+            file=d.get("file", ""),
+            function_name=d.get("function_name", ""),
+            line=d.get("line", 0),
+            direction=d.get("direction", ""),
+            variable=d.get("variable", ""),
+            slice_lines=list(d.get("slice_lines") or []),
+            lines=[SliceLine.from_dict(l) for l in d.get("lines") or []],
+        )
+
+
+@dataclass
+class WarmResult:
+    """Result of the ``warm`` command."""
+
+    extracted: int
+    paths: List[str] = field(default_factory=list)
+    degradations: List[Degradation] = field(default_factory=list)
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "WarmResult":
+        return cls(
+            extracted=d.get("extracted", 0),
+            paths=list(d.get("paths") or []),
+            degradations=_parse_degradations(d),
+        )
+
+
+@dataclass
+class LoadIndexResult:
+    """Result of the ``load`` command."""
+
+    root: str
+    count: int
+    dimension: int
+    model: str = ""
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "LoadIndexResult":
+        return cls(
+            root=d.get("root", ""),
+            count=d.get("count", 0),
+            dimension=d.get("dimension", 0),
+            model=d.get("model", ""),
+        )
+
+
+@dataclass
+class NotifyResult:
+    """Result of the ``notify`` command."""
+
+    path: str
+    project: str = ""
+    dirty_count: int = 0
+    threshold: int = 0
+    reindex_triggered: bool = False
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "NotifyResult":
+        return cls(
+            path=d.get("path", ""),
+            project=d.get("project", ""),
+            dirty_count=d.get("dirty_count", 0),
+            threshold=d.get("threshold", 0),
+            reindex_triggered=d.get("reindex_triggered", False),
+        )
+
+
+@dataclass
+class ProjectInfo:
+    """A project open in the daemon."""
+
+    root: str
+    default: bool = False
+    index_path: str = ""
+    index_count: int = 0
+    dimension: int = 0
+    semantic_count: int = 0
+    paths: List[str] = field(default_factory=list)
+    dirty_count: int = 0
+    reindex_in_progress: bool = False
+    last_used: Optional[datetime] = None
+    quota: Optional[ProjectQuota] = None
+
+    @classmethod
+    def from_dict(cls, d: Dict[str, Any]) -> "ProjectInfo":
+        quota = d.get("quota")
+        return cls(
+            root=d.get("root", ""),
+            default=d.get("default", False),
+            index_path=d.get("index_path", ""),
+            index_count=d.get("index_count", 0),
+            dimension=d.get("dimension", 0),
+            semantic_count=d.get("semantic_count", 0),
+            paths=list(d.get("paths") or []),
+            dirty_count=d.get("dirty_count", 0),
+            reindex_in_progress=d.get("reindex_in_progress", False),
+            last_used=_parse_time(d.get("last_used")),
+            quota=ProjectQuota.from_dict(quota) if isinstance(quota, dict) else None,
+        )
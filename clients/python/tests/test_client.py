@@ -0,0 +1,301 @@
+import json
+import os
+import socket
+import tempfile
+import threading
+import unittest
+
+from gcq_client import Client, DaemonError, DaemonUnavailableError
+
+
+class FakeDaemon:
+    """Serves canned gcqd responses on a Unix socket, one thread per connection."""
+
+    def __init__(self, handler):
+        self.handler = handler
+        self.requests = []
+        self.connections = 0
+        self.dir = tempfile.mkdtemp()
+        self.path = os.path.join(self.dir, "gcq.sock")
+        self.sock = socket.socket(socket.AF_UNIX, socket.SOCK_STREAM)
+        self.sock.bind(self.path)
+        self.sock.listen()
+        threading.Thread(target=self._accept, daemon=True).start()
+
+    def _accept(self):
+        while True:
+            try:
+                conn, _ = self.sock.accept()
+            except OSError:
+                return
+            self.connections += 1
+            threading.Thread(target=self._serve, args=(conn,), daemon=True).start()
+
+    def _serve(self, conn):
+        with conn, conn.makefile("rb") as reader:
+            for line in reader:
+                cmd = json.loads(line)
+                self.requests.append(cmd)
+                for frame in self.handler(cmd):
+                    conn.sendall(json.dumps(frame).encode() + b"\n")
+
+    def close(self):
+        self.sock.close()
+        os.unlink(self.path)
+        os.rmdir(self.dir)
+
+
+def reply(cmd, result, type_=None):
+    return {"id": cmd["id"], "type": type_ or cmd["type"], "result": result}
+
+
+class ClientTest(unittest.TestCase):
+    def serve(self, handler):
+        daemon = FakeDaemon(handler)
+        self.addCleanup(daemon.close)
+        client = Client(socket_path=daemon.path)
+        self.addCleanup(client.close)
+        return daemon, client
+
+    def test_search_reuses_connection(self):
+        def handler(cmd):
+            yield reply(cmd, {
+                "mode": "semantic",
+                "query": cmd["params"]["query"],
+                "results": [{"uri": "go://pkg#Parse", "file_path": "pkg/parse.go", "line_number": 12,
+                             "name": "Parse", "type": "function", "score": 0.91}],
+                "count": 1,
+            })
+
+        daemon, client = self.serve(handler)
+        first = client.search("parse config", limit=5)
+        client.search("load index", project="/work/api")
+
+        self.assertEqual(first.results[0].file_path, "pkg/parse.go")
+        self.assertEqual(first.results[0].line_number, 12)
+        self.assertAlmostEqual(first.results[0].score, 0.91)
+        self.assertEqual(daemon.connections, 1)
+        self.assertEqual(daemon.requests[0]["params"], {"query": "parse config", "limit": 5, "mode": "semantic"})
+        self.assertEqual(daemon.requests[1]["params"]["project"], "/work/api")
+
+    def test_search_fuses_queries(self):
+        def handler(cmd):
+            params = cmd["params"]
+            yield reply(cmd, {"mode": "hybrid", "query": params["query"],
+                              "queries": [params["query"]] + params["queries"], "results": [], "count": 0})
+
+        daemon, client = self.serve(handler)
+        resp = client.search(["how are tokens refreshed", "refreshToken"], mode="hybrid", exclude_terms=["mock"])
+
+        self.assertEqual(daemon.requests[0]["params"],
+                         {"query": "how are tokens refreshed", "queries": ["refreshToken"], "mode": "hybrid",
+                          "exclude_terms": ["mock"]})
+        self.assertEqual(resp.queries, ["how are tokens refreshed", "refreshToken"])
+
+    def test_search_metadata(self):
+        def handler(cmd):
+            yield reply(cmd, {"mode": "semantic", "query": cmd["params"]["query"], "count": 1,
+                              "results": [{"file_path": "pay.py", "line_number": 3, "name": "charge",
+                                           "type": "function", "score": 0.8, "metadata": {"ticket": "PAY-12"}}]})
+
+        daemon, client = self.serve(handler)
+        resp = client.search("charge card", metadata={"ticket": "PAY-12"})
+        client.search("charge card", metadata={})
+
+        self.assertEqual(daemon.requests[0]["params"]["metadata"], {"ticket": "PAY-12"})
+        self.assertNotIn("metadata", daemon.requests[1]["params"])
+        self.assertEqual(resp.results[0].metadata, {"ticket": "PAY-12"})
+
+    def test_search_degradations(self):
+        def handler(cmd):
+            yield reply(cmd, {"mode": "semantic", "query": "parse", "count": 0, "results": [],
+                              "degradations": [{"feature": "stale_index", "reason": "files changed", "count": 2},
+                                               {"feature": "provider_fallback", "reason": "runtime unreachable"}]})
+
+        _, client = self.serve(handler)
+        resp = client.search("parse")
+        self.assertEqual([d.feature for d in resp.degradations], ["stale_index", "provider_fallback"])
+        self.assertEqual(resp.degradations[0].count, 2)
+        self.assertEqual(resp.degradations[1].count, 0)
+
+    def test_search_budget(self):
+        def handler(cmd):
+            yield reply(cmd, {"mode": "semantic", "query": "parse", "count": 0, "results": [],
+                              "degradations": [{"feature": "search_budget", "reason": "search budget ran out"}]})
+
+        daemon, client = self.serve(handler)
+        resp = client.search("parse", budget_ms=50)
+        client.search("parse")
+
+        self.assertEqual(daemon.requests[0]["params"]["budget_ms"], 50)
+        self.assertNotIn("budget_ms", daemon.requests[1]["params"])
+        self.assertEqual(resp.degradations[0].feature, "search_budget")
+
+    def test_context_blame(self):
+        def handler(cmd):
+            yield reply(cmd, {"query": "parse", "context": [
+                {"file": "p.py", "line": 3, "name": "parse", "type": "function", "score": 0.9,
+                 "blame": [{"start_line": 3, "end_line": 5, "commit": "1a2b3c4", "author": "alice",
+                            "date": "2026-01-02T10:00:00Z", "subject": "Add parser"}]}]})
+
+        daemon, client = self.serve(handler)
+        resp = client.context("parse", blame=True)
+        client.context("parse")
+
+        self.assertTrue(daemon.requests[0]["params"]["blame"])
+        self.assertNotIn("blame", daemon.requests[1]["params"])
+        hunk = resp.context[0].blame[0]
+        self.assertEqual((hunk.start_line, hunk.end_line, hunk.author, hunk.subject), (3, 5, "alice", "Add parser"))
+
+    def test_skips_stale_frames(self):
+        def handler(cmd):
+            # gcqd writes an id-less decode error to connections left idle
+            yield {"error": "decode error: i/o timeout"}
+            yield reply(cmd, {"func": "handle", "root": "/p", "depth": 2, "targets": ["py://app.py#handle"],
+                              "callers": [{"id": "py://app.py#serve", "name": "serve", "type": "function",
+                                           "file": "app.py", "line": 10, "depth": 1, "calls": "handle"}],
+                              "count": 1})
+
+        _, client = self.serve(handler)
+        result = client.callers("handle", depth=2)
+        self.assertEqual(result.callers[0].name, "serve")
+        self.assertEqual(result.callers[0].calls, "handle")
+
+    def test_symbols(self):
+        def handler(cmd):
+            yield reply(cmd, {"pattern": "prs", "root": "/p", "total": 12, "count": 1,
+                              "matches": [{"name": "Parser.parse", "kind": "method", "file": "app.py", "line": 4,
+                                           "score": 70, "positions": [0, 2, 7]}]})
+
+        daemon, client = self.serve(handler)
+        result = client.symbols("prs", limit=5)
+        self.assertEqual(daemon.requests[0]["params"], {"pattern": "prs", "limit": 5})
+        self.assertEqual(result.total, 12)
+        self.assertEqual(result.matches[0].name, "Parser.parse")
+        self.assertEqual(result.matches[0].positions, [0, 2, 7])
+
+    def test_hover(self):
+        def handler(cmd):
+            yield reply(cmd, {"id": "py://app.py#Parser.parse", "name": "Parser.parse", "kind": "method",
+                              "file": "app.py", "line": 4, "complexity": 3, "caller_count": 7,
+                              "type": {"owner": "Parser", "returns": "list"},
+                              "callers": [{"id": "py://app.py#load", "name": "load", "type": "function",
+                                           "file": "app.py", "line": 20, "depth": 1, "calls": "Parser.parse"}],
+                              "markdown": "```python\ndef parse(self, text)\n```\n"})
+
+        daemon, client = self.serve(handler)
+        result = client.hover("app.py", 4, col=9)
+        self.assertEqual(daemon.requests[0]["params"], {"file": "app.py", "line": 4, "col": 9})
+        self.assertEqual(result.type["owner"], "Parser")
+        self.assertEqual(result.caller_count, 7)
+        self.assertEqual(result.callers[0].name, "load")
+        self.assertTrue(result.markdown.startswith("```python"))
+
+    def test_status_quotas(self):
+        def handler(cmd):
+            yield reply(cmd, {"version": "1.0", "status": "running", "projects": 2,
+                              "quotas": [{"root": "/mono", "index_mb": 64.5, "max_index_mb": 64,
+                                          "embed_calls_last_hour": 12, "embed_calls_per_hour": 100,
+                                          "jobs": 1, "max_jobs": 2, "exceeded": ["index_memory"]}]})
+
+        _, client = self.serve(handler)
+        status = client.status()
+        self.assertEqual(status.quotas[0].root, "/mono")
+        self.assertEqual(status.quotas[0].exceeded, ["index_memory"])
+        self.assertEqual(status.quotas[0].embed_calls_last_hour, 12)
+
+    def test_timeout_sent(self):
+        def handler(cmd):
+            yield reply(cmd, {"version": "1.0", "status": "running"})
+
+        daemon, client = self.serve(handler)
+        client.request("status", timeout=2.5)
+        client.request("status", timeout=None)
+        self.assertEqual(daemon.requests[0]["timeout_ms"], 2500)
+        self.assertNotIn("timeout_ms", daemon.requests[1])
+
+    def test_daemon_error(self):
+        def handler(cmd):
+            yield {"id": cmd["id"], "error": "query is required"}
+
+        _, client = self.serve(handler)
+        with self.assertRaises(DaemonError) as ctx:
+            client.context("")
+        self.assertEqual(ctx.exception.message, "query is required")
+
+    def test_text_search_stream(self):
+        def handler(cmd):
+            match = {"FilePath": "a.go", "LineNumber": 3, "LineContent": "// TODO", "Match": "TODO",
+                     "Highlights": [{"Start": 3, "End": 7}]}
+            yield reply(cmd, {"matches": [match], "count": 1}, "search_batch")
+            yield reply(cmd, {"matches": [match], "count": 1}, "search_batch")
+            yield reply(cmd, {"mode": "text", "count": 2, "done": True}, "search")
+
+        _, client = self.serve(handler)
+        batches = list(client.text_search_stream("TODO", "/src", batch_size=1))
+        self.assertEqual(len(batches), 2)
+        self.assertEqual(batches[0][0].highlights, [(3, 7)])
+        # the connection is still usable after a completed stream
+        self.assertEqual(len(list(client.text_search_stream("TODO", "/src"))), 2)
+
+    def test_warm_progress(self):
+        def handler(cmd):
+            if cmd["params"].get("progress"):
+                yield reply(cmd, {"event": "extracted", "file": "/src/a.py", "done": 0, "total": 2}, "warm_batch")
+                yield reply(cmd, {"event": "embedded", "file": "/src/a.py", "done": 1, "total": 2}, "warm_batch")
+                yield reply(cmd, {"event": "error", "file": "/src/b.py", "error": "syntax error",
+                                  "done": 2, "total": 2}, "warm_batch")
+            yield reply(cmd, {"extracted": 1, "paths": ["/src"]}, "warm")
+
+        _, client = self.serve(handler)
+        events = []
+        result = client.warm("/src", progress=events.append)
+        self.assertEqual(result.extracted, 1)
+        self.assertEqual([e.event for e in events], ["extracted", "embedded", "error"])
+        self.assertEqual(events[2].error, "syntax error")
+        self.assertEqual(events[2].done, events[2].total)
+        # without a callback the command is sent as before
+        self.assertEqual(client.warm("/src").extracted, 1)
+
+    def test_warm_async(self):
+        def handler(cmd):
+            if cmd["type"] == "warm":
+                self.assertTrue(cmd["params"]["async"])
+                yield reply(cmd, {"job_id": "job-1", "type": "warm", "state": "queued",
+                                  "created_at": "2026-01-02T03:04:05.123456789Z"})
+            else:
+                yield reply(cmd, {"job_id": cmd["params"]["job_id"], "type": "warm", "state": "done",
+                                  "done": 2, "total": 2, "result": {"extracted": 2, "paths": ["/src"]}})
+
+        _, client = self.serve(handler)
+        job = client.warm_async("/src")
+        self.assertEqual(job.state, "queued")
+        self.assertFalse(job.finished)
+        self.assertIsNotNone(job.created_at)
+
+        job = client.job_status(job.job_id)
+        self.assertTrue(job.finished)
+        self.assertEqual(job.result["extracted"], 2)
+
+    def test_batch_resolves_references(self):
+        def handler(cmd):
+            yield reply(cmd, {
+                "results": [{"query": "a", "context": [{"ref": "u1", "score": 0.5}]}],
+                "units": {"u1": {"file_path": "x.py", "line_number": 1, "name": "f", "type": "function", "score": 0.5}},
+                "total_hits": 1,
+                "unique_units": 1,
+            })
+
+        _, client = self.serve(handler)
+        result = client.batch(["a"])
+        self.assertEqual([u.name for u in result.resolve(result.results[0])], ["f"])
+
+    def test_unavailable(self):
+        client = Client(socket_path=os.path.join(tempfile.gettempdir(), "gcq-missing.sock"))
+        self.assertFalse(client.is_running())
+        with self.assertRaises(DaemonUnavailableError):
+            client.status()
+
+
+if __name__ == "__main__":
+    unittest.main()
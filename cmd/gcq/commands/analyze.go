@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/l3aro/go-context-query/pkg/findings"
+	"github.com/l3aro/go-context-query/pkg/semantic"
+	"github.com/spf13/cobra"
+)
+
+// Rules reported by the analysis commands
+var (
+	complexityRule = findings.Rule{ID: "gcq/complexity", Description: "Function cyclomatic complexity exceeds the threshold"}
+	deadCodeRule   = findings.Rule{ID: "gcq/dead-code", Description: "Function is not called anywhere in the project"}
+)
+
+const analysisOutputHelp = `
+Findings are printed as a table by default. --format selects another sink:
+  json     gcq's JSON report (to --output or stdout)
+  sarif    a SARIF 2.1.0 log for code scanning tools (to --output or stdout)
+  github   GitHub Actions annotations on stdout
+  webhook  the JSON report POSTed to the --output URL`
+
+// complexityCmd represents the complexity command
+var complexityCmd = &cobra.Command{
+	Use:   "complexity [path]",
+	Short: "Report functions whose cyclomatic complexity exceeds a threshold",
+	Long: `Reports every function and method in the semantic index whose cyclomatic
+complexity is above --threshold. Functions above twice the threshold are
+errors, the rest warnings. Run 'gcq warm' first to build the index.
+` + analysisOutputHelp,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root, units, err := analysisUnits(args)
+		if err != nil {
+			return err
+		}
+		threshold, _ := cmd.Flags().GetInt("threshold")
+		if threshold <= 0 {
+			return fmt.Errorf("threshold must be positive, got %d", threshold)
+		}
+
+		report := &findings.Report{Analysis: "complexity", Root: root, Rules: []findings.Rule{complexityRule}}
+		for _, unit := range units {
+			complexity, ok := semantic.UnitComplexity(unit)
+			if !ok || complexity <= threshold {
+				continue
+			}
+			severity := findings.SeverityWarning
+			if complexity > 2*threshold {
+				severity = findings.SeverityError
+			}
+			report.Findings = append(report.Findings, findings.Finding{
+				RuleID:   complexityRule.ID,
+				Severity: severity,
+				Message:  fmt.Sprintf("%s has cyclomatic complexity %d (threshold %d)", unit.Name, complexity, threshold),
+				File:     unit.FilePath,
+				Line:     unit.LineNumber,
+				Unit:     unit.Name,
+			})
+		}
+		return writeFindings(cmd, report)
+	},
+}
+
+// deadCodeCmd represents the deadcode command
+var deadCodeCmd = &cobra.Command{
+	Use:   "deadcode [path]",
+	Short: "Report functions nothing in the project calls",
+	Long: `Reports functions and methods in the semantic index that have no callers in
+the project's call graph. Tests, entry points such as main and __init__,
+and exported Go identifiers are left out, since they are called from
+outside the project. Run 'gcq warm' first to build the index.
+` + analysisOutputHelp,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root, units, err := analysisUnits(args)
+		if err != nil {
+			return err
+		}
+
+		report := &findings.Report{Analysis: "dead-code", Root: root, Rules: []findings.Rule{deadCodeRule}}
+		for _, unit := range units {
+			if !semantic.IsDeadCode(unit) {
+				continue
+			}
+			report.Findings = append(report.Findings, findings.Finding{
+				RuleID:   deadCodeRule.ID,
+				Severity: findings.SeverityNote,
+				Message:  fmt.Sprintf("%s %s is never called", unit.Type, unit.Name),
+				File:     unit.FilePath,
+				Line:     unit.LineNumber,
+				Unit:     unit.Name,
+			})
+		}
+		return writeFindings(cmd, report)
+	},
+}
+
+func init() {
+	complexityCmd.Flags().Int("threshold", 10, "Report functions with a cyclomatic complexity above this")
+	addFindingsFlags(complexityCmd)
+	addFindingsFlags(deadCodeCmd)
+}
@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/l3aro/go-context-query/internal/config"
+	"github.com/l3aro/go-context-query/pkg/blame"
+	"github.com/l3aro/go-context-query/pkg/bundle"
+	"github.com/l3aro/go-context-query/pkg/search"
+	"github.com/l3aro/go-context-query/pkg/semantic"
+	"github.com/spf13/cobra"
+)
+
+// bundleCmd represents the bundle command
+var bundleCmd = &cobra.Command{
+	Use:   "bundle <query>",
+	Short: "Assemble an LLM-ready context document for a query",
+	Long: `Searches the semantic index for <query> and writes one Markdown document
+with the top units: their source, their direct callers and callees, and the
+imports of their files.
+
+The document is cut to fit a token budget (--tokens, default
+limits.bundle_tokens, 8000). Tokens are counted the way byte pair encoders
+such as cl100k_base split text. Units are added best first; one that does
+not fit has its source shortened, then its callers, callees and imports
+dropped, and is left out when not even its heading fits.
+
+With --blame each unit also lists the commits that last changed its lines
+(short hash, author, date and subject), read from the git repository's
+HEAD.
+
+Examples:
+  gcq bundle "how are auth tokens refreshed" > context.md
+  gcq bundle "parse config" -k 5 --tokens 4000
+  gcq bundle "retry logic" --hybrid --json
+  gcq bundle "session expiry" --blame`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		queries := search.CombineQueries("", args)
+		if len(queries) == 0 {
+			return fmt.Errorf("query cannot be empty")
+		}
+
+		rootDir, err := semanticRootDir(cmd)
+		if err != nil {
+			return err
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		k, _ := cmd.Flags().GetInt("k")
+		k = cfg.Limits.ContextLimit(k)
+		budget, _ := cmd.Flags().GetInt("tokens")
+		budget = cfg.Limits.BundleBudget(budget)
+
+		searcher, opened, err := openSearcher(cmd, cfg, rootDir)
+		if err != nil {
+			return err
+		}
+		includeTests, _ := cmd.Flags().GetBool("include-tests")
+		mode := ""
+		if hybrid, _ := cmd.Flags().GetBool("hybrid"); hybrid {
+			mode = "hybrid"
+		}
+		results, err := searcher.SearchQueries(context.Background(), mode, queries, k, search.SearchOptions{IncludeTests: includeTests})
+		if err != nil {
+			return fmt.Errorf("performing search: %w", err)
+		}
+
+		graph, err := semantic.LoadCallerGraph(rootDir)
+		if err != nil {
+			return fmt.Errorf("loading call graph: %w", err)
+		}
+		opts := bundle.Options{Budget: budget}
+		if withBlame, _ := cmd.Flags().GetBool("blame"); withBlame {
+			if opts.Blamer, err = blame.Open(rootDir); err != nil {
+				return err
+			}
+		}
+		b := bundle.Build(rootDir, queries[0], results, graph, opts)
+
+		var resultFiles []string
+		for _, r := range results {
+			resultFiles = append(resultFiles, r.FilePath)
+		}
+		printDegradations(opened.resultDegradations(rootDir, resultFiles))
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			data, err := json.MarshalIndent(b, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshaling JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Print(b.Document)
+		fmt.Fprintf(os.Stderr, "%d units, %d of %d tokens", len(b.Entries), b.Tokens, b.Budget)
+		if b.Omitted > 0 {
+			fmt.Fprintf(os.Stderr, ", %d units left out", b.Omitted)
+		}
+		fmt.Fprintln(os.Stderr)
+		return nil
+	},
+}
+
+func init() {
+	bundleCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	bundleCmd.Flags().IntP("k", "k", 0, "Number of units to search for (default: limits.context_results, 5)")
+	bundleCmd.Flags().Int("tokens", 0, "Token budget of the document (default: limits.bundle_tokens, 8000)")
+	bundleCmd.Flags().String("path", "", "Project path to search (defaults to current directory)")
+	bundleCmd.Flags().Bool("hybrid", false, "Fuse vector search with a keyword (BM25) pass")
+	bundleCmd.Flags().Bool("include-tests", false, "Include units from test files")
+	bundleCmd.Flags().Bool("blame", false, "List the commits that last changed each unit (git repositories only)")
+	bundleCmd.Flags().StringP("provider", "p", "", "Embedding provider (ollama, huggingface, local or mock)")
+	bundleCmd.Flags().StringP("model", "m", "", "Embedding model name")
+}
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/l3aro/go-context-query/internal/daemon"
+	"github.com/l3aro/go-context-query/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+// callersCmd represents the callers command
+var callersCmd = &cobra.Command{
+	Use:   "callers <func>",
+	Short: "Find the functions that call a function",
+	Long: `Answers "who calls X" from the cross-file call graph saved in the semantic
+index, without re-parsing the project. <func> is a function name, a
+qualified name (Class.method, Type.Method) or a unit URI; --file narrows it
+to one file when the name is defined in several.
+
+--depth follows callers of callers: depth 2 adds the functions that call the
+direct callers, and so on. Each caller is listed once, at its shortest
+distance, with the function it calls on the way to the target.
+
+Uses the daemon when it is running. Run 'gcq warm' first to build the index.
+
+Examples:
+  gcq callers parseConfig
+  gcq callers VectorIndex.Save --depth 3
+  gcq callers handle --file api/server.py --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rootDir, err := semanticRootDir(cmd)
+		if err != nil {
+			return err
+		}
+		depth, _ := cmd.Flags().GetInt("depth")
+		if depth <= 0 {
+			return fmt.Errorf("depth must be positive")
+		}
+		file, _ := cmd.Flags().GetString("file")
+
+		params := client.CallersParams{Func: args[0], File: file, Depth: depth, Root: rootDir}
+
+		var result *client.CallersResult
+		if daemon.IsRunning() {
+			result, err = client.New().Callers(context.Background(), params)
+		}
+		if result == nil {
+			// No daemon, or it couldn't serve the index: read it directly
+			executor := &client.Executor{}
+			result, err = executor.Callers(context.Background(), params)
+		}
+		if err != nil {
+			return err
+		}
+		printDegradations(result.Degradations)
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			data, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshaling JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		printCallers(result)
+		return nil
+	},
+}
+
+func printCallers(result *client.CallersResult) {
+	if len(result.Callers) == 0 {
+		fmt.Printf("No callers of %s found\n", strings.Join(result.Targets, ", "))
+		return
+	}
+
+	fmt.Printf("Callers of %s:\n\n", strings.Join(result.Targets, ", "))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DEPTH\tCALLER\tLOCATION\tCALLS")
+	for _, c := range result.Callers {
+		fmt.Fprintf(w, "%d\t%s\t%s:%d\t%s\n", c.Depth, c.Name, c.File, c.Line, c.Calls)
+	}
+	w.Flush()
+	fmt.Printf("\n%d callers\n", result.Count)
+}
+
+func init() {
+	callersCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	callersCmd.Flags().IntP("depth", "d", 1, "Levels of transitive callers to follow")
+	callersCmd.Flags().StringP("file", "f", "", "Only match the function in this file")
+	callersCmd.Flags().String("path", "", "Project path whose index is queried (defaults to current directory)")
+}
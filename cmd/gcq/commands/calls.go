@@ -1,14 +1,18 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/l3aro/go-context-query/internal/config"
 	"github.com/l3aro/go-context-query/internal/daemon"
 	"github.com/l3aro/go-context-query/internal/scanner"
 	"github.com/l3aro/go-context-query/pkg/callgraph"
+	"github.com/l3aro/go-context-query/pkg/client"
 	"github.com/l3aro/go-context-query/pkg/extractor"
 	"github.com/l3aro/go-context-query/pkg/types"
 	"github.com/spf13/cobra"
@@ -32,20 +36,57 @@ type CallGraphStats struct {
 
 // UnresolvedCall represents an unresolved call
 type UnresolvedCall struct {
-	CallerFile string `json:"caller_file"`
-	CallerFunc string `json:"caller_func"`
-	CallName   string `json:"call_name"`
-	Reason     string `json:"reason"`
+	CallerFile string   `json:"caller_file"`
+	CallerFunc string   `json:"caller_func"`
+	CallName   string   `json:"call_name"`
+	Reason     string   `json:"reason"`
+	Candidates []string `json:"candidates,omitempty"`
 }
 
 // callsCmd represents the calls command
 var callsCmd = &cobra.Command{
-	Use:   "calls [path]",
+	Use:   "calls [path] | calls <file> <func> | calls <unit-uri>",
 	Short: "Build call graph for a project",
 	Long: `Analyzes a project and builds a call graph showing function calls.
-The call graph includes both intra-file and cross-file edges.`,
-	Args: cobra.RangeArgs(0, 1),
+The call graph includes both intra-file and cross-file edges.
+
+Each edge records the line of its call site and the source text of the
+call's arguments, so call sites can be filtered by what they pass:
+--callee keeps calls to one function, --arg calls with an argument
+containing the given text, and --literal calls passing a hardcoded string,
+number or constant.
+
+When given a file and a function name, or a unit URI, renders the call tree
+rooted at that function instead. Use --depth to control how many levels are
+expanded and --reverse to show callers rather than callees.
+
+Examples:
+  gcq calls ./src
+  gcq calls --callee load_config --literal
+  gcq calls --callee Open --arg .yaml --json
+  gcq calls main.py main --depth 3
+  gcq calls py://pkg/mod.py#Worker.run
+  gcq calls index.go VectorIndex.Save
+  gcq calls utils.py helper --reverse --json`,
+	Args: cobra.RangeArgs(0, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		installLanguagePacks()
+
+		if len(args) == 2 {
+			return runCallTree(args[0], args[1], cmd)
+		}
+		if len(args) == 1 && types.IsUnitURI(args[0]) {
+			file, funcName, err := resolveUnitURI(args[0])
+			if err != nil {
+				return err
+			}
+			// Go methods are keyed by Type.Method in the call graph
+			if u, _ := types.ParseUnitURI(args[0]); u.Language() == "go" {
+				funcName = u.Symbol
+			}
+			return runCallTree(file, funcName, cmd)
+		}
+
 		path := "."
 		if len(args) > 0 {
 			path = args[0]
@@ -136,13 +177,19 @@ func runCallsLocally(path string, cmd *cobra.Command) error {
 			CallerFunc: u.CallerFunc,
 			CallName:   u.CallName,
 			Reason:     u.Reason,
+			Candidates: u.Candidates,
 		})
 	}
 
+	callee, _ := cmd.Flags().GetString("callee")
+	arg, _ := cmd.Flags().GetString("arg")
+	literal, _ := cmd.Flags().GetBool("literal")
+	edges := callgraph.FilterEdges(stopSymbols().Filter(callGraph.Edges), callgraph.EdgeFilter{Callee: callee, Arg: arg, Literal: literal})
+
 	output := CallGraphOutput{
 		RootDir:    rootDir,
 		Stats:      stats,
-		Edges:      callGraph.Edges,
+		Edges:      edges,
 		Unresolved: unresolved,
 	}
 
@@ -173,9 +220,15 @@ func printCallGraph(output CallGraphOutput) {
 	if len(output.Edges) > 0 {
 		fmt.Println("Edges:")
 		for _, edge := range output.Edges {
-			fmt.Printf("  %s:%s -> %s:%s\n",
+			if edge.Line == 0 {
+				fmt.Printf("  %s:%s -> %s:%s\n",
+					edge.SourceFile, edge.SourceFunc,
+					edge.DestFile, edge.DestFunc)
+				continue
+			}
+			fmt.Printf("  %s:%s -> %s:%s(%s) at line %d\n",
 				edge.SourceFile, edge.SourceFunc,
-				edge.DestFile, edge.DestFunc)
+				edge.DestFile, edge.DestFunc, strings.Join(edge.Args, ", "), edge.Line)
 		}
 	}
 
@@ -184,8 +237,140 @@ func printCallGraph(output CallGraphOutput) {
 		for _, u := range output.Unresolved {
 			fmt.Printf("  %s:%s calls %s (%s)\n",
 				u.CallerFile, u.CallerFunc, u.CallName, u.Reason)
+			if len(u.Candidates) > 0 {
+				fmt.Printf("      candidates: %s\n", strings.Join(u.Candidates, ", "))
+			}
+		}
+	}
+}
+
+// runCallTree renders the call tree rooted at funcName in file
+func runCallTree(file, funcName string, cmd *cobra.Command) error {
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return fmt.Errorf("getting absolute path: %w", err)
+	}
+	if _, err := os.Stat(absFile); err != nil {
+		return fmt.Errorf("file not found: %s", file)
+	}
+
+	depth, _ := cmd.Flags().GetInt("depth")
+	reverse, _ := cmd.Flags().GetBool("reverse")
+	if depth <= 0 {
+		return fmt.Errorf("depth must be positive")
+	}
+
+	var tree *callgraph.CallTreeNode
+	if daemon.IsRunning() {
+		tree, err = runCallTreeViaDaemon(absFile, funcName, depth, reverse)
+	} else {
+		tree, err = runCallTreeLocally(absFile, funcName, depth, reverse)
+	}
+	if err != nil {
+		return err
+	}
+
+	jsonOutput, _ := cmd.Flags().GetBool("json")
+	if jsonOutput {
+		data, err := json.MarshalIndent(tree, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	printCallTree(tree, reverse)
+	return nil
+}
+
+func runCallTreeViaDaemon(absFile, funcName string, depth int, reverse bool) (*callgraph.CallTreeNode, error) {
+	result, err := client.New().Calls(context.Background(), client.CallsParams{
+		File:    absFile,
+		Func:    funcName,
+		Depth:   depth,
+		Reverse: reverse,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("daemon calls query: %w", err)
+	}
+	if result.Tree == nil {
+		return nil, fmt.Errorf("daemon returned no call tree")
+	}
+	return result.Tree, nil
+}
+
+func runCallTreeLocally(absFile, funcName string, depth int, reverse bool) (*callgraph.CallTreeNode, error) {
+	// Resolve against the working directory when the file lives inside it,
+	// so cross-file edges cover the whole project
+	rootDir := filepath.Dir(absFile)
+	if cwd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(cwd, absFile); err == nil && !strings.HasPrefix(rel, "..") {
+			rootDir = cwd
 		}
 	}
+
+	ext, err := extractor.GetLanguageRegistry().GetExtractor(absFile)
+	if err != nil {
+		return nil, fmt.Errorf("getting extractor: %w", err)
+	}
+
+	graph, err := callgraph.BuildProjectCallGraph(rootDir, ext)
+	if err != nil {
+		return nil, fmt.Errorf("building call graph: %w", err)
+	}
+
+	relFile, err := filepath.Rel(rootDir, absFile)
+	if err != nil {
+		return nil, fmt.Errorf("getting relative path: %w", err)
+	}
+
+	return callgraph.BuildCallTree(stopSymbols().Filter(graph.Edges), relFile, funcName, depth, reverse), nil
+}
+
+// stopSymbols returns the configured callees left out of call graph output
+func stopSymbols() callgraph.StopSymbols {
+	if cfg, err := config.Load(); err == nil {
+		return cfg.CallGraph.StopSymbols
+	}
+	return nil
+}
+
+func printCallTree(tree *callgraph.CallTreeNode, reverse bool) {
+	direction := "Calls from"
+	if reverse {
+		direction = "Callers of"
+	}
+	fmt.Printf("=== %s %s:%s ===\n\n", direction, tree.File, tree.Func)
+
+	if len(tree.Children) == 0 {
+		fmt.Println("  (none)")
+		return
+	}
+
+	fmt.Printf("%s:%s\n", tree.File, tree.Func)
+	for i, child := range tree.Children {
+		printCallTreeNode(child, "", i == len(tree.Children)-1)
+	}
+}
+
+func printCallTreeNode(node *callgraph.CallTreeNode, prefix string, isLast bool) {
+	connector := "├── "
+	childPrefix := prefix + "│   "
+	if isLast {
+		connector = "└── "
+		childPrefix = prefix + "    "
+	}
+
+	suffix := ""
+	if node.Recursive {
+		suffix = " (recursive)"
+	}
+	fmt.Printf("%s%s%s:%s%s\n", prefix, connector, node.File, node.Func, suffix)
+
+	for i, child := range node.Children {
+		printCallTreeNode(child, childPrefix, i == len(node.Children)-1)
+	}
 }
 
 // getExtractorForLanguage returns an extractor for the specified language
@@ -225,4 +410,9 @@ func getExtractorForLanguage(lang string) extractor.Extractor {
 func init() {
 	callsCmd.Flags().BoolP("json", "j", false, "Output as JSON")
 	callsCmd.Flags().StringP("language", "l", "", "Language to analyze (python, go, php, etc.)")
+	callsCmd.Flags().IntP("depth", "d", 3, "Levels of the call tree to expand (with <file> <func>)")
+	callsCmd.Flags().BoolP("reverse", "r", false, "Show callers instead of callees (with <file> <func>)")
+	callsCmd.Flags().String("callee", "", "Only list calls to this function")
+	callsCmd.Flags().String("arg", "", "Only list calls with an argument containing this text")
+	callsCmd.Flags().Bool("literal", false, "Only list calls passing a literal (hardcoded) argument")
 }
@@ -16,7 +16,7 @@ var cfgCmd = &cobra.Command{
 	Use:   "cfg <file> <function>",
 	Short: "Extract control flow graph for a function",
 	Long: `Extracts the Control Flow Graph (CFG) for a specific function.
-Supports Python, Go, TypeScript, Rust, Java, C, C++, Ruby, and PHP.
+Supports Python, Go, TypeScript, Rust, Java, C, C++, Ruby, PHP, and Kotlin.
 Outputs JSON with blocks, edges, and cyclomatic complexity.`,
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -38,6 +38,8 @@ var contextCmd = &cobra.Command{
 	Long:  `Analyzes an entry point file and gathers its dependencies, imports, and call graph to provide a comprehensive context for LLM processing.`,
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		installLanguagePacks()
+
 		entryPath := args[0]
 
 		// Get path flag
@@ -320,6 +322,20 @@ func printContext(output ContextOutput) {
 			}
 			fmt.Println(":")
 
+			for _, field := range cls.Fields {
+				decl := field.Name + " " + field.Type
+				if field.Embedded {
+					decl = field.Type
+				}
+				if field.Tag != "" {
+					decl += " `" + field.Tag + "`"
+				}
+				if field.Doc != "" {
+					decl += " // " + field.Doc
+				}
+				fmt.Printf("    %s\n", decl)
+			}
+
 			for _, method := range cls.Methods {
 				asyncPrefix := ""
 				if method.IsAsync {
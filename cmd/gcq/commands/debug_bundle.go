@@ -0,0 +1,164 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/l3aro/go-context-query/internal/bundle"
+	"github.com/l3aro/go-context-query/internal/config"
+	"github.com/l3aro/go-context-query/internal/daemon"
+	"github.com/l3aro/go-context-query/pkg/semantic"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var debugBundleCmd = &cobra.Command{
+	Use:   "debug-bundle",
+	Short: "Collect diagnostics into a tarball for bug reports",
+	Long: `Collects version info, the project configuration, daemon status, semantic
+index metadata, and recent daemon logs into a .tar.gz you can attach to a
+bug report.
+
+Tokens and API keys are redacted, and paths under your home directory are
+shown relative to "~". Source code is not included. Review the bundle
+before sharing it.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		logLines, _ := cmd.Flags().GetInt("log-lines")
+		if output == "" {
+			output = fmt.Sprintf("gcq-debug-%s.tar.gz", time.Now().Format("20060102-150405"))
+		}
+		return runDebugBundle(output, logLines)
+	},
+}
+
+func init() {
+	debugBundleCmd.Flags().StringP("output", "o", "", "Output file (default: gcq-debug-<timestamp>.tar.gz)")
+	debugBundleCmd.Flags().Int("log-lines", 500, "Number of recent daemon log lines to include")
+}
+
+func runDebugBundle(output string, logLines int) error {
+	// Known secrets are redacted verbatim wherever they appear, including
+	// in logs, on top of the pattern-based redaction.
+	cfg, cfgErr := config.Load()
+	var secrets []string
+	if cfgErr == nil {
+		secrets = cfg.Secrets()
+	}
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if isSecretEnv(name) {
+			secrets = append(secrets, value)
+		}
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("creating bundle: %w", err)
+	}
+	defer file.Close()
+
+	prefix := strings.TrimSuffix(filepath.Base(output), ".tar.gz")
+	w := bundle.NewWriter(file, prefix, bundle.NewRedactor(secrets...))
+
+	var notes []string
+	add := func(name string, data []byte, err error) {
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("%s: %v", name, err))
+			return
+		}
+		if err := w.Add(name, data); err != nil {
+			notes = append(notes, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+
+	add("version.txt", []byte(debugVersionInfo()), nil)
+	add("env.txt", []byte(debugEnv()), nil)
+
+	if cfgErr == nil {
+		data, err := yaml.Marshal(cfg.Redacted())
+		add("config.yaml", data, err)
+	} else {
+		// Include the raw file so configs that fail to load can still be
+		// inspected; pattern redaction covers its token fields.
+		notes = append(notes, fmt.Sprintf("config: %v", cfgErr))
+		data, err := os.ReadFile(filepath.Join(".gcq", "config.yaml"))
+		add("config.raw.yaml", data, err)
+	}
+
+	status, _ := daemon.GetStatus()
+	data, err := json.MarshalIndent(status, "", "  ")
+	add("daemon/status.json", data, err)
+
+	instances, err := daemon.ListInstances()
+	if err == nil {
+		data, err = json.MarshalIndent(instances, "", "  ")
+	}
+	add("daemon/instances.json", data, err)
+
+	data, err = os.ReadFile(filepath.Join(semantic.ActiveIndexDir("."), "metadata.json"))
+	add("index/metadata.json", data, err)
+
+	var logs bytes.Buffer
+	_, err = daemon.TailLog(&logs, daemon.LogFile(), logLines)
+	add("logs/daemon.log", logs.Bytes(), err)
+
+	if len(notes) > 0 {
+		add("NOTES.txt", []byte("Items that could not be collected:\n"+strings.Join(notes, "\n")+"\n"), nil)
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %s\n", output)
+	fmt.Println("Secrets and home paths are redacted; review the bundle before attaching it to a report.")
+	return nil
+}
+
+// debugVersionInfo describes the gcq build and platform
+func debugVersionInfo() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "gcq version: %s\n", RootCmd.Version)
+	fmt.Fprintf(&b, "go version: %s\n", runtime.Version())
+	fmt.Fprintf(&b, "platform: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "generated: %s\n", time.Now().Format(time.RFC3339))
+	if wd, err := os.Getwd(); err == nil {
+		fmt.Fprintf(&b, "working directory: %s\n", wd)
+	}
+	return b.String()
+}
+
+// debugEnv lists the GCQ_ environment variables, hiding secret values
+func debugEnv() string {
+	var b strings.Builder
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, "GCQ_") {
+			continue
+		}
+		if isSecretEnv(name) && value != "" {
+			value = bundle.Redacted
+		}
+		fmt.Fprintf(&b, "%s=%s\n", name, value)
+	}
+	return b.String()
+}
+
+// isSecretEnv reports whether an environment variable holds a credential
+func isSecretEnv(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, marker := range []string{"TOKEN", "API_KEY", "APIKEY", "SECRET", "PASSWORD"} {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
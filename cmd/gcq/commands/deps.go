@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/l3aro/go-context-query/internal/config"
+	"github.com/l3aro/go-context-query/internal/scanner"
+	"github.com/l3aro/go-context-query/pkg/deps"
+	"github.com/l3aro/go-context-query/pkg/semantic"
+	"github.com/spf13/cobra"
+)
+
+// depsCmd groups the dependency commands
+var depsCmd = &cobra.Command{
+	Use:   "deps",
+	Short: "Inspect the project's third-party dependencies",
+}
+
+// depsReportCmd represents the deps report command
+var depsReportCmd = &cobra.Command{
+	Use:   "report [path]",
+	Short: "List third-party dependencies with usage counts and locations",
+	Long: `Aggregates the dependencies each code unit imports into a project-level
+list. Imports are classified with the nearest go.mod, package.json,
+pyproject.toml or requirements.txt, so standard library and project-internal
+imports are left out and each dependency carries its declared version.
+Packages listed under index.dependencies.ignore in the config are left out
+too.
+
+For every dependency the report shows how many units and files use it and
+where. Use --json for gcq's own JSON, or --cyclonedx to export a CycloneDX
+1.5 SBOM with the usage counts as properties and the locations as evidence.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		installLanguagePacks()
+
+		path := "."
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("getting absolute path: %w", err)
+		}
+		info, err := os.Stat(absPath)
+		if err != nil {
+			return fmt.Errorf("stat path: %w", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("path is not a directory: %s", path)
+		}
+
+		sc := scanner.New(scanner.DefaultOptions())
+		files, err := sc.Scan(absPath)
+		if err != nil {
+			return fmt.Errorf("scanning directory: %w", err)
+		}
+		// The report works without a config; one adds its ignore lists
+		var filter semantic.DependencyFilter
+		if cfg, err := config.Load(); err == nil {
+			filter = dependencyFilter(cfg)
+		}
+		report := deps.BuildReport(absPath, semantic.DependencyUnits(absPath, files, filter))
+
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		cyclonedx, _ := cmd.Flags().GetBool("cyclonedx")
+		output, _ := cmd.Flags().GetString("output")
+
+		var data []byte
+		switch {
+		case cyclonedx:
+			bom := report.CycloneDX(filepath.Base(absPath), RootCmd.Version, time.Now())
+			data, err = json.MarshalIndent(bom, "", "  ")
+		case jsonOutput:
+			data, err = json.MarshalIndent(report, "", "  ")
+		default:
+			if output != "" {
+				return fmt.Errorf("--output requires --json or --cyclonedx")
+			}
+			limit, _ := cmd.Flags().GetInt("locations")
+			return outputDepsText(report, limit)
+		}
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+
+		if output == "" {
+			fmt.Println(string(data))
+			return nil
+		}
+		if err := os.WriteFile(output, append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf("writing report: %w", err)
+		}
+		fmt.Printf("Wrote %d dependencies to %s\n", len(report.Dependencies), output)
+		return nil
+	},
+}
+
+func init() {
+	depsReportCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	depsReportCmd.Flags().Bool("cyclonedx", false, "Output as a CycloneDX 1.5 JSON SBOM")
+	depsReportCmd.Flags().StringP("output", "o", "", "Write the JSON or CycloneDX output to a file")
+	depsReportCmd.Flags().Int("locations", 3, "Locations shown per dependency in text output (0 = all)")
+	depsCmd.AddCommand(depsReportCmd)
+}
+
+func outputDepsText(report *deps.Report, limit int) error {
+	if len(report.Dependencies) == 0 {
+		fmt.Println("No third-party dependencies found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DEPENDENCY\tVERSION\tECOSYSTEM\tUNITS\tFILES\tLOCATIONS")
+	for _, usage := range report.Dependencies {
+		version := usage.Version
+		if version == "" {
+			version = "-"
+		}
+		shown := usage.Locations
+		more := ""
+		if limit > 0 && len(shown) > limit {
+			more = fmt.Sprintf(" (+%d more)", len(shown)-limit)
+			shown = shown[:limit]
+		}
+		locations := make([]string, len(shown))
+		for i, loc := range shown {
+			locations[i] = loc.String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s%s\n",
+			usage.Name, version, usage.Ecosystem, usage.Units, usage.Files, strings.Join(locations, ", "), more)
+	}
+	return w.Flush()
+}
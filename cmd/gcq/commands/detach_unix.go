@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package commands
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach makes cmd run in its own session, so it outlives gcq and the
+// terminal that started it
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,22 @@
+//go:build windows
+// +build windows
+
+package commands
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detachedProcess starts a process without a console (DETACHED_PROCESS is
+// not exported by syscall)
+const detachedProcess = 0x00000008
+
+// detach makes cmd run without gcq's console, so it outlives gcq and Ctrl+C
+// in that console doesn't reach it
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess,
+		HideWindow:    true,
+	}
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"fmt"
 	"os"
+	"sort"
 
 	"github.com/l3aro/go-context-query/internal/config"
 	"github.com/l3aro/go-context-query/internal/healthcheck"
@@ -13,7 +14,8 @@ var doctorCmd = &cobra.Command{
 	Use:   "doctor",
 	Short: "Run health checks on configuration and models",
 	Long: `Checks the configuration and verifies that embedding models
-are accessible and working properly.`,
+are accessible and working properly, and reports which local runtime and
+hardware acceleration (Metal, CUDA or CPU) embeddings use.`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cfg, configPath, err := loadConfigWithPath()
@@ -88,12 +90,41 @@ func displayDoctorResult(result *healthcheck.HealthCheckResult) {
 	} else {
 		printModelStatus(result.SearchModel.Status, result.SearchModel.Error)
 	}
+
+	displayAcceleration(result.Acceleration)
+}
+
+func displayAcceleration(accel healthcheck.AccelerationStatus) {
+	fmt.Println("\nAcceleration:")
+	fmt.Printf("  Platform: %s\n", accel.Platform)
+	if accel.Runtime == "" {
+		fmt.Printf("  Local runtime: none (%s)\n", accel.Detail)
+		return
+	}
+	fmt.Printf("  Local runtime: %s (%s)\n", accel.Runtime, accel.RuntimePath)
+	fmt.Printf("  Backend: %s\n", accel.Backend)
+	if accel.Detail != "" {
+		fmt.Printf("  Detail: %s\n", accel.Detail)
+	}
+
+	if len(accel.Processors) == 0 {
+		fmt.Println("  In use: no model loaded (run a search or gcq warm, then re-run gcq doctor)")
+		return
+	}
+	models := make([]string, 0, len(accel.Processors))
+	for model := range accel.Processors {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	for _, model := range models {
+		fmt.Printf("  In use: %s on %s\n", model, accel.Processors[model])
+	}
 }
 
 func printModelStatus(status string, errMsg string) {
 	icon := formatStatusIcon(status)
 	fmt.Printf("  Status: %s %s\n", icon, status)
-	if errMsg != "" && status == "error" {
+	if errMsg != "" && (status == "error" || status == "fallback") {
 		fmt.Printf("  Error: %s\n", errMsg)
 	}
 }
@@ -102,7 +133,7 @@ func formatStatusIcon(status string) string {
 	switch status {
 	case "ready":
 		return "✓"
-	case "downloading":
+	case "downloading", "fallback":
 		return "◐"
 	case "inherited":
 		return "✓"
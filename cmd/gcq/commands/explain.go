@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/l3aro/go-context-query/internal/config"
+	"github.com/l3aro/go-context-query/internal/scanner"
+	"github.com/l3aro/go-context-query/pkg/extractor"
+	"github.com/l3aro/go-context-query/pkg/semantic"
+	"github.com/l3aro/go-context-query/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// ExplainStep is one check applied to the file, in the order gcq warm applies them
+type ExplainStep struct {
+	Step   string `json:"step"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail"`
+}
+
+// ExplainOutput represents the output of the explain command
+type ExplainOutput struct {
+	File     string        `json:"file"`
+	RootDir  string        `json:"root_dir"`
+	Indexed  bool          `json:"indexed"`
+	Verdict  string        `json:"verdict"`
+	Steps    []ExplainStep `json:"steps"`
+	Units    int           `json:"units"`
+	InIndex  int           `json:"in_index"`
+	Language string        `json:"language,omitempty"`
+}
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <file>",
+	Short: "Explain why a file is or isn't in the semantic index",
+	Long: `Walks one file through the rules gcq warm applies: scanner rules (hidden
+and default-excluded directories, .gcqignore and .gitignore patterns,
+symlinks), language support, size, and the extraction result, then checks
+the active semantic index for the file's units. Prints the first rule that
+keeps the file out of the index, or confirms that it is indexed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		installLanguagePacks()
+
+		rootFlag, _ := cmd.Flags().GetString("root")
+		rootDir, err := filepath.Abs(rootFlag)
+		if err != nil {
+			return fmt.Errorf("getting absolute path: %w", err)
+		}
+
+		output, err := explainFile(rootDir, args[0])
+		if err != nil {
+			return err
+		}
+
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		if jsonOutput {
+			data, err := json.MarshalIndent(output, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshaling JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		printExplain(output)
+		return nil
+	},
+}
+
+func init() {
+	explainCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	explainCmd.Flags().String("root", ".", "Project root the index was built for")
+}
+
+// explainFile runs the indexing checks for path under rootDir, stopping at
+// the first one that excludes the file
+func explainFile(rootDir, path string) (*ExplainOutput, error) {
+	e, err := scanner.New(scanner.DefaultOptions()).Explain(rootDir, path)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &ExplainOutput{File: e.Path, RootDir: rootDir, Language: e.Language}
+	step := func(name string, passed bool, detail string) bool {
+		output.Steps = append(output.Steps, ExplainStep{Step: name, Passed: passed, Detail: detail})
+		if !passed && output.Verdict == "" {
+			output.Verdict = fmt.Sprintf("not indexed: %s", detail)
+		}
+		return passed
+	}
+
+	if !step("scanner", e.Included, e.Reason) {
+		return output, nil
+	}
+
+	if e.Language == "" {
+		step("language", false, fmt.Sprintf("extension %q is not recognised", filepath.Ext(e.Path)))
+		return output, nil
+	}
+	ext, err := extractor.GetLanguageRegistry().GetExtractor(e.FullPath)
+	if err != nil {
+		step("language", false, fmt.Sprintf("%s has no code extractor, so no units are indexed", e.Language))
+		return output, nil
+	}
+	step("language", true, fmt.Sprintf("%s is supported", e.Language))
+
+	sizeDetail := fmt.Sprintf("%d bytes; gcq warm has no size limit", e.Size)
+	if cfg, err := config.Load(); err == nil && cfg.TextSearch.MaxFileSize > 0 && e.Size > cfg.TextSearch.MaxFileSize {
+		sizeDetail += fmt.Sprintf(" (gcq search skips it: text_search.max_file_size is %d)", cfg.TextSearch.MaxFileSize)
+	}
+	step("size", true, sizeDetail)
+
+	moduleInfo, err := ext.Extract(e.FullPath)
+	if err != nil {
+		step("extract", false, fmt.Sprintf("extraction failed: %v", err))
+		return output, nil
+	}
+	output.Units = countModuleUnits(moduleInfo)
+	if output.Units == 0 {
+		step("extract", false, "no functions, classes or methods were extracted")
+		return output, nil
+	}
+	step("extract", true, fmt.Sprintf("%d functions, classes and methods extracted", output.Units))
+
+	if types.IsTestFile(e.Path) {
+		step("tests", true, "test file: indexed, but left out of search results unless --include-tests is given")
+	}
+
+	vecIndex, metadata, err := semantic.LoadIndex(rootDir)
+	if err != nil {
+		step("index", false, "no semantic index found; run 'gcq warm'")
+		return output, nil
+	}
+	vecIndex.IterVectors(func(id string, vector []float32, unit types.EmbeddingUnit) bool {
+		if unit.Unit != nil && filepath.ToSlash(unit.Unit.FilePath) == e.Path {
+			output.InIndex++
+		}
+		return true
+	})
+	if output.InIndex == 0 {
+		step("index", false, fmt.Sprintf("the index built %s has no units from this file; run 'gcq warm' to add it",
+			metadata.Timestamp.Format("2006-01-02 15:04")))
+		return output, nil
+	}
+
+	detail := fmt.Sprintf("%d units in the index built %s", output.InIndex, metadata.Timestamp.Format("2006-01-02 15:04"))
+	if info, err := os.Stat(e.FullPath); err == nil && info.ModTime().After(metadata.Timestamp) {
+		detail += "; the file changed since, run 'gcq warm' to refresh it"
+	}
+	step("index", true, detail)
+
+	output.Indexed = true
+	output.Verdict = "indexed"
+	return output, nil
+}
+
+// countModuleUnits counts the units the semantic builder creates from a file
+func countModuleUnits(moduleInfo *types.ModuleInfo) int {
+	count := len(moduleInfo.Functions)
+	for _, cls := range moduleInfo.Classes {
+		count += 1 + len(cls.Methods)
+	}
+	for _, iface := range moduleInfo.Interfaces {
+		count += 1 + len(iface.Methods)
+	}
+	for _, trait := range moduleInfo.Traits {
+		count += 1 + len(trait.Methods)
+	}
+	return count
+}
+
+func printExplain(output *ExplainOutput) {
+	fmt.Printf("=== Explain: %s ===\n\n", output.File)
+	for _, s := range output.Steps {
+		mark := "ok  "
+		if !s.Passed {
+			mark = "FAIL"
+		}
+		fmt.Printf("[%s] %-8s %s\n", mark, s.Step, s.Detail)
+	}
+	fmt.Printf("\nVerdict: %s\n", output.Verdict)
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/l3aro/go-context-query/pkg/exporter"
+	"github.com/spf13/cobra"
+)
+
+// exportCmd groups the export commands
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the semantic index for other code intelligence tools",
+}
+
+// exportSCIPCmd represents the export scip command
+var exportSCIPCmd = &cobra.Command{
+	Use:   "scip [path]",
+	Short: "Write the indexed symbols and call graph as a SCIP index",
+	Long: `Converts the units and call graph saved by 'gcq warm' into a SCIP index
+(https://github.com/sourcegraph/scip), so tools that read SCIP, such as
+Sourcegraph's code navigation or scip print, can reuse gcq's extraction.
+
+Every function, method, class, interface and trait becomes a symbol with its
+definition range, signature and docs. Call graph edges become references at
+the call sites in the caller's source, so "find references" in those tools
+lists the callers gcq resolved. Symbols use the scheme "gcq" and the project
+directory as their package.
+
+Run 'gcq warm' first to build the index.
+
+Examples:
+  gcq export scip
+  gcq export scip -o /tmp/project.scip ./your-project`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rootDir, units, err := analysisUnits(args)
+		if err != nil {
+			return err
+		}
+		output, _ := cmd.Flags().GetString("output")
+
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("creating %s: %w", output, err)
+		}
+		w := bufio.NewWriter(f)
+		stats, err := exporter.WriteSCIP(w, rootDir, units, RootCmd.Version)
+		if err == nil {
+			err = w.Flush()
+		}
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", output, err)
+		}
+
+		fmt.Printf("Wrote %d symbols and %d references in %d documents to %s\n", stats.Symbols, stats.References, stats.Documents, output)
+		return nil
+	},
+}
+
+func init() {
+	exportSCIPCmd.Flags().StringP("output", "o", "index.scip", "File to write the SCIP index to")
+	exportCmd.AddCommand(exportSCIPCmd)
+}
@@ -17,6 +17,8 @@ var extractCmd = &cobra.Command{
 	Long:  `Extracts complete module information including functions, classes, imports, and call graph from a single file.`,
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		installLanguagePacks()
+
 		filePath := args[0]
 
 		// Check if file exists
@@ -0,0 +1,228 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/l3aro/go-context-query/internal/config"
+	"github.com/l3aro/go-context-query/pkg/feedback"
+	"github.com/l3aro/go-context-query/pkg/search"
+	"github.com/spf13/cobra"
+)
+
+// feedbackCmd groups the commands that record and replay judgments of
+// search results
+var feedbackCmd = &cobra.Command{
+	Use:   "feedback",
+	Short: "Mark search results as helpful or unhelpful and measure ranking against them",
+	Long: `Records whether a search result helped for a query, so relevance tuning
+has real data to work from. Judgments are appended to .gcq/feedback.jsonl
+in the project; marking the same result for the same query again replaces
+the earlier verdict.
+
+Results are addressed by the unit URI that gcq semantic prints (and
+returns as "uri" with --json).
+
+gcq feedback eval replays every judged query and reports how the helpful
+units rank. Setting search.feedback_boost in config also scores judged
+units up or down in semantic search.
+
+Examples:
+  gcq feedback helpful "load config" go://internal/config#Load
+  gcq feedback unhelpful "load config" go://internal/config#loadTestConfig
+  gcq feedback list
+  gcq feedback eval -k 5`,
+}
+
+// newFeedbackMarkCmd returns the command that records results as helpful
+// or not
+func newFeedbackMarkCmd(verdict string, helpful bool) *cobra.Command {
+	return &cobra.Command{
+		Use:   verdict + " <query> <uri>...",
+		Short: "Mark search results as " + verdict + " for a query",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootDir, err := semanticRootDir(cmd)
+			if err != nil {
+				return err
+			}
+			var judgments []feedback.Judgment
+			for _, uri := range args[1:] {
+				judgments = append(judgments, feedback.Judgment{Query: args[0], URI: uri, Helpful: helpful})
+			}
+			if err := feedback.Record(rootDir, judgments...); err != nil {
+				return err
+			}
+			noun := "results"
+			if len(judgments) == 1 {
+				noun = "result"
+			}
+			fmt.Printf("Marked %d %s as %s for %q\n", len(judgments), noun, verdict, args[0])
+			return nil
+		},
+	}
+}
+
+// feedbackListCmd represents the feedback list command
+var feedbackListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the latest judgment of each query and result",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rootDir, err := semanticRootDir(cmd)
+		if err != nil {
+			return err
+		}
+		judgments, err := feedback.Load(rootDir)
+		if err != nil {
+			return err
+		}
+		judgments = feedback.Latest(judgments)
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			if judgments == nil {
+				judgments = []feedback.Judgment{}
+			}
+			data, err := json.MarshalIndent(judgments, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshaling JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if len(judgments) == 0 {
+			fmt.Println("No feedback recorded.")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "QUERY\tVERDICT\tURI\tWHEN")
+		for _, j := range judgments {
+			verdict := "unhelpful"
+			if j.Helpful {
+				verdict = "helpful"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", j.Query, verdict, j.URI, j.Time.Local().Format("2006-01-02 15:04"))
+		}
+		return w.Flush()
+	},
+}
+
+// feedbackEvalCmd represents the feedback eval command
+var feedbackEvalCmd = &cobra.Command{
+	Use:   "eval",
+	Short: "Replay judged queries and report how helpful results rank",
+	Long: `Runs every judged query against the local semantic index and reports the
+mean reciprocal rank of the first helpful result, the share of helpful
+results found in the top k, and the share of top k slots taken by
+results marked unhelpful. Compare runs across models, config changes or
+index rebuilds to see whether relevance improved.
+
+Rankings ignore search.feedback_boost unless --boost is given, since
+boosting by the judgments being replayed flatters the scores.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rootDir, err := semanticRootDir(cmd)
+		if err != nil {
+			return err
+		}
+		judgments, err := feedback.Load(rootDir)
+		if err != nil {
+			return err
+		}
+		cases := feedback.Cases(judgments)
+		if len(cases) == 0 {
+			return fmt.Errorf("no feedback recorded in %s; mark results with 'gcq feedback helpful' first", feedback.Path(rootDir))
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		searcher, _, err := openSearcher(cmd, cfg, rootDir)
+		if err != nil {
+			return err
+		}
+
+		k, _ := cmd.Flags().GetInt("k")
+		if k <= 0 {
+			return fmt.Errorf("k must be positive, got %d", k)
+		}
+		boost, _ := cmd.Flags().GetFloat64("boost")
+		if boost < 0 || boost > 1 {
+			return fmt.Errorf("boost must be between 0 and 1, got %g", boost)
+		}
+		opts := search.SearchOptions{Boosts: feedback.Boosts(judgments, boost)}
+		report, err := feedback.Evaluate(cases, k, func(query string, k int) ([]string, error) {
+			results, err := searcher.SearchWithOptions(context.Background(), query, k, opts)
+			if err != nil {
+				return nil, fmt.Errorf("searching %q: %w", query, err)
+			}
+			uris := make([]string, len(results))
+			for i, r := range results {
+				uris[i] = r.URI
+			}
+			return uris, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshaling JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "QUERY\tRANK\tHELPFUL FOUND\tUNHELPFUL")
+		for _, c := range report.Cases {
+			rank := "-"
+			if c.Rank > 0 {
+				rank = fmt.Sprint(c.Rank)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d/%d\t%d\n", c.Query, rank, c.Found, c.Helpful, c.Unhelpful)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Printf("\n%d queries, k=%d: MRR %.3f, recall %.3f, unhelpful rate %.3f\n", report.Queries, report.K, report.MRR, report.Recall, report.UnhelpfulRate)
+		return nil
+	},
+}
+
+// feedbackBoosts returns the score multipliers of judged units of the
+// project at rootDir, or nil when weight is zero or nothing was judged
+func feedbackBoosts(rootDir string, weight float64) (map[string]float32, error) {
+	if weight <= 0 {
+		return nil, nil
+	}
+	judgments, err := feedback.Load(rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("loading feedback: %w", err)
+	}
+	return feedback.Boosts(judgments, weight), nil
+}
+
+func init() {
+	for _, c := range []*cobra.Command{newFeedbackMarkCmd("helpful", true), newFeedbackMarkCmd("unhelpful", false)} {
+		c.Flags().String("path", "", "Project path (defaults to current directory)")
+		feedbackCmd.AddCommand(c)
+	}
+
+	feedbackListCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	feedbackListCmd.Flags().String("path", "", "Project path (defaults to current directory)")
+	feedbackCmd.AddCommand(feedbackListCmd)
+
+	feedbackEvalCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	feedbackEvalCmd.Flags().IntP("k", "k", 10, "Number of results to score per query")
+	feedbackEvalCmd.Flags().Float64("boost", 0, "Rank with feedback boosts of this weight (0 to 1)")
+	feedbackEvalCmd.Flags().String("path", "", "Project path (defaults to current directory)")
+	feedbackCmd.AddCommand(feedbackEvalCmd)
+}
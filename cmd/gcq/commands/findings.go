@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/l3aro/go-context-query/pkg/findings"
+	"github.com/l3aro/go-context-query/pkg/semantic"
+	"github.com/spf13/cobra"
+)
+
+// addFindingsFlags adds the output flags shared by analysis commands
+func addFindingsFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("format", "f", "text", "Output format: "+strings.Join(findings.Formats, ", "))
+	cmd.Flags().StringP("output", "o", "", "File for json and sarif output, URL for webhook output")
+	cmd.Flags().BoolP("json", "j", false, "Output as JSON (same as --format json)")
+}
+
+// writeFindings sorts report and writes it to the sink selected by the
+// command's output flags
+func writeFindings(cmd *cobra.Command, report *findings.Report) error {
+	format, _ := cmd.Flags().GetString("format")
+	if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+		format = "json"
+	}
+	output, _ := cmd.Flags().GetString("output")
+
+	sink, err := findings.NewSink(format, output, RootCmd.Version)
+	if err != nil {
+		return err
+	}
+
+	report.Sort()
+	if report.Findings == nil {
+		report.Findings = []findings.Finding{}
+	}
+	if err := sink.Write(report); err != nil {
+		return fmt.Errorf("writing %s findings: %w", report.Analysis, err)
+	}
+	if output != "" {
+		fmt.Fprintf(os.Stderr, "Wrote %d %s findings to %s\n", len(report.Findings), report.Analysis, output)
+	}
+	return nil
+}
+
+// analysisUnits returns the root and indexed units of the project at the
+// command's path argument. Analyses read units from the semantic index,
+// which carries call graph and CFG data.
+func analysisUnits(args []string) (string, []*semantic.CodeUnit, error) {
+	path := "."
+	if len(args) > 0 {
+		path = args[0]
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("getting absolute path: %w", err)
+	}
+
+	units, err := semantic.LoadUnits(absPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("loading semantic index: %w\nRun 'gcq warm' first to build the index", err)
+	}
+	return absPath, units, nil
+}
@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/l3aro/go-context-query/pkg/hierarchy"
+	"github.com/spf13/cobra"
+)
+
+// HierarchyOutput is the JSON output of the hierarchy command for one type
+type HierarchyOutput struct {
+	hierarchy.Type
+	Ancestors   []hierarchy.Relative `json:"ancestors"`
+	Descendants []hierarchy.Relative `json:"descendants"`
+	MRO         []string             `json:"mro,omitempty"`
+	// MROError explains why a Python class has no consistent order
+	MROError string `json:"mro_error,omitempty"`
+	// Method is the type the --method flag resolved to
+	Method *hierarchy.Type `json:"method,omitempty"`
+}
+
+// hierarchyCmd represents the hierarchy command
+var hierarchyCmd = &cobra.Command{
+	Use:   "hierarchy <type>",
+	Short: "Show the ancestors, descendants and method resolution order of a type",
+	Long: `Builds the project's inheritance graph and shows where <type> sits in it:
+the classes and interfaces it extends, implements or embeds, the types
+that inherit from it, and its method resolution order. Python bases,
+TypeScript extends and implements clauses and Go struct and interface
+embedding are all followed; bases defined outside the project, such as
+Exception, are shown as external.
+
+The method resolution order is Python's C3 linearization for Python,
+breadth first for Go, where shallower embedded methods win, and depth
+first elsewhere. --method names the type whose method a call on <type>
+resolves to.
+
+Types come from extraction only, so no embeddings or index are needed.
+
+Examples:
+  gcq hierarchy Animal
+  gcq hierarchy Dog --method speak
+  gcq hierarchy Store --path ./internal --up --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rootDir, err := semanticRootDir(cmd)
+		if err != nil {
+			return err
+		}
+		up, _ := cmd.Flags().GetBool("up")
+		down, _ := cmd.Flags().GetBool("down")
+		method, _ := cmd.Flags().GetString("method")
+		if !up && !down {
+			up, down = true, true
+		}
+
+		g, err := hierarchy.Build(rootDir)
+		if err != nil {
+			return err
+		}
+		found := g.Find(args[0])
+		if len(found) == 0 {
+			return fmt.Errorf("no class, interface or struct named %q in %s", args[0], rootDir)
+		}
+
+		var outputs []HierarchyOutput
+		for _, t := range found {
+			output := HierarchyOutput{Type: *t}
+			if up {
+				output.Ancestors = g.Ancestors(t)
+				order, err := g.MRO(t)
+				if err != nil {
+					output.MROError = err.Error()
+				}
+				for _, n := range order {
+					output.MRO = append(output.MRO, n.Name)
+				}
+			}
+			if down {
+				output.Descendants = g.Descendants(t)
+			}
+			if method != "" {
+				output.Method, err = g.Resolve(t, method)
+				if err != nil {
+					output.MROError = err.Error()
+				}
+			}
+			outputs = append(outputs, output)
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			data, err := json.MarshalIndent(outputs, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshaling JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		for i, output := range outputs {
+			if i > 0 {
+				fmt.Println()
+			}
+			printHierarchy(output, up, down, method)
+		}
+		return nil
+	},
+}
+
+// hierarchyLocation describes where a type is defined
+func hierarchyLocation(t hierarchy.Type) string {
+	if t.Kind == hierarchy.KindExternal {
+		return t.Kind
+	}
+	return fmt.Sprintf("%s  %s:%d", t.Kind, t.File, t.Line)
+}
+
+func printHierarchy(output HierarchyOutput, up, down bool, method string) {
+	fmt.Printf("%s  %s\n", output.Name, hierarchyLocation(output.Type))
+
+	printRelatives := func(title string, relatives []hierarchy.Relative) {
+		fmt.Printf("\n%s:\n", title)
+		if len(relatives) == 0 {
+			fmt.Println("  (none)")
+			return
+		}
+		for _, r := range relatives {
+			fmt.Printf("%s%s  %s\n", strings.Repeat("  ", r.Depth), r.Name, hierarchyLocation(r.Type))
+		}
+	}
+	if up {
+		printRelatives("Ancestors", output.Ancestors)
+	}
+	if down {
+		printRelatives("Descendants", output.Descendants)
+	}
+	if up {
+		fmt.Println("\nMethod resolution order:")
+		if output.MROError != "" {
+			fmt.Printf("  %s\n", output.MROError)
+		} else {
+			fmt.Printf("  %s\n", strings.Join(output.MRO, " -> "))
+		}
+	}
+	if method != "" {
+		if output.Method == nil {
+			fmt.Printf("\n%s.%s is not defined in the project\n", output.Name, method)
+		} else {
+			fmt.Printf("\n%s.%s resolves to %s.%s  %s:%d\n", output.Name, method, output.Method.Name, method, output.Method.File, output.Method.Line)
+		}
+	}
+}
+
+func init() {
+	hierarchyCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	hierarchyCmd.Flags().Bool("up", false, "Show only ancestors and the method resolution order")
+	hierarchyCmd.Flags().Bool("down", false, "Show only descendants")
+	hierarchyCmd.Flags().StringP("method", "m", "", "Show which type a call of this method resolves to")
+	hierarchyCmd.Flags().String("path", "", "Project path to analyze (defaults to current directory)")
+}
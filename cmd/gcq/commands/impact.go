@@ -38,6 +38,8 @@ var impactCmd = &cobra.Command{
 This helps understand the impact of changing a function.`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		installLanguagePacks()
+
 		funcName := args[0]
 
 		// Check if daemon is available and use it
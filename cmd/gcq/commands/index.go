@@ -0,0 +1,382 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/l3aro/go-context-query/internal/daemon"
+	"github.com/l3aro/go-context-query/internal/scanner"
+	"github.com/l3aro/go-context-query/pkg/client"
+	"github.com/l3aro/go-context-query/pkg/index"
+	"github.com/l3aro/go-context-query/pkg/semantic"
+	"github.com/l3aro/go-context-query/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// IndexUnitRef identifies a unit stored in the semantic index
+type IndexUnitRef struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name,omitempty"`
+	Type  string  `json:"type,omitempty"`
+	File  string  `json:"file,omitempty"`
+	Line  int     `json:"line,omitempty"`
+	Score float32 `json:"score,omitempty"`
+}
+
+// IndexInspectOutput is the output of index inspect --id
+type IndexInspectOutput struct {
+	Root      string              `json:"root"`
+	Model     string              `json:"model"`
+	Unit      IndexUnitRef        `json:"unit"`
+	Stats     index.VectorStats   `json:"stats"`
+	Neighbors []IndexUnitRef      `json:"neighbors"`
+	Payload   types.EmbeddingUnit `json:"payload"`
+}
+
+// IndexSampleOutput is the output of index inspect --sample
+type IndexSampleOutput struct {
+	Root  string         `json:"root"`
+	Model string         `json:"model"`
+	Total int            `json:"total"`
+	Seed  int64          `json:"seed"`
+	Units []IndexUnitRef `json:"units"`
+}
+
+// indexCmd groups the commands that look inside and maintain the indexes
+var indexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Inspect the semantic index built by gcq warm and compact daemon indexes",
+}
+
+// indexInspectCmd represents the index inspect command
+var indexInspectCmd = &cobra.Command{
+	Use:   "inspect (--id <unit> | --sample N)",
+	Short: "Show a unit's stored vector, nearest neighbours and payload",
+	Long: `Debugs retrieval from the semantic index. With --id, shows statistics of
+the unit's stored vector (a zero norm, NaNs or a near-constant vector make
+a unit match everything), its nearest neighbours in the index with their
+cosine scores, and the payload stored with it. Neighbours are ranked by an
+exact scan, whatever search backend the index uses.
+
+--id takes the unit URI printed by gcq semantic --json, or a bare or
+qualified name such as parseConfig or Server.Run when it is unique.
+
+With --sample, lists randomly chosen units to inspect; the same --seed
+gives the same sample.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rootDir, err := semanticRootDir(cmd)
+		if err != nil {
+			return err
+		}
+		model, _ := cmd.Flags().GetString("model")
+		vecIndex, metadata, err := semantic.LoadIndexForModel(rootDir, model)
+		if err != nil {
+			return fmt.Errorf("loading index (run 'gcq warm' first): %w", err)
+		}
+
+		id, _ := cmd.Flags().GetString("id")
+		sample, _ := cmd.Flags().GetInt("sample")
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+
+		var output any
+		switch {
+		case id != "" && sample > 0:
+			return fmt.Errorf("--id and --sample are mutually exclusive")
+		case id != "":
+			k, _ := cmd.Flags().GetInt("k")
+			ids, err := semantic.LoadUnitIDMap(rootDir)
+			if err != nil {
+				return err
+			}
+			inspected, err := inspectUnit(vecIndex, ids, id, k)
+			if err != nil {
+				return err
+			}
+			inspected.Root, inspected.Model = rootDir, metadata.GetModel()
+			output = inspected
+		case sample > 0:
+			seed, _ := cmd.Flags().GetInt64("seed")
+			sampled := &IndexSampleOutput{Root: rootDir, Model: metadata.GetModel(), Total: vecIndex.Count(), Seed: seed}
+			for _, sid := range vecIndex.Sample(sample, seed) {
+				_, unit, _ := vecIndex.Get(sid)
+				sampled.Units = append(sampled.Units, indexUnitRef(sid, unit))
+			}
+			output = sampled
+		default:
+			return fmt.Errorf("either --id or --sample is required")
+		}
+
+		if jsonOutput {
+			data, err := json.MarshalIndent(output, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshaling JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		switch o := output.(type) {
+		case *IndexInspectOutput:
+			printIndexInspect(o)
+		case *IndexSampleOutput:
+			printIndexSample(o)
+		}
+		return nil
+	},
+}
+
+// indexIDsCmd represents the index ids command
+var indexIDsCmd = &cobra.Command{
+	Use:   "ids",
+	Short: "List units that moved or were renamed between builds",
+	Long: `Shows the unit ID map recorded by gcq warm when index.stable_ids is
+enabled. Each build compares its units with the index it replaces: a unit
+whose URI is gone but whose stable ID (type, signature and body) reappears
+under another URI moved, and one whose body reappears under another name
+was renamed.
+
+Commands that take a unit URI, such as gcq callers and gcq index inspect,
+follow the map, so URIs saved before a refactor still find the unit.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rootDir, err := semanticRootDir(cmd)
+		if err != nil {
+			return err
+		}
+		ids, err := semantic.LoadUnitIDMap(rootDir)
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			if ids.Changes == nil {
+				ids.Changes = []semantic.UnitIDChange{}
+			}
+			data, err := json.MarshalIndent(ids, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshaling JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if len(ids.Changes) == 0 {
+			fmt.Println("No moved or renamed units recorded (set index.stable_ids and run gcq warm)")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "OLD ID\tNEW ID\tKIND\tWHEN")
+		for _, c := range ids.Changes {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", c.OldID, c.NewID, c.Kind, c.Time.Format("2006-01-02 15:04"))
+		}
+		return w.Flush()
+	},
+}
+
+// indexCompactCmd represents the index compact command
+var indexCompactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Reclaim the space of removed entries in the daemon's indexes",
+	Long: `Files the daemon re-indexes, or drops when they are deleted, leave their
+old entries in its in-memory indexes as tombstones until the index is
+compacted. The daemon compacts an index on its own when it is saved and,
+every daemon.compact_interval while idle, once the removed entries pass
+daemon.compact_min_removed and daemon.compact_ratio. This command compacts
+now, whatever the thresholds.
+
+Compacts the project at --path (or the current directory), or every project
+open in the daemon with --all. Indexes on disk, including the semantic
+index written by gcq warm, are always saved without removed entries, so
+there is nothing to compact without a running daemon.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !daemon.IsRunning() {
+			return fmt.Errorf("daemon is not running; indexes on disk hold no removed entries")
+		}
+		project := ""
+		if all, _ := cmd.Flags().GetBool("all"); !all {
+			rootDir, err := semanticRootDir(cmd)
+			if err != nil {
+				return err
+			}
+			project = rootDir
+		}
+
+		compacted, err := client.New().CompactProjects(context.Background(), project)
+		if err != nil {
+			return fmt.Errorf("compacting: %w", err)
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			if compacted == nil {
+				compacted = []client.CompactResult{}
+			}
+			data, err := json.MarshalIndent(compacted, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshaling JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if len(compacted) == 0 {
+			fmt.Println("No projects open in the daemon")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PROJECT\tRECLAIMED\tLIVE")
+		for _, c := range compacted {
+			fmt.Fprintf(w, "%s\t%d\t%d\n", c.Root, c.Reclaimed, c.Count)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	indexIDsCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	indexIDsCmd.Flags().StringP("path", "", "", "Project path (defaults to current directory)")
+	indexCmd.AddCommand(indexIDsCmd)
+
+	indexInspectCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	indexInspectCmd.Flags().String("id", "", "Unit URI or name to inspect")
+	indexInspectCmd.Flags().IntP("k", "k", 10, "Number of nearest neighbours to show")
+	indexInspectCmd.Flags().Int("sample", 0, "List N randomly chosen units instead")
+	indexInspectCmd.Flags().Int64("seed", 1, "Random seed for --sample")
+	indexInspectCmd.Flags().StringP("path", "", "", "Project path (defaults to current directory)")
+	indexInspectCmd.Flags().StringP("model", "m", "", "Inspect the index kept for this embedding model (default: the active index)")
+	indexCmd.AddCommand(indexInspectCmd)
+
+	indexCompactCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	indexCompactCmd.Flags().Bool("all", false, "Compact every project open in the daemon")
+	indexCompactCmd.Flags().StringP("path", "", "", "Project path (defaults to current directory)")
+	indexCmd.AddCommand(indexCompactCmd)
+}
+
+// inspectUnit gathers the vector stats, neighbours and payload of the unit
+// addressed by ref
+func inspectUnit(vecIndex *index.VectorIndex, ids *semantic.UnitIDMap, ref string, k int) (*IndexInspectOutput, error) {
+	id, err := resolveIndexUnit(vecIndex, ids, ref)
+	if err != nil {
+		return nil, err
+	}
+	vector, unit, _ := vecIndex.Get(id)
+
+	neighbors, err := vecIndex.Neighbors(id, k)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &IndexInspectOutput{
+		Unit:      indexUnitRef(id, unit),
+		Stats:     index.ComputeVectorStats(vector),
+		Neighbors: make([]IndexUnitRef, len(neighbors)),
+		Payload:   unit,
+	}
+	for i, n := range neighbors {
+		output.Neighbors[i] = indexUnitRef(n.ID, n.Metadata)
+		output.Neighbors[i].Score = n.Score
+	}
+	return output, nil
+}
+
+// resolveIndexUnit finds the index ID for ref: an exact unit URI or a
+// file path and symbol such as "pkg/mod.py#func", the old URI of a unit
+// that moved or was renamed, a stable ID, or a name matching exactly one
+// unit's symbol, or else the end of exactly one unit's qualified symbol
+func resolveIndexUnit(vecIndex *index.VectorIndex, ids *semantic.UnitIDMap, ref string) (string, error) {
+	if _, _, ok := vecIndex.Get(ref); ok {
+		return ref, nil
+	}
+	if file, symbol, ok := strings.Cut(ref, "#"); ok && !types.IsUnitURI(ref) {
+		uri := types.NewUnitURI(scanner.DetectLanguage(filepath.Ext(file)), file, symbol).String()
+		if _, _, ok := vecIndex.Get(uri); ok {
+			return uri, nil
+		}
+	}
+	if current, ok := ids.Resolve(ref); ok {
+		if _, _, ok := vecIndex.Get(current); ok {
+			return current, nil
+		}
+	}
+
+	var exact, suffix []string
+	vecIndex.IterVectors(func(id string, _ []float32, unit types.EmbeddingUnit) bool {
+		symbol := id
+		if i := strings.LastIndex(id, "#"); i >= 0 {
+			symbol = id[i+1:]
+		}
+		switch {
+		case unit.Unit != nil && unit.Unit.StableID == ref:
+			exact = append(exact, id)
+		case symbol == ref:
+			exact = append(exact, id)
+		case strings.HasSuffix(symbol, "."+ref):
+			suffix = append(suffix, id)
+		}
+		return true
+	})
+
+	matches := exact
+	if len(matches) == 0 {
+		matches = suffix
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no unit in the index matches %q", ref)
+	case 1:
+		return matches[0], nil
+	}
+	total := len(matches)
+	sort.Strings(matches)
+	if total > 10 {
+		matches = append(matches[:10], "...")
+	}
+	return "", fmt.Errorf("%q matches %d units, use one of:\n  %s", ref, total, strings.Join(matches, "\n  "))
+}
+
+// indexUnitRef describes a stored unit, preferring the full code unit in
+// the payload over the module-level fields
+func indexUnitRef(id string, unit types.EmbeddingUnit) IndexUnitRef {
+	if u := unit.Unit; u != nil {
+		return IndexUnitRef{ID: id, Name: u.Name, Type: u.Type, File: u.FilePath, Line: u.LineNumber}
+	}
+	return IndexUnitRef{ID: id, Type: unit.L1Data.Type, File: unit.L1Data.Path, Line: unit.L1Data.LineNumber}
+}
+
+func printIndexInspect(o *IndexInspectOutput) {
+	fmt.Printf("=== Inspect: %s ===\n\n", o.Unit.ID)
+	fmt.Printf("%s %s at %s:%d (model %s)\n\n", o.Unit.Type, o.Unit.Name, o.Unit.File, o.Unit.Line, o.Model)
+
+	s := o.Stats
+	fmt.Println("Vector:")
+	fmt.Printf("  dimension %d, norm %.4f\n", s.Dimension, s.Norm)
+	fmt.Printf("  min %.4f, max %.4f, mean %.4f, std dev %.4f\n", s.Min, s.Max, s.Mean, s.StdDev)
+	fmt.Printf("  %d zero and %d NaN/Inf components\n", s.Zeros, s.NaNs)
+	if s.Norm == 0 || s.NaNs > 0 {
+		fmt.Println("  warning: degenerate vector, it scores the same against every query")
+	}
+
+	fmt.Printf("\nNearest neighbours (%d):\n", len(o.Neighbors))
+	for i, n := range o.Neighbors {
+		fmt.Printf("  %2d. %.4f  %s (%s:%d)\n", i+1, n.Score, n.ID, n.File, n.Line)
+	}
+
+	payload, err := json.MarshalIndent(o.Payload, "  ", "  ")
+	if err == nil {
+		fmt.Printf("\nPayload:\n  %s\n", payload)
+	}
+}
+
+func printIndexSample(o *IndexSampleOutput) {
+	fmt.Printf("=== Sample: %d of %d units (seed %d, model %s) ===\n\n", len(o.Units), o.Total, o.Seed, o.Model)
+	for _, u := range o.Units {
+		fmt.Printf("  %s (%s:%d)\n", u.ID, u.File, u.Line)
+	}
+}
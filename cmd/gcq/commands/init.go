@@ -4,12 +4,15 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/huh"
 	"github.com/l3aro/go-context-query/internal/config"
 	"github.com/l3aro/go-context-query/internal/healthcheck"
+	"github.com/l3aro/go-context-query/internal/onboard"
 	"github.com/spf13/cobra"
 )
 
@@ -20,6 +23,14 @@ var initCmd = &cobra.Command{
 	Long: `Guides you through setting up gcq configuration step by step.
 Creates a config file with warm model (for indexing) and search model settings.
 
+It then onboards the project in the current directory: detects the languages
+present, enables chunked indexing of long function bodies when there is code
+to index, appends recommended ignore patterns for generated and vendored
+files of those languages to .gcqignore, and runs a first 'gcq warm' limited
+to --budget (the most useful units first, the rest in the background). The
+warm is skipped when the health check finds the warm model unavailable, or
+with --no-warm. --daemon starts the daemon afterwards.
+
 Use non-interactive mode with flags:
   gcq init --warm-provider ollama --warm-model nomic-embed-text
 
@@ -43,6 +54,10 @@ func runInit(cmd *cobra.Command) error {
 	yesFlag, _ := cmd.Flags().GetBool("yes")
 	skipHealthCheck, _ := cmd.Flags().GetBool("skip-health-check")
 	skillFlag, _ := cmd.Flags().GetBool("skill")
+	onboarding := onboardOptions{}
+	onboarding.daemon, _ = cmd.Flags().GetBool("daemon")
+	onboarding.skipWarm, _ = cmd.Flags().GetBool("no-warm")
+	onboarding.budget, _ = cmd.Flags().GetDuration("budget")
 
 	// Handle skill installation (can be used in both modes)
 	if skillFlag {
@@ -59,7 +74,7 @@ func runInit(cmd *cobra.Command) error {
 		return runInitNonInteractive(
 			warmProviderFlag, warmModelFlag, warmBaseURLFlag, warmAPIKeyFlag,
 			searchProviderFlag, searchModelFlag, searchBaseURLFlag, searchAPIKeyFlag,
-			locationFlag, yesFlag, skipHealthCheck,
+			locationFlag, yesFlag, skipHealthCheck, onboarding,
 		)
 	}
 
@@ -311,6 +326,7 @@ func runInit(cmd *cobra.Command) error {
 
 	// === Build config struct ===
 	cfg := config.DefaultConfig()
+	langs := recommendProjectSettings(cfg)
 
 	// Set warm provider and settings
 	cfg.Warm.Provider = config.ProviderType(warmProvider)
@@ -423,6 +439,10 @@ func runInit(cmd *cobra.Command) error {
 		}
 	}
 
+	if err := onboardProject(langs, onboarding, result.WarmModel.Status != "error"); err != nil {
+		return err
+	}
+
 	fmt.Println("\n=== Initialization Complete ===")
 	return nil
 }
@@ -430,7 +450,7 @@ func runInit(cmd *cobra.Command) error {
 func runInitNonInteractive(
 	warmProviderFlag, warmModelFlag, warmBaseURLFlag, warmAPIKeyFlag string,
 	searchProviderFlag, searchModelFlag, searchBaseURLFlag, searchAPIKeyFlag string,
-	locationFlag string, yesFlag, skipHealthCheck bool,
+	locationFlag string, yesFlag, skipHealthCheck bool, onboarding onboardOptions,
 ) error {
 	warmProvider := warmProviderFlag
 	warmModel := warmModelFlag
@@ -518,6 +538,7 @@ func runInitNonInteractive(
 	}
 
 	cfg := config.DefaultConfig()
+	langs := recommendProjectSettings(cfg)
 
 	cfg.Warm.Provider = config.ProviderType(warmProvider)
 	if warmProvider == "huggingface" {
@@ -580,6 +601,10 @@ func runInitNonInteractive(
 
 	if skipHealthCheck {
 		fmt.Println("\n=== Health check skipped ===")
+		if err := onboardProject(langs, onboarding, true); err != nil {
+			return err
+		}
+		fmt.Println("\n=== Initialization Complete ===")
 		return nil
 	}
 
@@ -623,6 +648,10 @@ func runInitNonInteractive(
 		}
 	}
 
+	if err := onboardProject(langs, onboarding, result.WarmModel.Status != "error"); err != nil {
+		return err
+	}
+
 	fmt.Println("\n=== Initialization Complete ===")
 	return nil
 }
@@ -725,6 +754,87 @@ func installSkill() error {
 	return nil
 }
 
+// defaultInitWarmBudget bounds the first warm gcq init runs, so a large
+// project is searchable within a minute
+const defaultInitWarmBudget = time.Minute
+
+// onboardOptions are the steps gcq init takes after writing the config
+type onboardOptions struct {
+	daemon   bool
+	skipWarm bool
+	budget   time.Duration
+}
+
+// recommendProjectSettings detects the languages of the project in the
+// current directory, prints them and applies the recommended index
+// settings to cfg
+func recommendProjectSettings(cfg *config.Config) []onboard.Language {
+	langs, err := onboard.Detect(".")
+	if err != nil {
+		fmt.Printf("Warning: detecting languages: %v\n", err)
+		return nil
+	}
+	onboard.Recommend(cfg, langs)
+
+	var names []string
+	for _, lang := range langs {
+		if lang.Supported {
+			names = append(names, fmt.Sprintf("%s (%d files)", lang.Name, lang.Files))
+		}
+	}
+	if len(names) == 0 {
+		fmt.Println("\nNo supported languages detected")
+		return langs
+	}
+	fmt.Printf("\nDetected languages: %s\n", strings.Join(names, ", "))
+	if cfg.Embedding.ChunkBodies {
+		fmt.Printf("Long function bodies will be indexed in chunks of %d with an overlap of %d\n", cfg.ChunkSize, cfg.ChunkOverlap)
+	}
+	return langs
+}
+
+// onboardProject writes the recommended ignore patterns for langs, runs a
+// first warm within the budget unless the provider is unhealthy, and
+// starts the daemon when asked
+func onboardProject(langs []onboard.Language, opts onboardOptions, healthy bool) error {
+	fmt.Println("\n=== Project Setup ===")
+	added, err := onboard.WriteIgnoreFile(".", onboard.IgnoreGlobs(langs))
+	if err != nil {
+		return fmt.Errorf("writing ignore patterns: %w", err)
+	}
+	if len(added) > 0 {
+		fmt.Printf("Added to .gcqignore: %s\n", strings.Join(added, " "))
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("finding gcq executable: %w", err)
+	}
+
+	switch {
+	case opts.skipWarm:
+		fmt.Println("First warm skipped; run 'gcq warm' to build the index")
+	case !healthy:
+		fmt.Println("First warm skipped because the warm model is not ready; run 'gcq warm' once it is")
+	default:
+		fmt.Printf("\nBuilding the index (budget %s)\n", opts.budget)
+		warm := exec.Command(exe, "warm", ".", "--budget", opts.budget.String())
+		warm.Stdout, warm.Stderr = os.Stdout, os.Stderr
+		if err := warm.Run(); err != nil {
+			fmt.Printf("Warning: first warm failed (%v); run 'gcq warm' once the warm model is reachable\n", err)
+		}
+	}
+
+	if opts.daemon {
+		start := exec.Command(exe, "start", "-d")
+		start.Stdout, start.Stderr = os.Stdout, os.Stderr
+		if err := start.Run(); err != nil {
+			return fmt.Errorf("starting daemon: %w", err)
+		}
+	}
+	return nil
+}
+
 func init() {
 	initCmd.Flags().String("warm-provider", "", "Warm provider: ollama or huggingface (required in non-interactive mode)")
 	initCmd.Flags().String("warm-model", "", "Warm model name (optional, has sensible defaults)")
@@ -738,6 +848,9 @@ func init() {
 	initCmd.Flags().BoolP("yes", "y", false, "Skip all confirmations, overwrite if exists")
 	initCmd.Flags().Bool("skip-health-check", false, "Skip health check after initialization")
 	initCmd.Flags().Bool("skill", false, "Install gcq skill files to ~/.agents/skills/gcq/")
+	initCmd.Flags().Duration("budget", defaultInitWarmBudget, "Time budget of the first warm; the rest is indexed in the background")
+	initCmd.Flags().Bool("no-warm", false, "Skip the first warm")
+	initCmd.Flags().Bool("daemon", false, "Start the daemon in the background after the first warm")
 
 	RootCmd.AddCommand(initCmd)
 }
@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/l3aro/go-context-query/internal/config"
+	"github.com/l3aro/go-context-query/pkg/langpack"
+)
+
+// languagePacksOnce installs the language packs once per process
+var languagePacksOnce sync.Once
+
+// installLanguagePacks loads the grammars listed in languages.packs of the
+// user config, downloading those not cached yet. Commands that extract or
+// index files call it before scanning. A grammar that fails to load is
+// reported and its files are skipped like any unsupported file. Packs in
+// the project config are ignored, since a grammar runs as native code.
+func installLanguagePacks() {
+	languagePacksOnce.Do(func() {
+		if cfg, err := config.Load(); err == nil && len(cfg.Languages.Packs) > 0 {
+			path, _ := config.UserConfigFilePath()
+			fmt.Fprintf(os.Stderr, "Warning: languages.packs in the project config is ignored; list grammars in %s\n", path)
+		}
+
+		languages, err := config.LoadLanguages()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			return
+		}
+		for _, err := range langpack.Install(context.Background(), languages) {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	})
+}
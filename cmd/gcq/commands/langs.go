@@ -0,0 +1,258 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/l3aro/go-context-query/internal/scanner"
+	"github.com/l3aro/go-context-query/pkg/extractor"
+	"github.com/l3aro/go-context-query/pkg/index"
+	"github.com/l3aro/go-context-query/pkg/semantic"
+	"github.com/l3aro/go-context-query/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// LanguageStats holds per-language counts for the langs command
+type LanguageStats struct {
+	Language string `json:"language"`
+	Files    int    `json:"files"`
+	LOC      int    `json:"loc"`
+	// Supported is true when an extractor exists for the language
+	Supported bool `json:"supported"`
+	// ParsedFiles is the number of files the extractor handled without error
+	ParsedFiles int `json:"parsed_files"`
+	// Units is the number of functions, classes, methods and interfaces extracted
+	Units int `json:"units"`
+	// Embedded is the number of units of this language in the semantic index
+	Embedded int `json:"embedded"`
+	// ExtractedPct is ParsedFiles as a percentage of Files
+	ExtractedPct float64 `json:"extracted_pct"`
+	// EmbeddedPct is Embedded as a percentage of Units
+	EmbeddedPct float64 `json:"embedded_pct"`
+}
+
+// LangsOutput is the JSON output of the langs command
+type LangsOutput struct {
+	Root      string           `json:"root"`
+	Indexed   bool             `json:"indexed"`
+	Languages []*LanguageStats `json:"languages"`
+}
+
+// langsCmd represents the langs command
+var langsCmd = &cobra.Command{
+	Use:   "langs [path]",
+	Short: "Show per-language file, LOC, and unit statistics",
+	Long: `Reports, for each language found under the given path, the number of files,
+non-blank lines of code, extracted units (functions, classes, methods,
+interfaces and traits), and coverage:
+
+  extracted  percentage of files the language's extractor parsed
+  embedded   percentage of extracted units present in the semantic index
+
+Languages without an extractor are listed with their file and line counts
+only. Use it to check that an extractor actually fires on a repository.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		installLanguagePacks()
+
+		path := "."
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("getting absolute path: %w", err)
+		}
+
+		info, err := os.Stat(absPath)
+		if err != nil {
+			return fmt.Errorf("stat path: %w", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("path is not a directory: %s", path)
+		}
+
+		sc := scanner.New(scanner.DefaultOptions())
+		files, err := sc.Scan(absPath)
+		if err != nil {
+			return fmt.Errorf("scanning directory: %w", err)
+		}
+
+		output := &LangsOutput{Root: absPath}
+		vecIndex, _, err := semantic.LoadIndex(absPath)
+		if err == nil {
+			output.Indexed = true
+		}
+		output.Languages = collectLanguageStats(files, vecIndex)
+
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		if jsonOutput {
+			data, err := json.MarshalIndent(output, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshaling JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+		return outputLangsText(output)
+	},
+}
+
+func init() {
+	langsCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+}
+
+// collectLanguageStats extracts every scanned file and counts units per
+// language. vecIndex may be nil when the project has no semantic index.
+func collectLanguageStats(files []scanner.FileInfo, vecIndex *index.VectorIndex) []*LanguageStats {
+	registry := extractor.GetLanguageRegistry()
+	byLang := make(map[string]*LanguageStats)
+
+	for _, f := range files {
+		if f.Language == "" {
+			continue
+		}
+		stats, ok := byLang[f.Language]
+		if !ok {
+			stats = &LanguageStats{Language: f.Language}
+			byLang[f.Language] = stats
+		}
+		stats.Files++
+		stats.LOC += countLOC(f.FullPath)
+
+		ext, err := registry.GetExtractor(f.FullPath)
+		if err != nil {
+			continue
+		}
+		stats.Supported = true
+
+		moduleInfo, err := ext.Extract(f.FullPath)
+		if err != nil {
+			continue
+		}
+		stats.ParsedFiles++
+		stats.Units += countUnits(moduleInfo)
+	}
+
+	if vecIndex != nil {
+		vecIndex.IterVectors(func(id string, vector []float32, metadata types.EmbeddingUnit) bool {
+			if metadata.Unit == nil {
+				return true
+			}
+			if stats, ok := byLang[metadata.Unit.Language]; ok {
+				stats.Embedded++
+			}
+			return true
+		})
+	}
+
+	result := make([]*LanguageStats, 0, len(byLang))
+	for _, stats := range byLang {
+		if stats.Files > 0 {
+			stats.ExtractedPct = percent(stats.ParsedFiles, stats.Files)
+		}
+		if stats.Units > 0 {
+			stats.EmbeddedPct = percent(stats.Embedded, stats.Units)
+		}
+		result = append(result, stats)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Files != result[j].Files {
+			return result[i].Files > result[j].Files
+		}
+		return result[i].Language < result[j].Language
+	})
+	return result
+}
+
+// countUnits counts the units the semantic builder would create for a module.
+// Interfaces and traits that an extractor also lists as classes count once.
+func countUnits(moduleInfo *types.ModuleInfo) int {
+	count := len(moduleInfo.Functions)
+	abstract := make(map[string]bool)
+	for _, iface := range moduleInfo.Interfaces {
+		abstract[iface.Name] = true
+		count += 1 + len(iface.Methods)
+	}
+	for _, trait := range moduleInfo.Traits {
+		abstract[trait.Name] = true
+		count += 1 + len(trait.Methods)
+	}
+	for _, cls := range moduleInfo.Classes {
+		if !abstract[cls.Name] {
+			count += 1 + len(cls.Methods)
+		}
+	}
+	return count
+}
+
+// countLOC counts the non-blank lines of a file
+func countLOC(path string) int {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	loc := 0
+	sc := bufio.NewScanner(file)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		if len(bytes.TrimSpace(sc.Bytes())) > 0 {
+			loc++
+		}
+	}
+	return loc
+}
+
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(total)
+}
+
+func outputLangsText(output *LangsOutput) error {
+	if len(output.Languages) == 0 {
+		fmt.Println("No source files found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "LANGUAGE\tFILES\tLOC\tUNITS\tEXTRACTED\tEMBEDDED")
+
+	var totalFiles, totalLOC, totalUnits int
+	for _, stats := range output.Languages {
+		totalFiles += stats.Files
+		totalLOC += stats.LOC
+		totalUnits += stats.Units
+
+		extracted, embedded, units := "-", "-", "-"
+		if stats.Supported {
+			units = fmt.Sprintf("%d", stats.Units)
+			extracted = fmt.Sprintf("%.0f%%", stats.ExtractedPct)
+			if output.Indexed && stats.Units > 0 {
+				embedded = fmt.Sprintf("%.0f%%", stats.EmbeddedPct)
+			}
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\n",
+			stats.Language, stats.Files, stats.LOC, units, extracted, embedded)
+	}
+	fmt.Fprintf(w, "total\t%d\t%d\t%d\t\t\n", totalFiles, totalLOC, totalUnits)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if !output.Indexed {
+		fmt.Println("\nNo semantic index found; run gcq warm to report embedding coverage.")
+	}
+	return nil
+}
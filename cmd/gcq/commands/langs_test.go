@@ -0,0 +1,167 @@
+package commands
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/l3aro/go-context-query/internal/scanner"
+	"github.com/l3aro/go-context-query/pkg/index"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+func TestCollectLanguageStats(t *testing.T) {
+	root := t.TempDir()
+	sources := map[string]string{
+		// An interface the extractor also lists as a class counts once
+		"shapes.go": `package shapes
+
+// Shape has an area
+type Shape interface {
+	Area() float64
+}
+
+type Square struct{ side float64 }
+
+func (s Square) Area() float64 { return s.side * s.side }
+
+func NewSquare(side float64) Square { return Square{side} }
+`,
+		"util.go": "package shapes\n\nfunc double(x float64) float64 {\n\treturn 2 * x\n}\n",
+		// Counted as a Go file with no units
+		"go.mod": "module example.com/shapes\n\ngo 1.22\n",
+		"app.py": `class Greeter:
+    def greet(self, name):
+        return "hello " + name
+
+
+def main():
+    Greeter().greet("world")
+`,
+		// Detected as Python, but there is no extractor for it
+		"app.pyc":   "compiled\n",
+		"deploy.sh": "#!/bin/sh\n\necho deploying\n",
+	}
+	for name, src := range sources {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(src), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	files, err := scanner.New(scanner.DefaultOptions()).Scan(root)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	vecIndex := index.NewVectorIndex(2)
+	embedded := []struct {
+		id       string
+		language string
+	}{
+		{"go://.#NewSquare", "go"},
+		{"go://.#Square.Area", "go"},
+		{"go://.#Shape", "go"},
+		{"py://app.py#main", "python"},
+		{"py://app.py#Greeter", "python"},
+		{"py://app.py#Greeter.greet", "python"},
+		// Languages that weren't scanned are not counted
+		{"rs://lib.rs#run", "rust"},
+	}
+	for _, e := range embedded {
+		unit := types.EmbeddingUnit{Unit: &types.CodeUnit{ID: e.id, Language: e.language}}
+		if err := vecIndex.Add(e.id, []float32{1, 0}, unit); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	// File-level entries without a code unit are not counted either
+	if err := vecIndex.Add("go://util.go", []float32{0, 1}, types.EmbeddingUnit{}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		index *index.VectorIndex
+		want  []LanguageStats
+	}{
+		{
+			name:  "indexed",
+			index: vecIndex,
+			want: []LanguageStats{
+				{Language: "go", Files: 3, LOC: 14, Supported: true, ParsedFiles: 3, Units: 6, Embedded: 3, ExtractedPct: 100, EmbeddedPct: 50},
+				{Language: "python", Files: 2, LOC: 6, Supported: true, ParsedFiles: 1, Units: 3, Embedded: 3, ExtractedPct: 50, EmbeddedPct: 100},
+				{Language: "shell", Files: 1, LOC: 2},
+			},
+		},
+		{
+			name: "not indexed",
+			want: []LanguageStats{
+				{Language: "go", Files: 3, LOC: 14, Supported: true, ParsedFiles: 3, Units: 6, ExtractedPct: 100},
+				{Language: "python", Files: 2, LOC: 6, Supported: true, ParsedFiles: 1, Units: 3, ExtractedPct: 50},
+				{Language: "shell", Files: 1, LOC: 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := collectLanguageStats(files, tt.index)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d languages, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, want := range tt.want {
+				g := *got[i]
+				if math.Abs(g.ExtractedPct-want.ExtractedPct) > 0.01 || math.Abs(g.EmbeddedPct-want.EmbeddedPct) > 0.01 {
+					t.Errorf("language %d coverage = %.2f%% extracted, %.2f%% embedded, want %.2f%%, %.2f%%",
+						i, g.ExtractedPct, g.EmbeddedPct, want.ExtractedPct, want.EmbeddedPct)
+				}
+				g.ExtractedPct, g.EmbeddedPct = want.ExtractedPct, want.EmbeddedPct
+				if g != want {
+					t.Errorf("language %d = %+v, want %+v", i, g, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCountUnits(t *testing.T) {
+	method := types.Method{Name: "run"}
+	tests := []struct {
+		name   string
+		module types.ModuleInfo
+		want   int
+	}{
+		{"empty", types.ModuleInfo{}, 0},
+		{
+			name:   "functions",
+			module: types.ModuleInfo{Functions: []types.Function{{Name: "a"}, {Name: "b", IsMethod: true}}},
+			want:   2,
+		},
+		{
+			name:   "class and its methods",
+			module: types.ModuleInfo{Classes: []types.Class{{Name: "Runner", Methods: []types.Method{method, method}}}},
+			want:   3,
+		},
+		{
+			name: "interface also listed as a class",
+			module: types.ModuleInfo{
+				Interfaces: []types.Interface{{Name: "Runner", Methods: []types.Method{method}}},
+				Classes:    []types.Class{{Name: "Runner", Methods: []types.Method{method}}, {Name: "Job"}},
+			},
+			want: 3,
+		},
+		{
+			name: "trait also listed as a class",
+			module: types.ModuleInfo{
+				Traits:  []types.Trait{{Name: "Run", Methods: []types.Method{method, method}}},
+				Classes: []types.Class{{Name: "Run"}},
+			},
+			want: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := countUnits(&tt.module); got != tt.want {
+				t.Errorf("countUnits = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/l3aro/go-context-query/pkg/outline"
+	"github.com/spf13/cobra"
+)
+
+// outlineCmd represents the outline command
+var outlineCmd = &cobra.Command{
+	Use:   "outline [file|package]",
+	Short: "Show a hierarchical outline of a file or package",
+	Long: `Prints the definitions of a source file, or of every supported file
+directly in a directory such as a Go package, as a tree: classes,
+interfaces and structs with their fields and methods, enums with their
+variants, and functions with the functions nested in them. Go methods
+are listed under their receiver's type. Each line shows the definition's
+line number, kind, name and signature.
+
+The outline comes from extraction only, so it is a cheap structural
+overview to read before asking for code; no embeddings or index are
+needed.
+
+Examples:
+  gcq outline internal/config/config.go
+  gcq outline pkg/search --depth 1
+  gcq outline app/models.py --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) > 0 {
+			path = args[0]
+		}
+		depth, _ := cmd.Flags().GetInt("depth")
+
+		files, err := outline.Build(path)
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			outline.Prune(f.Nodes, depth)
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			data, err := json.MarshalIndent(files, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshaling JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if len(files) == 0 {
+			fmt.Println("No supported files found")
+			return nil
+		}
+		for i, f := range files {
+			if i > 0 {
+				fmt.Println()
+			}
+			printOutline(f)
+		}
+		return nil
+	},
+}
+
+func printOutline(f outline.File) {
+	fmt.Printf("%s (%s)\n", f.Path, f.Language)
+	if len(f.Nodes) == 0 {
+		fmt.Println("  (no definitions)")
+		return
+	}
+	var print func(nodes []*outline.Node, depth int)
+	print = func(nodes []*outline.Node, depth int) {
+		for _, n := range nodes {
+			line := ""
+			if n.Line > 0 {
+				line = fmt.Sprint(n.Line)
+			}
+			fmt.Printf("%5s  %s%s %s\n", line, strings.Repeat("  ", depth), n.Kind, outlineLabel(n))
+			print(n.Children, depth+1)
+		}
+	}
+	print(f.Nodes, 0)
+}
+
+// outlineLabel formats a node's name with its detail: a signature after a
+// function's name, a type after a field's and bases in parentheses after
+// a type's
+func outlineLabel(n *outline.Node) string {
+	switch {
+	case n.Detail == "":
+		return n.Name
+	case n.Kind == outline.KindFunction || n.Kind == outline.KindMethod:
+		return n.Name + n.Detail
+	case n.Kind == outline.KindField:
+		return n.Name + " " + n.Detail
+	default:
+		return fmt.Sprintf("%s (%s)", n.Name, n.Detail)
+	}
+}
+
+func init() {
+	outlineCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	outlineCmd.Flags().Int("depth", 0, "Levels of the tree to show, 1 for top-level definitions only (0 = all)")
+}
@@ -3,6 +3,7 @@
 package commands
 
 import (
+	"github.com/l3aro/go-context-query/internal/daemon"
 	"github.com/spf13/cobra"
 )
 
@@ -22,8 +23,11 @@ Commands:
   warm        Build semantic index for a project
   semantic    Semantic search over indexed code
   notify      Mark a file as dirty for tracking
+  langs       Per-language file, LOC, and unit statistics
+  debug-bundle  Collect redacted diagnostics for bug reports
 
 Use "gcq [command] --help" for more information about a command.`,
+	PersistentPreRunE: selectInstance,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately
@@ -31,13 +35,34 @@ func Execute() error {
 	return RootCmd.Execute()
 }
 
+// selectInstance points daemon-backed commands at the instance named by
+// --instance, as listed by gcq status --all.
+func selectInstance(cmd *cobra.Command, args []string) error {
+	ref, _ := cmd.Flags().GetString("instance")
+	if ref == "" {
+		return nil
+	}
+	inst, err := daemon.FindInstance(ref)
+	if err != nil {
+		return err
+	}
+	daemon.UseInstance(inst)
+	return nil
+}
+
 func init() {
+	RootCmd.PersistentFlags().String("instance", "", "Daemon instance to use: name, name prefix, or project path (see gcq status --all)")
+
 	RootCmd.AddCommand(treeCmd)
 	RootCmd.AddCommand(structureCmd)
 	RootCmd.AddCommand(extractCmd)
 	RootCmd.AddCommand(contextCmd)
 	RootCmd.AddCommand(callsCmd)
 	RootCmd.AddCommand(impactCmd)
+	RootCmd.AddCommand(callersCmd)
+	RootCmd.AddCommand(symCmd)
+	RootCmd.AddCommand(hierarchyCmd)
+	RootCmd.AddCommand(bundleCmd)
 	RootCmd.AddCommand(warmCmd)
 	RootCmd.AddCommand(semanticCmd)
 	RootCmd.AddCommand(cfgCmd)
@@ -45,4 +70,17 @@ func init() {
 	RootCmd.AddCommand(sliceCmd)
 	RootCmd.AddCommand(searchCmd)
 	RootCmd.AddCommand(notifyCmd)
+	RootCmd.AddCommand(langsCmd)
+	RootCmd.AddCommand(depsCmd)
+	RootCmd.AddCommand(debugBundleCmd)
+	RootCmd.AddCommand(explainCmd)
+	RootCmd.AddCommand(indexCmd)
+	RootCmd.AddCommand(trendsCmd)
+	RootCmd.AddCommand(complexityCmd)
+	RootCmd.AddCommand(deadCodeCmd)
+	RootCmd.AddCommand(exportCmd)
+	RootCmd.AddCommand(todosCmd)
+	RootCmd.AddCommand(feedbackCmd)
+	RootCmd.AddCommand(outlineCmd)
+	RootCmd.AddCommand(genFixtureCmd)
 }
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/l3aro/go-context-query/internal/config"
 	"github.com/l3aro/go-context-query/pkg/search"
 	"github.com/spf13/cobra"
 )
@@ -16,7 +17,9 @@ type SearchOutput struct {
 	Path        string   `json:"path"`
 	Line        int      `json:"line"`
 	Column      int      `json:"column"`
+	EndColumn   int      `json:"end_column"`
 	Match       string   `json:"match"`
+	Highlights  [][2]int `json:"highlights,omitempty"`
 	LineContent string   `json:"line_content"`
 	Context     []string `json:"context,omitempty"`
 }
@@ -58,29 +61,34 @@ Examples:
 
 		// Get flags
 		jsonOutput, _ := cmd.Flags().GetBool("json")
-		contextLines, _ := cmd.Flags().GetInt("context")
-		maxResults, _ := cmd.Flags().GetInt("max")
 		extensions, _ := cmd.Flags().GetStringSlice("ext")
 
 		// Create searcher
-		searcher := search.NewTextSearcher(search.TextSearchOptions{
-			Extensions:   extensions,
-			ContextLines: contextLines,
-			MaxResults:   maxResults,
-		})
+		searcher := search.NewTextSearcher(textSearchOptions(cmd, extensions))
 
 		// Perform search
 		ctx := context.Background()
-		matches, err := searcher.Search(ctx, pattern, absPath)
+		if jsonOutput {
+			matches, err := searcher.Search(ctx, pattern, absPath)
+			if err != nil {
+				return fmt.Errorf("searching: %w", err)
+			}
+			return outputSearchJSON(matches)
+		}
+
+		// Print each file's matches as soon as it has been searched
+		found := false
+		err = searcher.SearchStream(ctx, pattern, absPath, func(matches []search.TextMatch) error {
+			found = true
+			return outputSearchText(matches)
+		})
 		if err != nil {
 			return fmt.Errorf("searching: %w", err)
 		}
-
-		// Output results
-		if jsonOutput {
-			return outputSearchJSON(matches)
+		if !found {
+			fmt.Println("No matches found")
 		}
-		return outputSearchText(matches)
+		return nil
 	},
 }
 
@@ -89,9 +97,47 @@ func init() {
 	searchCmd.Flags().IntP("context", "c", 0, "Number of context lines before and after match")
 	searchCmd.Flags().StringSliceP("ext", "e", []string{}, "File extensions to search (can repeat)")
 	searchCmd.Flags().IntP("max", "m", 0, "Maximum number of results (0 = unlimited)")
+	searchCmd.Flags().Int64("max-file-size", config.DefaultTextSearchConfig().MaxFileSize, "Skip files larger than this many bytes (0 = unlimited)")
+	searchCmd.Flags().Bool("binary", false, "Search binary files too")
 	searchCmd.Flags().BoolP("json", "j", false, "Output as JSON")
 }
 
+// textSearchOptions builds text search options from the project's text_search
+// config (when a project config exists) overridden by any flags that were set
+func textSearchOptions(cmd *cobra.Command, extensions []string) search.TextSearchOptions {
+	contextLines, _ := cmd.Flags().GetInt("context")
+	maxResults, _ := cmd.Flags().GetInt("max")
+	maxFileSize, _ := cmd.Flags().GetInt64("max-file-size")
+	binary, _ := cmd.Flags().GetBool("binary")
+
+	opts := search.TextSearchOptions{
+		Extensions:   extensions,
+		ContextLines: contextLines,
+		MaxResults:   maxResults,
+		MaxFileSize:  maxFileSize,
+		SkipBinary:   !binary,
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return opts
+	}
+	if !cmd.Flags().Changed("context") {
+		opts.ContextLines = cfg.TextSearch.ContextLines
+	}
+	if !cmd.Flags().Changed("max") {
+		opts.MaxResults = cfg.TextSearch.MaxResults
+	}
+	if !cmd.Flags().Changed("max-file-size") {
+		opts.MaxFileSize = cfg.TextSearch.MaxFileSize
+	}
+	if !cmd.Flags().Changed("binary") {
+		opts.SkipBinary = cfg.TextSearch.SkipBinary
+	}
+
+	return opts
+}
+
 func outputSearchJSON(matches []search.TextMatch) error {
 	var results []SearchOutput
 	for _, m := range matches {
@@ -99,9 +145,13 @@ func outputSearchJSON(matches []search.TextMatch) error {
 			Path:        m.FilePath,
 			Line:        m.LineNumber,
 			Column:      m.Column,
+			EndColumn:   m.EndColumn,
 			Match:       m.Match,
 			LineContent: m.LineContent,
 		}
+		for _, h := range m.Highlights {
+			output.Highlights = append(output.Highlights, [2]int{h.Start, h.End})
+		}
 		if len(m.ContextBefore) > 0 || len(m.ContextAfter) > 0 {
 			output.Context = append(output.Context, m.ContextBefore...)
 			output.Context = append(output.Context, m.LineContent)
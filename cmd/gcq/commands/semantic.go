@@ -1,37 +1,62 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/l3aro/go-context-query/internal/config"
 	"github.com/l3aro/go-context-query/internal/daemon"
+	"github.com/l3aro/go-context-query/pkg/client"
 	"github.com/l3aro/go-context-query/pkg/embed"
+	"github.com/l3aro/go-context-query/pkg/index"
 	"github.com/l3aro/go-context-query/pkg/search"
 	"github.com/l3aro/go-context-query/pkg/semantic"
+	"github.com/l3aro/go-context-query/pkg/types"
 	"github.com/spf13/cobra"
 )
 
 // SemanticOutput represents the output of the semantic command
 type SemanticOutput struct {
-	Query   string         `json:"query"`
+	Query string `json:"query"`
+	// Queries lists every query when several were fused
+	Queries []string       `json:"queries,omitempty"`
 	Results []SearchResult `json:"results"`
 	Stats   SemanticStats  `json:"stats"`
 	RootDir string         `json:"root_dir,omitempty"`
+	GroupBy string         `json:"group_by,omitempty"`
+	Groups  []ResultGroup  `json:"groups,omitempty"`
+	// Degradations lists what was skipped or fallen back on, when the
+	// results are partial
+	Degradations types.Degradations `json:"degradations,omitempty"`
+}
+
+// ResultGroup collapses the results of one file or package
+type ResultGroup struct {
+	Key     string         `json:"key"`
+	Count   int            `json:"count"`
+	Results []SearchResult `json:"results"`
 }
 
 // SearchResult represents a single search result
 type SearchResult struct {
+	// URI is the unit URI, which `gcq feedback` takes to judge the result
+	URI        string  `json:"uri,omitempty"`
 	FilePath   string  `json:"file_path"`
 	LineNumber int     `json:"line_number"`
+	EndLine    int     `json:"end_line,omitempty"`
 	Name       string  `json:"name"`
 	Signature  string  `json:"signature,omitempty"`
 	Docstring  string  `json:"docstring,omitempty"`
 	Type       string  `json:"type"`
 	Score      float32 `json:"score"`
+	// Metadata holds the annotations enrichers attached to the unit
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 // SemanticStats represents statistics about the search
@@ -41,55 +66,169 @@ type SemanticStats struct {
 
 // semanticCmd represents the semantic command
 var semanticCmd = &cobra.Command{
-	Use:   "semantic <query>",
+	Use:   "semantic <query> [<query>...]",
 	Short: "Search the code index using semantic similarity",
 	Long: `Performs semantic search over the indexed code to find
-functions, methods, and classes that match the query.`,
-	Args: cobra.ExactArgs(1),
+functions, methods, and classes that match the query.
+
+With --files N the search runs in two phases: files are ranked by the mean
+of their unit vectors, then only units in the top N files are scored. This
+is faster on very large indexes and favours files that match the query as
+a whole.
+
+Units from test files (such as _test.go, test_*.py or *.spec.ts) are left
+out of results unless --include-tests is given.
+
+--exclude mock,fixtures/ (or "-mock" words in the query) penalizes units
+whose path contains a term or whose name, signature or doc comment
+mentions it, so test doubles and fixtures drop below real code.
+
+A type:name word in the query, such as type:function or type:todo, keeps
+only units of that type. TODO comments indexed with index.todos and
+commits indexed with index.commits are left out of results unless
+type:todo or type:commit is given.
+
+--meta ticket=PAY-12 keeps only units whose metadata, attached at index
+time by the enrichers in index.enrichers, has that value; --meta ticket
+keeps units with any value for the key. --meta tags=billing keeps units
+tagged with a gcq:tag billing comment in the source.
+
+With --hybrid a BM25 keyword pass over unit names, signatures and
+docstrings runs alongside the vector search and the two rankings are fused
+with reciprocal rank fusion, so exact identifiers such as parseImportSpec
+are found even when their embedding is not the nearest.
+
+With --keyword only the BM25 pass runs, against the keyword index saved by
+'gcq warm'; the query is not embedded and no files are read.
+
+Several queries, such as a question and candidate identifier names, are
+searched separately and their rankings fused with reciprocal rank fusion,
+so units matching more than one query rank first:
+
+  gcq semantic "how are auth tokens refreshed" refreshToken renewSession`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		query := args[0]
+		queries := search.CombineQueries("", args)
+		if len(queries) == 0 {
+			return fmt.Errorf("query cannot be empty")
+		}
+
+		groupBy, _ := cmd.Flags().GetString("group-by")
+		if err := search.ValidateGroupBy(groupBy); err != nil {
+			return err
+		}
 
 		// Check if daemon is available and use it
 		if daemon.IsRunning() {
-			return runSemanticViaDaemon(query, cmd)
+			return runSemanticViaDaemon(queries, cmd)
 		}
 
-		return runSemanticLocally(query, cmd)
+		return runSemanticLocally(queries, cmd)
 	},
 }
 
-func runSemanticViaDaemon(query string, cmd *cobra.Command) error {
-	// TODO: Implement daemon-based semantic search
-	// For now, fall back to local
-	return runSemanticLocally(query, cmd)
+func runSemanticViaDaemon(queries []string, cmd *cobra.Command) error {
+	rootDir, err := semanticRootDir(cmd)
+	if err != nil {
+		return err
+	}
+
+	// A zero k lets the daemon apply its configured default
+	k, _ := cmd.Flags().GetInt("k")
+	files, _ := cmd.Flags().GetInt("files")
+	includeTests, _ := cmd.Flags().GetBool("include-tests")
+	exclude, _ := cmd.Flags().GetStringSlice("exclude")
+	metadata, err := metadataFilter(cmd)
+	if err != nil {
+		return err
+	}
+	mode := ""
+	if hybrid, _ := cmd.Flags().GetBool("hybrid"); hybrid {
+		mode = "hybrid"
+	}
+	if keyword, _ := cmd.Flags().GetBool("keyword"); keyword {
+		mode = "keyword"
+	}
+	budget, _ := cmd.Flags().GetDuration("budget")
+
+	// Search the project's semantic index inside the daemon; if the daemon
+	// can't serve it (e.g. the index isn't built yet) fall back to local
+	response, err := client.New().SearchDetailed(context.Background(), client.SearchParams{
+		Query:        queries[0],
+		Queries:      queries[1:],
+		Limit:        k,
+		Root:         rootDir,
+		Files:        files,
+		IncludeTests: includeTests,
+		ExcludeTerms: exclude,
+		Metadata:     metadata,
+		Mode:         mode,
+		BudgetMS:     budget.Milliseconds(),
+	})
+	if err != nil {
+		return runSemanticLocally(queries, cmd)
+	}
+
+	var searchResults []SearchResult
+	for _, r := range response.Results {
+		searchResults = append(searchResults, SearchResult{
+			URI:        r.URI,
+			FilePath:   r.FilePath,
+			LineNumber: r.LineNumber,
+			EndLine:    r.EndLine,
+			Name:       r.Name,
+			Signature:  r.Signature,
+			Docstring:  r.Docstring,
+			Type:       r.Type,
+			Score:      float32(r.Score),
+			Metadata:   r.Metadata,
+		})
+	}
+
+	return outputSemantic(SemanticOutput{
+		Query:   queries[0],
+		Queries: multipleQueries(queries),
+		Results: searchResults,
+		Stats:   SemanticStats{TotalResults: len(searchResults)},
+		RootDir: rootDir,
+
+		Degradations: response.Degradations,
+	}, cmd)
 }
 
-func runSemanticLocally(query string, cmd *cobra.Command) error {
-	// Get path flag - defaults to current directory
+// semanticRootDir resolves the project root from --path or the working directory
+func semanticRootDir(cmd *cobra.Command) (string, error) {
 	pathFlag, _ := cmd.Flags().GetString("path")
 
-	// Determine the root directory
-	rootDir := ""
 	if pathFlag != "" {
 		absPath, err := filepath.Abs(pathFlag)
 		if err != nil {
-			return fmt.Errorf("getting absolute path: %w", err)
-		}
-		rootDir, err = findProjectRoot(absPath)
-		if err != nil {
-			return fmt.Errorf("finding project root: %w", err)
+			return "", fmt.Errorf("getting absolute path: %w", err)
 		}
-	} else {
-		// Find project root from current directory
-		cwd, err := os.Getwd()
+		rootDir, err := findProjectRoot(absPath)
 		if err != nil {
-			return fmt.Errorf("getting current directory: %w", err)
+			return "", fmt.Errorf("finding project root: %w", err)
 		}
+		return rootDir, nil
+	}
 
-		rootDir, err = findProjectRoot(cwd)
-		if err != nil {
-			return fmt.Errorf("finding project root: %w", err)
-		}
+	// Find project root from current directory
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("getting current directory: %w", err)
+	}
+
+	rootDir, err := findProjectRoot(cwd)
+	if err != nil {
+		return "", fmt.Errorf("finding project root: %w", err)
+	}
+	return rootDir, nil
+}
+
+func runSemanticLocally(queries []string, cmd *cobra.Command) error {
+	rootDir, err := semanticRootDir(cmd)
+	if err != nil {
+		return err
 	}
 
 	// Load config
@@ -98,22 +237,113 @@ func runSemanticLocally(query string, cmd *cobra.Command) error {
 		return fmt.Errorf("loading config: %w", err)
 	}
 
-	// Load the semantic index
-	vecIndex, metadata, err := semantic.LoadIndex(rootDir)
+	k, _ := cmd.Flags().GetInt("k")
+	k = cfg.Limits.SearchLimit(k)
+	files, _ := cmd.Flags().GetInt("files")
+
+	searcher, opened, err := openSearcher(cmd, cfg, rootDir)
+	if err != nil {
+		return err
+	}
+
+	includeTests, _ := cmd.Flags().GetBool("include-tests")
+	hybrid, _ := cmd.Flags().GetBool("hybrid")
+	keyword, _ := cmd.Flags().GetBool("keyword")
+	exclude, _ := cmd.Flags().GetStringSlice("exclude")
+	metadata, err := metadataFilter(cmd)
 	if err != nil {
-		return fmt.Errorf("loading semantic index: %w\nRun 'gcq warm' first to build the index", err)
+		return err
+	}
+	var budgetDegradations types.Degradations
+	opts := search.SearchOptions{Files: files, IncludeTests: includeTests, ExcludeTerms: exclude, Metadata: metadata, Degradations: &budgetDegradations}
+	opts.Budget, _ = cmd.Flags().GetDuration("budget")
+	if opts.Boosts, err = feedbackBoosts(rootDir, cfg.Search.FeedbackBoost); err != nil {
+		return err
+	}
+	mode := ""
+	switch {
+	case keyword:
+		mode = "keyword"
+	case hybrid:
+		mode = "hybrid"
+	}
+	results, err := searcher.SearchQueries(context.Background(), mode, queries, k, opts)
+	if err != nil {
+		return fmt.Errorf("performing search: %w", err)
+	}
+
+	// Convert results to our format
+	var searchResults []SearchResult
+	var resultFiles []string
+	for _, r := range results {
+		resultFiles = append(resultFiles, r.FilePath)
+		searchResults = append(searchResults, SearchResult{
+			URI:        r.URI,
+			FilePath:   r.FilePath,
+			LineNumber: r.LineNumber,
+			EndLine:    r.EndLine,
+			Name:       r.Name,
+			Signature:  r.Signature,
+			Docstring:  r.Docstring,
+			Type:       r.Type,
+			Score:      r.Score,
+			Metadata:   r.Metadata,
+		})
+	}
+
+	return outputSemantic(SemanticOutput{
+		Query:   queries[0],
+		Queries: multipleQueries(queries),
+		Results: searchResults,
+		Stats:   SemanticStats{TotalResults: len(searchResults)},
+		RootDir: rootDir,
+
+		Degradations: append(opened.resultDegradations(rootDir, resultFiles), budgetDegradations...),
+	}, cmd)
+}
+
+// openedIndex is the semantic index a searcher from openSearcher runs over,
+// with what fell back while opening it
+type openedIndex struct {
+	metadata     *semantic.IndexMetadata
+	provider     embed.Provider
+	degradations types.Degradations
+}
+
+// resultDegradations returns the degradations of results searched from the
+// index: those of its build, result files changed since, and fallbacks of
+// the search provider
+func (o *openedIndex) resultDegradations(rootDir string, files []string) types.Degradations {
+	ds := o.metadata.ResultDegradations(rootDir, files, types.DegradedStaleIndex)
+	for _, d := range o.degradations {
+		ds.Add(d.Feature, d.Reason, d.Count)
+	}
+	if model, ok := embed.FallbackModel(o.provider); ok {
+		ds.Add(types.DegradedProviderFallback, fmt.Sprintf("local embedding runtime unreachable; embedded the query with %s", model), 0)
 	}
+	return ds
+}
 
+// printDegradations notes on stderr what a result was degraded by
+func printDegradations(ds types.Degradations) {
+	for _, d := range ds {
+		if d.Count > 0 {
+			fmt.Fprintf(os.Stderr, "Note: results may be partial; %s: %s (%d affected)\n", d.Feature, d.Reason, d.Count)
+		} else {
+			fmt.Fprintf(os.Stderr, "Note: results may be partial; %s: %s\n", d.Feature, d.Reason)
+		}
+	}
+}
+
+// openSearcher loads the semantic index of rootDir and returns a searcher
+// over it, with the search provider and model from cfg overridden by the
+// command's provider and model flags
+func openSearcher(cmd *cobra.Command, cfg *config.Config, rootDir string) (*search.Searcher, *openedIndex, error) {
 	// Get CLI flags
 	searchProviderFlag, _ := cmd.Flags().GetString("search-provider")
 	providerFlag, _ := cmd.Flags().GetString("provider")
 	searchModelFlag, _ := cmd.Flags().GetString("search-model")
 	modelFlag, _ := cmd.Flags().GetString("model")
-	k, _ := cmd.Flags().GetInt("k")
-
-	if k <= 0 {
-		k = 10
-	}
 
 	// Apply CLI flags to config for search provider
 	if searchProviderFlag != "" {
@@ -131,13 +361,36 @@ func runSemanticLocally(query string, cmd *cobra.Command) error {
 	// Create embedding service with config
 	service, err := embed.NewEmbeddingService(cfg)
 	if err != nil {
-		return fmt.Errorf("creating embedding service: %w", err)
+		return nil, nil, fmt.Errorf("creating embedding service: %w", err)
 	}
 
 	// Get search provider from service
 	provider := service.SearchProvider()
 	if provider == nil {
-		return fmt.Errorf("search provider not initialized")
+		return nil, nil, fmt.Errorf("search provider not initialized")
+	}
+
+	// Find the index built for the search model, or the active one
+	model := provider.Config().Model
+	metadata, err := semantic.LoadMetadataForModel(rootDir, model)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading semantic index: %w\nRun 'gcq warm' first to build the index", err)
+	}
+
+	opened := &openedIndex{metadata: metadata}
+
+	// After a model change in config, the new index may still be building;
+	// until it is active, query the current index with the model that built it
+	indexModel := metadata.GetModel()
+	if searchModelFlag == "" && modelFlag == "" && indexModel != "" && indexModel != provider.Config().Model {
+		opened.degradations.Add(types.DegradedProviderFallback, fmt.Sprintf("no index built with %s yet; searching with %s, which built the active index. Run 'gcq warm' to switch", provider.Config().Model, indexModel), 0)
+		cfg.Search.Model = indexModel
+		if service, err = embed.NewEmbeddingService(cfg); err != nil {
+			return nil, nil, fmt.Errorf("creating embedding service: %w", err)
+		}
+		if provider = service.SearchProvider(); provider == nil {
+			return nil, nil, fmt.Errorf("search provider not initialized")
+		}
 	}
 
 	// Check dimension compatibility between index and search provider
@@ -145,45 +398,124 @@ func runSemanticLocally(query string, cmd *cobra.Command) error {
 	if metadata.Dimension > 0 {
 		if err := embed.ValidateSearchCompatibility(metadata.Dimension, provider); err != nil {
 			if errors.Is(err, embed.ErrDimensionMismatch) {
-				// Dimensions differ but both can report - warn and continue
-				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
-				fmt.Fprintf(os.Stderr, "Search results may be degraded due to dimension mismatch.\n")
+				// Dimensions differ but both can report - note and continue
+				opened.degradations.Add(types.DegradedDimension, err.Error(), 0)
 			} else {
 				// Can't determine provider dimension - this is severe
-				return fmt.Errorf("dimension compatibility check failed: %w", err)
+				return nil, nil, fmt.Errorf("dimension compatibility check failed: %w", err)
 			}
 		}
 	}
 
-	// Create searcher and perform search
-	searcher := search.NewSearcher(provider, vecIndex)
-	results, err := searcher.Search(query, k)
+	opened.provider = provider
+
+	// A SQLite index is searched in place rather than loaded
+	if metadata.Storage == semantic.StorageSQLite {
+		store, err := semantic.OpenSQLiteIndex(metadata.Dir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("loading semantic index: %w\nRun 'gcq warm' first to build the index", err)
+		}
+		return search.NewSQLiteSearcher(provider, store), opened, nil
+	}
+
+	vecIndex, metadata, err := semantic.LoadIndexForModel(rootDir, model)
 	if err != nil {
-		return fmt.Errorf("performing search: %w", err)
+		return nil, nil, fmt.Errorf("loading semantic index: %w\nRun 'gcq warm' first to build the index", err)
 	}
+	backend := semantic.LoadBackend(metadata.Dir, vecIndex, indexBackendOptions(cfg))
+	return search.NewSearcher(provider, vecIndex).WithBackend(backend).WithTextIndex(semantic.LoadTextIndex(metadata.Dir)), opened, nil
+}
 
-	// Convert results to our format
-	var searchResults []SearchResult
-	for _, r := range results {
-		searchResults = append(searchResults, SearchResult{
-			FilePath:   r.FilePath,
-			LineNumber: r.LineNumber,
-			Name:       r.Name,
-			Signature:  r.Signature,
-			Docstring:  r.Docstring,
-			Type:       r.Type,
-			Score:      r.Score,
-		})
+// multipleQueries returns queries when several were fused, for SemanticOutput
+func multipleQueries(queries []string) []string {
+	if len(queries) > 1 {
+		return queries
 	}
+	return nil
+}
 
-	output := SemanticOutput{
-		Query:   query,
-		Results: searchResults,
-		Stats:   SemanticStats{TotalResults: len(searchResults)},
-		RootDir: rootDir,
+// indexBackendOptions returns the search backend options from the index config
+func indexBackendOptions(cfg *config.Config) index.BackendOptions {
+	return index.BackendOptions{
+		Kind:          cfg.Index.Backend,
+		AutoThreshold: cfg.Index.HNSWThreshold,
+		HNSW: index.HNSWOptions{
+			M:              cfg.Index.HNSWM,
+			EfConstruction: cfg.Index.HNSWEfConstruction,
+			EfSearch:       cfg.Index.HNSWEfSearch,
+		},
+		Quantization: cfg.Index.Quantization,
+	}
+}
+
+// chunkOptions returns the body chunking settings, or none when
+// embedding.chunk_bodies is off
+func chunkOptions(cfg *config.Config) semantic.ChunkOptions {
+	if !cfg.Embedding.ChunkBodies {
+		return semantic.ChunkOptions{}
+	}
+	return semantic.ChunkOptions{Size: cfg.ChunkSize, Overlap: cfg.ChunkOverlap}
+}
+
+// commitOptions returns the git history indexing settings
+func commitOptions(cfg *config.Config) semantic.CommitOptions {
+	c := cfg.Index.Commits
+	return semantic.CommitOptions{
+		Enabled:     c.Enabled,
+		Limit:       c.Limit,
+		GitHubToken: c.GitHubToken,
+		GitHubRepo:  c.GitHubRepo,
+		GitHubAPI:   c.GitHubAPI,
+	}
+}
+
+// dependencyFilter returns the imports left out of unit dependencies
+func dependencyFilter(cfg *config.Config) semantic.DependencyFilter {
+	return semantic.DependencyFilter{
+		Stdlib: cfg.Index.Dependencies.Stdlib,
+		Ignore: cfg.Index.Dependencies.Ignore,
+	}
+}
+
+// metadataFilter parses the --meta key=value (or key) flags into a
+// metadata filter
+func metadataFilter(cmd *cobra.Command) (map[string]string, error) {
+	pairs, _ := cmd.Flags().GetStringArray("meta")
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	filter := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, _ := strings.Cut(pair, "=")
+		if key = strings.TrimSpace(key); key == "" {
+			return nil, fmt.Errorf("invalid --meta %q: expected key or key=value", pair)
+		}
+		filter[key] = strings.TrimSpace(value)
+	}
+	return filter, nil
+}
+
+// indexEnrichers returns the pattern enrichers configured in
+// index.enrichers
+func indexEnrichers(cfg *config.Config) ([]semantic.Enricher, error) {
+	var enrichers []semantic.Enricher
+	for _, e := range cfg.Index.Enrichers {
+		enricher, err := semantic.NewPatternEnricher(e.Key, e.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		enrichers = append(enrichers, enricher)
+	}
+	return enrichers, nil
+}
+
+func outputSemantic(output SemanticOutput, cmd *cobra.Command) error {
+	printDegradations(output.Degradations)
+	output.GroupBy, _ = cmd.Flags().GetString("group-by")
+	if output.GroupBy != "" {
+		output.Groups = groupSemanticResults(output.Results, output.RootDir, output.GroupBy)
 	}
 
-	// Output
 	jsonOutput, _ := cmd.Flags().GetBool("json")
 	if jsonOutput {
 		data, err := json.MarshalIndent(output, "", "  ")
@@ -198,28 +530,84 @@ func runSemanticLocally(query string, cmd *cobra.Command) error {
 	return nil
 }
 
+// groupSemanticResults collapses results by file or package, keyed by
+// paths relative to rootDir, in rank order
+func groupSemanticResults(results []SearchResult, rootDir, by string) []ResultGroup {
+	var groups []ResultGroup
+	positions := make(map[string]int)
+	for _, r := range results {
+		key := search.GroupKey(relativeResultPath(r.FilePath, rootDir), by)
+		i, ok := positions[key]
+		if !ok {
+			i = len(groups)
+			positions[key] = i
+			groups = append(groups, ResultGroup{Key: key})
+		}
+		groups[i].Results = append(groups[i].Results, r)
+		groups[i].Count++
+	}
+	return groups
+}
+
+// relativeResultPath returns a result path relative to rootDir when possible
+func relativeResultPath(filePath, rootDir string) string {
+	if !filepath.IsAbs(filePath) {
+		return filePath
+	}
+	rel, err := filepath.Rel(rootDir, filePath)
+	if err != nil {
+		return filePath
+	}
+	return rel
+}
+
+// lineRange formats a result's line, or its line range when the result is
+// a body chunk or has a known end
+func lineRange(r SearchResult) string {
+	if r.EndLine > r.LineNumber {
+		return fmt.Sprintf("%d-%d", r.LineNumber, r.EndLine)
+	}
+	return strconv.Itoa(r.LineNumber)
+}
+
 func printSemantic(output SemanticOutput) {
-	fmt.Printf("=== Semantic Search: %s ===\n\n", output.Query)
+	query := output.Query
+	if len(output.Queries) > 1 {
+		query = strings.Join(output.Queries, " | ")
+	}
+	fmt.Printf("=== Semantic Search: %s ===\n\n", query)
 
 	if len(output.Results) == 0 {
 		fmt.Println("No results found.")
 		return
 	}
 
+	if output.GroupBy != "" {
+		fmt.Printf("Found %d result(s) in %d %s group(s):\n\n", len(output.Results), len(output.Groups), output.GroupBy)
+		for _, g := range output.Groups {
+			noun := "matches"
+			if g.Count == 1 {
+				noun = "match"
+			}
+			fmt.Printf("%s – %d %s\n", g.Key, g.Count, noun)
+			for _, r := range g.Results {
+				fmt.Printf("   %s:%s  %s (%s, %.3f)\n", relativeResultPath(r.FilePath, output.RootDir), lineRange(r), r.Name, r.Type, r.Score)
+			}
+			fmt.Println()
+		}
+		return
+	}
+
 	fmt.Printf("Found %d result(s):\n\n", len(output.Results))
 
 	for i, r := range output.Results {
-		relPath := r.FilePath
-		if filepath.IsAbs(r.FilePath) {
-			var err error
-			relPath, err = filepath.Rel(output.RootDir, r.FilePath)
-			if err != nil {
-				relPath = r.FilePath
-			}
-		}
-		fmt.Printf("%d. %s:%d\n", i+1, relPath, r.LineNumber)
+		relPath := relativeResultPath(r.FilePath, output.RootDir)
+		fmt.Printf("%d. %s:%s\n", i+1, relPath, lineRange(r))
 		fmt.Printf("   Name: %s (type: %s)\n", r.Name, r.Type)
 		fmt.Printf("   Score: %.3f\n", r.Score)
+		if r.URI != "" {
+			fmt.Printf("   URI: %s\n", r.URI)
+		}
 		if r.Signature != "" {
 			fmt.Printf("   Signature: %s\n", r.Signature)
 		}
@@ -227,7 +615,7 @@ func printSemantic(output SemanticOutput) {
 			// Truncate docstring if too long
 			doc := r.Docstring
 			if len(doc) > 100 {
-				doc = doc[:100] + "..."
+				doc = types.TruncateUTF8(doc, 100) + "..."
 			}
 			fmt.Printf("   Doc: %s\n", doc)
 		}
@@ -237,10 +625,19 @@ func printSemantic(output SemanticOutput) {
 
 func init() {
 	semanticCmd.Flags().BoolP("json", "j", false, "Output as JSON")
-	semanticCmd.Flags().StringP("provider", "p", "", "Embedding provider for backward compatibility (ollama or huggingface)")
+	semanticCmd.Flags().StringP("provider", "p", "", "Embedding provider for backward compatibility (ollama, huggingface, local or mock)")
 	semanticCmd.Flags().StringP("model", "m", "", "Embedding model name for backward compatibility")
-	semanticCmd.Flags().String("search-provider", "", "Search-specific embedding provider (ollama or huggingface)")
+	semanticCmd.Flags().String("search-provider", "", "Search-specific embedding provider (ollama, huggingface, local or mock)")
 	semanticCmd.Flags().String("search-model", "", "Search-specific embedding model name")
-	semanticCmd.Flags().IntP("k", "k", 10, "Number of results to return")
+	semanticCmd.Flags().IntP("k", "k", 0, "Number of results to return (default: limits.search_results, 10)")
 	semanticCmd.Flags().StringP("path", "", "", "Project path to search (defaults to current directory)")
+	semanticCmd.Flags().Int("files", 0, "Two-phase search: rank files first and only search units in the top N files (0 = search all units)")
+	semanticCmd.Flags().Bool("include-tests", false, "Include units from test files in results")
+	semanticCmd.Flags().StringSlice("exclude", nil, "Penalize results whose path or text contains these terms (e.g. mock,fixtures/)")
+	semanticCmd.Flags().StringArray("meta", nil, "Only return units whose enricher metadata has this key, or key=value (repeatable)")
+	semanticCmd.Flags().String("group-by", "", "Collapse results by 'file' or 'package'")
+	semanticCmd.Flags().Bool("hybrid", false, "Fuse vector search with a keyword (BM25) pass over names, signatures and docstrings")
+	semanticCmd.Flags().Bool("keyword", false, "Rank by the keyword (BM25) index only, without embedding the query")
+	semanticCmd.Flags().Duration("budget", 0, "Stop scanning the index after this long (e.g. 200ms) and return the best results found so far")
+	semanticCmd.MarkFlagsMutuallyExclusive("hybrid", "keyword")
 }
@@ -2,29 +2,56 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
-	"slices"
+	"path/filepath"
 	"strings"
 
+	"github.com/l3aro/go-context-query/internal/daemon"
+	"github.com/l3aro/go-context-query/pkg/client"
 	"github.com/l3aro/go-context-query/pkg/pdg"
+	"github.com/l3aro/go-context-query/pkg/types"
 	"github.com/spf13/cobra"
 )
 
 var sliceCmd = &cobra.Command{
-	Use:   "slice <file> <function> --line N [--backward|--forward] [--var NAME] [--json]",
+	Use:   "slice (<file> [<function>] | <unit-uri> | --file FILE) --line N [--backward|--forward] [--var NAME] [--json]",
 	Short: "Perform backward or forward slice analysis on a function",
 	Long: `Perform slice analysis on a specific function to find data and control dependencies.
 
 Backward slice: Find all lines that may affect the value at the target line.
 Forward slice: Find all lines that may be affected by the value at the source line.
 
+The function may also be given as a unit URI, e.g. py://pkg/mod.py#Worker.run.
+Without a function, the one enclosing --line is used, so editors can ask
+"what affects this line" with just --file and --line. The slice lines are
+printed with their source code.
+
+Uses the daemon when it is running.
+
 Supports Python, Go, TypeScript, Rust, Java, C, C++, Ruby, and PHP.`,
-	Args: cobra.ExactArgs(2),
+	Args: cobra.RangeArgs(0, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		filePath := args[0]
-		functionName := args[1]
+		installLanguagePacks()
+
+		filePath, _ := cmd.Flags().GetString("file")
+		var functionName string
+		switch {
+		case len(args) == 2:
+			filePath, functionName = args[0], args[1]
+		case len(args) == 1 && types.IsUnitURI(args[0]):
+			var err error
+			filePath, functionName, err = resolveUnitURI(args[0])
+			if err != nil {
+				return err
+			}
+		case len(args) == 1:
+			filePath = args[0]
+		case filePath == "":
+			return fmt.Errorf("expected <file> [<function>], a unit URI or --file")
+		}
 
 		info, err := os.Stat(filePath)
 		if err != nil {
@@ -35,6 +62,12 @@ Supports Python, Go, TypeScript, Rust, Java, C, C++, Ruby, and PHP.`,
 			return fmt.Errorf("path is a directory, expected a file: %s", filePath)
 		}
 
+		// The daemon resolves relative paths against its own project root
+		absFile, err := filepath.Abs(filePath)
+		if err != nil {
+			return fmt.Errorf("resolving file path: %w", err)
+		}
+
 		lineNum, err := cmd.Flags().GetInt("line")
 		if err != nil {
 			return fmt.Errorf("getting line flag: %w", err)
@@ -43,99 +76,70 @@ Supports Python, Go, TypeScript, Rust, Java, C, C++, Ruby, and PHP.`,
 			return fmt.Errorf("line number must be positive: %d", lineNum)
 		}
 
-		backward, _ := cmd.Flags().GetBool("backward")
 		forward, _ := cmd.Flags().GetBool("forward")
 
-		// Default to backward if neither specified
-		if !backward && !forward {
-			backward = true
+		params := client.SliceParams{File: absFile, Func: functionName, Line: lineNum, Direction: "backward"}
+		if forward {
+			params.Direction = "forward"
 		}
-
-		var varFilter *string
 		if cmd.Flags().Changed("var") {
 			varName, _ := cmd.Flags().GetString("var")
-			varFilter = &varName
+			params.Var = &varName
 		}
 
-		pdgInfo, err := pdg.ExtractPDG(filePath, functionName)
+		var result *pdg.SliceResult
+		if daemon.IsRunning() {
+			result, err = client.New().Slice(context.Background(), params)
+		} else {
+			result, err = (&client.Executor{}).Slice(context.Background(), params)
+		}
 		if err != nil {
-			if isFunctionNotFoundError(err) {
+			if functionName != "" && isFunctionNotFoundError(err) {
 				return fmt.Errorf("function %q not found in %s", functionName, filePath)
 			}
-			return fmt.Errorf("extracting PDG: %w", err)
-		}
-
-		var sliceLines []int
-		if backward {
-			sliceLines = pdg.BackwardSlice(pdgInfo, lineNum, varFilter)
-		} else {
-			sliceLines = pdg.ForwardSlice(pdgInfo, lineNum, varFilter)
-		}
-
-		if sliceLines == nil {
-			sliceLines = []int{}
+			return err
 		}
 
-		// Sort slice lines for consistent output
-		slices.Sort(sliceLines)
-
 		jsonOutput, _ := cmd.Flags().GetBool("json")
 
 		if jsonOutput {
-			output := struct {
-				FunctionName string `json:"function_name"`
-				Line         int    `json:"line"`
-				Direction    string `json:"direction"`
-				Variable     string `json:"variable,omitempty"`
-				SliceLines   []int  `json:"slice_lines"`
-			}{
-				FunctionName: functionName,
-				Line:         lineNum,
-				Direction:    map[bool]string{true: "backward", false: "forward"}[backward],
-				SliceLines:   sliceLines,
-			}
-			if varFilter != nil {
-				output.Variable = *varFilter
-			}
-
-			data, err := json.MarshalIndent(output, "", "  ")
+			data, err := json.MarshalIndent(result, "", "  ")
 			if err != nil {
 				return fmt.Errorf("marshaling JSON: %w", err)
 			}
 			fmt.Println(string(data))
 		} else {
-			printSliceInfo(functionName, lineNum, backward, varFilter, sliceLines, pdgInfo)
+			printSliceInfo(result)
 		}
 
 		return nil
 	},
 }
 
-func printSliceInfo(functionName string, lineNum int, backward bool, varFilter *string, sliceLines []int, pdgInfo *pdg.PDGInfo) {
-	direction := "backward"
-	if !backward {
-		direction = "forward"
-	}
-
-	fmt.Printf("=== Slice for function: %s (line %d, %s) ===\n", functionName, lineNum, direction)
+func printSliceInfo(result *pdg.SliceResult) {
+	fmt.Printf("=== Slice for function: %s (line %d, %s) ===\n", result.FunctionName, result.Line, result.Direction)
 
-	if varFilter != nil {
-		fmt.Printf("Variable filter: %s\n", *varFilter)
+	if result.Variable != "" {
+		fmt.Printf("Variable filter: %s\n", result.Variable)
 	}
 
-	fmt.Printf("\nSlice lines (%d): ", len(sliceLines))
-	if len(sliceLines) == 0 {
+	fmt.Printf("\nSlice lines (%d): ", len(result.SliceLines))
+	if len(result.SliceLines) == 0 {
 		fmt.Println("none")
 		return
 	}
 
 	// Print as range representation
-	fmt.Println(formatLineRanges(sliceLines))
-
-	// If we have the PDG, show the source code with highlighted slice lines
-	if pdgInfo != nil && pdgInfo.CFG != nil {
-		fmt.Println("\n--- Source code with slice lines highlighted ---")
-		printSourceWithHighlights(pdgInfo, sliceLines)
+	fmt.Println(formatLineRanges(result.SliceLines))
+
+	// Show the source of the slice lines, marking the line sliced from
+	fmt.Println("\n--- Source ---")
+	for _, l := range result.Lines {
+		marker := "    "
+		if l.Line == result.Line {
+			marker = " >>>"
+		}
+		fmt.Printf("%5d:%s %s\n", l.Line, marker, l.Code)
 	}
 }
 
@@ -172,66 +176,12 @@ func formatLineRanges(lines []int) string {
 	return strings.Join(ranges, ", ")
 }
 
-func printSourceWithHighlights(pdgInfo *pdg.PDGInfo, sliceLines []int) {
-	// Create a set for O(1) lookup
-	lineSet := make(map[int]bool)
-	for _, line := range sliceLines {
-		lineSet[line] = true
-	}
-
-	// Collect all statements from CFG blocks with their line numbers
-	type lineContent struct {
-		lineNum int
-		content string
-	}
-
-	var allLines []lineContent
-
-	// Get line ranges from PDG nodes
-	for _, node := range pdgInfo.Nodes {
-		for line := node.StartLine; line <= node.EndLine; line++ {
-			// Try to get the statement from CFG blocks
-			if block, ok := pdgInfo.CFG.Blocks[node.CFGBlockID]; ok {
-				stmtIndex := line - block.StartLine
-				if stmtIndex >= 0 && stmtIndex < len(block.Statements) {
-					allLines = append(allLines, lineContent{
-						lineNum: line,
-						content: block.Statements[stmtIndex],
-					})
-				}
-			}
-		}
-	}
-
-	// Sort by line number and print
-	slices.SortFunc(allLines, func(a, b lineContent) int {
-		return a.lineNum - b.lineNum
-	})
-
-	// Deduplicate while preserving order
-	seen := make(map[int]bool)
-	var uniqueLines []lineContent
-	for _, lc := range allLines {
-		if !seen[lc.lineNum] {
-			seen[lc.lineNum] = true
-			uniqueLines = append(uniqueLines, lc)
-		}
-	}
-
-	for _, lc := range uniqueLines {
-		highlight := ""
-		if lineSet[lc.lineNum] {
-			highlight = " >>>"
-		}
-		fmt.Printf("%5d:%s %s\n", lc.lineNum, highlight, lc.content)
-	}
-}
-
 func init() {
 	sliceCmd.Flags().IntP("line", "l", 0, "Line number to slice from (required)")
 	sliceCmd.Flags().BoolP("backward", "b", false, "Backward slice (default)")
 	sliceCmd.Flags().BoolP("forward", "f", false, "Forward slice")
 	sliceCmd.Flags().StringP("var", "v", "", "Variable name to filter (optional)")
+	sliceCmd.Flags().String("file", "", "File to slice; the function enclosing --line is used")
 	sliceCmd.Flags().BoolP("json", "j", false, "Output as JSON")
 
 	_ = sliceCmd.MarkFlagRequired("line")
@@ -28,6 +28,8 @@ var structureCmd = &cobra.Command{
 	Long:  `Analyzes all supported files in the given path and shows their structure including functions, classes, and imports.`,
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		installLanguagePacks()
+
 		path := "."
 		if len(args) > 0 {
 			path = args[0]
@@ -208,8 +210,7 @@ func printStructureFile(info StructureOutput) {
 }
 
 func isSupported(filePath string) bool {
-	registry := extractor.NewLanguageRegistry()
-	return registry.IsSupported(filePath)
+	return extractor.GetLanguageRegistry().IsSupported(filePath)
 }
 
 func detectLanguage(filePath string) string {
@@ -237,6 +238,9 @@ func detectLanguage(filePath string) string {
 	if lang, ok := langMap[ext]; ok {
 		return lang
 	}
+	if lang := scanner.DetectLanguage(ext); lang != "" {
+		return lang
+	}
 	return "unknown"
 }
 
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/l3aro/go-context-query/internal/daemon"
+	"github.com/l3aro/go-context-query/pkg/client"
+	"github.com/l3aro/go-context-query/pkg/symbols"
+	"github.com/spf13/cobra"
+)
+
+// symCmd represents the sym command
+var symCmd = &cobra.Command{
+	Use:   "sym <pattern>",
+	Short: "Fuzzy find functions, classes and methods by name",
+	Long: `Matches <pattern> against the names of every function, class and method
+in the project the way fzf does: the pattern's characters must appear in
+order, and matches at word starts, after '_' or '.', on camelCase humps and
+in consecutive runs rank higher. "pc" finds parseConfig, "p.prs" finds
+Parser.parse. Matching ignores case unless the pattern has an upper case
+letter.
+
+Symbols come from extraction only, so no embeddings or index are needed.
+Uses the daemon when it is running, which answers from the files it has
+indexed.
+
+Examples:
+  gcq sym pc
+  gcq sym Parser.prs --limit 5
+  gcq sym handler --path ./api --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rootDir, err := semanticRootDir(cmd)
+		if err != nil {
+			return err
+		}
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		params := client.SymbolsParams{Pattern: args[0], Limit: limit, Root: rootDir}
+
+		var result *client.SymbolsResult
+		if daemon.IsRunning() {
+			result, err = client.New().Symbols(context.Background(), params)
+		}
+		if result == nil {
+			executor := &client.Executor{}
+			result, err = executor.Symbols(context.Background(), params)
+		}
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			data, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshaling JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		printSymbols(result)
+		return nil
+	},
+}
+
+func printSymbols(result *client.SymbolsResult) {
+	if len(result.Matches) == 0 {
+		fmt.Printf("No symbols matching %q in %d symbols\n", result.Pattern, result.Total)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tKIND\tLOCATION\tSCORE")
+	for _, m := range result.Matches {
+		fmt.Fprintf(w, "%s\t%s\t%s:%d\t%d\n", m.Name, m.Kind, m.File, m.Line, m.Score)
+	}
+	w.Flush()
+	fmt.Printf("\n%d of %d symbols\n", result.Count, result.Total)
+}
+
+func init() {
+	symCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	symCmd.Flags().IntP("limit", "n", symbols.DefaultLimit, "Maximum number of matches")
+	symCmd.Flags().String("path", "", "Project path to search (defaults to current directory)")
+}
@@ -0,0 +1,205 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/l3aro/go-context-query/pkg/semantic"
+	"github.com/spf13/cobra"
+)
+
+// noOwner labels the group of TODOs without an owner
+const noOwner = "(no owner)"
+
+// TodosOutput is the JSON output of the todos command
+type TodosOutput struct {
+	Root   string          `json:"root"`
+	Count  int             `json:"count"`
+	Todos  []semantic.Todo `json:"todos,omitempty"`
+	Owners []OwnerTodos    `json:"owners,omitempty"`
+}
+
+// OwnerTodos holds the TODOs of one owner
+type OwnerTodos struct {
+	Owner string          `json:"owner"`
+	Count int             `json:"count"`
+	Todos []semantic.Todo `json:"todos"`
+}
+
+// todosCmd represents the todos command
+var todosCmd = &cobra.Command{
+	Use:   "todos [path]",
+	Short: "List TODO, FIXME and HACK comments",
+	Long: `Lists the TODO, FIXME and HACK comments in the source files under the
+given path, found in the comment nodes of each file's syntax tree, so
+strings and identifiers that merely contain the words are skipped.
+
+An owner is read from TODO(alice) or TODO @alice. --by-owner groups the
+comments by owner, busiest first, with unowned ones last.
+
+With index.todos set, 'gcq warm' also indexes these comments as "todo"
+units with their surrounding lines; search them with a type:todo word in
+the query:
+
+  gcq semantic "retry type:todo"
+
+Examples:
+  gcq todos
+  gcq todos --by-owner
+  gcq todos --marker FIXME,HACK --owner alice src/`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		installLanguagePacks()
+
+		path := "."
+		if len(args) > 0 {
+			path = args[0]
+		}
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("getting absolute path: %w", err)
+		}
+		if info, err := os.Stat(absPath); err != nil {
+			return fmt.Errorf("stat path: %w", err)
+		} else if !info.IsDir() {
+			return fmt.Errorf("path is not a directory: %s", path)
+		}
+
+		todos, err := semantic.ScanTodos(absPath)
+		if err != nil {
+			return err
+		}
+		markers, _ := cmd.Flags().GetStringSlice("marker")
+		owner, _ := cmd.Flags().GetString("owner")
+		todos = filterTodos(todos, markers, owner)
+
+		output := TodosOutput{Root: absPath, Count: len(todos)}
+		if byOwner, _ := cmd.Flags().GetBool("by-owner"); byOwner {
+			output.Owners = groupTodosByOwner(todos)
+		} else {
+			output.Todos = todos
+		}
+
+		if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+			data, err := json.MarshalIndent(output, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshaling JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+		outputTodosText(output)
+		return nil
+	},
+}
+
+func init() {
+	todosCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	todosCmd.Flags().Bool("by-owner", false, "Group comments by owner")
+	todosCmd.Flags().StringSlice("marker", nil, "Only list these markers (TODO, FIXME, HACK)")
+	todosCmd.Flags().String("owner", "", "Only list comments of this owner")
+}
+
+// filterTodos keeps the TODOs with one of markers, when given, and owner,
+// when given
+func filterTodos(todos []semantic.Todo, markers []string, owner string) []semantic.Todo {
+	if len(markers) == 0 && owner == "" {
+		return todos
+	}
+	var kept []semantic.Todo
+	for _, todo := range todos {
+		if len(markers) > 0 && !containsFold(markers, todo.Marker) {
+			continue
+		}
+		if owner != "" && !strings.EqualFold(owner, todo.Owner) {
+			continue
+		}
+		kept = append(kept, todo)
+	}
+	return kept
+}
+
+// containsFold reports whether list holds s, ignoring case
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(strings.TrimSpace(item), s) {
+			return true
+		}
+	}
+	return false
+}
+
+// groupTodosByOwner groups todos by owner, most TODOs first and those
+// without an owner last
+func groupTodosByOwner(todos []semantic.Todo) []OwnerTodos {
+	byOwner := make(map[string]*OwnerTodos)
+	var groups []*OwnerTodos
+	for _, todo := range todos {
+		owner := todo.Owner
+		if owner == "" {
+			owner = noOwner
+		}
+		group := byOwner[owner]
+		if group == nil {
+			group = &OwnerTodos{Owner: owner}
+			byOwner[owner] = group
+			groups = append(groups, group)
+		}
+		group.Todos = append(group.Todos, todo)
+		group.Count++
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if (a.Owner == noOwner) != (b.Owner == noOwner) {
+			return b.Owner == noOwner
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Owner < b.Owner
+	})
+	result := make([]OwnerTodos, len(groups))
+	for i, group := range groups {
+		result[i] = *group
+	}
+	return result
+}
+
+func outputTodosText(output TodosOutput) {
+	if output.Count == 0 {
+		fmt.Println("No TODO, FIXME or HACK comments found")
+		return
+	}
+	if output.Owners == nil {
+		for _, todo := range output.Todos {
+			fmt.Println(formatTodo(todo, true))
+		}
+		return
+	}
+	for i, group := range output.Owners {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s (%d)\n", group.Owner, group.Count)
+		for _, todo := range group.Todos {
+			fmt.Println("  " + formatTodo(todo, false))
+		}
+	}
+}
+
+// formatTodo formats a TODO as "file:line MARKER(owner): text"
+func formatTodo(todo semantic.Todo, withOwner bool) string {
+	marker := todo.Marker
+	if withOwner && todo.Owner != "" {
+		marker += "(" + todo.Owner + ")"
+	}
+	line := fmt.Sprintf("%s:%d %s", todo.File, todo.Line, marker)
+	if todo.Text != "" {
+		line += ": " + todo.Text
+	}
+	return line
+}
@@ -28,6 +28,8 @@ var treeCmd = &cobra.Command{
 	Long:  `Shows a tree view of the directory structure starting from the given path.`,
 	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		installLanguagePacks()
+
 		path := "."
 		if len(args) > 0 {
 			path = args[0]
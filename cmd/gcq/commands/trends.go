@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/l3aro/go-context-query/pkg/semantic"
+	"github.com/spf13/cobra"
+)
+
+// TrendsOutput is the JSON output of the trends command
+type TrendsOutput struct {
+	Root      string                     `json:"root"`
+	Snapshots []semantic.MetricsSnapshot `json:"snapshots"`
+}
+
+// trendMetric is a metric the trends command tracks across builds
+type trendMetric struct {
+	name  string
+	value func(semantic.MetricsSnapshot) float64
+	// format renders a value of the metric
+	format string
+}
+
+var trendMetrics = []trendMetric{
+	{"units", func(s semantic.MetricsSnapshot) float64 { return float64(s.Units) }, "%.0f"},
+	{"files", func(s semantic.MetricsSnapshot) float64 { return float64(s.Files) }, "%.0f"},
+	{"mean complexity", func(s semantic.MetricsSnapshot) float64 { return s.Complexity.Mean }, "%.2f"},
+	{"high complexity", func(s semantic.MetricsSnapshot) float64 {
+		return float64(s.Complexity.High + s.Complexity.VeryHigh)
+	}, "%.0f"},
+	{"max complexity", func(s semantic.MetricsSnapshot) float64 { return float64(s.Complexity.Max) }, "%.0f"},
+	{"dead code", func(s semantic.MetricsSnapshot) float64 { return float64(s.DeadCode) }, "%.0f"},
+}
+
+// trendsCmd represents the trends command
+var trendsCmd = &cobra.Command{
+	Use:   "trends [path]",
+	Short: "Show how code metrics changed over recent index builds",
+	Long: `Every 'gcq warm' records a snapshot of code-health metrics: unit and
+file counts, the cyclomatic complexity distribution of functions and
+methods, and the number of functions nothing in the project calls (dead
+code candidates; tests, entry points and exported Go identifiers are not
+counted).
+
+trends lists the most recent snapshots with the change since the previous
+build, followed by a sparkline of each metric over those builds.
+
+Complexity buckets: low 1-5, moderate 6-10, high 11-20, very high above 20.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("getting absolute path: %w", err)
+		}
+
+		history, err := semantic.LoadMetrics(absPath)
+		if err != nil {
+			return fmt.Errorf("loading metrics: %w", err)
+		}
+		last, _ := cmd.Flags().GetInt("last")
+		if last > 0 && len(history) > last {
+			history = history[len(history)-last:]
+		}
+
+		output := TrendsOutput{Root: absPath, Snapshots: history}
+		if output.Snapshots == nil {
+			output.Snapshots = []semantic.MetricsSnapshot{}
+		}
+
+		jsonOutput, _ := cmd.Flags().GetBool("json")
+		if jsonOutput {
+			data, err := json.MarshalIndent(output, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshaling JSON: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+		return outputTrendsText(output)
+	},
+}
+
+func init() {
+	trendsCmd.Flags().BoolP("json", "j", false, "Output as JSON")
+	trendsCmd.Flags().IntP("last", "n", 10, "Number of recent builds to show (0 = all)")
+}
+
+func outputTrendsText(output TrendsOutput) error {
+	snapshots := output.Snapshots
+	if len(snapshots) == 0 {
+		fmt.Println("No metrics recorded yet; run gcq warm to record a snapshot.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BUILD\tUNITS\tFILES\tMEAN CX\tHIGH CX\tMAX CX\tDEAD CODE")
+	for i, s := range snapshots {
+		fmt.Fprint(w, s.Timestamp.Local().Format("2006-01-02 15:04"))
+		for _, metric := range trendMetrics {
+			value := fmt.Sprintf(metric.format, metric.value(s))
+			if i > 0 {
+				value += formatDelta(metric.value(s)-metric.value(snapshots[i-1]), metric.format)
+			}
+			fmt.Fprintf(w, "\t%s", value)
+		}
+		fmt.Fprintln(w)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(snapshots) < 2 {
+		return nil
+	}
+
+	fmt.Printf("\nOver the last %d builds:\n", len(snapshots))
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, metric := range trendMetrics {
+		values := make([]float64, len(snapshots))
+		for i, s := range snapshots {
+			values[i] = metric.value(s)
+		}
+		first, latest := values[0], values[len(values)-1]
+		fmt.Fprintf(w, "  %s\t%s\t"+metric.format+" -> "+metric.format+"%s\n",
+			metric.name, sparkline(values), first, latest, formatDelta(latest-first, metric.format))
+	}
+	return w.Flush()
+}
+
+// formatDelta renders a change as " (+3)", or nothing when it is zero
+func formatDelta(delta float64, format string) string {
+	rendered := fmt.Sprintf(format, delta)
+	if rendered == fmt.Sprintf(format, 0.0) || rendered == fmt.Sprintf(format, -0.0) {
+		return ""
+	}
+	if delta > 0 {
+		rendered = "+" + rendered
+	}
+	return " (" + rendered + ")"
+}
+
+// sparkBlocks are the bar glyphs of a sparkline, lowest first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline plots values as a row of bars scaled between their minimum
+// and maximum
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo = min(lo, v)
+		hi = max(hi, v)
+	}
+
+	runes := make([]rune, len(values))
+	for i, v := range values {
+		level := 0
+		if hi > lo {
+			level = int((v - lo) / (hi - lo) * float64(len(sparkBlocks)-1))
+		}
+		runes[i] = sparkBlocks[level]
+	}
+	return string(runes)
+}
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/l3aro/go-context-query/pkg/extractor"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// resolveUnitURI resolves a canonical unit URI such as "py://pkg/mod.py#Class.method"
+// to the file defining the unit and the bare function name used by the call
+// graph and slicer. Go URIs address a package directory, so the files in that
+// directory are searched for the symbol.
+func resolveUnitURI(arg string) (string, string, error) {
+	u, err := types.ParseUnitURI(arg)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Symbol == "" {
+		return "", "", fmt.Errorf("unit URI has no symbol: %s", arg)
+	}
+
+	funcName := u.Symbol
+	if i := strings.LastIndex(funcName, "."); i >= 0 {
+		funcName = funcName[i+1:]
+	}
+
+	path := filepath.FromSlash(u.Path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", "", fmt.Errorf("resolving %s: %w", arg, err)
+	}
+	if !info.IsDir() {
+		return path, funcName, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return "", "", fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	registry := extractor.GetLanguageRegistry()
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		file := filepath.Join(path, entry.Name())
+		if lang, err := registry.GetLanguage(file); err != nil || string(lang) != u.Language() {
+			continue
+		}
+
+		moduleInfo, err := extractor.ExtractFile(file)
+		if err != nil {
+			continue
+		}
+		if moduleDefines(moduleInfo, u.Symbol) {
+			return file, funcName, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("symbol %s not found in %s", u.Symbol, path)
+}
+
+// moduleDefines reports whether a module defines the given qualified symbol.
+func moduleDefines(m *types.ModuleInfo, symbol string) bool {
+	receiver, name := "", symbol
+	if i := strings.LastIndex(symbol, "."); i >= 0 {
+		receiver, name = symbol[:i], symbol[i+1:]
+	}
+
+	for _, fn := range m.Functions {
+		// Go methods are extracted as functions flagged IsMethod, with
+		// their receiver type
+		if fn.Name == name && (receiver == "" || fn.IsMethod && (fn.Receiver == "" || fn.Receiver == receiver)) {
+			return true
+		}
+	}
+	for _, cls := range m.Classes {
+		if receiver == "" && cls.Name == name {
+			return true
+		}
+		if cls.Name != receiver {
+			continue
+		}
+		for _, method := range cls.Methods {
+			if method.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
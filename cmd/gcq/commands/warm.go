@@ -4,7 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/l3aro/go-context-query/internal/config"
 	"github.com/l3aro/go-context-query/internal/daemon"
@@ -12,6 +15,7 @@ import (
 	"github.com/l3aro/go-context-query/pkg/embed"
 	"github.com/l3aro/go-context-query/pkg/semantic"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // WarmOutput represents the output of the warm command
@@ -25,6 +29,12 @@ type WarmOutput struct {
 	Message       string   `json:"message"`
 	Languages     []string `json:"languages,omitempty"`
 	ProcessedLang string   `json:"processed_lang,omitempty"`
+	// Remaining counts the units a --budget warm left for the background
+	Remaining     int    `json:"remaining,omitempty"`
+	BackgroundLog string `json:"background_log,omitempty"`
+	// ChangedFiles and ReusedUnits report an update of the index
+	ChangedFiles int `json:"changed_files,omitempty"`
+	ReusedUnits  int `json:"reused_units,omitempty"`
 }
 
 // supportedLanguages returns the list of supported languages for indexing
@@ -43,17 +53,62 @@ func supportedLanguages() []string {
 		"swift",
 		"kotlin",
 		"csharp",
+		"vue",
+		"svelte",
 	}
 }
 
 // warmCmd represents the warm command
 var warmCmd = &cobra.Command{
-	Use:   "warm [path]",
-	Short: "Build semantic index for a project",
+	Use:     "warm [path]",
+	Aliases: []string{"build"},
+	Short:   "Build semantic index for a project",
 	Long: `Scans the project, extracts code units, generates embeddings,
-and builds a searchable semantic index.`,
+and builds a searchable semantic index.
+
+--import also indexes the functions, methods and types in a ctags tags
+file, LSIF dump or SCIP index, for languages gcq does not parse natively.
+Dumps listed under index.imports in the config are imported too.
+
+--budget time-boxes the build for a project you just cloned: the files and
+units most likely to matter (source before tests, shallow before deeply
+nested, widely called before rarely called) are embedded first, and when
+the budget runs out the partial index is saved so searches work right away.
+A full warm then continues in the background, reusing the embeddings
+already computed, and logs to .gcq/cache/warm.log. Scanning and extraction
+always run to completion, so very large projects can overrun the budget.
+
+A warm updates the index instead of rebuilding it when the index was
+built with the same model and settings: the content hash of each file is
+saved with the index, only files whose content or calls changed are
+extracted again, and units whose embedding text is unchanged keep their
+vectors. --force extracts and embeds every file.
+
+--since picks the files to update from git instead: only the files
+reported changed since the given commit or branch, committed or not, are
+extracted and embedded, which makes refreshing an index in CI fast. Calls
+and callers stored with units of other files are not refreshed. Without a
+complete index built with the same model and settings to update, every
+file is indexed.
+
+--file and --unit re-extract and re-embed just one file, or one unit and
+its body chunks, right away, without scanning the project: useful after
+editing a hot file. Both are repeatable and need a complete index built
+with the same model and settings. Calls are resolved among the targets
+only; calls to and from other files are kept from the index, and the next
+warm extracts the targets again.
+
+Examples:
+  gcq warm
+  gcq warm --budget 60s
+  gcq build --since origin/main
+  gcq warm --file pkg/mod.py
+  gcq warm --unit 'pkg/mod.py#func'
+  gcq warm --import index.scip ./your-project`,
 	Args: cobra.RangeArgs(0, 1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		installLanguagePacks()
+
 		path := "."
 		if len(args) > 0 {
 			path = args[0]
@@ -107,6 +162,47 @@ and builds a searchable semantic index.`,
 	},
 }
 
+// warmTargets resolves --file paths to paths relative to the project root
+// and --unit references to the URIs of units in the active index
+func warmTargets(cmd *cobra.Command, rootDir string) ([]string, []string, error) {
+	fileFlags, _ := cmd.Flags().GetStringArray("file")
+	unitFlags, _ := cmd.Flags().GetStringArray("unit")
+
+	var files []string
+	for _, f := range fileFlags {
+		abs, err := filepath.Abs(f)
+		if err != nil {
+			return nil, nil, fmt.Errorf("resolving %s: %w", f, err)
+		}
+		rel, err := filepath.Rel(rootDir, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, nil, fmt.Errorf("%s is outside the project %s", f, rootDir)
+		}
+		files = append(files, filepath.ToSlash(rel))
+	}
+	if len(unitFlags) == 0 {
+		return files, nil, nil
+	}
+
+	vecIndex, _, err := semantic.LoadIndex(rootDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading index: %w", err)
+	}
+	ids, err := semantic.LoadUnitIDMap(rootDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	var units []string
+	for _, ref := range unitFlags {
+		id, err := resolveIndexUnit(vecIndex, ids, ref)
+		if err != nil {
+			return nil, nil, err
+		}
+		units = append(units, id)
+	}
+	return files, units, nil
+}
+
 func runWarmViaDaemon(path string, cmd *cobra.Command, langFlag string, forceFlag bool, tracker *dirty.Tracker) error {
 	// TODO: Implement daemon-based semantic indexing
 	// For now, fall back to local
@@ -166,12 +262,84 @@ func runWarmLocally(path string, cmd *cobra.Command, langFlag string, forceFlag
 		return fmt.Errorf("warm provider not initialized")
 	}
 
+	templates, err := semantic.ParseEmbeddingTemplates(cfg.Embedding.Templates)
+	if err != nil {
+		return fmt.Errorf("loading embedding templates: %w", err)
+	}
+
+	// Symbol dumps from the config are relative to the project root
+	imports, _ := cmd.Flags().GetStringArray("import")
+	for _, dump := range cfg.Index.Imports {
+		if !filepath.IsAbs(dump) {
+			dump = filepath.Join(rootDir, dump)
+		}
+		imports = append(imports, dump)
+	}
+
+	budget, _ := cmd.Flags().GetDuration("budget")
+	if budget < 0 {
+		return fmt.Errorf("budget must not be negative")
+	}
+	since, _ := cmd.Flags().GetString("since")
+	if since != "" && budget > 0 {
+		return fmt.Errorf("--since and --budget cannot be combined")
+	}
+	if since != "" && forceFlag {
+		return fmt.Errorf("--since and --force cannot be combined")
+	}
+	files, units, err := warmTargets(cmd, rootDir)
+	if err != nil {
+		return err
+	}
+	if len(files)+len(units) > 0 && (since != "" || budget > 0 || forceFlag) {
+		return fmt.Errorf("--file and --unit cannot be combined with --since, --budget or --force")
+	}
+
+	enrichers, err := indexEnrichers(cfg)
+	if err != nil {
+		return err
+	}
+
 	// Build the index
-	err = semantic.BuildIndex(rootDir, provider)
+	stats, err := semantic.BuildIndexWithStats(rootDir, provider, semantic.BuildOptions{
+		Templates: templates,
+		Limits: semantic.EmbeddingLimits{
+			Dependencies:  cfg.Limits.Dependencies,
+			CallListChars: cfg.Limits.CallListChars,
+		},
+		GraphContext: semantic.GraphContext{
+			Callees:      cfg.Embedding.CalleeSummaries,
+			SummaryChars: cfg.Embedding.CalleeSummaryChars,
+		},
+		Backend:      indexBackendOptions(cfg),
+		Imports:      imports,
+		Chunks:       chunkOptions(cfg),
+		StableIDs:    cfg.Index.StableIDs,
+		Budget:       budget,
+		Enrichers:    enrichers,
+		Todos:        cfg.Index.Todos,
+		Commits:      commitOptions(cfg),
+		Docs:         cfg.Index.Docs,
+		Dependencies: dependencyFilter(cfg),
+		StopSymbols:  cfg.CallGraph.StopSymbols,
+		Since:        since,
+		Full:         forceFlag,
+		Files:        files,
+		Units:        units,
+	})
 	if err != nil {
 		return fmt.Errorf("building index: %w", err)
 	}
 
+	// Index what the budget left out in the background
+	var backgroundLog string
+	if stats.Remaining > 0 {
+		backgroundLog, err = warmInBackground(cmd, rootDir)
+		if err != nil {
+			fmt.Printf("Warning: could not start a background warm for the remaining units: %v\n", err)
+		}
+	}
+
 	// Try to load the index
 	vecIndex, metadata, err := semantic.LoadIndex(rootDir)
 	if err != nil {
@@ -203,10 +371,26 @@ func runWarmLocally(path string, cmd *cobra.Command, langFlag string, forceFlag
 			UnitsCount:    vecIndex.Count(),
 			Dimension:     vecIndex.Dimension(),
 			Model:         metadata.WarmModel,
-			CacheDir:      filepath.Join(rootDir, ".gcq", "cache", "semantic"),
+			CacheDir:      semantic.ActiveIndexDir(rootDir),
 			Message:       fmt.Sprintf("Indexed %d code units", vecIndex.Count()),
 			ProcessedLang: processedLang,
 			Languages:     supportedLanguages(),
+			Remaining:     stats.Remaining,
+			BackgroundLog: backgroundLog,
+			ChangedFiles:  stats.Changed,
+			ReusedUnits:   stats.Reused,
+		}
+		switch {
+		case len(files)+len(units) > 0:
+			output.Message = fmt.Sprintf("Indexed %d code units: re-indexed %s, kept %d other units",
+				vecIndex.Count(), strings.Join(slices.Concat(files, units), ", "), stats.Reused)
+		case stats.Changed > 0 || stats.Reused > 0:
+			output.Message = fmt.Sprintf("Indexed %d code units: re-indexed %d changed files, kept %d units of unchanged files",
+				vecIndex.Count(), stats.Changed, stats.Reused)
+		}
+		if backgroundLog != "" {
+			output.Message = fmt.Sprintf("Indexed %d code units within the budget; the other %d are being indexed in the background (log: %s)",
+				vecIndex.Count(), stats.Remaining, backgroundLog)
 		}
 	} else {
 		processedLang := langFlag
@@ -224,6 +408,11 @@ func runWarmLocally(path string, cmd *cobra.Command, langFlag string, forceFlag
 
 	printWarmOutput(output, cmd)
 
+	// The background warm clears dirty flags once the index is complete
+	if stats.Remaining > 0 {
+		return nil
+	}
+
 	// Clear dirty flags after successful warm
 	tracker.ClearDirty(nil)
 	if err := tracker.Save(); err != nil {
@@ -233,6 +422,47 @@ func runWarmLocally(path string, cmd *cobra.Command, langFlag string, forceFlag
 	return nil
 }
 
+// warmInBackground starts a full warm of rootDir in a detached gcq process,
+// with this command's flags minus --budget and --json, to index the units a
+// budgeted warm left out. It returns the log the process writes to.
+func warmInBackground(cmd *cobra.Command, rootDir string) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("finding gcq executable: %w", err)
+	}
+
+	args := []string{"warm", rootDir}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		switch f.Name {
+		case "budget", "json":
+			return
+		}
+		if values, ok := f.Value.(pflag.SliceValue); ok {
+			for _, v := range values.GetSlice() {
+				args = append(args, "--"+f.Name+"="+v)
+			}
+			return
+		}
+		args = append(args, "--"+f.Name+"="+f.Value.String())
+	})
+
+	logPath := filepath.Join(rootDir, ".gcq", "cache", "warm.log")
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return "", fmt.Errorf("creating warm log: %w", err)
+	}
+	defer logFile.Close()
+
+	background := exec.Command(exe, args...)
+	background.Stdout = logFile
+	background.Stderr = logFile
+	detach(background)
+	if err := background.Start(); err != nil {
+		return "", fmt.Errorf("starting background warm: %w", err)
+	}
+	return logPath, background.Process.Release()
+}
+
 func printWarmOutput(output WarmOutput, cmd *cobra.Command) {
 	jsonOutput, _ := cmd.Flags().GetBool("json")
 	if jsonOutput {
@@ -271,8 +501,13 @@ func init() {
 	warmCmd.Flags().BoolP("json", "j", false, "Output as JSON")
 	warmCmd.Flags().StringP("provider", "p", "", "Embedding provider for backward compatibility (use --warm-provider for separate warm provider)")
 	warmCmd.Flags().StringP("model", "m", "", "Embedding model name for backward compatibility (use --warm-model for separate warm model)")
-	warmCmd.Flags().String("warm-provider", "", "Embedding provider for indexing (ollama or huggingface). Overrides --provider")
+	warmCmd.Flags().String("warm-provider", "", "Embedding provider for indexing (ollama, huggingface, local or mock). Overrides --provider")
 	warmCmd.Flags().String("warm-model", "", "Embedding model name for indexing. Overrides --model")
-	warmCmd.Flags().StringP("language", "l", "", "Language to index (auto-detects all by default). Supported: python, go, typescript, javascript, java, rust, c, cpp, ruby, php, swift, kotlin, csharp")
-	warmCmd.Flags().BoolP("force", "f", false, "Force full rebuild, ignoring dirty tracking")
+	warmCmd.Flags().StringP("language", "l", "", "Language to index (auto-detects all by default). Supported: python, go, typescript, javascript, java, rust, c, cpp, ruby, php, swift, kotlin, csharp, vue, svelte")
+	warmCmd.Flags().BoolP("force", "f", false, "Force full rebuild, re-extracting unchanged files")
+	warmCmd.Flags().StringArray("import", nil, "Also index the definitions in a ctags, LSIF or SCIP dump (repeatable)")
+	warmCmd.Flags().Duration("budget", 0, "Index the most useful units within this time (e.g. 60s) and the rest in the background")
+	warmCmd.Flags().StringArray("file", nil, "Re-index only this file, without scanning the project (repeatable)")
+	warmCmd.Flags().StringArray("unit", nil, "Re-index only this unit, by URI, path#symbol or name, without scanning the project (repeatable)")
+	warmCmd.Flags().String("since", "", "Update the index with only the files git reports changed since this commit or branch")
 }
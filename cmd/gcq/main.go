@@ -4,17 +4,22 @@
 package main
 
 import (
+	"context"
 	"crypto/md5"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"text/tabwriter"
 	"time"
 
 	"github.com/l3aro/go-context-query/cmd/gcq/commands"
 	"github.com/l3aro/go-context-query/internal/config"
 	"github.com/l3aro/go-context-query/internal/daemon"
 	"github.com/l3aro/go-context-query/pkg/embed"
+	"github.com/l3aro/go-context-query/pkg/index"
 	"github.com/l3aro/go-context-query/pkg/semantic"
 	"github.com/spf13/cobra"
 )
@@ -35,6 +40,19 @@ func computeSocketPath(projectPath string) string {
 	return fmt.Sprintf("/tmp/gcq-%x.sock", hash[:8])
 }
 
+// selectProjectDaemon points lifecycle commands at the daemon for --project
+// unless --instance already selected one.
+func selectProjectDaemon(cmd *cobra.Command) {
+	if instance, _ := cmd.Flags().GetString("instance"); instance != "" {
+		return
+	}
+	projectPath, _ := cmd.Flags().GetString("project")
+	if projectPath == "" {
+		projectPath = "."
+	}
+	os.Setenv("GCQ_SOCKET_PATH", computeSocketPath(projectPath))
+}
+
 func main() {
 	// Add build command
 	buildCmd := &cobra.Command{
@@ -48,7 +66,7 @@ func main() {
 		},
 	}
 	buildCmd.Flags().String("project", ".", "Project directory to index")
-	buildCmd.Flags().String("provider", "ollama", "Embedding provider (ollama or huggingface)")
+	buildCmd.Flags().String("provider", "ollama", "Embedding provider (ollama, huggingface, local or mock)")
 	buildCmd.Flags().String("model", "", "Embedding model name")
 
 	// Add start command
@@ -89,11 +107,7 @@ uses the current working directory.`,
 		Use:   "stop",
 		Short: "Stop daemon",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			projectPath, _ := cmd.Flags().GetString("project")
-			if projectPath == "" {
-				projectPath = "."
-			}
-			os.Setenv("GCQ_SOCKET_PATH", computeSocketPath(projectPath))
+			selectProjectDaemon(cmd)
 			return runStop()
 		},
 	}
@@ -105,22 +119,39 @@ uses the current working directory.`,
 		Short: "Show daemon status",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			jsonOutput, _ := cmd.Flags().GetBool("json")
-			projectPath, _ := cmd.Flags().GetString("project")
-			if projectPath == "" {
-				projectPath = "."
+			all, _ := cmd.Flags().GetBool("all")
+			if all {
+				return runStatusAll(jsonOutput)
 			}
-			os.Setenv("GCQ_SOCKET_PATH", computeSocketPath(projectPath))
+			selectProjectDaemon(cmd)
 			return runStatus(jsonOutput)
 		},
 	}
 	statusCmd.Flags().BoolP("json", "j", false, "Output as JSON")
 	statusCmd.Flags().StringP("project", "p", "", "Project path (default: current directory)")
+	statusCmd.Flags().BoolP("all", "a", false, "List all registered daemon instances")
+
+	// Add logs command
+	logsCmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Show background daemon logs",
+		Long: `Show the output of a daemon started with gcq start -d. Output is written
+to .gcq/logs/daemon.log and rotated when the daemon starts.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			follow, _ := cmd.Flags().GetBool("follow")
+			lines, _ := cmd.Flags().GetInt("lines")
+			return runLogs(follow, lines)
+		},
+	}
+	logsCmd.Flags().BoolP("follow", "f", false, "Keep printing new log output")
+	logsCmd.Flags().IntP("lines", "n", 100, "Number of lines to show (0 for all)")
 
 	// Add all commands to root
 	commands.RootCmd.AddCommand(buildCmd)
 	commands.RootCmd.AddCommand(startCmd)
 	commands.RootCmd.AddCommand(stopCmd)
 	commands.RootCmd.AddCommand(statusCmd)
+	commands.RootCmd.AddCommand(logsCmd)
 
 	commands.RootCmd.Flags().BoolP("version", "v", false, "Print version information")
 	commands.RootCmd.SetVersionTemplate(`gcq version {{.Version}}
@@ -176,11 +207,77 @@ func runBuild(projectPath, providerType, modelName string) error {
 		if err != nil {
 			return fmt.Errorf("creating HuggingFace provider: %w", err)
 		}
+	case "local":
+		model := modelName
+		if model == "" {
+			model = cfg.Warm.Model
+		}
+		provider, err = embed.NewLocalProviderFromConfig(&embed.Config{
+			Model:    model,
+			Endpoint: cfg.Warm.BaseURL,
+		}, cfg)
+		if err != nil {
+			return fmt.Errorf("creating local provider: %w", err)
+		}
+	case "mock":
+		provider, err = embed.NewMockProviderFromConfig(cfg)
+		if err != nil {
+			return fmt.Errorf("creating mock provider: %w", err)
+		}
 	default:
-		return fmt.Errorf("unknown provider: %s (use 'ollama' or 'huggingface')", providerType)
+		return fmt.Errorf("unknown provider: %s (use 'ollama', 'huggingface', 'local' or 'mock')", providerType)
 	}
 
-	return semantic.BuildIndex(projectPath, provider)
+	var chunks semantic.ChunkOptions
+	if cfg.Embedding.ChunkBodies {
+		chunks = semantic.ChunkOptions{Size: cfg.ChunkSize, Overlap: cfg.ChunkOverlap}
+	}
+	var enrichers []semantic.Enricher
+	for _, e := range cfg.Index.Enrichers {
+		enricher, err := semantic.NewPatternEnricher(e.Key, e.Pattern)
+		if err != nil {
+			return err
+		}
+		enrichers = append(enrichers, enricher)
+	}
+
+	return semantic.BuildIndexWithOptions(projectPath, provider, semantic.BuildOptions{
+		Limits: semantic.EmbeddingLimits{
+			Dependencies:  cfg.Limits.Dependencies,
+			CallListChars: cfg.Limits.CallListChars,
+		},
+		GraphContext: semantic.GraphContext{
+			Callees:      cfg.Embedding.CalleeSummaries,
+			SummaryChars: cfg.Embedding.CalleeSummaryChars,
+		},
+		Backend: index.BackendOptions{
+			Kind:          cfg.Index.Backend,
+			AutoThreshold: cfg.Index.HNSWThreshold,
+			HNSW: index.HNSWOptions{
+				M:              cfg.Index.HNSWM,
+				EfConstruction: cfg.Index.HNSWEfConstruction,
+				EfSearch:       cfg.Index.HNSWEfSearch,
+			},
+			Quantization: cfg.Index.Quantization,
+		},
+		Chunks:    chunks,
+		StableIDs: cfg.Index.StableIDs,
+		Enrichers: enrichers,
+		Todos:     cfg.Index.Todos,
+		Commits: semantic.CommitOptions{
+			Enabled:     cfg.Index.Commits.Enabled,
+			Limit:       cfg.Index.Commits.Limit,
+			GitHubToken: cfg.Index.Commits.GitHubToken,
+			GitHubRepo:  cfg.Index.Commits.GitHubRepo,
+			GitHubAPI:   cfg.Index.Commits.GitHubAPI,
+		},
+		Docs: cfg.Index.Docs,
+		Dependencies: semantic.DependencyFilter{
+			Stdlib: cfg.Index.Dependencies.Stdlib,
+			Ignore: cfg.Index.Dependencies.Ignore,
+		},
+		StopSymbols: cfg.CallGraph.StopSymbols,
+	})
 }
 
 func runStart(daemonPath, socketPath, projectPath, configPath string, verbose, background bool) error {
@@ -214,9 +311,84 @@ func runStart(daemonPath, socketPath, projectPath, configPath string, verbose, b
 	}
 
 	fmt.Printf("Daemon started with PID %d\n", result.PID)
+	if result.LogPath != "" {
+		fmt.Printf("Logs: %s\n", result.LogPath)
+	}
 	return nil
 }
 
+func runStatusAll(jsonOutput bool) error {
+	instances, err := daemon.ListInstances()
+	if err != nil {
+		return err
+	}
+
+	type instanceStatus struct {
+		*daemon.Instance
+		Status  string `json:"status"`
+		Version string `json:"version,omitempty"`
+		Error   string `json:"error,omitempty"`
+	}
+	statuses := make([]instanceStatus, 0, len(instances))
+	for _, inst := range instances {
+		st := daemon.InstanceStatus(inst)
+		entry := instanceStatus{Instance: inst, Status: "running", Version: st.Version, Error: st.Error}
+		if !st.Ready {
+			entry.Status = "starting"
+		}
+		statuses = append(statuses, entry)
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(statuses, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(statuses) == 0 {
+		fmt.Println("No daemon instances running")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "INSTANCE\tSTATUS\tPID\tPROJECT\tSOCKET")
+	for _, st := range statuses {
+		address := st.SocketPath
+		if st.TCPPort != "" {
+			address = "localhost:" + st.TCPPort
+		}
+		project := st.ProjectPath
+		if project == "" {
+			project = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", st.Name, st.Status, st.PID, project, address)
+	}
+	return w.Flush()
+}
+
+func runLogs(follow bool, lines int) error {
+	path := daemon.LogFile()
+	offset, err := daemon.TailLog(os.Stdout, path, lines)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if !follow {
+			return fmt.Errorf("no daemon logs at %s (start the daemon with gcq start -d)", path)
+		}
+	}
+	if !follow {
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	return daemon.FollowLog(ctx, os.Stdout, path, offset)
+}
+
 func runStop() error {
 	result, err := daemon.Stop()
 	if err != nil {
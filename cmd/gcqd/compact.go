@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/l3aro/go-context-query/pkg/index"
+)
+
+// compactResult reports the compaction of one project's index
+type compactResult struct {
+	Root      string `json:"root"`
+	Reclaimed int    `json:"reclaimed"`
+	Count     int    `json:"count"`
+}
+
+// compactionPolicy returns the thresholds of daemon.compact_min_removed
+// and daemon.compact_ratio, with defaults for unset ones
+func (d *Daemon) compactionPolicy() index.CompactionPolicy {
+	policy := index.DefaultCompactionPolicy()
+	if n := d.config.Daemon.CompactMinRemoved; n > 0 {
+		policy.MinRemoved = n
+	}
+	if r := d.config.Daemon.CompactRatio; r > 0 {
+		policy.Ratio = r
+	}
+	return policy
+}
+
+// runCompactLoop periodically compacts the project indexes whose removed
+// entries pass the compaction thresholds, while the daemon is idle. Files
+// re-indexed by the watcher, refresh or warm leave their old entries
+// behind as tombstones until then.
+func (d *Daemon) runCompactLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("Background compaction enabled every %s", interval)
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			d.mu.Lock()
+			if time.Since(d.lastActivity) >= refreshIdleThreshold {
+				policy := d.compactionPolicy()
+				for _, p := range d.projectList() {
+					if p.reindexInProgress || p.indexing > 0 {
+						continue
+					}
+					if reclaimed := p.compact(policy); reclaimed > 0 {
+						log.Printf("Compacted index of %s: reclaimed %d removed entries, %d live", p.displayRoot(), reclaimed, p.index.Count())
+					}
+				}
+			}
+			d.mu.Unlock()
+		}
+	}
+}
+
+// compactProjects compacts the index of the named project, or of every
+// open project when name is empty, whatever the thresholds
+func (d *Daemon) compactProjects(name string) ([]compactResult, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	projects := d.projectList()
+	if name != "" {
+		root, err := filepath.Abs(name)
+		if err != nil {
+			return nil, fmt.Errorf("resolving project: %w", err)
+		}
+		p, ok := d.projects[root]
+		if !ok {
+			return nil, fmt.Errorf("project %s is not open", root)
+		}
+		projects = []*project{p}
+	}
+
+	results := make([]compactResult, 0, len(projects))
+	for _, p := range projects {
+		reclaimed := p.index.Compact()
+		if reclaimed > 0 {
+			log.Printf("Compacted index of %s: reclaimed %d removed entries, %d live", p.displayRoot(), reclaimed, p.index.Count())
+		}
+		results = append(results, compactResult{Root: p.displayRoot(), Reclaimed: reclaimed, Count: p.index.Count()})
+	}
+	return results, nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/l3aro/go-context-query/pkg/embed"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// providerDegradation records the embedding provider's switch to its
+// fallback, whose vectors may not match an index built by the local runtime
+func (d *Daemon) providerDegradation(ds *types.Degradations) {
+	if model, ok := embed.FallbackModel(d.embedder); ok {
+		ds.Add(types.DegradedProviderFallback, fmt.Sprintf("local embedding runtime unreachable; embedding with %s", model), 0)
+	}
+}
+
+// dirtyDegradation records the files of p changed since they were indexed
+// and not re-indexed yet. Callers must hold d.mu.
+func dirtyDegradation(p *project, ds *types.Degradations) {
+	if n := len(p.dirtyFiles); n > 0 {
+		ds.Add(types.DegradedStaleIndex, "files changed since they were indexed; re-index pending", n)
+	}
+}
+
+// fileIndexDegradations returns the degradations of a result from p's
+// file-level index
+func (d *Daemon) fileIndexDegradations(p *project) types.Degradations {
+	var ds types.Degradations
+	d.mu.RLock()
+	dirtyDegradation(p, &ds)
+	d.mu.RUnlock()
+	d.providerDegradation(&ds)
+	return ds
+}
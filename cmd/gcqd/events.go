@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/l3aro/go-context-query/internal/webhook"
+)
+
+// providerAlertCooldown is the minimum time between provider.failed events,
+// so an outage during a large warm sends one alert rather than one per file
+const providerAlertCooldown = 5 * time.Minute
+
+// webhookShutdownTimeout bounds how long Stop waits for pending webhooks
+const webhookShutdownTimeout = 5 * time.Second
+
+// errEmbedding marks re-indexing errors caused by the embedding provider
+var errEmbedding = errors.New("embedding")
+
+// providerAlerts throttles provider.failed events. It has its own lock
+// because failures are reported while Daemon.mu is held.
+type providerAlerts struct {
+	mu   sync.Mutex
+	last time.Time
+}
+
+// notify sends an event to the configured webhooks. p may be nil for
+// events that don't belong to one project.
+func (d *Daemon) notify(eventType string, p *project, text string, data map[string]any) {
+	if !d.webhooks.Enabled(eventType) {
+		return
+	}
+	ev := webhook.Event{Type: eventType, Text: "gcqd: " + text, Data: data}
+	if p != nil {
+		ev.Project = p.displayRoot()
+	}
+	d.webhooks.Notify(ev)
+}
+
+// providerFailed reports embedding failures during operation (warm, reindex,
+// refresh, watch or warmup). err is the last failure seen. Refusals by a
+// project quota are not provider failures and aren't reported.
+func (d *Daemon) providerFailed(operation string, p *project, failures int, err error) {
+	if failures == 0 || errors.Is(err, errQuotaExceeded) || !d.webhooks.Enabled(webhook.EventProviderFailed) {
+		return
+	}
+
+	d.providerAlerts.mu.Lock()
+	if time.Since(d.providerAlerts.last) < providerAlertCooldown {
+		d.providerAlerts.mu.Unlock()
+		return
+	}
+	d.providerAlerts.last = time.Now()
+	d.providerAlerts.mu.Unlock()
+
+	data := map[string]any{
+		"operation": operation,
+		"failures":  failures,
+		"error":     err.Error(),
+	}
+	if cfg := d.embedder.Config(); cfg != nil {
+		data["model"] = cfg.Model
+		data["endpoint"] = cfg.Endpoint
+	}
+	d.notify(webhook.EventProviderFailed, p,
+		fmt.Sprintf("embedding provider failed %d times during %s: %v", failures, operation, err), data)
+}
+
+// finishJob reports a finished background job: a dirty-file reindex or a
+// scheduled refresh
+func (d *Daemon) finishJob(job string, p *project, start time.Time, files, reindexed int) {
+	duration := time.Since(start)
+	d.notify(webhook.EventJobFinished, p,
+		fmt.Sprintf("%s of %s finished: %d of %d files re-indexed in %s", job, p.displayRoot(), reindexed, files, duration.Round(time.Millisecond)),
+		map[string]any{
+			"job":         job,
+			"files":       files,
+			"reindexed":   reindexed,
+			"duration_ms": duration.Milliseconds(),
+		})
+}
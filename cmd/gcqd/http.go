@@ -0,0 +1,218 @@
+package main
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// openAPISpec describes the HTTP API; it is served at /openapi.yaml
+//
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// maxHTTPBodySize bounds the JSON parameters of one HTTP request
+const maxHTTPBodySize = 1 << 20
+
+// httpShutdownTimeout bounds how long Stop waits for HTTP requests in flight
+const httpShutdownTimeout = 5 * time.Second
+
+// httpRoutes maps HTTP API routes to the socket commands they run. Requests
+// carry the command's params as a JSON body and get its result back.
+var httpRoutes = map[string]string{
+	"GET /status":      "status",
+	"POST /search":     "search",
+	"POST /context":    "context",
+	"POST /calls":      "calls",
+	"POST /hover":      "hover",
+	"POST /extract":    "extract",
+	"POST /job_status": "job_status",
+	"POST /job_cancel": "job_cancel",
+}
+
+// startHTTPServer serves the HTTP API on addr in the background. Listening
+// errors are returned; later serve errors are logged.
+func (d *Daemon) startHTTPServer(addr string) (*http.Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("listening on %s: %w", addr, err)
+	}
+
+	srv := &http.Server{
+		Handler:           d.httpHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("HTTP server error: %v", err)
+		}
+	}()
+
+	log.Printf("Started HTTP server on %s", listener.Addr())
+	return srv, nil
+}
+
+// httpHandler routes the HTTP API to the daemon's command handlers
+func (d *Daemon) httpHandler() http.Handler {
+	mux := http.NewServeMux()
+	for route, cmdType := range httpRoutes {
+		mux.HandleFunc(route, d.httpCommand(cmdType))
+	}
+	mux.HandleFunc("GET /openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write(openAPISpec)
+	})
+	return d.withHostCheck(d.withCORS(mux))
+}
+
+// httpCommand runs cmdType with the request body as its params. Command
+// errors become 400 responses with an {"error": ...} body. POST bodies
+// must be sent as application/json, which a cross-site form cannot do
+// without a CORS preflight.
+func (d *Daemon) httpCommand(cmdType string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cmd := Command{Type: cmdType, Params: json.RawMessage("{}")}
+		if r.Method == http.MethodPost {
+			mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil || mediaType != "application/json" {
+				writeHTTPError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+				return
+			}
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxHTTPBodySize))
+			if err != nil {
+				writeHTTPError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("reading body: %v", err))
+				return
+			}
+			if len(body) > 0 {
+				if !json.Valid(body) {
+					writeHTTPError(w, http.StatusBadRequest, "body is not valid JSON")
+					return
+				}
+				cmd.Params = body
+			}
+		}
+
+		// A client that hangs up cancels the command's provider requests
+		ctx, cancel := d.commandContext(r.Context(), cmd)
+		resp := d.handleCommand(ctx, cmd)
+		cancel()
+		if resp.Error != "" {
+			writeHTTPError(w, http.StatusBadRequest, resp.Error)
+			return
+		}
+		if resp.Generation != 0 {
+			// Clients polling the same query revalidate instead of
+			// downloading the same results again
+			etag := resultETag(resp.Generation, resp.Result)
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Cache-Control", "no-cache")
+			if etagMatches(r.Header.Get("If-None-Match"), etag) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(resp.Result)
+	}
+}
+
+// resultETag returns the entity tag of a result read from the index at
+// generation. The generation changes on every index update and the hash
+// tells apart results of different params, or partial ones.
+func resultETag(generation uint64, result []byte) string {
+	sum := sha256.Sum256(result)
+	return fmt.Sprintf(`"%d-%s"`, generation, hex.EncodeToString(sum[:8]))
+}
+
+// etagMatches reports whether an If-None-Match header lists etag. Weak
+// tags match their strong form, as If-None-Match compares them weakly.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// withCORS lets the origins in daemon.http_allowed_origins call the API from
+// a browser and answers their preflight requests. Requests from any other
+// origin are refused, so a page the user happens to visit cannot reach the
+// daemon even with a request that needs no preflight.
+func (d *Daemon) withCORS(next http.Handler) http.Handler {
+	allowed := d.config.Daemon.HTTPAllowedOrigins
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !slices.Contains(allowed, "*") && !slices.Contains(allowed, origin) {
+			writeHTTPError(w, http.StatusForbidden, fmt.Sprintf("origin %s is not allowed", origin))
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag")
+		if r.Method == http.MethodOptions {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, If-None-Match")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withHostCheck refuses requests whose Host is not the host of
+// daemon.http_addr, a loopback name or an IP address. A site that rebinds
+// its own name to 127.0.0.1 still sends that name, so it cannot use the
+// visitor's browser to reach the daemon.
+func (d *Daemon) withHostCheck(next http.Handler) http.Handler {
+	listenHost, _, _ := net.SplitHostPort(d.config.Daemon.HTTPAddr)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowedHost(r.Host, listenHost) {
+			writeHTTPError(w, http.StatusForbidden, fmt.Sprintf("host %s is not allowed", r.Host))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost reports whether the Host header hostPort names the daemon:
+// listenHost, localhost or an IP address, with or without a port
+func allowedHost(hostPort, listenHost string) bool {
+	host, _, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		host = hostPort
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if host == "" {
+		return false
+	}
+	if net.ParseIP(host) != nil || strings.EqualFold(host, "localhost") {
+		return true
+	}
+	return listenHost != "" && strings.EqualFold(host, listenHost)
+}
+
+func writeHTTPError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEtagMatches(t *testing.T) {
+	const etag = `"7-0123456789abcdef"`
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{"empty", "", false},
+		{"same", etag, true},
+		{"other", `"8-0123456789abcdef"`, false},
+		{"weak", "W/" + etag, true},
+		{"list", `"1-aaaa", ` + etag + `, "2-bbbb"`, true},
+		{"list with weak", `"1-aaaa",W/` + etag, true},
+		{"list without", `"1-aaaa", "2-bbbb"`, false},
+		{"any", "*", true},
+		{"unquoted", strings.Trim(etag, `"`), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := etagMatches(tt.header, etag); got != tt.want {
+				t.Errorf("etagMatches(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPSearchETag(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, src string) string {
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	d := newTestDaemon(t, root)
+	p := d.defaultProject
+	if err := d.reindexFile(p, write("parse.go", "package p\n\nfunc ParseConfig() {}\n")); err != nil {
+		t.Fatalf("reindexFile failed: %v", err)
+	}
+
+	srv := httptest.NewServer(d.httpHandler())
+	defer srv.Close()
+
+	search := func(ifNoneMatch string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/search", strings.NewReader(`{"query": "parse config"}`))
+		if err != nil {
+			t.Fatalf("NewRequest failed: %v", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("search request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	first := search("")
+	if first.StatusCode != http.StatusOK {
+		t.Fatalf("search returned %d, want 200", first.StatusCode)
+	}
+	etag := first.Header.Get("ETag")
+	if etag == "" {
+		t.Fatal("search response has no ETag")
+	}
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        int
+	}{
+		{"matching", etag, http.StatusNotModified},
+		{"weak", "W/" + etag, http.StatusNotModified},
+		{"list", `"0-0000000000000000", ` + etag, http.StatusNotModified},
+		{"any", "*", http.StatusNotModified},
+		{"other", `"0-0000000000000000"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := search(tt.ifNoneMatch)
+			if resp.StatusCode != tt.want {
+				t.Errorf("If-None-Match %s returned %d, want %d", tt.ifNoneMatch, resp.StatusCode, tt.want)
+			}
+			if got := resp.Header.Get("ETag"); got != etag {
+				t.Errorf("ETag = %s, want %s", got, etag)
+			}
+		})
+	}
+
+	// Indexing another file moves the index to a new generation
+	if err := d.reindexFile(p, write("load.go", "package p\n\nfunc LoadConfig() {}\n")); err != nil {
+		t.Fatalf("reindexFile failed: %v", err)
+	}
+	resp := search(etag)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("search after an index update returned %d, want 200", resp.StatusCode)
+	}
+	if got := resp.Header.Get("ETag"); got == "" || got == etag {
+		t.Errorf("ETag after an index update = %s, want one other than %s", got, etag)
+	}
+}
+
+func TestAllowedHost(t *testing.T) {
+	tests := []struct {
+		host       string
+		listenHost string
+		want       bool
+	}{
+		{"localhost:9848", "", true},
+		{"LOCALHOST", "", true},
+		{"127.0.0.1:9848", "", true},
+		{"[::1]:9848", "", true},
+		{"192.168.1.20:9848", "", true},
+		{"gcq.internal:9848", "gcq.internal", true},
+		{"gcq.internal:9848", "", false},
+		{"attacker.example:9848", "localhost", false},
+		{"localhost.attacker.example", "localhost", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		if got := allowedHost(tt.host, tt.listenHost); got != tt.want {
+			t.Errorf("allowedHost(%q, %q) = %v, want %v", tt.host, tt.listenHost, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPRequestChecks(t *testing.T) {
+	d := newTestDaemon(t, t.TempDir())
+	d.config.Daemon.HTTPAllowedOrigins = []string{"http://localhost:3000"}
+	srv := httptest.NewServer(d.httpHandler())
+	defer srv.Close()
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		host        string
+		origin      string
+		contentType string
+		want        int
+	}{
+		{"status", http.MethodGet, "/status", "", "", "", http.StatusOK},
+		{"allowed origin", http.MethodGet, "/status", "", "http://localhost:3000", "", http.StatusOK},
+		{"other origin", http.MethodGet, "/status", "", "http://attacker.example", "", http.StatusForbidden},
+		{"other origin preflight", http.MethodOptions, "/search", "", "http://attacker.example", "", http.StatusForbidden},
+		{"json", http.MethodPost, "/job_status", "", "", "application/json; charset=utf-8", http.StatusOK},
+		{"form", http.MethodPost, "/job_status", "", "", "application/x-www-form-urlencoded", http.StatusUnsupportedMediaType},
+		{"text", http.MethodPost, "/job_status", "", "", "text/plain", http.StatusUnsupportedMediaType},
+		{"no content type", http.MethodPost, "/job_status", "", "", "", http.StatusUnsupportedMediaType},
+		{"localhost", http.MethodGet, "/status", "localhost", "", "", http.StatusOK},
+		{"rebound name", http.MethodGet, "/status", "attacker.example", "", "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, srv.URL+tt.path, strings.NewReader("{}"))
+			if err != nil {
+				t.Fatalf("NewRequest failed: %v", err)
+			}
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("%s %s returned %d, want %d", tt.method, tt.path, resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
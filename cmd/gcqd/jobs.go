@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxQueuedJobs bounds the jobs waiting to run; submitting beyond it fails
+const maxQueuedJobs = 32
+
+// maxFinishedJobs is how many finished jobs job_status remembers
+const maxFinishedJobs = 100
+
+// Background job states
+const (
+	jobQueued    = "queued"
+	jobRunning   = "running"
+	jobDone      = "done"
+	jobFailed    = "failed"
+	jobCancelled = "cancelled"
+)
+
+// JobStatus describes a background extract or warm job. Result holds the
+// command's usual result once the job is done.
+type JobStatus struct {
+	ID      string `json:"job_id"`
+	Type    string `json:"type"`
+	Project string `json:"project,omitempty"`
+	State   string `json:"state"`
+	// Done counts the files finished so far, indexed or failed, out of Total
+	Done       int             `json:"done"`
+	Total      int             `json:"total"`
+	Errors     int             `json:"errors"`
+	Error      string          `json:"error,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  time.Time       `json:"started_at,omitzero"`
+	FinishedAt time.Time       `json:"finished_at,omitzero"`
+}
+
+// jobFunc does a job's work, reporting per-file progress, and returns its
+// result. It should return promptly once ctx is cancelled.
+type jobFunc func(ctx context.Context, progress progressFunc) (any, error)
+
+type job struct {
+	status JobStatus
+	run    jobFunc
+	ctx    context.Context
+	cancel context.CancelFunc
+	// release is called once when the job finishes, in any state
+	release func()
+}
+
+// jobQueue runs extract and warm jobs one at a time in the background, so
+// a long build neither holds a client connection nor competes with another
+// build for the embedding provider. It has its own lock so job_status never
+// waits on Daemon.mu.
+type jobQueue struct {
+	mu      sync.Mutex
+	jobs    map[string]*job
+	pending chan *job
+	// finished holds the IDs of finished jobs, oldest first
+	finished []string
+	seq      int
+}
+
+func newJobQueue() *jobQueue {
+	return &jobQueue{
+		jobs:    make(map[string]*job),
+		pending: make(chan *job, maxQueuedJobs),
+	}
+}
+
+// submit queues run as a job of jobType on project. release is called when
+// the job finishes; it is not called when the queue is full.
+func (q *jobQueue) submit(ctx context.Context, jobType, project string, run jobFunc, release func()) (JobStatus, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.seq++
+	jobCtx, cancel := context.WithCancel(ctx)
+	j := &job{
+		status: JobStatus{
+			ID:        fmt.Sprintf("job-%d", q.seq),
+			Type:      jobType,
+			Project:   project,
+			State:     jobQueued,
+			CreatedAt: time.Now(),
+		},
+		run:     run,
+		ctx:     jobCtx,
+		cancel:  cancel,
+		release: release,
+	}
+
+	select {
+	case q.pending <- j:
+	default:
+		cancel()
+		return JobStatus{}, fmt.Errorf("job queue is full (%d jobs waiting)", maxQueuedJobs)
+	}
+	q.jobs[j.status.ID] = j
+	return j.status, nil
+}
+
+// run executes queued jobs until ctx is cancelled
+func (q *jobQueue) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case j := <-q.pending:
+			q.runJob(j)
+		}
+	}
+}
+
+func (q *jobQueue) runJob(j *job) {
+	q.mu.Lock()
+	if j.status.State != jobQueued {
+		// Cancelled while waiting
+		q.mu.Unlock()
+		return
+	}
+	j.status.State = jobRunning
+	j.status.StartedAt = time.Now()
+	q.mu.Unlock()
+
+	log.Printf("Running %s job %s", j.status.Type, j.status.ID)
+	result, err := j.run(j.ctx, func(ev ProgressEvent) {
+		q.mu.Lock()
+		j.status.Done, j.status.Total = ev.Done, ev.Total
+		if ev.Event == progressError {
+			j.status.Errors++
+		}
+		q.mu.Unlock()
+	})
+
+	q.mu.Lock()
+	switch {
+	case errors.Is(err, context.Canceled):
+		j.status.State = jobCancelled
+	case err != nil:
+		j.status.State = jobFailed
+		j.status.Error = err.Error()
+	default:
+		j.status.State = jobDone
+		if j.status.Result, err = json.Marshal(result); err != nil {
+			j.status.State = jobFailed
+			j.status.Error = fmt.Sprintf("marshal error: %v", err)
+		}
+	}
+	log.Printf("Job %s %s", j.status.ID, j.status.State)
+	q.finishLocked(j)
+	q.mu.Unlock()
+	j.release()
+}
+
+// finishLocked records j as finished and forgets the oldest finished jobs.
+// Callers must hold q.mu, and call j.release once they let go of it.
+func (q *jobQueue) finishLocked(j *job) {
+	j.status.FinishedAt = time.Now()
+	j.cancel()
+
+	q.finished = append(q.finished, j.status.ID)
+	for len(q.finished) > maxFinishedJobs {
+		delete(q.jobs, q.finished[0])
+		q.finished = q.finished[1:]
+	}
+}
+
+// status returns the status of job id
+func (q *jobQueue) status(id string) (JobStatus, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j, ok := q.jobs[id]
+	if !ok {
+		return JobStatus{}, false
+	}
+	return j.status, true
+}
+
+// list returns the status of every known job, oldest first
+func (q *jobQueue) list() []JobStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := make([]JobStatus, 0, len(q.jobs))
+	for _, j := range q.jobs {
+		jobs = append(jobs, j.status)
+	}
+	sort.Slice(jobs, func(a, b int) bool { return jobs[a].CreatedAt.Before(jobs[b].CreatedAt) })
+	return jobs
+}
+
+// cancelJob cancels job id. A queued job is dropped at once; a running job
+// stops after the file it is indexing, keeping the files already indexed.
+func (q *jobQueue) cancelJob(id string) (JobStatus, error) {
+	q.mu.Lock()
+	j, ok := q.jobs[id]
+	if !ok {
+		q.mu.Unlock()
+		return JobStatus{}, fmt.Errorf("unknown job: %s", id)
+	}
+	switch j.status.State {
+	case jobQueued:
+		j.status.State = jobCancelled
+		q.finishLocked(j)
+		status := j.status
+		q.mu.Unlock()
+		j.release()
+		return status, nil
+	case jobRunning:
+		j.cancel()
+		status := j.status
+		q.mu.Unlock()
+		return status, nil
+	default:
+		status := j.status
+		q.mu.Unlock()
+		return status, fmt.Errorf("job %s already %s", id, status.State)
+	}
+}
+
+// submitJob queues run as a background job for cmd and answers with the
+// job's status. release is called when the job finishes.
+func (d *Daemon) submitJob(cmd Command, p *project, release func(), run jobFunc) Response {
+	status, err := d.jobs.submit(d.ctx, cmd.Type, p.root, run, release)
+	if err != nil {
+		release()
+		return Response{ID: cmd.ID, Error: err.Error()}
+	}
+	return jobResponse(cmd, status)
+}
+
+type JobParams struct {
+	// ID selects one job; job_status without it lists every job
+	ID string `json:"job_id,omitempty"`
+}
+
+func (d *Daemon) handleJobStatus(cmd Command) Response {
+	var params JobParams
+	if len(cmd.Params) > 0 {
+		if err := json.Unmarshal(cmd.Params, &params); err != nil {
+			return Response{ID: cmd.ID, Error: fmt.Sprintf("invalid params: %v", err)}
+		}
+	}
+
+	if params.ID == "" {
+		resultJSON, err := json.Marshal(map[string]any{"jobs": d.jobs.list()})
+		if err != nil {
+			return Response{ID: cmd.ID, Error: fmt.Sprintf("marshal error: %v", err)}
+		}
+		return Response{ID: cmd.ID, Type: "job_status", Result: resultJSON}
+	}
+
+	status, ok := d.jobs.status(params.ID)
+	if !ok {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("unknown job: %s", params.ID)}
+	}
+	return jobResponse(cmd, status)
+}
+
+func (d *Daemon) handleJobCancel(cmd Command) Response {
+	var params JobParams
+	if err := json.Unmarshal(cmd.Params, &params); err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("invalid params: %v", err)}
+	}
+	if params.ID == "" {
+		return Response{ID: cmd.ID, Error: "job_id is required"}
+	}
+
+	status, err := d.jobs.cancelJob(params.ID)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: err.Error()}
+	}
+	return jobResponse(cmd, status)
+}
+
+func jobResponse(cmd Command, status JobStatus) Response {
+	resultJSON, err := json.Marshal(status)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("marshal error: %v", err)}
+	}
+	return Response{ID: cmd.ID, Type: cmd.Type, Result: resultJSON}
+}
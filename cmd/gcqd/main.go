@@ -7,10 +7,12 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -21,12 +23,22 @@ import (
 	"time"
 
 	"github.com/l3aro/go-context-query/internal/config"
+	gcqdaemon "github.com/l3aro/go-context-query/internal/daemon"
 	"github.com/l3aro/go-context-query/internal/scanner"
+	"github.com/l3aro/go-context-query/internal/watch"
+	"github.com/l3aro/go-context-query/internal/webhook"
+	"github.com/l3aro/go-context-query/pkg/blame"
 	"github.com/l3aro/go-context-query/pkg/callgraph"
 	"github.com/l3aro/go-context-query/pkg/embed"
 	"github.com/l3aro/go-context-query/pkg/extractor"
+	"github.com/l3aro/go-context-query/pkg/feedback"
+	"github.com/l3aro/go-context-query/pkg/hover"
 	"github.com/l3aro/go-context-query/pkg/index"
+	"github.com/l3aro/go-context-query/pkg/langpack"
+	"github.com/l3aro/go-context-query/pkg/pdg"
 	"github.com/l3aro/go-context-query/pkg/search"
+	"github.com/l3aro/go-context-query/pkg/semantic"
+	"github.com/l3aro/go-context-query/pkg/symbols"
 	"github.com/l3aro/go-context-query/pkg/types"
 )
 
@@ -42,26 +54,91 @@ func isWindows() bool {
 
 type Daemon struct {
 	config       *config.Config
-	index        *index.VectorIndex
-	searcher     *search.Searcher
-	textSearcher *search.TextSearcher
+	textDefaults search.TextSearchOptions
 	embedder     embed.Provider
 	scanner      *scanner.Scanner
 	callGraph    *callgraph.Builder
 	mu           sync.RWMutex
 	ctx          context.Context
 	cancel       context.CancelFunc
-	indexPath    string
 	projectPath  string
 	socketPath   string
 
-	// Dirty tracking for file change notifications
-	dirtyFiles        map[string]bool
-	dirtyCount        int
-	reindexThreshold  int
-	reindexInProgress bool
+	// Project registry keyed by absolute root ("" for the global project);
+	// requests without a project use defaultProject
+	projects       map[string]*project
+	defaultProject *project
+
+	// Dirty file count at which a project is re-indexed in the background
+	reindexThreshold int
+
+	// Semantic indexes built by `gcq warm`, keyed by absolute project root,
+	// with the metadata they were loaded with
+	semanticSearchers map[string]*search.Searcher
+	semanticMetadata  map[string]*semantic.IndexMetadata
+
+	// Reverse call graphs of the semantic indexes, built on first callers
+	// query and dropped when the index is reloaded
+	callerGraphs map[string]*semantic.CallerGraph
+
+	// Periodic refresh of registered projects during idle time
+	lastActivity time.Time
+
+	// File watcher over registered paths; nil unless daemon.watch is set
+	watcher *watch.Watcher
+
+	// Progress of the startup warm-up, reported by status
+	warmup warmupStatus
+
+	// Webhooks notified of builds, watched changes, provider failures and
+	// finished background jobs; nil when none are configured
+	webhooks       *webhook.Notifier
+	providerAlerts providerAlerts
+
+	// HTTP API listener; nil unless daemon.http_addr is set
+	httpServer *http.Server
+
+	// Background extract and warm jobs submitted with "async"
+	jobs *jobQueue
+
+	// Recent search responses; nil when daemon.result_cache_size is 0
+	resultCache *resultCache
+
+	// Per-project limits and usage from daemon.project_quota
+	quotas *quotas
+}
+
+// warmupStatus describes the startup warm-up: preloading semantic indexes
+// and embedding canary queries so the first search is not a cold one.
+type warmupStatus struct {
+	// State is "running" or "done"
+	State string `json:"state"`
+	// IndexesLoaded counts the semantic roots loaded during warm-up
+	IndexesLoaded int `json:"indexes_loaded"`
+	// Queries counts the canary queries embedded and searched
+	Queries int `json:"queries"`
+	// DurationMS is how long warm-up took, once done
+	DurationMS int64    `json:"duration_ms,omitempty"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+// defaultWarmupQueries are run when daemon.warmup_queries is empty
+var defaultWarmupQueries = []string{
+	"main entry point",
+	"parse configuration file",
+	"handle request error",
 }
 
+// refreshIdleThreshold is how long the daemon must go without handling a
+// command before a scheduled refresh is allowed to run.
+const refreshIdleThreshold = 30 * time.Second
+
+// Defaults for daemon.embed_batch_size and daemon.embed_concurrency
+const (
+	defaultEmbedBatchSize   = 32
+	defaultEmbedConcurrency = 2
+)
+
 func computeSocketPath(projectPath string) string {
 	if projectPath == "" {
 		return "/tmp/gcq.sock"
@@ -88,7 +165,14 @@ func NewDaemon(cfg *config.Config, projectPath string) (*Daemon, error) {
 	if socketPath == "" {
 		socketPath = computeSocketPath(projectPath)
 	}
-	indexPath := computeIndexPath(projectPath)
+	if projectPath != "" {
+		absPath, err := filepath.Abs(projectPath)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("resolving project path: %w", err)
+		}
+		projectPath = absPath
+	}
 
 	d := &Daemon{
 		config:            cfg,
@@ -96,11 +180,15 @@ func NewDaemon(cfg *config.Config, projectPath string) (*Daemon, error) {
 		cancel:            cancel,
 		projectPath:       projectPath,
 		socketPath:        socketPath,
-		indexPath:         indexPath,
-		dirtyFiles:        make(map[string]bool),
-		dirtyCount:        0,
+		projects:          make(map[string]*project),
 		reindexThreshold:  20,
-		reindexInProgress: false,
+		semanticSearchers: make(map[string]*search.Searcher),
+		semanticMetadata:  make(map[string]*semantic.IndexMetadata),
+		callerGraphs:      make(map[string]*semantic.CallerGraph),
+		lastActivity:      time.Now(),
+		jobs:              newJobQueue(),
+		quotas:            newQuotas(cfg.Daemon.ProjectQuota),
+		resultCache:       newResultCache(cfg.Daemon.ResultCacheSize),
 	}
 
 	var err error
@@ -110,30 +198,203 @@ func NewDaemon(cfg *config.Config, projectPath string) (*Daemon, error) {
 		return nil, fmt.Errorf("initializing embedder: %w", err)
 	}
 
-	dimension := d.getEmbeddingDimension()
-	d.index = index.NewVectorIndex(dimension)
+	d.webhooks, err = webhook.New(cfg.Daemon.Webhooks, cfg.Daemon.WebhookEvents)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("configuring webhooks: %w", err)
+	}
+
+	// The daemon's own project, or the global index without one
+	d.defaultProject = d.openProject(projectPath)
 
-	// Create .gcq directory if it doesn't exist
-	if d.projectPath != "" {
-		gcqDir := filepath.Join(d.projectPath, ".gcq")
-		if err := os.MkdirAll(gcqDir, 0755); err != nil {
-			log.Printf("Warning: could not create .gcq directory: %v", err)
+	d.textDefaults = search.TextSearchOptions{
+		ContextLines: cfg.TextSearch.ContextLines,
+		MaxResults:   cfg.TextSearch.MaxResults,
+		MaxFileSize:  cfg.TextSearch.MaxFileSize,
+		SkipBinary:   cfg.TextSearch.SkipBinary,
+	}
+	d.scanner = scanner.New(scanner.DefaultOptions())
+	d.callGraph = callgraph.NewBuilder()
+
+	// Serve semantic indexes that were already built for this project or
+	// listed in config; missing indexes can be loaded later with "load".
+	// Warm-up runs in the background so the socket comes up immediately;
+	// searches that arrive first load their index on demand.
+	roots := cfg.Daemon.SemanticRoots
+	if projectPath != "" {
+		roots = append([]string{projectPath}, roots...)
+	}
+	d.warmup.State = "running"
+	go d.warmUp(roots)
+	go d.jobs.run(ctx)
+
+	return d, nil
+}
+
+// warmUp preloads the semantic indexes for roots and, unless daemon.warmup
+// is off, embeds the canary queries and searches every loaded index with
+// them. The embedding provider and index pages are then hot when the first
+// interactive search arrives.
+func (d *Daemon) warmUp(roots []string) {
+	start := time.Now()
+	status := warmupStatus{State: "done"}
+
+	for _, root := range roots {
+		if _, err := d.loadSemanticIndex(root); err != nil {
+			log.Printf("No semantic index loaded for %s: %v", root, err)
+			continue
+		}
+		status.IndexesLoaded++
+	}
+
+	if d.config.Daemon.Warmup {
+		queries := d.config.Daemon.WarmupQueries
+		if len(queries) == 0 {
+			queries = defaultWarmupQueries
+		}
+
+		d.mu.RLock()
+		searchers := make([]*search.Searcher, 0, len(d.semanticSearchers)+1)
+		searchers = append(searchers, d.defaultProject.searcher)
+		for _, searcher := range d.semanticSearchers {
+			searchers = append(searchers, searcher)
+		}
+		// Warm-up is the daemon's own work, not charged to a project quota
+		canary := search.NewSearcher(d.embedder, d.defaultProject.index)
+		d.mu.RUnlock()
+
+		for _, query := range queries {
+			if d.ctx.Err() != nil {
+				break
+			}
+			embedding, err := canary.EmbedQuery(d.ctx, query)
+			if err != nil {
+				// The provider is down or misconfigured; more queries
+				// would only wait on the same failure
+				log.Printf("Warm-up query %q failed: %v", query, err)
+				status.Errors = append(status.Errors, err.Error())
+				d.providerFailed("warm-up", nil, 1, err)
+				break
+			}
+			d.mu.RLock()
+			for _, searcher := range searchers {
+				if count, dim := searcher.IndexStats(); count == 0 || dim != len(embedding) {
+					continue
+				}
+				if _, err := searcher.SearchWithEmbedding(embedding, 1); err != nil {
+					status.Errors = append(status.Errors, err.Error())
+				}
+			}
+			d.mu.RUnlock()
+			status.Queries++
 		}
 	}
 
-	if err := d.index.Load(d.indexPath); err != nil {
-		log.Printf("No existing index found or error loading: %v", err)
+	status.DurationMS = time.Since(start).Milliseconds()
+	log.Printf("Warm-up done in %dms: %d semantic indexes, %d queries",
+		status.DurationMS, status.IndexesLoaded, status.Queries)
+
+	d.mu.Lock()
+	d.warmup = status
+	d.mu.Unlock()
+}
+
+// loadSemanticIndex loads (or reloads) the semantic index that `gcq warm`
+// wrote under root/.gcq/cache/semantic and registers it for searches.
+func (d *Daemon) loadSemanticIndex(root string) (*semantic.IndexMetadata, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("resolving root: %w", err)
+	}
+
+	vecIndex, metadata, err := semantic.LoadIndex(absRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	if metadata.Dimension > 0 {
+		if dim := d.getEmbeddingDimension(); dim > 0 && dim != metadata.Dimension {
+			log.Printf("Warning: semantic index for %s has dimension %d, daemon embedder has %d",
+				absRoot, metadata.Dimension, dim)
+		}
 	}
 
-	d.searcher = search.NewSearcher(d.embedder, d.index)
-	d.textSearcher = search.NewTextSearcher(search.TextSearchOptions{
-		ContextLines: 2,
-		MaxResults:   100,
+	backend := semantic.LoadBackend(metadata.Dir, vecIndex, index.BackendOptions{
+		Kind:          d.config.Index.Backend,
+		AutoThreshold: d.config.Index.HNSWThreshold,
+		HNSW: index.HNSWOptions{
+			M:              d.config.Index.HNSWM,
+			EfConstruction: d.config.Index.HNSWEfConstruction,
+			EfSearch:       d.config.Index.HNSWEfSearch,
+		},
+		Quantization: d.config.Index.Quantization,
 	})
-	d.scanner = scanner.New(scanner.DefaultOptions())
-	d.callGraph = callgraph.NewBuilder()
 
-	return d, nil
+	d.mu.Lock()
+	d.semanticSearchers[absRoot] = search.NewSearcher(d.embedderFor(absRoot), vecIndex).WithBackend(backend).WithTextIndex(semantic.LoadTextIndex(metadata.Dir))
+	d.semanticMetadata[absRoot] = metadata
+	delete(d.callerGraphs, absRoot)
+	d.mu.Unlock()
+
+	log.Printf("Loaded semantic index for %s (%d units)", absRoot, vecIndex.Count())
+	return metadata, nil
+}
+
+// semanticSearcherFor returns the searcher for the project semantic index
+// covering root, loading it on first use.
+func (d *Daemon) semanticSearcherFor(root string) (*search.Searcher, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("resolving root: %w", err)
+	}
+
+	d.mu.RLock()
+	searcher, ok := d.semanticSearchers[absRoot]
+	d.mu.RUnlock()
+	if ok {
+		return searcher, nil
+	}
+
+	if _, err := d.loadSemanticIndex(absRoot); err != nil {
+		return nil, fmt.Errorf("no semantic index for %s (run 'gcq warm'): %w", absRoot, err)
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.semanticSearchers[absRoot], nil
+}
+
+// semanticMetadataFor returns the metadata of the loaded semantic index
+// covering root, or nil
+func (d *Daemon) semanticMetadataFor(root string) *semantic.IndexMetadata {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.semanticMetadata[absRoot]
+}
+
+// feedbackBoosts returns the score multipliers search.feedback_boost
+// derives from the judgments recorded under root, and a stamp of the
+// judgments file that changes whenever they do. Both are empty when
+// boosting is off or nothing was judged.
+func (d *Daemon) feedbackBoosts(root string) (map[string]float32, string) {
+	weight := d.config.Search.FeedbackBoost
+	if weight <= 0 || root == "" {
+		return nil, ""
+	}
+	info, err := os.Stat(feedback.Path(root))
+	if err != nil {
+		return nil, ""
+	}
+	judgments, err := feedback.Load(root)
+	if err != nil {
+		log.Printf("Ignoring search feedback for %s: %v", root, err)
+		return nil, ""
+	}
+	return feedback.Boosts(judgments, weight), fmt.Sprintf(":feedback=%d/%d", info.ModTime().UnixNano(), info.Size())
 }
 
 func (d *Daemon) initEmbedder(cfg *config.Config) (embed.Provider, error) {
@@ -175,6 +436,10 @@ func (d *Daemon) initEmbedder(cfg *config.Config) (embed.Provider, error) {
 	switch providerType {
 	case config.ProviderOllama:
 		return embed.NewOllamaProvider(embedCfg)
+	case config.ProviderLocal:
+		return embed.NewLocalProviderFromConfig(embedCfg, cfg)
+	case config.ProviderMock:
+		return embed.NewMockProviderFromConfig(cfg)
 	case config.ProviderHuggingFace:
 		hfModel := cfg.Warm.Model
 		if hfModel == "" {
@@ -217,6 +482,11 @@ func (d *Daemon) getEmbeddingDimension() int {
 			return 384
 		}
 		return 384
+	case config.ProviderMock:
+		if d.config.MockDimension > 0 {
+			return d.config.MockDimension
+		}
+		return embed.DefaultMockDimension
 	default:
 		return 768
 	}
@@ -234,6 +504,13 @@ func (d *Daemon) StartSocketServer() error {
 			port = DefaultTCPPort
 		}
 		listener, err = net.Listen("tcp", "localhost:"+port)
+		if err != nil {
+			return fmt.Errorf("listening on port %s: %w", port, err)
+		}
+		if err := serveReadyPipe(d.ctx, port); err != nil {
+			listener.Close()
+			return fmt.Errorf("serving readiness pipe: %w", err)
+		}
 		log.Printf("Started TCP server on localhost:%s", port)
 	} else {
 		if err := os.Remove(socketPath); err != nil && !os.IsNotExist(err) {
@@ -252,13 +529,45 @@ func (d *Daemon) StartSocketServer() error {
 		log.Printf("Started Unix socket server on %s", socketPath)
 	}
 
+	if d.config.Daemon.RefreshInterval > 0 {
+		go d.runRefreshLoop(d.config.Daemon.RefreshInterval)
+	}
+	if d.config.Daemon.CompactInterval > 0 {
+		go d.runCompactLoop(d.config.Daemon.CompactInterval)
+	}
+	go d.runConfigWatch()
+
+	if d.config.Daemon.Watch {
+		if err := d.startWatcher(); err != nil {
+			log.Printf("Warning: file watching disabled: %v", err)
+		}
+	}
+
+	if d.config.Daemon.HTTPAddr != "" {
+		d.httpServer, err = d.startHTTPServer(d.config.Daemon.HTTPAddr)
+		if err != nil {
+			listener.Close()
+			return fmt.Errorf("starting HTTP server: %w", err)
+		}
+	}
+
 	go func() {
 		sigCh := make(chan os.Signal, 1)
 		signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-		<-sigCh
+		// A stop command cancels the context without a signal; close the
+		// listener either way so the accept loop returns.
+		select {
+		case <-sigCh:
+		case <-d.ctx.Done():
+		}
 		log.Println("Shutting down server...")
 		d.Stop()
 		listener.Close()
+		if d.httpServer != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+			d.httpServer.Shutdown(ctx)
+			cancel()
+		}
 	}()
 
 	var tempDelay time.Duration
@@ -315,7 +624,19 @@ func (d *Daemon) handleConnection(conn net.Conn) {
 			continue
 		}
 
-		resp := d.handleCommand(cmd)
+		var resp Response
+		ctx, cancel := d.commandContext(d.ctx, cmd)
+		switch cmd.Type {
+		case "search_stream":
+			// Streamed searches write batch frames before the final response
+			resp = d.handleSearchStream(ctx, cmd, encoder)
+		case "extract", "warm":
+			// Indexing may write progress frames before the final response
+			resp = d.handleIndexStream(cmd, encoder)
+		default:
+			resp = d.handleCommand(ctx, cmd)
+		}
+		cancel()
 		if err := encoder.Encode(resp); err != nil {
 			log.Printf("Encode error: %v", err)
 			return
@@ -329,6 +650,29 @@ type Command struct {
 	Type   string          `json:"type"`
 	Params json.RawMessage `json:"params,omitempty"`
 	ID     string          `json:"id,omitempty"`
+	// TimeoutMS bounds the embedding and search work of a query command;
+	// provider requests still running when it passes are abandoned
+	TimeoutMS int64 `json:"timeout_ms,omitempty"`
+}
+
+// commandContext returns the context cmd runs under: parent, also ended
+// when the daemon stops and bounded by the command's timeout_ms
+func (d *Daemon) commandContext(parent context.Context, cmd Command) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	stop := context.AfterFunc(d.ctx, cancel)
+	if cmd.TimeoutMS > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, time.Duration(cmd.TimeoutMS)*time.Millisecond)
+		return ctx, func() {
+			cancelTimeout()
+			stop()
+			cancel()
+		}
+	}
+	return ctx, func() {
+		stop()
+		cancel()
+	}
 }
 
 type Response struct {
@@ -336,24 +680,51 @@ type Response struct {
 	Type   string          `json:"type,omitempty"`
 	Result json.RawMessage `json:"result,omitempty"`
 	Error  string          `json:"error,omitempty"`
+	// Generation is the generation of the index the result was read
+	// from, which the HTTP API puts in its ETag; 0 when there is none
+	Generation uint64 `json:"-"`
 }
 
-func (d *Daemon) handleCommand(cmd Command) Response {
+// handleCommand runs cmd; query commands stop waiting on the embedding
+// provider once ctx is done
+func (d *Daemon) handleCommand(ctx context.Context, cmd Command) Response {
+	d.mu.Lock()
+	d.lastActivity = time.Now()
+	d.mu.Unlock()
+
 	switch cmd.Type {
 	case "status":
 		return d.handleStatus(cmd)
 	case "search":
-		return d.handleSearch(cmd)
+		return d.handleSearch(ctx, cmd)
 	case "extract":
-		return d.handleExtract(cmd)
+		return d.handleExtract(cmd, nil)
 	case "context":
-		return d.handleContext(cmd)
+		return d.handleContext(ctx, cmd)
+	case "batch":
+		return d.handleBatch(ctx, cmd)
 	case "calls":
 		return d.handleCalls(cmd)
+	case "callers":
+		return d.handleCallers(cmd)
+	case "slice":
+		return d.handleSlice(cmd)
+	case "symbols":
+		return d.handleSymbols(cmd)
+	case "hover":
+		return d.handleHover(cmd)
 	case "warm":
-		return d.handleWarm(cmd)
+		return d.handleWarm(cmd, nil)
+	case "load":
+		return d.handleLoad(cmd)
 	case "notify":
 		return d.handleNotify(cmd)
+	case "projects":
+		return d.handleProjects(cmd)
+	case "job_status":
+		return d.handleJobStatus(cmd)
+	case "job_cancel":
+		return d.handleJobCancel(cmd)
 	case "stop":
 		return d.handleStop(cmd)
 	default:
@@ -368,7 +739,7 @@ func (d *Daemon) handleStatus(cmd Command) Response {
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
-	count, dim := d.searcher.IndexStats()
+	count, dim := d.defaultProject.searcher.IndexStats()
 
 	result := map[string]interface{}{
 		"version":             version,
@@ -377,8 +748,28 @@ func (d *Daemon) handleStatus(cmd Command) Response {
 		"dimension":           dim,
 		"provider":            d.config.Provider,
 		"model":               d.getModelName(),
-		"dirty_count":         d.dirtyCount,
-		"reindex_in_progress": d.reindexInProgress,
+		"dirty_count":         d.defaultProject.dirtyCount,
+		"reindex_in_progress": d.defaultProject.reindexInProgress,
+		"projects":            len(d.projects),
+		"semantic_indexes":    d.semanticIndexStats(),
+		"watching":            d.watcher != nil,
+		"warmup":              d.warmup,
+	}
+	if d.watcher != nil {
+		result["watched_dirs"] = d.watcher.Dirs()
+	}
+	if d.httpServer != nil {
+		result["http_addr"] = d.config.Daemon.HTTPAddr
+	}
+	if d.resultCache != nil {
+		result["result_cache"] = d.resultCache.stats()
+	}
+	if d.config.Daemon.ProjectQuota != (config.ProjectQuota{}) {
+		quotas := make([]quotaStatus, 0, len(d.projects))
+		for _, p := range d.projectList() {
+			quotas = append(quotas, d.quotaStatus(p))
+		}
+		result["quotas"] = quotas
 	}
 
 	resultJSON, err := json.Marshal(result)
@@ -405,38 +796,125 @@ func (d *Daemon) getModelName() string {
 }
 
 type SearchParams struct {
-	Query     string  `json:"query"`
-	Limit     int     `json:"limit,omitempty"`
-	Threshold float64 `json:"threshold,omitempty"`
-	Mode      string  `json:"mode,omitempty"` // "semantic" (default) or "text"
-	Root      string  `json:"root,omitempty"` // root directory for text search
+	Query string `json:"query"`
+	// Queries are searched along with Query and the rankings fused with
+	// reciprocal rank fusion (semantic, hybrid and keyword modes)
+	Queries   []string `json:"queries,omitempty"`
+	Limit     int      `json:"limit,omitempty"`
+	Threshold float64  `json:"threshold,omitempty"`
+	Mode      string   `json:"mode,omitempty"`  // "semantic" (default), "hybrid", "keyword" or "text"
+	Root      string   `json:"root,omitempty"`  // project root for semantic search, directory for text search
+	Files     int      `json:"files,omitempty"` // two-phase semantic search over the top N files
+	Project   string   `json:"project,omitempty"`
+	// IncludeTests keeps units from test files in semantic results
+	IncludeTests bool `json:"include_tests,omitempty"`
+	// ExcludeTerms penalizes results whose path or text contains one of
+	// the terms; "-term" words in the query are added to it
+	ExcludeTerms []string `json:"exclude_terms,omitempty"`
+	// Metadata keeps only semantic results whose enricher metadata has
+	// every key and, for non-empty values, that value
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// GroupBy adds results collapsed by "file" or "package" to semantic responses
+	GroupBy string `json:"group_by,omitempty"`
+	// BudgetMS bounds the index scan of a semantic or hybrid search; when
+	// it runs out the best results so far are returned as partial. Zero
+	// uses daemon.search_budget.
+	BudgetMS int64 `json:"budget_ms,omitempty"`
+
+	// Text search overrides; unset fields use the text_search config
+	ContextLines *int     `json:"context_lines,omitempty"`
+	MaxFileSize  int64    `json:"max_file_size,omitempty"`
+	SkipBinary   *bool    `json:"skip_binary,omitempty"`
+	Extensions   []string `json:"extensions,omitempty"`
+
+	// BatchSize is the number of matches per frame for search_stream
+	BatchSize int `json:"batch_size,omitempty"`
 }
 
-func (d *Daemon) handleSearch(cmd Command) Response {
+func (d *Daemon) handleSearch(ctx context.Context, cmd Command) Response {
 	var params SearchParams
 	if err := json.Unmarshal(cmd.Params, &params); err != nil {
 		return Response{ID: cmd.ID, Error: fmt.Sprintf("invalid params: %v", err)}
 	}
 
-	if params.Query == "" {
+	queries := search.CombineQueries(params.Query, params.Queries)
+	if len(queries) == 0 {
 		return Response{ID: cmd.ID, Error: "query is required"}
 	}
 
-	if params.Limit <= 0 {
-		params.Limit = 10
-	}
-
 	// Default to semantic mode if not specified
 	if params.Mode == "" {
 		params.Mode = "semantic"
 	}
 
-	if params.Mode == "text" {
-		return d.handleTextSearch(cmd, params)
+	switch params.Mode {
+	case "text":
+		if len(params.Queries) > 0 {
+			return Response{ID: cmd.ID, Error: "queries is not supported in text mode"}
+		}
+		return d.handleTextSearch(ctx, cmd, params)
+	case "semantic", "hybrid", "keyword":
+	default:
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("unknown mode: %s (must be 'semantic', 'hybrid', 'keyword' or 'text')", params.Mode)}
+	}
+	if err := search.ValidateGroupBy(params.GroupBy); err != nil {
+		return Response{ID: cmd.ID, Error: err.Error()}
+	}
+
+	params.Limit = d.config.Limits.SearchLimit(params.Limit)
+
+	p, err := d.projectFor(params.Project)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: err.Error()}
+	}
+	if params.Threshold == 0 {
+		d.mu.RLock()
+		params.Threshold = p.settings.MinScore
+		d.mu.RUnlock()
+	}
+
+	// Prefer the semantic index for the requested root (or the project's
+	// own root), falling back to the project's file index
+	searcher := p.searcher
+	var degradations types.Degradations
+	var metadata *semantic.IndexMetadata
+	root := params.Root
+	if root == "" {
+		root = p.root
+	}
+	if root != "" {
+		projectSearcher, err := d.semanticSearcherFor(root)
+		switch {
+		case err == nil:
+			searcher = projectSearcher
+			metadata = d.semanticMetadataFor(root)
+		case params.Root != "":
+			return Response{ID: cmd.ID, Error: err.Error()}
+		default:
+			degradations.Add(types.DegradedSemanticIndex, err.Error()+"; searched the file-level index", 0)
+		}
 	}
 
-	// Semantic search (existing behavior)
-	results, err := d.searcher.Search(params.Query, params.Limit)
+	// Repeated searches against an unchanged index are answered from cache
+	keyParams := params
+	keyParams.Root, keyParams.Project = root, p.root
+	boosts, feedbackStamp := d.feedbackBoosts(root)
+	cacheKey, err := searchCacheKey(searcher.Generation(), keyParams)
+	if err == nil {
+		// Judgments recorded since a response was cached change its ranking
+		cacheKey += feedbackStamp
+		if cached, ok := d.resultCache.get(cacheKey); ok {
+			return Response{ID: cmd.ID, Type: "search", Result: cached, Generation: searcher.Generation()}
+		}
+	}
+
+	opts := search.SearchOptions{Files: params.Files, IncludeTests: params.IncludeTests, ExcludeTerms: params.ExcludeTerms, Metadata: params.Metadata, Boosts: boosts}
+	opts.Budget = d.config.Daemon.SearchBudget
+	if params.BudgetMS > 0 {
+		opts.Budget = time.Duration(params.BudgetMS) * time.Millisecond
+	}
+	opts.Degradations = &degradations
+	results, err := searcher.SearchQueries(ctx, params.Mode, queries, params.Limit, opts)
 	if err != nil {
 		return Response{ID: cmd.ID, Error: fmt.Sprintf("search error: %v", err)}
 	}
@@ -451,36 +929,85 @@ func (d *Daemon) handleSearch(cmd Command) Response {
 		results = filtered
 	}
 
-	resultJSON, err := json.Marshal(results)
+	result := map[string]interface{}{
+		"mode":    params.Mode,
+		"query":   params.Query,
+		"root":    root,
+		"results": results,
+		"count":   len(results),
+	}
+	if len(queries) > 1 {
+		result["queries"] = queries
+	}
+	if metadata != nil {
+		files := make([]string, len(results))
+		for i, r := range results {
+			files[i] = r.FilePath
+		}
+		degradations = append(degradations, metadata.ResultDegradations(root, files, types.DegradedStaleIndex)...)
+		d.providerDegradation(&degradations)
+	} else {
+		degradations = append(degradations, d.fileIndexDegradations(p)...)
+	}
+	if len(degradations) > 0 {
+		result["degradations"] = degradations
+	}
+	if params.GroupBy != "" {
+		groups, _ := search.GroupResults(results, params.GroupBy)
+		result["group_by"] = params.GroupBy
+		result["groups"] = groups
+	}
+
+	resultJSON, err := json.Marshal(result)
 	if err != nil {
 		return Response{ID: cmd.ID, Error: fmt.Sprintf("marshal error: %v", err)}
 	}
+	// Partial results aren't cached, so they are recomputed once the cause
+	// is fixed
+	if cacheKey != "" && len(degradations) == 0 {
+		d.resultCache.put(cacheKey, resultJSON)
+	}
 
 	return Response{
-		ID:     cmd.ID,
-		Type:   "search",
-		Result: resultJSON,
+		ID:         cmd.ID,
+		Type:       "search",
+		Result:     resultJSON,
+		Generation: searcher.Generation(),
+	}
+}
+
+// textSearchOptions applies per-request overrides to the configured text search defaults
+func (d *Daemon) textSearchOptions(params SearchParams) search.TextSearchOptions {
+	opts := d.textDefaults
+	if params.Limit > 0 {
+		opts.MaxResults = params.Limit
+	}
+	if params.ContextLines != nil {
+		opts.ContextLines = *params.ContextLines
+	}
+	if params.MaxFileSize > 0 {
+		opts.MaxFileSize = params.MaxFileSize
 	}
+	if params.SkipBinary != nil {
+		opts.SkipBinary = *params.SkipBinary
+	}
+	opts.Extensions = params.Extensions
+	return opts
 }
 
-func (d *Daemon) handleTextSearch(cmd Command, params SearchParams) Response {
+func (d *Daemon) handleTextSearch(ctx context.Context, cmd Command, params SearchParams) Response {
 	if params.Root == "" {
 		return Response{ID: cmd.ID, Error: "root is required for text search"}
 	}
 
-	ctx, cancel := context.WithTimeout(d.ctx, 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	matches, err := d.textSearcher.Search(ctx, params.Query, params.Root)
+	matches, err := search.NewTextSearcher(d.textSearchOptions(params)).Search(ctx, params.Query, params.Root)
 	if err != nil {
 		return Response{ID: cmd.ID, Error: fmt.Sprintf("text search error: %v", err)}
 	}
 
-	// Apply limit
-	if params.Limit > 0 && len(matches) > params.Limit {
-		matches = matches[:params.Limit]
-	}
-
 	result := map[string]interface{}{
 		"mode":    "text",
 		"query":   params.Query,
@@ -501,72 +1028,132 @@ func (d *Daemon) handleTextSearch(cmd Command, params SearchParams) Response {
 	}
 }
 
-type ExtractParams struct {
-	Path string `json:"path"`
-}
+// streamBatchSize is the default number of matches per streamed frame.
+const streamBatchSize = 50
 
-func (d *Daemon) handleExtract(cmd Command) Response {
-	var params ExtractParams
+// streamFlushInterval bounds how long matches wait before being sent, so a
+// slow search still delivers results promptly.
+const streamFlushInterval = 100 * time.Millisecond
+
+// handleSearchStream runs a text search and writes matches to the client as
+// "search_batch" frames while the search is running. The returned response is
+// the final frame and carries the total count.
+func (d *Daemon) handleSearchStream(ctx context.Context, cmd Command, encoder *json.Encoder) Response {
+	d.mu.Lock()
+	d.lastActivity = time.Now()
+	d.mu.Unlock()
+
+	var params SearchParams
 	if err := json.Unmarshal(cmd.Params, &params); err != nil {
 		return Response{ID: cmd.ID, Error: fmt.Sprintf("invalid params: %v", err)}
 	}
-
-	if params.Path == "" {
-		return Response{ID: cmd.ID, Error: "path is required"}
+	if params.Query == "" {
+		return Response{ID: cmd.ID, Error: "query is required"}
 	}
-
-	files, err := d.scanner.Scan(params.Path)
-	if err != nil {
-		return Response{ID: cmd.ID, Error: fmt.Sprintf("scan error: %v", err)}
+	if params.Mode != "" && params.Mode != "text" {
+		return Response{ID: cmd.ID, Error: "streaming is only supported for text search"}
+	}
+	if params.Root == "" {
+		return Response{ID: cmd.ID, Error: "root is required for text search"}
 	}
 
-	d.mu.Lock()
-	defer d.mu.Unlock()
+	batchSize := params.BatchSize
+	if batchSize <= 0 {
+		batchSize = streamBatchSize
+	}
 
-	var extractedCount int
-	for _, file := range files {
-		filePath := file.FullPath
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
 
-		moduleInfo, err := extractor.ExtractFile(filePath)
-		if err != nil {
-			log.Printf("Error extracting %s: %v", filePath, err)
-			continue
+	var pending []search.TextMatch
+	var lastFlush time.Time
+	total := 0
+	flush := func() error {
+		if len(pending) == 0 {
+			return nil
 		}
-
-		cg, err := d.callGraph.BuildFromFile(filePath, moduleInfo)
+		resultJSON, err := json.Marshal(map[string]interface{}{
+			"matches": pending,
+			"count":   len(pending),
+		})
 		if err != nil {
-			log.Printf("Error building call graph for %s: %v", filePath, err)
-		} else {
-			moduleInfo.CallGraph = cg.ToCallGraph()
+			return fmt.Errorf("marshal error: %w", err)
 		}
+		pending = nil
+		lastFlush = time.Now()
+		return encoder.Encode(Response{ID: cmd.ID, Type: "search_batch", Result: resultJSON})
+	}
 
-		unit := types.EmbeddingUnit{
-			L1Data: *moduleInfo,
-			L2Data: moduleInfo.CallGraph.Edges,
+	// The first matches are flushed immediately; later ones are batched
+	err := search.NewTextSearcher(d.textSearchOptions(params)).SearchStream(ctx, params.Query, params.Root, func(matches []search.TextMatch) error {
+		pending = append(pending, matches...)
+		total += len(matches)
+		if len(pending) >= batchSize || time.Since(lastFlush) >= streamFlushInterval {
+			return flush()
 		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("text search error: %v", err)}
+	}
 
-		text := moduleInfoToText(moduleInfo)
-		embeddings, err := d.embedder.Embed([]string{text})
-		if err != nil {
-			log.Printf("Error embedding %s: %v", filePath, err)
-			continue
-		}
+	result := map[string]interface{}{
+		"mode":  "text",
+		"query": params.Query,
+		"root":  params.Root,
+		"count": total,
+		"done":  true,
+	}
 
-		if err := d.index.Add(filePath, embeddings[0], unit); err != nil {
-			log.Printf("Error adding to index: %v", err)
-			continue
-		}
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("marshal error: %v", err)}
+	}
 
-		extractedCount++
+	return Response{
+		ID:     cmd.ID,
+		Type:   "search",
+		Result: resultJSON,
 	}
+}
+
+type ExtractParams struct {
+	Path    string `json:"path"`
+	Project string `json:"project,omitempty"`
+	// Progress streams a ProgressEvent frame per file over the connection
+	Progress bool `json:"progress,omitempty"`
+	// Async queues the extraction as a background job and returns its ID
+	Async bool `json:"async,omitempty"`
+}
 
-	if err := d.index.Save(d.indexPath); err != nil {
-		log.Printf("Error saving index: %v", err)
+func (d *Daemon) handleExtract(cmd Command, progress progressFunc) Response {
+	var params ExtractParams
+	if err := json.Unmarshal(cmd.Params, &params); err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("invalid params: %v", err)}
 	}
 
-	result := map[string]interface{}{
-		"extracted": extractedCount,
-		"total":     len(files),
+	if params.Path == "" {
+		return Response{ID: cmd.ID, Error: "path is required"}
+	}
+
+	p, release, err := d.acquireProject(params.Project)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: err.Error()}
+	}
+
+	if params.Async {
+		return d.submitJob(cmd, p, release, func(ctx context.Context, progress progressFunc) (any, error) {
+			return d.runExtract(ctx, p, params.Path, progress)
+		})
+	}
+
+	defer release()
+	result, err := d.runExtract(d.ctx, p, params.Path, progress)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: err.Error()}
 	}
 
 	resultJSON, err := json.Marshal(result)
@@ -581,29 +1168,380 @@ func (d *Daemon) handleExtract(cmd Command) Response {
 	}
 }
 
-func moduleInfoToText(m *types.ModuleInfo) string {
-	var sb strings.Builder
-	sb.WriteString(m.Path)
-	sb.WriteString("\n")
+// acquireProject resolves a request's project and marks it as being indexed,
+// so it can't be evicted until release is called
+func (d *Daemon) acquireProject(name string) (*project, func(), error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 
-	for _, fn := range m.Functions {
-		sb.WriteString("def ")
-		sb.WriteString(fn.Name)
-		sb.WriteString("(")
-		sb.WriteString(fn.Params)
-		sb.WriteString(")")
-		if fn.ReturnType != "" {
-			sb.WriteString(" -> ")
-			sb.WriteString(fn.ReturnType)
-		}
-		sb.WriteString("\n")
-		if fn.Docstring != "" {
-			sb.WriteString(fn.Docstring)
-			sb.WriteString("\n")
+	p, err := d.projectForLocked(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if limit := d.quotas.limits.MaxJobs; limit > 0 && p.indexing >= limit {
+		return nil, nil, fmt.Errorf("%w: %s already has %d extract or warm runs queued or running",
+			errQuotaExceeded, p.displayRoot(), p.indexing)
+	}
+	p.indexing++
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			d.mu.Lock()
+			p.indexing--
+			d.mu.Unlock()
+		})
+	}
+	return p, release, nil
+}
+
+// runExtract indexes the files under path into p and saves the index
+func (d *Daemon) runExtract(ctx context.Context, p *project, path string, progress progressFunc) (map[string]any, error) {
+	files, err := d.projectScanner(p).Scan(path)
+	if err != nil {
+		return nil, fmt.Errorf("scan error: %w", err)
+	}
+
+	stats := d.indexFiles(ctx, p, files, progress)
+
+	d.mu.Lock()
+	d.saveProject(p, "")
+	d.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	result := map[string]any{
+		"extracted": stats.extracted,
+		"total":     len(files),
+	}
+	if degradations := d.indexDegradations(stats); len(degradations) > 0 {
+		result["degradations"] = degradations
+	}
+	return result, nil
+}
+
+// indexStats counts the outcome of indexFiles
+type indexStats struct {
+	extracted     int
+	embedFailures int
+	// embedErr is the last embedding failure
+	embedErr error
+	// callGraphFailures counts files indexed without a call graph
+	callGraphFailures int
+}
+
+// indexDegradations reports what an extract or warm skipped or fell back on
+func (d *Daemon) indexDegradations(stats indexStats) types.Degradations {
+	var ds types.Degradations
+	if stats.callGraphFailures > 0 {
+		ds.Add(types.DegradedCallGraph, "call graph extraction failed; files are indexed without their calls", stats.callGraphFailures)
+	}
+	d.providerDegradation(&ds)
+	return ds
+}
+
+// embedBatch is a run of extracted files embedded in one provider request
+type embedBatch struct {
+	paths []string
+	units []types.EmbeddingUnit
+	texts []string
+}
+
+// embedBatchSize returns how many files are embedded per provider request
+func (d *Daemon) embedBatchSize() int {
+	if n := d.config.Daemon.EmbedBatchSize; n > 0 {
+		return n
+	}
+	return defaultEmbedBatchSize
+}
+
+// embedConcurrency returns how many embedding requests run at once
+func (d *Daemon) embedConcurrency() int {
+	if n := d.config.Daemon.EmbedConcurrency; n > 0 {
+		return n
+	}
+	return defaultEmbedConcurrency
+}
+
+// indexFiles extracts, embeds and adds files to p's index, reporting
+// each file to progress. Files are extracted in parallel by
+// extractFiles, batched in order and embedded by a few concurrent
+// workers, so a build costs one provider round trip per batch rather
+// than per file. d.mu is only held while the index is updated, so
+// searches keep being served during a long build. It stops early when
+// ctx is cancelled; the index is not saved.
+func (d *Daemon) indexFiles(ctx context.Context, p *project, files []scanner.FileInfo, progress progressFunc) indexStats {
+	var (
+		stats indexStats
+		// mu guards stats and done, and serializes progress
+		mu   sync.Mutex
+		done int
+	)
+	report := func(event string, filePath string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		ev := ProgressEvent{Event: event, File: filePath, Done: done, Total: len(files)}
+		switch event {
+		case progressEmbedded:
+			stats.extracted++
+			done++
+			ev.Done = done
+		case progressError:
+			done++
+			ev.Done = done
+			ev.Error = err.Error()
+		}
+		progress.emit(ev)
+	}
+
+	batches := make(chan *embedBatch)
+	var wg sync.WaitGroup
+	for range d.embedConcurrency() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range batches {
+				if ctx.Err() != nil {
+					continue
+				}
+				errs, embedErr := d.embedAndAdd(ctx, p, b)
+				if ctx.Err() != nil {
+					// Cancelled, not a provider failure
+					continue
+				}
+				if embedErr != nil {
+					mu.Lock()
+					stats.embedFailures += len(b.paths)
+					stats.embedErr = embedErr
+					mu.Unlock()
+				}
+				for i, filePath := range b.paths {
+					if errs[i] != nil {
+						report(progressError, filePath, errs[i])
+					} else {
+						report(progressEmbedded, filePath, nil)
+					}
+				}
+			}
+		}()
+	}
+
+	size := d.embedBatchSize()
+	batch := &embedBatch{}
+	d.extractFiles(ctx, files, func(file scanner.FileInfo, extracted extractedFile) {
+		filePath := file.FullPath
+		if extracted.err != nil {
+			log.Printf("Error extracting %s: %v", filePath, extracted.err)
+			report(progressError, filePath, extracted.err)
+			return
+		}
+		moduleInfo := extracted.moduleInfo
+		if extracted.callGraphErr != nil {
+			log.Printf("Error building call graph for %s: %v", filePath, extracted.callGraphErr)
+			mu.Lock()
+			stats.callGraphFailures++
+			mu.Unlock()
+		}
+
+		batch.paths = append(batch.paths, filePath)
+		batch.units = append(batch.units, types.EmbeddingUnit{
+			L1Data:  *moduleInfo,
+			L2Data:  moduleInfo.CallGraph.Edges,
+			ModTime: extracted.modTime,
+		})
+		batch.texts = append(batch.texts, moduleInfoToText(moduleInfo))
+		report(progressExtracted, filePath, nil)
+
+		if len(batch.paths) >= size {
+			batches <- batch
+			batch = &embedBatch{}
+		}
+	})
+	if ctx.Err() != nil {
+		batch = &embedBatch{}
+	}
+	if len(batch.paths) > 0 {
+		batches <- batch
+	}
+	close(batches)
+	wg.Wait()
+	return stats
+}
+
+// extractedFile is the outcome of extracting one file in extractFiles
+type extractedFile struct {
+	moduleInfo *types.ModuleInfo
+	// modTime is the file's modification time from before it was read
+	modTime time.Time
+	err     error
+	// callGraphErr is why moduleInfo has no call graph
+	callGraphErr error
+}
+
+// extractFiles extracts files on a bounded pool of GOMAXPROCS workers
+// and calls yield with each, in the order of files. Extractors and the
+// call graph builder parse with pooled parsers, so workers share them.
+// Workers run at most a few files ahead of yield, so a slow embedding
+// provider holds back extraction rather than piling up extracted files.
+// It stops early when ctx is cancelled.
+func (d *Daemon) extractFiles(ctx context.Context, files []scanner.FileInfo, yield func(scanner.FileInfo, extractedFile)) {
+	workers := min(runtime.GOMAXPROCS(0), len(files))
+	if workers == 0 {
+		return
+	}
+	// A file only gets a slot in ahead once the file window files before
+	// it has been yielded, so results can be reused round robin
+	window := 2 * workers
+	ahead := make(chan struct{}, window)
+	results := make([]chan extractedFile, window)
+	for i := range results {
+		results[i] = make(chan extractedFile, 1)
+	}
+	jobs := make(chan int)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go func() {
+		defer close(jobs)
+		for i := range files {
+			select {
+			case ahead <- struct{}{}:
+			case <-stop:
+				return
+			}
+			select {
+			case jobs <- i:
+			case <-stop:
+				return
+			}
 		}
+	}()
+	for range workers {
+		go func() {
+			for i := range jobs {
+				results[i%window] <- extractFile(d.callGraph, files[i].FullPath)
+			}
+		}()
+	}
+
+	for i, file := range files {
+		if ctx.Err() != nil {
+			return
+		}
+		yield(file, <-results[i%window])
+		<-ahead
+	}
+}
+
+// extractFile extracts a file and builds its call graph with builder
+func extractFile(builder *callgraph.Builder, filePath string) extractedFile {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return extractedFile{err: err}
+	}
+	moduleInfo, err := extractor.ExtractFile(filePath)
+	if err != nil {
+		return extractedFile{err: err}
+	}
+	cg, err := builder.BuildFromFile(filePath, moduleInfo)
+	if err != nil {
+		return extractedFile{moduleInfo: moduleInfo, modTime: info.ModTime(), callGraphErr: err}
+	}
+	moduleInfo.CallGraph = cg.ToCallGraph()
+	return extractedFile{moduleInfo: moduleInfo, modTime: info.ModTime()}
+}
+
+// embedAndAdd embeds one batch and adds its units to p's index, returning
+// the error for each file. A provider failure fails the whole batch and is
+// also returned as embedErr.
+func (d *Daemon) embedAndAdd(ctx context.Context, p *project, b *embedBatch) (errs []error, embedErr error) {
+	errs = make([]error, len(b.paths))
+
+	// Files new to an index at its memory quota aren't worth embedding
+	var admitted []int
+	var texts []string
+	d.mu.RLock()
+	for i, filePath := range b.paths {
+		if errs[i] = d.indexQuotaErr(p, fileUnitKey(filePath)); errs[i] == nil {
+			admitted = append(admitted, i)
+			texts = append(texts, b.texts[i])
+		}
+	}
+	d.mu.RUnlock()
+	if len(admitted) == 0 {
+		return errs, nil
+	}
+
+	embeddings, err := d.embedderFor(p.root).EmbedContext(ctx, texts)
+	if err == nil && len(embeddings) != len(texts) {
+		err = fmt.Errorf("embedding provider returned %d vectors for %d texts", len(embeddings), len(texts))
+	}
+	if err != nil {
+		log.Printf("Error embedding %d files: %v", len(texts), err)
+		for _, i := range admitted {
+			errs[i] = err
+		}
+		return errs, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for j, i := range admitted {
+		key := fileUnitKey(b.paths[i])
+		if errs[i] = d.indexQuotaErr(p, key); errs[i] == nil {
+			errs[i] = p.index.Add(key, embeddings[j], b.units[i])
+		}
+		if errs[i] != nil {
+			log.Printf("Error adding to index: %v", errs[i])
+		}
+	}
+	return errs, nil
+}
+
+// fileUnitKey returns the index key for a file-level unit: its canonical URI
+// without a symbol, e.g. "py:///project/pkg/mod.py".
+func fileUnitKey(filePath string) string {
+	lang := scanner.DetectLanguage(filepath.Ext(filePath))
+	return types.NewUnitURI(lang, filePath, "").String()
+}
+
+func moduleInfoToText(m *types.ModuleInfo) string {
+	var sb strings.Builder
+	sb.WriteString(m.Path)
+	sb.WriteString("\n")
+
+	for _, fn := range m.Functions {
+		sb.WriteString("def ")
+		sb.WriteString(fn.Name)
+		sb.WriteString("(")
+		sb.WriteString(fn.Params)
+		sb.WriteString(")")
+		if fn.ReturnType != "" {
+			sb.WriteString(" -> ")
+			sb.WriteString(fn.ReturnType)
+		}
+		sb.WriteString("\n")
+		if fn.Docstring != "" {
+			sb.WriteString(fn.Docstring)
+			sb.WriteString("\n")
+		}
+	}
+
+	// Interfaces and traits are written with their method sets below; Go
+	// and Rust extractors also list them as classes
+	abstract := make(map[string]bool, len(m.Interfaces)+len(m.Traits))
+	for _, iface := range m.Interfaces {
+		abstract[iface.Name] = true
+	}
+	for _, trait := range m.Traits {
+		abstract[trait.Name] = true
 	}
 
 	for _, cls := range m.Classes {
+		if abstract[cls.Name] {
+			continue
+		}
 		sb.WriteString("class ")
 		sb.WriteString(cls.Name)
 		if len(cls.Bases) > 0 {
@@ -626,15 +1564,65 @@ func moduleInfoToText(m *types.ModuleInfo) string {
 		}
 	}
 
+	for _, iface := range m.Interfaces {
+		sb.WriteString("interface ")
+		sb.WriteString(iface.Name)
+		if len(iface.Bases) > 0 {
+			sb.WriteString(" extends ")
+			sb.WriteString(strings.Join(iface.Bases, ", "))
+		}
+		sb.WriteString("\n")
+		writeMemberSignatures(&sb, iface.Docstring, iface.Methods)
+	}
+
+	for _, trait := range m.Traits {
+		sb.WriteString("trait ")
+		sb.WriteString(trait.Name)
+		sb.WriteString("\n")
+		writeMemberSignatures(&sb, trait.Docstring, trait.Methods)
+	}
+
 	return sb.String()
 }
 
+// writeMemberSignatures writes an interface or trait's docstring and the
+// full signature of each of its methods
+func writeMemberSignatures(sb *strings.Builder, docstring string, methods []types.Method) {
+	if docstring != "" {
+		sb.WriteString("  ")
+		sb.WriteString(docstring)
+		sb.WriteString("\n")
+	}
+	for _, method := range methods {
+		sb.WriteString("  ")
+		sb.WriteString(method.Name)
+		if method.Params != "" {
+			if !strings.HasPrefix(method.Params, "(") {
+				sb.WriteString("(")
+				sb.WriteString(method.Params)
+				sb.WriteString(")")
+			} else {
+				sb.WriteString(method.Params)
+			}
+		}
+		if method.ReturnType != "" {
+			sb.WriteString(" -> ")
+			sb.WriteString(method.ReturnType)
+		}
+		sb.WriteString("\n")
+	}
+}
+
+// ContextParams asks for the units relevant to a query. With Blame, each
+// unit lists the commits that last changed its lines.
 type ContextParams struct {
-	Query string `json:"query"`
-	Limit int    `json:"limit,omitempty"`
+	Query   string `json:"query"`
+	Limit   int    `json:"limit,omitempty"`
+	Project string `json:"project,omitempty"`
+	Blame   bool   `json:"blame,omitempty"`
 }
 
-func (d *Daemon) handleContext(cmd Command) Response {
+func (d *Daemon) handleContext(ctx context.Context, cmd Command) Response {
 	var params ContextParams
 	if err := json.Unmarshal(cmd.Params, &params); err != nil {
 		return Response{ID: cmd.ID, Error: fmt.Sprintf("invalid params: %v", err)}
@@ -644,16 +1632,31 @@ func (d *Daemon) handleContext(cmd Command) Response {
 		return Response{ID: cmd.ID, Error: "query is required"}
 	}
 
-	if params.Limit <= 0 {
-		params.Limit = 5
+	params.Limit = d.config.Limits.ContextLimit(params.Limit)
+
+	p, err := d.projectFor(params.Project)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: err.Error()}
 	}
 
-	results, err := d.searcher.Search(params.Query, params.Limit)
+	results, err := p.searcher.SearchWithOptions(ctx, params.Query, params.Limit, search.SearchOptions{})
 	if err != nil {
 		return Response{ID: cmd.ID, Error: fmt.Sprintf("search error: %v", err)}
 	}
 
+	degradations := d.fileIndexDegradations(p)
+	var blamer *blame.Blamer
+	if params.Blame {
+		// The global project has no root to find a repository from
+		if p.root == "" {
+			degradations.Add(types.DegradedBlame, "the global project has no git repository", 0)
+		} else if blamer, err = blame.Open(p.root); err != nil {
+			degradations.Add(types.DegradedBlame, "project is not a git repository", 0)
+		}
+	}
+
 	contextResults := make([]map[string]interface{}, len(results))
+	unblamed := 0
 	for i, r := range results {
 		contextResults[i] = map[string]interface{}{
 			"file":      r.FilePath,
@@ -664,11 +1667,570 @@ func (d *Daemon) handleContext(cmd Command) Response {
 			"type":      r.Type,
 			"score":     r.Score,
 		}
+		if blamer == nil {
+			continue
+		}
+		path := r.FilePath
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(p.root, path)
+		}
+		hunks, err := blamer.Lines(path, r.LineNumber, r.EndLine)
+		if err != nil {
+			unblamed++
+			continue
+		}
+		contextResults[i]["blame"] = hunks
+	}
+	if unblamed > 0 {
+		degradations.Add(types.DegradedBlame, "files not committed to the git repository", unblamed)
+	}
+
+	result := map[string]interface{}{
+		"context": contextResults,
+		"query":   params.Query,
+	}
+	if len(degradations) > 0 {
+		result["degradations"] = degradations
+	}
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("marshal error: %v", err)}
+	}
+
+	return Response{
+		ID:         cmd.ID,
+		Type:       "context",
+		Result:     resultJSON,
+		Generation: p.searcher.Generation(),
+	}
+}
+
+// BatchParams holds several context queries answered in one request. With
+// Dedupe (the default), units returned by more than one query are sent once.
+type BatchParams struct {
+	Queries []search.BatchQuery `json:"queries"`
+	Dedupe  *bool               `json:"dedupe,omitempty"`
+	Project string              `json:"project,omitempty"`
+}
+
+func (d *Daemon) handleBatch(ctx context.Context, cmd Command) Response {
+	var params BatchParams
+	if err := json.Unmarshal(cmd.Params, &params); err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("invalid params: %v", err)}
+	}
+
+	if len(params.Queries) == 0 {
+		return Response{ID: cmd.ID, Error: "queries are required"}
+	}
+
+	for i := range params.Queries {
+		params.Queries[i].Limit = d.config.Limits.ContextLimit(params.Queries[i].Limit)
+	}
+
+	p, err := d.projectFor(params.Project)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: err.Error()}
+	}
+
+	dedupe := params.Dedupe == nil || *params.Dedupe
+	batch, err := p.searcher.SearchBatch(ctx, params.Queries, d.config.Limits.ContextLimit(0), dedupe)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("search error: %v", err)}
+	}
+	batch.Degradations = d.fileIndexDegradations(p)
+
+	resultJSON, err := json.Marshal(batch)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("marshal error: %v", err)}
+	}
+
+	return Response{
+		ID:     cmd.ID,
+		Type:   "batch",
+		Result: resultJSON,
+	}
+}
+
+type CallsParams struct {
+	File    string `json:"file"`
+	Func    string `json:"func"`
+	Type    string `json:"type,omitempty"`
+	Depth   int    `json:"depth,omitempty"`
+	Reverse bool   `json:"reverse,omitempty"`
+	Project string `json:"project,omitempty"`
+}
+
+func (d *Daemon) handleCalls(cmd Command) Response {
+	var params CallsParams
+	if err := json.Unmarshal(cmd.Params, &params); err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("invalid params: %v", err)}
+	}
+
+	if params.File == "" || params.Func == "" {
+		return Response{ID: cmd.ID, Error: "file and func are required"}
+	}
+
+	content, err := os.ReadFile(params.File)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("read error: %v", err)}
+	}
+
+	moduleInfo, err := extractor.ExtractFile(params.File)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("extract error: %v", err)}
+	}
+
+	cg, err := d.callGraph.BuildFromBytes(content, params.File, moduleInfo)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("call graph error: %v", err)}
+	}
+
+	var calls []callgraph.CalledFunction
+	callType := params.Type
+	if callType == "" || callType == "all" {
+		calls = cg.GetCalls(params.Func)
+	} else {
+		switch callType {
+		case "local":
+			calls = cg.GetLocalCalls(params.Func)
+		case "external":
+			calls = cg.GetExternalCalls(params.Func)
+		case "method":
+			calls = cg.GetMethodCalls(params.Func)
+		default:
+			return Response{ID: cmd.ID, Error: fmt.Sprintf("invalid call type: %s", callType)}
+		}
+	}
+
+	result := map[string]interface{}{
+		"function": params.Func,
+		"file":     params.File,
+		"calls":    calls,
+		"count":    len(calls),
+	}
+
+	// A call tree needs the cross-file graph, so only build it when asked for
+	if params.Depth > 0 || params.Reverse {
+		tree, err := d.buildCallTree(params)
+		if err != nil {
+			return Response{ID: cmd.ID, Error: fmt.Sprintf("call tree error: %v", err)}
+		}
+		result["tree"] = tree
+	}
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("marshal error: %v", err)}
+	}
+
+	return Response{
+		ID:     cmd.ID,
+		Type:   "calls",
+		Result: resultJSON,
+	}
+}
+
+// buildCallTree resolves the cross-file call graph for the project containing
+// params.File and returns the call tree rooted at params.Func.
+func (d *Daemon) buildCallTree(params CallsParams) (*callgraph.CallTreeNode, error) {
+	absFile, err := filepath.Abs(params.File)
+	if err != nil {
+		return nil, fmt.Errorf("resolving file path: %w", err)
+	}
+
+	p, err := d.projectFor(params.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use the project root when the file lives inside it, otherwise the file's directory
+	rootDir := filepath.Dir(absFile)
+	if p.root != "" && isWithin(p.root, absFile) {
+		rootDir = p.root
+	}
+
+	ext, err := extractor.GetLanguageRegistry().GetExtractor(absFile)
+	if err != nil {
+		return nil, err
+	}
+
+	graph, err := callgraph.BuildProjectCallGraph(rootDir, ext)
+	if err != nil {
+		return nil, err
+	}
+
+	relFile, err := filepath.Rel(rootDir, absFile)
+	if err != nil {
+		return nil, fmt.Errorf("resolving relative path: %w", err)
+	}
+
+	edges := callgraph.StopSymbols(d.config.CallGraph.StopSymbols).Filter(graph.Edges)
+	return callgraph.BuildCallTree(edges, relFile, params.Func, params.Depth, params.Reverse), nil
+}
+
+// CallersParams selects the function whose callers are returned
+type CallersParams struct {
+	// Func is a function name, a qualified name (Class.method) or a unit URI
+	Func string `json:"func"`
+	// File narrows Func to units in this file (relative to the root)
+	File string `json:"file,omitempty"`
+	// Depth is how many levels of transitive callers to follow (default 1)
+	Depth   int    `json:"depth,omitempty"`
+	Root    string `json:"root,omitempty"`
+	Project string `json:"project,omitempty"`
+}
+
+// handleCallers answers "who calls X" from the call graph persisted in the
+// project's semantic index
+func (d *Daemon) handleCallers(cmd Command) Response {
+	var params CallersParams
+	if err := json.Unmarshal(cmd.Params, &params); err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("invalid params: %v", err)}
+	}
+	if params.Func == "" {
+		return Response{ID: cmd.ID, Error: "func is required"}
+	}
+	if params.Depth <= 0 {
+		params.Depth = 1
+	}
+
+	root := params.Root
+	if root == "" {
+		p, err := d.projectFor(params.Project)
+		if err != nil {
+			return Response{ID: cmd.ID, Error: err.Error()}
+		}
+		root = p.root
+	}
+	if root == "" {
+		return Response{ID: cmd.ID, Error: "root is required when the daemon has no project"}
+	}
+
+	graph, err := d.callerGraphFor(root)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: err.Error()}
+	}
+	targets, callers, err := graph.CallersOf(params.Func, params.File, params.Depth)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: err.Error()}
+	}
+
+	targetIDs := make([]string, len(targets))
+	for i, t := range targets {
+		targetIDs[i] = t.ID
+	}
+	if callers == nil {
+		callers = []semantic.Caller{}
+	}
+
+	result := map[string]interface{}{
+		"func":    params.Func,
+		"root":    root,
+		"depth":   params.Depth,
+		"targets": targetIDs,
+		"callers": callers,
+		"count":   len(callers),
+	}
+	if degradations := graph.Degradations(root, targets, callers); len(degradations) > 0 {
+		result["degradations"] = degradations
+	}
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("marshal error: %v", err)}
+	}
+
+	return Response{
+		ID:     cmd.ID,
+		Type:   "callers",
+		Result: resultJSON,
+	}
+}
+
+// SymbolsParams selects the pattern matched against symbol names
+type SymbolsParams struct {
+	Pattern string `json:"pattern"`
+	// Limit caps the number of matches (default symbols.DefaultLimit)
+	Limit   int    `json:"limit,omitempty"`
+	Root    string `json:"root,omitempty"`
+	Project string `json:"project,omitempty"`
+}
+
+// handleSymbols fuzzy matches a pattern against the function, class and
+// method names of a project. A project the daemon has indexed is served
+// from the modules in its index; any other root is extracted from disk.
+func (d *Daemon) handleSymbols(cmd Command) Response {
+	var params SymbolsParams
+	if err := json.Unmarshal(cmd.Params, &params); err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("invalid params: %v", err)}
+	}
+	if params.Pattern == "" {
+		return Response{ID: cmd.ID, Error: "pattern is required"}
+	}
+	if params.Limit <= 0 {
+		params.Limit = symbols.DefaultLimit
+	}
+
+	var p *project
+	root := params.Root
+	if root == "" {
+		var err error
+		if p, err = d.projectFor(params.Project); err != nil {
+			return Response{ID: cmd.ID, Error: err.Error()}
+		}
+		root = p.root
+	} else {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return Response{ID: cmd.ID, Error: fmt.Sprintf("resolving root: %v", err)}
+		}
+		root = abs
+		d.mu.RLock()
+		p = d.projects[root]
+		d.mu.RUnlock()
+	}
+
+	syms := d.indexedSymbols(p)
+	if syms == nil {
+		if root == "" {
+			return Response{ID: cmd.ID, Error: "root is required when the daemon has no project"}
+		}
+		var err error
+		if syms, err = symbols.Collect(root); err != nil {
+			return Response{ID: cmd.ID, Error: err.Error()}
+		}
+	}
+
+	matches := symbols.Search(syms, params.Pattern, params.Limit)
+	if matches == nil {
+		matches = []symbols.Match{}
+	}
+	resultJSON, err := json.Marshal(map[string]interface{}{
+		"pattern": params.Pattern,
+		"root":    root,
+		"matches": matches,
+		"count":   len(matches),
+		"total":   len(syms),
+	})
+	if err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("marshal error: %v", err)}
+	}
+
+	return Response{
+		ID:     cmd.ID,
+		Type:   "symbols",
+		Result: resultJSON,
+	}
+}
+
+// HoverParams selects the source position whose symbol is described
+type HoverParams struct {
+	// File is the source file, relative to the root or absolute
+	File string `json:"file"`
+	// Line and Col are counted from 1; Col in characters
+	Line    int    `json:"line"`
+	Col     int    `json:"col"`
+	Root    string `json:"root,omitempty"`
+	Project string `json:"project,omitempty"`
+}
+
+// handleHover describes the symbol at a source position for editor
+// hovers, from the project's semantic index
+func (d *Daemon) handleHover(cmd Command) Response {
+	var params HoverParams
+	if err := json.Unmarshal(cmd.Params, &params); err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("invalid params: %v", err)}
+	}
+	if params.File == "" || params.Line <= 0 {
+		return Response{ID: cmd.ID, Error: "file and line are required"}
+	}
+
+	root := params.Root
+	if root == "" {
+		p, err := d.projectFor(params.Project)
+		if err != nil {
+			return Response{ID: cmd.ID, Error: err.Error()}
+		}
+		root = p.root
+	}
+	if root == "" {
+		return Response{ID: cmd.ID, Error: "root is required when the daemon has no project"}
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("resolving root: %v", err)}
+	}
+
+	graph, err := d.callerGraphFor(absRoot)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: err.Error()}
+	}
+	h, err := hover.At(absRoot, graph, params.File, params.Line, params.Col)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: err.Error()}
+	}
+
+	resultJSON, err := json.Marshal(h)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("marshal error: %v", err)}
+	}
+
+	return Response{
+		ID:     cmd.ID,
+		Type:   "hover",
+		Result: resultJSON,
+	}
+}
+
+// indexedSymbols returns the symbols of the modules in p's index, with
+// paths relative to its root, or nil when p is nil or has nothing indexed
+func (d *Daemon) indexedSymbols(p *project) []symbols.Symbol {
+	if p == nil {
+		return nil
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var syms []symbols.Symbol
+	p.index.IterVectors(func(_ string, _ []float32, unit types.EmbeddingUnit) bool {
+		path := unit.L1Data.Path
+		if p.root != "" {
+			if rel, err := filepath.Rel(p.root, path); err == nil {
+				path = rel
+			}
+		}
+		syms = append(syms, symbols.FromModule(filepath.ToSlash(path), &unit.L1Data)...)
+		return true
+	})
+	return syms
+}
+
+type SliceParams struct {
+	File string `json:"file"`
+	// Func is the function containing Line; empty finds the enclosing one
+	Func string `json:"func,omitempty"`
+	Line int    `json:"line"`
+	// Var restricts the slice to dependencies through one variable
+	Var *string `json:"var,omitempty"`
+	// Direction is "backward" (default) or "forward"
+	Direction string `json:"direction,omitempty"`
+	Project   string `json:"project,omitempty"`
+}
+
+// handleSlice returns the lines that affect (backward) or are affected by
+// (forward) a line, with their source
+func (d *Daemon) handleSlice(cmd Command) Response {
+	var params SliceParams
+	if err := json.Unmarshal(cmd.Params, &params); err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("invalid params: %v", err)}
+	}
+	if params.File == "" || params.Line <= 0 {
+		return Response{ID: cmd.ID, Error: "file and line are required"}
+	}
+	if params.Direction != "" && params.Direction != "backward" && params.Direction != "forward" {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("unknown direction: %s (must be 'backward' or 'forward')", params.Direction)}
+	}
+
+	// Relative files are resolved against the project root
+	file := params.File
+	if !filepath.IsAbs(file) {
+		p, err := d.projectFor(params.Project)
+		if err != nil {
+			return Response{ID: cmd.ID, Error: err.Error()}
+		}
+		if p.root != "" {
+			file = filepath.Join(p.root, file)
+		}
+	}
+
+	result, err := pdg.Slice(pdg.SliceQuery{
+		File:     file,
+		Function: params.Func,
+		Line:     params.Line,
+		Variable: params.Var,
+		Forward:  params.Direction == "forward",
+	})
+	if err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("slice error: %v", err)}
+	}
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("marshal error: %v", err)}
+	}
+
+	return Response{
+		ID:     cmd.ID,
+		Type:   "slice",
+		Result: resultJSON,
+	}
+}
+
+// callerGraphFor returns the reverse call graph of root's semantic index,
+// building it on first use
+func (d *Daemon) callerGraphFor(root string) (*semantic.CallerGraph, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("resolving root: %w", err)
+	}
+
+	d.mu.RLock()
+	graph, ok := d.callerGraphs[absRoot]
+	d.mu.RUnlock()
+	if ok {
+		return graph, nil
+	}
+
+	graph, err = semantic.LoadCallerGraph(absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("no semantic index for %s (run 'gcq warm'): %w", absRoot, err)
+	}
+
+	d.mu.Lock()
+	d.callerGraphs[absRoot] = graph
+	d.mu.Unlock()
+	return graph, nil
+}
+
+// semanticIndexStats summarizes the loaded project semantic indexes.
+// Callers must hold d.mu.
+func (d *Daemon) semanticIndexStats() map[string]int {
+	stats := make(map[string]int, len(d.semanticSearchers))
+	for root, searcher := range d.semanticSearchers {
+		count, _ := searcher.IndexStats()
+		stats[root] = count
+	}
+	return stats
+}
+
+type LoadParams struct {
+	Root string `json:"root"`
+}
+
+func (d *Daemon) handleLoad(cmd Command) Response {
+	var params LoadParams
+	if err := json.Unmarshal(cmd.Params, &params); err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("invalid params: %v", err)}
+	}
+
+	if params.Root == "" {
+		params.Root = d.defaultProject.root
+	}
+	if params.Root == "" {
+		return Response{ID: cmd.ID, Error: "root is required"}
+	}
+
+	metadata, err := d.loadSemanticIndex(params.Root)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("load error: %v", err)}
 	}
 
+	absRoot, _ := filepath.Abs(params.Root)
 	result := map[string]interface{}{
-		"context": contextResults,
-		"query":   params.Query,
+		"root":      absRoot,
+		"count":     metadata.Count,
+		"dimension": metadata.Dimension,
+		"model":     metadata.GetModel(),
 	}
 
 	resultJSON, err := json.Marshal(result)
@@ -678,64 +2240,45 @@ func (d *Daemon) handleContext(cmd Command) Response {
 
 	return Response{
 		ID:     cmd.ID,
-		Type:   "context",
+		Type:   "load",
 		Result: resultJSON,
 	}
 }
 
-type CallsParams struct {
-	File string `json:"file"`
-	Func string `json:"func"`
-	Type string `json:"type,omitempty"`
+type WarmParams struct {
+	Paths   []string `json:"paths,omitempty"`
+	Project string   `json:"project,omitempty"`
+	// Progress streams a ProgressEvent frame per file over the connection
+	Progress bool `json:"progress,omitempty"`
+	// Async queues the build as a background job and returns its ID
+	Async bool `json:"async,omitempty"`
 }
 
-func (d *Daemon) handleCalls(cmd Command) Response {
-	var params CallsParams
+func (d *Daemon) handleWarm(cmd Command, progress progressFunc) Response {
+	var params WarmParams
 	if err := json.Unmarshal(cmd.Params, &params); err != nil {
 		return Response{ID: cmd.ID, Error: fmt.Sprintf("invalid params: %v", err)}
 	}
 
-	if params.File == "" || params.Func == "" {
-		return Response{ID: cmd.ID, Error: "file and func are required"}
+	if len(params.Paths) == 0 {
+		return Response{ID: cmd.ID, Error: "paths are required"}
 	}
 
-	content, err := os.ReadFile(params.File)
+	p, release, err := d.acquireProject(params.Project)
 	if err != nil {
-		return Response{ID: cmd.ID, Error: fmt.Sprintf("read error: %v", err)}
+		return Response{ID: cmd.ID, Error: err.Error()}
 	}
 
-	moduleInfo, err := extractor.ExtractFile(params.File)
-	if err != nil {
-		return Response{ID: cmd.ID, Error: fmt.Sprintf("extract error: %v", err)}
+	if params.Async {
+		return d.submitJob(cmd, p, release, func(ctx context.Context, progress progressFunc) (any, error) {
+			return d.runWarm(ctx, p, params.Paths, progress)
+		})
 	}
 
-	cg, err := d.callGraph.BuildFromBytes(content, params.File, moduleInfo)
+	defer release()
+	result, err := d.runWarm(d.ctx, p, params.Paths, progress)
 	if err != nil {
-		return Response{ID: cmd.ID, Error: fmt.Sprintf("call graph error: %v", err)}
-	}
-
-	var calls []callgraph.CalledFunction
-	callType := params.Type
-	if callType == "" || callType == "all" {
-		calls = cg.GetCalls(params.Func)
-	} else {
-		switch callType {
-		case "local":
-			calls = cg.GetLocalCalls(params.Func)
-		case "external":
-			calls = cg.GetExternalCalls(params.Func)
-		case "method":
-			calls = cg.GetMethodCalls(params.Func)
-		default:
-			return Response{ID: cmd.ID, Error: fmt.Sprintf("invalid call type: %s", callType)}
-		}
-	}
-
-	result := map[string]interface{}{
-		"function": params.Func,
-		"file":     params.File,
-		"calls":    calls,
-		"count":    len(calls),
+		return Response{ID: cmd.ID, Error: err.Error()}
 	}
 
 	resultJSON, err := json.Marshal(result)
@@ -745,91 +2288,83 @@ func (d *Daemon) handleCalls(cmd Command) Response {
 
 	return Response{
 		ID:     cmd.ID,
-		Type:   "calls",
+		Type:   "warm",
 		Result: resultJSON,
 	}
 }
 
-type WarmParams struct {
-	Paths []string `json:"paths,omitempty"`
-}
-
-func (d *Daemon) handleWarm(cmd Command) Response {
-	var params WarmParams
-	if err := json.Unmarshal(cmd.Params, &params); err != nil {
-		return Response{ID: cmd.ID, Error: fmt.Sprintf("invalid params: %v", err)}
-	}
-
-	if len(params.Paths) == 0 {
-		return Response{ID: cmd.ID, Error: "paths are required"}
-	}
+// runWarm registers paths with p, indexes every file under them and saves
+// the index
+func (d *Daemon) runWarm(ctx context.Context, p *project, paths []string, progress progressFunc) (map[string]any, error) {
+	start := time.Now()
 
 	d.mu.Lock()
-	defer d.mu.Unlock()
+	roots := make([]string, len(paths))
+	for i, path := range paths {
+		if absPath, err := filepath.Abs(path); err == nil {
+			path = absPath
+		}
+		if !p.paths[path] && d.watcher != nil {
+			if err := d.watcher.Add(path); err != nil {
+				log.Printf("Error watching %s: %v", path, err)
+			}
+		}
+		p.paths[path] = true
+		roots[i] = path
+	}
+	sc := p.scanner
+	d.mu.Unlock()
 
-	var totalExtracted int
-	for _, path := range params.Paths {
-		files, err := d.scanner.Scan(path)
+	// Scan every path first so progress events carry the total file count
+	var files []scanner.FileInfo
+	var scanErrors []ProgressEvent
+	for _, path := range roots {
+		scanned, err := sc.Scan(path)
 		if err != nil {
 			log.Printf("Error scanning %s: %v", path, err)
+			scanErrors = append(scanErrors, ProgressEvent{Event: progressError, File: path, Error: err.Error()})
 			continue
 		}
+		files = append(files, scanned...)
+	}
+	for _, ev := range scanErrors {
+		ev.Total = len(files)
+		progress.emit(ev)
+	}
 
-		for _, file := range files {
-			filePath := file.FullPath
-
-			moduleInfo, err := extractor.ExtractFile(filePath)
-			if err != nil {
-				continue
-			}
-
-			cg, err := d.callGraph.BuildFromFile(filePath, moduleInfo)
-			if err == nil {
-				moduleInfo.CallGraph = cg.ToCallGraph()
-			}
-
-			unit := types.EmbeddingUnit{
-				L1Data: *moduleInfo,
-				L2Data: moduleInfo.CallGraph.Edges,
-			}
-
-			text := moduleInfoToText(moduleInfo)
-			embeddings, err := d.embedder.Embed([]string{text})
-			if err != nil {
-				continue
-			}
-
-			if err := d.index.Add(filePath, embeddings[0], unit); err != nil {
-				continue
-			}
+	stats := d.indexFiles(ctx, p, files, progress)
 
-			totalExtracted++
-		}
-	}
+	d.mu.Lock()
+	d.saveProject(p, "")
+	d.mu.Unlock()
 
-	if err := d.index.Save(d.indexPath); err != nil {
-		log.Printf("Error saving index: %v", err)
+	d.providerFailed("warm", p, stats.embedFailures, stats.embedErr)
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	result := map[string]interface{}{
-		"extracted": totalExtracted,
-		"paths":     params.Paths,
-	}
+	duration := time.Since(start)
+	d.notify(webhook.EventIndexBuilt, p,
+		fmt.Sprintf("index built for %s: %d files in %s", p.displayRoot(), stats.extracted, duration.Round(time.Millisecond)),
+		map[string]any{
+			"paths":       paths,
+			"files":       stats.extracted,
+			"duration_ms": duration.Milliseconds(),
+		})
 
-	resultJSON, err := json.Marshal(result)
-	if err != nil {
-		return Response{ID: cmd.ID, Error: fmt.Sprintf("marshal error: %v", err)}
+	result := map[string]any{
+		"extracted": stats.extracted,
+		"paths":     paths,
 	}
-
-	return Response{
-		ID:     cmd.ID,
-		Type:   "warm",
-		Result: resultJSON,
+	if degradations := d.indexDegradations(stats); len(degradations) > 0 {
+		result["degradations"] = degradations
 	}
+	return result, nil
 }
 
 type NotifyParams struct {
-	Path string `json:"path"`
+	Path    string `json:"path"`
+	Project string `json:"project,omitempty"`
 }
 
 func (d *Daemon) handleNotify(cmd Command) Response {
@@ -845,23 +2380,39 @@ func (d *Daemon) handleNotify(cmd Command) Response {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	if !d.dirtyFiles[params.Path] {
-		d.dirtyFiles[params.Path] = true
-		d.dirtyCount++
-		log.Printf("Dirty file tracked: %s (count: %d)", params.Path, d.dirtyCount)
+	// Without an explicit project, the file goes to the open project that
+	// owns it, or the default project
+	var p *project
+	if params.Project == "" {
+		if absPath, err := filepath.Abs(params.Path); err == nil {
+			p = d.projectOwning(absPath)
+		}
+	}
+	if p == nil {
+		var err error
+		if p, err = d.projectForLocked(params.Project); err != nil {
+			return Response{ID: cmd.ID, Error: err.Error()}
+		}
+	}
+
+	if !p.dirtyFiles[params.Path] {
+		p.dirtyFiles[params.Path] = true
+		p.dirtyCount++
+		log.Printf("Dirty file tracked: %s (project: %s, count: %d)", params.Path, p.displayRoot(), p.dirtyCount)
 	}
 
-	shouldReindex := d.dirtyCount >= d.reindexThreshold && !d.reindexInProgress
+	shouldReindex := p.dirtyCount >= d.reindexThreshold && !p.reindexInProgress
 
 	if shouldReindex {
-		d.reindexInProgress = true
-		go d.triggerBackgroundReindex()
+		p.reindexInProgress = true
+		go d.triggerBackgroundReindex(p)
 	}
 
 	result := map[string]interface{}{
 		"status":            "ok",
 		"path":              params.Path,
-		"dirty_count":       d.dirtyCount,
+		"project":           p.displayRoot(),
+		"dirty_count":       p.dirtyCount,
 		"threshold":         d.reindexThreshold,
 		"reindex_triggered": shouldReindex,
 	}
@@ -878,64 +2429,324 @@ func (d *Daemon) handleNotify(cmd Command) Response {
 	}
 }
 
-func (d *Daemon) triggerBackgroundReindex() {
-	log.Printf("Triggering background reindex for %d dirty files", d.dirtyCount)
-
+func (d *Daemon) triggerBackgroundReindex(p *project) {
 	d.mu.Lock()
-	files := make([]string, 0, len(d.dirtyFiles))
-	for f := range d.dirtyFiles {
-		files = append(files, f)
+	files := make([]scanner.FileInfo, 0, len(p.dirtyFiles))
+	for f := range p.dirtyFiles {
+		files = append(files, scanner.FileInfo{FullPath: f})
+	}
+	d.mu.Unlock()
+
+	log.Printf("Triggering background reindex for %d dirty files in %s", len(files), p.displayRoot())
+
+	start := time.Now()
+	stats := d.indexFiles(d.ctx, p, files, nil)
+	if d.ctx.Err() != nil {
+		return
 	}
+
+	d.mu.Lock()
+	d.saveProject(p, " after reindex")
+
+	p.dirtyFiles = make(map[string]bool)
+	p.dirtyCount = 0
+	p.reindexInProgress = false
 	d.mu.Unlock()
 
-	for _, file := range files {
+	log.Printf("Background reindex completed for %d files", len(files))
+
+	d.finishJob("reindex", p, start, len(files), stats.extracted)
+	d.providerFailed("reindex", p, stats.embedFailures, stats.embedErr)
+}
+
+// runRefreshLoop periodically re-indexes registered projects while the daemon
+// is idle, so indexes stay fresh without an explicit warm.
+func (d *Daemon) runRefreshLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("Periodic refresh enabled every %s", interval)
+
+	for {
 		select {
 		case <-d.ctx.Done():
 			return
-		default:
+		case <-ticker.C:
+			d.mu.Lock()
+			idle := time.Since(d.lastActivity) >= refreshIdleThreshold
+			var due []*project
+			if idle {
+				for _, p := range d.projectList() {
+					if !p.reindexInProgress {
+						p.reindexInProgress = true
+						due = append(due, p)
+					}
+				}
+			}
+			d.mu.Unlock()
+
+			if !idle {
+				log.Printf("Skipping scheduled refresh (not idle)")
+				continue
+			}
+
+			for _, p := range due {
+				d.refreshProject(p)
+			}
 		}
+	}
+}
+
+// refreshProject re-extracts and re-embeds files in the project's registered
+// paths whose modification time differs from the one they were indexed
+// with, including files edited while the daemon was down, and drops files
+// that were deleted from the index. Callers must set reindexInProgress
+// before calling; it is cleared on return.
+func (d *Daemon) refreshProject(p *project) {
+	d.mu.Lock()
+	paths := make([]string, 0, len(p.paths))
+	for path := range p.paths {
+		paths = append(paths, path)
+	}
+	sc := p.scanner
+	d.mu.Unlock()
 
-		moduleInfo, err := extractor.ExtractFile(file)
+	startedAt := time.Now()
+	var attempted, refreshed, embedFailures int
+	var embedErr error
+
+	for _, path := range paths {
+		files, err := sc.Scan(path)
 		if err != nil {
-			log.Printf("Error re-extracting %s: %v", file, err)
+			log.Printf("Error scanning %s during refresh: %v", path, err)
 			continue
 		}
 
-		cg, err := d.callGraph.BuildFromFile(file, moduleInfo)
-		if err == nil {
-			moduleInfo.CallGraph = cg.ToCallGraph()
-		}
+		for _, file := range files {
+			select {
+			case <-d.ctx.Done():
+				return
+			default:
+			}
 
-		unit := types.EmbeddingUnit{
-			L1Data: *moduleInfo,
-			L2Data: moduleInfo.CallGraph.Edges,
-		}
+			filePath := file.FullPath
 
-		text := moduleInfoToText(moduleInfo)
-		embeddings, err := d.embedder.Embed([]string{text})
-		if err != nil {
-			log.Printf("Error re-embedding %s: %v", file, err)
-			continue
+			info, err := os.Stat(filePath)
+			if err != nil {
+				continue
+			}
+
+			d.mu.RLock()
+			_, unit, indexed := p.index.Get(fileUnitKey(filePath))
+			d.mu.RUnlock()
+
+			if indexed && info.ModTime().Equal(unit.ModTime) {
+				continue
+			}
+
+			attempted++
+			if err := d.reindexFile(p, filePath); err != nil {
+				log.Printf("Error re-indexing %s during refresh: %v", filePath, err)
+				if errors.Is(err, errEmbedding) {
+					embedFailures++
+					embedErr = err
+				}
+				continue
+			}
+			refreshed++
 		}
+	}
+
+	d.mu.Lock()
+	removed := p.removeDeleted()
+	if refreshed+removed > 0 {
+		d.saveProject(p, " after refresh")
+	}
+	p.reindexInProgress = false
+	d.mu.Unlock()
+
+	log.Printf("Scheduled refresh of %s completed: %d files re-indexed, %d removed", p.displayRoot(), refreshed, removed)
+
+	// Idle refreshes that found nothing to do aren't worth a notification
+	if attempted > 0 {
+		d.finishJob("refresh", p, startedAt, attempted, refreshed)
+	}
+	d.providerFailed("refresh", p, embedFailures, embedErr)
+}
+
+// reindexFile re-extracts and re-embeds a single file and replaces its entry
+// in the project's index. The index is not saved.
+func (d *Daemon) reindexFile(p *project, filePath string) error {
+	extracted := extractFile(d.callGraph, filePath)
+	if extracted.err != nil {
+		return fmt.Errorf("extracting: %w", extracted.err)
+	}
+	moduleInfo := extracted.moduleInfo
+
+	unit := types.EmbeddingUnit{
+		L1Data:  *moduleInfo,
+		L2Data:  moduleInfo.CallGraph.Edges,
+		ModTime: extracted.modTime,
+	}
+
+	key := fileUnitKey(filePath)
+	d.mu.RLock()
+	err := d.indexQuotaErr(p, key)
+	d.mu.RUnlock()
+	if err != nil {
+		return err
+	}
 
-		d.mu.Lock()
-		if err := d.index.Add(file, embeddings[0], unit); err != nil {
-			log.Printf("Error re-adding to index: %v", err)
+	text := moduleInfoToText(moduleInfo)
+	embeddings, err := d.embedderFor(p.root).EmbedContext(d.ctx, []string{text})
+	if err != nil {
+		if errors.Is(err, errQuotaExceeded) {
+			return err
 		}
-		d.mu.Unlock()
+		return fmt.Errorf("%w: %w", errEmbedding, err)
 	}
 
 	d.mu.Lock()
-	if err := d.index.Save(d.indexPath); err != nil {
-		log.Printf("Error saving index after reindex: %v", err)
+	defer d.mu.Unlock()
+	if err := d.indexQuotaErr(p, key); err != nil {
+		return err
+	}
+	if err := p.index.Update(key, embeddings[0], unit); err != nil {
+		return fmt.Errorf("updating index: %w", err)
+	}
+	return nil
+}
+
+// startWatcher watches the registered paths of every open project and
+// re-indexes files as they change, so edits are searchable without a warm
+// or refresh pass
+func (d *Daemon) startWatcher() error {
+	w, err := watch.New(watch.Options{
+		Debounce: d.config.Daemon.WatchDebounce,
+		SkipDir:  d.scanner.SkipsDir,
+		Match: func(path string) bool {
+			return scanner.DetectLanguage(filepath.Ext(path)) != ""
+		},
+	})
+	if err != nil {
+		return err
 	}
 
-	d.dirtyFiles = make(map[string]bool)
-	d.dirtyCount = 0
-	d.reindexInProgress = false
+	d.mu.Lock()
+	var paths []string
+	for _, p := range d.projectList() {
+		for path := range p.paths {
+			paths = append(paths, path)
+		}
+	}
+	d.watcher = w
 	d.mu.Unlock()
 
-	log.Printf("Background reindex completed for %d files", len(files))
+	for _, path := range paths {
+		if err := w.Add(path); err != nil {
+			log.Printf("Error watching %s: %v", path, err)
+		}
+	}
+	log.Printf("Watching %d directories for changes", w.Dirs())
+
+	go func() {
+		w.Run(d.ctx, d.applyWatchBatch)
+		w.Close()
+	}()
+	return nil
+}
+
+// applyWatchBatch re-indexes changed files and drops removed files, and
+// every file below a removed directory, from the index of the project that
+// owns them. Changes outside every open project (such as an evicted one)
+// are ignored.
+func (d *Daemon) applyWatchBatch(batch watch.Batch) {
+	d.mu.RLock()
+	changed := make(map[*project][]string)
+	removedPaths := make(map[*project][]string)
+	for _, path := range batch.Changed {
+		if p := d.projectOwning(path); p != nil {
+			changed[p] = append(changed[p], path)
+		}
+	}
+	for _, path := range batch.Removed {
+		if p := d.projectOwning(path); p != nil {
+			removedPaths[p] = append(removedPaths[p], path)
+		}
+	}
+	d.mu.RUnlock()
+
+	reindexed := make(map[*project]int)
+	for p, paths := range changed {
+		var embedFailures int
+		var embedErr error
+		for _, path := range paths {
+			if d.ctx.Err() != nil {
+				return
+			}
+			if err := d.reindexFile(p, path); err != nil {
+				log.Printf("Error re-indexing %s: %v", path, err)
+				if errors.Is(err, errEmbedding) {
+					embedFailures++
+					embedErr = err
+				}
+				continue
+			}
+			reindexed[p]++
+		}
+		d.providerFailed("watch", p, embedFailures, embedErr)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var totalReindexed, totalRemoved int
+	for _, p := range d.projectList() {
+		var removed int
+		for _, path := range removedPaths[p] {
+			if p.index.Remove(fileUnitKey(path)) {
+				removed++
+				continue
+			}
+			// Not a file we indexed; it may have been a directory
+			var stale []string
+			prefix := path + string(filepath.Separator)
+			p.index.IterVectors(func(id string, _ []float32, unit types.EmbeddingUnit) bool {
+				if strings.HasPrefix(unit.L1Data.Path, prefix) {
+					stale = append(stale, id)
+				}
+				return true
+			})
+			for _, id := range stale {
+				p.index.Remove(id)
+			}
+			removed += len(stale)
+		}
+
+		// Watched changes supersede pending notify calls for the same files
+		for _, path := range append(changed[p], removedPaths[p]...) {
+			if p.dirtyFiles[path] {
+				delete(p.dirtyFiles, path)
+				p.dirtyCount--
+			}
+		}
+
+		if reindexed[p]+removed > 0 {
+			d.saveProject(p, " after file changes")
+		}
+		if changes := len(changed[p]) + len(removedPaths[p]); changes > 0 {
+			d.notify(webhook.EventWatchChanges, p,
+				fmt.Sprintf("%d file changes in %s: %d re-indexed, %d removed", changes, p.displayRoot(), reindexed[p], removed),
+				map[string]any{
+					"changes":   changes,
+					"reindexed": reindexed[p],
+					"removed":   removed,
+				})
+		}
+		totalReindexed += reindexed[p]
+		totalRemoved += removed
+	}
+	log.Printf("Applied file changes: %d re-indexed, %d removed", totalReindexed, totalRemoved)
 }
 
 func (d *Daemon) handleStop(cmd Command) Response {
@@ -959,6 +2770,7 @@ func (d *Daemon) handleStop(cmd Command) Response {
 
 func (d *Daemon) Stop() {
 	d.cancel()
+	d.webhooks.Wait(webhookShutdownTimeout)
 }
 
 func main() {
@@ -966,6 +2778,9 @@ func main() {
 	configPath := ""
 	projectPath := ""
 	verbose := false
+	watchFiles := false
+	httpAddr := ""
+	logPath := ""
 
 	for i := 1; i < len(os.Args); i++ {
 		switch os.Args[i] {
@@ -986,6 +2801,18 @@ func main() {
 			}
 		case "-v", "--verbose", "-verbose":
 			verbose = true
+		case "-watch", "--watch":
+			watchFiles = true
+		case "-http", "--http":
+			if i+1 < len(os.Args) {
+				httpAddr = os.Args[i+1]
+				i++
+			}
+		case "-log", "--log":
+			if i+1 < len(os.Args) {
+				logPath = os.Args[i+1]
+				i++
+			}
 		case "-version", "--version":
 			fmt.Printf("gcqd version %s\n", version)
 			os.Exit(0)
@@ -995,6 +2822,9 @@ func main() {
 			fmt.Println("  -project PATH  Project root path for per-project daemon isolation")
 			fmt.Println("  -socket PATH  Unix socket path (default: auto-computed from project)")
 			fmt.Println("  -config PATH  Config file path")
+			fmt.Println("  -watch       Watch project files and re-index changes as they are saved")
+			fmt.Println("  -http ADDR   Also serve the JSON API over HTTP on ADDR (e.g. localhost:9848)")
+			fmt.Println("  -log PATH    Append logs to PATH, rotating it as it grows")
 			fmt.Println("  -v, -verbose Verbose logging")
 			fmt.Println("  -h, -help    Show this help")
 			os.Exit(0)
@@ -1020,17 +2850,64 @@ func main() {
 	if socketPath != "" {
 		cfg.SocketPath = socketPath
 	}
+	if watchFiles {
+		cfg.Daemon.Watch = true
+	}
+	if httpAddr != "" {
+		cfg.Daemon.HTTPAddr = httpAddr
+	}
+
+	if os.Getenv("GCQ_VERBOSE") == "true" {
+		verbose = true
+	}
 
-	if verbose || cfg.Verbose {
+	// A background daemon logs through a writer that rotates its log file
+	// as it grows. Its stdout and stderr follow the current file, so a
+	// panic or a printed warning doesn't end up in a rotated backup.
+	var stderr io.Writer = os.Stderr
+	logToFile := false
+	if logPath != "" {
+		logWriter, err := gcqdaemon.NewLogWriter(logPath, gcqdaemon.LogMaxSize, gcqdaemon.LogMaxBackups)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else {
+			defer logWriter.Close()
+			if err := logWriter.RedirectStdout(); err != nil {
+				fmt.Fprintf(logWriter, "Warning: %v\n", err)
+			}
+			stderr = logWriter
+			logToFile = true
+		}
+	}
+
+	switch {
+	case verbose || cfg.Verbose:
 		log.SetFlags(log.LstdFlags | log.Lshortfile)
-	} else {
+		log.SetOutput(stderr)
+	case logToFile:
+		log.SetFlags(log.LstdFlags)
+		log.SetOutput(stderr)
+	default:
 		log.SetFlags(0)
 		log.SetOutput(io.Discard)
 	}
 
+	// Grammar packs run as native code, so they only come from the user
+	// config and never from the project being served
+	languages, err := config.LoadLanguages()
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	for _, err := range langpack.Install(context.Background(), languages) {
+		log.Printf("Warning: %v", err)
+	}
+
+	// Fatal errors go to stderr even when logging is off, so a daemon
+	// started in the background leaves a reason in its log file.
 	daemon, err := NewDaemon(cfg, projectPath)
 	if err != nil {
-		log.Fatalf("Failed to create daemon: %v", err)
+		fmt.Fprintf(stderr, "Failed to create daemon: %v\n", err)
+		os.Exit(1)
 	}
 
 	log.Printf("Starting gcqd v%s", version)
@@ -1046,9 +2923,44 @@ func main() {
 		}
 	}
 
+	instance := daemon.registerInstance(projectPath)
+
 	if err := daemon.StartSocketServer(); err != nil {
-		log.Fatalf("Server error: %v", err)
+		gcqdaemon.UnregisterInstance(instance)
+		fmt.Fprintf(stderr, "Server error: %v\n", err)
+		os.Exit(1)
 	}
 
+	gcqdaemon.UnregisterInstance(instance)
 	log.Println("gcqd stopped")
 }
+
+// registerInstance records this daemon in the instance registry so it can be
+// listed with gcq status --all and selected with --instance. It returns the
+// instance name.
+func (d *Daemon) registerInstance(projectPath string) string {
+	inst := &gcqdaemon.Instance{
+		Name:       gcqdaemon.InstanceName(projectPath),
+		PID:        os.Getpid(),
+		SocketPath: d.socketPath,
+		DaemonDir:  gcqdaemon.DaemonDir(),
+		StartedAt:  time.Now(),
+	}
+	if projectPath != "" {
+		if absPath, err := filepath.Abs(projectPath); err == nil {
+			inst.ProjectPath = absPath
+			inst.DaemonDir = filepath.Join(absPath, ".gcq")
+		}
+	}
+	if isWindows() {
+		inst.TCPPort = os.Getenv("GCQ_TCP_PORT")
+		if inst.TCPPort == "" {
+			inst.TCPPort = DefaultTCPPort
+		}
+	}
+
+	if err := gcqdaemon.RegisterInstance(inst); err != nil {
+		log.Printf("Warning: could not register daemon instance: %v", err)
+	}
+	return inst.Name
+}
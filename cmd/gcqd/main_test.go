@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/l3aro/go-context-query/internal/config"
+	gcqdaemon "github.com/l3aro/go-context-query/internal/daemon"
+	"github.com/l3aro/go-context-query/internal/scanner"
+	"github.com/l3aro/go-context-query/pkg/callgraph"
+	"github.com/l3aro/go-context-query/pkg/extractor"
+	"github.com/l3aro/go-context-query/pkg/fixture"
+)
+
+func TestMain(m *testing.M) {
+	if os.Getenv("GCQD_TEST_DAEMON") == "1" {
+		runTestDaemon()
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// runTestDaemon runs gcqd with the test binary's arguments. Once the
+// socket is up it prints to stdout and panics, as a stray warning or a
+// bug would in a background daemon.
+func runTestDaemon() {
+	socketPath := os.Getenv("GCQD_TEST_SOCKET")
+	go func() {
+		for range 2000 {
+			if _, err := os.Stat(socketPath); err == nil {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		fmt.Println("printed after rotation")
+		panic("test panic after rotation")
+	}()
+	main()
+}
+
+// scanFixture writes a generated fixture to a temp dir and returns the
+// source files scanned in it
+func scanFixture(t *testing.T, files int) []scanner.FileInfo {
+	t.Helper()
+	root := t.TempDir()
+	if _, err := fixture.Generate(root, fixture.Options{Files: files, Seed: 1}); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	scanned, err := scanner.New(scanner.Options{}).Scan(root)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	var sources []scanner.FileInfo
+	for _, f := range scanned {
+		if extractor.GetLanguageRegistry().IsSupported(f.FullPath) {
+			sources = append(sources, f)
+		}
+	}
+	if len(sources) < files {
+		t.Fatalf("scanned %d source files, want at least %d", len(sources), files)
+	}
+	return sources
+}
+
+func TestExtractFilesOrder(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	files := scanFixture(t, 40)
+	d := &Daemon{callGraph: callgraph.NewBuilder()}
+
+	var yielded []string
+	d.extractFiles(context.Background(), files, func(file scanner.FileInfo, extracted extractedFile) {
+		yielded = append(yielded, file.Path)
+		if extracted.err != nil {
+			t.Errorf("extracting %s: %v", file.Path, extracted.err)
+			return
+		}
+		if extracted.moduleInfo == nil || extracted.moduleInfo.Path != file.FullPath {
+			t.Errorf("file %s yielded with the module of another file", file.Path)
+		}
+	})
+
+	if len(yielded) != len(files) {
+		t.Fatalf("yielded %d files, want %d", len(yielded), len(files))
+	}
+	for i, file := range files {
+		if yielded[i] != file.Path {
+			t.Fatalf("file %d yielded is %s, want %s", i, yielded[i], file.Path)
+		}
+	}
+}
+
+func TestExtractFilesCancel(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	files := scanFixture(t, 40)
+	d := &Daemon{callGraph: callgraph.NewBuilder()}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	yielded := 0
+	d.extractFiles(ctx, files, func(scanner.FileInfo, extractedFile) {
+		yielded++
+		if yielded == 5 {
+			cancel()
+		}
+	})
+
+	if yielded != 5 {
+		t.Errorf("yielded %d files after cancelling on the 5th, want 5", yielded)
+	}
+}
+
+// newTestDaemon starts a daemon with the mock embedding provider for the
+// project at root, stopped when the test ends
+func newTestDaemon(t *testing.T, root string) *Daemon {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.Provider = config.ProviderMock
+	d, err := NewDaemon(cfg, root)
+	if err != nil {
+		t.Fatalf("NewDaemon failed: %v", err)
+	}
+	t.Cleanup(d.Stop)
+	return d
+}
+
+func TestRefreshLoop(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, src string) string {
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		return path
+	}
+	edited := write("edited.go", "package p\n\nfunc Before() {}\n")
+	deleted := write("deleted.go", "package p\n\nfunc Gone() {}\n")
+	unchanged := write("unchanged.go", "package p\n\nfunc Same() {}\n")
+
+	d := newTestDaemon(t, root)
+	p := d.defaultProject
+	for _, path := range []string{edited, deleted, unchanged} {
+		if err := d.reindexFile(p, path); err != nil {
+			t.Fatalf("reindexFile(%s) failed: %v", path, err)
+		}
+	}
+	// Blank the indexed functions of the unchanged file, which a needless
+	// re-index would bring back
+	vector, unit, _ := p.index.Get(fileUnitKey(unchanged))
+	unit.L1Data.Functions = nil
+	if err := p.index.Update(fileUnitKey(unchanged), vector, unit); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	// Edited while the daemon was down: the file is older than the daemon
+	// but newer than its indexed state
+	write("edited.go", "package p\n\nfunc After() {}\n")
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(edited, past, past); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+	if err := os.Remove(deleted); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	added := write("added.go", "package p\n\nfunc New() {}\n")
+
+	d.mu.Lock()
+	d.lastActivity = time.Now().Add(-2 * refreshIdleThreshold)
+	d.mu.Unlock()
+	go d.runRefreshLoop(10 * time.Millisecond)
+
+	refreshed := func() bool {
+		d.mu.RLock()
+		defer d.mu.RUnlock()
+		_, unit, ok := p.index.Get(fileUnitKey(edited))
+		if !ok || len(unit.L1Data.Functions) != 1 || unit.L1Data.Functions[0].Name != "After" {
+			return false
+		}
+		if _, _, ok := p.index.Get(fileUnitKey(deleted)); ok {
+			return false
+		}
+		_, _, ok = p.index.Get(fileUnitKey(added))
+		return ok && !p.reindexInProgress
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for !refreshed() {
+		if time.Now().After(deadline) {
+			t.Fatal("refresh did not re-index the edited file, drop the deleted one and index the new one")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	d.mu.RLock()
+	_, unit, ok := p.index.Get(fileUnitKey(unchanged))
+	d.mu.RUnlock()
+	if !ok || len(unit.L1Data.Functions) != 0 {
+		t.Errorf("unchanged file was re-indexed or dropped")
+	}
+}
+
+func TestDaemonLogRotation(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows doesn't rotate a log the daemon's stdout holds open")
+	}
+	dir := t.TempDir()
+	project := filepath.Join(dir, "project")
+	if err := os.Mkdir(project, 0755); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	socketPath := filepath.Join(dir, "gcqd.sock")
+	logPath := filepath.Join(dir, gcqdaemon.LogFileName)
+
+	// A log already at its limit is rotated by the daemon's first line
+	if err := os.WriteFile(logPath, []byte("before rotation\n"), 0644); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+	if err := os.Truncate(logPath, gcqdaemon.LogMaxSize); err != nil {
+		t.Fatalf("Truncate failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, os.Args[0], "-project", project, "-socket", socketPath, "-log", logPath)
+	cmd.Dir = project
+	cmd.Env = append(os.Environ(),
+		"GCQD_TEST_DAEMON=1",
+		"GCQD_TEST_SOCKET="+socketPath,
+		"GCQ_VERBOSE=false",
+		"GCQ_PROVIDER=mock",
+		"GCQ_DAEMON_DIR="+dir,
+		"GCQ_REGISTRY_DIR="+dir,
+		"GCQ_USER_CONFIG="+filepath.Join(dir, "config.yaml"),
+	)
+	if out, err := cmd.CombinedOutput(); err == nil {
+		t.Fatalf("daemon exited without panicking: %s", out)
+	} else if len(out) > 0 {
+		t.Errorf("daemon wrote to the stdout it was started with: %s", out)
+	}
+
+	backup, err := os.ReadFile(logPath + ".1")
+	if err != nil {
+		t.Fatalf("log was not rotated: %v", err)
+	}
+	if !strings.HasPrefix(string(backup), "before rotation\n") || len(backup) != gcqdaemon.LogMaxSize {
+		t.Errorf("backup holds %d bytes, want only the %d written before rotation", len(backup), gcqdaemon.LogMaxSize)
+	}
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	for _, want := range []string{"Starting gcqd", "printed after rotation", "panic: test panic after rotation"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("log after rotation lacks %q:\n%s", want, data)
+		}
+	}
+}
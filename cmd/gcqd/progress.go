@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// Progress events reported for each file while extract or warm runs
+const (
+	progressExtracted = "extracted"
+	progressEmbedded  = "embedded"
+	progressError     = "error"
+)
+
+// ProgressEvent reports one file's progress through extract or warm. Done
+// counts the files finished so far, embedded or failed, out of Total.
+type ProgressEvent struct {
+	Event string `json:"event"`
+	File  string `json:"file,omitempty"`
+	Error string `json:"error,omitempty"`
+	Done  int    `json:"done"`
+	Total int    `json:"total"`
+}
+
+// progressFunc receives progress events; a nil progressFunc drops them
+type progressFunc func(ProgressEvent)
+
+func (f progressFunc) emit(ev ProgressEvent) {
+	if f != nil {
+		f(ev)
+	}
+}
+
+// handleIndexStream runs extract or warm. When the params set "progress",
+// each ProgressEvent is written to the client as an "<type>_batch" frame
+// before the final response, so clients can show progress on large repos.
+func (d *Daemon) handleIndexStream(cmd Command, encoder *json.Encoder) Response {
+	d.mu.Lock()
+	d.lastActivity = time.Now()
+	d.mu.Unlock()
+
+	var opts struct {
+		Progress bool `json:"progress"`
+	}
+	if len(cmd.Params) > 0 {
+		json.Unmarshal(cmd.Params, &opts)
+	}
+
+	var progress progressFunc
+	if opts.Progress {
+		failed := false
+		progress = func(ev ProgressEvent) {
+			if failed {
+				return
+			}
+			eventJSON, err := json.Marshal(ev)
+			if err == nil {
+				err = encoder.Encode(Response{ID: cmd.ID, Type: cmd.Type + "_batch", Result: eventJSON})
+			}
+			if err != nil {
+				// The client went away; finish indexing without reporting
+				log.Printf("Progress write error: %v", err)
+				failed = true
+			}
+		}
+	}
+
+	if cmd.Type == "warm" {
+		return d.handleWarm(cmd, progress)
+	}
+	return d.handleExtract(cmd, progress)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/l3aro/go-context-query/internal/config"
+	"github.com/l3aro/go-context-query/internal/scanner"
+)
+
+// configWatchInterval is how often the daemon checks the config files of
+// open projects for changes
+const configWatchInterval = 2 * time.Second
+
+// projectSettings are the settings of a project's config that the daemon
+// applies to the project while running, without a restart
+type projectSettings struct {
+	Ignore      []string
+	IgnoreFiles []string
+	MinScore    float64
+}
+
+// settingsFrom returns the live settings of cfg
+func settingsFrom(cfg *config.Config) projectSettings {
+	return projectSettings{
+		Ignore:      slices.Clone(cfg.Daemon.Ignore),
+		IgnoreFiles: slices.Clone(cfg.Daemon.IgnoreFiles),
+		MinScore:    cfg.Daemon.MinScore,
+	}
+}
+
+// diff describes each setting that differs in next, such as
+// "daemon.min_score 0 -> 0.4"
+func (s projectSettings) diff(next projectSettings) []string {
+	var changes []string
+	if !slices.Equal(s.Ignore, next.Ignore) {
+		changes = append(changes, fmt.Sprintf("daemon.ignore %q -> %q", s.Ignore, next.Ignore))
+	}
+	if !slices.Equal(s.IgnoreFiles, next.IgnoreFiles) {
+		changes = append(changes, fmt.Sprintf("daemon.ignore_files %q -> %q", s.IgnoreFiles, next.IgnoreFiles))
+	}
+	if s.MinScore != next.MinScore {
+		changes = append(changes, fmt.Sprintf("daemon.min_score %g -> %g", s.MinScore, next.MinScore))
+	}
+	return changes
+}
+
+// configPath returns the project's config file; "" for the global project
+func (p *project) configPath() string {
+	if p.root == "" {
+		return ""
+	}
+	return filepath.Join(p.root, ".gcq", "config.yaml")
+}
+
+// applySettings makes the project scan and search with s. Callers must
+// hold d.mu.
+func (p *project) applySettings(s projectSettings) {
+	opts := scanner.DefaultOptions()
+	opts.Ignore = s.Ignore
+	for _, path := range s.IgnoreFiles {
+		if p.root != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(p.root, path)
+		}
+		opts.IgnoreFiles = append(opts.IgnoreFiles, path)
+	}
+	p.settings = s
+	p.scanner = scanner.New(opts)
+}
+
+// projectScanner returns the scanner of p's current settings
+func (d *Daemon) projectScanner(p *project) *scanner.Scanner {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return p.scanner
+}
+
+// readProjectSettings returns the settings in p's config file and the
+// file's modification time. A project without a config file uses the
+// daemon's settings and a zero time.
+func (d *Daemon) readProjectSettings(p *project) (projectSettings, time.Time, error) {
+	path := p.configPath()
+	if path == "" {
+		return settingsFrom(d.config), time.Time{}, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return settingsFrom(d.config), time.Time{}, nil
+	}
+	cfg, err := config.LoadFromFile(path)
+	if err != nil {
+		return projectSettings{}, info.ModTime(), err
+	}
+	return settingsFrom(cfg), info.ModTime(), nil
+}
+
+// runConfigWatch applies edits to the config files of open projects as
+// they are saved
+func (d *Daemon) runConfigWatch() {
+	ticker := time.NewTicker(configWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			d.mu.RLock()
+			projects := d.projectList()
+			d.mu.RUnlock()
+			for _, p := range projects {
+				d.reloadProjectConfig(p)
+			}
+		}
+	}
+}
+
+// reloadProjectConfig re-reads p's config file when it changed since it was
+// last read, applies the settings that differ and logs them. A config that
+// fails to load keeps the current settings.
+func (d *Daemon) reloadProjectConfig(p *project) {
+	path := p.configPath()
+	if path == "" {
+		return
+	}
+	var modTime time.Time
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime()
+	}
+	d.mu.RLock()
+	unchanged := modTime.Equal(p.configModTime)
+	d.mu.RUnlock()
+	if unchanged {
+		return
+	}
+
+	next, modTime, err := d.readProjectSettings(p)
+	d.mu.Lock()
+	p.configModTime = modTime
+	var changes []string
+	if err == nil {
+		changes = p.settings.diff(next)
+		if len(changes) > 0 {
+			p.applySettings(next)
+		}
+	}
+	d.mu.Unlock()
+
+	switch {
+	case err != nil:
+		log.Printf("Config change for %s not applied: %v", p.displayRoot(), err)
+	case len(changes) > 0:
+		log.Printf("Applied config change for %s: %s", p.displayRoot(), strings.Join(changes, ", "))
+	}
+}
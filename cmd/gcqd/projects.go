@@ -0,0 +1,380 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/l3aro/go-context-query/internal/config"
+	"github.com/l3aro/go-context-query/internal/scanner"
+	"github.com/l3aro/go-context-query/pkg/index"
+	"github.com/l3aro/go-context-query/pkg/search"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// project is one entry in the daemon's project registry: a root path with
+// its own file-level index, index metadata and dirty-file tracking. Requests
+// pick a project with their "project" parameter; without one they use the
+// daemon's default project (its -project root, or a global index when the
+// daemon was started without one). All fields are guarded by Daemon.mu.
+type project struct {
+	// root is the absolute project root; "" for the global project
+	root      string
+	index     *index.VectorIndex
+	searcher  *search.Searcher
+	indexPath string
+
+	// paths registered with warm, re-indexed by refresh and the watcher
+	paths map[string]bool
+
+	// Settings from the project's config file, reapplied when the file
+	// changes, and the scanner built from them
+	settings      projectSettings
+	scanner       *scanner.Scanner
+	configModTime time.Time
+
+	// Dirty tracking for file change notifications
+	dirtyFiles        map[string]bool
+	dirtyCount        int
+	reindexInProgress bool
+
+	// indexing counts extract and warm runs, queued or running, using the
+	// project; it can't be evicted while any are pending
+	indexing int
+
+	lastUsed time.Time
+}
+
+// projectMetadata is saved next to a project's index
+type projectMetadata struct {
+	Root      string    `json:"root,omitempty"`
+	Model     string    `json:"model"`
+	Dimension int       `json:"dimension"`
+	Count     int       `json:"count"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// metadataPath returns the metadata file kept next to the index
+func (p *project) metadataPath() string {
+	return strings.TrimSuffix(p.indexPath, filepath.Ext(p.indexPath)) + ".json"
+}
+
+// owns reports whether path belongs to the project: it lies under the root
+// or under one of the registered paths
+func (p *project) owns(path string) bool {
+	if p.root != "" && isWithin(p.root, path) {
+		return true
+	}
+	for registered := range p.paths {
+		if isWithin(registered, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWithin reports whether path is dir or lies below it
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// removeDeleted drops the file units of files that no longer exist from
+// the index, returning how many were removed. Callers must hold d.mu.
+func (p *project) removeDeleted() int {
+	var stale []string
+	p.index.IterVectors(func(id string, _ []float32, unit types.EmbeddingUnit) bool {
+		path := unit.L1Data.Path
+		if path == "" || id != fileUnitKey(path) {
+			return true
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			stale = append(stale, id)
+		}
+		return true
+	})
+	for _, id := range stale {
+		p.index.Remove(id)
+	}
+	return len(stale)
+}
+
+// compact reclaims the storage of removed entries when policy says they
+// are worth it, returning how many were reclaimed
+func (p *project) compact(policy index.CompactionPolicy) int {
+	if !policy.Due(p.index) {
+		return 0
+	}
+	return p.index.Compact()
+}
+
+// save writes the project's index and metadata
+func (p *project) save(model string) error {
+	if err := p.index.Save(p.indexPath); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(projectMetadata{
+		Root:      p.root,
+		Model:     model,
+		Dimension: p.index.Dimension(),
+		Count:     p.index.Count(),
+		UpdatedAt: time.Now(),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling metadata: %w", err)
+	}
+	if err := os.WriteFile(p.metadataPath(), data, 0644); err != nil {
+		return fmt.Errorf("writing metadata: %w", err)
+	}
+	return nil
+}
+
+// saveProject saves p, logging failures the way the handlers always have.
+// Callers must hold d.mu.
+func (d *Daemon) saveProject(p *project, context string) {
+	p.compact(d.compactionPolicy())
+	if err := p.save(d.getModelName()); err != nil {
+		log.Printf("Error saving index%s: %v", context, err)
+	}
+}
+
+// openProject loads (or creates) the index of the project rooted at root.
+// Callers must hold d.mu.
+func (d *Daemon) openProject(root string) *project {
+	p := &project{
+		root:       root,
+		index:      index.NewVectorIndex(d.getEmbeddingDimension()),
+		indexPath:  computeIndexPath(root),
+		paths:      make(map[string]bool),
+		dirtyFiles: make(map[string]bool),
+		lastUsed:   time.Now(),
+	}
+
+	if root != "" {
+		p.paths[root] = true
+		if err := os.MkdirAll(filepath.Join(root, ".gcq"), 0755); err != nil {
+			log.Printf("Warning: could not create .gcq directory: %v", err)
+		}
+	}
+
+	settings, modTime, err := d.readProjectSettings(p)
+	if err != nil {
+		log.Printf("Warning: using daemon settings for %s: %v", p.displayRoot(), err)
+		settings = settingsFrom(d.config)
+	}
+	p.configModTime = modTime
+	p.applySettings(settings)
+
+	if err := p.index.Load(p.indexPath); errors.Is(err, index.ErrFormat) {
+		log.Printf("Index of %s was written by another version of gcq and can't be read; starting a new one: %v", p.displayRoot(), err)
+	} else if err != nil {
+		log.Printf("No existing index found for %s or error loading: %v", p.displayRoot(), err)
+	}
+	p.searcher = search.NewSearcher(d.embedderFor(root), p.index)
+
+	d.projects[root] = p
+	return p
+}
+
+// displayRoot names the project in logs and responses
+func (p *project) displayRoot() string {
+	if p.root == "" {
+		return "global"
+	}
+	return p.root
+}
+
+// projectFor returns the project named by a request's "project" parameter,
+// opening it on first use. An empty name selects the default project.
+func (d *Daemon) projectFor(name string) (*project, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.projectForLocked(name)
+}
+
+// projectForLocked is projectFor for callers that hold d.mu
+func (d *Daemon) projectForLocked(name string) (*project, error) {
+	if name == "" {
+		d.defaultProject.lastUsed = time.Now()
+		return d.defaultProject, nil
+	}
+
+	root, err := filepath.Abs(name)
+	if err != nil {
+		return nil, fmt.Errorf("resolving project: %w", err)
+	}
+	if p, ok := d.projects[root]; ok {
+		p.lastUsed = time.Now()
+		return p, nil
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("project %s: %w", root, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("project %s is not a directory", root)
+	}
+
+	log.Printf("Opening project %s", root)
+	return d.openProject(root), nil
+}
+
+// projectOwning returns the open project a file belongs to, preferring the
+// project with the deepest root, or nil when no open project owns it.
+// Callers must hold d.mu.
+func (d *Daemon) projectOwning(path string) *project {
+	var best *project
+	for _, p := range d.projects {
+		if !p.owns(path) {
+			continue
+		}
+		if best == nil || len(p.root) > len(best.root) {
+			best = p
+		}
+	}
+	return best
+}
+
+// projectList snapshots the open projects. Callers must hold d.mu.
+func (d *Daemon) projectList() []*project {
+	projects := make([]*project, 0, len(d.projects))
+	for _, p := range d.projects {
+		projects = append(projects, p)
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].root < projects[j].root })
+	return projects
+}
+
+type ProjectsParams struct {
+	// Action is "list" (default), "evict" or "compact". Compact without a
+	// project compacts every open project.
+	Action  string `json:"action,omitempty"`
+	Project string `json:"project,omitempty"`
+}
+
+// projectInfo describes an open project in a projects response
+type projectInfo struct {
+	Root              string    `json:"root"`
+	Default           bool      `json:"default"`
+	IndexPath         string    `json:"index_path"`
+	IndexCount        int       `json:"index_count"`
+	Dimension         int       `json:"dimension"`
+	SemanticCount     int       `json:"semantic_count,omitempty"`
+	Paths             []string  `json:"paths,omitempty"`
+	DirtyCount        int       `json:"dirty_count"`
+	ReindexInProgress bool      `json:"reindex_in_progress"`
+	LastUsed          time.Time `json:"last_used"`
+	// Quota is set when daemon.project_quota limits anything
+	Quota *quotaStatus `json:"quota,omitempty"`
+}
+
+func (d *Daemon) handleProjects(cmd Command) Response {
+	var params ProjectsParams
+	if len(cmd.Params) > 0 {
+		if err := json.Unmarshal(cmd.Params, &params); err != nil {
+			return Response{ID: cmd.ID, Error: fmt.Sprintf("invalid params: %v", err)}
+		}
+	}
+
+	var result interface{}
+	switch params.Action {
+	case "", "list":
+		d.mu.RLock()
+		projects := make([]projectInfo, 0, len(d.projects))
+		for _, p := range d.projectList() {
+			info := projectInfo{
+				Root:              p.displayRoot(),
+				Default:           p == d.defaultProject,
+				IndexPath:         p.indexPath,
+				IndexCount:        p.index.Count(),
+				Dimension:         p.index.Dimension(),
+				DirtyCount:        p.dirtyCount,
+				ReindexInProgress: p.reindexInProgress,
+				LastUsed:          p.lastUsed,
+			}
+			if searcher, ok := d.semanticSearchers[p.root]; ok {
+				info.SemanticCount, _ = searcher.IndexStats()
+			}
+			if d.config.Daemon.ProjectQuota != (config.ProjectQuota{}) {
+				quota := d.quotaStatus(p)
+				info.Quota = &quota
+			}
+			for path := range p.paths {
+				info.Paths = append(info.Paths, path)
+			}
+			sort.Strings(info.Paths)
+			projects = append(projects, info)
+		}
+		d.mu.RUnlock()
+		result = map[string]interface{}{"projects": projects, "count": len(projects)}
+
+	case "evict":
+		root, err := d.evictProject(params.Project)
+		if err != nil {
+			return Response{ID: cmd.ID, Error: err.Error()}
+		}
+		result = map[string]interface{}{"evicted": root}
+
+	case "compact":
+		compacted, err := d.compactProjects(params.Project)
+		if err != nil {
+			return Response{ID: cmd.ID, Error: err.Error()}
+		}
+		result = map[string]interface{}{"compacted": compacted}
+
+	default:
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("unknown action: %s (must be 'list', 'evict' or 'compact')", params.Action)}
+	}
+
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return Response{ID: cmd.ID, Error: fmt.Sprintf("marshal error: %v", err)}
+	}
+
+	return Response{
+		ID:     cmd.ID,
+		Type:   "projects",
+		Result: resultJSON,
+	}
+}
+
+// evictProject saves a project's index and drops it, and its semantic
+// index, from memory. The next request naming the project reopens it.
+func (d *Daemon) evictProject(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("project is required")
+	}
+	root, err := filepath.Abs(name)
+	if err != nil {
+		return "", fmt.Errorf("resolving project: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	p, ok := d.projects[root]
+	if !ok {
+		return "", fmt.Errorf("project %s is not open", root)
+	}
+	if p == d.defaultProject {
+		return "", fmt.Errorf("cannot evict the default project %s", root)
+	}
+	if p.reindexInProgress || p.indexing > 0 {
+		return "", fmt.Errorf("project %s is being re-indexed", root)
+	}
+
+	d.saveProject(p, " for evicted project")
+	delete(d.projects, root)
+	delete(d.semanticSearchers, root)
+	delete(d.semanticMetadata, root)
+	log.Printf("Evicted project %s", root)
+	return root, nil
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/l3aro/go-context-query/internal/config"
+	"github.com/l3aro/go-context-query/pkg/embed"
+	"github.com/l3aro/go-context-query/pkg/index"
+)
+
+// errQuotaExceeded marks requests refused by a project quota. They are not
+// provider failures.
+var errQuotaExceeded = errors.New("project quota exceeded")
+
+// embedQuotaWindow is the sliding window of embed_calls_per_hour
+const embedQuotaWindow = time.Hour
+
+// Quota names reported in quotaStatus.Exceeded
+const (
+	quotaIndexMemory = "index_memory"
+	quotaEmbedCalls  = "embed_calls"
+	quotaJobs        = "jobs"
+)
+
+// quotas tracks per-project usage against daemon.project_quota. Usage is
+// keyed by project root, so evicting and reopening a project keeps its
+// embedding history. It has its own lock so embedding never waits on
+// Daemon.mu.
+type quotas struct {
+	limits config.ProjectQuota
+
+	mu sync.Mutex
+	// embedCalls holds the times of each root's embedding calls within the
+	// last embedQuotaWindow, oldest first
+	embedCalls map[string][]time.Time
+}
+
+func newQuotas(limits config.ProjectQuota) *quotas {
+	return &quotas{limits: limits, embedCalls: make(map[string][]time.Time)}
+}
+
+// recentLocked drops the calls of root older than the window and returns
+// the rest. Callers must hold q.mu.
+func (q *quotas) recentLocked(root string, now time.Time) []time.Time {
+	calls := q.embedCalls[root]
+	cutoff := now.Add(-embedQuotaWindow)
+	i := sort.Search(len(calls), func(i int) bool { return calls[i].After(cutoff) })
+	calls = calls[i:]
+	if len(calls) == 0 {
+		delete(q.embedCalls, root)
+	} else {
+		q.embedCalls[root] = calls
+	}
+	return calls
+}
+
+// allowEmbed records an embedding call for root, or refuses it when root
+// has used its calls for the hour
+func (q *quotas) allowEmbed(root string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	calls := q.recentLocked(root, now)
+	if limit := q.limits.EmbedCallsPerHour; limit > 0 && len(calls) >= limit {
+		retry := calls[0].Add(embedQuotaWindow).Sub(now).Round(time.Second)
+		return fmt.Errorf("%w: %d embedding calls in the last hour (limit %d), retry in %s",
+			errQuotaExceeded, len(calls), limit, retry)
+	}
+	if q.limits.EmbedCallsPerHour > 0 {
+		// Unlimited projects aren't tracked
+		q.embedCalls[root] = append(calls, now)
+	}
+	return nil
+}
+
+// embedCallsLastHour returns root's embedding calls within the window
+func (q *quotas) embedCallsLastHour(root string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.recentLocked(root, time.Now()))
+}
+
+// indexBytes estimates the memory of an index's vectors
+func indexBytes(idx *index.VectorIndex) int64 {
+	return int64(idx.Count()) * int64(idx.Dimension()) * 4
+}
+
+// indexFull reports whether idx has reached max_index_mb
+func (q *quotas) indexFull(idx *index.VectorIndex) bool {
+	return q.limits.MaxIndexMB > 0 && indexBytes(idx) >= int64(q.limits.MaxIndexMB)<<20
+}
+
+// quotaEmbedder charges a project's embedding calls to its quota
+type quotaEmbedder struct {
+	embed.Provider
+	quotas *quotas
+	root   string
+}
+
+func (e quotaEmbedder) EmbedContext(ctx context.Context, texts []string) ([][]float32, error) {
+	if err := e.quotas.allowEmbed(e.root); err != nil {
+		return nil, err
+	}
+	return e.Provider.EmbedContext(ctx, texts)
+}
+
+// embedderFor returns the embedding provider for work on the project rooted
+// at root
+func (d *Daemon) embedderFor(root string) embed.Provider {
+	if d.quotas.limits.EmbedCallsPerHour == 0 {
+		return d.embedder
+	}
+	return quotaEmbedder{Provider: d.embedder, quotas: d.quotas, root: root}
+}
+
+// indexQuotaErr refuses adding the file unit key to p's index once the
+// index has reached max_index_mb. Units already indexed may still be
+// updated. Callers must hold d.mu.
+func (d *Daemon) indexQuotaErr(p *project, key string) error {
+	if !d.quotas.indexFull(p.index) {
+		return nil
+	}
+	if _, _, ok := p.index.Get(key); ok {
+		return nil
+	}
+	return fmt.Errorf("%w: index of %s is at its %d MB limit", errQuotaExceeded, p.displayRoot(), d.quotas.limits.MaxIndexMB)
+}
+
+// quotaStatus reports a project's usage against its quotas. Zero limits are
+// unlimited.
+type quotaStatus struct {
+	Root               string  `json:"root"`
+	IndexMB            float64 `json:"index_mb"`
+	MaxIndexMB         int     `json:"max_index_mb"`
+	EmbedCallsLastHour int     `json:"embed_calls_last_hour"`
+	EmbedCallsPerHour  int     `json:"embed_calls_per_hour"`
+	// Jobs counts the extract and warm runs queued or running
+	Jobs    int `json:"jobs"`
+	MaxJobs int `json:"max_jobs"`
+	// Exceeded names the quotas at their limit: "index_memory",
+	// "embed_calls" or "jobs"
+	Exceeded []string `json:"exceeded,omitempty"`
+}
+
+// quotaStatus reports p's quota usage. Callers must hold d.mu.
+func (d *Daemon) quotaStatus(p *project) quotaStatus {
+	limits := d.quotas.limits
+	s := quotaStatus{
+		Root:               p.displayRoot(),
+		IndexMB:            float64(indexBytes(p.index)*100>>20) / 100,
+		MaxIndexMB:         limits.MaxIndexMB,
+		EmbedCallsLastHour: d.quotas.embedCallsLastHour(p.root),
+		EmbedCallsPerHour:  limits.EmbedCallsPerHour,
+		Jobs:               p.indexing,
+		MaxJobs:            limits.MaxJobs,
+	}
+	if d.quotas.indexFull(p.index) {
+		s.Exceeded = append(s.Exceeded, quotaIndexMemory)
+	}
+	if limits.EmbedCallsPerHour > 0 && s.EmbedCallsLastHour >= limits.EmbedCallsPerHour {
+		s.Exceeded = append(s.Exceeded, quotaEmbedCalls)
+	}
+	if limits.MaxJobs > 0 && s.Jobs >= limits.MaxJobs {
+		s.Exceeded = append(s.Exceeded, quotaJobs)
+	}
+	return s
+}
@@ -0,0 +1,12 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import "context"
+
+// serveReadyPipe is a no-op: where Unix sockets are used, gcq start waits
+// for the socket to accept connections
+func serveReadyPipe(ctx context.Context, port string) error {
+	return nil
+}
@@ -0,0 +1,62 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// readyPipePath names the pipe the daemon serving TCP port serves once it
+// accepts connections; gcq start waits for it to appear. It must match
+// readyPipePath in internal/daemon.
+func readyPipePath(port string) string {
+	return `\\.\pipe\gcqd-` + port
+}
+
+// serveReadyPipe creates the readiness pipe for port and accepts probes on
+// it until ctx is done. A probe only opens the pipe; nothing is read or
+// written.
+func serveReadyPipe(ctx context.Context, port string) error {
+	path := readyPipePath(port)
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return err
+	}
+	// A single instance, refused while another daemon holds the name
+	pipe, err := windows.CreateNamedPipe(name,
+		windows.PIPE_ACCESS_OUTBOUND|windows.FILE_FLAG_FIRST_PIPE_INSTANCE,
+		windows.PIPE_TYPE_BYTE|windows.PIPE_WAIT|windows.PIPE_REJECT_REMOTE_CLIENTS,
+		1, 0, 0, 0, nil)
+	if err != nil {
+		return fmt.Errorf("creating pipe %s: %w", path, err)
+	}
+
+	go func() {
+		defer windows.CloseHandle(pipe)
+		for {
+			err := windows.ConnectNamedPipe(pipe, nil)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil && !errors.Is(err, windows.ERROR_PIPE_CONNECTED) {
+				return
+			}
+			windows.DisconnectNamedPipe(pipe)
+		}
+	}()
+	go func() {
+		// ConnectNamedPipe blocks until a client opens the pipe, so open
+		// it once more to let the loop see ctx is done
+		<-ctx.Done()
+		if f, err := os.Open(path); err == nil {
+			f.Close()
+		}
+	}()
+	return nil
+}
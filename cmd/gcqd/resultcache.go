@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/l3aro/go-context-query/pkg/cache"
+)
+
+// maxResultCacheBytes bounds the memory held by cached search responses
+const maxResultCacheBytes = 64 << 20
+
+// resultCache keeps marshaled search responses so an agent retrying the
+// same search is answered without embedding the query again. Keys include
+// the generation of the searched index, so any index update makes older
+// entries unreachable; they age out of the LRU.
+type resultCache struct {
+	lru    *cache.LRUCache
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// resultCacheStats is reported by status
+type resultCacheStats struct {
+	Entries int   `json:"entries"`
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+}
+
+// newResultCache returns a cache of size entries, or nil when size is 0
+func newResultCache(size int) *resultCache {
+	if size <= 0 {
+		return nil
+	}
+	return &resultCache{lru: cache.New(cache.Options{MaxSize: size, MaxBytes: maxResultCacheBytes})}
+}
+
+// searchCacheKey identifies a search by the contents of the index it runs
+// against and its normalized params
+func searchCacheKey(generation uint64, params SearchParams) (string, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d:%s", generation, data), nil
+}
+
+func (c *resultCache) get(key string) (json.RawMessage, bool) {
+	if c == nil {
+		return nil, false
+	}
+	if v, ok := c.lru.Get(key); ok {
+		c.hits.Add(1)
+		return json.RawMessage(v.([]byte)), true
+	}
+	c.misses.Add(1)
+	return nil, false
+}
+
+func (c *resultCache) put(key string, result json.RawMessage) {
+	if c == nil {
+		return
+	}
+	c.lru.Set(key, []byte(result))
+}
+
+func (c *resultCache) stats() resultCacheStats {
+	return resultCacheStats{Entries: c.lru.Len(), Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
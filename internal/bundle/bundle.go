@@ -0,0 +1,133 @@
+// Package bundle writes issue-reproduction bundles: gzipped tarballs of
+// configuration, status, and logs that users attach to bug reports. Every
+// file added to a bundle passes through a Redactor first, so tokens and
+// home directory paths do not leave the machine.
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Redacted replaces secrets in bundle contents
+const Redacted = "[REDACTED]"
+
+// secretPatterns match secrets that are not known up front, such as tokens
+// that ended up in log lines or in a config file that failed to load.
+var secretPatterns = []*regexp.Regexp{
+	// Hugging Face access tokens
+	regexp.MustCompile(`hf_[A-Za-z0-9]{8,}`),
+	// Authorization headers
+	regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]+`),
+	// key: value and key=value pairs whose key names a secret
+	regexp.MustCompile(`(?i)((?:token|api_key|apikey|secret|password)["']?\s*[:=]\s*["']?)[^\s"',}]+`),
+}
+
+// Redactor removes secrets and home directory paths from text
+type Redactor struct {
+	home    string
+	secrets []string
+}
+
+// NewRedactor creates a Redactor that replaces the given secret values, any
+// text matching common token patterns, and the current user's home
+// directory, which is shown as "~".
+func NewRedactor(secrets ...string) *Redactor {
+	r := &Redactor{}
+	if home, err := os.UserHomeDir(); err == nil && home != "/" {
+		r.home = strings.TrimRight(home, "/\\")
+	}
+	for _, s := range secrets {
+		if s != "" && s != Redacted {
+			r.secrets = append(r.secrets, s)
+		}
+	}
+	// Replace longer secrets first so a secret containing another is not
+	// left half redacted.
+	sort.Slice(r.secrets, func(i, j int) bool {
+		return len(r.secrets[i]) > len(r.secrets[j])
+	})
+	return r
+}
+
+// Redact returns text with secrets and the home directory replaced
+func (r *Redactor) Redact(text string) string {
+	for _, s := range r.secrets {
+		text = strings.ReplaceAll(text, s, Redacted)
+	}
+	for _, re := range secretPatterns {
+		if re.NumSubexp() > 0 {
+			text = re.ReplaceAllString(text, "${1}"+Redacted)
+		} else {
+			text = re.ReplaceAllString(text, Redacted)
+		}
+	}
+	if r.home != "" {
+		text = strings.ReplaceAll(text, r.home, "~")
+	}
+	return text
+}
+
+// Writer writes redacted files into a gzipped tarball
+type Writer struct {
+	gz       *gzip.Writer
+	tw       *tar.Writer
+	redactor *Redactor
+	prefix   string
+	modTime  time.Time
+}
+
+// NewWriter creates a Writer that writes the tarball to w. Files are placed
+// under the prefix directory inside the archive.
+func NewWriter(w io.Writer, prefix string, redactor *Redactor) *Writer {
+	gz := gzip.NewWriter(w)
+	return &Writer{
+		gz:       gz,
+		tw:       tar.NewWriter(gz),
+		redactor: redactor,
+		prefix:   prefix,
+		modTime:  time.Now().Truncate(time.Second),
+	}
+}
+
+// Add redacts data and writes it to the archive as name
+func (w *Writer) Add(name string, data []byte) error {
+	content := []byte(w.redactor.Redact(string(data)))
+	header := &tar.Header{
+		Name:    path.Join(w.prefix, name),
+		Mode:    0644,
+		Size:    int64(len(content)),
+		ModTime: w.modTime,
+	}
+	if err := w.tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("writing %s header: %w", name, err)
+	}
+	if _, err := w.tw.Write(content); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	return nil
+}
+
+// AddString is Add for text content
+func (w *Writer) AddString(name, text string) error {
+	return w.Add(name, []byte(text))
+}
+
+// Close flushes the archive. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if err := w.tw.Close(); err != nil {
+		return fmt.Errorf("closing tar: %w", err)
+	}
+	if err := w.gz.Close(); err != nil {
+		return fmt.Errorf("closing gzip: %w", err)
+	}
+	return nil
+}
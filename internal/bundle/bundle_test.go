@@ -0,0 +1,112 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "/" {
+		t.Skip("no home directory")
+	}
+
+	r := NewRedactor("s3cr3t-value", "")
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "known secret",
+			input: "connecting with s3cr3t-value",
+			want:  "connecting with " + Redacted,
+		},
+		{
+			name:  "yaml token",
+			input: "warm:\n  token: abc123\n  model: nomic",
+			want:  "warm:\n  token: " + Redacted + "\n  model: nomic",
+		},
+		{
+			name:  "json api key",
+			input: `{"ollama_api_key": "xyz"}`,
+			want:  `{"ollama_api_key": "` + Redacted + `"}`,
+		},
+		{
+			name:  "huggingface token in log",
+			input: "request failed for hf_abcdefghijklmnop",
+			want:  "request failed for " + Redacted,
+		},
+		{
+			name:  "bearer header",
+			input: "Authorization: Bearer eyJhbGciOi.x.y",
+			want:  "Authorization: Bearer " + Redacted,
+		},
+		{
+			name:  "home path",
+			input: "index at " + filepath.Join(home, "proj", ".gcq"),
+			want:  "index at " + filepath.Join("~", "proj", ".gcq"),
+		},
+		{
+			name:  "plain text untouched",
+			input: "Started Unix socket server on /tmp/gcq.sock",
+			want:  "Started Unix socket server on /tmp/gcq.sock",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.Redact(tt.input); got != tt.want {
+				t.Errorf("Redact(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, "gcq-debug", NewRedactor("topsecret"))
+	if err := w.AddString("version.txt", "gcq dev\n"); err != nil {
+		t.Fatalf("AddString failed: %v", err)
+	}
+	if err := w.Add("logs/daemon.log", []byte("using topsecret\n")); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatalf("Not a gzip stream: %v", err)
+	}
+	tr := tar.NewReader(gz)
+
+	files := make(map[string]string)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Reading tar: %v", err)
+		}
+		data, _ := io.ReadAll(tr)
+		files[header.Name] = string(data)
+	}
+
+	if files["gcq-debug/version.txt"] != "gcq dev\n" {
+		t.Errorf("Unexpected version.txt: %q", files["gcq-debug/version.txt"])
+	}
+	log := files["gcq-debug/logs/daemon.log"]
+	if strings.Contains(log, "topsecret") || !strings.Contains(log, Redacted) {
+		t.Errorf("Expected secret to be redacted in log, got %q", log)
+	}
+}
@@ -1,9 +1,18 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -14,6 +23,13 @@ type ProviderType string
 const (
 	ProviderHuggingFace ProviderType = "huggingface"
 	ProviderOllama      ProviderType = "ollama"
+	// ProviderLocal embeds with a locally installed runtime (Ollama, which
+	// uses Metal on Apple Silicon) and falls back to HuggingFace when the
+	// runtime is not reachable
+	ProviderLocal ProviderType = "local"
+	// ProviderMock hashes words into deterministic embeddings without any
+	// model, for smoke tests and reproducible bug reports
+	ProviderMock ProviderType = "mock"
 )
 
 // WarmConfig holds configuration for the warm (indexing) provider
@@ -30,6 +46,429 @@ type SearchConfig struct {
 	Model    string       `yaml:"model" env:"MODEL"`
 	BaseURL  string       `yaml:"base_url" env:"BASE_URL"`
 	Token    string       `yaml:"token" env:"TOKEN"`
+
+	// FeedbackBoost weighs the judgments recorded with `gcq feedback` into
+	// semantic search: units marked helpful score up to this much higher,
+	// units marked unhelpful up to this much lower (0 to 1, 0 = off)
+	FeedbackBoost float64 `yaml:"feedback_boost" env:"GCQ_SEARCH_FEEDBACK_BOOST"`
+}
+
+// DaemonConfig holds configuration for the background daemon (gcqd)
+type DaemonConfig struct {
+	// RefreshInterval is how often the daemon re-runs incremental indexing
+	// for registered projects while idle. Zero disables periodic refresh.
+	RefreshInterval time.Duration `yaml:"refresh_interval" env:"GCQ_DAEMON_REFRESH_INTERVAL"`
+
+	// Watch makes the daemon watch its registered roots and re-index changed
+	// files as soon as they are saved, and drop deleted files from the index
+	Watch bool `yaml:"watch" env:"GCQ_DAEMON_WATCH"`
+
+	// WatchDebounce is how long the watcher waits for changes to settle
+	// before re-indexing. Zero uses the default of 500ms.
+	WatchDebounce time.Duration `yaml:"watch_debounce" env:"GCQ_DAEMON_WATCH_DEBOUNCE"`
+
+	// SemanticRoots lists additional project roots whose `gcq warm` semantic
+	// index the daemon loads at startup. The daemon's own project is always tried.
+	SemanticRoots []string `yaml:"semantic_roots"`
+
+	// Warmup makes the daemon embed WarmupQueries once its semantic indexes
+	// are loaded and search each index with them, so the first interactive
+	// search does not pay for loading the embedding model or touching cold
+	// index pages
+	Warmup bool `yaml:"warmup" env:"GCQ_DAEMON_WARMUP"`
+
+	// WarmupQueries are the canary queries run during warm-up. Empty uses a
+	// small built-in set.
+	WarmupQueries []string `yaml:"warmup_queries" env:"GCQ_DAEMON_WARMUP_QUERIES"`
+
+	// Webhooks are URLs the daemon POSTs a JSON event to when an index build
+	// completes, the watcher re-indexes changes, the embedding provider
+	// fails or a background job finishes
+	Webhooks []string `yaml:"webhooks" env:"GCQ_DAEMON_WEBHOOKS"`
+
+	// WebhookEvents limits webhooks to these event types (index.built,
+	// watch.changes, provider.failed, job.finished). Empty sends all.
+	WebhookEvents []string `yaml:"webhook_events" env:"GCQ_DAEMON_WEBHOOK_EVENTS"`
+
+	// HTTPAddr is a host:port on which the daemon also serves its JSON API
+	// over HTTP (/status, /search, /context, /calls, /extract). Empty
+	// disables the HTTP listener.
+	HTTPAddr string `yaml:"http_addr" env:"GCQ_DAEMON_HTTP_ADDR"`
+
+	// HTTPAllowedOrigins lists browser origins allowed to call the HTTP API
+	// (CORS). Empty allows none; "*" allows any origin.
+	HTTPAllowedOrigins []string `yaml:"http_allowed_origins" env:"GCQ_DAEMON_HTTP_ALLOWED_ORIGINS"`
+
+	// ResultCacheSize is how many search responses the daemon keeps to
+	// answer repeated searches without embedding or scanning again. Entries
+	// are keyed by the index contents, so index updates invalidate them.
+	// Zero disables the cache.
+	ResultCacheSize int `yaml:"result_cache_size" env:"GCQ_DAEMON_RESULT_CACHE_SIZE"`
+
+	// EmbedBatchSize is how many files the daemon sends to the embedding
+	// provider per request while building an index. Zero uses the default
+	// of 32.
+	EmbedBatchSize int `yaml:"embed_batch_size" env:"GCQ_DAEMON_EMBED_BATCH_SIZE"`
+
+	// EmbedConcurrency is how many embedding requests the daemon keeps in
+	// flight while building an index. Zero uses the default of 2.
+	EmbedConcurrency int `yaml:"embed_concurrency" env:"GCQ_DAEMON_EMBED_CONCURRENCY"`
+
+	// ProjectQuota limits what each project served by the daemon may use,
+	// so one large project can't starve the others
+	ProjectQuota ProjectQuota `yaml:"project_quota"`
+
+	// Ignore lists gitignore-style patterns the daemon's scanner skips on
+	// top of .gcqignore and .gitignore. Edits to a project's config take
+	// effect without restarting the daemon.
+	Ignore []string `yaml:"ignore" env:"GCQ_DAEMON_IGNORE"`
+
+	// IgnoreFiles lists files of gitignore-style patterns, such as a list
+	// shared across projects, that the daemon's scanner applies after
+	// Ignore. Relative paths are relative to the project root.
+	IgnoreFiles []string `yaml:"ignore_files" env:"GCQ_DAEMON_IGNORE_FILES"`
+
+	// MinScore is the lowest score of a search result returned by the
+	// daemon when the request sets no threshold. Zero returns every result.
+	// Edits to a project's config take effect without restarting the daemon.
+	MinScore float64 `yaml:"min_score" env:"GCQ_DAEMON_MIN_SCORE"`
+
+	// SearchBudget bounds the index scan of each semantic or hybrid search
+	// when the request sets no budget_ms. A search that runs out returns
+	// the best results found so far with a search_budget degradation
+	// instead of running past the client's timeout. Zero is unbounded.
+	SearchBudget time.Duration `yaml:"search_budget" env:"GCQ_DAEMON_SEARCH_BUDGET"`
+
+	// CompactInterval is how often the daemon checks, while idle, whether
+	// its project indexes hold enough removed entries to compact. Zero
+	// disables background compaction; indexes are still compacted when
+	// saved and by `gcq index compact`.
+	CompactInterval time.Duration `yaml:"compact_interval" env:"GCQ_DAEMON_COMPACT_INTERVAL"`
+
+	// CompactMinRemoved is the fewest removed entries worth compacting.
+	// Zero uses the default of 100.
+	CompactMinRemoved int `yaml:"compact_min_removed" env:"GCQ_DAEMON_COMPACT_MIN_REMOVED"`
+
+	// CompactRatio is the share of an index's stored entries that must be
+	// removed before it is compacted (0 to 1). Zero uses the default of 0.25.
+	CompactRatio float64 `yaml:"compact_ratio" env:"GCQ_DAEMON_COMPACT_RATIO"`
+}
+
+// ProjectQuota holds the per-project limits of a daemon. Zero fields are
+// unlimited.
+type ProjectQuota struct {
+	// MaxIndexMB caps the memory of a project's index vectors. Files new
+	// to a full index are not indexed; files already in it are updated.
+	MaxIndexMB int `yaml:"max_index_mb" env:"GCQ_DAEMON_QUOTA_MAX_INDEX_MB"`
+
+	// EmbedCallsPerHour caps the embedding provider requests made for a
+	// project in any hour: index batches, re-indexed files and search queries
+	EmbedCallsPerHour int `yaml:"embed_calls_per_hour" env:"GCQ_DAEMON_QUOTA_EMBED_CALLS_PER_HOUR"`
+
+	// MaxJobs caps a project's background jobs queued or running at once
+	MaxJobs int `yaml:"max_jobs" env:"GCQ_DAEMON_QUOTA_MAX_JOBS"`
+}
+
+// DefaultDaemonConfig returns the default daemon settings
+func DefaultDaemonConfig() DaemonConfig {
+	return DaemonConfig{
+		Warmup:          true,
+		ResultCacheSize: 256,
+		CompactInterval: 5 * time.Minute,
+	}
+}
+
+// EmbeddingConfig holds configuration for how code units become embedding text
+type EmbeddingConfig struct {
+	// Templates maps a language (or "default") to a Go text/template that builds
+	// the embedding text for each unit. Languages without an entry use the built-in format.
+	Templates map[string]string `yaml:"templates"`
+
+	// CalleeSummaries is how many direct callees have their docstring summary
+	// appended to a unit's embedding text (0 = disabled). It helps thin
+	// wrapper functions whose own text says little.
+	CalleeSummaries int `yaml:"callee_summaries"`
+	// CalleeSummaryChars caps each callee summary (0 = 80 characters)
+	CalleeSummaryChars int `yaml:"callee_summary_chars"`
+
+	// ChunkBodies also indexes functions and methods longer than chunk_size
+	// tokens as chunks of their source that share chunk_overlap tokens, so
+	// searches can match code deep inside a long body
+	ChunkBodies bool `yaml:"chunk_bodies"`
+}
+
+// TextSearchConfig holds defaults for regex text search (gcq search and the daemon's text mode)
+type TextSearchConfig struct {
+	// ContextLines is the number of lines included before and after each match
+	ContextLines int `yaml:"context_lines"`
+	// MaxResults caps the number of matches returned (0 = unlimited)
+	MaxResults int `yaml:"max_results"`
+	// MaxFileSize skips files larger than this many bytes (0 = unlimited)
+	MaxFileSize int64 `yaml:"max_file_size"`
+	// SkipBinary skips files that look binary
+	SkipBinary bool `yaml:"skip_binary"`
+}
+
+// DefaultTextSearchConfig returns the default text search settings
+func DefaultTextSearchConfig() TextSearchConfig {
+	return TextSearchConfig{
+		ContextLines: 2,
+		MaxResults:   100,
+		MaxFileSize:  1 << 20,
+		SkipBinary:   true,
+	}
+}
+
+// Search index backends
+const (
+	IndexBackendAuto   = "auto"
+	IndexBackendFlat   = "flat"
+	IndexBackendHNSW   = "hnsw"
+	IndexBackendSQLite = "sqlite"
+)
+
+// Vector quantizations of a saved index
+const (
+	IndexQuantizationNone = "none"
+	IndexQuantizationInt8 = "int8"
+)
+
+// IndexConfig selects how semantic search finds nearest neighbours. Zero
+// values use the built-in defaults.
+type IndexConfig struct {
+	// Backend is "flat" (exact, scans every vector), "hnsw" (approximate
+	// graph search), "auto" (hnsw once an index reaches HNSWThreshold units)
+	// or "sqlite" (exact, saved as a SQLite database searched without
+	// loading it into memory)
+	Backend string `yaml:"backend" env:"GCQ_INDEX_BACKEND"`
+	// HNSWThreshold is the unit count at which auto switches to hnsw
+	HNSWThreshold int `yaml:"hnsw_threshold" env:"GCQ_INDEX_HNSW_THRESHOLD"`
+	// HNSWM is the number of links per graph node
+	HNSWM int `yaml:"hnsw_m"`
+	// HNSWEfConstruction is the candidate list size while building the graph
+	HNSWEfConstruction int `yaml:"hnsw_ef_construction"`
+	// HNSWEfSearch is the candidate list size while searching; higher
+	// values trade speed for recall
+	HNSWEfSearch int `yaml:"hnsw_ef_search" env:"GCQ_INDEX_HNSW_EF_SEARCH"`
+	// Quantization is "none" (float32 vectors) or "int8" (a byte per
+	// value, cutting the saved vectors to about a quarter of their size at
+	// a small cost in ranking precision)
+	Quantization string `yaml:"quantization" env:"GCQ_INDEX_QUANTIZATION"`
+	// Imports lists ctags, LSIF or SCIP dumps, relative to the project
+	// root, whose definitions `gcq warm` indexes for languages gcq does
+	// not parse natively
+	Imports []string `yaml:"imports"`
+	// StableIDs gives each unit a content and signature based ID next to its
+	// path based URI and records units that moved or were renamed between
+	// builds, so IDs saved before a refactor still resolve
+	StableIDs bool `yaml:"stable_ids" env:"GCQ_INDEX_STABLE_IDS"`
+	// Todos indexes TODO, FIXME and HACK comments as "todo" units, found
+	// with "type:todo" in a search query
+	Todos bool `yaml:"todos" env:"GCQ_INDEX_TODOS"`
+	// Docs indexes Markdown and reStructuredText files as "doc" units, one
+	// per section, which searches return next to code
+	Docs bool `yaml:"docs" env:"GCQ_INDEX_DOCS"`
+	// Commits indexes the project's recent commit messages, and optionally
+	// its merged pull requests, as "commit" units
+	Commits CommitsConfig `yaml:"commits"`
+	// Enrichers annotate units with metadata at index time, which searches
+	// can filter on (gcq semantic --meta key=value)
+	Enrichers []EnricherConfig `yaml:"enrichers"`
+	// Dependencies selects the imports left out of unit dependencies and
+	// dependency reports
+	Dependencies DependenciesConfig `yaml:"dependencies"`
+}
+
+// DependenciesConfig extends the standard library modules built into gcq
+// and lists packages that never count as dependencies. Entries also cover
+// their submodules, so "github.com/acme" covers "github.com/acme/auth".
+type DependenciesConfig struct {
+	// Stdlib adds standard library modules per language, keyed by language
+	// name such as "python" or "go"
+	Stdlib map[string][]string `yaml:"stdlib,omitempty"`
+	// Ignore lists packages, such as an organization's internal modules,
+	// left out of dependencies
+	Ignore []string `yaml:"ignore,omitempty" env:"GCQ_INDEX_DEPENDENCIES_IGNORE"`
+}
+
+// CallGraphConfig tunes the call graph behind calls and callers output
+// and the call lists in embedding text
+type CallGraphConfig struct {
+	// StopSymbols lists "boring" callees, such as logging, print and
+	// assert helpers, left out of calls and callers and of embedding text.
+	// Keys are language names such as "python", or "default" for every
+	// language; values are glob patterns matched against the callee's
+	// name and qualified name, such as "log_*" or "Logger.*".
+	StopSymbols map[string][]string `yaml:"stop_symbols,omitempty"`
+}
+
+// LanguagesConfig lists tree-sitter grammars loaded as shared libraries at
+// run time, for languages gcq has no built-in parser for. Grammars are
+// downloaded once into Dir and verified against their checksum before they
+// are loaded. Packs are only read from the user-level config; see
+// LoadLanguages.
+type LanguagesConfig struct {
+	// Dir caches downloaded grammars ("" = gcq/grammars in the user cache
+	// directory)
+	Dir string `yaml:"dir" env:"GCQ_LANGUAGES_DIR"`
+	// Offline never downloads grammars; only those already in Dir load
+	Offline bool `yaml:"offline" env:"GCQ_LANGUAGES_OFFLINE"`
+	// Packs are the grammars to load
+	Packs []LanguagePack `yaml:"packs"`
+}
+
+// validate checks that every pack names its language and has a checksum
+// for each library
+func (l LanguagesConfig) validate() error {
+	for i, pack := range l.Packs {
+		if pack.Name == "" || len(pack.Extensions) == 0 {
+			return fmt.Errorf("languages.packs[%d]: name and extensions are required", i)
+		}
+		for platform, lib := range pack.Libraries {
+			if lib.URL == "" {
+				return fmt.Errorf("languages.packs[%d].libraries[%s]: url is required", i, platform)
+			}
+			if sum, err := hex.DecodeString(lib.SHA256); err != nil || len(sum) != sha256.Size {
+				return fmt.Errorf("languages.packs[%d].libraries[%s]: sha256 must be a hex SHA-256 checksum", i, platform)
+			}
+		}
+	}
+	return nil
+}
+
+// LanguagePack describes a tree-sitter grammar built as a shared library
+// and the nodes of its syntax tree that become units
+type LanguagePack struct {
+	// Name is the language name, such as "lua"
+	Name string `yaml:"name"`
+	// Extensions are the file extensions of the language, such as ".lua"
+	Extensions []string `yaml:"extensions"`
+	// Symbol is the function the library exports the grammar with
+	// ("" = tree_sitter_<name>)
+	Symbol string `yaml:"symbol,omitempty"`
+	// Functions and Classes are the node types extracted as functions and
+	// classes; functions inside a class become its methods
+	Functions []string `yaml:"functions"`
+	Classes   []string `yaml:"classes,omitempty"`
+	// Libraries maps a platform, such as "linux/amd64", to the grammar
+	// library built for it
+	Libraries map[string]LanguageLibrary `yaml:"libraries"`
+}
+
+// LanguageLibrary is a downloadable grammar library
+type LanguageLibrary struct {
+	// URL is where the library is downloaded from; a file:// URL or a
+	// plain path copies a local build
+	URL string `yaml:"url"`
+	// SHA256 is the hex checksum the library must match
+	SHA256 string `yaml:"sha256"`
+}
+
+// CommitsConfig selects the git history indexed as "commit" units, found
+// with "type:commit" in a search query
+type CommitsConfig struct {
+	// Enabled indexes recent commit messages, linked to the files they
+	// touched
+	Enabled bool `yaml:"enabled" env:"GCQ_INDEX_COMMITS"`
+	// Limit is the number of recent commits, and of merged pull requests,
+	// indexed (0 = 200)
+	Limit int `yaml:"limit" env:"GCQ_INDEX_COMMITS_LIMIT"`
+	// GitHubToken also indexes merged pull request descriptions from the
+	// GitHub API
+	GitHubToken string `yaml:"github_token,omitempty" env:"GCQ_GITHUB_TOKEN"`
+	// GitHubRepo is the repository as "owner/name"; empty uses the origin
+	// remote
+	GitHubRepo string `yaml:"github_repo,omitempty"`
+	// GitHubAPI is the API base URL for GitHub Enterprise; empty uses
+	// https://api.github.com
+	GitHubAPI string `yaml:"github_api,omitempty"`
+}
+
+// EnricherConfig records what Pattern, a regular expression, matches in a
+// unit's doc comment and body under the metadata key Key. When the pattern
+// has a capture group, the first group is recorded.
+type EnricherConfig struct {
+	Key     string `yaml:"key"`
+	Pattern string `yaml:"pattern"`
+}
+
+// Built-in limits used when LimitsConfig leaves a value at zero
+const (
+	DefaultSearchResults  = 10
+	DefaultContextResults = 5
+	DefaultDependencies   = 5
+	DefaultCallListChars  = 200
+	DefaultMaxResults     = 1000
+	DefaultBundleTokens   = 8000
+)
+
+// LimitsConfig holds default result counts and size caps. Commands and daemon
+// requests can override the result counts per call, up to MaxResults.
+type LimitsConfig struct {
+	// SearchResults is the default number of semantic search results
+	SearchResults int `yaml:"search_results" env:"GCQ_LIMIT_SEARCH_RESULTS"`
+	// ContextResults is the default number of units in a context query
+	ContextResults int `yaml:"context_results" env:"GCQ_LIMIT_CONTEXT_RESULTS"`
+	// Dependencies caps the external dependencies attached to each unit (0 = unlimited)
+	Dependencies int `yaml:"dependencies" env:"GCQ_LIMIT_DEPENDENCIES"`
+	// CallListChars caps the calls and callers lists in embedding text (0 = unlimited)
+	CallListChars int `yaml:"call_list_chars" env:"GCQ_LIMIT_CALL_LIST_CHARS"`
+	// MaxResults is the most results any single request may ask for (0 = unlimited)
+	MaxResults int `yaml:"max_results" env:"GCQ_LIMIT_MAX_RESULTS"`
+	// BundleTokens is the default token budget of a gcq bundle document
+	BundleTokens int `yaml:"bundle_tokens" env:"GCQ_LIMIT_BUNDLE_TOKENS"`
+}
+
+// DefaultLimitsConfig returns the default limits
+func DefaultLimitsConfig() LimitsConfig {
+	return LimitsConfig{
+		SearchResults:  DefaultSearchResults,
+		ContextResults: DefaultContextResults,
+		Dependencies:   DefaultDependencies,
+		CallListChars:  DefaultCallListChars,
+		MaxResults:     DefaultMaxResults,
+		BundleTokens:   DefaultBundleTokens,
+	}
+}
+
+// SearchLimit returns the number of semantic search results to return when
+// a request asks for requested (0 = the configured default)
+func (l LimitsConfig) SearchLimit(requested int) int {
+	return l.limit(requested, l.SearchResults, DefaultSearchResults)
+}
+
+// ContextLimit returns the number of context units to return when a request
+// asks for requested (0 = the configured default)
+func (l LimitsConfig) ContextLimit(requested int) int {
+	return l.limit(requested, l.ContextResults, DefaultContextResults)
+}
+
+// BundleBudget returns the token budget of a bundle when a request asks for
+// requested (0 = the configured default). It is not capped by MaxResults.
+func (l LimitsConfig) BundleBudget(requested int) int {
+	if requested > 0 {
+		return requested
+	}
+	if l.BundleTokens > 0 {
+		return l.BundleTokens
+	}
+	return DefaultBundleTokens
+}
+
+// limit picks the requested, configured or built-in value, in that order,
+// and caps it at MaxResults
+func (l LimitsConfig) limit(requested, configured, builtin int) int {
+	limit := requested
+	if limit <= 0 {
+		limit = configured
+	}
+	if limit <= 0 {
+		limit = builtin
+	}
+	if l.MaxResults > 0 && limit > l.MaxResults {
+		limit = l.MaxResults
+	}
+	return limit
 }
 
 // Config holds all configuration for go-context-query
@@ -49,6 +488,30 @@ type Config struct {
 	OllamaBaseURL string `yaml:"ollama_base_url,omitempty" env:"GCQ_OLLAMA_BASE_URL"`
 	OllamaAPIKey  string `yaml:"ollama_api_key,omitempty" env:"GCQ_OLLAMA_API_KEY"`
 
+	// Embedding dimension of the mock provider (0 uses its default)
+	MockDimension int `yaml:"mock_dimension,omitempty" env:"GCQ_MOCK_DIMENSION"`
+
+	// Embedding text settings
+	Embedding EmbeddingConfig `yaml:"embedding"`
+
+	// Daemon settings
+	Daemon DaemonConfig `yaml:"daemon"`
+
+	// Text search settings
+	TextSearch TextSearchConfig `yaml:"text_search"`
+
+	// Result counts and size caps
+	Limits LimitsConfig `yaml:"limits"`
+
+	// Nearest neighbour backend for semantic search
+	Index IndexConfig `yaml:"index"`
+
+	// Callees left out of call graph output and embedding text
+	CallGraph CallGraphConfig `yaml:"call_graph"`
+
+	// Grammars loaded at run time for languages without a built-in parser
+	Languages LanguagesConfig `yaml:"languages"`
+
 	// Socket path for IPC communication
 	SocketPath string `yaml:"socket_path" env:"GCQ_SOCKET_PATH"`
 
@@ -70,6 +533,11 @@ func DefaultConfig() *Config {
 	return &Config{
 		Warm:                WarmConfig{},
 		Search:              SearchConfig{},
+		Embedding:           EmbeddingConfig{},
+		Daemon:              DefaultDaemonConfig(),
+		TextSearch:          DefaultTextSearchConfig(),
+		Limits:              DefaultLimitsConfig(),
+		Index:               IndexConfig{},
 		Provider:            "",
 		HFModel:             "",
 		HFToken:             "",
@@ -119,6 +587,47 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// UserConfigFilePath returns the user-level config file: GCQ_USER_CONFIG,
+// or gcq/config.yaml in the user config directory
+func UserConfigFilePath() (string, error) {
+	if path := os.Getenv("GCQ_USER_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("finding the user config directory: %w", err)
+	}
+	return filepath.Join(dir, "gcq", "config.yaml"), nil
+}
+
+// LoadLanguages reads the languages section of the user-level config. A
+// grammar library runs as native code in the process that loads it, so
+// packs are only taken from the user's own config, never from a project's
+// .gcq/config.yaml that may have come with a cloned repository. A missing
+// file lists no packs.
+func LoadLanguages() (LanguagesConfig, error) {
+	cfg := DefaultConfig()
+	path, err := UserConfigFilePath()
+	if err != nil {
+		return LanguagesConfig{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return LanguagesConfig{}, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	user := struct {
+		Languages *LanguagesConfig `yaml:"languages"`
+	}{&cfg.Languages}
+	if err := yaml.Unmarshal(data, &user); err != nil {
+		return LanguagesConfig{}, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	applyEnvOverrides(cfg)
+	if err := cfg.Languages.validate(); err != nil {
+		return LanguagesConfig{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg.Languages, nil
+}
+
 // LoadFromFile reads configuration from a specific YAML file path
 func LoadFromFile(path string) (*Config, error) {
 	cfg := DefaultConfig()
@@ -163,6 +672,37 @@ func (c *Config) Save(path string) error {
 	return nil
 }
 
+// RedactedValue replaces secrets in configuration shown to users
+const RedactedValue = "[REDACTED]"
+
+// Redacted returns a copy of the configuration with tokens and API keys
+// replaced by RedactedValue, suitable for logs and bug reports.
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redact := func(s *string) {
+		if *s != "" {
+			*s = RedactedValue
+		}
+	}
+	redact(&redacted.Warm.Token)
+	redact(&redacted.Search.Token)
+	redact(&redacted.HFToken)
+	redact(&redacted.OllamaAPIKey)
+	redact(&redacted.Index.Commits.GitHubToken)
+	return &redacted
+}
+
+// Secrets returns the non-empty tokens and API keys in the configuration
+func (c *Config) Secrets() []string {
+	var secrets []string
+	for _, s := range []string{c.Warm.Token, c.Search.Token, c.HFToken, c.OllamaAPIKey, c.Index.Commits.GitHubToken} {
+		if s != "" {
+			secrets = append(secrets, s)
+		}
+	}
+	return secrets
+}
+
 // applyEnvOverrides applies environment variable overrides to the config
 func applyEnvOverrides(cfg *Config) {
 	if v := os.Getenv("GCQ_PROVIDER"); v != "" {
@@ -186,6 +726,9 @@ func applyEnvOverrides(cfg *Config) {
 	if v := os.Getenv("GCQ_WARM_HF_TOKEN"); v != "" {
 		cfg.Warm.Token = v
 	}
+	if v := os.Getenv("GCQ_SEARCH_FEEDBACK_BOOST"); v != "" {
+		cfg.Search.FeedbackBoost = parseFloat(v)
+	}
 	if v := os.Getenv("GCQ_SEARCH_HF_MODEL"); v != "" {
 		cfg.Search.Model = v
 	}
@@ -250,6 +793,135 @@ func applyEnvOverrides(cfg *Config) {
 	if v := os.Getenv("GCQ_VERBOSE"); v != "" {
 		cfg.Verbose = v == "true" || v == "1" || v == "yes"
 	}
+	if v := os.Getenv("GCQ_INDEX_BACKEND"); v != "" {
+		cfg.Index.Backend = v
+	}
+	if v := os.Getenv("GCQ_INDEX_QUANTIZATION"); v != "" {
+		cfg.Index.Quantization = v
+	}
+	if v := os.Getenv("GCQ_INDEX_STABLE_IDS"); v != "" {
+		cfg.Index.StableIDs = v == "true" || v == "1" || v == "yes"
+	}
+	if v := os.Getenv("GCQ_INDEX_TODOS"); v != "" {
+		cfg.Index.Todos = v == "true" || v == "1" || v == "yes"
+	}
+	if v := os.Getenv("GCQ_INDEX_DOCS"); v != "" {
+		cfg.Index.Docs = v == "true" || v == "1" || v == "yes"
+	}
+	if v := os.Getenv("GCQ_INDEX_COMMITS"); v != "" {
+		cfg.Index.Commits.Enabled = v == "true" || v == "1" || v == "yes"
+	}
+	if v := os.Getenv("GCQ_GITHUB_TOKEN"); v != "" {
+		cfg.Index.Commits.GitHubToken = v
+	}
+	for name, field := range map[string]*int{
+		"GCQ_LIMIT_SEARCH_RESULTS":       &cfg.Limits.SearchResults,
+		"GCQ_LIMIT_CONTEXT_RESULTS":      &cfg.Limits.ContextResults,
+		"GCQ_LIMIT_DEPENDENCIES":         &cfg.Limits.Dependencies,
+		"GCQ_LIMIT_CALL_LIST_CHARS":      &cfg.Limits.CallListChars,
+		"GCQ_LIMIT_MAX_RESULTS":          &cfg.Limits.MaxResults,
+		"GCQ_LIMIT_BUNDLE_TOKENS":        &cfg.Limits.BundleTokens,
+		"GCQ_INDEX_HNSW_THRESHOLD":       &cfg.Index.HNSWThreshold,
+		"GCQ_INDEX_HNSW_EF_SEARCH":       &cfg.Index.HNSWEfSearch,
+		"GCQ_INDEX_COMMITS_LIMIT":        &cfg.Index.Commits.Limit,
+		"GCQ_MOCK_DIMENSION":             &cfg.MockDimension,
+		"GCQ_DAEMON_RESULT_CACHE_SIZE":   &cfg.Daemon.ResultCacheSize,
+		"GCQ_DAEMON_EMBED_BATCH_SIZE":    &cfg.Daemon.EmbedBatchSize,
+		"GCQ_DAEMON_EMBED_CONCURRENCY":   &cfg.Daemon.EmbedConcurrency,
+		"GCQ_DAEMON_COMPACT_MIN_REMOVED": &cfg.Daemon.CompactMinRemoved,
+
+		"GCQ_DAEMON_QUOTA_MAX_INDEX_MB":         &cfg.Daemon.ProjectQuota.MaxIndexMB,
+		"GCQ_DAEMON_QUOTA_EMBED_CALLS_PER_HOUR": &cfg.Daemon.ProjectQuota.EmbedCallsPerHour,
+		"GCQ_DAEMON_QUOTA_MAX_JOBS":             &cfg.Daemon.ProjectQuota.MaxJobs,
+	} {
+		if v := os.Getenv(name); v != "" {
+			if i, err := strconv.Atoi(v); err == nil && i >= 0 {
+				*field = i
+			}
+		}
+	}
+	if v := os.Getenv("GCQ_DAEMON_REFRESH_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.Daemon.RefreshInterval = d
+		}
+	}
+	if v := os.Getenv("GCQ_DAEMON_COMPACT_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.Daemon.CompactInterval = d
+		}
+	}
+	if v := os.Getenv("GCQ_DAEMON_COMPACT_RATIO"); v != "" {
+		if f := parseFloat(v); f >= 0 {
+			cfg.Daemon.CompactRatio = f
+		}
+	}
+	if v := os.Getenv("GCQ_DAEMON_WATCH"); v != "" {
+		cfg.Daemon.Watch = v == "true" || v == "1" || v == "yes"
+	}
+	if v := os.Getenv("GCQ_DAEMON_WATCH_DEBOUNCE"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.Daemon.WatchDebounce = d
+		}
+	}
+	if v := os.Getenv("GCQ_DAEMON_SEARCH_BUDGET"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			cfg.Daemon.SearchBudget = d
+		}
+	}
+	if v := os.Getenv("GCQ_DAEMON_WARMUP"); v != "" {
+		cfg.Daemon.Warmup = v == "true" || v == "1" || v == "yes"
+	}
+	if v := os.Getenv("GCQ_DAEMON_WARMUP_QUERIES"); v != "" {
+		cfg.Daemon.WarmupQueries = nil
+		for _, q := range strings.Split(v, ",") {
+			if q = strings.TrimSpace(q); q != "" {
+				cfg.Daemon.WarmupQueries = append(cfg.Daemon.WarmupQueries, q)
+			}
+		}
+	}
+	if v := os.Getenv("GCQ_DAEMON_WEBHOOKS"); v != "" {
+		cfg.Daemon.Webhooks = splitList(v)
+	}
+	if v := os.Getenv("GCQ_DAEMON_WEBHOOK_EVENTS"); v != "" {
+		cfg.Daemon.WebhookEvents = splitList(v)
+	}
+	if v := os.Getenv("GCQ_DAEMON_IGNORE"); v != "" {
+		cfg.Daemon.Ignore = splitList(v)
+	}
+	if v := os.Getenv("GCQ_DAEMON_IGNORE_FILES"); v != "" {
+		cfg.Daemon.IgnoreFiles = splitList(v)
+	}
+	if v := os.Getenv("GCQ_INDEX_DEPENDENCIES_IGNORE"); v != "" {
+		cfg.Index.Dependencies.Ignore = splitList(v)
+	}
+	if v := os.Getenv("GCQ_DAEMON_MIN_SCORE"); v != "" {
+		if f := parseFloat(v); f >= 0 {
+			cfg.Daemon.MinScore = f
+		}
+	}
+	if v := os.Getenv("GCQ_LANGUAGES_DIR"); v != "" {
+		cfg.Languages.Dir = v
+	}
+	if v := os.Getenv("GCQ_LANGUAGES_OFFLINE"); v != "" {
+		cfg.Languages.Offline = v == "true" || v == "1" || v == "yes"
+	}
+	if v := os.Getenv("GCQ_DAEMON_HTTP_ADDR"); v != "" {
+		cfg.Daemon.HTTPAddr = v
+	}
+	if v := os.Getenv("GCQ_DAEMON_HTTP_ALLOWED_ORIGINS"); v != "" {
+		cfg.Daemon.HTTPAllowedOrigins = splitList(v)
+	}
+}
+
+// splitList splits a comma-separated environment value, dropping empty items
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
 
 // Validate checks that the configuration has valid required fields
@@ -273,6 +945,34 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("threshold_min_score must be between 0 and 1")
 	}
 
+	if c.MockDimension < 0 {
+		return fmt.Errorf("mock_dimension must be non-negative")
+	}
+	if c.Search.FeedbackBoost < 0 || c.Search.FeedbackBoost > 1 {
+		return fmt.Errorf("search.feedback_boost must be between 0 and 1")
+	}
+
+	for i, e := range c.Index.Enrichers {
+		if e.Key == "" {
+			return fmt.Errorf("index.enrichers[%d]: key is required", i)
+		}
+		if _, err := regexp.Compile(e.Pattern); err != nil || e.Pattern == "" {
+			return fmt.Errorf("index.enrichers[%d]: invalid pattern %q", i, e.Pattern)
+		}
+	}
+
+	for lang, patterns := range c.CallGraph.StopSymbols {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+				return fmt.Errorf("call_graph.stop_symbols.%s: invalid pattern %q", lang, pattern)
+			}
+		}
+	}
+
+	if err := c.Languages.validate(); err != nil {
+		return err
+	}
+
 	if c.ChunkSize <= 0 {
 		return fmt.Errorf("chunk_size must be positive")
 	}
@@ -282,6 +982,99 @@ func (c *Config) Validate() error {
 	if c.MaxContextChunks <= 0 {
 		return fmt.Errorf("max_context_chunks must be positive")
 	}
+	if c.Daemon.RefreshInterval < 0 {
+		return fmt.Errorf("daemon.refresh_interval must be non-negative")
+	}
+	if c.Daemon.CompactInterval < 0 {
+		return fmt.Errorf("daemon.compact_interval must be non-negative")
+	}
+	if c.Daemon.CompactMinRemoved < 0 {
+		return fmt.Errorf("daemon.compact_min_removed must be non-negative")
+	}
+	if c.Daemon.CompactRatio < 0 || c.Daemon.CompactRatio > 1 {
+		return fmt.Errorf("daemon.compact_ratio must be between 0 and 1")
+	}
+	if c.Daemon.WatchDebounce < 0 {
+		return fmt.Errorf("daemon.watch_debounce must be non-negative")
+	}
+	if c.Daemon.ResultCacheSize < 0 {
+		return fmt.Errorf("daemon.result_cache_size must be non-negative")
+	}
+	if c.Daemon.EmbedBatchSize < 0 {
+		return fmt.Errorf("daemon.embed_batch_size must be non-negative")
+	}
+	if c.Daemon.EmbedConcurrency < 0 {
+		return fmt.Errorf("daemon.embed_concurrency must be non-negative")
+	}
+	if c.Daemon.MinScore < 0 || c.Daemon.MinScore > 1 {
+		return fmt.Errorf("daemon.min_score must be between 0 and 1")
+	}
+	if q := c.Daemon.ProjectQuota; q.MaxIndexMB < 0 || q.EmbedCallsPerHour < 0 || q.MaxJobs < 0 {
+		return fmt.Errorf("daemon.project_quota limits must be non-negative")
+	}
+	for _, hook := range c.Daemon.Webhooks {
+		if u, err := url.Parse(hook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("daemon.webhooks: %q is not an http(s) URL", hook)
+		}
+	}
+	if c.Daemon.HTTPAddr != "" {
+		if _, _, err := net.SplitHostPort(c.Daemon.HTTPAddr); err != nil {
+			return fmt.Errorf("daemon.http_addr: %q is not a host:port address", c.Daemon.HTTPAddr)
+		}
+	}
+	if c.TextSearch.ContextLines < 0 {
+		return fmt.Errorf("text_search.context_lines must be non-negative")
+	}
+	if c.TextSearch.MaxResults < 0 {
+		return fmt.Errorf("text_search.max_results must be non-negative")
+	}
+	if c.TextSearch.MaxFileSize < 0 {
+		return fmt.Errorf("text_search.max_file_size must be non-negative")
+	}
+	for _, limit := range []struct {
+		name  string
+		value int
+	}{
+		{"search_results", c.Limits.SearchResults},
+		{"context_results", c.Limits.ContextResults},
+		{"dependencies", c.Limits.Dependencies},
+		{"call_list_chars", c.Limits.CallListChars},
+		{"max_results", c.Limits.MaxResults},
+		{"bundle_tokens", c.Limits.BundleTokens},
+	} {
+		if limit.value < 0 {
+			return fmt.Errorf("limits.%s must be non-negative", limit.name)
+		}
+	}
+	if c.Embedding.CalleeSummaries < 0 {
+		return fmt.Errorf("embedding.callee_summaries must be non-negative")
+	}
+	if c.Embedding.CalleeSummaryChars < 0 {
+		return fmt.Errorf("embedding.callee_summary_chars must be non-negative")
+	}
+	switch c.Index.Backend {
+	case "", IndexBackendAuto, IndexBackendFlat, IndexBackendHNSW, IndexBackendSQLite:
+	default:
+		return fmt.Errorf("invalid index.backend: %s (must be 'auto', 'flat', 'hnsw' or 'sqlite')", c.Index.Backend)
+	}
+	switch c.Index.Quantization {
+	case "", IndexQuantizationNone, IndexQuantizationInt8:
+	default:
+		return fmt.Errorf("invalid index.quantization: %s (must be 'none' or 'int8')", c.Index.Quantization)
+	}
+	for _, setting := range []struct {
+		name  string
+		value int
+	}{
+		{"hnsw_threshold", c.Index.HNSWThreshold},
+		{"hnsw_m", c.Index.HNSWM},
+		{"hnsw_ef_construction", c.Index.HNSWEfConstruction},
+		{"hnsw_ef_search", c.Index.HNSWEfSearch},
+	} {
+		if setting.value < 0 {
+			return fmt.Errorf("index.%s must be non-negative", setting.name)
+		}
+	}
 
 	return nil
 }
@@ -290,10 +1083,10 @@ func (c *Config) Validate() error {
 func (c *Config) validateSingleProviderMode() error {
 	// Validate provider
 	switch c.Provider {
-	case ProviderHuggingFace, ProviderOllama:
+	case ProviderHuggingFace, ProviderOllama, ProviderLocal, ProviderMock:
 		// Valid
 	default:
-		return fmt.Errorf("invalid provider: %s (must be 'huggingface' or 'ollama')", c.Provider)
+		return fmt.Errorf("invalid provider: %s (must be 'huggingface', 'ollama', 'local' or 'mock')", c.Provider)
 	}
 
 	// Validate provider-specific settings
@@ -327,9 +1120,9 @@ func (c *Config) validateDualProviderMode() error {
 
 	if warmProvider != "" {
 		switch warmProvider {
-		case ProviderHuggingFace, ProviderOllama:
+		case ProviderHuggingFace, ProviderOllama, ProviderLocal, ProviderMock:
 		default:
-			return fmt.Errorf("invalid warm.provider: %s (must be 'huggingface' or 'ollama')", warmProvider)
+			return fmt.Errorf("invalid warm.provider: %s (must be 'huggingface', 'ollama', 'local' or 'mock')", warmProvider)
 		}
 
 		if warmProvider == ProviderHuggingFace && c.Warm.Model == "" && c.HFModel == "" {
@@ -348,9 +1141,9 @@ func (c *Config) validateDualProviderMode() error {
 
 	if searchProvider != "" {
 		switch searchProvider {
-		case ProviderHuggingFace, ProviderOllama:
+		case ProviderHuggingFace, ProviderOllama, ProviderLocal, ProviderMock:
 		default:
-			return fmt.Errorf("invalid search.provider: %s (must be 'huggingface' or 'ollama')", searchProvider)
+			return fmt.Errorf("invalid search.provider: %s (must be 'huggingface', 'ollama', 'local' or 'mock')", searchProvider)
 		}
 
 		if searchProvider == ProviderHuggingFace && c.Search.Model == "" && c.HFModel == "" {
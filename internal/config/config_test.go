@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -28,6 +29,11 @@ func TestDefaultConfig(t *testing.T) {
 		{"ChunkOverlap", cfg.ChunkOverlap, 100},
 		{"ChunkSize", cfg.ChunkSize, 512},
 		{"Verbose", cfg.Verbose, false},
+		// Text search
+		{"TextSearch.ContextLines", cfg.TextSearch.ContextLines, 2},
+		{"TextSearch.MaxResults", cfg.TextSearch.MaxResults, 100},
+		{"TextSearch.MaxFileSize", cfg.TextSearch.MaxFileSize, int64(1 << 20)},
+		{"TextSearch.SkipBinary", cfg.TextSearch.SkipBinary, true},
 	}
 
 	for _, tt := range tests {
@@ -69,6 +75,29 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid mock config",
+			cfg: &Config{
+				Provider:         ProviderMock,
+				MockDimension:    64,
+				ChunkSize:        512,
+				ChunkOverlap:     100,
+				MaxContextChunks: 10,
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative mock_dimension",
+			cfg: &Config{
+				Provider:         ProviderMock,
+				MockDimension:    -1,
+				ChunkSize:        512,
+				ChunkOverlap:     100,
+				MaxContextChunks: 10,
+			},
+			wantErr:     true,
+			errContains: "mock_dimension must be non-negative",
+		},
 		{
 			name: "invalid provider",
 			cfg: &Config{
@@ -187,6 +216,206 @@ func TestConfigValidate(t *testing.T) {
 			wantErr:     true,
 			errContains: "max_context_chunks must be positive",
 		},
+		{
+			name: "invalid text_search.max_file_size",
+			cfg: &Config{
+				Provider:         ProviderOllama,
+				OllamaModel:      "test",
+				OllamaBaseURL:    "http://localhost:11434",
+				ChunkSize:        512,
+				ChunkOverlap:     100,
+				MaxContextChunks: 10,
+				TextSearch:       TextSearchConfig{MaxFileSize: -1},
+			},
+			wantErr:     true,
+			errContains: "text_search.max_file_size must be non-negative",
+		},
+		{
+			name: "invalid limits.max_results",
+			cfg: &Config{
+				Provider:         ProviderOllama,
+				OllamaModel:      "test",
+				OllamaBaseURL:    "http://localhost:11434",
+				ChunkSize:        512,
+				ChunkOverlap:     100,
+				MaxContextChunks: 10,
+				Limits:           LimitsConfig{MaxResults: -1},
+			},
+			wantErr:     true,
+			errContains: "limits.max_results must be non-negative",
+		},
+		{
+			name: "invalid embedding.callee_summaries",
+			cfg: &Config{
+				Provider:         ProviderOllama,
+				OllamaModel:      "test",
+				OllamaBaseURL:    "http://localhost:11434",
+				ChunkSize:        512,
+				ChunkOverlap:     100,
+				MaxContextChunks: 10,
+				Embedding:        EmbeddingConfig{CalleeSummaries: -1},
+			},
+			wantErr:     true,
+			errContains: "embedding.callee_summaries must be non-negative",
+		},
+		{
+			name: "invalid daemon.watch_debounce",
+			cfg: &Config{
+				Provider:         ProviderOllama,
+				OllamaModel:      "test",
+				OllamaBaseURL:    "http://localhost:11434",
+				ChunkSize:        512,
+				ChunkOverlap:     100,
+				MaxContextChunks: 10,
+				Daemon:           DaemonConfig{WatchDebounce: -time.Second},
+			},
+			wantErr:     true,
+			errContains: "daemon.watch_debounce must be non-negative",
+		},
+		{
+			name: "invalid daemon.project_quota",
+			cfg: &Config{
+				Provider:         ProviderOllama,
+				OllamaModel:      "test",
+				OllamaBaseURL:    "http://localhost:11434",
+				ChunkSize:        512,
+				ChunkOverlap:     100,
+				MaxContextChunks: 10,
+				Daemon:           DaemonConfig{ProjectQuota: ProjectQuota{MaxJobs: -1}},
+			},
+			wantErr:     true,
+			errContains: "daemon.project_quota limits must be non-negative",
+		},
+		{
+			name: "daemon.min_score out of range",
+			cfg: &Config{
+				Provider:         ProviderOllama,
+				OllamaModel:      "test",
+				OllamaBaseURL:    "http://localhost:11434",
+				ChunkSize:        512,
+				ChunkOverlap:     100,
+				MaxContextChunks: 10,
+				Daemon:           DaemonConfig{MinScore: 1.5},
+			},
+			wantErr:     true,
+			errContains: "daemon.min_score must be between 0 and 1",
+		},
+		{
+			name: "language pack without a checksum",
+			cfg: &Config{
+				Provider:         ProviderOllama,
+				OllamaModel:      "test",
+				OllamaBaseURL:    "http://localhost:11434",
+				ChunkSize:        512,
+				ChunkOverlap:     100,
+				MaxContextChunks: 10,
+				Languages: LanguagesConfig{Packs: []LanguagePack{{
+					Name:       "lua",
+					Extensions: []string{".lua"},
+					Libraries:  map[string]LanguageLibrary{"linux/amd64": {URL: "https://example.com/lua.so"}},
+				}}},
+			},
+			wantErr:     true,
+			errContains: "languages.packs[0].libraries[linux/amd64]: sha256 must be a hex SHA-256 checksum",
+		},
+		{
+			name: "invalid daemon.webhooks",
+			cfg: &Config{
+				Provider:         ProviderOllama,
+				OllamaModel:      "test",
+				OllamaBaseURL:    "http://localhost:11434",
+				ChunkSize:        512,
+				ChunkOverlap:     100,
+				MaxContextChunks: 10,
+				Daemon:           DaemonConfig{Webhooks: []string{"https://hooks.example.com/gcq", "hooks.example.com"}},
+			},
+			wantErr:     true,
+			errContains: `daemon.webhooks: "hooks.example.com" is not an http(s) URL`,
+		},
+		{
+			name: "invalid daemon.http_addr",
+			cfg: &Config{
+				Provider:         ProviderOllama,
+				OllamaModel:      "test",
+				OllamaBaseURL:    "http://localhost:11434",
+				ChunkSize:        512,
+				ChunkOverlap:     100,
+				MaxContextChunks: 10,
+				Daemon:           DaemonConfig{HTTPAddr: "localhost"},
+			},
+			wantErr:     true,
+			errContains: `daemon.http_addr: "localhost" is not a host:port address`,
+		},
+		{
+			name: "invalid index.backend",
+			cfg: &Config{
+				Provider:         ProviderOllama,
+				OllamaModel:      "test",
+				OllamaBaseURL:    "http://localhost:11434",
+				ChunkSize:        512,
+				ChunkOverlap:     100,
+				MaxContextChunks: 10,
+				Index:            IndexConfig{Backend: "annoy"},
+			},
+			wantErr:     true,
+			errContains: "invalid index.backend",
+		},
+		{
+			name: "invalid index.quantization",
+			cfg: &Config{
+				Provider:         ProviderOllama,
+				OllamaModel:      "test",
+				OllamaBaseURL:    "http://localhost:11434",
+				ChunkSize:        512,
+				ChunkOverlap:     100,
+				MaxContextChunks: 10,
+				Index:            IndexConfig{Quantization: "pq"},
+			},
+			wantErr:     true,
+			errContains: "invalid index.quantization",
+		},
+		{
+			name: "invalid index.hnsw_ef_search",
+			cfg: &Config{
+				Provider:         ProviderOllama,
+				OllamaModel:      "test",
+				OllamaBaseURL:    "http://localhost:11434",
+				ChunkSize:        512,
+				ChunkOverlap:     100,
+				MaxContextChunks: 10,
+				Index:            IndexConfig{Backend: IndexBackendHNSW, HNSWEfSearch: -1},
+			},
+			wantErr:     true,
+			errContains: "index.hnsw_ef_search must be non-negative",
+		},
+		{
+			name: "invalid index.enrichers pattern",
+			cfg: &Config{
+				Provider:         ProviderOllama,
+				OllamaModel:      "test",
+				OllamaBaseURL:    "http://localhost:11434",
+				ChunkSize:        512,
+				ChunkOverlap:     100,
+				MaxContextChunks: 10,
+				Index:            IndexConfig{Enrichers: []EnricherConfig{{Key: "ticket", Pattern: "[A-Z+-"}}},
+			},
+			wantErr:     true,
+			errContains: "index.enrichers[0]: invalid pattern",
+		},
+		{
+			name: "invalid call_graph.stop_symbols pattern",
+			cfg: &Config{
+				Provider:         ProviderOllama,
+				OllamaModel:      "test",
+				OllamaBaseURL:    "http://localhost:11434",
+				ChunkSize:        512,
+				ChunkOverlap:     100,
+				MaxContextChunks: 10,
+				CallGraph:        CallGraphConfig{StopSymbols: map[string][]string{"python": {"log_[*"}}},
+			},
+			wantErr:     true,
+			errContains: "call_graph.stop_symbols.python: invalid pattern",
+		},
 	}
 
 	for _, tt := range tests {
@@ -394,6 +623,7 @@ func TestApplyEnvOverrides(t *testing.T) {
 		os.Unsetenv("GCQ_CHUNK_OVERLAP")
 		os.Unsetenv("GCQ_CHUNK_SIZE")
 		os.Unsetenv("GCQ_VERBOSE")
+		os.Unsetenv("GCQ_DAEMON_REFRESH_INTERVAL")
 		for _, e := range origEnv {
 			parts := splitEnv(e)
 			if len(parts) == 2 {
@@ -548,6 +778,176 @@ func TestApplyEnvOverrides(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "daemon refresh interval override",
+			envVars: map[string]string{
+				"GCQ_DAEMON_REFRESH_INTERVAL": "5m",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Daemon.RefreshInterval != 5*time.Minute {
+					t.Errorf("Daemon.RefreshInterval = %v, want 5m", cfg.Daemon.RefreshInterval)
+				}
+			},
+		},
+		{
+			name: "daemon compaction override",
+			envVars: map[string]string{
+				"GCQ_DAEMON_COMPACT_INTERVAL":    "0",
+				"GCQ_DAEMON_COMPACT_MIN_REMOVED": "500",
+				"GCQ_DAEMON_COMPACT_RATIO":       "0.4",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Daemon.CompactInterval != 0 || cfg.Daemon.CompactMinRemoved != 500 || cfg.Daemon.CompactRatio != 0.4 {
+					t.Errorf("Daemon compaction = %v, %d, %g, want 0, 500, 0.4", cfg.Daemon.CompactInterval, cfg.Daemon.CompactMinRemoved, cfg.Daemon.CompactRatio)
+				}
+			},
+		},
+		{
+			name: "index stable ids override",
+			envVars: map[string]string{
+				"GCQ_INDEX_STABLE_IDS": "1",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if !cfg.Index.StableIDs {
+					t.Error("Index.StableIDs = false, want true")
+				}
+			},
+		},
+		{
+			name: "index dependencies ignore override",
+			envVars: map[string]string{
+				"GCQ_INDEX_DEPENDENCIES_IGNORE": "github.com/acme, @acme",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if len(cfg.Index.Dependencies.Ignore) != 2 || cfg.Index.Dependencies.Ignore[1] != "@acme" {
+					t.Errorf("Index.Dependencies.Ignore = %q", cfg.Index.Dependencies.Ignore)
+				}
+			},
+		},
+		{
+			name: "daemon watch override",
+			envVars: map[string]string{
+				"GCQ_DAEMON_WATCH":          "true",
+				"GCQ_DAEMON_WATCH_DEBOUNCE": "2s",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if !cfg.Daemon.Watch {
+					t.Error("Daemon.Watch = false, want true")
+				}
+				if cfg.Daemon.WatchDebounce != 2*time.Second {
+					t.Errorf("Daemon.WatchDebounce = %v, want 2s", cfg.Daemon.WatchDebounce)
+				}
+			},
+		},
+		{
+			name: "languages override",
+			envVars: map[string]string{
+				"GCQ_LANGUAGES_DIR":     "/opt/gcq/grammars",
+				"GCQ_LANGUAGES_OFFLINE": "1",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Languages.Dir != "/opt/gcq/grammars" || !cfg.Languages.Offline {
+					t.Errorf("Languages = %+v", cfg.Languages)
+				}
+			},
+		},
+		{
+			name: "daemon search budget override",
+			envVars: map[string]string{
+				"GCQ_DAEMON_SEARCH_BUDGET": "250ms",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Daemon.SearchBudget != 250*time.Millisecond {
+					t.Errorf("Daemon.SearchBudget = %v, want 250ms", cfg.Daemon.SearchBudget)
+				}
+			},
+		},
+		{
+			name: "daemon warmup override",
+			envVars: map[string]string{
+				"GCQ_DAEMON_WARMUP":         "false",
+				"GCQ_DAEMON_WARMUP_QUERIES": "parse config, open database connection ,",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Daemon.Warmup {
+					t.Error("Daemon.Warmup = true, want false")
+				}
+				want := []string{"parse config", "open database connection"}
+				if len(cfg.Daemon.WarmupQueries) != len(want) || cfg.Daemon.WarmupQueries[0] != want[0] || cfg.Daemon.WarmupQueries[1] != want[1] {
+					t.Errorf("Daemon.WarmupQueries = %q, want %q", cfg.Daemon.WarmupQueries, want)
+				}
+			},
+		},
+		{
+			name: "daemon webhooks override",
+			envVars: map[string]string{
+				"GCQ_DAEMON_WEBHOOKS":       "https://hooks.example.com/a, https://hooks.example.com/b",
+				"GCQ_DAEMON_WEBHOOK_EVENTS": "index.built",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if len(cfg.Daemon.Webhooks) != 2 || cfg.Daemon.Webhooks[1] != "https://hooks.example.com/b" {
+					t.Errorf("Daemon.Webhooks = %q", cfg.Daemon.Webhooks)
+				}
+				if len(cfg.Daemon.WebhookEvents) != 1 || cfg.Daemon.WebhookEvents[0] != "index.built" {
+					t.Errorf("Daemon.WebhookEvents = %q", cfg.Daemon.WebhookEvents)
+				}
+			},
+		},
+		{
+			name: "daemon http override",
+			envVars: map[string]string{
+				"GCQ_DAEMON_HTTP_ADDR":            "127.0.0.1:9848",
+				"GCQ_DAEMON_HTTP_ALLOWED_ORIGINS": "http://localhost:3000",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Daemon.HTTPAddr != "127.0.0.1:9848" {
+					t.Errorf("Daemon.HTTPAddr = %q", cfg.Daemon.HTTPAddr)
+				}
+				if len(cfg.Daemon.HTTPAllowedOrigins) != 1 || cfg.Daemon.HTTPAllowedOrigins[0] != "http://localhost:3000" {
+					t.Errorf("Daemon.HTTPAllowedOrigins = %q", cfg.Daemon.HTTPAllowedOrigins)
+				}
+			},
+		},
+		{
+			name: "daemon result cache override",
+			envVars: map[string]string{
+				"GCQ_DAEMON_RESULT_CACHE_SIZE": "0",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Daemon.ResultCacheSize != 0 {
+					t.Errorf("Daemon.ResultCacheSize = %d, want 0", cfg.Daemon.ResultCacheSize)
+				}
+			},
+		},
+		{
+			name: "daemon embed batching override",
+			envVars: map[string]string{
+				"GCQ_DAEMON_EMBED_BATCH_SIZE":  "64",
+				"GCQ_DAEMON_EMBED_CONCURRENCY": "4",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Daemon.EmbedBatchSize != 64 {
+					t.Errorf("Daemon.EmbedBatchSize = %d, want 64", cfg.Daemon.EmbedBatchSize)
+				}
+				if cfg.Daemon.EmbedConcurrency != 4 {
+					t.Errorf("Daemon.EmbedConcurrency = %d, want 4", cfg.Daemon.EmbedConcurrency)
+				}
+			},
+		},
+		{
+			name: "daemon project quota override",
+			envVars: map[string]string{
+				"GCQ_DAEMON_QUOTA_MAX_INDEX_MB":         "256",
+				"GCQ_DAEMON_QUOTA_EMBED_CALLS_PER_HOUR": "1000",
+				"GCQ_DAEMON_QUOTA_MAX_JOBS":             "2",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				want := ProjectQuota{MaxIndexMB: 256, EmbedCallsPerHour: 1000, MaxJobs: 2}
+				if cfg.Daemon.ProjectQuota != want {
+					t.Errorf("Daemon.ProjectQuota = %+v, want %+v", cfg.Daemon.ProjectQuota, want)
+				}
+			},
+		},
 		{
 			name: "socket path override",
 			envVars: map[string]string{
@@ -577,10 +977,12 @@ func TestApplyEnvOverrides(t *testing.T) {
 			os.Unsetenv("GCQ_CHUNK_OVERLAP")
 			os.Unsetenv("GCQ_CHUNK_SIZE")
 			os.Unsetenv("GCQ_VERBOSE")
+			os.Unsetenv("GCQ_DAEMON_REFRESH_INTERVAL")
 
 			// Set test env vars
 			for k, v := range tt.envVars {
 				os.Setenv(k, v)
+				defer os.Unsetenv(k)
 			}
 
 			cfg := DefaultConfig()
@@ -877,6 +1279,116 @@ max_context_chunks: 10
 			},
 			wantErr: false,
 		},
+		{
+			name: "daemon refresh interval",
+			configYAML: `
+warm:
+  provider: ollama
+  model: nomic-embed-text
+  base_url: http://localhost:11434
+daemon:
+  refresh_interval: 15m
+  semantic_roots:
+    - /srv/project-a
+    - /srv/project-b
+  warmup_queries:
+    - load configuration
+chunk_size: 512
+chunk_overlap: 100
+max_context_chunks: 10
+`,
+			checkCfg: func(t *testing.T, cfg *Config) {
+				if cfg.Daemon.RefreshInterval != 15*time.Minute {
+					t.Errorf("Daemon.RefreshInterval = %v, want 15m", cfg.Daemon.RefreshInterval)
+				}
+				if len(cfg.Daemon.SemanticRoots) != 2 || cfg.Daemon.SemanticRoots[1] != "/srv/project-b" {
+					t.Errorf("Daemon.SemanticRoots = %v, want [/srv/project-a /srv/project-b]", cfg.Daemon.SemanticRoots)
+				}
+				if !cfg.Daemon.Warmup {
+					t.Error("Daemon.Warmup = false, want true (default)")
+				}
+				if len(cfg.Daemon.WarmupQueries) != 1 || cfg.Daemon.WarmupQueries[0] != "load configuration" {
+					t.Errorf("Daemon.WarmupQueries = %q, want [load configuration]", cfg.Daemon.WarmupQueries)
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name: "text search settings",
+			configYAML: `
+warm:
+  provider: ollama
+  model: nomic-embed-text
+  base_url: http://localhost:11434
+text_search:
+  context_lines: 0
+  max_results: 500
+  skip_binary: false
+chunk_size: 512
+chunk_overlap: 100
+max_context_chunks: 10
+`,
+			checkCfg: func(t *testing.T, cfg *Config) {
+				if cfg.TextSearch.ContextLines != 0 || cfg.TextSearch.MaxResults != 500 || cfg.TextSearch.SkipBinary {
+					t.Errorf("TextSearch = %+v, want context_lines 0, max_results 500, skip_binary false", cfg.TextSearch)
+				}
+				if cfg.TextSearch.MaxFileSize != 1<<20 {
+					t.Errorf("TextSearch.MaxFileSize = %d, want default %d", cfg.TextSearch.MaxFileSize, 1<<20)
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name: "embedding templates",
+			configYAML: `
+warm:
+  provider: ollama
+  model: nomic-embed-text
+  base_url: http://localhost:11434
+embedding:
+  templates:
+    default: "{{.Name}}: {{.Signature}}"
+    go: |
+      {{.Type}} {{.Name}}
+      {{.Docstring}}
+chunk_size: 512
+chunk_overlap: 100
+max_context_chunks: 10
+`,
+			checkCfg: func(t *testing.T, cfg *Config) {
+				if len(cfg.Embedding.Templates) != 2 {
+					t.Fatalf("Embedding.Templates = %v, want 2 entries", cfg.Embedding.Templates)
+				}
+				if cfg.Embedding.Templates["default"] != "{{.Name}}: {{.Signature}}" {
+					t.Errorf("default template = %q", cfg.Embedding.Templates["default"])
+				}
+			},
+			wantErr: false,
+		},
+		{
+			name: "index dependencies",
+			configYAML: `
+warm:
+  provider: mock
+search:
+  provider: mock
+index:
+  dependencies:
+    stdlib:
+      python: [tomllib, zoneinfo]
+    ignore:
+      - acme_internal
+`,
+			checkCfg: func(t *testing.T, cfg *Config) {
+				deps := cfg.Index.Dependencies
+				if len(deps.Stdlib["python"]) != 2 || deps.Stdlib["python"][1] != "zoneinfo" {
+					t.Errorf("Index.Dependencies.Stdlib = %v", deps.Stdlib)
+				}
+				if len(deps.Ignore) != 1 || deps.Ignore[0] != "acme_internal" {
+					t.Errorf("Index.Dependencies.Ignore = %q", deps.Ignore)
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1069,3 +1581,149 @@ func TestNestedConfigValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigRedacted(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Warm = WarmConfig{Provider: ProviderHuggingFace, Model: "m", Token: "hf_warm"}
+	cfg.Search = SearchConfig{Provider: ProviderOllama, Model: "m", BaseURL: "http://localhost:11434"}
+	cfg.HFToken = "hf_legacy"
+	cfg.OllamaAPIKey = "ollama-key"
+	cfg.Index.Commits.GitHubToken = "ghp_token"
+
+	redacted := cfg.Redacted()
+
+	if redacted.Warm.Token != RedactedValue {
+		t.Errorf("Warm.Token = %q, want %q", redacted.Warm.Token, RedactedValue)
+	}
+	if redacted.Search.Token != "" {
+		t.Errorf("Search.Token = %q, empty tokens should stay empty", redacted.Search.Token)
+	}
+	if redacted.HFToken != RedactedValue || redacted.OllamaAPIKey != RedactedValue {
+		t.Errorf("Legacy secrets not redacted: %q, %q", redacted.HFToken, redacted.OllamaAPIKey)
+	}
+	if redacted.Index.Commits.GitHubToken != RedactedValue {
+		t.Errorf("GitHubToken = %q, want %q", redacted.Index.Commits.GitHubToken, RedactedValue)
+	}
+	if redacted.Warm.Model != "m" || redacted.Search.BaseURL != "http://localhost:11434" {
+		t.Error("Non-secret fields should be preserved")
+	}
+	if cfg.Warm.Token != "hf_warm" {
+		t.Error("Redacted should not modify the original config")
+	}
+
+	secrets := cfg.Secrets()
+	if len(secrets) != 4 {
+		t.Errorf("Secrets() = %v, want 4 values", secrets)
+	}
+}
+
+func TestLimitsConfig(t *testing.T) {
+	limits := DefaultLimitsConfig()
+	limits.SearchResults = 20
+	limits.MaxResults = 50
+
+	tests := []struct {
+		name      string
+		got, want int
+	}{
+		{"configured search default", limits.SearchLimit(0), 20},
+		{"search override", limits.SearchLimit(3), 3},
+		{"search capped", limits.SearchLimit(500), 50},
+		{"context default", limits.ContextLimit(0), DefaultContextResults},
+		{"zero config uses built-in", LimitsConfig{}.SearchLimit(0), DefaultSearchResults},
+		{"no cap", LimitsConfig{}.ContextLimit(5000), 5000},
+		{"bundle default", LimitsConfig{}.BundleBudget(0), DefaultBundleTokens},
+		{"bundle override", limits.BundleBudget(2000), 2000},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLimitsEnvOverrides(t *testing.T) {
+	t.Setenv("GCQ_LIMIT_SEARCH_RESULTS", "25")
+	t.Setenv("GCQ_LIMIT_DEPENDENCIES", "0")
+	t.Setenv("GCQ_LIMIT_MAX_RESULTS", "bogus")
+
+	cfg := DefaultConfig()
+	applyEnvOverrides(cfg)
+
+	if cfg.Limits.SearchResults != 25 {
+		t.Errorf("SearchResults = %d, want 25", cfg.Limits.SearchResults)
+	}
+	if cfg.Limits.Dependencies != 0 {
+		t.Errorf("Dependencies = %d, want 0 (unlimited)", cfg.Limits.Dependencies)
+	}
+	if cfg.Limits.MaxResults != DefaultMaxResults {
+		t.Errorf("MaxResults = %d, invalid value should keep default", cfg.Limits.MaxResults)
+	}
+}
+
+func TestLoadLanguages(t *testing.T) {
+	const packs = `languages:
+  offline: true
+  packs:
+    - name: lua
+      extensions: [".lua"]
+      functions: ["function_statement"]
+      libraries:
+        linux/amd64:
+          url: https://example.com/lua.so
+          sha256: "0000000000000000000000000000000000000000000000000000000000000000"
+`
+	dir := t.TempDir()
+	userPath := filepath.Join(dir, "user.yaml")
+	if err := os.WriteFile(userPath, []byte(packs), 0644); err != nil {
+		t.Fatalf("Failed to write user config: %v", err)
+	}
+	// A project config listing packs of its own must not add to them
+	project := filepath.Join(dir, "project")
+	if err := os.MkdirAll(filepath.Join(project, ".gcq"), 0755); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	projectPacks := `languages:
+  packs:
+    - name: evil
+      extensions: [".evil"]
+      functions: ["f"]
+      libraries:
+        linux/amd64:
+          url: ./evil.so
+          sha256: "1111111111111111111111111111111111111111111111111111111111111111"
+`
+	if err := os.WriteFile(filepath.Join(project, ".gcq", "config.yaml"), []byte(projectPacks), 0644); err != nil {
+		t.Fatalf("Failed to write project config: %v", err)
+	}
+	t.Chdir(project)
+
+	tests := []struct {
+		name        string
+		userPath    string
+		wantPacks   []string
+		wantOffline bool
+	}{
+		{"user config", userPath, []string{"lua"}, true},
+		{"missing user config", filepath.Join(dir, "missing.yaml"), nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GCQ_USER_CONFIG", tt.userPath)
+			languages, err := LoadLanguages()
+			if err != nil {
+				t.Fatalf("LoadLanguages failed: %v", err)
+			}
+			var names []string
+			for _, pack := range languages.Packs {
+				names = append(names, pack.Name)
+			}
+			if len(names) != len(tt.wantPacks) || (len(names) > 0 && names[0] != tt.wantPacks[0]) {
+				t.Errorf("packs = %v, want %v", names, tt.wantPacks)
+			}
+			if languages.Offline != tt.wantOffline {
+				t.Errorf("Offline = %v, want %v", languages.Offline, tt.wantOffline)
+			}
+		})
+	}
+}
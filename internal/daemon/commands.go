@@ -7,11 +7,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strings"
 	"syscall"
 	"time"
 )
@@ -65,9 +63,19 @@ func Start(opts *StartOptions) (*StartResult, error) {
 		Env:  env,
 	}
 
+	var logPath string
 	if opts.Background {
-		cmd.Stdout = nil
-		cmd.Stderr = nil
+		// Background output goes to the rotating log so it can be read
+		// with gcq logs after this process exits.
+		logFile, err := OpenLogFile()
+		if err != nil {
+			return nil, err
+		}
+		defer logFile.Close()
+		logPath = logFile.Name()
+		cmd.Args = append(cmd.Args, "-log", logPath)
+		cmd.Stdout = logFile
+		cmd.Stderr = logFile
 		cmd.SysProcAttr = &syscall.SysProcAttr{
 			Setsid: true,
 		}
@@ -123,6 +131,7 @@ func Start(opts *StartOptions) (*StartResult, error) {
 				Success:   false,
 				PID:       pid,
 				StartedAt: startedAt,
+				LogPath:   logPath,
 				Error:     fmt.Sprintf("daemon not ready: %v", err),
 			}, nil
 		}
@@ -140,6 +149,7 @@ func Start(opts *StartOptions) (*StartResult, error) {
 		Success:   true,
 		PID:       pid,
 		StartedAt: startedAt,
+		LogPath:   logPath,
 	}, nil
 }
 
@@ -189,8 +199,7 @@ func waitForReady(ctx context.Context, timeout time.Duration) (bool, error) {
 		}
 
 		// Try to ping daemon directly - don't require PID file
-		socketPath := GetSocketPath()
-		conn, err := net.Dial("unix", socketPath)
+		conn, err := dialDaemon()
 		if err == nil {
 			conn.Close()
 			return true, nil
@@ -280,28 +289,9 @@ func Stop() (*StopResult, error) {
 	}, nil
 }
 
-// sendStopCommand sends a stop command via Unix socket
+// sendStopCommand sends a stop command to the daemon
 func sendStopCommand() error {
-	socketPath := GetSocketPath()
-	var conn net.Conn
-	var err error
-
-	// Check if we should use TCP (Windows)
-	isWindows := false
-	if runtime := strings.ToLower(os.Getenv("GOOS")); runtime == "windows" || !strings.HasPrefix(socketPath, "/") {
-		isWindows = true
-	}
-
-	if isWindows {
-		port := os.Getenv("GCQ_TCP_PORT")
-		if port == "" {
-			port = DefaultTCPPort
-		}
-		conn, err = net.Dial("tcp", "localhost:"+port)
-	} else {
-		conn, err = net.Dial("unix", socketPath)
-	}
-
+	conn, err := dialDaemon()
 	if err != nil {
 		return fmt.Errorf("connecting to daemon: %w", err)
 	}
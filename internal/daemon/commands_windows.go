@@ -4,16 +4,27 @@
 package daemon
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"syscall"
 	"time"
+
+	"golang.org/x/sys/windows"
 )
 
+// detachedProcess starts the daemon without a console so it outlives the
+// terminal that launched it (DETACHED_PROCESS is not exported by syscall).
+const detachedProcess = 0x00000008
+
+// Start starts the daemon
 func Start(opts *StartOptions) (*StartResult, error) {
+	// Check if already running
 	status, err := CheckStatus()
 	if err == nil && status.Running && status.Ready {
 		return &StartResult{
@@ -23,6 +34,7 @@ func Start(opts *StartOptions) (*StartResult, error) {
 		}, nil
 	}
 
+	// Find daemon binary if not specified
 	daemonPath := opts.DaemonPath
 	if daemonPath == "" {
 		daemonPath = findDaemonBinary()
@@ -31,6 +43,7 @@ func Start(opts *StartOptions) (*StartResult, error) {
 		}
 	}
 
+	// Prepare environment
 	env := os.Environ()
 	if opts.SocketPath != "" {
 		env = append(env, "GCQ_SOCKET_PATH="+opts.SocketPath)
@@ -42,14 +55,39 @@ func Start(opts *StartOptions) (*StartResult, error) {
 		env = append(env, "GCQ_VERBOSE=true")
 	}
 
+	// Build args with project path
+	args := []string{daemonPath}
+	if opts.ProjectPath != "" {
+		args = append(args, "-project", opts.ProjectPath)
+	}
+	if opts.SocketPath != "" {
+		args = append(args, "-socket", opts.SocketPath)
+	}
+
+	// Start the daemon
 	cmd := &exec.Cmd{
 		Path: daemonPath,
+		Args: args,
 		Env:  env,
 	}
 
+	var logPath string
 	if opts.Background {
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+		logFile, err := OpenLogFile()
+		if err != nil {
+			return nil, err
+		}
+		defer logFile.Close()
+		logPath = logFile.Name()
+		cmd.Args = append(cmd.Args, "-log", logPath)
+		cmd.Stdout = logFile
+		cmd.Stderr = logFile
+		// A new process group keeps Ctrl+C in the launching console from
+		// reaching the daemon.
+		cmd.SysProcAttr = &syscall.SysProcAttr{
+			CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess,
+			HideWindow:    true,
+		}
 	} else {
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
@@ -62,117 +100,269 @@ func Start(opts *StartOptions) (*StartResult, error) {
 	pid := cmd.Process.Pid
 	startedAt := time.Now()
 
+	// Write PID file
 	if err := WritePID(pid); err != nil {
 		cmd.Process.Kill()
 		return nil, fmt.Errorf("writing PID file: %w", err)
 	}
 
-	result := &StartResult{
-		Success:   true,
+	// Write initial status
+	if err := WriteStatus(&DaemonStatus{
+		Running:   true,
 		PID:       pid,
+		Ready:     false,
 		StartedAt: startedAt,
+	}); err != nil {
+		cmd.Process.Kill()
+		RemovePID()
+		return nil, fmt.Errorf("writing status: %w", err)
 	}
 
+	// Wait for ready if requested
 	if opts.WaitForReady {
-		if err := waitForReady(opts.SocketPath, opts.ReadyTimeout); err != nil {
-			return result, fmt.Errorf("waiting for ready: %w", err)
+		timeout := opts.ReadyTimeout
+		if timeout <= 0 {
+			timeout = ReadyTimeout
 		}
-		result.Ready = true
+
+		waitCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		ready, err := waitForReady(waitCtx, timeout)
+		if err != nil {
+			if !opts.Background {
+				cmd.Process.Kill()
+				RemovePID()
+				RemoveStatus()
+			}
+			return &StartResult{
+				Success:   false,
+				PID:       pid,
+				StartedAt: startedAt,
+				LogPath:   logPath,
+				Error:     fmt.Sprintf("daemon not ready: %v", err),
+			}, nil
+		}
+
+		// Update status to ready
+		WriteStatus(&DaemonStatus{
+			Running:   true,
+			PID:       pid,
+			Ready:     ready,
+			StartedAt: startedAt,
+		})
 	}
 
-	return result, nil
+	return &StartResult{
+		Success:   true,
+		PID:       pid,
+		StartedAt: startedAt,
+		Ready:     opts.WaitForReady,
+		LogPath:   logPath,
+	}, nil
 }
 
-func Stop() (*StopResult, error) {
-	pid, err := ReadPID()
-	if err != nil {
-		return nil, fmt.Errorf("reading PID: %w", err)
+// findDaemonBinary finds the daemon binary path
+func findDaemonBinary() string {
+	// Check bin directories relative to the current directory
+	for _, dir := range []string{".", ".."} {
+		exePath := filepath.Join(dir, "bin", "gcqd.exe")
+		if _, err := os.Stat(exePath); err == nil {
+			return exePath
+		}
 	}
 
-	process, err := os.FindProcess(pid)
-	if err != nil {
-		return nil, fmt.Errorf("finding process: %w", err)
+	// Check GCQ_DAEMON_PATH env var
+	if path := os.Getenv("GCQ_DAEMON_PATH"); path != "" {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
 	}
 
-	if err := process.Kill(); err != nil {
-		return nil, fmt.Errorf("killing process: %w", err)
+	// Check next to the gcq executable
+	if exePath, err := os.Executable(); err == nil {
+		daemonPath := filepath.Join(filepath.Dir(exePath), "gcqd.exe")
+		if _, err := os.Stat(daemonPath); err == nil {
+			return daemonPath
+		}
 	}
 
-	RemovePID()
+	// Use default, resolved through PATH
+	return "gcqd.exe"
+}
 
-	return &StopResult{
-		Success: true,
-	}, nil
+// readyPipePath names the pipe the daemon serving TCP port serves once it
+// accepts connections. It must match readyPipePath in cmd/gcqd.
+func readyPipePath(port string) string {
+	return `\\.\pipe\gcqd-` + port
 }
 
-func findDaemonBinary() string {
-	exePath, err := os.Executable()
+// probeReadyPipe reports whether the daemon's readiness pipe exists. A
+// busy pipe exists too: the daemon is answering another probe.
+func probeReadyPipe(path string) bool {
+	f, err := os.Open(path)
 	if err != nil {
-		return ""
+		return errors.Is(err, windows.ERROR_PIPE_BUSY)
 	}
+	f.Close()
+	return true
+}
 
-	exeDir := filepath.Dir(exePath)
-	daemonPath := filepath.Join(exeDir, "gcqd")
+// waitForReady waits for the daemon to serve its readiness pipe, which it
+// creates once its TCP port accepts connections. It checks the context for
+// cancellation and respects context deadlines.
+func waitForReady(ctx context.Context, timeout time.Duration) (bool, error) {
+	deadline := time.Now().Add(timeout)
 
-	if _, err := os.Stat(daemonPath); err == nil {
-		return daemonPath
+	// Use earlier of context deadline or timeout
+	if d, ok := ctx.Deadline(); ok {
+		if d.Before(deadline) {
+			deadline = d
+		}
 	}
 
-	return ""
-}
+	path := readyPipePath(GetTCPPort())
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return false, fmt.Errorf("context cancelled: %w", ctx.Err())
+		default:
+		}
 
-func getSocketPath() (string, error) {
-	socketPath := os.Getenv("GCQ_SOCKET_PATH")
-	if socketPath != "" {
-		return socketPath, nil
+		if probeReadyPipe(path) {
+			return true, nil
+		}
+		time.Sleep(100 * time.Millisecond)
 	}
 
-	configPath := os.Getenv("GCQ_CONFIG_PATH")
-	if configPath == "" {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return "", fmt.Errorf("getting home dir: %w", err)
-		}
-		configPath = filepath.Join(homeDir, ".gcq", "config.yaml")
+	return false, fmt.Errorf("timeout waiting for daemon to be ready")
+}
+
+// Stop stops the daemon. Windows has no SIGTERM, so a graceful stop goes
+// through the daemon's stop command; if that fails the process is
+// terminated with TerminateProcess and, as a last resort, taskkill.
+func Stop() (*StopResult, error) {
+	// Check if PID file exists
+	if !PIDExists() {
+		return &StopResult{
+			Success: false,
+			Error:   "daemon not running (no PID file)",
+		}, nil
 	}
 
-	data, err := os.ReadFile(configPath)
+	// Read PID
+	pid, err := ReadPID()
 	if err != nil {
-		return "", fmt.Errorf("reading config: %w", err)
+		return &StopResult{
+			Success: false,
+			Error:   fmt.Sprintf("failed to read PID: %v", err),
+		}, nil
 	}
 
-	var config struct {
-		SocketPath string `yaml:"socket_path"`
+	// Check if process is running
+	if !IsProcessRunning(pid) {
+		// Cleanup stale PID file
+		RemovePID()
+		RemoveStatus()
+		return &StopResult{
+			Success: false,
+			Error:   "daemon not running (process not found)",
+		}, nil
+	}
+
+	// Try graceful shutdown first via the daemon connection
+	if err := sendStopCommand(); err == nil {
+		if waitForShutdown(pid, ShutdownTimeout) {
+			RemovePID()
+			RemoveStatus()
+			return &StopResult{
+				Success:   true,
+				PID:       pid,
+				StoppedAt: time.Now(),
+			}, nil
+		}
 	}
-	if err := json.Unmarshal(data, &config); err != nil {
-		return "", fmt.Errorf("parsing config: %w", err)
+
+	// Force termination if graceful shutdown failed
+	if err := terminateProcess(pid); err != nil {
+		return &StopResult{
+			Success: false,
+			PID:     pid,
+			Error:   fmt.Sprintf("failed to kill process: %v", err),
+		}, nil
 	}
 
-	if config.SocketPath == "" {
-		return "", fmt.Errorf("socket_path not configured")
+	// Wait for process to exit
+	waitForShutdown(pid, 2*time.Second)
+
+	// Cleanup
+	RemovePID()
+	RemoveStatus()
+
+	return &StopResult{
+		Success:   true,
+		PID:       pid,
+		StoppedAt: time.Now(),
+	}, nil
+}
+
+// terminateProcess kills the process with Process.Kill, falling back to
+// taskkill for processes this user cannot open directly.
+func terminateProcess(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err == nil {
+		if err = process.Kill(); err == nil {
+			return nil
+		}
 	}
 
-	return config.SocketPath, nil
+	out, taskkillErr := exec.Command("taskkill", "/F", "/T", "/PID", strconv.Itoa(pid)).CombinedOutput()
+	if taskkillErr != nil {
+		return fmt.Errorf("%v; taskkill: %s", err, out)
+	}
+	return nil
 }
 
-func processAlive(process *os.Process) bool {
-	err := process.Signal(os.Signal(nil))
-	return err == nil
+// sendStopCommand sends a stop command to the daemon
+func sendStopCommand() error {
+	conn, err := dialDaemon()
+	if err != nil {
+		return fmt.Errorf("connecting to daemon: %w", err)
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// Send stop command
+	cmd := map[string]interface{}{
+		"type": "stop",
+		"id":   "stop-cmd",
+	}
+
+	encoder := json.NewEncoder(conn)
+	return encoder.Encode(cmd)
 }
 
-func waitForReady(socketPath string, timeout time.Duration) error {
+// waitForShutdown waits for the process to shutdown
+func waitForShutdown(pid int, timeout time.Duration) bool {
 	deadline := time.Now().Add(timeout)
+
 	for time.Now().Before(deadline) {
-		conn, err := net.Dial("unix", socketPath)
-		if err == nil {
-			conn.Close()
-			return nil
+		if !IsProcessRunning(pid) {
+			return true
 		}
-		time.Sleep(100 * time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
 	}
-	return fmt.Errorf("timeout waiting for daemon ready")
+
+	return false
+}
+
+// Status returns the current daemon status
+func Status() (*DaemonStatus, error) {
+	return CheckStatus()
 }
 
+// GetStatus returns a formatted status result
 func GetStatus() (*StatusResult, error) {
 	status, err := CheckStatus()
 	if err != nil {
@@ -184,11 +374,25 @@ func GetStatus() (*StatusResult, error) {
 		}, nil
 	}
 
-	return &StatusResult{
-		Status:    "running",
+	result := &StatusResult{
 		Running:   status.Running,
 		Ready:     status.Ready,
 		PID:       status.PID,
+		Version:   status.Version,
 		StartedAt: status.StartedAt,
-	}, nil
+	}
+
+	if !status.Running {
+		result.Status = "stopped"
+	} else if !status.Ready {
+		result.Status = "starting"
+	} else {
+		result.Status = "running"
+	}
+
+	if status.Error != "" {
+		result.Error = status.Error
+	}
+
+	return result, nil
 }
@@ -8,9 +8,9 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
-	"syscall"
 	"time"
 )
 
@@ -151,17 +151,6 @@ func RemoveStatus() error {
 	return nil
 }
 
-// IsProcessRunning checks if a process with the given PID is running
-func IsProcessRunning(pid int) bool {
-	process, err := os.FindProcess(pid)
-	if err != nil {
-		return false
-	}
-	// On Unix, FindProcess always succeeds, we need to send signal 0 to check
-	err = process.Signal(syscall.Signal(0))
-	return err == nil
-}
-
 // GetSocketPath returns the socket path from config or default
 func GetSocketPath() string {
 	if socketPath := os.Getenv("GCQ_SOCKET_PATH"); socketPath != "" {
@@ -170,23 +159,38 @@ func GetSocketPath() string {
 	return DefaultSocketPath
 }
 
+// GetTCPPort returns the TCP port the daemon listens on where Unix sockets
+// are not used
+func GetTCPPort() string {
+	if port := os.Getenv("GCQ_TCP_PORT"); port != "" {
+		return port
+	}
+	return DefaultTCPPort
+}
+
+// dialDaemon connects to the daemon selected by the environment
+func dialDaemon() (net.Conn, error) {
+	return dialAddress(GetSocketPath(), GetTCPPort())
+}
+
+// dialAddress connects over TCP on Windows, or when the socket path is not
+// an absolute Unix path, and over the Unix socket otherwise
+func dialAddress(socketPath, tcpPort string) (net.Conn, error) {
+	if runtime.GOOS == "windows" || !strings.HasPrefix(socketPath, "/") {
+		return net.DialTimeout("tcp", "localhost:"+tcpPort, 2*time.Second)
+	}
+	return net.DialTimeout("unix", socketPath, 2*time.Second)
+}
+
 // pingDaemon sends a status ping to the daemon and returns the response
 func pingDaemon() (*DaemonStatus, error) {
-	socketPath := GetSocketPath()
-	var conn net.Conn
-	var err error
-
-	// Check if we should use TCP (Windows)
-	if runtime := strings.ToLower(os.Getenv("GOOS")); runtime == "windows" || !strings.HasPrefix(socketPath, "/") {
-		port := os.Getenv("GCQ_TCP_PORT")
-		if port == "" {
-			port = DefaultTCPPort
-		}
-		conn, err = net.Dial("tcp", "localhost:"+port)
-	} else {
-		conn, err = net.Dial("unix", socketPath)
-	}
+	return pingAddress(GetSocketPath(), GetTCPPort())
+}
 
+// pingAddress sends a status ping to the daemon listening on socketPath or
+// tcpPort and returns the response
+func pingAddress(socketPath, tcpPort string) (*DaemonStatus, error) {
+	conn, err := dialAddress(socketPath, tcpPort)
 	if err != nil {
 		return nil, fmt.Errorf("connecting to daemon: %w", err)
 	}
@@ -0,0 +1,272 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// LogsDirName is the name of the log directory inside the daemon directory
+	LogsDirName = "logs"
+	// LogFileName is the name of the background daemon log file
+	LogFileName = "daemon.log"
+	// LogMaxSize is the size at which the log file is rotated
+	LogMaxSize = 10 * 1024 * 1024
+	// LogMaxBackups is the number of rotated log files kept
+	LogMaxBackups = 3
+	// logPollInterval is how often FollowLog checks the log file for new output
+	logPollInterval = 250 * time.Millisecond
+)
+
+// LogsDir returns the path to the daemon log directory
+func LogsDir() string {
+	return filepath.Join(DaemonDir(), LogsDirName)
+}
+
+// LogFile returns the path to the background daemon log file
+func LogFile() string {
+	return filepath.Join(LogsDir(), LogFileName)
+}
+
+// OpenLogFile rotates the daemon log if it has grown past LogMaxSize and
+// opens it for appending. The returned file is meant to be handed to the
+// daemon process as its stdout and stderr, which catch output such as a
+// panic; the daemon writes its log through a LogWriter on the same file.
+func OpenLogFile() (*os.File, error) {
+	if err := os.MkdirAll(LogsDir(), 0755); err != nil {
+		return nil, fmt.Errorf("creating logs directory: %w", err)
+	}
+
+	path := LogFile()
+	if err := RotateLog(path, LogMaxSize, LogMaxBackups); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening log file: %w", err)
+	}
+	return file, nil
+}
+
+// LogWriter appends to a log file, rotating it with RotateLog before a
+// write once the file has reached its maximum size, so the log of a
+// long-running daemon stays bounded. It is safe for concurrent use.
+type LogWriter struct {
+	path    string
+	maxSize int64
+	backups int
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	redirect bool
+}
+
+// NewLogWriter opens the log file at path for appending, rotating it once
+// it reaches maxSize bytes and keeping backups older files
+func NewLogWriter(path string, maxSize int64, backups int) (*LogWriter, error) {
+	w := &LogWriter{path: path, maxSize: maxSize, backups: backups}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// RedirectStdout points the process's stdout and stderr at the log file,
+// now and again after every rotation, so panics and output printed rather
+// than logged land in the current log instead of a rotated backup. Such
+// output is not counted towards the size the log is rotated at.
+func (w *LogWriter) RedirectStdout() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := redirectStdout(w.file); err != nil {
+		return err
+	}
+	w.redirect = true
+	return nil
+}
+
+// open opens the log file and reads its size
+func (w *LogWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("checking log file: %w", err)
+	}
+	w.file, w.size = file, info.Size()
+	return nil
+}
+
+// Write appends p to the log file, rotating the file first when it has
+// reached its maximum size. When rotation fails, as it does on Windows
+// while another process holds the file open, output keeps going to the
+// same file and rotation is tried again after another maxSize bytes.
+func (w *LogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.size >= w.maxSize {
+		if err := w.file.Close(); err != nil {
+			return 0, fmt.Errorf("closing log file: %w", err)
+		}
+		rotateErr := RotateLog(w.path, w.maxSize, w.backups)
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+		if rotateErr != nil {
+			w.size = 0
+		}
+		if w.redirect {
+			// Should this fail, printed output keeps going to the backup
+			redirectStdout(w.file)
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the log file
+func (w *LogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+// RotateLog renames path to path.1, shifting older backups up to
+// path.<backups>, once the file reaches maxSize bytes. The oldest backup is
+// dropped. Missing or small files are left alone.
+func RotateLog(path string, maxSize int64, backups int) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("checking log file: %w", err)
+	}
+	if info.Size() < maxSize {
+		return nil
+	}
+
+	if backups <= 0 {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("removing log file: %w", err)
+		}
+		return nil
+	}
+
+	oldest := fmt.Sprintf("%s.%d", path, backups)
+	if err := os.Remove(oldest); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing old log file: %w", err)
+	}
+	for i := backups - 1; i >= 1; i-- {
+		from := fmt.Sprintf("%s.%d", path, i)
+		to := fmt.Sprintf("%s.%d", path, i+1)
+		if err := os.Rename(from, to); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("rotating log file: %w", err)
+		}
+	}
+	if err := os.Rename(path, path+".1"); err != nil {
+		return fmt.Errorf("rotating log file: %w", err)
+	}
+	return nil
+}
+
+// TailLog writes the last n lines of the log file at path to w and returns
+// the offset it stopped reading at. n <= 0 writes the whole file.
+func TailLog(w io.Writer, path string, n int) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("opening log file: %w", err)
+	}
+	defer file.Close()
+
+	var lines []string
+	var offset int64
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			offset += int64(len(line))
+			lines = append(lines, line)
+			if n > 0 && len(lines) > n {
+				lines = lines[1:]
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return offset, fmt.Errorf("reading log file: %w", err)
+		}
+	}
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, line); err != nil {
+			return offset, err
+		}
+	}
+	return offset, nil
+}
+
+// FollowLog copies output appended to the log file at path after offset to w
+// until ctx is cancelled. When the file shrinks, as it does after rotation,
+// it is read again from the start.
+func FollowLog(ctx context.Context, w io.Writer, path string, offset int64) error {
+	ticker := time.NewTicker(logPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("checking log file: %w", err)
+		}
+		if info.Size() < offset {
+			offset = 0
+		}
+		if info.Size() == offset {
+			continue
+		}
+
+		n, err := copyFrom(w, path, offset)
+		offset += n
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// copyFrom copies the contents of path from offset to the end of the file to w.
+func copyFrom(w io.Writer, path string, offset int64) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("opening log file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("seeking log file: %w", err)
+	}
+	return io.Copy(w, file)
+}
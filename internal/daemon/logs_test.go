@@ -0,0 +1,262 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestRotateLog tests that an oversized log is shifted into numbered backups
+func TestRotateLog(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, LogFileName)
+
+	// Missing file is not an error
+	if err := RotateLog(path, 10, 2); err != nil {
+		t.Fatalf("RotateLog on missing file failed: %v", err)
+	}
+
+	write := func(name, content string) {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	read := func(name string) string {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		return string(data)
+	}
+
+	// Small file is left alone
+	write(path, "short")
+	if err := RotateLog(path, 10, 2); err != nil {
+		t.Fatalf("RotateLog failed: %v", err)
+	}
+	if read(path) != "short" {
+		t.Error("Expected small log to be left in place")
+	}
+
+	write(path, "first log output")
+	if err := RotateLog(path, 10, 2); err != nil {
+		t.Fatalf("RotateLog failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected log file to be moved away")
+	}
+	if read(path+".1") != "first log output" {
+		t.Errorf("Unexpected backup content: %q", read(path+".1"))
+	}
+
+	write(path, "second log output")
+	if err := RotateLog(path, 10, 2); err != nil {
+		t.Fatalf("RotateLog failed: %v", err)
+	}
+	write(path, "third log output")
+	if err := RotateLog(path, 10, 2); err != nil {
+		t.Fatalf("RotateLog failed: %v", err)
+	}
+
+	if read(path+".1") != "third log output" {
+		t.Errorf("Unexpected .1 content: %q", read(path+".1"))
+	}
+	if read(path+".2") != "second log output" {
+		t.Errorf("Unexpected .2 content: %q", read(path+".2"))
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("Expected only 2 backups to be kept")
+	}
+}
+
+// TestLogWriter tests that the log is rotated while it is written, not
+// only when the daemon starts
+func TestLogWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LogFileName)
+	if err := os.WriteFile(path, []byte("existing output\n"), 0644); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+
+	w, err := NewLogWriter(path, 64, 2)
+	if err != nil {
+		t.Fatalf("NewLogWriter failed: %v", err)
+	}
+	defer w.Close()
+
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 10 {
+				if _, err := fmt.Fprintf(w, "writer %d line %d\n", i, j); err != nil {
+					t.Errorf("Write failed: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		// A file is rotated before the write that follows the one taking
+		// it to the limit
+		if len(data) >= 64+len("writer 0 line 0\n") {
+			t.Errorf("%s grew to %d bytes, past its limit", name, len(data))
+		}
+		if len(data) > 0 && !strings.HasSuffix(string(data), "\n") {
+			t.Errorf("%s ends with a partial line: %q", name, data)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("Expected only 2 backups to be kept")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	if strings.Contains(string(data), "existing output") {
+		t.Error("Expected the output written before the writer was opened to be rotated away")
+	}
+}
+
+// TestOpenLogFile tests that the log file is created under the daemon directory
+func TestOpenLogFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("GCQ_DAEMON_DIR", tmpDir)
+
+	file, err := OpenLogFile()
+	if err != nil {
+		t.Fatalf("OpenLogFile failed: %v", err)
+	}
+	defer file.Close()
+
+	expected := filepath.Join(tmpDir, LogsDirName, LogFileName)
+	if file.Name() != expected {
+		t.Errorf("Expected log file %s, got %s", expected, file.Name())
+	}
+	if LogFile() != expected {
+		t.Errorf("Expected LogFile() to be %s, got %s", expected, LogFile())
+	}
+}
+
+// TestTailLog tests that only the last lines of the log are written
+func TestTailLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LogFileName)
+	content := "one\ntwo\nthree\nfour\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		lines    int
+		expected string
+	}{
+		{name: "last two lines", lines: 2, expected: "three\nfour\n"},
+		{name: "more lines than file", lines: 10, expected: content},
+		{name: "whole file", lines: 0, expected: content},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			offset, err := TailLog(&buf, path, tt.lines)
+			if err != nil {
+				t.Fatalf("TailLog failed: %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, buf.String())
+			}
+			if offset != int64(len(content)) {
+				t.Errorf("Expected offset %d, got %d", len(content), offset)
+			}
+		})
+	}
+
+	if _, err := TailLog(&bytes.Buffer{}, filepath.Join(t.TempDir(), "missing.log"), 10); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected not-exist error for missing log, got %v", err)
+	}
+}
+
+// TestFollowLog tests that appended and rotated output is picked up
+func TestFollowLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LogFileName)
+	if err := os.WriteFile(path, []byte("old\n"), 0644); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	buf := &syncBuffer{}
+	done := make(chan error, 1)
+	go func() {
+		done <- FollowLog(ctx, buf, path, 4)
+	}()
+
+	appendLog := func(s string) {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			t.Fatalf("Failed to open log: %v", err)
+		}
+		f.WriteString(s)
+		f.Close()
+	}
+	waitFor := func(want string) {
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if strings.Contains(buf.String(), want) {
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Fatalf("Timed out waiting for %q, got %q", want, buf.String())
+	}
+
+	appendLog("new line\n")
+	waitFor("new line\n")
+
+	// Simulate rotation by replacing the file with a shorter one
+	if err := os.WriteFile(path, []byte("fresh\n"), 0644); err != nil {
+		t.Fatalf("Failed to rewrite log: %v", err)
+	}
+	waitFor("fresh\n")
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("FollowLog returned error: %v", err)
+	}
+	if strings.Contains(buf.String(), "old") {
+		t.Errorf("Expected output before offset to be skipped, got %q", buf.String())
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent reads and writes
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
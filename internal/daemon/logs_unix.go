@@ -0,0 +1,22 @@
+//go:build !windows
+// +build !windows
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// redirectStdout makes file descriptors 1 and 2 refer to file. Output the
+// runtime writes straight to them, such as a panic, follows too.
+func redirectStdout(file *os.File) error {
+	for _, fd := range []int{int(os.Stdout.Fd()), int(os.Stderr.Fd())} {
+		if err := unix.Dup2(int(file.Fd()), fd); err != nil {
+			return fmt.Errorf("redirecting output to the log file: %w", err)
+		}
+	}
+	return nil
+}
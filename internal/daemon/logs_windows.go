@@ -0,0 +1,14 @@
+//go:build windows
+// +build windows
+
+package daemon
+
+import "os"
+
+// redirectStdout leaves stdout and stderr alone on Windows. A file can't
+// be renamed there while the handles the daemon inherited hold it open, so
+// the log is never rotated away from them and their output already lands
+// in the current log.
+func redirectStdout(*os.File) error {
+	return nil
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+// +build !windows
+
+package daemon
+
+import (
+	"os"
+	"syscall"
+)
+
+// IsProcessRunning checks if a process with the given PID is running
+func IsProcessRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// On Unix, FindProcess always succeeds, we need to send signal 0 to check
+	err = process.Signal(syscall.Signal(0))
+	return err == nil
+}
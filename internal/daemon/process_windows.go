@@ -0,0 +1,30 @@
+//go:build windows
+// +build windows
+
+package daemon
+
+import "syscall"
+
+// stillActive is the exit code GetExitCodeProcess reports for a process
+// that has not exited (STILL_ACTIVE is not exported by syscall).
+const stillActive = 259
+
+// IsProcessRunning checks if a process with the given PID is running.
+// Windows processes do not accept signal 0, so this opens the process and
+// checks whether it has an exit code yet.
+func IsProcessRunning(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	handle, err := syscall.OpenProcess(syscall.PROCESS_QUERY_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer syscall.CloseHandle(handle)
+
+	var exitCode uint32
+	if err := syscall.GetExitCodeProcess(handle, &exitCode); err != nil {
+		return false
+	}
+	return exitCode == stillActive
+}
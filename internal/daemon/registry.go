@@ -0,0 +1,197 @@
+package daemon
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// InstancesDirName is the name of the instance registry directory
+	InstancesDirName = "instances"
+	// DefaultInstanceName is the instance name of a daemon without a project
+	DefaultInstanceName = "default"
+)
+
+// Instance describes a running daemon in the instance registry. Each daemon
+// registers itself on start so several daemons on one machine can be listed
+// and addressed by name.
+type Instance struct {
+	Name        string    `json:"name"`
+	PID         int       `json:"pid"`
+	SocketPath  string    `json:"socket_path,omitempty"`
+	TCPPort     string    `json:"tcp_port,omitempty"`
+	ProjectPath string    `json:"project_path,omitempty"`
+	DaemonDir   string    `json:"daemon_dir,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+}
+
+// RegistryDir returns the path to the per-user instance registry
+func RegistryDir() string {
+	if dir := os.Getenv("GCQ_REGISTRY_DIR"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "gcq-"+InstancesDirName)
+	}
+	return filepath.Join(home, DefaultDir, InstancesDirName)
+}
+
+// InstanceName returns the registry name for a daemon serving projectPath:
+// the project directory name followed by a short hash of its absolute path,
+// so projects with the same directory name do not collide.
+func InstanceName(projectPath string) string {
+	if projectPath == "" {
+		return DefaultInstanceName
+	}
+	absPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		absPath = projectPath
+	}
+	hash := md5.Sum([]byte(absPath))
+	return fmt.Sprintf("%s-%x", filepath.Base(absPath), hash[:3])
+}
+
+// instanceFile returns the registry file for the named instance
+func instanceFile(name string) string {
+	return filepath.Join(RegistryDir(), name+".json")
+}
+
+// RegisterInstance writes inst to the registry, replacing any entry with
+// the same name
+func RegisterInstance(inst *Instance) error {
+	if inst.Name == "" {
+		return fmt.Errorf("instance name is required")
+	}
+	if err := os.MkdirAll(RegistryDir(), 0755); err != nil {
+		return fmt.Errorf("creating registry directory: %w", err)
+	}
+	data, err := json.MarshalIndent(inst, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling instance: %w", err)
+	}
+	if err := os.WriteFile(instanceFile(inst.Name), data, 0644); err != nil {
+		return fmt.Errorf("writing instance file: %w", err)
+	}
+	return nil
+}
+
+// UnregisterInstance removes the named instance from the registry
+func UnregisterInstance(name string) error {
+	if err := os.Remove(instanceFile(name)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing instance file: %w", err)
+	}
+	return nil
+}
+
+// ListInstances returns the registered instances sorted by name. Entries
+// whose process is no longer running are removed from the registry.
+func ListInstances() ([]*Instance, error) {
+	entries, err := os.ReadDir(RegistryDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading registry directory: %w", err)
+	}
+
+	var instances []*Instance
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(RegistryDir(), entry.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var inst Instance
+		if err := json.Unmarshal(data, &inst); err != nil {
+			continue
+		}
+		if !IsProcessRunning(inst.PID) {
+			os.Remove(path)
+			continue
+		}
+		instances = append(instances, &inst)
+	}
+
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].Name < instances[j].Name
+	})
+	return instances, nil
+}
+
+// FindInstance looks up a running instance by name, unique name prefix, or
+// project path
+func FindInstance(ref string) (*Instance, error) {
+	instances, err := ListInstances()
+	if err != nil {
+		return nil, err
+	}
+
+	absRef, _ := filepath.Abs(ref)
+	var matches []*Instance
+	for _, inst := range instances {
+		if inst.Name == ref || (inst.ProjectPath != "" && inst.ProjectPath == absRef) {
+			return inst, nil
+		}
+		if strings.HasPrefix(inst.Name, ref) {
+			matches = append(matches, inst)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no running daemon instance %q (see gcq status --all)", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		names := make([]string, len(matches))
+		for i, inst := range matches {
+			names[i] = inst.Name
+		}
+		return nil, fmt.Errorf("instance %q is ambiguous: %s", ref, strings.Join(names, ", "))
+	}
+}
+
+// UseInstance points the current process at inst, so the client and the
+// lifecycle commands talk to that daemon
+func UseInstance(inst *Instance) {
+	if inst.SocketPath != "" {
+		os.Setenv("GCQ_SOCKET_PATH", inst.SocketPath)
+	}
+	if inst.TCPPort != "" {
+		os.Setenv("GCQ_TCP_PORT", inst.TCPPort)
+	}
+	if inst.DaemonDir != "" {
+		os.Setenv("GCQ_DAEMON_DIR", inst.DaemonDir)
+	}
+}
+
+// InstanceStatus pings inst and reports whether it is responding
+func InstanceStatus(inst *Instance) *DaemonStatus {
+	tcpPort := inst.TCPPort
+	if tcpPort == "" {
+		tcpPort = DefaultTCPPort
+	}
+	status, err := pingAddress(inst.SocketPath, tcpPort)
+	if err != nil {
+		return &DaemonStatus{
+			Running:   true,
+			PID:       inst.PID,
+			Ready:     false,
+			StartedAt: inst.StartedAt,
+			Error:     fmt.Sprintf("daemon not responding: %v", err),
+		}
+	}
+	status.PID = inst.PID
+	status.StartedAt = inst.StartedAt
+	return status
+}
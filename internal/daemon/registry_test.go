@@ -0,0 +1,142 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestInstanceName tests that instance names are stable and project specific
+func TestInstanceName(t *testing.T) {
+	if got := InstanceName(""); got != DefaultInstanceName {
+		t.Errorf("Expected %q for empty project, got %q", DefaultInstanceName, got)
+	}
+
+	tmpDir := t.TempDir()
+	a := filepath.Join(tmpDir, "a", "app")
+	b := filepath.Join(tmpDir, "b", "app")
+
+	nameA := InstanceName(a)
+	if nameA != InstanceName(a) {
+		t.Error("Expected instance name to be stable")
+	}
+	if !strings.HasPrefix(nameA, "app-") {
+		t.Errorf("Expected name to start with project directory, got %q", nameA)
+	}
+	if nameA == InstanceName(b) {
+		t.Errorf("Expected different names for different projects, both got %q", nameA)
+	}
+}
+
+// TestInstanceRegistry tests registering, listing, finding and removing instances
+func TestInstanceRegistry(t *testing.T) {
+	t.Setenv("GCQ_REGISTRY_DIR", t.TempDir())
+
+	instances, err := ListInstances()
+	if err != nil {
+		t.Fatalf("ListInstances on empty registry failed: %v", err)
+	}
+	if len(instances) != 0 {
+		t.Fatalf("Expected no instances, got %d", len(instances))
+	}
+
+	projectA := filepath.Join(t.TempDir(), "alpha")
+	projectB := filepath.Join(t.TempDir(), "beta")
+	for _, inst := range []*Instance{
+		{Name: "alpha-111111", PID: os.Getpid(), SocketPath: "/tmp/a.sock", ProjectPath: projectA, StartedAt: time.Now()},
+		{Name: "beta-222222", PID: os.Getpid(), SocketPath: "/tmp/b.sock", ProjectPath: projectB, StartedAt: time.Now()},
+		{Name: "stale-333333", PID: 999999, SocketPath: "/tmp/c.sock"},
+	} {
+		if err := RegisterInstance(inst); err != nil {
+			t.Fatalf("RegisterInstance failed: %v", err)
+		}
+	}
+
+	instances, err = ListInstances()
+	if err != nil {
+		t.Fatalf("ListInstances failed: %v", err)
+	}
+	if len(instances) != 2 {
+		t.Fatalf("Expected 2 running instances, got %d", len(instances))
+	}
+	if instances[0].Name != "alpha-111111" || instances[1].Name != "beta-222222" {
+		t.Errorf("Expected instances sorted by name, got %s, %s", instances[0].Name, instances[1].Name)
+	}
+	if _, err := os.Stat(instanceFile("stale-333333")); !os.IsNotExist(err) {
+		t.Error("Expected stale instance to be removed from the registry")
+	}
+
+	tests := []struct {
+		name     string
+		ref      string
+		expected string
+		wantErr  bool
+	}{
+		{name: "exact name", ref: "beta-222222", expected: "beta-222222"},
+		{name: "name prefix", ref: "alp", expected: "alpha-111111"},
+		{name: "project path", ref: projectB, expected: "beta-222222"},
+		{name: "unknown", ref: "gamma", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inst, err := FindInstance(tt.ref)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %q, got %s", tt.ref, inst.Name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindInstance failed: %v", err)
+			}
+			if inst.Name != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, inst.Name)
+			}
+		})
+	}
+
+	if err := UnregisterInstance("alpha-111111"); err != nil {
+		t.Fatalf("UnregisterInstance failed: %v", err)
+	}
+	if err := UnregisterInstance("alpha-111111"); err != nil {
+		t.Errorf("UnregisterInstance on missing instance failed: %v", err)
+	}
+	instances, _ = ListInstances()
+	if len(instances) != 1 || instances[0].Name != "beta-222222" {
+		t.Errorf("Expected only beta to remain, got %v", instances)
+	}
+}
+
+// TestFindInstanceAmbiguous tests that a prefix matching several instances is rejected
+func TestFindInstanceAmbiguous(t *testing.T) {
+	t.Setenv("GCQ_REGISTRY_DIR", t.TempDir())
+
+	for _, name := range []string{"app-aaaaaa", "app-bbbbbb"} {
+		if err := RegisterInstance(&Instance{Name: name, PID: os.Getpid()}); err != nil {
+			t.Fatalf("RegisterInstance failed: %v", err)
+		}
+	}
+
+	_, err := FindInstance("app")
+	if err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("Expected ambiguous error, got %v", err)
+	}
+}
+
+// TestUseInstance tests that selecting an instance updates the environment
+func TestUseInstance(t *testing.T) {
+	t.Setenv("GCQ_SOCKET_PATH", "")
+	t.Setenv("GCQ_DAEMON_DIR", "")
+
+	UseInstance(&Instance{SocketPath: "/tmp/gcq-test.sock", DaemonDir: "/tmp/project/.gcq"})
+
+	if GetSocketPath() != "/tmp/gcq-test.sock" {
+		t.Errorf("Expected socket path to be selected, got %s", GetSocketPath())
+	}
+	if DaemonDir() != "/tmp/project/.gcq" {
+		t.Errorf("Expected daemon dir to be selected, got %s", DaemonDir())
+	}
+}
@@ -19,6 +19,7 @@ type StartResult struct {
 	Error     string    `json:"error,omitempty"`
 	StartedAt time.Time `json:"started_at"`
 	Ready     bool      `json:"ready"`
+	LogPath   string    `json:"log_path,omitempty"`
 }
 
 type StopResult struct {
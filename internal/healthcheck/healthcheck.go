@@ -10,17 +10,27 @@ import (
 	"time"
 
 	"github.com/l3aro/go-context-query/internal/config"
+	"github.com/l3aro/go-context-query/pkg/embed"
 )
 
 // ModelStatus represents the health status of a single model configuration.
 type ModelStatus struct {
-	Provider string // "huggingface" or "ollama"
+	Provider string // "huggingface", "ollama", "local" or "mock"
 	Model    string
 	URL      string // ollama endpoint
-	Status   string // "ready", "downloading", "error", "inherited"
+	Status   string // "ready", "downloading", "fallback", "error", "inherited"
 	Error    string
 }
 
+// AccelerationStatus reports the local embedding runtime and the hardware
+// acceleration in use.
+type AccelerationStatus struct {
+	*embed.Acceleration
+	// Processors maps each model loaded in Ollama to where it runs, e.g.
+	// "100% GPU". Empty when no model is loaded.
+	Processors map[string]string
+}
+
 // HealthCheckResult contains the full health check output for display.
 type HealthCheckResult struct {
 	SavedPath      string
@@ -29,6 +39,7 @@ type HealthCheckResult struct {
 	EffectiveScope string // "global" or "project"
 	WarmModel      ModelStatus
 	SearchModel    ModelStatus
+	Acceleration   AccelerationStatus
 }
 
 // Check performs a health check against the given config.
@@ -48,10 +59,23 @@ func Check(cfg *config.Config, savedPath string, effectivePath string) (*HealthC
 
 	result.WarmModel = checkWarmModel(cfg)
 	result.SearchModel = checkSearchModel(cfg, result.WarmModel)
+	result.Acceleration = checkAcceleration()
 
 	return result, nil
 }
 
+// checkAcceleration detects the local embedding runtime and, when it is
+// Ollama, asks it where loaded models run.
+func checkAcceleration() AccelerationStatus {
+	status := AccelerationStatus{Acceleration: embed.DetectAcceleration()}
+	if status.Runtime == "ollama" {
+		if processors, err := embed.OllamaProcessors(context.Background(), status.RuntimePath); err == nil {
+			status.Processors = processors
+		}
+	}
+	return status
+}
+
 // scopeFromPath determines the scope from a config file path.
 // Returns empty string if path is empty, otherwise returns "project".
 // Global config is no longer supported.
@@ -69,6 +93,10 @@ func checkWarmModel(cfg *config.Config) ModelStatus {
 	switch provider {
 	case config.ProviderOllama:
 		return checkOllamaModel(cfg.Warm.Model, cfg.Warm.BaseURL, cfg.Warm.Token)
+	case config.ProviderLocal:
+		return checkLocalModel(cfg.Warm.Model, cfg.Warm.BaseURL, cfg.HFToken)
+	case config.ProviderMock:
+		return checkMockModel(cfg)
 	case config.ProviderHuggingFace:
 		return checkHuggingFaceModel(cfg.Warm.Model)
 	default:
@@ -107,6 +135,10 @@ func checkSearchModel(cfg *config.Config, warmStatus ModelStatus) ModelStatus {
 	switch provider {
 	case config.ProviderOllama:
 		return checkOllamaModel(cfg.Search.Model, cfg.Search.BaseURL, cfg.Search.Token)
+	case config.ProviderLocal:
+		return checkLocalModel(cfg.Search.Model, cfg.Search.BaseURL, cfg.HFToken)
+	case config.ProviderMock:
+		return checkMockModel(cfg)
 	case config.ProviderHuggingFace:
 		return checkHuggingFaceModel(cfg.Search.Model)
 	default:
@@ -135,11 +167,13 @@ func isSameAsWarm(cfg *config.Config) bool {
 		return false
 	}
 	switch cfg.EffectiveWarmProvider() {
-	case config.ProviderOllama:
+	case config.ProviderOllama, config.ProviderLocal:
 		return cfg.Warm.Model == cfg.Search.Model &&
 			cfg.Warm.BaseURL == cfg.Search.BaseURL
 	case config.ProviderHuggingFace:
 		return cfg.Warm.Model == cfg.Search.Model
+	case config.ProviderMock:
+		return true
 	}
 	return false
 }
@@ -193,6 +227,44 @@ func checkOllamaModel(model, baseURL, apiKey string) ModelStatus {
 	return status
 }
 
+// checkLocalModel checks the local Ollama runtime behind the local provider.
+// When it is not reachable, the provider falls back to HuggingFace if a token
+// is configured, which is reported as "fallback" rather than an error.
+func checkLocalModel(model, baseURL, hfToken string) ModelStatus {
+	if model == "" {
+		model = embed.DefaultOllamaModel
+	}
+	if baseURL == "" {
+		baseURL = embed.DefaultOllamaEndpoint
+	}
+
+	status := checkOllamaModel(model, baseURL, "")
+	status.Provider = string(config.ProviderLocal)
+	if status.Status != "error" {
+		return status
+	}
+
+	if hfToken != "" {
+		status.Status = "fallback"
+		status.Error = fmt.Sprintf("%s; embedding with HuggingFace instead", status.Error)
+	}
+	return status
+}
+
+// checkMockModel reports the mock provider, which needs no model and is
+// always ready.
+func checkMockModel(cfg *config.Config) ModelStatus {
+	dimension := cfg.MockDimension
+	if dimension == 0 {
+		dimension = embed.DefaultMockDimension
+	}
+	return ModelStatus{
+		Provider: string(config.ProviderMock),
+		Model:    fmt.Sprintf("%s (%d dimensions)", embed.DefaultMockModel, dimension),
+		Status:   "ready",
+	}
+}
+
 // checkHuggingFaceModel checks if a HuggingFace model is cached locally.
 // It looks for model files in the HuggingFace cache directory.
 // This avoids any network calls or API key requirements.
@@ -147,3 +147,21 @@ func TestIsSearchExplicitlyConfigured(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckLocalModelFallback(t *testing.T) {
+	// Nothing listens on port 1, so the local runtime is unreachable
+	unreachable := "http://127.0.0.1:1"
+
+	status := checkLocalModel("", unreachable, "hf_token")
+	if status.Status != "fallback" {
+		t.Errorf("Status = %q, want %q", status.Status, "fallback")
+	}
+	if status.Provider != "local" || status.Model == "" {
+		t.Errorf("Unexpected status %+v", status)
+	}
+
+	status = checkLocalModel("", unreachable, "")
+	if status.Status != "error" {
+		t.Errorf("Status without fallback = %q, want %q", status.Status, "error")
+	}
+}
@@ -0,0 +1,155 @@
+// Package onboard sets a project up for gcq: it detects the languages the
+// project uses and derives the ignore patterns and index settings gcq init
+// writes for it.
+package onboard
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/l3aro/go-context-query/internal/config"
+	"github.com/l3aro/go-context-query/internal/scanner"
+	"github.com/l3aro/go-context-query/pkg/extractor"
+)
+
+// IgnoreHeader starts the block of patterns WriteIgnoreFile appends
+const IgnoreHeader = "# Generated and vendored files (added by gcq init)"
+
+// ignoreGlobs lists generated, vendored and build output paths per
+// language. Directories the scanner always skips (node_modules, vendor,
+// target, build, dist and hidden directories) are left out.
+var ignoreGlobs = map[string][]string{
+	"python":     {"*.egg-info/", "htmlcov/", "*_pb2.py", "*_pb2_grpc.py"},
+	"javascript": {"coverage/", "out/", "*.min.js", "*.bundle.js"},
+	"typescript": {"coverage/", "out/", "*.d.ts"},
+	"go":         {"*.pb.go", "*_string.go", "zz_generated*.go"},
+	"java":       {"out/", "generated/"},
+	"kotlin":     {"out/", "generated/"},
+	"scala":      {"out/", "generated/"},
+	"php":        {"storage/", "bootstrap/cache/"},
+	"ruby":       {"tmp/", "log/", "coverage/"},
+	"csharp":     {"packages/", "*.Designer.cs"},
+	"c":          {"cmake-build-*/", "third_party/"},
+	"cpp":        {"cmake-build-*/", "third_party/", "*.pb.cc", "*.pb.h"},
+	"swift":      {"Pods/", "DerivedData/"},
+}
+
+// Language is a language found in a project
+type Language struct {
+	Name  string `json:"name"`
+	Files int    `json:"files"`
+	// Supported reports whether gcq extracts units from the language
+	Supported bool `json:"supported"`
+}
+
+// Detect counts the files of each language under root that the scanner
+// would index, most files first
+func Detect(root string) ([]Language, error) {
+	files, err := scanner.New(scanner.DefaultOptions()).Scan(root)
+	if err != nil {
+		return nil, fmt.Errorf("scanning %s: %w", root, err)
+	}
+
+	registry := extractor.GetLanguageRegistry()
+	byName := make(map[string]*Language)
+	for _, f := range files {
+		if f.Language == "" {
+			continue
+		}
+		lang := byName[f.Language]
+		if lang == nil {
+			_, err := registry.GetExtractor(f.FullPath)
+			lang = &Language{Name: f.Language, Supported: err == nil}
+			byName[f.Language] = lang
+		}
+		lang.Files++
+	}
+
+	langs := make([]Language, 0, len(byName))
+	for _, lang := range byName {
+		langs = append(langs, *lang)
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		if langs[i].Files != langs[j].Files {
+			return langs[i].Files > langs[j].Files
+		}
+		return langs[i].Name < langs[j].Name
+	})
+	return langs, nil
+}
+
+// IgnoreGlobs returns the recommended ignore patterns for langs, without
+// duplicates
+func IgnoreGlobs(langs []Language) []string {
+	var globs []string
+	seen := make(map[string]bool)
+	for _, lang := range langs {
+		for _, glob := range ignoreGlobs[lang.Name] {
+			if !seen[glob] {
+				seen[glob] = true
+				globs = append(globs, glob)
+			}
+		}
+	}
+	return globs
+}
+
+// Recommend applies the index settings recommended for a project using
+// langs to cfg: body chunking, so searches reach code deep inside long
+// functions, with the configured chunk size and overlap
+func Recommend(cfg *config.Config, langs []Language) {
+	for _, lang := range langs {
+		if lang.Supported {
+			cfg.Embedding.ChunkBodies = true
+			return
+		}
+	}
+}
+
+// WriteIgnoreFile appends the globs missing from root's .gcqignore to it,
+// creating the file if needed, and returns the globs it added
+func WriteIgnoreFile(root string, globs []string) ([]string, error) {
+	path := filepath.Join(root, scanner.DefaultOptions().IgnoreFileName)
+	existing := make(map[string]bool)
+	if f, err := os.Open(path); err == nil {
+		lines := bufio.NewScanner(f)
+		for lines.Scan() {
+			existing[strings.TrimSpace(lines.Text())] = true
+		}
+		f.Close()
+		if err := lines.Err(); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var added []string
+	for _, glob := range globs {
+		if !existing[glob] {
+			added = append(added, glob)
+		}
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	block := IgnoreHeader + "\n" + strings.Join(added, "\n") + "\n"
+	if len(existing) > 0 {
+		block = "\n" + block
+	}
+	if _, err := f.WriteString(block); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", path, err)
+	}
+	return added, nil
+}
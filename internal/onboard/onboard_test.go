@@ -0,0 +1,111 @@
+package onboard
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/l3aro/go-context-query/internal/config"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"app/main.py":          "def main():\n    pass\n",
+		"app/util.py":          "def util():\n    pass\n",
+		"cmd/tool.go":          "package main\n\nfunc main() {}\n",
+		"README.md":            "# readme\n",
+		"node_modules/x/a.js":  "function a() {}\n",
+		"app/__pycache__/m.py": "x = 1\n",
+	})
+
+	langs, err := Detect(dir)
+	if err != nil {
+		t.Fatalf("Detect failed: %v", err)
+	}
+	if len(langs) < 2 || langs[0].Name != "python" || langs[0].Files != 2 || !langs[0].Supported {
+		t.Fatalf("langs = %+v", langs)
+	}
+	for _, lang := range langs {
+		switch lang.Name {
+		case "javascript":
+			t.Errorf("files under node_modules were counted: %+v", lang)
+		case "markdown":
+			if lang.Supported {
+				t.Errorf("markdown reported as supported")
+			}
+		}
+	}
+}
+
+func TestIgnoreGlobs(t *testing.T) {
+	globs := IgnoreGlobs([]Language{{Name: "javascript"}, {Name: "typescript"}, {Name: "markdown"}})
+	if !slices.Contains(globs, "*.min.js") || !slices.Contains(globs, "*.d.ts") {
+		t.Errorf("globs = %v", globs)
+	}
+	count := 0
+	for _, g := range globs {
+		if g == "coverage/" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("coverage/ listed %d times in %v", count, globs)
+	}
+	if got := IgnoreGlobs([]Language{{Name: "markdown"}}); len(got) != 0 {
+		t.Errorf("markdown globs = %v", got)
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	cfg := config.DefaultConfig()
+	Recommend(cfg, []Language{{Name: "markdown", Files: 3}})
+	if cfg.Embedding.ChunkBodies {
+		t.Error("chunking enabled for a project without code")
+	}
+	Recommend(cfg, []Language{{Name: "go", Files: 3, Supported: true}})
+	if !cfg.Embedding.ChunkBodies {
+		t.Error("chunking not enabled")
+	}
+}
+
+func TestWriteIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{".gcqignore": "*.pb.go\n"})
+
+	added, err := WriteIgnoreFile(dir, []string{"*.pb.go", "*_string.go"})
+	if err != nil {
+		t.Fatalf("WriteIgnoreFile failed: %v", err)
+	}
+	if !slices.Equal(added, []string{"*_string.go"}) {
+		t.Errorf("added = %v", added)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, ".gcqignore"))
+	if want := "*.pb.go\n\n" + IgnoreHeader + "\n*_string.go\n"; string(data) != want {
+		t.Errorf(".gcqignore = %q, want %q", data, want)
+	}
+
+	// Running again adds nothing
+	if added, err := WriteIgnoreFile(dir, []string{"*.pb.go", "*_string.go"}); err != nil || added != nil {
+		t.Errorf("second run added %v, %v", added, err)
+	}
+	data, _ = os.ReadFile(filepath.Join(dir, ".gcqignore"))
+	if strings.Count(string(data), IgnoreHeader) != 1 {
+		t.Errorf(".gcqignore = %q", data)
+	}
+}
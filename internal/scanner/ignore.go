@@ -1,6 +1,8 @@
 package scanner
 
 import (
+	"fmt"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -21,8 +23,13 @@ type IgnorePattern struct {
 	pattern     string // Original pattern
 	isNegation  bool   // True if pattern starts with !
 	isDirectory bool   // True if pattern ends with /
-	isAbsolute  bool   // True if pattern starts with /
+	isAbsolute  bool   // True if pattern has a / before its end, anchoring it to base
 	segments    []string
+	// base is the directory of the ignore file the pattern comes from,
+	// relative to the scan root with forward slashes; "" for the root
+	base string
+	// source is the ignore file; "" for a pattern from Options.Ignore
+	source string
 }
 
 // ParseIgnorePattern parses a gitignore-style pattern string.
@@ -41,10 +48,11 @@ func ParseIgnorePattern(pattern string) IgnorePattern {
 		pattern = strings.TrimSuffix(pattern, "/")
 	}
 
-	// Check if absolute path pattern (starts with /)
-	if strings.HasPrefix(pattern, "/") {
+	// A pattern with a slash at its start or in its middle is relative to
+	// its ignore file's directory; one without matches at any level
+	if strings.Contains(pattern, "/") {
 		p.isAbsolute = true
-		pattern = pattern[1:]
+		pattern = strings.TrimPrefix(pattern, "/")
 	}
 
 	// Split pattern into segments
@@ -53,11 +61,33 @@ func ParseIgnorePattern(pattern string) IgnorePattern {
 	return p
 }
 
-// Match checks if the given path matches this ignore pattern.
+// parseIgnorePatterns parses the lines of an ignore file in directory base,
+// relative to the scan root, skipping blank lines and comments
+func parseIgnorePatterns(lines []string, base string) []IgnorePattern {
+	var patterns []IgnorePattern
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p := ParseIgnorePattern(line)
+		p.base = base
+		patterns = append(patterns, p)
+	}
+	return patterns
+}
+
+// Match checks if the given path, relative to the scan root, matches this
+// ignore pattern. As in git, a pattern matching a directory matches
+// everything inside it, and a directory pattern only matches directories.
 // Returns true if the path should be ignored (matches and is not negation),
 // or if it matches a negation pattern (to be handled by caller).
 func (p IgnorePattern) Match(path string) bool {
-	cacheKey := p.pattern + "::" + path
+	return p.match(path, false)
+}
+
+// match is Match for a path that is a directory when isDir is set
+func (p IgnorePattern) match(path string, isDir bool) bool {
+	cacheKey := fmt.Sprintf("%s::%s::%s::%t", p.base, p.pattern, path, isDir)
 
 	// Check cache first
 	matchCache.RLock()
@@ -67,26 +97,23 @@ func (p IgnorePattern) Match(path string) bool {
 	}
 	matchCache.RUnlock()
 
+	result := false
 	path = filepath.ToSlash(path)
-
-	// For directory patterns, check if the path is within the directory
-	var result bool
-	if p.isDirectory {
-		result = p.matchDirectory(path)
-	} else if strings.Contains(p.pattern, "*") || strings.Contains(p.pattern, "?") || strings.Contains(p.pattern, "[") {
-		// Handle glob patterns
-		result = p.matchGlob(path)
+	if p.base != "" {
+		path, result = strings.CutPrefix(path, p.base+"/")
 	} else {
-		// Split path into segments
-		pathSegments := strings.Split(path, "/")
-
-		// Try matching at each possible starting position
+		result = true
+	}
+	if result {
 		result = false
-		for startIdx := 0; startIdx < len(pathSegments); startIdx++ {
-			if p.matchSegments(pathSegments[startIdx:]) {
-				result = true
-				break
-			}
+		pathSegments := strings.Split(path, "/")
+		// The path itself, then each directory above it
+		last := len(pathSegments)
+		if p.isDirectory && !isDir {
+			last--
+		}
+		for n := last; n >= 1 && !result; n-- {
+			result = p.matchSegments(pathSegments[:n])
 		}
 	}
 
@@ -116,65 +143,18 @@ func (p IgnorePattern) IsNegation() bool {
 	return p.isNegation
 }
 
-// matchDirectory checks if the path is within a directory matching the pattern.
-func (p IgnorePattern) matchDirectory(path string) bool {
-	// For absolute directory patterns, check from root
-	if p.isAbsolute {
-		pathSegments := strings.Split(path, "/")
-		if len(pathSegments) < len(p.segments) {
-			return false
-		}
-		for i, seg := range p.segments {
-			if !strings.EqualFold(seg, pathSegments[i]) {
-				return false
-			}
-		}
-		return true
-	}
-
-	// For relative directory patterns, check at any level
-	pathSegments := strings.Split(path, "/")
-	for startIdx := 0; startIdx <= len(pathSegments)-len(p.segments); startIdx++ {
-		match := true
-		for i, seg := range p.segments {
-			if !strings.EqualFold(seg, pathSegments[startIdx+i]) {
-				match = false
-				break
-			}
-		}
-		if match {
-			return true
-		}
-	}
-
-	return false
-}
-
-// matchGlob handles wildcard patterns (*, ?, and [...]).
-func (p IgnorePattern) matchGlob(path string) bool {
-	pattern := p.pattern
-	if p.isNegation {
-		pattern = pattern[1:]
-	}
-	if strings.HasPrefix(pattern, "/") {
-		pattern = pattern[1:]
-	}
-
-	segments := strings.Split(pattern, "/")
-	pathSegments := strings.Split(path, "/")
-
-	// Try matching at each possible starting position
-	for startIdx := 0; startIdx < len(pathSegments); startIdx++ {
-		if p.matchGlobSegments(segments, pathSegments[startIdx:]) {
-			return true
-		}
+// matchSegments reports whether the pattern matches exactly the path with
+// pathSegments. An anchored pattern is matched from the start of the path,
+// any other against its last segment.
+func (p IgnorePattern) matchSegments(pathSegments []string) bool {
+	if !p.isAbsolute {
+		return matchGlobSegment(p.segments[0], pathSegments[len(pathSegments)-1])
 	}
-
-	return false
+	return matchGlobSegments(p.segments, pathSegments)
 }
 
 // matchGlobSegments matches glob patterns against path segments.
-func (p IgnorePattern) matchGlobSegments(patternSegs, pathSegs []string) bool {
+func matchGlobSegments(patternSegs, pathSegs []string) bool {
 	if len(patternSegs) == 0 {
 		return len(pathSegs) == 0
 	}
@@ -182,11 +162,11 @@ func (p IgnorePattern) matchGlobSegments(patternSegs, pathSegs []string) bool {
 	// Handle ** (match any number of directories)
 	if patternSegs[0] == "**" {
 		if len(patternSegs) == 1 {
-			return true // ** matches everything
+			return len(pathSegs) > 0 // a trailing ** matches everything inside
 		}
 		// Try matching the rest of the pattern at each position
 		for i := 0; i <= len(pathSegs); i++ {
-			if p.matchGlobSegments(patternSegs[1:], pathSegs[i:]) {
+			if matchGlobSegments(patternSegs[1:], pathSegs[i:]) {
 				return true
 			}
 		}
@@ -201,106 +181,17 @@ func (p IgnorePattern) matchGlobSegments(patternSegs, pathSegs []string) bool {
 		return false
 	}
 
-	return p.matchGlobSegments(patternSegs[1:], pathSegs[1:])
-}
-
-// matchSegments matches simple (non-glob) pattern segments against path segments.
-func (p IgnorePattern) matchSegments(pathSegments []string) bool {
-	if len(p.segments) > len(pathSegments) {
-		return false
-	}
-
-	for i, seg := range p.segments {
-		if seg == "**" {
-			// Handle ** pattern
-			if i == len(p.segments)-1 {
-				return true // ** at end matches everything
-			}
-			// Try matching the rest at each position
-			for j := i; j <= len(pathSegments); j++ {
-				if p.matchSegmentsAt(pathSegments[j:], p.segments[i+1:]) {
-					return true
-				}
-			}
-			return false
-		}
-
-		if !strings.EqualFold(seg, pathSegments[i]) {
-			return false
-		}
-	}
-
-	return len(p.segments) == len(pathSegments)
+	return matchGlobSegments(patternSegs[1:], pathSegs[1:])
 }
 
-// matchSegmentsAt matches remaining pattern segments at a specific position.
-func (p IgnorePattern) matchSegmentsAt(pathSegments, patternSegments []string) bool {
-	if len(patternSegments) > len(pathSegments) {
-		return false
-	}
-
-	for i, seg := range patternSegments {
-		if !strings.EqualFold(seg, pathSegments[i]) {
-			return false
-		}
-	}
-
-	return true
-}
-
-// matchGlobSegment matches a single glob pattern segment against a path segment.
+// matchGlobSegment matches a single pattern segment, with *, ? and [...]
+// wildcards, against a path segment. Segments without wildcards compare
+// case-insensitively.
 func matchGlobSegment(pattern, segment string) bool {
-	// Simple glob matching for *, ?, and [...]
-	patternIdx, segmentIdx := 0, 0
-
-	for patternIdx < len(pattern) && segmentIdx < len(segment) {
-		p := pattern[patternIdx]
-		s := segment[segmentIdx]
-
-		switch p {
-		case '*':
-			// Handle ** separately
-			if patternIdx+1 < len(pattern) && pattern[patternIdx+1] == '*' {
-				// This is ** - should be handled at segment level
-				return matchGlobSegment(pattern[patternIdx+1:], segment[segmentIdx:])
-			}
-			// * matches any sequence of characters
-			if patternIdx+1 == len(pattern) {
-				return true
-			}
-			nextChar := pattern[patternIdx+1]
-			for segmentIdx < len(segment) && segment[segmentIdx] != nextChar {
-				segmentIdx++
-			}
-			patternIdx++
-		case '?':
-			patternIdx++
-			segmentIdx++
-		case '[':
-			// Character class [...]
-			endIdx := strings.IndexByte(pattern[patternIdx:], ']')
-			if endIdx == -1 {
-				return false
-			}
-			charClass := pattern[patternIdx+1 : patternIdx+endIdx]
-			if !strings.ContainsRune(charClass, rune(s)) {
-				return false
-			}
-			patternIdx += endIdx + 1
-			segmentIdx++
-		default:
-			if p != s {
-				return false
-			}
-			patternIdx++
-			segmentIdx++
-		}
-	}
-
-	// Handle trailing *
-	for patternIdx < len(pattern) && pattern[patternIdx] == '*' {
-		patternIdx++
+	if !strings.ContainsAny(pattern, "*?[") {
+		return strings.EqualFold(pattern, segment)
 	}
-
-	return patternIdx == len(pattern) && segmentIdx == len(segment)
+	// Git negates a character class with [!...], path.Match with [^...]
+	matched, err := path.Match(strings.ReplaceAll(pattern, "[!", "[^"), segment)
+	return err == nil && matched
 }
@@ -224,3 +224,12 @@ func DetectLanguage(ext string) string {
 
 	return ""
 }
+
+// RegisterLanguage makes DetectLanguage report language for extensions,
+// such as those of a grammar loaded at run time. It must be called before
+// files are scanned.
+func RegisterLanguage(language string, extensions []string) {
+	for _, ext := range extensions {
+		languageMap[strings.ToLower(ext)] = language
+	}
+}
@@ -0,0 +1,29 @@
+package scanner
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// Prioritize orders files for a build that may not get through all of them,
+// so the files most likely to answer a first search come first: files in a
+// supported language before others, non-test files before tests, files near
+// the root before deeply nested ones, and smaller files before larger ones.
+// The order is otherwise kept.
+func Prioritize(files []FileInfo) {
+	sort.SliceStable(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+		if (a.Language == "") != (b.Language == "") {
+			return a.Language != ""
+		}
+		if testA, testB := types.IsTestFile(a.Path), types.IsTestFile(b.Path); testA != testB {
+			return !testA
+		}
+		if depthA, depthB := strings.Count(a.Path, "/"), strings.Count(b.Path, "/"); depthA != depthB {
+			return depthA < depthB
+		}
+		return a.Size < b.Size
+	})
+}
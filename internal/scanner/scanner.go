@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 )
@@ -26,6 +27,8 @@ type Options struct {
 	FollowSymlinks  bool     // Follow symlinks (within root only)
 	DefaultExcludes []string // Default directories to exclude
 	IgnoreFileName  string   // Name of the ignore file (default: .gcqignore)
+	Ignore          []string // Extra ignore patterns, applied from the root before any ignore file
+	IgnoreFiles     []string // Extra ignore files, such as a shared list, applied from the root after Ignore
 }
 
 // DefaultOptions returns scanner options with sensible defaults.
@@ -69,7 +72,9 @@ func New(opts Options) *Scanner {
 }
 
 // Scan recursively scans the directory at root and returns a list of FileInfo.
-// It respects .gcqignore patterns and default exclusions.
+// It respects the .gcqignore and .gitignore files of the root and of every
+// directory below it, each applying to its own directory, and default
+// exclusions. Directories the patterns ignore are not walked.
 func (s *Scanner) Scan(root string) ([]FileInfo, error) {
 	absRoot, err := filepath.Abs(root)
 	if err != nil {
@@ -77,9 +82,14 @@ func (s *Scanner) Scan(root string) ([]FileInfo, error) {
 	}
 	s.root = absRoot
 
-	ignoreFiles, err := s.findIgnoreFiles(absRoot)
+	rootPatterns, err := s.rootPatterns()
 	if err != nil {
-		return nil, fmt.Errorf("finding ignore files: %w", err)
+		return nil, err
+	}
+	// The patterns that apply to the entries of each directory: those of
+	// the directories above it and its own ignore files, in that order
+	dirPatterns := map[string][]IgnorePattern{
+		".": s.withIgnoreFiles(absRoot, "", rootPatterns),
 	}
 
 	var files []FileInfo
@@ -116,15 +126,19 @@ func (s *Scanner) Scan(root string) ([]FileInfo, error) {
 			return nil
 		}
 
+		patterns := dirPatterns[filepath.ToSlash(filepath.Dir(relPath))]
 		if d.IsDir() {
 			if s.isDefaultExcluded(d.Name()) {
 				return filepath.SkipDir
 			}
+			if s.prunes(relPathSlash, patterns) {
+				return filepath.SkipDir
+			}
+			dirPatterns[relPathSlash] = s.withIgnoreFiles(path, relPathSlash, patterns)
 			return nil
 		}
 
-		patterns := s.getPatternsForPath(absRoot, relPath, ignoreFiles)
-		if s.matchesIgnorePatterns(relPathSlash, patterns) {
+		if ignored, _ := s.matchesIgnorePatterns(relPathSlash, false, patterns); ignored {
 			return nil
 		}
 
@@ -214,6 +228,13 @@ func (s *Scanner) isHidden(name string) bool {
 	return strings.HasPrefix(name, ".")
 }
 
+// SkipsDir reports whether Scan skips directories with this name because
+// they are hidden or excluded by default. Ignore-file patterns are not
+// considered.
+func (s *Scanner) SkipsDir(name string) bool {
+	return (s.opts.SkipHidden && s.isHidden(name)) || s.isDefaultExcluded(name)
+}
+
 // isDefaultExcluded checks if the name matches default exclusion patterns.
 func (s *Scanner) isDefaultExcluded(name string) bool {
 	for _, exclude := range s.opts.DefaultExcludes {
@@ -224,8 +245,24 @@ func (s *Scanner) isDefaultExcluded(name string) bool {
 	return false
 }
 
-// loadPatternsFromFile loads ignore patterns from a single file.
-func (s *Scanner) loadPatternsFromFile(ignorePath string) ([]IgnorePattern, error) {
+// rootPatterns returns the patterns of Options.Ignore and then of
+// Options.IgnoreFiles, which apply from the root before any ignore file of
+// the project. A missing ignore file adds no patterns.
+func (s *Scanner) rootPatterns() ([]IgnorePattern, error) {
+	patterns := parseIgnorePatterns(s.opts.Ignore, "")
+	for _, ignorePath := range s.opts.IgnoreFiles {
+		filePatterns, err := s.loadPatternsFromFile(ignorePath, "")
+		if err != nil {
+			return nil, fmt.Errorf("reading ignore file %s: %w", ignorePath, err)
+		}
+		patterns = append(patterns, filePatterns...)
+	}
+	return patterns, nil
+}
+
+// loadPatternsFromFile loads ignore patterns from a single file whose
+// patterns are relative to base, a directory relative to the root.
+func (s *Scanner) loadPatternsFromFile(ignorePath, base string) ([]IgnorePattern, error) {
 	file, err := os.Open(ignorePath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -235,128 +272,67 @@ func (s *Scanner) loadPatternsFromFile(ignorePath string) ([]IgnorePattern, erro
 	}
 	defer file.Close()
 
-	var patterns []IgnorePattern
+	var lines []string
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		patterns = append(patterns, ParseIgnorePattern(line))
+		lines = append(lines, scanner.Text())
 	}
-
-	return patterns, scanner.Err()
-}
-
-// loadIgnorePatterns loads ignore patterns from .gcqignore and .gitignore files.
-func (s *Scanner) loadIgnorePatterns(dir string) ([]IgnorePattern, error) {
-	var allPatterns []IgnorePattern
-
-	// Load .gcqignore first
-	gcqignorePath := filepath.Join(dir, s.opts.IgnoreFileName)
-	patterns, err := s.loadPatternsFromFile(gcqignorePath)
-	if err != nil {
-		return nil, err
-	}
-	if len(patterns) > 0 {
-		allPatterns = append(allPatterns, patterns...)
+	patterns := parseIgnorePatterns(lines, base)
+	for i := range patterns {
+		patterns[i].source = ignorePath
 	}
 
-	// Load .gitignore
-	gitignorePath := filepath.Join(dir, ".gitignore")
-	patterns, err = s.loadPatternsFromFile(gitignorePath)
-	if err != nil {
-		return nil, err
-	}
-	if len(patterns) > 0 {
-		allPatterns = append(allPatterns, patterns...)
-	}
-
-	return allPatterns, nil
+	return patterns, scanner.Err()
 }
 
-// findIgnoreFiles finds all .gcqignore files in the directory tree.
-func (s *Scanner) findIgnoreFiles(root string) (map[string][]IgnorePattern, error) {
-	ignoreFiles := make(map[string][]IgnorePattern)
-
-	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return nil
-		}
-
-		if !d.IsDir() {
-			return nil
-		}
-
-		// Skip hidden directories
-		if s.opts.SkipHidden && s.isHidden(d.Name()) {
-			return nil
-		}
-
-		if s.isDefaultExcluded(d.Name()) {
-			return nil
-		}
-
-		patterns, err := s.loadIgnorePatterns(path)
+// withIgnoreFiles returns patterns followed by those of the .gcqignore and
+// .gitignore files in dir, whose path relative to the root is base. Ignore
+// files that can't be read are skipped.
+func (s *Scanner) withIgnoreFiles(dir, base string, patterns []IgnorePattern) []IgnorePattern {
+	patterns = slices.Clip(patterns)
+	for _, name := range []string{s.opts.IgnoreFileName, ".gitignore"} {
+		filePatterns, err := s.loadPatternsFromFile(filepath.Join(dir, name), base)
 		if err != nil {
-			return nil // Continue walking even if we can't read ignore file
-		}
-
-		if len(patterns) > 0 {
-			ignoreFiles[path] = patterns
+			continue
 		}
-
-		return nil
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("finding ignore files: %w", err)
+		patterns = append(patterns, filePatterns...)
 	}
-
-	return ignoreFiles, nil
+	return patterns
 }
 
-// getPatternsForPath returns all ignore patterns that apply to a given path.
-// Patterns are collected from all ancestor directories, with closer ancestors
-// taking precedence (later patterns override earlier ones).
-func (s *Scanner) getPatternsForPath(absRoot, relPath string, ignoreFiles map[string][]IgnorePattern) []IgnorePattern {
-	var result []IgnorePattern
-
-	relPath = filepath.ToSlash(relPath)
-	parts := strings.Split(relPath, "/")
-
-	// Build all ancestor paths from root to the file's parent
-	for i := 0; i <= len(parts); i++ {
-		var ancestorPath string
-		if i == 0 {
-			ancestorPath = absRoot
-		} else {
-			ancestorPath = filepath.Join(absRoot, strings.Join(parts[:i], string(filepath.Separator)))
-		}
-
-		if patterns, ok := ignoreFiles[ancestorPath]; ok {
-			result = append(result, patterns...)
-		}
+// prunes reports whether Scan skips the directory at relPath whole because
+// patterns ignore it. A directory is still walked when any of the patterns
+// is a negation, which may re-include a file inside it.
+func (s *Scanner) prunes(relPath string, patterns []IgnorePattern) bool {
+	if slices.ContainsFunc(patterns, IgnorePattern.IsNegation) {
+		return false
 	}
-
-	return result
+	ignored, _ := s.matchesIgnorePatterns(relPath, true, patterns)
+	return ignored
 }
 
 // matchesIgnorePatterns checks if the given path should be ignored based on patterns.
 // It implements gitignore semantics: patterns are checked in order, and negation
-// patterns can override previous positive matches.
-func (s *Scanner) matchesIgnorePatterns(relPath string, patterns []IgnorePattern) bool {
+// patterns can override previous positive matches. It also returns the last
+// pattern that matched, which decided, or nil.
+func (s *Scanner) matchesIgnorePatterns(relPath string, isDir bool, patterns []IgnorePattern) (bool, *IgnorePattern) {
 	ignored := false
-	for _, pattern := range patterns {
-		if pattern.Match(relPath) {
-			if pattern.IsNegation() {
-				ignored = false
-			} else {
-				ignored = true
-			}
+	var decided *IgnorePattern
+	for i, pattern := range patterns {
+		if pattern.match(relPath, isDir) {
+			ignored = !pattern.IsNegation()
+			decided = &patterns[i]
 		}
 	}
-	return ignored
+	return ignored, decided
+}
+
+// patternOrigin describes an ignore pattern and where it comes from
+func patternOrigin(p *IgnorePattern) string {
+	if p.source == "" {
+		return fmt.Sprintf("configured pattern %q", p.pattern)
+	}
+	return fmt.Sprintf("pattern %q in %s", p.pattern, p.source)
 }
 
 // Scan is a convenience function that scans a directory with default options.
@@ -370,3 +346,128 @@ func ScanWithOptions(root string, opts Options) ([]FileInfo, error) {
 	scanner := New(opts)
 	return scanner.Scan(root)
 }
+
+// Explanation describes whether Scan would return a file, and which rule
+// decided it.
+type Explanation struct {
+	Path     string // Relative path from root
+	FullPath string // Absolute path
+	Included bool   // True if Scan returns the file
+	Reason   string // Human-readable reason for the decision
+	Language string // Detected language from extension ("" if unknown)
+	Size     int64  // File size in bytes
+
+	// IgnoreFile and Pattern name the ignore rule that decided the file,
+	// including a negation that re-included it. IgnoreFile is empty for a
+	// pattern from Options.Ignore.
+	IgnoreFile string
+	Pattern    string
+}
+
+// Explain applies the rules of Scan to a single file under root and reports
+// whether it would be returned and why.
+func (s *Scanner) Explain(root, path string) (*Explanation, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("getting absolute path: %w", err)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("getting absolute path: %w", err)
+	}
+	relPath, err := filepath.Rel(absRoot, absPath)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("%s is outside the project root %s", path, absRoot)
+	}
+	relPathSlash := filepath.ToSlash(relPath)
+
+	e := &Explanation{
+		Path:     relPathSlash,
+		FullPath: absPath,
+		Language: DetectLanguage(filepath.Ext(absPath)),
+	}
+
+	info, err := os.Lstat(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("stat %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+	e.Size = info.Size()
+
+	// Scan skips hidden and excluded directories without descending, so
+	// their ignore files are never read either
+	parts := strings.Split(relPathSlash, "/")
+	for i, name := range parts {
+		isDir := i < len(parts)-1
+		if s.opts.SkipHidden && s.isHidden(name) {
+			if isDir {
+				e.Reason = fmt.Sprintf("inside hidden directory %s", strings.Join(parts[:i+1], "/"))
+			} else {
+				e.Reason = "hidden file"
+			}
+			return e, nil
+		}
+		if isDir && s.isDefaultExcluded(name) {
+			e.Reason = fmt.Sprintf("inside %s, which is excluded by default", strings.Join(parts[:i+1], "/"))
+			return e, nil
+		}
+	}
+
+	// Ignore files apply from the root down to the file's directory, and the
+	// last matching pattern wins. Scan skips an ignored directory whole
+	// unless a negation may re-include a file in it.
+	rootPatterns, err := s.rootPatterns()
+	if err != nil {
+		return nil, err
+	}
+	patterns := s.withIgnoreFiles(absRoot, "", rootPatterns)
+	for i := 1; i < len(parts); i++ {
+		dir := strings.Join(parts[:i], "/")
+		if s.prunes(dir, patterns) {
+			_, p := s.matchesIgnorePatterns(dir, true, patterns)
+			e.IgnoreFile, e.Pattern = p.source, p.pattern
+			e.Reason = fmt.Sprintf("inside %s, ignored by %s", dir, patternOrigin(p))
+			return e, nil
+		}
+		patterns = s.withIgnoreFiles(filepath.Join(absRoot, filepath.FromSlash(dir)), dir, patterns)
+	}
+	ignored, decided := s.matchesIgnorePatterns(relPathSlash, false, patterns)
+	if decided != nil {
+		e.IgnoreFile, e.Pattern = decided.source, decided.pattern
+	}
+	if ignored {
+		e.Reason = "ignored by " + patternOrigin(decided)
+		return e, nil
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		if !s.opts.FollowSymlinks {
+			e.Reason = "symlink, and symlinks are not followed"
+			return e, nil
+		}
+		realPath, err := filepath.EvalSymlinks(absPath)
+		if err != nil {
+			e.Reason = fmt.Sprintf("broken symlink: %v", err)
+			return e, nil
+		}
+		if !strings.HasPrefix(realPath, absRoot+string(filepath.Separator)) {
+			e.Reason = fmt.Sprintf("symlink to %s, outside the project root", realPath)
+			return e, nil
+		}
+		targetInfo, err := os.Stat(realPath)
+		if err != nil || targetInfo.IsDir() {
+			e.Reason = fmt.Sprintf("symlink to %s, which is not a file", realPath)
+			return e, nil
+		}
+		e.Size = targetInfo.Size()
+	}
+
+	e.Included = true
+	e.Reason = "scanned"
+	if decided != nil {
+		e.Reason = fmt.Sprintf("scanned (re-included by %s)", patternOrigin(decided))
+	}
+	return e, nil
+}
@@ -3,6 +3,8 @@ package scanner
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -184,6 +186,18 @@ func TestScannerSkipHidden(t *testing.T) {
 	}
 }
 
+func TestScannerSkipsDir(t *testing.T) {
+	scanner := New(DefaultOptions())
+	for _, name := range []string{".git", "node_modules"} {
+		if !scanner.SkipsDir(name) {
+			t.Errorf("SkipsDir(%q) = false, want true", name)
+		}
+	}
+	if scanner.SkipsDir("src") {
+		t.Error("SkipsDir(\"src\") = true, want false")
+	}
+}
+
 func TestLanguageDetection(t *testing.T) {
 	tests := []struct {
 		ext      string
@@ -251,6 +265,22 @@ func TestIgnorePattern(t *testing.T) {
 
 		// Negation - pattern matches but is negation
 		{"!*.js", "file.js", true}, // Negation pattern still matches the file
+
+		// A pattern with a slash in the middle is anchored
+		{"src/app.js", "src/app.js", true},
+		{"src/app.js", "lib/src/app.js", false},
+		{"src/*.js", "lib/src/app.js", false},
+
+		// A pattern matching a directory matches everything inside it
+		{"generated", "generated/model.go", true},
+		{"generated", "pkg/generated/model.go", true},
+		{"*.egg-info", "pkg.egg-info/PKG-INFO", true},
+		{"build/", "build", false}, // a file, not a directory
+
+		// Wildcards
+		{"*.js", "app.test.js", true},
+		{"file[!0-9].js", "filea.js", true},
+		{"file[!0-9].js", "file1.js", false},
 	}
 
 	for _, tt := range tests {
@@ -261,3 +291,197 @@ func TestIgnorePattern(t *testing.T) {
 		}
 	}
 }
+
+func TestScannerExplain(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"main.go":                  "package main",
+		"gen/model.go":             "package gen",
+		"gen/keep.go":              "package gen",
+		".hidden/file.go":          "package hidden",
+		"node_modules/pkg/main.js": "module.exports = {}",
+		".gcqignore":               "gen/\n!gen/keep.go\n",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	scanner := New(DefaultOptions())
+	tests := []struct {
+		path     string
+		included bool
+		pattern  string
+	}{
+		{"main.go", true, ""},
+		{"gen/model.go", false, "gen/"},
+		{"gen/keep.go", true, "!gen/keep.go"},
+		{".hidden/file.go", false, ""},
+		{"node_modules/pkg/main.js", false, ""},
+	}
+	for _, tt := range tests {
+		e, err := scanner.Explain(tmpDir, filepath.Join(tmpDir, tt.path))
+		if err != nil {
+			t.Fatalf("Explain(%s) failed: %v", tt.path, err)
+		}
+		if e.Included != tt.included || e.Pattern != tt.pattern {
+			t.Errorf("Explain(%s) = included %v, pattern %q (%s), want %v, %q", tt.path, e.Included, e.Pattern, e.Reason, tt.included, tt.pattern)
+		}
+	}
+
+	// Explain agrees with Scan
+	results, err := scanner.Scan(tmpDir)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	scanned := make(map[string]bool)
+	for _, f := range results {
+		scanned[f.Path] = true
+	}
+	for _, tt := range tests {
+		if scanned[tt.path] != tt.included {
+			t.Errorf("Scan included %s = %v, Explain says %v", tt.path, scanned[tt.path], tt.included)
+		}
+	}
+
+	if _, err := scanner.Explain(tmpDir, filepath.Dir(tmpDir)); err == nil {
+		t.Error("Expected an error for a path outside the root")
+	}
+}
+
+func TestScannerIgnoreOption(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"main.go":      "package main",
+		"gen/model.go": "package gen",
+		"gen/keep.go":  "package gen",
+		"docs/api.md":  "# API",
+		".gcqignore":   "!gen/keep.go\n",
+	}
+	for path, content := range files {
+		fullPath := filepath.Join(tmpDir, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	opts := DefaultOptions()
+	opts.Ignore = []string{"gen/", "*.md"}
+	scanner := New(opts)
+	results, err := scanner.Scan(tmpDir)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	var found []string
+	for _, f := range results {
+		found = append(found, f.Path)
+	}
+	slices.Sort(found)
+	// The ignore file is applied after the configured patterns, so its
+	// negation re-includes gen/keep.go
+	if strings.Join(found, ",") != "gen/keep.go,main.go" {
+		t.Errorf("Scan found %v, want gen/keep.go and main.go", found)
+	}
+
+	e, err := scanner.Explain(tmpDir, filepath.Join(tmpDir, "docs/api.md"))
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if e.Included || e.Pattern != "*.md" || e.IgnoreFile != "" {
+		t.Errorf("Explain(docs/api.md) = included %v, pattern %q in %q", e.Included, e.Pattern, e.IgnoreFile)
+	}
+}
+
+func TestPrioritize(t *testing.T) {
+	files := []FileInfo{
+		{Path: "README.md", Size: 10},
+		{Path: "pkg/deep/util.go", Language: "go", Size: 10},
+		{Path: "main_test.go", Language: "go", Size: 10},
+		{Path: "server.go", Language: "go", Size: 500},
+		{Path: "main.go", Language: "go", Size: 100},
+		{Path: "pkg/api.go", Language: "go", Size: 10},
+	}
+	Prioritize(files)
+
+	var got []string
+	for _, f := range files {
+		got = append(got, f.Path)
+	}
+	want := []string{"main.go", "server.go", "pkg/api.go", "pkg/deep/util.go", "main_test.go", "README.md"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Prioritize = %v, want %v", got, want)
+	}
+}
+
+func TestScannerNestedIgnoreFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"main.go":            "package main",
+		"gen.go":             "package main",
+		"generated/model.go": "package generated",
+		"api/gen.go":         "package api",
+		"api/handler.go":     "package api",
+		"api/v2/gen.go":      "package v2",
+		"web/app.test.js":    "test()",
+		"web/app.js":         "app()",
+		".gitignore":         "generated\n",
+		"api/.gitignore":     "/gen.go\n",
+	}
+	root := filepath.Join(tmpDir, "project")
+	for path, content := range files {
+		fullPath := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+	// A shared ignore file outside the project; a missing one is skipped
+	shared := filepath.Join(tmpDir, "shared.ignore")
+	if err := os.WriteFile(shared, []byte("*.test.js\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	opts := DefaultOptions()
+	opts.IgnoreFiles = []string{shared, filepath.Join(tmpDir, "missing.ignore")}
+	scanner := New(opts)
+	results, err := scanner.Scan(root)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	var found []string
+	for _, f := range results {
+		found = append(found, f.Path)
+	}
+	slices.Sort(found)
+	// api/.gitignore anchors /gen.go to api/, so gen.go and api/v2/gen.go
+	// stay; the bare name generated skips the whole directory
+	want := "api/handler.go,api/v2/gen.go,gen.go,main.go,web/app.js"
+	if strings.Join(found, ",") != want {
+		t.Errorf("Scan found %v, want %s", found, want)
+	}
+
+	e, err := scanner.Explain(root, filepath.Join(root, "generated/model.go"))
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if e.Included || e.Pattern != "generated" || !strings.HasPrefix(e.Reason, "inside generated") {
+		t.Errorf("Explain(generated/model.go) = included %v, %s", e.Included, e.Reason)
+	}
+	e, err = scanner.Explain(root, filepath.Join(root, "web/app.test.js"))
+	if err != nil {
+		t.Fatalf("Explain failed: %v", err)
+	}
+	if e.Included || e.IgnoreFile != opts.IgnoreFiles[0] {
+		t.Errorf("Explain(web/app.test.js) = included %v, %s", e.Included, e.Reason)
+	}
+}
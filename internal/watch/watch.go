@@ -0,0 +1,260 @@
+// Package watch watches project roots for source file changes so the daemon
+// can re-index only the files that changed. Events are debounced and
+// delivered in batches of changed and removed paths.
+package watch
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDebounce is how long the watcher waits for activity to settle
+// before delivering a batch
+const DefaultDebounce = 500 * time.Millisecond
+
+// Batch is a set of paths that changed during one debounce window
+type Batch struct {
+	// Changed lists files that were created or written and still exist
+	Changed []string
+	// Removed lists files and directories that were deleted or renamed away
+	Removed []string
+}
+
+// Empty reports whether the batch has no paths
+func (b Batch) Empty() bool {
+	return len(b.Changed) == 0 && len(b.Removed) == 0
+}
+
+// Options configures a Watcher
+type Options struct {
+	// Debounce is the quiet period before a batch is delivered
+	// (0 = DefaultDebounce)
+	Debounce time.Duration
+	// SkipDir reports whether a directory name should not be watched
+	SkipDir func(name string) bool
+	// Match reports whether a file is of interest; nil matches every file
+	Match func(path string) bool
+}
+
+// Watcher watches directory trees and delivers debounced batches of changes
+type Watcher struct {
+	fsw  *fsnotify.Watcher
+	opts Options
+
+	mu      sync.Mutex
+	dirs    map[string]bool
+	changed map[string]bool
+	removed map[string]bool
+}
+
+// New creates a Watcher. Call Add for each root, then Run.
+func New(opts Options) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating file watcher: %w", err)
+	}
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultDebounce
+	}
+	return &Watcher{
+		fsw:     fsw,
+		opts:    opts,
+		dirs:    make(map[string]bool),
+		changed: make(map[string]bool),
+		removed: make(map[string]bool),
+	}, nil
+}
+
+// Add watches root and every directory below it that is not skipped
+func (w *Watcher) Add(root string) error {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return fmt.Errorf("getting absolute path: %w", err)
+	}
+	return filepath.WalkDir(absRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if path != absRoot && w.skipDir(d.Name()) {
+			return filepath.SkipDir
+		}
+		return w.addDir(path)
+	})
+}
+
+// Dirs returns the number of directories being watched
+func (w *Watcher) Dirs() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.dirs)
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	return w.fsw.Close()
+}
+
+// Run delivers batches to fn until ctx is done. fn is called from Run's
+// goroutine, one batch at a time; changes arriving while fn runs are
+// collected into the next batch.
+func (w *Watcher) Run(ctx context.Context, fn func(Batch)) {
+	timer := time.NewTimer(w.opts.Debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			if w.handleEvent(event) {
+				timer.Reset(w.opts.Debounce)
+			}
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("File watcher error: %v", err)
+		case <-timer.C:
+			if batch := w.flush(); !batch.Empty() {
+				fn(batch)
+			}
+		}
+	}
+}
+
+// handleEvent records an event and reports whether it is of interest
+func (w *Watcher) handleEvent(event fsnotify.Event) bool {
+	path := event.Name
+
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		// Directories are reported too, so their files can be dropped
+		wasDir := w.dirs[path]
+		if wasDir {
+			for dir := range w.dirs {
+				if dir == path || isWithin(dir, path) {
+					delete(w.dirs, dir)
+				}
+			}
+			for changed := range w.changed {
+				if isWithin(changed, path) {
+					delete(w.changed, changed)
+				}
+			}
+		} else if !w.match(path) {
+			return false
+		}
+		delete(w.changed, path)
+		w.removed[path] = true
+		return true
+	}
+
+	if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) {
+		return false
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	if info.IsDir() {
+		if !event.Has(fsnotify.Create) || w.skipDir(filepath.Base(path)) {
+			return false
+		}
+		// Watch the new directory and pick up files created in it before
+		// the watch was in place
+		var found bool
+		_ = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				if p != path && w.skipDir(d.Name()) {
+					return filepath.SkipDir
+				}
+				if err := w.addDir(p); err != nil {
+					log.Printf("File watcher: %v", err)
+				}
+				return nil
+			}
+			if w.match(p) {
+				w.markChanged(p)
+				found = true
+			}
+			return nil
+		})
+		return found
+	}
+
+	if !w.match(path) {
+		return false
+	}
+	w.markChanged(path)
+	return true
+}
+
+func (w *Watcher) markChanged(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.removed, path)
+	w.changed[path] = true
+}
+
+// flush returns and resets the pending batch
+func (w *Watcher) flush() Batch {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var batch Batch
+	for path := range w.changed {
+		batch.Changed = append(batch.Changed, path)
+	}
+	for path := range w.removed {
+		batch.Removed = append(batch.Removed, path)
+	}
+	sort.Strings(batch.Changed)
+	sort.Strings(batch.Removed)
+	w.changed = make(map[string]bool)
+	w.removed = make(map[string]bool)
+	return batch
+}
+
+func (w *Watcher) addDir(path string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.dirs[path] {
+		return nil
+	}
+	if err := w.fsw.Add(path); err != nil {
+		return fmt.Errorf("watching %s: %w", path, err)
+	}
+	w.dirs[path] = true
+	return nil
+}
+
+func (w *Watcher) skipDir(name string) bool {
+	return w.opts.SkipDir != nil && w.opts.SkipDir(name)
+}
+
+func (w *Watcher) match(path string) bool {
+	return w.opts.Match == nil || w.opts.Match(path)
+}
+
+// isWithin reports whether path lies below dir
+func isWithin(path, dir string) bool {
+	return strings.HasPrefix(path, dir+string(filepath.Separator))
+}
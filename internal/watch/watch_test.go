@@ -0,0 +1,121 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// collect runs the watcher and returns a channel receiving its batches
+func collect(t *testing.T, w *Watcher) <-chan Batch {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	batches := make(chan Batch, 10)
+	go w.Run(ctx, func(b Batch) { batches <- b })
+	return batches
+}
+
+func waitBatch(t *testing.T, batches <-chan Batch) Batch {
+	t.Helper()
+	select {
+	case b := <-batches:
+		return b
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a batch")
+	}
+	return Batch{}
+}
+
+func newTestWatcher(t *testing.T, root string) *Watcher {
+	t.Helper()
+	w, err := New(Options{
+		Debounce: 50 * time.Millisecond,
+		SkipDir:  func(name string) bool { return strings.HasPrefix(name, ".") || name == "node_modules" },
+		Match:    func(path string) bool { return filepath.Ext(path) == ".go" },
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { w.Close() })
+	if err := w.Add(root); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	return w
+}
+
+func TestWatcherChangedAndRemoved(t *testing.T) {
+	root := t.TempDir()
+	existing := filepath.Join(root, "old.go")
+	if err := os.WriteFile(existing, []byte("package a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "node_modules", "x"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	w := newTestWatcher(t, root)
+	if w.Dirs() != 1 {
+		t.Errorf("Expected only the root to be watched, got %d dirs", w.Dirs())
+	}
+	batches := collect(t, w)
+
+	created := filepath.Join(root, "new.go")
+	if err := os.WriteFile(created, []byte("package a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "notes.txt"), []byte("ignored"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	batch := waitBatch(t, batches)
+	if len(batch.Changed) != 1 || batch.Changed[0] != created {
+		t.Errorf("Changed = %v, want [%s]", batch.Changed, created)
+	}
+	if len(batch.Removed) != 1 || batch.Removed[0] != existing {
+		t.Errorf("Removed = %v, want [%s]", batch.Removed, existing)
+	}
+}
+
+func TestWatcherNewAndRemovedDirectory(t *testing.T) {
+	root := t.TempDir()
+	w := newTestWatcher(t, root)
+	batches := collect(t, w)
+
+	dir := filepath.Join(root, "pkg")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "a.go")
+	if err := os.WriteFile(file, []byte("package pkg\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	batch := waitBatch(t, batches)
+	if len(batch.Changed) != 1 || batch.Changed[0] != file {
+		t.Fatalf("Changed = %v, want [%s]", batch.Changed, file)
+	}
+	if w.Dirs() != 2 {
+		t.Errorf("Expected new directory to be watched, got %d dirs", w.Dirs())
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	batch = waitBatch(t, batches)
+	found := false
+	for _, p := range batch.Removed {
+		if p == dir {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected removed directory %s in %v", dir, batch.Removed)
+	}
+}
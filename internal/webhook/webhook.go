@@ -0,0 +1,150 @@
+// Package webhook delivers daemon events to HTTP endpoints, so a shared
+// daemon can report reindexing and failures to chat channels or CI.
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Event types sent by the daemon
+const (
+	// EventIndexBuilt is sent when a warm request finishes building an index
+	EventIndexBuilt = "index.built"
+	// EventWatchChanges is sent when the file watcher re-indexes a batch of changes
+	EventWatchChanges = "watch.changes"
+	// EventProviderFailed is sent when the embedding provider returns errors
+	EventProviderFailed = "provider.failed"
+	// EventJobFinished is sent when a background reindex or refresh finishes
+	EventJobFinished = "job.finished"
+)
+
+// Events lists every event type
+var Events = []string{EventIndexBuilt, EventWatchChanges, EventProviderFailed, EventJobFinished}
+
+// deliveryTimeout bounds each POST so a slow endpoint can't pile up goroutines
+const deliveryTimeout = 10 * time.Second
+
+// Event is the JSON body POSTed to each webhook. Text is a one-line summary;
+// chat services that read a "text" field (Slack, Mattermost, Discord via
+// /slack) show it as the message.
+type Event struct {
+	Type    string         `json:"event"`
+	Time    time.Time      `json:"time"`
+	Host    string         `json:"host,omitempty"`
+	Project string         `json:"project,omitempty"`
+	Text    string         `json:"text"`
+	Data    map[string]any `json:"data,omitempty"`
+}
+
+// Notifier POSTs events to a list of URLs in the background. A nil Notifier
+// drops every event, so callers don't need to check whether webhooks are
+// configured.
+type Notifier struct {
+	urls   []string
+	events map[string]bool
+	host   string
+	client *http.Client
+	wg     sync.WaitGroup
+}
+
+// New creates a notifier for urls that sends the given event types, or every
+// type when events is empty. It returns nil when urls is empty.
+func New(urls, events []string) (*Notifier, error) {
+	if len(urls) == 0 {
+		return nil, nil
+	}
+
+	n := &Notifier{
+		urls:   urls,
+		client: &http.Client{Timeout: deliveryTimeout},
+	}
+	if len(events) > 0 {
+		n.events = make(map[string]bool, len(events))
+		for _, event := range events {
+			if !isEvent(event) {
+				return nil, fmt.Errorf("unknown webhook event %q (must be one of %v)", event, Events)
+			}
+			n.events[event] = true
+		}
+	}
+	n.host, _ = os.Hostname()
+	return n, nil
+}
+
+func isEvent(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Enabled reports whether events of the given type are sent
+func (n *Notifier) Enabled(eventType string) bool {
+	return n != nil && (n.events == nil || n.events[eventType])
+}
+
+// Notify sends ev to every URL without blocking. Delivery failures are
+// logged and not retried.
+func (n *Notifier) Notify(ev Event) {
+	if !n.Enabled(ev.Type) {
+		return
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now().UTC()
+	}
+	ev.Host = n.host
+
+	body, err := json.Marshal(ev)
+	if err != nil {
+		log.Printf("Error encoding %s webhook: %v", ev.Type, err)
+		return
+	}
+
+	for _, url := range n.urls {
+		n.wg.Add(1)
+		go func(url string) {
+			defer n.wg.Done()
+			if err := n.post(url, body); err != nil {
+				log.Printf("Error sending %s webhook to %s: %v", ev.Type, url, err)
+			}
+		}(url)
+	}
+}
+
+func (n *Notifier) post(url string, body []byte) error {
+	resp, err := n.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// Wait blocks until events already sent are delivered, or timeout passes.
+// The daemon calls it on shutdown so its last events aren't lost.
+func (n *Notifier) Wait(timeout time.Duration) {
+	if n == nil {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	if n, err := New(nil, []string{EventIndexBuilt}); n != nil || err != nil {
+		t.Errorf("New without URLs = %v, %v, want nil, nil", n, err)
+	}
+	if _, err := New([]string{"http://example.com"}, []string{"index.deleted"}); err == nil {
+		t.Error("expected an error for an unknown event")
+	}
+
+	var n *Notifier
+	if n.Enabled(EventIndexBuilt) {
+		t.Error("nil notifier reports events as enabled")
+	}
+	n.Notify(Event{Type: EventIndexBuilt})
+	n.Wait(time.Second)
+}
+
+func TestNotify(t *testing.T) {
+	var mu sync.Mutex
+	var received []Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var ev Event
+		if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		received = append(received, ev)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	n, err := New([]string{server.URL, server.URL}, []string{EventIndexBuilt, EventJobFinished})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	n.Notify(Event{Type: EventIndexBuilt, Project: "/project", Text: "built", Data: map[string]any{"files": 3}})
+	n.Notify(Event{Type: EventWatchChanges, Text: "filtered out"})
+	n.Wait(5 * time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 2 {
+		t.Fatalf("expected the event at both URLs, got %+v", received)
+	}
+	ev := received[0]
+	if ev.Type != EventIndexBuilt || ev.Project != "/project" || ev.Text != "built" || ev.Time.IsZero() {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+	if files, _ := ev.Data["files"].(float64); files != 3 {
+		t.Errorf("data = %v, want files 3", ev.Data)
+	}
+}
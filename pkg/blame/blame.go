@@ -0,0 +1,149 @@
+// Package blame summarizes who last changed the lines of a unit and why:
+// consecutive lines last changed by the same commit form a hunk, reported
+// with the commit's author, date and subject. Blame is read from the
+// repository's HEAD commit with go-git, so no git binary is needed; lines
+// changed in the working tree since are attributed as of HEAD.
+package blame
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Hunk is a run of lines last changed by one commit
+type Hunk struct {
+	StartLine int       `json:"start_line"`
+	EndLine   int       `json:"end_line"`
+	Commit    string    `json:"commit"`
+	Author    string    `json:"author"`
+	Date      time.Time `json:"date"`
+	Subject   string    `json:"subject"`
+}
+
+// String formats the hunk on one line, e.g.
+// `1a2b3c4 Jane Doe 2026-03-01 "Fix parser" (lines 10-14)`
+func (h Hunk) String() string {
+	lines := fmt.Sprintf("line %d", h.StartLine)
+	if h.EndLine > h.StartLine {
+		lines = fmt.Sprintf("lines %d-%d", h.StartLine, h.EndLine)
+	}
+	return fmt.Sprintf("%s %s %s %q (%s)", h.Commit, h.Author, h.Date.Format(time.DateOnly), h.Subject, lines)
+}
+
+// Blamer blames files of one repository at its HEAD commit. Results are
+// cached per file, so blaming several units of a file walks its history
+// once. A Blamer is safe for concurrent use.
+type Blamer struct {
+	repo *git.Repository
+	head *object.Commit
+	root string
+
+	mu       sync.Mutex
+	files    map[string]*git.BlameResult
+	subjects map[plumbing.Hash]string
+}
+
+// Open returns a Blamer for the repository containing dir
+func Open(dir string) (*Blamer, error) {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("opening git repository at %s: %w", dir, err)
+	}
+	ref, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("resolving HEAD: %w", err)
+	}
+	head, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("reading HEAD commit: %w", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("opening worktree: %w", err)
+	}
+	return &Blamer{
+		repo:     repo,
+		head:     head,
+		root:     wt.Filesystem.Root(),
+		files:    make(map[string]*git.BlameResult),
+		subjects: make(map[plumbing.Hash]string),
+	}, nil
+}
+
+// Lines returns the hunks of lines start to end (1-based, inclusive) of
+// path, which is absolute or relative to the repository root. An end
+// before start blames the start line only. Files not committed at HEAD
+// return an error.
+func (b *Blamer) Lines(path string, start, end int) ([]Hunk, error) {
+	rel, err := b.relPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	result, ok := b.files[rel]
+	if !ok {
+		result, err = git.Blame(b.head, rel)
+		if err != nil {
+			return nil, fmt.Errorf("blaming %s: %w", rel, err)
+		}
+		b.files[rel] = result
+	}
+
+	start = max(start, 1)
+	end = min(max(end, start), len(result.Lines))
+	var hunks []Hunk
+	for n := start; n <= end; n++ {
+		line := result.Lines[n-1]
+		commit := line.Hash.String()[:7]
+		if last := len(hunks) - 1; last >= 0 && hunks[last].Commit == commit {
+			hunks[last].EndLine = n
+			continue
+		}
+		hunks = append(hunks, Hunk{
+			StartLine: n,
+			EndLine:   n,
+			Commit:    commit,
+			Author:    line.AuthorName,
+			Date:      line.Date,
+			Subject:   b.subject(line.Hash),
+		})
+	}
+	return hunks, nil
+}
+
+// subject returns the first line of a commit's message. Callers must hold
+// b.mu.
+func (b *Blamer) subject(hash plumbing.Hash) string {
+	if s, ok := b.subjects[hash]; ok {
+		return s
+	}
+	var s string
+	if commit, err := b.repo.CommitObject(hash); err == nil {
+		s, _, _ = strings.Cut(strings.TrimSpace(commit.Message), "\n")
+	}
+	b.subjects[hash] = s
+	return s
+}
+
+// relPath returns path relative to the repository root, with forward
+// slashes as git uses
+func (b *Blamer) relPath(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		return filepath.ToSlash(filepath.Clean(path)), nil
+	}
+	rel, err := filepath.Rel(b.root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("%s is outside the repository at %s", path, b.root)
+	}
+	return filepath.ToSlash(rel), nil
+}
@@ -0,0 +1,87 @@
+package blame
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestLines(t *testing.T) {
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit := func(content, message, author string, when time.Time) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, "main.go"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := wt.Add("main.go"); err != nil {
+			t.Fatal(err)
+		}
+		sig := &object.Signature{Name: author, Email: author + "@example.com", When: when}
+		if _, err := wt.Commit(message, &git.CommitOptions{Author: sig, Committer: sig}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	commit("package main\n\nfunc main() {\n}\n", "Add main\n\nLonger body.", "alice", first)
+	commit("package main\n\nfunc main() {\n\trun()\n}\n", "Call run from main", "bob", first.Add(24*time.Hour))
+
+	if _, err := Open(t.TempDir()); err == nil {
+		t.Fatal("Open() outside a repository succeeded")
+	}
+	b, err := Open(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hunks, err := b.Lines(filepath.Join(root, "main.go"), 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hunks) != 3 {
+		t.Fatalf("Lines() = %+v, want 3 hunks", hunks)
+	}
+	if h := hunks[0]; h.StartLine != 3 || h.EndLine != 3 || h.Author != "alice" || h.Subject != "Add main" {
+		t.Errorf("hunk 0 = %+v", h)
+	}
+	if h := hunks[1]; h.StartLine != 4 || h.EndLine != 4 || h.Author != "bob" || h.Subject != "Call run from main" {
+		t.Errorf("hunk 1 = %+v", h)
+	}
+	if h := hunks[2]; h.StartLine != 5 || h.Author != "alice" || !h.Date.Equal(first) {
+		t.Errorf("hunk 2 = %+v", h)
+	}
+	if len(hunks[0].Commit) != 7 {
+		t.Errorf("commit = %q, want a short hash", hunks[0].Commit)
+	}
+	want := hunks[0].Commit + ` alice 2026-01-02 "Add main" (line 3)`
+	if got := hunks[0].String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	// Relative paths, clamping to the file and merging of runs
+	hunks, err = b.Lines("main.go", 0, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hunks) != 3 || hunks[0].StartLine != 1 || hunks[0].EndLine != 3 || hunks[2].EndLine != 5 {
+		t.Errorf("Lines(1, 100) = %+v", hunks)
+	}
+
+	if _, err := b.Lines("missing.go", 1, 1); err == nil {
+		t.Error("Lines() of an untracked file succeeded")
+	}
+	if _, err := b.Lines(filepath.Join(t.TempDir(), "main.go"), 1, 1); err == nil {
+		t.Error("Lines() outside the repository succeeded")
+	}
+}
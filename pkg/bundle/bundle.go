@@ -0,0 +1,340 @@
+// Package bundle assembles the units a search finds into one context
+// document for a language model: each unit's source, its direct callers and
+// callees, and the imports of its file, cut to fit a token budget.
+package bundle
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/l3aro/go-context-query/pkg/blame"
+	"github.com/l3aro/go-context-query/pkg/extractor"
+	"github.com/l3aro/go-context-query/pkg/search"
+	"github.com/l3aro/go-context-query/pkg/semantic"
+	"github.com/l3aro/go-context-query/pkg/tokens"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// maxRefs is the most callers or callees listed for one unit
+const maxRefs = 8
+
+// Options controls how a bundle is assembled
+type Options struct {
+	// Budget is the most tokens the document may take; 0 means no limit
+	Budget int
+	// Counter counts tokens; nil uses tokens.Count
+	Counter tokens.Counter
+	// Blamer, when set, adds who last changed each unit's lines and why;
+	// nil leaves blame out
+	Blamer *blame.Blamer
+}
+
+// Ref is a caller or callee of a bundled unit
+type Ref struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// Entry is a unit in the bundle
+type Entry struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Type      string  `json:"type"`
+	Language  string  `json:"language,omitempty"`
+	File      string  `json:"file"`
+	Line      int     `json:"line"`
+	EndLine   int     `json:"end_line,omitempty"`
+	Score     float32 `json:"score"`
+	Signature string  `json:"signature,omitempty"`
+	Docstring string  `json:"docstring,omitempty"`
+	// Source is the unit's source as included in the document
+	Source string `json:"source,omitempty"`
+	// Truncated reports that the source or references were cut to fit
+	// the budget
+	Truncated bool     `json:"truncated,omitempty"`
+	Imports   []string `json:"imports,omitempty"`
+	Callers   []Ref    `json:"callers,omitempty"`
+	Callees   []Ref    `json:"callees,omitempty"`
+	// Blame lists the commits that last changed the unit's lines
+	Blame []blame.Hunk `json:"blame,omitempty"`
+
+	// lines is the full source, Source may hold fewer
+	lines []string
+}
+
+// Bundle is a context document assembled for a query
+type Bundle struct {
+	Query   string  `json:"query"`
+	Budget  int     `json:"budget"`
+	Tokens  int     `json:"tokens"`
+	Entries []Entry `json:"entries"`
+	// Omitted is the number of units left out because the budget ran out
+	Omitted  int    `json:"omitted"`
+	Document string `json:"document"`
+}
+
+// Build assembles the bundle of hits, search results best first, for query.
+// graph supplies the units behind the hits and their callers and callees;
+// source and imports are read from the files under rootDir. Units are added
+// in rank order; one that doesn't fit the budget has its source cut, then
+// its references, and is left out when even its heading doesn't fit.
+func Build(rootDir, query string, hits []search.SearchResult, graph *semantic.CallerGraph, opts Options) *Bundle {
+	count := opts.Counter
+	if count == nil {
+		count = tokens.Count
+	}
+	fits := func(doc string) bool {
+		return opts.Budget <= 0 || count(doc) <= opts.Budget
+	}
+
+	b := &Bundle{Query: query, Budget: opts.Budget}
+	doc := fmt.Sprintf("# Context for %q\n", query)
+	files := make(map[string]*sourceFile)
+	seen := make(map[string]bool)
+	for _, hit := range hits {
+		unit := resolve(graph, hit)
+		key := hit.FilePath + ":" + hit.Name
+		if unit != nil {
+			key = unit.ID
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		file := files[hit.FilePath]
+		if file == nil {
+			file = loadSourceFile(rootDir, hit.FilePath)
+			files[hit.FilePath] = file
+		}
+		entry := newEntry(hit, unit, graph, file)
+		if opts.Blamer != nil {
+			// Files outside the repository or not committed have no blame
+			entry.Blame, _ = opts.Blamer.Lines(filepath.Join(rootDir, entry.File), entry.Line, entry.EndLine)
+		}
+		if !file.importsShown {
+			entry.Imports = file.imports
+		}
+
+		rank := len(b.Entries) + 1
+		block, ok := fit(&entry, rank, func(block string) bool { return fits(doc + block) })
+		if !ok {
+			b.Omitted++
+			continue
+		}
+		doc += block
+		file.importsShown = file.importsShown || len(entry.Imports) > 0
+		b.Entries = append(b.Entries, entry)
+	}
+	if b.Omitted > 0 {
+		note := fmt.Sprintf("\n_%d more units matched but did not fit the token budget._\n", b.Omitted)
+		if fits(doc + note) {
+			doc += note
+		}
+	}
+
+	b.Document = doc
+	b.Tokens = count(doc)
+	return b
+}
+
+// fit renders entry at rank, cutting it until fits accepts the block:
+// first the source, line by line from the end, then the references,
+// imports and blame. It reports false when not even the heading fits.
+func fit(entry *Entry, rank int, fits func(string) bool) (string, bool) {
+	total := len(entry.lines)
+	render := func(lines int) string {
+		entry.Source = strings.Join(entry.lines[:lines], "\n")
+		entry.Truncated = lines < total
+		return renderEntry(*entry, rank, total-lines)
+	}
+
+	if block := render(total); fits(block) {
+		return block, true
+	}
+	// The most source lines that fit, by binary search
+	lo, hi := 0, total-1
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if fits(render(mid)) {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	if block := render(lo); fits(block) {
+		return block, true
+	}
+
+	entry.Imports, entry.Callers, entry.Callees, entry.Blame = nil, nil, nil, nil
+	if block := render(0); fits(block) {
+		entry.Truncated = true
+		return block, true
+	}
+	return "", false
+}
+
+// renderEntry renders entry as a Markdown section; omitted is the number of
+// source lines cut from the end
+func renderEntry(e Entry, rank, omitted int) string {
+	var sb strings.Builder
+	location := fmt.Sprintf("%s:%d", e.File, e.Line)
+	if e.EndLine > e.Line {
+		location += fmt.Sprintf("-%d", e.EndLine)
+	}
+	fmt.Fprintf(&sb, "\n## %d. %s (%s) `%s`\n\n", rank, e.Name, e.Type, location)
+
+	if e.Source == "" {
+		if e.Signature != "" {
+			fmt.Fprintf(&sb, "`%s`\n\n", e.Signature)
+		}
+		if e.Docstring != "" {
+			sb.WriteString(e.Docstring + "\n\n")
+		}
+	}
+	if len(e.Imports) > 0 {
+		fmt.Fprintf(&sb, "Imports: %s\n", strings.Join(e.Imports, "; "))
+	}
+	if len(e.Callers) > 0 {
+		fmt.Fprintf(&sb, "Called by: %s\n", renderRefs(e.Callers))
+	}
+	if len(e.Callees) > 0 {
+		fmt.Fprintf(&sb, "Calls: %s\n", renderRefs(e.Callees))
+	}
+	for _, h := range e.Blame {
+		fmt.Fprintf(&sb, "Blame: %s\n", h)
+	}
+	if len(e.Imports)+len(e.Callers)+len(e.Callees)+len(e.Blame) > 0 {
+		sb.WriteString("\n")
+	}
+
+	switch {
+	case e.Source != "":
+		fmt.Fprintf(&sb, "```%s\n%s\n```\n", e.Language, e.Source)
+		if omitted > 0 {
+			fmt.Fprintf(&sb, "_… %d more lines_\n", omitted)
+		}
+	case omitted > 0:
+		fmt.Fprintf(&sb, "_%d lines of source left out_\n", omitted)
+	}
+	return sb.String()
+}
+
+func renderRefs(refs []Ref) string {
+	parts := make([]string, len(refs))
+	for i, r := range refs {
+		parts[i] = fmt.Sprintf("%s (%s:%d)", r.Name, r.File, r.Line)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// resolve returns the indexed unit behind hit. Body chunks resolve to the
+// function or method they belong to.
+func resolve(graph *semantic.CallerGraph, hit search.SearchResult) *types.CodeUnit {
+	if graph == nil {
+		return nil
+	}
+	if hit.URI != "" {
+		if units := graph.Find(hit.URI, ""); len(units) > 0 {
+			return units[0]
+		}
+	}
+	for _, unit := range graph.Find(hit.Name, hit.FilePath) {
+		if unit.FilePath == hit.FilePath && unit.Name == hit.Name {
+			return unit
+		}
+	}
+	return nil
+}
+
+// newEntry returns the entry of hit with its source, callers and callees
+func newEntry(hit search.SearchResult, unit *types.CodeUnit, graph *semantic.CallerGraph, file *sourceFile) Entry {
+	e := Entry{
+		ID:        hit.URI,
+		Name:      hit.Name,
+		Type:      hit.Type,
+		Language:  hit.Language,
+		File:      hit.FilePath,
+		Line:      hit.LineNumber,
+		EndLine:   hit.EndLine,
+		Score:     hit.Score,
+		Signature: hit.Signature,
+		Docstring: hit.Docstring,
+	}
+	if unit != nil {
+		// A body chunk stands for its whole function
+		e.ID, e.Name, e.Type = unit.ID, unit.Name, unit.Type
+		e.Line, e.EndLine = unit.LineNumber, unit.EndLine
+		if unit.Language != "" {
+			e.Language = unit.Language
+		}
+		if e.Signature == "" {
+			e.Signature, e.Docstring = unit.Signature, unit.Docstring
+		}
+
+		for _, c := range graph.Callers([]*types.CodeUnit{unit}, 1) {
+			e.Callers = append(e.Callers, Ref{Name: c.Name, File: c.File, Line: c.Line})
+		}
+		for _, c := range graph.Callees(unit) {
+			e.Callees = append(e.Callees, Ref{Name: c.Name, File: c.FilePath, Line: c.LineNumber})
+		}
+		e.Callers = e.Callers[:min(len(e.Callers), maxRefs)]
+		e.Callees = e.Callees[:min(len(e.Callees), maxRefs)]
+	}
+
+	if e.Type == "function" || e.Type == "method" || e.Type == semantic.UnitTypeChunk || e.Type == semantic.UnitTypeDoc {
+		if e.EndLine == 0 {
+			e.EndLine = file.extents[e.Line]
+		}
+		e.lines = file.span(e.Line, e.EndLine)
+	}
+	return e
+}
+
+// sourceFile is a file some bundled units come from
+type sourceFile struct {
+	lines []string
+	// extents maps the first line of each function to its last
+	extents      map[int]int
+	imports      []string
+	importsShown bool
+}
+
+// loadSourceFile reads rel under rootDir; a file that can't be read gives
+// units without source
+func loadSourceFile(rootDir, rel string) *sourceFile {
+	path := filepath.Join(rootDir, rel)
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return &sourceFile{}
+	}
+	f := &sourceFile{
+		lines:   strings.Split(string(source), "\n"),
+		extents: semantic.BodyExtents(path, source),
+	}
+	if moduleInfo, err := extractor.ExtractFile(path); err == nil {
+		for _, imp := range moduleInfo.Imports {
+			f.imports = append(f.imports, formatImport(imp))
+		}
+	}
+	return f
+}
+
+// span returns lines start to end, counted from 1; nil when the range is
+// unknown or out of the file
+func (f *sourceFile) span(start, end int) []string {
+	if start < 1 || end < start || end > len(f.lines) {
+		return nil
+	}
+	return f.lines[start-1 : end]
+}
+
+func formatImport(imp types.Import) string {
+	if len(imp.Names) == 0 {
+		return imp.Module
+	}
+	return fmt.Sprintf("%s (%s)", imp.Module, strings.Join(imp.Names, ", "))
+}
@@ -0,0 +1,147 @@
+package bundle
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/l3aro/go-context-query/pkg/blame"
+	"github.com/l3aro/go-context-query/pkg/search"
+	"github.com/l3aro/go-context-query/pkg/semantic"
+)
+
+const testSource = `import os
+from typing import List
+
+
+def load(path):
+    """Load and parse a file."""
+    return parse(open(path).read())
+
+
+def parse(text):
+    lines = []
+    for line in text.split("\n"):
+        line = line.strip()
+        if line and not line.startswith("#"):
+            lines.append(line)
+    return lines
+`
+
+func testBundleInput(t *testing.T) (string, []search.SearchResult, *semantic.CallerGraph) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "m.py"), []byte(testSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	units := []*semantic.CodeUnit{
+		{ID: "py://m.py#load", Name: "load", Type: "function", Language: "python", FilePath: "m.py", LineNumber: 5, EndLine: 7,
+			Calls: []string{"m.py:parse"}},
+		{ID: "py://m.py#parse", Name: "parse", Type: "function", Language: "python", FilePath: "m.py", LineNumber: 10},
+	}
+	hits := []search.SearchResult{
+		{URI: "py://m.py#parse", Name: "parse", Type: "function", FilePath: "m.py", LineNumber: 10, Score: 0.9},
+		// A body chunk of parse adds nothing new
+		{URI: "py://m.py#parse@12-15", Name: "parse", Type: semantic.UnitTypeChunk, FilePath: "m.py", LineNumber: 12, EndLine: 15, Score: 0.8},
+		{URI: "py://m.py#load", Name: "load", Type: "function", FilePath: "m.py", LineNumber: 5, Score: 0.7},
+	}
+	return dir, hits, semantic.NewCallerGraph(units)
+}
+
+func TestBuild(t *testing.T) {
+	dir, hits, graph := testBundleInput(t)
+
+	b := Build(dir, "parse lines", hits, graph, Options{})
+	if len(b.Entries) != 2 || b.Entries[0].Name != "parse" || b.Entries[1].Name != "load" {
+		t.Fatalf("entries = %+v", b.Entries)
+	}
+	parse, load := b.Entries[0], b.Entries[1]
+	// parse has no EndLine in the index; its body is found by parsing
+	if parse.EndLine != 16 || !strings.HasSuffix(parse.Source, "    return lines") {
+		t.Errorf("parse source (to line %d) = %q", parse.EndLine, parse.Source)
+	}
+	if len(parse.Callers) != 1 || parse.Callers[0].Name != "load" || parse.Callers[0].Line != 5 {
+		t.Errorf("parse callers = %+v", parse.Callers)
+	}
+	if len(load.Callees) != 1 || load.Callees[0].Name != "parse" {
+		t.Errorf("load callees = %+v", load.Callees)
+	}
+	// Imports are listed once per file
+	if len(parse.Imports) != 2 || load.Imports != nil {
+		t.Errorf("imports = %v, %v", parse.Imports, load.Imports)
+	}
+
+	for _, want := range []string{
+		`# Context for "parse lines"`,
+		"## 1. parse (function) `m.py:10-16`",
+		"Called by: load (m.py:5)",
+		"Calls: parse (m.py:10)",
+		"```python\ndef parse(text):",
+	} {
+		if !strings.Contains(b.Document, want) {
+			t.Errorf("document is missing %q:\n%s", want, b.Document)
+		}
+	}
+	if b.Omitted != 0 || b.Tokens == 0 {
+		t.Errorf("omitted %d, tokens %d", b.Omitted, b.Tokens)
+	}
+}
+
+func TestBuildBudget(t *testing.T) {
+	dir, hits, graph := testBundleInput(t)
+	full := Build(dir, "parse lines", hits, graph, Options{})
+
+	// Room for the first unit with part of its source only
+	b := Build(dir, "parse lines", hits, graph, Options{Budget: full.Tokens / 2})
+	if b.Tokens > b.Budget {
+		t.Errorf("document takes %d tokens, over the budget of %d", b.Tokens, b.Budget)
+	}
+	if len(b.Entries) == 0 || !b.Entries[0].Truncated || !strings.Contains(b.Document, "more lines_") {
+		t.Errorf("expected the first unit cut to fit:\n%s", b.Document)
+	}
+
+	// A counter charging a token a character leaves no room for any unit
+	tiny := Build(dir, "parse lines", hits, graph, Options{Budget: 30, Counter: func(s string) int { return len(s) }})
+	if len(tiny.Entries) != 0 || tiny.Omitted != 2 {
+		t.Errorf("entries %d, omitted %d", len(tiny.Entries), tiny.Omitted)
+	}
+}
+
+func TestBuildBlame(t *testing.T) {
+	dir, hits, graph := testBundleInput(t)
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.Add("m.py"); err != nil {
+		t.Fatal(err)
+	}
+	sig := &object.Signature{Name: "alice", Email: "alice@example.com", When: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)}
+	if _, err := wt.Commit("Add the parser", &git.CommitOptions{Author: sig, Committer: sig}); err != nil {
+		t.Fatal(err)
+	}
+	blamer, err := blame.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := Build(dir, "parse lines", hits, graph, Options{Blamer: blamer})
+	if len(b.Entries) != 2 {
+		t.Fatalf("entries = %+v", b.Entries)
+	}
+	parse := b.Entries[0]
+	if len(parse.Blame) != 1 || parse.Blame[0].StartLine != 10 || parse.Blame[0].EndLine != 16 || parse.Blame[0].Author != "alice" {
+		t.Errorf("parse blame = %+v", parse.Blame)
+	}
+	if want := `alice 2026-01-02 "Add the parser" (lines 10-16)`; !strings.Contains(b.Document, want) {
+		t.Errorf("document is missing %q:\n%s", want, b.Document)
+	}
+}
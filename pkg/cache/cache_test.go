@@ -228,6 +228,20 @@ func TestEmbeddingStore_Persistence(t *testing.T) {
 	assert.Equal(t, vector, retrieved)
 }
 
+func TestEmbeddingStore_LoadIgnoresOtherModel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "embeddings.cache")
+
+	ec := NewEmbeddingStore(EmbeddingCacheOptions{MaxEmbeddings: 10, Model: "old-model"}, path)
+	ec.Set("hash1", Embedding{1, 2, 3})
+	require.NoError(t, ec.Save())
+
+	ec2 := NewEmbeddingStore(EmbeddingCacheOptions{MaxEmbeddings: 10, Model: "new-model"}, path)
+	require.NoError(t, ec2.Load())
+
+	_, found := ec2.Get("hash1")
+	assert.False(t, found)
+}
+
 func TestEmbeddingCacheWithMetrics(t *testing.T) {
 	ecm := NewEmbeddingCacheWithMetrics(EmbeddingCacheOptions{
 		MaxEmbeddings: 10,
@@ -301,6 +301,12 @@ func (es *EmbeddingStore) loadFromFile(r io.Reader) error {
 		return fmt.Errorf("failed to decode store: %w", err)
 	}
 
+	// Vectors from another model are meaningless (and often a different
+	// dimension), so a store saved for a different model starts empty
+	if data.Model != "" && es.cache.model != "" && data.Model != es.cache.model {
+		return nil
+	}
+
 	for hash, entry := range data.Entries {
 		es.cache.Set(hash, entry.Vector)
 	}
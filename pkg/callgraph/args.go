@@ -0,0 +1,142 @@
+package callgraph
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// MaxArgLength bounds the bytes of source text kept for each call argument
+const MaxArgLength = 80
+
+// callArguments returns the source text of each argument of a call node,
+// whitespace collapsed and cut to MaxArgLength bytes. It returns nil for
+// calls without arguments.
+func (b *Builder) callArguments(node *sitter.Node, content []byte) []string {
+	argsNode := node.ChildByFieldName("arguments")
+	if argsNode == nil {
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			if child := node.NamedChild(i); child != nil && (child.Type() == "argument_list" || child.Type() == "arguments") {
+				argsNode = child
+				break
+			}
+		}
+	}
+	if argsNode == nil {
+		return nil
+	}
+	if argsNode.Type() == "generator_expression" {
+		// Python's f(x for x in xs) has the generator as its only argument
+		return []string{argText(b.nodeText(argsNode, content))}
+	}
+
+	var args []string
+	for i := 0; i < int(argsNode.NamedChildCount()); i++ {
+		child := argsNode.NamedChild(i)
+		if child == nil || strings.Contains(child.Type(), "comment") {
+			continue
+		}
+		args = append(args, argText(b.nodeText(child, content)))
+	}
+	return args
+}
+
+// argText collapses the whitespace of an argument expression and bounds its
+// length
+func argText(text string) string {
+	return types.TruncateUTF8(strings.Join(strings.Fields(text), " "), MaxArgLength)
+}
+
+// EdgeFilter selects call graph edges by callee and call site arguments.
+// Empty fields match every edge.
+type EdgeFilter struct {
+	// Callee keeps edges to functions with this name or qualified name
+	// ("load", "Config.load")
+	Callee string
+	// Arg keeps edges with an argument containing this text
+	Arg string
+	// Literal keeps edges with at least one literal argument, such as a
+	// hardcoded string or number
+	Literal bool
+}
+
+// Match reports whether edge passes the filter
+func (f EdgeFilter) Match(edge types.CallGraphEdge) bool {
+	if f.Callee != "" && edge.DestFunc != f.Callee && !strings.HasSuffix(edge.DestFunc, "."+f.Callee) {
+		return false
+	}
+	if f.Arg != "" && !containsArg(edge.Args, func(arg string) bool { return strings.Contains(arg, f.Arg) }) {
+		return false
+	}
+	if f.Literal && !containsArg(edge.Args, IsLiteralArg) {
+		return false
+	}
+	return true
+}
+
+// FilterEdges returns the edges matching f
+func FilterEdges(edges []types.CallGraphEdge, f EdgeFilter) []types.CallGraphEdge {
+	if f == (EdgeFilter{}) {
+		return edges
+	}
+	var kept []types.CallGraphEdge
+	for _, edge := range edges {
+		if f.Match(edge) {
+			kept = append(kept, edge)
+		}
+	}
+	return kept
+}
+
+func containsArg(args []string, match func(string) bool) bool {
+	for _, arg := range args {
+		if match(arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// literalKeywords are the literal constants of the supported languages
+var literalKeywords = map[string]bool{
+	"True": true, "False": true, "None": true,
+	"true": true, "false": true, "null": true, "nil": true, "undefined": true,
+}
+
+// IsLiteralArg reports whether a call argument is a literal: a string,
+// number or constant such as true or None. The value of a keyword or named
+// argument (path="a.yaml", path: "a.yaml") is checked.
+func IsLiteralArg(arg string) bool {
+	arg = strings.TrimSpace(keywordValue(arg))
+	if arg == "" {
+		return false
+	}
+	if literalKeywords[arg] {
+		return true
+	}
+	// String prefixes: Python r"", b"", f"" and PHP/Go-style quoting
+	value := strings.TrimLeft(arg, "rbfuRBFU")
+	if value != "" && len(arg)-len(value) <= 2 && strings.ContainsRune("\"'`", rune(value[0])) {
+		return true
+	}
+	value = strings.TrimPrefix(arg, "-")
+	return value != "" && unicode.IsDigit(rune(value[0]))
+}
+
+// keywordValue returns the value of a keyword or named argument, or arg
+// itself for positional arguments
+func keywordValue(arg string) string {
+	i := strings.IndexAny(arg, "=:")
+	if i <= 0 || strings.HasPrefix(arg[i:], "==") || strings.HasPrefix(arg[i:], "::") {
+		return arg
+	}
+	name := strings.TrimSpace(arg[:i])
+	for _, r := range name {
+		if r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return arg
+		}
+	}
+	return arg[i+1:]
+}
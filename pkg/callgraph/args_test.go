@@ -0,0 +1,118 @@
+package callgraph
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/extractor"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+func TestCallArguments(t *testing.T) {
+	long := strings.Repeat("x", 200)
+	tests := []struct {
+		name string
+		lang extractor.Language
+		file string
+		code string
+		want [][]string
+	}{
+		{
+			name: "python",
+			lang: extractor.Python,
+			file: "app.py",
+			code: "def main():\n    load_config(\"/etc/app.yaml\", strict=True)\n    load_config(path)\n    run()\n",
+			want: [][]string{{`"/etc/app.yaml"`, "strict=True"}, {"path"}, nil},
+		},
+		{
+			name: "go",
+			lang: extractor.Go,
+			file: "main.go",
+			code: "package main\n\nfunc main() {\n\tLoad(\"app.yaml\",\n\t\t3)\n\tLoad(\"" + long + "\")\n}\n",
+			want: [][]string{{`"app.yaml"`, "3"}, {types.TruncateUTF8(`"`+long+`"`, MaxArgLength)}},
+		},
+		{
+			name: "typescript",
+			lang: extractor.TypeScript,
+			file: "main.ts",
+			code: "function main() {\n  loadConfig('./app.json', { watch: true });\n  new Client(url);\n}\n",
+			want: [][]string{{"'./app.json'", "{ watch: true }"}, {"url"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph, err := NewBuilderForLanguage(tt.lang).BuildFromBytes([]byte(tt.code), tt.file, &types.ModuleInfo{Path: tt.file})
+			if err != nil {
+				t.Fatalf("BuildFromBytes() failed: %v", err)
+			}
+			calls := graph.GetCalls("main")
+			if len(calls) != len(tt.want) {
+				t.Fatalf("calls = %+v, want %d", calls, len(tt.want))
+			}
+			for i, call := range calls {
+				if !slices.Equal(call.Args, tt.want[i]) {
+					t.Errorf("%s args = %q, want %q", call.Name, call.Args, tt.want[i])
+				}
+			}
+			for _, edge := range graph.ToCallGraph().Edges {
+				if edge.Line == 0 {
+					t.Errorf("edge %+v has no line", edge)
+				}
+			}
+		})
+	}
+}
+
+func TestIsLiteralArg(t *testing.T) {
+	tests := map[string]bool{
+		`"/etc/app.yaml"`:       true,
+		`'app.json'`:            true,
+		"`tmpl`":                true,
+		`r"C:\path"`:            true,
+		`path="/etc/app.yaml"`:  true,
+		`path: "app.yaml"`:      true,
+		"42":                    true,
+		"-1.5":                  true,
+		"None":                  true,
+		"nil":                   true,
+		"path":                  false,
+		"buf":                   false,
+		"os.Getenv(\"CONFIG\")": false,
+		`path=cfg_path`:         false,
+		`a == "x"`:              false,
+		"-x":                    false,
+	}
+	for arg, want := range tests {
+		if got := IsLiteralArg(arg); got != want {
+			t.Errorf("IsLiteralArg(%q) = %v, want %v", arg, got, want)
+		}
+	}
+}
+
+func TestFilterEdges(t *testing.T) {
+	edges := []types.CallGraphEdge{
+		{SourceFunc: "main", DestFunc: "load_config", Args: []string{`"/etc/app.yaml"`}},
+		{SourceFunc: "reload", DestFunc: "load_config", Args: []string{"path"}},
+		{SourceFunc: "run", DestFunc: "Config.Load", Args: []string{`"app.yaml"`}},
+		{SourceFunc: "main", DestFunc: "print", Args: []string{`"/etc/app.yaml"`}},
+	}
+	callers := func(f EdgeFilter) []string {
+		var names []string
+		for _, e := range FilterEdges(edges, f) {
+			names = append(names, e.SourceFunc+"->"+e.DestFunc)
+		}
+		return names
+	}
+
+	if got := callers(EdgeFilter{Callee: "load_config", Literal: true}); !slices.Equal(got, []string{"main->load_config"}) {
+		t.Errorf("literal load_config calls = %v", got)
+	}
+	if got := callers(EdgeFilter{Callee: "Load", Arg: ".yaml"}); !slices.Equal(got, []string{"run->Config.Load"}) {
+		t.Errorf("Load calls with .yaml = %v", got)
+	}
+	if got := FilterEdges(edges, EdgeFilter{}); len(got) != len(edges) {
+		t.Errorf("empty filter kept %d of %d edges", len(got), len(edges))
+	}
+}
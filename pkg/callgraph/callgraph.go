@@ -7,17 +7,22 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/l3aro/go-context-query/pkg/extractor"
 	"github.com/l3aro/go-context-query/pkg/types"
 	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/golang"
+	"github.com/smacker/go-tree-sitter/php"
 	"github.com/smacker/go-tree-sitter/python"
+	"github.com/smacker/go-tree-sitter/typescript/typescript"
 )
 
 // languageNodeTypes defines the node type names for each language
 type languageNodeTypes struct {
 	functionDef string
 	classDef    string
+	classBody   string
 	block       string
 	call        string
 	identifier  string
@@ -32,16 +37,27 @@ func nodeTypesByLanguage(lang extractor.Language) languageNodeTypes {
 		return languageNodeTypes{
 			functionDef: "function_definition",
 			classDef:    "class_declaration",
+			classBody:   "declaration_list",
 			block:       "compound_statement",
 			call:        "function_call_expression",
 			identifier:  "name",
 			methodDef:   "method_declaration",
 			methodCall:  "member_call_expression",
 		}
+	case extractor.Go:
+		return languageNodeTypes{
+			functionDef: "function_declaration",
+			block:       "block",
+			call:        "call_expression",
+			identifier:  "identifier",
+			methodDef:   "method_declaration",
+			methodCall:  "call_expression",
+		}
 	default:
 		return languageNodeTypes{
 			functionDef: "function_definition",
 			classDef:    "class_definition",
+			classBody:   "block",
 			block:       "block",
 			call:        "call",
 			identifier:  "identifier",
@@ -79,6 +95,11 @@ type CalledFunction struct {
 	LineNumber int `json:"line_number"`
 	// IsAttribute indicates if this is an attribute/method access
 	IsAttribute bool `json:"is_attribute"`
+	// Receiver is the receiver type of a Go method call when it could be
+	// inferred (e.g., "VectorIndex" for s.Save() with s *VectorIndex)
+	Receiver string `json:"receiver,omitempty"`
+	// Args holds the source text of the call's arguments (see MaxArgLength)
+	Args []string `json:"args,omitempty"`
 }
 
 // CallGraphEntry represents all calls from a single caller function
@@ -113,9 +134,10 @@ func NewIntraFileCallGraph(filePath string) *IntraFileCallGraph {
 	}
 }
 
-// Builder builds intra-file call graphs using tree-sitter parsing
+// Builder builds intra-file call graphs using tree-sitter parsing. It
+// parses with pooled parsers and building a graph does not change it, so
+// one builder can serve several goroutines at once.
 type Builder struct {
-	parser    *sitter.Parser
 	language  extractor.Language
 	nodeTypes languageNodeTypes
 }
@@ -127,17 +149,7 @@ func NewBuilder() *Builder {
 
 // NewBuilderForLanguage creates a new call graph builder for the specified language
 func NewBuilderForLanguage(lang extractor.Language) *Builder {
-	parser := sitter.NewParser()
-
-	switch lang {
-	case extractor.Python:
-		parser.SetLanguage(python.GetLanguage())
-	default:
-		parser.SetLanguage(python.GetLanguage())
-	}
-
 	return &Builder{
-		parser:    parser,
 		language:  lang,
 		nodeTypes: nodeTypesByLanguage(lang),
 	}
@@ -147,15 +159,52 @@ func NewBuilderForLanguage(lang extractor.Language) *Builder {
 func (b *Builder) SetLanguage(lang extractor.Language) {
 	b.language = lang
 	b.nodeTypes = nodeTypesByLanguage(lang)
+}
+
+// parserPools holds a *sync.Pool of parsers per grammar, keyed by its
+// *sitter.Language
+var parserPools sync.Map
+
+// parse parses content with a parser for grammar borrowed from its pool.
+// The tree outlives the parser's return to the pool.
+func parse(grammar *sitter.Language, content []byte) *sitter.Tree {
+	pool, ok := parserPools.Load(grammar)
+	if !ok {
+		pool, _ = parserPools.LoadOrStore(grammar, &sync.Pool{New: func() any {
+			parser := sitter.NewParser()
+			parser.SetLanguage(grammar)
+			return parser
+		}})
+	}
+	parser := pool.(*sync.Pool).Get().(*sitter.Parser)
+	defer pool.(*sync.Pool).Put(parser)
+	return parser.Parse(nil, content)
+}
 
-	parser := sitter.NewParser()
+// grammarFor returns the tree-sitter grammar used to parse the given language
+func grammarFor(lang extractor.Language) *sitter.Language {
 	switch lang {
-	case extractor.Python:
-		parser.SetLanguage(python.GetLanguage())
+	case extractor.Go:
+		return golang.GetLanguage()
+	case extractor.TypeScript, extractor.JavaScript, extractor.Vue, extractor.Svelte:
+		return typescript.GetLanguage()
+	case extractor.PHP:
+		return php.GetLanguage()
 	default:
-		parser.SetLanguage(python.GetLanguage())
+		return python.GetLanguage()
 	}
-	b.parser = parser
+}
+
+// hasOwnBuilder reports whether lang has a dedicated call graph builder
+// rather than the Python-style one
+func hasOwnBuilder(lang extractor.Language) bool {
+	return lang == extractor.Go || isESLanguage(lang)
+}
+
+// hasOwnGrammar reports whether lang is parsed with a grammar other than
+// Python's
+func hasOwnGrammar(lang extractor.Language) bool {
+	return hasOwnBuilder(lang) || lang == extractor.PHP
 }
 
 // BuildFromFile builds a call graph by analyzing a source file
@@ -168,8 +217,24 @@ func (b *Builder) BuildFromFile(filePath string, moduleInfo *types.ModuleInfo) (
 	return b.BuildFromBytes(content, filePath, moduleInfo)
 }
 
-// BuildFromBytes builds a call graph from source code bytes
+// BuildFromBytes builds a call graph from source code bytes.
+// Go, TypeScript, JavaScript and PHP files are always parsed with their own
+// grammar, by a builder for their language in place of b, so one builder
+// can serve a mixed-language project. Only the script blocks of Vue and
+// Svelte components are parsed.
 func (b *Builder) BuildFromBytes(content []byte, filePath string, moduleInfo *types.ModuleInfo) (*IntraFileCallGraph, error) {
+	content = extractor.ScriptSource(filePath, content)
+	if lang, err := extractor.GetLanguageRegistry().GetLanguage(filePath); err == nil && lang != b.language &&
+		(hasOwnGrammar(lang) || hasOwnGrammar(b.language)) {
+		b = NewBuilderForLanguage(lang)
+	}
+	if b.language == extractor.Go {
+		return b.buildGoFromBytes(content, filePath, moduleInfo)
+	}
+	if isESLanguage(b.language) {
+		return b.buildESFromBytes(content, filePath, moduleInfo)
+	}
+
 	graph := NewIntraFileCallGraph(filePath)
 
 	// Index local functions
@@ -181,6 +246,11 @@ func (b *Builder) BuildFromBytes(content []byte, filePath string, moduleInfo *ty
 			graph.LocalFunctions[method.Name] = true
 		}
 	}
+	for _, trait := range moduleInfo.Traits {
+		for _, method := range trait.Methods {
+			graph.LocalFunctions[method.Name] = true
+		}
+	}
 
 	// Index imports
 	for _, imp := range moduleInfo.Imports {
@@ -200,7 +270,7 @@ func (b *Builder) BuildFromBytes(content []byte, filePath string, moduleInfo *ty
 	}
 
 	// Parse the AST
-	tree := b.parser.Parse(nil, content)
+	tree := parse(grammarFor(b.language), content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -223,7 +293,7 @@ func (b *Builder) walkForCallGraph(node *sitter.Node, content []byte, graph *Int
 	nodeType := node.Type()
 
 	switch nodeType {
-	case b.nodeTypes.functionDef:
+	case b.nodeTypes.functionDef, b.nodeTypes.methodDef:
 		fn := b.parseFunctionForCallGraph(node, content)
 		if fn != nil {
 			graph.Entries[fn.Caller] = fn
@@ -238,7 +308,7 @@ func (b *Builder) walkForCallGraph(node *sitter.Node, content []byte, graph *Int
 	case b.nodeTypes.classDef:
 		for i := 0; i < int(node.ChildCount()); i++ {
 			child := node.Child(i)
-			if child != nil && child.Type() == b.nodeTypes.block {
+			if child != nil && child.Type() == b.nodeTypes.classBody {
 				for j := 0; j < int(child.ChildCount()); j++ {
 					b.walkForCallGraph(child.Child(j), content, graph, nil)
 				}
@@ -249,6 +319,7 @@ func (b *Builder) walkForCallGraph(node *sitter.Node, content []byte, graph *Int
 		if currentFunction != nil {
 			calledFn := b.extractCall(node, content, graph)
 			if calledFn != nil {
+				calledFn.Args = b.callArguments(node, content)
 				currentFunction.Calls = append(currentFunction.Calls, *calledFn)
 			}
 		}
@@ -261,7 +332,7 @@ func (b *Builder) walkForCallGraph(node *sitter.Node, content []byte, graph *Int
 
 // parseFunctionForCallGraph extracts function name and creates an entry
 func (b *Builder) parseFunctionForCallGraph(node *sitter.Node, content []byte) *CallGraphEntry {
-	if node == nil || node.Type() != b.nodeTypes.functionDef {
+	if node == nil || (node.Type() != b.nodeTypes.functionDef && node.Type() != b.nodeTypes.methodDef) {
 		return nil
 	}
 
@@ -404,6 +475,10 @@ func (b *Builder) extractMethodCall(node *sitter.Node, content []byte, graph *In
 
 	fullName := base + "->" + methodName
 	callType := b.determineCallType(methodName, graph)
+	if base == "$this" && callType == LocalCall {
+		// A method of the same class, keyed by name like its own entry
+		fullName = methodName
+	}
 
 	return &CalledFunction{
 		Name:        fullName,
@@ -620,12 +695,7 @@ func (b *Builder) nodeText(node *sitter.Node, content []byte) string {
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
 
 // GetCalls returns all calls made by a specific function
@@ -684,6 +754,8 @@ func (g *IntraFileCallGraph) ToCallGraph() types.CallGraph {
 				SourceFunc: callerName,
 				DestFile:   g.FilePath,
 				DestFunc:   call.Name,
+				Line:       call.LineNumber,
+				Args:       call.Args,
 			}
 			edges = append(edges, edge)
 		}
@@ -3,6 +3,8 @@ package callgraph
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"sync"
 	"testing"
 
 	"github.com/l3aro/go-context-query/pkg/extractor"
@@ -666,3 +668,113 @@ def main():
 		}
 	}
 }
+
+// TestPHPProjectCallGraph tests that PHP functions and methods are linked
+// across files, including $this->method() calls
+func TestPHPProjectCallGraph(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Invoice.php": `<?php
+namespace App;
+
+use function App\Support\format_money;
+
+class Invoice
+{
+    public function total(): int
+    {
+        return $this->sum([1, 2]);
+    }
+
+    private function sum(array $lines): int
+    {
+        return 0;
+    }
+}
+
+function format_invoice(Invoice $invoice): string
+{
+    return format_money($invoice->total(), "EUR");
+}
+`,
+		"Support.php": `<?php
+namespace App\Support;
+
+function format_money(int $cents, string $currency): string
+{
+    return "";
+}
+`,
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	graph, err := BuildProjectCallGraph(dir, extractor.NewPHPExtractor())
+	if err != nil {
+		t.Fatalf("BuildProjectCallGraph failed: %v", err)
+	}
+
+	find := func(edges []types.CallGraphEdge, src, dst string) *types.CallGraphEdge {
+		for i, e := range edges {
+			if e.SourceFunc == src && e.DestFunc == dst {
+				return &edges[i]
+			}
+		}
+		return nil
+	}
+	if find(graph.IntraFileEdges, "total", "sum") == nil {
+		t.Errorf("missing total -> sum in %+v", graph.IntraFileEdges)
+	}
+	edge := find(graph.CrossFileEdges, "format_invoice", "format_money")
+	if edge == nil {
+		t.Fatalf("missing format_invoice -> format_money in %+v", graph.CrossFileEdges)
+	}
+	if edge.DestFile != "Support.php" || !slices.Equal(edge.Args, []string{"$invoice->total()", `"EUR"`}) {
+		t.Errorf("edge = %+v", edge)
+	}
+}
+
+// TestBuilderConcurrentLanguages tests that one builder builds files of
+// several languages on several goroutines at once without changing
+func TestBuilderConcurrentLanguages(t *testing.T) {
+	sources := []struct {
+		file, code, caller, callee string
+	}{
+		{"a.py", "def first():\n    pass\n\ndef second():\n    first()\n", "second", "first"},
+		{"b.go", "package b\n\nfunc First() {}\n\nfunc Second() { First() }\n", "Second", "First"},
+		{"c.ts", "function first() {}\n\nfunction second() { first(); }\n", "second", "first"},
+		{"d.php", "<?php\nfunction first() {}\n\nfunction second() { first(); }\n", "second", "first"},
+	}
+	builder := NewBuilder()
+
+	var wg sync.WaitGroup
+	errs := make(chan string, 8*len(sources))
+	for range 8 {
+		for _, src := range sources {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				graph, err := builder.BuildFromBytes([]byte(src.code), src.file, &types.ModuleInfo{Path: src.file})
+				if err != nil {
+					errs <- err.Error()
+					return
+				}
+				entry := graph.Entries[src.caller]
+				if entry == nil || !slices.ContainsFunc(entry.Calls, func(c CalledFunction) bool { return c.Name == src.callee }) {
+					errs <- src.file + ": " + src.caller + " does not call " + src.callee
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if builder.language != extractor.Python {
+		t.Errorf("builder language = %s after building other languages, want %s", builder.language, extractor.Python)
+	}
+}
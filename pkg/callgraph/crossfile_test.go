@@ -1,7 +1,9 @@
 package callgraph
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/l3aro/go-context-query/pkg/extractor"
@@ -53,24 +55,28 @@ func TestGoCrossFileResolution(t *testing.T) {
 	}
 
 	// Test function lookup
-	// The module name for main.go should be "main" (file path relative to root)
-	if file, found := index.Lookup("main", "main"); !found {
+	// testdata lies inside this repository's module, so its files are
+	// indexed under their package import paths
+	if file, found := index.Lookup("go", "main"); !found {
 		t.Error("Expected to find 'main' function")
 	} else if filepath.Base(file) != "main.go" {
 		t.Errorf("Expected main function in main.go, got %s", file)
 	}
 
 	// Test lookup - main.go has a local helper() so use qualified lookup for unique name
-	if file, found := index.Lookup("main", "HelperFunction"); !found {
+	if file, found := index.Lookup("go", "HelperFunction"); !found {
 		t.Error("Expected to find 'HelperFunction' function")
 	} else if filepath.Base(file) != "helper.go" {
 		t.Errorf("Expected HelperFunction in helper.go, got %s", file)
 	}
 
 	// Test lookup of math package functions
-	// The module name for utils/math.go should be "utils.math"
-	if file, found := index.Lookup("utils.math", "Add"); !found {
-		t.Error("Expected to find 'Add' function in utils.math")
+	// The module name for utils/math.go is its package path, ending in "utils"
+	if module, _ := index.ModuleForFile(mathFile); !strings.HasSuffix(module, "/testdata/go/utils") {
+		t.Errorf("Expected utils/math.go in the testdata/go/utils package, got %q", module)
+	}
+	if file, found := index.Lookup("utils", "Add"); !found {
+		t.Error("Expected to find 'Add' function in utils")
 	} else if filepath.Base(filepath.Dir(file)) != "utils" {
 		t.Errorf("Expected Add function in utils/, got %s", file)
 	}
@@ -169,6 +175,77 @@ func TestGoModuleNameResolution(t *testing.T) {
 	}
 }
 
+// TestGoModulePackagePaths tests that Go files inside a module are indexed
+// under their package import path, shared by every file of the package, and
+// that calls through unaliased imports resolve to it.
+func TestGoModulePackagePaths(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":               "module example.com/app\n\ngo 1.22\n",
+		"main.go":              "package main\n\nimport \"example.com/app/pkg/index\"\n\nfunc main() { index.New() }\n",
+		"pkg/index/index.go":   "package index\n\nfunc New() int { return helper() }\n",
+		"pkg/index/util.go":    "package index\n\nfunc helper() int { return 1 }\n",
+		"tools/gen/go.mod":     "module example.com/gen\n",
+		"tools/gen/v2/main.go": "package main\n\nfunc main() {}\n",
+	}
+	var goFiles []string
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Ext(name) == ".go" {
+			goFiles = append(goFiles, path)
+		}
+	}
+
+	resolver := NewResolver(root, extractor.NewGoExtractor())
+	graph, err := resolver.ResolveCalls(goFiles)
+	if err != nil {
+		t.Fatalf("ResolveCalls failed: %v", err)
+	}
+
+	index := resolver.GetIndex()
+	for file, want := range map[string]string{
+		"main.go":              "example.com/app",
+		"pkg/index/index.go":   "example.com/app/pkg/index",
+		"pkg/index/util.go":    "example.com/app/pkg/index",
+		"tools/gen/v2/main.go": "example.com/gen/v2",
+	} {
+		if got, _ := index.ModuleForFile(filepath.Join(root, file)); got != want {
+			t.Errorf("ModuleForFile(%s) = %q, want %q", file, got, want)
+		}
+	}
+	if file, ok := index.Lookup("example.com/app/pkg/index", "helper"); !ok || filepath.Base(file) != "util.go" {
+		t.Errorf("Expected helper in the index package, got %q (ok=%v)", file, ok)
+	}
+
+	var resolved bool
+	for _, edge := range graph.CrossFileEdges {
+		if edge.SourceFunc == "main" && edge.DestFile == filepath.Join("pkg", "index", "index.go") && edge.DestFunc == "New" {
+			resolved = true
+		}
+	}
+	if !resolved {
+		t.Errorf("Expected main -> index.New edge, got %+v (unresolved %+v)", graph.CrossFileEdges, graph.UnresolvedCalls)
+	}
+}
+
+func TestGoPackageName(t *testing.T) {
+	for importPath, want := range map[string]string{
+		"example.com/app/pkg/index":    "index",
+		"github.com/redis/go-redis/v9": "go-redis",
+		"fmt":                          "fmt",
+	} {
+		if got := goPackageName(importPath); got != want {
+			t.Errorf("goPackageName(%q) = %q, want %q", importPath, got, want)
+		}
+	}
+}
+
 // TestTypeScriptModuleNameResolution tests that module names are derived correctly from file paths.
 func TestTypeScriptModuleNameResolution(t *testing.T) {
 	testDataDir := filepath.Join("..", "..", "testdata", "typescript")
@@ -279,3 +356,38 @@ func TestCrossFileEdgeResolution(t *testing.T) {
 		t.Error("Expected to resolve cross-file call to utils.math.Add")
 	}
 }
+
+// TestFunctionIndexDuplicateNames tests that duplicate simple names keep all
+// candidates and are ranked by import evidence and proximity.
+func TestFunctionIndexDuplicateNames(t *testing.T) {
+	index := NewFunctionIndex()
+	index.AddFunction("app.worker", "run", "/proj/app/worker.py")
+	index.AddFunction("tools.cli", "run", "/proj/tools/cli.py")
+	index.AddFunction("tools.extra", "run", "/proj/tools/extra.py")
+
+	candidates := index.Candidates("run")
+	if len(candidates) != 3 {
+		t.Fatalf("Expected 3 candidates for run, got %v", candidates)
+	}
+
+	// Import evidence wins over proximity
+	file, _, ok := index.LookupBest("run", "/proj/tools/main.py", []string{"app.worker"})
+	if !ok || file != "/proj/app/worker.py" {
+		t.Errorf("Expected imported module to win, got %q (ok=%v)", file, ok)
+	}
+
+	// Without imports the nearest directory wins
+	file, _, ok = index.LookupBest("run", "/proj/app/main.py", nil)
+	if !ok || file != "/proj/app/worker.py" {
+		t.Errorf("Expected nearest file to win, got %q (ok=%v)", file, ok)
+	}
+
+	// Two equally close candidates are ambiguous
+	file, candidates, ok = index.LookupBest("run", "/proj/tools/main.py", nil)
+	if ok {
+		t.Errorf("Expected ambiguous lookup, got %q", file)
+	}
+	if len(candidates) != 3 {
+		t.Errorf("Expected all candidates in ambiguity info, got %v", candidates)
+	}
+}
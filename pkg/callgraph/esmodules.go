@@ -0,0 +1,420 @@
+package callgraph
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ESExtensions are the TypeScript and JavaScript source extensions, in the
+// order an extensionless import specifier is tried
+var ESExtensions = []string{".ts", ".tsx", ".mts", ".cts", ".js", ".jsx", ".mjs", ".cjs"}
+
+// SFCExtensions are the extensions of Vue and Svelte components, whose
+// script blocks import and are imported like ES modules. Imports name them
+// with their extension, so they are not tried for extensionless specifiers.
+var SFCExtensions = []string{".vue", ".svelte"}
+
+// IsESFile reports whether path is a TypeScript or JavaScript source file
+// or a Vue or Svelte component
+func IsESFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range ESExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	for _, e := range SFCExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// esOutputExtensions maps the extension an ESM import names to the source
+// extensions it may have been compiled from: TypeScript projects import
+// "./util.js" for ./util.ts
+var esOutputExtensions = map[string][]string{
+	".js":  {".ts", ".tsx"},
+	".jsx": {".tsx"},
+	".mjs": {".mts"},
+	".cjs": {".cts"},
+}
+
+// ESModuleResolver resolves ES module specifiers to project files the way
+// TypeScript's bundler resolution does: relative paths with or without an
+// extension, directory index files, tsconfig.json baseUrl and paths, and
+// project packages (the root package and its workspaces) through their
+// package.json exports, module or main fields. Packages outside the project,
+// such as those in node_modules, are not resolved.
+type ESModuleResolver struct {
+	rootDir string
+
+	loadOnce sync.Once
+	baseURL  string
+	paths    []tsPathMapping
+	packages []*esPackage
+}
+
+// tsPathMapping is one tsconfig "paths" entry; a "*" in Pattern is
+// substituted into each target
+type tsPathMapping struct {
+	Pattern string
+	Targets []string
+}
+
+// esPackage is a package.json inside the project
+type esPackage struct {
+	Name    string
+	Dir     string
+	Exports any
+	Module  string
+	Main    string
+}
+
+// NewESModuleResolver creates a resolver for the project at rootDir.
+// tsconfig.json and package.json files are read on first use.
+func NewESModuleResolver(rootDir string) *ESModuleResolver {
+	return &ESModuleResolver{rootDir: rootDir}
+}
+
+// Resolve returns the project file that specifier refers to when imported
+// from fromFile, or false for packages outside the project
+func (r *ESModuleResolver) Resolve(specifier, fromFile string) (string, bool) {
+	r.loadOnce.Do(r.load)
+
+	if specifier == "" {
+		return "", false
+	}
+	if strings.HasPrefix(specifier, "./") || strings.HasPrefix(specifier, "../") || specifier == "." || specifier == ".." {
+		return resolveESPath(filepath.Join(filepath.Dir(fromFile), filepath.FromSlash(specifier)))
+	}
+	if filepath.IsAbs(specifier) {
+		return resolveESPath(specifier)
+	}
+
+	// tsconfig paths: the longest matching prefix wins
+	for _, m := range r.paths {
+		star, ok := matchTSPath(m.Pattern, specifier)
+		if !ok {
+			continue
+		}
+		for _, target := range m.Targets {
+			target = strings.Replace(target, "*", star, 1)
+			if file, ok := resolveESPath(filepath.Join(r.baseURL, filepath.FromSlash(target))); ok {
+				return file, true
+			}
+		}
+	}
+	if r.baseURL != "" {
+		if file, ok := resolveESPath(filepath.Join(r.baseURL, filepath.FromSlash(specifier))); ok {
+			return file, true
+		}
+	}
+
+	for _, pkg := range r.packages {
+		if specifier != pkg.Name && !strings.HasPrefix(specifier, pkg.Name+"/") {
+			continue
+		}
+		if file, ok := pkg.resolve("." + strings.TrimPrefix(specifier, pkg.Name)); ok {
+			return file, true
+		}
+	}
+
+	return "", false
+}
+
+// matchTSPath matches specifier against a tsconfig paths pattern and
+// returns the text matched by its "*"
+func matchTSPath(pattern, specifier string) (string, bool) {
+	prefix, suffix, wildcard := strings.Cut(pattern, "*")
+	if !wildcard {
+		return "", pattern == specifier
+	}
+	if len(specifier) < len(prefix)+len(suffix) || !strings.HasPrefix(specifier, prefix) || !strings.HasSuffix(specifier, suffix) {
+		return "", false
+	}
+	return specifier[len(prefix) : len(specifier)-len(suffix)], true
+}
+
+// resolveESPath resolves a path without or with an extension to a source
+// file: the file itself, the path with a source extension, a TypeScript
+// source for a compiled .js path, or a directory's index file
+func resolveESPath(path string) (string, bool) {
+	if info, err := os.Stat(path); err == nil && !info.IsDir() && IsESFile(path) {
+		return path, true
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, sourceExt := range esOutputExtensions[ext] {
+		candidate := strings.TrimSuffix(path, filepath.Ext(path)) + sourceExt
+		if fileExists(candidate) {
+			return candidate, true
+		}
+	}
+
+	for _, e := range ESExtensions {
+		if fileExists(path + e) {
+			return path + e, true
+		}
+	}
+	for _, e := range ESExtensions {
+		if candidate := filepath.Join(path, "index"+e); fileExists(candidate) {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// resolve resolves a subpath such as "." or "./utils" of the package
+func (p *esPackage) resolve(subpath string) (string, bool) {
+	if p.Exports != nil {
+		if target, ok := resolveExports(p.Exports, subpath); ok {
+			return resolveESPath(filepath.Join(p.Dir, filepath.FromSlash(target)))
+		}
+		// Exports hide every subpath they don't list
+		return "", false
+	}
+
+	if subpath == "." {
+		for _, entry := range []string{p.Module, p.Main} {
+			if entry == "" {
+				continue
+			}
+			if file, ok := resolveESPath(filepath.Join(p.Dir, filepath.FromSlash(entry))); ok {
+				return file, true
+			}
+		}
+	}
+	return resolveESPath(filepath.Join(p.Dir, filepath.FromSlash(subpath)))
+}
+
+// exportConditions are the package.json export conditions followed, in
+// order of preference
+var exportConditions = []string{"types", "import", "module", "default", "require", "node"}
+
+// resolveExports looks subpath up in a package.json "exports" value: a
+// string for the root, a map of subpaths (with "*" patterns), or a map of
+// conditions
+func resolveExports(exports any, subpath string) (string, bool) {
+	switch v := exports.(type) {
+	case string:
+		if subpath == "." {
+			return v, true
+		}
+		return "", false
+	case map[string]any:
+		if !hasSubpathKeys(v) {
+			if subpath != "." {
+				return "", false
+			}
+			return exportTarget(v)
+		}
+		if target, ok := v[subpath]; ok {
+			return exportTarget(target)
+		}
+		// Subpath patterns: the longest matching prefix wins
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			if strings.Contains(key, "*") {
+				keys = append(keys, key)
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool { return len(keys[i]) > len(keys[j]) })
+		for _, key := range keys {
+			if star, ok := matchTSPath(key, subpath); ok {
+				if target, ok := exportTarget(v[key]); ok {
+					return strings.ReplaceAll(target, "*", star), true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// hasSubpathKeys reports whether an exports map is keyed by subpaths
+// (".", "./x") rather than conditions
+func hasSubpathKeys(m map[string]any) bool {
+	for key := range m {
+		return strings.HasPrefix(key, ".")
+	}
+	return false
+}
+
+// exportTarget returns the file an exports entry points to, following
+// conditions and the first usable entry of arrays
+func exportTarget(target any) (string, bool) {
+	switch v := target.(type) {
+	case string:
+		return v, true
+	case []any:
+		for _, t := range v {
+			if s, ok := exportTarget(t); ok {
+				return s, true
+			}
+		}
+	case map[string]any:
+		for _, cond := range exportConditions {
+			if t, ok := v[cond]; ok {
+				if s, ok := exportTarget(t); ok {
+					return s, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// load reads the project's tsconfig.json (or jsconfig.json) and package.json
+// files. Missing or malformed files leave the matching resolution step off.
+func (r *ESModuleResolver) load() {
+	for _, name := range []string{"tsconfig.json", "jsconfig.json"} {
+		if r.loadTSConfig(filepath.Join(r.rootDir, name), 0) {
+			break
+		}
+	}
+
+	root, ok := readPackageJSON(r.rootDir)
+	if !ok {
+		return
+	}
+	if root.pkg.Name != "" {
+		r.packages = append(r.packages, root.pkg)
+	}
+	for _, pattern := range root.workspaces {
+		dirs, _ := filepath.Glob(filepath.Join(r.rootDir, filepath.FromSlash(pattern)))
+		for _, dir := range dirs {
+			if ws, ok := readPackageJSON(dir); ok && ws.pkg.Name != "" {
+				r.packages = append(r.packages, ws.pkg)
+			}
+		}
+	}
+	// Longer names first so "@app/core-utils" isn't taken for "@app/core"
+	sort.SliceStable(r.packages, func(i, j int) bool { return len(r.packages[i].Name) > len(r.packages[j].Name) })
+}
+
+// tsconfig is the part of tsconfig.json used for module resolution
+type tsconfig struct {
+	Extends         string `json:"extends"`
+	CompilerOptions struct {
+		BaseURL *string             `json:"baseUrl"`
+		Paths   map[string][]string `json:"paths"`
+	} `json:"compilerOptions"`
+}
+
+// maxTSConfigExtends bounds how many "extends" links are followed
+const maxTSConfigExtends = 5
+
+// loadTSConfig reads baseUrl and paths from a tsconfig file and the
+// relative configs it extends, the extending file taking precedence
+func (r *ESModuleResolver) loadTSConfig(path string, depth int) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var cfg tsconfig
+	if err := json.Unmarshal(stripJSONComments(data), &cfg); err != nil {
+		return false
+	}
+
+	dir := filepath.Dir(path)
+	if cfg.Extends != "" && depth < maxTSConfigExtends && strings.HasPrefix(cfg.Extends, ".") {
+		parent := filepath.Join(dir, filepath.FromSlash(cfg.Extends))
+		if filepath.Ext(parent) != ".json" {
+			parent += ".json"
+		}
+		r.loadTSConfig(parent, depth+1)
+	}
+
+	if cfg.CompilerOptions.BaseURL != nil {
+		r.baseURL = filepath.Join(dir, filepath.FromSlash(*cfg.CompilerOptions.BaseURL))
+	}
+	if len(cfg.CompilerOptions.Paths) > 0 {
+		// Paths are relative to baseUrl, or to the tsconfig without one
+		if r.baseURL == "" {
+			r.baseURL = dir
+		}
+		r.paths = r.paths[:0]
+		for pattern, targets := range cfg.CompilerOptions.Paths {
+			r.paths = append(r.paths, tsPathMapping{Pattern: pattern, Targets: targets})
+		}
+		sort.Slice(r.paths, func(i, j int) bool {
+			pi, _, _ := strings.Cut(r.paths[i].Pattern, "*")
+			pj, _, _ := strings.Cut(r.paths[j].Pattern, "*")
+			if len(pi) != len(pj) {
+				return len(pi) > len(pj)
+			}
+			return r.paths[i].Pattern < r.paths[j].Pattern
+		})
+	}
+	return true
+}
+
+// jsonComment matches strings (kept) and // or /* */ comments (removed)
+var jsonComment = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|//[^\n]*|/\*[\s\S]*?\*/`)
+
+// jsonTrailingComma matches a comma before a closing bracket
+var jsonTrailingComma = regexp.MustCompile(`,(\s*[}\]])`)
+
+// stripJSONComments turns the JSON-with-comments of tsconfig files into JSON
+func stripJSONComments(data []byte) []byte {
+	data = jsonComment.ReplaceAllFunc(data, func(m []byte) []byte {
+		if m[0] == '"' {
+			return m
+		}
+		return nil
+	})
+	return jsonTrailingComma.ReplaceAll(data, []byte("$1"))
+}
+
+// packageJSON is a parsed package.json with its workspace patterns
+type packageJSON struct {
+	pkg        *esPackage
+	workspaces []string
+}
+
+// readPackageJSON reads the package.json in dir
+func readPackageJSON(dir string) (*packageJSON, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil, false
+	}
+	var raw struct {
+		Name       string          `json:"name"`
+		Exports    any             `json:"exports"`
+		Module     string          `json:"module"`
+		Main       string          `json:"main"`
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, false
+	}
+
+	result := &packageJSON{pkg: &esPackage{
+		Name:    raw.Name,
+		Dir:     dir,
+		Exports: raw.Exports,
+		Module:  raw.Module,
+		Main:    raw.Main,
+	}}
+	// "workspaces" is a list of globs, or {"packages": [...]} for Yarn
+	if err := json.Unmarshal(raw.Workspaces, &result.workspaces); err != nil {
+		var yarn struct {
+			Packages []string `json:"packages"`
+		}
+		if json.Unmarshal(raw.Workspaces, &yarn) == nil {
+			result.workspaces = yarn.Packages
+		}
+	}
+	return result, true
+}
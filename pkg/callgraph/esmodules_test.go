@@ -0,0 +1,152 @@
+package callgraph
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/extractor"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// writeFiles writes files (relative path -> content) under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestESModuleResolver(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"tsconfig.json": `{
+  // comments and trailing commas are allowed
+  "compilerOptions": {
+    "baseUrl": "src",
+    "paths": {
+      "@/*": ["*"],
+      "@utils": ["lib/utils/index.ts"],
+    },
+  },
+}`,
+		"package.json":                     `{"name": "app", "workspaces": ["packages/*"]}`,
+		"src/main.ts":                      "",
+		"src/helpers.ts":                   "",
+		"src/view.tsx":                     "",
+		"src/legacy.js":                    "",
+		"src/lib/utils/index.ts":           "",
+		"src/models/index.ts":              "",
+		"packages/core/package.json":       `{"name": "@app/core", "exports": {".": {"import": "./src/index.ts"}, "./math/*": "./src/math/*.ts"}}`,
+		"packages/core/src/index.ts":       "",
+		"packages/core/src/math/add.ts":    "",
+		"packages/core/src/internal.ts":    "",
+		"packages/plain/package.json":      `{"name": "plain", "main": "lib/main.js"}`,
+		"packages/plain/lib/main.js":       "",
+		"packages/plain/lib/extra.js":      "",
+		"packages/core-utils/package.json": `{"name": "@app/core-utils", "exports": "./index.ts"}`,
+		"packages/core-utils/index.ts":     "",
+	})
+	from := filepath.Join(dir, "src", "main.ts")
+	resolver := NewESModuleResolver(dir)
+
+	tests := []struct {
+		specifier string
+		want      string
+	}{
+		{"./helpers", "src/helpers.ts"},
+		{"./helpers.js", "src/helpers.ts"},
+		{"./view", "src/view.tsx"},
+		{"./legacy", "src/legacy.js"},
+		{"./models", "src/models/index.ts"},
+		{"@/lib/utils", "src/lib/utils/index.ts"},
+		{"@utils", "src/lib/utils/index.ts"},
+		{"models", "src/models/index.ts"},
+		{"@app/core", "packages/core/src/index.ts"},
+		{"@app/core/math/add", "packages/core/src/math/add.ts"},
+		{"@app/core-utils", "packages/core-utils/index.ts"},
+		{"plain", "packages/plain/lib/main.js"},
+		{"plain/lib/extra", "packages/plain/lib/extra.js"},
+	}
+	for _, tt := range tests {
+		got, ok := resolver.Resolve(tt.specifier, from)
+		if !ok {
+			t.Errorf("Resolve(%q) failed", tt.specifier)
+			continue
+		}
+		if want := filepath.Join(dir, filepath.FromSlash(tt.want)); got != want {
+			t.Errorf("Resolve(%q) = %s, want %s", tt.specifier, got, want)
+		}
+	}
+
+	for _, specifier := range []string{"react", "./missing", "@app/core/internal"} {
+		if got, ok := resolver.Resolve(specifier, from); ok {
+			t.Errorf("Resolve(%q) = %s, expected no project file", specifier, got)
+		}
+	}
+}
+
+func TestESCrossFileResolution(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"tsconfig.json": `{"compilerOptions": {"paths": {"~/*": ["./src/*"]}}}`,
+		"src/main.ts": `import { formatName } from './format.js';
+import * as math from '~/math';
+import { log } from './log';
+
+export function run(name: string): string {
+  log(name);
+  return formatName(name) + math.double(2);
+}
+`,
+		"src/format.ts": `export function formatName(name: string): string {
+  return name.trim();
+}
+`,
+		"src/math/index.ts": `export function double(n: number): number {
+  return n * 2;
+}
+`,
+		"src/log.js": `const { formatName } = require('./format');
+
+function log(message) {
+  console.log(formatName(message));
+}
+
+module.exports = { log };
+`,
+	})
+
+	graph, err := BuildProjectCallGraph(dir, extractor.NewTypeScriptExtractor())
+	if err != nil {
+		t.Fatalf("BuildProjectCallGraph failed: %v", err)
+	}
+
+	want := []types.CallGraphEdge{
+		{SourceFile: "src/main.ts", SourceFunc: "run", DestFile: "src/format.ts", DestFunc: "formatName"},
+		{SourceFile: "src/main.ts", SourceFunc: "run", DestFile: "src/math/index.ts", DestFunc: "double"},
+		{SourceFile: "src/main.ts", SourceFunc: "run", DestFile: "src/log.js", DestFunc: "log"},
+		{SourceFile: "src/log.js", SourceFunc: "log", DestFile: "src/format.ts", DestFunc: "formatName"},
+	}
+	for _, edge := range want {
+		edge.SourceFile = filepath.FromSlash(edge.SourceFile)
+		edge.DestFile = filepath.FromSlash(edge.DestFile)
+		found := false
+		for _, e := range graph.CrossFileEdges {
+			if e.SourceFile == edge.SourceFile && e.SourceFunc == edge.SourceFunc &&
+				e.DestFile == edge.DestFile && e.DestFunc == edge.DestFunc {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("missing edge %s:%s -> %s:%s in %+v", edge.SourceFile, edge.SourceFunc, edge.DestFile, edge.DestFunc, graph.CrossFileEdges)
+		}
+	}
+}
@@ -0,0 +1,325 @@
+package callgraph
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/golang"
+)
+
+// goScope tracks the inferred types of variables visible in a Go function body.
+// Only types defined in the file being analyzed are recorded, since those are
+// the only ones whose methods can be resolved intra-file.
+type goScope struct {
+	// vars maps variable names to their receiver type (without pointer prefix)
+	vars map[string]string
+}
+
+// GoMethodName returns the qualified call graph name of a Go method,
+// e.g. GoMethodName("*VectorIndex", "Save") returns "VectorIndex.Save".
+func GoMethodName(receiverType, method string) string {
+	return strings.TrimPrefix(receiverType, "*") + "." + method
+}
+
+// buildGoFromBytes builds a call graph for a Go source file. Methods are keyed
+// by their qualified name (Type.Method) and calls on variables whose type is
+// known (receivers, typed parameters, var declarations and composite literals)
+// are attributed to that type's method.
+func (b *Builder) buildGoFromBytes(content []byte, filePath string, moduleInfo *types.ModuleInfo) (*IntraFileCallGraph, error) {
+	graph := NewIntraFileCallGraph(filePath)
+
+	tree := parse(golang.GetLanguage(), content)
+	if tree == nil {
+		return nil, fmt.Errorf("parsing file %s failed", filePath)
+	}
+	defer tree.Close()
+
+	root := tree.RootNode()
+
+	// Index local types, functions and methods
+	localTypes := make(map[string]bool)
+	returnTypes := make(map[string]string)
+	for _, s := range moduleInfo.Structs {
+		localTypes[s.Name] = true
+	}
+	for _, cls := range moduleInfo.Classes {
+		localTypes[cls.Name] = true
+	}
+	for _, fn := range moduleInfo.Functions {
+		if fn.IsMethod {
+			continue
+		}
+		graph.LocalFunctions[fn.Name] = true
+	}
+	for i := 0; i < int(root.ChildCount()); i++ {
+		child := root.Child(i)
+		if child == nil {
+			continue
+		}
+		switch child.Type() {
+		case b.nodeTypes.methodDef:
+			if recv, name := b.goMethodReceiver(child, content); recv != "" && name != "" {
+				graph.LocalFunctions[GoMethodName(recv, name)] = true
+			}
+		case b.nodeTypes.functionDef:
+			if name := b.nodeText(child.ChildByFieldName("name"), content); name != "" {
+				returnTypes[name] = b.goTypeName(child.ChildByFieldName("result"), content)
+			}
+		}
+	}
+
+	// Index imports by package name (alias or last path element)
+	for _, imp := range moduleInfo.Imports {
+		name := path.Base(imp.Module)
+		if len(imp.Names) > 0 {
+			name = imp.Names[0]
+		}
+		graph.ImportedNames[name] = imp.Module
+	}
+
+	for i := 0; i < int(root.ChildCount()); i++ {
+		child := root.Child(i)
+		if child == nil {
+			continue
+		}
+
+		var entry *CallGraphEntry
+		scope := &goScope{vars: make(map[string]string)}
+
+		switch child.Type() {
+		case b.nodeTypes.functionDef:
+			name := b.nodeText(child.ChildByFieldName("name"), content)
+			if name == "" {
+				continue
+			}
+			entry = &CallGraphEntry{Caller: name, Calls: []CalledFunction{}, LineNumber: int(child.StartPoint().Row) + 1}
+		case b.nodeTypes.methodDef:
+			recv, name := b.goMethodReceiver(child, content)
+			if name == "" {
+				continue
+			}
+			caller := name
+			if recv != "" {
+				caller = GoMethodName(recv, name)
+			}
+			entry = &CallGraphEntry{Caller: caller, Calls: []CalledFunction{}, LineNumber: int(child.StartPoint().Row) + 1}
+			b.declareGoParams(child.ChildByFieldName("receiver"), content, scope, localTypes)
+		default:
+			continue
+		}
+
+		b.declareGoParams(child.ChildByFieldName("parameters"), content, scope, localTypes)
+		graph.Entries[entry.Caller] = entry
+
+		if body := child.ChildByFieldName("body"); body != nil {
+			b.walkGoBody(body, content, graph, entry, scope, localTypes, returnTypes)
+		}
+	}
+
+	return graph, nil
+}
+
+// goMethodReceiver returns the receiver type name (e.g. "VectorIndex") and name of a method_declaration
+func (b *Builder) goMethodReceiver(node *sitter.Node, content []byte) (string, string) {
+	name := b.nodeText(node.ChildByFieldName("name"), content)
+
+	receiver := node.ChildByFieldName("receiver")
+	if receiver == nil {
+		return "", name
+	}
+	for i := 0; i < int(receiver.NamedChildCount()); i++ {
+		param := receiver.NamedChild(i)
+		if param != nil && param.Type() == "parameter_declaration" {
+			return b.goTypeName(param.ChildByFieldName("type"), content), name
+		}
+	}
+
+	return "", name
+}
+
+// goTypeName returns the bare type name of a type node, stripping pointers and
+// type arguments. It returns "" for anything other than a named type.
+func (b *Builder) goTypeName(node *sitter.Node, content []byte) string {
+	if node == nil {
+		return ""
+	}
+
+	switch node.Type() {
+	case "type_identifier":
+		return b.nodeText(node, content)
+	case "pointer_type":
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			if name := b.goTypeName(node.NamedChild(i), content); name != "" {
+				return name
+			}
+		}
+	case "generic_type":
+		return b.goTypeName(node.ChildByFieldName("type"), content)
+	}
+
+	return ""
+}
+
+// declareGoParams records typed parameters (or a receiver) in scope
+func (b *Builder) declareGoParams(params *sitter.Node, content []byte, scope *goScope, localTypes map[string]bool) {
+	if params == nil {
+		return
+	}
+
+	for i := 0; i < int(params.NamedChildCount()); i++ {
+		param := params.NamedChild(i)
+		if param == nil || param.Type() != "parameter_declaration" {
+			continue
+		}
+		typeName := b.goTypeName(param.ChildByFieldName("type"), content)
+		if !localTypes[typeName] {
+			continue
+		}
+		for j := 0; j < int(param.NamedChildCount()); j++ {
+			if ident := param.NamedChild(j); ident != nil && ident.Type() == "identifier" {
+				scope.vars[b.nodeText(ident, content)] = typeName
+			}
+		}
+	}
+}
+
+// walkGoBody walks a function body, tracking variable types and recording calls
+func (b *Builder) walkGoBody(node *sitter.Node, content []byte, graph *IntraFileCallGraph, entry *CallGraphEntry, scope *goScope, localTypes map[string]bool, returnTypes map[string]string) {
+	if node == nil {
+		return
+	}
+
+	switch node.Type() {
+	case "var_spec":
+		typeName := b.goTypeName(node.ChildByFieldName("type"), content)
+		if localTypes[typeName] {
+			for i := 0; i < int(node.NamedChildCount()); i++ {
+				if ident := node.NamedChild(i); ident != nil && ident.Type() == "identifier" {
+					scope.vars[b.nodeText(ident, content)] = typeName
+				}
+			}
+		}
+	case "short_var_declaration":
+		left, right := node.ChildByFieldName("left"), node.ChildByFieldName("right")
+		if left != nil && right != nil {
+			for i := 0; i < int(left.NamedChildCount()) && i < int(right.NamedChildCount()); i++ {
+				ident := left.NamedChild(i)
+				if ident == nil || ident.Type() != "identifier" {
+					continue
+				}
+				if typeName := b.goExprType(right.NamedChild(i), content, returnTypes); localTypes[typeName] {
+					scope.vars[b.nodeText(ident, content)] = typeName
+				}
+			}
+		}
+	case b.nodeTypes.call:
+		if calledFn := b.extractGoCall(node, content, graph, scope); calledFn != nil {
+			calledFn.Args = b.callArguments(node, content)
+			entry.Calls = append(entry.Calls, *calledFn)
+		}
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		b.walkGoBody(node.Child(i), content, graph, entry, scope, localTypes, returnTypes)
+	}
+}
+
+// goExprType infers the named type of an expression: composite literals
+// (T{} and &T{}) and calls to local constructors returning a named type.
+func (b *Builder) goExprType(node *sitter.Node, content []byte, returnTypes map[string]string) string {
+	if node == nil {
+		return ""
+	}
+
+	switch node.Type() {
+	case "composite_literal":
+		return b.goTypeName(node.ChildByFieldName("type"), content)
+	case "unary_expression":
+		return b.goExprType(node.ChildByFieldName("operand"), content, returnTypes)
+	case "call_expression":
+		fn := node.ChildByFieldName("function")
+		if fn != nil && fn.Type() == "identifier" {
+			return returnTypes[b.nodeText(fn, content)]
+		}
+	}
+
+	return ""
+}
+
+// extractGoCall extracts call information from a Go call_expression node
+func (b *Builder) extractGoCall(node *sitter.Node, content []byte, graph *IntraFileCallGraph, scope *goScope) *CalledFunction {
+	fnNode := node.ChildByFieldName("function")
+	if fnNode == nil {
+		return nil
+	}
+
+	lineNumber := int(node.StartPoint().Row) + 1
+
+	switch fnNode.Type() {
+	case "identifier":
+		// Simple function call: helper()
+		name := b.nodeText(fnNode, content)
+		callType := UnknownCall
+		if graph.LocalFunctions[name] {
+			callType = LocalCall
+		}
+
+		return &CalledFunction{
+			Name:       name,
+			Base:       name,
+			Type:       callType,
+			LineNumber: lineNumber,
+		}
+
+	case "selector_expression":
+		// Method or package call: s.Save() or fmt.Println()
+		base := b.nodeText(fnNode.ChildByFieldName("operand"), content)
+		method := b.nodeText(fnNode.ChildByFieldName("field"), content)
+
+		if recv, ok := scope.vars[base]; ok {
+			// Method on a variable of a type defined in this file. If the
+			// method isn't declared here it lives elsewhere in the package.
+			name := GoMethodName(recv, method)
+			callType := UnknownCall
+			if graph.LocalFunctions[name] {
+				callType = MethodCall
+			}
+			return &CalledFunction{
+				Name:        name,
+				Base:        base,
+				Method:      method,
+				Type:        callType,
+				LineNumber:  lineNumber,
+				IsAttribute: true,
+				Receiver:    recv,
+			}
+		}
+
+		callType := UnknownCall
+		if _, ok := graph.ImportedNames[base]; ok {
+			callType = ExternalCall
+		}
+
+		return &CalledFunction{
+			Name:        b.nodeText(fnNode, content),
+			Base:        base,
+			Method:      method,
+			Type:        callType,
+			LineNumber:  lineNumber,
+			IsAttribute: true,
+		}
+
+	default:
+		// Function literals, indexed calls, etc.
+		name := b.nodeText(fnNode, content)
+		return &CalledFunction{
+			Name:       name,
+			Base:       name,
+			Type:       UnknownCall,
+			LineNumber: lineNumber,
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package callgraph
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/extractor"
+)
+
+// TestBuilderGoReceiverMethods tests that Go method calls resolve to the receiver type's method
+func TestBuilderGoReceiverMethods(t *testing.T) {
+	tmpDir := t.TempDir()
+	goFile := filepath.Join(tmpDir, "index.go")
+
+	goCode := `package index
+
+import "fmt"
+
+type VectorIndex struct {
+	items []string
+}
+
+type Cache struct{}
+
+func NewVectorIndex() *VectorIndex {
+	return &VectorIndex{}
+}
+
+func (v *VectorIndex) Save(path string) error {
+	v.flush()
+	fmt.Println(path)
+	return nil
+}
+
+func (v *VectorIndex) flush() {}
+
+func (c Cache) Save() {}
+
+func run(c *Cache) {
+	s := NewVectorIndex()
+	s.Save("out")
+	c.Save()
+
+	var other VectorIndex
+	other.flush()
+	helper()
+}
+
+func helper() {}
+`
+
+	if err := os.WriteFile(goFile, []byte(goCode), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	moduleInfo, err := extractor.NewGoExtractor().Extract(goFile)
+	if err != nil {
+		t.Fatalf("Failed to extract module info: %v", err)
+	}
+
+	// A Python builder switches to the Go grammar for .go files
+	builder := NewBuilder()
+	graph, err := builder.BuildFromFile(goFile, moduleInfo)
+	if err != nil {
+		t.Fatalf("BuildFromFile() failed: %v", err)
+	}
+
+	for _, name := range []string{"NewVectorIndex", "VectorIndex.Save", "VectorIndex.flush", "Cache.Save", "run", "helper"} {
+		if _, ok := graph.Entries[name]; !ok {
+			t.Errorf("Expected entry %q, got %v", name, graph.GetAllFunctions())
+		}
+	}
+
+	want := map[string]CallType{
+		"VectorIndex.Save":  MethodCall,
+		"Cache.Save":        MethodCall,
+		"VectorIndex.flush": MethodCall,
+		"helper":            LocalCall,
+		"NewVectorIndex":    LocalCall,
+	}
+	calls := graph.GetCalls("run")
+	for _, call := range calls {
+		if wantType, ok := want[call.Name]; ok {
+			if call.Type != wantType {
+				t.Errorf("Call %s: expected type %s, got %s", call.Name, wantType, call.Type)
+			}
+			delete(want, call.Name)
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("Missing calls from run: %v (got %+v)", want, calls)
+	}
+
+	saveCalls := graph.GetCalls("VectorIndex.Save")
+	if len(saveCalls) != 2 {
+		t.Fatalf("Expected 2 calls from VectorIndex.Save, got %+v", saveCalls)
+	}
+	if saveCalls[0].Name != "VectorIndex.flush" || saveCalls[0].Receiver != "VectorIndex" {
+		t.Errorf("Expected v.flush() to resolve to VectorIndex.flush, got %+v", saveCalls[0])
+	}
+	if saveCalls[1].Type != ExternalCall || saveCalls[1].Name != "fmt.Println" {
+		t.Errorf("Expected fmt.Println to be external, got %+v", saveCalls[1])
+	}
+
+	// The builder switches back to Python for .py files
+	pyFile := filepath.Join(tmpDir, "mod.py")
+	if err := os.WriteFile(pyFile, []byte("def a():\n    b()\n\ndef b():\n    pass\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	pyInfo, err := extractor.NewPythonExtractor().Extract(pyFile)
+	if err != nil {
+		t.Fatalf("Failed to extract module info: %v", err)
+	}
+	pyGraph, err := builder.BuildFromFile(pyFile, pyInfo)
+	if err != nil {
+		t.Fatalf("BuildFromFile() failed: %v", err)
+	}
+	if len(pyGraph.GetLocalCalls("a")) != 1 {
+		t.Errorf("Expected a() to call b() locally, got %+v", pyGraph.GetCalls("a"))
+	}
+}
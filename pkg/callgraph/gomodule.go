@@ -0,0 +1,84 @@
+package callgraph
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/l3aro/go-context-query/pkg/deps"
+	"github.com/l3aro/go-context-query/pkg/extractor"
+)
+
+// goPackages derives the import paths of Go packages from the go.mod file
+// of their module, caching each directory it looks at
+type goPackages struct {
+	mu sync.Mutex
+	// modules maps a directory to the module path of its go.mod, or "" when
+	// it has none
+	modules map[string]string
+}
+
+// newGoPackages creates an empty go.mod cache
+func newGoPackages() *goPackages {
+	return &goPackages{modules: make(map[string]string)}
+}
+
+// importPath returns the import path of the package in dir, e.g.
+// "example.com/app/pkg/index" for pkg/index under a go.mod declaring
+// "module example.com/app". ok is false when no go.mod covers dir.
+func (p *goPackages) importPath(dir string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for modDir := dir; ; {
+		module, seen := p.modules[modDir]
+		if !seen {
+			module, _ = deps.GoModulePath(filepath.Join(modDir, "go.mod"))
+			p.modules[modDir] = module
+		}
+		if module != "" {
+			rel, err := filepath.Rel(modDir, dir)
+			if err != nil {
+				return "", false
+			}
+			if rel == "." {
+				return module, true
+			}
+			return module + "/" + filepath.ToSlash(rel), true
+		}
+		parent := filepath.Dir(modDir)
+		if parent == modDir {
+			return "", false
+		}
+		modDir = parent
+	}
+}
+
+// goPackageName returns the name a Go import is referred to by when it has
+// no alias: the last element of its path, skipping a major version suffix
+// such as "/v2"
+func goPackageName(importPath string) string {
+	name := path.Base(importPath)
+	if len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		if parent := path.Dir(importPath); parent != "." {
+			name = path.Base(parent)
+		}
+	}
+	return name
+}
+
+// moduleName returns the module functions in filePath are indexed under:
+// the package import path for Go files inside a module, so every file of a
+// package shares the name its importers use, and the dotted file path
+// otherwise
+func (r *Resolver) moduleName(filePath, relPath string) string {
+	if r.extractor.Language() == extractor.Go {
+		if absPath, err := filepath.Abs(filePath); err == nil {
+			if importPath, ok := r.goPackages.importPath(filepath.Dir(absPath)); ok {
+				return importPath
+			}
+		}
+	}
+	return r.filePathToModuleName(relPath)
+}
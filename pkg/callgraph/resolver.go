@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 
@@ -26,6 +27,13 @@ type FunctionIndex struct {
 	//   - "module_path:function_name" (file-based lookup)
 	funcToFile map[string]string
 
+	// candidates maps simple function names to every file defining them,
+	// so common names (run, init, main) can be disambiguated at lookup time
+	candidates map[string][]string
+
+	// fileToModule maps file paths to their dotted module name
+	fileToModule map[string]string
+
 	// fileToFunctions maps file paths to the functions defined in them
 	fileToFunctions map[string][]string
 }
@@ -34,12 +42,15 @@ type FunctionIndex struct {
 func NewFunctionIndex() *FunctionIndex {
 	return &FunctionIndex{
 		funcToFile:      make(map[string]string),
+		candidates:      make(map[string][]string),
+		fileToModule:    make(map[string]string),
 		fileToFunctions: make(map[string][]string),
 	}
 }
 
 // AddFunction adds a function to the index.
-// moduleName is the dotted module path (e.g., "pkg.utils")
+// moduleName is the dotted module path (e.g., "pkg.utils") or, for Go, the
+// package import path (e.g., "example.com/app/pkg/utils")
 // funcName is the function name
 // filePath is the absolute path to the file
 func (idx *FunctionIndex) AddFunction(moduleName, funcName, filePath string) {
@@ -51,6 +62,10 @@ func (idx *FunctionIndex) AddFunction(moduleName, funcName, filePath string) {
 	if _, exists := idx.funcToFile[simpleKey]; !exists {
 		idx.funcToFile[simpleKey] = filePath
 	}
+	if !slices.Contains(idx.candidates[simpleKey], filePath) {
+		idx.candidates[simpleKey] = append(idx.candidates[simpleKey], filePath)
+	}
+	idx.fileToModule[filePath] = moduleName
 
 	// Add qualified name mapping
 	if moduleName != "" {
@@ -58,11 +73,8 @@ func (idx *FunctionIndex) AddFunction(moduleName, funcName, filePath string) {
 		idx.funcToFile[qualifiedKey] = filePath
 
 		// Also add the simple module name (last component)
-		parts := strings.Split(moduleName, ".")
-		if len(parts) > 0 {
-			simpleModuleKey := parts[len(parts)-1] + "." + funcName
-			idx.funcToFile[simpleModuleKey] = filePath
-		}
+		simpleModuleKey := lastModuleElement(moduleName) + "." + funcName
+		idx.funcToFile[simpleModuleKey] = filePath
 	}
 
 	// Track functions by file
@@ -83,12 +95,9 @@ func (idx *FunctionIndex) Lookup(moduleName, funcName string) (string, bool) {
 		}
 
 		// Try with simple module name
-		parts := strings.Split(moduleName, ".")
-		if len(parts) > 0 {
-			simpleKey := parts[len(parts)-1] + "." + funcName
-			if file, ok := idx.funcToFile[simpleKey]; ok {
-				return file, true
-			}
+		simpleKey := lastModuleElement(moduleName) + "." + funcName
+		if file, ok := idx.funcToFile[simpleKey]; ok {
+			return file, true
 		}
 	}
 
@@ -100,6 +109,113 @@ func (idx *FunctionIndex) Lookup(moduleName, funcName string) (string, bool) {
 	return "", false
 }
 
+// lastModuleElement returns the last component of a dotted module path or
+// Go import path, e.g. "utils" for "pkg.utils" and "example.com/app/utils"
+func lastModuleElement(moduleName string) string {
+	if i := strings.LastIndexAny(moduleName, "./"); i >= 0 {
+		return moduleName[i+1:]
+	}
+	return moduleName
+}
+
+// Candidates returns every file defining a function with the given simple name, sorted.
+func (idx *FunctionIndex) Candidates(funcName string) []string {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	files := slices.Clone(idx.candidates[funcName])
+	slices.Sort(files)
+	return files
+}
+
+// LookupBest resolves a simple function name called from fromFile when several
+// files may define it. Candidates are ranked first by import evidence (the
+// candidate's module appears in importedModules), then by directory proximity
+// to fromFile. If the best two candidates rank equally the call is ambiguous:
+// ok is false and all candidates are returned for diagnostics.
+func (idx *FunctionIndex) LookupBest(funcName, fromFile string, importedModules []string) (file string, candidates []string, ok bool) {
+	candidates = idx.Candidates(funcName)
+	switch len(candidates) {
+	case 0:
+		return "", nil, false
+	case 1:
+		return candidates[0], nil, true
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	type ranked struct {
+		file     string
+		imported bool
+		distance int
+	}
+
+	ranks := make([]ranked, len(candidates))
+	for i, c := range candidates {
+		ranks[i] = ranked{
+			file:     c,
+			imported: moduleImported(idx.fileToModule[c], importedModules),
+			distance: dirDistance(fromFile, c),
+		}
+	}
+
+	better := func(a, b ranked) bool {
+		if a.imported != b.imported {
+			return a.imported
+		}
+		return a.distance < b.distance
+	}
+	slices.SortStableFunc(ranks, func(a, b ranked) int {
+		switch {
+		case better(a, b):
+			return -1
+		case better(b, a):
+			return 1
+		}
+		return 0
+	})
+
+	if !better(ranks[0], ranks[1]) {
+		return "", candidates, false
+	}
+	return ranks[0].file, candidates, true
+}
+
+// moduleImported reports whether moduleName matches one of the imported modules,
+// allowing either side to be a dotted or slashed suffix of the other (e.g. "math"
+// and "utils.math", or "utils/math" and "example.com/app/utils/math").
+func moduleImported(moduleName string, importedModules []string) bool {
+	if moduleName == "" {
+		return false
+	}
+	for _, imported := range importedModules {
+		if imported == "" {
+			continue
+		}
+		if imported == moduleName ||
+			strings.HasSuffix(moduleName, "."+imported) ||
+			strings.HasSuffix(imported, "."+moduleName) ||
+			strings.HasSuffix(moduleName, "/"+imported) ||
+			strings.HasSuffix(imported, "/"+moduleName) {
+			return true
+		}
+	}
+	return false
+}
+
+// dirDistance counts the directory steps between the directories of two files.
+func dirDistance(a, b string) int {
+	aParts := strings.Split(filepath.ToSlash(filepath.Dir(a)), "/")
+	bParts := strings.Split(filepath.ToSlash(filepath.Dir(b)), "/")
+
+	shared := 0
+	for shared < len(aParts) && shared < len(bParts) && aParts[shared] == bParts[shared] {
+		shared++
+	}
+	return (len(aParts) - shared) + (len(bParts) - shared)
+}
+
 // LookupByQualifiedName looks up a function by its qualified name (e.g., "module.func").
 func (idx *FunctionIndex) LookupByQualifiedName(qualifiedName string) (string, bool) {
 	if file, ok := idx.funcToFile[qualifiedName]; ok {
@@ -108,6 +224,15 @@ func (idx *FunctionIndex) LookupByQualifiedName(qualifiedName string) (string, b
 	return "", false
 }
 
+// ModuleForFile returns the module name functions in filePath were indexed under.
+func (idx *FunctionIndex) ModuleForFile(filePath string) (string, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	moduleName, ok := idx.fileToModule[filePath]
+	return moduleName, ok
+}
+
 // GetFunctionsInFile returns all functions defined in a given file.
 func (idx *FunctionIndex) GetFunctionsInFile(filePath string) []string {
 	return idx.fileToFunctions[filePath]
@@ -115,15 +240,17 @@ func (idx *FunctionIndex) GetFunctionsInFile(filePath string) []string {
 
 // ImportResolver resolves imported module names to file paths.
 type ImportResolver struct {
-	rootDir string
-	index   *FunctionIndex
+	rootDir   string
+	index     *FunctionIndex
+	esModules *ESModuleResolver
 }
 
 // NewImportResolver creates a new import resolver.
 func NewImportResolver(rootDir string, index *FunctionIndex) *ImportResolver {
 	return &ImportResolver{
-		rootDir: rootDir,
-		index:   index,
+		rootDir:   rootDir,
+		index:     index,
+		esModules: NewESModuleResolver(rootDir),
 	}
 }
 
@@ -141,11 +268,17 @@ type ImportMapping struct {
 	IsRelative bool
 	// RelativeLevel is the number of dots for relative imports (1 for ., 2 for ..)
 	RelativeLevel int
+	// FilePath is the file an ES module import resolves to
+	FilePath string
 }
 
 // ResolveImport resolves an import statement to a file path.
 // Returns the file path and true if resolved, or empty string and false if not.
 func (r *ImportResolver) ResolveImport(imp types.Import, fromFile string) (*ImportMapping, error) {
+	if IsESFile(fromFile) {
+		return r.resolveESImport(imp, fromFile)
+	}
+
 	mapping := &ImportMapping{
 		ModulePath:    imp.Module,
 		IsFrom:        imp.IsFrom,
@@ -165,6 +298,27 @@ func (r *ImportResolver) ResolveImport(imp types.Import, fromFile string) (*Impo
 	return mapping, nil
 }
 
+// resolveESImport resolves a TypeScript or JavaScript import to the project
+// file it names. The mapping's ModulePath is that file's indexed module, so
+// lookups work as they do for Python imports.
+func (r *ImportResolver) resolveESImport(imp types.Import, fromFile string) (*ImportMapping, error) {
+	file, ok := r.esModules.Resolve(imp.Module, fromFile)
+	if !ok {
+		return nil, fmt.Errorf("module %s not found in project", imp.Module)
+	}
+
+	mapping := &ImportMapping{
+		ModulePath: imp.Module,
+		IsFrom:     imp.IsFrom,
+		IsRelative: strings.HasPrefix(imp.Module, "."),
+		FilePath:   file,
+	}
+	if moduleName, ok := r.index.ModuleForFile(file); ok {
+		mapping.ModulePath = moduleName
+	}
+	return mapping, nil
+}
+
 // resolveRelativeImport resolves a relative import path to an absolute module path.
 // Examples:
 //   - "." from "pkg/utils.py" -> "pkg"
@@ -223,13 +377,13 @@ func (r *ImportResolver) resolveRelativeImport(module string, fromFile string) (
 // Resolver builds and resolves cross-file call graphs.
 type Resolver struct {
 	mu          sync.RWMutex
-	parseMu     sync.Mutex // Protects tree-sitter parser (not thread-safe)
 	rootDir     string
 	index       *FunctionIndex
 	importCache map[string][]types.Import // filePath -> imports
 	callGraph   *CrossFileCallGraph
 	extractor   extractor.Extractor
 	builder     *Builder
+	goPackages  *goPackages
 }
 
 // CrossFileCallGraph represents a complete cross-file call graph.
@@ -250,6 +404,8 @@ type UnresolvedCall struct {
 	CallerFunc string
 	CallName   string
 	Reason     string
+	// Candidates lists the files defining CallName when the call is ambiguous
+	Candidates []string
 }
 
 // NewResolver creates a new cross-file call graph resolver.
@@ -265,14 +421,25 @@ func NewResolver(rootDir string, ext extractor.Extractor) *Resolver {
 			CrossFileEdges:  []types.CallGraphEdge{},
 			UnresolvedCalls: []UnresolvedCall{},
 		},
-		extractor: ext,
-		builder:   NewBuilder(),
+		extractor:  ext,
+		builder:    NewBuilder(),
+		goPackages: newGoPackages(),
+	}
+}
+
+// fileExtensions returns the extensions of the files the resolver links.
+// TypeScript, JavaScript and components import each other, so any of their
+// extractors covers them all.
+func (r *Resolver) fileExtensions() []string {
+	if isESLanguage(r.extractor.Language()) {
+		return append(slices.Clone(ESExtensions), SFCExtensions...)
 	}
+	return r.extractor.FileExtensions()
 }
 
 // isSupportedFile checks if a file has a supported extension for the extractor.
 func (r *Resolver) isSupportedFile(filePath string) bool {
-	for _, ext := range r.extractor.FileExtensions() {
+	for _, ext := range r.fileExtensions() {
 		if strings.HasSuffix(filePath, ext) {
 			return true
 		}
@@ -294,11 +461,9 @@ func (r *Resolver) BuildIndex(filePaths []string) error {
 				return
 			}
 
-			// Parse mutex needed - tree-sitter parser isn't thread-safe
-			r.parseMu.Lock()
+			// Extractors parse with pooled parsers, so files are parsed in
+			// parallel
 			moduleInfo, err := r.extractor.Extract(fp)
-			r.parseMu.Unlock()
-
 			if err != nil {
 				return
 			}
@@ -309,7 +474,7 @@ func (r *Resolver) BuildIndex(filePaths []string) error {
 				return
 			}
 
-			moduleName := r.filePathToModuleName(relPath)
+			moduleName := r.moduleName(fp, relPath)
 
 			// Index all functions
 			for _, fn := range moduleInfo.Functions {
@@ -343,7 +508,7 @@ func (r *Resolver) BuildIndex(filePaths []string) error {
 // filePathToModuleName converts a file path to a dotted module name.
 // Example: "pkg/utils.py" -> "pkg.utils"
 func (r *Resolver) filePathToModuleName(filePath string) string {
-	for _, ext := range r.extractor.FileExtensions() {
+	for _, ext := range r.fileExtensions() {
 		filePath = strings.TrimSuffix(filePath, ext)
 	}
 
@@ -395,11 +560,8 @@ func (r *Resolver) ResolveCalls(filePaths []string) (*CrossFileCallGraph, error)
 
 // resolveFileCalls resolves calls within a single file.
 func (r *Resolver) resolveFileCalls(filePath string, resolver *ImportResolver) error {
-	// Get module info - must hold parseMu since tree-sitter parser is not thread-safe
-	r.parseMu.Lock()
+	// Get module info
 	moduleInfo, err := r.extractor.Extract(filePath)
-	r.parseMu.Unlock()
-
 	if err != nil {
 		return fmt.Errorf("extracting module info: %w", err)
 	}
@@ -413,10 +575,8 @@ func (r *Resolver) resolveFileCalls(filePath string, resolver *ImportResolver) e
 	// Build import mapping for this file
 	importMap := r.buildImportMap(imports, filePath, resolver)
 
-	// Build intra-file call graph (builder.parser is not thread-safe)
-	r.parseMu.Lock()
+	// Build intra-file call graph
 	intraGraph, err := r.builder.BuildFromFile(filePath, moduleInfo)
-	r.parseMu.Unlock()
 	if err != nil {
 		return fmt.Errorf("building intra-file call graph: %w", err)
 	}
@@ -474,6 +634,11 @@ func (r *Resolver) buildImportMap(imports []types.Import, fromFile string, resol
 					// Wildcard import - can't resolve specific names
 					continue
 				}
+				if alias, ok := strings.CutPrefix(name, "*"); ok {
+					// ES namespace import: import * as alias from 'module'
+					result.moduleAliases[alias] = mapping.ModulePath
+					continue
+				}
 
 				info := ImportInfo{
 					ModulePath:   mapping.ModulePath,
@@ -482,7 +647,12 @@ func (r *Resolver) buildImportMap(imports []types.Import, fromFile string, resol
 				}
 
 				// Try to find the file for this import
-				if file, ok := r.index.Lookup(mapping.ModulePath, name); ok {
+				if mapping.FilePath != "" {
+					// ES imports name their file; only link names it defines
+					if slices.Contains(r.index.GetFunctionsInFile(mapping.FilePath), name) {
+						info.FilePath = mapping.FilePath
+					}
+				} else if file, ok := r.index.Lookup(mapping.ModulePath, name); ok {
 					info.FilePath = file
 				}
 
@@ -490,7 +660,12 @@ func (r *Resolver) buildImportMap(imports []types.Import, fromFile string, resol
 			}
 		} else {
 			// import module or import module as alias
-			for _, name := range imp.Names {
+			names := imp.Names
+			if len(names) == 0 && r.extractor.Language() == extractor.Go {
+				// Go code refers to an unaliased import by its package name
+				names = []string{goPackageName(imp.Module)}
+			}
+			for _, name := range names {
 				// The name is either the module itself or an alias
 				result.moduleAliases[name] = mapping.ModulePath
 
@@ -519,6 +694,8 @@ func (r *Resolver) resolveSingleCall(
 	edge := types.CallGraphEdge{
 		SourceFile: callerFile,
 		SourceFunc: callerFunc,
+		Line:       call.LineNumber,
+		Args:       call.Args,
 	}
 
 	switch call.Type {
@@ -529,21 +706,19 @@ func (r *Resolver) resolveSingleCall(
 		r.addEdge(edge, true)
 
 	case ExternalCall:
-		// Try to resolve via imports
-		if resolved := r.resolveExternalCall(call, importMap); resolved != nil {
-			edge.DestFile = resolved.DestFile
+		// Try to resolve via imports, then by name across the project
+		resolved := r.resolveExternalCall(call, importMap)
+		var candidates []string
+		if resolved == nil {
+			resolved, candidates = r.resolveByName(callerFile, call, importMap)
+		}
+		if resolved != nil {
+			edge.DestFile = r.relativePath(resolved.DestFile)
 			edge.DestFunc = resolved.DestFunc
 			r.addEdge(edge, false)
 		} else {
 			// Unresolved external call
-			r.mu.Lock()
-			r.callGraph.UnresolvedCalls = append(r.callGraph.UnresolvedCalls, UnresolvedCall{
-				CallerFile: callerFile,
-				CallerFunc: callerFunc,
-				CallName:   call.Name,
-				Reason:     "external module not resolved",
-			})
-			r.mu.Unlock()
+			r.addUnresolved(callerFile, callerFunc, call.Name, "external module not resolved", candidates)
 		}
 
 	case MethodCall:
@@ -553,9 +728,9 @@ func (r *Resolver) resolveSingleCall(
 		r.addEdge(edge, true)
 
 	case UnknownCall:
-		// Try to resolve as external first, then intra-file
+		// Try imports first, then intra-file, then by name across the project
 		if resolved := r.resolveExternalCall(call, importMap); resolved != nil {
-			edge.DestFile = resolved.DestFile
+			edge.DestFile = r.relativePath(resolved.DestFile)
 			edge.DestFunc = resolved.DestFunc
 			r.addEdge(edge, false)
 		} else if intraGraph.LocalFunctions[call.Name] {
@@ -563,16 +738,13 @@ func (r *Resolver) resolveSingleCall(
 			edge.DestFile = callerFile
 			edge.DestFunc = call.Name
 			r.addEdge(edge, true)
+		} else if resolved, candidates := r.resolveByName(callerFile, call, importMap); resolved != nil {
+			edge.DestFile = r.relativePath(resolved.DestFile)
+			edge.DestFunc = resolved.DestFunc
+			r.addEdge(edge, edge.DestFile == callerFile)
 		} else {
 			// Truly unresolved
-			r.mu.Lock()
-			r.callGraph.UnresolvedCalls = append(r.callGraph.UnresolvedCalls, UnresolvedCall{
-				CallerFile: callerFile,
-				CallerFunc: callerFunc,
-				CallName:   call.Name,
-				Reason:     "unknown call target",
-			})
-			r.mu.Unlock()
+			r.addUnresolved(callerFile, callerFunc, call.Name, "unknown call target", candidates)
 		}
 	}
 }
@@ -616,15 +788,73 @@ func (r *Resolver) resolveExternalCall(call CalledFunction, importMap *ImportMap
 		}
 	}
 
-	// Try to find in the index by simple name
-	if file, ok := r.index.Lookup("", call.Name); ok {
-		return &types.CallGraphEdge{
-			DestFile: file,
-			DestFunc: call.Name,
+	return nil
+}
+
+// resolveByName resolves a call by its simple name across the whole project,
+// ranking duplicate definitions by import evidence and proximity to the caller.
+// When the name is ambiguous it returns nil along with the candidate files.
+func (r *Resolver) resolveByName(callerFile string, call CalledFunction, importMap *ImportMap) (*types.CallGraphEdge, []string) {
+	var imported []string
+	for _, info := range importMap.nameToModule {
+		imported = append(imported, info.ModulePath)
+	}
+	for _, modulePath := range importMap.moduleAliases {
+		imported = append(imported, modulePath)
+	}
+
+	fromFile := callerFile
+	if !filepath.IsAbs(fromFile) {
+		fromFile = filepath.Join(r.rootDir, fromFile)
+	}
+
+	file, candidates, ok := r.index.LookupBest(call.Name, fromFile, imported)
+	if !ok {
+		if len(candidates) > 1 {
+			for i, c := range candidates {
+				candidates[i] = r.relativePath(c)
+			}
+			return nil, candidates
 		}
+		return nil, nil
 	}
 
-	return nil
+	return &types.CallGraphEdge{
+		DestFile: file,
+		DestFunc: call.Name,
+	}, nil
+}
+
+// addUnresolved records a call that couldn't be resolved. A non-empty
+// candidates list marks the call as ambiguous rather than missing.
+func (r *Resolver) addUnresolved(callerFile, callerFunc, callName, reason string, candidates []string) {
+	if len(candidates) > 1 {
+		reason = fmt.Sprintf("ambiguous: %d candidates", len(candidates))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.callGraph.UnresolvedCalls = append(r.callGraph.UnresolvedCalls, UnresolvedCall{
+		CallerFile: callerFile,
+		CallerFunc: callerFunc,
+		CallName:   callName,
+		Reason:     reason,
+		Candidates: candidates,
+	})
+}
+
+// relativePath converts an indexed file path to one relative to the root directory,
+// matching how source files are recorded on edges.
+func (r *Resolver) relativePath(filePath string) string {
+	if !filepath.IsAbs(filePath) {
+		return filePath
+	}
+	relPath, err := filepath.Rel(r.rootDir, filePath)
+	if err != nil {
+		return filePath
+	}
+	return relPath
 }
 
 // addEdge adds an edge to the call graph, tracking whether it's intra-file or cross-file.
@@ -655,15 +885,15 @@ func (r *Resolver) GetCallGraph() *CrossFileCallGraph {
 // It scans the project, builds the index, and resolves all calls.
 // It accepts an Extractor to support any language.
 func BuildProjectCallGraph(rootDir string, ext extractor.Extractor) (*CrossFileCallGraph, error) {
+	resolver := NewResolver(rootDir, ext)
+
 	// Find all files matching the extractor's supported extensions
-	filePaths, err := findFilesByExtension(rootDir, ext.FileExtensions())
+	filePaths, err := findFilesByExtension(rootDir, resolver.fileExtensions())
 	if err != nil {
 		return nil, fmt.Errorf("finding files: %w", err)
 	}
 
-	// Create resolver and build call graph
-	resolver := NewResolver(rootDir, ext)
-
+	// Build the call graph
 	callGraph, err := resolver.ResolveCalls(filePaths)
 	if err != nil {
 		return nil, fmt.Errorf("resolving calls: %w", err)
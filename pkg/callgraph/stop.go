@@ -0,0 +1,64 @@
+package callgraph
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/l3aro/go-context-query/internal/scanner"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// StopSymbols lists "boring" callees, such as logging, print and assert
+// helpers, left out of call graph output and of the embedding text derived
+// from the call graph, so they don't crowd out the calls that matter.
+// Patterns are keyed by language name, or "default" for every language,
+// and are path.Match globs matched against both the callee's name and its
+// qualified name: "debug", "log_*" and "Logger.*" all match Logger.debug.
+type StopSymbols map[string][]string
+
+// Match reports whether name, a function or qualified method name of the
+// given language, is a stop symbol
+func (s StopSymbols) Match(language, name string) bool {
+	if len(s) == 0 || name == "" {
+		return false
+	}
+	short := name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		short = name[i+1:]
+	}
+	for _, patterns := range [][]string{s["default"], s[language]} {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+			if ok, _ := path.Match(pattern, short); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MatchEdge reports whether edge calls a stop symbol. The callee's
+// language comes from the extension of its file.
+func (s StopSymbols) MatchEdge(edge types.CallGraphEdge) bool {
+	if len(s) == 0 {
+		return false
+	}
+	return s.Match(scanner.DetectLanguage(filepath.Ext(edge.DestFile)), edge.DestFunc)
+}
+
+// Filter returns the edges that don't call a stop symbol
+func (s StopSymbols) Filter(edges []types.CallGraphEdge) []types.CallGraphEdge {
+	if len(s) == 0 {
+		return edges
+	}
+	var kept []types.CallGraphEdge
+	for _, edge := range edges {
+		if !s.MatchEdge(edge) {
+			kept = append(kept, edge)
+		}
+	}
+	return kept
+}
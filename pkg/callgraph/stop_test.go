@@ -0,0 +1,46 @@
+package callgraph
+
+import (
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+func TestStopSymbols(t *testing.T) {
+	stop := StopSymbols{
+		"default": {"assert_*"},
+		"python":  {"log_*", "Logger.*"},
+		"go":      {"must"},
+	}
+	tests := []struct {
+		language, name string
+		want           bool
+	}{
+		{"python", "log_debug", true},
+		{"python", "Logger.debug", true},
+		{"python", "Service.log_event", true},
+		{"python", "load_config", false},
+		{"go", "assert_equal", true},
+		{"go", "must", true},
+		{"go", "log_debug", false},
+		{"typescript", "must", false},
+	}
+	for _, tt := range tests {
+		if got := stop.Match(tt.language, tt.name); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.language, tt.name, got, tt.want)
+		}
+	}
+
+	edges := []types.CallGraphEdge{
+		{SourceFile: "app.py", SourceFunc: "run", DestFile: "util.py", DestFunc: "log_debug"},
+		{SourceFile: "app.py", SourceFunc: "run", DestFile: "util.py", DestFunc: "load_config"},
+		{SourceFile: "main.go", SourceFunc: "main", DestFile: "util.go", DestFunc: "log_debug"},
+	}
+	kept := stop.Filter(edges)
+	if len(kept) != 2 || kept[0].DestFunc != "load_config" || kept[1].DestFile != "util.go" {
+		t.Errorf("Filter() = %+v, want the python log_debug edge dropped", kept)
+	}
+	if got := StopSymbols(nil).Filter(edges); len(got) != len(edges) {
+		t.Errorf("nil Filter() dropped edges: %+v", got)
+	}
+}
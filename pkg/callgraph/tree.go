@@ -0,0 +1,92 @@
+package callgraph
+
+import (
+	"sort"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// CallTreeNode is a single node in a rendered call tree.
+type CallTreeNode struct {
+	// File is the file containing the function (relative to the project root)
+	File string `json:"file"`
+	// Func is the function name
+	Func string `json:"func"`
+	// Recursive is set when the function already appears on the path from the root,
+	// in which case its children are not expanded again
+	Recursive bool `json:"recursive,omitempty"`
+	// Children are the callees (or callers when the tree is reversed)
+	Children []*CallTreeNode `json:"children,omitempty"`
+}
+
+// BuildCallTree builds a call tree rooted at file:funcName from a list of edges.
+// With reverse set, children are the callers of each node instead of its callees.
+// depth limits how many levels below the root are expanded; values <= 0 mean 1.
+func BuildCallTree(edges []types.CallGraphEdge, file, funcName string, depth int, reverse bool) *CallTreeNode {
+	if depth <= 0 {
+		depth = 1
+	}
+
+	adjacency := make(map[string][]types.CallGraphEdge)
+	for _, edge := range edges {
+		key := edge.SourceFile + ":" + edge.SourceFunc
+		if reverse {
+			key = edge.DestFile + ":" + edge.DestFunc
+		}
+		adjacency[key] = append(adjacency[key], edge)
+	}
+
+	root := &CallTreeNode{File: file, Func: funcName}
+	onPath := map[string]bool{file + ":" + funcName: true}
+	expandCallTree(root, adjacency, onPath, depth, reverse)
+
+	return root
+}
+
+// expandCallTree recursively attaches children to node until depth is exhausted.
+func expandCallTree(node *CallTreeNode, adjacency map[string][]types.CallGraphEdge, onPath map[string]bool, depth int, reverse bool) {
+	if depth == 0 {
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, edge := range adjacency[node.File+":"+node.Func] {
+		childFile, childFunc := edge.DestFile, edge.DestFunc
+		if reverse {
+			childFile, childFunc = edge.SourceFile, edge.SourceFunc
+		}
+
+		key := childFile + ":" + childFunc
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		child := &CallTreeNode{File: childFile, Func: childFunc}
+		if onPath[key] {
+			child.Recursive = true
+		} else {
+			onPath[key] = true
+			expandCallTree(child, adjacency, onPath, depth-1, reverse)
+			delete(onPath, key)
+		}
+
+		node.Children = append(node.Children, child)
+	}
+
+	sort.Slice(node.Children, func(i, j int) bool {
+		if node.Children[i].File != node.Children[j].File {
+			return node.Children[i].File < node.Children[j].File
+		}
+		return node.Children[i].Func < node.Children[j].Func
+	})
+}
+
+// Size returns the number of nodes in the tree, excluding the root.
+func (n *CallTreeNode) Size() int {
+	count := 0
+	for _, child := range n.Children {
+		count += 1 + child.Size()
+	}
+	return count
+}
@@ -0,0 +1,80 @@
+package callgraph
+
+import (
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+func treeTestEdges() []types.CallGraphEdge {
+	return []types.CallGraphEdge{
+		{SourceFile: "main.py", SourceFunc: "main", DestFile: "main.py", DestFunc: "run"},
+		{SourceFile: "main.py", SourceFunc: "run", DestFile: "utils.py", DestFunc: "helper"},
+		{SourceFile: "main.py", SourceFunc: "run", DestFile: "main.py", DestFunc: "run"},
+		{SourceFile: "utils.py", SourceFunc: "helper", DestFile: "utils.py", DestFunc: "format"},
+		{SourceFile: "cli.py", SourceFunc: "cli", DestFile: "utils.py", DestFunc: "helper"},
+	}
+}
+
+func TestBuildCallTree_Forward(t *testing.T) {
+	tree := BuildCallTree(treeTestEdges(), "main.py", "main", 2, false)
+
+	if tree.Func != "main" || tree.File != "main.py" {
+		t.Fatalf("root = %s:%s, want main.py:main", tree.File, tree.Func)
+	}
+	if len(tree.Children) != 1 || tree.Children[0].Func != "run" {
+		t.Fatalf("expected main -> run, got %+v", tree.Children)
+	}
+
+	run := tree.Children[0]
+	if len(run.Children) != 2 {
+		t.Fatalf("expected run to have 2 children, got %d", len(run.Children))
+	}
+	// Depth 2 stops below run's children
+	for _, child := range run.Children {
+		if len(child.Children) != 0 {
+			t.Errorf("expected %s to be unexpanded at depth limit", child.Func)
+		}
+	}
+}
+
+func TestBuildCallTree_Recursion(t *testing.T) {
+	tree := BuildCallTree(treeTestEdges(), "main.py", "run", 5, false)
+
+	var recursive *CallTreeNode
+	for _, child := range tree.Children {
+		if child.Func == "run" {
+			recursive = child
+		}
+	}
+	if recursive == nil || !recursive.Recursive {
+		t.Fatalf("expected self-call to be marked recursive, got %+v", tree.Children)
+	}
+	if len(recursive.Children) != 0 {
+		t.Errorf("recursive node should not be expanded")
+	}
+}
+
+func TestBuildCallTree_Reverse(t *testing.T) {
+	tree := BuildCallTree(treeTestEdges(), "utils.py", "helper", 3, true)
+
+	if len(tree.Children) != 2 {
+		t.Fatalf("expected 2 callers of helper, got %d", len(tree.Children))
+	}
+	if tree.Children[0].Func != "cli" || tree.Children[1].Func != "run" {
+		t.Errorf("unexpected caller order: %s, %s", tree.Children[0].Func, tree.Children[1].Func)
+	}
+
+	run := tree.Children[1]
+	if len(run.Children) == 0 || run.Children[0].Func != "main" {
+		t.Errorf("expected main to call run in reverse tree, got %+v", run.Children)
+	}
+}
+
+func TestCallTreeNode_Size(t *testing.T) {
+	tree := BuildCallTree(treeTestEdges(), "main.py", "main", 3, false)
+	// run, helper, run(recursive), format
+	if got := tree.Size(); got != 4 {
+		t.Errorf("Size() = %d, want 4", got)
+	}
+}
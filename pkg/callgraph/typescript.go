@@ -0,0 +1,198 @@
+package callgraph
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/l3aro/go-context-query/pkg/extractor"
+	"github.com/l3aro/go-context-query/pkg/types"
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/typescript/tsx"
+	"github.com/smacker/go-tree-sitter/typescript/typescript"
+)
+
+// isESLanguage reports whether lang is TypeScript, JavaScript or a Vue or
+// Svelte component, which share a grammar, a call graph builder and ES
+// module import resolution
+func isESLanguage(lang extractor.Language) bool {
+	switch lang {
+	case extractor.TypeScript, extractor.JavaScript, extractor.Vue, extractor.Svelte:
+		return true
+	}
+	return false
+}
+
+// esGrammar returns the grammar for an ES source file: TSX for .tsx and
+// .jsx files, TypeScript (a superset of JavaScript) otherwise
+func esGrammar(filePath string) *sitter.Language {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".tsx", ".jsx":
+		return tsx.GetLanguage()
+	default:
+		return typescript.GetLanguage()
+	}
+}
+
+// buildESFromBytes builds a call graph for a TypeScript or JavaScript file.
+// Callers are function declarations, functions assigned to variables and
+// class methods (keyed by method name, as for Python). Calls on an imported
+// binding (utils.parse() after import * as utils) are external; calls on
+// this are method calls.
+func (b *Builder) buildESFromBytes(content []byte, filePath string, moduleInfo *types.ModuleInfo) (*IntraFileCallGraph, error) {
+	graph := NewIntraFileCallGraph(filePath)
+
+	for _, fn := range moduleInfo.Functions {
+		graph.LocalFunctions[fn.Name] = true
+	}
+	for _, cls := range moduleInfo.Classes {
+		for _, method := range cls.Methods {
+			graph.LocalFunctions[method.Name] = true
+		}
+	}
+
+	// Index imported bindings: named and default imports, namespace imports
+	// (recorded as "*name") and require() assignments
+	for _, imp := range moduleInfo.Imports {
+		for _, name := range imp.Names {
+			graph.ImportedNames[strings.TrimPrefix(name, "*")] = imp.Module
+		}
+	}
+
+	tree := parse(esGrammar(filePath), content)
+	if tree == nil {
+		return nil, fmt.Errorf("parsing file %s failed", filePath)
+	}
+	defer tree.Close()
+
+	// Functions assigned to variables are callers too
+	b.collectESFunctions(tree.RootNode(), content, graph)
+	b.walkESNode(tree.RootNode(), content, graph, nil)
+
+	return graph, nil
+}
+
+// collectESFunctions records top-level const/let/var functions as local
+func (b *Builder) collectESFunctions(root *sitter.Node, content []byte, graph *IntraFileCallGraph) {
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		node := root.NamedChild(i)
+		if node != nil && node.Type() == "export_statement" {
+			node = node.ChildByFieldName("declaration")
+		}
+		if name, _ := b.esVariableFunction(node, content); name != "" {
+			graph.LocalFunctions[name] = true
+		}
+	}
+}
+
+// esVariableFunction returns the name and function node of a declaration
+// like "const parse = (s) => ..." or "let parse = function () {...}"
+func (b *Builder) esVariableFunction(node *sitter.Node, content []byte) (string, *sitter.Node) {
+	if node == nil || (node.Type() != "lexical_declaration" && node.Type() != "variable_declaration") {
+		return "", nil
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		decl := node.NamedChild(i)
+		if decl == nil || decl.Type() != "variable_declarator" {
+			continue
+		}
+		value := decl.ChildByFieldName("value")
+		if value == nil {
+			continue
+		}
+		switch value.Type() {
+		case "arrow_function", "function", "function_expression":
+			return b.nodeText(decl.ChildByFieldName("name"), content), value
+		}
+	}
+	return "", nil
+}
+
+// walkESNode walks the AST, opening an entry for each function or method
+// and recording the calls made in its body
+func (b *Builder) walkESNode(node *sitter.Node, content []byte, graph *IntraFileCallGraph, entry *CallGraphEntry) {
+	if node == nil {
+		return
+	}
+
+	switch node.Type() {
+	case "function_declaration", "generator_function_declaration", "method_definition":
+		name := b.nodeText(node.ChildByFieldName("name"), content)
+		if name == "" {
+			break
+		}
+		fn := &CallGraphEntry{Caller: name, Calls: []CalledFunction{}, LineNumber: int(node.StartPoint().Row) + 1}
+		graph.Entries[name] = fn
+		b.walkESNode(node.ChildByFieldName("body"), content, graph, fn)
+		return
+	case "lexical_declaration", "variable_declaration":
+		if entry == nil {
+			if name, fnNode := b.esVariableFunction(node, content); name != "" {
+				fn := &CallGraphEntry{Caller: name, Calls: []CalledFunction{}, LineNumber: int(node.StartPoint().Row) + 1}
+				graph.Entries[name] = fn
+				b.walkESNode(fnNode.ChildByFieldName("body"), content, graph, fn)
+				return
+			}
+		}
+	case "call_expression", "new_expression":
+		if entry != nil {
+			if call := b.extractESCall(node, content, graph); call != nil {
+				call.Args = b.callArguments(node, content)
+				entry.Calls = append(entry.Calls, *call)
+			}
+		}
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		b.walkESNode(node.Child(i), content, graph, entry)
+	}
+}
+
+// extractESCall extracts call information from a call_expression or
+// new_expression node
+func (b *Builder) extractESCall(node *sitter.Node, content []byte, graph *IntraFileCallGraph) *CalledFunction {
+	fnNode := node.ChildByFieldName("function")
+	if node.Type() == "new_expression" {
+		fnNode = node.ChildByFieldName("constructor")
+	}
+	if fnNode == nil {
+		return nil
+	}
+
+	lineNumber := int(node.StartPoint().Row) + 1
+
+	switch fnNode.Type() {
+	case "identifier":
+		name := b.nodeText(fnNode, content)
+		callType := UnknownCall
+		if graph.LocalFunctions[name] {
+			callType = LocalCall
+		} else if _, ok := graph.ImportedNames[name]; ok {
+			callType = ExternalCall
+		}
+		return &CalledFunction{Name: name, Base: name, Type: callType, LineNumber: lineNumber}
+
+	case "member_expression":
+		base := b.nodeText(fnNode.ChildByFieldName("object"), content)
+		method := b.nodeText(fnNode.ChildByFieldName("property"), content)
+		callType := UnknownCall
+		switch {
+		case base == "this":
+			callType = MethodCall
+		case graph.ImportedNames[strings.Split(base, ".")[0]] != "":
+			callType = ExternalCall
+		}
+		return &CalledFunction{
+			Name:        b.nodeText(fnNode, content),
+			Base:        base,
+			Method:      method,
+			Type:        callType,
+			LineNumber:  lineNumber,
+			IsAttribute: true,
+		}
+
+	default:
+		// Immediately invoked functions, computed members, etc.
+		return nil
+	}
+}
@@ -7,6 +7,8 @@ import (
 
 	sitter "github.com/smacker/go-tree-sitter"
 	"github.com/smacker/go-tree-sitter/java"
+
+	"github.com/l3aro/go-context-query/pkg/types"
 )
 
 type javaCFGExtractor struct {
@@ -876,10 +878,5 @@ func (e *javaCFGExtractor) nodeText(node *sitter.Node) string {
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(e.content)) || end > uint32(len(e.content)) {
-		return ""
-	}
-	return string(e.content[start:end])
+	return types.NodeText(e.content, node.StartByte(), node.EndByte())
 }
@@ -0,0 +1,547 @@
+package cfg
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+	"github.com/smacker/go-tree-sitter/kotlin"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+type kotlinCFGExtractor struct {
+	content  []byte
+	tree     *sitter.Tree
+	blocks   map[string]*CFGBlock
+	edges    []CFGEdge
+	blockID  int
+	funcName string
+}
+
+func newKotlinCFGExtractor(content []byte, funcName string) *kotlinCFGExtractor {
+	parser := sitter.NewParser()
+	parser.SetLanguage(kotlin.GetLanguage())
+	tree := parser.Parse(nil, content)
+
+	return &kotlinCFGExtractor{
+		content:  content,
+		tree:     tree,
+		blocks:   make(map[string]*CFGBlock),
+		edges:    make([]CFGEdge, 0),
+		blockID:  0,
+		funcName: funcName,
+	}
+}
+
+// ExtractKotlinCFG extracts the Control Flow Graph of a Kotlin function,
+// method or companion object member. Kotlin's if, when and try are
+// expressions; they are treated as control flow when they stand as
+// statements, and counted towards complexity wherever they appear.
+func ExtractKotlinCFG(filePath string, functionName string) (*CFGInfo, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
+	}
+
+	extractor := newKotlinCFGExtractor(content, functionName)
+	defer extractor.tree.Close()
+
+	root := extractor.tree.RootNode()
+	funcNode := extractor.findFunction(root, functionName)
+	if funcNode == nil {
+		return nil, fmt.Errorf("function %q not found in %s", functionName, filePath)
+	}
+
+	bodyNode := extractor.findChildByType(funcNode, "function_body")
+	if bodyNode == nil {
+		return nil, fmt.Errorf("function body not found for %s", functionName)
+	}
+
+	entryBlock := extractor.newBlock(BlockTypeEntry, int(funcNode.StartPoint().Row)+1)
+	entryBlock.Statements = []string{"entry"}
+	extractor.addBlock(entryBlock)
+
+	currentBlock := entryBlock
+	extractor.processBlock(bodyNode, &currentBlock)
+
+	exitBlock := extractor.newBlock(BlockTypeExit, int(funcNode.EndPoint().Row)+1)
+	exitBlock.Statements = []string{"exit"}
+	extractor.addBlock(exitBlock)
+
+	if currentBlock != nil && currentBlock.ID != exitBlock.ID {
+		extractor.addEdge(currentBlock.ID, exitBlock.ID, EdgeTypeUnconditional)
+	}
+
+	complexity := extractor.calculateCyclomaticComplexity(bodyNode)
+
+	return &CFGInfo{
+		FunctionName:         functionName,
+		Blocks:               extractor.blocksToMap(),
+		Edges:                extractor.edges,
+		EntryBlockID:         entryBlock.ID,
+		ExitBlockIDs:         []string{exitBlock.ID},
+		CyclomaticComplexity: complexity,
+	}, nil
+}
+
+func (e *kotlinCFGExtractor) findFunction(node *sitter.Node, funcName string) *sitter.Node {
+	if node == nil {
+		return nil
+	}
+
+	if node.Type() == "function_declaration" {
+		nameNode := e.findChildByType(node, "simple_identifier")
+		if nameNode != nil && e.nodeText(nameNode) == funcName {
+			return node
+		}
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if child == nil {
+			continue
+		}
+		if result := e.findFunction(child, funcName); result != nil {
+			return result
+		}
+	}
+
+	return nil
+}
+
+// processBlock adds the statements under node to the graph. node is a
+// function_body, a control_structure_body or a braced block; bodies without
+// braces hold a single statement or expression.
+func (e *kotlinCFGExtractor) processBlock(node *sitter.Node, currentBlock **CFGBlock) {
+	if node == nil {
+		return
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if child == nil {
+			continue
+		}
+
+		switch child.Type() {
+		case "{", "}", "=", "line_comment", "multiline_comment":
+			continue
+
+		case "statements", "control_structure_body":
+			e.processBlock(child, currentBlock)
+
+		case "if_expression":
+			e.processIfExpression(child, currentBlock)
+
+		case "when_expression":
+			e.processWhenExpression(child, currentBlock)
+
+		case "for_statement":
+			e.processLoop(child, "for", currentBlock)
+
+		case "while_statement":
+			e.processLoop(child, "while", currentBlock)
+
+		case "do_while_statement":
+			e.processDoWhileStatement(child, currentBlock)
+
+		case "try_expression":
+			e.processTryExpression(child, currentBlock)
+
+		case "jump_expression":
+			e.processJumpExpression(child, currentBlock)
+
+		default:
+			stmt := strings.TrimSpace(e.nodeText(child))
+			if stmt != "" && *currentBlock != nil {
+				(*currentBlock).Statements = append((*currentBlock).Statements, stmt)
+				(*currentBlock).EndLine = int(child.EndPoint().Row) + 1
+			}
+		}
+	}
+}
+
+// condition returns the text between the parentheses of an if, while or
+// do-while
+func (e *kotlinCFGExtractor) condition(node *sitter.Node) string {
+	inParens := false
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if child == nil {
+			continue
+		}
+		switch child.Type() {
+		case "(":
+			inParens = true
+		case ")":
+			inParens = false
+		default:
+			if inParens {
+				return e.nodeText(child)
+			}
+		}
+	}
+	return ""
+}
+
+func (e *kotlinCFGExtractor) processIfExpression(node *sitter.Node, currentBlock **CFGBlock) {
+	if node == nil {
+		return
+	}
+
+	var consequence, alternative *sitter.Node
+	seenElse := false
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if child == nil {
+			continue
+		}
+		switch child.Type() {
+		case "else":
+			seenElse = true
+		case "control_structure_body":
+			if seenElse {
+				alternative = child
+			} else {
+				consequence = child
+			}
+		}
+	}
+
+	branchBlock := e.newBlock(BlockTypeBranch, int(node.StartPoint().Row)+1)
+	branchBlock.Statements = []string{"if (" + e.condition(node) + ")"}
+	e.addBlock(branchBlock)
+
+	if *currentBlock != nil {
+		e.addEdge((*currentBlock).ID, branchBlock.ID, EdgeTypeUnconditional)
+	}
+
+	consequentBlock := e.newBlock(BlockTypePlain, int(node.StartPoint().Row)+1)
+	e.addBlock(consequentBlock)
+	e.addEdge(branchBlock.ID, consequentBlock.ID, EdgeTypeTrue)
+	if consequence != nil {
+		e.processBlock(consequence, &consequentBlock)
+	}
+
+	if alternative == nil {
+		*currentBlock = branchBlock
+		return
+	}
+
+	elseBlock := e.newBlock(BlockTypePlain, int(alternative.StartPoint().Row)+1)
+	e.addBlock(elseBlock)
+	e.addEdge(branchBlock.ID, elseBlock.ID, EdgeTypeFalse)
+	e.processBlock(alternative, &elseBlock)
+
+	*currentBlock = elseBlock
+}
+
+func (e *kotlinCFGExtractor) processWhenExpression(node *sitter.Node, currentBlock **CFGBlock) {
+	if node == nil {
+		return
+	}
+
+	whenBlock := e.newBlock(BlockTypeBranch, int(node.StartPoint().Row)+1)
+	if subject := e.findChildByType(node, "when_subject"); subject != nil {
+		whenBlock.Statements = []string{"when " + e.nodeText(subject)}
+	} else {
+		whenBlock.Statements = []string{"when"}
+	}
+	e.addBlock(whenBlock)
+
+	if *currentBlock != nil {
+		e.addEdge((*currentBlock).ID, whenBlock.ID, EdgeTypeUnconditional)
+	}
+
+	lastBlock := whenBlock
+	for i := 0; i < int(node.ChildCount()); i++ {
+		entry := node.Child(i)
+		if entry == nil || entry.Type() != "when_entry" {
+			continue
+		}
+
+		var conditions []string
+		for j := 0; j < int(entry.ChildCount()); j++ {
+			child := entry.Child(j)
+			if child != nil && (child.Type() == "when_condition" || child.Type() == "else") {
+				conditions = append(conditions, e.nodeText(child))
+			}
+		}
+
+		entryBlock := e.newBlock(BlockTypeBranch, int(entry.StartPoint().Row)+1)
+		entryBlock.Statements = []string{strings.Join(conditions, ", ") + " ->"}
+		e.addBlock(entryBlock)
+		e.addEdge(whenBlock.ID, entryBlock.ID, EdgeTypeUnconditional)
+
+		if body := e.findChildByType(entry, "control_structure_body"); body != nil {
+			bodyBlock := e.newBlock(BlockTypePlain, int(body.StartPoint().Row)+1)
+			e.addBlock(bodyBlock)
+			e.addEdge(entryBlock.ID, bodyBlock.ID, EdgeTypeUnconditional)
+			e.processBlock(body, &bodyBlock)
+			lastBlock = bodyBlock
+		} else {
+			lastBlock = entryBlock
+		}
+	}
+
+	*currentBlock = lastBlock
+}
+
+// processLoop handles for and while loops, whose header is checked before
+// each iteration
+func (e *kotlinCFGExtractor) processLoop(node *sitter.Node, keyword string, currentBlock **CFGBlock) {
+	if node == nil {
+		return
+	}
+
+	header := e.condition(node)
+	if keyword == "for" {
+		// for (item in items): the parentheses hold several nodes
+		start, end := -1, -1
+		for i := 0; i < int(node.ChildCount()); i++ {
+			switch child := node.Child(i); child.Type() {
+			case "(":
+				start = int(child.EndByte())
+			case ")":
+				end = int(child.StartByte())
+			}
+		}
+		if start >= 0 && end > start {
+			header = strings.TrimSpace(string(e.content[start:end]))
+		}
+	}
+
+	loopHeader := e.newBlock(BlockTypeBranch, int(node.StartPoint().Row)+1)
+	loopHeader.Statements = []string{keyword + " (" + header + ")"}
+	e.addBlock(loopHeader)
+
+	if *currentBlock != nil {
+		e.addEdge((*currentBlock).ID, loopHeader.ID, EdgeTypeUnconditional)
+	}
+
+	loopBody := e.newBlock(BlockTypeLoopBody, int(node.StartPoint().Row)+1)
+	e.addBlock(loopBody)
+	e.addEdge(loopHeader.ID, loopBody.ID, EdgeTypeTrue)
+
+	if body := e.findChildByType(node, "control_structure_body"); body != nil {
+		e.processBlock(body, &loopBody)
+	}
+
+	e.addEdge(loopBody.ID, loopHeader.ID, EdgeTypeBackEdge)
+
+	*currentBlock = loopHeader
+}
+
+func (e *kotlinCFGExtractor) processDoWhileStatement(node *sitter.Node, currentBlock **CFGBlock) {
+	if node == nil {
+		return
+	}
+
+	loopBody := e.newBlock(BlockTypeLoopBody, int(node.StartPoint().Row)+1)
+	loopBody.Statements = []string{"do {"}
+	e.addBlock(loopBody)
+
+	if *currentBlock != nil {
+		e.addEdge((*currentBlock).ID, loopBody.ID, EdgeTypeUnconditional)
+	}
+
+	if body := e.findChildByType(node, "control_structure_body"); body != nil {
+		e.processBlock(body, &loopBody)
+	}
+
+	loopCondition := e.newBlock(BlockTypeBranch, int(node.EndPoint().Row)+1)
+	loopCondition.Statements = []string{"while (" + e.condition(node) + ")"}
+	e.addBlock(loopCondition)
+
+	e.addEdge(loopBody.ID, loopCondition.ID, EdgeTypeTrue)
+	e.addEdge(loopCondition.ID, loopBody.ID, EdgeTypeBackEdge)
+
+	*currentBlock = loopCondition
+}
+
+func (e *kotlinCFGExtractor) processTryExpression(node *sitter.Node, currentBlock **CFGBlock) {
+	if node == nil {
+		return
+	}
+
+	tryStartBlock := e.newBlock(BlockTypePlain, int(node.StartPoint().Row)+1)
+	tryStartBlock.Statements = []string{"try {"}
+	e.addBlock(tryStartBlock)
+
+	if *currentBlock != nil {
+		e.addEdge((*currentBlock).ID, tryStartBlock.ID, EdgeTypeUnconditional)
+	}
+
+	lastBlock := tryStartBlock
+	if body := e.findChildByType(node, "statements"); body != nil {
+		e.processBlock(body, &lastBlock)
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if child == nil {
+			continue
+		}
+		switch child.Type() {
+		case "catch_block":
+			catchBlock := e.newBlock(BlockTypeBranch, int(child.StartPoint().Row)+1)
+			catchBlock.Statements = []string{e.catchHeader(child)}
+			e.addBlock(catchBlock)
+
+			e.addEdge(lastBlock.ID, catchBlock.ID, EdgeTypeTrue)
+			if body := e.findChildByType(child, "statements"); body != nil {
+				e.processBlock(body, &catchBlock)
+			}
+			lastBlock = catchBlock
+
+		case "finally_block":
+			finalBlock := e.newBlock(BlockTypePlain, int(child.StartPoint().Row)+1)
+			finalBlock.Statements = []string{"finally {"}
+			e.addBlock(finalBlock)
+
+			e.addEdge(lastBlock.ID, finalBlock.ID, EdgeTypeUnconditional)
+			if body := e.findChildByType(child, "statements"); body != nil {
+				e.processBlock(body, &finalBlock)
+			}
+			lastBlock = finalBlock
+		}
+	}
+
+	*currentBlock = lastBlock
+}
+
+// catchHeader returns "catch (e: Exception)" for a catch_block
+func (e *kotlinCFGExtractor) catchHeader(node *sitter.Node) string {
+	if brace := e.findChildByType(node, "{"); brace != nil {
+		return strings.TrimSpace(string(e.content[node.StartByte():brace.StartByte()]))
+	}
+	return e.nodeText(node)
+}
+
+// processJumpExpression handles return, throw, break and continue, with
+// optional @label
+func (e *kotlinCFGExtractor) processJumpExpression(node *sitter.Node, currentBlock **CFGBlock) {
+	if node == nil || *currentBlock == nil {
+		return
+	}
+
+	stmt := e.nodeText(node)
+	switch {
+	case strings.HasPrefix(stmt, "break"):
+		(*currentBlock).Statements = append((*currentBlock).Statements, stmt)
+		(*currentBlock).EndLine = int(node.EndPoint().Row) + 1
+		e.addEdge((*currentBlock).ID, "", EdgeTypeBreak)
+
+	case strings.HasPrefix(stmt, "continue"):
+		(*currentBlock).Statements = append((*currentBlock).Statements, stmt)
+		(*currentBlock).EndLine = int(node.EndPoint().Row) + 1
+		e.addEdge((*currentBlock).ID, "", EdgeTypeContinue)
+
+	default:
+		jumpBlock := e.newBlock(BlockTypeReturn, int(node.StartPoint().Row)+1)
+		jumpBlock.Statements = []string{stmt}
+		e.addBlock(jumpBlock)
+
+		e.addEdge((*currentBlock).ID, jumpBlock.ID, EdgeTypeUnconditional)
+
+		*currentBlock = jumpBlock
+	}
+}
+
+func (e *kotlinCFGExtractor) newBlock(blockType BlockType, line int) *CFGBlock {
+	e.blockID++
+	block := &CFGBlock{
+		ID:           fmt.Sprintf("block_%d", e.blockID),
+		Type:         blockType,
+		StartLine:    line,
+		EndLine:      line,
+		Statements:   make([]string, 0),
+		Predecessors: make([]string, 0),
+	}
+	return block
+}
+
+func (e *kotlinCFGExtractor) addBlock(block *CFGBlock) {
+	e.blocks[block.ID] = block
+}
+
+func (e *kotlinCFGExtractor) addEdge(sourceID, targetID string, edgeType EdgeType) {
+	edge := CFGEdge{
+		SourceID: sourceID,
+		TargetID: targetID,
+		EdgeType: edgeType,
+	}
+	e.edges = append(e.edges, edge)
+}
+
+func (e *kotlinCFGExtractor) blocksToMap() map[string]CFGBlock {
+	result := make(map[string]CFGBlock)
+	for id, block := range e.blocks {
+		result[id] = *block
+	}
+	return result
+}
+
+func (e *kotlinCFGExtractor) calculateCyclomaticComplexity(node *sitter.Node) int {
+	if node == nil {
+		return 1
+	}
+
+	decisionPoints := e.countDecisionPoints(node)
+	return decisionPoints + 1
+}
+
+func (e *kotlinCFGExtractor) countDecisionPoints(node *sitter.Node) int {
+	if node == nil {
+		return 0
+	}
+
+	count := 0
+
+	switch node.Type() {
+	case "if_expression", "for_statement", "while_statement", "do_while_statement", "catch_block":
+		count++
+
+	case "when_entry":
+		// The else entry is the fall-through path, not a decision
+		if e.findChildByType(node, "else") == nil {
+			count++
+		}
+
+	case "&&", "||", "?:":
+		count++
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if child != nil {
+			count += e.countDecisionPoints(child)
+		}
+	}
+
+	return count
+}
+
+func (e *kotlinCFGExtractor) findChildByType(node *sitter.Node, childType string) *sitter.Node {
+	if node == nil {
+		return nil
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if child != nil && child.Type() == childType {
+			return child
+		}
+	}
+
+	return nil
+}
+
+func (e *kotlinCFGExtractor) nodeText(node *sitter.Node) string {
+	if node == nil {
+		return ""
+	}
+	return types.NodeText(e.content, node.StartByte(), node.EndByte())
+}
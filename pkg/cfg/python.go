@@ -1,6 +1,7 @@
 package cfg
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -8,8 +9,14 @@ import (
 
 	sitter "github.com/smacker/go-tree-sitter"
 	"github.com/smacker/go-tree-sitter/python"
+
+	"github.com/l3aro/go-context-query/pkg/types"
 )
 
+// ErrUnsupportedFile is returned by ExtractCFG for files of a language
+// without CFG support
+var ErrUnsupportedFile = errors.New("unsupported file type")
+
 // ExtractCFG extracts the Control Flow Graph from a file for the specified function.
 // It dispatches to the appropriate language-specific extractor based on file extension.
 func ExtractCFG(filePath string, functionName string) (*CFGInfo, error) {
@@ -32,10 +39,12 @@ func ExtractCFG(filePath string, functionName string) (*CFGInfo, error) {
 		return ExtractCppCFG(filePath, functionName)
 	case ".rb":
 		return ExtractRubyCFG(filePath, functionName)
-	case ".php":
+	case ".php", ".phtml":
 		return ExtractPhpCFG(filePath, functionName)
+	case ".kt", ".kts":
+		return ExtractKotlinCFG(filePath, functionName)
 	default:
-		return nil, fmt.Errorf("unsupported file type: %s", filePath)
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFile, filePath)
 	}
 }
 
@@ -915,12 +924,7 @@ func (e *pythonCFGExtractor) nodeText(node *sitter.Node) string {
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(e.content)) || end > uint32(len(e.content)) {
-		return ""
-	}
-	return string(e.content[start:end])
+	return types.NodeText(e.content, node.StartByte(), node.EndByte())
 }
 
 func (e *pythonCFGExtractor) extractCallsFromNode(node *sitter.Node, block *CFGBlock) {
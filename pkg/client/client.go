@@ -15,6 +15,14 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/l3aro/go-context-query/pkg/callgraph"
+	"github.com/l3aro/go-context-query/pkg/hover"
+	"github.com/l3aro/go-context-query/pkg/pdg"
+	"github.com/l3aro/go-context-query/pkg/search"
+	"github.com/l3aro/go-context-query/pkg/semantic"
+	"github.com/l3aro/go-context-query/pkg/symbols"
+	"github.com/l3aro/go-context-query/pkg/types"
 )
 
 const (
@@ -190,6 +198,22 @@ func (c *Client) connect() (net.Conn, error) {
 	return conn, nil
 }
 
+// newCommand returns a command frame. The deadline of ctx is sent as the
+// command's timeout_ms, so the daemon stops waiting on its embedding
+// provider when the caller stops waiting on the daemon.
+func newCommand(ctx context.Context, cmdType string) map[string]any {
+	cmd := map[string]any{
+		"type": cmdType,
+		"id":   generateID(),
+	}
+	if ctx != nil {
+		if deadline, ok := ctx.Deadline(); ok {
+			cmd["timeout_ms"] = max(time.Until(deadline).Milliseconds(), 1)
+		}
+	}
+	return cmd
+}
+
 // sendCommand sends a command to the daemon and returns the response
 func (c *Client) sendCommand(ctx context.Context, cmdType string, params any) (map[string]any, error) {
 	conn, err := c.connect()
@@ -209,10 +233,7 @@ func (c *Client) sendCommand(ctx context.Context, cmdType string, params any) (m
 	}
 
 	// Create command
-	cmd := map[string]any{
-		"type": cmdType,
-		"id":   generateID(),
-	}
+	cmd := newCommand(ctx, cmdType)
 
 	if params != nil {
 		paramsJSON, err := json.Marshal(params)
@@ -252,6 +273,74 @@ func (c *Client) sendCommand(ctx context.Context, cmdType string, params any) (m
 	return result, nil
 }
 
+// streamCommand sends a command whose response is preceded by zero or more
+// "*_batch" frames. Each batch result is passed to onBatch; the final result is
+// returned. If onBatch fails the connection is discarded, since unread frames
+// may still be pending on it.
+func (c *Client) streamCommand(ctx context.Context, cmdType string, params any, onBatch func(map[string]any) error) (map[string]any, error) {
+	conn, err := c.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx != nil {
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+		} else {
+			conn.SetDeadline(time.Time{})
+		}
+	}
+
+	cmd := newCommand(ctx, cmdType)
+	if params != nil {
+		paramsJSON, err := json.Marshal(params)
+		if err != nil {
+			c.connPool.put(conn)
+			return nil, fmt.Errorf("marshaling params: %w", err)
+		}
+		cmd["params"] = paramsJSON
+	}
+
+	if err := json.NewEncoder(conn).Encode(cmd); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("sending command: %w", err)
+	}
+
+	decoder := json.NewDecoder(conn)
+	for {
+		var resp map[string]any
+		if err := decoder.Decode(&resp); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("reading response: %w", err)
+		}
+
+		if errMsg, ok := resp["error"].(string); ok && errMsg != "" {
+			c.connPool.put(conn)
+			return nil, fmt.Errorf("daemon error: %s", errMsg)
+		}
+
+		result, ok := resp["result"].(map[string]interface{})
+		if !ok {
+			conn.Close()
+			return nil, fmt.Errorf("invalid response format")
+		}
+
+		respType, _ := resp["type"].(string)
+		if !strings.HasSuffix(respType, "_batch") {
+			c.connPool.put(conn)
+			c.mu.Lock()
+			c.connected = true
+			c.mu.Unlock()
+			return result, nil
+		}
+
+		if err := onBatch(result); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+}
+
 // generateID generates a unique command ID
 func generateID() string {
 	return fmt.Sprintf("cmd-%d", time.Now().UnixNano())
@@ -267,6 +356,8 @@ type DaemonStatus struct {
 	Model      string `json:"model,omitempty"`
 	IndexCount int    `json:"index_count,omitempty"`
 	Dimension  int    `json:"dimension,omitempty"`
+	// Warmup is the startup warm-up state: "running" or "done"
+	Warmup string `json:"warmup,omitempty"`
 }
 
 // GetStatus gets the daemon status
@@ -296,34 +387,105 @@ func (c *Client) GetStatus(ctx context.Context) (*DaemonStatus, error) {
 	if v, ok := result["dimension"].(float64); ok {
 		status.Dimension = int(v)
 	}
+	if v, ok := result["warmup"].(map[string]interface{}); ok {
+		status.Warmup, _ = v["state"].(string)
+	}
 
 	return status, nil
 }
 
 // SearchParams defines parameters for search
 type SearchParams struct {
-	Query     string  `json:"query"`
+	Query string `json:"query"`
+	// Queries are searched along with Query and their rankings fused, for
+	// asking a question together with candidate identifier names
+	Queries []string `json:"queries,omitempty"`
+	// Limit is the number of results; 0 uses the daemon's limits.search_results
 	Limit     int     `json:"limit,omitempty"`
 	Threshold float64 `json:"threshold,omitempty"`
+	// Root selects the project semantic index to search (defaults to the daemon's project)
+	Root string `json:"root,omitempty"`
+	// Files enables two-phase search: files are ranked first and units are
+	// only scored within the top Files files (0 searches every unit)
+	Files int `json:"files,omitempty"`
+	// IncludeTests keeps units from test files, which are left out by default
+	IncludeTests bool `json:"include_tests,omitempty"`
+	// ExcludeTerms penalizes results whose file path contains one of the
+	// terms or whose name, signature or doc comment mentions it, such as
+	// "mock" or "fixtures/"; "-term" words in Query do the same
+	ExcludeTerms []string `json:"exclude_terms,omitempty"`
+	// Metadata keeps only results whose enricher metadata (see
+	// index.enrichers) has every key and, for non-empty values, that value
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Mode is "semantic" (default), "hybrid", which fuses vector search
+	// with a keyword pass over names, signatures and docstrings, or
+	// "keyword", which runs only the keyword pass
+	Mode string `json:"mode,omitempty"`
+	// GroupBy is "file" or "package"; SearchGroups requires it
+	GroupBy string `json:"group_by,omitempty"`
+	// BudgetMS bounds the index scan in milliseconds; when it runs out the
+	// best results so far are returned with a search_budget degradation.
+	// Zero uses the daemon's daemon.search_budget.
+	BudgetMS int64 `json:"budget_ms,omitempty"`
+	// Project selects the daemon project (defaults to the daemon's project)
+	Project string `json:"project,omitempty"`
+}
+
+// SearchGroups runs a search and returns its results collapsed by file or
+// package, as selected by params.GroupBy (default "file")
+func (c *Client) SearchGroups(ctx context.Context, params SearchParams) ([]search.ResultGroup, error) {
+	if params.GroupBy == "" {
+		params.GroupBy = search.GroupByFile
+	}
+	result, err := c.sendCommand(ctx, "search", params)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(result["groups"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal search groups: %w", err)
+	}
+	var groups []search.ResultGroup
+	if err := json.Unmarshal(data, &groups); err != nil {
+		return nil, fmt.Errorf("failed to parse search groups: %w", err)
+	}
+	return groups, nil
 }
 
 // SearchResult represents a search result
 type SearchResult struct {
+	URI        string  `json:"uri,omitempty"`
 	FilePath   string  `json:"file"`
 	LineNumber int     `json:"line"`
+	EndLine    int     `json:"end_line,omitempty"`
 	Name       string  `json:"name"`
 	Signature  string  `json:"signature"`
 	Docstring  string  `json:"docstring"`
 	Type       string  `json:"type"`
 	Score      float64 `json:"score"`
+	// Metadata holds the annotations enrichers attached to the unit
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 // Search performs a semantic search
 func (c *Client) Search(ctx context.Context, params SearchParams) ([]SearchResult, error) {
-	if params.Limit <= 0 {
-		params.Limit = 10
+	resp, err := c.SearchDetailed(ctx, params)
+	if err != nil {
+		return nil, err
 	}
+	return resp.Results, nil
+}
 
+// SearchResponse holds a search's results with the degradations the daemon
+// reported when they are partial
+type SearchResponse struct {
+	Results      []SearchResult     `json:"results"`
+	Degradations types.Degradations `json:"degradations,omitempty"`
+}
+
+// SearchDetailed is Search that also returns the response's degradations
+func (c *Client) SearchDetailed(ctx context.Context, params SearchParams) (*SearchResponse, error) {
 	result, err := c.sendCommand(ctx, "search", params)
 	if err != nil {
 		return nil, err
@@ -342,12 +504,18 @@ func (c *Client) Search(ctx context.Context, params SearchParams) ([]SearchResul
 		}
 
 		sr := SearchResult{}
-		if v, ok := rmap["file"].(string); ok {
+		if v, ok := rmap["uri"].(string); ok {
+			sr.URI = v
+		}
+		if v, ok := rmap["file_path"].(string); ok {
 			sr.FilePath = v
 		}
-		if v, ok := rmap["line"].(float64); ok {
+		if v, ok := rmap["line_number"].(float64); ok {
 			sr.LineNumber = int(v)
 		}
+		if v, ok := rmap["end_line"].(float64); ok {
+			sr.EndLine = int(v)
+		}
 		if v, ok := rmap["name"].(string); ok {
 			sr.Name = v
 		}
@@ -363,22 +531,212 @@ func (c *Client) Search(ctx context.Context, params SearchParams) ([]SearchResul
 		if v, ok := rmap["score"].(float64); ok {
 			sr.Score = v
 		}
+		if v, ok := rmap["metadata"].(map[string]interface{}); ok {
+			sr.Metadata = make(map[string]string, len(v))
+			for key, value := range v {
+				sr.Metadata[key], _ = value.(string)
+			}
+		}
 
 		results = append(results, sr)
 	}
 
-	return results, nil
+	return &SearchResponse{Results: results, Degradations: parseDegradations(result)}, nil
+}
+
+// parseDegradations reads the degradations field of a result
+func parseDegradations(result map[string]any) types.Degradations {
+	v, ok := result["degradations"]
+	if !ok {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var degradations types.Degradations
+	if err := json.Unmarshal(data, &degradations); err != nil {
+		return nil
+	}
+	return degradations
+}
+
+// TextSearchParams defines parameters for a regex text search in the daemon.
+// Unset fields fall back to the daemon's text_search config.
+type TextSearchParams struct {
+	Query string `json:"query"`
+	// Root is the directory to search
+	Root         string   `json:"root"`
+	Limit        int      `json:"limit,omitempty"`
+	ContextLines *int     `json:"context_lines,omitempty"`
+	MaxFileSize  int64    `json:"max_file_size,omitempty"`
+	SkipBinary   *bool    `json:"skip_binary,omitempty"`
+	Extensions   []string `json:"extensions,omitempty"`
+}
+
+// TextMatch represents a single text search match
+type TextMatch struct {
+	FilePath      string   `json:"file_path"`
+	LineNumber    int      `json:"line_number"`
+	Column        int      `json:"column"`
+	EndColumn     int      `json:"end_column"`
+	Match         string   `json:"match"`
+	Highlights    [][2]int `json:"highlights,omitempty"`
+	LineContent   string   `json:"line_content"`
+	ContextBefore []string `json:"context_before,omitempty"`
+	ContextAfter  []string `json:"context_after,omitempty"`
+}
+
+// TextSearch performs a regex text search
+func (c *Client) TextSearch(ctx context.Context, params TextSearchParams) ([]TextMatch, error) {
+	result, err := c.sendCommand(ctx, "search", struct {
+		TextSearchParams
+		Mode string `json:"mode"`
+	}{params, "text"})
+	if err != nil {
+		return nil, err
+	}
+
+	matchesJSON, ok := result["matches"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid text search results format")
+	}
+
+	return parseTextMatches(matchesJSON), nil
+}
+
+// TextSearchStream performs a regex text search, calling fn with each batch of
+// matches as the daemon finds them. It returns the total number of matches.
+// Returning an error from fn stops reading and is returned as is.
+func (c *Client) TextSearchStream(ctx context.Context, params TextSearchParams, fn func([]TextMatch) error) (int, error) {
+	result, err := c.streamCommand(ctx, "search_stream", params, func(batch map[string]any) error {
+		matchesJSON, ok := batch["matches"].([]interface{})
+		if !ok {
+			return fmt.Errorf("invalid text search batch format")
+		}
+		return fn(parseTextMatches(matchesJSON))
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	count, _ := result["count"].(float64)
+	return int(count), nil
+}
+
+// parseTextMatches converts decoded text search matches to TextMatch values
+func parseTextMatches(matchesJSON []interface{}) []TextMatch {
+	matches := make([]TextMatch, 0, len(matchesJSON))
+	for _, m := range matchesJSON {
+		mmap, ok := m.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		tm := TextMatch{}
+		if v, ok := mmap["FilePath"].(string); ok {
+			tm.FilePath = v
+		}
+		if v, ok := mmap["LineNumber"].(float64); ok {
+			tm.LineNumber = int(v)
+		}
+		if v, ok := mmap["Column"].(float64); ok {
+			tm.Column = int(v)
+		}
+		if v, ok := mmap["EndColumn"].(float64); ok {
+			tm.EndColumn = int(v)
+		}
+		if v, ok := mmap["Match"].(string); ok {
+			tm.Match = v
+		}
+		if highlights, ok := mmap["Highlights"].([]interface{}); ok {
+			for _, h := range highlights {
+				hmap, ok := h.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				start, _ := hmap["Start"].(float64)
+				end, _ := hmap["End"].(float64)
+				tm.Highlights = append(tm.Highlights, [2]int{int(start), int(end)})
+			}
+		}
+		if v, ok := mmap["LineContent"].(string); ok {
+			tm.LineContent = v
+		}
+		tm.ContextBefore = stringSlice(mmap["ContextBefore"])
+		tm.ContextAfter = stringSlice(mmap["ContextAfter"])
+
+		matches = append(matches, tm)
+	}
+
+	return matches
+}
+
+// stringSlice converts a decoded JSON array to a []string, skipping non-strings
+func stringSlice(v interface{}) []string {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// LoadIndexParams defines parameters for loading a project semantic index
+type LoadIndexParams struct {
+	Root string `json:"root"`
+}
+
+// LoadIndexResult represents a semantic index loaded by the daemon
+type LoadIndexResult struct {
+	Root      string `json:"root"`
+	Count     int    `json:"count"`
+	Dimension int    `json:"dimension"`
+	Model     string `json:"model"`
+}
+
+// LoadIndex asks the daemon to load (or reload) the semantic index built by
+// `gcq warm` for a project root, so later searches are served from it
+func (c *Client) LoadIndex(ctx context.Context, params LoadIndexParams) (*LoadIndexResult, error) {
+	result, err := c.sendCommand(ctx, "load", params)
+	if err != nil {
+		return nil, err
+	}
+
+	lr := &LoadIndexResult{}
+	if v, ok := result["root"].(string); ok {
+		lr.Root = v
+	}
+	if v, ok := result["count"].(float64); ok {
+		lr.Count = int(v)
+	}
+	if v, ok := result["dimension"].(float64); ok {
+		lr.Dimension = int(v)
+	}
+	if v, ok := result["model"].(string); ok {
+		lr.Model = v
+	}
+
+	return lr, nil
 }
 
 // ExtractParams defines parameters for extract
 type ExtractParams struct {
-	Path string `json:"path"`
+	Path    string `json:"path"`
+	Project string `json:"project,omitempty"`
 }
 
 // ExtractResult represents the result of an extract operation
 type ExtractResult struct {
 	Extracted int `json:"extracted"`
 	Total     int `json:"total"`
+	// Degradations lists what the build skipped or fell back on
+	Degradations types.Degradations `json:"degradations,omitempty"`
 }
 
 // Extract extracts code context from a path
@@ -396,27 +754,87 @@ func (c *Client) Extract(ctx context.Context, params ExtractParams) (*ExtractRes
 		er.Total = int(v)
 	}
 
+	er.Degradations = parseDegradations(result)
+
+	return er, nil
+}
+
+// ProgressEvent reports one file's progress through extract or warm: Event
+// is "extracted", "embedded" or "error". Done counts the files finished so
+// far, embedded or failed, out of Total.
+type ProgressEvent struct {
+	Event string `json:"event"`
+	File  string `json:"file,omitempty"`
+	Error string `json:"error,omitempty"`
+	Done  int    `json:"done"`
+	Total int    `json:"total"`
+}
+
+// parseProgressEvent converts a decoded progress frame to a ProgressEvent
+func parseProgressEvent(batch map[string]any) ProgressEvent {
+	ev := ProgressEvent{}
+	ev.Event, _ = batch["event"].(string)
+	ev.File, _ = batch["file"].(string)
+	ev.Error, _ = batch["error"].(string)
+	if v, ok := batch["done"].(float64); ok {
+		ev.Done = int(v)
+	}
+	if v, ok := batch["total"].(float64); ok {
+		ev.Total = int(v)
+	}
+	return ev
+}
+
+// ExtractStream is like Extract, but calls fn with each file's progress as
+// the daemon reports it. Returning an error from fn stops reading and is
+// returned as is; the daemon finishes the extraction regardless.
+func (c *Client) ExtractStream(ctx context.Context, params ExtractParams, fn func(ProgressEvent) error) (*ExtractResult, error) {
+	streamParams := struct {
+		ExtractParams
+		Progress bool `json:"progress"`
+	}{params, true}
+	result, err := c.streamCommand(ctx, "extract", streamParams, func(batch map[string]any) error {
+		return fn(parseProgressEvent(batch))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	er := &ExtractResult{}
+	if v, ok := result["extracted"].(float64); ok {
+		er.Extracted = int(v)
+	}
+	if v, ok := result["total"].(float64); ok {
+		er.Total = int(v)
+	}
+
+	er.Degradations = parseDegradations(result)
+
 	return er, nil
 }
 
 // ContextParams defines parameters for context query
 type ContextParams struct {
 	Query string `json:"query"`
-	Limit int    `json:"limit,omitempty"`
+	// Limit is the number of units; 0 uses the daemon's limits.context_results
+	Limit   int    `json:"limit,omitempty"`
+	Project string `json:"project,omitempty"`
+	// Blame adds each unit's "blame": the commits that last changed its
+	// lines, with author, date and subject
+	Blame bool `json:"blame,omitempty"`
 }
 
 // ContextResult represents the result of a context query
 type ContextResult struct {
 	Query   string                   `json:"query"`
 	Context []map[string]interface{} `json:"context"`
+	// Degradations lists what was skipped or fallen back on, when the
+	// context is partial
+	Degradations types.Degradations `json:"degradations,omitempty"`
 }
 
 // Context gets LLM-ready context from entry point
 func (c *Client) Context(ctx context.Context, params ContextParams) (*ContextResult, error) {
-	if params.Limit <= 0 {
-		params.Limit = 5
-	}
-
 	result, err := c.sendCommand(ctx, "context", params)
 	if err != nil {
 		return nil, err
@@ -435,23 +853,61 @@ func (c *Client) Context(ctx context.Context, params ContextParams) (*ContextRes
 			}
 		}
 	}
+	cr.Degradations = parseDegradations(result)
 
 	return cr, nil
 }
 
+// BatchParams defines parameters for a batch of context queries
+type BatchParams struct {
+	Queries []search.BatchQuery `json:"queries"`
+	// Dedupe returns units shared by several queries once, with each query
+	// referencing them; nil uses the daemon default (enabled)
+	Dedupe  *bool  `json:"dedupe,omitempty"`
+	Project string `json:"project,omitempty"`
+}
+
+// Batch runs several context queries in one request
+func (c *Client) Batch(ctx context.Context, params BatchParams) (*search.BatchResult, error) {
+	result, err := c.sendCommand(ctx, "batch", params)
+	if err != nil {
+		return nil, err
+	}
+	return parseBatchResult(result)
+}
+
+// parseBatchResult decodes a batch response into a search.BatchResult
+func parseBatchResult(result map[string]interface{}) (*search.BatchResult, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch result: %w", err)
+	}
+	br := &search.BatchResult{}
+	if err := json.Unmarshal(data, br); err != nil {
+		return nil, fmt.Errorf("failed to parse batch result: %w", err)
+	}
+	return br, nil
+}
+
 // CallsParams defines parameters for call graph queries
 type CallsParams struct {
 	File string `json:"file"`
 	Func string `json:"func"`
 	Type string `json:"type,omitempty"`
+	// Depth requests a cross-file call tree expanded this many levels
+	Depth int `json:"depth,omitempty"`
+	// Reverse builds the call tree from callers instead of callees
+	Reverse bool   `json:"reverse,omitempty"`
+	Project string `json:"project,omitempty"`
 }
 
 // CallsResult represents the result of a calls query
 type CallsResult struct {
-	Function string           `json:"function"`
-	File     string           `json:"file"`
-	Calls    []CalledFunction `json:"calls"`
-	Count    int              `json:"count"`
+	Function string                  `json:"function"`
+	File     string                  `json:"file"`
+	Calls    []CalledFunction        `json:"calls"`
+	Count    int                     `json:"count"`
+	Tree     *callgraph.CallTreeNode `json:"tree,omitempty"`
 }
 
 // CalledFunction represents a called function
@@ -502,18 +958,185 @@ func (c *Client) Calls(ctx context.Context, params CallsParams) (*CallsResult, e
 		}
 	}
 
+	if tree, ok := result["tree"]; ok && tree != nil {
+		data, err := json.Marshal(tree)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal call tree: %w", err)
+		}
+		cr.Tree = &callgraph.CallTreeNode{}
+		if err := json.Unmarshal(data, cr.Tree); err != nil {
+			return nil, fmt.Errorf("failed to parse call tree: %w", err)
+		}
+	}
+
 	return cr, nil
 }
 
+// CallersParams defines parameters for a callers ("who calls X") query
+type CallersParams struct {
+	// Func is a function name, a qualified name (Class.method) or a unit URI
+	Func string `json:"func"`
+	// File narrows Func to units in this file (relative to the root)
+	File string `json:"file,omitempty"`
+	// Depth is how many levels of transitive callers to follow (default 1)
+	Depth int `json:"depth,omitempty"`
+	// Root is the project whose semantic index is queried; empty uses the
+	// daemon's project
+	Root    string `json:"root,omitempty"`
+	Project string `json:"project,omitempty"`
+}
+
+// CallersResult represents the result of a callers query
+type CallersResult struct {
+	Func  string `json:"func"`
+	Root  string `json:"root"`
+	Depth int    `json:"depth"`
+	// Targets are the IDs of the units matching Func
+	Targets []string          `json:"targets"`
+	Callers []semantic.Caller `json:"callers"`
+	Count   int               `json:"count"`
+	// Degradations reports a stale or incomplete call graph
+	Degradations types.Degradations `json:"degradations,omitempty"`
+}
+
+// Callers returns the functions calling params.Func, read from the call
+// graph persisted in the project's semantic index
+func (c *Client) Callers(ctx context.Context, params CallersParams) (*CallersResult, error) {
+	result, err := c.sendCommand(ctx, "callers", params)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal callers: %w", err)
+	}
+	var cr CallersResult
+	if err := json.Unmarshal(data, &cr); err != nil {
+		return nil, fmt.Errorf("failed to parse callers: %w", err)
+	}
+	return &cr, nil
+}
+
+// SymbolsParams defines parameters for a fuzzy symbol name query
+type SymbolsParams struct {
+	// Pattern is matched fzf-style against function, class and method names
+	Pattern string `json:"pattern"`
+	// Limit caps the number of matches (default symbols.DefaultLimit)
+	Limit int `json:"limit,omitempty"`
+	// Root is the project whose symbols are searched; empty uses the
+	// daemon's project
+	Root    string `json:"root,omitempty"`
+	Project string `json:"project,omitempty"`
+}
+
+// SymbolsResult represents the result of a symbols query
+type SymbolsResult struct {
+	Pattern string          `json:"pattern"`
+	Root    string          `json:"root"`
+	Matches []symbols.Match `json:"matches"`
+	Count   int             `json:"count"`
+	// Total is the number of symbols searched
+	Total int `json:"total"`
+}
+
+// Symbols fuzzy matches params.Pattern against the names of the
+// project's functions, classes and methods
+func (c *Client) Symbols(ctx context.Context, params SymbolsParams) (*SymbolsResult, error) {
+	result, err := c.sendCommand(ctx, "symbols", params)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal symbols: %w", err)
+	}
+	var sr SymbolsResult
+	if err := json.Unmarshal(data, &sr); err != nil {
+		return nil, fmt.Errorf("failed to parse symbols: %w", err)
+	}
+	return &sr, nil
+}
+
+// HoverParams defines parameters for an editor hover query
+type HoverParams struct {
+	// File is the source file, relative to the project root or absolute
+	File string `json:"file"`
+	// Line and Col are counted from 1; Col in characters
+	Line int `json:"line"`
+	Col  int `json:"col"`
+	// Root is the project whose index is used; empty uses the daemon's
+	// project
+	Root    string `json:"root,omitempty"`
+	Project string `json:"project,omitempty"`
+}
+
+// Hover describes the symbol at params' position: signature, docs, type
+// details, complexity and top callers, with a Markdown rendering
+func (c *Client) Hover(ctx context.Context, params HoverParams) (*hover.Hover, error) {
+	result, err := c.sendCommand(ctx, "hover", params)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal hover: %w", err)
+	}
+	var h hover.Hover
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("failed to parse hover: %w", err)
+	}
+	return &h, nil
+}
+
+// SliceParams defines parameters for a program slice query
+type SliceParams struct {
+	// File is the source file; relative paths are resolved against the
+	// daemon project root
+	File string `json:"file"`
+	// Func is the function containing Line; empty finds the enclosing one
+	Func string `json:"func,omitempty"`
+	Line int    `json:"line"`
+	// Var restricts the slice to dependencies through one variable
+	Var *string `json:"var,omitempty"`
+	// Direction is "backward" (default) or "forward"
+	Direction string `json:"direction,omitempty"`
+	Project   string `json:"project,omitempty"`
+}
+
+// Slice returns the lines affecting (backward) or affected by (forward)
+// params.Line, with their source code
+func (c *Client) Slice(ctx context.Context, params SliceParams) (*pdg.SliceResult, error) {
+	result, err := c.sendCommand(ctx, "slice", params)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal slice: %w", err)
+	}
+	var sr pdg.SliceResult
+	if err := json.Unmarshal(data, &sr); err != nil {
+		return nil, fmt.Errorf("failed to parse slice: %w", err)
+	}
+	return &sr, nil
+}
+
 // WarmParams defines parameters for warm/indexing operation
 type WarmParams struct {
-	Paths []string `json:"paths"`
+	Paths   []string `json:"paths"`
+	Project string   `json:"project,omitempty"`
 }
 
 // WarmResult represents the result of a warm operation
 type WarmResult struct {
 	Extracted int      `json:"extracted"`
 	Paths     []string `json:"paths"`
+	// Degradations lists what the build skipped or fell back on
+	Degradations types.Degradations `json:"degradations,omitempty"`
 }
 
 // Warm builds the semantic index for specified paths
@@ -536,9 +1159,201 @@ func (c *Client) Warm(ctx context.Context, params WarmParams) (*WarmResult, erro
 		}
 	}
 
+	wr.Degradations = parseDegradations(result)
+
+	return wr, nil
+}
+
+// WarmStream is like Warm, but calls fn with each file's progress as the
+// daemon reports it. Returning an error from fn stops reading and is
+// returned as is; the daemon finishes the build regardless.
+func (c *Client) WarmStream(ctx context.Context, params WarmParams, fn func(ProgressEvent) error) (*WarmResult, error) {
+	streamParams := struct {
+		WarmParams
+		Progress bool `json:"progress"`
+	}{params, true}
+	result, err := c.streamCommand(ctx, "warm", streamParams, func(batch map[string]any) error {
+		return fn(parseProgressEvent(batch))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	wr := &WarmResult{}
+	if v, ok := result["extracted"].(float64); ok {
+		wr.Extracted = int(v)
+	}
+	if paths, ok := result["paths"].([]interface{}); ok {
+		wr.Paths = make([]string, 0, len(paths))
+		for _, p := range paths {
+			if ps, ok := p.(string); ok {
+				wr.Paths = append(wr.Paths, ps)
+			}
+		}
+	}
+
+	wr.Degradations = parseDegradations(result)
+
 	return wr, nil
 }
 
+// ProjectInfo describes a project open in the daemon
+type ProjectInfo struct {
+	Root              string    `json:"root"`
+	Default           bool      `json:"default"`
+	IndexPath         string    `json:"index_path"`
+	IndexCount        int       `json:"index_count"`
+	Dimension         int       `json:"dimension"`
+	SemanticCount     int       `json:"semantic_count,omitempty"`
+	Paths             []string  `json:"paths,omitempty"`
+	DirtyCount        int       `json:"dirty_count"`
+	ReindexInProgress bool      `json:"reindex_in_progress"`
+	LastUsed          time.Time `json:"last_used"`
+}
+
+// Projects lists the projects open in the daemon
+func (c *Client) Projects(ctx context.Context) ([]ProjectInfo, error) {
+	result, err := c.sendCommand(ctx, "projects", map[string]string{"action": "list"})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(result["projects"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal projects: %w", err)
+	}
+	var projects []ProjectInfo
+	if err := json.Unmarshal(data, &projects); err != nil {
+		return nil, fmt.Errorf("failed to parse projects: %w", err)
+	}
+	return projects, nil
+}
+
+// EvictProject saves a project's index and drops it from daemon memory,
+// returning the evicted root. The daemon's default project cannot be evicted.
+func (c *Client) EvictProject(ctx context.Context, project string) (string, error) {
+	result, err := c.sendCommand(ctx, "projects", map[string]string{"action": "evict", "project": project})
+	if err != nil {
+		return "", err
+	}
+	root, _ := result["evicted"].(string)
+	return root, nil
+}
+
+// CompactResult reports the compaction of one project's index
+type CompactResult struct {
+	Root string `json:"root"`
+	// Reclaimed is how many removed entries were dropped from the index
+	Reclaimed int `json:"reclaimed"`
+	// Count is how many live entries the index holds
+	Count int `json:"count"`
+}
+
+// CompactProjects reclaims the storage of removed entries in a project's
+// index, or in every open project's when project is empty
+func (c *Client) CompactProjects(ctx context.Context, project string) ([]CompactResult, error) {
+	result, err := c.sendCommand(ctx, "projects", map[string]string{"action": "compact", "project": project})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(result["compacted"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal compaction results: %w", err)
+	}
+	var compacted []CompactResult
+	if err := json.Unmarshal(data, &compacted); err != nil {
+		return nil, fmt.Errorf("failed to parse compaction results: %w", err)
+	}
+	return compacted, nil
+}
+
+// JobStatus describes a background extract or warm job. State is "queued",
+// "running", "done", "failed" or "cancelled"; Result holds the command's
+// usual result once the job is done.
+type JobStatus struct {
+	ID         string          `json:"job_id"`
+	Type       string          `json:"type"`
+	Project    string          `json:"project,omitempty"`
+	State      string          `json:"state"`
+	Done       int             `json:"done"`
+	Total      int             `json:"total"`
+	Errors     int             `json:"errors"`
+	Error      string          `json:"error,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  time.Time       `json:"started_at,omitzero"`
+	FinishedAt time.Time       `json:"finished_at,omitzero"`
+}
+
+// Finished reports whether the job is done, failed or cancelled
+func (j *JobStatus) Finished() bool {
+	return j.State == "done" || j.State == "failed" || j.State == "cancelled"
+}
+
+// ExtractAsync queues an extract as a background job and returns at once
+func (c *Client) ExtractAsync(ctx context.Context, params ExtractParams) (*JobStatus, error) {
+	return c.jobCommand(ctx, "extract", struct {
+		ExtractParams
+		Async bool `json:"async"`
+	}{params, true})
+}
+
+// WarmAsync queues a warm as a background job and returns at once
+func (c *Client) WarmAsync(ctx context.Context, params WarmParams) (*JobStatus, error) {
+	return c.jobCommand(ctx, "warm", struct {
+		WarmParams
+		Async bool `json:"async"`
+	}{params, true})
+}
+
+// JobStatus returns the status of a background job
+func (c *Client) JobStatus(ctx context.Context, id string) (*JobStatus, error) {
+	return c.jobCommand(ctx, "job_status", map[string]string{"job_id": id})
+}
+
+// CancelJob cancels a background job. A running job stops after its current
+// file and keeps the files already indexed.
+func (c *Client) CancelJob(ctx context.Context, id string) (*JobStatus, error) {
+	return c.jobCommand(ctx, "job_cancel", map[string]string{"job_id": id})
+}
+
+// Jobs lists the daemon's queued, running and recently finished jobs
+func (c *Client) Jobs(ctx context.Context) ([]JobStatus, error) {
+	result, err := c.sendCommand(ctx, "job_status", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(result["jobs"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal jobs: %w", err)
+	}
+	var jobs []JobStatus
+	if err := json.Unmarshal(data, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to parse jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// jobCommand sends a command answered with a job's status
+func (c *Client) jobCommand(ctx context.Context, cmdType string, params any) (*JobStatus, error) {
+	result, err := c.sendCommand(ctx, cmdType, params)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal job: %w", err)
+	}
+	var job JobStatus
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to parse job: %w", err)
+	}
+	return &job, nil
+}
+
 // IsConnected returns whether the client is connected to the daemon
 func (c *Client) IsConnected() bool {
 	c.mu.RLock()
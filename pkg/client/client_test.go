@@ -1,10 +1,15 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
 	"os"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/l3aro/go-context-query/pkg/types"
 )
 
 // TestNewWithDefaults tests client creation with default values
@@ -501,6 +506,99 @@ func TestCallsResult(t *testing.T) {
 	}
 }
 
+// TestCallsParamsTreeJSON tests that tree options are serialized for the daemon
+func TestCallsParamsTreeJSON(t *testing.T) {
+	params := CallsParams{File: "main.py", Func: "main", Depth: 3, Reverse: true}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded["depth"] != float64(3) {
+		t.Errorf("Expected depth 3, got %v", decoded["depth"])
+	}
+	if decoded["reverse"] != true {
+		t.Errorf("Expected reverse true, got %v", decoded["reverse"])
+	}
+
+	data, _ = json.Marshal(CallsParams{File: "main.py", Func: "main"})
+	if strings.Contains(string(data), "depth") || strings.Contains(string(data), "reverse") {
+		t.Errorf("Expected tree options to be omitted when unset, got %s", data)
+	}
+}
+
+func TestTextSearchParamsJSON(t *testing.T) {
+	contextLines := 0
+	params := TextSearchParams{Query: "TODO", Root: "/repo", ContextLines: &contextLines}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded["context_lines"] != float64(0) {
+		t.Errorf("Expected explicit context_lines 0 to be sent, got %v", decoded["context_lines"])
+	}
+	if _, ok := decoded["skip_binary"]; ok {
+		t.Errorf("Expected skip_binary to be omitted when unset, got %s", data)
+	}
+}
+
+func TestParseTextMatchesHighlights(t *testing.T) {
+	var decoded []interface{}
+	raw := `[{"FilePath":"a.go","LineNumber":3,"LineContent":"err = err","Column":0,"EndColumn":3,"Match":"err",
+		"Highlights":[{"Start":0,"End":3},{"Start":6,"End":9}]}]`
+	if err := json.Unmarshal([]byte(raw), &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	matches := parseTextMatches(decoded)
+	if len(matches) != 1 {
+		t.Fatalf("Expected 1 match, got %d", len(matches))
+	}
+	m := matches[0]
+	if m.EndColumn != 3 {
+		t.Errorf("Expected EndColumn 3, got %d", m.EndColumn)
+	}
+	if len(m.Highlights) != 2 || m.Highlights[1] != [2]int{6, 9} {
+		t.Errorf("Expected highlights [[0 3] [6 9]], got %v", m.Highlights)
+	}
+}
+
+func TestParseBatchResult(t *testing.T) {
+	var decoded map[string]interface{}
+	raw := `{"results":[{"query":"auth","context":[{"ref":"gcq://a.go#login","score":0.9}]},{"query":"","error":"query cannot be empty"}],
+		"units":{"gcq://a.go#login":{"uri":"gcq://a.go#login","file_path":"a.go","line_number":4,"name":"login","score":0.9}},
+		"total_hits":1,"unique_units":1}`
+	if err := json.Unmarshal([]byte(raw), &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	br, err := parseBatchResult(decoded)
+	if err != nil {
+		t.Fatalf("parseBatchResult failed: %v", err)
+	}
+	if len(br.Results) != 2 || br.Results[1].Error == "" {
+		t.Fatalf("Expected 2 results with a per-query error, got %+v", br.Results)
+	}
+	ref := br.Results[0].Context[0].Ref
+	if unit, ok := br.Units[ref]; !ok || unit.LineNumber != 4 {
+		t.Errorf("Expected reference %q to resolve to line 4, got %+v", ref, unit)
+	}
+	if br.UniqueUnits != 1 {
+		t.Errorf("Expected 1 unique unit, got %d", br.UniqueUnits)
+	}
+}
+
 // TestWarmParams tests WarmParams struct
 func TestWarmParams(t *testing.T) {
 	params := WarmParams{
@@ -512,6 +610,78 @@ func TestWarmParams(t *testing.T) {
 	}
 }
 
+func TestProjectParamJSON(t *testing.T) {
+	data, _ := json.Marshal(WarmParams{Paths: []string{"/repo/src"}, Project: "/repo"})
+	if !strings.Contains(string(data), `"project":"/repo"`) {
+		t.Errorf("Expected project to be sent, got %s", data)
+	}
+
+	data, _ = json.Marshal(ContextParams{Query: "main"})
+	if strings.Contains(string(data), "project") {
+		t.Errorf("Expected project to be omitted when unset, got %s", data)
+	}
+}
+
+func TestParseProgressEvent(t *testing.T) {
+	var decoded map[string]any
+	raw := `{"event":"error","file":"/repo/bad.py","error":"syntax error","done":3,"total":10}`
+	if err := json.Unmarshal([]byte(raw), &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	ev := parseProgressEvent(decoded)
+	want := ProgressEvent{Event: "error", File: "/repo/bad.py", Error: "syntax error", Done: 3, Total: 10}
+	if ev != want {
+		t.Errorf("Expected %+v, got %+v", want, ev)
+	}
+}
+
+func TestParseDegradations(t *testing.T) {
+	var decoded map[string]any
+	raw := `{"results":[],"degradations":[{"feature":"stale_index","reason":"files changed","count":2}]}`
+	if err := json.Unmarshal([]byte(raw), &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	got := parseDegradations(decoded)
+	if len(got) != 1 || got[0] != (types.Degradation{Feature: types.DegradedStaleIndex, Reason: "files changed", Count: 2}) {
+		t.Errorf("parseDegradations() = %+v", got)
+	}
+	if got := parseDegradations(map[string]any{"results": []any{}}); got != nil {
+		t.Errorf("parseDegradations() without degradations = %+v", got)
+	}
+}
+
+func TestJobStatusJSON(t *testing.T) {
+	raw := `{"job_id":"job-3","type":"warm","state":"done","done":12,"total":12,"errors":1,
+		"result":{"extracted":11,"paths":["/repo"]},"created_at":"2026-01-02T03:04:05Z"}`
+	var job JobStatus
+	if err := json.Unmarshal([]byte(raw), &job); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if job.ID != "job-3" || job.Done != 12 || job.Errors != 1 {
+		t.Errorf("Unexpected job %+v", job)
+	}
+	if !job.Finished() {
+		t.Errorf("Expected a done job to be finished")
+	}
+
+	var result WarmResult
+	if err := json.Unmarshal(job.Result, &result); err != nil || result.Extracted != 11 {
+		t.Errorf("Expected the warm result in Result, got %s (%v)", job.Result, err)
+	}
+
+	job.State = "running"
+	if job.Finished() {
+		t.Errorf("Expected a running job not to be finished")
+	}
+
+	data, _ := json.Marshal(JobStatus{ID: "job-1", State: "queued"})
+	if strings.Contains(string(data), "started_at") {
+		t.Errorf("Expected started_at to be omitted before the job starts, got %s", data)
+	}
+}
+
 // TestWarmResult tests WarmResult struct
 func TestWarmResult(t *testing.T) {
 	result := &WarmResult{
@@ -526,3 +696,46 @@ func TestWarmResult(t *testing.T) {
 		t.Errorf("Expected Paths length 2, got %d", len(result.Paths))
 	}
 }
+
+// TestModuleInfoToTextInterfaces tests that interfaces keep their method
+// signatures in file-level embedding text and are not repeated as classes
+func TestModuleInfoToTextInterfaces(t *testing.T) {
+	iface := types.Interface{
+		Name: "Provider",
+		Methods: []types.Method{
+			{Name: "Embed", Params: "(texts []string)", ReturnType: "([][]float32, error)"},
+			{Name: "Dimension", Params: "()", ReturnType: "int"},
+		},
+	}
+	m := &types.ModuleInfo{
+		Path:       "embed.go",
+		Classes:    []types.Class{{Name: "Provider", Methods: iface.Methods}, {Name: "Client"}},
+		Interfaces: []types.Interface{iface},
+	}
+
+	text := moduleInfoToText(m)
+	if strings.Contains(text, "class Provider") {
+		t.Errorf("Interface should not be written as a class:\n%s", text)
+	}
+	for _, want := range []string{"class Client", "interface Provider", "  Embed(texts []string) -> ([][]float32, error)", "  Dimension() -> int"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in:\n%s", want, text)
+		}
+	}
+}
+
+func TestNewCommandTimeout(t *testing.T) {
+	if cmd := newCommand(context.Background(), "search"); cmd["timeout_ms"] != nil {
+		t.Errorf("command without a deadline has timeout_ms %v", cmd["timeout_ms"])
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cmd := newCommand(ctx, "search")
+	if ms, ok := cmd["timeout_ms"].(int64); !ok || ms <= 0 || ms > 5000 {
+		t.Errorf("timeout_ms = %v, want the time left before the deadline", cmd["timeout_ms"])
+	}
+	if cmd["type"] != "search" || cmd["id"] == "" {
+		t.Errorf("command = %v", cmd)
+	}
+}
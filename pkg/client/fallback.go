@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/l3aro/go-context-query/internal/config"
 	"github.com/l3aro/go-context-query/internal/scanner"
@@ -14,7 +15,10 @@ import (
 	"github.com/l3aro/go-context-query/pkg/embed"
 	"github.com/l3aro/go-context-query/pkg/extractor"
 	"github.com/l3aro/go-context-query/pkg/index"
+	"github.com/l3aro/go-context-query/pkg/pdg"
 	"github.com/l3aro/go-context-query/pkg/search"
+	"github.com/l3aro/go-context-query/pkg/semantic"
+	"github.com/l3aro/go-context-query/pkg/symbols"
 	"github.com/l3aro/go-context-query/pkg/types"
 )
 
@@ -25,6 +29,7 @@ type Executor struct {
 	embedder  embed.Provider
 	scanner   *scanner.Scanner
 	callGraph *callgraph.Builder
+	limits    config.LimitsConfig
 }
 
 // NewExecutor creates a new fallback executor
@@ -72,6 +77,10 @@ func NewExecutor() (*Executor, error) {
 	switch providerType {
 	case config.ProviderOllama:
 		embedder, err = embed.NewOllamaProvider(embedCfg)
+	case config.ProviderLocal:
+		embedder, err = embed.NewLocalProviderFromConfig(embedCfg, cfg)
+	case config.ProviderMock:
+		embedder, err = embed.NewMockProviderFromConfig(cfg)
 	case config.ProviderHuggingFace:
 		hfModel := cfg.Warm.Model
 		if hfModel == "" {
@@ -97,6 +106,9 @@ func NewExecutor() (*Executor, error) {
 	}
 
 	dimension := 768
+	if mock, ok := embedder.(*embed.MockProvider); ok {
+		dimension = mock.Config().Dimensions
+	}
 	if providerType == config.ProviderHuggingFace {
 		modelCheck := cfg.Warm.Model
 		if modelCheck == "" {
@@ -121,16 +133,17 @@ func NewExecutor() (*Executor, error) {
 		embedder:  embedder,
 		scanner:   scanner.New(scanner.DefaultOptions()),
 		callGraph: callgraph.NewBuilder(),
+		limits:    cfg.Limits,
 	}, nil
 }
 
 // Search performs a semantic search using direct execution
 func (e *Executor) Search(ctx context.Context, params SearchParams) ([]SearchResult, error) {
-	if params.Limit <= 0 {
-		params.Limit = 10
-	}
+	params.Limit = e.limits.SearchLimit(params.Limit)
 
-	results, err := e.searcher.Search(params.Query, params.Limit)
+	opts := search.SearchOptions{Files: params.Files, IncludeTests: params.IncludeTests, ExcludeTerms: params.ExcludeTerms, Metadata: params.Metadata}
+	opts.Budget = time.Duration(params.BudgetMS) * time.Millisecond
+	results, err := e.searcher.SearchQueries(ctx, params.Mode, search.CombineQueries(params.Query, params.Queries), params.Limit, opts)
 	if err != nil {
 		return nil, fmt.Errorf("search error: %w", err)
 	}
@@ -155,6 +168,7 @@ func (e *Executor) Search(ctx context.Context, params SearchParams) ([]SearchRes
 			Docstring:  r.Docstring,
 			Type:       r.Type,
 			Score:      float64(r.Score),
+			Metadata:   r.Metadata,
 		}
 	}
 
@@ -190,12 +204,15 @@ func (e *Executor) Extract(ctx context.Context, params ExtractParams) (*ExtractR
 		}
 
 		text := moduleInfoToText(moduleInfo)
-		embeddings, err := e.embedder.Embed([]string{text})
+		embeddings, err := e.embedder.EmbedContext(ctx, []string{text})
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
 			continue
 		}
 
-		if err := e.index.Add(filePath, embeddings[0], unit); err != nil {
+		if err := e.index.Add(fileUnitKey(filePath), embeddings[0], unit); err != nil {
 			continue
 		}
 
@@ -215,9 +232,7 @@ func (e *Executor) Extract(ctx context.Context, params ExtractParams) (*ExtractR
 
 // Context gets LLM-ready context from entry point
 func (e *Executor) Context(ctx context.Context, params ContextParams) (*ContextResult, error) {
-	if params.Limit <= 0 {
-		params.Limit = 5
-	}
+	params.Limit = e.limits.ContextLimit(params.Limit)
 
 	results, err := e.searcher.Search(params.Query, params.Limit)
 	if err != nil {
@@ -291,14 +306,138 @@ func (e *Executor) Calls(ctx context.Context, params CallsParams) (*CallsResult,
 		}
 	}
 
-	return &CallsResult{
+	result := &CallsResult{
 		Function: params.Func,
 		File:     params.File,
 		Calls:    calledFuncs,
 		Count:    len(calls),
+	}
+
+	if params.Depth > 0 || params.Reverse {
+		tree, err := buildCallTree(params)
+		if err != nil {
+			return nil, fmt.Errorf("call tree error: %w", err)
+		}
+		result.Tree = tree
+	}
+
+	return result, nil
+}
+
+// Callers returns the functions calling params.Func from the semantic index
+// under params.Root, or the working directory without one
+func (e *Executor) Callers(ctx context.Context, params CallersParams) (*CallersResult, error) {
+	if params.Func == "" {
+		return nil, fmt.Errorf("func is required")
+	}
+	if params.Depth <= 0 {
+		params.Depth = 1
+	}
+	root := params.Root
+	if root == "" {
+		root = "."
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("resolving root: %w", err)
+	}
+
+	graph, err := semantic.LoadCallerGraph(absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("no semantic index for %s (run 'gcq warm'): %w", absRoot, err)
+	}
+	targets, callers, err := graph.CallersOf(params.Func, params.File, params.Depth)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CallersResult{
+		Func:         params.Func,
+		Root:         absRoot,
+		Depth:        params.Depth,
+		Targets:      make([]string, len(targets)),
+		Callers:      callers,
+		Count:        len(callers),
+		Degradations: graph.Degradations(absRoot, targets, callers),
+	}
+	for i, t := range targets {
+		result.Targets[i] = t.ID
+	}
+	return result, nil
+}
+
+// Symbols fuzzy matches params.Pattern against the symbols extracted from
+// params.Root, or the working directory without one
+func (e *Executor) Symbols(ctx context.Context, params SymbolsParams) (*SymbolsResult, error) {
+	if params.Pattern == "" {
+		return nil, fmt.Errorf("pattern is required")
+	}
+	if params.Limit <= 0 {
+		params.Limit = symbols.DefaultLimit
+	}
+	root := params.Root
+	if root == "" {
+		root = "."
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("resolving root: %w", err)
+	}
+
+	syms, err := symbols.Collect(absRoot)
+	if err != nil {
+		return nil, err
+	}
+	matches := symbols.Search(syms, params.Pattern, params.Limit)
+	if matches == nil {
+		matches = []symbols.Match{}
+	}
+	return &SymbolsResult{
+		Pattern: params.Pattern,
+		Root:    absRoot,
+		Matches: matches,
+		Count:   len(matches),
+		Total:   len(syms),
 	}, nil
 }
 
+// Slice computes a program slice directly
+func (e *Executor) Slice(ctx context.Context, params SliceParams) (*pdg.SliceResult, error) {
+	if params.File == "" || params.Line <= 0 {
+		return nil, fmt.Errorf("file and line are required")
+	}
+	return pdg.Slice(pdg.SliceQuery{
+		File:     params.File,
+		Function: params.Func,
+		Line:     params.Line,
+		Variable: params.Var,
+		Forward:  params.Direction == "forward",
+	})
+}
+
+// buildCallTree resolves the cross-file call graph around params.File and
+// returns the call tree rooted at params.Func. Without a daemon there is no
+// known project root, so the file's directory is used.
+func buildCallTree(params CallsParams) (*callgraph.CallTreeNode, error) {
+	absFile, err := filepath.Abs(params.File)
+	if err != nil {
+		return nil, fmt.Errorf("resolving file path: %w", err)
+	}
+	rootDir := filepath.Dir(absFile)
+
+	ext, err := extractor.GetLanguageRegistry().GetExtractor(absFile)
+	if err != nil {
+		return nil, err
+	}
+
+	graph, err := callgraph.BuildProjectCallGraph(rootDir, ext)
+	if err != nil {
+		return nil, err
+	}
+
+	return callgraph.BuildCallTree(graph.Edges, filepath.Base(absFile), params.Func, params.Depth, params.Reverse), nil
+}
+
 // Warm builds the semantic index for specified paths
 func (e *Executor) Warm(ctx context.Context, params WarmParams) (*WarmResult, error) {
 	if len(params.Paths) == 0 {
@@ -331,12 +470,15 @@ func (e *Executor) Warm(ctx context.Context, params WarmParams) (*WarmResult, er
 			}
 
 			text := moduleInfoToText(moduleInfo)
-			embeddings, err := e.embedder.Embed([]string{text})
+			embeddings, err := e.embedder.EmbedContext(ctx, []string{text})
 			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
 				continue
 			}
 
-			if err := e.index.Add(filePath, embeddings[0], unit); err != nil {
+			if err := e.index.Add(fileUnitKey(filePath), embeddings[0], unit); err != nil {
 				continue
 			}
 
@@ -355,6 +497,13 @@ func (e *Executor) Warm(ctx context.Context, params WarmParams) (*WarmResult, er
 	}, nil
 }
 
+// fileUnitKey returns the index key for a file-level unit: its canonical URI
+// without a symbol, matching the keys written by the daemon.
+func fileUnitKey(filePath string) string {
+	lang := scanner.DetectLanguage(filepath.Ext(filePath))
+	return types.NewUnitURI(lang, filePath, "").String()
+}
+
 // moduleInfoToText converts module info to text for embedding
 func moduleInfoToText(m *types.ModuleInfo) string {
 	var sb strings.Builder
@@ -378,7 +527,20 @@ func moduleInfoToText(m *types.ModuleInfo) string {
 		}
 	}
 
+	// Interfaces and traits are written with their method sets below; Go
+	// and Rust extractors also list them as classes
+	abstract := make(map[string]bool, len(m.Interfaces)+len(m.Traits))
+	for _, iface := range m.Interfaces {
+		abstract[iface.Name] = true
+	}
+	for _, trait := range m.Traits {
+		abstract[trait.Name] = true
+	}
+
 	for _, cls := range m.Classes {
+		if abstract[cls.Name] {
+			continue
+		}
 		sb.WriteString("class ")
 		sb.WriteString(cls.Name)
 		if len(cls.Bases) > 0 {
@@ -401,5 +563,51 @@ func moduleInfoToText(m *types.ModuleInfo) string {
 		}
 	}
 
+	for _, iface := range m.Interfaces {
+		sb.WriteString("interface ")
+		sb.WriteString(iface.Name)
+		if len(iface.Bases) > 0 {
+			sb.WriteString(" extends ")
+			sb.WriteString(strings.Join(iface.Bases, ", "))
+		}
+		sb.WriteString("\n")
+		writeMemberSignatures(&sb, iface.Docstring, iface.Methods)
+	}
+
+	for _, trait := range m.Traits {
+		sb.WriteString("trait ")
+		sb.WriteString(trait.Name)
+		sb.WriteString("\n")
+		writeMemberSignatures(&sb, trait.Docstring, trait.Methods)
+	}
+
 	return sb.String()
 }
+
+// writeMemberSignatures writes an interface or trait's docstring and the
+// full signature of each of its methods
+func writeMemberSignatures(sb *strings.Builder, docstring string, methods []types.Method) {
+	if docstring != "" {
+		sb.WriteString("  ")
+		sb.WriteString(docstring)
+		sb.WriteString("\n")
+	}
+	for _, method := range methods {
+		sb.WriteString("  ")
+		sb.WriteString(method.Name)
+		if method.Params != "" {
+			if !strings.HasPrefix(method.Params, "(") {
+				sb.WriteString("(")
+				sb.WriteString(method.Params)
+				sb.WriteString(")")
+			} else {
+				sb.WriteString(method.Params)
+			}
+		}
+		if method.ReturnType != "" {
+			sb.WriteString(" -> ")
+			sb.WriteString(method.ReturnType)
+		}
+		sb.WriteString("\n")
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/l3aro/go-context-query/pkg/search"
 )
 
 const defaultDaemonCacheTTL = 5 * time.Second
@@ -93,6 +95,14 @@ func (r *Router) Search(ctx context.Context, params SearchParams) ([]SearchResul
 	return nil, ErrDaemonNotAvailable
 }
 
+// SearchGroups performs a search with results collapsed by file or package
+func (r *Router) SearchGroups(ctx context.Context, params SearchParams) ([]search.ResultGroup, error) {
+	if r.ShouldUseDaemon() {
+		return r.client.SearchGroups(ctx, params)
+	}
+	return nil, ErrDaemonNotAvailable
+}
+
 // Extract extracts code context from a path
 func (r *Router) Extract(ctx context.Context, params ExtractParams) (*ExtractResult, error) {
 	if r.ShouldUseDaemon() {
@@ -101,6 +111,14 @@ func (r *Router) Extract(ctx context.Context, params ExtractParams) (*ExtractRes
 	return nil, ErrDaemonNotAvailable
 }
 
+// ExtractStream extracts code context from a path, reporting progress via daemon
+func (r *Router) ExtractStream(ctx context.Context, params ExtractParams, fn func(ProgressEvent) error) (*ExtractResult, error) {
+	if r.ShouldUseDaemon() {
+		return r.client.ExtractStream(ctx, params, fn)
+	}
+	return nil, ErrDaemonNotAvailable
+}
+
 // Context gets LLM-ready context from entry point
 func (r *Router) Context(ctx context.Context, params ContextParams) (*ContextResult, error) {
 	if r.ShouldUseDaemon() {
@@ -109,6 +127,14 @@ func (r *Router) Context(ctx context.Context, params ContextParams) (*ContextRes
 	return nil, ErrDaemonNotAvailable
 }
 
+// Batch runs several context queries in one request
+func (r *Router) Batch(ctx context.Context, params BatchParams) (*search.BatchResult, error) {
+	if r.ShouldUseDaemon() {
+		return r.client.Batch(ctx, params)
+	}
+	return nil, ErrDaemonNotAvailable
+}
+
 // Calls gets call graph information for a function
 func (r *Router) Calls(ctx context.Context, params CallsParams) (*CallsResult, error) {
 	if r.ShouldUseDaemon() {
@@ -117,6 +143,30 @@ func (r *Router) Calls(ctx context.Context, params CallsParams) (*CallsResult, e
 	return nil, ErrDaemonNotAvailable
 }
 
+// TextSearch performs a regex text search via daemon
+func (r *Router) TextSearch(ctx context.Context, params TextSearchParams) ([]TextMatch, error) {
+	if r.ShouldUseDaemon() {
+		return r.client.TextSearch(ctx, params)
+	}
+	return nil, ErrDaemonNotAvailable
+}
+
+// TextSearchStream performs a streamed regex text search via daemon
+func (r *Router) TextSearchStream(ctx context.Context, params TextSearchParams, fn func([]TextMatch) error) (int, error) {
+	if r.ShouldUseDaemon() {
+		return r.client.TextSearchStream(ctx, params, fn)
+	}
+	return 0, ErrDaemonNotAvailable
+}
+
+// LoadIndex loads a project semantic index into the daemon
+func (r *Router) LoadIndex(ctx context.Context, params LoadIndexParams) (*LoadIndexResult, error) {
+	if r.ShouldUseDaemon() {
+		return r.client.LoadIndex(ctx, params)
+	}
+	return nil, ErrDaemonNotAvailable
+}
+
 // Warm builds the semantic index for specified paths
 func (r *Router) Warm(ctx context.Context, params WarmParams) (*WarmResult, error) {
 	if r.ShouldUseDaemon() {
@@ -125,6 +175,70 @@ func (r *Router) Warm(ctx context.Context, params WarmParams) (*WarmResult, erro
 	return nil, ErrDaemonNotAvailable
 }
 
+// WarmStream builds the semantic index for specified paths, reporting progress via daemon
+func (r *Router) WarmStream(ctx context.Context, params WarmParams, fn func(ProgressEvent) error) (*WarmResult, error) {
+	if r.ShouldUseDaemon() {
+		return r.client.WarmStream(ctx, params, fn)
+	}
+	return nil, ErrDaemonNotAvailable
+}
+
+// ExtractAsync queues an extract as a background job via daemon
+func (r *Router) ExtractAsync(ctx context.Context, params ExtractParams) (*JobStatus, error) {
+	if r.ShouldUseDaemon() {
+		return r.client.ExtractAsync(ctx, params)
+	}
+	return nil, ErrDaemonNotAvailable
+}
+
+// WarmAsync queues a warm as a background job via daemon
+func (r *Router) WarmAsync(ctx context.Context, params WarmParams) (*JobStatus, error) {
+	if r.ShouldUseDaemon() {
+		return r.client.WarmAsync(ctx, params)
+	}
+	return nil, ErrDaemonNotAvailable
+}
+
+// JobStatus returns the status of a background job via daemon
+func (r *Router) JobStatus(ctx context.Context, id string) (*JobStatus, error) {
+	if r.ShouldUseDaemon() {
+		return r.client.JobStatus(ctx, id)
+	}
+	return nil, ErrDaemonNotAvailable
+}
+
+// CancelJob cancels a background job via daemon
+func (r *Router) CancelJob(ctx context.Context, id string) (*JobStatus, error) {
+	if r.ShouldUseDaemon() {
+		return r.client.CancelJob(ctx, id)
+	}
+	return nil, ErrDaemonNotAvailable
+}
+
+// Jobs lists the daemon's background jobs
+func (r *Router) Jobs(ctx context.Context) ([]JobStatus, error) {
+	if r.ShouldUseDaemon() {
+		return r.client.Jobs(ctx)
+	}
+	return nil, ErrDaemonNotAvailable
+}
+
+// Projects lists the projects open in the daemon
+func (r *Router) Projects(ctx context.Context) ([]ProjectInfo, error) {
+	if r.ShouldUseDaemon() {
+		return r.client.Projects(ctx)
+	}
+	return nil, ErrDaemonNotAvailable
+}
+
+// EvictProject drops a project from daemon memory
+func (r *Router) EvictProject(ctx context.Context, project string) (string, error) {
+	if r.ShouldUseDaemon() {
+		return r.client.EvictProject(ctx, project)
+	}
+	return "", ErrDaemonNotAvailable
+}
+
 // GetStatus gets daemon status
 func (r *Router) GetStatus(ctx context.Context) (*DaemonStatus, error) {
 	if r.ShouldUseDaemon() {
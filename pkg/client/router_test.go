@@ -210,6 +210,36 @@ func TestRouterWarmRequiresDaemon(t *testing.T) {
 	}
 }
 
+func TestRouterLoadIndexRequiresDaemon(t *testing.T) {
+	router := NewRouter(WithoutDaemon())
+
+	_, err := router.LoadIndex(nil, LoadIndexParams{Root: "/test"})
+	if err != ErrDaemonNotAvailable {
+		t.Errorf("Expected ErrDaemonNotAvailable, got %v", err)
+	}
+}
+
+func TestRouterTextSearchRequiresDaemon(t *testing.T) {
+	router := NewRouter(WithoutDaemon())
+
+	_, err := router.TextSearch(nil, TextSearchParams{Query: "TODO", Root: "/test"})
+	if err != ErrDaemonNotAvailable {
+		t.Errorf("Expected ErrDaemonNotAvailable, got %v", err)
+	}
+}
+
+func TestRouterTextSearchStreamRequiresDaemon(t *testing.T) {
+	router := NewRouter(WithoutDaemon())
+
+	_, err := router.TextSearchStream(nil, TextSearchParams{Query: "TODO", Root: "/test"}, func([]TextMatch) error {
+		t.Error("Expected no batches without a daemon")
+		return nil
+	})
+	if err != ErrDaemonNotAvailable {
+		t.Errorf("Expected ErrDaemonNotAvailable, got %v", err)
+	}
+}
+
 func TestRouterGetStatusRequiresDaemon(t *testing.T) {
 	router := NewRouter(WithoutDaemon())
 
@@ -0,0 +1,190 @@
+// Package deps reads project dependency manifests (go.mod, package.json,
+// pyproject.toml and requirements.txt) so imports can be classified as
+// third-party dependencies, the standard library, or the project itself,
+// and third-party imports can be labelled with their declared version.
+package deps
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Kind classifies an import
+type Kind int
+
+const (
+	// Unknown means no manifest covers the import's ecosystem
+	Unknown Kind = iota
+	// External is a third-party dependency
+	External
+	// Stdlib is part of the language's standard library
+	Stdlib
+	// Internal is code from the project itself
+	Internal
+)
+
+// Dependency is a third-party package declared in a manifest
+type Dependency struct {
+	// Name is the package name as declared, e.g. "github.com/redis/go-redis/v9"
+	Name string `json:"name"`
+	// Version is the declared version or constraint, empty when unpinned
+	Version string `json:"version,omitempty"`
+}
+
+// manifest holds the dependencies declared by one manifest file
+type manifest struct {
+	dir string
+	// module is the module or package name the manifest declares for the
+	// project itself
+	module string
+	deps   map[string]Dependency
+}
+
+// Manifests holds every manifest found under a project root, grouped by
+// ecosystem. Lookups use the manifest closest to the importing file, so
+// monorepos with several modules or packages resolve correctly.
+type Manifests struct {
+	root   string
+	goMods []*manifest
+	npm    []*manifest
+	python []*manifest
+}
+
+// skipDirs are directories never searched for manifests
+var skipDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "venv": true, "__pycache__": true,
+	"dist": true, "build": true, "target": true,
+}
+
+// Load finds and parses the dependency manifests under root. Manifests that
+// fail to parse are skipped.
+func Load(root string) (*Manifests, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifests{root: absRoot}
+	err = filepath.WalkDir(absRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != absRoot && (skipDirs[name] || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		dir := filepath.Dir(path)
+		switch d.Name() {
+		case "go.mod":
+			if mf, err := parseGoModFile(path); err == nil {
+				mf.dir = dir
+				m.goMods = append(m.goMods, mf)
+			}
+		case "package.json":
+			if mf, err := parsePackageJSONFile(path); err == nil {
+				mf.dir = dir
+				m.npm = append(m.npm, mf)
+			}
+		case "pyproject.toml", "requirements.txt":
+			mf, err := parsePythonFile(path)
+			if err != nil {
+				return nil
+			}
+			mf.dir = dir
+			m.python = mergePython(m.python, mf)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Deepest manifests first, so nearest() finds the closest ancestor
+	for _, list := range [][]*manifest{m.goMods, m.npm, m.python} {
+		sort.SliceStable(list, func(i, j int) bool {
+			return len(list[i].dir) > len(list[j].dir)
+		})
+	}
+	return m, nil
+}
+
+// mergePython combines pyproject.toml and requirements.txt in one directory
+func mergePython(list []*manifest, mf *manifest) []*manifest {
+	for _, existing := range list {
+		if existing.dir == mf.dir {
+			for key, dep := range mf.deps {
+				if _, ok := existing.deps[key]; !ok {
+					existing.deps[key] = dep
+				}
+			}
+			if existing.module == "" {
+				existing.module = mf.module
+			}
+			return list
+		}
+	}
+	return append(list, mf)
+}
+
+// Empty reports whether no manifests were found
+func (m *Manifests) Empty() bool {
+	return m == nil || len(m.goMods)+len(m.npm)+len(m.python) == 0
+}
+
+// Fingerprint describes every manifest found, one line per declared
+// dependency in a stable order, so callers can tell when they changed
+func (m *Manifests) Fingerprint() string {
+	if m == nil {
+		return ""
+	}
+	var lines []string
+	for _, list := range [][]*manifest{m.goMods, m.npm, m.python} {
+		for _, mf := range list {
+			dir, _ := filepath.Rel(m.root, mf.dir)
+			lines = append(lines, filepath.ToSlash(dir)+" "+mf.module)
+			for key, dep := range mf.deps {
+				lines = append(lines, filepath.ToSlash(dir)+" "+key+" "+dep.Name+"@"+dep.Version)
+			}
+		}
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// nearest returns the manifest in list closest to filePath
+func (m *Manifests) nearest(list []*manifest, filePath string) *manifest {
+	if !filepath.IsAbs(filePath) {
+		filePath = filepath.Join(m.root, filePath)
+	}
+	for _, mf := range list {
+		if mf.dir == filePath || strings.HasPrefix(filePath, mf.dir+string(filepath.Separator)) {
+			return mf
+		}
+	}
+	return nil
+}
+
+// Resolve classifies the import of module by a file of the given language.
+// filePath may be absolute or relative to the project root. For External
+// imports the declared dependency is returned; its Name is the package the
+// import belongs to, which may be shorter than the import path.
+func (m *Manifests) Resolve(filePath, language, module string) (Dependency, Kind) {
+	if m == nil || module == "" {
+		return Dependency{}, Unknown
+	}
+	switch language {
+	case "go":
+		return resolveGo(m.nearest(m.goMods, filePath), module)
+	case "javascript", "typescript", "vue", "svelte":
+		return resolveNPM(m.nearest(m.npm, filePath), module)
+	case "python":
+		return resolvePython(m.nearest(m.python, filePath), module)
+	}
+	return Dependency{}, Unknown
+}
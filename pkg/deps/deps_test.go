@@ -0,0 +1,217 @@
+package deps
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestParseGoMod(t *testing.T) {
+	mf := parseGoMod([]byte(`module example.com/app
+
+go 1.22
+
+require (
+	github.com/redis/go-redis/v9 v9.5.1
+	github.com/spf13/cobra v1.8.0 // indirect
+)
+
+require gopkg.in/yaml.v3 v3.0.1
+
+replace github.com/spf13/cobra => github.com/fork/cobra v1.9.0
+`))
+
+	if mf.module != "example.com/app" {
+		t.Errorf("module = %q, want example.com/app", mf.module)
+	}
+	want := map[string]string{
+		"github.com/redis/go-redis/v9": "v9.5.1",
+		"github.com/spf13/cobra":       "v1.9.0",
+		"gopkg.in/yaml.v3":             "v3.0.1",
+	}
+	for name, version := range want {
+		if got := mf.deps[name].Version; got != version {
+			t.Errorf("%s version = %q, want %q", name, got, version)
+		}
+	}
+}
+
+func TestResolveGo(t *testing.T) {
+	mf := parseGoMod([]byte("module example.com/app\nrequire github.com/redis/go-redis/v9 v9.5.1\n"))
+
+	tests := []struct {
+		importPath string
+		wantName   string
+		wantKind   Kind
+	}{
+		{"fmt", "", Stdlib},
+		{"net/http", "", Stdlib},
+		{"example.com/app/internal/store", "", Internal},
+		{"github.com/redis/go-redis/v9", "github.com/redis/go-redis/v9", External},
+		{"github.com/redis/go-redis/v9/internal/pool", "github.com/redis/go-redis/v9", External},
+		{"github.com/other/lib", "github.com/other/lib", External},
+	}
+	for _, tt := range tests {
+		dep, kind := resolveGo(mf, tt.importPath)
+		if kind != tt.wantKind || dep.Name != tt.wantName {
+			t.Errorf("resolveGo(%q) = %q, %v; want %q, %v", tt.importPath, dep.Name, kind, tt.wantName, tt.wantKind)
+		}
+	}
+}
+
+func TestResolveNPM(t *testing.T) {
+	mf, err := parsePackageJSON([]byte(`{
+		"name": "web",
+		"dependencies": {"ioredis": "^5.3.2", "@tanstack/react-query": "5.0.0"},
+		"devDependencies": {"vitest": "^1.0.0"}
+	}`))
+	if err != nil {
+		t.Fatalf("parsePackageJSON failed: %v", err)
+	}
+
+	tests := []struct {
+		specifier   string
+		wantName    string
+		wantVersion string
+		wantKind    Kind
+	}{
+		{"ioredis", "ioredis", "^5.3.2", External},
+		{"@tanstack/react-query/devtools", "@tanstack/react-query", "5.0.0", External},
+		{"vitest", "vitest", "^1.0.0", External},
+		{"fs", "", "", Stdlib},
+		{"node:path", "", "", Stdlib},
+		{"./utils", "", "", Internal},
+		{"@/components/Button", "", "", Internal},
+	}
+	for _, tt := range tests {
+		dep, kind := resolveNPM(mf, tt.specifier)
+		if kind != tt.wantKind || dep.Name != tt.wantName || dep.Version != tt.wantVersion {
+			t.Errorf("resolveNPM(%q) = %+v, %v; want %q@%q, %v", tt.specifier, dep, kind, tt.wantName, tt.wantVersion, tt.wantKind)
+		}
+	}
+}
+
+func TestParsePyProject(t *testing.T) {
+	mf := parsePyProject([]byte(`[project]
+name = "my_app"
+dependencies = [
+    "redis[hiredis]>=5.0",
+    "PyYAML==6.0.1",
+    "requests ; python_version >= '3.8'",
+]
+
+[tool.poetry.dependencies]
+python = "^3.11"
+Django = {version = "^4.2", extras = ["argon2"]}
+`))
+
+	if mf.module != "my-app" {
+		t.Errorf("module = %q, want my-app", mf.module)
+	}
+	want := map[string]string{
+		"redis":    ">=5.0",
+		"pyyaml":   "==6.0.1",
+		"requests": "",
+		"django":   "^4.2",
+	}
+	for name, version := range want {
+		dep, ok := mf.deps[name]
+		if !ok || dep.Version != version {
+			t.Errorf("%s = %+v (found %v), want version %q", name, dep, ok, version)
+		}
+	}
+	if _, ok := mf.deps["python"]; ok {
+		t.Error("python constraint should not be a dependency")
+	}
+}
+
+func TestResolvePython(t *testing.T) {
+	mf := parseRequirements([]byte("# deps\nredis>=5.0\nPyYAML==6.0.1\nscikit_learn\n-r dev.txt\n"))
+	mf.module = "myapp"
+
+	tests := []struct {
+		module   string
+		wantName string
+		wantKind Kind
+	}{
+		{"redis.asyncio", "redis", External},
+		{"yaml", "PyYAML", External},
+		{"sklearn.linear_model", "scikit_learn", External},
+		{"os", "", Stdlib},
+		{"myapp.models", "", Internal},
+		{".models", "", Internal},
+	}
+	for _, tt := range tests {
+		dep, kind := resolvePython(mf, tt.module)
+		if kind != tt.wantKind || dep.Name != tt.wantName {
+			t.Errorf("resolvePython(%q) = %q, %v; want %q, %v", tt.module, dep.Name, kind, tt.wantName, tt.wantKind)
+		}
+	}
+}
+
+func TestLoadNearestManifest(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/root\nrequire github.com/a/lib v1.0.0\n")
+	writeFile(t, filepath.Join(root, "services", "api", "go.mod"), "module example.com/api\nrequire github.com/a/lib v2.0.0\n")
+	writeFile(t, filepath.Join(root, "web", "package.json"), `{"dependencies": {"ioredis": "^5.0.0"}}`)
+	writeFile(t, filepath.Join(root, "web", "node_modules", "x", "package.json"), `{"dependencies": {"leftpad": "1.0.0"}}`)
+
+	m, err := Load(root)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if m.Empty() {
+		t.Fatal("Expected manifests to be found")
+	}
+
+	if dep, _ := m.Resolve("main.go", "go", "github.com/a/lib"); dep.Version != "v1.0.0" {
+		t.Errorf("root file resolved %+v, want v1.0.0", dep)
+	}
+	if dep, _ := m.Resolve(filepath.Join(root, "services", "api", "handler.go"), "go", "github.com/a/lib"); dep.Version != "v2.0.0" {
+		t.Errorf("nested module resolved %+v, want v2.0.0", dep)
+	}
+	if _, kind := m.Resolve("services/api/handler.go", "go", "example.com/api/store"); kind != Internal {
+		t.Errorf("Expected own module import to be Internal, got %v", kind)
+	}
+	if dep, kind := m.Resolve("web/src/cache.ts", "typescript", "ioredis"); kind != External || dep.Version != "^5.0.0" {
+		t.Errorf("ioredis resolved %+v, %v", dep, kind)
+	}
+	if _, kind := m.Resolve("scripts/run.py", "python", "redis"); kind != Unknown {
+		t.Errorf("Expected Unknown without a Python manifest, got %v", kind)
+	}
+	if len(m.npm) != 1 {
+		t.Errorf("Expected node_modules to be skipped, found %d package.json files", len(m.npm))
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/root\nrequire github.com/a/lib v1.0.0\n")
+
+	load := func() string {
+		m, err := Load(root)
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		return m.Fingerprint()
+	}
+	before := load()
+	if before == "" || load() != before {
+		t.Fatalf("Fingerprint() = %q, want a stable description", before)
+	}
+
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/root\nrequire github.com/a/lib v1.1.0\n")
+	if load() == before {
+		t.Error("Fingerprint() did not change with a dependency version")
+	}
+}
@@ -0,0 +1,125 @@
+package deps
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strings"
+)
+
+// parseGoModFile reads the module path and requirements from a go.mod file
+func parseGoModFile(path string) (*manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseGoMod(data), nil
+}
+
+// GoModulePath returns the module path declared by the go.mod file at path
+func GoModulePath(path string) (string, error) {
+	mf, err := parseGoModFile(path)
+	if err != nil {
+		return "", err
+	}
+	return mf.module, nil
+}
+
+// parseGoMod parses the module, require and replace directives of a go.mod
+// file. Replaced modules keep the version of their replacement when it has
+// one.
+func parseGoMod(data []byte) *manifest {
+	mf := &manifest{deps: make(map[string]Dependency)}
+	replaced := make(map[string]string)
+
+	block := ""
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if block != "" {
+			if fields[0] == ")" {
+				block = ""
+				continue
+			}
+			fields = append([]string{block}, fields...)
+		} else if len(fields) == 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+
+		switch fields[0] {
+		case "module":
+			if len(fields) >= 2 {
+				mf.module = unquote(fields[1])
+			}
+		case "require":
+			if len(fields) >= 3 {
+				name := unquote(fields[1])
+				mf.deps[name] = Dependency{Name: name, Version: fields[2]}
+			}
+		case "replace":
+			// old [version] => new [version]
+			for i, f := range fields {
+				if f == "=>" && i+2 < len(fields) {
+					replaced[unquote(fields[1])] = fields[i+2]
+				}
+			}
+		}
+	}
+
+	for name, version := range replaced {
+		if dep, ok := mf.deps[name]; ok {
+			dep.Version = version
+			mf.deps[name] = dep
+		}
+	}
+	return mf
+}
+
+// resolveGo classifies a Go import path using the nearest go.mod
+func resolveGo(mf *manifest, importPath string) (Dependency, Kind) {
+	first, _, _ := strings.Cut(importPath, "/")
+	if !strings.Contains(first, ".") {
+		// Standard library paths have no dot in their first element
+		if mf != nil && mf.module != "" && hasPathPrefix(importPath, mf.module) {
+			return Dependency{}, Internal
+		}
+		return Dependency{}, Stdlib
+	}
+	if mf == nil {
+		return Dependency{Name: importPath}, Unknown
+	}
+	if mf.module != "" && hasPathPrefix(importPath, mf.module) {
+		return Dependency{}, Internal
+	}
+
+	// The longest required module that prefixes the import path owns it
+	var best Dependency
+	for name, dep := range mf.deps {
+		if hasPathPrefix(importPath, name) && len(name) > len(best.Name) {
+			best = dep
+		}
+	}
+	if best.Name != "" {
+		return best, External
+	}
+	// Not listed (e.g. pruned indirect requirement): still third-party
+	return Dependency{Name: importPath}, External
+}
+
+// hasPathPrefix reports whether path is prefix or lies below it
+func hasPathPrefix(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+func unquote(s string) string {
+	return strings.Trim(s, "\"`")
+}
@@ -0,0 +1,94 @@
+package deps
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// packageJSON is the subset of package.json read for dependencies
+type packageJSON struct {
+	Name                 string            `json:"name"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// nodeBuiltins are the Node.js core modules
+var nodeBuiltins = map[string]bool{
+	"assert": true, "async_hooks": true, "buffer": true, "child_process": true,
+	"cluster": true, "console": true, "crypto": true, "dgram": true, "dns": true,
+	"events": true, "fs": true, "http": true, "http2": true, "https": true,
+	"module": true, "net": true, "os": true, "path": true, "perf_hooks": true,
+	"process": true, "querystring": true, "readline": true, "stream": true,
+	"string_decoder": true, "timers": true, "tls": true, "tty": true, "url": true,
+	"util": true, "v8": true, "vm": true, "worker_threads": true, "zlib": true,
+}
+
+// parsePackageJSONFile reads the dependencies declared in a package.json
+func parsePackageJSONFile(path string) (*manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parsePackageJSON(data)
+}
+
+func parsePackageJSON(data []byte) (*manifest, error) {
+	var pkg packageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("parsing package.json: %w", err)
+	}
+
+	mf := &manifest{module: pkg.Name, deps: make(map[string]Dependency)}
+	// Runtime dependencies win over dev, peer and optional declarations
+	for _, group := range []map[string]string{pkg.OptionalDependencies, pkg.PeerDependencies, pkg.DevDependencies, pkg.Dependencies} {
+		for name, version := range group {
+			mf.deps[name] = Dependency{Name: name, Version: version}
+		}
+	}
+	return mf, nil
+}
+
+// npmPackageName returns the package a bare import specifier belongs to:
+// "lodash/fp" -> "lodash", "@scope/pkg/sub" -> "@scope/pkg"
+func npmPackageName(specifier string) string {
+	parts := strings.Split(specifier, "/")
+	if strings.HasPrefix(specifier, "@") && len(parts) >= 2 {
+		return parts[0] + "/" + parts[1]
+	}
+	return parts[0]
+}
+
+// resolveNPM classifies a JavaScript or TypeScript import specifier using
+// the nearest package.json
+func resolveNPM(mf *manifest, specifier string) (Dependency, Kind) {
+	if strings.HasPrefix(specifier, ".") || strings.HasPrefix(specifier, "/") {
+		return Dependency{}, Internal
+	}
+	if strings.HasPrefix(specifier, "node:") {
+		return Dependency{}, Stdlib
+	}
+
+	name := npmPackageName(specifier)
+	if mf == nil {
+		if nodeBuiltins[name] {
+			return Dependency{}, Stdlib
+		}
+		return Dependency{Name: name}, Unknown
+	}
+	if dep, ok := mf.deps[name]; ok {
+		return dep, External
+	}
+	if nodeBuiltins[name] {
+		return Dependency{}, Stdlib
+	}
+	if mf.module != "" && name == mf.module {
+		return Dependency{}, Internal
+	}
+	// Bare specifiers missing from package.json are path aliases
+	// (e.g. "@/components") or workspace packages
+	return Dependency{}, Internal
+}
@@ -0,0 +1,203 @@
+package deps
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// pythonAliases maps import names to the distribution that provides them
+// where the two differ
+var pythonAliases = map[string]string{
+	"yaml":     "pyyaml",
+	"cv2":      "opencv-python",
+	"pil":      "pillow",
+	"sklearn":  "scikit-learn",
+	"bs4":      "beautifulsoup4",
+	"dateutil": "python-dateutil",
+	"jwt":      "pyjwt",
+	"dotenv":   "python-dotenv",
+}
+
+// parsePythonFile reads a pyproject.toml or requirements.txt
+func parsePythonFile(path string) (*manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if filepath.Base(path) == "pyproject.toml" {
+		return parsePyProject(data), nil
+	}
+	return parseRequirements(data), nil
+}
+
+// parseRequirements parses requirements.txt lines such as "redis>=4.0"
+func parseRequirements(data []byte) *manifest {
+	mf := &manifest{deps: make(map[string]Dependency)}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			continue
+		}
+		addPEP508(mf, line)
+	}
+	return mf
+}
+
+// parsePyProject reads PEP 621 [project] dependencies and Poetry's
+// [tool.poetry.dependencies]. It understands only the subset of TOML those
+// tables use in practice.
+func parsePyProject(data []byte) *manifest {
+	mf := &manifest{deps: make(map[string]Dependency)}
+
+	table := ""
+	inArray := false
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if inArray {
+			// Inside a multi-line dependencies = [ ... ] array
+			for _, item := range tomlStrings(line) {
+				addPEP508(mf, item)
+			}
+			if strings.Contains(line, "]") && !strings.Contains(line, "[") {
+				inArray = false
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			table = strings.Trim(line, "[] ")
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.Trim(strings.TrimSpace(key), "\"'")
+		value = strings.TrimSpace(value)
+
+		switch table {
+		case "project", "tool.poetry":
+			switch key {
+			case "name":
+				if mf.module == "" {
+					mf.module = normalizePython(strings.Trim(value, "\"'"))
+				}
+			case "dependencies":
+				if !strings.HasPrefix(value, "[") {
+					continue
+				}
+				for _, item := range tomlStrings(value) {
+					addPEP508(mf, item)
+				}
+				inArray = !strings.HasSuffix(value, "]")
+			}
+		case "tool.poetry.dependencies", "tool.poetry.dev-dependencies", "tool.poetry.group.dev.dependencies":
+			if key == "python" {
+				continue
+			}
+			version := strings.Trim(value, "\"'")
+			if strings.HasPrefix(value, "{") {
+				version = inlineTableValue(value, "version")
+			}
+			name := normalizePython(key)
+			mf.deps[name] = Dependency{Name: key, Version: version}
+		}
+	}
+	return mf
+}
+
+// tomlStrings returns the quoted strings on a line of a TOML array
+func tomlStrings(line string) []string {
+	var out []string
+	for {
+		start := strings.IndexAny(line, "\"'")
+		if start < 0 {
+			return out
+		}
+		quote := line[start]
+		end := strings.IndexByte(line[start+1:], quote)
+		if end < 0 {
+			return out
+		}
+		out = append(out, line[start+1:start+1+end])
+		line = line[start+end+2:]
+	}
+}
+
+// inlineTableValue reads key from a TOML inline table like {version = "^1"}
+func inlineTableValue(table, key string) string {
+	for _, part := range strings.Split(strings.Trim(table, "{}"), ",") {
+		k, v, ok := strings.Cut(part, "=")
+		if ok && strings.TrimSpace(k) == key {
+			return strings.Trim(strings.TrimSpace(v), "\"'")
+		}
+	}
+	return ""
+}
+
+// addPEP508 adds a requirement such as "redis[hiredis]>=4.0; python_version>'3'"
+func addPEP508(mf *manifest, req string) {
+	req, _, _ = strings.Cut(req, ";")
+	req = strings.TrimSpace(req)
+	end := strings.IndexAny(req, "[<>=!~ @(")
+	name, version := req, ""
+	if end >= 0 {
+		name = req[:end]
+		version = req[end:]
+		if i := strings.IndexByte(version, ']'); strings.HasPrefix(version, "[") && i >= 0 {
+			version = version[i+1:]
+		}
+		version = strings.Trim(strings.TrimSpace(version), "()")
+	}
+	if name == "" {
+		return
+	}
+	mf.deps[normalizePython(name)] = Dependency{Name: name, Version: version}
+}
+
+// normalizePython normalizes a distribution name per PEP 503
+func normalizePython(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer("_", "-", ".", "-").Replace(name)
+}
+
+// resolvePython classifies a Python import using the nearest
+// pyproject.toml or requirements.txt
+func resolvePython(mf *manifest, module string) (Dependency, Kind) {
+	if strings.HasPrefix(module, ".") {
+		return Dependency{}, Internal
+	}
+	if mf == nil {
+		return Dependency{Name: module}, Unknown
+	}
+
+	top, _, _ := strings.Cut(module, ".")
+	name := normalizePython(top)
+	if dep, ok := mf.deps[name]; ok {
+		return dep, External
+	}
+	if alias, ok := pythonAliases[name]; ok {
+		if dep, ok := mf.deps[alias]; ok {
+			return dep, External
+		}
+	}
+	if mf.module != "" && name == mf.module {
+		return Dependency{}, Internal
+	}
+	// Unlisted imports are the standard library or the project's own
+	// packages; either way they are not third-party
+	return Dependency{}, Stdlib
+}
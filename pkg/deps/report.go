@@ -0,0 +1,223 @@
+package deps
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// Usage is one dependency aggregated across the project
+type Usage struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	// Ecosystem is the package registry: "golang", "npm" or "pypi"
+	Ecosystem string `json:"ecosystem"`
+	// Units is the number of code units whose file imports the dependency
+	Units int `json:"units"`
+	// Files is the number of files importing the dependency
+	Files int `json:"files"`
+	// Locations lists the units using the dependency
+	Locations []Location `json:"locations"`
+}
+
+// Location is a unit that uses a dependency
+type Location struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Unit string `json:"unit"`
+}
+
+// String formats the location as "file:line#unit"
+func (l Location) String() string {
+	return fmt.Sprintf("%s:%d#%s", l.File, l.Line, l.Unit)
+}
+
+// Report is a project-level list of third-party dependencies
+type Report struct {
+	Root         string   `json:"root"`
+	Dependencies []*Usage `json:"dependencies"`
+}
+
+// ecosystems maps unit languages to purl package types
+var ecosystems = map[string]string{
+	"go":         "golang",
+	"javascript": "npm",
+	"typescript": "npm",
+	"python":     "pypi",
+}
+
+// BuildReport aggregates the dependencies attached to each unit into a
+// project-level report, most used first
+func BuildReport(root string, units []*types.CodeUnit) *Report {
+	byKey := make(map[string]*Usage)
+	files := make(map[string]map[string]bool)
+
+	for _, unit := range units {
+		ecosystem := ecosystems[unit.Language]
+		if ecosystem == "" {
+			ecosystem = unit.Language
+		}
+		for _, name := range unit.Dependencies {
+			key := ecosystem + ":" + name
+			usage, ok := byKey[key]
+			if !ok {
+				usage = &Usage{Name: name, Ecosystem: ecosystem}
+				byKey[key] = usage
+				files[key] = make(map[string]bool)
+			}
+			if usage.Version == "" {
+				usage.Version = unit.DependencyVersions[name]
+			}
+			usage.Units++
+			usage.Locations = append(usage.Locations, Location{File: unit.FilePath, Line: unit.LineNumber, Unit: unit.Name})
+			files[key][unit.FilePath] = true
+		}
+	}
+
+	report := &Report{Root: root, Dependencies: make([]*Usage, 0, len(byKey))}
+	for key, usage := range byKey {
+		usage.Files = len(files[key])
+		sort.SliceStable(usage.Locations, func(i, j int) bool {
+			a, b := usage.Locations[i], usage.Locations[j]
+			if a.File != b.File {
+				return a.File < b.File
+			}
+			return a.Line < b.Line
+		})
+		report.Dependencies = append(report.Dependencies, usage)
+	}
+	sort.Slice(report.Dependencies, func(i, j int) bool {
+		a, b := report.Dependencies[i], report.Dependencies[j]
+		if a.Units != b.Units {
+			return a.Units > b.Units
+		}
+		if a.Ecosystem != b.Ecosystem {
+			return a.Ecosystem < b.Ecosystem
+		}
+		return a.Name < b.Name
+	})
+	return report
+}
+
+// CycloneDX types cover the subset of the CycloneDX 1.5 JSON format the
+// report needs
+type CycloneDX struct {
+	BOMFormat   string               `json:"bomFormat"`
+	SpecVersion string               `json:"specVersion"`
+	Version     int                  `json:"version"`
+	Metadata    CycloneDXMetadata    `json:"metadata"`
+	Components  []CycloneDXComponent `json:"components"`
+}
+
+// CycloneDXMetadata describes the BOM and the project it covers
+type CycloneDXMetadata struct {
+	Timestamp string              `json:"timestamp"`
+	Tools     CycloneDXTools      `json:"tools"`
+	Component *CycloneDXComponent `json:"component,omitempty"`
+}
+
+// CycloneDXTools lists the tools that produced the BOM
+type CycloneDXTools struct {
+	Components []CycloneDXComponent `json:"components"`
+}
+
+// CycloneDXComponent is one library or application in the BOM
+type CycloneDXComponent struct {
+	Type       string              `json:"type"`
+	BOMRef     string              `json:"bom-ref,omitempty"`
+	Name       string              `json:"name"`
+	Version    string              `json:"version,omitempty"`
+	PURL       string              `json:"purl,omitempty"`
+	Properties []CycloneDXProperty `json:"properties,omitempty"`
+	Evidence   *CycloneDXEvidence  `json:"evidence,omitempty"`
+}
+
+// CycloneDXProperty is a name/value pair attached to a component
+type CycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CycloneDXEvidence records where a component is used
+type CycloneDXEvidence struct {
+	Occurrences []CycloneDXOccurrence `json:"occurrences,omitempty"`
+}
+
+// CycloneDXOccurrence is one place a component is used
+type CycloneDXOccurrence struct {
+	Location string `json:"location"`
+}
+
+// CycloneDX converts the report to a CycloneDX BOM. Usage counts are
+// recorded as gcq:units and gcq:files properties and locations as evidence
+// occurrences. toolVersion is the gcq version recorded in the metadata.
+func (r *Report) CycloneDX(projectName, toolVersion string, now time.Time) *CycloneDX {
+	bom := &CycloneDX{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Metadata: CycloneDXMetadata{
+			Timestamp: now.UTC().Format(time.RFC3339),
+			Tools: CycloneDXTools{Components: []CycloneDXComponent{
+				{Type: "application", Name: "gcq", Version: toolVersion},
+			}},
+		},
+		Components: make([]CycloneDXComponent, 0, len(r.Dependencies)),
+	}
+	if projectName != "" {
+		bom.Metadata.Component = &CycloneDXComponent{Type: "application", Name: projectName}
+	}
+
+	for _, usage := range r.Dependencies {
+		purl := PackageURL(usage.Ecosystem, usage.Name, usage.Version)
+		component := CycloneDXComponent{
+			Type:    "library",
+			BOMRef:  purl,
+			Name:    usage.Name,
+			Version: usage.Version,
+			PURL:    purl,
+			Properties: []CycloneDXProperty{
+				{Name: "gcq:units", Value: strconv.Itoa(usage.Units)},
+				{Name: "gcq:files", Value: strconv.Itoa(usage.Files)},
+			},
+		}
+		if len(usage.Locations) > 0 {
+			component.Evidence = &CycloneDXEvidence{}
+			for _, loc := range usage.Locations {
+				component.Evidence.Occurrences = append(component.Evidence.Occurrences, CycloneDXOccurrence{Location: loc.String()})
+			}
+		}
+		bom.Components = append(bom.Components, component)
+	}
+	return bom
+}
+
+// PackageURL builds a purl such as "pkg:npm/%40scope/name@1.0.0". The
+// version is only included when it is exact; ranges like "^1.2" are left
+// out because a purl names a single release.
+func PackageURL(ecosystem, name, version string) string {
+	segments := strings.Split(name, "/")
+	for i, s := range segments {
+		segments[i] = strings.ReplaceAll(url.PathEscape(s), "@", "%40")
+	}
+	purl := "pkg:" + ecosystem + "/" + strings.Join(segments, "/")
+	if v := exactVersion(version); v != "" {
+		purl += "@" + strings.ReplaceAll(url.PathEscape(v), "@", "%40")
+	}
+	return purl
+}
+
+// exactVersion returns version without a leading "==" or "=", or "" when it
+// is a range
+func exactVersion(version string) string {
+	v := strings.TrimPrefix(strings.TrimPrefix(version, "=="), "=")
+	if v == "" || v == "latest" || strings.ContainsAny(v, "^~<>=*!, |") {
+		return ""
+	}
+	return v
+}
@@ -0,0 +1,87 @@
+package deps
+
+import (
+	"testing"
+	"time"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+func TestBuildReport(t *testing.T) {
+	redis := "github.com/redis/go-redis/v9"
+	units := []*types.CodeUnit{
+		{Name: "Get", Language: "go", FilePath: "cache/cache.go", LineNumber: 20, Dependencies: []string{redis}, DependencyVersions: map[string]string{redis: "v9.5.1"}},
+		{Name: "New", Language: "go", FilePath: "cache/cache.go", LineNumber: 10, Dependencies: []string{redis}, DependencyVersions: map[string]string{redis: "v9.5.1"}},
+		{Name: "Publish", Language: "go", FilePath: "events/pub.go", LineNumber: 5, Dependencies: []string{redis, "github.com/google/uuid"}},
+		{Name: "connect", Language: "python", FilePath: "scripts/cache.py", LineNumber: 3, Dependencies: []string{"redis"}},
+	}
+
+	report := BuildReport("/project", units)
+	if len(report.Dependencies) != 3 {
+		t.Fatalf("Expected 3 dependencies, got %d", len(report.Dependencies))
+	}
+
+	top := report.Dependencies[0]
+	if top.Name != redis || top.Ecosystem != "golang" || top.Version != "v9.5.1" {
+		t.Errorf("Expected go-redis first, got %+v", top)
+	}
+	if top.Units != 3 || top.Files != 2 {
+		t.Errorf("Expected 3 units in 2 files, got %d units in %d files", top.Units, top.Files)
+	}
+	if got := top.Locations[0].String(); got != "cache/cache.go:10#New" {
+		t.Errorf("Expected locations sorted by file and line, got %s first", got)
+	}
+
+	for _, usage := range report.Dependencies[1:] {
+		if usage.Name == "redis" && usage.Ecosystem != "pypi" {
+			t.Errorf("Python redis should be a separate pypi dependency, got %+v", usage)
+		}
+	}
+}
+
+func TestReportCycloneDX(t *testing.T) {
+	report := &Report{Dependencies: []*Usage{
+		{Name: "@tanstack/react-query", Version: "5.0.0", Ecosystem: "npm", Units: 2, Files: 1,
+			Locations: []Location{{File: "src/app.ts", Line: 4, Unit: "App"}}},
+		{Name: "redis", Version: ">=5.0", Ecosystem: "pypi", Units: 1, Files: 1},
+	}}
+
+	bom := report.CycloneDX("web", "1.2.3", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	if bom.BOMFormat != "CycloneDX" || bom.SpecVersion != "1.5" {
+		t.Errorf("Unexpected header: %s %s", bom.BOMFormat, bom.SpecVersion)
+	}
+	if bom.Metadata.Timestamp != "2026-01-02T03:04:05Z" || bom.Metadata.Component.Name != "web" {
+		t.Errorf("Unexpected metadata: %+v", bom.Metadata)
+	}
+	if len(bom.Components) != 2 {
+		t.Fatalf("Expected 2 components, got %d", len(bom.Components))
+	}
+
+	npm := bom.Components[0]
+	if npm.PURL != "pkg:npm/%40tanstack/react-query@5.0.0" {
+		t.Errorf("purl = %s", npm.PURL)
+	}
+	if npm.Evidence == nil || npm.Evidence.Occurrences[0].Location != "src/app.ts:4#App" {
+		t.Errorf("Expected location evidence, got %+v", npm.Evidence)
+	}
+	if npm.Properties[0].Name != "gcq:units" || npm.Properties[0].Value != "2" {
+		t.Errorf("Expected units property, got %+v", npm.Properties)
+	}
+
+	if pypi := bom.Components[1]; pypi.PURL != "pkg:pypi/redis" || pypi.Evidence != nil {
+		t.Errorf("Expected range version left out of purl, got %+v", pypi)
+	}
+}
+
+func TestPackageURLExactVersion(t *testing.T) {
+	tests := map[string]string{
+		"==6.0.1": "pkg:pypi/pyyaml@6.0.1",
+		"^6.0":    "pkg:pypi/pyyaml",
+		"":        "pkg:pypi/pyyaml",
+	}
+	for version, want := range tests {
+		if got := PackageURL("pypi", "pyyaml", version); got != want {
+			t.Errorf("PackageURL(%q) = %s, want %s", version, got, want)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/smacker/go-tree-sitter/c"
 
 	"github.com/l3aro/go-context-query/pkg/cfg"
+	"github.com/l3aro/go-context-query/pkg/types"
 )
 
 type cDefUseVisitor struct {
@@ -147,7 +148,7 @@ func (v *cDefUseVisitor) extractParameterDeclaration(paramNode *sitter.Node) {
 					Name:    name,
 					RefType: RefTypeDefinition,
 					Line:    int(child.StartPoint().Row) + 1,
-					Column:  int(child.StartPoint().Column) + 1,
+					Column:  nodeColumn(v.content, child) + 1,
 				}
 				v.addRef(ref)
 			}
@@ -215,7 +216,7 @@ func (v *cDefUseVisitor) processDeclaration(node *sitter.Node) {
 						Name:    name,
 						RefType: RefTypeDefinition,
 						Line:    int(nameNode.StartPoint().Row) + 1,
-						Column:  int(nameNode.StartPoint().Column) + 1,
+						Column:  nodeColumn(v.content, nameNode) + 1,
 					}
 					v.addRef(ref)
 				}
@@ -368,7 +369,7 @@ func (v *cDefUseVisitor) extractUses(node *sitter.Node) {
 					Name:    name,
 					RefType: RefTypeUse,
 					Line:    int(node.StartPoint().Row) + 1,
-					Column:  int(node.StartPoint().Column) + 1,
+					Column:  nodeColumn(v.content, node) + 1,
 				}
 				v.addRef(ref)
 			}
@@ -387,7 +388,7 @@ func (v *cDefUseVisitor) extractUses(node *sitter.Node) {
 
 func (v *cDefUseVisitor) isDefinitionAtPosition(node *sitter.Node) bool {
 	line := int(node.StartPoint().Row) + 1
-	col := int(node.StartPoint().Column) + 1
+	col := nodeColumn(v.content, node) + 1
 
 	for _, ref := range v.refs {
 		if ref.Line == line && ref.Column == col {
@@ -411,7 +412,7 @@ func (v *cDefUseVisitor) extractIdentifiersFromNode(node *sitter.Node, refType R
 				Name:    name,
 				RefType: refType,
 				Line:    int(node.StartPoint().Row) + 1,
-				Column:  int(node.StartPoint().Column) + 1,
+				Column:  nodeColumn(v.content, node) + 1,
 			}
 			v.addRef(ref)
 		}
@@ -475,12 +476,7 @@ func nodeTextC(node *sitter.Node, content []byte) string {
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
 
 func isCBuiltin(name string) bool {
@@ -8,6 +8,7 @@ import (
 	"github.com/smacker/go-tree-sitter/cpp"
 
 	"github.com/l3aro/go-context-query/pkg/cfg"
+	"github.com/l3aro/go-context-query/pkg/types"
 )
 
 type cppDefUseVisitor struct {
@@ -160,7 +161,7 @@ func (v *cppDefUseVisitor) extractParameterDeclaration(paramNode *sitter.Node) {
 					Name:    name,
 					RefType: RefTypeDefinition,
 					Line:    int(child.StartPoint().Row) + 1,
-					Column:  int(child.StartPoint().Column) + 1,
+					Column:  nodeColumn(v.content, child) + 1,
 				}
 				v.addRef(ref)
 			}
@@ -230,7 +231,7 @@ func (v *cppDefUseVisitor) processDeclaration(node *sitter.Node) {
 						Name:    name,
 						RefType: RefTypeDefinition,
 						Line:    int(nameNode.StartPoint().Row) + 1,
-						Column:  int(nameNode.StartPoint().Column) + 1,
+						Column:  nodeColumn(v.content, nameNode) + 1,
 					}
 					v.addRef(ref)
 				}
@@ -412,7 +413,7 @@ func (v *cppDefUseVisitor) extractUses(node *sitter.Node) {
 					Name:    name,
 					RefType: RefTypeUse,
 					Line:    int(node.StartPoint().Row) + 1,
-					Column:  int(node.StartPoint().Column) + 1,
+					Column:  nodeColumn(v.content, node) + 1,
 				}
 				v.addRef(ref)
 			}
@@ -430,7 +431,7 @@ func (v *cppDefUseVisitor) extractUses(node *sitter.Node) {
 
 func (v *cppDefUseVisitor) isDefinitionAtPosition(node *sitter.Node) bool {
 	line := int(node.StartPoint().Row) + 1
-	col := int(node.StartPoint().Column) + 1
+	col := nodeColumn(v.content, node) + 1
 
 	for _, ref := range v.refs {
 		if ref.Line == line && ref.Column == col {
@@ -454,7 +455,7 @@ func (v *cppDefUseVisitor) extractIdentifiersFromNode(node *sitter.Node, refType
 				Name:    name,
 				RefType: refType,
 				Line:    int(node.StartPoint().Row) + 1,
-				Column:  int(node.StartPoint().Column) + 1,
+				Column:  nodeColumn(v.content, node) + 1,
 			}
 			v.addRef(ref)
 		}
@@ -515,12 +516,7 @@ func nodeTextCpp(node *sitter.Node, content []byte) string {
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
 
 func isCppBuiltin(name string) bool {
@@ -9,6 +9,7 @@ import (
 	"github.com/smacker/go-tree-sitter/golang"
 
 	"github.com/l3aro/go-context-query/pkg/cfg"
+	"github.com/l3aro/go-context-query/pkg/types"
 )
 
 // goDefUseVisitor walks Go AST to extract variable references.
@@ -169,7 +170,7 @@ func (v *goDefUseVisitor) extractParameters(funcNode *sitter.Node) {
 						Name:    name,
 						RefType: RefTypeDefinition,
 						Line:    int(nameNode.StartPoint().Row) + 1,
-						Column:  int(nameNode.StartPoint().Column) + 1,
+						Column:  nodeColumn(v.content, nameNode) + 1,
 					}
 					v.addRef(ref)
 				}
@@ -244,7 +245,7 @@ func (v *goDefUseVisitor) processVarSpec(node *sitter.Node) {
 					Name:    name,
 					RefType: RefTypeDefinition,
 					Line:    int(child.StartPoint().Row) + 1,
-					Column:  int(child.StartPoint().Column) + 1,
+					Column:  nodeColumn(v.content, child) + 1,
 				}
 				v.addRef(ref)
 			}
@@ -387,7 +388,7 @@ func (v *goDefUseVisitor) extractUses(node *sitter.Node) {
 					Name:    name,
 					RefType: RefTypeUse,
 					Line:    int(node.StartPoint().Row) + 1,
-					Column:  int(node.StartPoint().Column) + 1,
+					Column:  nodeColumn(v.content, node) + 1,
 				}
 				v.addRef(ref)
 			}
@@ -405,7 +406,7 @@ func (v *goDefUseVisitor) extractUses(node *sitter.Node) {
 
 func (v *goDefUseVisitor) isDefinitionAtPosition(node *sitter.Node) bool {
 	line := int(node.StartPoint().Row) + 1
-	col := int(node.StartPoint().Column) + 1
+	col := nodeColumn(v.content, node) + 1
 
 	for _, ref := range v.refs {
 		if ref.Line == line && ref.Column == col {
@@ -429,7 +430,7 @@ func (v *goDefUseVisitor) extractIdentifiersFromNode(node *sitter.Node, refType
 				Name:    name,
 				RefType: refType,
 				Line:    int(node.StartPoint().Row) + 1,
-				Column:  int(node.StartPoint().Column) + 1,
+				Column:  nodeColumn(v.content, node) + 1,
 			}
 			v.addRef(ref)
 		}
@@ -490,12 +491,7 @@ func nodeTextGo(node *sitter.Node, content []byte) string {
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
 
 func isGoBuiltin(name string) bool {
@@ -8,6 +8,7 @@ import (
 	"github.com/smacker/go-tree-sitter/java"
 
 	"github.com/l3aro/go-context-query/pkg/cfg"
+	"github.com/l3aro/go-context-query/pkg/types"
 )
 
 type javaDefUseVisitor struct {
@@ -190,7 +191,7 @@ func (v *javaDefUseVisitor) extractParameterNames(paramNode *sitter.Node) {
 					Name:    name,
 					RefType: RefTypeDefinition,
 					Line:    int(nameNode.StartPoint().Row) + 1,
-					Column:  int(nameNode.StartPoint().Column) + 1,
+					Column:  nodeColumn(v.content, nameNode) + 1,
 				}
 				v.addRef(ref)
 			}
@@ -264,7 +265,7 @@ func (v *javaDefUseVisitor) processLocalVariableDeclaration(node *sitter.Node) {
 						Name:    name,
 						RefType: RefTypeDefinition,
 						Line:    int(nameNode.StartPoint().Row) + 1,
-						Column:  int(nameNode.StartPoint().Column) + 1,
+						Column:  nodeColumn(v.content, nameNode) + 1,
 					}
 					v.addRef(ref)
 				}
@@ -336,7 +337,7 @@ func (v *javaDefUseVisitor) processEnhancedForStatement(node *sitter.Node) {
 					Name:    name,
 					RefType: RefTypeDefinition,
 					Line:    int(nameNode.StartPoint().Row) + 1,
-					Column:  int(nameNode.StartPoint().Column) + 1,
+					Column:  nodeColumn(v.content, nameNode) + 1,
 				}
 				v.addRef(ref)
 			}
@@ -497,7 +498,7 @@ func (v *javaDefUseVisitor) extractUses(node *sitter.Node) {
 					Name:    name,
 					RefType: RefTypeUse,
 					Line:    int(node.StartPoint().Row) + 1,
-					Column:  int(node.StartPoint().Column) + 1,
+					Column:  nodeColumn(v.content, node) + 1,
 				}
 				v.addRef(ref)
 			}
@@ -515,7 +516,7 @@ func (v *javaDefUseVisitor) extractUses(node *sitter.Node) {
 
 func (v *javaDefUseVisitor) isDefinitionAtPosition(node *sitter.Node) bool {
 	line := int(node.StartPoint().Row) + 1
-	col := int(node.StartPoint().Column) + 1
+	col := nodeColumn(v.content, node) + 1
 
 	for _, ref := range v.refs {
 		if ref.Line == line && ref.Column == col {
@@ -539,7 +540,7 @@ func (v *javaDefUseVisitor) extractIdentifiersFromNode(node *sitter.Node, refTyp
 				Name:    name,
 				RefType: refType,
 				Line:    int(node.StartPoint().Row) + 1,
-				Column:  int(node.StartPoint().Column) + 1,
+				Column:  nodeColumn(v.content, node) + 1,
 			}
 			v.addRef(ref)
 		}
@@ -600,12 +601,7 @@ func nodeTextJava(node *sitter.Node, content []byte) string {
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
 
 func isJavaBuiltin(name string) bool {
@@ -8,6 +8,7 @@ import (
 	"github.com/smacker/go-tree-sitter/php"
 
 	"github.com/l3aro/go-context-query/pkg/cfg"
+	"github.com/l3aro/go-context-query/pkg/types"
 )
 
 type phpDefUseVisitor struct {
@@ -162,7 +163,7 @@ func (v *phpDefUseVisitor) extractParameterFromNode(paramNode *sitter.Node) {
 					Name:    name,
 					RefType: RefTypeDefinition,
 					Line:    int(child.StartPoint().Row) + 1,
-					Column:  int(child.StartPoint().Column) + 1,
+					Column:  nodeColumn(v.content, child) + 1,
 				}
 				v.addRef(ref)
 			}
@@ -232,7 +233,7 @@ func (v *phpDefUseVisitor) processAssignment(node *sitter.Node) {
 					Name:    name,
 					RefType: RefTypeDefinition,
 					Line:    int(child.StartPoint().Row) + 1,
-					Column:  int(child.StartPoint().Column) + 1,
+					Column:  nodeColumn(v.content, child) + 1,
 				}
 				v.addRef(ref)
 			}
@@ -380,7 +381,7 @@ func (v *phpDefUseVisitor) extractUses(node *sitter.Node) {
 					Name:    name,
 					RefType: RefTypeUse,
 					Line:    int(node.StartPoint().Row) + 1,
-					Column:  int(node.StartPoint().Column) + 1,
+					Column:  nodeColumn(v.content, node) + 1,
 				}
 				v.addRef(ref)
 			}
@@ -398,7 +399,7 @@ func (v *phpDefUseVisitor) extractUses(node *sitter.Node) {
 
 func (v *phpDefUseVisitor) isDefinitionAtPosition(node *sitter.Node) bool {
 	line := int(node.StartPoint().Row) + 1
-	col := int(node.StartPoint().Column) + 1
+	col := nodeColumn(v.content, node) + 1
 
 	for _, ref := range v.refs {
 		if ref.Line == line && ref.Column == col {
@@ -422,7 +423,7 @@ func (v *phpDefUseVisitor) extractIdentifiersFromNode(node *sitter.Node, refType
 				Name:    name,
 				RefType: refType,
 				Line:    int(node.StartPoint().Row) + 1,
-				Column:  int(node.StartPoint().Column) + 1,
+				Column:  nodeColumn(v.content, node) + 1,
 			}
 			v.addRef(ref)
 		}
@@ -483,12 +484,7 @@ func nodeTextPhp(node *sitter.Node, content []byte) string {
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
 
 func isPhpBuiltin(name string) bool {
@@ -9,6 +9,7 @@ import (
 	"github.com/smacker/go-tree-sitter/python"
 
 	"github.com/l3aro/go-context-query/pkg/cfg"
+	"github.com/l3aro/go-context-query/pkg/types"
 )
 
 // scopeStack tracks variable definitions at each scope level.
@@ -289,7 +290,7 @@ func (v *pythonDefUseVisitor) extractParameters(funcNode *sitter.Node) {
 					Name:    name,
 					RefType: RefTypeDefinition,
 					Line:    int(child.StartPoint().Row) + 1,
-					Column:  int(child.StartPoint().Column) + 1,
+					Column:  nodeColumn(v.content, child) + 1,
 				}
 				v.addRef(ref)
 				v.scopeStack.addDefinition(name)
@@ -314,7 +315,7 @@ func (v *pythonDefUseVisitor) extractParameters(funcNode *sitter.Node) {
 							Name:    name,
 							RefType: RefTypeDefinition,
 							Line:    int(identifier.StartPoint().Row) + 1,
-							Column:  int(identifier.StartPoint().Column) + 1,
+							Column:  nodeColumn(v.content, identifier) + 1,
 						}
 						v.addRef(ref)
 						v.scopeStack.addDefinition(name)
@@ -528,7 +529,7 @@ func (v *pythonDefUseVisitor) extractExceptionVariable(handlerNode *sitter.Node)
 						Name:    name,
 						RefType: RefTypeDefinition,
 						Line:    int(identifier.StartPoint().Row) + 1,
-						Column:  int(identifier.StartPoint().Column) + 1,
+						Column:  nodeColumn(v.content, identifier) + 1,
 					}
 					v.addRef(ref)
 					v.scopeStack.addDefinition(name)
@@ -593,7 +594,7 @@ func (v *pythonDefUseVisitor) extractLambdaParamIdentifiers(node *sitter.Node) {
 				Name:    name,
 				RefType: RefTypeDefinition,
 				Line:    int(node.StartPoint().Row) + 1,
-				Column:  int(node.StartPoint().Column) + 1,
+				Column:  nodeColumn(v.content, node) + 1,
 			}
 			v.addRef(ref)
 			v.scopeStack.addDefinition(name)
@@ -715,7 +716,7 @@ func (v *pythonDefUseVisitor) extractAssignmentTarget(node *sitter.Node, refType
 				Name:    name,
 				RefType: refType,
 				Line:    int(node.StartPoint().Row) + 1,
-				Column:  int(node.StartPoint().Column) + 1,
+				Column:  nodeColumn(v.content, node) + 1,
 			}
 			v.addRef(ref)
 			// Track definition in current scope
@@ -756,7 +757,7 @@ func (v *pythonDefUseVisitor) extractUses(node *sitter.Node) {
 				Name:    name,
 				RefType: RefTypeUse,
 				Line:    int(node.StartPoint().Row) + 1,
-				Column:  int(node.StartPoint().Column) + 1,
+				Column:  nodeColumn(v.content, node) + 1,
 			}
 			v.addRef(ref)
 		}
@@ -778,7 +779,7 @@ func (v *pythonDefUseVisitor) extractIdentifiers(node *sitter.Node, refType RefT
 				Name:    name,
 				RefType: refType,
 				Line:    int(node.StartPoint().Row) + 1,
-				Column:  int(node.StartPoint().Column) + 1,
+				Column:  nodeColumn(v.content, node) + 1,
 			}
 			v.addRef(ref)
 		}
@@ -822,7 +823,7 @@ func (v *pythonDefUseVisitor) processAttributeAccess(node *sitter.Node) {
 
 	// Get line number from the attribute node
 	line := int(node.StartPoint().Row) + 1
-	col := int(node.StartPoint().Column)
+	col := nodeColumn(v.content, node)
 
 	// Add as a use of the attribute
 	v.addRef(VarRef{
@@ -882,12 +883,13 @@ func nodeText(node *sitter.Node, content []byte) string {
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
+}
+
+// nodeColumn returns the 0-based character column where node starts. Tree-sitter
+// columns are byte offsets, which overcount on lines with multibyte characters.
+func nodeColumn(content []byte, node *sitter.Node) int {
+	return types.RuneColumn(content, node.StartByte(), node.StartPoint().Column)
 }
 
 func isBuiltin(name string) bool {
@@ -227,3 +227,34 @@ func contains(slice []string, item string) bool {
 	}
 	return false
 }
+
+func TestPythonColumnsAreCharacterBased(t *testing.T) {
+	code := `def test_func():
+    größe = "日本"; total = größe
+    return total`
+
+	tmpFile := filepath.Join(t.TempDir(), "test.py")
+	if err := os.WriteFile(tmpFile, []byte(code), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	dfg, err := extractPythonDFG(tmpFile, "test_func")
+	if err != nil {
+		t.Fatalf("extractPythonDFG failed: %v", err)
+	}
+
+	// "total" starts after `    größe = "日本"; `, which is 18 characters
+	// but 24 bytes long.
+	var found bool
+	for _, ref := range dfg.VarRefs {
+		if ref.Name == "total" && ref.Line == 2 {
+			found = true
+			if ref.Column != 19 {
+				t.Errorf("expected column 19 for total, got %d", ref.Column)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected a reference to total on line 2, got %v", dfg.VarRefs)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/smacker/go-tree-sitter/ruby"
 
 	"github.com/l3aro/go-context-query/pkg/cfg"
+	"github.com/l3aro/go-context-query/pkg/types"
 )
 
 type rubyDefUseVisitor struct {
@@ -143,7 +144,7 @@ func (v *rubyDefUseVisitor) extractParameters(funcNode *sitter.Node) {
 					Name:    name,
 					RefType: RefTypeDefinition,
 					Line:    int(child.StartPoint().Row) + 1,
-					Column:  int(child.StartPoint().Column) + 1,
+					Column:  nodeColumn(v.content, child) + 1,
 				}
 				v.addRef(ref)
 			}
@@ -203,7 +204,7 @@ func (v *rubyDefUseVisitor) processAssignment(node *sitter.Node) {
 					Name:    name,
 					RefType: RefTypeDefinition,
 					Line:    int(child.StartPoint().Row) + 1,
-					Column:  int(child.StartPoint().Column) + 1,
+					Column:  nodeColumn(v.content, child) + 1,
 				}
 				v.addRef(ref)
 			}
@@ -222,7 +223,7 @@ func (v *rubyDefUseVisitor) processForStatement(node *sitter.Node) {
 				Name:    name,
 				RefType: RefTypeDefinition,
 				Line:    int(variable.StartPoint().Row) + 1,
-				Column:  int(variable.StartPoint().Column) + 1,
+				Column:  nodeColumn(v.content, variable) + 1,
 			}
 			v.addRef(ref)
 		}
@@ -306,7 +307,7 @@ func (v *rubyDefUseVisitor) extractUses(node *sitter.Node) {
 					Name:    name,
 					RefType: RefTypeUse,
 					Line:    int(node.StartPoint().Row) + 1,
-					Column:  int(node.StartPoint().Column) + 1,
+					Column:  nodeColumn(v.content, node) + 1,
 				}
 				v.addRef(ref)
 			}
@@ -324,7 +325,7 @@ func (v *rubyDefUseVisitor) extractUses(node *sitter.Node) {
 
 func (v *rubyDefUseVisitor) isDefinitionAtPosition(node *sitter.Node) bool {
 	line := int(node.StartPoint().Row) + 1
-	col := int(node.StartPoint().Column) + 1
+	col := nodeColumn(v.content, node) + 1
 
 	for _, ref := range v.refs {
 		if ref.Line == line && ref.Column == col {
@@ -382,12 +383,7 @@ func nodeTextRuby(node *sitter.Node, content []byte) string {
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
 
 func isRubyBuiltin(name string) bool {
@@ -8,6 +8,7 @@ import (
 	"github.com/smacker/go-tree-sitter/rust"
 
 	"github.com/l3aro/go-context-query/pkg/cfg"
+	"github.com/l3aro/go-context-query/pkg/types"
 )
 
 type rustDefUseVisitor struct {
@@ -150,7 +151,7 @@ func (v *rustDefUseVisitor) extractParameters(funcNode *sitter.Node) {
 			Name:    "self",
 			RefType: RefTypeDefinition,
 			Line:    int(selfNode.StartPoint().Row) + 1,
-			Column:  int(selfNode.StartPoint().Column) + 1,
+			Column:  nodeColumn(v.content, selfNode) + 1,
 		}
 		v.addRef(ref)
 	}
@@ -169,7 +170,7 @@ func (v *rustDefUseVisitor) extractParameterNames(paramNode *sitter.Node) {
 				Name:    name,
 				RefType: RefTypeDefinition,
 				Line:    int(identifier.StartPoint().Row) + 1,
-				Column:  int(identifier.StartPoint().Column) + 1,
+				Column:  nodeColumn(v.content, identifier) + 1,
 			}
 			v.addRef(ref)
 		}
@@ -241,7 +242,7 @@ func (v *rustDefUseVisitor) processLetDeclaration(node *sitter.Node) {
 				Name:    name,
 				RefType: RefTypeDefinition,
 				Line:    int(pattern.StartPoint().Row) + 1,
-				Column:  int(pattern.StartPoint().Column) + 1,
+				Column:  nodeColumn(v.content, pattern) + 1,
 			}
 			v.addRef(ref)
 		}
@@ -269,7 +270,7 @@ func (v *rustDefUseVisitor) processLetElseDeclaration(node *sitter.Node) {
 				Name:    name,
 				RefType: RefTypeDefinition,
 				Line:    int(pattern.StartPoint().Row) + 1,
-				Column:  int(pattern.StartPoint().Column) + 1,
+				Column:  nodeColumn(v.content, pattern) + 1,
 			}
 			v.addRef(ref)
 		}
@@ -304,7 +305,7 @@ func (v *rustDefUseVisitor) extractAssignmentTarget(node *sitter.Node, refType R
 				Name:    name,
 				RefType: refType,
 				Line:    int(node.StartPoint().Row) + 1,
-				Column:  int(node.StartPoint().Column) + 1,
+				Column:  nodeColumn(v.content, node) + 1,
 			}
 			v.addRef(ref)
 		}
@@ -344,7 +345,7 @@ func (v *rustDefUseVisitor) extractDestructuring(node *sitter.Node, refType RefT
 						Name:    name,
 						RefType: refType,
 						Line:    int(child.StartPoint().Row) + 1,
-						Column:  int(child.StartPoint().Column) + 1,
+						Column:  nodeColumn(v.content, child) + 1,
 					}
 					v.addRef(ref)
 				}
@@ -369,7 +370,7 @@ func (v *rustDefUseVisitor) extractDestructuring(node *sitter.Node, refType RefT
 						Name:    name,
 						RefType: refType,
 						Line:    int(child.StartPoint().Row) + 1,
-						Column:  int(child.StartPoint().Column) + 1,
+						Column:  nodeColumn(v.content, child) + 1,
 					}
 					v.addRef(ref)
 				}
@@ -402,7 +403,7 @@ func (v *rustDefUseVisitor) extractStructDestructuring(node *sitter.Node, refTyp
 						Name:    name,
 						RefType: refType,
 						Line:    int(identifier.StartPoint().Row) + 1,
-						Column:  int(identifier.StartPoint().Column) + 1,
+						Column:  nodeColumn(v.content, identifier) + 1,
 					}
 					v.addRef(ref)
 				}
@@ -503,7 +504,7 @@ func (v *rustDefUseVisitor) processForExpression(node *sitter.Node) {
 						Name:    name,
 						RefType: RefTypeDefinition,
 						Line:    int(identifier.StartPoint().Row) + 1,
-						Column:  int(identifier.StartPoint().Column) + 1,
+						Column:  nodeColumn(v.content, identifier) + 1,
 					}
 					v.addRef(ref)
 				}
@@ -532,7 +533,7 @@ func (v *rustDefUseVisitor) extractUses(node *sitter.Node) {
 					Name:    name,
 					RefType: RefTypeUse,
 					Line:    int(node.StartPoint().Row) + 1,
-					Column:  int(node.StartPoint().Column) + 1,
+					Column:  nodeColumn(v.content, node) + 1,
 				}
 				v.addRef(ref)
 			}
@@ -550,7 +551,7 @@ func (v *rustDefUseVisitor) extractUses(node *sitter.Node) {
 
 func (v *rustDefUseVisitor) isDefinitionAtPosition(node *sitter.Node) bool {
 	line := int(node.StartPoint().Row) + 1
-	col := int(node.StartPoint().Column) + 1
+	col := nodeColumn(v.content, node) + 1
 
 	for _, ref := range v.refs {
 		if ref.Line == line && ref.Column == col {
@@ -574,7 +575,7 @@ func (v *rustDefUseVisitor) extractIdentifiers(node *sitter.Node, refType RefTyp
 				Name:    name,
 				RefType: refType,
 				Line:    int(node.StartPoint().Row) + 1,
-				Column:  int(node.StartPoint().Column) + 1,
+				Column:  nodeColumn(v.content, node) + 1,
 			}
 			v.addRef(ref)
 		}
@@ -635,12 +636,7 @@ func nodeTextRust(node *sitter.Node, content []byte) string {
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
 
 func isRustBuiltin(name string) bool {
@@ -9,6 +9,7 @@ import (
 	"github.com/smacker/go-tree-sitter/typescript/typescript"
 
 	"github.com/l3aro/go-context-query/pkg/cfg"
+	"github.com/l3aro/go-context-query/pkg/types"
 )
 
 type tsDefUseVisitor struct {
@@ -185,7 +186,7 @@ func (v *tsDefUseVisitor) extractParameters(funcNode *sitter.Node) {
 				Name:    "this",
 				RefType: RefTypeDefinition,
 				Line:    int(thisNode.StartPoint().Row) + 1,
-				Column:  int(thisNode.StartPoint().Column) + 1,
+				Column:  nodeColumn(v.content, thisNode) + 1,
 			}
 			v.addRef(ref)
 		}
@@ -205,7 +206,7 @@ func (v *tsDefUseVisitor) extractParameterNames(paramNode *sitter.Node) {
 				Name:    name,
 				RefType: RefTypeDefinition,
 				Line:    int(identifier.StartPoint().Row) + 1,
-				Column:  int(identifier.StartPoint().Column) + 1,
+				Column:  nodeColumn(v.content, identifier) + 1,
 			}
 			v.addRef(ref)
 		}
@@ -310,7 +311,7 @@ func (v *tsDefUseVisitor) processVariableDeclarator(node *sitter.Node, refType R
 				Name:    name,
 				RefType: refType,
 				Line:    int(nameNode.StartPoint().Row) + 1,
-				Column:  int(nameNode.StartPoint().Column) + 1,
+				Column:  nodeColumn(v.content, nameNode) + 1,
 			}
 			v.addRef(ref)
 		}
@@ -348,7 +349,7 @@ func (v *tsDefUseVisitor) extractDestructuring(node *sitter.Node, refType RefTyp
 						Name:    name,
 						RefType: refType,
 						Line:    int(child.StartPoint().Row) + 1,
-						Column:  int(child.StartPoint().Column) + 1,
+						Column:  nodeColumn(v.content, child) + 1,
 					}
 					v.addRef(ref)
 				}
@@ -366,7 +367,7 @@ func (v *tsDefUseVisitor) extractDestructuring(node *sitter.Node, refType RefTyp
 							Name:    name,
 							RefType: refType,
 							Line:    int(restId.StartPoint().Row) + 1,
-							Column:  int(restId.StartPoint().Column) + 1,
+							Column:  nodeColumn(v.content, restId) + 1,
 						}
 						v.addRef(ref)
 					}
@@ -388,7 +389,7 @@ func (v *tsDefUseVisitor) extractDestructuring(node *sitter.Node, refType RefTyp
 						Name:    name,
 						RefType: refType,
 						Line:    int(child.StartPoint().Row) + 1,
-						Column:  int(child.StartPoint().Column) + 1,
+						Column:  nodeColumn(v.content, child) + 1,
 					}
 					v.addRef(ref)
 				}
@@ -405,7 +406,7 @@ func (v *tsDefUseVisitor) extractDestructuring(node *sitter.Node, refType RefTyp
 							Name:    name,
 							RefType: refType,
 							Line:    int(restId.StartPoint().Row) + 1,
-							Column:  int(restId.StartPoint().Column) + 1,
+							Column:  nodeColumn(v.content, restId) + 1,
 						}
 						v.addRef(ref)
 					}
@@ -449,7 +450,7 @@ func (v *tsDefUseVisitor) extractAssignmentTarget(node *sitter.Node, refType Ref
 				Name:    name,
 				RefType: refType,
 				Line:    int(node.StartPoint().Row) + 1,
-				Column:  int(node.StartPoint().Column) + 1,
+				Column:  nodeColumn(v.content, node) + 1,
 			}
 			v.addRef(ref)
 		}
@@ -622,7 +623,7 @@ func (v *tsDefUseVisitor) processCatchClause(node *sitter.Node) {
 					Name:    name,
 					RefType: RefTypeDefinition,
 					Line:    int(identifier.StartPoint().Row) + 1,
-					Column:  int(identifier.StartPoint().Column) + 1,
+					Column:  nodeColumn(v.content, identifier) + 1,
 				}
 				v.addRef(ref)
 			}
@@ -653,7 +654,7 @@ func (v *tsDefUseVisitor) extractUses(node *sitter.Node) {
 					Name:    name,
 					RefType: RefTypeUse,
 					Line:    int(node.StartPoint().Row) + 1,
-					Column:  int(node.StartPoint().Column) + 1,
+					Column:  nodeColumn(v.content, node) + 1,
 				}
 				v.addRef(ref)
 			}
@@ -681,7 +682,7 @@ func (v *tsDefUseVisitor) extractIdentifiers(node *sitter.Node, refType RefType)
 				Name:    name,
 				RefType: refType,
 				Line:    int(node.StartPoint().Row) + 1,
-				Column:  int(node.StartPoint().Column) + 1,
+				Column:  nodeColumn(v.content, node) + 1,
 			}
 			v.addRef(ref)
 		}
@@ -698,7 +699,7 @@ func (v *tsDefUseVisitor) extractIdentifiers(node *sitter.Node, refType RefType)
 
 func (v *tsDefUseVisitor) isDefinitionAtPosition(node *sitter.Node) bool {
 	line := int(node.StartPoint().Row) + 1
-	col := int(node.StartPoint().Column) + 1
+	col := nodeColumn(v.content, node) + 1
 
 	for _, ref := range v.refs {
 		if ref.Line == line && ref.Column == col {
@@ -756,12 +757,7 @@ func nodeTextTS(node *sitter.Node, content []byte) string {
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
 
 func isTSBuiltin(name string) bool {
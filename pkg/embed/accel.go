@@ -0,0 +1,135 @@
+package embed
+
+import (
+	"bufio"
+	"context"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Acceleration backends reported by DetectAcceleration
+const (
+	BackendMetal = "metal"
+	BackendCUDA  = "cuda"
+	BackendCPU   = "cpu"
+	// BackendNone means no local embedding runtime was found
+	BackendNone = "none"
+)
+
+// Acceleration describes the local embedding runtime and the hardware
+// acceleration it is expected to use
+type Acceleration struct {
+	// Platform is GOOS/GOARCH of the current machine
+	Platform string `json:"platform"`
+	// Runtime is the local embedding runtime found, e.g. "ollama"
+	Runtime string `json:"runtime,omitempty"`
+	// RuntimePath is the path of the runtime's CLI
+	RuntimePath string `json:"runtime_path,omitempty"`
+	// Backend is one of the Backend constants
+	Backend string `json:"backend"`
+	// Detail explains how the backend was determined
+	Detail string `json:"detail,omitempty"`
+}
+
+// Accelerated reports whether the runtime is expected to use a GPU
+func (a *Acceleration) Accelerated() bool {
+	return a.Backend == BackendMetal || a.Backend == BackendCUDA
+}
+
+// DetectAcceleration looks for a local embedding runtime on PATH and reports
+// the acceleration it will use. Ollama uses Metal on Apple Silicon and CUDA
+// when an NVIDIA driver is present; elsewhere it runs on the CPU.
+func DetectAcceleration() *Acceleration {
+	return detectAcceleration(runtime.GOOS, runtime.GOARCH, exec.LookPath)
+}
+
+func detectAcceleration(goos, goarch string, lookPath func(string) (string, error)) *Acceleration {
+	accel := &Acceleration{
+		Platform: goos + "/" + goarch,
+		Backend:  BackendNone,
+	}
+
+	path, err := lookPath("ollama")
+	if err != nil {
+		accel.Detail = "ollama CLI not found on PATH"
+		return accel
+	}
+	accel.Runtime = "ollama"
+	accel.RuntimePath = path
+
+	switch {
+	case goos == "darwin" && goarch == "arm64":
+		accel.Backend = BackendMetal
+		accel.Detail = "Apple Silicon: Ollama runs models on the GPU through Metal"
+	case goos == "darwin":
+		accel.Backend = BackendCPU
+		accel.Detail = "Intel Mac: Metal acceleration requires Apple Silicon"
+	default:
+		if _, err := lookPath("nvidia-smi"); err == nil {
+			accel.Backend = BackendCUDA
+			accel.Detail = "NVIDIA driver found: Ollama can run models on the GPU through CUDA"
+		} else {
+			accel.Backend = BackendCPU
+			accel.Detail = "no supported GPU runtime found"
+		}
+	}
+	return accel
+}
+
+// OllamaProcessors runs `ollama ps` and returns the processor split of each
+// loaded model, e.g. "100% GPU", keyed by model name. Models are only listed
+// while loaded, so the result is empty until the first embedding request.
+func OllamaProcessors(ctx context.Context, ollamaPath string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, ollamaPath, "ps").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseOllamaPS(string(out)), nil
+}
+
+// parseOllamaPS parses the table printed by `ollama ps`:
+//
+//	NAME                       ID              SIZE      PROCESSOR    UNTIL
+//	nomic-embed-text:latest    0a109f422b47    849 MB    100% GPU     4 minutes from now
+//
+// Columns are separated by runs of two or more spaces.
+func parseOllamaPS(out string) map[string]string {
+	processors := make(map[string]string)
+	sc := bufio.NewScanner(strings.NewReader(out))
+
+	processorCol := -1
+	for sc.Scan() {
+		fields := splitColumns(sc.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if processorCol < 0 {
+			for i, f := range fields {
+				if f == "PROCESSOR" {
+					processorCol = i
+				}
+			}
+			continue
+		}
+		if processorCol < len(fields) {
+			processors[fields[0]] = fields[processorCol]
+		}
+	}
+	return processors
+}
+
+// splitColumns splits a line on runs of two or more spaces
+func splitColumns(line string) []string {
+	var fields []string
+	for _, f := range strings.Split(line, "  ") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
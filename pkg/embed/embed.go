@@ -1,9 +1,11 @@
 package embed
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
+	"net/http"
 )
 
 // ErrInvalidInput is returned when the input is invalid
@@ -58,6 +60,10 @@ type Config struct {
 	// dimensionality reduction)
 	// 0 means use model default
 	Dimensions int
+
+	// Transport carries the provider's HTTP requests. nil uses the default
+	// transport, or a Recorder when GCQ_CASSETTE is set.
+	Transport http.RoundTripper
 }
 
 // Validate checks that the configuration has valid required fields
@@ -73,10 +79,12 @@ func (c *Config) Validate() error {
 
 // Provider defines the interface for embedding providers
 type Provider interface {
-	// Embed generates embeddings for the given texts.
+	// EmbedContext generates embeddings for the given texts.
 	// The returned slice has the same length as the input texts.
 	// Each embedding is a slice of float32 values.
-	Embed(texts []string) ([][]float32, error)
+	// Once ctx is done, requests in flight are abandoned and the
+	// context's error is returned.
+	EmbedContext(ctx context.Context, texts []string) ([][]float32, error)
 
 	// Config returns the provider configuration
 	Config() *Config
@@ -1,8 +1,10 @@
 package embed
 
 import (
+	"context"
 	"errors"
 	"log"
+	"os"
 	"testing"
 )
 
@@ -12,7 +14,7 @@ type mockDimensionedProvider struct {
 	dimensionErr error
 }
 
-func (m *mockDimensionedProvider) Embed(texts []string) ([][]float32, error) {
+func (m *mockDimensionedProvider) EmbedContext(_ context.Context, texts []string) ([][]float32, error) {
 	embeddings := make([][]float32, len(texts))
 	for i := range texts {
 		embeddings[i] = make([]float32, m.dimension)
@@ -31,7 +33,7 @@ func (m *mockDimensionedProvider) Dimension() (int, error) {
 // mockNonDimensionedProvider implements Provider but not DimensionedProvider
 type mockNonDimensionedProvider struct{}
 
-func (m *mockNonDimensionedProvider) Embed(texts []string) ([][]float32, error) {
+func (m *mockNonDimensionedProvider) EmbedContext(_ context.Context, texts []string) ([][]float32, error) {
 	return [][]float32{{0.1, 0.2, 0.3}}, nil
 }
 
@@ -272,7 +274,7 @@ func TestWarnDimensionMismatch(t *testing.T) {
 			// Capture log output
 			var logOutput string
 			log.SetOutput(&logWriter{&logOutput})
-			defer log.SetOutput(nil)
+			defer log.SetOutput(os.Stderr)
 
 			WarnDimensionMismatch(tt.indexDim, tt.searchProvider)
 
@@ -526,7 +528,7 @@ type mockProvider struct {
 	config    *Config
 }
 
-func (m *mockProvider) Embed(texts []string) ([][]float32, error) {
+func (m *mockProvider) EmbedContext(_ context.Context, texts []string) ([][]float32, error) {
 	return m.embedFunc(texts)
 }
 
@@ -539,7 +541,7 @@ func TestProviderInterface(t *testing.T) {
 	var _ Provider = (*mockProvider)(nil)
 }
 
-// TestMockProviderEmbed tests the mockProvider.Embed method
+// TestMockProviderEmbed tests the mockProvider.EmbedContext method
 func TestMockProviderEmbed(t *testing.T) {
 	embeddings := [][]float32{
 		{0.1, 0.2, 0.3},
@@ -559,7 +561,7 @@ func TestMockProviderEmbed(t *testing.T) {
 		},
 	}
 
-	result, err := mp.Embed([]string{"hello", "world"})
+	result, err := mp.EmbedContext(context.Background(), []string{"hello", "world"})
 	if err != nil {
 		t.Errorf("Embed() unexpected error: %v", err)
 	}
@@ -681,7 +683,7 @@ func TestProviderInterfaceEmbeddingError(t *testing.T) {
 		},
 	}
 
-	_, err := mp.Embed([]string{"test"})
+	_, err := mp.EmbedContext(context.Background(), []string{"test"})
 	if err == nil {
 		t.Errorf("expected error, got nil")
 	}
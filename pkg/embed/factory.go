@@ -7,15 +7,45 @@ import (
 )
 
 // NewProvider creates a new embedding provider based on the provider type.
-// It returns the appropriate provider (Ollama or HuggingFace) based on the
-// provider type string. Returns an error for unknown provider types.
+// It returns the appropriate provider (Ollama, HuggingFace or mock) based on
+// the provider type string. The local provider is created without a fallback.
+// Returns an error for unknown provider types.
 func NewProvider(providerType config.ProviderType, cfg *Config) (Provider, error) {
 	switch providerType {
 	case config.ProviderOllama:
 		return NewOllamaProvider(cfg)
 	case config.ProviderHuggingFace:
 		return NewHuggingFaceProvider(cfg)
+	case config.ProviderLocal:
+		return NewLocalProvider(cfg, nil)
+	case config.ProviderMock:
+		return NewMockProvider(cfg)
 	default:
 		return nil, fmt.Errorf("unknown provider type: %s", providerType)
 	}
 }
+
+// NewLocalProviderFromConfig creates a local provider whose fallback is the
+// HuggingFace model configured in hf_model and hf_token. The fallback is
+// omitted when no HuggingFace token is configured.
+func NewLocalProviderFromConfig(cfg *Config, appCfg *config.Config) (*LocalProvider, error) {
+	var fallback Provider
+	if appCfg.HFToken != "" {
+		hf, err := NewHuggingFaceProvider(&Config{
+			Model:  appCfg.HFModel,
+			APIKey: appCfg.HFToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("creating fallback provider: %w", err)
+		}
+		fallback = hf
+	}
+	return NewLocalProvider(cfg, fallback)
+}
+
+// NewMockProviderFromConfig creates a mock provider with the dimension set in
+// mock_dimension. Configured models are ignored so that warm and search
+// always agree on the embeddings.
+func NewMockProviderFromConfig(appCfg *config.Config) (*MockProvider, error) {
+	return NewMockProvider(&Config{Dimensions: appCfg.MockDimension})
+}
@@ -12,6 +12,8 @@ import (
 	"slices"
 	"strings"
 	"sync"
+
+	"github.com/l3aro/go-context-query/pkg/types"
 )
 
 // DefaultGemmaModel is the default embedding model
@@ -68,9 +70,9 @@ func NewHuggingFaceProvider(cfg *Config) (*HuggingFaceProvider, error) {
 
 	return &HuggingFaceProvider{
 		config: cfg,
-		httpClient: &http.Client{
+		httpClient: providerClient(cfg, &http.Client{
 			Timeout: 120, // 2 minutes timeout for large batches
-		},
+		}),
 	}, nil
 }
 
@@ -81,8 +83,13 @@ func (p *HuggingFaceProvider) Config() *Config {
 	return p.config
 }
 
-// Embed generates embeddings for the given texts using HuggingFace Inference API
+// Embed is EmbedContext without a deadline
 func (p *HuggingFaceProvider) Embed(texts []string) ([][]float32, error) {
+	return p.EmbedContext(context.Background(), texts)
+}
+
+// EmbedContext generates embeddings for the given texts using HuggingFace Inference API
+func (p *HuggingFaceProvider) EmbedContext(ctx context.Context, texts []string) ([][]float32, error) {
 	if len(texts) == 0 {
 		return [][]float32{}, nil
 	}
@@ -95,11 +102,16 @@ func (p *HuggingFaceProvider) Embed(texts []string) ([][]float32, error) {
 	}
 
 	// Use batch processing for efficiency
-	return p.EmbedBatch(texts, p.config.BatchSize)
+	return p.embedBatches(ctx, texts, p.config.BatchSize)
 }
 
 // EmbedBatch generates embeddings for texts in batches
 func (p *HuggingFaceProvider) EmbedBatch(texts []string, batchSize int) ([][]float32, error) {
+	return p.embedBatches(context.Background(), texts, batchSize)
+}
+
+// embedBatches sends texts in batches of batchSize until ctx is done
+func (p *HuggingFaceProvider) embedBatches(ctx context.Context, texts []string, batchSize int) ([][]float32, error) {
 	if len(texts) == 0 {
 		return [][]float32{}, nil
 	}
@@ -118,7 +130,7 @@ func (p *HuggingFaceProvider) EmbedBatch(texts []string, batchSize int) ([][]flo
 		}
 
 		batch := texts[i:end]
-		embeddings, err := p.embedBatchRequest(batch)
+		embeddings, err := p.embedBatchRequest(ctx, batch)
 		if err != nil {
 			return nil, fmt.Errorf("batch %d-%d: %w", i, end, err)
 		}
@@ -131,7 +143,7 @@ func (p *HuggingFaceProvider) EmbedBatch(texts []string, batchSize int) ([][]flo
 }
 
 // embedBatchRequest sends a single batch request to HuggingFace Inference API
-func (p *HuggingFaceProvider) embedBatchRequest(texts []string) ([][]float32, error) {
+func (p *HuggingFaceProvider) embedBatchRequest(ctx context.Context, texts []string) ([][]float32, error) {
 	// Build request
 	reqBody, err := json.Marshal(hfRequest{
 		Inputs: texts,
@@ -142,7 +154,7 @@ func (p *HuggingFaceProvider) embedBatchRequest(texts []string) ([][]float32, er
 
 	endpoint := fmt.Sprintf("%s/%s", p.config.Endpoint, p.config.Model)
 
-	req, err := http.NewRequestWithContext(context.Background(), "POST", endpoint, bytes.NewReader(reqBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -286,10 +298,7 @@ func (p *HuggingFaceProvider) BatchWithMetadata(texts []string, batchSize int) (
 func (p *HuggingFaceProvider) TruncateText(text string, maxTokens int) string {
 	// Rough estimate: 1 token ≈ 4 characters
 	maxChars := maxTokens * 4
-	if len(text) <= maxChars {
-		return text
-	}
-	return text[:maxChars]
+	return types.TruncateUTF8(text, maxChars)
 }
 
 // EmbedSingle generates embedding for a single text
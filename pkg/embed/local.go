@@ -0,0 +1,124 @@
+package embed
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"strings"
+	"sync"
+)
+
+// LocalProvider embeds with a local Ollama runtime, which uses Metal on
+// Apple Silicon, and switches to a fallback provider the first time the
+// runtime cannot be reached. The switch is permanent for the provider's
+// lifetime so one run never mixes embeddings from two models.
+type LocalProvider struct {
+	local        *OllamaProvider
+	fallback     Provider
+	acceleration *Acceleration
+
+	mu          sync.Mutex
+	useFallback bool
+}
+
+// NewLocalProvider creates a provider that embeds with Ollama at
+// cfg.Endpoint (default DefaultOllamaEndpoint). fallback may be nil, in
+// which case errors from the local runtime are returned as is.
+func NewLocalProvider(cfg *Config, fallback Provider) (*LocalProvider, error) {
+	local, err := NewOllamaProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &LocalProvider{
+		local:        local,
+		fallback:     fallback,
+		acceleration: DetectAcceleration(),
+	}, nil
+}
+
+// Acceleration returns the detected local runtime and its acceleration
+func (p *LocalProvider) Acceleration() *Acceleration {
+	return p.acceleration
+}
+
+// UsingFallback reports whether the provider has switched to the fallback
+func (p *LocalProvider) UsingFallback() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.useFallback
+}
+
+// FallbackModel reports whether p is a LocalProvider that has switched to
+// its fallback, and the model it embeds with since
+func FallbackModel(p Provider) (string, bool) {
+	local, ok := p.(*LocalProvider)
+	if !ok || !local.UsingFallback() {
+		return "", false
+	}
+	return local.fallback.Config().Model, true
+}
+
+// active returns the provider currently in use
+func (p *LocalProvider) active() Provider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.useFallback {
+		return p.fallback
+	}
+	return p.local
+}
+
+// Config returns the configuration of the provider currently in use
+func (p *LocalProvider) Config() *Config {
+	return p.active().Config()
+}
+
+// Embed is EmbedContext without a deadline
+func (p *LocalProvider) Embed(texts []string) ([][]float32, error) {
+	return p.EmbedContext(context.Background(), texts)
+}
+
+// EmbedContext generates embeddings with the local runtime, or with the
+// fallback once the local runtime has been found unreachable. A request
+// cut short by ctx does not switch to the fallback.
+func (p *LocalProvider) EmbedContext(ctx context.Context, texts []string) ([][]float32, error) {
+	provider := p.active()
+	embeddings, err := provider.EmbedContext(ctx, texts)
+	if err == nil || ctx.Err() != nil || provider != Provider(p.local) || p.fallback == nil || !isUnreachable(err) {
+		return embeddings, err
+	}
+
+	p.mu.Lock()
+	if !p.useFallback {
+		log.Printf("Local embedding runtime unavailable (%v); falling back to %s", err, p.fallback.Config().Model)
+		p.useFallback = true
+	}
+	p.mu.Unlock()
+
+	return p.fallback.EmbedContext(ctx, texts)
+}
+
+// Dimension returns the embedding dimension of the provider in use
+func (p *LocalProvider) Dimension() (int, error) {
+	if _, err := p.Embed([]string{"test"}); err != nil {
+		return 0, err
+	}
+	return GetDimension(p.active())
+}
+
+// isUnreachable reports whether err means the local runtime is not running
+// or does not serve the model, as opposed to a problem with the input
+func isUnreachable(err error) bool {
+	if errors.Is(err, ErrInvalidInput) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, ErrProviderUnavailable) {
+		return true
+	}
+	return strings.Contains(err.Error(), "connection refused")
+}
+
+// Ensure LocalProvider implements Provider
+var _ Provider = (*LocalProvider)(nil)
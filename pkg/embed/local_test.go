@@ -0,0 +1,203 @@
+package embed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestDetectAcceleration tests backend detection per platform
+func TestDetectAcceleration(t *testing.T) {
+	found := func(names ...string) func(string) (string, error) {
+		return func(name string) (string, error) {
+			for _, n := range names {
+				if n == name {
+					return "/usr/local/bin/" + name, nil
+				}
+			}
+			return "", errors.New("not found")
+		}
+	}
+
+	tests := []struct {
+		name     string
+		goos     string
+		goarch   string
+		lookPath func(string) (string, error)
+		backend  string
+	}{
+		{name: "apple silicon", goos: "darwin", goarch: "arm64", lookPath: found("ollama"), backend: BackendMetal},
+		{name: "intel mac", goos: "darwin", goarch: "amd64", lookPath: found("ollama"), backend: BackendCPU},
+		{name: "linux nvidia", goos: "linux", goarch: "amd64", lookPath: found("ollama", "nvidia-smi"), backend: BackendCUDA},
+		{name: "linux cpu", goos: "linux", goarch: "amd64", lookPath: found("ollama"), backend: BackendCPU},
+		{name: "no runtime", goos: "darwin", goarch: "arm64", lookPath: found(), backend: BackendNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accel := detectAcceleration(tt.goos, tt.goarch, tt.lookPath)
+			if accel.Backend != tt.backend {
+				t.Errorf("Backend = %q, want %q", accel.Backend, tt.backend)
+			}
+			if accel.Platform != tt.goos+"/"+tt.goarch {
+				t.Errorf("Platform = %q", accel.Platform)
+			}
+			if tt.backend == BackendNone && accel.Runtime != "" {
+				t.Errorf("Expected no runtime, got %q", accel.Runtime)
+			}
+		})
+	}
+}
+
+// TestParseOllamaPS tests reading the processor column from ollama ps
+func TestParseOllamaPS(t *testing.T) {
+	out := `NAME                       ID              SIZE      PROCESSOR          UNTIL
+nomic-embed-text:latest    0a109f422b47    849 MB    100% GPU           4 minutes from now
+bge-m3:latest              790764642607    1.2 GB    48%/52% CPU/GPU    Forever
+`
+	processors := parseOllamaPS(out)
+	if len(processors) != 2 {
+		t.Fatalf("Expected 2 models, got %v", processors)
+	}
+	if processors["nomic-embed-text:latest"] != "100% GPU" {
+		t.Errorf("Unexpected processor %q", processors["nomic-embed-text:latest"])
+	}
+	if processors["bge-m3:latest"] != "48%/52% CPU/GPU" {
+		t.Errorf("Unexpected processor %q", processors["bge-m3:latest"])
+	}
+
+	if got := parseOllamaPS("NAME    ID    SIZE    PROCESSOR    UNTIL\n"); len(got) != 0 {
+		t.Errorf("Expected no models, got %v", got)
+	}
+}
+
+// TestLocalProviderUsesLocalRuntime tests that a reachable runtime is used
+func TestLocalProviderUsesLocalRuntime(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ollamaResponse{Embedding: []float32{0.1, 0.2}})
+	}))
+	defer server.Close()
+
+	fallback := &mockProvider{
+		embedFunc: func(texts []string) ([][]float32, error) {
+			t.Error("Fallback should not be used")
+			return nil, nil
+		},
+		config: &Config{Model: "fallback"},
+	}
+
+	p, err := NewLocalProvider(&Config{Endpoint: server.URL}, fallback)
+	if err != nil {
+		t.Fatalf("NewLocalProvider failed: %v", err)
+	}
+
+	embeddings, err := p.Embed([]string{"hello"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(embeddings) != 1 || len(embeddings[0]) != 2 {
+		t.Errorf("Unexpected embeddings %v", embeddings)
+	}
+	if p.UsingFallback() {
+		t.Error("Expected local runtime to stay in use")
+	}
+	if p.Config().Model != DefaultOllamaModel {
+		t.Errorf("Expected default Ollama model, got %q", p.Config().Model)
+	}
+}
+
+// TestLocalProviderFallback tests switching to the fallback when the runtime is down
+func TestLocalProviderFallback(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	endpoint := server.URL
+	server.Close()
+
+	calls := 0
+	fallback := &mockProvider{
+		embedFunc: func(texts []string) ([][]float32, error) {
+			calls++
+			return [][]float32{{1, 2, 3}}, nil
+		},
+		config: &Config{Model: "fallback"},
+	}
+
+	p, err := NewLocalProvider(&Config{Endpoint: endpoint}, fallback)
+	if err != nil {
+		t.Fatalf("NewLocalProvider failed: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := p.Embed([]string{"hello"}); err != nil {
+			t.Fatalf("Embed failed: %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected fallback to handle both calls, got %d", calls)
+	}
+	if !p.UsingFallback() || p.Config().Model != "fallback" {
+		t.Error("Expected provider to report the fallback as active")
+	}
+	if model, ok := FallbackModel(p); !ok || model != "fallback" {
+		t.Errorf("FallbackModel() = %q, %v", model, ok)
+	}
+
+	noFallback, _ := NewLocalProvider(&Config{Endpoint: endpoint}, nil)
+	if _, err := noFallback.Embed([]string{"hello"}); err == nil {
+		t.Error("Expected error without a fallback")
+	}
+
+	// Invalid input is not a reason to fall back
+	fresh, _ := NewLocalProvider(&Config{Endpoint: endpoint}, fallback)
+	if _, err := fresh.Embed([]string{" "}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected invalid input error, got %v", err)
+	}
+	if fresh.UsingFallback() {
+		t.Error("Expected invalid input to keep the local runtime")
+	}
+	if _, ok := FallbackModel(fresh); ok {
+		t.Error("Expected FallbackModel to report the local runtime")
+	}
+}
+
+// TestLocalProviderCancel tests that a cancelled request returns without
+// waiting for the runtime and without switching to the fallback
+func TestLocalProviderCancel(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	fallback := &mockProvider{
+		embedFunc: func(texts []string) ([][]float32, error) {
+			t.Error("Fallback should not be used")
+			return nil, nil
+		},
+		config: &Config{Model: "fallback"},
+	}
+	p, err := NewLocalProvider(&Config{Endpoint: server.URL}, fallback)
+	if err != nil {
+		t.Fatalf("NewLocalProvider failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := p.EmbedContext(ctx, []string{"hello"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("EmbedContext() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("EmbedContext() returned after %s", elapsed)
+	}
+	if p.UsingFallback() {
+		t.Error("Expected a cancelled request to keep the local runtime")
+	}
+}
@@ -0,0 +1,150 @@
+package embed
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// DefaultMockModel is the model name reported by the mock provider
+const DefaultMockModel = "mock"
+
+// DefaultMockDimension is the embedding dimension of the mock provider
+const DefaultMockDimension = 256
+
+// mockEndpoint stands in for an endpoint in index metadata
+const mockEndpoint = "mock://"
+
+// MockProvider produces deterministic embeddings from hashed words, with no
+// model or network. Texts sharing words get similar vectors, so indexing and
+// search behave plausibly, and the same input always yields the same index,
+// which makes smoke tests and bug reports reproducible.
+type MockProvider struct {
+	config *Config
+}
+
+// NewMockProvider creates a mock provider. cfg.Dimensions sets the vector
+// size (DefaultMockDimension when 0); the model name seeds the hashes.
+func NewMockProvider(cfg *Config) (*MockProvider, error) {
+	if cfg == nil {
+		return nil, errors.New("config is required")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = mockEndpoint
+	}
+	if cfg.Model == "" {
+		cfg.Model = DefaultMockModel
+	}
+	if cfg.Dimensions == 0 {
+		cfg.Dimensions = DefaultMockDimension
+	}
+	if cfg.Dimensions < 0 {
+		return nil, errors.New("dimensions must be positive")
+	}
+	return &MockProvider{config: cfg}, nil
+}
+
+// Config returns the provider configuration
+func (p *MockProvider) Config() *Config {
+	return p.config
+}
+
+// Dimension returns the configured embedding dimension
+func (p *MockProvider) Dimension() (int, error) {
+	return p.config.Dimensions, nil
+}
+
+// Embed returns one unit-length vector per text
+func (p *MockProvider) Embed(texts []string) ([][]float32, error) {
+	return p.EmbedContext(context.Background(), texts)
+}
+
+// EmbedContext is Embed, failing with ctx's error once it is done
+func (p *MockProvider) EmbedContext(ctx context.Context, texts []string) ([][]float32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embeddings[i] = p.embed(text)
+	}
+	return embeddings, nil
+}
+
+// EmbedBatch embeds texts; the mock has no batch limit
+func (p *MockProvider) EmbedBatch(texts []string, batchSize int) ([][]float32, error) {
+	return p.Embed(texts)
+}
+
+// embed hashes each word of text (identifiers are split at case changes and
+// underscores) into a signed bucket and normalizes the result
+func (p *MockProvider) embed(text string) []float32 {
+	vec := make([]float32, p.config.Dimensions)
+	words := mockWords(text)
+	if len(words) == 0 {
+		words = []string{text}
+	}
+	for _, word := range words {
+		h := fnv.New64a()
+		h.Write([]byte(p.config.Model))
+		h.Write([]byte{0})
+		h.Write([]byte(word))
+		sum := h.Sum64()
+		bucket := sum % uint64(len(vec))
+		if sum>>63 == 1 {
+			vec[bucket]--
+		} else {
+			vec[bucket]++
+		}
+	}
+
+	var norm float64
+	for _, v := range vec {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		// Every word cancelled out; fall back to a fixed direction
+		vec[0] = 1
+		return vec
+	}
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range vec {
+		vec[i] *= scale
+	}
+	return vec
+}
+
+// mockWords splits text into lower-case words, breaking identifiers such as
+// parseConfig and parse_config into their parts
+func mockWords(text string) []string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+
+	runes := []rune(text)
+	for i, r := range runes {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if unicode.IsUpper(r) && len(word) > 0 {
+				prev := runes[i-1]
+				nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+					flush()
+				}
+			}
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
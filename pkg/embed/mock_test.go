@@ -0,0 +1,77 @@
+package embed
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// TestMockProviderDeterministic tests that equal inputs give equal vectors
+func TestMockProviderDeterministic(t *testing.T) {
+	p, err := NewMockProvider(&Config{Dimensions: 32})
+	if err != nil {
+		t.Fatalf("NewMockProvider: %v", err)
+	}
+	q, err := NewMockProvider(&Config{Dimensions: 32})
+	if err != nil {
+		t.Fatalf("NewMockProvider: %v", err)
+	}
+
+	texts := []string{"func parseConfig(path string) error", ""}
+	a, _ := p.Embed(texts)
+	b, _ := q.EmbedBatch(texts, 1)
+	if !reflect.DeepEqual(a, b) {
+		t.Error("Expected identical embeddings across providers")
+	}
+
+	for i, vec := range a {
+		if len(vec) != 32 {
+			t.Fatalf("len(vec[%d]) = %d, want 32", i, len(vec))
+		}
+		var norm float64
+		for _, v := range vec {
+			norm += float64(v) * float64(v)
+		}
+		if math.Abs(norm-1) > 1e-5 {
+			t.Errorf("vec[%d] norm = %f, want 1", i, norm)
+		}
+	}
+}
+
+// TestMockProviderSimilarity tests that shared words bring texts closer
+func TestMockProviderSimilarity(t *testing.T) {
+	p, err := NewMockProvider(&Config{})
+	if err != nil {
+		t.Fatalf("NewMockProvider: %v", err)
+	}
+	if dim, _ := p.Dimension(); dim != DefaultMockDimension {
+		t.Errorf("Dimension = %d, want %d", dim, DefaultMockDimension)
+	}
+
+	vecs, _ := p.Embed([]string{"parseConfig", "parse_config file", "render widget"})
+	dot := func(a, b []float32) float32 {
+		var s float32
+		for i := range a {
+			s += a[i] * b[i]
+		}
+		return s
+	}
+	if dot(vecs[0], vecs[1]) <= dot(vecs[0], vecs[2]) {
+		t.Error("Expected parseConfig closer to parse_config than to render widget")
+	}
+}
+
+// TestMockWords tests identifier splitting
+func TestMockWords(t *testing.T) {
+	tests := map[string][]string{
+		"parseConfig":      {"parse", "config"},
+		"parse_config":     {"parse", "config"},
+		"HTTPServer.Run()": {"http", "server", "run"},
+		"v2Client":         {"v2", "client"},
+	}
+	for in, want := range tests {
+		if got := mockWords(in); !reflect.DeepEqual(got, want) {
+			t.Errorf("mockWords(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
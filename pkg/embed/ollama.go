@@ -10,6 +10,8 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+
+	"github.com/l3aro/go-context-query/pkg/types"
 )
 
 // DefaultOllamaModel is the default embedding model for Ollama
@@ -66,7 +68,7 @@ func NewOllamaProvider(cfg *Config) (*OllamaProvider, error) {
 
 	return &OllamaProvider{
 		config:     cfg,
-		httpClient: http.DefaultClient,
+		httpClient: providerClient(cfg, http.DefaultClient),
 	}, nil
 }
 
@@ -77,8 +79,13 @@ func (p *OllamaProvider) Config() *Config {
 	return p.config
 }
 
-// Embed generates embeddings for the given texts using Ollama API
+// Embed is EmbedContext without a deadline
 func (p *OllamaProvider) Embed(texts []string) ([][]float32, error) {
+	return p.EmbedContext(context.Background(), texts)
+}
+
+// EmbedContext generates embeddings for the given texts using Ollama API
+func (p *OllamaProvider) EmbedContext(ctx context.Context, texts []string) ([][]float32, error) {
 	if len(texts) == 0 {
 		return [][]float32{}, nil
 	}
@@ -91,11 +98,16 @@ func (p *OllamaProvider) Embed(texts []string) ([][]float32, error) {
 	}
 
 	// Use batch processing
-	return p.EmbedBatch(texts, p.config.BatchSize)
+	return p.embedBatches(ctx, texts, p.config.BatchSize)
 }
 
 // EmbedBatch generates embeddings for texts in batches
 func (p *OllamaProvider) EmbedBatch(texts []string, batchSize int) ([][]float32, error) {
+	return p.embedBatches(context.Background(), texts, batchSize)
+}
+
+// embedBatches sends texts in batches of batchSize until ctx is done
+func (p *OllamaProvider) embedBatches(ctx context.Context, texts []string, batchSize int) ([][]float32, error) {
 	if len(texts) == 0 {
 		return [][]float32{}, nil
 	}
@@ -120,7 +132,7 @@ func (p *OllamaProvider) EmbedBatch(texts []string, batchSize int) ([][]float32,
 		}
 
 		batch := texts[i:end]
-		embeddings, err := p.embedBatchRequest(batch)
+		embeddings, err := p.embedBatchRequest(ctx, batch)
 		if err != nil {
 			return nil, fmt.Errorf("batch %d-%d: %w", i, end, err)
 		}
@@ -132,7 +144,7 @@ func (p *OllamaProvider) EmbedBatch(texts []string, batchSize int) ([][]float32,
 }
 
 // embedBatchRequest sends a single batch request to Ollama API
-func (p *OllamaProvider) embedBatchRequest(texts []string) ([][]float32, error) {
+func (p *OllamaProvider) embedBatchRequest(ctx context.Context, texts []string) ([][]float32, error) {
 	// Ollama API only supports single prompt, so we only take the first one
 	if len(texts) == 0 {
 		return [][]float32{}, nil
@@ -151,7 +163,7 @@ func (p *OllamaProvider) embedBatchRequest(texts []string) ([][]float32, error)
 
 	endpoint := p.config.Endpoint + "/api/embeddings"
 
-	req, err := http.NewRequestWithContext(context.Background(), "POST", endpoint, bytes.NewReader(reqBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -247,8 +259,5 @@ func (p *OllamaProvider) EmbedWithMetadata(texts []string) (*EmbedResult, error)
 func (p *OllamaProvider) TruncateText(text string, maxTokens int) string {
 	// Rough estimate: 1 token ≈ 4 characters
 	maxChars := maxTokens * 4
-	if len(text) <= maxChars {
-		return text
-	}
-	return text[:maxChars]
+	return types.TruncateUTF8(text, maxChars)
 }
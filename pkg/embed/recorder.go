@@ -0,0 +1,239 @@
+package embed
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// RecordEnv enables record mode: provider requests go to the network and
+// their responses are saved to the cassette
+const RecordEnv = "GCQ_RECORD"
+
+// CassetteEnv names the cassette file provider requests are recorded to or
+// replayed from. When unset, providers talk to the network as usual.
+const CassetteEnv = "GCQ_CASSETTE"
+
+// ErrNotRecorded is returned in replay mode for a request with no recorded response
+var ErrNotRecorded = errors.New("request not recorded")
+
+// Interaction is a provider request and the response it received
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest identifies a request. Headers are not stored, so API keys
+// never end up in a cassette.
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body"`
+}
+
+// RecordedResponse is the part of a response providers read
+type RecordedResponse struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+// cassette is the on-disk format of a recording
+type cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Recorder is an http.RoundTripper that records provider responses to a
+// cassette file, or replays them from it without touching the network, so
+// integration tests of the build and search pipeline can run offline.
+// Requests match on method, URL and body.
+type Recorder struct {
+	path   string
+	record bool
+	next   http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+// NewRecorder returns a recorder for the cassette at path. In record mode
+// requests are sent through next (http.DefaultTransport when nil) and each
+// response is written to the cassette as it arrives; in replay mode the
+// cassette must exist.
+func NewRecorder(path string, record bool, next http.RoundTripper) (*Recorder, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	r := &Recorder{path: path, record: record, next: next}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		var c cassette
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("parsing cassette %s: %w", path, err)
+		}
+		r.interactions = c.Interactions
+	case errors.Is(err, os.ErrNotExist) && record:
+	default:
+		return nil, fmt.Errorf("reading cassette: %w", err)
+	}
+	return r, nil
+}
+
+// Recording reports whether GCQ_RECORD asks for record mode
+func Recording() bool {
+	v := strings.ToLower(os.Getenv(RecordEnv))
+	return v == "1" || v == "true" || v == "yes"
+}
+
+// RoundTrip replays or records req
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading request body: %w", err)
+		}
+	}
+	key := RecordedRequest{Method: req.Method, URL: req.URL.String(), Body: string(body)}
+
+	if !r.record {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for _, in := range r.interactions {
+			if in.Request == key {
+				return in.Response.httpResponse(req), nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s %s in %s (re-record with %s=1)", ErrNotRecorded, req.Method, key.URL, r.path, RecordEnv)
+	}
+
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+	resp, err := r.next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+
+	recorded := RecordedResponse{
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(respBody),
+	}
+	if err := r.save(Interaction{Request: key, Response: recorded}); err != nil {
+		return nil, err
+	}
+	return recorded.httpResponse(req), nil
+}
+
+// save adds or replaces the interaction for its request and writes the cassette
+func (r *Recorder) save(in Interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	replaced := false
+	for i := range r.interactions {
+		if r.interactions[i].Request == in.Request {
+			r.interactions[i] = in
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		r.interactions = append(r.interactions, in)
+	}
+
+	data, err := json.MarshalIndent(cassette{Interactions: r.interactions}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("creating cassette directory: %w", err)
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing cassette: %w", err)
+	}
+	return os.Rename(tmp, r.path)
+}
+
+// httpResponse builds the response returned to the provider
+func (rr RecordedResponse) httpResponse(req *http.Request) *http.Response {
+	header := make(http.Header)
+	if rr.ContentType != "" {
+		header.Set("Content-Type", rr.ContentType)
+	}
+	return &http.Response{
+		StatusCode:    rr.Status,
+		Status:        fmt.Sprintf("%d %s", rr.Status, http.StatusText(rr.Status)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(rr.Body)),
+		ContentLength: int64(len(rr.Body)),
+		Request:       req,
+	}
+}
+
+// errTransport fails every request, for a cassette that could not be loaded
+type errTransport struct{ err error }
+
+func (t errTransport) RoundTrip(*http.Request) (*http.Response, error) { return nil, t.err }
+
+var (
+	envTransportOnce sync.Once
+	envTransport     http.RoundTripper
+)
+
+// transportFromEnv returns the recorder selected by GCQ_CASSETTE and
+// GCQ_RECORD, shared by all providers in the process, or nil when no
+// cassette is configured
+func transportFromEnv() http.RoundTripper {
+	envTransportOnce.Do(func() {
+		path := os.Getenv(CassetteEnv)
+		if path == "" {
+			return
+		}
+		rec, err := NewRecorder(path, Recording(), nil)
+		if err != nil {
+			log.Printf("embed: %v", err)
+			envTransport = errTransport{err}
+			return
+		}
+		envTransport = rec
+	})
+	return envTransport
+}
+
+// providerClient returns client with its transport replaced by
+// cfg.Transport or the GCQ_CASSETTE recorder, when either is set
+func providerClient(cfg *Config, client *http.Client) *http.Client {
+	transport := cfg.Transport
+	if transport == nil {
+		transport = transportFromEnv()
+	}
+	if transport == nil {
+		return client
+	}
+	c := *client
+	c.Transport = transport
+	return &c
+}
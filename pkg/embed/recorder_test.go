@@ -0,0 +1,103 @@
+package embed
+
+import (
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeOllama serves deterministic embeddings derived from the prompt
+func fakeOllama(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h := fnv.New32a()
+		h.Write([]byte(req.Prompt))
+		seed := float32(h.Sum32()%1000) / 1000
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ollamaResponse{Embedding: []float32{seed, 1 - seed, 0.5}})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRecorderRecordAndReplay(t *testing.T) {
+	srv := fakeOllama(t)
+	path := filepath.Join(t.TempDir(), "cassettes", "ollama.json")
+
+	rec, err := NewRecorder(path, true, nil)
+	if err != nil {
+		t.Fatalf("NewRecorder(record) error = %v", err)
+	}
+	live, err := NewOllamaProvider(&Config{Endpoint: srv.URL, APIKey: "secret-token", Transport: rec})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := live.Embed([]string{"parse config", "open socket"})
+	if err != nil {
+		t.Fatalf("recording Embed() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("cassette not written: %v", err)
+	}
+	if strings.Contains(string(data), "secret-token") {
+		t.Error("cassette contains the API key")
+	}
+
+	// Replay with the server gone
+	srv.Close()
+	replay, err := NewRecorder(path, false, nil)
+	if err != nil {
+		t.Fatalf("NewRecorder(replay) error = %v", err)
+	}
+	offline, err := NewOllamaProvider(&Config{Endpoint: srv.URL, APIKey: "secret-token", Transport: replay})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := offline.Embed([]string{"parse config", "open socket"})
+	if err != nil {
+		t.Fatalf("replayed Embed() error = %v", err)
+	}
+	for i := range want {
+		for j := range want[i] {
+			if got[i][j] != want[i][j] {
+				t.Fatalf("replayed embedding %d = %v, want %v", i, got[i], want[i])
+			}
+		}
+	}
+
+	_, err = offline.Embed([]string{"never recorded"})
+	if !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("Embed() of an unrecorded text error = %v, want ErrNotRecorded", err)
+	}
+}
+
+func TestNewRecorderReplayMissingCassette(t *testing.T) {
+	if _, err := NewRecorder(filepath.Join(t.TempDir(), "missing.json"), false, nil); err == nil {
+		t.Error("NewRecorder() in replay mode should fail without a cassette")
+	}
+}
+
+func TestRecording(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		want  bool
+	}{{"", false}, {"0", false}, {"1", true}, {"true", true}} {
+		t.Setenv(RecordEnv, tc.value)
+		if got := Recording(); got != tc.want {
+			t.Errorf("Recording() with %s=%q = %v, want %v", RecordEnv, tc.value, got, tc.want)
+		}
+	}
+}
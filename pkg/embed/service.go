@@ -108,7 +108,7 @@ func (s *EmbeddingService) embedWithRetry(ctx context.Context, provider Provider
 		default:
 		}
 
-		embeddings, err := provider.Embed(texts)
+		embeddings, err := provider.EmbedContext(ctx, texts)
 		if err == nil {
 			return embeddings, nil
 		}
@@ -226,6 +226,15 @@ func createProviderFromConfig(cfg *config.Config, isWarm bool) (Provider, error)
 		Model:    model,
 	}
 
+	if providerType == config.ProviderLocal {
+		// The token belongs to the HuggingFace fallback, not the local runtime
+		embedConfig.APIKey = ""
+		return NewLocalProviderFromConfig(embedConfig, cfg)
+	}
+	if providerType == config.ProviderMock {
+		return NewMockProviderFromConfig(cfg)
+	}
+
 	return NewProvider(providerType, embedConfig)
 }
 
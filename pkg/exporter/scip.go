@@ -0,0 +1,418 @@
+// Package exporter writes the units and call graph of a semantic index in
+// formats other code intelligence tools read, so they can reuse gcq's
+// extraction instead of indexing the project again.
+package exporter
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// SCIP indexes are protobuf messages (see scip.proto in
+// github.com/sourcegraph/scip). The exporter encodes the fields it fills
+// directly in the wire format, the counterpart of pkg/importer's reader.
+
+// scipKinds maps unit types to SymbolInformation.Kind values
+var scipKinds = map[string]uint64{
+	"class":     7,  // Class
+	"function":  17, // Function
+	"interface": 21, // Interface
+	"method":    26, // Method
+	"trait":     53, // Trait
+}
+
+// scipLanguages maps gcq language names to SCIP Language names; others are
+// written as they are
+var scipLanguages = map[string]string{
+	"c":          "C",
+	"cpp":        "CPP",
+	"csharp":     "CSharp",
+	"go":         "Go",
+	"java":       "Java",
+	"javascript": "JavaScript",
+	"kotlin":     "Kotlin",
+	"lua":        "Lua",
+	"php":        "PHP",
+	"python":     "Python",
+	"ruby":       "Ruby",
+	"rust":       "Rust",
+	"scala":      "Scala",
+	"swift":      "Swift",
+	"typescript": "TypeScript",
+}
+
+const (
+	scipDefinitionRole = 1
+	// scipUTF8 is both TextEncoding.UTF8 and
+	// PositionEncoding.UTF8CodeUnitOffsetFromLineStart
+	scipUTF8 = 1
+)
+
+// SCIPStats counts what WriteSCIP wrote
+type SCIPStats struct {
+	Documents  int `json:"documents"`
+	Symbols    int `json:"symbols"`
+	References int `json:"references"`
+}
+
+// WriteSCIP writes units, with file paths relative to rootDir, as a SCIP
+// index. Each unit becomes a global symbol with its definition, signature
+// and docs. Call graph edges become reference occurrences at the call sites
+// found in the caller's source; an edge whose call site can't be found in
+// the caller's body is left out.
+func WriteSCIP(w io.Writer, rootDir string, units []*types.CodeUnit, toolVersion string) (SCIPStats, error) {
+	var stats SCIPStats
+	pkg := scipEscapeSpaces(filepath.Base(rootDir))
+
+	byFile := make(map[string][]*types.CodeUnit)
+	symbols := make(map[*types.CodeUnit]string, len(units))
+	for _, u := range units {
+		if _, ok := scipKinds[u.Type]; !ok {
+			continue
+		}
+		byFile[u.FilePath] = append(byFile[u.FilePath], u)
+		symbols[u] = scipSymbol(pkg, u)
+	}
+	files := make([]string, 0, len(byFile))
+	for file, fileUnits := range byFile {
+		files = append(files, file)
+		sort.SliceStable(fileUnits, func(i, j int) bool { return fileUnits[i].LineNumber < fileUnits[j].LineNumber })
+	}
+	sort.Strings(files)
+
+	var index protoMessage
+	var metadata, tool protoMessage
+	tool.string(1, "gcq")
+	tool.string(2, toolVersion)
+	metadata.message(2, tool)
+	metadata.string(3, "file://"+filepath.ToSlash(rootDir))
+	metadata.uint(4, scipUTF8)
+	index.message(1, metadata)
+	if _, err := w.Write(index); err != nil {
+		return stats, err
+	}
+
+	for _, file := range files {
+		fileUnits := byFile[file]
+		lines := readLines(filepath.Join(rootDir, file))
+
+		var doc protoMessage
+		doc.string(1, filepath.ToSlash(file))
+		doc.string(4, scipLanguage(fileUnits[0].Language))
+		doc.uint(6, scipUTF8)
+
+		for i, u := range fileUnits {
+			end := unitEnd(fileUnits, i, lines)
+
+			var occ protoMessage
+			occ.ints(1, definitionRange(lines, u))
+			occ.string(2, symbols[u])
+			occ.uint(3, scipDefinitionRole)
+			if end > 0 {
+				occ.ints(7, []int32{int32(u.LineNumber - 1), 0, int32(end - 1), int32(len(lineAt(lines, end)))})
+			}
+			doc.message(2, occ)
+
+			sites := callSites(lines, u, end, calleesOf(u, byFile))
+			for _, site := range sites {
+				var occ protoMessage
+				occ.ints(1, site.rng)
+				occ.string(2, symbols[site.callee])
+				doc.message(2, occ)
+			}
+			stats.References += len(sites)
+		}
+
+		for _, u := range fileUnits {
+			doc.message(3, symbolInformation(u, symbols, byFile[u.FilePath]))
+			stats.Symbols++
+		}
+		stats.Documents++
+
+		var entry protoMessage
+		entry.message(2, doc)
+		if _, err := w.Write(entry); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// symbolInformation encodes the SymbolInformation of u
+func symbolInformation(u *types.CodeUnit, symbols map[*types.CodeUnit]string, fileUnits []*types.CodeUnit) protoMessage {
+	var info protoMessage
+	info.string(1, symbols[u])
+	if u.Docstring != "" {
+		info.string(3, u.Docstring)
+	}
+	info.uint(5, scipKinds[u.Type])
+	info.string(6, simpleName(u.Name))
+	if u.Signature != "" {
+		var sig protoMessage
+		sig.string(4, scipLanguage(u.Language))
+		sig.string(5, u.Signature)
+		info.message(7, sig)
+	}
+	if i := strings.LastIndex(u.Name, "."); i > 0 {
+		owner := u.Name[:i]
+		for _, other := range fileUnits {
+			if other.Name == owner && other.Type != "function" && other.Type != "method" {
+				info.string(8, symbols[other])
+				break
+			}
+		}
+	}
+	return info
+}
+
+// scipSymbol returns the global SCIP symbol of u: the file's path as
+// namespaces, then the owner types and the unit itself
+func scipSymbol(pkg string, u *types.CodeUnit) string {
+	var sb strings.Builder
+	sb.WriteString("gcq . ")
+	sb.WriteString(pkg)
+	sb.WriteString(" . ")
+	for _, part := range strings.Split(filepath.ToSlash(u.FilePath), "/") {
+		sb.WriteString(scipDescriptorName(part))
+		sb.WriteByte('/')
+	}
+
+	parts := strings.Split(u.Name, ".")
+	for _, owner := range parts[:len(parts)-1] {
+		sb.WriteString(scipDescriptorName(owner))
+		sb.WriteByte('#')
+	}
+	sb.WriteString(scipDescriptorName(parts[len(parts)-1]))
+	if u.Type == "function" || u.Type == "method" {
+		sb.WriteString("().")
+	} else {
+		sb.WriteByte('#')
+	}
+	return sb.String()
+}
+
+// scipDescriptorName returns name as a SCIP descriptor name, in backticks
+// unless it is a simple identifier
+func scipDescriptorName(name string) string {
+	simple := name != ""
+	for i := 0; i < len(name) && simple; i++ {
+		c := name[i]
+		simple = c == '_' || c == '+' || c == '-' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+	}
+	if simple {
+		return name
+	}
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// scipEscapeSpaces doubles the spaces of a symbol's package name, or returns
+// "." for an empty one
+func scipEscapeSpaces(s string) string {
+	if s == "" || s == "." || s == string(filepath.Separator) {
+		return "."
+	}
+	return strings.ReplaceAll(s, " ", "  ")
+}
+
+func scipLanguage(lang string) string {
+	if name, ok := scipLanguages[lang]; ok {
+		return name
+	}
+	return lang
+}
+
+// simpleName returns the last part of a qualified unit name
+func simpleName(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// readLines returns the lines of a file, or nil if it can't be read
+func readLines(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(string(data), "\n")
+}
+
+// lineAt returns 1-based line n, or "" when out of range
+func lineAt(lines []string, n int) string {
+	if n < 1 || n > len(lines) {
+		return ""
+	}
+	return lines[n-1]
+}
+
+// unitEnd returns the last line of fileUnits[i]: its recorded end line for
+// functions and methods, otherwise the last non-blank line before the next
+// unit. Types get an end line only when it was recorded, since their
+// members follow them. It returns 0 when the source is missing.
+func unitEnd(fileUnits []*types.CodeUnit, i int, lines []string) int {
+	u := fileUnits[i]
+	lineCount := len(lines)
+	if lineCount == 0 || u.LineNumber < 1 || u.LineNumber > lineCount {
+		return 0
+	}
+	if u.EndLine >= u.LineNumber {
+		return min(u.EndLine, lineCount)
+	}
+	if u.Type != "function" && u.Type != "method" {
+		return 0
+	}
+	end := lineCount
+	for _, next := range fileUnits[i+1:] {
+		if next.LineNumber > u.LineNumber {
+			end = next.LineNumber - 1
+			break
+		}
+	}
+	for end > u.LineNumber && strings.TrimSpace(lineAt(lines, end)) == "" {
+		end--
+	}
+	return end
+}
+
+// definitionRange returns the range of u's name on its definition line, or
+// of the whole line when the name isn't on it
+func definitionRange(lines []string, u *types.CodeUnit) []int32 {
+	line := int32(max(u.LineNumber-1, 0))
+	text := lineAt(lines, u.LineNumber)
+	name := simpleName(u.Name)
+	if cols := wordColumns(text, name); len(cols) > 0 {
+		return []int32{line, int32(cols[0]), int32(cols[0] + len(name))}
+	}
+	indent := len(text) - len(strings.TrimLeft(text, " \t"))
+	return []int32{line, int32(indent), int32(len(strings.TrimRight(text, " \t\r")))}
+}
+
+// calleesOf resolves the "file:Name" keys of u.Calls to units
+func calleesOf(u *types.CodeUnit, byFile map[string][]*types.CodeUnit) []*types.CodeUnit {
+	var callees []*types.CodeUnit
+	seen := make(map[*types.CodeUnit]bool)
+	for _, key := range u.Calls {
+		file, name, ok := strings.Cut(key, ":")
+		if !ok {
+			continue
+		}
+		for _, callee := range byFile[file] {
+			if seen[callee] || callee == u || (callee.Name != name && !strings.HasSuffix(callee.Name, "."+name)) {
+				continue
+			}
+			seen[callee] = true
+			callees = append(callees, callee)
+		}
+	}
+	return callees
+}
+
+type callSite struct {
+	rng    []int32
+	callee *types.CodeUnit
+}
+
+// callSites finds where the body of u, ending at line end, calls each
+// callee: its name followed by "(", or failing that any use of the name
+func callSites(lines []string, u *types.CodeUnit, end int, callees []*types.CodeUnit) []callSite {
+	if end == 0 || len(callees) == 0 {
+		return nil
+	}
+	defCol := int(definitionRange(lines, u)[1])
+	var sites []callSite
+	for _, callee := range callees {
+		name := simpleName(callee.Name)
+		var calls, uses []callSite
+		for n := u.LineNumber; n <= end; n++ {
+			text := lineAt(lines, n)
+			for _, col := range wordColumns(text, name) {
+				if n == u.LineNumber && col == defCol {
+					continue
+				}
+				site := callSite{rng: []int32{int32(n - 1), int32(col), int32(col + len(name))}, callee: callee}
+				if strings.HasPrefix(strings.TrimLeft(text[col+len(name):], " \t"), "(") {
+					calls = append(calls, site)
+				} else {
+					uses = append(uses, site)
+				}
+			}
+		}
+		if len(calls) == 0 {
+			calls = uses
+		}
+		sites = append(sites, calls...)
+	}
+	return sites
+}
+
+// wordColumns returns the byte offsets where word appears in text as a
+// whole identifier
+func wordColumns(text, word string) []int {
+	if word == "" {
+		return nil
+	}
+	var cols []int
+	for from := 0; ; {
+		i := strings.Index(text[from:], word)
+		if i < 0 {
+			return cols
+		}
+		start, end := from+i, from+i+len(word)
+		if (start == 0 || !isIdentByte(text[start-1])) && (end == len(text) || !isIdentByte(text[end])) {
+			cols = append(cols, start)
+		}
+		from = end
+	}
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// protoMessage is an encoded protobuf message being built field by field.
+// Zero values are skipped, as proto3 does.
+type protoMessage []byte
+
+func (m *protoMessage) key(field, wire int) {
+	*m = binary.AppendUvarint(*m, uint64(field)<<3|uint64(wire))
+}
+
+func (m *protoMessage) uint(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	m.key(field, 0)
+	*m = binary.AppendUvarint(*m, v)
+}
+
+func (m *protoMessage) bytes(field int, b []byte) {
+	m.key(field, 2)
+	*m = binary.AppendUvarint(*m, uint64(len(b)))
+	*m = append(*m, b...)
+}
+
+func (m *protoMessage) string(field int, s string) {
+	if s != "" {
+		m.bytes(field, []byte(s))
+	}
+}
+
+func (m *protoMessage) message(field int, sub protoMessage) {
+	m.bytes(field, sub)
+}
+
+// ints writes a packed repeated int32 field
+func (m *protoMessage) ints(field int, vs []int32) {
+	if len(vs) == 0 {
+		return
+	}
+	var packed []byte
+	for _, v := range vs {
+		packed = binary.AppendUvarint(packed, uint64(uint32(v)))
+	}
+	m.bytes(field, packed)
+}
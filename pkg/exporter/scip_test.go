@@ -0,0 +1,105 @@
+package exporter
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/importer"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+const parserSource = `class Parser:
+    def parse(self, text):
+        return tokenize(text)
+
+
+def tokenize(text):
+    """Split text into words."""
+    return text.split()
+
+
+def main():
+    p = Parser()
+    p.parse("a b")
+`
+
+func TestWriteSCIPRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.py"), []byte(parserSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	units := []*types.CodeUnit{
+		{Name: "Parser", Type: "class", Language: "python", FilePath: "main.py", LineNumber: 1, Signature: "class Parser"},
+		{Name: "Parser.parse", Type: "method", Language: "python", FilePath: "main.py", LineNumber: 2,
+			Signature: "def parse(self, text)", Calls: []string{"main.py:tokenize"}},
+		{Name: "tokenize", Type: "function", Language: "python", FilePath: "main.py", LineNumber: 6,
+			Signature: "def tokenize(text)", Docstring: "Split text into words."},
+		{Name: "main", Type: "function", Language: "python", FilePath: "main.py", LineNumber: 11,
+			Signature: "def main()", Calls: []string{"main.py:Parser", "main.py:parse"}},
+	}
+
+	var buf bytes.Buffer
+	stats, err := WriteSCIP(&buf, dir, units, "test")
+	if err != nil {
+		t.Fatalf("WriteSCIP failed: %v", err)
+	}
+	if stats != (SCIPStats{Documents: 1, Symbols: 4, References: 3}) {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	path := filepath.Join(dir, "index.scip")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	imported, err := importer.Load(path, dir, importer.FormatSCIP)
+	if err != nil {
+		t.Fatalf("importing the export failed: %v", err)
+	}
+
+	byName := make(map[string]*types.CodeUnit)
+	for _, u := range imported {
+		byName[u.Name] = u
+	}
+	for _, want := range units {
+		got, ok := byName[want.Name]
+		if !ok {
+			t.Errorf("%s missing from the import, got %v", want.Name, imported)
+			continue
+		}
+		if got.Type != want.Type || got.LineNumber != want.LineNumber || got.Signature != want.Signature || got.Docstring != want.Docstring {
+			t.Errorf("%s imported as %+v", want.Name, got)
+		}
+	}
+	if u := byName["tokenize"]; u == nil || !slices.Contains(u.CalledBy, "main.py:Parser.parse") {
+		t.Errorf("tokenize callers not exported: %+v", u)
+	}
+	if u := byName["Parser.parse"]; u == nil || !slices.Contains(u.CalledBy, "main.py:main") {
+		t.Errorf("Parser.parse callers not exported: %+v", u)
+	}
+}
+
+func TestSCIPSymbol(t *testing.T) {
+	tests := []struct {
+		unit types.CodeUnit
+		want string
+	}{
+		{types.CodeUnit{Name: "tokenize", Type: "function", FilePath: "src/main.py"}, "gcq . my  app . src/`main.py`/tokenize()."},
+		{types.CodeUnit{Name: "Parser.parse", Type: "method", FilePath: "parser.go"}, "gcq . my  app . `parser.go`/Parser#parse()."},
+		{types.CodeUnit{Name: "Store", Type: "interface", FilePath: "a b/store.ts"}, "gcq . my  app . `a b`/`store.ts`/Store#"},
+	}
+	for _, tt := range tests {
+		if got := scipSymbol("my  app", &tt.unit); got != tt.want {
+			t.Errorf("scipSymbol(%s) = %q, want %q", tt.unit.Name, got, tt.want)
+		}
+	}
+}
+
+func TestWordColumns(t *testing.T) {
+	got := wordColumns("parse(x) + reparse(parse_all(parse))", "parse")
+	if want := []int{0, 29}; !slices.Equal(got, want) {
+		t.Errorf("wordColumns = %v, want %v", got, want)
+	}
+}
@@ -31,7 +31,7 @@ type CExtractor struct {
 // NewCExtractor creates a new C extractor with initialized parser.
 func NewCExtractor() Extractor {
 	return &CExtractor{
-		BaseExtractor: NewBaseExtractor(NewCParser(), C),
+		BaseExtractor: NewBaseExtractor(&cParserPool, C),
 	}
 }
 
@@ -64,7 +64,7 @@ func (e *CExtractor) Extract(filePath string) (*types.ModuleInfo, error) {
 // ExtractFromBytes extracts module information from C source code bytes.
 func (e *CExtractor) ExtractFromBytes(content []byte, filePath string) (*types.ModuleInfo, error) {
 	// Parse the full AST
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -517,12 +517,7 @@ func (e *CExtractor) nodeText(node *sitter.Node, content []byte) string {
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
 
 // ExtractFunctions extracts only function definitions from a C file.
@@ -532,7 +527,7 @@ func (e *CExtractor) ExtractFunctions(filePath string) ([]types.Function, error)
 		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
 	}
 
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
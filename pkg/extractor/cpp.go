@@ -31,7 +31,7 @@ type CPPExtractor struct {
 // NewCPPExtractor creates a new C++ extractor with initialized parser.
 func NewCPPExtractor() Extractor {
 	return &CPPExtractor{
-		BaseExtractor: NewBaseExtractor(NewCPPParser(), CPP),
+		BaseExtractor: NewBaseExtractor(&cppParserPool, CPP),
 	}
 }
 
@@ -64,7 +64,7 @@ func (e *CPPExtractor) Extract(filePath string) (*types.ModuleInfo, error) {
 // ExtractFromBytes extracts module information from C++ source code bytes.
 func (e *CPPExtractor) ExtractFromBytes(content []byte, filePath string) (*types.ModuleInfo, error) {
 	// Parse the full AST
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -749,12 +749,7 @@ func (e *CPPExtractor) nodeText(node *sitter.Node, content []byte) string {
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
 
 // ExtractFunctions extracts only function definitions from a C++ file.
@@ -764,7 +759,7 @@ func (e *CPPExtractor) ExtractFunctions(filePath string) ([]types.Function, erro
 		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
 	}
 
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -781,7 +776,7 @@ func (e *CPPExtractor) ExtractClasses(filePath string) ([]types.Class, error) {
 		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
 	}
 
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -4,6 +4,7 @@ package extractor
 import (
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 
 	"github.com/l3aro/go-context-query/pkg/types"
@@ -28,7 +29,7 @@ type CSharpExtractor struct {
 // NewCSharpExtractor creates a new C# extractor with initialized parser.
 func NewCSharpExtractor() Extractor {
 	return &CSharpExtractor{
-		BaseExtractor: NewBaseExtractor(NewCSharpParser(), CSharp),
+		BaseExtractor: NewBaseExtractor(&csharpParserPool, CSharp),
 	}
 }
 
@@ -59,167 +60,257 @@ func (e *CSharpExtractor) Extract(filePath string) (*types.ModuleInfo, error) {
 }
 
 // ExtractFromBytes extracts module information from C# source code bytes.
+// Classes, structs and records are listed as classes qualified with their
+// namespace and enclosing types, XML doc comments (///) become docstrings
+// and attributes become decorators.
 func (e *CSharpExtractor) ExtractFromBytes(content []byte, filePath string) (*types.ModuleInfo, error) {
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
 	defer tree.Close()
 
-	root := tree.RootNode()
-
-	classes := e.extractClasses(root, content)
-	interfaces := e.extractInterfaces(root, content)
-	imports := e.extractUsingStatements(root, content)
-
-	return &types.ModuleInfo{
-		Path:       filePath,
-		Functions:  []types.Function{},
-		Classes:    classes,
-		Interfaces: interfaces,
-		Imports:    imports,
-		CallGraph:  types.CallGraph{Edges: []types.CallGraphEdge{}},
-	}, nil
+	info := &types.ModuleInfo{
+		Path:      filePath,
+		Language:  string(e.Language()),
+		Functions: []types.Function{},
+		CallGraph: types.CallGraph{Edges: []types.CallGraphEdge{}},
+	}
+	e.walkDeclarations(tree.RootNode(), content, "", info)
+	return info, nil
 }
 
-// extractUsingStatements extracts using statements.
-func (e *CSharpExtractor) extractUsingStatements(node *sitter.Node, content []byte) []types.Import {
-	var imports []types.Import
-	e.walkForUsing(node, content, &imports)
-	return imports
+// walkDeclarations collects the declarations among node's children into
+// info. scope is the namespace and enclosing types in effect, set by a
+// file-scoped "namespace X;", an enclosing "namespace X { }" block or an
+// enclosing type.
+func (e *CSharpExtractor) walkDeclarations(node *sitter.Node, content []byte, scope string, info *types.ModuleInfo) {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child == nil {
+			continue
+		}
+		switch child.Type() {
+		case "namespace_declaration":
+			name := qualifyCSharpName(scope, e.getNodeText(child.ChildByFieldName("name"), content))
+			if body := child.ChildByFieldName("body"); body != nil {
+				e.walkDeclarations(body, content, name, info)
+			}
+		case "file_scoped_namespace_declaration":
+			scope = qualifyCSharpName(scope, e.getNodeText(child.ChildByFieldName("name"), content))
+		case "using_directive":
+			if imp := e.parseUsingDirective(child, content); imp != nil {
+				info.Imports = append(info.Imports, *imp)
+			}
+		case "class_declaration", "struct_declaration", "record_declaration", "record_struct_declaration":
+			class := e.parseClass(child, content, scope)
+			if class == nil {
+				continue
+			}
+			info.Classes = append(info.Classes, *class)
+			// Nested types are qualified with the enclosing type
+			if body := child.ChildByFieldName("body"); body != nil {
+				e.walkDeclarations(body, content, class.QualifiedName, info)
+			}
+		case "interface_declaration":
+			if iface := e.parseInterface(child, content); iface != nil {
+				info.Interfaces = append(info.Interfaces, *iface)
+			}
+		case "enum_declaration":
+			if enum := e.parseEnum(child, content); enum != nil {
+				info.Enums = append(info.Enums, *enum)
+			}
+		}
+	}
 }
 
-func (e *CSharpExtractor) walkForUsing(node *sitter.Node, content []byte, imports *[]types.Import) {
-	if node == nil {
-		return
-	}
-	if node.Type() == "using_directive" {
-		lineNumber := int(node.StartPoint().Row) + 1
-		module := e.getNodeText(node, content)
-		*imports = append(*imports, types.Import{
-			Module:     module,
-			LineNumber: lineNumber,
-		})
-		return
-	}
+// parseUsingDirective returns the import of a using directive. An alias
+// ("using Json = System.Text.Json;") is the imported name; "using static"
+// brings in the members of a type, like a from-import.
+func (e *CSharpExtractor) parseUsingDirective(node *sitter.Node, content []byte) *types.Import {
+	var names []string
+	isStatic := false
 	for i := 0; i < int(node.ChildCount()); i++ {
-		e.walkForUsing(node.Child(i), content, imports)
+		child := node.Child(i)
+		if child == nil {
+			continue
+		}
+		switch child.Type() {
+		case "static":
+			isStatic = true
+		case "identifier", "qualified_name", "generic_name", "alias_qualified_name":
+			names = append(names, e.getNodeText(child, content))
+		}
+	}
+	if len(names) == 0 {
+		return nil
 	}
-}
 
-// extractClasses extracts class definitions.
-func (e *CSharpExtractor) extractClasses(node *sitter.Node, content []byte) []types.Class {
-	var classes []types.Class
-	e.walkForClasses(node, content, &classes)
-	return classes
+	imp := &types.Import{
+		Module:     names[len(names)-1],
+		IsFrom:     isStatic,
+		LineNumber: int(node.StartPoint().Row) + 1,
+	}
+	if len(names) > 1 {
+		imp.Names = []string{names[0]}
+	}
+	return imp
 }
 
-func (e *CSharpExtractor) walkForClasses(node *sitter.Node, content []byte, classes *[]types.Class) {
-	if node == nil {
-		return
+// parseClass extracts a class, struct or record with its base class and
+// interfaces, XML doc comment, attributes, constructors and methods
+func (e *CSharpExtractor) parseClass(node *sitter.Node, content []byte, scope string) *types.Class {
+	name := e.getNodeText(node.ChildByFieldName("name"), content)
+	if name == "" {
+		return nil
 	}
-	if node.Type() == "class_declaration" {
-		lineNumber := int(node.StartPoint().Row) + 1
-		var name string
-		var methods []types.Method
-
-		for i := 0; i < int(node.ChildCount()); i++ {
-			child := node.Child(i)
-			if child != nil && child.Type() == "identifier" {
-				name = e.getNodeText(child, content)
-			}
-			if child != nil && child.Type() == "class_body" {
-				methods = e.extractMethods(child, content)
-			}
-		}
-
-		if name != "" {
-			*classes = append(*classes, types.Class{
-				Name:       name,
-				Methods:    methods,
-				LineNumber: lineNumber,
-			})
-		}
-		return
+	class := &types.Class{
+		Name:          name,
+		QualifiedName: qualifyCSharpName(scope, name),
+		Bases:         e.baseTypes(node, content),
+		Docstring:     e.xmlDoc(node, content),
+		Decorators:    e.attributes(node, content),
+		LineNumber:    int(node.StartPoint().Row) + 1,
 	}
-	for i := 0; i < int(node.ChildCount()); i++ {
-		e.walkForClasses(node.Child(i), content, classes)
+	if body := node.ChildByFieldName("body"); body != nil {
+		class.Methods = e.extractMethods(body, content)
 	}
+	return class
 }
 
-// extractInterfaces extracts interface definitions.
-func (e *CSharpExtractor) extractInterfaces(node *sitter.Node, content []byte) []types.Interface {
-	var interfaces []types.Interface
-	e.walkForInterfaces(node, content, &interfaces)
-	return interfaces
+// parseInterface extracts an interface with the interfaces it extends
+func (e *CSharpExtractor) parseInterface(node *sitter.Node, content []byte) *types.Interface {
+	name := e.getNodeText(node.ChildByFieldName("name"), content)
+	if name == "" {
+		return nil
+	}
+	iface := &types.Interface{
+		Name:       name,
+		Bases:      e.baseTypes(node, content),
+		Docstring:  e.xmlDoc(node, content),
+		LineNumber: int(node.StartPoint().Row) + 1,
+	}
+	if body := node.ChildByFieldName("body"); body != nil {
+		iface.Methods = e.extractMethods(body, content)
+	}
+	return iface
 }
 
-func (e *CSharpExtractor) walkForInterfaces(node *sitter.Node, content []byte, interfaces *[]types.Interface) {
-	if node == nil {
-		return
+// parseEnum extracts an enum with its members
+func (e *CSharpExtractor) parseEnum(node *sitter.Node, content []byte) *types.Enum {
+	name := e.getNodeText(node.ChildByFieldName("name"), content)
+	if name == "" {
+		return nil
 	}
-	if node.Type() == "interface_declaration" {
-		lineNumber := int(node.StartPoint().Row) + 1
-		var name string
-
-		for i := 0; i < int(node.ChildCount()); i++ {
-			child := node.Child(i)
-			if child != nil && child.Type() == "identifier" {
-				name = e.getNodeText(child, content)
+	enum := &types.Enum{
+		Name:       name,
+		Docstring:  e.xmlDoc(node, content),
+		LineNumber: int(node.StartPoint().Row) + 1,
+	}
+	if body := node.ChildByFieldName("body"); body != nil {
+		for i := 0; i < int(body.NamedChildCount()); i++ {
+			if child := body.NamedChild(i); child != nil && child.Type() == "enum_member_declaration" {
+				enum.Variants = append(enum.Variants, e.getNodeText(child.ChildByFieldName("name"), content))
 			}
 		}
+	}
+	return enum
+}
 
-		if name != "" {
-			*interfaces = append(*interfaces, types.Interface{
-				Name:       name,
-				LineNumber: lineNumber,
-			})
+// extractMethods extracts the methods and constructors of a type body.
+func (e *CSharpExtractor) extractMethods(node *sitter.Node, content []byte) []types.Method {
+	var methods []types.Method
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child == nil || (child.Type() != "method_declaration" && child.Type() != "constructor_declaration") {
+			continue
+		}
+		name := e.getNodeText(child.ChildByFieldName("name"), content)
+		if name == "" {
+			continue
 		}
-		return
+		methods = append(methods, types.Method{
+			Name:       name,
+			Params:     e.getNodeText(child.ChildByFieldName("parameters"), content),
+			ReturnType: e.getNodeText(child.ChildByFieldName("returns"), content),
+			Docstring:  e.xmlDoc(child, content),
+			Decorators: e.attributes(child, content),
+			LineNumber: int(child.StartPoint().Row) + 1,
+			IsMethod:   true,
+			IsAsync:    e.hasModifier(child, "async", content),
+		})
 	}
-	for i := 0; i < int(node.ChildCount()); i++ {
-		e.walkForInterfaces(node.Child(i), content, interfaces)
+	return methods
+}
+
+// xmlDoc returns the XML doc comment (consecutive /// lines, or a /** */
+// block) directly above a declaration, or "". Attributes belong to the
+// declaration node, so the comment may sit above them.
+func (e *CSharpExtractor) xmlDoc(node *sitter.Node, content []byte) string {
+	var lines []string
+	for prev := node.PrevNamedSibling(); prev != nil && prev.Type() == "comment"; prev = prev.PrevNamedSibling() {
+		text := e.getNodeText(prev, content)
+		if strings.HasPrefix(text, "/**") && len(lines) == 0 {
+			return text
+		}
+		if !strings.HasPrefix(text, "///") {
+			break
+		}
+		lines = append([]string{text}, lines...)
 	}
+	return strings.Join(lines, "\n")
 }
 
-// extractMethods extracts method definitions from a class body.
-func (e *CSharpExtractor) extractMethods(node *sitter.Node, content []byte) []types.Method {
-	var methods []types.Method
-	for i := 0; i < int(node.ChildCount()); i++ {
-		child := node.Child(i)
-		if child == nil {
+// attributes returns the names of a declaration's attributes, e.g.
+// "HttpPost" for [HttpPost] or "Route" for [Route("api/orders")]
+func (e *CSharpExtractor) attributes(node *sitter.Node, content []byte) []string {
+	var names []string
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		list := node.NamedChild(i)
+		if list == nil || list.Type() != "attribute_list" {
 			continue
 		}
-		if child.Type() == "method_declaration" {
-			lineNumber := int(child.StartPoint().Row) + 1
-			var name, params, returnType string
-
-			for j := 0; j < int(child.ChildCount()); j++ {
-				mchild := child.Child(j)
-				if mchild == nil {
-					continue
-				}
-				switch mchild.Type() {
-				case "identifier":
-					name = e.getNodeText(mchild, content)
-				case "parameter_list":
-					params = e.getNodeText(mchild, content)
-				case "predefined_type", "builtin_type":
-					returnType = e.getNodeText(mchild, content)
-				}
+		for j := 0; j < int(list.NamedChildCount()); j++ {
+			if attr := list.NamedChild(j); attr != nil && attr.Type() == "attribute" {
+				names = append(names, e.getNodeText(attr.ChildByFieldName("name"), content))
 			}
+		}
+	}
+	return names
+}
 
-			if name != "" {
-				methods = append(methods, types.Method{
-					Name:       name,
-					Params:     params,
-					ReturnType: returnType,
-					LineNumber: lineNumber,
-				})
+// baseTypes returns the types listed after the colon of a type declaration
+func (e *CSharpExtractor) baseTypes(node *sitter.Node, content []byte) []string {
+	var bases []string
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		list := node.NamedChild(i)
+		if list == nil || list.Type() != "base_list" {
+			continue
+		}
+		for j := 0; j < int(list.NamedChildCount()); j++ {
+			base := list.NamedChild(j)
+			if base == nil {
+				continue
 			}
+			// record Payment(...) : Base(Amount) passes arguments to its base
+			if base.Type() == "primary_constructor_base_type" {
+				base = base.NamedChild(0)
+			}
+			bases = append(bases, e.getNodeText(base, content))
 		}
 	}
-	return methods
+	return bases
+}
+
+// hasModifier reports whether a declaration has a modifier such as async
+func (e *CSharpExtractor) hasModifier(node *sitter.Node, modifier string, content []byte) bool {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child != nil && child.Type() == "modifier" && e.getNodeText(child, content) == modifier {
+			return true
+		}
+	}
+	return false
 }
 
 // getNodeText extracts text from a node.
@@ -227,10 +318,13 @@ func (e *CSharpExtractor) getNodeText(node *sitter.Node, content []byte) string
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
+	return types.NodeText(content, node.StartByte(), node.EndByte())
+}
+
+// qualifyCSharpName prefixes name with scope, if any
+func qualifyCSharpName(scope, name string) string {
+	if scope == "" {
+		return name
 	}
-	return string(content[start:end])
+	return scope + "." + name
 }
@@ -0,0 +1,113 @@
+package extractor
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+const csharpSource = `using System;
+using static System.Math;
+global using Json = System.Text.Json;
+
+namespace Acme.Billing
+{
+    /// <summary>
+    /// An invoice.
+    /// </summary>
+    [Serializable, Table("invoices")]
+    public partial class Invoice : EntityBase, IPayable
+    {
+        /// <summary>Creates an invoice.</summary>
+        public Invoice(int id) { }
+
+        /// <summary>Pays the invoice.</summary>
+        [HttpPost]
+        public async Task<bool> PayAsync(CancellationToken ct = default) { return true; }
+
+        private class Line { }
+    }
+
+    /// <summary>A payment.</summary>
+    public record Payment(decimal Amount) : IPayable;
+
+    public interface IPayable : IDisposable
+    {
+        Task<bool> PayAsync(CancellationToken ct);
+    }
+
+    public enum Status { Open, Paid = 2 }
+}
+`
+
+func TestCSharpExtractor(t *testing.T) {
+	m, err := NewCSharpExtractor().(*CSharpExtractor).ExtractFromBytes([]byte(csharpSource), "Invoice.cs")
+	if err != nil {
+		t.Fatalf("ExtractFromBytes failed: %v", err)
+	}
+
+	wantImports := []types.Import{
+		{Module: "System"},
+		{Module: "System.Math", IsFrom: true},
+		{Module: "System.Text.Json", Names: []string{"Json"}},
+	}
+	if len(m.Imports) != len(wantImports) {
+		t.Fatalf("imports = %+v", m.Imports)
+	}
+	for i, want := range wantImports {
+		if got := m.Imports[i]; got.Module != want.Module || got.IsFrom != want.IsFrom || !slices.Equal(got.Names, want.Names) {
+			t.Errorf("import %d = %+v, want %+v", i, got, want)
+		}
+	}
+
+	var names []string
+	for _, c := range m.Classes {
+		names = append(names, c.QualifiedName)
+	}
+	if want := []string{"Acme.Billing.Invoice", "Acme.Billing.Invoice.Line", "Acme.Billing.Payment"}; !slices.Equal(names, want) {
+		t.Fatalf("classes = %v, want %v", names, want)
+	}
+
+	invoice := m.Classes[0]
+	if invoice.Docstring != "/// <summary>\n/// An invoice.\n/// </summary>" {
+		t.Errorf("docstring = %q", invoice.Docstring)
+	}
+	if !slices.Equal(invoice.Bases, []string{"EntityBase", "IPayable"}) || !slices.Equal(invoice.Decorators, []string{"Serializable", "Table"}) {
+		t.Errorf("class = %+v", invoice)
+	}
+	if len(invoice.Methods) != 2 {
+		t.Fatalf("methods = %+v", invoice.Methods)
+	}
+	if ctor := invoice.Methods[0]; ctor.Name != "Invoice" || ctor.Params != "(int id)" {
+		t.Errorf("constructor = %+v", ctor)
+	}
+	pay := invoice.Methods[1]
+	if pay.ReturnType != "Task<bool>" || !pay.IsAsync || pay.Docstring != "/// <summary>Pays the invoice.</summary>" || !slices.Equal(pay.Decorators, []string{"HttpPost"}) {
+		t.Errorf("method = %+v", pay)
+	}
+
+	if payment := m.Classes[2]; payment.Docstring == "" || !slices.Equal(payment.Bases, []string{"IPayable"}) {
+		t.Errorf("record = %+v", payment)
+	}
+	if len(m.Interfaces) != 1 || !slices.Equal(m.Interfaces[0].Bases, []string{"IDisposable"}) || len(m.Interfaces[0].Methods) != 1 {
+		t.Errorf("interfaces = %+v", m.Interfaces)
+	}
+	if len(m.Enums) != 1 || !slices.Equal(m.Enums[0].Variants, []string{"Open", "Paid"}) {
+		t.Errorf("enums = %+v", m.Enums)
+	}
+}
+
+func TestCSharpFileScopedNamespace(t *testing.T) {
+	code := `namespace Acme.Api;
+
+public sealed record class Order(int Id);
+`
+	m, err := NewCSharpExtractor().(*CSharpExtractor).ExtractFromBytes([]byte(code), "Order.cs")
+	if err != nil {
+		t.Fatalf("ExtractFromBytes failed: %v", err)
+	}
+	if len(m.Classes) != 1 || m.Classes[0].QualifiedName != "Acme.Api.Order" {
+		t.Errorf("classes = %+v", m.Classes)
+	}
+}
@@ -0,0 +1,267 @@
+package extractor
+
+import (
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// Directive comments control indexing from the source itself
+const (
+	// DirectiveIgnoreFile keeps the whole file out of the index
+	DirectiveIgnoreFile = "gcq:ignore-file"
+	// DirectiveIgnoreNext keeps the next definition out of the index
+	DirectiveIgnoreNext = "gcq:ignore-next"
+	// DirectiveTag tags the next definition, or every unit of the file
+	// when it comes before the first definition and a blank line parts
+	// them
+	DirectiveTag = "gcq:tag"
+)
+
+// directivePattern matches a directive after a line or block comment
+// marker of any supported language
+var directivePattern = regexp.MustCompile(`(?://|#|/\*|\*|--|;|<!--)\s*gcq:(ignore-file|ignore-next|tag)\b(.*)`)
+
+// Directives are the gcq: comments of a source file
+type Directives struct {
+	// IgnoreFile is set by a gcq:ignore-file comment anywhere in the file
+	IgnoreFile bool
+	// FileTags are the tags of gcq:tag comments in the file header, set
+	// by Apply
+	FileTags []string
+	// Tags maps definitions, such as "parse" or "Parser.parse", to the
+	// tags of the gcq:tag comments above them, set by Apply. A class's
+	// tags also apply to its methods.
+	Tags map[string][]string
+
+	ignoreNext []int
+	tagNext    map[int][]string
+	blanks     []int
+}
+
+// ParseDirectives finds the directive comments in content
+func ParseDirectives(content []byte) *Directives {
+	d := &Directives{}
+	for i, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == "" {
+			d.blanks = append(d.blanks, i+1)
+			continue
+		}
+		if !strings.Contains(line, "gcq:") {
+			continue
+		}
+		m := directivePattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		switch "gcq:" + m[1] {
+		case DirectiveIgnoreFile:
+			d.IgnoreFile = true
+		case DirectiveIgnoreNext:
+			d.ignoreNext = append(d.ignoreNext, i+1)
+		case DirectiveTag:
+			if tags := parseTags(m[2]); len(tags) > 0 {
+				if d.tagNext == nil {
+					d.tagNext = make(map[int][]string)
+				}
+				d.tagNext[i+1] = append(d.tagNext[i+1], tags...)
+			}
+		}
+	}
+	return d
+}
+
+// ReadDirectives reads the directive comments of the file at path
+func ReadDirectives(path string) (*Directives, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDirectives(content), nil
+}
+
+// parseTags splits the arguments of a gcq:tag comment, separated by
+// spaces or commas, dropping a block comment's closing marker
+func parseTags(args string) []string {
+	args = strings.NewReplacer("*/", " ", "-->", " ", ",", " ").Replace(args)
+	var tags []string
+	for _, tag := range strings.Fields(args) {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// Apply removes the definitions below gcq:ignore-next comments from module
+// and resolves gcq:tag comments into FileTags and Tags. A directive applies
+// to the first definition, function, class, method or type, that starts
+// after it.
+func (d *Directives) Apply(module *types.ModuleInfo) {
+	defs := definitionLines(module)
+	if len(defs) == 0 {
+		return
+	}
+	next := func(line int) int {
+		i, _ := slices.BinarySearch(defs, line+1)
+		if i == len(defs) {
+			return 0
+		}
+		return defs[i]
+	}
+
+	ignored := make(map[int]bool)
+	for _, line := range d.ignoreNext {
+		if target := next(line); target > 0 {
+			ignored[target] = true
+		}
+	}
+	if len(ignored) > 0 {
+		removeDefinitions(module, ignored)
+	}
+
+	for _, line := range slices.Sorted(maps.Keys(d.tagNext)) {
+		tags := d.tagNext[line]
+		target := next(line)
+		if line < defs[0] && d.blankBetween(line, target) {
+			d.FileTags = appendTags(d.FileTags, tags)
+			continue
+		}
+		if target == 0 || ignored[target] {
+			continue
+		}
+		for _, name := range definitionNames(module, target) {
+			if d.Tags == nil {
+				d.Tags = make(map[string][]string)
+			}
+			d.Tags[name] = appendTags(d.Tags[name], tags)
+		}
+	}
+}
+
+// blankBetween reports whether a blank line lies between lines from and to
+func (d *Directives) blankBetween(from, to int) bool {
+	i, _ := slices.BinarySearch(d.blanks, from+1)
+	return i < len(d.blanks) && d.blanks[i] < to
+}
+
+// UnitTags returns the tags of the unit named name: the file's tags, then
+// those of the definition and, for a method, of its class
+func (d *Directives) UnitTags(name string) []string {
+	tags := appendTags(nil, d.FileTags)
+	if owner, _, ok := strings.Cut(name, "."); ok {
+		tags = appendTags(tags, d.Tags[owner])
+	}
+	return appendTags(tags, d.Tags[name])
+}
+
+// appendTags adds the tags not already in tags
+func appendTags(tags, more []string) []string {
+	for _, tag := range more {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// definitionLines returns the sorted, distinct lines definitions of module
+// start on
+func definitionLines(module *types.ModuleInfo) []int {
+	var lines []int
+	add := func(line int) {
+		if line > 0 {
+			lines = append(lines, line)
+		}
+	}
+	for _, fn := range module.Functions {
+		add(fn.LineNumber)
+	}
+	for _, cls := range module.Classes {
+		add(cls.LineNumber)
+		for _, m := range cls.Methods {
+			add(m.LineNumber)
+		}
+	}
+	for _, iface := range module.Interfaces {
+		add(iface.LineNumber)
+	}
+	for _, trait := range module.Traits {
+		add(trait.LineNumber)
+		for _, m := range trait.Methods {
+			add(m.LineNumber)
+		}
+	}
+	for _, p := range module.Protocols {
+		add(p.LineNumber)
+	}
+	for _, e := range module.Enums {
+		add(e.LineNumber)
+	}
+	for _, st := range module.Structs {
+		add(st.LineNumber)
+	}
+	slices.Sort(lines)
+	return slices.Compact(lines)
+}
+
+// definitionNames returns the names of the definitions starting on line,
+// methods qualified with their class
+func definitionNames(module *types.ModuleInfo, line int) []string {
+	var names []string
+	add := func(name string, l int) {
+		if l == line && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	for _, fn := range module.Functions {
+		add(fn.Name, fn.LineNumber)
+	}
+	for _, cls := range module.Classes {
+		add(cls.Name, cls.LineNumber)
+		for _, m := range cls.Methods {
+			add(cls.Name+"."+m.Name, m.LineNumber)
+		}
+	}
+	for _, iface := range module.Interfaces {
+		add(iface.Name, iface.LineNumber)
+	}
+	for _, trait := range module.Traits {
+		add(trait.Name, trait.LineNumber)
+		for _, m := range trait.Methods {
+			add(trait.Name+"."+m.Name, m.LineNumber)
+		}
+	}
+	for _, p := range module.Protocols {
+		add(p.Name, p.LineNumber)
+	}
+	for _, e := range module.Enums {
+		add(e.Name, e.LineNumber)
+	}
+	for _, st := range module.Structs {
+		add(st.Name, st.LineNumber)
+	}
+	return names
+}
+
+// removeDefinitions drops the definitions of module starting on the lines
+// in ignored, with the methods of the classes and traits dropped
+func removeDefinitions(module *types.ModuleInfo, ignored map[int]bool) {
+	module.Functions = slices.DeleteFunc(module.Functions, func(fn types.Function) bool { return ignored[fn.LineNumber] })
+	module.Classes = slices.DeleteFunc(module.Classes, func(cls types.Class) bool { return ignored[cls.LineNumber] })
+	for i := range module.Classes {
+		module.Classes[i].Methods = slices.DeleteFunc(module.Classes[i].Methods, func(m types.Method) bool { return ignored[m.LineNumber] })
+	}
+	module.Interfaces = slices.DeleteFunc(module.Interfaces, func(iface types.Interface) bool { return ignored[iface.LineNumber] })
+	module.Traits = slices.DeleteFunc(module.Traits, func(trait types.Trait) bool { return ignored[trait.LineNumber] })
+	for i := range module.Traits {
+		module.Traits[i].Methods = slices.DeleteFunc(module.Traits[i].Methods, func(m types.Method) bool { return ignored[m.LineNumber] })
+	}
+	module.Protocols = slices.DeleteFunc(module.Protocols, func(p types.Protocol) bool { return ignored[p.LineNumber] })
+	module.Enums = slices.DeleteFunc(module.Enums, func(e types.Enum) bool { return ignored[e.LineNumber] })
+	module.Structs = slices.DeleteFunc(module.Structs, func(st types.Struct) bool { return ignored[st.LineNumber] })
+}
@@ -0,0 +1,87 @@
+package extractor
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseDirectives(t *testing.T) {
+	d := ParseDirectives([]byte("// gcq:ignore-file\nx = 'gcq:ignore-next'\n/* gcq:tag billing, payments */\n"))
+	if !d.IgnoreFile {
+		t.Error("IgnoreFile not set")
+	}
+	if len(d.ignoreNext) != 0 {
+		t.Errorf("a directive in a string was honored: %v", d.ignoreNext)
+	}
+	if tags := d.tagNext[3]; !slices.Equal(tags, []string{"billing", "payments"}) {
+		t.Errorf("tags = %v", tags)
+	}
+}
+
+func TestDirectivesApply(t *testing.T) {
+	src := `# gcq:tag billing
+
+import stripe
+
+
+# gcq:ignore-next
+def debug_dump():
+    pass
+
+
+# gcq:tag payments
+class Charge:
+    # gcq:ignore-next
+    def _trace(self):
+        pass
+
+    # gcq:tag refunds
+    def refund(self):
+        pass
+
+
+def total():
+    pass
+`
+	module, err := NewPythonExtractor().(*PythonExtractor).ExtractFromBytes([]byte(src), "pay.py")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := ParseDirectives([]byte(src))
+	d.Apply(module)
+
+	var functions []string
+	for _, fn := range module.Functions {
+		functions = append(functions, fn.Name)
+	}
+	if !slices.Equal(functions, []string{"total"}) {
+		t.Errorf("functions = %v, want [total]", functions)
+	}
+	if len(module.Classes) != 1 || len(module.Classes[0].Methods) != 1 || module.Classes[0].Methods[0].Name != "refund" {
+		t.Fatalf("classes = %+v", module.Classes)
+	}
+
+	tests := map[string][]string{
+		"total":         {"billing"},
+		"Charge":        {"billing", "payments"},
+		"Charge.refund": {"billing", "payments", "refunds"},
+	}
+	for name, want := range tests {
+		if got := d.UnitTags(name); !slices.Equal(got, want) {
+			t.Errorf("UnitTags(%s) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestDirectivesTagAboveFirstDefinition(t *testing.T) {
+	src := "// gcq:tag auth\nfunction login() {}\n\nfunction logout() {}\n"
+	module, err := NewTypeScriptExtractor().(*TypeScriptExtractor).ExtractFromBytes([]byte(src), "auth.ts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := ParseDirectives([]byte(src))
+	d.Apply(module)
+	if len(d.FileTags) != 0 || !slices.Equal(d.UnitTags("login"), []string{"auth"}) || d.UnitTags("logout") != nil {
+		t.Errorf("file tags %v, login %v, logout %v", d.FileTags, d.UnitTags("login"), d.UnitTags("logout"))
+	}
+}
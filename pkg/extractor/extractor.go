@@ -52,6 +52,10 @@ const (
 	Kotlin Language = "kotlin"
 	// CSharp language support
 	CSharp Language = "csharp"
+	// Vue single-file component support
+	Vue Language = "vue"
+	// Svelte component support
+	Svelte Language = "svelte"
 )
 
 // ParserFactory is a function that creates a new tree-sitter parser for a language.
@@ -90,6 +94,9 @@ func NewLanguageRegistry() *LanguageRegistry {
 	registry.RegisterLanguage(Swift, []string{".swift"}, NewSwiftExtractor, NewSwiftParser)
 	registry.RegisterLanguage(Kotlin, []string{".kt", ".kts"}, NewKotlinExtractor, NewKotlinParser)
 	registry.RegisterLanguage(CSharp, []string{".cs", ".csx"}, NewCSharpExtractor, NewCSharpParser)
+	registry.RegisterLanguage(Vue, []string{".vue"}, NewVueExtractor, NewTypeScriptParser)
+	registry.RegisterLanguage(Svelte, []string{".svelte"}, NewSvelteExtractor, NewTypeScriptParser)
+	registry.registerGrammars()
 
 	return registry
 }
@@ -177,19 +184,30 @@ func (r *LanguageRegistry) GetSupportedExtensions() []string {
 }
 
 // BaseExtractor provides common functionality for all language extractors.
+// Parsers come from a pool, so one extractor can parse files on several
+// goroutines at once.
 type BaseExtractor struct {
-	parser *sitter.Parser
-	lang   Language
+	parsers *sync.Pool
+	lang    Language
 }
 
-// NewBaseExtractor creates a new base extractor.
-func NewBaseExtractor(parser *sitter.Parser, lang Language) *BaseExtractor {
+// NewBaseExtractor creates a new base extractor parsing with parsers from
+// the pool, such as goParserPool.
+func NewBaseExtractor(parsers *sync.Pool, lang Language) *BaseExtractor {
 	return &BaseExtractor{
-		parser: parser,
-		lang:   lang,
+		parsers: parsers,
+		lang:    lang,
 	}
 }
 
+// parse parses content with a parser borrowed from the pool. The tree
+// outlives the parser's return to the pool.
+func (b *BaseExtractor) parse(content []byte) *sitter.Tree {
+	parser := b.parsers.Get().(*sitter.Parser)
+	defer b.parsers.Put(parser)
+	return parser.Parse(nil, content)
+}
+
 // ExtractFile extracts module information from a file using the appropriate extractor.
 func ExtractFile(filePath string) (*types.ModuleInfo, error) {
 	registry := GetLanguageRegistry()
@@ -1,8 +1,10 @@
 package extractor
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 )
 
@@ -294,3 +296,42 @@ func TestGetExtractor(t *testing.T) {
 		t.Error("Expected error for unsupported file type")
 	}
 }
+
+// TestExtractConcurrent tests that one extractor can parse files on several
+// goroutines at once
+func TestExtractConcurrent(t *testing.T) {
+	tmpDir := t.TempDir()
+	sources := map[string]string{
+		"a.go": "package a\n\nfunc First() {}\n\nfunc Second() { First() }\n",
+		"b.py": "def first():\n    pass\n\ndef second():\n    first()\n",
+		"c.ts": "export function first() {}\n\nexport function second() { first(); }\n",
+	}
+	for name, source := range sources {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(source), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8*len(sources))
+	for range 8 {
+		for name := range sources {
+			wg.Add(1)
+			go func(path string) {
+				defer wg.Done()
+				info, err := ExtractFile(path)
+				if err == nil && len(info.Functions) != 2 {
+					err = fmt.Errorf("%s: got %d functions, want 2", path, len(info.Functions))
+				}
+				if err != nil {
+					errs <- err
+				}
+			}(filepath.Join(tmpDir, name))
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -4,6 +4,7 @@ package extractor
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -31,7 +32,7 @@ type GoExtractor struct {
 // NewGoExtractor creates a new Go extractor with initialized parser.
 func NewGoExtractor() Extractor {
 	return &GoExtractor{
-		BaseExtractor: NewBaseExtractor(NewGoParser(), Go),
+		BaseExtractor: NewBaseExtractor(&goParserPool, Go),
 	}
 }
 
@@ -59,7 +60,7 @@ func (e *GoExtractor) Extract(filePath string) (*types.ModuleInfo, error) {
 	}
 
 	// Parse the full AST
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -79,14 +80,17 @@ func (e *GoExtractor) Extract(filePath string) (*types.ModuleInfo, error) {
 	for _, s := range state.structs {
 		classes = append(classes, types.Class{
 			Name:       s.Name,
+			TypeParams: s.TypeParams,
 			Docstring:  s.Docstring,
 			Methods:    []types.Method{},
+			Fields:     s.FieldInfo,
 			LineNumber: s.LineNumber,
 		})
 	}
 	for _, i := range state.interfaces {
 		classes = append(classes, types.Class{
 			Name:       i.Name,
+			TypeParams: i.TypeParams,
 			Docstring:  i.Docstring,
 			Methods:    i.Methods,
 			LineNumber: i.LineNumber,
@@ -260,6 +264,7 @@ func (e *GoExtractor) parseFunctionDeclaration(node *sitter.Node, content []byte
 	}
 
 	var name string
+	var typeParams string
 	var params string
 	var returnType string
 
@@ -274,8 +279,19 @@ func (e *GoExtractor) parseFunctionDeclaration(node *sitter.Node, content []byte
 		switch child.Type() {
 		case "identifier":
 			name = e.nodeText(child, content)
+		case "type_parameter_list":
+			// Generic type parameters and constraints: [T any, U comparable]
+			typeParams = e.nodeText(child, content)
 		case "parameter_list":
-			params = e.nodeText(child, content)
+			// Multiple or named results are a parameter list too
+			if node.FieldNameForChild(i) == "result" {
+				returnType = e.nodeText(child, content)
+			} else {
+				params = e.nodeText(child, content)
+			}
+		case "generic_type":
+			// Instantiated generic return types like Stack[T]
+			returnType = e.nodeText(child, content)
 		case "pointer_type":
 			// Handle pointer return types like *int
 			returnType = e.nodeText(child, content)
@@ -312,6 +328,7 @@ func (e *GoExtractor) parseFunctionDeclaration(node *sitter.Node, content []byte
 
 	return &types.Function{
 		Name:       name,
+		TypeParams: typeParams,
 		Params:     params,
 		ReturnType: returnType,
 		LineNumber: lineNumber,
@@ -369,12 +386,18 @@ func (e *GoExtractor) parseMethodDeclaration(node *sitter.Node, content []byte)
 		case "field_identifier":
 			name = e.nodeText(child, content)
 		case "parameter_list":
-			// First parameter_list is the receiver, second is the method params
-			if receiver == "" {
-				receiver = e.nodeText(child, content)
-			} else {
+			// The receiver, the method params and multiple or named
+			// results are all parameter lists
+			switch node.FieldNameForChild(i) {
+			case "receiver":
+				receiver = e.receiverTypeName(child, content)
+			case "parameters":
 				params = e.nodeText(child, content)
+			case "result":
+				returnType = e.nodeText(child, content)
 			}
+		case "generic_type":
+			returnType = e.nodeText(child, content)
 		case "pointer_type":
 			returnType = e.nodeText(child, content)
 		case "qualified_type":
@@ -410,7 +433,25 @@ func (e *GoExtractor) parseMethodDeclaration(node *sitter.Node, content []byte)
 		ReturnType: returnType,
 		LineNumber: lineNumber,
 		IsMethod:   true,
+		Receiver:   receiver,
+	}
+}
+
+// receiverTypeName returns the bare type name of a method receiver list,
+// without pointer and type parameters, so (s *Store[K]) gives "Store"
+func (e *GoExtractor) receiverTypeName(receiver *sitter.Node, content []byte) string {
+	for i := 0; i < int(receiver.NamedChildCount()); i++ {
+		param := receiver.NamedChild(i)
+		if param == nil || param.Type() != "parameter_declaration" {
+			continue
+		}
+		typeName := strings.TrimLeft(e.nodeText(param.ChildByFieldName("type"), content), "*")
+		if j := strings.IndexByte(typeName, '['); j >= 0 {
+			typeName = typeName[:j]
+		}
+		return strings.TrimSpace(typeName)
 	}
+	return ""
 }
 
 // extractStructs extracts all struct definitions from the AST.
@@ -459,7 +500,9 @@ func (e *GoExtractor) parseTypeDeclaration(node *sitter.Node, content []byte) *s
 		}
 
 		var name string
+		var typeParams string
 		var fields []string
+		var fieldInfo []types.Field
 		var docstring string
 
 		// Parse type_spec children
@@ -485,8 +528,11 @@ func (e *GoExtractor) parseTypeDeclaration(node *sitter.Node, content []byte) *s
 						Struct:   types.Struct{},
 					}
 				}
+			case "type_parameter_list":
+				typeParams = e.nodeText(specChild, content)
 			case "struct_type":
 				fields = e.parseStructFields(specChild, content)
+				fieldInfo = e.parseStructFieldInfo(specChild, content)
 			case "interface_type":
 				// This is an interface, handled elsewhere
 				return nil
@@ -504,7 +550,9 @@ func (e *GoExtractor) parseTypeDeclaration(node *sitter.Node, content []byte) *s
 			IsStruct: true,
 			Struct: types.Struct{
 				Name:       name,
+				TypeParams: typeParams,
 				Fields:     fields,
+				FieldInfo:  fieldInfo,
 				Docstring:  docstring,
 				LineNumber: lineNumber,
 			},
@@ -555,6 +603,124 @@ func (e *GoExtractor) parseStructFields(node *sitter.Node, content []byte) []str
 	return fields
 }
 
+// parseStructFieldInfo extracts the name, type, tag and comment of each
+// field of a struct_type node. A declaration naming several fields, such as
+// "X, Y int", yields one entry per name.
+func (e *GoExtractor) parseStructFieldInfo(node *sitter.Node, content []byte) []types.Field {
+	var fields []types.Field
+
+	if node == nil {
+		return fields
+	}
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if child == nil || child.Type() != "field_declaration_list" {
+			continue
+		}
+
+		// Comment lines above a field document it; so does a comment
+		// after it on the same line
+		var pending []string
+		lastRow := -1
+		lastStart := -1
+		for j := 0; j < int(child.ChildCount()); j++ {
+			fieldChild := child.Child(j)
+			if fieldChild == nil {
+				continue
+			}
+
+			switch fieldChild.Type() {
+			case "comment":
+				text := goCommentText(e.nodeText(fieldChild, content))
+				if int(fieldChild.StartPoint().Row) == lastRow && lastStart >= 0 {
+					for k := lastStart; k < len(fields); k++ {
+						if fields[k].Doc == "" {
+							fields[k].Doc = text
+						}
+					}
+					continue
+				}
+				pending = append(pending, text)
+			case "field_declaration", "anonymous_field":
+				parsed := e.parseFieldDeclaration(fieldChild, content)
+				doc := strings.Join(pending, " ")
+				for k := range parsed {
+					parsed[k].Doc = doc
+				}
+				pending = nil
+				lastRow = int(fieldChild.EndPoint().Row)
+				lastStart = len(fields)
+				fields = append(fields, parsed...)
+			}
+		}
+	}
+
+	return fields
+}
+
+// parseFieldDeclaration extracts the fields of a field_declaration node.
+// An embedded field is named after its type without package or pointer,
+// as in Go.
+func (e *GoExtractor) parseFieldDeclaration(node *sitter.Node, content []byte) []types.Field {
+	var names []string
+	var typeText string
+	var tag string
+
+	for i := 0; i < int(node.ChildCount()); i++ {
+		child := node.Child(i)
+		if child == nil {
+			continue
+		}
+		switch node.FieldNameForChild(i) {
+		case "name":
+			names = append(names, e.nodeText(child, content))
+		case "type":
+			typeText = e.nodeText(child, content)
+		case "tag":
+			tag = e.nodeText(child, content)
+			if unquoted, err := strconv.Unquote(tag); err == nil {
+				tag = unquoted
+			}
+		}
+	}
+
+	if len(names) == 0 {
+		// Embedded field: the declaration holds "*" and the type
+		full := e.nodeText(node, content)
+		if i := strings.IndexAny(full, "`\""); i >= 0 {
+			full = full[:i]
+		}
+		typeText = strings.TrimSpace(full)
+		name := strings.TrimPrefix(typeText, "*")
+		if i := strings.Index(name, "["); i >= 0 {
+			name = name[:i]
+		}
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+		if name == "" {
+			return nil
+		}
+		return []types.Field{{Name: name, Type: typeText, Tag: tag, Embedded: true}}
+	}
+
+	fields := make([]types.Field, len(names))
+	for i, name := range names {
+		fields[i] = types.Field{Name: name, Type: typeText, Tag: tag}
+	}
+	return fields
+}
+
+// goCommentText strips the markers of a // or /* */ comment
+func goCommentText(comment string) string {
+	if strings.HasPrefix(comment, "//") {
+		return strings.TrimSpace(strings.TrimPrefix(comment, "//"))
+	}
+	comment = strings.TrimSuffix(strings.TrimPrefix(comment, "/*"), "*/")
+	return strings.TrimSpace(comment)
+}
+
 // extractInterfaces extracts all interface definitions from the AST.
 func (e *GoExtractor) extractInterfaces(node *sitter.Node, content []byte) []types.Interface {
 	var interfaces []types.Interface
@@ -598,6 +764,7 @@ func (e *GoExtractor) parseInterfaceDeclaration(node *sitter.Node, content []byt
 		}
 
 		var name string
+		var typeParams string
 		var methods []types.Method
 
 		// Parse type_spec children
@@ -610,6 +777,8 @@ func (e *GoExtractor) parseInterfaceDeclaration(node *sitter.Node, content []byt
 			switch specChild.Type() {
 			case "type_identifier":
 				name = e.nodeText(specChild, content)
+			case "type_parameter_list":
+				typeParams = e.nodeText(specChild, content)
 			case "interface_type":
 				methods = e.parseInterfaceMethods(specChild, content)
 			}
@@ -621,6 +790,7 @@ func (e *GoExtractor) parseInterfaceDeclaration(node *sitter.Node, content []byt
 
 		return &types.Interface{
 			Name:       name,
+			TypeParams: typeParams,
 			Methods:    methods,
 			LineNumber: lineNumber,
 		}
@@ -650,8 +820,9 @@ func (e *GoExtractor) parseInterfaceMethods(node *sitter.Node, content []byte) [
 			if method != nil {
 				methods = append(methods, *method)
 			}
-		case "field_declaration":
+		case "field_declaration", "type_elem":
 			// Embedded interface: type Reader interface { OtherInterface }
+			// or a constraint's type set: type Number interface { ~int | ~float64 }
 			method := e.parseEmbeddedInterfaceField(child, content)
 			if method != nil {
 				methods = append(methods, *method)
@@ -740,12 +911,7 @@ func (e *GoExtractor) nodeText(node *sitter.Node, content []byte) string {
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
 
 // ExtractFunctions extracts only function definitions from a Go file.
@@ -755,7 +921,7 @@ func (e *GoExtractor) ExtractFunctions(filePath string) ([]types.Function, error
 		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
 	}
 
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -769,7 +935,7 @@ func (e *GoExtractor) ExtractFunctions(filePath string) ([]types.Function, error
 
 // ExtractFromBytes extracts module information from Go source code bytes.
 func (e *GoExtractor) ExtractFromBytes(content []byte, filePath string) (*types.ModuleInfo, error) {
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing content failed")
 	}
@@ -787,14 +953,17 @@ func (e *GoExtractor) ExtractFromBytes(content []byte, filePath string) (*types.
 	for _, s := range state.structs {
 		classes = append(classes, types.Class{
 			Name:       s.Name,
+			TypeParams: s.TypeParams,
 			Docstring:  s.Docstring,
 			Methods:    []types.Method{},
+			Fields:     s.FieldInfo,
 			LineNumber: s.LineNumber,
 		})
 	}
 	for _, i := range state.interfaces {
 		classes = append(classes, types.Class{
 			Name:       i.Name,
+			TypeParams: i.TypeParams,
 			Docstring:  i.Docstring,
 			Methods:    i.Methods,
 			LineNumber: i.LineNumber,
@@ -929,7 +1098,9 @@ func (e *GoExtractor) extractTypeDeclaration(node *sitter.Node, content []byte,
 		}
 
 		var name string
+		var typeParams string
 		var fields []string
+		var fieldInfo []types.Field
 		var methods []types.Method
 		var isStruct bool
 		var isInterface bool
@@ -949,8 +1120,11 @@ func (e *GoExtractor) extractTypeDeclaration(node *sitter.Node, content []byte,
 					// Second type_identifier means it's a type alias
 					return
 				}
+			case "type_parameter_list":
+				typeParams = e.nodeText(specChild, content)
 			case "struct_type":
 				fields = e.parseStructFields(specChild, content)
+				fieldInfo = e.parseStructFieldInfo(specChild, content)
 				isStruct = true
 			case "interface_type":
 				methods = e.parseInterfaceMethods(specChild, content)
@@ -965,12 +1139,15 @@ func (e *GoExtractor) extractTypeDeclaration(node *sitter.Node, content []byte,
 		if isStruct {
 			state.structs = append(state.structs, types.Struct{
 				Name:       name,
+				TypeParams: typeParams,
 				Fields:     fields,
+				FieldInfo:  fieldInfo,
 				LineNumber: lineNumber,
 			})
 		} else if isInterface {
 			state.interfaces = append(state.interfaces, types.Interface{
 				Name:       name,
+				TypeParams: typeParams,
 				Methods:    methods,
 				LineNumber: lineNumber,
 			})
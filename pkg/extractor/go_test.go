@@ -76,6 +76,23 @@ func (m MyInt) Double() int {
 				if !fn.IsMethod {
 					t.Errorf("expected IsMethod to be true")
 				}
+				if fn.Receiver != "MyInt" {
+					t.Errorf("expected receiver MyInt, got %q", fn.Receiver)
+				}
+			},
+		},
+		{
+			name: "method with generic pointer receiver",
+			code: `package main
+
+func (s *Store[K, V]) Get(key K) V {
+	return s.items[key]
+}
+`,
+			check: func(t *testing.T, m *types.ModuleInfo) {
+				if len(m.Functions) != 1 || m.Functions[0].Receiver != "Store" {
+					t.Errorf("expected Get on Store, got %+v", m.Functions)
+				}
 			},
 		},
 		{
@@ -100,6 +117,43 @@ type Person struct {
 				}
 			},
 		},
+		{
+			name: "struct fields with types and tags",
+			code: "package main\n\n" +
+				"type Config struct {\n" +
+				"\tio.Reader\n" +
+				"\t*Base\n" +
+				"\t// SocketPath is where the daemon listens\n" +
+				"\tSocketPath string `yaml:\"socket_path\" env:\"GCQ_SOCKET_PATH\"`\n" +
+				"\tMin, Max int // score bounds\n" +
+				"\tHooks map[string]func(ctx context.Context) error\n" +
+				"}\n",
+			check: func(t *testing.T, m *types.ModuleInfo) {
+				if len(m.Structs) != 1 {
+					t.Fatalf("expected 1 struct, got %d", len(m.Structs))
+				}
+				want := []types.Field{
+					{Name: "Reader", Type: "io.Reader", Embedded: true},
+					{Name: "Base", Type: "*Base", Embedded: true},
+					{Name: "SocketPath", Type: "string", Tag: `yaml:"socket_path" env:"GCQ_SOCKET_PATH"`, Doc: "SocketPath is where the daemon listens"},
+					{Name: "Min", Type: "int", Doc: "score bounds"},
+					{Name: "Max", Type: "int", Doc: "score bounds"},
+					{Name: "Hooks", Type: "map[string]func(ctx context.Context) error"},
+				}
+				got := m.Structs[0].FieldInfo
+				if len(got) != len(want) {
+					t.Fatalf("expected %d fields, got %+v", len(want), got)
+				}
+				for i := range want {
+					if got[i] != want[i] {
+						t.Errorf("field %d: expected %+v, got %+v", i, want[i], got[i])
+					}
+				}
+				if len(m.Classes) != 1 || len(m.Classes[0].Fields) != len(want) {
+					t.Errorf("expected the fields on the Config class, got %+v", m.Classes)
+				}
+			},
+		},
 		{
 			name: "interface definition",
 			code: `package main
@@ -175,6 +229,107 @@ func (c Container) Method2() {}
 				}
 			},
 		},
+		{
+			name: "multiple results",
+			code: `package main
+
+type Store struct{}
+
+func Parse(s string) (int, error) {
+	return 0, nil
+}
+
+func (st *Store) Get(key string) (value []byte, ok bool) {
+	return nil, false
+}
+`,
+			check: func(t *testing.T, m *types.ModuleInfo) {
+				if len(m.Functions) != 2 {
+					t.Fatalf("expected 2 functions, got %d", len(m.Functions))
+				}
+				for _, fn := range m.Functions {
+					want := map[string][2]string{
+						"Parse": {"(s string)", "(int, error)"},
+						"Get":   {"(key string)", "(value []byte, ok bool)"},
+					}[fn.Name]
+					if fn.Params != want[0] || fn.ReturnType != want[1] {
+						t.Errorf("%s: expected params %q and results %q, got %q and %q", fn.Name, want[0], want[1], fn.Params, fn.ReturnType)
+					}
+				}
+			},
+		},
+		{
+			name: "generic function",
+			code: `package main
+
+func Map[T any, U comparable](xs []T, f func(T) U) []U {
+	return nil
+}
+`,
+			check: func(t *testing.T, m *types.ModuleInfo) {
+				if len(m.Functions) != 1 {
+					t.Fatalf("expected 1 function, got %d", len(m.Functions))
+				}
+				fn := m.Functions[0]
+				if fn.TypeParams != "[T any, U comparable]" {
+					t.Errorf("expected type params '[T any, U comparable]', got %q", fn.TypeParams)
+				}
+				if fn.Params != "(xs []T, f func(T) U)" || fn.ReturnType != "[]U" {
+					t.Errorf("expected params and return type, got %q %q", fn.Params, fn.ReturnType)
+				}
+			},
+		},
+		{
+			name: "generic types",
+			code: `package main
+
+type Number interface {
+	~int | ~float64
+}
+
+type Stack[T Number] struct {
+	items []T
+}
+
+type Container[K comparable, V any] interface {
+	Get(key K) V
+}
+
+func (s *Stack[T]) Push(v T) {}
+
+func New[T Number]() Stack[T] {
+	return Stack[T]{}
+}
+`,
+			check: func(t *testing.T, m *types.ModuleInfo) {
+				if len(m.Structs) != 1 || m.Structs[0].TypeParams != "[T Number]" {
+					t.Errorf("expected Stack with type params '[T Number]', got %+v", m.Structs)
+				}
+				if len(m.Interfaces) != 2 {
+					t.Fatalf("expected 2 interfaces, got %d", len(m.Interfaces))
+				}
+				number := m.Interfaces[0]
+				if len(number.Methods) != 1 || number.Methods[0].Name != "~int | ~float64" {
+					t.Errorf("expected Number's type set as a member, got %+v", number.Methods)
+				}
+				if m.Interfaces[1].TypeParams != "[K comparable, V any]" {
+					t.Errorf("expected Container type params, got %q", m.Interfaces[1].TypeParams)
+				}
+				for _, cls := range m.Classes {
+					if cls.Name == "Stack" && cls.TypeParams != "[T Number]" {
+						t.Errorf("expected Stack class type params, got %q", cls.TypeParams)
+					}
+				}
+				for _, fn := range m.Functions {
+					if fn.Name == "New" && (fn.TypeParams != "[T Number]" || fn.ReturnType != "Stack[T]") {
+						t.Errorf("expected New[T Number]() Stack[T], got %+v", fn)
+					}
+					if fn.Name == "Push" && fn.Params != "(v T)" {
+						t.Errorf("expected Push params '(v T)', got %q", fn.Params)
+					}
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,211 @@
+package extractor
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// GrammarNodes names the syntax tree nodes a GrammarExtractor turns into
+// units
+type GrammarNodes struct {
+	// Functions are the node types of function and method definitions
+	Functions []string
+	// Classes are the node types of class-like definitions; functions
+	// inside them become their methods
+	Classes []string
+}
+
+// GrammarExtractor extracts functions and classes from any tree-sitter
+// grammar, such as one loaded from a language pack at run time, by node
+// type alone. It reads names, parameters and preceding comments, but not
+// imports or calls.
+type GrammarExtractor struct {
+	lang       Language
+	grammar    *sitter.Language
+	extensions []string
+	nodes      GrammarNodes
+	parsers    sync.Pool
+}
+
+// NewGrammarExtractor returns an extractor for lang that parses files with
+// grammar
+func NewGrammarExtractor(lang Language, grammar *sitter.Language, extensions []string, nodes GrammarNodes) *GrammarExtractor {
+	e := &GrammarExtractor{lang: lang, grammar: grammar, extensions: extensions, nodes: nodes}
+	e.parsers.New = func() interface{} { return e.NewParser() }
+	return e
+}
+
+// NewParser returns a parser for the extractor's grammar
+func (e *GrammarExtractor) NewParser() *sitter.Parser {
+	parser := sitter.NewParser()
+	parser.SetLanguage(e.grammar)
+	return parser
+}
+
+// Language returns the language identifier of the grammar.
+func (e *GrammarExtractor) Language() Language {
+	return e.lang
+}
+
+// FileExtensions returns the file extensions of the grammar's language.
+func (e *GrammarExtractor) FileExtensions() []string {
+	return e.extensions
+}
+
+// Extract parses a file and returns its functions and classes.
+func (e *GrammarExtractor) Extract(filePath string) (*types.ModuleInfo, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
+	}
+	return e.ExtractFromBytes(content, filePath)
+}
+
+// ExtractFromBytes extracts functions and classes from source code bytes.
+func (e *GrammarExtractor) ExtractFromBytes(content []byte, filePath string) (*types.ModuleInfo, error) {
+	parser := e.parsers.Get().(*sitter.Parser)
+	defer e.parsers.Put(parser)
+
+	tree := parser.Parse(nil, content)
+	if tree == nil {
+		return nil, fmt.Errorf("parsing file %s failed", filePath)
+	}
+	defer tree.Close()
+
+	module := &types.ModuleInfo{
+		Path:      filePath,
+		Language:  string(e.lang),
+		CallGraph: types.CallGraph{Edges: []types.CallGraphEdge{}},
+	}
+	e.walk(tree.RootNode(), content, module, nil)
+	return module, nil
+}
+
+// walk collects the definitions under node. Functions found inside class
+// are added to its methods.
+func (e *GrammarExtractor) walk(node *sitter.Node, content []byte, module *types.ModuleInfo, class *types.Class) {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		switch {
+		case slices.Contains(e.nodes.Classes, child.Type()):
+			c := types.Class{
+				Name:       grammarNodeName(child, content),
+				Docstring:  grammarDocComment(child, content),
+				LineNumber: int(child.StartPoint().Row) + 1,
+			}
+			e.walk(child, content, module, &c)
+			module.Classes = append(module.Classes, c)
+		case slices.Contains(e.nodes.Functions, child.Type()):
+			fn := types.Function{
+				Name:       grammarNodeName(child, content),
+				Docstring:  grammarDocComment(child, content),
+				LineNumber: int(child.StartPoint().Row) + 1,
+			}
+			if params := grammarParams(child); params != nil {
+				fn.Params = "(" + strings.Trim(params.Content(content), "()") + ")"
+			}
+			if class != nil {
+				fn.IsMethod = true
+				class.Methods = append(class.Methods, fn)
+			} else {
+				module.Functions = append(module.Functions, fn)
+			}
+		default:
+			e.walk(child, content, module, class)
+		}
+	}
+}
+
+// grammarNodeName returns the name of a definition: its "name" field, or
+// else its first identifier
+func grammarNodeName(node *sitter.Node, content []byte) string {
+	if name := node.ChildByFieldName("name"); name != nil {
+		return name.Content(content)
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); strings.Contains(child.Type(), "identifier") || strings.HasSuffix(child.Type(), "name") {
+			return child.Content(content)
+		}
+	}
+	return "<anonymous>"
+}
+
+// grammarParams returns the parameter list of a function: its "parameters"
+// field, or else its first child named like one
+func grammarParams(node *sitter.Node) *sitter.Node {
+	if params := node.ChildByFieldName("parameters"); params != nil {
+		return params
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); strings.Contains(child.Type(), "parameter") {
+			return child
+		}
+	}
+	return nil
+}
+
+// grammarDocComment returns the comments directly above a definition
+func grammarDocComment(node *sitter.Node, content []byte) string {
+	var lines []string
+	line := node.StartPoint().Row
+	for prev := node.PrevNamedSibling(); prev != nil && strings.Contains(prev.Type(), "comment") && prev.EndPoint().Row+1 >= line; prev = prev.PrevNamedSibling() {
+		lines = append([]string{strings.TrimSpace(strings.TrimLeft(prev.Content(content), "/#-;*! "))}, lines...)
+		line = prev.StartPoint().Row
+	}
+	return strings.Join(lines, "\n")
+}
+
+// grammarPack is a grammar registered with RegisterGrammar
+type grammarPack struct {
+	lang       Language
+	grammar    *sitter.Language
+	extensions []string
+	nodes      GrammarNodes
+}
+
+var (
+	grammarsMu sync.Mutex
+	grammars   []grammarPack
+)
+
+// RegisterGrammar makes the shared registry, and every registry created
+// later, extract lang with a GrammarExtractor over grammar. A built-in
+// language keeps its own extractor unless it is a placeholder.
+func RegisterGrammar(lang Language, grammar *sitter.Language, extensions []string, nodes GrammarNodes) error {
+	if err := GetLanguageRegistry().RegisterGrammar(lang, grammar, extensions, nodes); err != nil {
+		return err
+	}
+	grammarsMu.Lock()
+	defer grammarsMu.Unlock()
+	grammars = append(grammars, grammarPack{lang, grammar, extensions, nodes})
+	return nil
+}
+
+// registerGrammars adds the grammars registered with RegisterGrammar to r
+func (r *LanguageRegistry) registerGrammars() {
+	grammarsMu.Lock()
+	defer grammarsMu.Unlock()
+	for _, g := range grammars {
+		r.RegisterGrammar(g.lang, g.grammar, g.extensions, g.nodes)
+	}
+}
+
+// RegisterGrammar registers lang with a GrammarExtractor over grammar in r
+// only. A built-in language keeps its own extractor unless it is a
+// placeholder.
+func (r *LanguageRegistry) RegisterGrammar(lang Language, grammar *sitter.Language, extensions []string, nodes GrammarNodes) error {
+	switch r.extractors[lang].(type) {
+	case nil, *notImplementedExtractor, *GrammarExtractor:
+	default:
+		return fmt.Errorf("language %s is built in", lang)
+	}
+	e := NewGrammarExtractor(lang, grammar, extensions, nodes)
+	r.RegisterLanguage(lang, extensions, func() Extractor { return e }, e.NewParser)
+	return nil
+}
@@ -0,0 +1,77 @@
+package extractor
+
+import (
+	"testing"
+
+	"github.com/smacker/go-tree-sitter/python"
+)
+
+func TestGrammarExtractor(t *testing.T) {
+	// Any grammar works; Python's stands in for one loaded at run time
+	e := NewGrammarExtractor("snake", python.GetLanguage(), []string{".snake"}, GrammarNodes{
+		Functions: []string{"function_definition"},
+		Classes:   []string{"class_definition"},
+	})
+	source := []byte(`# Adds two numbers
+def add(a, b):
+    return a + b
+
+class Greeter:
+    def greet(self, name):
+        print(name)
+`)
+
+	module, err := e.ExtractFromBytes(source, "m.snake")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if module.Language != "snake" {
+		t.Errorf("Language = %q", module.Language)
+	}
+	if len(module.Functions) != 1 {
+		t.Fatalf("expected 1 function, got %+v", module.Functions)
+	}
+	fn := module.Functions[0]
+	if fn.Name != "add" || fn.Params != "(a, b)" || fn.Docstring != "Adds two numbers" || fn.LineNumber != 2 {
+		t.Errorf("function = %+v", fn)
+	}
+	if len(module.Classes) != 1 || module.Classes[0].Name != "Greeter" || module.Classes[0].LineNumber != 5 {
+		t.Fatalf("classes = %+v", module.Classes)
+	}
+	methods := module.Classes[0].Methods
+	if len(methods) != 1 || methods[0].Name != "greet" || !methods[0].IsMethod {
+		t.Errorf("methods = %+v", methods)
+	}
+}
+
+func TestRegisterGrammar(t *testing.T) {
+	registry := NewLanguageRegistry()
+	nodes := GrammarNodes{Functions: []string{"function_definition"}}
+
+	if err := registry.RegisterGrammar(Python, python.GetLanguage(), []string{".py"}, nodes); err == nil {
+		t.Error("RegisterGrammar() replaced a built-in language")
+	}
+	if err := registry.RegisterGrammar(Swift, python.GetLanguage(), []string{".swift"}, nodes); err != nil {
+		t.Errorf("RegisterGrammar() over a placeholder: %v", err)
+	}
+	if err := registry.RegisterGrammar("snake", python.GetLanguage(), []string{".snake"}, nodes); err != nil {
+		t.Fatal(err)
+	}
+	ext, err := registry.GetExtractor("pkg/m.snake")
+	if err != nil || ext.Language() != "snake" {
+		t.Errorf("GetExtractor(m.snake) = %v, %v", ext, err)
+	}
+	if parser, err := registry.GetParser("pkg/m.snake"); err != nil || parser == nil {
+		t.Errorf("GetParser(m.snake) = %v, %v", parser, err)
+	}
+
+	// Grammars registered for the process reach registries created later
+	if err := RegisterGrammar("snake2", python.GetLanguage(), []string{".snake2"}, nodes); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range []*LanguageRegistry{GetLanguageRegistry(), NewLanguageRegistry()} {
+		if !r.IsSupported("m.snake2") {
+			t.Error("registry does not support a grammar registered with RegisterGrammar")
+		}
+	}
+}
@@ -30,7 +30,7 @@ type JavaExtractor struct {
 // NewJavaExtractor creates a new Java extractor with initialized parser.
 func NewJavaExtractor() Extractor {
 	return &JavaExtractor{
-		BaseExtractor: NewBaseExtractor(NewJavaParser(), Java),
+		BaseExtractor: NewBaseExtractor(&javaParserPool, Java),
 	}
 }
 
@@ -63,7 +63,7 @@ func (e *JavaExtractor) Extract(filePath string) (*types.ModuleInfo, error) {
 // ExtractFromBytes extracts module information from Java source code bytes.
 func (e *JavaExtractor) ExtractFromBytes(content []byte, filePath string) (*types.ModuleInfo, error) {
 	// Parse the full AST
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -530,12 +530,7 @@ func (e *JavaExtractor) nodeText(node *sitter.Node, content []byte) string {
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
 
 // ExtractFunctions extracts only function/method definitions from a Java file.
@@ -545,7 +540,7 @@ func (e *JavaExtractor) ExtractFunctions(filePath string) ([]types.Function, err
 		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
 	}
 
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -572,7 +567,7 @@ func (e *JavaExtractor) ExtractClasses(filePath string) ([]types.Class, error) {
 		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
 	}
 
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -589,7 +584,7 @@ func (e *JavaExtractor) GetPackageName(filePath string) (string, error) {
 		return "", fmt.Errorf("reading file %s: %w", filePath, err)
 	}
 
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return "", fmt.Errorf("parsing file %s failed", filePath)
 	}
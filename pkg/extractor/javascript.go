@@ -2,6 +2,7 @@
 package extractor
 
 import (
+	"fmt"
 	"os"
 	"sync"
 
@@ -24,12 +25,14 @@ var javascriptParserPool = sync.Pool{
 // Note: This extractor uses the TypeScript parser since JavaScript is a subset of TypeScript.
 type JavaScriptExtractor struct {
 	*BaseExtractor
+	importParser *TypeScriptImportParser
 }
 
 // NewJavaScriptExtractor creates a new JavaScript extractor with initialized parser.
 func NewJavaScriptExtractor() Extractor {
 	return &JavaScriptExtractor{
-		BaseExtractor: NewBaseExtractor(NewJavaScriptParser(), JavaScript),
+		BaseExtractor: NewBaseExtractor(&javascriptParserPool, JavaScript),
+		importParser:  NewTypeScriptImportParser(),
 	}
 }
 
@@ -49,11 +52,21 @@ func (e *JavaScriptExtractor) Extract(filePath string) (*types.ModuleInfo, error
 	if err != nil {
 		return nil, err
 	}
+	return e.ExtractFromBytes(ScriptSource(filePath, content), filePath)
+}
+
+// ExtractFromBytes extracts module information from JavaScript source code bytes.
+func (e *JavaScriptExtractor) ExtractFromBytes(content []byte, filePath string) (*types.ModuleInfo, error) {
+	// Parse imports and require() calls with the TypeScript import parser
+	imports, err := e.importParser.ParseImportsFromBytes(content, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("parsing imports: %w", err)
+	}
 
 	// Parse the full AST using TypeScript parser (JavaScript is a subset)
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
-		return nil, err
+		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
 	defer tree.Close()
 
@@ -67,7 +80,7 @@ func (e *JavaScriptExtractor) Extract(filePath string) (*types.ModuleInfo, error
 		Path:      filePath,
 		Functions: functions,
 		Classes:   classes,
-		Imports:   []types.Import{},
+		Imports:   imports,
 		CallGraph: types.CallGraph{
 			Edges: []types.CallGraphEdge{},
 		},
@@ -299,12 +312,7 @@ func (e *JavaScriptExtractor) nodeText(node *sitter.Node, content []byte) string
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
 
 func NewJavaScriptParser() *sitter.Parser {
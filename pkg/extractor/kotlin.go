@@ -4,6 +4,7 @@ package extractor
 import (
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 
 	"github.com/l3aro/go-context-query/pkg/types"
@@ -23,6 +24,7 @@ var kotlinParserPool = sync.Pool{
 // KotlinExtractor implements the Extractor interface for Kotlin files.
 // It uses tree-sitter to parse Kotlin source code and extract structured information
 // about classes, interfaces, functions, imports, data classes, and object declarations.
+// KDoc blocks (/** ... */) become docstrings and annotations become decorators.
 type KotlinExtractor struct {
 	*BaseExtractor
 }
@@ -30,7 +32,7 @@ type KotlinExtractor struct {
 // NewKotlinExtractor creates a new Kotlin extractor with initialized parser.
 func NewKotlinExtractor() Extractor {
 	return &KotlinExtractor{
-		BaseExtractor: NewBaseExtractor(NewKotlinParser(), Kotlin),
+		BaseExtractor: NewBaseExtractor(&kotlinParserPool, Kotlin),
 	}
 }
 
@@ -63,7 +65,7 @@ func (e *KotlinExtractor) Extract(filePath string) (*types.ModuleInfo, error) {
 // ExtractFromBytes extracts module information from Kotlin source code bytes.
 func (e *KotlinExtractor) ExtractFromBytes(content []byte, filePath string) (*types.ModuleInfo, error) {
 	// Parse the full AST
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -82,6 +84,7 @@ func (e *KotlinExtractor) ExtractFromBytes(content []byte, filePath string) (*ty
 	for _, obj := range objects {
 		classes = append(classes, types.Class{
 			Name:       obj.Name,
+			Bases:      obj.Bases,
 			Docstring:  obj.Docstring,
 			Methods:    obj.Methods,
 			LineNumber: obj.LineNumber,
@@ -184,8 +187,9 @@ func (e *KotlinExtractor) walkForClasses(node *sitter.Node, content []byte, clas
 		return
 	}
 
-	// Kotlin class types: class_declaration, data_class_declaration, etc.
-	if node.Type() == "class_declaration" || node.Type() == "data_class_declaration" {
+	// Data, sealed and enum classes are class_declaration nodes too;
+	// interfaces are extracted by walkForInterfaces
+	if node.Type() == "class_declaration" && !e.isInterfaceDeclaration(node) {
 		class := e.parseClassDeclaration(node, content)
 		if class != nil {
 			*classes = append(*classes, *class)
@@ -198,7 +202,7 @@ func (e *KotlinExtractor) walkForClasses(node *sitter.Node, content []byte, clas
 	}
 }
 
-// parseClassDeclaration extracts information from a class_declaration or data_class_declaration node.
+// parseClassDeclaration extracts information from a class_declaration node.
 func (e *KotlinExtractor) parseClassDeclaration(node *sitter.Node, content []byte) *types.Class {
 	if node == nil {
 		return nil
@@ -207,8 +211,8 @@ func (e *KotlinExtractor) parseClassDeclaration(node *sitter.Node, content []byt
 	lineNumber := int(node.StartPoint().Row) + 1
 	var name string
 	var bases []string
-	var docstring string
-	isDataClass := node.Type() == "data_class_declaration"
+	docstring := e.kdoc(node, content)
+	isDataClass := e.hasModifier(node, "data", content)
 
 	for i := 0; i < int(node.ChildCount()); i++ {
 		child := node.Child(i)
@@ -231,8 +235,6 @@ func (e *KotlinExtractor) parseClassDeclaration(node *sitter.Node, content []byt
 			if supertype != "" {
 				bases = append(bases, supertype)
 			}
-		case "class_body":
-			docstring = e.extractClassDocstring(child, content)
 		}
 	}
 
@@ -242,7 +244,7 @@ func (e *KotlinExtractor) parseClassDeclaration(node *sitter.Node, content []byt
 
 	methods := e.extractClassMethods(node, content)
 
-	// Add [data] prefix for data classes in docstring
+	// Mark data classes without KDoc in the docstring
 	if isDataClass && docstring == "" {
 		docstring = "data class"
 	}
@@ -252,6 +254,7 @@ func (e *KotlinExtractor) parseClassDeclaration(node *sitter.Node, content []byt
 		Bases:      bases,
 		Docstring:  docstring,
 		Methods:    methods,
+		Decorators: e.annotations(node, content),
 		LineNumber: lineNumber,
 	}
 }
@@ -297,27 +300,9 @@ func (e *KotlinExtractor) extractSupertype(node *sitter.Node, content []byte) st
 		switch child.Type() {
 		case "type_identifier", "simple_identifier", "user_type":
 			return e.nodeText(child, content)
-		}
-	}
-
-	return ""
-}
-
-// extractClassDocstring extracts documentation from a class body.
-func (e *KotlinExtractor) extractClassDocstring(node *sitter.Node, content []byte) string {
-	if node == nil {
-		return ""
-	}
-
-	// Look for line comments at the start of the class body
-	for i := 0; i < int(node.ChildCount()); i++ {
-		child := node.Child(i)
-		if child == nil {
-			continue
-		}
-
-		if child.Type() == "line_comment" || child.Type() == "multiline_comment" {
-			return e.nodeText(child, content)
+		case "constructor_invocation", "explicit_delegation":
+			// Base(args) or Iface by delegate: the type comes first
+			return e.extractSupertype(child, content)
 		}
 	}
 
@@ -362,6 +347,10 @@ func (e *KotlinExtractor) extractClassMethods(classNode *sitter.Node, content []
 				methods = append(methods, *method)
 			}
 		}
+		// Companion object members are called on the class
+		if child.Type() == "companion_object" {
+			methods = append(methods, e.extractObjectMethods(child, content)...)
+		}
 	}
 
 	return methods
@@ -444,6 +433,7 @@ func (e *KotlinExtractor) parseInterfaceDeclaration(node *sitter.Node, content [
 	return &types.Interface{
 		Name:       name,
 		Bases:      bases,
+		Docstring:  e.kdoc(node, content),
 		Methods:    methods,
 		LineNumber: lineNumber,
 	}
@@ -487,6 +477,7 @@ func (e *KotlinExtractor) extractInterfaceMethods(interfaceNode *sitter.Node, co
 // ObjectInfo represents a Kotlin object declaration.
 type ObjectInfo struct {
 	Name       string
+	Bases      []string
 	Docstring  string
 	Methods    []types.Method
 	LineNumber int
@@ -526,6 +517,7 @@ func (e *KotlinExtractor) parseObjectDeclaration(node *sitter.Node, content []by
 
 	lineNumber := int(node.StartPoint().Row) + 1
 	var name string
+	var bases []string
 
 	for i := 0; i < int(node.ChildCount()); i++ {
 		child := node.Child(i)
@@ -538,6 +530,10 @@ func (e *KotlinExtractor) parseObjectDeclaration(node *sitter.Node, content []by
 			if name == "" {
 				name = e.nodeText(child, content)
 			}
+		case "delegation_specifier":
+			if supertype := e.extractSupertype(child, content); supertype != "" {
+				bases = append(bases, supertype)
+			}
 		}
 	}
 
@@ -549,6 +545,8 @@ func (e *KotlinExtractor) parseObjectDeclaration(node *sitter.Node, content []by
 
 	return &ObjectInfo{
 		Name:       name,
+		Bases:      bases,
+		Docstring:  e.kdoc(node, content),
 		Methods:    methods,
 		LineNumber: lineNumber,
 	}
@@ -655,7 +653,7 @@ func (e *KotlinExtractor) parseTopLevelFunctionDeclaration(node *sitter.Node, co
 			}
 		case "function_value_parameters":
 			params = e.nodeText(child, content)
-		case "type_reference", "user_type", "type_identifier":
+		case "type_reference", "user_type", "type_identifier", "nullable_type", "function_type":
 			if returnType == "" {
 				returnType = e.nodeText(child, content)
 			}
@@ -670,8 +668,11 @@ func (e *KotlinExtractor) parseTopLevelFunctionDeclaration(node *sitter.Node, co
 		Name:       name,
 		Params:     params,
 		ReturnType: returnType,
+		Docstring:  e.kdoc(node, content),
 		LineNumber: lineNumber,
 		IsMethod:   false,
+		IsAsync:    e.hasModifier(node, "suspend", content),
+		Decorators: e.annotations(node, content),
 	}
 }
 
@@ -699,7 +700,7 @@ func (e *KotlinExtractor) parseFunctionDeclaration(node *sitter.Node, content []
 			}
 		case "function_value_parameters":
 			params = e.nodeText(child, content)
-		case "type_reference", "user_type", "type_identifier":
+		case "type_reference", "user_type", "type_identifier", "nullable_type", "function_type":
 			if returnType == "" {
 				returnType = e.nodeText(child, content)
 			}
@@ -714,8 +715,11 @@ func (e *KotlinExtractor) parseFunctionDeclaration(node *sitter.Node, content []
 		Name:       name,
 		Params:     params,
 		ReturnType: returnType,
+		Docstring:  e.kdoc(node, content),
 		LineNumber: lineNumber,
 		IsMethod:   true,
+		IsAsync:    e.hasModifier(node, "suspend", content),
+		Decorators: e.annotations(node, content),
 	}
 }
 
@@ -740,6 +744,19 @@ func (e *KotlinExtractor) parsePropertyDeclaration(node *sitter.Node, content []
 			if name == "" {
 				name = e.nodeText(child, content)
 			}
+		case "variable_declaration":
+			// val name: Type
+			if name == "" {
+				name = e.nodeText(e.findChild(child, "simple_identifier"), content)
+			}
+			if returnType == "" {
+				for _, t := range []string{"user_type", "nullable_type", "function_type"} {
+					if typeNode := e.findChild(child, t); typeNode != nil {
+						returnType = e.nodeText(typeNode, content)
+						break
+					}
+				}
+			}
 		case "type_reference", "user_type", "type_identifier":
 			if returnType == "" {
 				returnType = e.nodeText(child, content)
@@ -754,9 +771,82 @@ func (e *KotlinExtractor) parsePropertyDeclaration(node *sitter.Node, content []
 	return &types.Method{
 		Name:       name,
 		ReturnType: returnType,
+		Docstring:  e.kdoc(node, content),
 		LineNumber: lineNumber,
 		IsMethod:   true,
+		Decorators: e.annotations(node, content),
+	}
+}
+
+// kdoc returns the KDoc block (/** ... */) directly above a declaration, or
+// "". The grammar attaches a comment that follows the package or import
+// headers to the last header, so the block is looked up there too.
+func (e *KotlinExtractor) kdoc(node *sitter.Node, content []byte) string {
+	prev := node.PrevNamedSibling()
+	for prev != nil {
+		switch prev.Type() {
+		case "package_header", "import_list", "import_header":
+			prev = prev.NamedChild(int(prev.NamedChildCount()) - 1)
+			continue
+		}
+		break
+	}
+	if prev == nil || prev.Type() != "multiline_comment" {
+		return ""
+	}
+	text := e.nodeText(prev, content)
+	if !strings.HasPrefix(text, "/**") {
+		return ""
+	}
+	return text
+}
+
+// hasModifier reports whether a declaration has a modifier keyword such as
+// data or suspend
+func (e *KotlinExtractor) hasModifier(node *sitter.Node, keyword string, content []byte) bool {
+	modifiers := e.findChild(node, "modifiers")
+	if modifiers == nil {
+		return false
+	}
+	for i := 0; i < int(modifiers.NamedChildCount()); i++ {
+		if e.nodeText(modifiers.NamedChild(i), content) == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+// annotations returns the names of a declaration's annotations, e.g.
+// "Composable" for @Composable
+func (e *KotlinExtractor) annotations(node *sitter.Node, content []byte) []string {
+	modifiers := e.findChild(node, "modifiers")
+	if modifiers == nil {
+		return nil
+	}
+	var names []string
+	for i := 0; i < int(modifiers.NamedChildCount()); i++ {
+		child := modifiers.NamedChild(i)
+		if child.Type() != "annotation" {
+			continue
+		}
+		for j := 0; j < int(child.NamedChildCount()); j++ {
+			switch typeNode := child.NamedChild(j); typeNode.Type() {
+			case "user_type", "constructor_invocation":
+				names = append(names, e.nodeText(e.findChild(typeNode, "type_identifier"), content))
+			}
+		}
+	}
+	return names
+}
+
+// findChild returns the first direct child of node with the given type
+func (e *KotlinExtractor) findChild(node *sitter.Node, childType string) *sitter.Node {
+	for i := 0; i < int(node.ChildCount()); i++ {
+		if child := node.Child(i); child != nil && child.Type() == childType {
+			return child
+		}
 	}
+	return nil
 }
 
 // nodeText extracts the text content of a node from the source.
@@ -764,12 +854,7 @@ func (e *KotlinExtractor) nodeText(node *sitter.Node, content []byte) string {
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
 
 // ExtractFunctions extracts only function definitions from a Kotlin file.
@@ -779,7 +864,7 @@ func (e *KotlinExtractor) ExtractFunctions(filePath string) ([]types.Function, e
 		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
 	}
 
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -798,7 +883,7 @@ func (e *KotlinExtractor) ExtractClasses(filePath string) ([]types.Class, error)
 		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
 	}
 
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
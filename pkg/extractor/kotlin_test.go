@@ -3,6 +3,8 @@ package extractor
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/l3aro/go-context-query/pkg/types"
@@ -309,3 +311,90 @@ func TestKotlinScriptFile(t *testing.T) {
 		t.Errorf("expected function name main, got %s", m.Functions[0].Name)
 	}
 }
+
+func TestKotlinKDocAndDeclarations(t *testing.T) {
+	code := `package com.example
+
+/**
+ * A user account.
+ */
+data class User(val name: String)
+
+/** Loads screens. */
+@Composable
+sealed class Screen(id: Int) : Base(id), Loader<User> {
+    companion object {
+        fun create(): Screen? = null
+    }
+
+    /** Current title. */
+    val title: String = ""
+
+    /** Loads the users. */
+    suspend fun load(): List<User>? = null
+}
+
+/** The registry. */
+object Registry : Base(0)
+
+/** Shapes with an area. */
+interface Shape {
+    fun area(): Double
+}
+
+/** Doubles x. */
+fun double(x: Int): Int = x * 2
+`
+	extractor := NewKotlinExtractor().(*KotlinExtractor)
+	m, err := extractor.ExtractFromBytes([]byte(code), "Screen.kt")
+	if err != nil {
+		t.Fatalf("ExtractFromBytes failed: %v", err)
+	}
+
+	classes := make(map[string]types.Class)
+	for _, c := range m.Classes {
+		classes[c.Name] = c
+	}
+	if len(classes) != 3 {
+		t.Fatalf("expected User, Screen and Registry, got %+v", m.Classes)
+	}
+
+	if doc := classes["User"].Docstring; !strings.Contains(doc, "A user account.") {
+		t.Errorf("User docstring = %q, want the KDoc after the package header", doc)
+	}
+
+	screen := classes["Screen"]
+	if screen.Docstring != "/** Loads screens. */" {
+		t.Errorf("Screen docstring = %q", screen.Docstring)
+	}
+	if !slices.Equal(screen.Bases, []string{"Base", "Loader<User>"}) {
+		t.Errorf("Screen bases = %v", screen.Bases)
+	}
+	if !slices.Equal(screen.Decorators, []string{"Composable"}) {
+		t.Errorf("Screen decorators = %v", screen.Decorators)
+	}
+	methods := make(map[string]types.Method)
+	for _, method := range screen.Methods {
+		methods[method.Name] = method
+	}
+	if _, ok := methods["create"]; !ok {
+		t.Error("expected the companion object's create as a Screen method")
+	}
+	if title := methods["title"]; title.ReturnType != "String" || title.Docstring != "/** Current title. */" {
+		t.Errorf("title property = %+v", title)
+	}
+	if load := methods["load"]; !load.IsAsync || load.ReturnType != "List<User>?" || load.Docstring != "/** Loads the users. */" {
+		t.Errorf("load method = %+v", load)
+	}
+
+	if registry := classes["Registry"]; registry.Docstring != "/** The registry. */" || !slices.Equal(registry.Bases, []string{"Base"}) {
+		t.Errorf("Registry object = %+v", registry)
+	}
+
+	if len(m.Interfaces) != 1 || m.Interfaces[0].Docstring != "/** Shapes with an area. */" {
+		t.Errorf("interfaces = %+v", m.Interfaces)
+	}
+	if len(m.Functions) != 1 || m.Functions[0].Docstring != "/** Doubles x. */" {
+		t.Errorf("functions = %+v", m.Functions)
+	}
+}
@@ -4,6 +4,7 @@ package extractor
 import (
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 
 	"github.com/l3aro/go-context-query/pkg/types"
@@ -28,7 +29,7 @@ type PHPExtractor struct {
 // NewPHPExtractor creates a new PHP extractor with initialized parser.
 func NewPHPExtractor() Extractor {
 	return &PHPExtractor{
-		BaseExtractor: NewBaseExtractor(NewPHPParser(), PHP),
+		BaseExtractor: NewBaseExtractor(&phpParserPool, PHP),
 	}
 }
 
@@ -59,246 +60,322 @@ func (e *PHPExtractor) Extract(filePath string) (*types.ModuleInfo, error) {
 }
 
 // ExtractFromBytes extracts module information from PHP source code bytes.
+// Classes, interfaces and traits are qualified with the namespace they are
+// declared in, and PHPDoc blocks (/** ... */) become their docstrings.
 func (e *PHPExtractor) ExtractFromBytes(content []byte, filePath string) (*types.ModuleInfo, error) {
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
 	defer tree.Close()
 
+	info := &types.ModuleInfo{
+		Path:      filePath,
+		Language:  string(e.Language()),
+		CallGraph: types.CallGraph{Edges: []types.CallGraphEdge{}},
+	}
 	root := tree.RootNode()
-
-	classes := e.extractClasses(root, content)
-	interfaces := e.extractInterfaces(root, content)
-	traits := e.extractTraits(root, content)
-	functions := e.extractFunctions(root, content)
-	imports := e.extractUseStatements(root, content)
-
-	return &types.ModuleInfo{
-		Path:       filePath,
-		Functions:  functions,
-		Classes:    classes,
-		Imports:    imports,
-		Traits:     traits,
-		Interfaces: interfaces,
-		Language:   string(e.Language()),
-		CallGraph:  types.CallGraph{Edges: []types.CallGraphEdge{}},
-	}, nil
-}
-
-// extractUseStatements extracts use statements.
-func (e *PHPExtractor) extractUseStatements(node *sitter.Node, content []byte) []types.Import {
-	var imports []types.Import
-	e.walkForUse(node, content, &imports)
-	return imports
+	info.Docstring = e.fileDoc(root, content)
+	e.walkDeclarations(root, content, "", info)
+	return info, nil
 }
 
-func (e *PHPExtractor) walkForUse(node *sitter.Node, content []byte, imports *[]types.Import) {
-	if node == nil {
-		return
-	}
-	if node.Type() == "use_declaration" || node.Type() == "use_statement" {
-		lineNumber := int(node.StartPoint().Row) + 1
-		module := e.getNodeText(node, content)
-		*imports = append(*imports, types.Import{
-			Module:     module,
-			LineNumber: lineNumber,
-		})
-		return
-	}
-	for i := 0; i < int(node.ChildCount()); i++ {
-		e.walkForUse(node.Child(i), content, imports)
+// fileDoc returns the PHPDoc block opening a file, unless it documents the
+// declaration right below it
+func (e *PHPExtractor) fileDoc(root *sitter.Node, content []byte) string {
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		child := root.NamedChild(i)
+		if child == nil || child.Type() == "php_tag" {
+			continue
+		}
+		text := e.getNodeText(child, content)
+		if child.Type() != "comment" || !strings.HasPrefix(text, "/**") {
+			return ""
+		}
+		switch next := child.NextNamedSibling(); {
+		case next == nil:
+			return text
+		case strings.HasSuffix(next.Type(), "_declaration") || next.Type() == "function_definition":
+			return ""
+		default:
+			return text
+		}
 	}
+	return ""
 }
 
-// extractClasses extracts class definitions.
-func (e *PHPExtractor) extractClasses(node *sitter.Node, content []byte) []types.Class {
-	var classes []types.Class
-	e.walkForClasses(node, content, &classes)
-	return classes
-}
-
-func (e *PHPExtractor) walkForClasses(node *sitter.Node, content []byte, classes *[]types.Class) {
-	if node == nil {
-		return
-	}
-	if node.Type() == "class_declaration" {
-		lineNumber := int(node.StartPoint().Row) + 1
-		var name string
-		var methods []types.Method
-
-		for i := 0; i < int(node.ChildCount()); i++ {
-			child := node.Child(i)
-			if child != nil && child.Type() == "name" {
-				name = e.getNodeText(child, content)
+// walkDeclarations collects the declarations among node's children into
+// info. namespace is the namespace in effect, set by a preceding
+// "namespace X;" statement or an enclosing "namespace X { }" block.
+func (e *PHPExtractor) walkDeclarations(node *sitter.Node, content []byte, namespace string, info *types.ModuleInfo) {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child == nil {
+			continue
+		}
+		switch child.Type() {
+		case "namespace_definition":
+			name := e.getNodeText(child.ChildByFieldName("name"), content)
+			if body := child.ChildByFieldName("body"); body != nil {
+				e.walkDeclarations(body, content, name, info)
+			} else {
+				namespace = name
 			}
-			if child != nil && child.Type() == "declaration_list" {
-				methods = e.extractMethods(child, content)
+		case "namespace_use_declaration":
+			info.Imports = append(info.Imports, e.parseUseDeclaration(child, content)...)
+		case "class_declaration":
+			if class := e.parseClass(child, content, namespace); class != nil {
+				info.Classes = append(info.Classes, *class)
 			}
+		case "interface_declaration":
+			if iface := e.parseInterface(child, content); iface != nil {
+				info.Interfaces = append(info.Interfaces, *iface)
+			}
+		case "trait_declaration":
+			if trait := e.parseTrait(child, content); trait != nil {
+				info.Traits = append(info.Traits, *trait)
+			}
+		case "enum_declaration":
+			if enum := e.parseEnum(child, content); enum != nil {
+				info.Enums = append(info.Enums, *enum)
+			}
+		case "function_definition":
+			if fn := e.parseFunction(child, content, false); fn != nil {
+				info.Functions = append(info.Functions, *fn)
+			}
+		default:
+			// Declarations nested in blocks, such as functions defined
+			// under if (!function_exists(...))
+			e.walkDeclarations(child, content, namespace, info)
 		}
-
-		if name != "" {
-			*classes = append(*classes, types.Class{
-				Name:       name,
-				Methods:    methods,
-				LineNumber: lineNumber,
-			})
-		}
-		return
-	}
-	for i := 0; i < int(node.ChildCount()); i++ {
-		e.walkForClasses(node.Child(i), content, classes)
 	}
 }
 
-// extractInterfaces extracts interface definitions.
-func (e *PHPExtractor) extractInterfaces(node *sitter.Node, content []byte) []types.Interface {
-	var interfaces []types.Interface
-	e.walkForInterfaces(node, content, &interfaces)
-	return interfaces
-}
-
-func (e *PHPExtractor) walkForInterfaces(node *sitter.Node, content []byte, interfaces *[]types.Interface) {
-	if node == nil {
-		return
-	}
-	if node.Type() == "interface_declaration" {
-		lineNumber := int(node.StartPoint().Row) + 1
-		var name string
-
-		for i := 0; i < int(node.ChildCount()); i++ {
-			child := node.Child(i)
-			if child != nil && child.Type() == "name" {
-				name = e.getNodeText(child, content)
+// parseUseDeclaration returns an import for each name a use statement
+// brings in: "use App\Models\User as U;" imports module App\Models\User
+// under the name U. Grouped uses (use App\{A, B};) are expanded.
+func (e *PHPExtractor) parseUseDeclaration(node *sitter.Node, content []byte) []types.Import {
+	lineNumber := int(node.StartPoint().Row) + 1
+	var imports []types.Import
+	add := func(module string, clause *sitter.Node) {
+		module = strings.TrimPrefix(module, `\`)
+		if module == "" {
+			return
+		}
+		name := module[strings.LastIndex(module, `\`)+1:]
+		for i := 0; i < int(clause.NamedChildCount()); i++ {
+			if alias := clause.NamedChild(i); alias != nil && alias.Type() == "namespace_aliasing_clause" {
+				name = e.getNodeText(alias.NamedChild(0), content)
 			}
 		}
+		imports = append(imports, types.Import{Module: module, Names: []string{name}, LineNumber: lineNumber})
+	}
 
-		if name != "" {
-			*interfaces = append(*interfaces, types.Interface{
-				Name:       name,
-				LineNumber: lineNumber,
-			})
+	var prefix string
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child == nil {
+			continue
+		}
+		switch child.Type() {
+		case "namespace_name":
+			prefix = e.getNodeText(child, content)
+		case "namespace_use_clause":
+			add(e.getNodeText(child.NamedChild(0), content), child)
+		case "namespace_use_group":
+			for j := 0; j < int(child.NamedChildCount()); j++ {
+				clause := child.NamedChild(j)
+				if clause == nil || clause.NamedChildCount() == 0 {
+					continue
+				}
+				add(prefix+`\`+e.getNodeText(clause.NamedChild(0), content), clause)
+			}
 		}
-		return
-	}
-	for i := 0; i < int(node.ChildCount()); i++ {
-		e.walkForInterfaces(node.Child(i), content, interfaces)
 	}
+	return imports
 }
 
-// extractTraits extracts trait definitions.
-func (e *PHPExtractor) extractTraits(node *sitter.Node, content []byte) []types.Trait {
-	var traits []types.Trait
-	e.walkForTraits(node, content, &traits)
-	return traits
-}
-
-func (e *PHPExtractor) walkForTraits(node *sitter.Node, content []byte, traits *[]types.Trait) {
-	if node == nil {
-		return
+// parseClass extracts a class with its bases: the parent class, the
+// implemented interfaces and the traits it uses, in that order
+func (e *PHPExtractor) parseClass(node *sitter.Node, content []byte, namespace string) *types.Class {
+	name := e.getNodeText(node.ChildByFieldName("name"), content)
+	if name == "" {
+		return nil
 	}
-	if node.Type() == "trait_declaration" {
-		lineNumber := int(node.StartPoint().Row) + 1
-		var name string
-
-		for i := 0; i < int(node.ChildCount()); i++ {
-			child := node.Child(i)
-			if child != nil && child.Type() == "name" {
-				name = e.getNodeText(child, content)
+	class := &types.Class{
+		Name:          name,
+		QualifiedName: qualifyPHPName(namespace, name),
+		Docstring:     e.phpDoc(node, content),
+		Decorators:    e.attributes(node, content),
+		LineNumber:    int(node.StartPoint().Row) + 1,
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child != nil && (child.Type() == "base_clause" || child.Type() == "class_interface_clause") {
+			class.Bases = append(class.Bases, e.typeNames(child, content)...)
+		}
+	}
+	if body := node.ChildByFieldName("body"); body != nil {
+		for i := 0; i < int(body.NamedChildCount()); i++ {
+			if child := body.NamedChild(i); child != nil && child.Type() == "use_declaration" {
+				class.Bases = append(class.Bases, e.typeNames(child, content)...)
 			}
 		}
+		class.Methods = e.extractMethods(body, content)
+	}
+	return class
+}
 
-		if name != "" {
-			*traits = append(*traits, types.Trait{
-				Name:       name,
-				LineNumber: lineNumber,
-			})
+// parseInterface extracts an interface with the interfaces it extends
+func (e *PHPExtractor) parseInterface(node *sitter.Node, content []byte) *types.Interface {
+	name := e.getNodeText(node.ChildByFieldName("name"), content)
+	if name == "" {
+		return nil
+	}
+	iface := &types.Interface{
+		Name:       name,
+		Docstring:  e.phpDoc(node, content),
+		LineNumber: int(node.StartPoint().Row) + 1,
+	}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child != nil && child.Type() == "base_clause" {
+			iface.Bases = append(iface.Bases, e.typeNames(child, content)...)
 		}
-		return
 	}
-	for i := 0; i < int(node.ChildCount()); i++ {
-		e.walkForTraits(node.Child(i), content, traits)
+	if body := node.ChildByFieldName("body"); body != nil {
+		iface.Methods = e.extractMethods(body, content)
 	}
+	return iface
 }
 
-// extractFunctions extracts function definitions.
-func (e *PHPExtractor) extractFunctions(node *sitter.Node, content []byte) []types.Function {
-	var functions []types.Function
-	e.walkForFunctions(node, content, &functions)
-	return functions
+// parseTrait extracts a trait with its methods
+func (e *PHPExtractor) parseTrait(node *sitter.Node, content []byte) *types.Trait {
+	name := e.getNodeText(node.ChildByFieldName("name"), content)
+	if name == "" {
+		return nil
+	}
+	trait := &types.Trait{
+		Name:       name,
+		Docstring:  e.phpDoc(node, content),
+		LineNumber: int(node.StartPoint().Row) + 1,
+	}
+	if body := node.ChildByFieldName("body"); body != nil {
+		trait.Methods = e.extractMethods(body, content)
+	}
+	return trait
 }
 
-func (e *PHPExtractor) walkForFunctions(node *sitter.Node, content []byte, functions *[]types.Function) {
-	if node == nil {
-		return
+// parseEnum extracts an enum with its cases
+func (e *PHPExtractor) parseEnum(node *sitter.Node, content []byte) *types.Enum {
+	name := e.getNodeText(node.ChildByFieldName("name"), content)
+	if name == "" {
+		return nil
 	}
-	if node.Type() == "function_definition" {
-		lineNumber := int(node.StartPoint().Row) + 1
-		var name, params string
-
-		for i := 0; i < int(node.ChildCount()); i++ {
-			child := node.Child(i)
-			if child != nil && child.Type() == "name" {
-				name = e.getNodeText(child, content)
-			}
-			if child != nil && child.Type() == "formal_parameters" {
-				params = e.getNodeText(child, content)
+	enum := &types.Enum{
+		Name:       name,
+		Docstring:  e.phpDoc(node, content),
+		LineNumber: int(node.StartPoint().Row) + 1,
+	}
+	if body := node.ChildByFieldName("body"); body != nil {
+		for i := 0; i < int(body.NamedChildCount()); i++ {
+			if child := body.NamedChild(i); child != nil && child.Type() == "enum_case" {
+				enum.Variants = append(enum.Variants, e.getNodeText(child.ChildByFieldName("name"), content))
 			}
 		}
+	}
+	return enum
+}
 
-		if name != "" {
-			*functions = append(*functions, types.Function{
-				Name:       name,
-				Params:     params,
-				LineNumber: lineNumber,
-			})
-		}
-		return
+// parseFunction extracts a function or method with its parameters, return
+// type, PHPDoc and attributes
+func (e *PHPExtractor) parseFunction(node *sitter.Node, content []byte, isMethod bool) *types.Function {
+	name := e.getNodeText(node.ChildByFieldName("name"), content)
+	if name == "" {
+		return nil
 	}
-	for i := 0; i < int(node.ChildCount()); i++ {
-		e.walkForFunctions(node.Child(i), content, functions)
+	return &types.Function{
+		Name:       name,
+		Params:     e.getNodeText(node.ChildByFieldName("parameters"), content),
+		ReturnType: e.getNodeText(node.ChildByFieldName("return_type"), content),
+		Docstring:  e.phpDoc(node, content),
+		Decorators: e.attributes(node, content),
+		LineNumber: int(node.StartPoint().Row) + 1,
+		IsMethod:   isMethod,
 	}
 }
 
-// extractMethods extracts method definitions from a class body.
+// extractMethods extracts method definitions from a class, interface or
+// trait body.
 func (e *PHPExtractor) extractMethods(node *sitter.Node, content []byte) []types.Method {
 	var methods []types.Method
-	for i := 0; i < int(node.ChildCount()); i++ {
-		child := node.Child(i)
-		if child == nil {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child == nil || child.Type() != "method_declaration" {
 			continue
 		}
-		if child.Type() == "method_declaration" {
-			lineNumber := int(child.StartPoint().Row) + 1
-			var name, params, returnType string
+		if method := e.parseFunction(child, content, true); method != nil {
+			methods = append(methods, *method)
+		}
+	}
+	return methods
+}
 
-			for j := 0; j < int(child.ChildCount()); j++ {
-				mchild := child.Child(j)
-				if mchild == nil {
-					continue
-				}
-				switch mchild.Type() {
-				case "name":
-					name = e.getNodeText(mchild, content)
-				case "formal_parameters":
-					params = e.getNodeText(mchild, content)
-				case "primitive_type":
-					returnType = e.getNodeText(mchild, content)
-				}
-			}
+// phpDoc returns the PHPDoc block directly above a declaration, or "".
+// Attributes such as #[Route] belong to the declaration node, so the block
+// may sit above them.
+func (e *PHPExtractor) phpDoc(node *sitter.Node, content []byte) string {
+	prev := node.PrevNamedSibling()
+	if prev == nil || prev.Type() != "comment" {
+		return ""
+	}
+	text := e.getNodeText(prev, content)
+	if !strings.HasPrefix(text, "/**") {
+		return ""
+	}
+	return text
+}
 
-			if name != "" {
-				methods = append(methods, types.Method{
-					Name:       name,
-					Params:     params,
-					ReturnType: returnType,
-					LineNumber: lineNumber,
-					IsMethod:   true,
-				})
-			}
+// attributes returns the names of a declaration's attributes, e.g. "Route"
+// for #[Route('/invoices')]
+func (e *PHPExtractor) attributes(node *sitter.Node, content []byte) []string {
+	list := node.ChildByFieldName("attributes")
+	if list == nil {
+		return nil
+	}
+	var names []string
+	var walk func(n *sitter.Node)
+	walk = func(n *sitter.Node) {
+		if n.Type() == "attribute" {
+			names = append(names, e.getNodeText(n.NamedChild(0), content))
+			return
+		}
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			walk(n.NamedChild(i))
 		}
 	}
-	return methods
+	walk(list)
+	return names
+}
+
+// typeNames returns the type names listed in an extends, implements or
+// trait use clause, without a leading backslash
+func (e *PHPExtractor) typeNames(node *sitter.Node, content []byte) []string {
+	var names []string
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child != nil && (child.Type() == "name" || child.Type() == "qualified_name") {
+			names = append(names, strings.TrimPrefix(e.getNodeText(child, content), `\`))
+		}
+	}
+	return names
+}
+
+// qualifyPHPName prefixes name with namespace, if any
+func qualifyPHPName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + `\` + name
 }
 
 // getNodeText extracts text from a node.
@@ -306,10 +383,5 @@ func (e *PHPExtractor) getNodeText(node *sitter.Node, content []byte) string {
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
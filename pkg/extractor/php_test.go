@@ -0,0 +1,131 @@
+package extractor
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+const phpSource = `<?php
+/**
+ * Billing helpers.
+ */
+namespace App\Billing;
+
+use App\Models\User;
+use App\Contracts\{Payable, Refundable as Refund};
+use function App\Support\format_money;
+
+/**
+ * An invoice.
+ */
+#[Entity]
+final class Invoice extends Document implements Payable, \Countable
+{
+    use HasTotals;
+
+    /** Total in cents. */
+    public function total(int $tax = 0): ?Money
+    {
+        return $this->sum($this->lines) + $tax;
+    }
+}
+
+interface Payable extends Base
+{
+    public function pay(): void;
+}
+
+/** Sums invoice lines. */
+trait HasTotals
+{
+    public function sum(array $lines): int
+    {
+        return 0;
+    }
+}
+
+enum Status: string
+{
+    case Paid = 'paid';
+    case Open = 'open';
+}
+
+/** Formats an invoice. */
+function format_invoice(Invoice $invoice): string
+{
+    return format_money($invoice->total());
+}
+
+namespace Legacy {
+    if (!function_exists('helper')) {
+        function helper() {}
+    }
+}
+`
+
+func TestPHPExtractor(t *testing.T) {
+	m, err := NewPHPExtractor().(*PHPExtractor).ExtractFromBytes([]byte(phpSource), "Invoice.php")
+	if err != nil {
+		t.Fatalf("ExtractFromBytes failed: %v", err)
+	}
+
+	if m.Docstring == "" {
+		t.Error("expected the file PHPDoc as module docstring")
+	}
+
+	wantImports := []types.Import{
+		{Module: `App\Models\User`, Names: []string{"User"}},
+		{Module: `App\Contracts\Payable`, Names: []string{"Payable"}},
+		{Module: `App\Contracts\Refundable`, Names: []string{"Refund"}},
+		{Module: `App\Support\format_money`, Names: []string{"format_money"}},
+	}
+	if len(m.Imports) != len(wantImports) {
+		t.Fatalf("imports = %+v", m.Imports)
+	}
+	for i, want := range wantImports {
+		if got := m.Imports[i]; got.Module != want.Module || !slices.Equal(got.Names, want.Names) {
+			t.Errorf("import %d = %+v, want %+v", i, got, want)
+		}
+	}
+
+	if len(m.Classes) != 1 {
+		t.Fatalf("classes = %+v", m.Classes)
+	}
+	class := m.Classes[0]
+	if class.QualifiedName != `App\Billing\Invoice` || class.Docstring == "" || !slices.Equal(class.Decorators, []string{"Entity"}) {
+		t.Errorf("class = %+v", class)
+	}
+	if want := []string{"Document", "Payable", "Countable", "HasTotals"}; !slices.Equal(class.Bases, want) {
+		t.Errorf("bases = %v, want %v", class.Bases, want)
+	}
+	if len(class.Methods) != 1 {
+		t.Fatalf("methods = %+v", class.Methods)
+	}
+	total := class.Methods[0]
+	if total.Name != "total" || total.Params != "(int $tax = 0)" || total.ReturnType != "?Money" || total.Docstring != "/** Total in cents. */" || !total.IsMethod {
+		t.Errorf("method = %+v", total)
+	}
+
+	if len(m.Interfaces) != 1 || !slices.Equal(m.Interfaces[0].Bases, []string{"Base"}) || len(m.Interfaces[0].Methods) != 1 {
+		t.Errorf("interfaces = %+v", m.Interfaces)
+	}
+	if len(m.Traits) != 1 || m.Traits[0].Docstring == "" || len(m.Traits[0].Methods) != 1 || m.Traits[0].Methods[0].Name != "sum" {
+		t.Errorf("traits = %+v", m.Traits)
+	}
+	if len(m.Enums) != 1 || !slices.Equal(m.Enums[0].Variants, []string{"Paid", "Open"}) {
+		t.Errorf("enums = %+v", m.Enums)
+	}
+
+	var names []string
+	for _, fn := range m.Functions {
+		names = append(names, fn.Name)
+	}
+	if !slices.Equal(names, []string{"format_invoice", "helper"}) {
+		t.Fatalf("functions = %v", names)
+	}
+	if fn := m.Functions[0]; fn.ReturnType != "string" || fn.Docstring != "/** Formats an invoice. */" {
+		t.Errorf("function = %+v", fn)
+	}
+}
@@ -22,7 +22,7 @@ type PythonExtractor struct {
 // NewPythonExtractor creates a new Python extractor with initialized parsers.
 func NewPythonExtractor() Extractor {
 	return &PythonExtractor{
-		BaseExtractor: NewBaseExtractor(NewPythonParser(), Python),
+		BaseExtractor: NewBaseExtractor(&pythonParserPool, Python),
 		importParser:  NewPythonImportParser(),
 	}
 }
@@ -52,7 +52,7 @@ func (e *PythonExtractor) Extract(filePath string) (*types.ModuleInfo, error) {
 	}
 
 	// Parse the full AST
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -738,12 +738,7 @@ func (e *PythonExtractor) nodeText(node *sitter.Node, content []byte) string {
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
 
 // ExtractFunctions extracts only function definitions from a Python file.
@@ -754,7 +749,7 @@ func (e *PythonExtractor) ExtractFunctions(filePath string) ([]types.Function, e
 		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
 	}
 
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -774,7 +769,7 @@ func (e *PythonExtractor) ExtractClasses(filePath string) ([]types.Class, error)
 		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
 	}
 
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -796,7 +791,7 @@ func (e *PythonExtractor) ExtractFromBytes(content []byte, filePath string) (*ty
 	}
 
 	// Parse the full AST
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing content failed")
 	}
@@ -272,12 +272,7 @@ func (p *PythonImportParser) nodeText(node *sitter.Node, content []byte) string
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
 
 // ParsePythonImports is a convenience function to parse imports from a file.
@@ -337,7 +332,10 @@ func (p *PythonImportParser) ParseImportsDetailed(filePath string) ([]ImportInfo
 		return nil, fmt.Errorf("reading file: %w", err)
 	}
 
-	tree := p.parser.Parse(nil, content)
+	parser := pythonParserPool.Get().(*sitter.Parser)
+	defer pythonParserPool.Put(parser)
+
+	tree := parser.Parse(nil, content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing failed")
 	}
@@ -577,7 +577,7 @@ async def async_func():
 
 	// Parse the file to get AST
 	content, _ := os.ReadFile(pyFile)
-	tree := extractor.parser.Parse(nil, content)
+	tree := extractor.parse(content)
 	defer tree.Close()
 	root := tree.RootNode()
 
@@ -669,3 +669,56 @@ func classNames(classes []types.Class) []string {
 	}
 	return names
 }
+
+// TestPythonExtractorUTF8 tests extraction from a file with multibyte characters
+func TestPythonExtractorUTF8(t *testing.T) {
+	tmpDir := t.TempDir()
+	pyFile := filepath.Join(tmpDir, "unicode_test.py")
+
+	pythonCode := `"""Модуль для проверки 日本語 😀."""
+
+def grüße(name: str) -> str:
+    """Return a greeting — «héllo»."""
+    return f"héllo {name} 世界"
+
+class Café:
+    """☕ coffee shop."""
+
+    def brew(self, größe: int) -> None:
+        pass
+`
+
+	if err := os.WriteFile(pyFile, []byte(pythonCode), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	moduleInfo, err := NewPythonExtractor().Extract(pyFile)
+	if err != nil {
+		t.Fatalf("Failed to extract: %v", err)
+	}
+
+	var found bool
+	for _, fn := range moduleInfo.Functions {
+		if fn.Name != "grüße" {
+			continue
+		}
+		found = true
+		if fn.Params != "(name: str)" {
+			t.Errorf("Unexpected params: %q", fn.Params)
+		}
+		if fn.Docstring != `"""Return a greeting — «héllo»."""` {
+			t.Errorf("Unexpected docstring: %q", fn.Docstring)
+		}
+	}
+	if !found {
+		t.Errorf("Expected function grüße, got %+v", moduleInfo.Functions)
+	}
+
+	if len(moduleInfo.Classes) != 1 || moduleInfo.Classes[0].Name != "Café" {
+		t.Fatalf("Expected class Café, got %+v", moduleInfo.Classes)
+	}
+	methods := moduleInfo.Classes[0].Methods
+	if len(methods) != 1 || methods[0].Name != "brew" || methods[0].Params != "(self, größe: int)" {
+		t.Errorf("Unexpected methods: %+v", methods)
+	}
+}
@@ -31,7 +31,7 @@ type RubyExtractor struct {
 // NewRubyExtractor creates a new Ruby extractor with initialized parser.
 func NewRubyExtractor() Extractor {
 	return &RubyExtractor{
-		BaseExtractor: NewBaseExtractor(NewRubyParser(), Ruby),
+		BaseExtractor: NewBaseExtractor(&rubyParserPool, Ruby),
 	}
 }
 
@@ -64,7 +64,7 @@ func (e *RubyExtractor) Extract(filePath string) (*types.ModuleInfo, error) {
 // ExtractFromBytes extracts module information from Ruby source code bytes.
 func (e *RubyExtractor) ExtractFromBytes(content []byte, filePath string) (*types.ModuleInfo, error) {
 	// Parse the full AST
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -722,12 +722,7 @@ func (e *RubyExtractor) nodeText(node *sitter.Node, content []byte) string {
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
 
 // ExtractFunctions extracts only function/method definitions from a Ruby file.
@@ -737,7 +732,7 @@ func (e *RubyExtractor) ExtractFunctions(filePath string) ([]types.Function, err
 		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
 	}
 
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -756,7 +751,7 @@ func (e *RubyExtractor) ExtractClasses(filePath string) ([]types.Class, error) {
 		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
 	}
 
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -775,7 +770,7 @@ func (e *RubyExtractor) ExtractModules(filePath string) ([]RubyModule, error) {
 		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
 	}
 
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -31,7 +31,7 @@ type RustExtractor struct {
 // NewRustExtractor creates a new Rust extractor with initialized parser.
 func NewRustExtractor() Extractor {
 	return &RustExtractor{
-		BaseExtractor: NewBaseExtractor(NewRustParser(), Rust),
+		BaseExtractor: NewBaseExtractor(&rustParserPool, Rust),
 	}
 }
 
@@ -64,7 +64,7 @@ func (e *RustExtractor) Extract(filePath string) (*types.ModuleInfo, error) {
 // ExtractFromBytes extracts module information from Rust source code bytes.
 func (e *RustExtractor) ExtractFromBytes(content []byte, filePath string) (*types.ModuleInfo, error) {
 	// Parse the full AST
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -859,12 +859,7 @@ func (e *RustExtractor) nodeText(node *sitter.Node, content []byte) string {
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
 
 // ExtractFunctions extracts only function definitions from a Rust file.
@@ -874,7 +869,7 @@ func (e *RustExtractor) ExtractFunctions(filePath string) ([]types.Function, err
 		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
 	}
 
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
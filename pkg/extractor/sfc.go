@@ -0,0 +1,305 @@
+package extractor
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// SFCBlock is a top-level block of a Vue or Svelte single-file component
+type SFCBlock struct {
+	// Lang is the block's lang attribute, such as "ts", or empty
+	Lang string
+	// Start and End are the byte offsets of the block's content, between
+	// its opening and closing tags
+	Start, End int
+	// Line and EndLine are the lines the content starts and ends on,
+	// counted from 1
+	Line, EndLine int
+	// Text is the content. For a Svelte template the script and style
+	// blocks within it are blanked.
+	Text string
+}
+
+// SFC is a single-file component split into its blocks
+type SFC struct {
+	Scripts []SFCBlock
+	// Template is the markup: the <template> block of a Vue component,
+	// everything outside the script and style blocks of a Svelte one. It
+	// is nil when there is none.
+	Template *SFCBlock
+}
+
+var (
+	// sfcOpenTag matches the opening tag of a top-level block
+	sfcOpenTag = regexp.MustCompile(`(?i)<(script|style|template)(\s[^>]*)?>`)
+	// sfcTemplateTag matches opening and closing template tags, to find
+	// the end of a Vue template with nested <template v-if> tags
+	sfcTemplateTag = regexp.MustCompile(`(?i)<(/?)template(\s[^>]*)?>`)
+	// sfcCloseTags match the closing tags of script and style blocks
+	sfcCloseTags = map[string]*regexp.Regexp{
+		"script": regexp.MustCompile(`(?i)</script\s*>`),
+		"style":  regexp.MustCompile(`(?i)</style\s*>`),
+	}
+	// sfcLangAttr matches the lang attribute of a block
+	sfcLangAttr = regexp.MustCompile(`(?i)\blang\s*=\s*["']?([\w-]+)`)
+)
+
+// SFCLanguage returns the component language of a .vue or .svelte file
+func SFCLanguage(filePath string) (Language, bool) {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".vue":
+		return Vue, true
+	case ".svelte":
+		return Svelte, true
+	}
+	return "", false
+}
+
+// SplitSFC splits the source of a lang component into its script blocks
+// and template. Blocks inside HTML comments are skipped.
+func SplitSFC(content []byte, lang Language) *SFC {
+	sfc := &SFC{}
+	var markup [][2]int // script and style blocks with their tags
+	for pos := 0; pos < len(content); {
+		loc := sfcOpenTag.FindSubmatchIndex(content[pos:])
+		if loc == nil {
+			break
+		}
+		for i := range loc {
+			if loc[i] >= 0 {
+				loc[i] += pos
+			}
+		}
+		if c := bytes.Index(content[pos:loc[0]], []byte("<!--")); c >= 0 {
+			end := bytes.Index(content[pos+c:], []byte("-->"))
+			if end < 0 {
+				break
+			}
+			pos += c + end + len("-->")
+			continue
+		}
+
+		name := strings.ToLower(string(content[loc[2]:loc[3]]))
+		var attrs string
+		if loc[4] >= 0 {
+			attrs = string(content[loc[4]:loc[5]])
+		}
+		start := loc[1]
+		var end, next int
+		switch {
+		case name == "template" && lang == Vue:
+			end, next = templateEnd(content, start)
+		case name == "template":
+			// Svelte has no template block; it is part of the markup
+			pos = start
+			continue
+		default:
+			closing := sfcCloseTags[name].FindIndex(content[start:])
+			if closing == nil {
+				end, next = len(content), len(content)
+			} else {
+				end, next = start+closing[0], start+closing[1]
+			}
+		}
+
+		block := newSFCBlock(content, start, end)
+		if m := sfcLangAttr.FindStringSubmatch(attrs); m != nil {
+			block.Lang = strings.ToLower(m[1])
+		}
+		switch name {
+		case "script":
+			sfc.Scripts = append(sfc.Scripts, block)
+		case "template":
+			if sfc.Template == nil {
+				sfc.Template = trimBlock(content, block)
+			}
+		}
+		if name != "template" {
+			markup = append(markup, [2]int{loc[0], next})
+		}
+		pos = next
+	}
+
+	if lang == Svelte {
+		masked := bytes.Clone(content)
+		for _, m := range markup {
+			blank(masked[m[0]:m[1]])
+		}
+		block := newSFCBlock(masked, 0, len(masked))
+		sfc.Template = trimBlock(masked, block)
+	}
+	return sfc
+}
+
+// templateEnd returns the offsets of the closing tag of the Vue template
+// whose content starts at start, and of the byte after it
+func templateEnd(content []byte, start int) (int, int) {
+	depth := 1
+	for _, loc := range sfcTemplateTag.FindAllSubmatchIndex(content[start:], -1) {
+		if loc[3] > loc[2] {
+			depth--
+		} else {
+			depth++
+		}
+		if depth == 0 {
+			return start + loc[0], start + loc[1]
+		}
+	}
+	return len(content), len(content)
+}
+
+// newSFCBlock returns the block of content between start and end
+func newSFCBlock(content []byte, start, end int) SFCBlock {
+	return SFCBlock{
+		Start:   start,
+		End:     end,
+		Line:    bytes.Count(content[:start], []byte("\n")) + 1,
+		EndLine: bytes.Count(content[:end], []byte("\n")) + 1,
+		Text:    string(content[start:end]),
+	}
+}
+
+// trimBlock returns block without its leading and trailing blank lines,
+// or nil when it is blank
+func trimBlock(content []byte, block SFCBlock) *SFCBlock {
+	const space = " \t\r\n"
+	text := block.Text
+	if strings.Trim(text, space) == "" {
+		return nil
+	}
+	// The first line keeps its indentation
+	lead := len(text) - len(strings.TrimLeft(text, space))
+	lead = strings.LastIndexByte(text[:lead], '\n') + 1
+	trimmed := newSFCBlock(content, block.Start+lead, block.Start+len(strings.TrimRight(text, space)))
+	trimmed.Lang = block.Lang
+	return &trimmed
+}
+
+// Mask returns a copy of content with everything outside the script
+// blocks blanked, so a TypeScript or JavaScript parser sees only the
+// scripts, at the lines and offsets they have in the component
+func (s *SFC) Mask(content []byte) []byte {
+	return maskExcept(content, s.Scripts)
+}
+
+// maskExcept returns a copy of content with everything outside blocks
+// blanked
+func maskExcept(content []byte, blocks []SFCBlock) []byte {
+	masked := bytes.Clone(content)
+	pos := 0
+	for _, b := range blocks {
+		blank(masked[pos:b.Start])
+		pos = b.End
+	}
+	blank(masked[pos:])
+	return masked
+}
+
+// blank replaces every byte of b but newlines with a space
+func blank(b []byte) {
+	for i, c := range b {
+		if c != '\n' && c != '\r' {
+			b[i] = ' '
+		}
+	}
+}
+
+// ScriptSource returns the script blocks of a .vue or .svelte file, masked
+// as by SFC.Mask, and the content of any other file unchanged. Parsers that
+// take the source of an ES file use it to read components too.
+func ScriptSource(filePath string, content []byte) []byte {
+	lang, ok := SFCLanguage(filePath)
+	if !ok {
+		return content
+	}
+	return SplitSFC(content, lang).Mask(content)
+}
+
+// isTSBlock reports whether a script block is written in TypeScript
+func isTSBlock(b SFCBlock) bool {
+	return b.Lang == "ts" || b.Lang == "tsx" || b.Lang == "typescript"
+}
+
+// SFCExtractor implements the Extractor interface for Vue and Svelte
+// single-file components. Each <script> block is extracted with the
+// TypeScript or JavaScript extractor, by its lang attribute, on the
+// component's source with everything else blanked, so line numbers are
+// those of the component. Other ES files are extracted as TypeScript, so
+// the extractor can serve a call graph mixing components and modules.
+type SFCExtractor struct {
+	lang Language
+	ts   *TypeScriptExtractor
+	js   *JavaScriptExtractor
+}
+
+// NewVueExtractor creates a new extractor for Vue single-file components.
+func NewVueExtractor() Extractor {
+	return newSFCExtractor(Vue)
+}
+
+// NewSvelteExtractor creates a new extractor for Svelte components.
+func NewSvelteExtractor() Extractor {
+	return newSFCExtractor(Svelte)
+}
+
+func newSFCExtractor(lang Language) *SFCExtractor {
+	return &SFCExtractor{
+		lang: lang,
+		ts:   NewTypeScriptExtractor().(*TypeScriptExtractor),
+		js:   NewJavaScriptExtractor().(*JavaScriptExtractor),
+	}
+}
+
+// Language returns the component language.
+func (e *SFCExtractor) Language() Language {
+	return e.lang
+}
+
+// FileExtensions returns the component file extension.
+func (e *SFCExtractor) FileExtensions() []string {
+	return []string{"." + string(e.lang)}
+}
+
+// Extract parses a component and returns the merged module information of
+// its script blocks.
+func (e *SFCExtractor) Extract(filePath string) (*types.ModuleInfo, error) {
+	lang, ok := SFCLanguage(filePath)
+	if !ok {
+		return e.ts.Extract(filePath)
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	sfc := SplitSFC(content, lang)
+	module := &types.ModuleInfo{
+		Path: filePath,
+		CallGraph: types.CallGraph{
+			Edges: []types.CallGraphEdge{},
+		},
+	}
+	for _, block := range sfc.Scripts {
+		source := maskExcept(content, []SFCBlock{block})
+		var part *types.ModuleInfo
+		if isTSBlock(block) {
+			part, err = e.ts.ExtractFromBytes(source, filePath)
+		} else {
+			part, err = e.js.ExtractFromBytes(source, filePath)
+		}
+		if err != nil {
+			return nil, err
+		}
+		module.Functions = append(module.Functions, part.Functions...)
+		module.Classes = append(module.Classes, part.Classes...)
+		module.Interfaces = append(module.Interfaces, part.Interfaces...)
+		module.Enums = append(module.Enums, part.Enums...)
+		module.Imports = append(module.Imports, part.Imports...)
+	}
+	return module, nil
+}
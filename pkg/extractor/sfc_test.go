@@ -0,0 +1,128 @@
+package extractor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const vueComponent = `<template>
+  <div>
+    <template v-if="user">{{ user.name }}</template>
+    <button @click="save">Save</button>
+  </div>
+</template>
+
+<!-- <script>function commented() {}</script> -->
+<script setup lang="ts">
+import { ref } from 'vue'
+import { api } from './api'
+
+interface User { name: string }
+
+function save(): void {
+  api.post(user.value)
+}
+</script>
+
+<style scoped>
+button { color: red; }
+</style>
+`
+
+const svelteComponent = `<script context="module">
+export function preload() {
+  return {}
+}
+</script>
+
+<script>
+  let count = 0
+  function increment() {
+    count += 1
+  }
+</script>
+
+<button on:click={increment}>{count}</button>
+
+<style>
+  button { color: red; }
+</style>
+`
+
+func TestSplitSFCVue(t *testing.T) {
+	sfc := SplitSFC([]byte(vueComponent), Vue)
+	if len(sfc.Scripts) != 1 {
+		t.Fatalf("Scripts = %+v, want the one outside the comment", sfc.Scripts)
+	}
+	if script := sfc.Scripts[0]; script.Lang != "ts" || script.Line != 9 || script.EndLine != 18 {
+		t.Errorf("script = lang %q, lines %d-%d", script.Lang, script.Line, script.EndLine)
+	}
+	tmpl := sfc.Template
+	if tmpl == nil {
+		t.Fatal("Template = nil")
+	}
+	if tmpl.Line != 2 || tmpl.EndLine != 5 || !strings.HasPrefix(tmpl.Text, "  <div>") || !strings.HasSuffix(tmpl.Text, "</div>") {
+		t.Errorf("Template = lines %d-%d %q", tmpl.Line, tmpl.EndLine, tmpl.Text)
+	}
+}
+
+func TestSplitSFCSvelte(t *testing.T) {
+	sfc := SplitSFC([]byte(svelteComponent), Svelte)
+	if len(sfc.Scripts) != 2 {
+		t.Fatalf("Scripts = %+v", sfc.Scripts)
+	}
+	tmpl := sfc.Template
+	if tmpl == nil || strings.TrimSpace(tmpl.Text) != "<button on:click={increment}>{count}</button>" || tmpl.Line != 14 {
+		t.Errorf("Template = %+v", tmpl)
+	}
+}
+
+func TestScriptSource(t *testing.T) {
+	source := ScriptSource("App.vue", []byte(vueComponent))
+	if len(source) != len(vueComponent) || strings.Count(string(source), "\n") != strings.Count(vueComponent, "\n") {
+		t.Fatal("masking changed offsets")
+	}
+	if strings.Contains(string(source), "<div>") || strings.Contains(string(source), "commented") {
+		t.Errorf("markup left in script source:\n%s", source)
+	}
+	if plain := []byte("const x = 1\n"); string(ScriptSource("x.ts", plain)) != string(plain) {
+		t.Error("ScriptSource changed a TypeScript file")
+	}
+}
+
+func TestSFCExtractor(t *testing.T) {
+	dir := t.TempDir()
+	for name, src := range map[string]string{"App.vue": vueComponent, "Counter.svelte": svelteComponent} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	module, err := ExtractFile(filepath.Join(dir, "App.vue"))
+	if err != nil {
+		t.Fatalf("Extract(App.vue) failed: %v", err)
+	}
+	if len(module.Functions) != 1 || module.Functions[0].Name != "save" || module.Functions[0].LineNumber != 15 {
+		t.Errorf("Functions = %+v, want save on line 15", module.Functions)
+	}
+	if len(module.Interfaces) != 1 || module.Interfaces[0].Name != "User" {
+		t.Errorf("Interfaces = %+v", module.Interfaces)
+	}
+	if len(module.Imports) != 2 {
+		t.Errorf("Imports = %+v", module.Imports)
+	}
+
+	module, err = ExtractFile(filepath.Join(dir, "Counter.svelte"))
+	if err != nil {
+		t.Fatalf("Extract(Counter.svelte) failed: %v", err)
+	}
+	lines := map[string]int{}
+	for _, fn := range module.Functions {
+		lines[fn.Name] = fn.LineNumber
+	}
+	if lines["preload"] != 2 || lines["increment"] != 9 {
+		t.Errorf("Functions = %+v, want preload on line 2 and increment on line 9", module.Functions)
+	}
+}
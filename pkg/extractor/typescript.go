@@ -22,7 +22,7 @@ type TypeScriptExtractor struct {
 // NewTypeScriptExtractor creates a new TypeScript extractor with initialized parsers.
 func NewTypeScriptExtractor() Extractor {
 	return &TypeScriptExtractor{
-		BaseExtractor: NewBaseExtractor(NewTypeScriptParser(), TypeScript),
+		BaseExtractor: NewBaseExtractor(&typescriptParserPool, TypeScript),
 		importParser:  NewTypeScriptImportParser(),
 	}
 }
@@ -43,39 +43,7 @@ func (e *TypeScriptExtractor) Extract(filePath string) (*types.ModuleInfo, error
 	if err != nil {
 		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
 	}
-
-	// Parse imports using the import parser
-	imports, err := e.importParser.ParseImportsFromBytes(content, filePath)
-	if err != nil {
-		return nil, fmt.Errorf("parsing imports: %w", err)
-	}
-
-	// Parse the full AST
-	tree := e.parser.Parse(nil, content)
-	if tree == nil {
-		return nil, fmt.Errorf("parsing file %s failed", filePath)
-	}
-	defer tree.Close()
-
-	root := tree.RootNode()
-
-	// Extract all constructs
-	functions := e.extractFunctions(root, content)
-	classes := e.extractClasses(root, content)
-	interfaces := e.extractInterfaces(root, content)
-	enums := e.extractEnums(root, content)
-
-	return &types.ModuleInfo{
-		Path:       filePath,
-		Functions:  functions,
-		Classes:    classes,
-		Interfaces: interfaces,
-		Enums:      enums,
-		Imports:    imports,
-		CallGraph: types.CallGraph{
-			Edges: []types.CallGraphEdge{},
-		},
-	}, nil
+	return e.ExtractFromBytes(ScriptSource(filePath, content), filePath)
 }
 
 // extractFunctions extracts all function definitions from the AST.
@@ -338,6 +306,15 @@ func (e *TypeScriptExtractor) parseClassHeritage(node *sitter.Node, content []by
 		switch child.Type() {
 		case "extends":
 			// Next should be the base class
+		case "extends_clause", "implements_clause":
+			// class A extends B<T> implements C, ns.D
+			bases = append(bases, e.parseClassHeritage(child, content)...)
+		case "generic_type":
+			// implements Repository<User>
+			bases = append(bases, e.nodeText(child.ChildByFieldName("name"), content))
+		case "nested_type_identifier":
+			// Module-qualified interface: implements ns.Named
+			bases = append(bases, e.nodeText(child, content))
 		case "identifier":
 			base := e.nodeText(child, content)
 			if base != "" {
@@ -504,7 +481,7 @@ func (e *TypeScriptExtractor) parseInterface(node *sitter.Node, content []byte)
 		case "interface":
 		case "type_identifier":
 			name = e.nodeText(child, content)
-		case "class_heritage":
+		case "class_heritage", "extends_type_clause":
 			bases = e.parseInterfaceHeritage(child, content)
 		case "object_type":
 			// Interface body - extract methods
@@ -533,11 +510,14 @@ func (e *TypeScriptExtractor) parseInterfaceHeritage(node *sitter.Node, content
 
 		switch child.Type() {
 		case "extends":
-		case "type_identifier":
+		case "type_identifier", "nested_type_identifier":
 			base := e.nodeText(child, content)
 			if base != "" {
 				bases = append(bases, base)
 			}
+		case "generic_type":
+			// extends Repository<User>
+			bases = append(bases, e.nodeText(child.ChildByFieldName("name"), content))
 		}
 	}
 
@@ -663,12 +643,7 @@ func (e *TypeScriptExtractor) nodeText(node *sitter.Node, content []byte) string
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
 
 // ExtractFunctions extracts only function definitions from a TypeScript file.
@@ -678,7 +653,7 @@ func (e *TypeScriptExtractor) ExtractFunctions(filePath string) ([]types.Functio
 		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
 	}
 
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -697,7 +672,7 @@ func (e *TypeScriptExtractor) ExtractClasses(filePath string) ([]types.Class, er
 		return nil, fmt.Errorf("reading file %s: %w", filePath, err)
 	}
 
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing file %s failed", filePath)
 	}
@@ -718,7 +693,7 @@ func (e *TypeScriptExtractor) ExtractFromBytes(content []byte, filePath string)
 	}
 
 	// Parse the full AST
-	tree := e.parser.Parse(nil, content)
+	tree := e.parse(content)
 	if tree == nil {
 		return nil, fmt.Errorf("parsing content failed")
 	}
@@ -299,12 +299,7 @@ func (p *TypeScriptImportParser) nodeText(node *sitter.Node, content []byte) str
 	if node == nil {
 		return ""
 	}
-	start := node.StartByte()
-	end := node.EndByte()
-	if start >= uint32(len(content)) || end > uint32(len(content)) {
-		return ""
-	}
-	return string(content[start:end])
+	return types.NodeText(content, node.StartByte(), node.EndByte())
 }
 
 // GetModuleName extracts the module name from a file path.
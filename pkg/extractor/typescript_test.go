@@ -3,6 +3,7 @@ package extractor
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/l3aro/go-context-query/pkg/types"
@@ -308,3 +309,37 @@ class Greeter {
 		t.Errorf("Expected path 'test.ts', got '%s'", info.Path)
 	}
 }
+
+// TestTypeScriptHeritage tests extends and implements clauses
+func TestTypeScriptHeritage(t *testing.T) {
+	tsCode := []byte(`
+interface Named extends Base, Repository<User> {
+    name: string;
+}
+
+class Animal extends Model<Animal> implements Named, ns.Tagged {
+    speak(): void {}
+}
+
+class Dog extends Animal {}
+`)
+
+	extractor := NewTypeScriptExtractor().(*TypeScriptExtractor)
+	info, err := extractor.ExtractFromBytes(tsCode, "test.ts")
+	if err != nil {
+		t.Fatalf("ExtractFromBytes() failed: %v", err)
+	}
+
+	if len(info.Interfaces) != 1 || !slices.Equal(info.Interfaces[0].Bases, []string{"Base", "Repository"}) {
+		t.Errorf("interface bases = %+v", info.Interfaces)
+	}
+	want := map[string][]string{
+		"Animal": {"Model", "Named", "ns.Tagged"},
+		"Dog":    {"Animal"},
+	}
+	for _, cls := range info.Classes {
+		if !slices.Equal(cls.Bases, want[cls.Name]) {
+			t.Errorf("class %s bases = %v, want %v", cls.Name, cls.Bases, want[cls.Name])
+		}
+	}
+}
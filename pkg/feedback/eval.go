@@ -0,0 +1,122 @@
+package feedback
+
+// Case is a judged query: the units marked helpful and unhelpful for it
+type Case struct {
+	Query     string   `json:"query"`
+	Helpful   []string `json:"helpful,omitempty"`
+	Unhelpful []string `json:"unhelpful,omitempty"`
+}
+
+// Cases groups the latest judgments by query, in the order queries were
+// first judged
+func Cases(judgments []Judgment) []Case {
+	positions := make(map[string]int)
+	var cases []Case
+	for _, j := range Latest(judgments) {
+		q := NormalizeQuery(j.Query)
+		i, ok := positions[q]
+		if !ok {
+			i = len(cases)
+			positions[q] = i
+			cases = append(cases, Case{Query: j.Query})
+		}
+		if j.Helpful {
+			cases[i].Helpful = append(cases[i].Helpful, j.URI)
+		} else {
+			cases[i].Unhelpful = append(cases[i].Unhelpful, j.URI)
+		}
+	}
+	return cases
+}
+
+// CaseResult is how one judged query ranks now
+type CaseResult struct {
+	Query string `json:"query"`
+	// Rank is the position of the first helpful unit, from 1, or 0 when
+	// none is in the top k
+	Rank int `json:"rank"`
+	// Found is how many helpful units are in the top k, of Helpful
+	Found   int `json:"found"`
+	Helpful int `json:"helpful"`
+	// Unhelpful is how many units judged unhelpful are in the top k
+	Unhelpful int `json:"unhelpful"`
+}
+
+// Report summarizes how well search ranks judged results
+type Report struct {
+	K       int `json:"k"`
+	Queries int `json:"queries"`
+	// MRR is the mean reciprocal rank of the first helpful unit over the
+	// queries with one
+	MRR float64 `json:"mrr"`
+	// Recall is the share of helpful units found in the top k
+	Recall float64 `json:"recall"`
+	// UnhelpfulRate is the share of top k slots taken by units judged
+	// unhelpful for the query
+	UnhelpfulRate float64      `json:"unhelpful_rate"`
+	Cases         []CaseResult `json:"cases"`
+}
+
+// Evaluate replays each case through search, which returns the URIs of the
+// top k results for a query, and scores the rankings against the judgments
+func Evaluate(cases []Case, k int, search func(query string, k int) ([]string, error)) (*Report, error) {
+	report := &Report{K: k, Cases: []CaseResult{}}
+	var rankQueries, helpful, found, slots, unhelpful int
+	var reciprocal float64
+	for _, c := range cases {
+		uris, err := search(c.Query, k)
+		if err != nil {
+			return nil, err
+		}
+		if len(uris) > k {
+			uris = uris[:k]
+		}
+		good := toSet(c.Helpful)
+		bad := toSet(c.Unhelpful)
+
+		result := CaseResult{Query: c.Query, Helpful: len(c.Helpful)}
+		for i, uri := range uris {
+			switch {
+			case good[uri]:
+				result.Found++
+				if result.Rank == 0 {
+					result.Rank = i + 1
+				}
+			case bad[uri]:
+				result.Unhelpful++
+			}
+		}
+		report.Cases = append(report.Cases, result)
+
+		if len(c.Helpful) > 0 {
+			rankQueries++
+			if result.Rank > 0 {
+				reciprocal += 1 / float64(result.Rank)
+			}
+		}
+		helpful += len(c.Helpful)
+		found += result.Found
+		slots += len(uris)
+		unhelpful += result.Unhelpful
+	}
+
+	report.Queries = len(cases)
+	if rankQueries > 0 {
+		report.MRR = reciprocal / float64(rankQueries)
+	}
+	if helpful > 0 {
+		report.Recall = float64(found) / float64(helpful)
+	}
+	if slots > 0 {
+		report.UnhelpfulRate = float64(unhelpful) / float64(slots)
+	}
+	return report, nil
+}
+
+func toSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
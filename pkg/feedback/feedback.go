@@ -0,0 +1,161 @@
+// Package feedback records whether search results helped, so relevance can
+// be measured against real queries and, optionally, nudged by them. Each
+// judgment marks one unit as helpful or unhelpful for one query; they are
+// appended to a JSON lines file in the project's .gcq directory.
+package feedback
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileName is the name of the judgments file inside a project's .gcq
+// directory
+const FileName = "feedback.jsonl"
+
+// Judgment marks a search result as helpful or not for a query
+type Judgment struct {
+	Query string `json:"query"`
+	// URI is the unit URI of the result, as reported by search
+	URI     string    `json:"uri"`
+	Helpful bool      `json:"helpful"`
+	Time    time.Time `json:"time"`
+}
+
+// Path returns the judgments file of the project at rootDir
+func Path(rootDir string) string {
+	return filepath.Join(rootDir, ".gcq", FileName)
+}
+
+// NormalizeQuery folds case and whitespace, so judgments of the same query
+// typed differently are grouped together
+func NormalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// Record appends judgments to the project's judgments file, creating it
+// when needed. Judgments without a time are stamped with the current one.
+func Record(rootDir string, judgments ...Judgment) error {
+	for _, j := range judgments {
+		if strings.TrimSpace(j.Query) == "" {
+			return fmt.Errorf("query cannot be empty")
+		}
+		if strings.TrimSpace(j.URI) == "" {
+			return fmt.Errorf("result URI cannot be empty")
+		}
+	}
+
+	path := Path(rootDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	now := time.Now().UTC()
+	enc := json.NewEncoder(f)
+	for _, j := range judgments {
+		if j.Time.IsZero() {
+			j.Time = now
+		}
+		if err := enc.Encode(j); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+	}
+	return f.Close()
+}
+
+// Load reads every judgment of the project at rootDir in the order they
+// were recorded. A project without a judgments file has none.
+func Load(rootDir string) ([]Judgment, error) {
+	path := Path(rootDir)
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var judgments []Judgment
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1<<20)
+	for line := 1; sc.Scan(); line++ {
+		if strings.TrimSpace(sc.Text()) == "" {
+			continue
+		}
+		var j Judgment
+		if err := json.Unmarshal(sc.Bytes(), &j); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		judgments = append(judgments, j)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return judgments, nil
+}
+
+// Latest keeps the last judgment of each query and URI, so marking a result
+// again replaces the earlier verdict. Pairs keep the order they were first
+// judged in.
+func Latest(judgments []Judgment) []Judgment {
+	type key struct{ query, uri string }
+	positions := make(map[key]int)
+	var out []Judgment
+	for _, j := range judgments {
+		k := key{NormalizeQuery(j.Query), j.URI}
+		if i, ok := positions[k]; ok {
+			out[i] = j
+			continue
+		}
+		positions[k] = len(out)
+		out = append(out, j)
+	}
+	return out
+}
+
+// Boosts returns a score multiplier for each judged unit URI. A unit found
+// helpful h times and unhelpful u times, over all queries, is scaled by
+// 1 + weight*(h-u)/(h+u+1), so a single judgment moves it less than a
+// consistent record, and weight (0 to 1) bounds the effect. Only the latest
+// judgment of each query counts.
+func Boosts(judgments []Judgment, weight float64) map[string]float32 {
+	if weight <= 0 {
+		return nil
+	}
+	type tally struct{ helpful, unhelpful int }
+	tallies := make(map[string]*tally)
+	for _, j := range Latest(judgments) {
+		t := tallies[j.URI]
+		if t == nil {
+			t = &tally{}
+			tallies[j.URI] = t
+		}
+		if j.Helpful {
+			t.helpful++
+		} else {
+			t.unhelpful++
+		}
+	}
+
+	boosts := make(map[string]float32, len(tallies))
+	for uri, t := range tallies {
+		if t.helpful == t.unhelpful {
+			continue
+		}
+		net := float64(t.helpful - t.unhelpful)
+		boosts[uri] = float32(1 + weight*net/float64(t.helpful+t.unhelpful+1))
+	}
+	return boosts
+}
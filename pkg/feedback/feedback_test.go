@@ -0,0 +1,89 @@
+package feedback
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRecordAndLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	judgments, err := Load(dir)
+	if err != nil || judgments != nil {
+		t.Fatalf("Load without a file = %v, %v; want nothing", judgments, err)
+	}
+
+	if err := Record(dir, Judgment{Query: "parse config", URI: "go://internal/config#Load", Helpful: true}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := Record(dir, Judgment{Query: "Parse  Config", URI: "go://internal/config#Load"}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := Record(dir, Judgment{Query: "", URI: "go://x#y"}); err == nil {
+		t.Error("Record accepted an empty query")
+	}
+
+	judgments, err = Load(dir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(judgments) != 2 || judgments[0].Time.IsZero() {
+		t.Fatalf("Load = %+v", judgments)
+	}
+	latest := Latest(judgments)
+	if len(latest) != 1 || latest[0].Helpful {
+		t.Errorf("Latest = %+v, want the later unhelpful verdict only", latest)
+	}
+}
+
+func TestBoosts(t *testing.T) {
+	judgments := []Judgment{
+		{Query: "a", URI: "good", Helpful: true},
+		{Query: "b", URI: "good", Helpful: true},
+		{Query: "a", URI: "bad"},
+		{Query: "a", URI: "mixed", Helpful: true},
+		{Query: "b", URI: "mixed"},
+	}
+	boosts := Boosts(judgments, 0.5)
+	if got := boosts["good"]; math.Abs(float64(got)-(1+0.5*2.0/3)) > 1e-6 {
+		t.Errorf("boost of good = %v", got)
+	}
+	if got := boosts["bad"]; math.Abs(float64(got)-0.75) > 1e-6 {
+		t.Errorf("boost of bad = %v, want 0.75", got)
+	}
+	if _, ok := boosts["mixed"]; ok {
+		t.Error("an evenly judged unit was boosted")
+	}
+	if Boosts(judgments, 0) != nil {
+		t.Error("a zero weight produced boosts")
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	judgments := []Judgment{
+		{Query: "open store", URI: "store#Open", Helpful: true},
+		{Query: "open store", URI: "mock#Open"},
+		{Query: "close", URI: "store#Close", Helpful: true},
+	}
+	cases := Cases(judgments)
+	if len(cases) != 2 {
+		t.Fatalf("Cases = %+v", cases)
+	}
+
+	rankings := map[string][]string{
+		"open store": {"mock#Open", "store#Open", "other"},
+		"close":      {"other", "more"},
+	}
+	report, err := Evaluate(cases, 2, func(query string, k int) ([]string, error) {
+		return rankings[query], nil
+	})
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	if report.MRR != 0.25 || report.Recall != 0.5 || report.UnhelpfulRate != 0.25 {
+		t.Errorf("report = %+v, want MRR 0.25, recall 0.5, unhelpful rate 0.25", report)
+	}
+	if c := report.Cases[0]; c.Rank != 2 || c.Found != 1 || c.Unhelpful != 1 {
+		t.Errorf("case = %+v", c)
+	}
+}
@@ -0,0 +1,105 @@
+// Package findings defines the results of analysis commands and the sinks
+// that write them, so every analysis can be printed, saved as JSON or SARIF,
+// shown as GitHub annotations or posted to a webhook in the same way.
+package findings
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Severity is how serious a finding is
+type Severity string
+
+const (
+	SeverityNote    Severity = "note"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Rule describes a check that produces findings
+type Rule struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+}
+
+// Finding is one problem an analysis reports at a location
+type Finding struct {
+	RuleID   string   `json:"rule_id"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	// File is relative to the report root
+	File string `json:"file"`
+	Line int    `json:"line,omitempty"`
+	// Unit is the name of the function, method or class the finding is about
+	Unit string `json:"unit,omitempty"`
+}
+
+// Location formats the finding's location as "file:line"
+func (f Finding) Location() string {
+	if f.Line > 0 {
+		return fmt.Sprintf("%s:%d", f.File, f.Line)
+	}
+	return f.File
+}
+
+// Report is the findings of one analysis run
+type Report struct {
+	// Analysis names the command that produced the report, e.g. "complexity"
+	Analysis string    `json:"analysis"`
+	Root     string    `json:"root"`
+	Rules    []Rule    `json:"rules"`
+	Findings []Finding `json:"findings"`
+}
+
+// Sort orders findings by file, line and rule
+func (r *Report) Sort() {
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		a, b := r.Findings[i], r.Findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.RuleID < b.RuleID
+	})
+}
+
+// Sink writes a report somewhere
+type Sink interface {
+	Write(report *Report) error
+}
+
+// Formats lists the sink formats accepted by NewSink
+var Formats = []string{"text", "json", "sarif", "github", "webhook"}
+
+// NewSink returns the sink for format. target is the file the json and
+// sarif formats write to (stdout when empty) and the URL the webhook format
+// posts to; the text and github formats always write to stdout.
+func NewSink(format, target, toolVersion string) (Sink, error) {
+	switch format {
+	case "", "text":
+		if target != "" {
+			return nil, fmt.Errorf("--output is not supported with the text format")
+		}
+		return NewTextSink(nil), nil
+	case "json":
+		return &JSONSink{Path: target}, nil
+	case "sarif":
+		return &SARIFSink{Path: target, ToolVersion: toolVersion}, nil
+	case "github":
+		if target != "" {
+			return nil, fmt.Errorf("--output is not supported with the github format")
+		}
+		return NewGitHubSink(nil), nil
+	case "webhook":
+		if target == "" {
+			return nil, fmt.Errorf("the webhook format requires --output <url>")
+		}
+		return NewWebhookSink(target), nil
+	default:
+		return nil, fmt.Errorf("unknown format: %s (must be one of %s)", format, strings.Join(Formats, ", "))
+	}
+}
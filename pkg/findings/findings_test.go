@@ -0,0 +1,146 @@
+package findings
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testReport() *Report {
+	return &Report{
+		Analysis: "complexity",
+		Root:     "/project",
+		Rules:    []Rule{{ID: "gcq/complexity", Description: "Too complex"}},
+		Findings: []Finding{
+			{RuleID: "gcq/complexity", Severity: SeverityError, Message: "parse is complex, 25", File: "pkg/parse.go", Line: 40, Unit: "parse"},
+			{RuleID: "gcq/complexity", Severity: SeverityWarning, Message: "load: 12\nbranches", File: "pkg/load.go", Line: 7, Unit: "load"},
+		},
+	}
+}
+
+func TestReportSort(t *testing.T) {
+	report := testReport()
+	report.Sort()
+	if report.Findings[0].File != "pkg/load.go" {
+		t.Errorf("expected findings ordered by file, got %+v", report.Findings)
+	}
+}
+
+func TestTextSink(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewTextSink(&buf).Write(testReport()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "pkg/parse.go:40") || !strings.Contains(out, "2 complexity findings") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	buf.Reset()
+	NewTextSink(&buf).Write(&Report{Analysis: "dead-code"})
+	if !strings.Contains(buf.String(), "No dead-code findings") {
+		t.Errorf("unexpected output for an empty report: %q", buf.String())
+	}
+}
+
+func TestGitHubSink(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewGitHubSink(&buf).Write(testReport()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	want := []string{
+		"::error file=pkg/parse.go,line=40,title=gcq/complexity::parse is complex, 25",
+		"::warning file=pkg/load.go,line=7,title=gcq/complexity::load: 12%0Abranches",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d annotations, got %q", len(want), lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("annotation %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestSARIF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.sarif")
+	if err := (&SARIFSink{Path: path, ToolVersion: "1.2.3"}).Write(testReport()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log SARIF
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("invalid SARIF JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF log: %+v", log)
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "gcq" || run.Tool.Driver.Version != "1.2.3" || len(run.Tool.Driver.Rules) != 1 {
+		t.Errorf("unexpected driver: %+v", run.Tool.Driver)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(run.Results))
+	}
+	res := run.Results[0]
+	if res.Level != "error" || res.Locations[0].PhysicalLocation.ArtifactLocation.URI != "pkg/parse.go" ||
+		res.Locations[0].PhysicalLocation.Region.StartLine != 40 {
+		t.Errorf("unexpected result: %+v", res)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received Report
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+	}))
+	defer server.Close()
+
+	if err := NewWebhookSink(server.URL).Write(testReport()); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if received.Analysis != "complexity" || len(received.Findings) != 2 {
+		t.Errorf("unexpected report received: %+v", received)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	if err := NewWebhookSink(failing.URL).Write(testReport()); err == nil {
+		t.Error("expected an error for a 500 response")
+	}
+}
+
+func TestNewSink(t *testing.T) {
+	for _, format := range []string{"", "text", "json", "sarif", "github"} {
+		if _, err := NewSink(format, "", ""); err != nil {
+			t.Errorf("NewSink(%q) failed: %v", format, err)
+		}
+	}
+	if _, err := NewSink("webhook", "", ""); err == nil {
+		t.Error("expected an error for a webhook without a URL")
+	}
+	if _, err := NewSink("text", "out.txt", ""); err == nil {
+		t.Error("expected an error for text output to a file")
+	}
+	if _, err := NewSink("xml", "", ""); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,120 @@
+package findings
+
+import "path/filepath"
+
+// sarifSchema is the JSON schema of SARIF 2.1.0 logs
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// SARIFSink writes the report as a SARIF 2.1.0 log to Path, or stdout when
+// Path is empty, for code scanning tools such as GitHub code scanning
+type SARIFSink struct {
+	Path        string
+	ToolVersion string
+}
+
+// Write converts and writes the report
+func (s *SARIFSink) Write(report *Report) error {
+	return writeJSON(s.Path, report.SARIF(s.ToolVersion))
+}
+
+// SARIF is a SARIF log
+type SARIF struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun is the output of one tool run
+type SARIFRun struct {
+	Tool    SARIFTool     `json:"tool"`
+	Results []SARIFResult `json:"results"`
+}
+
+// SARIFTool describes the tool that produced a run
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver is the tool's main component and its rules
+type SARIFDriver struct {
+	Name    string      `json:"name"`
+	Version string      `json:"version,omitempty"`
+	Rules   []SARIFRule `json:"rules"`
+}
+
+// SARIFRule describes a rule results refer to
+type SARIFRule struct {
+	ID               string       `json:"id"`
+	ShortDescription SARIFMessage `json:"shortDescription"`
+}
+
+// SARIFResult is one finding
+type SARIFResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   SARIFMessage    `json:"message"`
+	Locations []SARIFLocation `json:"locations,omitempty"`
+}
+
+// SARIFMessage is a plain-text message
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFLocation is where a result was found
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+// SARIFPhysicalLocation is a file region
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Region           *SARIFRegion          `json:"region,omitempty"`
+}
+
+// SARIFArtifactLocation is a file URI, relative to the repository root
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// SARIFRegion is a range of lines
+type SARIFRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// SARIF converts the report to a SARIF log. toolVersion is the gcq version
+// recorded as the driver version.
+func (r *Report) SARIF(toolVersion string) *SARIF {
+	run := SARIFRun{
+		Tool: SARIFTool{Driver: SARIFDriver{
+			Name:    "gcq",
+			Version: toolVersion,
+			Rules:   make([]SARIFRule, 0, len(r.Rules)),
+		}},
+		Results: make([]SARIFResult, 0, len(r.Findings)),
+	}
+	for _, rule := range r.Rules {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, SARIFRule{
+			ID:               rule.ID,
+			ShortDescription: SARIFMessage{Text: rule.Description},
+		})
+	}
+
+	for _, f := range r.Findings {
+		level := string(f.Severity)
+		if level == "" {
+			level = string(SeverityWarning)
+		}
+		result := SARIFResult{RuleID: f.RuleID, Level: level, Message: SARIFMessage{Text: f.Message}}
+		if f.File != "" {
+			loc := SARIFPhysicalLocation{ArtifactLocation: SARIFArtifactLocation{URI: filepath.ToSlash(f.File)}}
+			if f.Line > 0 {
+				loc.Region = &SARIFRegion{StartLine: f.Line}
+			}
+			result.Locations = []SARIFLocation{{PhysicalLocation: loc}}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	return &SARIF{Schema: sarifSchema, Version: "2.1.0", Runs: []SARIFRun{run}}
+}
@@ -0,0 +1,160 @@
+package findings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// TextSink prints findings as a table
+type TextSink struct {
+	w io.Writer
+}
+
+// NewTextSink creates a TextSink writing to w, or stdout when w is nil
+func NewTextSink(w io.Writer) *TextSink {
+	if w == nil {
+		w = os.Stdout
+	}
+	return &TextSink{w: w}
+}
+
+// Write prints the report
+func (s *TextSink) Write(report *Report) error {
+	if len(report.Findings) == 0 {
+		_, err := fmt.Fprintf(s.w, "No %s findings\n", report.Analysis)
+		return err
+	}
+
+	tw := tabwriter.NewWriter(s.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LOCATION\tSEVERITY\tRULE\tMESSAGE")
+	for _, f := range report.Findings {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.Location(), f.Severity, f.RuleID, f.Message)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(s.w, "\n%d %s findings\n", len(report.Findings), report.Analysis)
+	return err
+}
+
+// JSONSink writes the report as JSON to Path, or stdout when Path is empty
+type JSONSink struct {
+	Path string
+}
+
+// Write encodes the report
+func (s *JSONSink) Write(report *Report) error {
+	return writeJSON(s.Path, report)
+}
+
+// GitHubSink prints findings as GitHub Actions workflow commands, which
+// show up as annotations on the changed lines of a pull request
+type GitHubSink struct {
+	w io.Writer
+}
+
+// NewGitHubSink creates a GitHubSink writing to w, or stdout when w is nil
+func NewGitHubSink(w io.Writer) *GitHubSink {
+	if w == nil {
+		w = os.Stdout
+	}
+	return &GitHubSink{w: w}
+}
+
+// Write prints one annotation per finding
+func (s *GitHubSink) Write(report *Report) error {
+	for _, f := range report.Findings {
+		level := "warning"
+		switch f.Severity {
+		case SeverityError:
+			level = "error"
+		case SeverityNote:
+			level = "notice"
+		}
+		props := "file=" + escapeGitHubProperty(f.File)
+		if f.Line > 0 {
+			props += fmt.Sprintf(",line=%d", f.Line)
+		}
+		props += ",title=" + escapeGitHubProperty(f.RuleID)
+		if _, err := fmt.Fprintf(s.w, "::%s %s::%s\n", level, props, escapeGitHubData(f.Message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeGitHubData escapes a workflow command message
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeGitHubProperty escapes a workflow command property value
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer(":", "%3A", ",", "%2C").Replace(escapeGitHubData(s))
+}
+
+// webhookTimeout bounds a webhook POST
+const webhookTimeout = 10 * time.Second
+
+// WebhookSink posts the report as JSON to a URL
+type WebhookSink struct {
+	URL    string
+	client *http.Client
+}
+
+// NewWebhookSink creates a WebhookSink posting to url
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{URL: url, client: &http.Client{Timeout: webhookTimeout}}
+}
+
+// Write posts the report; any status other than 2xx is an error
+func (s *WebhookSink) Write(report *Report) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("marshaling report: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting report: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// writeJSON writes v as indented JSON to path, or stdout when path is empty
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling JSON: %w", err)
+	}
+	data = append(data, '\n')
+
+	if path == "" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,417 @@
+// Package fixture generates synthetic source repositories for benchmarking
+// indexing and search. A fixture is made of packages per domain, each file
+// holding documented functions and a class whose bodies call functions of
+// the same file, of other files in the package and of other packages, with
+// test files mixed in, so scanning, extraction, call graph resolution and
+// embedding all see realistic work. The same options and seed always write
+// the same files.
+package fixture
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// Default fixture shape
+const (
+	DefaultFiles     = 100
+	DefaultFunctions = 6
+)
+
+// ManifestFile is the manifest written at the root of a fixture
+const ManifestFile = "fixture.json"
+
+// queriesPerLanguage is the number of search queries listed per language
+const queriesPerLanguage = 10
+
+// testEvery makes every testEvery-th file of a language a test file
+const testEvery = 10
+
+// Options shapes a generated fixture
+type Options struct {
+	// Files is the number of source and test files, shared between the
+	// languages (0 = DefaultFiles)
+	Files int
+	// Languages are the languages written, from Languages() (empty = all)
+	Languages []string
+	// Functions is the number of functions per file (0 = DefaultFunctions)
+	Functions int
+	// Seed picks names and calls; the same seed writes the same fixture
+	Seed int64
+}
+
+// Manifest describes a generated fixture
+type Manifest struct {
+	Seed      int64    `json:"seed"`
+	Languages []string `json:"languages"`
+	// Files is the number of source and test files written
+	Files int `json:"files"`
+	// Units is the number of functions, classes and methods written
+	Units int `json:"units"`
+	// Lines is the number of lines of the source and test files
+	Lines int `json:"lines"`
+	// Queries are searches with the unit each one describes, for
+	// measuring search quality on the fixture
+	Queries []Query `json:"queries"`
+}
+
+// Query is a search and the unit it should find
+type Query struct {
+	Query string `json:"query"`
+	// Unit is the URI of the described unit
+	Unit string `json:"unit"`
+}
+
+// Word lists names and doc comments are made from
+var (
+	domains    = []string{"billing", "accounts", "inventory", "shipping", "reports", "search", "notify", "storage"}
+	verbs      = []string{"load", "save", "validate", "parse", "render", "compute", "fetch", "send", "merge", "archive", "resolve", "sync", "export", "import", "schedule", "cancel"}
+	adjectives = []string{"pending", "archived", "cached", "remote", "daily", "expired", "draft", "shared", "primary", "batched", "signed", "stale"}
+	nouns      = []string{"invoice", "customer", "token", "session", "record", "query", "config", "report", "order", "payment", "address", "shipment", "ledger", "webhook", "quota", "template"}
+	kinds      = []string{"Store", "Service", "Cache", "Client", "Handler", "Registry"}
+)
+
+// Languages returns the languages a fixture can be written in
+func Languages() []string {
+	return []string{"go", "python", "typescript"}
+}
+
+// Generate writes a fixture into dir, creating it if needed, and returns
+// its manifest, also saved as ManifestFile
+func Generate(dir string, opts Options) (*Manifest, error) {
+	if opts.Files <= 0 {
+		opts.Files = DefaultFiles
+	}
+	if opts.Functions <= 0 {
+		opts.Functions = DefaultFunctions
+	}
+	if len(opts.Languages) == 0 {
+		opts.Languages = Languages()
+	}
+	for _, lang := range opts.Languages {
+		if !slices.Contains(Languages(), lang) {
+			return nil, fmt.Errorf("unsupported fixture language %q (use %s)", lang, strings.Join(Languages(), ", "))
+		}
+	}
+
+	manifest := &Manifest{Seed: opts.Seed, Languages: opts.Languages}
+	rng := rand.New(rand.NewSource(opts.Seed))
+	for i, lang := range opts.Languages {
+		// Spread the files evenly, the first languages taking the remainder
+		files := opts.Files / len(opts.Languages)
+		if i < opts.Files%len(opts.Languages) {
+			files++
+		}
+		g := &generator{
+			root:      dir,
+			lang:      lang,
+			functions: opts.Functions,
+			rng:       rng,
+			used:      make(map[string]bool),
+			manifest:  manifest,
+		}
+		if err := g.generate(files); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshaling manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestFile), append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("writing manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// function is a generated function other files can call
+type function struct {
+	name   string
+	module string // file path relative to the language directory, without extension
+	domain int
+	query  string
+	file   string // file path relative to the fixture root
+}
+
+// generator writes the files of one language
+type generator struct {
+	root      string
+	lang      string
+	functions int
+	rng       *rand.Rand
+	// used holds the names taken, unique across the language so imports
+	// never clash
+	used     map[string]bool
+	defined  []function
+	manifest *Manifest
+}
+
+// generate writes files source and test files and the project files of the
+// language
+func (g *generator) generate(files int) error {
+	dir := filepath.Join(g.root, g.lang)
+	switch g.lang {
+	case "go":
+		if err := writeFile(filepath.Join(dir, "go.mod"), "module example.com/fixture\n\ngo 1.21\n"); err != nil {
+			return err
+		}
+	case "python":
+		// The language directory is a package too, for imports between
+		// its packages
+		if err := writeFile(filepath.Join(dir, "__init__.py"), ""); err != nil {
+			return err
+		}
+	case "typescript":
+		if err := writeFile(filepath.Join(dir, "package.json"), "{\n  \"name\": \"fixture\",\n  \"private\": true\n}\n"); err != nil {
+			return err
+		}
+	}
+
+	start := len(g.manifest.Queries)
+	for i := 0; i < files; i++ {
+		domain := i % len(domains)
+		var err error
+		if i%testEvery == testEvery-1 && g.hasFunctions(domain) {
+			err = g.writeTest(domain, i)
+		} else {
+			err = g.writeSource(domain, i)
+		}
+		if err != nil {
+			return err
+		}
+		g.manifest.Files++
+	}
+
+	// Spread the queries over the language's functions
+	if n := len(g.defined); n > 0 {
+		step := max(n/queriesPerLanguage, 1)
+		for i := 0; i < n && len(g.manifest.Queries)-start < queriesPerLanguage; i += step {
+			f := g.defined[i]
+			g.manifest.Queries = append(g.manifest.Queries, Query{
+				Query: f.query,
+				Unit:  types.NewUnitURI(g.lang, f.file, f.name).String(),
+			})
+		}
+	}
+	return nil
+}
+
+// hasFunctions reports whether domain already has functions to test
+func (g *generator) hasFunctions(domain int) bool {
+	return slices.ContainsFunc(g.defined, func(f function) bool { return f.domain == domain })
+}
+
+// writeSource writes the i-th file of the language, in package domain
+func (g *generator) writeSource(domain, i int) error {
+	pkg := domains[domain]
+	noun := nouns[g.rng.Intn(len(nouns))]
+	module := fmt.Sprintf("%s/%s_%d", pkg, noun, i)
+	file := g.relPath(module, false)
+
+	// Pick the names first, so functions can call later ones of the file
+	local := make([]function, g.functions)
+	for j := range local {
+		verb, adjective, object := verbs[g.rng.Intn(len(verbs))], adjectives[g.rng.Intn(len(adjectives))], nouns[g.rng.Intn(len(nouns))]
+		local[j] = function{
+			name:   g.uniqueName(g.funcName(verb, adjective, plural(object))),
+			module: module,
+			domain: domain,
+			query:  fmt.Sprintf("%s %s %s in %s", verb, adjective, plural(object), pkg),
+			file:   file,
+		}
+	}
+	class := g.uniqueName(title(noun) + kinds[g.rng.Intn(len(kinds))])
+	// Method names are unique to the language, like function names
+	methods := [2]string{
+		g.uniqueName(g.funcName(verbs[g.rng.Intn(len(verbs))], noun)),
+		g.uniqueName(g.funcName("flush", plural(noun))),
+	}
+
+	w := &writer{lang: g.lang, pkg: pkg, module: module}
+	var bodies strings.Builder
+	for j, f := range local {
+		// Call later functions of the file, and earlier functions of this
+		// package or of packages before it, so packages never import each
+		// other in a cycle
+		var callees []function
+		for range g.rng.Intn(3) + 1 {
+			if j+1 < len(local) && g.rng.Intn(2) == 0 {
+				callees = append(callees, local[j+1+g.rng.Intn(len(local)-j-1)])
+			} else if callee, ok := g.pickCallee(domain); ok {
+				callees = append(callees, callee)
+			}
+		}
+		bodies.WriteString(w.function(f, callees, g.doc(f.query)))
+	}
+	bodies.WriteString(w.class(class, methods, noun, local[0], local[len(local)-1]))
+
+	source := w.header() + bodies.String()
+	g.defined = append(g.defined, local...)
+	// The class and its methods, with a constructor outside Go
+	g.manifest.Units += len(local) + 3
+	if g.lang != "go" {
+		g.manifest.Units++
+	}
+	return g.write(file, source)
+}
+
+// writeTest writes the i-th file of the language as a test of functions of
+// package domain
+func (g *generator) writeTest(domain, i int) error {
+	var candidates []function
+	for _, f := range g.defined {
+		if f.domain == domain {
+			candidates = append(candidates, f)
+		}
+	}
+	target := candidates[g.rng.Intn(len(candidates))]
+	module := fmt.Sprintf("%s/%s_%d", domains[domain], strings.ToLower(target.name), i)
+	file := g.relPath(module, true)
+
+	tested := []function{target}
+	for _, f := range candidates {
+		if len(tested) < 3 && f.module == target.module && f.name != target.name {
+			tested = append(tested, f)
+		}
+	}
+	names := make([]string, len(tested))
+	for j, f := range tested {
+		switch g.lang {
+		case "go":
+			names[j] = g.uniqueName("Test" + f.name)
+		case "python":
+			names[j] = g.uniqueName("test_" + f.name)
+		default:
+			names[j] = g.uniqueName("test" + title(f.name))
+		}
+	}
+	w := &writer{lang: g.lang, pkg: domains[domain], module: module}
+	g.manifest.Units += len(tested)
+	return g.write(file, w.test(tested, names))
+}
+
+// pickCallee returns a random defined function of domain or of an earlier
+// domain
+func (g *generator) pickCallee(domain int) (function, bool) {
+	if len(g.defined) == 0 {
+		return function{}, false
+	}
+	for range 4 {
+		f := g.defined[g.rng.Intn(len(g.defined))]
+		if f.domain <= domain {
+			return f, true
+		}
+	}
+	return function{}, false
+}
+
+// uniqueName returns name, numbered when the language already defines it
+func (g *generator) uniqueName(name string) string {
+	unique := name
+	for n := 2; g.used[unique]; n++ {
+		unique = fmt.Sprintf("%s%d", name, n)
+	}
+	g.used[unique] = true
+	return unique
+}
+
+// funcName joins words the way the language names functions
+func (g *generator) funcName(words ...string) string {
+	switch g.lang {
+	case "python":
+		return strings.Join(words, "_")
+	case "go":
+		return title(words[0]) + titleAll(words[1:])
+	default:
+		return words[0] + titleAll(words[1:])
+	}
+}
+
+// doc turns a query into the doc comment sentence of its function
+func (g *generator) doc(query string) string {
+	verb, rest, _ := strings.Cut(query, " ")
+	return fmt.Sprintf("%s %s for the record id, keeping at most limit entries.", plural(verb), rest)
+}
+
+// relPath returns the path of module, relative to the fixture root
+func (g *generator) relPath(module string, test bool) string {
+	base := g.lang + "/" + module
+	switch {
+	case g.lang == "go" && test:
+		return base + "_test.go"
+	case g.lang == "go":
+		return base + ".go"
+	case g.lang == "python" && test:
+		dir, name := filepath.Split(base)
+		return dir + "test_" + name + ".py"
+	case g.lang == "python":
+		return base + ".py"
+	case test:
+		return base + ".test.ts"
+	default:
+		return base + ".ts"
+	}
+}
+
+// write saves a file of the fixture, adding Python package markers
+func (g *generator) write(file, source string) error {
+	path := filepath.Join(g.root, filepath.FromSlash(file))
+	if g.lang == "python" {
+		if err := writeFile(filepath.Join(filepath.Dir(path), "__init__.py"), ""); err != nil {
+			return err
+		}
+	}
+	g.manifest.Lines += strings.Count(source, "\n")
+	return writeFile(path, source)
+}
+
+// writeFile writes content to path, creating its directory
+func writeFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// plural adds the -s or -es of an English plural noun or third person verb
+func plural(word string) string {
+	switch {
+	case strings.HasSuffix(word, "y"):
+		return strings.TrimSuffix(word, "y") + "ies"
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	default:
+		return word + "s"
+	}
+}
+
+// title upper-cases the first letter of word
+func title(word string) string {
+	if word == "" {
+		return word
+	}
+	r := []rune(word)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// titleAll joins words with their first letters upper-cased
+func titleAll(words []string) string {
+	var sb strings.Builder
+	for _, w := range words {
+		sb.WriteString(title(w))
+	}
+	return sb.String()
+}
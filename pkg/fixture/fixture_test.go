@@ -0,0 +1,120 @@
+package fixture
+
+import (
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readTree returns the files under dir by relative path
+func readTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		files[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestGenerate(t *testing.T) {
+	opts := Options{Files: 45, Seed: 7}
+	dir := t.TempDir()
+	manifest, err := Generate(dir, opts)
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	files := readTree(t, dir)
+
+	var sources, tests int
+	for name, content := range files {
+		switch {
+		case strings.HasSuffix(name, "_test.go"), strings.HasSuffix(name, ".test.ts"), strings.HasPrefix(filepath.Base(name), "test_"):
+			tests++
+		case strings.HasSuffix(name, ".go"), strings.HasSuffix(name, ".ts"), strings.HasSuffix(name, ".py") && content != "":
+			sources++
+		}
+		if strings.HasSuffix(name, ".go") {
+			if _, err := parser.ParseFile(token.NewFileSet(), name, content, 0); err != nil {
+				t.Errorf("%s does not parse: %v", name, err)
+			}
+		}
+	}
+	if manifest.Files != 45 || sources+tests != 45 {
+		t.Errorf("Files = %d, %d source and %d test files written, want 45", manifest.Files, sources, tests)
+	}
+	if tests == 0 {
+		t.Error("no test files written")
+	}
+	if manifest.Units <= manifest.Files || manifest.Lines == 0 {
+		t.Errorf("Units, Lines = %d, %d", manifest.Units, manifest.Lines)
+	}
+	if len(manifest.Queries) != 3*queriesPerLanguage {
+		t.Errorf("%d queries, want %d", len(manifest.Queries), 3*queriesPerLanguage)
+	}
+	for _, name := range []string{ManifestFile, "go/go.mod", "python/__init__.py", "typescript/package.json"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("%s not written", name)
+		}
+	}
+
+	// The same seed writes the same fixture, another seed another one
+	again := t.TempDir()
+	if _, err := Generate(again, opts); err != nil {
+		t.Fatal(err)
+	}
+	same := readTree(t, again)
+	if len(same) != len(files) {
+		t.Fatalf("regenerated %d files, want %d", len(same), len(files))
+	}
+	for name, content := range files {
+		if same[name] != content {
+			t.Errorf("%s differs between runs with the same seed", name)
+		}
+	}
+	opts.Seed = 8
+	other := t.TempDir()
+	if _, err := Generate(other, opts); err != nil {
+		t.Fatal(err)
+	}
+	if readTree(t, other)[ManifestFile] == files[ManifestFile] {
+		t.Error("another seed wrote the same fixture")
+	}
+}
+
+func TestGenerateLanguages(t *testing.T) {
+	dir := t.TempDir()
+	manifest, err := Generate(dir, Options{Files: 10, Languages: []string{"python"}, Functions: 2})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	for name := range readTree(t, dir) {
+		if name != ManifestFile && !strings.HasPrefix(name, "python/") {
+			t.Errorf("wrote %s for a python fixture", name)
+		}
+	}
+	for _, q := range manifest.Queries {
+		if !strings.HasPrefix(q.Unit, "py://python/") {
+			t.Errorf("query unit %s is not a python unit", q.Unit)
+		}
+	}
+
+	if _, err := Generate(t.TempDir(), Options{Languages: []string{"cobol"}}); err == nil {
+		t.Error("Generate accepted an unsupported language")
+	}
+}
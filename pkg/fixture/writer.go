@@ -0,0 +1,185 @@
+package fixture
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// writer renders the source of one fixture file. Functions of every
+// language share one signature, taking a record id and a limit and
+// returning the id, so any function can call any other.
+type writer struct {
+	lang   string
+	pkg    string
+	module string
+	// imports are the import lines the file needs, in order of first use
+	imports []string
+}
+
+// header returns the start of the file, with the imports the rendered
+// functions need
+func (w *writer) header() string {
+	var sb strings.Builder
+	switch w.lang {
+	case "go":
+		fmt.Fprintf(&sb, "// %s of the %s package, generated by gcq gen-fixture.\n\npackage %s\n\n", path.Base(w.module), w.pkg, w.pkg)
+		if len(w.imports) > 0 {
+			// Standard library first, like goimports
+			var std, local []string
+			for _, imp := range w.imports {
+				if strings.Contains(imp, ".") {
+					local = append(local, imp)
+				} else {
+					std = append(std, imp)
+				}
+			}
+			slices.Sort(std)
+			slices.Sort(local)
+			sb.WriteString("import (\n")
+			for _, imp := range std {
+				fmt.Fprintf(&sb, "\t%s\n", imp)
+			}
+			if len(std) > 0 && len(local) > 0 {
+				sb.WriteString("\n")
+			}
+			for _, imp := range local {
+				fmt.Fprintf(&sb, "\t%s\n", imp)
+			}
+			sb.WriteString(")\n\n")
+		}
+	case "python":
+		fmt.Fprintf(&sb, "\"\"\"%s of the %s package, generated by gcq gen-fixture.\"\"\"\n\n", path.Base(w.module), w.pkg)
+		for _, imp := range w.imports {
+			sb.WriteString(imp + "\n")
+		}
+		if len(w.imports) > 0 {
+			sb.WriteString("\n\n")
+		}
+	default:
+		fmt.Fprintf(&sb, "// %s of the %s package, generated by gcq gen-fixture.\n\n", path.Base(w.module), w.pkg)
+		for _, imp := range w.imports {
+			sb.WriteString(imp + "\n")
+		}
+		if len(w.imports) > 0 {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// function renders f, calling callees in turn
+func (w *writer) function(f function, callees []function, doc string) string {
+	var sb strings.Builder
+	switch w.lang {
+	case "go":
+		fmt.Fprintf(&sb, "// %s %s\nfunc %s(id string, limit int) (string, error) {\n\tresult := id\n", f.name, doc, f.name)
+		if len(callees) > 0 {
+			w.addImport(`"fmt"`)
+			sb.WriteString("\tvar err error\n")
+		}
+		for _, c := range callees {
+			fmt.Fprintf(&sb, "\tif result, err = %s(result, limit); err != nil {\n\t\treturn \"\", fmt.Errorf(\"%s: %%w\", err)\n\t}\n", w.ref(c), f.name)
+		}
+		sb.WriteString("\tif limit > 0 && len(result) > limit {\n\t\tresult = result[:limit]\n\t}\n\treturn result, nil\n}\n\n")
+	case "python":
+		fmt.Fprintf(&sb, "def %s(record_id, limit):\n    \"\"\"%s\"\"\"\n    result = record_id\n", f.name, title(doc))
+		for _, c := range callees {
+			fmt.Fprintf(&sb, "    result = %s(result, limit)\n", w.ref(c))
+		}
+		sb.WriteString("    if limit > 0:\n        result = result[:limit]\n    return result\n\n\n")
+	default:
+		fmt.Fprintf(&sb, "/** %s */\nexport function %s(id: string, limit: number): string {\n  let result = id;\n", title(doc), f.name)
+		for _, c := range callees {
+			fmt.Fprintf(&sb, "  result = %s(result, limit);\n", w.ref(c))
+		}
+		sb.WriteString("  return limit > 0 ? result.slice(0, limit) : result;\n}\n\n")
+	}
+	return sb.String()
+}
+
+// class renders a class named name with two methods, the first calling
+// first and the second calling last, both functions of the file
+func (w *writer) class(name string, methods [2]string, noun string, first, last function) string {
+	var sb strings.Builder
+	switch w.lang {
+	case "go":
+		fmt.Fprintf(&sb, "// %s keeps %s records of the %s package.\ntype %s struct {\n\tprefix string\n\tlimit  int\n}\n\n", name, noun, w.pkg, name)
+		fmt.Fprintf(&sb, "// %s processes the %s with the given id.\nfunc (s *%s) %s(id string) (string, error) {\n\treturn %s(s.prefix+id, s.limit)\n}\n\n", methods[0], noun, name, methods[0], first.name)
+		fmt.Fprintf(&sb, "// %s writes the pending %s records.\nfunc (s *%s) %s() error {\n\t_, err := %s(s.prefix, s.limit)\n\treturn err\n}\n", methods[1], noun, name, methods[1], last.name)
+	case "python":
+		fmt.Fprintf(&sb, "class %s:\n    \"\"\"Keeps %s records of the %s package.\"\"\"\n\n", name, noun, w.pkg)
+		sb.WriteString("    def __init__(self, prefix, limit):\n        self.prefix = prefix\n        self.limit = limit\n\n")
+		fmt.Fprintf(&sb, "    def %s(self, record_id):\n        \"\"\"Processes the %s with the given id.\"\"\"\n        return %s(self.prefix + record_id, self.limit)\n\n", methods[0], noun, first.name)
+		fmt.Fprintf(&sb, "    def %s(self):\n        \"\"\"Writes the pending %s records.\"\"\"\n        %s(self.prefix, self.limit)\n", methods[1], noun, last.name)
+	default:
+		fmt.Fprintf(&sb, "/** Keeps %s records of the %s package. */\nexport class %s {\n  constructor(private prefix: string, private limit: number) {}\n\n", noun, w.pkg, name)
+		fmt.Fprintf(&sb, "  /** Processes the %s with the given id. */\n  %s(id: string): string {\n    return %s(this.prefix + id, this.limit);\n  }\n\n", noun, methods[0], first.name)
+		fmt.Fprintf(&sb, "  /** Writes the pending %s records. */\n  %s(): void {\n    %s(this.prefix, this.limit);\n  }\n}\n", noun, methods[1], last.name)
+	}
+	return sb.String()
+}
+
+// test renders a test file with one test, named from names, per function
+// of tested
+func (w *writer) test(tested []function, names []string) string {
+	var body strings.Builder
+	switch w.lang {
+	case "go":
+		w.addImport(`"testing"`)
+		for i, f := range tested {
+			fmt.Fprintf(&body, "func %s(t *testing.T) {\n\tgot, err := %s(\"record-1\", 4)\n\tif err != nil {\n\t\tt.Fatal(err)\n\t}\n\tif len(got) > 4 {\n\t\tt.Errorf(\"%s() = %%q, want at most 4 bytes\", got)\n\t}\n}\n\n", names[i], w.ref(f), f.name)
+		}
+	case "python":
+		for i, f := range tested {
+			fmt.Fprintf(&body, "def %s():\n    assert len(%s(\"record-1\", 4)) <= 4\n\n\n", names[i], w.ref(f))
+		}
+	default:
+		for i, f := range tested {
+			fmt.Fprintf(&body, "export function %s(): void {\n  if (%s(\"record-1\", 4).length > 4) {\n    throw new Error(\"%s kept too many entries\");\n  }\n}\n\n", names[i], w.ref(f), f.name)
+		}
+	}
+	return strings.TrimRight(w.header()+body.String(), "\n") + "\n"
+}
+
+// ref returns how the file refers to f, adding the import it needs
+func (w *writer) ref(f function) string {
+	switch w.lang {
+	case "go":
+		if f.module == w.module || path.Dir(f.module) == w.pkg {
+			return f.name
+		}
+		pkg := path.Dir(f.module)
+		w.addImport(fmt.Sprintf("%q", "example.com/fixture/"+pkg))
+		return pkg + "." + f.name
+	case "python":
+		if f.module == w.module {
+			return f.name
+		}
+		// Relative imports keep the fixture importable wherever it lives
+		from := "." + path.Base(f.module)
+		if dir := path.Dir(f.module); dir != w.pkg {
+			from = ".." + dir + "." + path.Base(f.module)
+		}
+		w.addImport(fmt.Sprintf("from %s import %s", from, f.name))
+		return f.name
+	default:
+		if f.module == w.module {
+			return f.name
+		}
+		from := "./" + path.Base(f.module)
+		if dir := path.Dir(f.module); dir != w.pkg {
+			from = "../" + f.module
+		}
+		w.addImport(fmt.Sprintf("import { %s } from %q;", f.name, from))
+		return f.name
+	}
+}
+
+// addImport adds an import line once
+func (w *writer) addImport(line string) {
+	if !slices.Contains(w.imports, line) {
+		w.imports = append(w.imports, line)
+	}
+}
@@ -0,0 +1,345 @@
+// Package hierarchy builds the inheritance graph of a project: Python and
+// TypeScript base classes, extended and implemented interfaces, and Go
+// struct and interface embedding. Like package symbols it works from
+// extraction alone, so it needs no embeddings or semantic index.
+package hierarchy
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/l3aro/go-context-query/internal/scanner"
+	"github.com/l3aro/go-context-query/pkg/extractor"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// Kinds of Type
+const (
+	KindClass     = "class"
+	KindInterface = "interface"
+	KindStruct    = "struct"
+	// KindExternal is a base named in the project but defined outside
+	// it, such as Exception or io.Reader
+	KindExternal = "external"
+)
+
+// Type is a class, interface or struct in the graph
+type Type struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"`
+	Language string `json:"language,omitempty"`
+	// File is relative to the project root; it is empty for external
+	// types
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+	// Bases are the types it extends, implements or embeds, as written,
+	// in declaration order
+	Bases []string `json:"bases,omitempty"`
+	// Methods are the methods the extractor attributes to the type. Go
+	// methods are declared outside their type and are not listed.
+	Methods []string `json:"methods,omitempty"`
+}
+
+// Relative is an ancestor or descendant of a type
+type Relative struct {
+	Type
+	// Depth is 1 for direct bases or subtypes, 2 for theirs and so on
+	Depth int `json:"depth"`
+}
+
+// FromModule returns the types of a module extracted from file
+func FromModule(file string, m *types.ModuleInfo) []Type {
+	var out []Type
+	// Go reports interfaces and structs both as classes and on their own;
+	// they are merged by name and line
+	seen := make(map[string]int)
+	add := func(t Type) *Type {
+		key := fmt.Sprintf("%s:%d", t.Name, t.Line)
+		if i, ok := seen[key]; ok {
+			return &out[i]
+		}
+		seen[key] = len(out)
+		out = append(out, t)
+		return &out[len(out)-1]
+	}
+
+	for _, cls := range m.Classes {
+		if cls.Name == "" {
+			continue
+		}
+		t := add(Type{Name: cls.Name, Kind: KindClass, Language: m.Language, File: file, Line: cls.LineNumber})
+		for _, base := range cls.Bases {
+			// Python keyword arguments such as metaclass=ABCMeta are not bases
+			if !strings.Contains(base, "=") {
+				t.Bases = append(t.Bases, base)
+			}
+		}
+		for _, f := range cls.Fields {
+			if f.Embedded {
+				t.Bases = append(t.Bases, f.Type)
+			}
+		}
+		t.Methods = methodNames(cls.Methods)
+	}
+	for _, iface := range m.Interfaces {
+		t := add(Type{Name: iface.Name, Language: m.Language, File: file, Line: iface.LineNumber})
+		t.Kind = KindInterface
+		t.Bases, t.Methods = iface.Bases, nil
+		for _, method := range iface.Methods {
+			switch {
+			case m.Language != "go" || method.Params != "":
+				t.Methods = append(t.Methods, method.Name)
+			case !strings.ContainsAny(method.Name, "|~ "):
+				// A Go interface lists an embedded interface as a method
+				// without parameters; a constraint's type set is skipped
+				t.Bases = append(t.Bases, method.Name)
+			}
+		}
+	}
+	for _, st := range m.Structs {
+		add(Type{Name: st.Name, Language: m.Language, File: file, Line: st.LineNumber}).Kind = KindStruct
+	}
+	return out
+}
+
+// methodNames returns the names of methods
+func methodNames(methods []types.Method) []string {
+	var names []string
+	for _, m := range methods {
+		if m.Name != "" {
+			names = append(names, m.Name)
+		}
+	}
+	return names
+}
+
+// Collect extracts the types of every supported file under rootDir. Files
+// that fail to parse are skipped.
+func Collect(rootDir string) ([]Type, error) {
+	files, err := scanner.New(scanner.DefaultOptions()).Scan(rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("scanning %s: %w", rootDir, err)
+	}
+
+	var out []Type
+	for _, f := range files {
+		if f.Language == "" {
+			continue
+		}
+		moduleInfo, err := extractor.ExtractFile(f.FullPath)
+		if err != nil {
+			continue
+		}
+		// Not every extractor names its language
+		if moduleInfo.Language == "" {
+			moduleInfo.Language = f.Language
+		}
+		out = append(out, FromModule(filepath.ToSlash(f.Path), moduleInfo)...)
+	}
+	return out, nil
+}
+
+// Build collects the types under rootDir and links them into a graph
+func Build(rootDir string) (*Graph, error) {
+	ts, err := Collect(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	return New(ts), nil
+}
+
+// BaseName returns the bare type name of a base as written: without
+// pointer, package or module qualifiers and type arguments, so
+// "*pkg.Store[K, V]" becomes "Store"
+func BaseName(base string) string {
+	base = qualifiedName(base)
+	if i := strings.LastIndexAny(base, `.:\`); i >= 0 {
+		base = base[i+1:]
+	}
+	return base
+}
+
+// qualifiedName returns base without pointer and type arguments, so
+// "*pkg.Store[K, V]" becomes "pkg.Store"
+func qualifiedName(base string) string {
+	base = strings.TrimSpace(strings.TrimLeft(base, "*&"))
+	if i := strings.IndexAny(base, "[<("); i >= 0 {
+		base = base[:i]
+	}
+	return strings.TrimSpace(base)
+}
+
+// family groups languages whose types can extend each other
+func family(lang string) string {
+	switch lang {
+	case "javascript", "typescript", "tsx", "vue", "svelte":
+		return "js"
+	}
+	return lang
+}
+
+// Graph links types to their bases and subtypes
+type Graph struct {
+	types    []*Type
+	byName   map[string][]*Type
+	bases    map[*Type][]*Type
+	children map[*Type][]*Type
+	external map[string]*Type
+}
+
+// New links ts into a graph. A base is resolved to a type of the same name
+// in the same language, preferring the same file and then the same
+// directory; a base defined outside the project becomes an external type.
+func New(ts []Type) *Graph {
+	g := &Graph{
+		byName:   make(map[string][]*Type),
+		bases:    make(map[*Type][]*Type),
+		children: make(map[*Type][]*Type),
+		external: make(map[string]*Type),
+	}
+	for i := range ts {
+		t := ts[i]
+		g.types = append(g.types, &t)
+		g.byName[t.Name] = append(g.byName[t.Name], &t)
+	}
+	for _, candidates := range g.byName {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			if candidates[i].File != candidates[j].File {
+				return candidates[i].File < candidates[j].File
+			}
+			return candidates[i].Line < candidates[j].Line
+		})
+	}
+
+	for _, t := range g.types {
+		for _, base := range t.Bases {
+			parent := g.resolve(t, base)
+			if parent == nil {
+				continue
+			}
+			g.bases[t] = append(g.bases[t], parent)
+			g.children[parent] = append(g.children[parent], t)
+		}
+	}
+	return g
+}
+
+// resolve returns the type that base names from t
+func (g *Graph) resolve(t *Type, base string) *Type {
+	name := BaseName(base)
+	if name == "" {
+		return nil
+	}
+
+	var best *Type
+	bestScore := -1
+	for _, c := range g.byName[name] {
+		// A type never extends itself, so in type Reader interface {
+		// io.Reader } the base is another Reader
+		if c == t || c.Kind == KindExternal || family(c.Language) != family(t.Language) {
+			continue
+		}
+		score := 0
+		switch {
+		case c.File == t.File:
+			score = 2
+		case path.Dir(c.File) == path.Dir(t.File):
+			score = 1
+		}
+		if score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	if best != nil {
+		return best
+	}
+
+	// External types keep their qualifier: io.Reader, not Reader
+	key := family(t.Language) + ":" + qualifiedName(base)
+	ext, ok := g.external[key]
+	if !ok {
+		ext = &Type{Name: qualifiedName(base), Kind: KindExternal, Language: t.Language}
+		g.external[key] = ext
+		g.byName[name] = append(g.byName[name], ext)
+	}
+	return ext
+}
+
+// Types returns the project's types in the order they were given to New
+func (g *Graph) Types() []*Type {
+	return g.types
+}
+
+// Find returns the types named name, project types first by file and
+// line. A qualified name such as "models.User" is matched by its last
+// part. External types are returned when no project type matches or when
+// name is their qualified name, such as "io.Reader".
+func (g *Graph) Find(name string) []*Type {
+	var found, external []*Type
+	for _, t := range g.byName[BaseName(name)] {
+		if t.Kind != KindExternal {
+			found = append(found, t)
+		} else {
+			external = append(external, t)
+		}
+	}
+	for _, t := range external {
+		if len(found) == 0 || t.Name == name {
+			found = append(found, t)
+		}
+	}
+	return found
+}
+
+// Bases returns the types t directly extends, implements or embeds
+func (g *Graph) Bases(t *Type) []*Type {
+	return g.bases[t]
+}
+
+// Subtypes returns the types that directly extend, implement or embed t
+func (g *Graph) Subtypes(t *Type) []*Type {
+	return g.children[t]
+}
+
+// Ancestors returns every type t inherits from, breadth first. A type
+// reached along several paths is listed once, at its smallest depth.
+func (g *Graph) Ancestors(t *Type) []Relative {
+	var out []Relative
+	g.walk(t, g.bases, func(n *Type, depth int) {
+		out = append(out, Relative{Type: *n, Depth: depth})
+	})
+	return out
+}
+
+// Descendants returns every type inheriting from t, breadth first
+func (g *Graph) Descendants(t *Type) []Relative {
+	var out []Relative
+	g.walk(t, g.children, func(n *Type, depth int) {
+		out = append(out, Relative{Type: *n, Depth: depth})
+	})
+	return out
+}
+
+// walk calls visit for each type reachable from t along edges, breadth
+// first
+func (g *Graph) walk(t *Type, edges map[*Type][]*Type, visit func(n *Type, depth int)) {
+	seen := map[*Type]bool{t: true}
+	level := []*Type{t}
+	for depth := 1; len(level) > 0; depth++ {
+		var next []*Type
+		for _, n := range level {
+			for _, m := range edges[n] {
+				if seen[m] {
+					continue
+				}
+				seen[m] = true
+				visit(m, depth)
+				next = append(next, m)
+			}
+		}
+		level = next
+	}
+}
@@ -0,0 +1,222 @@
+package hierarchy
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+func relativeNames(rs []Relative) []string {
+	var names []string
+	for _, r := range rs {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+func typeNames(ts []*Type) []string {
+	var names []string
+	for _, t := range ts {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// pyGraph builds a Python graph from name: bases pairs
+func pyGraph(classes map[string][]string) *Graph {
+	var ts []Type
+	for name, bases := range classes {
+		ts = append(ts, Type{Name: name, Kind: KindClass, Language: "python", File: "m.py", Bases: bases})
+	}
+	return New(ts)
+}
+
+func TestBaseName(t *testing.T) {
+	tests := map[string]string{
+		"Base":              "Base",
+		"mod.Base":          "Base",
+		"Generic[T]":        "Generic",
+		"*pkg.Store[K, V]":  "Store",
+		"Repository<User>":  "Repository",
+		`App\Models\Model`:  "Model",
+		"std::error::Error": "Error",
+	}
+	for base, want := range tests {
+		if got := BaseName(base); got != want {
+			t.Errorf("BaseName(%q) = %q, want %q", base, got, want)
+		}
+	}
+}
+
+func TestFromModuleGo(t *testing.T) {
+	m := &types.ModuleInfo{
+		Language: "go",
+		Classes: []types.Class{
+			{Name: "Store", LineNumber: 3, Fields: []types.Field{{Name: "Base", Type: "*Base", Embedded: true}, {Name: "n", Type: "int"}}},
+			{Name: "ReadCloser", LineNumber: 9},
+		},
+		Interfaces: []types.Interface{{Name: "ReadCloser", LineNumber: 9, Methods: []types.Method{
+			{Name: "io.Reader"},
+			{Name: "Close", Params: "()"},
+		}}},
+		Structs: []types.Struct{{Name: "Store", LineNumber: 3}},
+	}
+	got := FromModule("s.go", m)
+	want := []Type{
+		{Name: "Store", Kind: KindStruct, Language: "go", File: "s.go", Line: 3, Bases: []string{"*Base"}},
+		{Name: "ReadCloser", Kind: KindInterface, Language: "go", File: "s.go", Line: 9, Bases: []string{"io.Reader"}, Methods: []string{"Close"}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("FromModule = %+v", got)
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || got[i].Kind != want[i].Kind || !slices.Equal(got[i].Bases, want[i].Bases) || !slices.Equal(got[i].Methods, want[i].Methods) {
+			t.Errorf("FromModule[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestAncestorsDescendants(t *testing.T) {
+	g := pyGraph(map[string][]string{
+		"Animal": {"Base"},
+		"Dog":    {"Animal"},
+		"Puppy":  {"Dog"},
+		"Cat":    {"Animal", "mixins.Pet"},
+	})
+	dog := g.Find("Dog")[0]
+	if got := relativeNames(g.Ancestors(dog)); !slices.Equal(got, []string{"Animal", "Base"}) {
+		t.Errorf("Ancestors(Dog) = %v", got)
+	}
+	if anc := g.Ancestors(dog); anc[1].Kind != KindExternal || anc[1].Depth != 2 {
+		t.Errorf("Base = %+v, want an external type at depth 2", anc[1])
+	}
+
+	animal := g.Find("Animal")[0]
+	got := relativeNames(g.Descendants(animal))
+	slices.Sort(got[:2])
+	if !slices.Equal(got, []string{"Cat", "Dog", "Puppy"}) {
+		t.Errorf("Descendants(Animal) = %v", got)
+	}
+
+	pets := g.Find("Pet")
+	if len(pets) != 1 || pets[0].Kind != KindExternal || len(g.Subtypes(pets[0])) != 1 {
+		t.Errorf("external Pet = %+v", pets)
+	}
+}
+
+func TestMROPython(t *testing.T) {
+	// The diamond from the Python documentation on C3
+	g := pyGraph(map[string][]string{
+		"O": nil,
+		"F": {"O"},
+		"E": {"O"},
+		"D": {"O"},
+		"C": {"D", "F"},
+		"B": {"D", "E"},
+		"A": {"B", "C"},
+	})
+	order, err := g.MRO(g.Find("A")[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := typeNames(order); !slices.Equal(got, []string{"A", "B", "C", "D", "E", "F", "O"}) {
+		t.Errorf("MRO(A) = %v", got)
+	}
+
+	g = pyGraph(map[string][]string{
+		"X": nil,
+		"Y": {"X"},
+		"Z": {"X", "Y"},
+	})
+	if _, err := g.MRO(g.Find("Z")[0]); err == nil || !strings.Contains(err.Error(), "consistent") {
+		t.Errorf("MRO(Z) error = %v, want an inconsistent order", err)
+	}
+}
+
+func TestMROOrder(t *testing.T) {
+	// A embeds B and C; B embeds D. Go finds C's methods before D's, a
+	// depth first language finds D's first.
+	for lang, want := range map[string][]string{
+		"go":         {"A", "B", "C", "D"},
+		"typescript": {"A", "B", "D", "C"},
+	} {
+		// run is defined by both C and D; the first of them in the order wins
+		wantOwner := want[2]
+		g := New([]Type{
+			{Name: "A", Kind: KindClass, Language: lang, Bases: []string{"B", "C"}},
+			{Name: "B", Kind: KindClass, Language: lang, Bases: []string{"D"}},
+			{Name: "C", Kind: KindClass, Language: lang, Methods: []string{"run"}},
+			{Name: "D", Kind: KindClass, Language: lang, Methods: []string{"run"}},
+		})
+		a := g.Find("A")[0]
+		order, err := g.MRO(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := typeNames(order); !slices.Equal(got, want) {
+			t.Errorf("%s: MRO(A) = %v, want %v", lang, got, want)
+		}
+		owner, err := g.Resolve(a, "run")
+		if err != nil || owner == nil || owner.Name != wantOwner {
+			t.Errorf("%s: Resolve(A, run) = %+v, %v", lang, owner, err)
+		}
+	}
+}
+
+func TestResolvePrefersSameFile(t *testing.T) {
+	g := New([]Type{
+		{Name: "Base", Kind: KindClass, Language: "python", File: "a/base.py", Line: 1},
+		{Name: "Base", Kind: KindClass, Language: "python", File: "b/models.py", Line: 1},
+		{Name: "User", Kind: KindClass, Language: "python", File: "b/models.py", Line: 5, Bases: []string{"Base"}},
+		{Name: "Base", Kind: KindClass, Language: "go", File: "b/base.go", Line: 1},
+	})
+	bases := g.Bases(g.Find("User")[0])
+	if len(bases) != 1 || bases[0].File != "b/models.py" {
+		t.Errorf("Bases(User) = %+v", bases)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"zoo/animals.py": "class Animal:\n    def speak(self):\n        pass\n\n\nclass Dog(Animal):\n    pass\n",
+		"web/views.ts":   "interface Named { name: string }\nclass View implements Named {}\nclass Page extends View {}\n",
+		"store/store.go": "package store\n\ntype Base struct{}\n\ntype Store struct {\n\t*Base\n\tn int\n}\n",
+	}
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g, err := Build(dir)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	for name, want := range map[string][]string{
+		"Dog":   {"Animal"},
+		"Page":  {"View", "Named"},
+		"Store": {"Base"},
+	} {
+		found := g.Find(name)
+		if len(found) != 1 {
+			t.Fatalf("Find(%s) = %+v", name, found)
+		}
+		if got := relativeNames(g.Ancestors(found[0])); !slices.Equal(got, want) {
+			t.Errorf("Ancestors(%s) = %v, want %v", name, got, want)
+		}
+	}
+
+	owner, err := g.Resolve(g.Find("Dog")[0], "speak")
+	if err != nil || owner == nil || owner.File != "zoo/animals.py" {
+		t.Errorf("Resolve(Dog, speak) = %+v, %v", owner, err)
+	}
+}
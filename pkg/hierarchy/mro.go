@@ -0,0 +1,118 @@
+package hierarchy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MRO returns the method resolution order of t: the types searched, t
+// first, when a method is looked up on it. Python uses the C3
+// linearization and fails, as Python does, on a hierarchy with no
+// consistent order. Go promotes embedded methods by depth, so its order is
+// breadth first. Other languages inherit along their first base before the
+// next, so theirs is depth first.
+func (g *Graph) MRO(t *Type) ([]*Type, error) {
+	switch t.Language {
+	case "python":
+		return g.c3(t, nil)
+	case "go":
+		order := []*Type{t}
+		g.walk(t, g.bases, func(n *Type, _ int) {
+			order = append(order, n)
+		})
+		return order, nil
+	}
+
+	var order []*Type
+	seen := make(map[*Type]bool)
+	var visit func(*Type)
+	visit = func(n *Type) {
+		if seen[n] {
+			return
+		}
+		seen[n] = true
+		order = append(order, n)
+		for _, base := range g.bases[n] {
+			visit(base)
+		}
+	}
+	visit(t)
+	return order, nil
+}
+
+// Resolve returns the first type in t's method resolution order that
+// defines method, or nil when none of them does
+func (g *Graph) Resolve(t *Type, method string) (*Type, error) {
+	order, err := g.MRO(t)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range order {
+		if slices.Contains(n.Methods, method) {
+			return n, nil
+		}
+	}
+	return nil, nil
+}
+
+// c3 returns the C3 linearization of t. visiting holds the types being
+// linearized further down the stack, to report a cycle instead of
+// recursing forever.
+func (g *Graph) c3(t *Type, visiting []*Type) ([]*Type, error) {
+	if slices.Contains(visiting, t) {
+		return nil, fmt.Errorf("inheritance cycle through %s", t.Name)
+	}
+	visiting = append(visiting, t)
+
+	var seqs [][]*Type
+	for _, base := range g.bases[t] {
+		lin, err := g.c3(base, visiting)
+		if err != nil {
+			return nil, err
+		}
+		seqs = append(seqs, lin)
+	}
+	seqs = append(seqs, slices.Clone(g.bases[t]))
+
+	order := []*Type{t}
+	for {
+		seqs = slices.DeleteFunc(seqs, func(s []*Type) bool { return len(s) == 0 })
+		if len(seqs) == 0 {
+			return order, nil
+		}
+
+		// The next type is the first head that is in no other tail
+		var next *Type
+		for _, s := range seqs {
+			if !inTail(seqs, s[0]) {
+				next = s[0]
+				break
+			}
+		}
+		if next == nil {
+			var heads []string
+			for _, s := range seqs {
+				heads = append(heads, s[0].Name)
+			}
+			return nil, fmt.Errorf("cannot create a consistent method resolution order for %s (bases %s)", t.Name, strings.Join(heads, ", "))
+		}
+
+		order = append(order, next)
+		for i, s := range seqs {
+			if s[0] == next {
+				seqs[i] = s[1:]
+			}
+		}
+	}
+}
+
+// inTail reports whether t is in the tail of any of seqs
+func inTail(seqs [][]*Type, t *Type) bool {
+	for _, s := range seqs {
+		if slices.Contains(s[1:], t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,374 @@
+// Package hover builds editor hover payloads from a project's semantic
+// index: for the symbol at a source position it gathers the signature,
+// documentation, type details, complexity and callers, and renders them as
+// Markdown.
+package hover
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/l3aro/go-context-query/pkg/extractor"
+	"github.com/l3aro/go-context-query/pkg/semantic"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// MaxCallers is the number of callers listed in a hover
+const MaxCallers = 5
+
+// TypeInfo holds the type details of a symbol, as far as extraction
+// knows them
+type TypeInfo struct {
+	Params  string `json:"params,omitempty"`
+	Returns string `json:"returns,omitempty"`
+	// Owner is the class, struct or trait a method belongs to
+	Owner      string   `json:"owner,omitempty"`
+	Bases      []string `json:"bases,omitempty"`
+	Decorators []string `json:"decorators,omitempty"`
+	Async      bool     `json:"async,omitempty"`
+}
+
+// Hover describes the symbol at a source position
+type Hover struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	Language  string `json:"language,omitempty"`
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	EndLine   int    `json:"end_line,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	// Docs is the docstring or leading comment, without quotes or comment
+	// markers
+	Docs string   `json:"docs,omitempty"`
+	Type TypeInfo `json:"type"`
+	// Complexity is the cyclomatic complexity, 0 when unknown
+	Complexity  int    `json:"complexity,omitempty"`
+	ControlFlow string `json:"control_flow,omitempty"`
+	// Callers are the first MaxCallers direct callers; CallerCount counts
+	// them all
+	Callers     []semantic.Caller `json:"callers"`
+	CallerCount int               `json:"caller_count"`
+	// Markdown is the hover rendered for display
+	Markdown string `json:"markdown"`
+}
+
+// At returns the hover of the symbol at line and col (both counted from 1,
+// col in characters) of file, relative to rootDir or absolute. An
+// identifier under the cursor that names a unit of the index, such as a
+// call, shows that unit; elsewhere the innermost unit enclosing the line
+// is shown.
+func At(rootDir string, graph *semantic.CallerGraph, file string, line, col int) (*Hover, error) {
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(rootDir, path)
+	}
+	rel, err := filepath.Rel(rootDir, path)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", file, err)
+	}
+	rel = filepath.ToSlash(rel)
+
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", file, err)
+	}
+	lines := strings.Split(string(source), "\n")
+	if line < 1 || line > len(lines) {
+		return nil, fmt.Errorf("line %d is outside %s (%d lines)", line, file, len(lines))
+	}
+
+	unit := lookup(graph, rel, line, identifierAt(lines[line-1], col))
+	if unit == nil {
+		return nil, fmt.Errorf("no indexed symbol at %s:%d:%d", rel, line, col)
+	}
+	return build(rootDir, graph, unit), nil
+}
+
+// lookup returns the unit named ident, preferring its definition on line,
+// then a unit the enclosing one calls, then one in the same file; without
+// a match it returns the innermost unit enclosing line
+func lookup(graph *semantic.CallerGraph, file string, line int, ident string) *types.CodeUnit {
+	var enclosing *types.CodeUnit
+	for _, unit := range graph.Units() {
+		end := max(unit.EndLine, unit.LineNumber)
+		if unit.FilePath != file || line < unit.LineNumber || line > end {
+			continue
+		}
+		if enclosing == nil || unit.LineNumber > enclosing.LineNumber {
+			enclosing = unit
+		}
+	}
+	if ident == "" {
+		return enclosing
+	}
+
+	called := make(map[string]bool)
+	if enclosing != nil {
+		for _, callee := range graph.Callees(enclosing) {
+			called[callee.ID] = true
+		}
+	}
+	var best *types.CodeUnit
+	bestRank := 0
+	for _, unit := range graph.Find(ident, "") {
+		rank := 4
+		switch {
+		case unit.FilePath == file && unit.LineNumber == line:
+			rank = 1
+		case called[unit.ID]:
+			rank = 2
+		case unit.FilePath == file:
+			rank = 3
+		}
+		if best == nil || rank < bestRank {
+			best, bestRank = unit, rank
+		}
+	}
+	if best == nil {
+		return enclosing
+	}
+	return best
+}
+
+// identifierAt returns the identifier at col (from 1) of text, or at the
+// character before it when the cursor sits just past the identifier
+func identifierAt(text string, col int) string {
+	runes := []rune(text)
+	isIdent := func(i int) bool {
+		return i >= 0 && i < len(runes) && (runes[i] == '_' || runes[i] == '$' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]))
+	}
+	i := col - 1
+	if !isIdent(i) {
+		i--
+	}
+	if !isIdent(i) {
+		return ""
+	}
+	start, end := i, i
+	for isIdent(start - 1) {
+		start--
+	}
+	for isIdent(end + 1) {
+		end++
+	}
+	if unicode.IsDigit(runes[start]) {
+		return ""
+	}
+	return string(runes[start : end+1])
+}
+
+// build gathers the hover of unit
+func build(rootDir string, graph *semantic.CallerGraph, unit *types.CodeUnit) *Hover {
+	h := &Hover{
+		ID:          unit.ID,
+		Name:        unit.Name,
+		Kind:        unit.Type,
+		Language:    unit.Language,
+		File:        unit.FilePath,
+		Line:        unit.LineNumber,
+		EndLine:     unit.EndLine,
+		Signature:   unit.Signature,
+		ControlFlow: unit.CFGSummary,
+	}
+	h.Complexity, _ = semantic.UnitComplexity(unit)
+
+	path := filepath.Join(rootDir, unit.FilePath)
+	if moduleInfo, err := extractor.ExtractFile(path); err == nil {
+		h.Type = typeInfo(moduleInfo, unit)
+	}
+	h.Docs = CleanDocstring(unit.Docstring)
+	if h.Docs == "" {
+		if source, err := os.ReadFile(path); err == nil {
+			h.Docs = leadingComment(strings.Split(string(source), "\n"), unit.LineNumber)
+		}
+	}
+
+	callers := graph.Callers([]*types.CodeUnit{unit}, 1)
+	h.CallerCount = len(callers)
+	h.Callers = callers[:min(len(callers), MaxCallers)]
+	if h.Callers == nil {
+		h.Callers = []semantic.Caller{}
+	}
+
+	h.Markdown = render(h)
+	return h
+}
+
+// typeInfo finds unit's definition in its extracted module
+func typeInfo(m *types.ModuleInfo, unit *types.CodeUnit) TypeInfo {
+	owner, name := "", unit.Name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		owner, name = name[:i], name[i+1:]
+	}
+	fromFunction := func(fn types.Function) TypeInfo {
+		return TypeInfo{Params: fn.Params, Returns: fn.ReturnType, Owner: owner, Decorators: fn.Decorators, Async: fn.IsAsync}
+	}
+
+	for _, fn := range m.Functions {
+		if fn.Name == name && fn.LineNumber == unit.LineNumber {
+			return fromFunction(fn)
+		}
+	}
+	for _, cls := range m.Classes {
+		if cls.Name == unit.Name && cls.LineNumber == unit.LineNumber {
+			return TypeInfo{Bases: cls.Bases, Decorators: cls.Decorators}
+		}
+		for _, method := range cls.Methods {
+			if method.Name == name && method.LineNumber == unit.LineNumber {
+				return fromFunction(method)
+			}
+		}
+	}
+	for _, iface := range m.Interfaces {
+		for _, method := range iface.Methods {
+			if method.Name == name && method.LineNumber == unit.LineNumber {
+				return fromFunction(method)
+			}
+		}
+	}
+	for _, trait := range m.Traits {
+		for _, method := range trait.Methods {
+			if method.Name == name && method.LineNumber == unit.LineNumber {
+				return fromFunction(method)
+			}
+		}
+	}
+	return TypeInfo{Owner: owner}
+}
+
+// CleanDocstring strips the quotes or comment markers of a docstring and
+// removes the indentation its lines share
+func CleanDocstring(doc string) string {
+	doc = strings.TrimSpace(doc)
+	quoted := false
+	for _, quote := range []string{`"""`, `'''`} {
+		if strings.HasPrefix(doc, quote) && strings.HasSuffix(doc, quote) && len(doc) >= 2*len(quote) {
+			doc, quoted = doc[len(quote):len(doc)-len(quote)], true
+		}
+	}
+
+	lines := strings.Split(doc, "\n")
+	if !quoted {
+		for i, line := range lines {
+			trimmed := strings.TrimSpace(line)
+			trimmed = strings.TrimSuffix(strings.TrimPrefix(trimmed, "/**"), "*/")
+			trimmed = strings.TrimPrefix(trimmed, "/*")
+			for _, marker := range []string{"///", "//", "#", "*"} {
+				if strings.HasPrefix(trimmed, marker) {
+					trimmed = strings.TrimPrefix(strings.TrimPrefix(trimmed, marker), " ")
+					break
+				}
+			}
+			lines[i] = trimmed
+		}
+	}
+
+	// The first line follows the opening quotes, so it sets no indentation
+	indent := -1
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i := 1; i < len(lines); i++ {
+		if len(lines[i]) >= indent && indent > 0 {
+			lines[i] = lines[i][indent:]
+		}
+		lines[i] = strings.TrimRight(lines[i], " \t")
+	}
+	lines[0] = strings.TrimSpace(lines[0])
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// leadingComment returns the comment block right above line (from 1),
+// skipping decorators and attributes, as Go and C-family doc comments are
+// not part of the extracted docstring
+func leadingComment(lines []string, line int) string {
+	var block []string
+	for i := line - 2; i >= 0; i-- {
+		trimmed := strings.TrimSpace(lines[i])
+		isComment := false
+		for _, marker := range []string{"//", "#", "/*", "*"} {
+			if strings.HasPrefix(trimmed, marker) && !strings.HasPrefix(trimmed, "#[") {
+				isComment = true
+				break
+			}
+		}
+		if !isComment {
+			if len(block) == 0 && (strings.HasPrefix(trimmed, "@") || strings.HasPrefix(trimmed, "#[")) {
+				continue
+			}
+			break
+		}
+		block = append([]string{trimmed}, block...)
+	}
+	if len(block) == 0 {
+		return ""
+	}
+	return CleanDocstring(strings.Join(block, "\n"))
+}
+
+// render renders h as Markdown: the signature as code, the docs, then a
+// line each for type details, complexity and callers
+func render(h *Hover) string {
+	var sb strings.Builder
+	signature := h.Signature
+	if signature == "" {
+		signature = h.Name
+	}
+	fmt.Fprintf(&sb, "```%s\n%s\n```\n", h.Language, signature)
+	if h.Docs != "" {
+		fmt.Fprintf(&sb, "\n%s\n", h.Docs)
+	}
+
+	var details []string
+	if h.Type.Owner != "" {
+		details = append(details, fmt.Sprintf("%s of `%s`", h.Kind, h.Type.Owner))
+	} else {
+		details = append(details, h.Kind)
+	}
+	if h.Type.Async {
+		details = append(details, "async")
+	}
+	if h.Type.Returns != "" {
+		details = append(details, fmt.Sprintf("returns `%s`", h.Type.Returns))
+	}
+	if len(h.Type.Bases) > 0 {
+		details = append(details, fmt.Sprintf("extends `%s`", strings.Join(h.Type.Bases, "`, `")))
+	}
+	if len(h.Type.Decorators) > 0 {
+		details = append(details, fmt.Sprintf("decorated `%s`", strings.Join(h.Type.Decorators, "`, `")))
+	}
+	fmt.Fprintf(&sb, "\n---\n\n*%s* · `%s:%d`\n", strings.Join(details, " · "), h.File, h.Line)
+
+	if h.Complexity > 0 {
+		fmt.Fprintf(&sb, "\n**Complexity:** %d", h.Complexity)
+		if h.EndLine > h.Line {
+			fmt.Fprintf(&sb, " · %d lines", h.EndLine-h.Line+1)
+		}
+		sb.WriteString("\n")
+	}
+
+	if h.CallerCount == 0 {
+		sb.WriteString("\n**Callers:** none found\n")
+		return sb.String()
+	}
+	callers := make([]string, len(h.Callers))
+	for i, c := range h.Callers {
+		callers[i] = fmt.Sprintf("`%s` (%s:%d)", c.Name, c.File, c.Line)
+	}
+	list := strings.Join(callers, ", ")
+	if more := h.CallerCount - len(h.Callers); more > 0 {
+		list += fmt.Sprintf(" and %d more", more)
+	}
+	fmt.Fprintf(&sb, "\n**Callers (%d):** %s\n", h.CallerCount, list)
+	return sb.String()
+}
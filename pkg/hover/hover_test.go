@@ -0,0 +1,123 @@
+package hover
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/semantic"
+)
+
+const testSource = `class Parser(Base):
+    def parse(self, text: str) -> list:
+        """Split text into lines.
+
+        Blank lines are dropped.
+        """
+        return [l for l in text.split("\n") if l]
+
+
+def load(path):
+    return Parser().parse(open(path).read())
+`
+
+func testGraph(t *testing.T) (string, *semantic.CallerGraph) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "m.py"), []byte(testSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	units := []*semantic.CodeUnit{
+		{ID: "py://m.py#Parser", Name: "Parser", Type: "class", Language: "python", FilePath: "m.py", LineNumber: 1,
+			Signature: "class Parser(Base)"},
+		{ID: "py://m.py#Parser.parse", Name: "Parser.parse", Type: "method", Language: "python", FilePath: "m.py", LineNumber: 2, EndLine: 7,
+			Signature: "def parse(self, text: str) -> list", Docstring: "\"\"\"Split text into lines.\n\n        Blank lines are dropped.\n        \"\"\"",
+			CFGSummary: "complexity:3, blocks:4, branches:2, loops:1, depth:2"},
+		{ID: "py://m.py#load", Name: "load", Type: "function", Language: "python", FilePath: "m.py", LineNumber: 10, EndLine: 11,
+			Signature: "def load(path)", Calls: []string{"m.py:parse"}},
+	}
+	return dir, semantic.NewCallerGraph(units)
+}
+
+func TestAt(t *testing.T) {
+	dir, graph := testGraph(t)
+
+	// The cursor on the call to parse in load shows parse
+	h, err := At(dir, graph, "m.py", 11, 23)
+	if err != nil {
+		t.Fatalf("At failed: %v", err)
+	}
+	if h.Name != "Parser.parse" || h.Complexity != 3 || h.CallerCount != 1 || h.Callers[0].Name != "load" {
+		t.Errorf("hover = %+v", h)
+	}
+	if h.Docs != "Split text into lines.\n\nBlank lines are dropped." {
+		t.Errorf("docs = %q", h.Docs)
+	}
+	if h.Type.Owner != "Parser" || h.Type.Returns == "" {
+		t.Errorf("type = %+v", h.Type)
+	}
+	for _, want := range []string{"```python\ndef parse(self, text: str) -> list\n```", "*method of `Parser`", "**Complexity:** 3", "**Callers (1):** `load` (m.py:10)"} {
+		if !strings.Contains(h.Markdown, want) {
+			t.Errorf("markdown is missing %q:\n%s", want, h.Markdown)
+		}
+	}
+
+	// Off any identifier, the enclosing unit is shown
+	h, err = At(dir, graph, filepath.Join(dir, "m.py"), 11, 1)
+	if err != nil || h.Name != "load" || !strings.Contains(h.Markdown, "**Callers:** none found") {
+		t.Errorf("enclosing hover = %+v, %v", h, err)
+	}
+
+	// The class name on its definition line shows the class
+	if h, err = At(dir, graph, "m.py", 1, 8); err != nil || h.Name != "Parser" || len(h.Type.Bases) != 1 {
+		t.Errorf("class hover = %+v, %v", h, err)
+	}
+
+	if _, err := At(dir, graph, "m.py", 8, 1); err == nil {
+		t.Error("expected an error on a line outside any unit")
+	}
+}
+
+func TestIdentifierAt(t *testing.T) {
+	tests := []struct {
+		text string
+		col  int
+		want string
+	}{
+		{"    return parse(x)", 12, "parse"},
+		{"    return parse(x)", 17, "parse"},
+		{"x = 42", 5, ""},
+		{"self._cache", 7, "_cache"},
+		{"", 1, ""},
+	}
+	for _, tt := range tests {
+		if got := identifierAt(tt.text, tt.col); got != tt.want {
+			t.Errorf("identifierAt(%q, %d) = %q, want %q", tt.text, tt.col, got, tt.want)
+		}
+	}
+}
+
+func TestCleanDocstring(t *testing.T) {
+	tests := []struct{ doc, want string }{
+		{"// Add returns the sum\n// of a and b.", "Add returns the sum\nof a and b."},
+		{"/**\n * Loads a file.\n */", "Loads a file."},
+		{"'''One line.'''", "One line."},
+		{"\"\"\"Items:\n\n    * first\n    \"\"\"", "Items:\n\n* first"},
+	}
+	for _, tt := range tests {
+		if got := CleanDocstring(tt.doc); got != tt.want {
+			t.Errorf("CleanDocstring(%q) = %q, want %q", tt.doc, got, tt.want)
+		}
+	}
+}
+
+func TestLeadingComment(t *testing.T) {
+	lines := strings.Split("package p\n\n// Add returns the sum\n// of a and b.\nfunc Add(a, b int) int { return a + b }", "\n")
+	if got := leadingComment(lines, 5); got != "Add returns the sum\nof a and b." {
+		t.Errorf("leadingComment = %q", got)
+	}
+	if got := leadingComment(lines, 1); got != "" {
+		t.Errorf("leadingComment at line 1 = %q", got)
+	}
+}
@@ -0,0 +1,202 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ctagsTag is one tag of a tags file; field names follow Universal Ctags'
+// JSON output
+type ctagsTag struct {
+	Type      string `json:"_type"`
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Pattern   string `json:"pattern"`
+	Language  string `json:"language"`
+	Line      int    `json:"line"`
+	Kind      string `json:"kind"`
+	Scope     string `json:"scope"`
+	ScopeKind string `json:"scopeKind"`
+	Signature string `json:"signature"`
+	End       int    `json:"end"`
+}
+
+// ctagsKinds maps ctags kinds, long names and the unambiguous letters, to
+// unit types. Run ctags with --fields=+nKSe for lines, long kinds,
+// signatures and extents.
+var ctagsKinds = map[string]string{
+	"function":        kindFunction,
+	"func":            kindFunction,
+	"subroutine":      kindFunction,
+	"procedure":       kindFunction,
+	"f":               kindFunction,
+	"method":          kindMethod,
+	"singletonMethod": kindMethod,
+	"class":           kindClass,
+	"struct":          kindClass,
+	"enum":            kindClass,
+	"module":          kindClass,
+	"object":          kindClass,
+	"c":               kindClass,
+	"s":               kindClass,
+	"interface":       kindInterface,
+	"trait":           kindInterface,
+	"protocol":        kindInterface,
+	"i":               kindInterface,
+}
+
+// ctagsOwnerKinds are scope kinds whose functions are methods
+var ctagsOwnerKinds = map[string]bool{
+	"class": true, "struct": true, "interface": true, "trait": true,
+	"protocol": true, "object": true, "implementation": true, "enum": true,
+}
+
+// readCtags reads a tags file in classic or JSON format
+func (d *dump) readCtags(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		var tag ctagsTag
+		switch {
+		case line == "" || strings.HasPrefix(line, "!_"):
+			continue
+		case strings.HasPrefix(line, "{"):
+			if err := json.Unmarshal([]byte(line), &tag); err != nil {
+				return err
+			}
+			if tag.Type != "tag" {
+				continue
+			}
+		default:
+			var ok bool
+			if tag, ok = parseCtagsLine(line); !ok {
+				continue
+			}
+		}
+		d.addCtag(tag)
+	}
+	return sc.Err()
+}
+
+// parseCtagsLine parses a classic tags line:
+// name<TAB>file<TAB>address;"<TAB>kind<TAB>key:value...
+func parseCtagsLine(line string) (ctagsTag, bool) {
+	fields := strings.Split(line, "\t")
+	if len(fields) < 3 {
+		return ctagsTag{}, false
+	}
+	tag := ctagsTag{Name: fields[0], Path: fields[1]}
+
+	// The address may itself contain tabs; it ends at `;"`
+	rest := strings.Join(fields[2:], "\t")
+	address, ext, _ := strings.Cut(rest, ";\"")
+	if n, err := strconv.Atoi(address); err == nil {
+		tag.Line = n
+	} else {
+		tag.Pattern = address
+	}
+
+	for _, field := range strings.Split(strings.TrimPrefix(ext, "\t"), "\t") {
+		key, value, ok := strings.Cut(field, ":")
+		if !ok {
+			tag.Kind = field
+			continue
+		}
+		switch key {
+		case "kind":
+			tag.Kind = value
+		case "line":
+			tag.Line, _ = strconv.Atoi(value)
+		case "end":
+			tag.End, _ = strconv.Atoi(value)
+		case "signature":
+			tag.Signature = value
+		case "language":
+			tag.Language = value
+		case "file", "access", "implementation", "inherits", "roles", "typeref":
+		default:
+			// Any other key names the scope, e.g. class:Parser
+			tag.Scope, tag.ScopeKind = value, key
+		}
+	}
+	return tag, true
+}
+
+// addCtag records a tag as a symbol if it is a kind gcq indexes
+func (d *dump) addCtag(tag ctagsTag) {
+	kind, ok := ctagsKinds[tag.Kind]
+	if !ok || tag.Name == "" {
+		return
+	}
+
+	name := tag.Name
+	if ctagsOwnerKinds[tag.ScopeKind] && tag.Scope != "" {
+		if kind == kindFunction {
+			kind = kindMethod
+		}
+		if kind == kindMethod {
+			name = scopeOwner(tag.Scope) + "." + name
+		}
+	}
+
+	file := d.relPath(tag.Path)
+	line := tag.Line
+	if line == 0 && tag.Pattern != "" {
+		line = d.patternLine(file, tag.Pattern)
+	}
+
+	signature := ""
+	if tag.Signature != "" {
+		signature = tag.Name + tag.Signature
+	}
+	d.addSymbol(symbol{
+		name:      name,
+		kind:      kind,
+		language:  strings.ToLower(tag.Language),
+		file:      file,
+		line:      line,
+		endLine:   tag.End,
+		signature: signature,
+	})
+}
+
+// scopeOwner returns the innermost name of a ctags scope such as
+// "pkg.Parser" or "ns::Parser"
+func scopeOwner(scope string) string {
+	if i := strings.LastIndex(scope, "::"); i >= 0 {
+		scope = scope[i+2:]
+	}
+	return scope[strings.LastIndex(scope, ".")+1:]
+}
+
+// patternLine finds the line a ctags search pattern (/^text$/) points to,
+// or 0
+func (d *dump) patternLine(file, pattern string) int {
+	if len(pattern) < 2 {
+		return 0
+	}
+	pattern = pattern[1 : len(pattern)-1]
+	anchoredEnd := strings.HasSuffix(pattern, "$") && !strings.HasSuffix(pattern, `\$`)
+	pattern = strings.TrimPrefix(pattern, "^")
+	if anchoredEnd {
+		pattern = strings.TrimSuffix(pattern, "$")
+	}
+	pattern = strings.NewReplacer(`\/`, "/", `\?`, "?", `\\`, `\`, `\$`, "$").Replace(pattern)
+
+	for i, line := range d.source(file) {
+		if line == pattern || (!anchoredEnd && strings.HasPrefix(line, pattern)) {
+			return i + 1
+		}
+	}
+	return 0
+}
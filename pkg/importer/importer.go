@@ -0,0 +1,285 @@
+// Package importer reads symbol dumps written by other indexers (ctags,
+// LSIF and SCIP) and turns their definitions into code units, so the
+// semantic index and caller graph also cover languages gcq does not parse
+// natively. References inside a definition become call graph edges.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/l3aro/go-context-query/internal/scanner"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// Format is the kind of symbol dump
+type Format string
+
+const (
+	// FormatCtags is a Universal Ctags tags file, classic or JSON output
+	FormatCtags Format = "ctags"
+	// FormatLSIF is an LSIF dump in JSON lines
+	FormatLSIF Format = "lsif"
+	// FormatSCIP is a SCIP index in protobuf encoding
+	FormatSCIP Format = "scip"
+)
+
+// Unit types given to imported definitions; other kinds (variables,
+// fields, constants) are not imported
+const (
+	kindFunction  = "function"
+	kindMethod    = "method"
+	kindClass     = "class"
+	kindInterface = "interface"
+)
+
+// DetectFormat guesses the format of the dump at path from its name and,
+// failing that, its first bytes
+func DetectFormat(path string) (Format, error) {
+	name := strings.ToLower(filepath.Base(path))
+	switch {
+	case strings.HasSuffix(name, ".scip"):
+		return FormatSCIP, nil
+	case strings.HasSuffix(name, ".lsif"):
+		return FormatLSIF, nil
+	case name == "tags" || strings.HasSuffix(name, ".tags") || strings.HasSuffix(name, ".ctags"):
+		return FormatCtags, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	head := make([]byte, 4096)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	head = bytes.TrimSpace(head[:n])
+
+	switch {
+	case bytes.HasPrefix(head, []byte("!_TAG_")):
+		return FormatCtags, nil
+	case bytes.Contains(head, []byte(`"_type"`)):
+		return FormatCtags, nil
+	case bytes.Contains(head, []byte(`"vertex"`)) || bytes.Contains(head, []byte(`"edge"`)):
+		return FormatLSIF, nil
+	}
+	return "", fmt.Errorf("can't tell the format of %s; name it *.scip, *.lsif or tags", path)
+}
+
+// Load reads the dump at path and returns its definitions as code units
+// with file paths relative to rootDir. An empty format is detected.
+func Load(path, rootDir string, format Format) ([]*types.CodeUnit, error) {
+	if format == "" {
+		detected, err := DetectFormat(path)
+		if err != nil {
+			return nil, err
+		}
+		format = detected
+	}
+
+	absRoot, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("getting absolute path: %w", err)
+	}
+	d := newDump(absRoot)
+
+	switch format {
+	case FormatCtags:
+		err = d.readCtags(path)
+	case FormatLSIF:
+		err = d.readLSIF(path)
+	case FormatSCIP:
+		err = d.readSCIP(path)
+	default:
+		return nil, fmt.Errorf("unknown import format %q (ctags, lsif or scip)", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s dump %s: %w", format, path, err)
+	}
+	return d.units(), nil
+}
+
+// symbol is a definition read from a dump
+type symbol struct {
+	name      string // qualified name, Owner.method for methods
+	kind      string
+	language  string
+	file      string // slash-separated, relative to the root
+	line      int    // 1-based
+	endLine   int    // last line of the body, 0 when the dump doesn't say
+	signature string
+	doc       string
+}
+
+// reference is a use of symbols[target] at file:line
+type reference struct {
+	file   string
+	line   int
+	target int
+}
+
+// dump accumulates the symbols and references of one dump
+type dump struct {
+	root    string
+	symbols []symbol
+	refs    []reference
+	sources map[string][]string
+}
+
+func newDump(root string) *dump {
+	return &dump{root: root, sources: make(map[string][]string)}
+}
+
+// relPath makes a dump path relative to the root. Paths outside it are
+// kept as they are.
+func (d *dump) relPath(p string) string {
+	p = strings.TrimPrefix(p, "file://")
+	if filepath.IsAbs(p) {
+		if rel, err := filepath.Rel(d.root, p); err == nil && !strings.HasPrefix(rel, "..") {
+			p = rel
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(p))
+}
+
+// source returns the lines of file, read once from under the root; nil when
+// it can't be read
+func (d *dump) source(file string) []string {
+	if lines, ok := d.sources[file]; ok {
+		return lines
+	}
+	var lines []string
+	if f, err := os.Open(filepath.Join(d.root, filepath.FromSlash(file))); err == nil {
+		sc := bufio.NewScanner(f)
+		sc.Buffer(make([]byte, 64*1024), 1024*1024)
+		for sc.Scan() {
+			lines = append(lines, sc.Text())
+		}
+		f.Close()
+	}
+	d.sources[file] = lines
+	return lines
+}
+
+// addSymbol records a definition and returns its index. The signature
+// defaults to the definition's source line.
+func (d *dump) addSymbol(s symbol) int {
+	if s.language == "" {
+		s.language = languageForFile(s.file)
+	}
+	if s.signature == "" {
+		if lines := d.source(s.file); s.line > 0 && s.line <= len(lines) {
+			s.signature = strings.TrimSpace(lines[s.line-1])
+		}
+	}
+	d.symbols = append(d.symbols, s)
+	return len(d.symbols) - 1
+}
+
+// languageForFile names the language of file by its extension, using the
+// extension itself for languages the scanner doesn't know
+func languageForFile(file string) string {
+	ext := filepath.Ext(file)
+	if lang := scanner.DetectLanguage(ext); lang != "" {
+		return lang
+	}
+	return strings.ToLower(strings.TrimPrefix(ext, "."))
+}
+
+// callable reports whether a unit of kind can make calls
+func callable(kind string) bool {
+	return kind == kindFunction || kind == kindMethod
+}
+
+// units converts the dump to code units, linking each reference to the
+// function or method it appears in. A definition's extent is taken from the
+// dump when known, otherwise it runs until the next definition in the file.
+func (d *dump) units() []*types.CodeUnit {
+	units := make([]*types.CodeUnit, len(d.symbols))
+	byFile := make(map[string][]int)
+	// canonical maps each symbol to the first with the same unit ID
+	canonical := make([]int, len(d.symbols))
+	seen := make(map[string]int)
+	for i, s := range d.symbols {
+		id := types.NewUnitURI(s.language, s.file, s.name).String()
+		if first, ok := seen[id]; ok {
+			canonical[i] = first
+			continue
+		}
+		seen[id] = i
+		canonical[i] = i
+		units[i] = &types.CodeUnit{
+			ID:         id,
+			Name:       s.name,
+			Type:       s.kind,
+			Language:   s.language,
+			FilePath:   s.file,
+			LineNumber: s.line,
+			Signature:  s.signature,
+			Docstring:  s.doc,
+		}
+		byFile[s.file] = append(byFile[s.file], i)
+	}
+	for _, idx := range byFile {
+		sort.SliceStable(idx, func(a, b int) bool { return d.symbols[idx[a]].line < d.symbols[idx[b]].line })
+	}
+
+	calls := make(map[[2]int]bool)
+	for _, ref := range d.refs {
+		target := canonical[ref.target]
+		caller := d.enclosing(byFile[ref.file], ref.line)
+		if caller < 0 || caller == target || calls[[2]int{caller, target}] {
+			continue
+		}
+		calls[[2]int{caller, target}] = true
+		callee := units[target]
+		units[caller].Calls = append(units[caller].Calls, callee.FilePath+":"+callee.Name)
+		callee.CalledBy = append(callee.CalledBy, units[caller].FilePath+":"+units[caller].Name)
+	}
+
+	result := make([]*types.CodeUnit, 0, len(units))
+	for _, u := range units {
+		if u != nil {
+			result = append(result, u)
+		}
+	}
+	return result
+}
+
+// enclosing returns the innermost function or method of a file (idx, in
+// line order) containing line, or -1
+func (d *dump) enclosing(idx []int, line int) int {
+	best := -1
+	for n, i := range idx {
+		s := d.symbols[i]
+		if s.line > line {
+			break
+		}
+		if !callable(s.kind) {
+			continue
+		}
+		end := s.endLine
+		if end == 0 {
+			end = int(^uint(0) >> 1)
+			for _, j := range idx[n+1:] {
+				if d.symbols[j].line > s.line {
+					end = d.symbols[j].line - 1
+					break
+				}
+			}
+		}
+		if line <= end {
+			best = i
+		}
+	}
+	return best
+}
@@ -0,0 +1,279 @@
+package importer
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+const luaSource = `local Parser = {}
+
+function Parser.new(src)
+  return setmetatable({src = src}, Parser)
+end
+
+function Parser:parse()
+  return tokenize(self.src)
+end
+
+function tokenize(src)
+  return {}
+end
+`
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func unitByName(t *testing.T, units []*types.CodeUnit, name string) *types.CodeUnit {
+	t.Helper()
+	for _, u := range units {
+		if u.Name == name {
+			return u
+		}
+	}
+	names := make([]string, len(units))
+	for i, u := range units {
+		names[i] = u.Name
+	}
+	t.Fatalf("no unit %q in %v", name, names)
+	return nil
+}
+
+func TestLoadCtagsClassic(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "src/parser.lua"), luaSource)
+	tags := "!_TAG_FILE_FORMAT\t2\t/extended format/\n" +
+		"Parser\tsrc/parser.lua\t/^local Parser = {}$/;\"\tkind:variable\n" +
+		"Parser.new\tsrc/parser.lua\t/^function Parser.new(src)$/;\"\tkind:function\tsignature:(src)\n" +
+		"parse\tsrc/parser.lua\t7;\"\tkind:method\tclass:Parser\tend:9\n" +
+		"tokenize\tsrc/parser.lua\t/^function tokenize(src)$/;\"\tf\n"
+	writeFile(t, filepath.Join(root, "tags"), tags)
+
+	units, err := Load(filepath.Join(root, "tags"), root, "")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(units) != 3 {
+		t.Fatalf("got %d units, want 3 (variables are skipped)", len(units))
+	}
+
+	newFn := unitByName(t, units, "Parser.new")
+	if newFn.LineNumber != 3 || newFn.Signature != "Parser.new(src)" || newFn.Language != "lua" {
+		t.Errorf("Parser.new = line %d, signature %q, language %q", newFn.LineNumber, newFn.Signature, newFn.Language)
+	}
+	if newFn.ID != "lua://src/parser.lua#Parser.new" {
+		t.Errorf("ID = %q", newFn.ID)
+	}
+
+	parse := unitByName(t, units, "Parser.parse")
+	if parse.Type != "method" || parse.LineNumber != 7 || parse.Signature != "function Parser:parse()" {
+		t.Errorf("Parser.parse = %s at line %d, signature %q", parse.Type, parse.LineNumber, parse.Signature)
+	}
+	if unitByName(t, units, "tokenize").LineNumber != 11 {
+		t.Error("tokenize line not resolved from its pattern")
+	}
+}
+
+func TestLoadCtagsJSON(t *testing.T) {
+	root := t.TempDir()
+	tags := `{"_type": "ptag", "name": "JSON_OUTPUT_VERSION", "path": "0.0"}
+{"_type": "tag", "name": "Server", "path": "app/server.rb", "line": 1, "kind": "class", "language": "Ruby"}
+{"_type": "tag", "name": "start", "path": "app/server.rb", "line": 2, "kind": "method", "scope": "App::Server", "scopeKind": "class", "end": 4}
+`
+	writeFile(t, filepath.Join(root, "tags.json"), tags)
+
+	format, err := DetectFormat(filepath.Join(root, "tags.json"))
+	if err != nil || format != FormatCtags {
+		t.Fatalf("DetectFormat = %q, %v", format, err)
+	}
+	units, err := Load(filepath.Join(root, "tags.json"), root, format)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := unitByName(t, units, "Server.start"); got.Type != "method" || got.Language != "ruby" {
+		t.Errorf("Server.start = %+v", got)
+	}
+	if got := unitByName(t, units, "Server"); got.Type != "class" {
+		t.Errorf("Server type = %q", got.Type)
+	}
+}
+
+func TestLoadLSIF(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "src/parser.lua"), luaSource)
+	dump := `{"id":1,"type":"vertex","label":"metaData","projectRoot":"file:///work/repo"}
+{"id":2,"type":"vertex","label":"document","uri":"file:///work/repo/src/parser.lua","languageId":"lua"}
+{"id":3,"type":"vertex","label":"range","start":{"line":6,"character":16},"end":{"line":6,"character":21}}
+{"id":4,"type":"vertex","label":"resultSet"}
+{"id":5,"type":"vertex","label":"definitionResult"}
+{"id":6,"type":"vertex","label":"hoverResult","result":{"contents":{"kind":"markdown","value":"` + "```lua\\nfunction Parser:parse()\\n```\\n---\\nParses the source." + `"}}}
+{"id":7,"type":"vertex","label":"moniker","kind":"export","scheme":"lua","identifier":"parser:Parser.parse"}
+{"id":8,"type":"vertex","label":"range","start":{"line":10,"character":9},"end":{"line":10,"character":17}}
+{"id":9,"type":"vertex","label":"resultSet"}
+{"id":10,"type":"vertex","label":"definitionResult"}
+{"id":11,"type":"vertex","label":"hoverResult","result":{"contents":[{"language":"lua","value":"function tokenize(src)"}]}}
+{"id":12,"type":"vertex","label":"range","start":{"line":7,"character":9},"end":{"line":7,"character":17}}
+{"id":13,"type":"edge","label":"contains","outV":2,"inVs":[3,8,12]}
+{"id":14,"type":"edge","label":"next","outV":3,"inV":4}
+{"id":15,"type":"edge","label":"textDocument/definition","outV":4,"inV":5}
+{"id":16,"type":"edge","label":"item","outV":5,"inVs":[3],"document":2}
+{"id":17,"type":"edge","label":"textDocument/hover","outV":4,"inV":6}
+{"id":18,"type":"edge","label":"moniker","outV":4,"inV":7}
+{"id":19,"type":"edge","label":"next","outV":8,"inV":9}
+{"id":20,"type":"edge","label":"textDocument/definition","outV":9,"inV":10}
+{"id":21,"type":"edge","label":"item","outV":10,"inVs":[8],"document":2}
+{"id":22,"type":"edge","label":"textDocument/hover","outV":9,"inV":11}
+{"id":23,"type":"edge","label":"next","outV":12,"inV":9}
+`
+	writeFile(t, filepath.Join(root, "dump.lsif"), dump)
+
+	units, err := Load(filepath.Join(root, "dump.lsif"), root, "")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(units) != 2 {
+		t.Fatalf("got %d units, want 2", len(units))
+	}
+
+	parse := unitByName(t, units, "Parser.parse")
+	if parse.Type != "method" || parse.FilePath != "src/parser.lua" || parse.LineNumber != 7 {
+		t.Errorf("Parser.parse = %s in %s:%d", parse.Type, parse.FilePath, parse.LineNumber)
+	}
+	if parse.Signature != "function Parser:parse()" || parse.Docstring != "Parses the source." {
+		t.Errorf("Parser.parse signature %q, docstring %q", parse.Signature, parse.Docstring)
+	}
+
+	// tokenize is named from the source, and its use inside parse is a call
+	tokenize := unitByName(t, units, "tokenize")
+	if tokenize.Type != "function" || tokenize.LineNumber != 11 {
+		t.Errorf("tokenize = %s at line %d", tokenize.Type, tokenize.LineNumber)
+	}
+	if !slices.Equal(parse.Calls, []string{"src/parser.lua:tokenize"}) {
+		t.Errorf("Parser.parse calls %v", parse.Calls)
+	}
+	if !slices.Equal(tokenize.CalledBy, []string{"src/parser.lua:Parser.parse"}) {
+		t.Errorf("tokenize called by %v", tokenize.CalledBy)
+	}
+}
+
+// protoBuilder writes protobuf messages for SCIP fixtures
+type protoBuilder []byte
+
+func (b protoBuilder) varint(num int, v uint64) protoBuilder {
+	b = binary.AppendUvarint(b, uint64(num)<<3)
+	return binary.AppendUvarint(b, v)
+}
+
+func (b protoBuilder) bytes(num int, v []byte) protoBuilder {
+	b = binary.AppendUvarint(b, uint64(num)<<3|2)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+func (b protoBuilder) str(num int, v string) protoBuilder {
+	return b.bytes(num, []byte(v))
+}
+
+func (b protoBuilder) packed(num int, vs ...uint64) protoBuilder {
+	var p []byte
+	for _, v := range vs {
+		p = binary.AppendUvarint(p, v)
+	}
+	return b.bytes(num, p)
+}
+
+func TestLoadSCIP(t *testing.T) {
+	root := t.TempDir()
+	const (
+		parseSym    = "scip-lua luarocks parser 1.0 parser/Parser#parse()."
+		tokenizeSym = "scip-lua luarocks parser 1.0 parser/tokenize()."
+		classSym    = "scip-lua luarocks parser 1.0 parser/Parser#"
+		fieldSym    = "scip-lua luarocks parser 1.0 parser/Parser#src."
+	)
+
+	sigDoc := protoBuilder{}.str(5, "function Parser:parse()")
+	doc := protoBuilder{}.
+		str(1, "src/parser.lua").
+		str(4, "Lua").
+		bytes(2, protoBuilder{}.packed(1, 0, 6, 11).str(2, classSym).varint(3, 1)).
+		bytes(2, protoBuilder{}.packed(1, 6, 16, 21).str(2, parseSym).varint(3, 1).packed(7, 6, 0, 8, 3)).
+		bytes(2, protoBuilder{}.packed(1, 7, 9, 17).str(2, tokenizeSym)).
+		bytes(2, protoBuilder{}.packed(1, 10, 9, 17).str(2, tokenizeSym).varint(3, 1)).
+		bytes(2, protoBuilder{}.packed(1, 1, 2, 5).str(2, fieldSym).varint(3, 1)).
+		bytes(2, protoBuilder{}.packed(1, 7, 2, 5).str(2, "local 1").varint(3, 1)).
+		bytes(3, protoBuilder{}.str(1, parseSym).str(3, "Parses the source.").varint(5, 26).bytes(7, sigDoc)).
+		bytes(3, protoBuilder{}.str(1, tokenizeSym).str(3, "```lua\nfunction tokenize(src)\n```"))
+	index := protoBuilder{}.bytes(1, protoBuilder{}.str(3, "file:///work/repo")).bytes(2, doc)
+	writeFile(t, filepath.Join(root, "index.scip"), string(index))
+
+	units, err := Load(filepath.Join(root, "index.scip"), root, "")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(units) != 3 {
+		t.Fatalf("got %d units, want 3 (fields and locals are skipped)", len(units))
+	}
+
+	if got := unitByName(t, units, "Parser"); got.Type != "class" || got.Language != "lua" || got.LineNumber != 1 {
+		t.Errorf("Parser = %s (%s) at line %d", got.Type, got.Language, got.LineNumber)
+	}
+	parse := unitByName(t, units, "Parser.parse")
+	if parse.Type != "method" || parse.Signature != "function Parser:parse()" || parse.Docstring != "Parses the source." {
+		t.Errorf("Parser.parse = %s, signature %q, docstring %q", parse.Type, parse.Signature, parse.Docstring)
+	}
+	tokenize := unitByName(t, units, "tokenize")
+	if tokenize.Type != "function" || tokenize.Signature != "function tokenize(src)" || tokenize.LineNumber != 11 {
+		t.Errorf("tokenize = %s, signature %q, line %d", tokenize.Type, tokenize.Signature, tokenize.LineNumber)
+	}
+	if !slices.Equal(parse.Calls, []string{"src/parser.lua:tokenize"}) {
+		t.Errorf("Parser.parse calls %v", parse.Calls)
+	}
+}
+
+func TestLoadSCIPMalformed(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "index.scip"), "\x12\xff")
+	if _, err := Load(filepath.Join(root, "index.scip"), root, ""); err == nil {
+		t.Error("expected an error for a truncated index")
+	}
+}
+
+func TestParseSCIPDescriptors(t *testing.T) {
+	got := parseSCIPDescriptors("scip-go gomod example.com/my  mod v1 `example.com/my mod/pkg`/Server#Run(+1).")
+	want := []scipDescriptor{{"example.com/my mod/pkg", '/'}, {"Server", '#'}, {"Run", '('}}
+	if !slices.Equal(got, want) {
+		t.Errorf("descriptors = %v, want %v", got, want)
+	}
+	if parseSCIPDescriptors("local 4") != nil {
+		t.Error("local symbols have no descriptors")
+	}
+}
+
+func TestKindFromSignature(t *testing.T) {
+	tests := map[string]string{
+		"func (s *Server) Run() error":       kindMethod,
+		"export async function load(): void": kindFunction,
+		"(method) Parser.parse(): Token[]":   kindMethod,
+		"type Server struct":                 kindClass,
+		"type Store interface":               kindInterface,
+		"pub trait Storage":                  kindInterface,
+		"const limit = 10":                   "",
+		"":                                   "",
+	}
+	for sig, want := range tests {
+		if got := kindFromSignature(sig); got != want {
+			t.Errorf("kindFromSignature(%q) = %q, want %q", sig, got, want)
+		}
+	}
+}
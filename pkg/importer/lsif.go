@@ -0,0 +1,419 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// lsifElement is a vertex or edge of an LSIF dump, with the fields of the
+// labels the importer reads
+type lsifElement struct {
+	ID    json.RawMessage `json:"id"`
+	Type  string          `json:"type"`
+	Label string          `json:"label"`
+
+	// metaData and document
+	ProjectRoot string `json:"projectRoot"`
+	URI         string `json:"uri"`
+	LanguageID  string `json:"languageId"`
+
+	// range
+	Start *lsifPos `json:"start"`
+	Tag   *lsifTag `json:"tag"`
+
+	// moniker
+	Identifier string `json:"identifier"`
+
+	// hoverResult
+	Result *struct {
+		Contents json.RawMessage `json:"contents"`
+	} `json:"result"`
+
+	// edges
+	OutV json.RawMessage   `json:"outV"`
+	InV  json.RawMessage   `json:"inV"`
+	InVs []json.RawMessage `json:"inVs"`
+}
+
+type lsifPos struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// lsifTag describes a range in older dumps (LSIF 0.4)
+type lsifTag struct {
+	Type      string `json:"type"`
+	Text      string `json:"text"`
+	Kind      int    `json:"kind"`
+	FullRange *struct {
+		Start lsifPos `json:"start"`
+		End   lsifPos `json:"end"`
+	} `json:"fullRange"`
+}
+
+// lspSymbolKinds maps LSP SymbolKind values to unit types
+var lspSymbolKinds = map[int]string{
+	5:  kindClass,     // Class
+	6:  kindMethod,    // Method
+	9:  kindMethod,    // Constructor
+	10: kindClass,     // Enum
+	11: kindInterface, // Interface
+	12: kindFunction,  // Function
+	23: kindClass,     // Struct
+}
+
+// lsifID normalizes an element ID, which may be a number or a string
+func lsifID(raw json.RawMessage) string {
+	return string(bytes.Trim(raw, `"`))
+}
+
+// readLSIF reads an LSIF dump in JSON lines. Definitions are named by
+// their moniker, their range tag or the source text they cover; their
+// kind, signature and docs come from the range tag or hover text.
+// References resolve through result sets to their definitions.
+func (d *dump) readLSIF(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var (
+		projectRoot string
+		documents   = make(map[string]lsifElement)
+		ranges      = make(map[string]lsifElement)
+		rangeDoc    = make(map[string]string)
+		next        = make(map[string]string)
+		definition  = make(map[string]string)   // result set or range -> definitionResult
+		hover       = make(map[string]string)   // result set or range -> hoverResult
+		moniker     = make(map[string]string)   // result set or range -> moniker
+		items       = make(map[string][]string) // definitionResult -> ranges
+		hovers      = make(map[string]json.RawMessage)
+		identifiers = make(map[string]string)
+		defResults  = make(map[string]bool)
+		edges       []lsifElement
+	)
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		var el lsifElement
+		if err := json.Unmarshal(line, &el); err != nil {
+			return err
+		}
+		id := lsifID(el.ID)
+		if el.Type == "edge" {
+			edges = append(edges, el)
+			continue
+		}
+		switch el.Label {
+		case "metaData":
+			projectRoot = el.ProjectRoot
+		case "document":
+			documents[id] = el
+		case "range":
+			ranges[id] = el
+		case "definitionResult":
+			defResults[id] = true
+		case "moniker":
+			identifiers[id] = el.Identifier
+		case "hoverResult":
+			if el.Result != nil {
+				hovers[id] = el.Result.Contents
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+
+	for _, e := range edges {
+		out := lsifID(e.OutV)
+		targets := e.InVs
+		if len(e.InV) > 0 {
+			targets = append(targets, e.InV)
+		}
+		for _, raw := range targets {
+			in := lsifID(raw)
+			switch e.Label {
+			case "contains":
+				if _, ok := documents[out]; ok {
+					rangeDoc[in] = out
+				}
+			case "next":
+				next[out] = in
+			case "textDocument/definition":
+				definition[out] = in
+			case "textDocument/hover":
+				hover[out] = in
+			case "moniker":
+				moniker[out] = in
+			case "item":
+				if defResults[out] {
+					items[out] = append(items[out], in)
+				}
+			}
+		}
+	}
+
+	// lookup follows a range through its result sets to the first vertex
+	// an edge table links it to
+	lookup := func(table map[string]string, id string) string {
+		for seen := 0; id != "" && seen < 32; seen++ {
+			if v, ok := table[id]; ok {
+				return v
+			}
+			id = next[id]
+		}
+		return ""
+	}
+
+	docPath := func(docID string) string {
+		uri := documents[docID].URI
+		if projectRoot != "" {
+			uri = strings.TrimPrefix(uri, strings.TrimSuffix(projectRoot, "/")+"/")
+		}
+		if unescaped, err := url.PathUnescape(uri); err == nil {
+			uri = unescaped
+		}
+		return d.relPath(uri)
+	}
+
+	// Definitions, in dump order
+	isDef := make(map[string]bool)
+	for r, el := range ranges {
+		if el.Tag != nil && el.Tag.Type == "definition" {
+			isDef[r] = true
+		}
+	}
+	for _, defs := range items {
+		for _, r := range defs {
+			isDef[r] = true
+		}
+	}
+
+	defSymbol := make(map[string]int)
+	for _, r := range sortedKeys(isDef) {
+		el, ok := ranges[r]
+		docID, inDoc := rangeDoc[r]
+		if !ok || !inDoc || el.Start == nil {
+			continue
+		}
+		file := docPath(docID)
+		line := el.Start.Line + 1
+
+		var signature, doc string
+		if h := lookup(hover, r); h != "" {
+			signature, doc = splitHover(hoverText(hovers[h]))
+		}
+
+		kind := ""
+		if el.Tag != nil {
+			kind = lspSymbolKinds[el.Tag.Kind]
+		}
+		if kind == "" && el.Tag == nil {
+			kind = kindFromSignature(signature)
+		}
+		if kind == "" {
+			continue
+		}
+
+		name := ""
+		if m := lookup(moniker, r); m != "" {
+			ident := identifiers[m]
+			name = ident[strings.LastIndex(ident, ":")+1:]
+		}
+		if name == "" && el.Tag != nil {
+			name = el.Tag.Text
+		}
+		if name == "" {
+			name = d.rangeText(file, el)
+		}
+		if name == "" {
+			continue
+		}
+		if kind == kindFunction && strings.Contains(name, ".") {
+			kind = kindMethod
+		}
+
+		s := symbol{
+			name:      name,
+			kind:      kind,
+			language:  strings.ToLower(documents[docID].LanguageID),
+			file:      file,
+			line:      line,
+			signature: signature,
+			doc:       doc,
+		}
+		if el.Tag != nil && el.Tag.FullRange != nil {
+			s.endLine = el.Tag.FullRange.End.Line + 1
+		}
+		defSymbol[r] = d.addSymbol(s)
+	}
+
+	// References: every other range whose result set has a definition
+	for _, r := range sortedKeys(ranges) {
+		el := ranges[r]
+		docID, inDoc := rangeDoc[r]
+		if isDef[r] || !inDoc || el.Start == nil {
+			continue
+		}
+		dr := lookup(definition, r)
+		for _, target := range items[dr] {
+			if s, ok := defSymbol[target]; ok {
+				d.refs = append(d.refs, reference{file: docPath(docID), line: el.Start.Line + 1, target: s})
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// rangeText returns the identifier a single-line range covers in the
+// source, or ""
+func (d *dump) rangeText(file string, el lsifElement) string {
+	lines := d.source(file)
+	if el.Start.Line >= len(lines) {
+		return ""
+	}
+	text := lines[el.Start.Line]
+	start := el.Start.Character
+	if start > len(text) {
+		return ""
+	}
+	end := start
+	for end < len(text) && isIdentByte(text[end]) {
+		end++
+	}
+	return text[start:end]
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// hoverText flattens LSP hover contents (a string, MarkupContent,
+// MarkedString or a list of them) to markdown
+func hoverText(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var marked struct {
+		Kind     string `json:"kind"`
+		Language string `json:"language"`
+		Value    string `json:"value"`
+	}
+	if json.Unmarshal(raw, &marked) == nil && marked.Value != "" {
+		if marked.Language != "" {
+			return "```" + marked.Language + "\n" + marked.Value + "\n```"
+		}
+		return marked.Value
+	}
+	var list []json.RawMessage
+	if json.Unmarshal(raw, &list) == nil {
+		parts := make([]string, 0, len(list))
+		for _, item := range list {
+			if text := hoverText(item); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		return strings.Join(parts, "\n\n")
+	}
+	return ""
+}
+
+// splitHover takes the first line of a hover's first code block as the
+// signature and the text outside code blocks as documentation
+func splitHover(text string) (signature, doc string) {
+	var docLines []string
+	inCode := false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			continue
+		}
+		switch {
+		case inCode && signature == "" && strings.TrimSpace(line) != "":
+			signature = strings.TrimSpace(line)
+		case !inCode && line != "---":
+			docLines = append(docLines, line)
+		}
+	}
+	return signature, strings.TrimSpace(strings.Join(docLines, "\n"))
+}
+
+// signatureModifiers are dropped before kindFromSignature looks at the
+// leading keyword
+var signatureModifiers = []string{
+	"export ", "default ", "public ", "private ", "protected ", "internal ",
+	"static ", "async ", "abstract ", "final ", "declare ", "pub ", "open ",
+	"override ", "inline ", "virtual ", "const ", "unsafe ", "extern ",
+}
+
+// kindFromSignature guesses the unit type of a definition from the
+// signature in its hover, or "" when it isn't a function or type
+func kindFromSignature(signature string) string {
+	sig := strings.TrimSpace(signature)
+	switch {
+	case strings.HasPrefix(sig, "(method)"), strings.HasPrefix(sig, "(constructor)"), strings.HasPrefix(sig, "func ("):
+		return kindMethod
+	case strings.HasPrefix(sig, "(function)"):
+		return kindFunction
+	}
+	for trimmed := true; trimmed; {
+		trimmed = false
+		for _, m := range signatureModifiers {
+			if strings.HasPrefix(sig, m) {
+				sig, trimmed = strings.TrimPrefix(sig, m), true
+			}
+		}
+	}
+
+	keyword, rest, _ := strings.Cut(sig, " ")
+	switch keyword {
+	case "func", "def", "function", "fn", "fun", "sub", "procedure", "proc":
+		return kindFunction
+	case "class", "struct", "enum", "record", "object", "module":
+		return kindClass
+	case "interface", "trait", "protocol":
+		return kindInterface
+	case "type":
+		// Go: type Name struct / type Name interface
+		fields := strings.Fields(rest)
+		if len(fields) >= 2 {
+			switch fields[1] {
+			case "struct":
+				return kindClass
+			case "interface":
+				return kindInterface
+			}
+		}
+	}
+	return ""
+}
+
+// sortedKeys returns the element IDs of m in dump order; numeric IDs sort
+// by value
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) < len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
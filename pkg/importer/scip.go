@@ -0,0 +1,429 @@
+package importer
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"strings"
+)
+
+// SCIP indexes are protobuf messages (see scip.proto in
+// github.com/sourcegraph/scip). The importer decodes the few fields it
+// needs directly from the wire format.
+
+// scipDefinitionRole is the Definition bit of Occurrence.symbol_roles
+const scipDefinitionRole = 1
+
+// scipKinds maps SymbolInformation.Kind values to unit types. Other kinds
+// fall back to the symbol's descriptors.
+var scipKinds = map[uint64]string{
+	7:  kindClass,     // Class
+	9:  kindMethod,    // Constructor
+	11: kindClass,     // Enum
+	17: kindFunction,  // Function
+	21: kindInterface, // Interface
+	26: kindMethod,    // Method
+	33: kindClass,     // Object
+	42: kindInterface, // Protocol
+	49: kindClass,     // Struct
+	53: kindInterface, // Trait
+	56: kindInterface, // TypeClass
+	66: kindMethod,    // AbstractMethod
+	67: kindMethod,    // MethodSpecification
+	68: kindMethod,    // ProtocolMethod
+	69: kindMethod,    // PureVirtualMethod
+	70: kindMethod,    // TraitMethod
+	71: kindMethod,    // TypeClassMethod
+	75: kindClass,     // SingletonClass
+	76: kindMethod,    // SingletonMethod
+	80: kindMethod,    // StaticMethod
+}
+
+// scipNonCode are kinds that are never imported, whatever the descriptors say
+var scipNonCode = map[uint64]bool{
+	8:  true, // Constant
+	12: true, // EnumMember
+	15: true, // Field
+	37: true, // Parameter
+	41: true, // Property
+	58: true, // TypeParameter
+	61: true, // Variable
+	77: true, // StaticDataMember
+	79: true, // StaticField
+	81: true, // StaticProperty
+	82: true, // StaticVariable
+}
+
+type scipDocument struct {
+	path        string
+	language    string
+	occurrences []scipOccurrence
+}
+
+type scipOccurrence struct {
+	rng       []int32
+	symbol    string
+	roles     uint64
+	enclosing []int32
+}
+
+type scipSymbol struct {
+	kind        uint64
+	displayName string
+	signature   string
+	docs        []string
+}
+
+var errBadProto = errors.New("malformed protobuf")
+
+// protoField is one field of a protobuf message
+type protoField struct {
+	num    int
+	wire   int
+	varint uint64
+	bytes  []byte
+}
+
+// protoFields calls fn for each field of the encoded message b
+func protoFields(b []byte, fn func(protoField) error) error {
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errBadProto
+		}
+		b = b[n:]
+		f := protoField{num: int(key >> 3), wire: int(key & 7)}
+		switch f.wire {
+		case 0:
+			if f.varint, n = binary.Uvarint(b); n <= 0 {
+				return errBadProto
+			}
+			b = b[n:]
+		case 1:
+			if len(b) < 8 {
+				return errBadProto
+			}
+			b = b[8:]
+		case 2:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || l > uint64(len(b)-n) {
+				return errBadProto
+			}
+			f.bytes = b[n : n+int(l)]
+			b = b[n+int(l):]
+		case 5:
+			if len(b) < 4 {
+				return errBadProto
+			}
+			b = b[4:]
+		default:
+			return errBadProto
+		}
+		if err := fn(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendInts appends a repeated int32 field, packed or not, to dst
+func (f protoField) appendInts(dst []int32) ([]int32, error) {
+	if f.wire == 0 {
+		return append(dst, int32(f.varint)), nil
+	}
+	for b := f.bytes; len(b) > 0; {
+		v, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, errBadProto
+		}
+		dst = append(dst, int32(v))
+		b = b[n:]
+	}
+	return dst, nil
+}
+
+// readSCIP reads a SCIP index. Definition occurrences become symbols,
+// named and classified by their SymbolInformation and symbol descriptors;
+// other occurrences of those symbols become references.
+func (d *dump) readSCIP(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var docs []scipDocument
+	infos := make(map[string]scipSymbol)
+	err = protoFields(data, func(f protoField) error {
+		if f.num != 2 || f.wire != 2 {
+			return nil
+		}
+		doc, err := parseSCIPDocument(f.bytes, infos)
+		docs = append(docs, doc)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	defs := make(map[string]int)
+	for _, doc := range docs {
+		file := d.relPath(doc.path)
+		for _, occ := range doc.occurrences {
+			if occ.roles&scipDefinitionRole == 0 || len(occ.rng) < 3 || strings.HasPrefix(occ.symbol, "local ") {
+				continue
+			}
+			if _, ok := defs[occ.symbol]; ok {
+				continue
+			}
+			info := infos[occ.symbol]
+			name, kind := scipName(occ.symbol, info)
+			if kind == "" {
+				continue
+			}
+
+			signature, text := info.signature, ""
+			if len(info.docs) > 0 {
+				hoverSig, hoverDoc := splitHover(strings.Join(info.docs, "\n\n"))
+				if signature == "" {
+					signature = hoverSig
+				}
+				text = hoverDoc
+			}
+			s := symbol{
+				name:      name,
+				kind:      kind,
+				language:  strings.ToLower(doc.language),
+				file:      file,
+				line:      int(occ.rng[0]) + 1,
+				endLine:   scipEndLine(occ.enclosing),
+				signature: signature,
+				doc:       text,
+			}
+			defs[occ.symbol] = d.addSymbol(s)
+		}
+	}
+
+	for _, doc := range docs {
+		file := d.relPath(doc.path)
+		for _, occ := range doc.occurrences {
+			target, ok := defs[occ.symbol]
+			if !ok || occ.roles&scipDefinitionRole != 0 || len(occ.rng) < 3 {
+				continue
+			}
+			d.refs = append(d.refs, reference{file: file, line: int(occ.rng[0]) + 1, target: target})
+		}
+	}
+	return nil
+}
+
+// parseSCIPDocument decodes a Document, adding its SymbolInformation to
+// infos
+func parseSCIPDocument(b []byte, infos map[string]scipSymbol) (scipDocument, error) {
+	var doc scipDocument
+	err := protoFields(b, func(f protoField) error {
+		switch {
+		case f.num == 1 && f.wire == 2:
+			doc.path = string(f.bytes)
+		case f.num == 4 && f.wire == 2:
+			doc.language = string(f.bytes)
+		case f.num == 2 && f.wire == 2:
+			occ, err := parseSCIPOccurrence(f.bytes)
+			if err != nil {
+				return err
+			}
+			doc.occurrences = append(doc.occurrences, occ)
+		case f.num == 3 && f.wire == 2:
+			name, info, err := parseSCIPSymbol(f.bytes)
+			if err != nil {
+				return err
+			}
+			infos[name] = info
+		}
+		return nil
+	})
+	return doc, err
+}
+
+func parseSCIPOccurrence(b []byte) (scipOccurrence, error) {
+	var occ scipOccurrence
+	err := protoFields(b, func(f protoField) error {
+		var err error
+		switch f.num {
+		case 1:
+			occ.rng, err = f.appendInts(occ.rng)
+		case 2:
+			occ.symbol = string(f.bytes)
+		case 3:
+			occ.roles = f.varint
+		case 7:
+			occ.enclosing, err = f.appendInts(occ.enclosing)
+		}
+		return err
+	})
+	return occ, err
+}
+
+func parseSCIPSymbol(b []byte) (string, scipSymbol, error) {
+	var name string
+	var info scipSymbol
+	err := protoFields(b, func(f protoField) error {
+		switch f.num {
+		case 1:
+			name = string(f.bytes)
+		case 3:
+			info.docs = append(info.docs, string(f.bytes))
+		case 5:
+			info.kind = f.varint
+		case 6:
+			info.displayName = string(f.bytes)
+		case 7:
+			// signature_documentation is a Document; its text is field 5
+			return protoFields(f.bytes, func(g protoField) error {
+				if g.num == 5 && g.wire == 2 {
+					info.signature = strings.TrimSpace(string(g.bytes))
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return name, info, err
+}
+
+// scipEndLine returns the 1-based last line of an enclosing_range, or 0
+func scipEndLine(rng []int32) int {
+	switch len(rng) {
+	case 3:
+		return int(rng[0]) + 1
+	case 4:
+		return int(rng[2]) + 1
+	}
+	return 0
+}
+
+// scipDescriptor is one descriptor of a SCIP symbol: a name and its
+// suffix, '/' namespace, '#' type, '.' term, '(' method, ':' meta or
+// '!' macro
+type scipDescriptor struct {
+	name   string
+	suffix byte
+}
+
+// scipName returns the qualified unit name and type of a SCIP symbol, or
+// an empty type when it isn't a function, method or type
+func scipName(sym string, info scipSymbol) (string, string) {
+	descriptors := parseSCIPDescriptors(sym)
+	if len(descriptors) == 0 || scipNonCode[info.kind] {
+		return "", ""
+	}
+	last := descriptors[len(descriptors)-1]
+
+	owner := ""
+	for _, desc := range descriptors[:len(descriptors)-1] {
+		if desc.suffix == '#' {
+			owner = desc.name
+		}
+	}
+
+	kind, ok := scipKinds[info.kind]
+	if !ok {
+		switch last.suffix {
+		case '(':
+			kind = kindFunction
+		case '#':
+			kind = kindClass
+		default:
+			return "", ""
+		}
+	}
+
+	name := last.name
+	if info.displayName != "" {
+		name = info.displayName
+	}
+	if kind == kindFunction && owner != "" {
+		kind = kindMethod
+	}
+	if kind == kindMethod && owner != "" {
+		name = owner + "." + name
+	}
+	return name, kind
+}
+
+// parseSCIPDescriptors returns the descriptors of a global SCIP symbol:
+// "<scheme> <manager> <package> <version> <descriptors>", where spaces in
+// the first four parts are escaped by doubling them. Type and value
+// parameters are dropped.
+func parseSCIPDescriptors(sym string) []scipDescriptor {
+	for part := 0; part < 4; part++ {
+		i := 0
+		for {
+			j := strings.IndexByte(sym[i:], ' ')
+			if j < 0 {
+				return nil
+			}
+			i += j
+			if i+1 < len(sym) && sym[i+1] == ' ' {
+				i += 2
+				continue
+			}
+			break
+		}
+		sym = sym[i+1:]
+	}
+
+	var descriptors []scipDescriptor
+	for sym != "" {
+		// Parameters, (name), and type parameters, [name]
+		if sym[0] == '(' || sym[0] == '[' {
+			closer := map[byte]byte{'(': ')', '[': ']'}[sym[0]]
+			end := strings.IndexByte(sym, closer)
+			if end < 0 {
+				return descriptors
+			}
+			sym = sym[end+1:]
+			continue
+		}
+
+		var name string
+		if sym[0] == '`' {
+			var sb strings.Builder
+			i := 1
+			for i < len(sym) {
+				if sym[i] == '`' {
+					if i+1 < len(sym) && sym[i+1] == '`' {
+						sb.WriteByte('`')
+						i += 2
+						continue
+					}
+					break
+				}
+				sb.WriteByte(sym[i])
+				i++
+			}
+			name, sym = sb.String(), sym[min(i+1, len(sym)):]
+		} else {
+			i := 0
+			for i < len(sym) && (isIdentByte(sym[i]) || sym[i] == '+' || sym[i] == '-') {
+				i++
+			}
+			name, sym = sym[:i], sym[i:]
+		}
+		if sym == "" {
+			break
+		}
+
+		suffix := sym[0]
+		sym = sym[1:]
+		if suffix == '(' {
+			// Method: name(disambiguator).
+			end := strings.Index(sym, ").")
+			if end < 0 {
+				return descriptors
+			}
+			sym = sym[end+2:]
+		}
+		descriptors = append(descriptors, scipDescriptor{name: name, suffix: suffix})
+	}
+	return descriptors
+}
@@ -0,0 +1,70 @@
+package index
+
+// Backend finds the nearest neighbours of a query vector. VectorIndex is the
+// exact brute-force backend; HNSW is an approximate one for large indexes.
+type Backend interface {
+	// Search returns the top-k entries most similar to query, best first
+	Search(query []float32, k int) ([]SearchResult, error)
+	// Count returns the number of searchable entries
+	Count() int
+	// Dimension returns the vector dimension
+	Dimension() int
+}
+
+// Backend kinds accepted by BackendOptions
+const (
+	// BackendAuto uses HNSW once the index reaches AutoThreshold entries
+	BackendAuto = "auto"
+	// BackendFlat always searches every vector exactly
+	BackendFlat = "flat"
+	// BackendHNSW always uses an HNSW graph
+	BackendHNSW = "hnsw"
+	// BackendSQLite saves the index as a SQLite database, searched in
+	// place by SQLiteIndex without loading it into memory
+	BackendSQLite = "sqlite"
+)
+
+// DefaultHNSWThreshold is the index size at which BackendAuto switches to HNSW
+const DefaultHNSWThreshold = 20000
+
+// BackendOptions chooses and tunes the search backend
+type BackendOptions struct {
+	// Kind is BackendAuto, BackendFlat, BackendHNSW or BackendSQLite
+	// ("" = BackendAuto)
+	Kind string
+	// AutoThreshold is the entry count at which BackendAuto uses HNSW
+	// (0 = DefaultHNSWThreshold)
+	AutoThreshold int
+	// HNSW tunes the graph when HNSW is used
+	HNSW HNSWOptions
+	// Quantization is how saved vectors are stored, QuantizationNone or
+	// QuantizationInt8 ("" = QuantizationNone); see SetQuantization
+	Quantization string
+}
+
+// DefaultBackendOptions returns automatic backend selection with default
+// HNSW parameters
+func DefaultBackendOptions() BackendOptions {
+	return BackendOptions{
+		Kind:          BackendAuto,
+		AutoThreshold: DefaultHNSWThreshold,
+		HNSW:          DefaultHNSWOptions(),
+	}
+}
+
+// UseHNSW reports whether an index with count entries should be searched
+// through an HNSW graph
+func (o BackendOptions) UseHNSW(count int) bool {
+	switch o.Kind {
+	case BackendHNSW:
+		return true
+	case BackendFlat, BackendSQLite:
+		return false
+	default:
+		threshold := o.AutoThreshold
+		if threshold <= 0 {
+			threshold = DefaultHNSWThreshold
+		}
+		return count >= threshold
+	}
+}
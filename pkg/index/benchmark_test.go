@@ -2,6 +2,7 @@ package index
 
 import (
 	"fmt"
+	"math/rand"
 	"testing"
 
 	"github.com/l3aro/go-context-query/pkg/types"
@@ -25,3 +26,50 @@ func BenchmarkIndexSearch(b *testing.B) {
 		idx.Search(query, 10)
 	}
 }
+
+func BenchmarkIndexSearchLarge(b *testing.B) {
+	idx := randomIndex(50000, 384, 1)
+	query := randomQuery(384, rand.New(rand.NewSource(2)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		idx.Search(append([]float32(nil), query...), 10)
+	}
+}
+
+func BenchmarkDot(b *testing.B) {
+	for _, dimension := range []int{384, 768} {
+		rng := rand.New(rand.NewSource(1))
+		x, y := randomQuery(dimension, rng), randomQuery(dimension, rng)
+		b.Run(fmt.Sprintf("dim=%d", dimension), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				dot(x, y)
+			}
+		})
+		b.Run(fmt.Sprintf("dim=%d/generic", dimension), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				dotGeneric(x, y)
+			}
+		})
+	}
+}
+
+func BenchmarkHNSWSearch(b *testing.B) {
+	idx := randomIndex(5000, 384, 1)
+	graph := NewHNSW(idx, DefaultHNSWOptions())
+	query := make([]float32, 384)
+	for i := range query {
+		query[i] = float32(i%100) / 100.0
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		graph.Search(query, 10)
+	}
+}
+
+func BenchmarkHNSWBuild(b *testing.B) {
+	idx := randomIndex(2000, 384, 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewHNSW(idx, DefaultHNSWOptions())
+	}
+}
@@ -0,0 +1,84 @@
+package index
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// deadlineCheckEvery is how many vectors a budgeted scan scores between
+// clock reads
+const deadlineCheckEvery = 256
+
+// BudgetedBackend is a Backend that can stop searching at a deadline, so a
+// slow scan returns what it has instead of running past the caller's timeout
+type BudgetedBackend interface {
+	Backend
+	// SearchBefore is Search, except that once deadline passes it returns
+	// the best entries scored so far and reports that they are partial. A
+	// zero deadline searches to the end.
+	SearchBefore(query []float32, k int, deadline time.Time) ([]SearchResult, bool, error)
+}
+
+// SearchBefore scores vectors in insertion order until deadline and returns
+// the top-k of those scored. At least deadlineCheckEvery vectors are scored
+// however late it is called.
+func (v *VectorIndex) SearchBefore(query []float32, k int, deadline time.Time) ([]SearchResult, bool, error) {
+	if deadline.IsZero() {
+		results, err := v.Search(query, k)
+		return results, false, err
+	}
+	if len(query) != v.dimension {
+		return nil, false, fmt.Errorf("query dimension mismatch: expected %d, got %d", v.dimension, len(query))
+	}
+	if k <= 0 {
+		return nil, false, fmt.Errorf("k must be positive, got %d", k)
+	}
+
+	q := append([]float32(nil), query...)
+	if norm := normalize(q); norm > 0 {
+		for i := range q {
+			q[i] *= norm
+		}
+	}
+
+	var scored []candidate
+	partial := false
+	for i := range v.ids {
+		if i > 0 && i%deadlineCheckEvery == 0 && time.Now().After(deadline) {
+			partial = true
+			break
+		}
+		if v.removed[i] {
+			continue
+		}
+		start := i * v.dimension
+		scored = append(scored, candidate{node: int32(i), sim: cosineSimilarity(q, v.vectors[start:start+v.dimension])})
+	}
+
+	// Sort by score descending, ties in insertion order
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].sim != scored[j].sim {
+			return scored[i].sim > scored[j].sim
+		}
+		return scored[i].node < scored[j].node
+	})
+	if len(scored) > k {
+		scored = scored[:k]
+	}
+
+	results := make([]SearchResult, len(scored))
+	for i, c := range scored {
+		results[i] = SearchResult{
+			ID:       v.ids[c.node],
+			Metadata: v.metadata[c.node],
+			Score:    c.sim,
+		}
+	}
+	return results, partial, nil
+}
+
+var (
+	_ BudgetedBackend = (*VectorIndex)(nil)
+	_ BudgetedBackend = (*HNSW)(nil)
+)
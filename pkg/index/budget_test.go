@@ -0,0 +1,76 @@
+package index
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSearchBeforeWithoutDeadline(t *testing.T) {
+	const dimension, k = 16, 5
+	idx := randomIndex(1000, dimension, 3)
+	graph := NewHNSW(idx, DefaultHNSWOptions())
+	q := randomQuery(dimension, rand.New(rand.NewSource(4)))
+
+	for name, backend := range map[string]BudgetedBackend{"flat": idx, "hnsw": graph} {
+		want, err := backend.Search(slices.Clone(q), k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, partial, err := backend.SearchBefore(slices.Clone(q), k, time.Time{})
+		if err != nil || partial {
+			t.Fatalf("%s: SearchBefore() partial = %v, err = %v", name, partial, err)
+		}
+		if !slices.Equal(resultIDs(got), resultIDs(want)) {
+			t.Errorf("%s: SearchBefore() = %v, want %v", name, resultIDs(got), resultIDs(want))
+		}
+	}
+}
+
+func TestSearchBeforePastDeadline(t *testing.T) {
+	const dimension, k = 16, 5
+	idx := randomIndex(2000, dimension, 5)
+	q := randomQuery(dimension, rand.New(rand.NewSource(6)))
+	past := time.Now().Add(-time.Second)
+
+	results, partial, err := idx.SearchBefore(q, k, past)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !partial || len(results) != k {
+		t.Fatalf("SearchBefore() returned %d results, partial = %v", len(results), partial)
+	}
+	// Only the first vectors were scored, so their best k are returned
+	want, _ := firstEntries(idx, deadlineCheckEvery).Search(slices.Clone(q), k)
+	if !slices.Equal(resultIDs(results), resultIDs(want)) {
+		t.Errorf("SearchBefore() = %v, want %v", resultIDs(results), resultIDs(want))
+	}
+
+	graph := NewHNSW(idx, HNSWOptions{EfSearch: 1000})
+	results, partial, err = graph.SearchBefore(q, k, past)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !partial || len(results) == 0 {
+		t.Errorf("HNSW SearchBefore() returned %d results, partial = %v", len(results), partial)
+	}
+}
+
+// firstEntries returns an index of the first n entries of v
+func firstEntries(v *VectorIndex, n int) *VectorIndex {
+	first := NewVectorIndex(v.Dimension())
+	for i := 0; i < n; i++ {
+		start := i * v.dimension
+		first.Add(v.ids[i], v.vectors[start:start+v.dimension], v.metadata[i])
+	}
+	return first
+}
+
+func resultIDs(results []SearchResult) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.ID
+	}
+	return ids
+}
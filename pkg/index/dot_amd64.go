@@ -0,0 +1,24 @@
+//go:build !purego
+
+package index
+
+import "golang.org/x/sys/cpu"
+
+// useAVX2 is true when the CPU has the AVX2 and FMA instructions dotAVX2
+// uses
+var useAVX2 = cpu.X86.HasAVX2 && cpu.X86.HasFMA
+
+// dotAVX2 returns the dot product of the n values at a and b, eight at a
+// time with fused multiply-adds. It is implemented in dot_amd64.s.
+//
+//go:noescape
+func dotAVX2(a, b *float32, n int) float32
+
+// dot returns the dot product of a and b[:len(a)]
+func dot(a, b []float32) float32 {
+	if !useAVX2 || len(a) < 16 {
+		return dotGeneric(a, b)
+	}
+	b = b[:len(a)]
+	return dotAVX2(&a[0], &b[0], len(a))
+}
@@ -0,0 +1,31 @@
+//go:build !purego
+
+package index
+
+import "golang.org/x/sys/cpu"
+
+// useNEON is true when the CPU has the NEON instructions dotNEON uses
+var useNEON = cpu.ARM64.HasASIMD
+
+// dotNEON adds the products of the n values at a and b, n a multiple of
+// 16, into acc, four lanes per NEON register. It is implemented in
+// dot_arm64.s.
+//
+//go:noescape
+func dotNEON(a, b *float32, n int, acc *[16]float32)
+
+// dot returns the dot product of a and b[:len(a)]
+func dot(a, b []float32) float32 {
+	n := len(a) &^ 15
+	if !useNEON || n == 0 {
+		return dotGeneric(a, b)
+	}
+	b = b[:len(a)]
+	var acc [16]float32
+	dotNEON(&a[0], &b[0], n, &acc)
+	var sum float32
+	for _, x := range acc {
+		sum += x
+	}
+	return sum + dotGeneric(a[n:], b[n:])
+}
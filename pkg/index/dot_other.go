@@ -0,0 +1,8 @@
+//go:build (!amd64 && !arm64) || purego
+
+package index
+
+// dot returns the dot product of a and b[:len(a)]
+func dot(a, b []float32) float32 {
+	return dotGeneric(a, b)
+}
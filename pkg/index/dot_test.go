@@ -0,0 +1,72 @@
+package index
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestDot(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	values := randomQuery(256, rng)
+	for n := 0; n <= 100; n++ {
+		// Offsets move the vectors off any alignment the assembly could
+		// depend on
+		for offset := 0; offset < 3; offset++ {
+			a, b := values[offset:offset+n], values[128+offset:128+offset+n]
+			got, want := dot(a, b), dotGeneric(a, b)
+			var magnitude float64
+			for i := range a {
+				magnitude += math.Abs(float64(a[i] * b[i]))
+			}
+			if math.Abs(float64(got-want)) > 1e-5*(magnitude+1) {
+				t.Errorf("dot of %d values at offset %d = %v, want %v", n, offset, got, want)
+			}
+		}
+	}
+	if got := dot([]float32{1, 2, 3}, []float32{4, 5, 6, 7}); got != 32 {
+		t.Errorf("dot with a longer b = %v, want 32", got)
+	}
+}
+
+func TestSearchParallel(t *testing.T) {
+	const dimension, k = 16, 20
+	// Large enough that score splits the index across goroutines
+	idx := randomIndex(4*minScoreChunk+7, dimension, 3)
+	for i := 0; i < idx.Count(); i += 97 {
+		idx.Remove(idx.ids[i])
+	}
+	query := randomQuery(dimension, rand.New(rand.NewSource(4)))
+	results, err := idx.Search(append([]float32(nil), query...), k)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	normalized := append([]float32(nil), query...)
+	norm := normalize(normalized)
+	for i := range normalized {
+		normalized[i] *= norm
+	}
+	type scored struct {
+		index int
+		score float32
+	}
+	var all []scored
+	for i := range idx.ids {
+		if !idx.removed[i] {
+			all = append(all, scored{i, dotGeneric(normalized, idx.vectors[i*dimension:(i+1)*dimension])})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].score > all[j].score })
+
+	if len(results) != k {
+		t.Fatalf("got %d results, want %d", len(results), k)
+	}
+	for i, r := range results {
+		want := all[i]
+		if r.ID != idx.ids[want.index] || math.Abs(float64(r.Score-want.score)) > 1e-5 {
+			t.Errorf("result %d = %s (%v), want %s (%v)", i, r.ID, r.Score, idx.ids[want.index], want.score)
+		}
+	}
+}
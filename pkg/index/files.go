@@ -0,0 +1,114 @@
+package index
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// FileIndex groups the vectors of a VectorIndex by file and keeps one
+// centroid vector per file. It supports coarse-to-fine search: files are
+// ranked by their centroids first and only the units of the best files are
+// scored, so a query touches far fewer vectors on large indexes.
+//
+// A FileIndex is a snapshot; rebuild it after the unit index changes.
+type FileIndex struct {
+	units *VectorIndex
+	files *VectorIndex
+	rows  map[string][]int
+}
+
+// NewFileIndex builds a FileIndex over units. fileOf returns the file a
+// unit belongs to; units for which it returns "" are left out.
+func NewFileIndex(units *VectorIndex, fileOf func(types.EmbeddingUnit) string) *FileIndex {
+	f := &FileIndex{
+		units: units,
+		files: NewVectorIndex(units.dimension),
+		rows:  make(map[string][]int),
+	}
+
+	var order []string
+	for i, metadata := range units.metadata {
+		if units.removed[i] {
+			continue
+		}
+		file := fileOf(metadata)
+		if file == "" {
+			continue
+		}
+		if _, ok := f.rows[file]; !ok {
+			order = append(order, file)
+		}
+		f.rows[file] = append(f.rows[file], i)
+	}
+
+	// Stored unit vectors are normalized, so their mean points at the
+	// file's overall topic; Add normalizes the centroid itself
+	for _, file := range order {
+		centroid := make([]float32, units.dimension)
+		for _, row := range f.rows[file] {
+			vector := units.vectors[row*units.dimension : (row+1)*units.dimension]
+			for j, x := range vector {
+				centroid[j] += x
+			}
+		}
+		_ = f.files.Add(file, centroid, types.EmbeddingUnit{L1Data: types.ModuleInfo{Path: file}})
+	}
+
+	return f
+}
+
+// Files returns the number of files in the index
+func (f *FileIndex) Files() int {
+	return f.files.Count()
+}
+
+// Search ranks files by centroid similarity, then returns the top-k units
+// found in the best files, scored against their own vectors
+func (f *FileIndex) Search(query []float32, files, k int) ([]SearchResult, error) {
+	if len(query) != f.units.dimension {
+		return nil, fmt.Errorf("query dimension mismatch: expected %d, got %d", f.units.dimension, len(query))
+	}
+	if k <= 0 {
+		return nil, fmt.Errorf("k must be positive, got %d", k)
+	}
+	if files <= 0 {
+		return nil, fmt.Errorf("files must be positive, got %d", files)
+	}
+	if f.files.Count() == 0 {
+		return []SearchResult{}, nil
+	}
+
+	query = append([]float32(nil), query...)
+	if norm := normalize(query); norm > 0 {
+		for i := range query {
+			query[i] *= norm
+		}
+	}
+
+	topFiles, err := f.files.Search(append([]float32(nil), query...), files)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []SearchResult
+	for _, file := range topFiles {
+		for _, row := range f.rows[file.ID] {
+			vector := f.units.vectors[row*f.units.dimension : (row+1)*f.units.dimension]
+			results = append(results, SearchResult{
+				ID:       f.units.ids[row],
+				Metadata: f.units.metadata[row],
+				Score:    cosineSimilarity(query, vector),
+			})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
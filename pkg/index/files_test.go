@@ -0,0 +1,83 @@
+package index
+
+import (
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+func filePath(unit types.EmbeddingUnit) string {
+	return unit.L1Data.Path
+}
+
+func TestFileIndexSearch(t *testing.T) {
+	idx := NewVectorIndex(3)
+	units := []struct {
+		id     string
+		path   string
+		vector []float32
+	}{
+		{"auth.Login", "auth.go", []float32{1, 0, 0}},
+		{"auth.Logout", "auth.go", []float32{0.9, 0.1, 0}},
+		{"db.Query", "db.go", []float32{0, 1, 0}},
+		// A stray unit that matches the query well in an otherwise unrelated file
+		{"util.LoginHelper", "util.go", []float32{0.8, 0, 0.6}},
+		{"util.Format", "util.go", []float32{0, 0, 1}},
+		{"util.Pad", "util.go", []float32{0, 0.1, 1}},
+		{"orphan", "", []float32{1, 0, 0}},
+	}
+	for _, u := range units {
+		if err := idx.Add(u.id, u.vector, types.EmbeddingUnit{L1Data: types.ModuleInfo{Path: u.path}}); err != nil {
+			t.Fatalf("Add(%s) failed: %v", u.id, err)
+		}
+	}
+
+	files := NewFileIndex(idx, filePath)
+	if files.Files() != 3 {
+		t.Fatalf("Files() = %d, want 3", files.Files())
+	}
+
+	query := []float32{1, 0, 0}
+	results, err := files.Search(query, 1, 5)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected the 2 units of the top file, got %d", len(results))
+	}
+	if results[0].ID != "auth.Login" || results[1].ID != "auth.Logout" {
+		t.Errorf("Unexpected order: %s, %s", results[0].ID, results[1].ID)
+	}
+	if results[0].Score < 0.99 {
+		t.Errorf("Expected unit-level score near 1, got %f", results[0].Score)
+	}
+	if query[0] != 1 || query[1] != 0 {
+		t.Errorf("Search modified the query: %v", query)
+	}
+
+	results, err = files.Search(query, 2, 3)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 3 || results[2].ID != "util.LoginHelper" {
+		t.Errorf("Expected util.LoginHelper third when two files are searched, got %+v", results)
+	}
+
+	if _, err := files.Search([]float32{1, 0}, 1, 1); err == nil {
+		t.Error("Expected dimension mismatch error")
+	}
+	if _, err := files.Search(query, 0, 1); err == nil {
+		t.Error("Expected error for zero files")
+	}
+}
+
+func TestFileIndexEmpty(t *testing.T) {
+	files := NewFileIndex(NewVectorIndex(3), filePath)
+	results, err := files.Search([]float32{1, 0, 0}, 5, 5)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no results, got %d", len(results))
+	}
+}
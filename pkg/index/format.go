@@ -0,0 +1,69 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// FormatVersion is the version of the layout Save and WriteTo write. Bump
+// it when indexData or the unit payload changes in a way older code can't
+// read, and add the migration from the previous version.
+const FormatVersion = 2
+
+// ErrFormat is returned when an index file can't be read by this version:
+// it was written by a newer one, or it does not decode. Rebuilding the
+// index replaces the file.
+var ErrFormat = errors.New("unsupported index format")
+
+// migrations upgrade decoded index data one version at a time:
+// migrations[n] turns version n into version n+1
+var migrations = []func(*indexData) error{
+	// Version 0 was written before the version was recorded and has the
+	// layout of version 1
+	func(*indexData) error { return nil },
+	// Version 2 added quantized vectors; version 1 indexes hold float
+	// vectors, which it reads as they are
+	func(*indexData) error { return nil },
+}
+
+// decodeData decodes, migrates and checks the index data read by dec
+func decodeData(dec *msgpack.Decoder) (indexData, error) {
+	var data indexData
+	if err := dec.Decode(&data); err != nil {
+		return indexData{}, fmt.Errorf("%w: decoding index: %w", ErrFormat, err)
+	}
+	if data.Version > FormatVersion {
+		return indexData{}, fmt.Errorf("%w: index format %d is newer than format %d, the latest this version reads", ErrFormat, data.Version, FormatVersion)
+	}
+	for data.Version < FormatVersion {
+		if err := migrations[data.Version](&data); err != nil {
+			return indexData{}, fmt.Errorf("%w: upgrading index format %d: %w", ErrFormat, data.Version, err)
+		}
+		data.Version++
+	}
+
+	switch data.Quantization {
+	case "":
+	case QuantizationInt8:
+		if len(data.Codes) != len(data.IDs)*data.Dimension || len(data.Scales) != len(data.IDs) {
+			return indexData{}, fmt.Errorf("%w: %d ids, %d scales and %d int8 codes of dimension %d don't match",
+				ErrFormat, len(data.IDs), len(data.Scales), len(data.Codes), data.Dimension)
+		}
+		data.Vectors = make([]float32, len(data.Codes))
+		for i, scale := range data.Scales {
+			start, end := i*data.Dimension, (i+1)*data.Dimension
+			dequantizeInt8(data.Codes[start:end], scale, data.Vectors[start:end])
+		}
+		data.Codes, data.Scales = nil, nil
+	default:
+		return indexData{}, fmt.Errorf("%w: unknown vector quantization %q", ErrFormat, data.Quantization)
+	}
+
+	if len(data.Metadata) != len(data.IDs) || len(data.Vectors) != len(data.IDs)*data.Dimension {
+		return indexData{}, fmt.Errorf("%w: %d ids, %d payloads and %d vector values of dimension %d don't match",
+			ErrFormat, len(data.IDs), len(data.Metadata), len(data.Vectors), data.Dimension)
+	}
+	return data, nil
+}
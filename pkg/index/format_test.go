@@ -0,0 +1,69 @@
+package index
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestLoadFormatVersions(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data any) string {
+		t.Helper()
+		encoded, err := msgpack.Marshal(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, encoded, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	unit := types.EmbeddingUnit{L1Data: types.ModuleInfo{Path: "a.py"}}
+
+	// Indexes written before versions were recorded have no "v" field
+	legacy := write("legacy.msgpack", map[string]any{
+		"d":    2,
+		"ids":  []string{"a"},
+		"vecs": []float32{1, 0},
+		"meta": []types.EmbeddingUnit{unit},
+	})
+	v := NewVectorIndex(0)
+	if err := v.Load(legacy); err != nil {
+		t.Fatalf("loading a legacy index: %v", err)
+	}
+	if _, got, ok := v.Get("a"); !ok || got.L1Data.Path != "a.py" {
+		t.Errorf("legacy index lost its entry: %+v, %v", got, ok)
+	}
+
+	saved := filepath.Join(dir, "saved.msgpack")
+	if err := v.Save(saved); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := os.ReadFile(saved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var header struct {
+		Version int `msgpack:"v"`
+	}
+	if err := msgpack.Unmarshal(raw, &header); err != nil || header.Version != FormatVersion {
+		t.Errorf("saved format = %d (%v), want %d", header.Version, err, FormatVersion)
+	}
+
+	for name, data := range map[string]any{
+		"newer":     indexData{Version: FormatVersion + 1, Dimension: 2},
+		"truncated": indexData{Version: FormatVersion, Dimension: 2, IDs: []string{"a"}, Vectors: []float32{1}, Metadata: []types.EmbeddingUnit{unit}},
+		"garbage":   "not an index",
+	} {
+		err := NewVectorIndex(0).Load(write(name+".msgpack", data))
+		if !errors.Is(err, ErrFormat) {
+			t.Errorf("loading a %s index: err = %v, want ErrFormat", name, err)
+		}
+	}
+}
@@ -0,0 +1,451 @@
+package index
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Default HNSW parameters
+const (
+	DefaultHNSWM              = 16
+	DefaultHNSWEfConstruction = 100
+	DefaultHNSWEfSearch       = 64
+)
+
+// ErrStaleHNSW is returned by LoadHNSW when a saved graph no longer matches
+// the vector index it was built from
+var ErrStaleHNSW = errors.New("hnsw graph does not match the index")
+
+// HNSWOptions tunes an HNSW graph
+type HNSWOptions struct {
+	// M is the number of links per node on upper layers; layer 0 keeps 2*M
+	M int
+	// EfConstruction is the candidate list size while building. Higher
+	// values build a better graph, more slowly.
+	EfConstruction int
+	// EfSearch is the candidate list size while searching; it is raised to
+	// k when smaller. Higher values improve recall at some speed cost.
+	EfSearch int
+	// Seed makes level assignment, and so the graph, reproducible
+	Seed int64
+}
+
+// DefaultHNSWOptions returns the default HNSW parameters
+func DefaultHNSWOptions() HNSWOptions {
+	return HNSWOptions{
+		M:              DefaultHNSWM,
+		EfConstruction: DefaultHNSWEfConstruction,
+		EfSearch:       DefaultHNSWEfSearch,
+		Seed:           1,
+	}
+}
+
+func (o HNSWOptions) withDefaults() HNSWOptions {
+	if o.M <= 0 {
+		o.M = DefaultHNSWM
+	}
+	if o.EfConstruction <= 0 {
+		o.EfConstruction = DefaultHNSWEfConstruction
+	}
+	if o.EfSearch <= 0 {
+		o.EfSearch = DefaultHNSWEfSearch
+	}
+	return o
+}
+
+// HNSW is an approximate nearest neighbour backend over the vectors of a
+// VectorIndex, using a Hierarchical Navigable Small World graph. Queries
+// visit a small fraction of the vectors, so search time grows roughly
+// logarithmically with the index size.
+//
+// The graph is built from the index's live entries. Entries removed from the
+// index afterwards are skipped; entries added, updated or moved by Compact
+// are not reflected until the graph is rebuilt.
+type HNSW struct {
+	index *VectorIndex
+	opts  HNSWOptions
+
+	ids      []string    // node -> entry ID, to detect a changed index
+	rows     []int       // node -> VectorIndex row
+	levels   []int       // node -> top layer
+	links    [][][]int32 // node -> layer -> neighbour nodes
+	entry    int32       // entry point node, -1 when empty
+	maxLevel int
+}
+
+// NewHNSW builds an HNSW graph over the live entries of v
+func NewHNSW(v *VectorIndex, opts HNSWOptions) *HNSW {
+	opts = opts.withDefaults()
+	h := &HNSW{
+		index: v,
+		opts:  opts,
+		entry: -1,
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	levelMult := 1 / math.Log(float64(opts.M))
+
+	for row, id := range v.ids {
+		if v.removed[row] {
+			continue
+		}
+		node := int32(len(h.rows))
+		level := int(-math.Log(1-rng.Float64()) * levelMult)
+		h.ids = append(h.ids, id)
+		h.rows = append(h.rows, row)
+		h.levels = append(h.levels, level)
+		h.links = append(h.links, make([][]int32, level+1))
+		h.insert(node)
+	}
+
+	return h
+}
+
+// Count returns the number of nodes in the graph
+func (h *HNSW) Count() int {
+	return len(h.rows)
+}
+
+// Dimension returns the vector dimension
+func (h *HNSW) Dimension() int {
+	return h.index.dimension
+}
+
+// Search returns the approximate top-k entries most similar to query
+func (h *HNSW) Search(query []float32, k int) ([]SearchResult, error) {
+	results, _, err := h.SearchBefore(query, k, time.Time{})
+	return results, err
+}
+
+// SearchBefore is Search, except that the walk of the bottom layer stops
+// once deadline passes and the closest entries reached so far are returned
+// as partial
+func (h *HNSW) SearchBefore(query []float32, k int, deadline time.Time) ([]SearchResult, bool, error) {
+	if len(query) != h.index.dimension {
+		return nil, false, fmt.Errorf("query dimension mismatch: expected %d, got %d", h.index.dimension, len(query))
+	}
+	if k <= 0 {
+		return nil, false, fmt.Errorf("k must be positive, got %d", k)
+	}
+	if h.entry < 0 {
+		return []SearchResult{}, false, nil
+	}
+
+	q := append([]float32(nil), query...)
+	if norm := normalize(q); norm > 0 {
+		for i := range q {
+			q[i] *= norm
+		}
+	}
+
+	ef := h.opts.EfSearch
+	if ef < k {
+		ef = k
+	}
+	found, partial := h.searchLayer(q, h.descend(q, 0), ef, 0, deadline)
+
+	results := make([]SearchResult, 0, k)
+	for _, c := range found {
+		row := h.rows[c.node]
+		// Skip entries removed or replaced since the graph was built
+		if row >= len(h.index.ids) || h.index.removed[row] || h.index.ids[row] != h.ids[c.node] {
+			continue
+		}
+		results = append(results, SearchResult{
+			ID:       h.index.ids[row],
+			Metadata: h.index.metadata[row],
+			Score:    c.sim,
+		})
+		if len(results) == k {
+			break
+		}
+	}
+	return results, partial, nil
+}
+
+// insert links node into the graph
+func (h *HNSW) insert(node int32) {
+	level := h.levels[node]
+	if h.entry < 0 {
+		h.entry = node
+		h.maxLevel = level
+		return
+	}
+
+	q := h.vector(node)
+	entries := h.descend(q, level)
+	for layer := min(level, h.maxLevel); layer >= 0; layer-- {
+		found, _ := h.searchLayer(q, entries, h.opts.EfConstruction, layer, time.Time{})
+		neighbours := h.selectNeighbours(found, h.opts.M)
+		h.links[node][layer] = neighbours
+
+		for _, n := range neighbours {
+			h.links[n][layer] = append(h.links[n][layer], node)
+			if len(h.links[n][layer]) > h.maxLinks(layer) {
+				h.shrink(n, layer)
+			}
+		}
+		entries = found
+	}
+
+	if level > h.maxLevel {
+		h.maxLevel = level
+		h.entry = node
+	}
+}
+
+// descend greedily walks from the entry point down to layer stop+1 and
+// returns the closest node found as the entry for the layers below
+func (h *HNSW) descend(q []float32, stop int) []candidate {
+	best := candidate{node: h.entry, sim: cosineSimilarity(q, h.vector(h.entry))}
+	for layer := h.maxLevel; layer > stop; layer-- {
+		for changed := true; changed; {
+			changed = false
+			for _, n := range h.neighbours(best.node, layer) {
+				if sim := cosineSimilarity(q, h.vector(n)); sim > best.sim {
+					best = candidate{node: n, sim: sim}
+					changed = true
+				}
+			}
+		}
+	}
+	return []candidate{best}
+}
+
+// searchLayer returns up to ef nodes on layer closest to q, best first.
+// With a non-zero deadline the walk stops once it passes, and the nodes
+// reached so far are returned as partial.
+func (h *HNSW) searchLayer(q []float32, entries []candidate, ef, layer int, deadline time.Time) ([]candidate, bool) {
+	visited := make(map[int32]struct{}, ef*4)
+	candidates := &maxHeap{}
+	results := &minHeap{}
+	for _, e := range entries {
+		visited[e.node] = struct{}{}
+		heap.Push(candidates, e)
+		heap.Push(results, e)
+		if results.Len() > ef {
+			heap.Pop(results)
+		}
+	}
+
+	partial := false
+	scored, check := 0, deadlineCheckEvery
+	for candidates.Len() > 0 {
+		if !deadline.IsZero() && scored >= check {
+			if time.Now().After(deadline) {
+				partial = true
+				break
+			}
+			check = scored + deadlineCheckEvery
+		}
+		c := heap.Pop(candidates).(candidate)
+		if results.Len() >= ef && c.sim < (*results)[0].sim {
+			break
+		}
+		for _, n := range h.neighbours(c.node, layer) {
+			if _, ok := visited[n]; ok {
+				continue
+			}
+			visited[n] = struct{}{}
+			scored++
+			sim := cosineSimilarity(q, h.vector(n))
+			if results.Len() < ef || sim > (*results)[0].sim {
+				heap.Push(candidates, candidate{node: n, sim: sim})
+				heap.Push(results, candidate{node: n, sim: sim})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	found := make([]candidate, results.Len())
+	for i := len(found) - 1; i >= 0; i-- {
+		found[i] = heap.Pop(results).(candidate)
+	}
+	return found, partial
+}
+
+// selectNeighbours picks up to m nodes from candidates (best first) using
+// the HNSW heuristic: a candidate is kept only if it is closer to the query
+// than to every node already kept, which spreads links across directions.
+// Remaining slots are filled with the closest pruned candidates.
+func (h *HNSW) selectNeighbours(candidates []candidate, m int) []int32 {
+	selected := make([]int32, 0, m)
+	var pruned []int32
+	for _, c := range candidates {
+		if len(selected) == m {
+			break
+		}
+		keep := true
+		vec := h.vector(c.node)
+		for _, s := range selected {
+			if cosineSimilarity(vec, h.vector(s)) > c.sim {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			selected = append(selected, c.node)
+		} else {
+			pruned = append(pruned, c.node)
+		}
+	}
+	for _, n := range pruned {
+		if len(selected) == m {
+			break
+		}
+		selected = append(selected, n)
+	}
+	return selected
+}
+
+// shrink trims the links of node on layer back to the layer's maximum
+func (h *HNSW) shrink(node int32, layer int) {
+	vec := h.vector(node)
+	links := h.links[node][layer]
+	candidates := make([]candidate, len(links))
+	for i, n := range links {
+		candidates[i] = candidate{node: n, sim: cosineSimilarity(vec, h.vector(n))}
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].sim > candidates[j].sim })
+	h.links[node][layer] = h.selectNeighbours(candidates, h.maxLinks(layer))
+}
+
+func (h *HNSW) maxLinks(layer int) int {
+	if layer == 0 {
+		return 2 * h.opts.M
+	}
+	return h.opts.M
+}
+
+func (h *HNSW) neighbours(node int32, layer int) []int32 {
+	if layer >= len(h.links[node]) {
+		return nil
+	}
+	return h.links[node][layer]
+}
+
+func (h *HNSW) vector(node int32) []float32 {
+	start := h.rows[node] * h.index.dimension
+	return h.index.vectors[start : start+h.index.dimension]
+}
+
+// hnswData is the serialized structure for persistence
+type hnswData struct {
+	M              int         `msgpack:"m"`
+	EfConstruction int         `msgpack:"efc"`
+	IDs            []string    `msgpack:"ids"`
+	Rows           []int       `msgpack:"rows"`
+	Levels         []int       `msgpack:"levels"`
+	Links          [][][]int32 `msgpack:"links"`
+	Entry          int32       `msgpack:"entry"`
+	MaxLevel       int         `msgpack:"max_level"`
+}
+
+// Save persists the graph to a file using msgpack. Vectors are not saved;
+// they are read from the VectorIndex when the graph is loaded.
+func (h *HNSW) Save(path string) error {
+	data := hnswData{
+		M:              h.opts.M,
+		EfConstruction: h.opts.EfConstruction,
+		IDs:            h.ids,
+		Rows:           h.rows,
+		Levels:         h.levels,
+		Links:          h.links,
+		Entry:          h.entry,
+		MaxLevel:       h.maxLevel,
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	if err := msgpack.NewEncoder(file).Encode(&data); err != nil {
+		return fmt.Errorf("failed to encode hnsw graph: %w", err)
+	}
+	return nil
+}
+
+// LoadHNSW loads a graph saved by Save for the vector index v. Build
+// parameters come from the file; opts only sets EfSearch. It returns
+// ErrStaleHNSW when the index has changed since the graph was built.
+func LoadHNSW(path string, v *VectorIndex, opts HNSWOptions) (*HNSW, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	var data hnswData
+	if err := msgpack.NewDecoder(file).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode hnsw graph: %w", err)
+	}
+
+	nodes := len(data.IDs)
+	if len(data.Rows) != nodes || len(data.Levels) != nodes || len(data.Links) != nodes || nodes != v.Count() {
+		return nil, ErrStaleHNSW
+	}
+	for i, row := range data.Rows {
+		if row >= len(v.ids) || v.removed[row] || v.ids[row] != data.IDs[i] {
+			return nil, ErrStaleHNSW
+		}
+	}
+
+	opts.M = data.M
+	opts.EfConstruction = data.EfConstruction
+	return &HNSW{
+		index:    v,
+		opts:     opts.withDefaults(),
+		ids:      data.IDs,
+		rows:     data.Rows,
+		levels:   data.Levels,
+		links:    data.Links,
+		entry:    data.Entry,
+		maxLevel: data.MaxLevel,
+	}, nil
+}
+
+// candidate is a graph node with its similarity to the query
+type candidate struct {
+	node int32
+	sim  float32
+}
+
+// maxHeap pops the most similar candidate first
+type maxHeap []candidate
+
+func (h maxHeap) Len() int           { return len(h) }
+func (h maxHeap) Less(i, j int) bool { return h[i].sim > h[j].sim }
+func (h maxHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *maxHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// minHeap pops the least similar candidate first
+type minHeap []candidate
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return h[i].sim < h[j].sim }
+func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *minHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
@@ -0,0 +1,164 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// randomIndex builds an index of n random vectors
+func randomIndex(n, dimension int, seed int64) *VectorIndex {
+	rng := rand.New(rand.NewSource(seed))
+	idx := NewVectorIndex(dimension)
+	for i := 0; i < n; i++ {
+		vec := make([]float32, dimension)
+		for j := range vec {
+			vec[j] = rng.Float32()*2 - 1
+		}
+		idx.Add(fmt.Sprintf("id%d", i), vec, types.EmbeddingUnit{L1Data: types.ModuleInfo{Path: fmt.Sprintf("file%d.go", i)}})
+	}
+	return idx
+}
+
+func randomQuery(dimension int, rng *rand.Rand) []float32 {
+	q := make([]float32, dimension)
+	for j := range q {
+		q[j] = rng.Float32()*2 - 1
+	}
+	return q
+}
+
+func TestHNSWRecall(t *testing.T) {
+	const dimension, k = 32, 10
+	idx := randomIndex(3000, dimension, 7)
+	graph := NewHNSW(idx, DefaultHNSWOptions())
+	if graph.Count() != 3000 || graph.Dimension() != dimension {
+		t.Fatalf("Count() = %d, Dimension() = %d", graph.Count(), graph.Dimension())
+	}
+
+	rng := rand.New(rand.NewSource(99))
+	var hits, total int
+	for i := 0; i < 50; i++ {
+		q := randomQuery(dimension, rng)
+		exact, err := idx.Search(append([]float32(nil), q...), k)
+		if err != nil {
+			t.Fatalf("flat Search failed: %v", err)
+		}
+		approx, err := graph.Search(q, k)
+		if err != nil {
+			t.Fatalf("HNSW Search failed: %v", err)
+		}
+		if len(approx) != k {
+			t.Fatalf("expected %d results, got %d", k, len(approx))
+		}
+		for j := 1; j < len(approx); j++ {
+			if approx[j].Score > approx[j-1].Score {
+				t.Fatal("results not sorted by score descending")
+			}
+		}
+
+		want := make(map[string]bool, k)
+		for _, r := range exact {
+			want[r.ID] = true
+		}
+		for _, r := range approx {
+			if want[r.ID] {
+				hits++
+			}
+		}
+		total += k
+	}
+
+	if recall := float64(hits) / float64(total); recall < 0.9 {
+		t.Errorf("recall@%d = %.2f, want at least 0.9", k, recall)
+	}
+}
+
+func TestHNSWSkipsRemoved(t *testing.T) {
+	idx := NewVectorIndex(3)
+	idx.Add("x", []float32{1, 0, 0}, types.EmbeddingUnit{})
+	idx.Add("y", []float32{0, 1, 0}, types.EmbeddingUnit{})
+	idx.Add("z", []float32{0, 0, 1}, types.EmbeddingUnit{})
+	graph := NewHNSW(idx, DefaultHNSWOptions())
+
+	idx.Remove("x")
+	results, err := graph.Search([]float32{1, 0.1, 0}, 3)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	for _, r := range results {
+		if r.ID == "x" {
+			t.Error("Search returned a removed entry")
+		}
+	}
+	if len(results) != 2 || results[0].ID != "y" {
+		t.Errorf("unexpected results: %+v", results)
+	}
+
+	if _, err := graph.Search([]float32{1, 0}, 1); err == nil {
+		t.Error("expected dimension mismatch error")
+	}
+	if _, err := graph.Search([]float32{1, 0, 0}, 0); err == nil {
+		t.Error("expected error for zero k")
+	}
+}
+
+func TestHNSWEmpty(t *testing.T) {
+	graph := NewHNSW(NewVectorIndex(3), DefaultHNSWOptions())
+	results, err := graph.Search([]float32{1, 0, 0}, 5)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("expected no results, got %d", len(results))
+	}
+}
+
+func TestHNSWSaveLoad(t *testing.T) {
+	idx := randomIndex(500, 16, 3)
+	graph := NewHNSW(idx, DefaultHNSWOptions())
+	path := filepath.Join(t.TempDir(), "hnsw.msgpack")
+	if err := graph.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadHNSW(path, idx, HNSWOptions{EfSearch: 100})
+	if err != nil {
+		t.Fatalf("LoadHNSW failed: %v", err)
+	}
+	q := randomQuery(16, rand.New(rand.NewSource(5)))
+	want, _ := graph.Search(q, 5)
+	got, _ := loaded.Search(q, 5)
+	if len(got) != len(want) || got[0].ID != want[0].ID {
+		t.Errorf("loaded graph returned %v, want %v", got, want)
+	}
+
+	idx.Add("new", q, types.EmbeddingUnit{})
+	if _, err := LoadHNSW(path, idx, HNSWOptions{}); !errors.Is(err, ErrStaleHNSW) {
+		t.Errorf("expected ErrStaleHNSW after the index changed, got %v", err)
+	}
+}
+
+func TestBackendOptionsUseHNSW(t *testing.T) {
+	tests := []struct {
+		opts  BackendOptions
+		count int
+		want  bool
+	}{
+		{BackendOptions{Kind: BackendFlat}, 1000000, false},
+		{BackendOptions{Kind: BackendHNSW}, 10, true},
+		{BackendOptions{Kind: BackendAuto, AutoThreshold: 100}, 99, false},
+		{BackendOptions{Kind: BackendAuto, AutoThreshold: 100}, 100, true},
+		{BackendOptions{}, DefaultHNSWThreshold, true},
+		{BackendOptions{}, DefaultHNSWThreshold - 1, false},
+	}
+	for _, tt := range tests {
+		if got := tt.opts.UseHNSW(tt.count); got != tt.want {
+			t.Errorf("%+v.UseHNSW(%d) = %v, want %v", tt.opts, tt.count, got, tt.want)
+		}
+	}
+}
@@ -3,26 +3,44 @@
 package index
 
 import (
+	"container/heap"
 	"fmt"
 	"io"
 	"math"
 	"os"
+	"runtime"
 	"sort"
 	"sync"
+	"sync/atomic"
 
 	"github.com/l3aro/go-context-query/pkg/types"
 	"github.com/vmihailenco/msgpack/v5"
 )
 
-// VectorIndex is an in-memory vector store with brute-force search
+// VectorIndex is an in-memory vector store with brute-force search.
+//
+// Removed entries are tombstoned and skipped by reads; their storage is
+// reclaimed by Compact. Save and WriteTo only persist live entries.
 type VectorIndex struct {
 	vectors   []float32 // Flattened vectors: [v1[0], v1[1], ..., v1[dim-1], v2[0], ...]
 	metadata  []types.EmbeddingUnit
 	ids       []string
 	idIndex   map[string]int // O(1) ID to index lookup
 	dimension int
+
+	removed      []bool // Tombstones, parallel to ids
+	removedCount int
+
+	// quantization is how saved vectors are stored; see SetQuantization
+	quantization string
+
+	// generation stamps the current contents; see Generation
+	generation atomic.Uint64
 }
 
+// generations hands out generation stamps, unique across all indexes
+var generations atomic.Uint64
+
 // SearchResult represents a single search result
 type SearchResult struct {
 	ID       string
@@ -32,13 +50,29 @@ type SearchResult struct {
 
 // NewVectorIndex creates a new VectorIndex with the specified dimension
 func NewVectorIndex(dimension int) *VectorIndex {
-	return &VectorIndex{
+	v := &VectorIndex{
 		dimension: dimension,
 		vectors:   make([]float32, 0, dimension*100), // Pre-allocate for 100 vectors
 		metadata:  make([]types.EmbeddingUnit, 0, 100),
 		ids:       make([]string, 0, 100),
 		idIndex:   make(map[string]int, 100),
+		removed:   make([]bool, 0, 100),
 	}
+	v.touch()
+	return v
+}
+
+// Generation identifies the current contents of the index. It changes
+// whenever entries are added, updated or removed, or the index is loaded,
+// and no two indexes in the process share one, so it can key caches of
+// search results.
+func (v *VectorIndex) Generation() uint64 {
+	return v.generation.Load()
+}
+
+// touch gives the index a new generation after its contents change
+func (v *VectorIndex) touch() {
+	v.generation.Store(generations.Add(1))
 }
 
 // Dimension returns the vector dimension
@@ -48,10 +82,17 @@ func (v *VectorIndex) Dimension() int {
 
 // Count returns the number of vectors in the index
 func (v *VectorIndex) Count() int {
-	return len(v.ids)
+	return len(v.ids) - v.removedCount
+}
+
+// Removed returns the number of removed entries still holding storage.
+// Compact reclaims them.
+func (v *VectorIndex) Removed() int {
+	return v.removedCount
 }
 
-// Add adds a normalized vector with metadata to the index
+// Add adds a normalized vector with metadata to the index. Adding an ID
+// that is already present replaces the existing entry.
 func (v *VectorIndex) Add(id string, vector []float32, metadata types.EmbeddingUnit) error {
 	if len(vector) != v.dimension {
 		return fmt.Errorf("vector dimension mismatch: expected %d, got %d", v.dimension, len(vector))
@@ -65,14 +106,129 @@ func (v *VectorIndex) Add(id string, vector []float32, metadata types.EmbeddingU
 		}
 	}
 
+	v.Remove(id)
+
 	v.idIndex[id] = len(v.ids)
 	v.ids = append(v.ids, id)
 	v.vectors = append(v.vectors, vector...)
 	v.metadata = append(v.metadata, metadata)
+	v.removed = append(v.removed, false)
+	v.touch()
+
+	return nil
+}
+
+// Update replaces the vector and metadata of an entry in place, or adds it
+// if the ID is not present
+func (v *VectorIndex) Update(id string, vector []float32, metadata types.EmbeddingUnit) error {
+	i, ok := v.idIndex[id]
+	if !ok {
+		return v.Add(id, vector, metadata)
+	}
+	if len(vector) != v.dimension {
+		return fmt.Errorf("vector dimension mismatch: expected %d, got %d", v.dimension, len(vector))
+	}
+
+	start := i * v.dimension
+	copy(v.vectors[start:start+v.dimension], vector)
+	stored := v.vectors[start : start+v.dimension]
+	if norm := normalize(stored); norm > 0 {
+		for j := range stored {
+			stored[j] *= norm
+		}
+	}
+	v.metadata[i] = metadata
+	v.touch()
 
 	return nil
 }
 
+// Remove tombstones an entry so it is no longer returned by Get, Search or
+// IterVectors. It reports whether the ID was present. Call Compact to
+// reclaim the storage of removed entries.
+func (v *VectorIndex) Remove(id string) bool {
+	i, ok := v.idIndex[id]
+	if !ok {
+		return false
+	}
+
+	delete(v.idIndex, id)
+	v.removed[i] = true
+	v.removedCount++
+	// Release metadata now; it can be large
+	v.metadata[i] = types.EmbeddingUnit{}
+	v.touch()
+
+	return true
+}
+
+// Compact rewrites the backing store without removed entries and returns
+// the number of entries reclaimed
+func (v *VectorIndex) Compact() int {
+	reclaimed := v.removedCount
+	if reclaimed == 0 {
+		return 0
+	}
+
+	live := 0
+	for i, id := range v.ids {
+		if v.removed[i] {
+			continue
+		}
+		if live != i {
+			v.ids[live] = id
+			v.metadata[live] = v.metadata[i]
+			copy(v.vectors[live*v.dimension:(live+1)*v.dimension], v.vectors[i*v.dimension:(i+1)*v.dimension])
+		}
+		v.idIndex[id] = live
+		live++
+	}
+
+	// Clear the tail so dropped metadata can be garbage collected
+	for i := live; i < len(v.metadata); i++ {
+		v.metadata[i] = types.EmbeddingUnit{}
+	}
+	v.ids = v.ids[:live]
+	v.metadata = v.metadata[:live]
+	v.vectors = v.vectors[:live*v.dimension]
+	// Give back the memory of a store that shrank to less than half its
+	// capacity; appends would otherwise keep it until the index is reloaded
+	if cap(v.ids) > 2*live {
+		v.ids = append([]string(nil), v.ids...)
+		v.metadata = append([]types.EmbeddingUnit(nil), v.metadata...)
+		v.vectors = append([]float32(nil), v.vectors...)
+	}
+	v.removed = make([]bool, live)
+	v.removedCount = 0
+
+	return reclaimed
+}
+
+// CompactionPolicy decides when an index has accumulated enough removed
+// entries to be worth compacting
+type CompactionPolicy struct {
+	// MinRemoved is the fewest removed entries worth compacting
+	MinRemoved int
+	// Ratio is the share of stored entries, live and removed, that must be
+	// removed before compacting (0 to 1)
+	Ratio float64
+}
+
+// DefaultCompactionPolicy compacts once at least 100 entries, and a quarter
+// of the store, are removed
+func DefaultCompactionPolicy() CompactionPolicy {
+	return CompactionPolicy{MinRemoved: 100, Ratio: 0.25}
+}
+
+// Due reports whether v should be compacted under the policy
+func (p CompactionPolicy) Due(v *VectorIndex) bool {
+	removed := v.Removed()
+	if removed == 0 || removed < p.MinRemoved {
+		return false
+	}
+	return float64(removed) >= p.Ratio*float64(removed+v.Count())
+}
+
 // normalize computes the L2 norm (inverse) of a vector
 func normalize(vector []float32) float32 {
 	var sum float32
@@ -88,11 +244,7 @@ func normalize(vector []float32) float32 {
 // cosineSimilarity computes cosine similarity between two vectors
 // Both vectors should be normalized for true cosine similarity
 func cosineSimilarity(a, b []float32) float32 {
-	var dotProduct float32
-	for i := 0; i < len(a); i++ {
-		dotProduct += a[i] * b[i]
-	}
-	return dotProduct
+	return dot(a, b)
 }
 
 // cosineSimilarityWithNorm computes cosine similarity without pre-normalization
@@ -134,78 +286,157 @@ func (v *VectorIndex) Search(query []float32, k int) ([]SearchResult, error) {
 		}
 	}
 
-	// Compute similarity for all vectors in parallel
-	type scoredIndex struct {
-		index int
-		score float32
-	}
-
-	count := v.Count()
-	scores := make([]scoredIndex, count)
-	resultsChan := make(chan scoredIndex, count)
-	var wg sync.WaitGroup
-
-	for i := 0; i < count; i++ {
-		wg.Add(1)
-		go func(i int) {
-			defer wg.Done()
-			start := i * v.dimension
-			end := start + v.dimension
-			vector := v.vectors[start:end]
-
-			resultsChan <- scoredIndex{
-				index: i,
-				score: cosineSimilarity(query, vector),
-			}
-		}(i)
+	if k == 0 {
+		return []SearchResult{}, nil
 	}
 
-	// Wait for all goroutines to complete
-	go func() {
-		wg.Wait()
-		close(resultsChan)
-	}()
-
-	// Collect results
-	for scored := range resultsChan {
-		scores[scored.index] = scored
+	// Score every vector, then keep the best k, ties in insertion order
+	scores := make([]float32, len(v.ids))
+	v.score(query, scores)
+	best := make(worstFirst, 0, k)
+	for i, score := range scores {
+		if v.removed[i] {
+			continue
+		}
+		scored := scoredRow{row: int64(i), score: score}
+		if len(best) < k {
+			heap.Push(&best, scored)
+		} else if score > best[0].score {
+			best[0] = scored
+			heap.Fix(&best, 0)
+		}
 	}
+	sort.Slice(best, func(i, j int) bool { return best.Less(j, i) })
 
-	// Sort by score descending
-	sort.Slice(scores, func(i, j int) bool {
-		return scores[i].score > scores[j].score
-	})
-
-	// Take top-k
-	results := make([]SearchResult, k)
-	for i := 0; i < k; i++ {
-		idx := scores[i].index
+	results := make([]SearchResult, len(best))
+	for i, scored := range best {
 		results[i] = SearchResult{
-			ID:       v.ids[idx],
-			Metadata: v.metadata[idx],
-			Score:    scores[i].score,
+			ID:       v.ids[scored.row],
+			Metadata: v.metadata[scored.row],
+			Score:    scored.score,
 		}
 	}
-
 	return results, nil
 }
 
+// minScoreChunk is the fewest vectors score hands to one goroutine; below
+// twice this an index is scored on the calling goroutine, where starting
+// goroutines would cost more than it saves
+const minScoreChunk = 4096
+
+// score writes the similarity of query to each vector into scores,
+// splitting large indexes into contiguous chunks across GOMAXPROCS
+// goroutines. Removed entries are scored too and left to the caller to skip.
+func (v *VectorIndex) score(query []float32, scores []float32) {
+	workers := min(runtime.GOMAXPROCS(0), len(scores)/minScoreChunk)
+	if workers < 2 {
+		v.scoreRange(query, scores, 0, len(scores))
+		return
+	}
+	chunk := (len(scores) + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := 0; start < len(scores); start += chunk {
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			v.scoreRange(query, scores, start, end)
+		}(start, min(start+chunk, len(scores)))
+	}
+	wg.Wait()
+}
+
+// scoreRange scores the vectors from start up to end into scores
+func (v *VectorIndex) scoreRange(query []float32, scores []float32, start, end int) {
+	for i := start; i < end; i++ {
+		offset := i * v.dimension
+		scores[i] = cosineSimilarity(query, v.vectors[offset:offset+v.dimension])
+	}
+}
+
 // indexData is the serialized structure for persistence
 type indexData struct {
+	// Version is the FormatVersion the data was written with; it is 0 for
+	// indexes written before versions were recorded
+	Version   int                   `msgpack:"v"`
 	Dimension int                   `msgpack:"d"`
 	IDs       []string              `msgpack:"ids"`
 	Vectors   []float32             `msgpack:"vecs"`
 	Metadata  []types.EmbeddingUnit `msgpack:"meta"`
+	// Quantization is QuantizationInt8 when Codes and Scales hold the
+	// vectors in place of Vectors, or empty
+	Quantization string    `msgpack:"q,omitempty"`
+	Codes        []byte    `msgpack:"codes,omitempty"`
+	Scales       []float32 `msgpack:"scales,omitempty"`
 }
 
-// Save persists the index to a file using msgpack
-func (v *VectorIndex) Save(path string) error {
+// liveData returns the serialized form of the index without removed entries
+func (v *VectorIndex) liveData() indexData {
+	if v.removedCount == 0 {
+		return indexData{
+			Version:   FormatVersion,
+			Dimension: v.dimension,
+			IDs:       v.ids,
+			Vectors:   v.vectors,
+			Metadata:  v.metadata,
+		}
+	}
+
+	live := v.Count()
 	data := indexData{
+		Version:   FormatVersion,
 		Dimension: v.dimension,
-		IDs:       v.ids,
-		Vectors:   v.vectors,
-		Metadata:  v.metadata,
+		IDs:       make([]string, 0, live),
+		Vectors:   make([]float32, 0, live*v.dimension),
+		Metadata:  make([]types.EmbeddingUnit, 0, live),
+	}
+	for i, id := range v.ids {
+		if v.removed[i] {
+			continue
+		}
+		data.IDs = append(data.IDs, id)
+		data.Vectors = append(data.Vectors, v.vectors[i*v.dimension:(i+1)*v.dimension]...)
+		data.Metadata = append(data.Metadata, v.metadata[i])
+	}
+	return data
+}
+
+// savedData returns the live entries as Save writes them, quantized when
+// the index is set to
+func (v *VectorIndex) savedData() indexData {
+	data := v.liveData()
+	if v.quantization != QuantizationInt8 {
+		return data
+	}
+	data.Quantization = v.quantization
+	data.Codes = make([]byte, len(data.Vectors))
+	data.Scales = make([]float32, len(data.IDs))
+	for i := range data.IDs {
+		start, end := i*data.Dimension, (i+1)*data.Dimension
+		data.Scales[i] = quantizeInt8(data.Vectors[start:end], data.Codes[start:end])
+	}
+	data.Vectors = nil
+	return data
+}
+
+// setData replaces the contents of the index with deserialized data
+func (v *VectorIndex) setData(data indexData) {
+	v.quantization = data.Quantization
+	v.dimension = data.Dimension
+	v.ids = data.IDs
+	v.vectors = data.Vectors
+	v.metadata = data.Metadata
+	v.removed = make([]bool, len(data.IDs))
+	v.removedCount = 0
+
+	for i, id := range v.ids {
+		v.idIndex[id] = i
 	}
+	v.touch()
+}
+
+// Save persists the index to a file using msgpack
+func (v *VectorIndex) Save(path string) error {
+	data := v.savedData()
 
 	file, err := os.Create(path)
 	if err != nil {
@@ -222,7 +453,8 @@ func (v *VectorIndex) Save(path string) error {
 	return nil
 }
 
-// Load restores the index from a file using msgpack
+// Load restores the index from a file using msgpack, upgrading indexes
+// written in an older format. Files it can't read return ErrFormat.
 func (v *VectorIndex) Load(path string) error {
 	file, err := os.Open(path)
 	if err != nil {
@@ -230,21 +462,12 @@ func (v *VectorIndex) Load(path string) error {
 	}
 	defer file.Close()
 
-	decoder := msgpack.NewDecoder(file)
-
-	var data indexData
-	if err := decoder.Decode(&data); err != nil {
-		return fmt.Errorf("failed to decode index: %w", err)
+	data, err := decodeData(msgpack.NewDecoder(file))
+	if err != nil {
+		return err
 	}
 
-	v.dimension = data.Dimension
-	v.ids = data.IDs
-	v.vectors = data.Vectors
-	v.metadata = data.Metadata
-
-	for i, id := range v.ids {
-		v.idIndex[id] = i
-	}
+	v.setData(data)
 
 	return nil
 }
@@ -283,30 +506,9 @@ func (v *VectorIndex) Get(id string) ([]float32, types.EmbeddingUnit, bool) {
 	return vector, v.metadata[i], true
 }
 
-// Delete removes a vector by ID
+// Delete removes a vector by ID. It is the same as Remove.
 func (v *VectorIndex) Delete(id string) bool {
-	i, ok := v.idIndex[id]
-	if !ok {
-		return false
-	}
-
-	delete(v.idIndex, id)
-
-	// Remove from all slices
-	v.ids = append(v.ids[:i], v.ids[i+1:]...)
-	v.metadata = append(v.metadata[:i], v.metadata[i+1:]...)
-
-	// Remove vector (shift all vectors after this one)
-	start := i * v.dimension
-	end := start + v.dimension
-	v.vectors = append(v.vectors[:start], v.vectors[end:]...)
-
-	// Rebuild idIndex for remaining elements
-	for j := i; j < len(v.ids); j++ {
-		v.idIndex[v.ids[j]] = j
-	}
-
-	return true
+	return v.Remove(id)
 }
 
 // Clear removes all vectors from the index
@@ -314,14 +516,20 @@ func (v *VectorIndex) Clear() {
 	v.ids = v.ids[:0]
 	v.metadata = v.metadata[:0]
 	v.vectors = v.vectors[:0]
+	v.removed = v.removed[:0]
+	v.removedCount = 0
 	for k := range v.idIndex {
 		delete(v.idIndex, k)
 	}
+	v.touch()
 }
 
-// IterVectors iterates over all vectors with their IDs and metadata
+// IterVectors iterates over all live vectors with their IDs and metadata
 func (v *VectorIndex) IterVectors(fn func(id string, vector []float32, metadata types.EmbeddingUnit) bool) {
-	for i := 0; i < v.Count(); i++ {
+	for i := 0; i < len(v.ids); i++ {
+		if v.removed[i] {
+			continue
+		}
 		start := i * v.dimension
 		end := start + v.dimension
 		vector := v.vectors[start:end]
@@ -334,12 +542,7 @@ func (v *VectorIndex) IterVectors(fn func(id string, vector []float32, metadata
 
 // WriteTo writes the index to an io.Writer in msgpack format
 func (v *VectorIndex) WriteTo(w io.Writer) (int64, error) {
-	data := indexData{
-		Dimension: v.dimension,
-		IDs:       v.ids,
-		Vectors:   v.vectors,
-		Metadata:  v.metadata,
-	}
+	data := v.savedData()
 
 	encoder := msgpack.NewEncoder(w)
 
@@ -352,21 +555,12 @@ func (v *VectorIndex) WriteTo(w io.Writer) (int64, error) {
 
 // ReadFrom reads the index from an io.Reader in msgpack format
 func (v *VectorIndex) ReadFrom(r io.Reader) (int64, error) {
-	decoder := msgpack.NewDecoder(r)
-
-	var data indexData
-	if err := decoder.Decode(&data); err != nil {
-		return 0, fmt.Errorf("failed to decode index: %w", err)
+	data, err := decodeData(msgpack.NewDecoder(r))
+	if err != nil {
+		return 0, err
 	}
 
-	v.dimension = data.Dimension
-	v.ids = data.IDs
-	v.vectors = data.Vectors
-	v.metadata = data.Metadata
-
-	for i, id := range v.ids {
-		v.idIndex[id] = i
-	}
+	v.setData(data)
 
 	return int64(len(v.ids)), nil
 }
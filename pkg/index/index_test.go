@@ -1,6 +1,7 @@
 package index
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -229,6 +230,187 @@ func TestVectorIndexDelete(t *testing.T) {
 	}
 }
 
+func TestVectorIndexRemoveAndCompact(t *testing.T) {
+	idx := NewVectorIndex(3)
+	idx.Add("doc1", []float32{1.0, 0.0, 0.0}, types.EmbeddingUnit{L1Data: types.ModuleInfo{Path: "a.go"}})
+	idx.Add("doc2", []float32{0.0, 1.0, 0.0}, types.EmbeddingUnit{L1Data: types.ModuleInfo{Path: "b.go"}})
+	idx.Add("doc3", []float32{0.0, 0.0, 1.0}, types.EmbeddingUnit{L1Data: types.ModuleInfo{Path: "c.go"}})
+
+	if !idx.Remove("doc1") {
+		t.Fatal("Remove() expected to return true for existing vector")
+	}
+	if idx.Remove("doc1") {
+		t.Error("Remove() expected to return false for a removed vector")
+	}
+	if idx.Count() != 2 || idx.Removed() != 1 {
+		t.Errorf("Count() = %d, Removed() = %d, want 2 and 1", idx.Count(), idx.Removed())
+	}
+
+	results, err := idx.Search([]float32{1.0, 0.0, 0.0}, 10)
+	if err != nil {
+		t.Fatalf("Search() unexpected error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Search() returned %d results, want 2", len(results))
+	}
+	for _, r := range results {
+		if r.ID == "doc1" {
+			t.Error("Search() returned a removed vector")
+		}
+	}
+
+	var iterated []string
+	idx.IterVectors(func(id string, _ []float32, _ types.EmbeddingUnit) bool {
+		iterated = append(iterated, id)
+		return true
+	})
+	if len(iterated) != 2 || iterated[0] != "doc2" {
+		t.Errorf("IterVectors() visited %v, want [doc2 doc3]", iterated)
+	}
+
+	if reclaimed := idx.Compact(); reclaimed != 1 {
+		t.Errorf("Compact() = %d, want 1", reclaimed)
+	}
+	if idx.Count() != 2 || idx.Removed() != 0 {
+		t.Errorf("after Compact() Count() = %d, Removed() = %d, want 2 and 0", idx.Count(), idx.Removed())
+	}
+	vec, meta, found := idx.Get("doc3")
+	if !found || vec[2] != 1.0 || meta.L1Data.Path != "c.go" {
+		t.Errorf("Get(doc3) after Compact() = %v, %+v, %v", vec, meta, found)
+	}
+	if idx.Compact() != 0 {
+		t.Error("Compact() on a compact index should reclaim nothing")
+	}
+}
+
+func TestCompactionPolicy(t *testing.T) {
+	idx := NewVectorIndex(2)
+	for i := range 8 {
+		idx.Add(fmt.Sprintf("doc%d", i), []float32{1, float32(i)}, types.EmbeddingUnit{})
+	}
+	policy := CompactionPolicy{MinRemoved: 2, Ratio: 0.25}
+
+	if policy.Due(idx) {
+		t.Error("Due() on an index without removed entries")
+	}
+	idx.Remove("doc0")
+	if policy.Due(idx) {
+		t.Error("Due() with fewer removed entries than MinRemoved")
+	}
+	idx.Remove("doc1")
+	if !policy.Due(idx) {
+		t.Error("Due() = false with 2 of 8 entries removed")
+	}
+
+	policy.Ratio = 0.5
+	if policy.Due(idx) {
+		t.Error("Due() = true below Ratio")
+	}
+
+	idx.Compact()
+	if (CompactionPolicy{}).Due(idx) {
+		t.Error("Due() after Compact()")
+	}
+	if _, _, found := idx.Get("doc7"); !found || idx.Count() != 6 {
+		t.Errorf("after Compact() Count() = %d, doc7 found = %v", idx.Count(), found)
+	}
+}
+
+func TestVectorIndexUpdate(t *testing.T) {
+	idx := NewVectorIndex(3)
+	idx.Add("doc1", []float32{1.0, 0.0, 0.0}, types.EmbeddingUnit{L1Data: types.ModuleInfo{Path: "old.go"}})
+
+	if err := idx.Update("doc1", []float32{0.0, 2.0, 0.0}, types.EmbeddingUnit{L1Data: types.ModuleInfo{Path: "new.go"}}); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	if idx.Count() != 1 || idx.Removed() != 0 {
+		t.Errorf("Update() should replace in place, got Count() = %d, Removed() = %d", idx.Count(), idx.Removed())
+	}
+	vec, meta, _ := idx.Get("doc1")
+	if vec[1] != 1.0 || meta.L1Data.Path != "new.go" {
+		t.Errorf("Get() after Update() = %v, %s; want normalized new vector and metadata", vec, meta.L1Data.Path)
+	}
+
+	if err := idx.Update("doc2", []float32{0.0, 0.0, 1.0}, types.EmbeddingUnit{}); err != nil {
+		t.Fatalf("Update() of a new ID unexpected error: %v", err)
+	}
+	if idx.Count() != 2 {
+		t.Errorf("Update() of a new ID should add it, Count() = %d", idx.Count())
+	}
+	if err := idx.Update("doc1", []float32{1.0}, types.EmbeddingUnit{}); err == nil {
+		t.Error("Update() expected error for dimension mismatch")
+	}
+
+	// Adding an existing ID replaces it rather than keeping both
+	idx.Add("doc1", []float32{1.0, 0.0, 0.0}, types.EmbeddingUnit{})
+	if idx.Count() != 2 {
+		t.Errorf("Add() of an existing ID should replace it, Count() = %d", idx.Count())
+	}
+	results, _ := idx.Search([]float32{1.0, 0.0, 0.0}, 10)
+	if len(results) != 2 {
+		t.Errorf("Search() returned %d results, want 2", len(results))
+	}
+}
+
+func TestVectorIndexGeneration(t *testing.T) {
+	idx := NewVectorIndex(3)
+	other := NewVectorIndex(3)
+	if idx.Generation() == other.Generation() {
+		t.Error("Generation() should differ between indexes")
+	}
+
+	gen := idx.Generation()
+	idx.Add("doc1", []float32{1.0, 0.0, 0.0}, types.EmbeddingUnit{})
+	if idx.Generation() == gen {
+		t.Error("Add() should change the generation")
+	}
+
+	gen = idx.Generation()
+	idx.Search([]float32{1.0, 0.0, 0.0}, 1)
+	idx.Get("doc1")
+	if idx.Generation() != gen {
+		t.Error("reads should keep the generation")
+	}
+
+	for name, mutate := range map[string]func(){
+		"Update": func() { idx.Update("doc1", []float32{0.0, 1.0, 0.0}, types.EmbeddingUnit{}) },
+		"Remove": func() { idx.Remove("doc1") },
+		"Clear":  func() { idx.Clear() },
+	} {
+		gen = idx.Generation()
+		mutate()
+		if idx.Generation() == gen {
+			t.Errorf("%s() should change the generation", name)
+		}
+	}
+}
+
+func TestVectorIndexSaveSkipsRemoved(t *testing.T) {
+	idx := NewVectorIndex(3)
+	idx.Add("doc1", []float32{1.0, 0.0, 0.0}, types.EmbeddingUnit{})
+	idx.Add("doc2", []float32{0.0, 1.0, 0.0}, types.EmbeddingUnit{})
+	idx.Remove("doc1")
+
+	path := filepath.Join(t.TempDir(), "index.msgpack")
+	if err := idx.Save(path); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+	if idx.Removed() != 1 {
+		t.Error("Save() should not modify the index")
+	}
+
+	loaded := NewVectorIndex(0)
+	if err := loaded.Load(path); err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if loaded.Count() != 1 || loaded.Removed() != 0 {
+		t.Errorf("loaded Count() = %d, Removed() = %d, want 1 and 0", loaded.Count(), loaded.Removed())
+	}
+	if _, _, found := loaded.Get("doc2"); !found {
+		t.Error("loaded index should contain doc2")
+	}
+}
+
 func TestVectorIndexClear(t *testing.T) {
 	idx := NewVectorIndex(3)
 
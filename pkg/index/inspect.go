@@ -0,0 +1,116 @@
+package index
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// VectorStats summarizes one stored vector. Degenerate vectors (zero norm,
+// NaNs, a few dominant components) tend to retrieve together regardless of
+// the query, so these are the first things to check when unrelated units
+// keep co-retrieving.
+type VectorStats struct {
+	Dimension int `json:"dimension"`
+	// Norm is 1 for stored vectors, or 0 when the embedding was all zeros
+	Norm   float64 `json:"norm"`
+	Min    float32 `json:"min"`
+	Max    float32 `json:"max"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"std_dev"`
+	// Zeros counts components that are exactly zero
+	Zeros int `json:"zeros"`
+	// NaNs counts NaN and infinite components
+	NaNs int `json:"nans"`
+}
+
+// ComputeVectorStats returns summary statistics for vector
+func ComputeVectorStats(vector []float32) VectorStats {
+	stats := VectorStats{Dimension: len(vector)}
+	if len(vector) == 0 {
+		return stats
+	}
+
+	stats.Min, stats.Max = float32(math.Inf(1)), float32(math.Inf(-1))
+	var sum, sumSq float64
+	finite := 0
+	for _, v := range vector {
+		f := float64(v)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			stats.NaNs++
+			continue
+		}
+		if v == 0 {
+			stats.Zeros++
+		}
+		stats.Min = min(stats.Min, v)
+		stats.Max = max(stats.Max, v)
+		sum += f
+		sumSq += f * f
+		finite++
+	}
+	if finite == 0 {
+		stats.Min, stats.Max = 0, 0
+		return stats
+	}
+
+	stats.Norm = math.Sqrt(sumSq)
+	stats.Mean = sum / float64(finite)
+	stats.StdDev = math.Sqrt(max(sumSq/float64(finite)-stats.Mean*stats.Mean, 0))
+	return stats
+}
+
+// Neighbors returns the k entries most similar to the stored vector of id,
+// excluding id itself. It always scans the whole index, so the result is
+// the exact ranking an approximate backend should reproduce.
+func (v *VectorIndex) Neighbors(id string, k int) ([]SearchResult, error) {
+	vector, _, ok := v.Get(id)
+	if !ok {
+		return nil, fmt.Errorf("unit not in index: %s", id)
+	}
+	if k <= 0 {
+		return nil, fmt.Errorf("k must be positive, got %d", k)
+	}
+	if v.Count() <= 1 {
+		return nil, nil
+	}
+
+	results, err := v.Search(vector, k+1)
+	if err != nil {
+		return nil, err
+	}
+	neighbors := make([]SearchResult, 0, k)
+	for _, r := range results {
+		if r.ID != id && len(neighbors) < k {
+			neighbors = append(neighbors, r)
+		}
+	}
+	return neighbors, nil
+}
+
+// Sample returns up to n live IDs chosen at random, in index order. The
+// same seed over the same index yields the same sample.
+func (v *VectorIndex) Sample(n int, seed int64) []string {
+	ids := make([]string, 0, v.Count())
+	v.IterVectors(func(id string, _ []float32, _ types.EmbeddingUnit) bool {
+		ids = append(ids, id)
+		return true
+	})
+	if n >= len(ids) {
+		return ids
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	picked := rand.New(rand.NewSource(seed)).Perm(len(ids))[:n]
+	slices.Sort(picked)
+	sample := make([]string, n)
+	for i, p := range picked {
+		sample[i] = ids[p]
+	}
+	return sample
+}
@@ -0,0 +1,69 @@
+package index
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+func TestComputeVectorStats(t *testing.T) {
+	stats := ComputeVectorStats([]float32{3, 0, -4, float32(math.NaN())})
+	if stats.Dimension != 4 || stats.Zeros != 1 || stats.NaNs != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if stats.Norm != 5 || stats.Min != -4 || stats.Max != 3 {
+		t.Errorf("Norm/Min/Max = %v/%v/%v, want 5/-4/3", stats.Norm, stats.Min, stats.Max)
+	}
+	if math.Abs(stats.Mean-(-1.0/3)) > 1e-9 {
+		t.Errorf("Mean = %v, want -1/3", stats.Mean)
+	}
+
+	if empty := ComputeVectorStats(nil); empty.Dimension != 0 || empty.Norm != 0 {
+		t.Errorf("empty stats = %+v", empty)
+	}
+}
+
+func TestVectorIndexNeighbors(t *testing.T) {
+	idx := NewVectorIndex(2)
+	idx.Add("a", []float32{1, 0}, types.EmbeddingUnit{})
+	idx.Add("b", []float32{0.9, 0.1}, types.EmbeddingUnit{})
+	idx.Add("c", []float32{0, 1}, types.EmbeddingUnit{})
+	idx.Add("d", []float32{0.5, 0.5}, types.EmbeddingUnit{})
+
+	neighbors, err := idx.Neighbors("a", 2)
+	if err != nil {
+		t.Fatalf("Neighbors() error: %v", err)
+	}
+	var ids []string
+	for _, n := range neighbors {
+		ids = append(ids, n.ID)
+	}
+	if !slices.Equal(ids, []string{"b", "d"}) {
+		t.Errorf("Neighbors(a) = %v, want [b d]", ids)
+	}
+
+	if _, err := idx.Neighbors("missing", 2); err == nil {
+		t.Error("Neighbors() expected error for unknown ID")
+	}
+}
+
+func TestVectorIndexSample(t *testing.T) {
+	idx := NewVectorIndex(1)
+	for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+		idx.Add(id, []float32{1}, types.EmbeddingUnit{})
+	}
+	idx.Remove("c")
+
+	sample := idx.Sample(3, 42)
+	if len(sample) != 3 || slices.Contains(sample, "c") {
+		t.Errorf("Sample(3) = %v", sample)
+	}
+	if !slices.Equal(sample, idx.Sample(3, 42)) {
+		t.Error("Sample() not reproducible with the same seed")
+	}
+	if all := idx.Sample(10, 1); len(all) != 5 {
+		t.Errorf("Sample(10) returned %d IDs, want 5", len(all))
+	}
+}
@@ -0,0 +1,126 @@
+package index
+
+import (
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestQuantizeInt8(t *testing.T) {
+	vector := []float32{0.5, -0.25, 0, 0.125, -0.5}
+	codes := make([]byte, len(vector))
+	scale := quantizeInt8(vector, codes)
+	if scale != 0.5 || int8(codes[0]) != 127 || int8(codes[4]) != -127 {
+		t.Fatalf("quantizeInt8 = %v, scale %v", codes, scale)
+	}
+	for i, x := range dequantizeInt8(codes, scale, make([]float32, len(vector))) {
+		if math.Abs(float64(x-vector[i])) > 0.5/127 {
+			t.Errorf("value %d = %v, want about %v", i, x, vector[i])
+		}
+	}
+	query := []float32{1, 1, 1, 1, 1}
+	if got, want := dotInt8(query, codes, scale), cosineSimilarity(query, vector); math.Abs(float64(got-want)) > 0.01 {
+		t.Errorf("dotInt8 = %v, want about %v", got, want)
+	}
+
+	if scale := quantizeInt8(make([]float32, 3), codes[:3]); scale != 0 {
+		t.Errorf("zero vector scale = %v", scale)
+	}
+	if err := NewVectorIndex(2).SetQuantization("pq"); err == nil {
+		t.Error("SetQuantization accepted an unknown quantization")
+	}
+}
+
+func TestSaveQuantized(t *testing.T) {
+	const dimension, k = 64, 10
+	dir := t.TempDir()
+	v := randomIndex(300, dimension, 1)
+	v.Remove("id7")
+
+	plain := filepath.Join(dir, "plain.msgpack")
+	if err := v.Save(plain); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.SetQuantization(QuantizationInt8); err != nil {
+		t.Fatal(err)
+	}
+	quantized := filepath.Join(dir, "quantized.msgpack")
+	if err := v.Save(quantized); err != nil {
+		t.Fatal(err)
+	}
+	// Payloads are the same size in both files, so compare the growth of
+	// each file over an index without vectors
+	empty := filepath.Join(dir, "empty.msgpack")
+	if err := randomIndex(300, 0, 1).Save(empty); err != nil {
+		t.Fatal(err)
+	}
+	size := func(path string) int64 {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info.Size()
+	}
+	if vectors, codes := size(plain)-size(empty), size(quantized)-size(empty); codes*3 > vectors {
+		t.Errorf("quantized vectors take %d bytes, float vectors %d", codes, vectors)
+	}
+
+	loaded := NewVectorIndex(0)
+	if err := loaded.Load(quantized); err != nil {
+		t.Fatalf("loading a quantized index: %v", err)
+	}
+	if loaded.Count() != v.Count() || loaded.Quantization() != QuantizationInt8 {
+		t.Fatalf("loaded %d entries with quantization %s, want %d with int8", loaded.Count(), loaded.Quantization(), v.Count())
+	}
+	store := filepath.Join(dir, "index.db")
+	if err := v.SaveSQLite(store); err != nil {
+		t.Fatal(err)
+	}
+	s, err := OpenSQLite(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// Both quantized searches find nearly all of the exact top k, with
+	// scores close to the exact ones
+	rng := rand.New(rand.NewSource(2))
+	var found, total int
+	for range 20 {
+		query := randomQuery(dimension, rng)
+		exact, _ := v.Search(append([]float32(nil), query...), k)
+		fromFile, _ := loaded.Search(append([]float32(nil), query...), k)
+		inPlace, err := s.Search(query, k)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := make(map[string]float32)
+		for _, r := range exact {
+			want[r.ID] = r.Score
+		}
+		for _, results := range [][]SearchResult{fromFile, inPlace} {
+			for _, r := range results {
+				total++
+				if score, ok := want[r.ID]; ok {
+					found++
+					if math.Abs(float64(r.Score-score)) > 0.01 {
+						t.Errorf("%s scored %v, exact %v", r.ID, r.Score, score)
+					}
+				}
+			}
+		}
+	}
+	if recall := float64(found) / float64(total); recall < 0.9 {
+		t.Errorf("quantized recall@%d = %.2f, want at least 0.9", k, recall)
+	}
+
+	fromDB := NewVectorIndex(0)
+	if err := fromDB.LoadSQLite(store); err != nil {
+		t.Fatal(err)
+	}
+	if fromDB.Count() != v.Count() || fromDB.Quantization() != QuantizationInt8 {
+		t.Errorf("loaded %d entries with quantization %s from the database", fromDB.Count(), fromDB.Quantization())
+	}
+}
@@ -0,0 +1,49 @@
+package index
+
+import "github.com/l3aro/go-context-query/pkg/types"
+
+// The read API lets tools post-process an index, such as custom reports or
+// exports, without decoding the saved msgpack layout. Entries are visited
+// in insertion order and removed entries are skipped. The index must not be
+// modified while a call is running.
+
+// Entry is one live entry of a VectorIndex
+type Entry struct {
+	ID      string
+	Payload types.EmbeddingUnit
+}
+
+// Iterate calls fn with the ID and payload of each live entry until fn
+// returns false. Use IterVectors to also read the vectors.
+func (v *VectorIndex) Iterate(fn func(id string, payload types.EmbeddingUnit) bool) {
+	for i, id := range v.ids {
+		if v.removed[i] {
+			continue
+		}
+		if !fn(id, v.metadata[i]) {
+			return
+		}
+	}
+}
+
+// GetByID returns the payload stored under id. Use Get to also read the
+// vector.
+func (v *VectorIndex) GetByID(id string) (types.EmbeddingUnit, bool) {
+	i, ok := v.idIndex[id]
+	if !ok {
+		return types.EmbeddingUnit{}, false
+	}
+	return v.metadata[i], true
+}
+
+// Filter returns the live entries whose payload satisfies pred
+func (v *VectorIndex) Filter(pred func(id string, payload types.EmbeddingUnit) bool) []Entry {
+	var entries []Entry
+	v.Iterate(func(id string, payload types.EmbeddingUnit) bool {
+		if pred(id, payload) {
+			entries = append(entries, Entry{ID: id, Payload: payload})
+		}
+		return true
+	})
+	return entries
+}
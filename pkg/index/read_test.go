@@ -0,0 +1,86 @@
+package index
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+func readTestIndex() *VectorIndex {
+	idx := NewVectorIndex(2)
+	idx.Add("go://a.go", []float32{1, 0}, types.EmbeddingUnit{L1Data: types.ModuleInfo{Path: "a.go"}})
+	idx.Add("py://b.py", []float32{0, 1}, types.EmbeddingUnit{L1Data: types.ModuleInfo{Path: "b.py"}})
+	idx.Add("go://c.go", []float32{1, 1}, types.EmbeddingUnit{L1Data: types.ModuleInfo{Path: "c.go"}})
+	idx.Add("go://d.go", []float32{1, 1}, types.EmbeddingUnit{L1Data: types.ModuleInfo{Path: "d.go"}})
+	idx.Remove("go://c.go")
+	return idx
+}
+
+func TestVectorIndexIterate(t *testing.T) {
+	idx := readTestIndex()
+
+	var paths []string
+	idx.Iterate(func(id string, payload types.EmbeddingUnit) bool {
+		paths = append(paths, payload.L1Data.Path)
+		return true
+	})
+	if want := []string{"a.go", "b.py", "d.go"}; !slices.Equal(paths, want) {
+		t.Errorf("Iterate() visited %v, want %v", paths, want)
+	}
+
+	var visited int
+	idx.Iterate(func(string, types.EmbeddingUnit) bool {
+		visited++
+		return false
+	})
+	if visited != 1 {
+		t.Errorf("Iterate() kept going after fn returned false: %d calls", visited)
+	}
+}
+
+func TestVectorIndexGetByID(t *testing.T) {
+	idx := readTestIndex()
+
+	payload, ok := idx.GetByID("py://b.py")
+	if !ok || payload.L1Data.Path != "b.py" {
+		t.Errorf("GetByID(py://b.py) = %+v, %v", payload, ok)
+	}
+	if _, ok := idx.GetByID("go://c.go"); ok {
+		t.Error("GetByID() returned a removed entry")
+	}
+	if _, ok := idx.GetByID("go://missing.go"); ok {
+		t.Error("GetByID() returned a missing entry")
+	}
+}
+
+func TestVectorIndexFilter(t *testing.T) {
+	idx := readTestIndex()
+
+	entries := idx.Filter(func(id string, _ types.EmbeddingUnit) bool {
+		return strings.HasPrefix(id, "go://")
+	})
+	if len(entries) != 2 || entries[0].ID != "go://a.go" || entries[1].Payload.L1Data.Path != "d.go" {
+		t.Errorf("Filter() = %+v", entries)
+	}
+	if entries := idx.Filter(func(string, types.EmbeddingUnit) bool { return false }); entries != nil {
+		t.Errorf("Filter() matching nothing = %+v", entries)
+	}
+}
+
+func ExampleVectorIndex_Filter() {
+	idx := NewVectorIndex(2)
+	idx.Add("go://main.go", []float32{1, 0}, types.EmbeddingUnit{L1Data: types.ModuleInfo{Path: "main.go"}})
+	idx.Add("py://tool.py", []float32{0, 1}, types.EmbeddingUnit{L1Data: types.ModuleInfo{Path: "tool.py"}})
+
+	for _, e := range idx.Filter(func(_ string, payload types.EmbeddingUnit) bool {
+		return strings.HasSuffix(payload.L1Data.Path, ".py")
+	}) {
+		fmt.Println(e.ID)
+	}
+
+	// Output:
+	// py://tool.py
+}
@@ -0,0 +1,572 @@
+package index
+
+import (
+	"container/heap"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+	"github.com/vmihailenco/msgpack/v5"
+
+	// Registers the pure Go "sqlite" driver, which includes FTS5
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchema creates the tables of a SQLite index. Vectors are kept apart
+// from the unit payloads so a scan reads only vector pages, and the text
+// table holds each unit's search terms for FTS5 keyword search. A vector
+// is 4 bytes per float32 value, or with meta key "quantized" set to 1, a
+// float32 scale and a byte per value as encodeInt8Vector writes it.
+const sqliteSchema = `
+CREATE TABLE meta (key TEXT PRIMARY KEY, value INTEGER NOT NULL);
+CREATE TABLE units (row INTEGER PRIMARY KEY, id TEXT NOT NULL UNIQUE, file TEXT NOT NULL, payload BLOB NOT NULL);
+CREATE INDEX units_file ON units (file);
+CREATE TABLE vectors (row INTEGER PRIMARY KEY, vector BLOB NOT NULL);
+CREATE VIRTUAL TABLE text USING fts5 (terms, tokenize = "unicode61 tokenchars '_'");
+`
+
+// SQLiteIndex is a Backend over an index saved with SaveSQLite, queried in
+// place instead of loaded into memory. A search streams the vectors from
+// disk, keeping only the best k, and reads the payloads of those alone;
+// keyword search uses the database's FTS5 table. It suits indexes too large
+// to keep resident, at the cost of reading the vectors on every search.
+//
+// The index is read-only; rebuild the file to change it.
+type SQLiteIndex struct {
+	db         *sql.DB
+	dimension  int
+	count      int
+	generation uint64
+	// quantized is true when vectors are int8 codes, scored without
+	// dequantizing them
+	quantized bool
+
+	// files holds one centroid per file, computed on the first SearchFiles
+	filesOnce sync.Once
+	files     *VectorIndex
+	filesErr  error
+}
+
+// SaveSQLite writes the live entries of the index to a new SQLite database
+// at path, replacing any file there
+func (v *VectorIndex) SaveSQLite(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove old database: %w", err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("failed to create database: %w", err)
+	}
+	defer db.Close()
+
+	// The file is written once and renamed into place, so it needs no
+	// rollback journal
+	if _, err := db.Exec("PRAGMA journal_mode = OFF; PRAGMA synchronous = OFF;" + sqliteSchema); err != nil {
+		return fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	quantized := v.quantization == QuantizationInt8
+	if _, err := tx.Exec("INSERT INTO meta (key, value) VALUES ('version', ?), ('dimension', ?), ('quantized', ?)", FormatVersion, v.dimension, quantized); err != nil {
+		return fmt.Errorf("failed to write meta: %w", err)
+	}
+	insertUnit, err := tx.Prepare("INSERT INTO units (row, id, file, payload) VALUES (?, ?, ?, ?)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	insertVector, err := tx.Prepare("INSERT INTO vectors (row, vector) VALUES (?, ?)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	insertText, err := tx.Prepare("INSERT INTO text (rowid, terms) VALUES (?, ?)")
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+
+	row := 0
+	v.IterVectors(func(id string, vector []float32, unit types.EmbeddingUnit) bool {
+		var payload []byte
+		if payload, err = msgpack.Marshal(&unit); err != nil {
+			err = fmt.Errorf("failed to encode %s: %w", id, err)
+			return false
+		}
+		if _, err = insertUnit.Exec(row, id, unitFile(unit), payload); err != nil {
+			err = fmt.Errorf("failed to write %s: %w", id, err)
+			return false
+		}
+		blob := encodeVector(vector)
+		if quantized {
+			blob = encodeInt8Vector(vector)
+		}
+		if _, err = insertVector.Exec(row, blob); err != nil {
+			err = fmt.Errorf("failed to write %s: %w", id, err)
+			return false
+		}
+		_, fields := UnitTextDoc(id, unit)
+		if _, err = insertText.Exec(row, textTerms(fields)); err != nil {
+			err = fmt.Errorf("failed to write %s: %w", id, err)
+			return false
+		}
+		row++
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	return nil
+}
+
+// LoadSQLite replaces the contents of the index with a database written by
+// SaveSQLite, read whole into memory. Files it can't read return ErrFormat.
+func (v *VectorIndex) LoadSQLite(path string) error {
+	s, err := OpenSQLite(path)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	data := indexData{
+		Version:   FormatVersion,
+		Dimension: s.dimension,
+		IDs:       make([]string, 0, s.count),
+		Vectors:   make([]float32, 0, s.count*s.dimension),
+		Metadata:  make([]types.EmbeddingUnit, 0, s.count),
+	}
+	rows, err := s.db.Query("SELECT units.id, units.payload, vectors.vector FROM units JOIN vectors USING (row) ORDER BY row")
+	if err != nil {
+		return fmt.Errorf("%w: reading database: %w", ErrFormat, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		var payload, vector []byte
+		if err := rows.Scan(&id, &payload, &vector); err != nil {
+			return fmt.Errorf("%w: reading database: %w", ErrFormat, err)
+		}
+		var unit types.EmbeddingUnit
+		if err := msgpack.Unmarshal(payload, &unit); err != nil {
+			return fmt.Errorf("%w: decoding %s: %w", ErrFormat, id, err)
+		}
+		if len(vector) != s.vectorSize() {
+			return fmt.Errorf("%w: vector of %s has %d bytes, want %d", ErrFormat, id, len(vector), s.vectorSize())
+		}
+		data.IDs = append(data.IDs, id)
+		n := len(data.Vectors)
+		data.Vectors = slices.Grow(data.Vectors, s.dimension)[:n+s.dimension]
+		s.decode(vector, data.Vectors[n:])
+		data.Metadata = append(data.Metadata, unit)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%w: reading database: %w", ErrFormat, err)
+	}
+
+	v.setData(data)
+	// Saving the loaded index keeps its vectors quantized
+	if s.quantized {
+		v.quantization = QuantizationInt8
+	}
+	return nil
+}
+
+// OpenSQLite opens a database written by SaveSQLite for searching. Files it
+// can't read return ErrFormat. Close it when done.
+func OpenSQLite(path string) (*SQLiteIndex, error) {
+	// Opening a missing file would create an empty database
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	s := &SQLiteIndex{db: db, generation: generations.Add(1)}
+	var version int
+	err = db.QueryRow(`SELECT (SELECT value FROM meta WHERE key = 'version'), (SELECT value FROM meta WHERE key = 'dimension'),
+		coalesce((SELECT value FROM meta WHERE key = 'quantized'), 0), (SELECT count(*) FROM units)`).
+		Scan(&version, &s.dimension, &s.quantized, &s.count)
+	switch {
+	case err != nil:
+		err = fmt.Errorf("%w: reading database: %w", ErrFormat, err)
+	case version > FormatVersion:
+		err = fmt.Errorf("%w: index format %d is newer than format %d, the latest this version reads", ErrFormat, version, FormatVersion)
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close closes the database
+func (s *SQLiteIndex) Close() error {
+	return s.db.Close()
+}
+
+// Count returns the number of entries
+func (s *SQLiteIndex) Count() int {
+	return s.count
+}
+
+// Dimension returns the vector dimension
+func (s *SQLiteIndex) Dimension() int {
+	return s.dimension
+}
+
+// Generation identifies the opened database; see VectorIndex.Generation
+func (s *SQLiteIndex) Generation() uint64 {
+	return s.generation
+}
+
+// Search returns the top-k entries most similar to query, best first
+func (s *SQLiteIndex) Search(query []float32, k int) ([]SearchResult, error) {
+	results, _, err := s.SearchBefore(query, k, time.Time{})
+	return results, err
+}
+
+// SearchBefore scores vectors in insertion order until deadline and returns
+// the top-k of those scored. A zero deadline searches to the end.
+func (s *SQLiteIndex) SearchBefore(query []float32, k int, deadline time.Time) ([]SearchResult, bool, error) {
+	q, err := s.prepareQuery(query, k)
+	if err != nil {
+		return nil, false, err
+	}
+	rows, err := s.db.Query("SELECT row, vector FROM vectors ORDER BY row")
+	if err != nil {
+		return nil, false, fmt.Errorf("scanning vectors: %w", err)
+	}
+	best, partial, err := s.scoreRows(rows, q, k, deadline)
+	if err != nil {
+		return nil, false, err
+	}
+	results, err := s.results(best)
+	return results, partial, err
+}
+
+// SearchFiles is FileIndex.Search over the database: files are ranked by
+// the centroid of their vectors, computed on the first call, and the top-k
+// units of the best files are returned
+func (s *SQLiteIndex) SearchFiles(query []float32, files, k int) ([]SearchResult, error) {
+	q, err := s.prepareQuery(query, k)
+	if err != nil {
+		return nil, err
+	}
+	if files <= 0 {
+		return nil, fmt.Errorf("files must be positive, got %d", files)
+	}
+	s.filesOnce.Do(func() { s.files, s.filesErr = s.fileCentroids() })
+	if s.filesErr != nil {
+		return nil, s.filesErr
+	}
+	if s.files.Count() == 0 {
+		return []SearchResult{}, nil
+	}
+
+	topFiles, err := s.files.Search(append([]float32(nil), q...), files)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]any, len(topFiles))
+	for i, file := range topFiles {
+		args[i] = file.ID
+	}
+	rows, err := s.db.Query("SELECT row, vector FROM vectors JOIN units USING (row) WHERE file IN ("+placeholders(len(args))+") ORDER BY row", args...)
+	if err != nil {
+		return nil, fmt.Errorf("scanning vectors: %w", err)
+	}
+	best, _, err := s.scoreRows(rows, q, k, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	return s.results(best)
+}
+
+// SearchText returns the top-k entries by BM25 score for a keyword query,
+// best first, like TextIndex.Search. Entries that match no query term are
+// not returned.
+func (s *SQLiteIndex) SearchText(query string, k int) ([]SearchResult, error) {
+	if k <= 0 {
+		return nil, nil
+	}
+	var terms []string
+	seen := make(map[string]bool)
+	for _, term := range Tokenize(query) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+		}
+	}
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	// FTS5 ranks by bm25(), which is lower for better matches
+	rows, err := s.db.Query(`SELECT units.id, units.payload, -text.rank FROM text JOIN units ON units.row = text.rowid
+		WHERE text MATCH ? ORDER BY text.rank, units.id LIMIT ?`, strings.Join(terms, " OR "), k)
+	if err != nil {
+		return nil, fmt.Errorf("searching text: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var res SearchResult
+		var payload []byte
+		if err := rows.Scan(&res.ID, &payload, &res.Score); err != nil {
+			return nil, fmt.Errorf("searching text: %w", err)
+		}
+		if err := msgpack.Unmarshal(payload, &res.Metadata); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", res.ID, err)
+		}
+		results = append(results, res)
+	}
+	return results, rows.Err()
+}
+
+// prepareQuery checks a vector query and returns a normalized copy
+func (s *SQLiteIndex) prepareQuery(query []float32, k int) ([]float32, error) {
+	if len(query) != s.dimension {
+		return nil, fmt.Errorf("query dimension mismatch: expected %d, got %d", s.dimension, len(query))
+	}
+	if k <= 0 {
+		return nil, fmt.Errorf("k must be positive, got %d", k)
+	}
+	q := append([]float32(nil), query...)
+	if norm := normalize(q); norm > 0 {
+		for i := range q {
+			q[i] *= norm
+		}
+	}
+	return q, nil
+}
+
+// scoredRow is a database row and its similarity to a query
+type scoredRow struct {
+	row   int64
+	score float32
+}
+
+// worstFirst is a heap keeping the worst of the best rows on top; ties go
+// to the later row, so earlier rows win as in VectorIndex.Search
+type worstFirst []scoredRow
+
+func (h worstFirst) Len() int { return len(h) }
+func (h worstFirst) Less(i, j int) bool {
+	if h[i].score != h[j].score {
+		return h[i].score < h[j].score
+	}
+	return h[i].row > h[j].row
+}
+func (h worstFirst) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *worstFirst) Push(x any)   { *h = append(*h, x.(scoredRow)) }
+func (h *worstFirst) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// scoreRows scores the (row, vector) rows against q until deadline and
+// returns the best k, best first, and whether the deadline cut them short.
+// It closes rows.
+func (s *SQLiteIndex) scoreRows(rows *sql.Rows, q []float32, k int, deadline time.Time) ([]scoredRow, bool, error) {
+	defer rows.Close()
+
+	best := make(worstFirst, 0, k)
+	vector := make([]float32, s.dimension)
+	partial := false
+	for scanned := 0; rows.Next(); scanned++ {
+		if !deadline.IsZero() && scanned > 0 && scanned%deadlineCheckEvery == 0 && time.Now().After(deadline) {
+			partial = true
+			break
+		}
+		var row int64
+		var blob []byte
+		if err := rows.Scan(&row, &blob); err != nil {
+			return nil, false, fmt.Errorf("scanning vectors: %w", err)
+		}
+		if len(blob) != s.vectorSize() {
+			return nil, false, fmt.Errorf("%w: vector of row %d has %d bytes, want %d", ErrFormat, row, len(blob), s.vectorSize())
+		}
+		scored := scoredRow{row: row}
+		if s.quantized {
+			codes, scale := int8Blob(blob)
+			scored.score = dotInt8(q, codes, scale)
+		} else {
+			scored.score = cosineSimilarity(q, decodeVector(blob, vector))
+		}
+		if len(best) < k {
+			heap.Push(&best, scored)
+		} else if scored.score > best[0].score {
+			best[0] = scored
+			heap.Fix(&best, 0)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("scanning vectors: %w", err)
+	}
+
+	sort.Slice(best, func(i, j int) bool { return best.Less(j, i) })
+	return best, partial, nil
+}
+
+// results reads the IDs and payloads of the scored rows, in their order
+func (s *SQLiteIndex) results(scored []scoredRow) ([]SearchResult, error) {
+	if len(scored) == 0 {
+		return []SearchResult{}, nil
+	}
+	args := make([]any, len(scored))
+	for i, r := range scored {
+		args[i] = r.row
+	}
+	rows, err := s.db.Query("SELECT row, id, payload FROM units WHERE row IN ("+placeholders(len(args))+")", args...)
+	if err != nil {
+		return nil, fmt.Errorf("reading units: %w", err)
+	}
+	defer rows.Close()
+
+	byRow := make(map[int64]SearchResult, len(scored))
+	for rows.Next() {
+		var row int64
+		var res SearchResult
+		var payload []byte
+		if err := rows.Scan(&row, &res.ID, &payload); err != nil {
+			return nil, fmt.Errorf("reading units: %w", err)
+		}
+		if err := msgpack.Unmarshal(payload, &res.Metadata); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", res.ID, err)
+		}
+		byRow[row] = res
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading units: %w", err)
+	}
+
+	results := make([]SearchResult, len(scored))
+	for i, r := range scored {
+		results[i] = byRow[r.row]
+		results[i].Score = r.score
+	}
+	return results, nil
+}
+
+// fileCentroids streams every vector once and returns an index holding the
+// mean vector of each file, as NewFileIndex computes them
+func (s *SQLiteIndex) fileCentroids() (*VectorIndex, error) {
+	rows, err := s.db.Query("SELECT file, vector FROM vectors JOIN units USING (row) WHERE file != '' ORDER BY row")
+	if err != nil {
+		return nil, fmt.Errorf("scanning vectors: %w", err)
+	}
+	defer rows.Close()
+
+	var order []string
+	centroids := make(map[string][]float32)
+	vector := make([]float32, s.dimension)
+	for rows.Next() {
+		var file string
+		var blob []byte
+		if err := rows.Scan(&file, &blob); err != nil {
+			return nil, fmt.Errorf("scanning vectors: %w", err)
+		}
+		if len(blob) != s.vectorSize() {
+			return nil, fmt.Errorf("%w: vector has %d bytes, want %d", ErrFormat, len(blob), s.vectorSize())
+		}
+		centroid, ok := centroids[file]
+		if !ok {
+			centroid = make([]float32, s.dimension)
+			centroids[file] = centroid
+			order = append(order, file)
+		}
+		for j, x := range s.decode(blob, vector) {
+			centroid[j] += x
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scanning vectors: %w", err)
+	}
+
+	files := NewVectorIndex(s.dimension)
+	for _, file := range order {
+		_ = files.Add(file, centroids[file], types.EmbeddingUnit{L1Data: types.ModuleInfo{Path: file}})
+	}
+	return files, nil
+}
+
+// vectorSize returns the number of bytes of a stored vector
+func (s *SQLiteIndex) vectorSize() int {
+	if s.quantized {
+		return 4 + s.dimension
+	}
+	return 4 * s.dimension
+}
+
+// decode fills vector from a stored vector of vectorSize bytes and returns
+// it
+func (s *SQLiteIndex) decode(blob []byte, vector []float32) []float32 {
+	if s.quantized {
+		codes, scale := int8Blob(blob)
+		return dequantizeInt8(codes, scale, vector)
+	}
+	return decodeVector(blob, vector)
+}
+
+// unitFile returns the file an entry belongs to, for SearchFiles
+func unitFile(unit types.EmbeddingUnit) string {
+	if unit.Unit != nil && unit.Unit.FilePath != "" {
+		return unit.Unit.FilePath
+	}
+	return unit.L1Data.Path
+}
+
+// textTerms returns the FTS5 document of a unit: its tokens, each field
+// repeated Weight times as TextIndex counts them
+func textTerms(fields []TextField) string {
+	var terms []string
+	for _, field := range fields {
+		tokens := Tokenize(field.Text)
+		for range field.Weight {
+			terms = append(terms, tokens...)
+		}
+	}
+	return strings.Join(terms, " ")
+}
+
+// encodeVector returns the little-endian bytes of a vector
+func encodeVector(vector []float32) []byte {
+	blob := make([]byte, 4*len(vector))
+	for i, x := range vector {
+		binary.LittleEndian.PutUint32(blob[4*i:], math.Float32bits(x))
+	}
+	return blob
+}
+
+// decodeVector fills vector from the little-endian bytes of one and returns
+// it
+func decodeVector(blob []byte, vector []float32) []float32 {
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(blob[4*i:]))
+	}
+	return vector
+}
+
+// placeholders returns n comma separated SQL parameters
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
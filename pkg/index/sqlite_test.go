@@ -0,0 +1,128 @@
+package index
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+func TestSQLiteIndex(t *testing.T) {
+	v := NewVectorIndex(3)
+	units := []struct {
+		name, file string
+		vector     []float32
+	}{
+		{"parseImportSpec", "imports.go", []float32{1, 0, 0}},
+		{"resolveImport", "imports.go", []float32{0.9, 0.1, 0}},
+		{"loadConfig", "config.go", []float32{0, 1, 0}},
+		{"removed", "config.go", []float32{1, 0, 0}},
+		{"formatValue", "format.go", []float32{0.7, 0, 0.7}},
+	}
+	for _, u := range units {
+		v.Add("go://"+u.file+"#"+u.name, u.vector, types.EmbeddingUnit{Unit: &types.CodeUnit{
+			Name: u.name, FilePath: u.file, Docstring: "Handles " + u.name,
+		}})
+	}
+	v.Remove("go://config.go#removed")
+
+	path := filepath.Join(t.TempDir(), "index.db")
+	if err := v.SaveSQLite(path); err != nil {
+		t.Fatalf("SaveSQLite failed: %v", err)
+	}
+	s, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer s.Close()
+	if s.Count() != 4 || s.Dimension() != 3 {
+		t.Fatalf("Count, Dimension = %d, %d, want 4, 3", s.Count(), s.Dimension())
+	}
+
+	// Vector search ranks like the in-memory index and reads payloads
+	query := []float32{1, 0.05, 0}
+	want, _ := v.Search(append([]float32(nil), query...), 3)
+	got, err := s.Search(query, 3)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Search returned %d results, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].ID != want[i].ID || got[i].Metadata.Unit == nil || got[i].Metadata.Unit.Name != want[i].Metadata.Unit.Name {
+			t.Errorf("result %d = %s, want %s", i, got[i].ID, want[i].ID)
+		}
+	}
+	if query[0] != 1 || query[1] != 0.05 {
+		t.Errorf("Search modified the query: %v", query)
+	}
+
+	files, err := s.SearchFiles([]float32{0, 1, 0.1}, 1, 5)
+	if err != nil {
+		t.Fatalf("SearchFiles failed: %v", err)
+	}
+	if len(files) != 1 || files[0].ID != "go://config.go#loadConfig" {
+		t.Errorf("SearchFiles = %+v, want the unit of config.go", files)
+	}
+
+	text, err := s.SearchText("import spec", 10)
+	if err != nil {
+		t.Fatalf("SearchText failed: %v", err)
+	}
+	if len(text) != 2 || text[0].ID != "go://imports.go#parseImportSpec" || text[0].Score <= text[1].Score {
+		t.Errorf("SearchText = %+v, want parseImportSpec then resolveImport", text)
+	}
+	if text, _ := s.SearchText("nothing matches", 10); len(text) != 0 {
+		t.Errorf("SearchText matched unrelated units: %+v", text)
+	}
+
+	// Loading the database whole gives back the live entries
+	loaded := NewVectorIndex(0)
+	if err := loaded.LoadSQLite(path); err != nil {
+		t.Fatalf("LoadSQLite failed: %v", err)
+	}
+	if loaded.Count() != 4 {
+		t.Errorf("loaded Count = %d, want 4", loaded.Count())
+	}
+	vector, unit, ok := loaded.Get("go://format.go#formatValue")
+	if !ok || unit.Unit.Name != "formatValue" || vector[0] < 0.7 || vector[0] > 0.71 {
+		t.Errorf("loaded entry = %v, %+v, %v", vector, unit.Unit, ok)
+	}
+}
+
+func TestOpenSQLiteFormat(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "index.db")
+	if err := NewVectorIndex(2).SaveSQLite(path); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("UPDATE meta SET value = ? WHERE key = 'version'", FormatVersion+1); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+	if _, err := OpenSQLite(path); !errors.Is(err, ErrFormat) {
+		t.Errorf("opening a newer database: err = %v, want ErrFormat", err)
+	}
+
+	garbage := filepath.Join(dir, "garbage.db")
+	if err := os.WriteFile(garbage, []byte("not a database"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenSQLite(garbage); !errors.Is(err, ErrFormat) {
+		t.Errorf("opening garbage: err = %v, want ErrFormat", err)
+	}
+	if _, err := OpenSQLite(filepath.Join(dir, "missing.db")); err == nil || errors.Is(err, ErrFormat) {
+		t.Errorf("opening a missing file: err = %v, want a plain error", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "missing.db")); !os.IsNotExist(err) {
+		t.Error("opening a missing file created it")
+	}
+}
@@ -0,0 +1,270 @@
+package index
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// BM25 parameters: k1 limits how much repeated terms add, b how strongly
+// long documents are penalised
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// nameWeight is how many times a unit's name counts towards its document,
+// so identifier matches outrank passing mentions in docstrings
+const nameWeight = 3
+
+// TextIndex is an inverted index over code units scored with BM25. Terms
+// come from unit names, signatures and doc comments, with identifiers split
+// into their camelCase and snake_case parts. It finds exact identifier
+// matches that embeddings can miss, and serves keyword search without
+// reading source files.
+type TextIndex struct {
+	docs     []TextDoc
+	postings map[string][]Posting
+	totalLen int
+}
+
+// TextDoc is an indexed unit, with enough location data to report a result
+// without loading the unit itself
+type TextDoc struct {
+	ID     string `msgpack:"id"`
+	File   string `msgpack:"f,omitempty"`
+	Line   int    `msgpack:"l,omitempty"`
+	Name   string `msgpack:"n,omitempty"`
+	IsTest bool   `msgpack:"t,omitempty"`
+	Length int    `msgpack:"len"`
+}
+
+// Posting is one document containing a term
+type Posting struct {
+	Doc  int32 `msgpack:"d"`
+	Freq int32 `msgpack:"f"`
+}
+
+// TextField is a piece of text indexed for a document, counted Weight times
+type TextField struct {
+	Text   string
+	Weight int
+}
+
+// TextResult is a document matching a keyword query
+type TextResult struct {
+	Doc   TextDoc
+	Score float32
+}
+
+// NewTextIndex creates an empty TextIndex
+func NewTextIndex() *TextIndex {
+	return &TextIndex{postings: make(map[string][]Posting)}
+}
+
+// NewTextIndexFrom builds a TextIndex over the units of a vector index
+func NewTextIndexFrom(v *VectorIndex) *TextIndex {
+	t := NewTextIndex()
+	v.IterVectors(func(id string, _ []float32, unit types.EmbeddingUnit) bool {
+		doc, fields := UnitTextDoc(id, unit)
+		t.Add(doc, fields...)
+		return true
+	})
+	return t
+}
+
+// UnitTextDoc returns the document and fields indexed for a unit
+func UnitTextDoc(id string, unit types.EmbeddingUnit) (TextDoc, []TextField) {
+	if u := unit.Unit; u != nil {
+		fields := []TextField{
+			{u.Name, nameWeight},
+			{u.Signature, 1},
+			{u.Docstring, 1},
+			{u.Code, 1},
+		}
+		// Enricher annotations, such as ticket IDs, are searchable too
+		for _, value := range u.Metadata {
+			fields = append(fields, TextField{value, 1})
+		}
+		return TextDoc{ID: id, File: u.FilePath, Line: u.LineNumber, Name: u.Name, IsTest: u.IsTest}, fields
+	}
+
+	// File-level entries without a persisted unit: index the symbols they define
+	doc := TextDoc{ID: id, File: unit.L1Data.Path, Line: unit.L1Data.LineNumber}
+	fields := []TextField{{id, 1}, {unit.L1Data.Docstring, 1}}
+	for _, fn := range unit.L1Data.Functions {
+		fields = append(fields, TextField{fn.Name, nameWeight}, TextField{fn.Docstring, 1})
+	}
+	for _, cls := range unit.L1Data.Classes {
+		fields = append(fields, TextField{cls.Name, nameWeight}, TextField{cls.Docstring, 1})
+	}
+	return doc, fields
+}
+
+// Add indexes a document. doc.Length is computed from the fields.
+func (t *TextIndex) Add(doc TextDoc, fields ...TextField) {
+	n := int32(len(t.docs))
+	freqs := make(map[string]int32)
+	doc.Length = 0
+	for _, field := range fields {
+		for _, term := range Tokenize(field.Text) {
+			freqs[term] += int32(field.Weight)
+			doc.Length += field.Weight
+		}
+	}
+	for term, freq := range freqs {
+		t.postings[term] = append(t.postings[term], Posting{Doc: n, Freq: freq})
+	}
+	t.docs = append(t.docs, doc)
+	t.totalLen += doc.Length
+}
+
+// Count returns the number of indexed documents
+func (t *TextIndex) Count() int {
+	return len(t.docs)
+}
+
+// Terms returns the number of distinct terms
+func (t *TextIndex) Terms() int {
+	return len(t.postings)
+}
+
+// Search returns the top-k documents by BM25 score for query, best first.
+// Documents that match no query term are not returned.
+func (t *TextIndex) Search(query string, k int) []TextResult {
+	if k <= 0 || len(t.docs) == 0 {
+		return nil
+	}
+
+	docs := float64(len(t.docs))
+	avgLen := float64(t.totalLen) / docs
+	scores := make(map[int32]float64)
+	seen := make(map[string]bool)
+	for _, term := range Tokenize(query) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+
+		postings := t.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		idf := math.Log(1 + (docs-float64(len(postings))+0.5)/(float64(len(postings))+0.5))
+		for _, p := range postings {
+			tf := float64(p.Freq)
+			norm := 1 - bm25B + bm25B*float64(t.docs[p.Doc].Length)/avgLen
+			scores[p.Doc] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+	}
+
+	results := make([]TextResult, 0, len(scores))
+	for doc, score := range scores {
+		results = append(results, TextResult{Doc: t.docs[doc], Score: float32(score)})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Doc.ID < results[j].Doc.ID
+	})
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
+
+// textIndexData is the serialized form of a TextIndex
+type textIndexData struct {
+	Docs     []TextDoc            `msgpack:"docs"`
+	Postings map[string][]Posting `msgpack:"postings"`
+}
+
+// Save persists the index to a file using msgpack
+func (t *TextIndex) Save(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	data := textIndexData{Docs: t.docs, Postings: t.postings}
+	if err := msgpack.NewEncoder(file).Encode(&data); err != nil {
+		return fmt.Errorf("failed to encode text index: %w", err)
+	}
+	return nil
+}
+
+// Load restores the index from a file using msgpack
+func (t *TextIndex) Load(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	var data textIndexData
+	if err := msgpack.NewDecoder(file).Decode(&data); err != nil {
+		return fmt.Errorf("failed to decode text index: %w", err)
+	}
+
+	t.docs = data.Docs
+	t.postings = data.Postings
+	if t.postings == nil {
+		t.postings = make(map[string][]Posting)
+	}
+	t.totalLen = 0
+	for _, doc := range t.docs {
+		t.totalLen += doc.Length
+	}
+	return nil
+}
+
+// Tokenize splits text into lowercase search terms. Identifiers yield both
+// the whole identifier and its camelCase, PascalCase and snake_case parts, so
+// "parseImportSpec" matches the query "parseImportSpec" exactly and also
+// "import spec".
+func Tokenize(text string) []string {
+	var terms []string
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) {
+		parts := splitIdentifier(word)
+		if len(parts) > 1 {
+			terms = append(terms, strings.ToLower(strings.Trim(word, "_")))
+		}
+		for _, part := range parts {
+			terms = append(terms, strings.ToLower(part))
+		}
+	}
+	return terms
+}
+
+// splitIdentifier splits an identifier at underscores and case changes,
+// keeping acronyms together: "parseHTTPRequest_v2" -> parse, HTTP, Request, v2
+func splitIdentifier(word string) []string {
+	var parts []string
+	for _, chunk := range strings.Split(word, "_") {
+		runes := []rune(chunk)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			prev, cur := runes[i-1], runes[i]
+			boundary := (unicode.IsLower(prev) || unicode.IsDigit(prev)) && unicode.IsUpper(cur) ||
+				unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if boundary {
+				parts = append(parts, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < len(runes) {
+			parts = append(parts, string(runes[start:]))
+		}
+	}
+	return parts
+}
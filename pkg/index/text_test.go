@@ -0,0 +1,100 @@
+package index
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"parseImportSpec", []string{"parseimportspec", "parse", "import", "spec"}},
+		{"parseHTTPRequest", []string{"parsehttprequest", "parse", "http", "request"}},
+		{"load_config(path)", []string{"load_config", "load", "config", "path"}},
+		{"base64 utf8", []string{"base64", "utf8"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+// createTextTestIndex indexes a few units mentioning imports
+func createTextTestIndex() *TextIndex {
+	v := NewVectorIndex(3)
+	units := []struct{ name, doc string }{
+		{"parseImportSpec", "Parses a single import spec."},
+		{"loadConfig", "Loads the configuration file."},
+		{"resolveImports", "Resolves every import of a module."},
+		{"TestParseImportSpec", ""},
+	}
+	for _, u := range units {
+		v.Add("go://pkg#"+u.name, []float32{1, 0, 0}, types.EmbeddingUnit{Unit: &types.CodeUnit{
+			Name:       u.name,
+			FilePath:   "pkg/imports.go",
+			LineNumber: 10,
+			Signature:  "func " + u.name + "()",
+			Docstring:  u.doc,
+			IsTest:     u.name == "TestParseImportSpec",
+		}})
+	}
+	return NewTextIndexFrom(v)
+}
+
+func TestTextIndexSearch(t *testing.T) {
+	textIndex := createTextTestIndex()
+	if textIndex.Count() != 4 {
+		t.Fatalf("expected 4 indexed units, got %d", textIndex.Count())
+	}
+
+	results := textIndex.Search("parseImportSpec", 10)
+	if len(results) == 0 || results[0].Doc.ID != "go://pkg#parseImportSpec" {
+		t.Fatalf("expected parseImportSpec first, got %+v", results)
+	}
+	if doc := results[0].Doc; doc.File != "pkg/imports.go" || doc.Line != 10 || doc.Name != "parseImportSpec" {
+		t.Errorf("expected the unit's location in the result, got %+v", doc)
+	}
+
+	results = textIndex.Search("import", 10)
+	if len(results) != 3 {
+		t.Errorf("expected 3 units mentioning import, got %d", len(results))
+	}
+
+	if results := textIndex.Search("nonexistent", 10); len(results) != 0 {
+		t.Errorf("expected no results, got %+v", results)
+	}
+	if results := textIndex.Search("import", 0); results != nil {
+		t.Errorf("expected no results for zero k, got %+v", results)
+	}
+}
+
+func TestTextIndexSaveLoad(t *testing.T) {
+	textIndex := createTextTestIndex()
+	path := filepath.Join(t.TempDir(), "text.msgpack")
+	if err := textIndex.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded := NewTextIndex()
+	if err := loaded.Load(path); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Count() != textIndex.Count() || loaded.Terms() != textIndex.Terms() {
+		t.Fatalf("expected %d docs and %d terms, got %d and %d",
+			textIndex.Count(), textIndex.Terms(), loaded.Count(), loaded.Terms())
+	}
+	if got, want := loaded.Search("import spec", 10), textIndex.Search("import spec", 10); !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded index ranks differently: got %+v, want %+v", got, want)
+	}
+
+	if err := NewTextIndex().Load(filepath.Join(t.TempDir(), "missing.msgpack")); err == nil {
+		t.Error("expected error loading a missing file")
+	}
+}
@@ -0,0 +1,47 @@
+//go:build cgo && (linux || darwin || freebsd)
+
+package langpack
+
+/*
+#cgo linux LDFLAGS: -ldl
+#include <dlfcn.h>
+#include <stdlib.h>
+
+typedef const void *(*language_fn)(void);
+
+static const void *call_language(void *fn) {
+	return ((language_fn)fn)();
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// openGrammar opens the shared library at path and returns the grammar its
+// symbol function returns. Libraries stay loaded for the life of the
+// process, as parsers keep pointers into them.
+func openGrammar(path, symbol string) (*sitter.Language, error) {
+	cPath := C.CString(path)
+	defer C.free(unsafe.Pointer(cPath))
+	handle := C.dlopen(cPath, C.RTLD_NOW|C.RTLD_LOCAL)
+	if handle == nil {
+		return nil, fmt.Errorf("dlopen %s: %s", path, C.GoString(C.dlerror()))
+	}
+
+	cSymbol := C.CString(symbol)
+	defer C.free(unsafe.Pointer(cSymbol))
+	fn := C.dlsym(handle, cSymbol)
+	if fn == nil {
+		return nil, fmt.Errorf("%s has no symbol %s", path, symbol)
+	}
+	language := C.call_language(fn)
+	if language == nil {
+		return nil, fmt.Errorf("%s returned no grammar", symbol)
+	}
+	return sitter.NewLanguage(unsafe.Pointer(language)), nil
+}
@@ -0,0 +1,16 @@
+//go:build !cgo || !(linux || darwin || freebsd)
+
+package langpack
+
+import (
+	"fmt"
+	"runtime"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// openGrammar reports that grammars can't be loaded at run time on this
+// platform or in a build without cgo
+func openGrammar(path, symbol string) (*sitter.Language, error) {
+	return nil, fmt.Errorf("loading grammar libraries is not supported on %s", runtime.GOOS)
+}
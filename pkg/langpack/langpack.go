@@ -0,0 +1,250 @@
+// Package langpack loads tree-sitter grammars built as shared libraries at
+// run time, so a language without a built-in parser can be indexed without
+// a new gcq release. Each grammar is downloaded once per platform into a
+// cache directory and verified against its SHA-256 checksum every time it
+// is loaded.
+package langpack
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/l3aro/go-context-query/internal/config"
+	"github.com/l3aro/go-context-query/internal/scanner"
+	"github.com/l3aro/go-context-query/pkg/extractor"
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// downloadTimeout bounds the download of one grammar
+const downloadTimeout = 2 * time.Minute
+
+// ErrChecksum is returned when a grammar library does not match its
+// configured checksum
+var ErrChecksum = errors.New("checksum mismatch")
+
+// Platform returns the key of the running platform in
+// config.LanguagePack.Libraries, such as "linux/amd64"
+func Platform() string {
+	return runtime.GOOS + "/" + runtime.GOARCH
+}
+
+// DefaultDir returns the directory grammars are cached in when none is
+// configured
+func DefaultDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("finding the user cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "gcq", "grammars"), nil
+}
+
+// Manager downloads, verifies and opens grammar libraries
+type Manager struct {
+	dir     string
+	offline bool
+	client  *http.Client
+}
+
+// NewManager returns a Manager for the languages config
+func NewManager(cfg config.LanguagesConfig) (*Manager, error) {
+	dir := cfg.Dir
+	if dir == "" {
+		var err error
+		if dir, err = DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
+	return &Manager{
+		dir:     dir,
+		offline: cfg.Offline,
+		client:  &http.Client{Timeout: downloadTimeout},
+	}, nil
+}
+
+// Path returns where pack's library for this platform is cached. The
+// checksum is part of the name, so a new release of a grammar is
+// downloaded next to the old one rather than over it.
+func (m *Manager) Path(pack config.LanguagePack) (string, error) {
+	lib, err := library(pack)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(m.dir, fmt.Sprintf("%s-%s%s", pack.Name, strings.ToLower(lib.SHA256)[:16], libraryExt())), nil
+}
+
+// Fetch returns the path of pack's library for this platform, downloading
+// it first when it is not cached. A cached library that no longer matches
+// its checksum is downloaded again.
+func (m *Manager) Fetch(ctx context.Context, pack config.LanguagePack) (string, error) {
+	lib, err := library(pack)
+	if err != nil {
+		return "", err
+	}
+	path, err := m.Path(pack)
+	if err != nil {
+		return "", err
+	}
+	if verify(path, lib.SHA256) == nil {
+		return path, nil
+	}
+	if m.offline {
+		return "", fmt.Errorf("grammar for %s is not cached in %s and downloads are off", pack.Name, m.dir)
+	}
+
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return "", fmt.Errorf("creating grammar directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(m.dir, pack.Name+"-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("creating grammar file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	err = m.download(ctx, lib.URL, io.MultiWriter(tmp, hash))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("downloading grammar for %s: %w", pack.Name, err)
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(got, lib.SHA256) {
+		return "", fmt.Errorf("grammar for %s from %s: %w: got %s, want %s", pack.Name, lib.URL, ErrChecksum, got, lib.SHA256)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("saving grammar for %s: %w", pack.Name, err)
+	}
+	return path, nil
+}
+
+// Load fetches pack's library and opens its grammar
+func (m *Manager) Load(ctx context.Context, pack config.LanguagePack) (*sitter.Language, error) {
+	path, err := m.Fetch(ctx, pack)
+	if err != nil {
+		return nil, err
+	}
+	symbol := pack.Symbol
+	if symbol == "" {
+		symbol = "tree_sitter_" + strings.ReplaceAll(pack.Name, "-", "_")
+	}
+	grammar, err := openGrammar(path, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("loading grammar for %s: %w", pack.Name, err)
+	}
+	return grammar, nil
+}
+
+// Install loads every pack of cfg and registers its language with the
+// extractor registries and the scanner. A pack that fails to load is left
+// out and its error returned; the others are still installed.
+func Install(ctx context.Context, cfg config.LanguagesConfig) []error {
+	if len(cfg.Packs) == 0 {
+		return nil
+	}
+	m, err := NewManager(cfg)
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	for _, pack := range cfg.Packs {
+		grammar, err := m.Load(ctx, pack)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		nodes := extractor.GrammarNodes{Functions: pack.Functions, Classes: pack.Classes}
+		if err := extractor.RegisterGrammar(extractor.Language(pack.Name), grammar, pack.Extensions, nodes); err != nil {
+			errs = append(errs, fmt.Errorf("language pack %s: %w", pack.Name, err))
+			continue
+		}
+		scanner.RegisterLanguage(pack.Name, pack.Extensions)
+	}
+	return errs
+}
+
+// library returns pack's library for this platform
+func library(pack config.LanguagePack) (config.LanguageLibrary, error) {
+	lib, ok := pack.Libraries[Platform()]
+	if !ok {
+		return config.LanguageLibrary{}, fmt.Errorf("language pack %s has no library for %s", pack.Name, Platform())
+	}
+	if len(lib.SHA256) != 2*sha256.Size {
+		return config.LanguageLibrary{}, fmt.Errorf("language pack %s: invalid sha256 %q", pack.Name, lib.SHA256)
+	}
+	return lib, nil
+}
+
+// download copies the library at rawURL to w. file:// URLs and plain
+// paths are read from disk.
+func (m *Manager) download(ctx context.Context, rawURL string, w io.Writer) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		path := rawURL
+		if err == nil && u.Scheme == "file" {
+			path = u.Path
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(w, f)
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", rawURL, resp.Status)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// verify checks that the file at path matches the hex checksum want
+func verify(path, want string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(got, want) {
+		return ErrChecksum
+	}
+	return nil
+}
+
+// libraryExt returns the shared library extension of the platform
+func libraryExt() string {
+	switch runtime.GOOS {
+	case "darwin":
+		return ".dylib"
+	case "windows":
+		return ".dll"
+	default:
+		return ".so"
+	}
+}
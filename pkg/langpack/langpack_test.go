@@ -0,0 +1,114 @@
+package langpack
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/l3aro/go-context-query/internal/config"
+)
+
+var library1 = []byte("grammar library v1")
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func testPack(url, sum string) config.LanguagePack {
+	return config.LanguagePack{
+		Name:       "snake",
+		Extensions: []string{".snake"},
+		Functions:  []string{"function_definition"},
+		Libraries:  map[string]config.LanguageLibrary{Platform(): {URL: url, SHA256: sum}},
+	}
+}
+
+func TestFetchDownloadsOnce(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write(library1)
+	}))
+	defer server.Close()
+
+	m, err := NewManager(config.LanguagesConfig{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pack := testPack(server.URL+"/snake.so", checksum(library1))
+	for range 2 {
+		path, err := m.Fetch(context.Background(), pack)
+		if err != nil {
+			t.Fatalf("Fetch() error: %v", err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != string(library1) {
+			t.Errorf("cached library = %q", data)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("downloaded %d times, want once", requests)
+	}
+}
+
+func TestFetchChecksumMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("tampered library"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	m, _ := NewManager(config.LanguagesConfig{Dir: dir})
+	_, err := m.Fetch(context.Background(), testPack(server.URL, checksum(library1)))
+	if !errors.Is(err, ErrChecksum) {
+		t.Fatalf("Fetch() error = %v, want ErrChecksum", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("a library that failed verification was kept: %v", entries)
+	}
+}
+
+func TestFetchLocalAndOffline(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "snake.so")
+	os.WriteFile(src, library1, 0644)
+	pack := testPack("file://"+src, checksum(library1))
+
+	offline, _ := NewManager(config.LanguagesConfig{Dir: t.TempDir(), Offline: true})
+	if _, err := offline.Fetch(context.Background(), pack); err == nil {
+		t.Error("Fetch() downloaded with downloads off")
+	}
+
+	dir := t.TempDir()
+	m, _ := NewManager(config.LanguagesConfig{Dir: dir})
+	path, err := m.Fetch(context.Background(), pack)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Once cached, the library loads without downloading
+	offline, _ = NewManager(config.LanguagesConfig{Dir: dir, Offline: true})
+	if cached, err := offline.Fetch(context.Background(), pack); err != nil || cached != path {
+		t.Errorf("offline Fetch() = %q, %v; want %q", cached, err, path)
+	}
+
+	// A cached library that was modified is not trusted
+	os.WriteFile(path, []byte("modified"), 0644)
+	if _, err := offline.Fetch(context.Background(), pack); err == nil {
+		t.Error("Fetch() returned a modified library")
+	}
+}
+
+func TestFetchMissingPlatform(t *testing.T) {
+	m, _ := NewManager(config.LanguagesConfig{Dir: t.TempDir()})
+	pack := testPack("https://example.com/snake.so", checksum(library1))
+	pack.Libraries = map[string]config.LanguageLibrary{"plan9/mips": pack.Libraries[Platform()]}
+	if _, err := m.Fetch(context.Background(), pack); err == nil {
+		t.Error("Fetch() found a library for another platform")
+	}
+}
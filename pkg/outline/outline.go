@@ -0,0 +1,319 @@
+// Package outline turns extracted modules into a tree of their
+// definitions: types with their methods and fields, functions with the
+// functions nested in them. Like package symbols it works from extraction
+// alone, so it gives a cheap structural overview of a file or package
+// without embeddings, an index or the source text.
+package outline
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/l3aro/go-context-query/internal/scanner"
+	"github.com/l3aro/go-context-query/pkg/extractor"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// Kinds of Node
+const (
+	KindClass     = "class"
+	KindInterface = "interface"
+	KindTrait     = "trait"
+	KindProtocol  = "protocol"
+	KindStruct    = "struct"
+	KindEnum      = "enum"
+	KindFunction  = "function"
+	KindMethod    = "method"
+	KindField     = "field"
+	KindVariant   = "variant"
+)
+
+// Node is a definition in the outline
+type Node struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// Line is 0 when the extractor does not report it, as for the methods
+	// of a Go interface
+	Line int `json:"line,omitempty"`
+	// Detail is a function's parameters and result, a field's type or a
+	// type's bases
+	Detail   string  `json:"detail,omitempty"`
+	Children []*Node `json:"children,omitempty"`
+}
+
+// File is the outline of one source file
+type File struct {
+	Path     string  `json:"path"`
+	Language string  `json:"language,omitempty"`
+	Nodes    []*Node `json:"nodes"`
+}
+
+// FromModule returns the outline of an extracted module. Types and
+// top-level functions are ordered by line; members keep the order they are
+// declared in.
+func FromModule(m *types.ModuleInfo) []*Node {
+	lang := m.Language
+	var nodes []*Node
+	// Go reports interfaces and structs both as classes and on their own;
+	// they are merged by name and line, keeping the more specific kind and
+	// the members first seen
+	seen := make(map[string]*Node)
+	addType := func(name, kind string, line int) (*Node, bool) {
+		key := fmt.Sprintf("%s:%d", name, line)
+		if n, ok := seen[key]; ok {
+			if kind != KindClass {
+				n.Kind = kind
+			}
+			return n, false
+		}
+		n := &Node{Name: name, Kind: kind, Line: line}
+		seen[key] = n
+		nodes = append(nodes, n)
+		return n, true
+	}
+	addMethods := func(n *Node, methods []types.Method) {
+		for _, method := range methods {
+			if method.Name == "" {
+				continue
+			}
+			if lang == "go" && method.Params == "" {
+				// An interface embedded in a Go interface
+				n.Detail = joinDetail(n.Detail, method.Name)
+				continue
+			}
+			n.Children = append(n.Children, &Node{Name: method.Name, Kind: KindMethod, Line: method.LineNumber, Detail: signature(method, lang)})
+		}
+	}
+	addFields := func(n *Node, fields []types.Field) {
+		for _, f := range fields {
+			if f.Embedded {
+				n.Detail = joinDetail(n.Detail, f.Type)
+				continue
+			}
+			n.Children = append(n.Children, &Node{Name: f.Name, Kind: KindField, Detail: f.Type})
+		}
+	}
+
+	for _, cls := range m.Classes {
+		if cls.Name == "" {
+			continue
+		}
+		n, _ := addType(cls.Name, KindClass, cls.LineNumber)
+		n.Detail = strings.Join(cls.Bases, ", ")
+		addFields(n, cls.Fields)
+		addMethods(n, cls.Methods)
+	}
+	for _, iface := range m.Interfaces {
+		if n, created := addType(iface.Name, KindInterface, iface.LineNumber); created {
+			n.Detail = strings.Join(iface.Bases, ", ")
+			addMethods(n, iface.Methods)
+		}
+	}
+	for _, trait := range m.Traits {
+		if n, created := addType(trait.Name, KindTrait, trait.LineNumber); created {
+			addMethods(n, trait.Methods)
+		}
+	}
+	for _, p := range m.Protocols {
+		if n, created := addType(p.Name, KindProtocol, p.LineNumber); created {
+			n.Detail = strings.Join(p.Bases, ", ")
+			addMethods(n, p.Methods)
+		}
+	}
+	for _, st := range m.Structs {
+		n, created := addType(st.Name, KindStruct, st.LineNumber)
+		switch {
+		case !created:
+		case len(st.FieldInfo) > 0:
+			addFields(n, st.FieldInfo)
+		default:
+			// Fields as written, such as "int x;" in C
+			for _, f := range st.Fields {
+				n.Children = append(n.Children, &Node{Name: f, Kind: KindField})
+			}
+		}
+	}
+	for _, e := range m.Enums {
+		if n, created := addType(e.Name, KindEnum, e.LineNumber); created {
+			for _, v := range e.Variants {
+				n.Children = append(n.Children, &Node{Name: v, Kind: KindVariant})
+			}
+		}
+	}
+
+	// Functions go under the function they are nested in, Go methods under
+	// their receiver's type when it is declared in the same file
+	var functions []*Node
+	for _, fn := range m.Functions {
+		if fn.Name == "" {
+			continue
+		}
+		kind := KindFunction
+		if fn.IsMethod {
+			kind = KindMethod
+		}
+		n := &Node{Name: fn.Name, Kind: kind, Line: fn.LineNumber, Detail: signature(fn, lang)}
+		switch {
+		case fn.NestedIn != "":
+			if parent := enclosing(functions, fn.NestedIn, fn.LineNumber); parent != nil {
+				parent.Children = append(parent.Children, n)
+				functions = append(functions, n)
+				continue
+			}
+		case fn.Receiver != "":
+			if owner := typeNamed(nodes, fn.Receiver); owner != nil {
+				owner.Children = append(owner.Children, n)
+				continue
+			}
+			n.Name = fn.Receiver + "." + fn.Name
+		}
+		functions = append(functions, n)
+		nodes = append(nodes, n)
+	}
+
+	sortByLine(nodes)
+	for _, n := range nodes {
+		if n.Kind != KindFunction && n.Kind != KindMethod {
+			sortMembers(n.Children)
+		}
+	}
+	return nodes
+}
+
+// signature formats the parameters and result of a function as the
+// language writes them, without its name
+func signature(fn types.Function, lang string) string {
+	params := fn.TypeParams + fn.Params
+	if fn.Params == "" {
+		params += "()"
+	} else if !strings.HasPrefix(fn.Params, "(") {
+		params = fn.TypeParams + "(" + fn.Params + ")"
+	}
+	ret := strings.TrimSpace(fn.ReturnType)
+	switch {
+	case ret == "":
+		return params
+	case lang == "go":
+		return params + " " + ret
+	case strings.HasPrefix(ret, ":") || strings.HasPrefix(ret, "->"):
+		return params + ret
+	case lang == "typescript" || lang == "javascript" || lang == "vue" || lang == "svelte" || lang == "kotlin" || lang == "php":
+		return params + ": " + ret
+	default:
+		return params + " -> " + ret
+	}
+}
+
+// joinDetail appends item to a comma separated detail
+func joinDetail(detail, item string) string {
+	if detail == "" {
+		return item
+	}
+	return detail + ", " + item
+}
+
+// enclosing returns the last function named name starting at or before
+// line, the one a nested function at line is declared in
+func enclosing(functions []*Node, name string, line int) *Node {
+	var found *Node
+	for _, n := range functions {
+		if n.Name == name && (n.Line <= line || line == 0) {
+			found = n
+		}
+	}
+	return found
+}
+
+// typeNamed returns the type node named name, or nil
+func typeNamed(nodes []*Node, name string) *Node {
+	for _, n := range nodes {
+		if n.Name == name && n.Kind != KindFunction && n.Kind != KindMethod {
+			return n
+		}
+	}
+	return nil
+}
+
+// sortByLine orders nodes by line, keeping the declaration order of
+// nodes on the same line
+func sortByLine(nodes []*Node) {
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].Line < nodes[j].Line })
+}
+
+// sortMembers puts fields and variants before methods, so Go methods,
+// which are declared apart from their type, follow its fields
+func sortMembers(members []*Node) {
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].Kind != KindMethod && members[j].Kind == KindMethod
+	})
+}
+
+// Build outlines path: a source file, or every supported file directly in
+// a directory, such as a Go package. Files in subdirectories are left
+// out, as are files the scanner ignores; a directory's files that fail to
+// parse are skipped.
+func Build(path string) ([]File, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		file, err := outlineFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return []File{*file}, nil
+	}
+
+	files, err := scanner.New(scanner.DefaultOptions()).Scan(path)
+	if err != nil {
+		return nil, fmt.Errorf("scanning %s: %w", path, err)
+	}
+	out := []File{}
+	for _, f := range files {
+		if f.Language == "" || strings.ContainsAny(f.Path, `/\`) {
+			continue
+		}
+		file, err := outlineFile(filepath.Join(path, f.Path))
+		if err != nil {
+			continue
+		}
+		out = append(out, *file)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out, nil
+}
+
+// outlineFile extracts and outlines one file
+func outlineFile(path string) (*File, error) {
+	if !extractor.GetLanguageRegistry().IsSupported(path) {
+		return nil, fmt.Errorf("unsupported file type: %s", path)
+	}
+	m, err := extractor.ExtractFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("extracting %s: %w", path, err)
+	}
+	// Not every extractor names its language
+	if m.Language == "" {
+		m.Language = scanner.DetectLanguage(filepath.Ext(path))
+	}
+	return &File{Path: path, Language: m.Language, Nodes: FromModule(m)}, nil
+}
+
+// Prune drops the nodes deeper than depth, 1 keeping top-level nodes only.
+// depth <= 0 keeps everything.
+func Prune(nodes []*Node, depth int) {
+	if depth <= 0 {
+		return
+	}
+	for _, n := range nodes {
+		if depth == 1 {
+			n.Children = nil
+			continue
+		}
+		Prune(n.Children, depth-1)
+	}
+}
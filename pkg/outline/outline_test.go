@@ -0,0 +1,131 @@
+package outline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// render flattens nodes to "kind name detail" lines indented by depth
+func render(nodes []*Node, depth int) []string {
+	var out []string
+	for _, n := range nodes {
+		line := ""
+		for range depth {
+			line += "  "
+		}
+		line += n.Kind + " " + n.Name
+		if n.Detail != "" {
+			line += " " + n.Detail
+		}
+		out = append(out, line)
+		out = append(out, render(n.Children, depth+1)...)
+	}
+	return out
+}
+
+func assertLines(t *testing.T, got, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("outline =\n%q\nwant\n%q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("outline =\n%q\nwant\n%q", got, want)
+		}
+	}
+}
+
+func TestFromModuleGo(t *testing.T) {
+	m := &types.ModuleInfo{
+		Language: "go",
+		Functions: []types.Function{
+			{Name: "New", Params: "()", ReturnType: "*Store", LineNumber: 20},
+			{Name: "Get", Params: "(key string)", ReturnType: "int", LineNumber: 15, IsMethod: true, Receiver: "Store"},
+			{Name: "Close", Params: "()", LineNumber: 30, IsMethod: true, Receiver: "Remote"},
+		},
+		Classes: []types.Class{
+			{Name: "Store", LineNumber: 3, Fields: []types.Field{{Name: "sync.Mutex", Type: "sync.Mutex", Embedded: true}, {Name: "items", Type: "map[string]int"}}},
+			{Name: "Reader", LineNumber: 10, Methods: []types.Method{{Name: "io.Closer"}, {Name: "Read", Params: "()", ReturnType: "error"}}},
+		},
+		Interfaces: []types.Interface{
+			{Name: "Reader", LineNumber: 10, Methods: []types.Method{{Name: "io.Closer"}, {Name: "Read", Params: "()", ReturnType: "error"}}},
+		},
+		Structs: []types.Struct{
+			{Name: "Store", LineNumber: 3, FieldInfo: []types.Field{{Name: "items", Type: "map[string]int"}}},
+		},
+	}
+
+	assertLines(t, render(FromModule(m), 0), []string{
+		"struct Store sync.Mutex",
+		"  field items map[string]int",
+		"  method Get (key string) int",
+		"interface Reader io.Closer",
+		"  method Read () error",
+		"function New () *Store",
+		"method Remote.Close ()",
+	})
+}
+
+func TestFromModuleNested(t *testing.T) {
+	m := &types.ModuleInfo{
+		Language: "python",
+		Functions: []types.Function{
+			{Name: "outer", Params: "(a)", LineNumber: 10},
+			{Name: "helper", Params: "(b: int)", ReturnType: "str", LineNumber: 11, NestedIn: "outer"},
+			{Name: "deep", Params: "()", LineNumber: 12, NestedIn: "helper"},
+		},
+		Classes: []types.Class{
+			{Name: "Model", Bases: []string{"Base"}, LineNumber: 1, Methods: []types.Method{{Name: "save", Params: "(self)", LineNumber: 2}}},
+		},
+		Enums: []types.Enum{{Name: "Color", Variants: []string{"RED", "GREEN"}, LineNumber: 20}},
+	}
+
+	nodes := FromModule(m)
+	assertLines(t, render(nodes, 0), []string{
+		"class Model Base",
+		"  method save (self)",
+		"function outer (a)",
+		"  function helper (b: int) -> str",
+		"    function deep ()",
+		"enum Color",
+		"  variant RED",
+		"  variant GREEN",
+	})
+
+	Prune(nodes, 1)
+	assertLines(t, render(nodes, 0), []string{"class Model Base", "function outer (a)", "enum Color"})
+}
+
+func TestBuildPackage(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"store.go":     "package store\n\ntype Store struct{}\n\nfunc (s *Store) Get() int { return 0 }\n",
+		"open.go":      "package store\n\nfunc Open() *Store { return nil }\n",
+		"sub/other.go": "package sub\n\nfunc Other() {}\n",
+	}
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	outlines, err := Build(dir)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if len(outlines) != 2 || filepath.Base(outlines[0].Path) != "open.go" || filepath.Base(outlines[1].Path) != "store.go" {
+		t.Fatalf("Build = %+v, want open.go and store.go only", outlines)
+	}
+	assertLines(t, render(outlines[1].Nodes, 0), []string{"struct Store", "  method Get () int"})
+
+	if _, err := Build(filepath.Join(dir, "missing.go")); err == nil {
+		t.Error("Build of a missing file succeeded")
+	}
+}
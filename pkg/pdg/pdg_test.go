@@ -2,6 +2,8 @@ package pdg
 
 import (
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/l3aro/go-context-query/pkg/cfg"
@@ -936,3 +938,51 @@ func TestGetAllNodesAtLine(t *testing.T) {
 		t.Errorf("GetAllNodesAtLine(10) = %d nodes, want 0", len(nodes))
 	}
 }
+
+// TestSliceEnclosingFunction tests that Slice finds the function around the
+// line and returns the source of the slice lines
+func TestSliceEnclosingFunction(t *testing.T) {
+	src := `package main
+
+func helper() int {
+	return 1
+}
+
+func compute(n int) int {
+	x := n + 1
+	y := x * 2
+	z := 7
+	return y + z
+}
+`
+	file := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(file, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Slice(SliceQuery{File: file, Line: 9})
+	if err != nil {
+		t.Fatalf("Slice() error = %v", err)
+	}
+	if result.FunctionName != "compute" {
+		t.Errorf("FunctionName = %q, want compute", result.FunctionName)
+	}
+	if result.Direction != "backward" {
+		t.Errorf("Direction = %q, want backward", result.Direction)
+	}
+
+	code := make(map[int]string)
+	for _, l := range result.Lines {
+		code[l.Line] = l.Code
+	}
+	if code[8] != "\tx := n + 1" || code[9] != "\ty := x * 2" {
+		t.Errorf("Lines = %+v, want lines 8 and 9 with their code", result.Lines)
+	}
+	if len(result.Lines) != len(result.SliceLines) {
+		t.Errorf("got %d lines for %d slice lines", len(result.Lines), len(result.SliceLines))
+	}
+
+	if _, err := Slice(SliceQuery{File: file, Line: 1}); err == nil {
+		t.Error("Slice() on a line outside any function should fail")
+	}
+}
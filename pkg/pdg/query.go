@@ -0,0 +1,148 @@
+package pdg
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/l3aro/go-context-query/pkg/extractor"
+)
+
+// SliceQuery describes a slice request against a source file.
+type SliceQuery struct {
+	// File is the source file to slice.
+	File string
+	// Function is the function containing Line. When empty, the function
+	// enclosing Line is found by parsing File.
+	Function string
+	// Line is the line to slice from.
+	Line int
+	// Variable restricts the slice to dependencies through this variable.
+	Variable *string
+	// Forward computes a forward slice instead of a backward one.
+	Forward bool
+}
+
+// SliceLine is a line of a slice with its source code.
+type SliceLine struct {
+	Line int    `json:"line"`
+	Code string `json:"code"`
+}
+
+// SliceResult is the result of a slice query.
+type SliceResult struct {
+	File         string      `json:"file"`
+	FunctionName string      `json:"function_name"`
+	Line         int         `json:"line"`
+	Direction    string      `json:"direction"`
+	Variable     string      `json:"variable,omitempty"`
+	SliceLines   []int       `json:"slice_lines"`
+	Lines        []SliceLine `json:"lines"`
+}
+
+// Slice runs a backward or forward slice for q and returns the slice lines,
+// sorted, along with their source code.
+func Slice(q SliceQuery) (*SliceResult, error) {
+	if q.Line <= 0 {
+		return nil, fmt.Errorf("line number must be positive: %d", q.Line)
+	}
+
+	var pdgInfo *PDGInfo
+	var err error
+	if q.Function == "" {
+		q.Function, pdgInfo, err = enclosingFunction(q.File, q.Line)
+	} else {
+		pdgInfo, err = ExtractPDG(q.File, q.Function)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SliceResult{
+		File:         q.File,
+		FunctionName: q.Function,
+		Line:         q.Line,
+		Direction:    "backward",
+	}
+	if q.Variable != nil {
+		result.Variable = *q.Variable
+	}
+
+	var sliceLines []int
+	if q.Forward {
+		result.Direction = "forward"
+		sliceLines = ForwardSlice(pdgInfo, q.Line, q.Variable)
+	} else {
+		sliceLines = BackwardSlice(pdgInfo, q.Line, q.Variable)
+	}
+	if sliceLines == nil {
+		sliceLines = []int{}
+	}
+	slices.Sort(sliceLines)
+	result.SliceLines = sliceLines
+
+	source, err := os.ReadFile(q.File)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", q.File, err)
+	}
+	lines := strings.Split(string(source), "\n")
+	result.Lines = make([]SliceLine, 0, len(sliceLines))
+	for _, n := range sliceLines {
+		if n > len(lines) {
+			continue
+		}
+		result.Lines = append(result.Lines, SliceLine{Line: n, Code: strings.TrimRight(lines[n-1], "\r")})
+	}
+
+	return result, nil
+}
+
+// enclosingFunction finds the innermost function or method in file whose
+// body contains line, returning its name and PDG. Candidates are tried from
+// the closest preceding definition outwards, so a line after a nested
+// function's body resolves to the function around it.
+func enclosingFunction(file string, line int) (string, *PDGInfo, error) {
+	moduleInfo, err := extractor.ExtractFile(file)
+	if err != nil {
+		return "", nil, fmt.Errorf("extracting %s: %w", file, err)
+	}
+
+	type candidate struct {
+		name string
+		line int
+	}
+	var candidates []candidate
+	for _, fn := range moduleInfo.Functions {
+		candidates = append(candidates, candidate{fn.Name, fn.LineNumber})
+	}
+	for _, cls := range moduleInfo.Classes {
+		for _, m := range cls.Methods {
+			candidates = append(candidates, candidate{m.Name, m.LineNumber})
+		}
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int { return b.line - a.line })
+
+	for _, c := range candidates {
+		if c.line > line {
+			continue
+		}
+		pdgInfo, err := ExtractPDG(file, c.name)
+		if err != nil || pdgInfo == nil {
+			continue
+		}
+		if line <= lastLine(pdgInfo) {
+			return c.name, pdgInfo, nil
+		}
+	}
+	return "", nil, fmt.Errorf("no function found at %s:%d", file, line)
+}
+
+// lastLine returns the last source line covered by the PDG's nodes.
+func lastLine(pdgInfo *PDGInfo) int {
+	last := 0
+	for _, node := range pdgInfo.Nodes {
+		last = max(last, node.EndLine)
+	}
+	return last
+}
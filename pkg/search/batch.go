@@ -0,0 +1,146 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// BatchQuery is a single semantic query in a batch
+type BatchQuery struct {
+	Query string `json:"query"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+// UnitRef points at a unit in BatchResult.Units with the score it had for
+// one query
+type UnitRef struct {
+	Ref   string  `json:"ref"`
+	Score float32 `json:"score"`
+}
+
+// BatchQueryResult holds the results of one query in a batch. With
+// deduplication, Context lists references into BatchResult.Units; without
+// it, Results holds the full results.
+type BatchQueryResult struct {
+	Query   string         `json:"query"`
+	Context []UnitRef      `json:"context,omitempty"`
+	Results []SearchResult `json:"results,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// BatchResult is the response to a batch of queries. Units that several
+// queries return are stored once in Units, keyed by reference, with the
+// best score any query gave them.
+type BatchResult struct {
+	Results []BatchQueryResult      `json:"results"`
+	Units   map[string]SearchResult `json:"units,omitempty"`
+	// TotalHits is the number of results across all queries
+	TotalHits int `json:"total_hits"`
+	// UniqueUnits is the number of distinct units among those results
+	UniqueUnits int `json:"unique_units"`
+	// Degradations are the features skipped or fallen back on while
+	// answering; the daemon sets them
+	Degradations types.Degradations `json:"degradations,omitempty"`
+}
+
+// UnitKey returns the key a result is deduplicated under: its URI, or its
+// location when it has none
+func UnitKey(r SearchResult) string {
+	if r.URI != "" {
+		return r.URI
+	}
+	return fmt.Sprintf("%s:%d#%s", r.FilePath, r.LineNumber, r.Name)
+}
+
+// SearchBatch runs several queries with a single embedding request. A query
+// that fails records its error in its own result rather than failing the
+// batch. When dedupe is true, shared units are returned once in
+// BatchResult.Units and each query lists references to them.
+func (s *Searcher) SearchBatch(ctx context.Context, queries []BatchQuery, defaultLimit int, dedupe bool) (*BatchResult, error) {
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("no queries")
+	}
+
+	// Embed all non-empty queries at once
+	var texts []string
+	textIndex := make([]int, len(queries))
+	for i, q := range queries {
+		textIndex[i] = -1
+		if strings.TrimSpace(q.Query) == "" {
+			continue
+		}
+		textIndex[i] = len(texts)
+		texts = append(texts, GemmaQueryPrefix+q.Query)
+	}
+
+	var embeddings [][]float32
+	if len(texts) > 0 {
+		var err error
+		embeddings, err = s.embedProvider.EmbedContext(ctx, texts)
+		if err != nil {
+			return nil, fmt.Errorf("embedding queries: %w", err)
+		}
+		if len(embeddings) != len(texts) {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(embeddings))
+		}
+	}
+
+	perQuery := make([][]SearchResult, len(queries))
+	errs := make([]error, len(queries))
+	for i, q := range queries {
+		if textIndex[i] < 0 {
+			errs[i] = fmt.Errorf("query cannot be empty")
+			continue
+		}
+		limit := q.Limit
+		if limit <= 0 {
+			limit = defaultLimit
+		}
+		perQuery[i], errs[i] = s.SearchWithEmbedding(embeddings[textIndex[i]], limit)
+	}
+
+	return CombineBatch(queries, perQuery, errs, dedupe), nil
+}
+
+// CombineBatch assembles per-query results into a BatchResult. results and
+// errs are indexed like queries; errs may be nil.
+func CombineBatch(queries []BatchQuery, results [][]SearchResult, errs []error, dedupe bool) *BatchResult {
+	batch := &BatchResult{Results: make([]BatchQueryResult, len(queries))}
+	if dedupe {
+		batch.Units = make(map[string]SearchResult)
+	}
+	seen := make(map[string]bool)
+
+	for i, q := range queries {
+		qr := BatchQueryResult{Query: q.Query}
+		if errs != nil && errs[i] != nil {
+			qr.Error = errs[i].Error()
+			batch.Results[i] = qr
+			continue
+		}
+
+		for _, r := range results[i] {
+			key := UnitKey(r)
+			batch.TotalHits++
+			if !seen[key] {
+				seen[key] = true
+				batch.UniqueUnits++
+			}
+
+			if !dedupe {
+				qr.Results = append(qr.Results, r)
+				continue
+			}
+			if existing, ok := batch.Units[key]; !ok || r.Score > existing.Score {
+				batch.Units[key] = r
+			}
+			qr.Context = append(qr.Context, UnitRef{Ref: key, Score: r.Score})
+		}
+		batch.Results[i] = qr
+	}
+
+	return batch
+}
@@ -0,0 +1,120 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCombineBatchDedupe(t *testing.T) {
+	shared := SearchResult{URI: "gcq://main.go#handleRequest", FilePath: "main.go", Name: "handleRequest"}
+	other := SearchResult{FilePath: "utils/helper.go", LineNumber: 25, Name: "formatResponse"}
+
+	queries := []BatchQuery{{Query: "handle requests"}, {Query: "format output"}, {Query: "broken"}}
+	results := [][]SearchResult{
+		{withScore(shared, 0.9)},
+		{withScore(other, 0.8), withScore(shared, 0.5)},
+		nil,
+	}
+	errs := []error{nil, nil, errors.New("search failed")}
+
+	batch := CombineBatch(queries, results, errs, true)
+
+	if batch.TotalHits != 3 {
+		t.Errorf("TotalHits = %d, want 3", batch.TotalHits)
+	}
+	if batch.UniqueUnits != 2 || len(batch.Units) != 2 {
+		t.Errorf("Expected 2 unique units, got %d (%d in table)", batch.UniqueUnits, len(batch.Units))
+	}
+
+	sharedKey := UnitKey(shared)
+	if got := batch.Units[sharedKey].Score; got != 0.9 {
+		t.Errorf("Expected shared unit to keep best score 0.9, got %f", got)
+	}
+	if _, ok := batch.Units["utils/helper.go:25#formatResponse"]; !ok {
+		t.Error("Expected unit without URI to be keyed by location")
+	}
+
+	second := batch.Results[1]
+	if len(second.Context) != 2 || second.Results != nil {
+		t.Fatalf("Expected 2 references and no inline results, got %+v", second)
+	}
+	if second.Context[1].Ref != sharedKey || second.Context[1].Score != 0.5 {
+		t.Errorf("Expected reference to keep per-query score, got %+v", second.Context[1])
+	}
+
+	if batch.Results[2].Error != "search failed" {
+		t.Errorf("Expected per-query error, got %q", batch.Results[2].Error)
+	}
+}
+
+func TestCombineBatchNoDedupe(t *testing.T) {
+	unit := SearchResult{URI: "gcq://a.go#f", Name: "f", Score: 0.7}
+	batch := CombineBatch(
+		[]BatchQuery{{Query: "a"}, {Query: "b"}},
+		[][]SearchResult{{unit}, {unit}},
+		nil, false,
+	)
+
+	if batch.Units != nil {
+		t.Error("Expected no unit table without dedupe")
+	}
+	for i, qr := range batch.Results {
+		if len(qr.Results) != 1 || len(qr.Context) != 0 {
+			t.Errorf("Query %d: expected 1 inline result, got %+v", i, qr)
+		}
+	}
+	if batch.TotalHits != 2 || batch.UniqueUnits != 1 {
+		t.Errorf("Expected 2 hits and 1 unique unit, got %d and %d", batch.TotalHits, batch.UniqueUnits)
+	}
+}
+
+func TestSearchBatch(t *testing.T) {
+	dimension := 8
+	searcher := NewSearcher(&mockProvider{dimension: dimension}, createTestIndex(dimension))
+
+	batch, err := searcher.SearchBatch(context.Background(), []BatchQuery{
+		{Query: "handle request"},
+		{Query: "validate token", Limit: 2},
+		{Query: "  "},
+	}, 3, true)
+	if err != nil {
+		t.Fatalf("SearchBatch failed: %v", err)
+	}
+
+	if len(batch.Results) != 3 {
+		t.Fatalf("Expected 3 query results, got %d", len(batch.Results))
+	}
+	if len(batch.Results[0].Context) != 3 {
+		t.Errorf("Expected default limit of 3, got %d", len(batch.Results[0].Context))
+	}
+	if len(batch.Results[1].Context) != 2 {
+		t.Errorf("Expected per-query limit of 2, got %d", len(batch.Results[1].Context))
+	}
+	if batch.Results[2].Error == "" {
+		t.Error("Expected error for empty query")
+	}
+	for _, qr := range batch.Results {
+		for _, ref := range qr.Context {
+			if _, ok := batch.Units[ref.Ref]; !ok {
+				t.Errorf("Reference %q missing from unit table", ref.Ref)
+			}
+		}
+	}
+
+	if _, err := searcher.SearchBatch(context.Background(), nil, 3, true); err == nil {
+		t.Error("Expected error for empty batch")
+	}
+}
+
+func TestSearchBatchProviderError(t *testing.T) {
+	searcher := NewSearcher(&mockProviderWithError{}, createTestIndex(3))
+	if _, err := searcher.SearchBatch(context.Background(), []BatchQuery{{Query: "x"}}, 3, true); err == nil {
+		t.Error("Expected error when embedding fails")
+	}
+}
+
+func withScore(r SearchResult, score float32) SearchResult {
+	r.Score = score
+	return r
+}
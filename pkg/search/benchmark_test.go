@@ -0,0 +1,36 @@
+package search
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/fixture"
+	"github.com/l3aro/go-context-query/pkg/semantic"
+)
+
+func BenchmarkSearch(b *testing.B) {
+	for _, files := range []int{100, 500} {
+		b.Run(fmt.Sprintf("files=%d", files), func(b *testing.B) {
+			root := b.TempDir()
+			manifest, err := fixture.Generate(root, fixture.Options{Files: files, Seed: 1})
+			if err != nil {
+				b.Fatal(err)
+			}
+			provider := &mockProvider{dimension: 256}
+			if _, err := semantic.BuildIndexWithStats(root, provider, semantic.BuildOptions{}); err != nil {
+				b.Fatal(err)
+			}
+			idx, _, err := semantic.LoadIndex(root)
+			if err != nil {
+				b.Fatal(err)
+			}
+			searcher := NewSearcher(provider, idx)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := searcher.Search(manifest.Queries[i%len(manifest.Queries)].Query, 10); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
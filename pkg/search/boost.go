@@ -0,0 +1,24 @@
+package search
+
+import (
+	"github.com/l3aro/go-context-query/pkg/index"
+)
+
+// resultURI returns the unit URI of an index entry: the stored unit's ID,
+// or the entry ID for indexes without units
+func resultURI(res index.SearchResult) string {
+	if res.Metadata.Unit != nil && res.Metadata.Unit.ID != "" {
+		return res.Metadata.Unit.ID
+	}
+	return res.ID
+}
+
+// applyBoosts scales the scores of results by their unit URI's boost.
+// Callers re-sort the results afterwards.
+func applyBoosts(results []index.SearchResult, boosts map[string]float32) {
+	for i := range results {
+		if b, ok := boosts[resultURI(results[i])]; ok && results[i].Score > 0 {
+			results[i].Score *= b
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package search
+
+import (
+	"testing"
+)
+
+func TestSearchBoosts(t *testing.T) {
+	idx := createExcludeTestIndex()
+	query := []float32{1, 0, 0}
+	searcher := NewSearcher(&mockProvider{dimension: 3}, idx)
+
+	results, err := searcher.searchIndex(idx.Search, query, 1, SearchOptions{Boosts: map[string]float32{
+		"go://pkg/store#newMockStore": 0.5,
+		"go://pkg/store#openStore":    1.2,
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Metadata.Unit.Name != "openStore" {
+		t.Errorf("results = %+v, want the boosted openStore first", results)
+	}
+}
@@ -0,0 +1,28 @@
+package search
+
+import (
+	"time"
+
+	"github.com/l3aro/go-context-query/pkg/index"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// budgetReason explains a search_budget degradation
+const budgetReason = "search budget ran out; returned the best results scanned so far"
+
+// budgetedSearch returns a search over the backend that stops at deadline,
+// noting in ds when it did. Backends that can't stop early search to the
+// end.
+func (s *Searcher) budgetedSearch(deadline time.Time, ds *types.Degradations) func([]float32, int) ([]index.SearchResult, error) {
+	backend, ok := s.backend.(index.BudgetedBackend)
+	if !ok {
+		return s.backend.Search
+	}
+	return func(query []float32, k int) ([]index.SearchResult, error) {
+		results, partial, err := backend.SearchBefore(query, k, deadline)
+		if partial && ds != nil {
+			ds.Add(types.DegradedSearchBudget, budgetReason, 0)
+		}
+		return results, err
+	}
+}
@@ -0,0 +1,115 @@
+package search
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/l3aro/go-context-query/pkg/index"
+)
+
+// excludePenalty scales the score of a result that matches an excluded
+// term. Such results drop below comparable ones but still surface when
+// nothing else matches the query.
+const excludePenalty = 0.25
+
+// excludeCandidates is the minimum number of candidates fetched when terms
+// are excluded, so penalized results can be replaced by the next best ones
+const excludeCandidates = 50
+
+// ParseExcludeTerms splits "-term" words off query, so "session -mock
+// -fixtures/" searches for "session" while penalizing units that mention
+// mock or live under a fixtures/ path. Queries without such words are
+// returned unchanged.
+func ParseExcludeTerms(query string) (string, []string) {
+	fields := strings.Fields(query)
+	var kept, terms []string
+	for _, f := range fields {
+		if len(f) > 1 && f[0] == '-' && f[1] != '-' {
+			terms = append(terms, f[1:])
+			continue
+		}
+		kept = append(kept, f)
+	}
+	if len(terms) == 0 {
+		return query, nil
+	}
+	return strings.Join(kept, " "), terms
+}
+
+// withQueryExclusions moves "-term" words from query into opts.ExcludeTerms
+func withQueryExclusions(query string, opts SearchOptions) (string, SearchOptions) {
+	query, terms := ParseExcludeTerms(query)
+	if len(terms) > 0 {
+		opts.ExcludeTerms = append(append([]string(nil), opts.ExcludeTerms...), terms...)
+	}
+	return query, opts
+}
+
+// excludeTerm is an excluded term prepared for matching
+type excludeTerm struct {
+	// path is the lowercase term, matched as a substring of the file path
+	path string
+	// tokens must all appear among the unit's name, signature and doc tokens
+	tokens []string
+}
+
+func newExcludeTerms(terms []string) []excludeTerm {
+	var prepared []excludeTerm
+	for _, t := range terms {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		prepared = append(prepared, excludeTerm{path: strings.ToLower(t), tokens: index.Tokenize(t)})
+	}
+	return prepared
+}
+
+// excluded reports whether res matches any of terms
+func excluded(res index.SearchResult, terms []excludeTerm) bool {
+	file := strings.ToLower(resultFile(res.Metadata))
+	var text string
+	if u := res.Metadata.Unit; u != nil {
+		text = u.Name + " " + u.Signature + " " + u.Docstring
+	} else {
+		text = res.ID + " " + res.Metadata.L1Data.Signature + " " + res.Metadata.L1Data.Docstring
+	}
+	var tokens map[string]bool
+
+	for _, term := range terms {
+		if strings.Contains(file, term.path) {
+			return true
+		}
+		if len(term.tokens) == 0 {
+			continue
+		}
+		if tokens == nil {
+			tokens = make(map[string]bool)
+			for _, tok := range index.Tokenize(text) {
+				tokens[tok] = true
+			}
+		}
+		all := true
+		for _, tok := range term.tokens {
+			if !tokens[tok] {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
+
+// rescoreExcluded penalizes results matching terms and re-sorts them by
+// score, keeping the original order among equal scores
+func rescoreExcluded(results []index.SearchResult, terms []excludeTerm) {
+	for i := range results {
+		if results[i].Score > 0 && excluded(results[i], terms) {
+			results[i].Score *= excludePenalty
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+}
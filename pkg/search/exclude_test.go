@@ -0,0 +1,95 @@
+package search
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/index"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+func TestParseExcludeTerms(t *testing.T) {
+	tests := []struct {
+		query     string
+		wantQuery string
+		wantTerms []string
+	}{
+		{"session  handling", "session  handling", nil},
+		{"session -mock handling -fixtures/", "session handling", []string{"mock", "fixtures/"}},
+		{"non-blocking read - --verbose", "non-blocking read - --verbose", nil},
+	}
+	for _, tt := range tests {
+		query, terms := ParseExcludeTerms(tt.query)
+		if query != tt.wantQuery || !slices.Equal(terms, tt.wantTerms) {
+			t.Errorf("ParseExcludeTerms(%q) = %q, %q; want %q, %q", tt.query, query, terms, tt.wantQuery, tt.wantTerms)
+		}
+	}
+}
+
+// createExcludeTestIndex indexes a real unit and two near-identical doubles
+// that excluded terms should push down
+func createExcludeTestIndex() *index.VectorIndex {
+	idx := index.NewVectorIndex(3)
+	units := []struct {
+		name, file string
+		vector     []float32
+	}{
+		{"newMockStore", "pkg/store/store.go", []float32{1, 0, 0}},
+		{"loadFixture", "pkg/store/fixtures/data.go", []float32{0.95, 0.05, 0}},
+		{"openStore", "pkg/store/store.go", []float32{0.9, 0.1, 0}},
+	}
+	for _, u := range units {
+		idx.Add("go://pkg/store#"+u.name, u.vector, types.EmbeddingUnit{Unit: &types.CodeUnit{
+			Name:      u.name,
+			FilePath:  u.file,
+			Signature: "func " + u.name + "()",
+		}})
+	}
+	return idx
+}
+
+func TestSearchExcludeTerms(t *testing.T) {
+	idx := createExcludeTestIndex()
+	query := []float32{1, 0, 0}
+
+	names := func(results []index.SearchResult) []string {
+		var out []string
+		for _, r := range results {
+			out = append(out, r.Metadata.Unit.Name)
+		}
+		return out
+	}
+
+	searcher := NewSearcher(&mockProvider{dimension: 3}, idx)
+	results, err := searcher.searchIndex(idx.Search, query, 2, SearchOptions{ExcludeTerms: []string{"mock", "fixtures/"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := names(results); !slices.Equal(got, []string{"openStore", "newMockStore"}) {
+		t.Errorf("results = %v, want [openStore newMockStore]", got)
+	}
+
+	results, err = searcher.searchIndex(idx.Search, query, 3, SearchOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := names(results); got[0] != "newMockStore" {
+		t.Errorf("results without exclusions = %v", got)
+	}
+}
+
+func TestSearchKeywordsExcludeSyntax(t *testing.T) {
+	searcher := NewSearcher(&mockProvider{dimension: 3}, createKeywordTestIndex())
+
+	results, err := searcher.SearchKeywords("import -spec", 10, SearchOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) < 2 || results[0].Name != "resolveImports" {
+		t.Errorf("expected resolveImports ahead of parseImportSpec, got %+v", results)
+	}
+
+	if _, err := searcher.SearchKeywords("-spec", 10, SearchOptions{}); err == nil {
+		t.Error("expected error for a query with only excluded terms")
+	}
+}
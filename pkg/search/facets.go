@@ -0,0 +1,65 @@
+package search
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/l3aro/go-context-query/pkg/index"
+)
+
+// typeFilterPrefix starts a query word that filters results by unit type
+const typeFilterPrefix = "type:"
+
+// todoType is the unit type of indexed TODO, FIXME and HACK comments
+const todoType = "todo"
+
+// commitType is the unit type of indexed commit messages and pull requests
+const commitType = "commit"
+
+// ParseTypeFilters splits "type:name" words off query, so "retry type:todo"
+// searches for "retry" among todo units only. Several words, or a comma
+// separated list, keep units of any of the types. Queries without such
+// words are returned unchanged.
+func ParseTypeFilters(query string) (string, []string) {
+	fields := strings.Fields(query)
+	var kept, unitTypes []string
+	for _, f := range fields {
+		if name, ok := strings.CutPrefix(strings.ToLower(f), typeFilterPrefix); ok && name != "" {
+			for _, t := range strings.Split(name, ",") {
+				if t != "" {
+					unitTypes = append(unitTypes, t)
+				}
+			}
+			continue
+		}
+		kept = append(kept, f)
+	}
+	if len(unitTypes) == 0 {
+		return query, nil
+	}
+	return strings.Join(kept, " "), unitTypes
+}
+
+// withQueryFilters moves "-term" words from query into opts.ExcludeTerms
+// and "type:name" words into opts.Types
+func withQueryFilters(query string, opts SearchOptions) (string, SearchOptions) {
+	query, opts = withQueryExclusions(query, opts)
+	query, unitTypes := ParseTypeFilters(query)
+	if len(unitTypes) > 0 {
+		opts.Types = append(append([]string(nil), opts.Types...), unitTypes...)
+	}
+	return query, opts
+}
+
+// matchesType reports whether an index entry is of one of unitTypes. With
+// none, every entry but todo and commit units matches.
+func matchesType(res index.SearchResult, unitTypes []string) bool {
+	t := res.Metadata.L1Data.Type
+	if res.Metadata.Unit != nil {
+		t = res.Metadata.Unit.Type
+	}
+	if len(unitTypes) == 0 {
+		return t != todoType && t != commitType
+	}
+	return slices.Contains(unitTypes, t)
+}
@@ -0,0 +1,70 @@
+package search
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/index"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+func TestParseTypeFilters(t *testing.T) {
+	tests := []struct {
+		query     string
+		wantQuery string
+		wantTypes []string
+	}{
+		{"retry  logic", "retry  logic", nil},
+		{"retry type:todo logic", "retry logic", []string{"todo"}},
+		{"Type:Function,method parse", "parse", []string{"function", "method"}},
+		{"type: parse", "type: parse", nil},
+	}
+	for _, tt := range tests {
+		query, unitTypes := ParseTypeFilters(tt.query)
+		if query != tt.wantQuery || !slices.Equal(unitTypes, tt.wantTypes) {
+			t.Errorf("ParseTypeFilters(%q) = %q, %q; want %q, %q", tt.query, query, unitTypes, tt.wantQuery, tt.wantTypes)
+		}
+	}
+}
+
+func TestSearchTypeFilter(t *testing.T) {
+	idx := index.NewVectorIndex(3)
+	for i, u := range []struct{ name, unitType string }{
+		{"TODO(alice)", "todo"},
+		{"retry", "function"},
+		{"Client", "class"},
+		{"1a2b3c4", "commit"},
+	} {
+		idx.Add("go://c.go#"+u.name, []float32{1, float32(i) * 0.1, 0}, types.EmbeddingUnit{
+			Unit: &types.CodeUnit{Name: u.name, Type: u.unitType, FilePath: "c.go"},
+		})
+	}
+	searcher := NewSearcher(&mockProvider{dimension: 3}, idx)
+
+	tests := []struct {
+		queries []string
+		opts    SearchOptions
+		want    []string
+	}{
+		{[]string{"retry"}, SearchOptions{}, []string{"Client", "retry"}},
+		{[]string{"retry type:todo"}, SearchOptions{}, []string{"TODO(alice)"}},
+		{[]string{"why was retry added type:commit"}, SearchOptions{}, []string{"1a2b3c4"}},
+		{[]string{"retry", "type:class"}, SearchOptions{}, []string{"Client"}},
+		{[]string{"retry"}, SearchOptions{Types: []string{"function", "todo"}}, []string{"TODO(alice)", "retry"}},
+	}
+	for _, tt := range tests {
+		results, err := searcher.SearchQueries(context.Background(), "semantic", tt.queries, 5, tt.opts)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.queries, err)
+		}
+		var names []string
+		for _, r := range results {
+			names = append(names, r.Name)
+		}
+		slices.Sort(names)
+		if !slices.Equal(names, tt.want) {
+			t.Errorf("%q with %v: got %v, want %v", tt.queries, tt.opts.Types, names, tt.want)
+		}
+	}
+}
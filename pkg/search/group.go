@@ -0,0 +1,76 @@
+package search
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+)
+
+// Result grouping modes accepted by GroupResults
+const (
+	// GroupByFile collapses results from the same file
+	GroupByFile = "file"
+	// GroupByPackage collapses results from the same directory, which is
+	// the package for Go and Python and the module folder elsewhere
+	GroupByPackage = "package"
+)
+
+// ResultGroup is a set of results sharing a file or package
+type ResultGroup struct {
+	// Key is the file path or package directory ("." for the root)
+	Key string `json:"key"`
+	// Count is the number of results in the group
+	Count int `json:"count"`
+	// Score is the best score in the group
+	Score float32 `json:"score"`
+	// Results are the group's results, best first
+	Results []SearchResult `json:"results"`
+}
+
+// ValidateGroupBy returns an error unless by is "", GroupByFile or GroupByPackage
+func ValidateGroupBy(by string) error {
+	switch by {
+	case "", GroupByFile, GroupByPackage:
+		return nil
+	}
+	return fmt.Errorf("unknown group_by: %s (must be '%s' or '%s')", by, GroupByFile, GroupByPackage)
+}
+
+// GroupKey returns the group a result in filePath belongs to
+func GroupKey(filePath, by string) string {
+	file := filepath.ToSlash(filePath)
+	if by == GroupByPackage {
+		return path.Dir(file)
+	}
+	return file
+}
+
+// GroupResults collapses ranked results into groups by file or package.
+// Groups are ordered by their best result, so the ranking is preserved.
+func GroupResults(results []SearchResult, by string) ([]ResultGroup, error) {
+	if by == "" {
+		by = GroupByFile
+	}
+	if err := ValidateGroupBy(by); err != nil {
+		return nil, err
+	}
+
+	var groups []ResultGroup
+	positions := make(map[string]int)
+	for _, r := range results {
+		key := GroupKey(r.FilePath, by)
+		i, ok := positions[key]
+		if !ok {
+			i = len(groups)
+			positions[key] = i
+			groups = append(groups, ResultGroup{Key: key, Score: r.Score})
+		}
+		g := &groups[i]
+		g.Results = append(g.Results, r)
+		g.Count++
+		if r.Score > g.Score {
+			g.Score = r.Score
+		}
+	}
+	return groups, nil
+}
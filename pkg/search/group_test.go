@@ -0,0 +1,45 @@
+package search
+
+import "testing"
+
+func TestGroupResults(t *testing.T) {
+	results := []SearchResult{
+		{Name: "Add", FilePath: "pkg/index/index.go", Score: 0.9},
+		{Name: "Search", FilePath: "pkg/search/search.go", Score: 0.8},
+		{Name: "Remove", FilePath: "pkg/index/index.go", Score: 0.7},
+		{Name: "NewHNSW", FilePath: "pkg/index/hnsw.go", Score: 0.6},
+		{Name: "main", FilePath: "main.go", Score: 0.5},
+	}
+
+	groups, err := GroupResults(results, GroupByFile)
+	if err != nil {
+		t.Fatalf("GroupResults failed: %v", err)
+	}
+	if len(groups) != 4 || groups[0].Key != "pkg/index/index.go" || groups[0].Count != 2 {
+		t.Fatalf("unexpected file groups: %+v", groups)
+	}
+	if groups[0].Results[1].Name != "Remove" || groups[0].Score != 0.9 {
+		t.Errorf("expected group results in rank order, got %+v", groups[0])
+	}
+
+	groups, err = GroupResults(results, GroupByPackage)
+	if err != nil {
+		t.Fatalf("GroupResults failed: %v", err)
+	}
+	want := []struct {
+		key   string
+		count int
+	}{{"pkg/index", 3}, {"pkg/search", 1}, {".", 1}}
+	if len(groups) != len(want) {
+		t.Fatalf("expected %d package groups, got %+v", len(want), groups)
+	}
+	for i, w := range want {
+		if groups[i].Key != w.key || groups[i].Count != w.count {
+			t.Errorf("group %d = %s (%d), want %s (%d)", i, groups[i].Key, groups[i].Count, w.key, w.count)
+		}
+	}
+
+	if _, err := GroupResults(results, "module"); err == nil {
+		t.Error("expected error for unknown group_by")
+	}
+}
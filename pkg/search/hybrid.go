@@ -0,0 +1,111 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// rrfK dampens the weight of top ranks in reciprocal rank fusion; 60 is the
+// value from the original RRF paper and works well without tuning
+const rrfK = 60
+
+// hybridCandidates is the minimum number of candidates taken from each
+// pass before fusion
+const hybridCandidates = 50
+
+// HybridSearcher combines vector search with a BM25 keyword pass over unit
+// names, signatures and docstrings, fusing the two rankings with reciprocal
+// rank fusion. A unit ranked well by either pass surfaces, so exact
+// identifier queries such as "parseImportSpec" find their unit even when its
+// embedding is not the nearest.
+//
+// The keyword index is built on the first search, so the vector index must
+// not change while the HybridSearcher is in use.
+type HybridSearcher struct {
+	searcher *Searcher
+}
+
+// NewHybridSearcher creates a HybridSearcher over searcher's index. Use
+// Searcher.Hybrid to share one keyword index between calls.
+func NewHybridSearcher(searcher *Searcher) *HybridSearcher {
+	return &HybridSearcher{searcher: searcher}
+}
+
+// Search performs hybrid search and returns the top-k fused results,
+// leaving out units from test files
+func (h *HybridSearcher) Search(query string, k int) ([]SearchResult, error) {
+	return h.SearchWithOptions(context.Background(), query, k, SearchOptions{})
+}
+
+// SearchWithOptions performs hybrid search with per-query options. Files
+// limits the vector pass to the best files; the keyword pass always
+// considers every unit.
+//
+// Result scores are fused RRF scores scaled so that a unit ranked first by
+// both passes scores 1.
+func (h *HybridSearcher) SearchWithOptions(ctx context.Context, query string, k int, opts SearchOptions) ([]SearchResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("query cannot be empty")
+	}
+	if k <= 0 {
+		return nil, fmt.Errorf("k must be positive, got %d", k)
+	}
+
+	candidates := k * 4
+	if candidates < hybridCandidates {
+		candidates = hybridCandidates
+	}
+
+	semantic, err := h.searcher.SearchWithOptions(ctx, query, candidates, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	lexical, err := h.searcher.SearchKeywords(query, candidates, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := FuseRRF(semantic, lexical)
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
+
+// FuseRRF merges rankings with reciprocal rank fusion: each result scores
+// the sum of 1/(60+rank) over the rankings it appears in. Results are
+// matched by unit key and returned best first, with scores scaled so that a
+// result ranked first in every ranking scores 1.
+func FuseRRF(rankings ...[]SearchResult) []SearchResult {
+	if len(rankings) == 0 {
+		return nil
+	}
+
+	fused := make(map[string]*SearchResult)
+	scores := make(map[string]float64)
+	var order []string
+	for _, ranking := range rankings {
+		for rank, r := range ranking {
+			key := UnitKey(r)
+			if _, ok := fused[key]; !ok {
+				r := r
+				fused[key] = &r
+				order = append(order, key)
+			}
+			scores[key] += 1.0 / float64(rrfK+rank+1)
+		}
+	}
+
+	best := float64(len(rankings)) / float64(rrfK+1)
+	results := make([]SearchResult, 0, len(order))
+	for _, key := range order {
+		r := *fused[key]
+		r.Score = float32(scores[key] / best)
+		results = append(results, r)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	return results
+}
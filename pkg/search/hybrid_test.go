@@ -0,0 +1,148 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/index"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// createKeywordTestIndex indexes units whose vectors point away from any
+// mock query, so only the keyword pass can rank parseImportSpec first
+func createKeywordTestIndex() *index.VectorIndex {
+	idx := index.NewVectorIndex(3)
+	units := []struct {
+		name, doc string
+		vector    []float32
+	}{
+		{"parseImportSpec", "Parses a single import spec.", []float32{-1, -1, -1}},
+		{"loadConfig", "Loads the configuration file.", []float32{1, 1, 1}},
+		{"resolveImports", "Resolves every import of a module.", []float32{1, 0.9, 1}},
+		{"TestParseImportSpec", "", []float32{-1, -1, -0.9}},
+	}
+	for _, u := range units {
+		file := "pkg/imports.go"
+		if u.name == "TestParseImportSpec" {
+			file = "pkg/imports_test.go"
+		}
+		idx.Add("go://pkg#"+u.name, u.vector, types.EmbeddingUnit{Unit: &types.CodeUnit{
+			Name:      u.name,
+			FilePath:  file,
+			Signature: "func " + u.name + "()",
+			Docstring: u.doc,
+			IsTest:    file == "pkg/imports_test.go",
+		}})
+	}
+	return idx
+}
+
+func TestSearchKeywords(t *testing.T) {
+	searcher := NewSearcher(&mockProvider{dimension: 3}, createKeywordTestIndex())
+
+	results, err := searcher.SearchKeywords("parseImportSpec", 10, SearchOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) == 0 || results[0].Name != "parseImportSpec" {
+		t.Fatalf("expected parseImportSpec first, got %+v", results)
+	}
+	for _, r := range results {
+		if r.Name == "TestParseImportSpec" {
+			t.Errorf("expected test units to be left out, got %+v", results)
+		}
+	}
+
+	results, err = searcher.SearchKeywords("parseImportSpec", 10, SearchOptions{IncludeTests: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 3 {
+		t.Errorf("expected the test unit with IncludeTests, got %+v", results)
+	}
+
+	// A saved text index is used as is, even for units the vector index lacks
+	textIndex := index.NewTextIndex()
+	textIndex.Add(index.TextDoc{ID: "go://other#resolveSpec", File: "other.go", Line: 3, Name: "resolveSpec"},
+		index.TextField{Text: "resolveSpec", Weight: 1})
+	results, err = NewSearcher(&mockProvider{dimension: 3}, createKeywordTestIndex()).
+		WithTextIndex(textIndex).SearchKeywords("spec", 10, SearchOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].FilePath != "other.go" || results[0].LineNumber != 3 {
+		t.Errorf("expected the saved index's unit, got %+v", results)
+	}
+
+	if _, err := searcher.SearchKeywords("", 10, SearchOptions{}); err == nil {
+		t.Error("expected error for empty query")
+	}
+}
+
+func TestHybridSearch(t *testing.T) {
+	searcher := NewSearcher(&mockProvider{dimension: 3}, createKeywordTestIndex())
+
+	contains := func(results []SearchResult, name string) bool {
+		for _, r := range results {
+			if r.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	vector, err := searcher.Search("parseImportSpec", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if contains(vector, "parseImportSpec") {
+		t.Fatal("test index should keep parseImportSpec out of the vector top 2")
+	}
+
+	results, err := searcher.Hybrid().Search("parseImportSpec", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 || !contains(results, "parseImportSpec") {
+		t.Fatalf("expected hybrid search to surface parseImportSpec, got %+v", results)
+	}
+	if results[0].Score <= 0 || results[0].Score > 1 {
+		t.Errorf("expected a fused score in (0, 1], got %f", results[0].Score)
+	}
+
+	if contains(results, "TestParseImportSpec") {
+		t.Errorf("expected test units to be left out, got %+v", results)
+	}
+	results, err = searcher.Hybrid().SearchWithOptions(context.Background(), "parseImportSpec", 4, SearchOptions{IncludeTests: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 4 {
+		t.Errorf("expected test units with IncludeTests, got %d results", len(results))
+	}
+
+	if searcher.Hybrid() != searcher.Hybrid() {
+		t.Error("expected Hybrid to return a shared searcher")
+	}
+	if _, err := searcher.Hybrid().Search("", 2); err == nil {
+		t.Error("expected error for empty query")
+	}
+	if _, err := searcher.Hybrid().Search("x", 0); err == nil {
+		t.Error("expected error for zero k")
+	}
+}
+
+func TestFuseRRF(t *testing.T) {
+	a := []SearchResult{{URI: "a"}, {URI: "b"}}
+	b := []SearchResult{{URI: "b"}, {URI: "c"}}
+
+	results := FuseRRF(a, b)
+	if len(results) != 3 || results[0].URI != "b" {
+		t.Fatalf("expected b first, got %+v", results)
+	}
+
+	results = FuseRRF(a, a)
+	if results[0].URI != "a" || results[0].Score != 1 {
+		t.Errorf("expected a unit ranked first everywhere to score 1, got %+v", results[0])
+	}
+}
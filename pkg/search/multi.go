@@ -0,0 +1,86 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// QueryFunc runs a single query and returns its top-k results
+type QueryFunc func(query string, k int) ([]SearchResult, error)
+
+// CombineQueries returns query followed by queries, trimmed, without blanks
+// or repeats
+func CombineQueries(query string, queries []string) []string {
+	var combined []string
+	seen := make(map[string]bool)
+	for _, q := range append([]string{query}, queries...) {
+		q = strings.TrimSpace(q)
+		if q == "" || seen[q] {
+			continue
+		}
+		seen[q] = true
+		combined = append(combined, q)
+	}
+	return combined
+}
+
+// FuseQueries runs each query with run and fuses the rankings with
+// reciprocal rank fusion, so agents can ask a natural language question
+// together with candidate identifiers in one search. A unit found by
+// several queries ranks above one found by a single query at the same rank.
+//
+// A single query is run as is, keeping its own scores. Each of several
+// queries contributes at least hybridCandidates results to the fusion.
+func FuseQueries(queries []string, k int, run QueryFunc) ([]SearchResult, error) {
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("query cannot be empty")
+	}
+	if k <= 0 {
+		return nil, fmt.Errorf("k must be positive, got %d", k)
+	}
+	if len(queries) == 1 {
+		return run(queries[0], k)
+	}
+
+	candidates := max(k*4, hybridCandidates)
+	rankings := make([][]SearchResult, len(queries))
+	for i, q := range queries {
+		results, err := run(q, candidates)
+		if err != nil {
+			return nil, fmt.Errorf("query %q: %w", q, err)
+		}
+		rankings[i] = results
+	}
+
+	results := FuseRRF(rankings...)
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
+
+// SearchQueries runs queries in mode ("semantic", "hybrid" or "keyword";
+// empty means semantic) and fuses the rankings with FuseQueries. A
+// "type:name" word in any query filters the results of all of them.
+func (s *Searcher) SearchQueries(ctx context.Context, mode string, queries []string, k int, opts SearchOptions) ([]SearchResult, error) {
+	var kept []string
+	for _, q := range queries {
+		q, unitTypes := ParseTypeFilters(q)
+		opts.Types = append(slices.Clip(opts.Types), unitTypes...)
+		if strings.TrimSpace(q) != "" {
+			kept = append(kept, q)
+		}
+	}
+	return FuseQueries(kept, k, func(query string, k int) ([]SearchResult, error) {
+		switch mode {
+		case "hybrid":
+			return s.Hybrid().SearchWithOptions(ctx, query, k, opts)
+		case "keyword":
+			return s.SearchKeywords(query, k, opts)
+		default:
+			return s.SearchWithOptions(ctx, query, k, opts)
+		}
+	})
+}
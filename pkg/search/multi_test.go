@@ -0,0 +1,64 @@
+package search
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCombineQueries(t *testing.T) {
+	got := CombineQueries(" how are tokens refreshed ", []string{"refreshToken", "", "refreshToken", "how are tokens refreshed"})
+	want := []string{"how are tokens refreshed", "refreshToken"}
+	if !slices.Equal(got, want) {
+		t.Errorf("CombineQueries() = %q, want %q", got, want)
+	}
+	if got := CombineQueries("", nil); got != nil {
+		t.Errorf("CombineQueries() = %q, want nil", got)
+	}
+}
+
+func TestFuseQueries(t *testing.T) {
+	rankings := map[string][]SearchResult{
+		"question":   {{Name: "a", Score: 0.9}, {Name: "b", Score: 0.8}, {Name: "c", Score: 0.7}},
+		"identifier": {{Name: "b", Score: 0.6}, {Name: "d", Score: 0.5}},
+	}
+	var ks []int
+	run := func(query string, k int) ([]SearchResult, error) {
+		ks = append(ks, k)
+		if r, ok := rankings[query]; ok {
+			return r, nil
+		}
+		return nil, errors.New("backend down")
+	}
+
+	results, err := FuseQueries([]string{"question", "identifier"}, 3, run)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, r := range results {
+		names = append(names, r.Name)
+	}
+	if !slices.Equal(names, []string{"b", "a", "d"}) {
+		t.Errorf("fused order = %v, want [b a d]", names)
+	}
+	if ks[0] != hybridCandidates {
+		t.Errorf("expected %d candidates per query, got %d", hybridCandidates, ks[0])
+	}
+
+	// A single query keeps its own scores
+	results, err = FuseQueries([]string{"question"}, 2, run)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results[0].Score != 0.9 {
+		t.Errorf("expected the query's own score, got %v", results[0].Score)
+	}
+
+	if _, err := FuseQueries([]string{"question", "other"}, 3, run); err == nil {
+		t.Error("expected a failing query to fail the search")
+	}
+	if _, err := FuseQueries(nil, 3, run); err == nil {
+		t.Error("expected an error without queries")
+	}
+}
@@ -3,11 +3,16 @@
 package search
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/l3aro/go-context-query/pkg/embed"
 	"github.com/l3aro/go-context-query/pkg/index"
+	"github.com/l3aro/go-context-query/pkg/types"
 )
 
 // GemmaQueryPrefix is the instruction prefix for Gemma models
@@ -15,10 +20,14 @@ const GemmaQueryPrefix = "Given a codebase, find code that: "
 
 // SearchResult represents a single search result with metadata
 type SearchResult struct {
+	// URI is the canonical unit URI this result was indexed under
+	URI string `json:"uri,omitempty"`
 	// FilePath is the path to the file containing this code unit
 	FilePath string `json:"file_path"`
 	// LineNumber is the line where this code unit is defined
 	LineNumber int `json:"line_number"`
+	// EndLine is the last line of the unit, when known
+	EndLine int `json:"end_line,omitempty"`
 	// Name is the name of the function/method/class
 	Name string `json:"name"`
 	// Signature is the function signature
@@ -27,6 +36,10 @@ type SearchResult struct {
 	Docstring string `json:"docstring"`
 	// Type is the type of unit (function, method, class)
 	Type string `json:"type"`
+	// Language is the source language, when known
+	Language string `json:"language,omitempty"`
+	// Metadata holds the annotations enrichers attached to the unit
+	Metadata map[string]string `json:"metadata,omitempty"`
 	// Score is the similarity score (0-1, higher is better)
 	Score float32 `json:"score"`
 }
@@ -35,6 +48,21 @@ type SearchResult struct {
 type Searcher struct {
 	embedProvider embed.Provider
 	vectorIndex   *index.VectorIndex
+	// backend finds nearest neighbours; the vector index itself by default
+	backend index.Backend
+	// store replaces the vector index of a searcher opened with
+	// NewSQLiteSearcher
+	store *index.SQLiteIndex
+
+	// fileIndex is built on the first two-phase search
+	fileIndexOnce sync.Once
+	fileIndex     *index.FileIndex
+
+	// textIndex serves keyword and hybrid search; it is set with
+	// WithTextIndex or built from the vector index on first use
+	textIndexOnce sync.Once
+	textIndex     *index.TextIndex
+	hybrid        *HybridSearcher
 }
 
 // NewSearcher creates a new Searcher with the given embedding provider and vector index
@@ -42,18 +70,49 @@ func NewSearcher(embedProvider embed.Provider, vectorIndex *index.VectorIndex) *
 	return &Searcher{
 		embedProvider: embedProvider,
 		vectorIndex:   vectorIndex,
+		backend:       vectorIndex,
 	}
 }
 
+// NewSQLiteSearcher creates a Searcher over an index saved as a SQLite
+// database, searched in place: vector, two-phase and keyword searches all
+// query the database, which is not loaded into memory
+func NewSQLiteSearcher(embedProvider embed.Provider, store *index.SQLiteIndex) *Searcher {
+	return &Searcher{
+		embedProvider: embedProvider,
+		backend:       store,
+		store:         store,
+	}
+}
+
+// WithTextIndex makes keyword and hybrid search use textIndex, such as the
+// one saved by the semantic builder, instead of building one from the
+// vector index on first use
+func (s *Searcher) WithTextIndex(textIndex *index.TextIndex) *Searcher {
+	if textIndex != nil {
+		s.textIndex = textIndex
+	}
+	return s
+}
+
+// WithBackend makes Search and SearchWithEmbedding use backend, such as an
+// index.HNSW graph built over the searcher's vector index
+func (s *Searcher) WithBackend(backend index.Backend) *Searcher {
+	if backend != nil {
+		s.backend = backend
+	}
+	return s
+}
+
 // EmbedQuery embeds a search query with an instruction prefix for Gemma models
-func (s *Searcher) EmbedQuery(query string) ([]float32, error) {
+func (s *Searcher) EmbedQuery(ctx context.Context, query string) ([]float32, error) {
 	if strings.TrimSpace(query) == "" {
 		return nil, fmt.Errorf("query cannot be empty")
 	}
 
 	prefixedQuery := GemmaQueryPrefix + query
 
-	embeddings, err := s.embedProvider.Embed([]string{prefixedQuery})
+	embeddings, err := s.embedProvider.EmbedContext(ctx, []string{prefixedQuery})
 	if err != nil {
 		return nil, fmt.Errorf("embedding query: %w", err)
 	}
@@ -65,8 +124,47 @@ func (s *Searcher) EmbedQuery(query string) ([]float32, error) {
 	return embeddings[0], nil
 }
 
-// Search performs semantic search and returns top-k results
+// SearchOptions adjusts a single search
+type SearchOptions struct {
+	// Files enables two-phase search over the top Files files (0 = off)
+	Files int
+	// IncludeTests keeps units from test files, which are left out by default
+	IncludeTests bool
+	// ExcludeTerms penalizes units whose file path contains one of the
+	// terms, or whose name, signature or doc comment contains its tokens.
+	// "-term" words in the query are added to it.
+	ExcludeTerms []string
+	// Metadata keeps only units whose enricher metadata has every key. A
+	// non-empty value must also equal the stored value or one of its comma
+	// separated items.
+	Metadata map[string]string
+	// Types keeps only units of these types, such as "function" or "todo".
+	// Todo and commit units are left out unless their type is listed. "type:name" words
+	// in the query are added to it.
+	Types []string
+	// Boosts scales the scores of units by their unit URI, such as the
+	// boosts package feedback derives from judged results. Spare
+	// candidates are fetched so boosted units can climb into the top k.
+	Boosts map[string]float32
+	// Budget bounds the nearest neighbour scan of each query once its
+	// embedding is ready (0 = unbounded). When it runs out, the best units
+	// scored so far are returned and a search_budget degradation is added
+	// to Degradations. Two-phase search (Files) is not bounded.
+	Budget time.Duration
+	// Degradations, when set, collects what the search skipped
+	Degradations *types.Degradations
+}
+
+// Search performs semantic search and returns top-k results, leaving out
+// units from test files
 func (s *Searcher) Search(query string, k int) ([]SearchResult, error) {
+	return s.SearchWithOptions(context.Background(), query, k, SearchOptions{})
+}
+
+// SearchWithOptions performs semantic search with per-query options. The
+// query embedding request is abandoned once ctx is done.
+func (s *Searcher) SearchWithOptions(ctx context.Context, query string, k int, opts SearchOptions) ([]SearchResult, error) {
+	query, opts = withQueryFilters(query, opts)
 	if strings.TrimSpace(query) == "" {
 		return nil, fmt.Errorf("query cannot be empty")
 	}
@@ -74,13 +172,33 @@ func (s *Searcher) Search(query string, k int) ([]SearchResult, error) {
 	if k <= 0 {
 		return nil, fmt.Errorf("k must be positive, got %d", k)
 	}
+	if opts.Files < 0 {
+		return nil, fmt.Errorf("files must be positive, got %d", opts.Files)
+	}
 
-	queryEmbedding, err := s.EmbedQuery(query)
+	queryEmbedding, err := s.EmbedQuery(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 
-	indexResults, err := s.vectorIndex.Search(queryEmbedding, k)
+	search := s.backend.Search
+	if opts.Budget > 0 {
+		search = s.budgetedSearch(time.Now().Add(opts.Budget), opts.Degradations)
+	}
+	if opts.Files > 0 && s.store != nil {
+		search = func(q []float32, n int) ([]index.SearchResult, error) {
+			return s.store.SearchFiles(q, opts.Files, n)
+		}
+	} else if opts.Files > 0 {
+		s.fileIndexOnce.Do(func() {
+			s.fileIndex = index.NewFileIndex(s.vectorIndex, resultFile)
+		})
+		search = func(q []float32, n int) ([]index.SearchResult, error) {
+			return s.fileIndex.Search(q, opts.Files, n)
+		}
+	}
+
+	indexResults, err := s.searchIndex(search, queryEmbedding, k, opts)
 	if err != nil {
 		return nil, fmt.Errorf("searching index: %w", err)
 	}
@@ -93,6 +211,80 @@ func (s *Searcher) Search(query string, k int) ([]SearchResult, error) {
 	return results, nil
 }
 
+// searchIndex runs search for the top-k entries. Unless opts.IncludeTests
+// is set, entries tagged as tests are dropped, as are entries not matching
+// opts.Types or opts.Metadata. With opts.ExcludeTerms or opts.Boosts,
+// spare candidates are fetched and rescored so penalized entries make room
+// for the next best ones and boosted entries can move up.
+func (s *Searcher) searchIndex(search func([]float32, int) ([]index.SearchResult, error), query []float32, k int, opts SearchOptions) ([]index.SearchResult, error) {
+	terms := newExcludeTerms(opts.ExcludeTerms)
+	if len(terms) == 0 && len(opts.Boosts) == 0 {
+		return s.searchCandidates(search, query, k, opts)
+	}
+
+	results, err := s.searchCandidates(search, query, max(k*4, excludeCandidates), opts)
+	if err != nil {
+		return nil, err
+	}
+	applyBoosts(results, opts.Boosts)
+	rescoreExcluded(results, terms)
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
+
+// searchCandidates runs search for the top-k entries. Entries tagged as
+// tests (unless opts.IncludeTests is set) and entries not matching
+// opts.Types or opts.Metadata are dropped, and the search is repeated with
+// a larger k until k others are found or the index is exhausted.
+func (s *Searcher) searchCandidates(search func([]float32, int) ([]index.SearchResult, error), query []float32, k int, opts SearchOptions) ([]index.SearchResult, error) {
+	for n := k; ; n *= 4 {
+		results, err := search(query, n)
+		if err != nil {
+			return nil, err
+		}
+		kept := results[:0]
+		for _, res := range results {
+			if (opts.IncludeTests || !isTestResult(res)) && matchesType(res, opts.Types) && matchesMetadata(res, opts.Metadata) {
+				kept = append(kept, res)
+			}
+		}
+		if len(kept) >= k || len(results) < n || n >= s.backend.Count() {
+			if len(kept) > k {
+				kept = kept[:k]
+			}
+			return kept, nil
+		}
+	}
+}
+
+// isTestResult reports whether an index entry was tagged as a test unit
+func isTestResult(res index.SearchResult) bool {
+	return res.Metadata.Unit != nil && res.Metadata.Unit.IsTest
+}
+
+// matchesMetadata reports whether an index entry's enricher metadata
+// satisfies filter (see SearchOptions.Metadata)
+func matchesMetadata(res index.SearchResult, filter map[string]string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	if res.Metadata.Unit == nil {
+		return false
+	}
+	for key, want := range filter {
+		value, ok := res.Metadata.Unit.Metadata[key]
+		if !ok {
+			return false
+		}
+		if want != "" && value != want && !slices.Contains(strings.Split(value, ","), want) {
+			return false
+		}
+	}
+	return true
+}
+
 // SearchWithEmbedding performs search using a pre-computed query embedding
 // This is useful when the same query embedding is used multiple times
 func (s *Searcher) SearchWithEmbedding(queryEmbedding []float32, k int) ([]SearchResult, error) {
@@ -100,12 +292,12 @@ func (s *Searcher) SearchWithEmbedding(queryEmbedding []float32, k int) ([]Searc
 		return nil, fmt.Errorf("k must be positive, got %d", k)
 	}
 
-	if len(queryEmbedding) != s.vectorIndex.Dimension() {
+	if len(queryEmbedding) != s.backend.Dimension() {
 		return nil, fmt.Errorf("embedding dimension mismatch: expected %d, got %d",
-			s.vectorIndex.Dimension(), len(queryEmbedding))
+			s.backend.Dimension(), len(queryEmbedding))
 	}
 
-	indexResults, err := s.vectorIndex.Search(queryEmbedding, k)
+	indexResults, err := s.searchIndex(s.backend.Search, queryEmbedding, k, SearchOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("searching index: %w", err)
 	}
@@ -118,16 +310,106 @@ func (s *Searcher) SearchWithEmbedding(queryEmbedding []float32, k int) ([]Searc
 	return results, nil
 }
 
+// SearchTwoPhase performs coarse-to-fine search: it ranks files by the mean
+// of their unit vectors, then returns the top-k units from the best files.
+// This scores far fewer vectors than Search on large indexes and keeps
+// results from files that are on topic as a whole.
+//
+// The file-level vectors are computed on the first call, so the index must
+// not change while the Searcher is in use.
+func (s *Searcher) SearchTwoPhase(query string, k, files int) ([]SearchResult, error) {
+	if k <= 0 {
+		return nil, fmt.Errorf("k must be positive, got %d", k)
+	}
+	if files <= 0 {
+		return nil, fmt.Errorf("files must be positive, got %d", files)
+	}
+	return s.SearchWithOptions(context.Background(), query, k, SearchOptions{Files: files})
+}
+
+// Hybrid returns a HybridSearcher over the searcher's index. The keyword
+// index it uses is loaded or built once and shared by every call.
+func (s *Searcher) Hybrid() *HybridSearcher {
+	s.keywordIndex()
+	return s.hybrid
+}
+
+// keywordIndex returns the text index, building it on first use when none
+// was set
+func (s *Searcher) keywordIndex() *index.TextIndex {
+	s.textIndexOnce.Do(func() {
+		if s.textIndex == nil && s.store == nil {
+			s.textIndex = index.NewTextIndexFrom(s.vectorIndex)
+		}
+		s.hybrid = NewHybridSearcher(s)
+	})
+	return s.textIndex
+}
+
+// SearchKeywords ranks units by BM25 over their names, signatures and doc
+// comments only, without embedding the query. Files is ignored; units from
+// test files are left out unless opts.IncludeTests is set.
+func (s *Searcher) SearchKeywords(query string, k int, opts SearchOptions) ([]SearchResult, error) {
+	query, opts = withQueryFilters(query, opts)
+	if strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("query cannot be empty")
+	}
+	if k <= 0 {
+		return nil, fmt.Errorf("k must be positive, got %d", k)
+	}
+
+	textIndex := s.keywordIndex()
+	search := func(_ []float32, n int) ([]index.SearchResult, error) {
+		if s.store != nil {
+			return s.store.SearchText(query, n)
+		}
+		var results []index.SearchResult
+		for _, res := range textIndex.Search(query, n) {
+			_, unit, ok := s.vectorIndex.Get(res.Doc.ID)
+			if !ok {
+				// Tag the entry so test filtering still applies
+				unit = types.EmbeddingUnit{Unit: &types.CodeUnit{
+					ID: res.Doc.ID, Name: res.Doc.Name, FilePath: res.Doc.File, LineNumber: res.Doc.Line, IsTest: res.Doc.IsTest,
+				}}
+			}
+			results = append(results, index.SearchResult{ID: res.Doc.ID, Metadata: unit, Score: res.Score})
+		}
+		return results, nil
+	}
+	indexResults, err := s.searchIndex(search, nil, k, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, len(indexResults))
+	for i, res := range indexResults {
+		results[i] = s.convertResult(res)
+	}
+	return results, nil
+}
+
+// resultFile returns the file an indexed unit belongs to
+func resultFile(unit types.EmbeddingUnit) string {
+	if unit.Unit != nil && unit.Unit.FilePath != "" {
+		return unit.Unit.FilePath
+	}
+	return unit.L1Data.Path
+}
+
 // convertResult converts an index.SearchResult to a SearchResult
 func (s *Searcher) convertResult(res index.SearchResult) SearchResult {
-	parts := strings.SplitN(res.ID, ":", 2)
+	uri := ""
 	filePath := ""
-	name := ""
-	if len(parts) == 2 {
-		filePath = parts[0]
-		name = parts[1]
-	} else {
-		name = res.ID
+	name := res.ID
+	if u, err := types.ParseUnitURI(res.ID); err == nil {
+		if u.Scheme != "" {
+			uri = u.String()
+		}
+		filePath = u.Path
+		name = u.Path
+		if u.Symbol != "" {
+			name = u.Symbol
+		}
 	}
 
 	lineNumber := 0
@@ -135,6 +417,26 @@ func (s *Searcher) convertResult(res index.SearchResult) SearchResult {
 	docstring := ""
 	codeType := "function"
 
+	// Units persisted by the semantic builder carry everything needed
+	if unit := res.Metadata.Unit; unit != nil {
+		if unit.ID != "" {
+			uri = unit.ID
+		}
+		return SearchResult{
+			URI:        uri,
+			FilePath:   unit.FilePath,
+			LineNumber: unit.LineNumber,
+			EndLine:    unit.EndLine,
+			Name:       unit.Name,
+			Signature:  unit.Signature,
+			Docstring:  unit.Docstring,
+			Type:       unit.Type,
+			Language:   unit.Language,
+			Metadata:   unit.Metadata,
+			Score:      res.Score,
+		}
+	}
+
 	if res.Metadata.L1Data.Path != "" {
 		filePath = res.Metadata.L1Data.Path
 	}
@@ -169,12 +471,14 @@ func (s *Searcher) convertResult(res index.SearchResult) SearchResult {
 	}
 
 	return SearchResult{
+		URI:        uri,
 		FilePath:   filePath,
 		LineNumber: lineNumber,
 		Name:       name,
 		Signature:  signature,
 		Docstring:  docstring,
 		Type:       codeType,
+		Language:   res.Metadata.L1Data.Language,
 		Score:      res.Score,
 	}
 }
@@ -198,11 +502,20 @@ func (s *Searcher) SearchWithThreshold(query string, k int, threshold float32) (
 
 // IndexStats returns statistics about the vector index
 func (s *Searcher) IndexStats() (int, int) {
-	return s.vectorIndex.Count(), s.vectorIndex.Dimension()
+	return s.backend.Count(), s.backend.Dimension()
+}
+
+// Generation identifies the contents of the searched vector index; see
+// index.VectorIndex.Generation
+func (s *Searcher) Generation() uint64 {
+	if s.store != nil {
+		return s.store.Generation()
+	}
+	return s.vectorIndex.Generation()
 }
 
 // EmbedTexts embeds multiple texts (for batch processing)
-func (s *Searcher) EmbedTexts(texts []string) ([][]float32, error) {
+func (s *Searcher) EmbedTexts(ctx context.Context, texts []string) ([][]float32, error) {
 	if len(texts) == 0 {
 		return [][]float32{}, nil
 	}
@@ -213,5 +526,5 @@ func (s *Searcher) EmbedTexts(texts []string) ([][]float32, error) {
 		}
 	}
 
-	return s.embedProvider.Embed(texts)
+	return s.embedProvider.EmbedContext(ctx, texts)
 }
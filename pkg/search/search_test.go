@@ -1,9 +1,14 @@
 package search
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"hash/fnv"
+	"path/filepath"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/l3aro/go-context-query/pkg/embed"
 	"github.com/l3aro/go-context-query/pkg/index"
@@ -15,7 +20,7 @@ type mockProvider struct {
 	dimension int
 }
 
-func (m *mockProvider) Embed(texts []string) ([][]float32, error) {
+func (m *mockProvider) EmbedContext(_ context.Context, texts []string) ([][]float32, error) {
 	results := make([][]float32, len(texts))
 	for i, text := range texts {
 		results[i] = generateMockEmbedding(text, m.dimension)
@@ -182,7 +187,7 @@ func TestEmbedQuery(t *testing.T) {
 			idx := index.NewVectorIndex(dimension)
 			searcher := NewSearcher(provider, idx)
 
-			embedding, err := searcher.EmbedQuery(tt.query)
+			embedding, err := searcher.EmbedQuery(context.Background(), tt.query)
 
 			if tt.expectError {
 				if err == nil {
@@ -336,6 +341,111 @@ func TestSearchWithEmbedding(t *testing.T) {
 	}
 }
 
+func TestSearchTwoPhase(t *testing.T) {
+	dimension := 3
+	provider := &mockProvider{dimension: dimension}
+	searcher := NewSearcher(provider, createTestIndex(dimension))
+
+	// Every test file holds one unit, so the file limit caps the results
+	results, err := searcher.SearchTwoPhase("handle request", 10, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("expected 2 results from 2 files, got %d", len(results))
+	}
+
+	// Searching every file matches a full search
+	full, err := searcher.Search("handle request", 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	results, err = searcher.SearchTwoPhase("handle request", 3, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != len(full) {
+		t.Fatalf("expected %d results, got %d", len(full), len(results))
+	}
+	for i := range full {
+		if results[i].FilePath != full[i].FilePath {
+			t.Errorf("result %d: got %s, want %s", i, results[i].FilePath, full[i].FilePath)
+		}
+	}
+
+	if _, err := searcher.SearchTwoPhase("test", 0, 2); err == nil {
+		t.Error("expected error for zero k")
+	}
+	if _, err := searcher.SearchTwoPhase("test", 2, 0); err == nil {
+		t.Error("expected error for zero files")
+	}
+	if _, err := searcher.SearchTwoPhase("", 2, 2); err == nil {
+		t.Error("expected error for empty query")
+	}
+}
+
+func TestSearchWithBackend(t *testing.T) {
+	dimension := 3
+	provider := &mockProvider{dimension: dimension}
+	idx := createTestIndex(dimension)
+	searcher := NewSearcher(provider, idx).WithBackend(index.NewHNSW(idx, index.DefaultHNSWOptions()))
+
+	results, err := searcher.Search("handle request", 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	full, _ := NewSearcher(provider, idx).Search("handle request", 3)
+	if len(results) != len(full) || results[0].FilePath != full[0].FilePath {
+		t.Errorf("HNSW backend returned %+v, want %+v", results, full)
+	}
+}
+
+func TestSQLiteSearcher(t *testing.T) {
+	dimension := 3
+	provider := &mockProvider{dimension: dimension}
+	idx := createTestIndex(dimension)
+	path := filepath.Join(t.TempDir(), "index.db")
+	if err := idx.SaveSQLite(path); err != nil {
+		t.Fatalf("SaveSQLite failed: %v", err)
+	}
+	store, err := index.OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite failed: %v", err)
+	}
+	defer store.Close()
+	searcher := NewSQLiteSearcher(provider, store)
+	inMemory := NewSearcher(provider, idx)
+
+	if count, dim := searcher.IndexStats(); count != 5 || dim != dimension {
+		t.Errorf("IndexStats() = %d, %d, want 5, %d", count, dim, dimension)
+	}
+	for name, search := range map[string]func(*Searcher) ([]SearchResult, error){
+		"vector":    func(s *Searcher) ([]SearchResult, error) { return s.Search("handle request", 3) },
+		"two-phase": func(s *Searcher) ([]SearchResult, error) { return s.SearchTwoPhase("handle request", 3, 2) },
+		"keyword": func(s *Searcher) ([]SearchResult, error) {
+			return s.SearchKeywords("validates token", 3, SearchOptions{})
+		},
+	} {
+		got, err := search(searcher)
+		if err != nil {
+			t.Fatalf("%s search failed: %v", name, err)
+		}
+		want, _ := search(inMemory)
+		if len(got) == 0 || !slices.Equal(resultFiles(got), resultFiles(want)) {
+			t.Errorf("%s search returned %v, want %v", name, resultFiles(got), resultFiles(want))
+		}
+	}
+}
+
+// resultFiles returns the files of results, in order
+func resultFiles(results []SearchResult) []string {
+	var files []string
+	for _, r := range results {
+		files = append(files, r.FilePath)
+	}
+	return files
+}
+
 func TestSearchWithThreshold(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -463,7 +573,7 @@ func TestEmbedTexts(t *testing.T) {
 			idx := index.NewVectorIndex(dimension)
 			searcher := NewSearcher(provider, idx)
 
-			embeddings, err := searcher.EmbedTexts(tt.texts)
+			embeddings, err := searcher.EmbedTexts(context.Background(), tt.texts)
 
 			if tt.expectError {
 				if err == nil {
@@ -518,13 +628,30 @@ func TestSearchResultConversion(t *testing.T) {
 	}
 }
 
+func TestConvertResultUnitURI(t *testing.T) {
+	searcher := NewSearcher(&mockProvider{dimension: 3}, index.NewVectorIndex(3))
+
+	res := searcher.convertResult(index.SearchResult{ID: "go://pkg/index#VectorIndex.Add", Score: 0.5})
+	if res.URI != "go://pkg/index#VectorIndex.Add" {
+		t.Errorf("URI = %q, want go://pkg/index#VectorIndex.Add", res.URI)
+	}
+	if res.Name != "VectorIndex.Add" {
+		t.Errorf("Name = %q, want VectorIndex.Add", res.Name)
+	}
+
+	legacy := searcher.convertResult(index.SearchResult{ID: "src/main.py:run"})
+	if legacy.URI != "" || legacy.FilePath != "src/main.py" || legacy.Name != "run" {
+		t.Errorf("legacy ID parsed as %+v", legacy)
+	}
+}
+
 // mockProviderWithError is a mock provider that returns errors
 type mockProviderWithError struct {
 	mockProvider
 	err error
 }
 
-func (m *mockProviderWithError) Embed(texts []string) ([][]float32, error) {
+func (m *mockProviderWithError) EmbedContext(_ context.Context, texts []string) ([][]float32, error) {
 	return nil, m.err
 }
 
@@ -560,8 +687,165 @@ func TestGemmaQueryPrefix(t *testing.T) {
 
 	// EmbedQuery should add the prefix to the query
 	// We can't directly test the prefix was added, but we can verify it works
-	_, err := searcher.EmbedQuery("test query")
+	_, err := searcher.EmbedQuery(context.Background(), "test query")
 	if err != nil {
 		t.Fatalf("EmbedQuery failed: %v", err)
 	}
 }
+
+func TestConvertResultPersistedUnit(t *testing.T) {
+	searcher := NewSearcher(&mockProvider{dimension: 3}, index.NewVectorIndex(3))
+
+	res := searcher.convertResult(index.SearchResult{
+		ID:    "py://pkg/mod.py#Worker.run",
+		Score: 0.9,
+		Metadata: types.EmbeddingUnit{
+			Unit: &types.CodeUnit{
+				ID:         "py://pkg/mod.py#Worker.run",
+				Name:       "Worker.run",
+				Type:       "method",
+				Language:   "python",
+				FilePath:   "pkg/mod.py",
+				LineNumber: 42,
+				Signature:  "def Worker.run(self)",
+				Docstring:  "Run the worker.",
+			},
+		},
+	})
+
+	if res.Name != "Worker.run" || res.LineNumber != 42 || res.Signature != "def Worker.run(self)" {
+		t.Errorf("unexpected result from persisted unit: %+v", res)
+	}
+	if res.Docstring != "Run the worker." || res.Language != "python" || res.Type != "method" {
+		t.Errorf("unexpected result from persisted unit: %+v", res)
+	}
+}
+
+func TestSearchExcludesTests(t *testing.T) {
+	dimension := 3
+	idx := index.NewVectorIndex(dimension)
+	// Test units sit closest to the query so a plain top-k would return only them
+	for i, name := range []string{"TestA", "TestB", "TestC"} {
+		idx.Add("go://pkg#"+name, []float32{1, float32(i) * 0.01, 0}, types.EmbeddingUnit{
+			Unit: &types.CodeUnit{Name: name, FilePath: "pkg/a_test.go", IsTest: true},
+		})
+	}
+	idx.Add("go://pkg#Handle", []float32{0.5, 0.5, 0}, types.EmbeddingUnit{
+		Unit: &types.CodeUnit{Name: "Handle", FilePath: "pkg/a.go"},
+	})
+
+	searcher := NewSearcher(&mockProvider{dimension: dimension}, idx)
+	query := []float32{1, 0, 0}
+
+	results, err := searcher.SearchWithEmbedding(query, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "Handle" {
+		t.Errorf("expected the non-test unit, got %+v", results)
+	}
+
+	results, err = searcher.SearchWithOptions(context.Background(), "handle", 4, SearchOptions{IncludeTests: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 4 {
+		t.Errorf("expected test units with IncludeTests, got %d results", len(results))
+	}
+
+	results, err = searcher.SearchWithOptions(context.Background(), "handle", 4, SearchOptions{Files: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "Handle" {
+		t.Errorf("expected only the non-test unit from two-phase search, got %+v", results)
+	}
+}
+
+func TestSearchMetadataFilter(t *testing.T) {
+	dimension := 3
+	idx := index.NewVectorIndex(dimension)
+	idx.Add("py://pay.py#charge", []float32{1, 0, 0}, types.EmbeddingUnit{
+		Unit: &types.CodeUnit{Name: "charge", FilePath: "pay.py", Metadata: map[string]string{"ticket": "PAY-12,PAY-40"}},
+	})
+	idx.Add("py://pay.py#refund", []float32{0.9, 0.1, 0}, types.EmbeddingUnit{
+		Unit: &types.CodeUnit{Name: "refund", FilePath: "pay.py", Metadata: map[string]string{"ticket": "PAY-7"}},
+	})
+	idx.Add("py://pay.py#audit", []float32{0.8, 0.2, 0}, types.EmbeddingUnit{
+		Unit: &types.CodeUnit{Name: "audit", FilePath: "pay.py"},
+	})
+	searcher := NewSearcher(&mockProvider{dimension: dimension}, idx)
+
+	tests := []struct {
+		filter map[string]string
+		want   []string
+	}{
+		{nil, []string{"audit", "charge", "refund"}},
+		{map[string]string{"ticket": ""}, []string{"charge", "refund"}},
+		{map[string]string{"ticket": "PAY-40"}, []string{"charge"}},
+		{map[string]string{"ticket": "PAY-4"}, nil},
+		{map[string]string{"flag": ""}, nil},
+	}
+	for _, tt := range tests {
+		results, err := searcher.SearchWithOptions(context.Background(), "payments", 3, SearchOptions{Metadata: tt.filter})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var names []string
+		for _, r := range results {
+			names = append(names, r.Name)
+		}
+		slices.Sort(names)
+		if !slices.Equal(names, tt.want) {
+			t.Errorf("filter %v: got %v, want %v", tt.filter, names, tt.want)
+		}
+	}
+
+	results, _ := searcher.SearchWithOptions(context.Background(), "payments", 1, SearchOptions{Metadata: map[string]string{"ticket": "PAY-7"}})
+	if len(results) != 1 || results[0].Metadata["ticket"] != "PAY-7" {
+		t.Errorf("results = %+v", results)
+	}
+}
+
+func TestSearchBudget(t *testing.T) {
+	dimension := 8
+	idx := index.NewVectorIndex(dimension)
+	for i := 0; i < 1000; i++ {
+		name := fmt.Sprintf("unit%d", i)
+		idx.Add("go://pkg#"+name, generateMockEmbedding(name, dimension), types.EmbeddingUnit{
+			Unit: &types.CodeUnit{Name: name, FilePath: "pkg/a.go"},
+		})
+	}
+	searcher := NewSearcher(&mockProvider{dimension: dimension}, idx)
+	ctx := context.Background()
+
+	var ds types.Degradations
+	results, err := searcher.SearchWithOptions(ctx, "unit", 5, SearchOptions{Budget: time.Nanosecond, Degradations: &ds})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results) != 5 {
+		t.Errorf("expected the best results scanned so far, got %d", len(results))
+	}
+	if len(ds) != 1 || ds[0].Feature != types.DegradedSearchBudget {
+		t.Errorf("expected a search_budget degradation, got %+v", ds)
+	}
+
+	ds = nil
+	results, err = searcher.SearchWithOptions(ctx, "unit", 5, SearchOptions{Budget: time.Minute, Degradations: &ds})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	full, _ := searcher.SearchWithOptions(ctx, "unit", 5, SearchOptions{})
+	if len(ds) != 0 || !slices.Equal(resultNames(results), resultNames(full)) {
+		t.Errorf("budgeted search = %v (%+v), want %v", resultNames(results), ds, resultNames(full))
+	}
+}
+
+func resultNames(results []SearchResult) []string {
+	names := make([]string, len(results))
+	for i, r := range results {
+		names[i] = r.Name
+	}
+	return names
+}
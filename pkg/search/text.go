@@ -4,6 +4,7 @@ package search
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -52,8 +53,16 @@ type TextSearchOptions struct {
 	Excludes []string
 	// CaseSensitive determines if the search is case-sensitive.
 	CaseSensitive bool
+	// MaxFileSize skips files larger than this many bytes.
+	// 0 means no limit.
+	MaxFileSize int64
+	// SkipBinary skips files that look binary (contain a NUL byte near the start).
+	SkipBinary bool
 }
 
+// binarySniffLen is how many leading bytes are checked for NUL when detecting binary files.
+const binarySniffLen = 8000
+
 // TextMatch represents a single regex match in a file.
 type TextMatch struct {
 	// FilePath is the path to the file containing the match.
@@ -64,14 +73,26 @@ type TextMatch struct {
 	LineContent string
 	// Column is the 0-based column offset where the match starts.
 	Column int
+	// EndColumn is the 0-based column offset just past the end of the match.
+	EndColumn int
 	// Match is the matched text.
 	Match string
+	// Highlights are the offsets of every match of the pattern in LineContent,
+	// in order, so callers can highlight them without re-running the regex.
+	Highlights []Highlight
 	// ContextBefore contains lines before the match (if ContextLines > 0).
 	ContextBefore []string
 	// ContextAfter contains lines after the match (if ContextLines > 0).
 	ContextAfter []string
 }
 
+// Highlight is a matched range within a line. Offsets are 0-based byte
+// columns; End is exclusive, so LineContent[Start:End] is the matched text.
+type Highlight struct {
+	Start int
+	End   int
+}
+
 // TextSearcher provides regex-based text search across files.
 type TextSearcher struct {
 	opts   TextSearchOptions
@@ -90,104 +111,123 @@ func NewTextSearcher(opts TextSearchOptions) *TextSearcher {
 	return &TextSearcher{opts: opts, extMap: extMap}
 }
 
+// searchWorkers is the number of files searched concurrently.
+const searchWorkers = 10
+
 // Search performs a regex search for pattern in all files under root.
 // It returns all matches with their locations and optional context.
 func (s *TextSearcher) Search(ctx context.Context, pattern, root string) ([]TextMatch, error) {
+	matches := make([]TextMatch, 0)
+	err := s.SearchStream(ctx, pattern, root, func(batch []TextMatch) error {
+		matches = append(matches, batch...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// SearchStream performs the same search as Search but calls fn with each
+// file's matches as soon as that file has been searched instead of buffering
+// them. Files are searched while the directory tree is still being walked, so
+// the first matches arrive before the walk completes. The search stops once
+// MaxResults matches have been delivered or fn returns an error, which is
+// returned to the caller.
+func (s *TextSearcher) SearchStream(ctx context.Context, pattern, root string, fn func([]TextMatch) error) error {
 	if pattern == "" {
-		return nil, fmt.Errorf("pattern cannot be empty")
+		return fmt.Errorf("pattern cannot be empty")
 	}
 
 	absRoot, err := filepath.Abs(root)
 	if err != nil {
-		return nil, fmt.Errorf("getting absolute path: %w", err)
+		return fmt.Errorf("getting absolute path: %w", err)
 	}
 
 	// Verify root exists
 	info, err := os.Stat(absRoot)
 	if err != nil {
-		return nil, fmt.Errorf("checking root path: %w", err)
+		return fmt.Errorf("checking root path: %w", err)
 	}
 	if !info.IsDir() {
-		return nil, fmt.Errorf("root is not a directory: %s", absRoot)
+		return fmt.Errorf("root is not a directory: %s", absRoot)
 	}
 
 	// Compile regex
-	var regex *regexp.Regexp
 	flags := ""
 	if !s.opts.CaseSensitive {
 		flags = "(?i)"
 	}
-	regex, err = regexp.Compile(flags + pattern)
+	regex, err := regexp.Compile(flags + pattern)
 	if err != nil {
-		return nil, fmt.Errorf("compiling regex: %w", err)
+		return fmt.Errorf("compiling regex: %w", err)
 	}
 
-	// Collect files to search
-	files, err := s.collectFiles(absRoot)
-	if err != nil {
-		return nil, fmt.Errorf("collecting files: %w", err)
-	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Walk the tree and feed files to the workers as they are found
+	files := make(chan string, 64)
+	go func() {
+		defer close(files)
+		s.walkFiles(ctx, absRoot, files)
+	}()
 
 	// Search files concurrently
 	var wg sync.WaitGroup
-	var mu sync.Mutex
-	matches := make([]TextMatch, 0)
-	resultChan := make(chan []TextMatch, len(files))
-
-	// Limit concurrency
-	sem := make(chan struct{}, 10)
-
-	for _, file := range files {
+	resultChan := make(chan []TextMatch)
+	for i := 0; i < searchWorkers; i++ {
 		wg.Add(1)
-		go func(file string) {
-			sem <- struct{}{}
+		go func() {
 			defer wg.Done()
-			defer func() { <-sem }()
-
-			select {
-			case <-ctx.Done():
-				return
-			default:
-			}
-
-			fileMatches, err := s.searchFile(ctx, absRoot, file, regex)
-			if err != nil {
-				// Skip file on error, log if needed
-				return
-			}
-			if len(fileMatches) > 0 {
-				resultChan <- fileMatches
+			for file := range files {
+				fileMatches, err := s.searchFile(ctx, absRoot, file, regex)
+				if err != nil || len(fileMatches) == 0 {
+					// Skip file on error, log if needed
+					continue
+				}
+				select {
+				case resultChan <- fileMatches:
+				case <-ctx.Done():
+					return
+				}
 			}
-		}(file)
+		}()
 	}
 
-	// Close channel when all goroutines complete
+	// Close channel when all workers complete
 	go func() {
 		wg.Wait()
 		close(resultChan)
 	}()
 
-	// Collect results
+	// Deliver results, stopping early at the limit or on callback error
+	var fnErr error
+	delivered := 0
 	for fileMatches := range resultChan {
-		mu.Lock()
-		for _, m := range fileMatches {
-			if s.opts.MaxResults > 0 && len(matches) >= s.opts.MaxResults {
-				mu.Unlock()
-				return matches, nil
-			}
-			matches = append(matches, m)
+		if fnErr != nil || (s.opts.MaxResults > 0 && delivered >= s.opts.MaxResults) {
+			continue
+		}
+		if s.opts.MaxResults > 0 && delivered+len(fileMatches) > s.opts.MaxResults {
+			fileMatches = fileMatches[:s.opts.MaxResults-delivered]
+		}
+		delivered += len(fileMatches)
+		if err := fn(fileMatches); err != nil {
+			fnErr = err
+			cancel()
+		}
+		if s.opts.MaxResults > 0 && delivered >= s.opts.MaxResults {
+			cancel()
 		}
-		mu.Unlock()
 	}
 
-	return matches, nil
+	return fnErr
 }
 
-// collectFiles walks the directory tree and returns files matching the options.
-func (s *TextSearcher) collectFiles(root string) ([]string, error) {
-	var files []string
-
-	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+// walkFiles walks the directory tree and sends files matching the options to
+// files until the walk completes or ctx is cancelled.
+func (s *TextSearcher) walkFiles(ctx context.Context, root string, files chan<- string) {
+	filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			return nil
 		}
@@ -220,15 +260,21 @@ func (s *TextSearcher) collectFiles(root string) ([]string, error) {
 			}
 		}
 
-		files = append(files, path)
-		return nil
-	})
-
-	if err != nil {
-		return nil, err
-	}
+		// Check size limit
+		if s.opts.MaxFileSize > 0 {
+			info, err := d.Info()
+			if err != nil || info.Size() > s.opts.MaxFileSize {
+				return nil
+			}
+		}
 
-	return files, nil
+		select {
+		case files <- path:
+			return nil
+		case <-ctx.Done():
+			return filepath.SkipAll
+		}
+	})
 }
 
 // isExcluded checks if a directory name should be excluded.
@@ -249,8 +295,16 @@ func (s *TextSearcher) searchFile(ctx context.Context, root, filePath string, re
 	}
 	defer file.Close()
 
+	reader := bufio.NewReaderSize(file, binarySniffLen)
+	if s.opts.SkipBinary {
+		head, _ := reader.Peek(binarySniffLen)
+		if bytes.IndexByte(head, 0) >= 0 {
+			return nil, nil
+		}
+	}
+
 	var lines []string
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
 		lines = append(lines, scanner.Text())
 	}
@@ -268,17 +322,23 @@ func (s *TextSearcher) searchFile(ctx context.Context, root, filePath string, re
 		default:
 		}
 
-		loc := regex.FindStringIndex(line)
-		if loc == nil {
+		locs := regex.FindAllStringIndex(line, -1)
+		if locs == nil {
 			continue
 		}
 
+		loc := locs[0]
 		match := TextMatch{
 			FilePath:    filePath,
 			LineNumber:  i + 1, // 1-based
 			LineContent: line,
 			Column:      loc[0],
+			EndColumn:   loc[1],
 			Match:       line[loc[0]:loc[1]],
+			Highlights:  make([]Highlight, 0, len(locs)),
+		}
+		for _, l := range locs {
+			match.Highlights = append(match.Highlights, Highlight{Start: l[0], End: l[1]})
 		}
 
 		// Add context lines
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -238,6 +239,98 @@ func test5() {}
 	}
 }
 
+func TestTextSearcher_SearchStream(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	for i := 0; i < 5; i++ {
+		content := fmt.Sprintf("package main\n// needle %d\n// needle again\n", i)
+		if err := os.WriteFile(filepath.Join(tmpDir, fmt.Sprintf("file%d.go", i)), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	var batches, total int
+	err := NewTextSearcher(TextSearchOptions{MaxResults: 7}).SearchStream(context.Background(), "needle", tmpDir, func(batch []TextMatch) error {
+		batches++
+		total += len(batch)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SearchStream failed: %v", err)
+	}
+	if total != 7 {
+		t.Errorf("expected 7 streamed matches (max), got %d", total)
+	}
+	if batches < 2 {
+		t.Errorf("expected matches to arrive in per-file batches, got %d batch(es)", batches)
+	}
+
+	stop := fmt.Errorf("stop")
+	calls := 0
+	err = NewTextSearcher(TextSearchOptions{}).SearchStream(context.Background(), "needle", tmpDir, func(batch []TextMatch) error {
+		calls++
+		return stop
+	})
+	if err != stop {
+		t.Errorf("expected callback error to be returned, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected no callbacks after an error, got %d", calls)
+	}
+}
+
+func TestTextSearcher_Search_MaxFileSize(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "small.go"), []byte("var needle = 1\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	large := "var needle = 2\n" + strings.Repeat("x", 1024) + "\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "large.go"), []byte(large), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	searcher := NewTextSearcher(TextSearchOptions{
+		MaxFileSize: 512,
+	})
+
+	matches, err := searcher.Search(context.Background(), "needle", tmpDir)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if len(matches) != 1 || filepath.Base(matches[0].FilePath) != "small.go" {
+		t.Errorf("expected only small.go to match, got %+v", matches)
+	}
+}
+
+func TestTextSearcher_Search_SkipBinary(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "text.txt"), []byte("needle\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "blob.bin"), []byte("needle\x00\x01\x02\n"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	matches, err := NewTextSearcher(TextSearchOptions{SkipBinary: true}).Search(context.Background(), "needle", tmpDir)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(matches) != 1 || filepath.Base(matches[0].FilePath) != "text.txt" {
+		t.Errorf("expected binary file to be skipped, got %+v", matches)
+	}
+
+	matches, err = NewTextSearcher(TextSearchOptions{}).Search(context.Background(), "needle", tmpDir)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Errorf("expected binary file to be searched without SkipBinary, got %d matches", len(matches))
+	}
+}
+
 func TestTextSearcher_Search_ExcludesDirectories(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -451,3 +544,36 @@ var helloWorld = "test"
 		t.Errorf("expected column 4, got %d", m.Column)
 	}
 }
+
+func TestTextMatch_Highlights(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	testFile := filepath.Join(tmpDir, "test.go")
+	err := os.WriteFile(testFile, []byte(`package main
+var err = fmt.Errorf("err: %w", err)
+`), 0644)
+	if err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	matches, err := NewTextSearcher(TextSearchOptions{CaseSensitive: true}).Search(context.Background(), `err\b`, tmpDir)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 matching line, got %d", len(matches))
+	}
+
+	m := matches[0]
+	if m.Column != 4 || m.EndColumn != 7 {
+		t.Errorf("expected first match at [4,7), got [%d,%d)", m.Column, m.EndColumn)
+	}
+	if len(m.Highlights) != 3 {
+		t.Fatalf("expected 3 highlights, got %+v", m.Highlights)
+	}
+	for _, h := range m.Highlights {
+		if got := m.LineContent[h.Start:h.End]; got != "err" {
+			t.Errorf("highlight [%d,%d) = %q, want %q", h.Start, h.End, got, "err")
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package semantic
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/fixture"
+)
+
+func TestBuildFixture(t *testing.T) {
+	root := t.TempDir()
+	manifest, err := fixture.Generate(root, fixture.Options{Files: 30, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := BuildIndexWithStats(root, &mockProvider{}, BuildOptions{}); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	units, err := LoadUnits(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(units) != manifest.Units {
+		t.Errorf("indexed %d units, manifest lists %d", len(units), manifest.Units)
+	}
+
+	ids := make(map[string]bool)
+	var crossFile int
+	for _, u := range units {
+		ids[u.ID] = true
+		for _, call := range u.Calls {
+			if file, _, _ := strings.Cut(call, ":"); file != u.FilePath {
+				crossFile++
+			}
+		}
+	}
+	for _, q := range manifest.Queries {
+		if !ids[q.Unit] {
+			t.Errorf("query %q names %s, which is not indexed", q.Query, q.Unit)
+		}
+	}
+	if crossFile == 0 {
+		t.Error("no cross-file calls resolved in the fixture")
+	}
+}
+
+func BenchmarkBuildIndex(b *testing.B) {
+	for _, files := range []int{100, 500} {
+		b.Run(fmt.Sprintf("files=%d", files), func(b *testing.B) {
+			root := b.TempDir()
+			if _, err := fixture.Generate(root, fixture.Options{Files: files, Seed: 1}); err != nil {
+				b.Fatal(err)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := BuildIndexWithStats(root, &mockProvider{}, BuildOptions{Full: true}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
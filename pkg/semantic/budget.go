@@ -0,0 +1,88 @@
+package semantic
+
+import (
+	"sort"
+	"time"
+
+	"github.com/l3aro/go-context-query/internal/scanner"
+)
+
+// budgetBatchSize is how many units a budgeted build embeds per provider
+// request; the budget is checked between requests
+const budgetBatchSize = 64
+
+// WithBudget limits Build to about budget: scanning and extraction always
+// run to completion, then units are embedded in priority order until the
+// budget is spent, and the rest are left out of the index. A zero budget
+// embeds every unit.
+func (b *Builder) WithBudget(budget time.Duration) *Builder {
+	b.budget = budget
+	return b
+}
+
+// Remaining returns the number of units Build left out of the index because
+// its budget ran out
+func (b *Builder) Remaining() int {
+	return b.remaining
+}
+
+// embedWithinBudget embeds units, highest priority first, a batch at a time
+// until deadline passes, and returns the units embedded with their
+// embeddings. The first batch is always embedded, so a budgeted build still
+// gives a searchable index.
+func (b *Builder) embedWithinBudget(units []*CodeUnit, files []scanner.FileInfo, deadline time.Time) ([]*CodeUnit, [][]float32, error) {
+	ordered := prioritizeUnits(units, files)
+
+	var embeddings [][]float32
+	done := 0
+	for done < len(ordered) {
+		if done > 0 && time.Now().After(deadline) {
+			break
+		}
+		end := min(done+budgetBatchSize, len(ordered))
+		batch, err := b.Embed(ordered[done:end])
+		if err != nil {
+			return nil, nil, err
+		}
+		embeddings = append(embeddings, batch...)
+		done = end
+	}
+
+	b.remaining = len(ordered) - done
+	b.codeUnits = ordered[:done]
+	return ordered[:done], embeddings, nil
+}
+
+// prioritizeUnits returns units in the order a budgeted build embeds them:
+// by the order scanner.Prioritize gives their files, and within a file the
+// units with the most callers first. Body chunks and todo units come after
+// every other unit, since their functions are already found through their
+// signatures.
+func prioritizeUnits(units []*CodeUnit, files []scanner.FileInfo) []*CodeUnit {
+	files = append([]scanner.FileInfo(nil), files...)
+	scanner.Prioritize(files)
+	rank := make(map[string]int, len(files))
+	for i, f := range files {
+		rank[f.Path] = i
+	}
+	fileRank := func(u *CodeUnit) int {
+		if r, ok := rank[u.FilePath]; ok {
+			return r
+		}
+		// Imported units and files outside the scan go last
+		return len(files)
+	}
+
+	ordered := append([]*CodeUnit(nil), units...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		a, b := ordered[i], ordered[j]
+		if derivedA, derivedB := derivedUnit(a.Type), derivedUnit(b.Type); derivedA != derivedB {
+			return !derivedA
+		}
+		if ra, rb := fileRank(a), fileRank(b); ra != rb {
+			return ra < rb
+		}
+		return len(a.CalledBy) > len(b.CalledBy)
+	})
+	return ordered
+}
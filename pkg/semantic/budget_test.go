@@ -0,0 +1,83 @@
+package semantic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/l3aro/go-context-query/internal/scanner"
+)
+
+func TestPrioritizeUnits(t *testing.T) {
+	files := []scanner.FileInfo{
+		{Path: "tests/test_app.py", Language: "python"},
+		{Path: "app.py", Language: "python"},
+	}
+	units := []*CodeUnit{
+		{Name: "test_run", FilePath: "tests/test_app.py"},
+		{Name: "helper", FilePath: "app.py"},
+		{Name: "run#chunk1", Type: UnitTypeChunk, FilePath: "app.py"},
+		{Name: "run", FilePath: "app.py", CalledBy: []string{"a", "b"}},
+		{Name: "imported", FilePath: "vendor/lib.c"},
+	}
+
+	var got []string
+	for _, u := range prioritizeUnits(units, files) {
+		got = append(got, u.Name)
+	}
+	want := "run,helper,test_run,imported,run#chunk1"
+	if strings.Join(got, ",") != want {
+		t.Errorf("prioritizeUnits = %v, want %s", got, want)
+	}
+}
+
+func TestBuildWithBudget(t *testing.T) {
+	tmpDir := t.TempDir()
+	var src strings.Builder
+	for i := range budgetBatchSize + 10 {
+		fmt.Fprintf(&src, "def f%d():\n    return %d\n\n\n", i, i)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "many.py"), []byte(src.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var requests int
+	provider := &mockProvider{embedFn: func(texts []string) ([][]float32, error) {
+		requests++
+		embeddings := make([][]float32, len(texts))
+		for i := range texts {
+			embeddings[i] = []float32{1, 2, 3}
+		}
+		return embeddings, nil
+	}}
+	builder, err := NewBuilder(tmpDir, provider)
+	if err != nil {
+		t.Fatalf("NewBuilder failed: %v", err)
+	}
+
+	// A budget that has run out before embedding still embeds one batch
+	builder.WithBudget(time.Nanosecond)
+	vecIndex, metadata, err := builder.Build()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if vecIndex.Count() != budgetBatchSize || metadata.Count != budgetBatchSize || requests != 1 {
+		t.Errorf("indexed %d units (metadata %d) in %d requests, want %d in 1", vecIndex.Count(), metadata.Count, requests, budgetBatchSize)
+	}
+	if builder.Remaining() != 10 {
+		t.Errorf("Remaining = %d, want 10", builder.Remaining())
+	}
+	if err := builder.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	_, saved, err := LoadIndex(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadIndex failed: %v", err)
+	}
+	if saved.Remaining != 10 {
+		t.Errorf("saved metadata Remaining = %d, want 10", saved.Remaining)
+	}
+}
@@ -0,0 +1,209 @@
+package semantic
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// CallerGraph answers "who calls X" over the call graph persisted in a
+// semantic index. It is built from each unit's Calls ("file:func" keys), so
+// callers are linked to the exact units they call.
+type CallerGraph struct {
+	units   []*CodeUnit
+	callers map[string][]*CodeUnit // unit ID -> units that call it
+	callees map[string][]*CodeUnit // unit ID -> units it calls
+	// ids resolves the URIs of units that moved or were renamed
+	ids *UnitIDMap
+	// metadata describes the index the graph was loaded from, if any
+	metadata *IndexMetadata
+}
+
+// Caller is a unit that calls the query target, directly (Depth 1) or
+// through other callers
+type Caller struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+	// Depth is 1 for direct callers, 2 for their callers, and so on
+	Depth int `json:"depth"`
+	// Calls is the unit this caller calls on its path to the target
+	Calls string `json:"calls"`
+}
+
+// NewCallerGraph builds the reverse call graph of units
+func NewCallerGraph(units []*CodeUnit) *CallerGraph {
+	byFile := make(map[string][]*CodeUnit)
+	for _, unit := range units {
+		byFile[unit.FilePath] = append(byFile[unit.FilePath], unit)
+	}
+
+	g := &CallerGraph{units: units, callers: make(map[string][]*CodeUnit), callees: make(map[string][]*CodeUnit)}
+	for _, caller := range units {
+		seen := make(map[string]bool)
+		for _, key := range caller.Calls {
+			file, name, ok := strings.Cut(key, ":")
+			if !ok {
+				continue
+			}
+			for _, callee := range byFile[file] {
+				if seen[callee.ID] || callee.ID == caller.ID || !unitNameMatches(callee.Name, name) {
+					continue
+				}
+				seen[callee.ID] = true
+				g.callers[callee.ID] = append(g.callers[callee.ID], caller)
+				g.callees[caller.ID] = append(g.callees[caller.ID], callee)
+			}
+		}
+	}
+	return g
+}
+
+// LoadCallerGraph builds the caller graph of rootDir's semantic index. Its
+// Find also resolves the old URIs of units that moved or were renamed.
+func LoadCallerGraph(rootDir string) (*CallerGraph, error) {
+	units, metadata, err := loadUnits(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	g := NewCallerGraph(units)
+	g.metadata = metadata
+	if ids, err := LoadUnitIDMap(rootDir); err == nil {
+		g.ids = ids
+	}
+	return g, nil
+}
+
+// Degradations returns the degradations of an answer naming targets and
+// callers: those recorded when the index was built, and a stale call graph
+// when their files changed since
+func (g *CallerGraph) Degradations(rootDir string, targets []*CodeUnit, callers []Caller) types.Degradations {
+	files := make([]string, 0, len(targets)+len(callers))
+	for _, t := range targets {
+		files = append(files, t.FilePath)
+	}
+	for _, c := range callers {
+		files = append(files, c.File)
+	}
+	return g.metadata.ResultDegradations(rootDir, files, types.DegradedCallGraph)
+}
+
+// unitNameMatches reports whether a unit named unitName ("parse",
+// "Parser.parse") is the one a call graph key names. Python and TypeScript
+// keys name methods by their simple name, Go keys by Type.Method.
+func unitNameMatches(unitName, name string) bool {
+	return unitName == name || strings.HasSuffix(unitName, "."+name)
+}
+
+// Find returns the function, method and class units matching target: a
+// unit URI, a stable ID, a name ("parse"), or a qualified name
+// ("Parser.parse"). A non-empty file keeps units whose path is file or ends
+// with it. The URI of a unit that has since moved or been renamed finds the
+// unit at its new URI.
+func (g *CallerGraph) Find(target, file string) []*CodeUnit {
+	file = filepath.ToSlash(file)
+	var matches []*CodeUnit
+	for _, unit := range g.units {
+		if types.IsUnitURI(target) || IsStableID(target) {
+			if unit.ID == target || unit.StableID == target {
+				matches = append(matches, unit)
+			}
+			continue
+		}
+		if !unitNameMatches(unit.Name, target) {
+			continue
+		}
+		if file != "" {
+			path := filepath.ToSlash(unit.FilePath)
+			if path != file && !strings.HasSuffix(path, "/"+file) {
+				continue
+			}
+		}
+		matches = append(matches, unit)
+	}
+	if len(matches) == 0 && types.IsUnitURI(target) {
+		if current, ok := g.ids.Resolve(target); ok && current != target {
+			return g.Find(current, file)
+		}
+	}
+	return matches
+}
+
+// Callers returns the units calling targets, following callers of callers
+// up to depth levels. Each unit is reported once, at its shortest distance;
+// results are ordered by depth, then file and line.
+func (g *CallerGraph) Callers(targets []*CodeUnit, depth int) []Caller {
+	if depth <= 0 {
+		depth = 1
+	}
+
+	visited := make(map[string]bool, len(targets))
+	for _, t := range targets {
+		visited[t.ID] = true
+	}
+
+	var result []Caller
+	frontier := targets
+	for level := 1; level <= depth && len(frontier) > 0; level++ {
+		var next []*CodeUnit
+		for _, callee := range frontier {
+			for _, caller := range g.callers[callee.ID] {
+				if visited[caller.ID] {
+					continue
+				}
+				visited[caller.ID] = true
+				result = append(result, Caller{
+					ID:    caller.ID,
+					Name:  caller.Name,
+					Type:  caller.Type,
+					File:  caller.FilePath,
+					Line:  caller.LineNumber,
+					Depth: level,
+					Calls: callee.Name,
+				})
+				next = append(next, caller)
+			}
+		}
+		frontier = next
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Depth != b.Depth {
+			return a.Depth < b.Depth
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return result
+}
+
+// Units returns the units of the graph
+func (g *CallerGraph) Units() []*CodeUnit {
+	return g.units
+}
+
+// Callees returns the units unit calls directly, in call order
+func (g *CallerGraph) Callees(unit *CodeUnit) []*CodeUnit {
+	return g.callees[unit.ID]
+}
+
+// CallersOf finds target (see Find) and returns its callers up to depth.
+// It fails when no unit matches target.
+func (g *CallerGraph) CallersOf(target, file string, depth int) ([]*CodeUnit, []Caller, error) {
+	targets := g.Find(target, file)
+	if len(targets) == 0 {
+		if file != "" {
+			return nil, nil, fmt.Errorf("no function named %s in %s in the semantic index", target, file)
+		}
+		return nil, nil, fmt.Errorf("no function named %s in the semantic index", target)
+	}
+	return targets, g.Callers(targets, depth), nil
+}
@@ -0,0 +1,91 @@
+package semantic
+
+import (
+	"testing"
+)
+
+func callerTestUnits() []*CodeUnit {
+	return []*CodeUnit{
+		{ID: "py://app.py#main", Name: "main", Type: "function", FilePath: "app.py", LineNumber: 1,
+			Calls: []string{"app.py:serve", "db.py:connect"}},
+		{ID: "py://app.py#serve", Name: "serve", Type: "function", FilePath: "app.py", LineNumber: 10,
+			Calls: []string{"app.py:handle"}},
+		{ID: "py://app.py#handle", Name: "handle", Type: "function", FilePath: "app.py", LineNumber: 20,
+			Calls: []string{"db.py:query", "app.py:handle"}},
+		{ID: "py://db.py#connect", Name: "connect", Type: "function", FilePath: "db.py", LineNumber: 1},
+		{ID: "py://db.py#Store.query", Name: "Store.query", Type: "method", FilePath: "db.py", LineNumber: 5,
+			Calls: []string{"db.py:connect"}},
+		{ID: "py://cache.py#query", Name: "query", Type: "function", FilePath: "cache.py", LineNumber: 3},
+	}
+}
+
+func TestCallerGraphFind(t *testing.T) {
+	g := NewCallerGraph(callerTestUnits())
+
+	if got := g.Find("query", ""); len(got) != 2 {
+		t.Errorf("Find(query) = %d units, want 2", len(got))
+	}
+	if got := g.Find("query", "db.py"); len(got) != 1 || got[0].Name != "Store.query" {
+		t.Errorf("Find(query, db.py) = %+v", got)
+	}
+	if got := g.Find("Store.query", ""); len(got) != 1 {
+		t.Errorf("Find(Store.query) = %d units, want 1", len(got))
+	}
+	if got := g.Find("py://app.py#serve", ""); len(got) != 1 || got[0].Name != "serve" {
+		t.Errorf("Find(uri) = %+v", got)
+	}
+}
+
+func TestCallerGraphCallers(t *testing.T) {
+	g := NewCallerGraph(callerTestUnits())
+
+	_, direct, err := g.CallersOf("Store.query", "", 1)
+	if err != nil {
+		t.Fatalf("CallersOf failed: %v", err)
+	}
+	if len(direct) != 1 || direct[0].Name != "handle" || direct[0].Depth != 1 || direct[0].Calls != "Store.query" {
+		t.Errorf("direct callers = %+v", direct)
+	}
+
+	_, transitive, _ := g.CallersOf("Store.query", "", 5)
+	var names []string
+	for _, c := range transitive {
+		names = append(names, c.Name)
+	}
+	want := []string{"handle", "serve", "main"}
+	if len(names) != len(want) {
+		t.Fatalf("transitive callers = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] || transitive[i].Depth != i+1 {
+			t.Errorf("caller %d = %s at depth %d, want %s at depth %d", i, names[i], transitive[i].Depth, want[i], i+1)
+		}
+	}
+
+	// connect is called by main directly and by Store.query; each is reported once
+	_, callers, _ := g.CallersOf("connect", "", 3)
+	seen := make(map[string]int)
+	for _, c := range callers {
+		seen[c.Name]++
+	}
+	if seen["main"] != 1 || seen["Store.query"] != 1 || callers[0].Depth != 1 {
+		t.Errorf("callers of connect = %+v", callers)
+	}
+
+	if _, _, err := g.CallersOf("missing", "", 1); err == nil {
+		t.Error("expected an error for an unknown function")
+	}
+}
+
+func TestCallerGraphCallees(t *testing.T) {
+	g := NewCallerGraph(callerTestUnits())
+
+	handle := g.Find("handle", "")[0]
+	callees := g.Callees(handle)
+	if len(callees) != 1 || callees[0].Name != "Store.query" {
+		t.Errorf("callees of handle = %+v", callees)
+	}
+	if got := g.Callees(g.Find("connect", "")[0]); len(got) != 0 {
+		t.Errorf("callees of connect = %+v", got)
+	}
+}
@@ -0,0 +1,200 @@
+package semantic
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+
+	"github.com/l3aro/go-context-query/pkg/extractor"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// UnitTypeChunk is the type of units holding part of a function body
+const UnitTypeChunk = "chunk"
+
+// charsPerToken estimates token counts from text length
+const charsPerToken = 4
+
+// ChunkOptions controls how long function and method bodies are split into
+// chunk units, each indexed with its line range so a search can land on
+// the relevant part of a long function. Sizes are in tokens, estimated at
+// four characters each.
+type ChunkOptions struct {
+	// Size is the largest chunk; bodies no longer than this are not split.
+	// Zero disables chunking.
+	Size int
+	// Overlap is how much of a chunk is repeated at the start of the next
+	Overlap int
+}
+
+// WithChunkOptions enables chunking of long function bodies
+func (b *Builder) WithChunkOptions(opts ChunkOptions) *Builder {
+	b.chunks = opts
+	return b
+}
+
+// bodyChunk is a run of lines of a body, [start, end] counted from 0
+type bodyChunk struct {
+	start, end int
+}
+
+// splitBody splits lines into chunks of at most size tokens that share
+// about overlap tokens with the previous chunk. A line longer than size is
+// a chunk of its own. It returns nil when the body fits in one chunk.
+func splitBody(lines []string, size, overlap int) []bodyChunk {
+	maxChars, overlapChars := size*charsPerToken, overlap*charsPerToken
+	total := 0
+	for _, line := range lines {
+		total += len(line) + 1
+	}
+	if size <= 0 || total <= maxChars {
+		return nil
+	}
+
+	var chunks []bodyChunk
+	for start := 0; start < len(lines); {
+		end, chars := start, len(lines[start])+1
+		for end+1 < len(lines) && chars+len(lines[end+1])+1 <= maxChars {
+			end++
+			chars += len(lines[end]) + 1
+		}
+		chunks = append(chunks, bodyChunk{start, end})
+		if end == len(lines)-1 {
+			break
+		}
+
+		// Start the next chunk far enough back to repeat overlap tokens,
+		// but always move forward
+		next, shared := end+1, 0
+		for next-1 > start && shared < overlapChars {
+			next--
+			shared += len(lines[next]) + 1
+		}
+		start = next
+	}
+	return chunks
+}
+
+// annotateFile reads a file once for the passes that need unit bodies: it
+// sets the end line of the function and method units of the file, their
+// stable IDs when enabled, and runs the enrichers. It returns chunk units
+// for bodies longer than b.chunks.Size and, when enabled, todo units for
+// the file's TODO comments.
+func (b *Builder) annotateFile(filePath, relPath, lang string, units []*CodeUnit) []*CodeUnit {
+	enrichers := b.activeEnrichers()
+	bodies := len(units) > 0 && (b.chunks.Size > 0 || b.stableIDs || len(enrichers) > 0)
+	if !bodies && !b.todos {
+		return nil
+	}
+	source, err := os.ReadFile(filePath)
+	if err != nil {
+		b.enrichFile(enrichers, units, nil)
+		return nil
+	}
+	lines := strings.Split(string(source), "\n")
+	extents := bodyExtents(b.extractor, filePath, source)
+	for _, unit := range units {
+		if unit.Type != "function" && unit.Type != "method" {
+			continue
+		}
+		if end, ok := extents[unit.LineNumber]; ok && end <= len(lines) {
+			unit.EndLine = end
+		}
+	}
+
+	if b.stableIDs {
+		for _, unit := range units {
+			setStableID(unit, lines)
+		}
+	}
+	var derived []*CodeUnit
+	if b.chunks.Size > 0 {
+		derived = chunkUnits(units, lines, b.chunks)
+	}
+	if b.todos {
+		derived = append(derived, todoUnits(fileTodos(b.extractor, filePath, relPath, source), lang, units)...)
+	}
+	b.enrichFile(enrichers, units, lines)
+	b.enrichFile(enrichers, derived, lines)
+	return derived
+}
+
+// chunkUnits returns chunk units for the function and method units whose
+// bodies are longer than opts.Size
+func chunkUnits(units []*CodeUnit, lines []string, opts ChunkOptions) []*CodeUnit {
+	var chunks []*CodeUnit
+	for _, unit := range units {
+		if (unit.Type != "function" && unit.Type != "method") || unit.EndLine == 0 {
+			continue
+		}
+
+		body := lines[unit.LineNumber-1 : unit.EndLine]
+		for _, c := range splitBody(body, opts.Size, opts.Overlap) {
+			start, end := unit.LineNumber+c.start, unit.LineNumber+c.end
+			chunks = append(chunks, &CodeUnit{
+				ID:         types.NewUnitURI(unit.Language, unit.FilePath, fmt.Sprintf("%s@%d-%d", unit.Name, start, end)).String(),
+				Language:   unit.Language,
+				Name:       unit.Name,
+				Type:       UnitTypeChunk,
+				FilePath:   unit.FilePath,
+				LineNumber: start,
+				EndLine:    end,
+				Signature:  unit.Signature,
+				Parent:     unit.ID,
+				Code:       strings.Join(body[c.start:c.end+1], "\n"),
+			})
+		}
+	}
+	return chunks
+}
+
+// BodyExtents parses a source file and returns the last line of each
+// function or method, keyed by the line it starts on, both counted from 1.
+// It returns nil for files no parser supports.
+func BodyExtents(filePath string, source []byte) map[int]int {
+	return bodyExtents(extractor.GetLanguageRegistry(), filePath, source)
+}
+
+func bodyExtents(registry *extractor.LanguageRegistry, filePath string, source []byte) map[int]int {
+	parser, err := registry.GetParser(filePath)
+	if err != nil {
+		return nil
+	}
+	source = extractor.ScriptSource(filePath, source)
+	tree := parser.Parse(nil, source)
+	if tree == nil {
+		return nil
+	}
+	defer tree.Close()
+
+	extents := make(map[int]int)
+	var walk func(n *sitter.Node)
+	walk = func(n *sitter.Node) {
+		if isFunctionNode(n.Type()) {
+			start, end := int(n.StartPoint().Row)+1, int(n.EndPoint().Row)+1
+			if end > extents[start] {
+				extents[start] = end
+			}
+		}
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			walk(n.NamedChild(i))
+		}
+	}
+	walk(tree.RootNode())
+	return extents
+}
+
+// isFunctionNode reports whether a tree-sitter node type is a function or
+// method definition in any of the supported grammars
+func isFunctionNode(nodeType string) bool {
+	switch nodeType {
+	case "method", "singleton_method", "arrow_function", "function_expression":
+		return true
+	}
+	if !strings.Contains(nodeType, "function") && !strings.Contains(nodeType, "method") && !strings.Contains(nodeType, "constructor") {
+		return false
+	}
+	return strings.HasSuffix(nodeType, "_definition") || strings.HasSuffix(nodeType, "_declaration") || strings.HasSuffix(nodeType, "_item")
+}
@@ -0,0 +1,113 @@
+package semantic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitBody(t *testing.T) {
+	// Ten lines of 19 characters, 20 with the newline: 5 tokens each
+	var lines []string
+	for i := 0; i < 10; i++ {
+		lines = append(lines, fmt.Sprintf("    value_%02d = %04d", i, i))
+	}
+
+	if chunks := splitBody(lines, 50, 10); chunks != nil {
+		t.Errorf("body within size split into %v", chunks)
+	}
+	if chunks := splitBody(lines, 0, 0); chunks != nil {
+		t.Errorf("size 0 split into %v", chunks)
+	}
+
+	chunks := splitBody(lines, 20, 5)
+	want := []bodyChunk{{0, 3}, {3, 6}, {6, 9}}
+	if fmt.Sprint(chunks) != fmt.Sprint(want) {
+		t.Errorf("chunks = %v, want %v", chunks, want)
+	}
+
+	// A line longer than the chunk size is a chunk of its own
+	long := []string{"a", strings.Repeat("x", 200), "b"}
+	chunks = splitBody(long, 10, 0)
+	want = []bodyChunk{{0, 0}, {1, 1}, {2, 2}}
+	if fmt.Sprint(chunks) != fmt.Sprint(want) {
+		t.Errorf("chunks = %v, want %v", chunks, want)
+	}
+}
+
+func TestBuilderExtractChunks(t *testing.T) {
+	tmpDir := t.TempDir()
+	var src strings.Builder
+	src.WriteString("def short():\n    return 1\n\n\ndef long_function(x):\n")
+	for i := 0; i < 30; i++ {
+		fmt.Fprintf(&src, "    x = x + %d  # step %d\n", i, i)
+	}
+	src.WriteString("    return x\n")
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.py"), []byte(src.String()), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	builder, err := NewBuilder(tmpDir, &mockProvider{})
+	if err != nil {
+		t.Fatalf("NewBuilder failed: %v", err)
+	}
+	builder.WithChunkOptions(ChunkOptions{Size: 100, Overlap: 20})
+
+	files, err := builder.Scan()
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	units, err := builder.Extract(files)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	var parent *CodeUnit
+	var chunks []*CodeUnit
+	for _, u := range units {
+		switch {
+		case u.Type == UnitTypeChunk:
+			chunks = append(chunks, u)
+		case u.Name == "long_function":
+			parent = u
+		case u.Name == "short" && u.EndLine != 2:
+			t.Errorf("short EndLine = %d, want 2", u.EndLine)
+		}
+	}
+	if parent == nil {
+		t.Fatal("long_function not extracted")
+	}
+	if parent.EndLine != 36 {
+		t.Errorf("long_function EndLine = %d, want 36", parent.EndLine)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("got %d chunks, want at least 2", len(chunks))
+	}
+
+	for i, c := range chunks {
+		if c.Parent != parent.ID || c.Name != "long_function" {
+			t.Errorf("chunk %d: parent %q name %q", i, c.Parent, c.Name)
+		}
+		if c.LineNumber < parent.LineNumber || c.EndLine > parent.EndLine || c.EndLine < c.LineNumber {
+			t.Errorf("chunk %d: lines %d-%d outside %d-%d", i, c.LineNumber, c.EndLine, parent.LineNumber, parent.EndLine)
+		}
+		if len(c.Code) > 100*charsPerToken {
+			t.Errorf("chunk %d: %d characters", i, len(c.Code))
+		}
+		if i > 0 && c.LineNumber > chunks[i-1].EndLine {
+			t.Errorf("chunk %d starts at %d, after the previous chunk ends at %d", i, c.LineNumber, chunks[i-1].EndLine)
+		}
+		if !strings.Contains(EmbeddingText(c), fmt.Sprintf("Lines %d-%d:", c.LineNumber, c.EndLine)) {
+			t.Errorf("chunk %d: embedding text lacks its line range", i)
+		}
+	}
+	if chunks[0].LineNumber != parent.LineNumber || chunks[len(chunks)-1].EndLine != parent.EndLine {
+		t.Errorf("chunks cover %d-%d, want %d-%d", chunks[0].LineNumber, chunks[len(chunks)-1].EndLine, parent.LineNumber, parent.EndLine)
+	}
+
+	if m := ComputeMetrics(units); m.Units != len(units)-len(chunks) {
+		t.Errorf("metrics count %d units, want %d", m.Units, len(units)-len(chunks))
+	}
+}
@@ -0,0 +1,409 @@
+package semantic
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// UnitTypeCommit is the type of units holding a commit message or the
+// description of a merged pull request
+const UnitTypeCommit = "commit"
+
+// DefaultCommitLimit is the number of recent commits, and of merged pull
+// requests, indexed when CommitOptions.Limit is zero
+const DefaultCommitLimit = 200
+
+// DefaultGitHubAPI is the GitHub API used when CommitOptions.GitHubAPI is
+// empty
+const DefaultGitHubAPI = "https://api.github.com"
+
+const (
+	// maxCommitFiles caps the files listed on one commit unit
+	maxCommitFiles = 50
+	// commitScanFactor bounds the commits inspected per commit indexed,
+	// for projects in a subdirectory of a busy repository
+	commitScanFactor = 10
+	// githubPageSize is the most items the GitHub API returns per page
+	githubPageSize = 100
+)
+
+// CommitOptions controls indexing of the project's git history
+type CommitOptions struct {
+	// Enabled indexes the messages of recent commits as commit units,
+	// linked to the files they touched
+	Enabled bool
+	// Limit is the number of commits, newest first, and of merged pull
+	// requests indexed; zero uses DefaultCommitLimit
+	Limit int
+	// GitHubToken, when set, also indexes the descriptions of merged pull
+	// requests from the GitHub API
+	GitHubToken string
+	// GitHubRepo is the repository on GitHub as "owner/name"; empty uses
+	// the origin remote
+	GitHubRepo string
+	// GitHubAPI is the API base URL, for GitHub Enterprise; empty uses
+	// DefaultGitHubAPI
+	GitHubAPI string
+}
+
+// gitCommit is a commit with the files it touched under the project root
+type gitCommit struct {
+	hash    string
+	author  string
+	date    time.Time
+	message string
+	files   []string
+}
+
+// pullRequest is a merged pull request with the files it touched under
+// the project root
+type pullRequest struct {
+	number      int
+	title       string
+	body        string
+	author      string
+	mergedAt    time.Time
+	mergeCommit string
+	url         string
+	files       []string
+}
+
+// WithCommits enables indexing of commit messages and pull requests
+func (b *Builder) WithCommits(opts CommitOptions) *Builder {
+	b.commits = opts
+	return b
+}
+
+// historyUnits returns the commit units of the project at rootDir: one per
+// recent commit and, with a GitHub token, one per merged pull request. An
+// error for pull requests comes with the commit units found.
+func historyUnits(rootDir string, opts CommitOptions) ([]*CodeUnit, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultCommitLimit
+	}
+	repo, err := git.PlainOpenWithOptions(rootDir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("opening git repository: %w", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("opening worktree: %w", err)
+	}
+	prefix, err := filepath.Rel(wt.Filesystem.Root(), rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("locating %s in the repository: %w", rootDir, err)
+	}
+	prefix = filepath.ToSlash(prefix)
+	if prefix == "." {
+		prefix = ""
+	}
+
+	commits, err := scanCommits(repo, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	units := make([]*CodeUnit, 0, len(commits))
+	for _, c := range commits {
+		units = append(units, commitUnit(c))
+	}
+
+	if opts.GitHubToken == "" {
+		return units, nil
+	}
+	name := opts.GitHubRepo
+	if name == "" {
+		name = originGitHubRepo(repo)
+	}
+	if name == "" {
+		return units, fmt.Errorf("no GitHub repository to fetch pull requests from; set index.commits.github_repo")
+	}
+	pulls, err := fetchPullRequests(opts, name, prefix, limit, commits)
+	for _, pr := range pulls {
+		units = append(units, pullRequestUnit(name, pr))
+	}
+	return units, err
+}
+
+// scanCommits returns up to limit commits reachable from HEAD, newest
+// first, that touched files under prefix
+func scanCommits(repo *git.Repository, prefix string, limit int) ([]gitCommit, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("resolving HEAD: %w", err)
+	}
+	iter, err := repo.Log(&git.LogOptions{From: head.Hash(), Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, fmt.Errorf("reading git log: %w", err)
+	}
+	defer iter.Close()
+
+	var commits []gitCommit
+	scanned := 0
+	err = iter.ForEach(func(c *object.Commit) error {
+		if len(commits) >= limit || scanned >= limit*commitScanFactor {
+			return storer.ErrStop
+		}
+		scanned++
+		files, err := changedFiles(c)
+		if err != nil {
+			// A shallow clone ends where parents are missing
+			return storer.ErrStop
+		}
+		if files = filesUnder(files, prefix); len(files) == 0 {
+			return nil
+		}
+		commits = append(commits, gitCommit{
+			hash:    c.Hash.String(),
+			author:  c.Author.Name,
+			date:    c.Author.When,
+			message: strings.TrimSpace(c.Message),
+			files:   files,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading git log: %w", err)
+	}
+	return commits, nil
+}
+
+// changedFiles returns the paths a commit added, changed or removed
+// compared to its first parent, relative to the repository root
+func changedFiles(c *object.Commit) ([]string, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+	var parentTree *object.Tree
+	if c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+	}
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(changes))
+	for _, change := range changes {
+		name := change.To.Name
+		if name == "" {
+			name = change.From.Name
+		}
+		files = append(files, name)
+	}
+	return files, nil
+}
+
+// filesUnder keeps the files under prefix, relative to it
+func filesUnder(files []string, prefix string) []string {
+	if prefix == "" {
+		return files
+	}
+	var kept []string
+	for _, f := range files {
+		if rel, ok := strings.CutPrefix(f, prefix+"/"); ok {
+			kept = append(kept, rel)
+		}
+	}
+	return kept
+}
+
+// commitUnit returns the unit of a commit, named by its short hash
+func commitUnit(c gitCommit) *CodeUnit {
+	subject, _, _ := strings.Cut(c.message, "\n")
+	unit := &CodeUnit{
+		ID:        types.UnitURI{Scheme: "git", Path: c.hash}.String(),
+		Name:      c.hash[:7],
+		Type:      UnitTypeCommit,
+		FilePath:  c.files[0],
+		Signature: fmt.Sprintf("commit %s: %s", c.hash[:7], subject),
+		Docstring: c.message,
+	}
+	setHistoryMetadata(unit, c.author, c.date, c.files)
+	return unit
+}
+
+// pullRequestUnit returns the unit of a merged pull request of repo
+func pullRequestUnit(repo string, pr pullRequest) *CodeUnit {
+	name := fmt.Sprintf("#%d", pr.number)
+	unit := &CodeUnit{
+		ID:        types.UnitURI{Scheme: "github", Path: repo, Symbol: fmt.Sprintf("pull/%d", pr.number)}.String(),
+		Name:      name,
+		Type:      UnitTypeCommit,
+		Signature: fmt.Sprintf("pull request %s: %s", name, pr.title),
+		Docstring: strings.TrimSpace(pr.title + "\n\n" + pr.body),
+	}
+	if len(pr.files) > 0 {
+		unit.FilePath = pr.files[0]
+	}
+	setHistoryMetadata(unit, pr.author, pr.mergedAt, pr.files)
+	SetMetadata(unit, "pr", fmt.Sprint(pr.number))
+	SetMetadata(unit, "url", pr.url)
+	if pr.mergeCommit != "" {
+		SetMetadata(unit, "commit", pr.mergeCommit[:min(len(pr.mergeCommit), 7)])
+	}
+	return unit
+}
+
+// setHistoryMetadata records who made a change, when, and the files it
+// touched, which searches can filter on and embeddings include
+func setHistoryMetadata(unit *CodeUnit, author string, date time.Time, files []string) {
+	SetMetadata(unit, "author", author)
+	SetMetadata(unit, "date", date.Format(time.DateOnly))
+	if len(files) > 0 {
+		SetMetadata(unit, "files", strings.Join(files[:min(len(files), maxCommitFiles)], ","))
+	}
+}
+
+// originGitHubRepo returns the "owner/name" of the origin remote when it
+// is hosted on GitHub, or ""
+func originGitHubRepo(repo *git.Repository) string {
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return ""
+	}
+	for _, url := range remote.Config().URLs {
+		if name := parseGitHubRepo(url); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// parseGitHubRepo returns "owner/name" from a GitHub remote URL, over SSH
+// or HTTPS, or ""
+func parseGitHubRepo(url string) string {
+	for _, prefix := range []string{"git@github.com:", "ssh://git@github.com/", "https://github.com/", "http://github.com/"} {
+		rest, ok := strings.CutPrefix(url, prefix)
+		if !ok {
+			continue
+		}
+		rest = strings.TrimSuffix(strings.TrimSuffix(rest, "/"), ".git")
+		if owner, name, ok := strings.Cut(rest, "/"); ok && owner != "" && name != "" && !strings.Contains(name, "/") {
+			return rest
+		}
+	}
+	return ""
+}
+
+// githubPull is a pull request as the GitHub API lists it
+type githubPull struct {
+	Number         int        `json:"number"`
+	Title          string     `json:"title"`
+	Body           string     `json:"body"`
+	HTMLURL        string     `json:"html_url"`
+	MergedAt       *time.Time `json:"merged_at"`
+	MergeCommitSHA string     `json:"merge_commit_sha"`
+	User           struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+// fetchPullRequests returns up to limit pull requests of repo merged most
+// recently, with the files they touched under prefix. The files of a pull
+// request whose merge commit is among commits are taken from it; others
+// are fetched.
+func fetchPullRequests(opts CommitOptions, repo, prefix string, limit int, commits []gitCommit) ([]pullRequest, error) {
+	api := strings.TrimSuffix(opts.GitHubAPI, "/")
+	if api == "" {
+		api = DefaultGitHubAPI
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	commitFiles := make(map[string][]string, len(commits))
+	for _, c := range commits {
+		commitFiles[c.hash] = c.files
+	}
+
+	var pulls []pullRequest
+	for page := 1; len(pulls) < limit; page++ {
+		var batch []githubPull
+		url := fmt.Sprintf("%s/repos/%s/pulls?state=closed&sort=updated&direction=desc&per_page=%d&page=%d", api, repo, githubPageSize, page)
+		if err := getGitHub(client, url, opts.GitHubToken, &batch); err != nil {
+			return pulls, fmt.Errorf("listing pull requests of %s: %w", repo, err)
+		}
+		for _, p := range batch {
+			if p.MergedAt == nil || len(pulls) >= limit {
+				continue
+			}
+			pr := pullRequest{
+				number:      p.Number,
+				title:       p.Title,
+				body:        p.Body,
+				author:      p.User.Login,
+				mergedAt:    *p.MergedAt,
+				mergeCommit: p.MergeCommitSHA,
+				url:         p.HTMLURL,
+			}
+			files, ok := commitFiles[p.MergeCommitSHA]
+			if !ok {
+				var err error
+				if files, err = pullRequestFiles(client, api, repo, p.Number, opts.GitHubToken); err != nil {
+					return pulls, err
+				}
+				files = filesUnder(files, prefix)
+			}
+			// Pull requests that only touched other parts of the repository
+			if len(files) == 0 {
+				continue
+			}
+			pr.files = files
+			pulls = append(pulls, pr)
+		}
+		if len(batch) < githubPageSize {
+			break
+		}
+	}
+	return pulls, nil
+}
+
+// pullRequestFiles returns the first page of files a pull request changed,
+// relative to the repository root
+func pullRequestFiles(client *http.Client, api, repo string, number int, token string) ([]string, error) {
+	var changed []struct {
+		Filename string `json:"filename"`
+	}
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d/files?per_page=%d", api, repo, number, githubPageSize)
+	if err := getGitHub(client, url, token, &changed); err != nil {
+		return nil, fmt.Errorf("listing files of pull request #%d: %w", number, err)
+	}
+	files := make([]string, len(changed))
+	for i, f := range changed {
+		files[i] = f.Filename
+	}
+	return files, nil
+}
+
+// getGitHub decodes the JSON response of a GitHub API GET request into v
+func getGitHub(client *http.Client, url, token string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub API returned %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
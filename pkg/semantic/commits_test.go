@@ -0,0 +1,169 @@
+package semantic
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitFiles writes files under root and commits them a minute after the
+// repository's last commit, returning the hash
+func commitFiles(t *testing.T, repo *git.Repository, root, message string, files map[string]string) string {
+	t.Helper()
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := wt.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	when := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if head, err := repo.Head(); err == nil {
+		last, err := repo.CommitObject(head.Hash())
+		if err != nil {
+			t.Fatal(err)
+		}
+		when = last.Author.When.Add(time.Minute)
+	}
+	sig := &object.Signature{Name: "alice", Email: "alice@example.com", When: when}
+	hash, err := wt.Commit(message, &git.CommitOptions{Author: sig, Committer: sig})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash.String()
+}
+
+func TestHistoryUnits(t *testing.T) {
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, repo, root, "Add client", map[string]string{"svc/client.go": "package svc\n"})
+	commitFiles(t, repo, root, "Update docs", map[string]string{"README.md": "docs\n"})
+	retry := commitFiles(t, repo, root, "Retry failed requests\n\nThe upstream drops connections under load.",
+		map[string]string{"svc/client.go": "package svc\n\nfunc retry() {}\n", "svc/backoff.go": "package svc\n"})
+
+	// The project is a subdirectory; commits outside it are left out
+	units, err := historyUnits(filepath.Join(root, "svc"), CommitOptions{Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(units) != 2 {
+		t.Fatalf("historyUnits() = %d units, want 2", len(units))
+	}
+	u := units[0]
+	if u.ID != "git://"+retry || u.Name != retry[:7] || u.Type != UnitTypeCommit {
+		t.Errorf("unit = %s %s %s", u.ID, u.Name, u.Type)
+	}
+	if u.Signature != "commit "+retry[:7]+": Retry failed requests" || u.Docstring != "Retry failed requests\n\nThe upstream drops connections under load." {
+		t.Errorf("signature %q, docstring %q", u.Signature, u.Docstring)
+	}
+	if u.FilePath != "backoff.go" || u.Metadata["files"] != "backoff.go,client.go" {
+		t.Errorf("file %q, files %q", u.FilePath, u.Metadata["files"])
+	}
+	if u.Metadata["author"] != "alice" || u.Metadata["date"] != "2026-03-01" {
+		t.Errorf("metadata = %v", u.Metadata)
+	}
+	if units[1].Metadata["files"] != "client.go" {
+		t.Errorf("first commit files = %q", units[1].Metadata["files"])
+	}
+
+	if units, _ := historyUnits(root, CommitOptions{Enabled: true, Limit: 1}); len(units) != 1 || units[0].Name != retry[:7] {
+		t.Errorf("historyUnits() with limit 1 = %d units", len(units))
+	}
+	if _, err := historyUnits(t.TempDir(), CommitOptions{Enabled: true}); err == nil {
+		t.Error("historyUnits() outside a repository succeeded")
+	}
+}
+
+func TestHistoryUnitsPullRequests(t *testing.T) {
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	merge := commitFiles(t, repo, root, "Retry failed requests (#12)", map[string]string{"client.go": "package svc\n"})
+
+	merged := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/acme/svc/pulls", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode([]map[string]interface{}{
+			{"number": 12, "title": "Retry failed requests", "body": "Upstream drops connections.", "merged_at": merged,
+				"merge_commit_sha": merge, "html_url": "https://github.com/acme/svc/pull/12", "user": map[string]string{"login": "alice"}},
+			// Closed without merging
+			{"number": 11, "title": "Rewrite everything", "merged_at": nil},
+			{"number": 10, "title": "Add metrics", "merged_at": merged, "merge_commit_sha": "0000000000000000000000000000000000000000"},
+		})
+	})
+	mux.HandleFunc("/repos/acme/svc/pulls/10/files", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]string{{"filename": "metrics.go"}})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	units, err := historyUnits(root, CommitOptions{Enabled: true, GitHubToken: "secret", GitHubRepo: "acme/svc", GitHubAPI: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(units) != 3 {
+		t.Fatalf("historyUnits() = %d units, want a commit and 2 pull requests", len(units))
+	}
+	pr := units[1]
+	if pr.ID != "github://acme/svc#pull/12" || pr.Name != "#12" || pr.Type != UnitTypeCommit {
+		t.Errorf("pull request unit = %s %s %s", pr.ID, pr.Name, pr.Type)
+	}
+	if pr.Docstring != "Retry failed requests\n\nUpstream drops connections." || pr.FilePath != "client.go" {
+		t.Errorf("docstring %q, file %q", pr.Docstring, pr.FilePath)
+	}
+	if pr.Metadata["pr"] != "12" || pr.Metadata["commit"] != merge[:7] || pr.Metadata["author"] != "alice" {
+		t.Errorf("metadata = %v", pr.Metadata)
+	}
+	// Files of pull requests merged outside the scanned history are fetched
+	if units[2].Metadata["files"] != "metrics.go" {
+		t.Errorf("fetched files = %q", units[2].Metadata["files"])
+	}
+
+	// A failed request keeps the commit units
+	units, err = historyUnits(root, CommitOptions{Enabled: true, GitHubToken: "wrong", GitHubRepo: "acme/svc", GitHubAPI: server.URL})
+	if err == nil || len(units) != 1 {
+		t.Errorf("historyUnits() with a bad token = %d units, %v", len(units), err)
+	}
+}
+
+func TestParseGitHubRepo(t *testing.T) {
+	tests := map[string]string{
+		"git@github.com:acme/svc.git":        "acme/svc",
+		"https://github.com/acme/svc":        "acme/svc",
+		"https://github.com/acme/svc.git/":   "acme/svc",
+		"ssh://git@github.com/acme/svc.git":  "acme/svc",
+		"https://gitlab.com/acme/svc.git":    "",
+		"https://github.com/acme":            "",
+		"https://github.com/acme/svc/issues": "",
+	}
+	for url, want := range tests {
+		if got := parseGitHubRepo(url); got != want {
+			t.Errorf("parseGitHubRepo(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package semantic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// cfgFailureReason is the reason recorded for functions whose control flow
+// graph could not be extracted
+const cfgFailureReason = "control flow extraction failed; units have no complexity metrics"
+
+// historyFailureReason is the reason recorded when commit messages or pull
+// requests could not be indexed
+const historyFailureReason = "git history or pull requests could not be read; commit units are missing or partial"
+
+// Degradations returns the features the last build skipped or cut short:
+// call graphs that failed to build, functions without a CFG summary and
+// units left out when the budget ran out
+func (b *Builder) Degradations() types.Degradations {
+	ds := append(types.Degradations(nil), b.degradations...)
+	if b.remaining > 0 {
+		ds.Add(types.DegradedPartialIndex, fmt.Sprintf("build budget of %s ran out", b.budget), b.remaining)
+	}
+	return ds
+}
+
+// StaleFiles returns the files among paths modified after the index was
+// built, each once. Relative paths are resolved against rootDir; files that
+// no longer exist count as stale.
+func (m *IndexMetadata) StaleFiles(rootDir string, paths []string) []string {
+	var stale []string
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		if path == "" || seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		full := path
+		if !filepath.IsAbs(full) {
+			full = filepath.Join(rootDir, full)
+		}
+		info, err := os.Stat(full)
+		if err != nil || info.ModTime().After(m.Timestamp) {
+			stale = append(stale, path)
+		}
+	}
+	return stale
+}
+
+// ResultDegradations returns the degradations of a result drawn from the
+// index: those recorded when it was built, and feature for the result files
+// that changed since. It returns nil for nil metadata.
+func (m *IndexMetadata) ResultDegradations(rootDir string, files []string, feature string) types.Degradations {
+	if m == nil {
+		return nil
+	}
+	ds := append(types.Degradations(nil), m.Degradations...)
+	if stale := m.StaleFiles(rootDir, files); len(stale) > 0 {
+		ds.Add(feature, "files changed since the index was built; run 'gcq warm' to refresh", len(stale))
+	}
+	return ds
+}
@@ -0,0 +1,63 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+func TestStaleFiles(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"old.py", "new.py"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("x = 1\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	built := time.Now()
+	past := built.Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(root, "old.py"), past, past); err != nil {
+		t.Fatal(err)
+	}
+	future := built.Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(root, "new.py"), future, future); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &IndexMetadata{Timestamp: built}
+	got := m.StaleFiles(root, []string{"old.py", "new.py", "new.py", "gone.py", filepath.Join(root, "old.py")})
+	if want := []string{"new.py", "gone.py"}; !slices.Equal(got, want) {
+		t.Errorf("StaleFiles() = %v, want %v", got, want)
+	}
+
+	m.Degradations = types.Degradations{{Feature: types.DegradedCFG, Reason: cfgFailureReason, Count: 1}}
+	ds := m.ResultDegradations(root, []string{"old.py", "new.py"}, types.DegradedCallGraph)
+	if len(ds) != 2 || ds[1].Feature != types.DegradedCallGraph || ds[1].Count != 1 {
+		t.Errorf("ResultDegradations() = %+v", ds)
+	}
+	if ds := m.ResultDegradations(root, []string{"old.py"}, types.DegradedCallGraph); len(ds) != 1 {
+		t.Errorf("ResultDegradations() of fresh files = %+v", ds)
+	}
+	if ds := (*IndexMetadata)(nil).ResultDegradations(root, []string{"new.py"}, types.DegradedCallGraph); ds != nil {
+		t.Errorf("ResultDegradations() of nil metadata = %+v", ds)
+	}
+}
+
+func TestBuilderDegradations(t *testing.T) {
+	b := &Builder{budget: time.Minute, remaining: 7}
+	b.degradations.Add(types.DegradedCFG, cfgFailureReason, 2)
+
+	got := b.Degradations()
+	if len(got) != 2 || got[0].Feature != types.DegradedCFG || got[0].Count != 2 {
+		t.Fatalf("Degradations() = %+v", got)
+	}
+	if got[1].Feature != types.DegradedPartialIndex || got[1].Count != 7 {
+		t.Errorf("partial index degradation = %+v", got[1])
+	}
+	if len(b.degradations) != 1 {
+		t.Errorf("Degradations() modified the builder: %+v", b.degradations)
+	}
+}
@@ -0,0 +1,103 @@
+package semantic
+
+import "strings"
+
+// DefaultStdlib lists, per language, the standard library modules left out
+// of a unit's dependencies when no dependency manifest classifies an
+// import. An entry also covers its submodules, so "os" covers "os.path"
+// and "encoding" covers "encoding/json".
+var DefaultStdlib = map[string][]string{
+	"python": {
+		"abc", "argparse", "asyncio", "base64", "collections", "contextlib",
+		"copy", "csv", "dataclasses", "datetime", "enum", "functools", "glob",
+		"hashlib", "http", "importlib", "inspect", "io", "itertools", "json",
+		"logging", "math", "multiprocessing", "os", "pathlib", "pickle",
+		"random", "re", "shutil", "socket", "sqlite3", "string", "subprocess",
+		"sys", "tempfile", "threading", "time", "typing", "unittest", "urllib",
+		"uuid", "warnings",
+	},
+	"go": {
+		"bufio", "bytes", "container", "context", "crypto", "database",
+		"embed", "encoding", "errors", "flag", "fmt", "hash", "io", "iter",
+		"log", "maps", "math", "mime", "net", "os", "path", "reflect", "regexp",
+		"runtime", "slices", "sort", "strconv", "strings", "sync", "syscall",
+		"testing", "text", "time", "unicode",
+	},
+	"javascript": nodeBuiltins,
+	"typescript": nodeBuiltins,
+	"java":       {"java", "javax"},
+	"kotlin":     {"kotlin", "kotlinx", "java", "javax"},
+	"rust":       {"std", "core", "alloc"},
+	"csharp":     {"System", "Microsoft"},
+}
+
+// nodeBuiltins are the Node.js core modules
+var nodeBuiltins = []string{
+	"assert", "buffer", "child_process", "crypto", "events", "fs", "http",
+	"https", "net", "node:", "os", "path", "process", "stream", "url", "util",
+	"zlib",
+}
+
+// DependencyFilter selects the imports left out of a unit's dependencies
+// besides relative imports and those a manifest marks as standard library
+// or project code. The zero value uses DefaultStdlib.
+type DependencyFilter struct {
+	// Stdlib adds standard library modules per language to DefaultStdlib
+	Stdlib map[string][]string
+	// Ignore lists packages, such as an organization's internal modules,
+	// that are never reported as dependencies. An entry also covers its
+	// submodules.
+	Ignore []string
+}
+
+// WithDependencyFilter sets which imports are left out of unit
+// dependencies
+func (b *Builder) WithDependencyFilter(filter DependencyFilter) *Builder {
+	b.depFilter = filter
+	return b
+}
+
+// isStdlib reports whether module is a standard library module of lang.
+// An empty lang checks every language.
+func (f DependencyFilter) isStdlib(lang, module string) bool {
+	if lang == "" {
+		for l := range DefaultStdlib {
+			if matchModule(DefaultStdlib[l], module) {
+				return true
+			}
+		}
+		for l := range f.Stdlib {
+			if matchModule(f.Stdlib[l], module) {
+				return true
+			}
+		}
+		return false
+	}
+	return matchModule(DefaultStdlib[lang], module) || matchModule(f.Stdlib[lang], module)
+}
+
+// ignored reports whether module is on the ignore list
+func (f DependencyFilter) ignored(module string) bool {
+	return matchModule(f.Ignore, module)
+}
+
+// matchModule reports whether module is one of patterns or a submodule of
+// one. A pattern ending in a separator, such as "node:", matches any module
+// it prefixes.
+func matchModule(patterns []string, module string) bool {
+	for _, p := range patterns {
+		if p == "" {
+			continue
+		}
+		if module == p {
+			return true
+		}
+		if !strings.HasPrefix(module, p) {
+			continue
+		}
+		if strings.ContainsAny(p[len(p)-1:], "/.:") || strings.ContainsAny(module[len(p):len(p)+1], "/.:") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package semantic
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+func TestDependencyFilterStdlib(t *testing.T) {
+	var filter DependencyFilter
+	tests := []struct {
+		lang   string
+		module string
+		want   bool
+	}{
+		{"python", "os.path", true},
+		{"python", "osmnx", false},
+		{"go", "encoding/json", true},
+		{"go", "github.com/spf13/cobra", false},
+		{"typescript", "node:fs", true},
+		{"rust", "std::collections", true},
+		{"csharp", "System.IO", true},
+		// Python's stdlib is not Go's
+		{"go", "asyncio", false},
+		{"", "asyncio", true},
+	}
+	for _, tt := range tests {
+		if got := filter.isStdlib(tt.lang, tt.module); got != tt.want {
+			t.Errorf("isStdlib(%q, %q) = %v, want %v", tt.lang, tt.module, got, tt.want)
+		}
+	}
+
+	filter.Stdlib = map[string][]string{"python": {"tomllib"}}
+	if !filter.isStdlib("python", "tomllib") || filter.isStdlib("go", "tomllib") {
+		t.Error("Expected configured stdlib modules to apply to their language only")
+	}
+}
+
+func TestResolveDependenciesIgnore(t *testing.T) {
+	moduleInfo := &types.ModuleInfo{}
+	for _, m := range []string{"requests", "acme.billing", "acme", "acmesoft", "json"} {
+		moduleInfo.Imports = append(moduleInfo.Imports, types.Import{Module: m})
+	}
+
+	filter := DependencyFilter{Ignore: []string{"acme"}}
+	names, _ := resolveDependencies(moduleInfo, nil, filter, "app.py", "python", 0)
+	if want := []string{"requests", "acmesoft"}; !slices.Equal(names, want) {
+		t.Errorf("resolveDependencies() = %v, want %v", names, want)
+	}
+}
@@ -0,0 +1,328 @@
+package semantic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/l3aro/go-context-query/pkg/extractor"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// UnitTypeDoc is the type of units holding a section of a Markdown or
+// reStructuredText document, or the template of a Vue or Svelte component
+const UnitTypeDoc = "doc"
+
+// docSectionLines is the most lines of one doc unit; longer sections are
+// split into parts
+const docSectionLines = 80
+
+// atxHeading matches a Markdown "# Title" heading, with optional closing
+// hashes
+var atxHeading = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+
+// setextUnderline matches the === or --- line under a Markdown heading
+var setextUnderline = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+
+// DocSection is a heading of a document and the text under it, up to the
+// next heading
+type DocSection struct {
+	Title string `json:"title"`
+	// Path holds the titles of the enclosing sections and this one
+	Path []string `json:"path"`
+	// Level is 1 for top-level headings; 0 for text before the first one
+	Level   int    `json:"level"`
+	Line    int    `json:"line"`
+	EndLine int    `json:"end_line"`
+	Text    string `json:"text"`
+
+	// body is the index of the first line of Text after the heading
+	body int
+}
+
+// WithDocs enables indexing Markdown and reStructuredText files as doc
+// units, one per section
+func (b *Builder) WithDocs(enabled bool) *Builder {
+	b.docs = enabled
+	return b
+}
+
+// IsDocLanguage reports whether files of lang, as the scanner detects
+// it, are documents split into doc units
+func IsDocLanguage(lang string) bool {
+	return lang == "markdown" || lang == "rst"
+}
+
+// SplitDocSections splits a Markdown or reStructuredText document into its
+// sections, by the headings of any level. Text before the first heading is
+// a section titled with the file name. Sections without text under their
+// heading are left out.
+func SplitDocSections(filePath string, source []byte) []DocSection {
+	lines := strings.Split(strings.ReplaceAll(string(source), "\r\n", "\n"), "\n")
+	var headings []docHeading
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".rst", ".rest":
+		headings = rstHeadings(lines)
+	default:
+		headings = markdownHeadings(lines)
+	}
+
+	var sections []DocSection
+	var path []string
+	levels := []int{}
+	add := func(title string, level, start, bodyStart, end int) {
+		// Drop trailing blank lines
+		for end > start && strings.TrimSpace(lines[end-1]) == "" {
+			end--
+		}
+		if end <= bodyStart || strings.TrimSpace(strings.Join(lines[bodyStart:end], "\n")) == "" {
+			return
+		}
+		sections = append(sections, DocSection{
+			Title:   title,
+			Path:    append([]string(nil), path...),
+			Level:   level,
+			Line:    start + 1,
+			EndLine: end,
+			Text:    strings.Join(lines[start:end], "\n"),
+			body:    bodyStart - start,
+		})
+	}
+
+	first := len(lines)
+	if len(headings) > 0 {
+		first = headings[0].start
+	}
+	path = []string{filepath.Base(filePath)}
+	preamble := min(frontMatterEnd(lines), first)
+	add(filepath.Base(filePath), 0, preamble, preamble, first)
+
+	path = nil
+	for i, h := range headings {
+		for len(levels) > 0 && levels[len(levels)-1] >= h.level {
+			levels = levels[:len(levels)-1]
+			path = path[:len(path)-1]
+		}
+		levels = append(levels, h.level)
+		path = append(path, h.title)
+		end := len(lines)
+		if i+1 < len(headings) {
+			end = headings[i+1].start
+		}
+		add(h.title, h.level, h.start, h.bodyStart, end)
+	}
+	return sections
+}
+
+// docHeading is a heading found in a document; start and bodyStart are
+// 0-based line indexes of the heading and of the text under it
+type docHeading struct {
+	title     string
+	level     int
+	start     int
+	bodyStart int
+}
+
+// frontMatterEnd returns the index of the first line after the YAML front
+// matter of a Markdown document, or 0 when it has none
+func frontMatterEnd(lines []string) int {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return 0
+	}
+	for i := 1; i < len(lines); i++ {
+		if t := strings.TrimSpace(lines[i]); t == "---" || t == "..." {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// markdownHeadings returns the ATX (# Title) and setext (Title over === or
+// ---) headings of a Markdown document, skipping fenced code blocks and
+// front matter
+func markdownHeadings(lines []string) []docHeading {
+	var headings []docHeading
+	fence := ""
+	for i := frontMatterEnd(lines); i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		if m := atxHeading.FindStringSubmatch(line); m != nil {
+			headings = append(headings, docHeading{title: strings.TrimSpace(m[2]), level: len(m[1]), start: i, bodyStart: i + 1})
+			continue
+		}
+		// A setext underline turns the paragraph line above into a heading;
+		// --- after a blank line is a rule
+		if m := setextUnderline.FindStringSubmatch(line); m != nil && i > 0 {
+			prev := strings.TrimSpace(lines[i-1])
+			if prev == "" || (len(headings) > 0 && headings[len(headings)-1].start == i-1) {
+				continue
+			}
+			level := 1
+			if m[1][0] == '-' {
+				level = 2
+			}
+			headings = append(headings, docHeading{title: prev, level: level, start: i - 1, bodyStart: i + 1})
+		}
+	}
+	return headings
+}
+
+// rstHeadings returns the section titles of a reStructuredText document:
+// a line underlined, and optionally overlined, with a punctuation
+// character. Levels follow the order in which adornment styles first
+// appear.
+func rstHeadings(lines []string) []docHeading {
+	var headings []docHeading
+	styles := make(map[string]int)
+	for i := 0; i+1 < len(lines); i++ {
+		title := lines[i]
+		if strings.TrimSpace(title) == "" || title[0] == ' ' || title[0] == '\t' || rstAdornment(title) != 0 {
+			continue
+		}
+		under := rstAdornment(lines[i+1])
+		if under == 0 || utf8.RuneCountInString(strings.TrimSpace(lines[i+1])) < utf8.RuneCountInString(strings.TrimSpace(title)) {
+			continue
+		}
+		start, style := i, string(under)
+		if i > 0 && rstAdornment(lines[i-1]) == under {
+			start, style = i-1, "over"+style
+		}
+		level, ok := styles[style]
+		if !ok {
+			level = len(styles) + 1
+			styles[style] = level
+		}
+		headings = append(headings, docHeading{title: strings.TrimSpace(title), level: level, start: start, bodyStart: i + 2})
+		i++
+	}
+	return headings
+}
+
+// rstAdornment returns the character of a line made of one repeated
+// punctuation character, at least three long, or 0
+func rstAdornment(line string) byte {
+	line = strings.TrimRight(line, " \t")
+	if len(line) < 3 || !strings.ContainsRune("=-~^\"'`#*+:._!$%&,/;<>?@\\|", rune(line[0])) {
+		return 0
+	}
+	if strings.Trim(line, line[:1]) != "" {
+		return 0
+	}
+	return line[0]
+}
+
+// docUnits returns the doc units of a document, one per section and more
+// for sections longer than docSectionLines
+func docUnits(lang, relPath string, sections []DocSection) []*CodeUnit {
+	var units []*CodeUnit
+	seen := make(map[string]bool)
+	for _, s := range sections {
+		lines := strings.Split(s.Text, "\n")
+		for part, from := 1, 0; from < len(lines); part, from = part+1, from+docSectionLines {
+			to := min(from+docSectionLines, len(lines))
+			name := s.Title
+			if from > 0 {
+				name = fmt.Sprintf("%s (%d)", s.Title, part)
+			}
+			anchors := make([]string, len(s.Path))
+			for i, title := range s.Path {
+				anchors[i] = headingAnchor(title)
+			}
+			symbol := strings.Join(anchors, "/")
+			if from > 0 {
+				symbol = fmt.Sprintf("%s@%d", symbol, s.Line+from)
+			}
+			if seen[symbol] {
+				symbol = fmt.Sprintf("%s@%d", symbol, s.Line+from)
+			}
+			seen[symbol] = true
+			units = append(units, &CodeUnit{
+				ID:         types.NewUnitURI(lang, relPath, symbol).String(),
+				Language:   lang,
+				Name:       name,
+				Type:       UnitTypeDoc,
+				FilePath:   relPath,
+				LineNumber: s.Line + from,
+				EndLine:    s.Line + to - 1,
+				Signature:  strings.Join(s.Path, " > "),
+				Docstring:  docSummary(lines[max(from, s.body):to]),
+				Code:       strings.Join(lines[from:to], "\n"),
+			})
+		}
+	}
+	return units
+}
+
+// templateUnits returns the doc units of the template of a Vue or Svelte
+// component, whose script blocks are extracted as code
+func templateUnits(filePath, relPath, lang string) []*CodeUnit {
+	sfcLang, ok := extractor.SFCLanguage(filePath)
+	if !ok {
+		return nil
+	}
+	source, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil
+	}
+	template := extractor.SplitSFC(source, sfcLang).Template
+	if template == nil {
+		return nil
+	}
+	return docUnits(lang, relPath, []DocSection{{
+		Title:   "template",
+		Path:    []string{"template"},
+		Level:   1,
+		Line:    template.Line,
+		EndLine: template.EndLine,
+		Text:    template.Text,
+	}})
+}
+
+// headingAnchor returns the anchor GitHub links a heading with, e.g.
+// "retry-guide" for "Retry Guide"
+func headingAnchor(title string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.':
+			sb.WriteRune(r)
+		case r == ' ':
+			sb.WriteByte('-')
+		}
+	}
+	return sb.String()
+}
+
+// docSummary returns the first paragraph of lines
+func docSummary(lines []string) string {
+	var paragraph []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		paragraph = append(paragraph, trimmed)
+	}
+	summary := strings.Join(paragraph, " ")
+	if len(summary) > types.MaxDocstringLength {
+		summary = types.TruncateUTF8(summary, types.MaxDocstringLength) + "..."
+	}
+	return summary
+}
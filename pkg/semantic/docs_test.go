@@ -0,0 +1,173 @@
+package semantic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testMarkdown = `---
+title: Guide
+---
+Intro before any heading.
+
+# Retry Guide
+
+Requests are retried with backoff.
+
+## Configuration
+
+Set retries in config.yaml:
+
+` + "```yaml\n# not a heading\nretries: 3\n```" + `
+
+Limits
+------
+
+At most 5 retries.
+
+---
+
+# Empty
+
+## FAQ ##
+
+Why retry? Because networks fail.
+`
+
+func TestSplitMarkdownSections(t *testing.T) {
+	sections := SplitDocSections("docs/guide.md", []byte(testMarkdown))
+
+	var titles []string
+	for _, s := range sections {
+		titles = append(titles, strings.Join(s.Path, " > "))
+	}
+	want := []string{"guide.md", "Retry Guide", "Retry Guide > Configuration", "Retry Guide > Limits", "Empty > FAQ"}
+	if !slices.Equal(titles, want) {
+		t.Fatalf("sections = %q, want %q", titles, want)
+	}
+
+	intro := sections[0]
+	if intro.Level != 0 || intro.Line != 4 || intro.Text != "Intro before any heading." {
+		t.Errorf("preamble = %+v", intro)
+	}
+	config := sections[2]
+	if config.Level != 2 || config.Line != 10 || !strings.Contains(config.Text, "retries: 3") {
+		t.Errorf("configuration = %+v", config)
+	}
+	limits := sections[3]
+	if limits.Line != 19 || limits.EndLine != 24 {
+		t.Errorf("limits lines %d-%d, want 19-24", limits.Line, limits.EndLine)
+	}
+}
+
+func TestSplitRSTSections(t *testing.T) {
+	source := `=====
+Title
+=====
+
+Overview text.
+
+Install
+-------
+
+pip install it.
+
+From source
+~~~~~~~~~~~
+
+Clone the repository.
+
+    indented
+    --------
+
+Usage
+-----
+
+Run it.
+`
+	sections := SplitDocSections("README.rst", []byte(source))
+	var got []string
+	for _, s := range sections {
+		got = append(got, fmt.Sprintf("%d:%s", s.Level, strings.Join(s.Path, " > ")))
+	}
+	want := []string{"1:Title", "2:Title > Install", "3:Title > Install > From source", "2:Title > Usage"}
+	if !slices.Equal(got, want) {
+		t.Errorf("sections = %q, want %q", got, want)
+	}
+	if sections[0].Line != 1 {
+		t.Errorf("overlined title starts on line %d, want 1", sections[0].Line)
+	}
+}
+
+func TestDocUnits(t *testing.T) {
+	units := docUnits("markdown", "docs/guide.md", SplitDocSections("docs/guide.md", []byte(testMarkdown)))
+	if len(units) != 5 {
+		t.Fatalf("docUnits() = %d units, want 5", len(units))
+	}
+	u := units[2]
+	if u.ID != "markdown://docs/guide.md#retry-guide/configuration" || u.Type != UnitTypeDoc || u.Name != "Configuration" {
+		t.Errorf("unit = %s %s %s", u.ID, u.Type, u.Name)
+	}
+	if u.Signature != "Retry Guide > Configuration" || u.Docstring != "Set retries in config.yaml:" {
+		t.Errorf("signature %q, docstring %q", u.Signature, u.Docstring)
+	}
+	if u.LineNumber != 10 || u.EndLine != 17 {
+		t.Errorf("lines %d-%d, want 10-17", u.LineNumber, u.EndLine)
+	}
+
+	// Long sections are split into parts
+	long := "# Changelog\n\n" + strings.Repeat("- change\n", docSectionLines+10)
+	units = docUnits("markdown", "CHANGES.md", SplitDocSections("CHANGES.md", []byte(long)))
+	if len(units) != 2 || units[1].Name != "Changelog (2)" || units[1].LineNumber != docSectionLines+1 {
+		t.Fatalf("long section units = %+v", units)
+	}
+	if units[0].ID == units[1].ID {
+		t.Errorf("parts share the ID %s", units[0].ID)
+	}
+}
+
+func TestBuilderExtractComponents(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"api.ts":  "export function post(body: string): void {\n  console.log(body)\n}\n",
+		"App.vue": "<template>\n  <button @click=\"save\">Save</button>\n</template>\n\n<script setup lang=\"ts\">\nimport { post } from './api'\n\nfunction save(): void {\n  post('x')\n}\n</script>\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(src), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	builder, err := NewBuilder(tmpDir, &mockProvider{})
+	if err != nil {
+		t.Fatalf("NewBuilder failed: %v", err)
+	}
+	scanned, err := builder.Scan()
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	units, err := builder.Extract(scanned)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	found := map[string]*CodeUnit{}
+	for _, u := range units {
+		found[u.FilePath+":"+u.Name] = u
+	}
+	save := found["App.vue:save"]
+	if save == nil || save.Language != "vue" || save.LineNumber != 8 {
+		t.Fatalf("save = %+v, want a vue function on line 8", save)
+	}
+	if !slices.Contains(save.Calls, "api.ts:post") {
+		t.Errorf("save calls %v, want api.ts:post", save.Calls)
+	}
+	tmpl := found["App.vue:template"]
+	if tmpl == nil || tmpl.Type != UnitTypeDoc || tmpl.LineNumber != 2 || !strings.Contains(tmpl.Code, "@click") {
+		t.Errorf("template = %+v", tmpl)
+	}
+}
@@ -0,0 +1,158 @@
+package semantic
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Enricher attaches custom metadata to code units after extraction and
+// before embedding. Whatever it stores in unit.Metadata is saved with the
+// unit in the index, added to its embedding text and can be filtered on
+// with search.SearchOptions.Metadata. A build runs its enrichers on one unit
+// at a time, though it extracts files in parallel; an enricher shared by
+// builds running at once must be safe for concurrent use.
+type Enricher interface {
+	// Name identifies the enricher
+	Name() string
+	// Enrich annotates unit. body is the unit's source, lines LineNumber
+	// to EndLine (only the definition line when EndLine is unknown), or
+	// empty when the source could not be read.
+	Enrich(unit *CodeUnit, body string)
+}
+
+var (
+	enrichersMu sync.RWMutex
+	enrichers   []Enricher
+)
+
+// RegisterEnricher adds e to the enrichers every build runs, typically from
+// an init function of the package that defines it. Enrichers run in the
+// order they were registered, after those set with WithEnrichers.
+func RegisterEnricher(e Enricher) {
+	enrichersMu.Lock()
+	defer enrichersMu.Unlock()
+	enrichers = append(enrichers, e)
+}
+
+// RegisteredEnrichers returns the enrichers added with RegisterEnricher
+func RegisteredEnrichers() []Enricher {
+	enrichersMu.RLock()
+	defer enrichersMu.RUnlock()
+	return append([]Enricher(nil), enrichers...)
+}
+
+// WithEnrichers sets enrichers run on every unit in addition to the
+// registered ones
+func (b *Builder) WithEnrichers(e ...Enricher) *Builder {
+	b.enrichers = e
+	return b
+}
+
+// activeEnrichers returns the enrichers Extract runs, in order
+func (b *Builder) activeEnrichers() []Enricher {
+	return append(append([]Enricher(nil), b.enrichers...), RegisteredEnrichers()...)
+}
+
+// enrichFile runs enrichers on the units of a file, as enrich does. Extract
+// calls it from several goroutines at once, so it holds b.enrichMu and
+// enrichers never run concurrently.
+func (b *Builder) enrichFile(enrichers []Enricher, units []*CodeUnit, lines []string) {
+	if len(enrichers) == 0 || len(units) == 0 {
+		return
+	}
+	b.enrichMu.Lock()
+	defer b.enrichMu.Unlock()
+	enrich(enrichers, units, lines)
+}
+
+// enrich runs enrichers on the units of a file whose source is lines,
+// or nil when it could not be read. Chunk units are enriched with their
+// own code.
+func enrich(enrichers []Enricher, units []*CodeUnit, lines []string) {
+	for _, unit := range units {
+		body := unit.Code
+		if body == "" && lines != nil && unit.LineNumber > 0 && unit.LineNumber <= len(lines) {
+			end := unit.EndLine
+			if end < unit.LineNumber || end > len(lines) {
+				end = unit.LineNumber
+			}
+			body = strings.Join(lines[unit.LineNumber-1:end], "\n")
+		}
+		for _, e := range enrichers {
+			e.Enrich(unit, body)
+		}
+	}
+}
+
+// SetMetadata stores value under key in unit.Metadata, creating the map
+// when needed
+func SetMetadata(unit *CodeUnit, key, value string) {
+	if unit.Metadata == nil {
+		unit.Metadata = make(map[string]string)
+	}
+	unit.Metadata[key] = value
+}
+
+// metadataText formats a unit's metadata for embedding text, sorted by key
+func metadataText(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key + "=" + metadata[key]
+	}
+	return strings.Join(parts, ", ")
+}
+
+// PatternEnricher records what a regular expression matches in a unit's
+// docstring and body under Key, as a comma separated list of distinct
+// matches. When the pattern has a capture group, the first group is
+// recorded instead of the whole match.
+type PatternEnricher struct {
+	Key     string
+	Pattern *regexp.Regexp
+}
+
+// NewPatternEnricher compiles pattern into a PatternEnricher for key
+func NewPatternEnricher(key, pattern string) (*PatternEnricher, error) {
+	if key == "" {
+		return nil, fmt.Errorf("enricher key cannot be empty")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("enricher %s: %w", key, err)
+	}
+	return &PatternEnricher{Key: key, Pattern: re}, nil
+}
+
+// Name returns the metadata key the enricher sets
+func (p *PatternEnricher) Name() string {
+	return p.Key
+}
+
+// Enrich records the pattern's matches in unit.Metadata[p.Key]
+func (p *PatternEnricher) Enrich(unit *CodeUnit, body string) {
+	var found []string
+	seen := make(map[string]bool)
+	for _, text := range []string{unit.Docstring, body} {
+		for _, m := range p.Pattern.FindAllStringSubmatch(text, -1) {
+			value := m[0]
+			if len(m) > 1 {
+				value = m[1]
+			}
+			if value != "" && !seen[value] {
+				seen[value] = true
+				found = append(found, value)
+			}
+		}
+	}
+	if len(found) > 0 {
+		SetMetadata(unit, p.Key, strings.Join(found, ","))
+	}
+}
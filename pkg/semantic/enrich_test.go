@@ -0,0 +1,144 @@
+package semantic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// flagEnricher marks units whose body calls feature_enabled
+type flagEnricher struct{}
+
+func (flagEnricher) Name() string { return "flags" }
+
+func (flagEnricher) Enrich(unit *CodeUnit, body string) {
+	if strings.Contains(body, "feature_enabled(") {
+		SetMetadata(unit, "feature_flag", "true")
+	}
+}
+
+// countingEnricher numbers the units it sees, keeping its count without a
+// lock
+type countingEnricher struct {
+	seen int
+}
+
+func (*countingEnricher) Name() string { return "counting" }
+
+func (e *countingEnricher) Enrich(unit *CodeUnit, body string) {
+	e.seen++
+	SetMetadata(unit, "seen", strconv.Itoa(e.seen))
+}
+
+func TestPatternEnricher(t *testing.T) {
+	e, err := NewPatternEnricher("ticket", `\b([A-Z]+-\d+)\b`)
+	if err != nil {
+		t.Fatalf("NewPatternEnricher failed: %v", err)
+	}
+	unit := &CodeUnit{Name: "charge", Docstring: "Charges a card (PAY-12)."}
+	e.Enrich(unit, "def charge():\n    # TODO PAY-40, see PAY-12\n    pass")
+	if got := unit.Metadata["ticket"]; got != "PAY-12,PAY-40" {
+		t.Errorf("ticket = %q, want PAY-12,PAY-40", got)
+	}
+
+	unit = &CodeUnit{Name: "plain"}
+	e.Enrich(unit, "def plain():\n    pass")
+	if unit.Metadata != nil {
+		t.Errorf("metadata = %v, want none", unit.Metadata)
+	}
+
+	if _, err := NewPatternEnricher("ticket", "("); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+	if _, err := NewPatternEnricher("", "x"); err == nil {
+		t.Error("expected an error for an empty key")
+	}
+}
+
+func TestBuilderExtractEnrichers(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := "def charge(card):\n    # PAY-12\n    if feature_enabled(\"new_flow\"):\n        return 1\n    return 0\n\n\ndef refund():\n    return 0\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "pay.py"), []byte(src), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	builder, err := NewBuilder(tmpDir, &mockProvider{})
+	if err != nil {
+		t.Fatalf("NewBuilder failed: %v", err)
+	}
+	tickets, err := NewPatternEnricher("ticket", `PAY-\d+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder.WithEnrichers(tickets, flagEnricher{})
+
+	files, err := builder.Scan()
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	units, err := builder.Extract(files)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	found := map[string]*CodeUnit{}
+	for _, u := range units {
+		found[u.Name] = u
+	}
+	charge, refund := found["charge"], found["refund"]
+	if charge == nil || refund == nil {
+		t.Fatalf("units = %v", found)
+	}
+	if charge.Metadata["ticket"] != "PAY-12" || charge.Metadata["feature_flag"] != "true" {
+		t.Errorf("charge metadata = %v", charge.Metadata)
+	}
+	if refund.Metadata != nil {
+		t.Errorf("refund metadata = %v, want none", refund.Metadata)
+	}
+	if text := EmbeddingText(charge); !strings.Contains(text, "Metadata: feature_flag=true, ticket=PAY-12") {
+		t.Errorf("embedding text is missing metadata:\n%s", text)
+	}
+}
+
+// TestBuilderExtractStatefulEnricher tests that enrichers run on one unit at
+// a time while files are extracted in parallel; run with -race
+func TestBuilderExtractStatefulEnricher(t *testing.T) {
+	tmpDir := t.TempDir()
+	const files = 40
+	for i := range files {
+		src := fmt.Sprintf("def first_%d():\n    return %d\n\n\ndef second_%d():\n    return first_%d()\n", i, i, i, i)
+		if err := os.WriteFile(filepath.Join(tmpDir, fmt.Sprintf("mod_%d.py", i)), []byte(src), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	builder, err := NewBuilder(tmpDir, &mockProvider{})
+	if err != nil {
+		t.Fatalf("NewBuilder failed: %v", err)
+	}
+	counter := &countingEnricher{}
+	builder.WithEnrichers(counter)
+	scanned, err := builder.Scan()
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	units, err := builder.Extract(scanned)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	if len(units) != 2*files || counter.seen != len(units) {
+		t.Fatalf("enricher saw %d units of %d, want %d", counter.seen, len(units), 2*files)
+	}
+	seen := make(map[string]bool)
+	for _, u := range units {
+		n := u.Metadata["seen"]
+		if n == "" || seen[n] {
+			t.Errorf("unit %s numbered %q, want a number of its own", u.ID, n)
+		}
+		seen[n] = true
+	}
+}
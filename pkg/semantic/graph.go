@@ -0,0 +1,90 @@
+package semantic
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// DefaultCalleeSummaryChars is the length of a callee summary when
+// GraphContext.SummaryChars is not set
+const DefaultCalleeSummaryChars = 80
+
+// GraphContext controls graph-augmented embedding text: one-line summaries
+// of a unit's direct callees are attached to it, so thin wrappers whose own
+// signature and docstring say little are still found by what they do.
+type GraphContext struct {
+	// Callees is the most callee summaries attached per unit (0 = disabled)
+	Callees int
+	// SummaryChars caps each summary (0 = DefaultCalleeSummaryChars)
+	SummaryChars int
+}
+
+// AttachCalleeSummaries sets CalleeSummaries on each unit from the
+// docstrings of the units it calls, in call order. Callees without a
+// docstring are skipped.
+func AttachCalleeSummaries(units []*CodeUnit, gc GraphContext) {
+	if gc.Callees <= 0 {
+		return
+	}
+	chars := gc.SummaryChars
+	if chars <= 0 {
+		chars = DefaultCalleeSummaryChars
+	}
+
+	// Calls are recorded as "file:name"; methods are called by their short
+	// name, so index them under both
+	byKey := make(map[string]*CodeUnit, len(units))
+	for _, u := range units {
+		if u.Docstring == "" {
+			continue
+		}
+		key := fmt.Sprintf("%s:%s", u.FilePath, u.Name)
+		if _, ok := byKey[key]; !ok {
+			byKey[key] = u
+		}
+		if u.Type == "method" {
+			if _, short, ok := strings.Cut(u.Name, "."); ok {
+				key = fmt.Sprintf("%s:%s", u.FilePath, short)
+				if _, ok := byKey[key]; !ok {
+					byKey[key] = u
+				}
+			}
+		}
+	}
+
+	for _, u := range units {
+		u.CalleeSummaries = nil
+		seen := make(map[*CodeUnit]bool)
+		for _, call := range u.Calls {
+			callee, ok := byKey[call]
+			if !ok || callee == u || seen[callee] {
+				continue
+			}
+			seen[callee] = true
+			u.CalleeSummaries = append(u.CalleeSummaries,
+				fmt.Sprintf("%s: %s", callee.Name, summarizeDocstring(callee.Docstring, chars)))
+			if len(u.CalleeSummaries) == gc.Callees {
+				break
+			}
+		}
+	}
+}
+
+// summarizeDocstring returns the first sentence of the first paragraph of
+// a docstring, truncated to chars
+func summarizeDocstring(doc string, chars int) string {
+	doc = strings.TrimSpace(doc)
+	if para, _, ok := strings.Cut(doc, "\n\n"); ok {
+		doc = para
+	}
+	doc = strings.Join(strings.Fields(doc), " ")
+	if i := strings.Index(doc, ". "); i >= 0 {
+		doc = doc[:i+1]
+	}
+	if len(doc) > chars {
+		doc = types.TruncateUTF8(doc, chars) + "..."
+	}
+	return doc
+}
@@ -0,0 +1,91 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/callgraph"
+)
+
+func TestAttachCalleeSummaries(t *testing.T) {
+	wrapper := &CodeUnit{Name: "Handle", Type: "function", FilePath: "api.go",
+		Calls: []string{"api.go:validate", "store.go:Save", "api.go:undocumented", "store.go:Save", "store.go:Flush"}}
+	validate := &CodeUnit{Name: "validate", Type: "function", FilePath: "api.go",
+		Docstring: "Validate checks the request signature. It rejects expired tokens.\n\nDetails follow."}
+	save := &CodeUnit{Name: "Store.Save", Type: "method", FilePath: "store.go",
+		Docstring: "Save writes the record to Postgres"}
+	flush := &CodeUnit{Name: "Flush", Type: "function", FilePath: "store.go", Docstring: "Flush syncs to disk"}
+	undocumented := &CodeUnit{Name: "undocumented", Type: "function", FilePath: "api.go"}
+	units := []*CodeUnit{wrapper, validate, save, flush, undocumented}
+
+	AttachCalleeSummaries(units, GraphContext{})
+	if wrapper.CalleeSummaries != nil {
+		t.Fatalf("Expected no summaries when disabled, got %v", wrapper.CalleeSummaries)
+	}
+
+	AttachCalleeSummaries(units, GraphContext{Callees: 2})
+	want := []string{
+		"validate: Validate checks the request signature.",
+		"Store.Save: Save writes the record to Postgres",
+	}
+	if len(wrapper.CalleeSummaries) != len(want) {
+		t.Fatalf("CalleeSummaries = %v, want %v", wrapper.CalleeSummaries, want)
+	}
+	for i := range want {
+		if wrapper.CalleeSummaries[i] != want[i] {
+			t.Errorf("CalleeSummaries[%d] = %q, want %q", i, wrapper.CalleeSummaries[i], want[i])
+		}
+	}
+
+	text := EmbeddingText(wrapper)
+	if !strings.Contains(text, "Callee summaries: validate: Validate checks") {
+		t.Errorf("Expected callee summaries in embedding text, got %q", text)
+	}
+
+	AttachCalleeSummaries(units, GraphContext{Callees: 5, SummaryChars: 10})
+	if got := wrapper.CalleeSummaries[0]; got != "validate: Validate c..." {
+		t.Errorf("Expected summary truncated to 10 chars, got %q", got)
+	}
+	if len(wrapper.CalleeSummaries) != 3 {
+		t.Errorf("Expected 3 documented callees, got %v", wrapper.CalleeSummaries)
+	}
+}
+
+func TestBuildStopSymbols(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"app.py":     "from helpers import log_debug, load\n\ndef run():\n    log_debug()\n    load()\n",
+		"helpers.py": "def log_debug():\n    pass\n\ndef load():\n    pass\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	opts := BuildOptions{StopSymbols: callgraph.StopSymbols{"python": {"log_*"}}}
+	if _, err := BuildIndexWithStats(root, &mockProvider{}, opts); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	units, err := LoadUnits(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range units {
+		switch u.Name {
+		case "run":
+			if len(u.Calls) != 1 || u.Calls[0] != "helpers.py:load" {
+				t.Errorf("run calls = %q, want only helpers.py:load", u.Calls)
+			}
+		case "log_debug":
+			if len(u.CalledBy) != 0 {
+				t.Errorf("log_debug called by = %q, want no callers", u.CalledBy)
+			}
+		case "load":
+			if len(u.CalledBy) != 1 {
+				t.Errorf("load called by = %q, want run", u.CalledBy)
+			}
+		}
+	}
+}
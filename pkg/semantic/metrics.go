@@ -0,0 +1,226 @@
+package semantic
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// metricsFile holds one MetricsSnapshot per line, oldest first. It sits in
+// the semantic cache directory rather than a model directory so the history
+// survives model switches.
+const metricsFile = "metrics.jsonl"
+
+// maxMetricsHistory is the number of snapshots kept; older ones are dropped
+const maxMetricsHistory = 200
+
+// MetricsSnapshot records code-health metrics of one index build
+type MetricsSnapshot struct {
+	Timestamp time.Time `json:"timestamp"`
+	Model     string    `json:"model,omitempty"`
+	// Units is the number of indexed units; ByType and ByLanguage break it down
+	Units      int            `json:"units"`
+	Files      int            `json:"files"`
+	ByType     map[string]int `json:"by_type,omitempty"`
+	ByLanguage map[string]int `json:"by_language,omitempty"`
+	TestUnits  int            `json:"test_units"`
+	// Complexity summarizes the cyclomatic complexity of functions and methods
+	Complexity ComplexityDistribution `json:"complexity"`
+	// DeadCode counts functions and methods nothing in the project calls
+	// that are not tests, entry points or exported Go identifiers
+	DeadCode int `json:"dead_code"`
+}
+
+// ComplexityDistribution buckets functions and methods by cyclomatic
+// complexity: low 1-5, moderate 6-10, high 11-20, very high above 20
+type ComplexityDistribution struct {
+	Measured int     `json:"measured"`
+	Low      int     `json:"low"`
+	Moderate int     `json:"moderate"`
+	High     int     `json:"high"`
+	VeryHigh int     `json:"very_high"`
+	Max      int     `json:"max"`
+	Mean     float64 `json:"mean"`
+}
+
+// entryPoints are function names called by the runtime rather than by
+// project code, so an empty caller list doesn't make them dead
+var entryPoints = map[string]bool{
+	"main":        true,
+	"init":        true,
+	"__init__":    true,
+	"__main__":    true,
+	"constructor": true,
+	"initialize":  true,
+}
+
+// ComputeMetrics returns the metrics of a set of extracted units. Body
+// chunks and todo units are not counted.
+func ComputeMetrics(units []*CodeUnit) MetricsSnapshot {
+	m := MetricsSnapshot{
+		Timestamp:  time.Now(),
+		ByType:     make(map[string]int),
+		ByLanguage: make(map[string]int),
+	}
+
+	files := make(map[string]bool)
+	total := 0
+	for _, unit := range units {
+		if derivedUnit(unit.Type) {
+			continue
+		}
+		m.Units++
+		files[unit.FilePath] = true
+		m.ByType[unit.Type]++
+		if unit.Language != "" {
+			m.ByLanguage[unit.Language]++
+		}
+		if unit.IsTest {
+			m.TestUnits++
+		}
+
+		if unit.Type != "function" && unit.Type != "method" {
+			continue
+		}
+		if complexity, ok := UnitComplexity(unit); ok {
+			m.Complexity.add(complexity)
+			total += complexity
+		}
+		if IsDeadCode(unit) {
+			m.DeadCode++
+		}
+	}
+	m.Files = len(files)
+	if m.Complexity.Measured > 0 {
+		m.Complexity.Mean = float64(total) / float64(m.Complexity.Measured)
+	}
+
+	return m
+}
+
+// add counts one function of the given complexity
+func (d *ComplexityDistribution) add(complexity int) {
+	d.Measured++
+	switch {
+	case complexity <= 5:
+		d.Low++
+	case complexity <= 10:
+		d.Moderate++
+	case complexity <= 20:
+		d.High++
+	default:
+		d.VeryHigh++
+	}
+	if complexity > d.Max {
+		d.Max = complexity
+	}
+}
+
+// UnitComplexity reads the cyclomatic complexity from a unit's CFG summary.
+// It returns false for units without one, such as classes.
+func UnitComplexity(unit *CodeUnit) (int, bool) {
+	var complexity int
+	if _, err := fmt.Sscanf(unit.CFGSummary, "complexity:%d", &complexity); err != nil {
+		return 0, false
+	}
+	return complexity, true
+}
+
+// IsDeadCode reports whether a function or method looks unused: nothing in
+// the project calls it and it is not a test, an entry point or an exported
+// Go identifier that other modules may call
+func IsDeadCode(unit *CodeUnit) bool {
+	if unit.Type != "function" && unit.Type != "method" {
+		return false
+	}
+	if len(unit.CalledBy) > 0 || unit.IsTest || entryPoints[unit.Name] {
+		return false
+	}
+	if unit.Language == "go" {
+		name := unit.Name
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+		for _, r := range name {
+			return !unicode.IsUpper(r)
+		}
+	}
+	return true
+}
+
+// RecordMetrics appends a snapshot to the metrics history of rootDir,
+// dropping the oldest snapshots beyond maxMetricsHistory
+func RecordMetrics(rootDir string, snapshot MetricsSnapshot) error {
+	return recordMetrics(semanticCacheDir(rootDir), snapshot)
+}
+
+func recordMetrics(cacheDir string, snapshot MetricsSnapshot) error {
+	history, err := loadMetrics(cacheDir)
+	if err != nil {
+		return err
+	}
+	history = append(history, snapshot)
+	if len(history) > maxMetricsHistory {
+		history = history[len(history)-maxMetricsHistory:]
+	}
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	return replaceFile(filepath.Join(cacheDir, metricsFile), func(tmp string) error {
+		file, err := os.Create(tmp)
+		if err != nil {
+			return fmt.Errorf("creating metrics file: %w", err)
+		}
+		defer file.Close()
+
+		enc := json.NewEncoder(file)
+		for _, s := range history {
+			if err := enc.Encode(s); err != nil {
+				return fmt.Errorf("encoding metrics: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// LoadMetrics returns the metrics history of rootDir, oldest first. A
+// project that was never indexed has an empty history.
+func LoadMetrics(rootDir string) ([]MetricsSnapshot, error) {
+	return loadMetrics(semanticCacheDir(rootDir))
+}
+
+func loadMetrics(cacheDir string) ([]MetricsSnapshot, error) {
+	file, err := os.Open(filepath.Join(cacheDir, metricsFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening metrics file: %w", err)
+	}
+	defer file.Close()
+
+	var history []MetricsSnapshot
+	sc := bufio.NewScanner(file)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		if len(strings.TrimSpace(sc.Text())) == 0 {
+			continue
+		}
+		var s MetricsSnapshot
+		if err := json.Unmarshal(sc.Bytes(), &s); err != nil {
+			// Skip a line cut short by an interrupted write
+			continue
+		}
+		history = append(history, s)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading metrics file: %w", err)
+	}
+	return history, nil
+}
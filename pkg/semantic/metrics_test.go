@@ -0,0 +1,91 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestComputeMetrics(t *testing.T) {
+	units := []*CodeUnit{
+		{Name: "main", Type: "function", Language: "go", FilePath: "main.go", CFGSummary: "complexity:1, blocks:1"},
+		{Name: "parse", Type: "function", Language: "go", FilePath: "parse.go", CFGSummary: "complexity:8, blocks:6", CalledBy: []string{"main.go:main"}},
+		{Name: "unused", Type: "function", Language: "go", FilePath: "parse.go", CFGSummary: "complexity:25, blocks:20"},
+		{Name: "Exported", Type: "function", Language: "go", FilePath: "parse.go", CFGSummary: "complexity:12, blocks:9"},
+		{Name: "Parser.reset", Type: "method", Language: "go", FilePath: "parse.go"},
+		{Name: "helper", Type: "function", Language: "python", FilePath: "util.py", CFGSummary: "complexity:3, blocks:2"},
+		{Name: "TestParse", Type: "function", Language: "go", FilePath: "parse_test.go", IsTest: true},
+		{Name: "Parser", Type: "class", Language: "go", FilePath: "parse.go"},
+	}
+
+	m := ComputeMetrics(units)
+	if m.Units != 8 || m.Files != 4 || m.TestUnits != 1 {
+		t.Errorf("expected 8 units in 4 files with 1 test unit, got %+v", m)
+	}
+	if m.ByType["function"] != 6 || m.ByType["method"] != 1 || m.ByLanguage["python"] != 1 {
+		t.Errorf("unexpected breakdown: %v, %v", m.ByType, m.ByLanguage)
+	}
+
+	want := ComplexityDistribution{Measured: 5, Low: 2, Moderate: 1, High: 1, VeryHigh: 1, Max: 25, Mean: 49.0 / 5}
+	if m.Complexity != want {
+		t.Errorf("Complexity = %+v, want %+v", m.Complexity, want)
+	}
+
+	// unused, Parser.reset and helper: main is an entry point, parse is
+	// called, Exported may be called by other modules, TestParse is a test
+	if m.DeadCode != 3 {
+		t.Errorf("DeadCode = %d, want 3", m.DeadCode)
+	}
+}
+
+func TestRecordMetrics(t *testing.T) {
+	root := t.TempDir()
+
+	history, err := LoadMetrics(root)
+	if err != nil || len(history) != 0 {
+		t.Fatalf("expected an empty history, got %v, %v", history, err)
+	}
+
+	for i := 1; i <= maxMetricsHistory+2; i++ {
+		if err := RecordMetrics(root, MetricsSnapshot{Units: i}); err != nil {
+			t.Fatalf("RecordMetrics failed: %v", err)
+		}
+	}
+	history, err = LoadMetrics(root)
+	if err != nil {
+		t.Fatalf("LoadMetrics failed: %v", err)
+	}
+	if len(history) != maxMetricsHistory || history[0].Units != 3 || history[len(history)-1].Units != maxMetricsHistory+2 {
+		t.Errorf("expected the latest %d snapshots, got %d from %d", maxMetricsHistory, len(history), history[0].Units)
+	}
+
+	// A truncated last line is skipped
+	path := filepath.Join(semanticCacheDir(root), metricsFile)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"units":`)
+	f.Close()
+	if history, err = LoadMetrics(root); err != nil || len(history) != maxMetricsHistory {
+		t.Errorf("expected the truncated line to be skipped, got %d, %v", len(history), err)
+	}
+}
+
+func TestSaveRecordsMetrics(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "app.py"), []byte("def greet(name):\n    pass\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	buildAndSave(t, root, &mockProvider{})
+	buildAndSave(t, root, &mockProvider{})
+
+	history, err := LoadMetrics(root)
+	if err != nil {
+		t.Fatalf("LoadMetrics failed: %v", err)
+	}
+	if len(history) != 2 || history[1].Units != 1 || history[1].Model != "mock-model" {
+		t.Errorf("expected a snapshot per build, got %+v", history)
+	}
+}
@@ -0,0 +1,268 @@
+package semantic
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/l3aro/go-context-query/pkg/index"
+)
+
+// Each embedding model gets its own index directory under
+// .gcq/cache/semantic/models, named after the model and its dimension. The
+// "active" file in .gcq/cache/semantic names the directory searches use.
+// Building with a new model writes a new directory while the old index keeps
+// serving searches, then switches "active" with an atomic rename, so a model
+// change never leaves a half-built or mixed-dimension index in use.
+//
+// Indexes written before model directories existed live directly in
+// .gcq/cache/semantic; they stay readable and are moved into a model
+// directory by the next build.
+const (
+	modelsDir    = "models"
+	activeFile   = "active"
+	indexFile    = "index.msgpack"
+	metadataFile = "metadata.json"
+)
+
+// semanticCacheDir returns the directory holding the semantic indexes of rootDir
+func semanticCacheDir(rootDir string) string {
+	return filepath.Join(rootDir, ".gcq", "cache", "semantic")
+}
+
+// ModelDirName returns the directory name used for an index built with model
+// at the given dimension, e.g. "nomic-embed-text-768"
+func ModelDirName(model string, dimension int) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(model) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('-')
+		}
+	}
+	name := strings.Trim(sb.String(), "-.")
+	if name == "" {
+		name = "default"
+	}
+	return fmt.Sprintf("%s-%d", name, dimension)
+}
+
+// ActiveIndexDir returns the directory holding the active semantic index of
+// rootDir: the model directory named by the active file, or the legacy
+// location when no model directory has been activated yet
+func ActiveIndexDir(rootDir string) string {
+	return activeIndexDir(semanticCacheDir(rootDir))
+}
+
+func activeIndexDir(cacheDir string) string {
+	data, err := os.ReadFile(filepath.Join(cacheDir, activeFile))
+	if err != nil {
+		return cacheDir
+	}
+	name := strings.TrimSpace(string(data))
+	if name == "" || name != filepath.Base(name) {
+		return cacheDir
+	}
+	return filepath.Join(cacheDir, modelsDir, name)
+}
+
+// setActive points the active file at the model directory name. The file is
+// replaced with a rename so readers see either the old or the new index.
+func setActive(cacheDir, name string) error {
+	path := filepath.Join(cacheDir, activeFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(name+"\n"), 0600); err != nil {
+		return fmt.Errorf("writing active index: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("switching active index: %w", err)
+	}
+	return nil
+}
+
+// migrateLegacyIndex moves an index saved before model directories existed
+// into its own model directory so it is kept after the switch. It is a
+// no-op when there is no legacy index.
+func migrateLegacyIndex(cacheDir string) error {
+	metadata, err := loadMetadata(filepath.Join(cacheDir, metadataFile))
+	if err != nil {
+		return nil
+	}
+	dir := filepath.Join(cacheDir, modelsDir, ModelDirName(metadata.GetModel(), metadata.Dimension))
+	if _, err := os.Stat(dir); err == nil {
+		// A newer build for the same model already replaced it
+		for _, name := range []string{indexFile, metadataFile, hnswFile} {
+			os.Remove(filepath.Join(cacheDir, name))
+		}
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating model directory: %w", err)
+	}
+	for _, name := range []string{indexFile, metadataFile, hnswFile} {
+		err := os.Rename(filepath.Join(cacheDir, name), filepath.Join(dir, name))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("moving legacy %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// replaceFile writes a file through write at path+".tmp" and renames it into
+// place, so a concurrent reader never sees a partial file
+func replaceFile(path string, write func(tmp string) error) error {
+	tmp := path + ".tmp"
+	if err := write(tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// ModelSwitch describes a build whose embedding model differs from the one
+// that built the active index
+type ModelSwitch struct {
+	// FromModel and FromDimension describe the active index
+	FromModel     string
+	FromDimension int
+	// ToModel is the model of the new build
+	ToModel string
+}
+
+// DetectModelSwitch reports whether building rootDir with model would
+// replace an active index built with a different model
+func DetectModelSwitch(rootDir, model string) (ModelSwitch, bool) {
+	metadata, err := loadMetadata(filepath.Join(ActiveIndexDir(rootDir), metadataFile))
+	if err != nil || metadata.WarmModel == "" && metadata.Model == "" {
+		return ModelSwitch{}, false
+	}
+	from := metadata.WarmModel
+	if from == "" {
+		from = metadata.Model
+	}
+	if from == model {
+		return ModelSwitch{}, false
+	}
+	return ModelSwitch{FromModel: from, FromDimension: metadata.Dimension, ToModel: model}, true
+}
+
+// ModelIndex is a model-scoped index kept for a project
+type ModelIndex struct {
+	// Name is the model directory name
+	Name string `json:"name"`
+	// Active is true for the index searches use
+	Active bool `json:"active"`
+	// Metadata describes the index
+	Metadata *IndexMetadata `json:"metadata"`
+}
+
+// ListModelIndexes returns the model-scoped indexes kept for rootDir, sorted
+// by name. Directories without readable metadata (such as a build still in
+// progress) are skipped.
+func ListModelIndexes(rootDir string) ([]ModelIndex, error) {
+	cacheDir := semanticCacheDir(rootDir)
+	entries, err := os.ReadDir(filepath.Join(cacheDir, modelsDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading model indexes: %w", err)
+	}
+
+	active := activeIndexDir(cacheDir)
+	var indexes []ModelIndex
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(cacheDir, modelsDir, entry.Name())
+		metadata, err := loadMetadata(filepath.Join(dir, metadataFile))
+		if err != nil {
+			continue
+		}
+		metadata.Dir = dir
+		indexes = append(indexes, ModelIndex{Name: entry.Name(), Active: dir == active, Metadata: metadata})
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i].Name < indexes[j].Name })
+	return indexes, nil
+}
+
+// formatError adds the way out to an index this version can't read
+func formatError(dir string, err error) error {
+	if !errors.Is(err, index.ErrFormat) {
+		return err
+	}
+	return fmt.Errorf("the index in %s was written by another version of gcq and can't be read; rebuild it with gcq warm: %w", dir, err)
+}
+
+// LoadIndexForModel loads the newest kept index of rootDir that was built
+// for searching with model, falling back to the active index. It lets a
+// search keep working with the model it was configured for while switching
+// models back and forth, without a rebuild.
+func LoadIndexForModel(rootDir, model string) (*index.VectorIndex, *IndexMetadata, error) {
+	return loadIndexDir(indexDirForModel(rootDir, model))
+}
+
+// LoadMetadataForModel loads the metadata of the index LoadIndexForModel
+// would load, without the index, such as to check its Storage first
+func LoadMetadataForModel(rootDir, model string) (*IndexMetadata, error) {
+	dir := indexDirForModel(rootDir, model)
+	metadata, err := loadMetadata(filepath.Join(dir, metadataFile))
+	if err != nil {
+		return nil, formatError(dir, fmt.Errorf("loading metadata: %w", err))
+	}
+	metadata.Dir = dir
+	return metadata, nil
+}
+
+// indexDirForModel returns the directory of the newest kept index of rootDir
+// built for searching with model, or the active index directory
+func indexDirForModel(rootDir, model string) string {
+	if model != "" {
+		metadata, err := loadMetadata(filepath.Join(ActiveIndexDir(rootDir), metadataFile))
+		if err != nil || metadata.GetModel() != model {
+			indexes, _ := ListModelIndexes(rootDir)
+			var best *IndexMetadata
+			for _, m := range indexes {
+				if m.Metadata.GetModel() == model && (best == nil || m.Metadata.Timestamp.After(best.Timestamp)) {
+					best = m.Metadata
+				}
+			}
+			if best != nil {
+				return best.Dir
+			}
+		}
+	}
+	return ActiveIndexDir(rootDir)
+}
+
+// loadIndexDir loads the index and metadata saved in dir. An index this
+// version can't read is reported as index.ErrFormat with what to do.
+func loadIndexDir(dir string) (*index.VectorIndex, *IndexMetadata, error) {
+	metadata, err := loadMetadata(filepath.Join(dir, metadataFile))
+	if err != nil {
+		return nil, nil, formatError(dir, fmt.Errorf("loading metadata: %w", err))
+	}
+
+	vecIndex := index.NewVectorIndex(0)
+	path, load := filepath.Join(dir, indexFile), vecIndex.Load
+	if metadata.Storage == StorageSQLite {
+		path, load = filepath.Join(dir, sqliteFile), vecIndex.LoadSQLite
+	}
+	if err := load(path); err != nil {
+		return nil, nil, formatError(dir, fmt.Errorf("loading index: %w", err))
+	}
+	metadata.Dir = dir
+
+	return vecIndex, metadata, nil
+}
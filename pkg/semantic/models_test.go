@@ -0,0 +1,298 @@
+package semantic
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/l3aro/go-context-query/pkg/embed"
+	"github.com/l3aro/go-context-query/pkg/index"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+func TestModelDirName(t *testing.T) {
+	tests := []struct {
+		model     string
+		dimension int
+		want      string
+	}{
+		{"nomic-embed-text", 768, "nomic-embed-text-768"},
+		{"sentence-transformers/all-MiniLM-L6-v2", 384, "sentence-transformers-all-minilm-l6-v2-384"},
+		{"embeddinggemma:300m", 768, "embeddinggemma-300m-768"},
+		{"", 3, "default-3"},
+		{"../..", 3, "default-3"},
+	}
+	for _, tt := range tests {
+		if got := ModelDirName(tt.model, tt.dimension); got != tt.want {
+			t.Errorf("ModelDirName(%q, %d) = %q, want %q", tt.model, tt.dimension, got, tt.want)
+		}
+	}
+}
+
+// buildAndSave indexes root with provider and saves the result
+func buildAndSave(t *testing.T, root string, provider embed.Provider) *Builder {
+	t.Helper()
+	builder, err := NewBuilder(root, provider)
+	if err != nil {
+		t.Fatalf("NewBuilder failed: %v", err)
+	}
+	if _, _, err := builder.Build(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if err := builder.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	return builder
+}
+
+func TestModelSwitchKeepsOldIndex(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "app.py"), []byte("def greet(name):\n    pass\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	buildAndSave(t, root, &mockProvider{})
+	if got, want := ActiveIndexDir(root), filepath.Join(semanticCacheDir(root), modelsDir, "mock-model-3"); got != want {
+		t.Fatalf("ActiveIndexDir() = %s, want %s", got, want)
+	}
+
+	if _, switching := DetectModelSwitch(root, "mock-model"); switching {
+		t.Error("Expected no switch for the same model")
+	}
+	modelSwitch, switching := DetectModelSwitch(root, "custom-dim-model")
+	if !switching || modelSwitch.FromModel != "mock-model" || modelSwitch.FromDimension != 3 {
+		t.Fatalf("DetectModelSwitch() = %+v, %v", modelSwitch, switching)
+	}
+
+	// The new model is built but not saved yet: searches still get the old index
+	builder, err := NewBuilder(root, &mockProviderCustomEmbeddings{dimension: 5})
+	if err != nil {
+		t.Fatalf("NewBuilder failed: %v", err)
+	}
+	if _, _, err := builder.Build(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	vecIndex, _, err := LoadIndex(root)
+	if err != nil || vecIndex.Dimension() != 3 {
+		t.Fatalf("Expected the old index during the build, got %v, %v", vecIndex, err)
+	}
+
+	if err := builder.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	vecIndex, metadata, err := LoadIndex(root)
+	if err != nil || vecIndex.Dimension() != 5 || metadata.GetModel() != "custom-dim-model" {
+		t.Fatalf("Expected the new index after Save, got %v, %+v, %v", vecIndex, metadata, err)
+	}
+	if metadata.Dir != ActiveIndexDir(root) {
+		t.Errorf("metadata.Dir = %s, want %s", metadata.Dir, ActiveIndexDir(root))
+	}
+
+	indexes, err := ListModelIndexes(root)
+	if err != nil || len(indexes) != 2 {
+		t.Fatalf("ListModelIndexes() = %+v, %v", indexes, err)
+	}
+	if indexes[0].Name != "custom-dim-model-5" || !indexes[0].Active || indexes[1].Active {
+		t.Errorf("Unexpected model indexes: %+v", indexes)
+	}
+
+	old, oldMeta, err := LoadIndexForModel(root, "mock-model")
+	if err != nil || old.Dimension() != 3 || oldMeta.GetModel() != "mock-model" {
+		t.Errorf("LoadIndexForModel(mock-model) = %v, %+v, %v", old, oldMeta, err)
+	}
+	current, _, err := LoadIndexForModel(root, "unknown-model")
+	if err != nil || current.Dimension() != 5 {
+		t.Errorf("Expected the active index for an unknown model, got %v, %v", current, err)
+	}
+}
+
+func TestLegacyIndexIsKept(t *testing.T) {
+	root := t.TempDir()
+	cacheDir := semanticCacheDir(root)
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "app.py"), []byte("def greet(name):\n    pass\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// An index saved before model directories existed
+	legacy := index.NewVectorIndex(2)
+	legacy.Add("legacy", []float32{1, 0}, types.EmbeddingUnit{})
+	if err := legacy.Save(filepath.Join(cacheDir, indexFile)); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveMetadata(filepath.Join(cacheDir, metadataFile), IndexMetadata{
+		Timestamp: time.Now(), Count: 1, Dimension: 2, WarmModel: "old-model", SearchModel: "old-model",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if vecIndex, _, err := LoadIndex(root); err != nil || vecIndex.Count() != 1 {
+		t.Fatalf("Expected the legacy index to load, got %v, %v", vecIndex, err)
+	}
+
+	buildAndSave(t, root, &mockProvider{})
+
+	if _, err := os.Stat(filepath.Join(cacheDir, indexFile)); !os.IsNotExist(err) {
+		t.Errorf("Expected the legacy index to be moved, stat err = %v", err)
+	}
+	kept, metadata, err := loadIndexDir(filepath.Join(cacheDir, modelsDir, "old-model-2"))
+	if err != nil || kept.Count() != 1 || metadata.GetModel() != "old-model" {
+		t.Errorf("Expected the legacy index under its model directory, got %v, %+v, %v", kept, metadata, err)
+	}
+	if vecIndex, _, err := LoadIndex(root); err != nil || vecIndex.Dimension() != 3 {
+		t.Errorf("Expected the new index to be active, got %v, %v", vecIndex, err)
+	}
+}
+
+func TestNewerIndexFormatIsRebuilt(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "app.py"), []byte("def greet(name):\n    pass\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	provider := &mockProvider{}
+	if _, err := BuildIndexWithStats(root, provider, BuildOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// An index written by a later version
+	dir := ActiveIndexDir(root)
+	metadata, err := loadMetadata(filepath.Join(dir, metadataFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Version != index.FormatVersion {
+		t.Errorf("saved format = %d, want %d", metadata.Version, index.FormatVersion)
+	}
+	metadata.Version = index.FormatVersion + 1
+	if err := saveMetadata(filepath.Join(dir, metadataFile), *metadata); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = LoadIndex(root)
+	if !errors.Is(err, index.ErrFormat) || !strings.Contains(err.Error(), "gcq warm") {
+		t.Fatalf("loading a newer index: err = %v, want ErrFormat saying how to rebuild", err)
+	}
+
+	if _, err := BuildIndexWithStats(root, provider, BuildOptions{}); err != nil {
+		t.Fatalf("rebuilding: %v", err)
+	}
+	if _, metadata, err := LoadIndex(root); err != nil || metadata.Version != index.FormatVersion {
+		t.Errorf("rebuilt index: %+v, %v", metadata, err)
+	}
+}
+
+func TestSaveWritesTextIndex(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "app.py"), []byte("def greet_user(name):\n    \"\"\"Say hello.\"\"\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	buildAndSave(t, root, &mockProvider{})
+	_, metadata, err := LoadIndex(root)
+	if err != nil {
+		t.Fatalf("LoadIndex failed: %v", err)
+	}
+
+	textIndex := LoadTextIndex(metadata.Dir)
+	if textIndex == nil {
+		t.Fatal("expected a text index next to the semantic index")
+	}
+	results := textIndex.Search("greet", 5)
+	if len(results) == 0 || results[0].Doc.Name != "greet_user" {
+		t.Errorf("expected greet_user for 'greet', got %+v", results)
+	}
+
+	if LoadTextIndex(t.TempDir()) != nil {
+		t.Error("expected nil for a directory without a text index")
+	}
+}
+
+func TestSaveSQLiteStorage(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "app.py"), []byte("def greet_user(name):\n    \"\"\"Say hello.\"\"\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	provider := &mockProvider{}
+	sqlite := BuildOptions{Backend: index.BackendOptions{Kind: index.BackendSQLite}}
+	if _, err := BuildIndexWithStats(root, provider, sqlite); err != nil {
+		t.Fatalf("sqlite build: %v", err)
+	}
+
+	metadata, err := LoadMetadataForModel(root, "mock-model")
+	if err != nil {
+		t.Fatalf("LoadMetadataForModel failed: %v", err)
+	}
+	if metadata.Storage != StorageSQLite {
+		t.Fatalf("Storage = %q, want %q", metadata.Storage, StorageSQLite)
+	}
+	for name, want := range map[string]bool{sqliteFile: true, indexFile: false, textIndexFile: false} {
+		if _, err := os.Stat(filepath.Join(metadata.Dir, name)); (err == nil) != want {
+			t.Errorf("%s exists = %v, want %v", name, err == nil, want)
+		}
+	}
+
+	store, err := OpenSQLiteIndex(metadata.Dir)
+	if err != nil {
+		t.Fatalf("OpenSQLiteIndex failed: %v", err)
+	}
+	defer store.Close()
+	if results, err := store.SearchText("greet", 5); err != nil || len(results) != 1 || results[0].Metadata.Unit.Name != "greet_user" {
+		t.Errorf("SearchText(greet) = %+v, %v", results, err)
+	}
+
+	// Builds and tools load the database whole, and an update can switch
+	// the storage back without re-embedding
+	units, err := LoadUnits(root)
+	if err != nil || len(units) != 1 {
+		t.Fatalf("LoadUnits = %d units, %v", len(units), err)
+	}
+	stats, err := BuildIndexWithStats(root, provider, BuildOptions{})
+	if err != nil {
+		t.Fatalf("msgpack build: %v", err)
+	}
+	if stats.Reused != 1 {
+		t.Errorf("msgpack build stats = %+v, want the unit reused", stats)
+	}
+	if _, err := os.Stat(filepath.Join(metadata.Dir, sqliteFile)); !os.IsNotExist(err) {
+		t.Errorf("the database was kept after switching back: %v", err)
+	}
+}
+
+func TestSaveQuantized(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "app.py"), []byte("def greet_user(name):\n    \"\"\"Say hello.\"\"\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	provider := &mockProvider{}
+	quantized := BuildOptions{Backend: index.BackendOptions{Quantization: index.QuantizationInt8}}
+	if _, err := BuildIndexWithStats(root, provider, quantized); err != nil {
+		t.Fatalf("quantized build: %v", err)
+	}
+	vecIndex, metadata, err := LoadIndex(root)
+	if err != nil {
+		t.Fatalf("LoadIndex failed: %v", err)
+	}
+	if metadata.Quantization != index.QuantizationInt8 || vecIndex.Quantization() != index.QuantizationInt8 || vecIndex.Count() != 1 {
+		t.Fatalf("loaded %d units, quantization %q in metadata and %q in the index", vecIndex.Count(), metadata.Quantization, vecIndex.Quantization())
+	}
+
+	// Turning quantization off rewrites the vectors without re-embedding
+	stats, err := BuildIndexWithStats(root, provider, BuildOptions{})
+	if err != nil {
+		t.Fatalf("float build: %v", err)
+	}
+	if stats.Reused != 1 {
+		t.Errorf("float build stats = %+v, want the unit reused", stats)
+	}
+	if vecIndex, metadata, err = LoadIndex(root); err != nil || metadata.Quantization != "" || vecIndex.Quantization() != index.QuantizationNone {
+		t.Errorf("after turning quantization off: %q in metadata, %q in the index, %v", metadata.Quantization, vecIndex.Quantization(), err)
+	}
+
+	if _, err := BuildIndexWithStats(root, provider, BuildOptions{Backend: index.BackendOptions{Quantization: "pq"}}); err == nil {
+		t.Error("build accepted an unknown quantization")
+	}
+}
@@ -4,19 +4,27 @@
 package semantic
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/l3aro/go-context-query/internal/scanner"
 	"github.com/l3aro/go-context-query/pkg/callgraph"
 	"github.com/l3aro/go-context-query/pkg/cfg"
+	"github.com/l3aro/go-context-query/pkg/deps"
 	"github.com/l3aro/go-context-query/pkg/dfg"
 	"github.com/l3aro/go-context-query/pkg/embed"
 	"github.com/l3aro/go-context-query/pkg/extractor"
+	"github.com/l3aro/go-context-query/pkg/importer"
 	"github.com/l3aro/go-context-query/pkg/index"
 	"github.com/l3aro/go-context-query/pkg/types"
 
@@ -24,36 +32,33 @@ import (
 )
 
 // CodeUnit represents a single unit of code ready for embedding.
-// It combines L1 (local) and L2 (cross-file) data.
-type CodeUnit struct {
-	// Name is the name of the function/method/class
-	Name string `json:"name"`
-	// Type is the type of unit (function, method, class)
-	Type string `json:"type"`
-	// FilePath is the path to the file containing this unit
-	FilePath string `json:"file_path"`
-	// LineNumber is the line where this unit is defined
-	LineNumber int `json:"line_number"`
-	// Signature is the function signature
-	Signature string `json:"signature"`
-	// Docstring is the docstring/comment
-	Docstring string `json:"docstring"`
-	// Calls is the list of functions this unit calls (forward)
-	Calls []string `json:"calls"`
-	// CalledBy is the list of functions that call this unit (backward)
-	CalledBy []string `json:"called_by"`
-	// CFGSummary is an optional control flow graph summary (complexity, blocks)
-	CFGSummary string `json:"cfg_summary,omitempty"`
-	// DFGSummary is an optional data flow graph summary (variables, edges)
-	DFGSummary string `json:"dfg_summary,omitempty"`
-	// Dependencies is a list of significant imported modules/packages
-	Dependencies []string `json:"dependencies,omitempty"`
+// It is defined in the types package so it can be persisted in index payloads.
+type CodeUnit = types.CodeUnit
+
+// EmbeddingLimits caps how much of a unit's context goes into its embedding.
+// A zero value means unlimited.
+type EmbeddingLimits struct {
+	// Dependencies is the most external dependencies attached to a unit
+	Dependencies int
+	// CallListChars is the longest calls or callers list in embedding text
+	CallListChars int
+}
+
+// DefaultEmbeddingLimits returns the limits used when none are configured
+func DefaultEmbeddingLimits() EmbeddingLimits {
+	return EmbeddingLimits{Dependencies: 5, CallListChars: 200}
 }
 
 // EmbeddingText builds rich text for embedding from a CodeUnit.
 // It combines L1 (signature + docstring) and L2 (calls + called_by) data.
 // This follows the pattern from llm-tldr/tldr/semantic.py build_embedding_text().
 func EmbeddingText(unit *CodeUnit) string {
+	return EmbeddingTextWithLimits(unit, DefaultEmbeddingLimits())
+}
+
+// EmbeddingTextWithLimits builds embedding text like EmbeddingText, truncating
+// the calls and callers lists to limits.CallListChars
+func EmbeddingTextWithLimits(unit *CodeUnit, limits EmbeddingLimits) string {
 	var parts []string
 
 	// Add name and type for context at the beginning
@@ -74,17 +79,22 @@ func EmbeddingText(unit *CodeUnit) string {
 	// L2: Call graph (forward - callees)
 	if len(unit.Calls) > 0 {
 		callsStr := strings.Join(unit.Calls, ", ")
-		if len(callsStr) > 200 {
-			callsStr = callsStr[:200] + "..."
+		if limits.CallListChars > 0 && len(callsStr) > limits.CallListChars {
+			callsStr = types.TruncateUTF8(callsStr, limits.CallListChars) + "..."
 		}
 		parts = append(parts, fmt.Sprintf("Calls: %s", callsStr))
 	}
 
+	// L2: What the callees do (graph-augmented, optional)
+	if len(unit.CalleeSummaries) > 0 {
+		parts = append(parts, fmt.Sprintf("Callee summaries: %s", strings.Join(unit.CalleeSummaries, "; ")))
+	}
+
 	// L2: Call graph (backward - callers)
 	if len(unit.CalledBy) > 0 {
 		callersStr := strings.Join(unit.CalledBy, ", ")
-		if len(callersStr) > 200 {
-			callersStr = callersStr[:200] + "..."
+		if limits.CallListChars > 0 && len(callersStr) > limits.CallListChars {
+			callersStr = types.TruncateUTF8(callersStr, limits.CallListChars) + "..."
 		}
 		parts = append(parts, fmt.Sprintf("Called by: %s", callersStr))
 	}
@@ -105,11 +115,28 @@ func EmbeddingText(unit *CodeUnit) string {
 		parts = append(parts, fmt.Sprintf("Data flow: %s", unit.DFGSummary))
 	}
 
+	// Enricher annotations (optional)
+	if len(unit.Metadata) > 0 {
+		parts = append(parts, fmt.Sprintf("Metadata: %s", metadataText(unit.Metadata)))
+	}
+
+	// Body chunks, TODOs and doc sections: the source lines themselves
+	if unit.Type == UnitTypeTodo && unit.Code != "" {
+		parts = append(parts, fmt.Sprintf("Context:\n%s", unit.Code))
+	} else if unit.Type == UnitTypeDoc && unit.Code != "" {
+		parts = append(parts, fmt.Sprintf("Text:\n%s", unit.Code))
+	} else if unit.Code != "" {
+		parts = append(parts, fmt.Sprintf("Lines %d-%d:\n%s", unit.LineNumber, unit.EndLine, unit.Code))
+	}
+
 	return strings.Join(parts, "\n")
 }
 
 // IndexMetadata holds metadata about the semantic index
 type IndexMetadata struct {
+	// Version is the index.FormatVersion the index was written with; it
+	// is 0 for indexes written before versions were recorded
+	Version int `json:"version"`
 	// Model is the embedding model used (legacy field, use WarmModel/SearchModel)
 	Model string `json:"model,omitempty"`
 	// Timestamp is when the index was created
@@ -128,6 +155,27 @@ type IndexMetadata struct {
 	SearchProvider string `json:"searchProvider,omitempty"`
 	// SearchModel is the model used for search embeddings
 	SearchModel string `json:"searchModel,omitempty"`
+	// Remaining is the number of units a budgeted build left out
+	Remaining int `json:"remaining,omitempty"`
+	// Degradations are the features the build skipped or cut short
+	Degradations types.Degradations `json:"degradations,omitempty"`
+	// Files are the content hashes of the indexed files, relative to the
+	// project root with forward slashes; a rebuild skips files whose hash
+	// is unchanged. It is empty rather than missing when every file still
+	// has to be extracted again.
+	Files map[string]string `json:"files"`
+	// Settings is a hash of the builder settings that shape units and
+	// embedding text; a rebuild with other settings indexes every file
+	Settings string `json:"settings,omitempty"`
+	// Storage is StorageSQLite when the index was saved as a SQLite
+	// database, or empty for the msgpack files
+	Storage string `json:"storage,omitempty"`
+	// Quantization is index.QuantizationInt8 when the vectors were saved
+	// quantized, or empty for float32 vectors
+	Quantization string `json:"quantization,omitempty"`
+
+	// Dir is the directory the index was loaded from; it is not saved
+	Dir string `json:"-"`
 }
 
 // GetProvider returns the effective provider (searches new fields first, falls back to legacy)
@@ -188,10 +236,77 @@ type Builder struct {
 	embedProviderSearch embed.Provider
 	// vectorIndex stores the vector index
 	vectorIndex *index.VectorIndex
+	// textIndex is the BM25 keyword index over the same units
+	textIndex *index.TextIndex
 	// codeUnits stores the extracted code units
 	codeUnits []*CodeUnit
 	// embeddingCache caches embeddings for reuse
 	embeddingCache *cache.EmbeddingStore
+	// templates overrides EmbeddingText per language when set
+	templates EmbeddingTemplates
+	// limits caps dependencies and call lists in embedding text
+	limits EmbeddingLimits
+	// graph controls callee summaries in embedding text
+	graph GraphContext
+	// stopSymbols are the callees left out of the call graph
+	stopSymbols callgraph.StopSymbols
+	// backend decides whether Save also builds an HNSW graph
+	backend index.BackendOptions
+	// imported are units read from ctags, LSIF or SCIP dumps
+	imported []*CodeUnit
+	// chunks controls splitting of long function bodies
+	chunks ChunkOptions
+	// stableIDs enables content based unit IDs and the ID map
+	stableIDs bool
+	// idChanges are the units Save found moved or renamed since the
+	// previous build
+	idChanges []UnitIDChange
+	// budget limits how long Build spends; zero means no limit
+	budget time.Duration
+	// remaining counts the units Build left out when its budget ran out
+	remaining int
+	// enrichers annotate units after extraction, before the registered ones
+	enrichers []Enricher
+	// enrichMu keeps enrichers to one file at a time while files are
+	// extracted in parallel
+	enrichMu sync.Mutex
+	// todos enables todo units for TODO, FIXME and HACK comments
+	todos bool
+	// commits controls commit units for the project's git history
+	commits CommitOptions
+	// docs enables doc units for the sections of Markdown and
+	// reStructuredText files
+	docs bool
+	// degradations are the features Extract skipped
+	degradations types.Degradations
+	// depFilter selects the imports left out of unit dependencies
+	depFilter DependencyFilter
+	// changed limits extraction to these files, relative to rootDir with
+	// forward slashes, when Build updates the active index; nil extracts
+	// every file
+	changed map[string]bool
+	// reused counts the units Build kept from the active index
+	reused int
+	// full makes Build index every file instead of updating the active
+	// index
+	full bool
+	// fileHashes are the hashes of the files Extract indexed, saved in
+	// the metadata
+	fileHashes map[string]string
+	// previousFiles are the file hashes of the index being updated
+	previousFiles map[string]string
+	// reusable are the vectors of the index being updated by the hash of
+	// their unit's embedding text
+	reusable map[string][]float32
+	// targets are the files, relative to rootDir with forward slashes, a
+	// targeted build updates without scanning; nil scans the project
+	targets map[string]bool
+	// targetUnits are the unit URIs a targeted build replaces; nil
+	// replaces every unit of the target files
+	targetUnits map[string]bool
+	// previousDegradations are those recorded by the index a targeted
+	// build updates
+	previousDegradations types.Degradations
 }
 
 // NewBuilder creates a new semantic index builder
@@ -202,7 +317,7 @@ func NewBuilder(rootDir string, embedProvider embed.Provider) (*Builder, error)
 	}
 
 	// Create cache directory at project root
-	cacheDir := filepath.Join(absRoot, ".gcq", "cache", "semantic")
+	cacheDir := semanticCacheDir(absRoot)
 	if err := os.MkdirAll(cacheDir, 0755); err != nil {
 		return nil, fmt.Errorf("creating cache directory: %w", err)
 	}
@@ -236,6 +351,7 @@ func NewBuilder(rootDir string, embedProvider embed.Provider) (*Builder, error)
 		vectorIndex:       nil,
 		codeUnits:         nil,
 		embeddingCache:    embedStore,
+		limits:            DefaultEmbeddingLimits(),
 	}
 
 	return builder, nil
@@ -277,6 +393,46 @@ func (b *Builder) WithSearchProvider(provider embed.Provider) *Builder {
 	return b
 }
 
+// WithEmbeddingTemplates sets the templates used to build embedding text.
+// Languages without a template (and no default) use EmbeddingText.
+func (b *Builder) WithEmbeddingTemplates(templates EmbeddingTemplates) *Builder {
+	b.templates = templates
+	return b
+}
+
+// WithEmbeddingLimits sets the caps on dependencies and call lists
+func (b *Builder) WithEmbeddingLimits(limits EmbeddingLimits) *Builder {
+	b.limits = limits
+	return b
+}
+
+// WithGraphContext enables callee summaries in embedding text
+func (b *Builder) WithGraphContext(gc GraphContext) *Builder {
+	b.graph = gc
+	return b
+}
+
+// WithStopSymbols leaves calls to these callees out of unit calls and
+// callers, and so out of embedding text and callee summaries
+func (b *Builder) WithStopSymbols(stop callgraph.StopSymbols) *Builder {
+	b.stopSymbols = stop
+	return b
+}
+
+// WithBackendOptions sets the search backend options. When they select
+// HNSW for the built index, Save also builds and saves the graph.
+func (b *Builder) WithBackendOptions(opts index.BackendOptions) *Builder {
+	b.backend = opts
+	return b
+}
+
+// WithImportedUnits adds units read from symbol dumps (see package
+// importer). Extract keeps those in files no extractor parses natively.
+func (b *Builder) WithImportedUnits(units []*CodeUnit) *Builder {
+	b.imported = units
+	return b
+}
+
 // Scan scans the project for supported files
 func (b *Builder) Scan() ([]scanner.FileInfo, error) {
 	return b.scanner.Scan(b.rootDir)
@@ -284,6 +440,11 @@ func (b *Builder) Scan() ([]scanner.FileInfo, error) {
 
 // Extract extracts code units from scanned files
 func (b *Builder) Extract(files []scanner.FileInfo) ([]*CodeUnit, error) {
+	// Dependency manifests classify imports; without any, the stdlib
+	// heuristic in extractSignificantDeps is used
+	manifests, _ := deps.Load(b.rootDir)
+	b.degradations = nil
+
 	// Group files by language for processing
 	// We support multiple languages now, not just Python
 	languageFiles := make(map[string][]string)
@@ -299,8 +460,19 @@ func (b *Builder) Extract(files []scanner.FileInfo) ([]*CodeUnit, error) {
 	callsMap := make(map[string][]string)   // func -> functions it calls
 	callersMap := make(map[string][]string) // func -> functions that call it
 
-	// Process each language that has files
+	// TypeScript, JavaScript and Vue and Svelte component files import each
+	// other, so they share one call graph
+	callGraphFiles := make(map[string][]string)
 	for lang, files := range languageFiles {
+		switch lang {
+		case "javascript", "vue", "svelte":
+			lang = "typescript"
+		}
+		callGraphFiles[lang] = append(callGraphFiles[lang], files...)
+	}
+
+	// Process each language that has files
+	for lang, files := range callGraphFiles {
 		ext, err := b.extractor.GetExtractor(files[0])
 		if err != nil {
 			continue
@@ -310,196 +482,488 @@ func (b *Builder) Extract(files []scanner.FileInfo) ([]*CodeUnit, error) {
 		callGraph, err := resolver.ResolveCalls(files)
 		if err != nil {
 			fmt.Printf("Warning: building call graph for %s: %v\n", lang, err)
+			b.degradations.Add(types.DegradedCallGraph, fmt.Sprintf("building call graph for %s: %v", lang, err), len(files))
 			continue
 		}
 
 		// Process edges
 		for _, edge := range callGraph.CrossFileEdges {
+			if b.stopSymbols.MatchEdge(edge) {
+				continue
+			}
 			callerKey := fmt.Sprintf("%s:%s", edge.SourceFile, edge.SourceFunc)
 			calleeKey := fmt.Sprintf("%s:%s", edge.DestFile, edge.DestFunc)
 			callsMap[callerKey] = append(callsMap[callerKey], calleeKey)
-			callersMap[calleeKey] = append(callersMap[callerKey], callerKey)
+			callersMap[calleeKey] = append(callersMap[calleeKey], callerKey)
 		}
 
 		// Also process intra-file edges
 		for _, edge := range callGraph.IntraFileEdges {
+			if b.stopSymbols.MatchEdge(edge) {
+				continue
+			}
 			callerKey := fmt.Sprintf("%s:%s", edge.SourceFile, edge.SourceFunc)
 			calleeKey := fmt.Sprintf("%s:%s", edge.DestFile, edge.DestFunc)
 			callsMap[callerKey] = append(callsMap[callerKey], calleeKey)
-			callersMap[calleeKey] = append(callersMap[callerKey], callerKey)
+			callersMap[calleeKey] = append(callersMap[calleeKey], callerKey)
 		}
 	}
 
-	// Extract code units from each language's files
-	var units []*CodeUnit
+	// Hash the files to find the ones an update extracts
+	b.hashFiles(languageFiles, files, callsMap, manifests.Fingerprint())
 
+	// Extract code units from each language's files on a pool of
+	// workers. Units keep the order of the files, and degradations are
+	// merged in the same order.
+	var jobs []fileJob
 	for lang, filePaths := range languageFiles {
 		for _, filePath := range filePaths {
-			ext, err := b.extractor.GetExtractor(filePath)
-			if err != nil {
-				// Skip unsupported files
+			jobs = append(jobs, fileJob{lang: lang, path: filePath})
+		}
+	}
+	fileUnits := make([][]*CodeUnit, len(jobs))
+	fileDegradations := make([]types.Degradations, len(jobs))
+	forEachFile(len(jobs), func(i int) {
+		fileUnits[i] = b.extractFile(jobs[i].lang, jobs[i].path, manifests, callsMap, callersMap, &fileDegradations[i])
+	})
+	var units []*CodeUnit
+	for i := range jobs {
+		units = append(units, fileUnits[i]...)
+		for _, d := range fileDegradations[i] {
+			b.degradations.Add(d.Feature, d.Reason, d.Count)
+		}
+	}
+
+	// Imported units fill in languages the extractors don't cover; a
+	// targeted build keeps them from the active index
+	for _, unit := range b.imported {
+		if b.targets != nil {
+			break
+		}
+		if _, err := b.extractor.GetExtractor(filepath.Join(b.rootDir, unit.FilePath)); err == nil {
+			continue
+		}
+		if b.stableIDs {
+			setStableID(unit, nil)
+		}
+		enrich(b.activeEnrichers(), []*CodeUnit{unit}, nil)
+		units = append(units, unit)
+	}
+
+	// Documents are split into sections by heading
+	if b.docs {
+		for _, f := range files {
+			if !IsDocLanguage(f.Language) || b.unchanged(f.Path) {
 				continue
 			}
-
-			moduleInfo, err := ext.Extract(filePath)
+			source, err := os.ReadFile(f.FullPath)
 			if err != nil {
-				// Skip files that can't be parsed
 				continue
 			}
+			docs := docUnits(f.Language, f.Path, SplitDocSections(f.FullPath, source))
+			enrich(b.activeEnrichers(), docs, nil)
+			units = append(units, docs...)
+		}
+	}
 
-			relPath, err := filepath.Rel(b.rootDir, filePath)
-			if err != nil {
-				relPath = filePath
-			}
+	// Commit messages and pull requests explain why the code changed
+	if b.commits.Enabled && b.targets == nil {
+		history, err := historyUnits(b.rootDir, b.commits)
+		if err != nil {
+			fmt.Printf("Warning: indexing git history: %v\n", err)
+			b.degradations.Add(types.DegradedHistory, historyFailureReason, 0)
+		}
+		enrich(b.activeEnrichers(), history, nil)
+		units = append(units, history...)
+	}
 
-			// Determine language-specific signature prefix
-			sigPrefix := getSignaturePrefix(lang)
-
-			// Extract significant dependencies (external imports only)
-			deps := extractSignificantDeps(moduleInfo)
-
-			// Extract functions
-			for _, fn := range moduleInfo.Functions {
-				unit := &CodeUnit{
-					Name:         fn.Name,
-					Type:         "function",
-					FilePath:     relPath,
-					LineNumber:   fn.LineNumber,
-					Signature:    formatSignatureForLang(fn, lang, sigPrefix),
-					Docstring:    fn.Docstring,
-					Calls:        callsMap[fmt.Sprintf("%s:%s", relPath, fn.Name)],
-					CalledBy:     callersMap[fmt.Sprintf("%s:%s", relPath, fn.Name)],
-					Dependencies: deps,
-				}
+	for _, unit := range units {
+		unit.IsTest = types.IsTestFile(unit.FilePath)
+	}
 
-				// Extract CFG summary (optional - graceful degradation)
-				if cfgInfo, err := cfg.ExtractCFG(filePath, fn.Name); err == nil {
-					// Compute additional metrics from CFG
-					branches := 0
-					loops := 0
-					depth := 0
-					visited := make(map[string]bool)
-					var computeDepth func(string, int)
-					computeDepth = func(blockID string, currentDepth int) {
-						if visited[blockID] {
-							return
-						}
-						visited[blockID] = true
-						if currentDepth > depth {
-							depth = currentDepth
-						}
-						block, ok := cfgInfo.Blocks[blockID]
-						if !ok {
-							return
-						}
-						for _, edge := range cfgInfo.Edges {
-							if edge.SourceID == block.ID {
-								computeDepth(edge.TargetID, currentDepth+1)
-							}
-						}
-					}
-					if cfgInfo.EntryBlockID != "" {
-						computeDepth(cfgInfo.EntryBlockID, 0)
-					}
-					for _, edge := range cfgInfo.Edges {
-						if edge.EdgeType == cfg.EdgeTypeTrue || edge.EdgeType == cfg.EdgeTypeFalse {
-							branches++
-						}
-						if edge.EdgeType == cfg.EdgeTypeBackEdge {
-							loops++
-						}
-					}
-					unit.CFGSummary = fmt.Sprintf("complexity:%d, blocks:%d, branches:%d, loops:%d, depth:%d",
-						cfgInfo.CyclomaticComplexity, len(cfgInfo.Blocks), branches, loops, depth)
-				}
+	AttachCalleeSummaries(units, b.graph)
 
-				// Extract DFG summary (optional - graceful degradation)
-				if dfgInfo, err := dfg.ExtractDFG(filePath, fn.Name); err == nil {
-					// Count param references (variables used from function parameters)
-					paramCount := 0
-					if fn.Params != "" {
-						// Count parameters by splitting on comma
-						paramCount = 1
-						for _, c := range fn.Params {
-							if c == ',' {
-								paramCount++
-							}
-						}
-					}
-					// Count definitions (definition + update)
-					definitions := 0
-					uses := 0
-					for _, v := range dfgInfo.VarRefs {
-						if v.RefType == dfg.RefTypeDefinition || v.RefType == dfg.RefTypeUpdate {
-							definitions++
-						} else if v.RefType == dfg.RefTypeUse {
-							uses++
-						}
-					}
-					// Local variables = definitions - params (at minimum 0)
-					locals := definitions - paramCount
-					if locals < 0 {
-						locals = 0
-					}
-					unit.DFGSummary = fmt.Sprintf("params:%d, locals:%d, definitions:%d, uses:%d, edges:%d",
-						paramCount, locals, definitions, uses, len(dfgInfo.DataflowEdges))
-				}
+	b.codeUnits = units
+	return units, nil
+}
 
-				units = append(units, unit)
+// fileJob is a source file for extractFile
+type fileJob struct {
+	lang string
+	path string
+}
+
+// forEachFile calls fn with each index below n on a pool of
+// runtime.GOMAXPROCS goroutines and returns once every call has returned.
+// Extractors parse with pooled parsers, so files of the same language are
+// extracted in parallel too.
+func forEachFile(n int, fn func(i int)) {
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
 			}
+		}()
+	}
+	for i := range n {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+// extractFile returns the code units of one file, recording what it
+// falls back on in ds. It is called from several goroutines at once, so it
+// only reads the builder and the call maps.
+func (b *Builder) extractFile(lang, filePath string, manifests *deps.Manifests, callsMap, callersMap map[string][]string, ds *types.Degradations) []*CodeUnit {
+	relPath, err := filepath.Rel(b.rootDir, filePath)
+	if err != nil {
+		relPath = filePath
+	}
+	// An update keeps the units of unchanged files from the active
+	// index; the call graph still covers every file
+	if b.unchanged(relPath) {
+		return nil
+	}
+
+	ext, err := b.extractor.GetExtractor(filePath)
+	if err != nil {
+		// Skip unsupported files
+		return nil
+	}
+
+	moduleInfo, err := ext.Extract(filePath)
+	if err != nil {
+		// Skip files that can't be parsed
+		return nil
+	}
+
+	// gcq: comments leave the file or some definitions out and tag
+	// the rest
+	directives, err := extractor.ReadDirectives(filePath)
+	if err != nil {
+		directives = &extractor.Directives{}
+	}
+	if directives.IgnoreFile {
+		return nil
+	}
+	directives.Apply(moduleInfo)
+
+	// Determine language-specific signature prefix
+	sigPrefix := getSignaturePrefix(lang)
 
-			// Extract classes
-			for _, cls := range moduleInfo.Classes {
-				unit := &CodeUnit{
-					Name:         cls.Name,
-					Type:         "class",
-					FilePath:     relPath,
-					LineNumber:   cls.LineNumber,
-					Signature:    formatClassSignatureForLang(cls, lang),
-					Docstring:    cls.Docstring,
-					Calls:        callsMap[fmt.Sprintf("%s:%s", relPath, cls.Name)],
-					CalledBy:     callersMap[fmt.Sprintf("%s:%s", relPath, cls.Name)],
-					Dependencies: deps,
+	// Extract significant dependencies (external imports only)
+	unitDeps, depVersions := resolveDependencies(moduleInfo, manifests, b.depFilter, relPath, lang, b.limits.Dependencies)
+
+	var units []*CodeUnit
+
+	// Extract functions
+	for _, fn := range moduleInfo.Functions {
+		unit := &CodeUnit{
+			ID:                 types.NewUnitURI(lang, relPath, functionSymbol(fn, lang)).String(),
+			Language:           lang,
+			Name:               fn.Name,
+			Type:               "function",
+			FilePath:           relPath,
+			LineNumber:         fn.LineNumber,
+			Signature:          formatSignatureForLang(fn, lang, sigPrefix),
+			Docstring:          fn.Docstring,
+			Calls:              callsMap[fmt.Sprintf("%s:%s", relPath, fn.Name)],
+			CalledBy:           callersMap[fmt.Sprintf("%s:%s", relPath, fn.Name)],
+			Dependencies:       unitDeps,
+			DependencyVersions: depVersions,
+		}
+
+		// Extract CFG summary (optional - graceful degradation)
+		cfgInfo, err := cfg.ExtractCFG(filePath, fn.Name)
+		if err != nil && !errors.Is(err, cfg.ErrUnsupportedFile) {
+			ds.Add(types.DegradedCFG, cfgFailureReason, 1)
+		}
+		if err == nil {
+			// Compute additional metrics from CFG
+			branches := 0
+			loops := 0
+			depth := 0
+			visited := make(map[string]bool)
+			var computeDepth func(string, int)
+			computeDepth = func(blockID string, currentDepth int) {
+				if visited[blockID] {
+					return
+				}
+				visited[blockID] = true
+				if currentDepth > depth {
+					depth = currentDepth
+				}
+				block, ok := cfgInfo.Blocks[blockID]
+				if !ok {
+					return
 				}
-				units = append(units, unit)
-
-				// Extract methods
-				for _, method := range cls.Methods {
-					methodUnit := &CodeUnit{
-						Name:         fmt.Sprintf("%s.%s", cls.Name, method.Name),
-						Type:         "method",
-						FilePath:     relPath,
-						LineNumber:   method.LineNumber,
-						Signature:    formatMethodSignatureForLang(method, cls.Name, lang, sigPrefix),
-						Docstring:    method.Docstring,
-						Calls:        callsMap[fmt.Sprintf("%s:%s", relPath, method.Name)],
-						CalledBy:     callersMap[fmt.Sprintf("%s:%s", relPath, method.Name)],
-						Dependencies: deps,
+				for _, edge := range cfgInfo.Edges {
+					if edge.SourceID == block.ID {
+						computeDepth(edge.TargetID, currentDepth+1)
 					}
-					units = append(units, methodUnit)
 				}
 			}
+			if cfgInfo.EntryBlockID != "" {
+				computeDepth(cfgInfo.EntryBlockID, 0)
+			}
+			for _, edge := range cfgInfo.Edges {
+				if edge.EdgeType == cfg.EdgeTypeTrue || edge.EdgeType == cfg.EdgeTypeFalse {
+					branches++
+				}
+				if edge.EdgeType == cfg.EdgeTypeBackEdge {
+					loops++
+				}
+			}
+			unit.CFGSummary = fmt.Sprintf("complexity:%d, blocks:%d, branches:%d, loops:%d, depth:%d",
+				cfgInfo.CyclomaticComplexity, len(cfgInfo.Blocks), branches, loops, depth)
+		}
 
-			// Extract interfaces (for Go/TypeScript)
-			for _, iface := range moduleInfo.Interfaces {
-				unit := &CodeUnit{
-					Name:         iface.Name,
-					Type:         "interface",
-					FilePath:     relPath,
-					LineNumber:   iface.LineNumber,
-					Signature:    formatInterfaceSignature(iface),
-					Docstring:    iface.Docstring,
-					Calls:        callsMap[fmt.Sprintf("%s:%s", relPath, iface.Name)],
-					CalledBy:     callersMap[fmt.Sprintf("%s:%s", relPath, iface.Name)],
-					Dependencies: deps,
+		// Extract DFG summary (optional - graceful degradation)
+		if dfgInfo, err := dfg.ExtractDFG(filePath, fn.Name); err == nil {
+			// Count param references (variables used from function parameters)
+			paramCount := 0
+			if fn.Params != "" {
+				// Count parameters by splitting on comma
+				paramCount = 1
+				for _, c := range fn.Params {
+					if c == ',' {
+						paramCount++
+					}
+				}
+			}
+			// Count definitions (definition + update)
+			definitions := 0
+			uses := 0
+			for _, v := range dfgInfo.VarRefs {
+				if v.RefType == dfg.RefTypeDefinition || v.RefType == dfg.RefTypeUpdate {
+					definitions++
+				} else if v.RefType == dfg.RefTypeUse {
+					uses++
 				}
-				units = append(units, unit)
 			}
+			// Local variables = definitions - params (at minimum 0)
+			locals := definitions - paramCount
+			if locals < 0 {
+				locals = 0
+			}
+			unit.DFGSummary = fmt.Sprintf("params:%d, locals:%d, definitions:%d, uses:%d, edges:%d",
+				paramCount, locals, definitions, uses, len(dfgInfo.DataflowEdges))
 		}
+
+		units = append(units, unit)
 	}
 
-	b.codeUnits = units
-	return units, nil
+	// Interfaces and traits become their own units below; skip the
+	// copies Go and Rust extractors also list as classes
+	abstracts := abstractTypes(moduleInfo)
+	abstractNames := make(map[string]bool, len(abstracts))
+	for _, at := range abstracts {
+		abstractNames[at.Name] = true
+	}
+
+	// Extract classes
+	for _, cls := range moduleInfo.Classes {
+		if abstractNames[cls.Name] {
+			continue
+		}
+		unit := &CodeUnit{
+			ID:                 types.NewUnitURI(lang, relPath, cls.Name).String(),
+			Language:           lang,
+			Name:               cls.Name,
+			Type:               "class",
+			FilePath:           relPath,
+			LineNumber:         cls.LineNumber,
+			Signature:          formatClassSignatureForLang(cls, lang),
+			Docstring:          cls.Docstring,
+			Calls:              callsMap[fmt.Sprintf("%s:%s", relPath, cls.Name)],
+			CalledBy:           callersMap[fmt.Sprintf("%s:%s", relPath, cls.Name)],
+			Dependencies:       unitDeps,
+			DependencyVersions: depVersions,
+		}
+		units = append(units, unit)
+		units = append(units, methodUnits(cls.Name, cls.Methods, lang, relPath, sigPrefix, callsMap, callersMap, unitDeps, depVersions)...)
+	}
+
+	// Extract interfaces and traits with their full method sets
+	for _, at := range abstracts {
+		unit := &CodeUnit{
+			ID:                 types.NewUnitURI(lang, relPath, at.Name).String(),
+			Language:           lang,
+			Name:               at.Name,
+			Type:               at.Type,
+			FilePath:           relPath,
+			LineNumber:         at.LineNumber,
+			Signature:          formatAbstractSignatureForLang(at, lang),
+			Docstring:          at.Docstring,
+			Calls:              callsMap[fmt.Sprintf("%s:%s", relPath, at.Name)],
+			CalledBy:           callersMap[fmt.Sprintf("%s:%s", relPath, at.Name)],
+			Dependencies:       unitDeps,
+			DependencyVersions: depVersions,
+		}
+		units = append(units, unit)
+		units = append(units, methodUnits(at.Name, at.Methods, lang, relPath, sigPrefix, callsMap, callersMap, unitDeps, depVersions)...)
+	}
+
+	derived := b.annotateFile(filePath, relPath, lang, units)
+	// The markup of a component is indexed as a doc unit
+	if template := templateUnits(filePath, relPath, lang); len(template) > 0 {
+		b.enrichFile(b.activeEnrichers(), template, nil)
+		derived = append(derived, template...)
+	}
+	tagUnits(directives, units, derived)
+	units = append(units, derived...)
+	return units
+}
+
+// functionSymbol returns the URI symbol of a function unit. Go methods are
+// extracted as functions and addressed by package directory, where several
+// types may have a method of the same name, so they are qualified by their
+// receiver type: "VectorIndex.Add".
+func functionSymbol(fn types.Function, lang string) string {
+	if lang == "go" && fn.Receiver != "" {
+		return fn.Receiver + "." + fn.Name
+	}
+	return fn.Name
+}
+
+// methodUnits builds the method units of a class, interface or trait
+func methodUnits(owner string, methods []types.Method, lang, relPath, sigPrefix string, callsMap, callersMap map[string][]string, unitDeps []string, depVersions map[string]string) []*CodeUnit {
+	units := make([]*CodeUnit, 0, len(methods))
+	for _, method := range methods {
+		methodName := fmt.Sprintf("%s.%s", owner, method.Name)
+		// Go call graphs key methods by Type.Method, other languages by name
+		callKeys := []string{fmt.Sprintf("%s:%s", relPath, method.Name), fmt.Sprintf("%s:%s", relPath, methodName)}
+		units = append(units, &CodeUnit{
+			ID:                 types.NewUnitURI(lang, relPath, methodName).String(),
+			Language:           lang,
+			Name:               methodName,
+			Type:               "method",
+			FilePath:           relPath,
+			LineNumber:         method.LineNumber,
+			Signature:          formatMethodSignatureForLang(method, owner, lang, sigPrefix),
+			Docstring:          method.Docstring,
+			Calls:              slices.Concat(callsMap[callKeys[0]], callsMap[callKeys[1]]),
+			CalledBy:           slices.Concat(callersMap[callKeys[0]], callersMap[callKeys[1]]),
+			Dependencies:       unitDeps,
+			DependencyVersions: depVersions,
+		})
+	}
+	return units
+}
+
+// abstractType is an interface or trait indexed as its own unit type
+type abstractType struct {
+	Name       string
+	TypeParams string
+	Type       string // "interface" or "trait"
+	Bases      []string
+	Docstring  string
+	Methods    []types.Method
+	LineNumber int
+}
+
+// abstractTypes returns a module's interfaces and traits
+func abstractTypes(moduleInfo *types.ModuleInfo) []abstractType {
+	result := make([]abstractType, 0, len(moduleInfo.Interfaces)+len(moduleInfo.Traits))
+	for _, iface := range moduleInfo.Interfaces {
+		result = append(result, abstractType{
+			Name:       iface.Name,
+			TypeParams: iface.TypeParams,
+			Type:       "interface",
+			Bases:      iface.Bases,
+			Docstring:  iface.Docstring,
+			Methods:    iface.Methods,
+			LineNumber: iface.LineNumber,
+		})
+	}
+	for _, trait := range moduleInfo.Traits {
+		result = append(result, abstractType{
+			Name:       trait.Name,
+			Type:       "trait",
+			Docstring:  trait.Docstring,
+			Methods:    trait.Methods,
+			LineNumber: trait.LineNumber,
+		})
+	}
+	return result
+}
+
+// DependencyUnits extracts the units of files with the dependencies each
+// one uses, without building call graphs, CFG/DFG summaries or embeddings.
+// Files that import dependencies but define no units are reported as a
+// single "module" unit. It backs dependency reports. Imports filter leaves
+// out are not reported.
+func DependencyUnits(rootDir string, files []scanner.FileInfo, filter DependencyFilter) []*CodeUnit {
+	manifests, _ := deps.Load(rootDir)
+	registry := extractor.GetLanguageRegistry()
+
+	var units []*CodeUnit
+	for _, f := range files {
+		if f.Language == "" {
+			continue
+		}
+		ext, err := registry.GetExtractor(f.FullPath)
+		if err != nil {
+			continue
+		}
+		moduleInfo, err := ext.Extract(f.FullPath)
+		if err != nil {
+			continue
+		}
+		relPath, err := filepath.Rel(rootDir, f.FullPath)
+		if err != nil {
+			relPath = f.FullPath
+		}
+
+		unitDeps, depVersions := resolveDependencies(moduleInfo, manifests, filter, relPath, f.Language, 0)
+		if len(unitDeps) == 0 {
+			continue
+		}
+		add := func(name, unitType string, line int) {
+			units = append(units, &CodeUnit{
+				ID:                 types.NewUnitURI(f.Language, relPath, name).String(),
+				Language:           f.Language,
+				Name:               name,
+				Type:               unitType,
+				FilePath:           relPath,
+				LineNumber:         line,
+				Dependencies:       unitDeps,
+				DependencyVersions: depVersions,
+			})
+		}
+
+		before := len(units)
+		for _, fn := range moduleInfo.Functions {
+			add(functionSymbol(fn, f.Language), "function", fn.LineNumber)
+		}
+		abstracts := abstractTypes(moduleInfo)
+		abstractNames := make(map[string]bool, len(abstracts))
+		for _, at := range abstracts {
+			abstractNames[at.Name] = true
+			add(at.Name, at.Type, at.LineNumber)
+			for _, method := range at.Methods {
+				add(fmt.Sprintf("%s.%s", at.Name, method.Name), "method", method.LineNumber)
+			}
+		}
+		for _, cls := range moduleInfo.Classes {
+			if abstractNames[cls.Name] {
+				continue
+			}
+			add(cls.Name, "class", cls.LineNumber)
+			for _, method := range cls.Methods {
+				add(fmt.Sprintf("%s.%s", cls.Name, method.Name), "method", method.LineNumber)
+			}
+		}
+		if len(units) == before {
+			add(filepath.Base(relPath), "module", 1)
+		}
+	}
+	return units
 }
 
 // getSignaturePrefix returns the language-specific function keyword
@@ -509,68 +973,82 @@ func getSignaturePrefix(lang string) string {
 		return "def"
 	case "go":
 		return "func"
-	case "typescript", "javascript":
+	case "typescript", "javascript", "vue", "svelte", "php":
 		return "function"
+	case "kotlin":
+		return "fun"
 	default:
 		return "def"
 	}
 }
 
-// commonStdlib is a set of common standard library modules to exclude from dependencies
-var commonStdlib = map[string]bool{
-	// Python stdlib
-	"os": true, "sys": true, "json": true, "re": true, "time": true,
-	"datetime": true, "collections": true, "functools": true, "itertools": true,
-	"math": true, "random": true, "logging": true, "typing": true,
-	"pathlib": true, "argparse": true, "subprocess": true, "threading": true,
-	"multiprocessing": true, "asyncio": true, "contextlib": true,
-	// Go stdlib
-	"fmt": true, "errors": true, "strconv": true, "strings": true,
-	"bytes": true, "bufio": true, "io": true, "path": true,
-	"filepath": true, "sort": true, "container": true, "crypto": true,
-	"hash": true, "unicode": true, "encoding": true,
+// extractSignificantDeps extracts significant (external) dependencies from module imports
+// It filters out relative imports and common stdlib modules, keeping at most
+// maxDeps (0 = unlimited)
+func extractSignificantDeps(moduleInfo *types.ModuleInfo, maxDeps int) []string {
+	names, _ := resolveDependencies(moduleInfo, nil, DependencyFilter{}, "", "", maxDeps)
+	return names
 }
 
-// extractSignificantDeps extracts significant (external) dependencies from module imports
-// It filters out relative imports and common stdlib modules
-func extractSignificantDeps(moduleInfo *types.ModuleInfo) []string {
+// resolveDependencies returns the third-party dependencies imported by a
+// file, keeping at most maxDeps (0 = unlimited). Imports are classified with
+// the nearest dependency manifest, so they are reported under their declared
+// package name with its version; imports no manifest covers fall back to
+// filtering relative imports and the stdlib modules of filter. Imports on
+// filter's ignore list are always left out.
+func resolveDependencies(moduleInfo *types.ModuleInfo, manifests *deps.Manifests, filter DependencyFilter, filePath, lang string, maxDeps int) ([]string, map[string]string) {
 	if moduleInfo == nil || len(moduleInfo.Imports) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	deps := make([]string, 0)
+	names := make([]string, 0)
+	var versions map[string]string
 	seen := make(map[string]bool)
 
 	for _, imp := range moduleInfo.Imports {
-		// Skip relative imports
-		if strings.HasPrefix(imp.Module, ".") {
+		name := imp.Module
+		dep, kind := manifests.Resolve(filePath, lang, imp.Module)
+		switch kind {
+		case deps.Stdlib, deps.Internal:
+			continue
+		case deps.External:
+			name = dep.Name
+		default:
+			// Skip relative imports
+			if strings.HasPrefix(imp.Module, ".") {
+				continue
+			}
+			// Skip stdlib modules - only keep external dependencies
+			if filter.isStdlib(lang, imp.Module) {
+				continue
+			}
+		}
+		if filter.ignored(imp.Module) || filter.ignored(name) {
 			continue
 		}
 
-		// Skip stdlib modules - only keep external dependencies
-		if commonStdlib[imp.Module] {
+		if seen[name] {
 			continue
 		}
-
-		// Add the module if not already seen
-		if !seen[imp.Module] {
-			seen[imp.Module] = true
-			if imp.IsFrom {
-				// "from foo import bar" -> "foo"
-				deps = append(deps, imp.Module)
-			} else {
-				// "import foo" -> "foo"
-				deps = append(deps, imp.Module)
+		seen[name] = true
+		names = append(names, name)
+		if dep.Version != "" {
+			if versions == nil {
+				versions = make(map[string]string)
 			}
+			versions[name] = dep.Version
 		}
 	}
 
 	// Limit to a reasonable number of dependencies
-	if len(deps) > 5 {
-		deps = deps[:5]
+	if maxDeps > 0 && len(names) > maxDeps {
+		for _, name := range names[maxDeps:] {
+			delete(versions, name)
+		}
+		names = names[:maxDeps]
 	}
 
-	return deps
+	return names, versions
 }
 
 // formatSignatureForLang formats a function signature for the given language
@@ -579,13 +1057,15 @@ func formatSignatureForLang(fn types.Function, lang string, prefix string) strin
 	if params == "" {
 		params = "()"
 	}
+	// Generic type parameters go between the name and the params
+	params = fn.TypeParams + params
 	if fn.ReturnType != "" {
 		switch lang {
 		case "python":
 			return fmt.Sprintf("%s %s%s -> %s", prefix, fn.Name, params, fn.ReturnType)
 		case "go":
 			return fmt.Sprintf("%s %s%s %s", prefix, fn.Name, params, fn.ReturnType)
-		case "typescript", "javascript":
+		case "typescript", "javascript", "vue", "svelte", "php", "kotlin":
 			return fmt.Sprintf("%s %s%s: %s", prefix, fn.Name, params, fn.ReturnType)
 		default:
 			return fmt.Sprintf("%s %s%s -> %s", prefix, fn.Name, params, fn.ReturnType)
@@ -606,7 +1086,7 @@ func formatMethodSignatureForLang(method types.Method, className string, lang st
 			return fmt.Sprintf("def %s.%s%s -> %s", className, method.Name, params, method.ReturnType)
 		case "go":
 			return fmt.Sprintf("func (%s) %s%s %s", className, method.Name, params, method.ReturnType)
-		case "typescript", "javascript":
+		case "typescript", "javascript", "vue", "svelte", "php", "kotlin":
 			return fmt.Sprintf("%s %s.%s%s: %s", prefix, className, method.Name, params, method.ReturnType)
 		default:
 			return fmt.Sprintf("%s %s.%s%s -> %s", prefix, className, method.Name, params, method.ReturnType)
@@ -617,7 +1097,7 @@ func formatMethodSignatureForLang(method types.Method, className string, lang st
 		return fmt.Sprintf("def %s.%s%s", className, method.Name, params)
 	case "go":
 		return fmt.Sprintf("func (%s) %s%s", className, method.Name, params)
-	case "typescript", "javascript":
+	case "typescript", "javascript", "vue", "svelte":
 		return fmt.Sprintf("%s %s.%s%s", prefix, className, method.Name, params)
 	default:
 		return fmt.Sprintf("%s %s.%s%s", prefix, className, method.Name, params)
@@ -633,8 +1113,16 @@ func formatClassSignatureForLang(cls types.Class, lang string) string {
 		}
 		return fmt.Sprintf("class %s", cls.Name)
 	case "go":
-		return fmt.Sprintf("type %s struct", cls.Name)
-	case "typescript", "javascript":
+		header := fmt.Sprintf("type %s%s struct", cls.Name, cls.TypeParams)
+		if len(cls.Fields) == 0 {
+			return header
+		}
+		fields := make([]string, len(cls.Fields))
+		for i, f := range cls.Fields {
+			fields[i] = formatGoField(f)
+		}
+		return fmt.Sprintf("%s { %s }", header, strings.Join(fields, "; "))
+	case "typescript", "javascript", "vue", "svelte":
 		if len(cls.Bases) > 0 {
 			return fmt.Sprintf("class %s extends %s", cls.Name, cls.Bases[0])
 		}
@@ -647,16 +1135,79 @@ func formatClassSignatureForLang(cls types.Class, lang string) string {
 	}
 }
 
-// formatInterfaceSignature formats an interface signature
-func formatInterfaceSignature(iface types.Interface) string {
-	if len(iface.Methods) > 0 {
-		methodNames := make([]string, len(iface.Methods))
-		for i, m := range iface.Methods {
-			methodNames[i] = m.Name
+// formatGoField formats a struct field as declared, e.g.
+// "SocketPath string `yaml:"socket_path"`"
+func formatGoField(f types.Field) string {
+	decl := f.Type
+	if !f.Embedded {
+		decl = f.Name + " " + f.Type
+	}
+	if f.Tag != "" {
+		decl += " `" + f.Tag + "`"
+	}
+	return decl
+}
+
+// formatAbstractSignatureForLang formats an interface or trait signature
+// including the full method set, e.g.
+// "type Provider interface { Embed(texts []string) ([][]float32, error); Dimension() int }"
+func formatAbstractSignatureForLang(at abstractType, lang string) string {
+	keyword := at.Type
+	if lang == "ruby" {
+		// Ruby modules are extracted as traits
+		keyword = "module"
+	}
+
+	var header string
+	switch lang {
+	case "go":
+		header = fmt.Sprintf("type %s%s interface", at.Name, at.TypeParams)
+	case "rust":
+		header = fmt.Sprintf("trait %s", at.Name)
+		if len(at.Bases) > 0 {
+			header += ": " + strings.Join(at.Bases, " + ")
+		}
+	default:
+		header = fmt.Sprintf("%s %s", keyword, at.Name)
+		if len(at.Bases) > 0 {
+			header += " extends " + strings.Join(at.Bases, ", ")
 		}
-		return fmt.Sprintf("interface %s { %s }", iface.Name, strings.Join(methodNames, ", "))
 	}
-	return fmt.Sprintf("interface %s", iface.Name)
+
+	if len(at.Methods) == 0 {
+		return header
+	}
+	members := make([]string, len(at.Methods))
+	for i, m := range at.Methods {
+		members[i] = formatMemberSignature(m, lang)
+	}
+	return fmt.Sprintf("%s { %s }", header, strings.Join(members, "; "))
+}
+
+// formatMemberSignature formats a method as declared inside an interface
+// or trait body
+func formatMemberSignature(method types.Method, lang string) string {
+	if lang == "go" && method.Params == "" {
+		// Embedded interface
+		return method.Name
+	}
+	params := method.Params
+	if params == "" {
+		params = "()"
+	} else if !strings.HasPrefix(params, "(") {
+		params = "(" + params + ")"
+	}
+	if method.ReturnType == "" {
+		return method.Name + params
+	}
+	switch lang {
+	case "go":
+		return fmt.Sprintf("%s%s %s", method.Name, params, method.ReturnType)
+	case "typescript", "javascript", "vue", "svelte", "kotlin", "php":
+		return fmt.Sprintf("%s%s: %s", method.Name, params, method.ReturnType)
+	default:
+		return fmt.Sprintf("%s%s -> %s", method.Name, params, method.ReturnType)
+	}
 }
 
 // formatSignature formats a function signature
@@ -725,7 +1276,11 @@ func (b *Builder) EmbedWithProvider(units []*CodeUnit, providerType ProviderType
 	// Build embedding texts
 	texts := make([]string, len(units))
 	for i, unit := range units {
-		texts[i] = EmbeddingText(unit)
+		text, err := b.templates.TextWithLimits(unit, b.limits)
+		if err != nil {
+			return nil, err
+		}
+		texts[i] = text
 	}
 
 	// Check cache for each text and collect missing embeddings
@@ -736,8 +1291,11 @@ func (b *Builder) EmbedWithProvider(units []*CodeUnit, providerType ProviderType
 
 	for i, text := range texts {
 		hash := cache.HashString(text)
+		units[i].TextHash = hash
 		if cached, found := b.embeddingCache.Get(hash); found {
 			embeddings[i] = cached
+		} else if vector, found := b.reusable[hash]; found && providerType == ProviderTypeWarm {
+			embeddings[i] = vector
 		} else {
 			missingIndices = append(missingIndices, i)
 			missingTexts = append(missingTexts, text)
@@ -747,7 +1305,7 @@ func (b *Builder) EmbedWithProvider(units []*CodeUnit, providerType ProviderType
 
 	// Generate embeddings for missing texts
 	if len(missingTexts) > 0 {
-		newEmbeddings, err := provider.Embed(missingTexts)
+		newEmbeddings, err := provider.EmbedContext(context.Background(), missingTexts)
 		if err != nil {
 			return nil, fmt.Errorf("generating embeddings with %s provider: %w", providerType, err)
 		}
@@ -766,12 +1324,31 @@ func (b *Builder) EmbedWithProvider(units []*CodeUnit, providerType ProviderType
 	return embeddings, nil
 }
 
-// Build builds the complete semantic index
+// Build builds the complete semantic index. When a complete index built
+// with the same model and settings exists, it is updated instead: files
+// whose hash, saved in IndexMetadata.Files, is unchanged are not extracted
+// again and their units keep their vectors, and units whose embedding text
+// is unchanged reuse theirs.
 func (b *Builder) Build() (*index.VectorIndex, *IndexMetadata, error) {
-	// Step 1: Scan
-	files, err := b.Scan()
-	if err != nil {
-		return nil, nil, fmt.Errorf("scanning: %w", err)
+	deadline := time.Now().Add(b.budget)
+
+	// Step 1: Scan, or take the target files of a targeted build
+	var files []scanner.FileInfo
+	var previous *index.VectorIndex
+	var err error
+	if b.targets != nil {
+		if previous = b.indexToUpdate(); previous == nil {
+			return nil, nil, fmt.Errorf("no complete index built with this model and settings to update; run a full warm first")
+		}
+		if files, err = b.targetFiles(previous); err != nil {
+			return nil, nil, fmt.Errorf("resolving targets: %w", err)
+		}
+	} else {
+		if files, err = b.Scan(); err != nil {
+			return nil, nil, fmt.Errorf("scanning: %w", err)
+		}
+		// An update extracts only the changed files
+		previous = b.indexToUpdate()
 	}
 
 	// Step 2: Extract
@@ -779,8 +1356,14 @@ func (b *Builder) Build() (*index.VectorIndex, *IndexMetadata, error) {
 	if err != nil {
 		return nil, nil, fmt.Errorf("extracting: %w", err)
 	}
+	if b.targets != nil {
+		if units, err = b.targetUnitsOf(previous, units); err != nil {
+			return nil, nil, err
+		}
+		b.degradations = append(b.previousDegradations, b.degradations...)
+	}
 
-	if len(units) == 0 {
+	if len(units) == 0 && previous == nil {
 		warmConfig := b.embedProvider.Config()
 		metadata := &IndexMetadata{
 			Timestamp:      time.Now(),
@@ -798,12 +1381,24 @@ func (b *Builder) Build() (*index.VectorIndex, *IndexMetadata, error) {
 		return nil, metadata, nil
 	}
 
-	// Step 3: Embed
-	embeddings, err := b.Embed(units)
+	// Step 3: Embed, only as many units as the budget allows when one is set
+	var embeddings [][]float32
+	switch {
+	case b.budget > 0:
+		units, embeddings, err = b.embedWithinBudget(units, files, deadline)
+	case len(units) > 0:
+		embeddings, err = b.Embed(units)
+	}
 	if err != nil {
 		return nil, nil, fmt.Errorf("embedding: %w", err)
 	}
 
+	// Step 3b: Keep the units of unchanged files when updating
+	if previous != nil {
+		units, embeddings = b.mergeUnchanged(previous, units, embeddings)
+		b.codeUnits = units
+	}
+
 	if len(embeddings) == 0 {
 		return nil, nil, fmt.Errorf("no embeddings generated")
 	}
@@ -812,9 +1407,13 @@ func (b *Builder) Build() (*index.VectorIndex, *IndexMetadata, error) {
 
 	// Step 4: Store in vector index
 	vecIndex := index.NewVectorIndex(dimension)
+	textIndex := index.NewTextIndex()
 
 	for i, unit := range units {
-		unitID := fmt.Sprintf("%s:%s", unit.FilePath, unit.Name)
+		unitID := unit.ID
+		if unitID == "" {
+			unitID = types.NewUnitURI("", unit.FilePath, unit.Name).String()
+		}
 
 		embeddingUnit := types.EmbeddingUnit{
 			L1Data: types.ModuleInfo{
@@ -823,15 +1422,20 @@ func (b *Builder) Build() (*index.VectorIndex, *IndexMetadata, error) {
 				Signature:  unit.Signature,
 				Docstring:  unit.Docstring,
 				Type:       unit.Type,
+				Language:   unit.Language,
 			},
+			Unit: unit,
 		}
 
 		if err := vecIndex.Add(unitID, embeddings[i], embeddingUnit); err != nil {
 			return nil, nil, fmt.Errorf("adding to index: %w", err)
 		}
+		doc, fields := index.UnitTextDoc(unitID, embeddingUnit)
+		textIndex.Add(doc, fields...)
 	}
 
 	b.vectorIndex = vecIndex
+	b.textIndex = textIndex
 
 	// Create metadata with dual provider support
 	warmConfig := b.embedProvider.Config()
@@ -861,16 +1465,33 @@ func (b *Builder) Save() error {
 		return fmt.Errorf("no index to save")
 	}
 
-	// Save vector index
-	indexPath := filepath.Join(b.cacheDir, "index.msgpack")
-	if err := b.vectorIndex.Save(indexPath); err != nil {
-		return fmt.Errorf("saving index: %w", err)
+	// Compare with the index being replaced before it is overwritten; a
+	// build isn't failed for the ID map
+	if b.stableIDs {
+		changes, err := recordUnitIDChanges(b.cacheDir, b.codeUnits)
+		if err != nil {
+			fmt.Printf("Warning: recording unit ID changes: %v\n", err)
+		}
+		b.idChanges = changes
 	}
 
-	// Save metadata with dual provider support
-	metadataPath := filepath.Join(b.cacheDir, "metadata.json")
+	// Write into the model's own directory; the active index keeps serving
+	// searches until the switch below
 	warmConfig := b.embedProvider.Config()
+	name := ModelDirName(warmConfig.Model, b.vectorIndex.Dimension())
+	dir := filepath.Join(b.cacheDir, modelsDir, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating model directory: %w", err)
+	}
+
+	if err := b.saveIndexFiles(dir); err != nil {
+		return err
+	}
+
+	// Save metadata with dual provider support
+	metadataPath := filepath.Join(dir, metadataFile)
 	metadata := IndexMetadata{
+		Version:        index.FormatVersion,
 		Timestamp:      time.Now(),
 		Count:          len(b.codeUnits),
 		Dimension:      b.vectorIndex.Dimension(),
@@ -878,6 +1499,16 @@ func (b *Builder) Save() error {
 		WarmModel:      warmConfig.Model,
 		SearchProvider: warmConfig.Endpoint,
 		SearchModel:    warmConfig.Model,
+		Remaining:      b.remaining,
+		Degradations:   b.Degradations(),
+		Files:          b.fileHashes,
+		Settings:       b.settingsHash(),
+	}
+	if b.backend.Kind == index.BackendSQLite {
+		metadata.Storage = StorageSQLite
+	}
+	if b.vectorIndex.Quantization() != index.QuantizationNone {
+		metadata.Quantization = b.vectorIndex.Quantization()
 	}
 
 	// If search provider is explicitly set, use its config
@@ -887,10 +1518,32 @@ func (b *Builder) Save() error {
 		metadata.SearchModel = searchConfig.Model
 	}
 
-	if err := saveMetadata(metadataPath, metadata); err != nil {
+	if err := replaceFile(metadataPath, func(tmp string) error { return saveMetadata(tmp, metadata) }); err != nil {
 		return fmt.Errorf("saving metadata: %w", err)
 	}
 
+	// Switch searches to the new index, then keep a pre-model-directory
+	// index under its own model directory
+	legacy := activeIndexDir(b.cacheDir) == b.cacheDir
+	if err := setActive(b.cacheDir, name); err != nil {
+		return err
+	}
+	if legacy {
+		if err := migrateLegacyIndex(b.cacheDir); err != nil {
+			return err
+		}
+	}
+
+	// Record code-health metrics for gcq trends; a build isn't failed for
+	// them. A partial build would show a drop that isn't there.
+	if b.remaining == 0 {
+		snapshot := ComputeMetrics(b.codeUnits)
+		snapshot.Model = warmConfig.Model
+		if err := recordMetrics(b.cacheDir, snapshot); err != nil {
+			fmt.Printf("Warning: recording metrics: %v\n", err)
+		}
+	}
+
 	// Save embedding cache
 	if b.embeddingCache != nil {
 		if err := b.embeddingCache.Save(); err != nil {
@@ -901,26 +1554,63 @@ func (b *Builder) Save() error {
 	return nil
 }
 
+// saveIndexFiles writes the vector index to dir, with the keyword index and
+// HNSW graph that go with it, and removes the files of the other storage
+func (b *Builder) saveIndexFiles(dir string) error {
+	if err := b.vectorIndex.SetQuantization(b.backend.Quantization); err != nil {
+		return fmt.Errorf("saving index: %w", err)
+	}
+	stale := []string{sqliteFile}
+	if b.backend.Kind == index.BackendSQLite {
+		// The database holds the keyword index and is searched without a graph
+		if err := replaceFile(filepath.Join(dir, sqliteFile), b.vectorIndex.SaveSQLite); err != nil {
+			return fmt.Errorf("saving index: %w", err)
+		}
+		stale = []string{indexFile, textIndexFile, hnswFile}
+	} else {
+		if err := replaceFile(filepath.Join(dir, indexFile), b.vectorIndex.Save); err != nil {
+			return fmt.Errorf("saving index: %w", err)
+		}
+
+		// Save the keyword index so keyword and hybrid searches can load it
+		// instead of rebuilding it
+		if b.textIndex != nil {
+			if err := replaceFile(filepath.Join(dir, textIndexFile), b.textIndex.Save); err != nil {
+				return fmt.Errorf("saving text index: %w", err)
+			}
+		}
+
+		// Build the HNSW graph now so searches don't have to; drop a graph
+		// left from an earlier build that no longer applies
+		if b.backend.UseHNSW(b.vectorIndex.Count()) {
+			if err := replaceFile(filepath.Join(dir, hnswFile), index.NewHNSW(b.vectorIndex, b.backend.HNSW).Save); err != nil {
+				return fmt.Errorf("saving hnsw graph: %w", err)
+			}
+		} else {
+			stale = append(stale, hnswFile)
+		}
+	}
+
+	for _, name := range stale {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
 // Load loads an existing index from disk
 func (b *Builder) Load() (*index.VectorIndex, *IndexMetadata, error) {
-	indexPath := filepath.Join(b.cacheDir, "index.msgpack")
-	metadataPath := filepath.Join(b.cacheDir, "metadata.json")
+	dir := activeIndexDir(b.cacheDir)
 
 	// Check if index exists
-	if _, err := os.Stat(indexPath); os.IsNotExist(err) {
-		return nil, nil, fmt.Errorf("index not found at %s", indexPath)
-	}
-
-	// Load index
-	vecIndex := index.NewVectorIndex(0)
-	if err := vecIndex.Load(indexPath); err != nil {
-		return nil, nil, fmt.Errorf("loading index: %w", err)
+	if _, err := os.Stat(filepath.Join(dir, metadataFile)); os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("index not found in %s", dir)
 	}
 
-	// Load metadata
-	metadata, err := loadMetadata(metadataPath)
+	vecIndex, metadata, err := loadIndexDir(dir)
 	if err != nil {
-		return nil, nil, fmt.Errorf("loading metadata: %w", err)
+		return nil, nil, err
 	}
 
 	b.vectorIndex = vecIndex
@@ -963,49 +1653,266 @@ func NewBuilderWithHuggingFace(rootDir, model, token string) (*Builder, error) {
 
 // BuildIndex is a convenience function to build and save a semantic index
 func BuildIndex(rootDir string, embedProvider embed.Provider) error {
+	return BuildIndexWithTemplates(rootDir, embedProvider, nil)
+}
+
+// BuildIndexWithTemplates builds and saves a semantic index, building
+// embedding text from the given per-language templates.
+func BuildIndexWithTemplates(rootDir string, embedProvider embed.Provider, templates EmbeddingTemplates) error {
+	return BuildIndexWithOptions(rootDir, embedProvider, BuildOptions{
+		Templates: templates,
+		Limits:    DefaultEmbeddingLimits(),
+	})
+}
+
+// BuildOptions customizes how BuildIndexWithOptions builds embedding text
+type BuildOptions struct {
+	// Templates builds embedding text per language; nil uses EmbeddingText
+	Templates EmbeddingTemplates
+	// Limits caps dependencies and call lists in embedding text
+	Limits EmbeddingLimits
+	// GraphContext adds callee summaries to embedding text
+	GraphContext GraphContext
+	// Backend decides whether an HNSW graph is built alongside the index
+	Backend index.BackendOptions
+	// Imports are ctags, LSIF or SCIP dumps whose definitions are indexed
+	// for files no extractor parses natively
+	Imports []string
+	// Chunks splits long function bodies into separately indexed chunks
+	Chunks ChunkOptions
+	// StableIDs gives units content based IDs and records moved and
+	// renamed units in the project's UnitIDMap
+	StableIDs bool
+	// Budget limits the build to about this long, embedding the most
+	// useful units first; zero indexes every unit
+	Budget time.Duration
+	// Enrichers annotate units with metadata before they are embedded, in
+	// addition to those added with RegisterEnricher
+	Enrichers []Enricher
+	// Todos indexes TODO, FIXME and HACK comments as todo units
+	Todos bool
+	// Commits indexes recent commit messages and merged pull requests as
+	// commit units
+	Commits CommitOptions
+	// Docs indexes the sections of Markdown and reStructuredText files as
+	// doc units
+	Docs bool
+	// Dependencies selects the imports left out of unit dependencies
+	Dependencies DependencyFilter
+	// StopSymbols are the callees left out of unit calls and callers
+	StopSymbols callgraph.StopSymbols
+	// Since updates the active index with the files git reports changed
+	// since this commit or branch, instead of the files whose content hash
+	// changed
+	Since string
+	// Full indexes every file even when the active index could be updated
+	Full bool
+	// Files updates the active index with only these files, relative to
+	// the project root, without scanning the project
+	Files []string
+	// Units updates the active index with only these units, by unit URI,
+	// without scanning the project
+	Units []string
+}
+
+// BuildStats summarizes a build
+type BuildStats struct {
+	// Indexed is the number of units in the saved index
+	Indexed int
+	// Remaining is the number of units left out when the budget ran out
+	Remaining int
+	// Degradations are the features the build skipped or cut short
+	Degradations types.Degradations
+	// Changed is the number of files an update re-indexed
+	Changed int
+	// Reused is the number of units an update kept from the active index
+	Reused int
+}
+
+// BuildIndexWithOptions builds and saves a semantic index with the given options
+func BuildIndexWithOptions(rootDir string, embedProvider embed.Provider, opts BuildOptions) error {
+	_, err := BuildIndexWithStats(rootDir, embedProvider, opts)
+	return err
+}
+
+// BuildIndexWithStats is BuildIndexWithOptions that also reports how much
+// of the project a budgeted build indexed
+func BuildIndexWithStats(rootDir string, embedProvider embed.Provider, opts BuildOptions) (BuildStats, error) {
+	var stats BuildStats
 	builder, err := NewBuilder(rootDir, embedProvider)
 	if err != nil {
-		return fmt.Errorf("creating builder: %w", err)
+		return stats, fmt.Errorf("creating builder: %w", err)
+	}
+	builder.WithEmbeddingTemplates(opts.Templates).WithEmbeddingLimits(opts.Limits).WithGraphContext(opts.GraphContext).WithBackendOptions(opts.Backend).WithChunkOptions(opts.Chunks).WithStableIDs(opts.StableIDs).WithBudget(opts.Budget).WithEnrichers(opts.Enrichers...).WithTodos(opts.Todos).WithCommits(opts.Commits).WithDocs(opts.Docs).WithDependencyFilter(opts.Dependencies).WithFullBuild(opts.Full).WithStopSymbols(opts.StopSymbols)
+
+	var imported []*CodeUnit
+	for _, dump := range opts.Imports {
+		units, err := importer.Load(dump, builder.rootDir, "")
+		if err != nil {
+			return stats, fmt.Errorf("importing symbols: %w", err)
+		}
+		fmt.Printf("Imported %d symbols from %s\n", len(units), dump)
+		imported = append(imported, units...)
+	}
+	builder.WithImportedUnits(imported)
+
+	if len(opts.Files) > 0 || len(opts.Units) > 0 {
+		builder.WithTargets(opts.Files, opts.Units)
+	}
+
+	var changed []string
+	if opts.Since != "" {
+		if changed, err = ChangedSince(builder.rootDir, opts.Since); err != nil {
+			return stats, fmt.Errorf("listing files changed since %s: %w", opts.Since, err)
+		}
+		builder.WithChangedFiles(changed)
+	}
+
+	modelSwitch, switching := DetectModelSwitch(builder.rootDir, embedProvider.Config().Model)
+	if switching {
+		fmt.Printf("Embedding model changed from %s to %s; searches keep using the %s index until the new one is built\n",
+			modelSwitch.FromModel, modelSwitch.ToModel, modelSwitch.FromModel)
 	}
 
 	vecIndex, metadata, err := builder.Build()
 	if err != nil {
-		return fmt.Errorf("building index: %w", err)
+		return stats, fmt.Errorf("building index: %w", err)
 	}
 
 	if vecIndex == nil || vecIndex.Count() == 0 {
 		fmt.Println("No code units found to index")
-		return nil
+		return stats, nil
 	}
 
 	if err := builder.Save(); err != nil {
-		return fmt.Errorf("saving index: %w", err)
+		return stats, fmt.Errorf("saving index: %w", err)
+	}
+	stats = BuildStats{Indexed: metadata.Count, Remaining: builder.Remaining(), Degradations: builder.Degradations()}
+	if builder.changed != nil {
+		stats.Changed = len(builder.changed)
+		if opts.Since != "" {
+			stats.Changed = len(changed)
+		}
+		stats.Reused = builder.Reused()
 	}
 
 	fmt.Printf("Indexed %d code units (dimension: %d, model: %s)\n",
-		metadata.Count, metadata.Dimension, metadata.Model)
-	fmt.Printf("Index saved to: %s\n", builder.GetCacheDir())
+		metadata.Count, metadata.Dimension, metadata.WarmModel)
+	switch {
+	case builder.targets != nil:
+		fmt.Printf("Re-indexed %s; kept %d other units\n", strings.Join(slices.Concat(opts.Files, opts.Units), ", "), stats.Reused)
+	case builder.changed != nil && opts.Since != "":
+		fmt.Printf("Re-indexed %d files changed since %s; kept %d units of unchanged files\n", stats.Changed, opts.Since, stats.Reused)
+	case builder.changed != nil:
+		fmt.Printf("Re-indexed %d changed files; kept %d units of unchanged files\n", stats.Changed, stats.Reused)
+	}
+	if stats.Remaining > 0 {
+		fmt.Printf("Budget of %s ran out; %d lower priority units are not indexed yet\n", opts.Budget, stats.Remaining)
+	}
+	for _, d := range stats.Degradations {
+		if d.Feature != types.DegradedPartialIndex {
+			fmt.Printf("Degraded %s: %s (%d affected)\n", d.Feature, d.Reason, d.Count)
+		}
+	}
+	if switching {
+		fmt.Printf("Switched search to the new index (dimension %d -> %d); the %s index is kept in case you switch back\n",
+			modelSwitch.FromDimension, metadata.Dimension, modelSwitch.FromModel)
+	}
+	if changes := builder.UnitIDChanges(); len(changes) > 0 {
+		fmt.Printf("%d units moved or were renamed since the last build; their old IDs still resolve (see gcq index ids)\n", len(changes))
+	}
+	fmt.Printf("Index saved to: %s\n", ActiveIndexDir(builder.rootDir))
 
-	return nil
+	return stats, nil
 }
 
-// LoadIndex loads an existing semantic index
+// LoadIndex loads the active semantic index of rootDir
 func LoadIndex(rootDir string) (*index.VectorIndex, *IndexMetadata, error) {
-	cacheDir := filepath.Join(rootDir, ".gcq", "cache", "semantic")
-	indexPath := filepath.Join(cacheDir, "index.msgpack")
-	metadataPath := filepath.Join(cacheDir, "metadata.json")
+	return loadIndexDir(ActiveIndexDir(rootDir))
+}
 
-	vecIndex := index.NewVectorIndex(0)
-	if err := vecIndex.Load(indexPath); err != nil {
-		return nil, nil, fmt.Errorf("loading index: %w", err)
-	}
+// LoadUnits returns the code units stored in the active semantic index of
+// rootDir, in file and line order. Body chunks and todo units are left out.
+func LoadUnits(rootDir string) ([]*CodeUnit, error) {
+	units, _, err := loadUnits(rootDir)
+	return units, err
+}
 
-	metadata, err := loadMetadata(metadataPath)
+// loadUnits is LoadUnits that also returns the index metadata
+func loadUnits(rootDir string) ([]*CodeUnit, *IndexMetadata, error) {
+	vecIndex, metadata, err := LoadIndex(rootDir)
 	if err != nil {
-		return nil, nil, fmt.Errorf("loading metadata: %w", err)
+		return nil, nil, err
 	}
 
-	return vecIndex, metadata, nil
+	var units []*CodeUnit
+	vecIndex.IterVectors(func(_ string, _ []float32, metadata types.EmbeddingUnit) bool {
+		if metadata.Unit != nil && !derivedUnit(metadata.Unit.Type) {
+			units = append(units, metadata.Unit)
+		}
+		return true
+	})
+	sort.Slice(units, func(i, j int) bool {
+		if units[i].FilePath != units[j].FilePath {
+			return units[i].FilePath < units[j].FilePath
+		}
+		return units[i].LineNumber < units[j].LineNumber
+	})
+	return units, metadata, nil
+}
+
+// hnswFile is the HNSW graph saved next to the index
+const hnswFile = "hnsw.msgpack"
+
+// LoadBackend returns the search backend for a semantic index loaded from
+// dir (IndexMetadata.Dir). When opts select HNSW it loads the graph saved by
+// `gcq warm`, or builds one if it is missing or stale; otherwise it returns
+// vecIndex.
+func LoadBackend(dir string, vecIndex *index.VectorIndex, opts index.BackendOptions) index.Backend {
+	if !opts.UseHNSW(vecIndex.Count()) {
+		return vecIndex
+	}
+
+	path := filepath.Join(dir, hnswFile)
+	if graph, err := index.LoadHNSW(path, vecIndex, opts.HNSW); err == nil {
+		return graph
+	}
+
+	graph := index.NewHNSW(vecIndex, opts.HNSW)
+	// Best effort: the next search can reuse the graph
+	_ = graph.Save(path)
+	return graph
+}
+
+// textIndexFile is the BM25 keyword index saved next to the index
+const textIndexFile = "text.msgpack"
+
+// LoadTextIndex returns the keyword index saved with the semantic index in
+// dir (IndexMetadata.Dir), or nil if the index was built without one
+func LoadTextIndex(dir string) *index.TextIndex {
+	textIndex := index.NewTextIndex()
+	if err := textIndex.Load(filepath.Join(dir, textIndexFile)); err != nil {
+		return nil
+	}
+	return textIndex
+}
+
+// StorageSQLite is IndexMetadata.Storage for an index saved as a SQLite
+// database with index.BackendSQLite
+const StorageSQLite = "sqlite"
+
+// sqliteFile is the SQLite database holding an index saved with
+// index.BackendSQLite, in place of the msgpack index, keyword index and graph
+const sqliteFile = "index.db"
+
+// OpenSQLiteIndex opens the SQLite database of an index loaded from dir
+// (IndexMetadata.Dir) whose Storage is StorageSQLite, for searching in place
+func OpenSQLiteIndex(dir string) (*index.SQLiteIndex, error) {
+	store, err := index.OpenSQLite(filepath.Join(dir, sqliteFile))
+	if err != nil {
+		return nil, formatError(dir, fmt.Errorf("opening index: %w", err))
+	}
+	return store, nil
 }
 
 // saveMetadata saves index metadata to a JSON file
@@ -1028,7 +1935,12 @@ func loadMetadata(path string) (*IndexMetadata, error) {
 	}
 	var metadata IndexMetadata
 	if err := json.Unmarshal(data, &metadata); err != nil {
-		return nil, fmt.Errorf("unmarshaling metadata: %w", err)
+		return nil, fmt.Errorf("%w: unmarshaling metadata: %w", index.ErrFormat, err)
+	}
+	// Metadata of older formats only lacks fields added since
+	if metadata.Version > index.FormatVersion {
+		return nil, fmt.Errorf("%w: index format %d is newer than format %d, the latest this version reads",
+			index.ErrFormat, metadata.Version, index.FormatVersion)
 	}
 	return &metadata, nil
 }
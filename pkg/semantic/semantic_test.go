@@ -1,13 +1,21 @@
 package semantic
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/l3aro/go-context-query/internal/scanner"
+	"github.com/l3aro/go-context-query/pkg/deps"
 	"github.com/l3aro/go-context-query/pkg/embed"
+	"github.com/l3aro/go-context-query/pkg/index"
 	"github.com/l3aro/go-context-query/pkg/types"
 )
 
@@ -17,7 +25,7 @@ type mockProvider struct {
 	configFn func() *embed.Config
 }
 
-func (m *mockProvider) Embed(texts []string) ([][]float32, error) {
+func (m *mockProvider) EmbedContext(_ context.Context, texts []string) ([][]float32, error) {
 	if m.embedFn != nil {
 		return m.embedFn(texts)
 	}
@@ -43,7 +51,7 @@ func (m *mockProvider) Config() *embed.Config {
 // mockProviderWithError returns a provider that always returns an error
 type mockProviderWithError struct{}
 
-func (m *mockProviderWithError) Embed(texts []string) ([][]float32, error) {
+func (m *mockProviderWithError) EmbedContext(_ context.Context, texts []string) ([][]float32, error) {
 	return nil, embed.ErrProviderUnavailable
 }
 
@@ -56,7 +64,7 @@ type mockProviderCustomEmbeddings struct {
 	dimension int
 }
 
-func (m *mockProviderCustomEmbeddings) Embed(texts []string) ([][]float32, error) {
+func (m *mockProviderCustomEmbeddings) EmbedContext(_ context.Context, texts []string) ([][]float32, error) {
 	embeddings := make([][]float32, len(texts))
 	for i := range texts {
 		vec := make([]float32, m.dimension)
@@ -164,6 +172,43 @@ func TestBuilderScan(t *testing.T) {
 	}
 }
 
+func TestBuilderExtractImportedUnits(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.py"), []byte("def main():\n    pass\n"), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	builder, err := NewBuilder(tmpDir, &mockProvider{})
+	if err != nil {
+		t.Fatalf("NewBuilder failed: %v", err)
+	}
+	builder.WithImportedUnits([]*CodeUnit{
+		{ID: "lua://src/parser.lua#tokenize", Name: "tokenize", Type: "function", FilePath: "src/parser.lua"},
+		// main.py is parsed natively, so its imported copy is dropped
+		{ID: "py://main.py#main", Name: "main", Type: "function", FilePath: "main.py", Signature: "imported"},
+	})
+
+	files, err := builder.Scan()
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	units, err := builder.Extract(files)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	var ids []string
+	for _, u := range units {
+		ids = append(ids, u.ID)
+		if u.Name == "main" && u.Signature == "imported" {
+			t.Error("imported unit replaced the natively extracted main")
+		}
+	}
+	if !slices.Contains(ids, "lua://src/parser.lua#tokenize") || !slices.Contains(ids, "py://main.py#main") {
+		t.Errorf("units = %v", ids)
+	}
+}
+
 func TestBuilderGetCacheDir(t *testing.T) {
 	tmpDir := t.TempDir()
 	provider := &mockProvider{}
@@ -729,6 +774,141 @@ func TestEmbeddingTextLongCallTruncation(t *testing.T) {
 	}
 }
 
+func TestEmbeddingTextWithLimits(t *testing.T) {
+	calls := make([]string, 50)
+	for i := range calls {
+		calls[i] = "helper_function"
+	}
+	unit := &CodeUnit{Name: "test", Type: "function", Calls: calls}
+
+	short := EmbeddingTextWithLimits(unit, EmbeddingLimits{CallListChars: 20})
+	if !strings.Contains(short, "Calls: helper_function, hel...") {
+		t.Errorf("Expected calls truncated to 20 chars, got %q", short)
+	}
+
+	full := EmbeddingTextWithLimits(unit, EmbeddingLimits{})
+	if strings.Contains(full, "...") || strings.Count(full, "helper_function") != 50 {
+		t.Errorf("Expected zero limit to keep the full call list")
+	}
+}
+
+func TestExtractSignificantDepsLimit(t *testing.T) {
+	moduleInfo := &types.ModuleInfo{}
+	for _, m := range []string{"os", "requests", "numpy", "flask", "redis", "boto3", "django", ".local"} {
+		moduleInfo.Imports = append(moduleInfo.Imports, types.Import{Module: m})
+	}
+
+	if deps := extractSignificantDeps(moduleInfo, 2); len(deps) != 2 || deps[0] != "requests" {
+		t.Errorf("Expected first 2 external deps, got %v", deps)
+	}
+	if deps := extractSignificantDeps(moduleInfo, 0); len(deps) != 6 {
+		t.Errorf("Expected all 6 external deps with no limit, got %v", deps)
+	}
+}
+
+func TestResolveDependenciesWithManifest(t *testing.T) {
+	tmpDir := t.TempDir()
+	gomod := "module example.com/app\n\nrequire github.com/redis/go-redis/v9 v9.5.1\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte(gomod), 0644); err != nil {
+		t.Fatalf("Failed to write go.mod: %v", err)
+	}
+	manifests, err := deps.Load(tmpDir)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	moduleInfo := &types.ModuleInfo{}
+	for _, m := range []string{"net/http", "example.com/app/internal/store", "github.com/redis/go-redis/v9", "github.com/redis/go-redis/v9/maintnotifications"} {
+		moduleInfo.Imports = append(moduleInfo.Imports, types.Import{Module: m})
+	}
+
+	names, versions := resolveDependencies(moduleInfo, manifests, DependencyFilter{}, "cache.go", "go", 0)
+	if len(names) != 1 || names[0] != "github.com/redis/go-redis/v9" {
+		t.Errorf("Expected only the redis module, got %v", names)
+	}
+	if versions["github.com/redis/go-redis/v9"] != "v9.5.1" {
+		t.Errorf("Expected redis version v9.5.1, got %v", versions)
+	}
+}
+
+// TestBuildPersistsCodeUnits tests that the full CodeUnit survives a save/load round trip.
+func TestBuildPersistsCodeUnits(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	src := "def helper():\n    pass\n\ndef greet(name):\n    \"\"\"Say hello.\"\"\"\n    helper()\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, "app.py"), []byte(src), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	builder, err := NewBuilder(tmpDir, &mockProvider{})
+	if err != nil {
+		t.Fatalf("NewBuilder failed: %v", err)
+	}
+	if _, _, err := builder.Build(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if err := builder.Save(); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, _, err := LoadIndex(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadIndex failed: %v", err)
+	}
+
+	_, meta, ok := loaded.Get("py://app.py#greet")
+	if !ok {
+		t.Fatal("Expected greet to be indexed under its unit URI")
+	}
+	unit := meta.Unit
+	if unit == nil {
+		t.Fatal("Expected persisted CodeUnit in index payload")
+	}
+	if unit.Name != "greet" || unit.Language != "python" || unit.FilePath != "app.py" {
+		t.Errorf("Unexpected persisted unit: %+v", unit)
+	}
+	if unit.LineNumber != 4 || unit.Docstring == "" || unit.Signature == "" {
+		t.Errorf("Expected line, docstring and signature to be persisted, got %+v", unit)
+	}
+	if len(unit.Calls) == 0 {
+		t.Errorf("Expected call graph data to be persisted, got %+v", unit)
+	}
+}
+
+func TestBuildTagsTestUnits(t *testing.T) {
+	tmpDir := t.TempDir()
+	sources := map[string]string{
+		"app.py":      "def greet(name):\n    pass\n",
+		"test_app.py": "def test_greet():\n    greet('x')\n",
+	}
+	for name, src := range sources {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(src), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	builder, err := NewBuilder(tmpDir, &mockProvider{})
+	if err != nil {
+		t.Fatalf("NewBuilder failed: %v", err)
+	}
+	files, err := builder.Scan()
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	units, err := builder.Extract(files)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	tagged := make(map[string]bool)
+	for _, unit := range units {
+		tagged[unit.Name] = unit.IsTest
+	}
+	if tagged["greet"] || !tagged["test_greet"] {
+		t.Errorf("Expected only test_greet to be tagged as a test, got %v", tagged)
+	}
+}
+
 // TestGoSemanticIndexing tests the full semantic indexing pipeline for Go files.
 // This test verifies: scan → extract → embed → index for Go code.
 func TestGoSemanticIndexing(t *testing.T) {
@@ -1499,3 +1679,291 @@ func TestIndexMetadataIsCompatibleWith(t *testing.T) {
 		})
 	}
 }
+
+// TestExtractInterfaceUnits tests that interfaces and traits are indexed as
+// their own unit types with full method signatures, not as classes.
+func TestExtractInterfaceUnits(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"embed.go": `package embed
+
+// Provider generates embeddings
+type Provider interface {
+	Embed(texts []string) ([][]float32, error)
+	Dimension() int
+}
+
+type Client struct{}
+`,
+		"lib.rs": `pub trait Embedder {
+    fn embed(&self, text: &str) -> Vec<f32>;
+}
+`,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, path), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	builder, err := NewBuilder(tmpDir, &mockProvider{})
+	if err != nil {
+		t.Fatalf("NewBuilder failed: %v", err)
+	}
+	scanned, err := builder.Scan()
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	units, err := builder.Extract(scanned)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	byName := make(map[string][]*CodeUnit)
+	for _, u := range units {
+		byName[u.Name] = append(byName[u.Name], u)
+	}
+
+	provider := byName["Provider"]
+	if len(provider) != 1 || provider[0].Type != "interface" {
+		t.Fatalf("Expected one interface unit for Provider, got %+v", provider)
+	}
+	wantSig := "type Provider interface { Embed(texts []string) ([][]float32, error); Dimension() int }"
+	if provider[0].Signature != wantSig {
+		t.Errorf("Signature = %q, want %q", provider[0].Signature, wantSig)
+	}
+	if !strings.Contains(EmbeddingText(provider[0]), "Embed(texts []string)") {
+		t.Error("Expected method signatures in the interface's embedding text")
+	}
+	if m := byName["Provider.Embed"]; len(m) != 1 || m[0].Type != "method" {
+		t.Errorf("Expected one method unit for Provider.Embed, got %+v", m)
+	}
+	if c := byName["Client"]; len(c) != 1 || c[0].Type != "class" {
+		t.Errorf("Expected structs to remain class units, got %+v", c)
+	}
+
+	embedder := byName["Embedder"]
+	if len(embedder) != 1 || embedder[0].Type != "trait" {
+		t.Fatalf("Expected one trait unit for Embedder, got %+v", embedder)
+	}
+	if !strings.Contains(embedder[0].Signature, "trait Embedder { embed(") {
+		t.Errorf("Unexpected trait signature %q", embedder[0].Signature)
+	}
+}
+
+// TestExtractGoMethodURIs tests that Go methods of the same name on
+// different types of a package get their own units, qualified by receiver
+func TestExtractGoMethodURIs(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"idx/vector.go": `package idx
+
+type VectorIndex struct{}
+
+// Add stores a vector
+func (v *VectorIndex) Add(id string) {}
+
+// Sum adds two numbers
+func Sum(a, b int) int { return a + b }
+`,
+		"idx/text.go": `package idx
+
+type TextIndex struct{}
+
+// Add stores a document
+func (t TextIndex) Add(id string) {}
+`,
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(tmpDir, path)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, path), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	builder, err := NewBuilder(tmpDir, &mockProvider{})
+	if err != nil {
+		t.Fatalf("NewBuilder failed: %v", err)
+	}
+	scanned, err := builder.Scan()
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	units, err := builder.Extract(scanned)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	ids := make(map[string]int)
+	for _, u := range units {
+		ids[u.ID]++
+	}
+	for _, want := range []string{"go://idx#VectorIndex.Add", "go://idx#TextIndex.Add", "go://idx#Sum"} {
+		if ids[want] != 1 {
+			t.Errorf("Expected one unit %s, got %d (IDs %v)", want, ids[want], ids)
+		}
+	}
+	if ids["go://idx#Add"] != 0 {
+		t.Errorf("Expected no unit without its receiver, got IDs %v", ids)
+	}
+}
+
+// TestExtractGenericSignatures tests that Go type parameters and their
+// constraints appear in unit signatures
+func TestExtractGenericSignatures(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := `package stack
+
+type Stack[T any] struct {
+	items []T
+}
+
+type Getter[K comparable, V any] interface {
+	Get(key K) (V, bool)
+}
+
+func Map[T, U any](xs []T, f func(T) U) []U {
+	return nil
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "stack.go"), []byte(src), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	builder, err := NewBuilder(tmpDir, &mockProvider{})
+	if err != nil {
+		t.Fatalf("NewBuilder failed: %v", err)
+	}
+	scanned, err := builder.Scan()
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	units, err := builder.Extract(scanned)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	want := map[string]string{
+		"Stack":  "type Stack[T any] struct { items []T }",
+		"Getter": "type Getter[K comparable, V any] interface { Get(key K) (V, bool) }",
+		"Map":    "func Map[T, U any](xs []T, f func(T) U) []U",
+	}
+	for _, u := range units {
+		if sig, ok := want[u.Name]; ok {
+			if u.Signature != sig {
+				t.Errorf("%s signature = %q, want %q", u.Name, u.Signature, sig)
+			}
+			delete(want, u.Name)
+		}
+	}
+	for name := range want {
+		t.Errorf("No unit for %s", name)
+	}
+}
+
+func TestGoStructSignatureFields(t *testing.T) {
+	cls := types.Class{Name: "Config", Fields: []types.Field{
+		{Name: "Reader", Type: "io.Reader", Embedded: true},
+		{Name: "SocketPath", Type: "string", Tag: `yaml:"socket_path"`, Doc: "where the daemon listens"},
+	}}
+	want := "type Config struct { io.Reader; SocketPath string `yaml:\"socket_path\"` }"
+	if got := formatClassSignatureForLang(cls, "go"); got != want {
+		t.Errorf("formatClassSignatureForLang() = %q, want %q", got, want)
+	}
+}
+
+func TestLoadBackend(t *testing.T) {
+	cacheDir := t.TempDir()
+
+	vecIndex := index.NewVectorIndex(3)
+	vecIndex.Add("a", []float32{1, 0, 0}, types.EmbeddingUnit{})
+	vecIndex.Add("b", []float32{0, 1, 0}, types.EmbeddingUnit{})
+
+	if backend := LoadBackend(cacheDir, vecIndex, index.BackendOptions{}); backend != index.Backend(vecIndex) {
+		t.Errorf("Expected the flat index below the auto threshold, got %T", backend)
+	}
+
+	opts := index.BackendOptions{Kind: index.BackendHNSW}
+	backend := LoadBackend(cacheDir, vecIndex, opts)
+	if _, ok := backend.(*index.HNSW); !ok {
+		t.Fatalf("Expected an HNSW backend, got %T", backend)
+	}
+	graphPath := filepath.Join(cacheDir, hnswFile)
+	if _, err := os.Stat(graphPath); err != nil {
+		t.Errorf("Expected the built graph to be saved: %v", err)
+	}
+	if _, err := index.LoadHNSW(graphPath, vecIndex, opts.HNSW); err != nil {
+		t.Errorf("Saved graph could not be loaded: %v", err)
+	}
+
+	results, err := backend.Search([]float32{0.9, 0.1, 0}, 1)
+	if err != nil || len(results) != 1 || results[0].ID != "a" {
+		t.Errorf("Search() = %v, %v; want a", results, err)
+	}
+}
+
+// TestBuildAndSearchReplayed runs the build and search pipeline against a
+// recorded provider cassette, with the provider offline during replay
+func TestBuildAndSearchReplayed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Prompt string `json:"prompt"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		vec := []float32{0.1, 0.1, 0.1}
+		if strings.Contains(req.Prompt, "socket") {
+			vec = []float32{0.9, 0.1, 0.1}
+		}
+		json.NewEncoder(w).Encode(map[string][]float32{"embedding": vec})
+	}))
+	defer srv.Close()
+
+	project := t.TempDir()
+	src := "def open_socket(host):\n    \"\"\"Open a socket to host.\"\"\"\n    pass\n\n\ndef parse_config(path):\n    pass\n"
+	if err := os.WriteFile(filepath.Join(project, "net.py"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cassette := filepath.Join(t.TempDir(), "pipeline.json")
+
+	run := func(record bool) []index.SearchResult {
+		t.Helper()
+		rec, err := embed.NewRecorder(cassette, record, nil)
+		if err != nil {
+			t.Fatalf("NewRecorder failed: %v", err)
+		}
+		provider, err := embed.NewOllamaProvider(&embed.Config{Endpoint: srv.URL, Transport: rec})
+		if err != nil {
+			t.Fatal(err)
+		}
+		builder, err := NewBuilder(project, provider)
+		if err != nil {
+			t.Fatalf("NewBuilder failed: %v", err)
+		}
+		idx, _, err := builder.Build()
+		if err != nil {
+			t.Fatalf("Build failed: %v", err)
+		}
+		query, err := provider.Embed([]string{"socket connection"})
+		if err != nil {
+			t.Fatalf("embedding query: %v", err)
+		}
+		results, err := idx.Search(query[0], 1)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		return results
+	}
+
+	recorded := run(true)
+	srv.Close()
+	replayed := run(false)
+
+	if len(replayed) != 1 || !strings.HasSuffix(replayed[0].ID, "open_socket") {
+		t.Fatalf("replayed search = %+v, want open_socket", replayed)
+	}
+	if replayed[0].ID != recorded[0].ID {
+		t.Errorf("replayed result %s differs from recorded %s", replayed[0].ID, recorded[0].ID)
+	}
+}
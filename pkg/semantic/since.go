@@ -0,0 +1,87 @@
+package semantic
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// ChangedSince returns the files under rootDir that differ from the commit
+// or branch ref: files changed, added or removed by the commits since ref,
+// plus uncommitted and untracked changes. Paths are relative to rootDir
+// with forward slashes, sorted. Renamed files are listed under both names.
+func ChangedSince(rootDir, ref string) ([]string, error) {
+	repo, err := git.PlainOpenWithOptions(rootDir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("opening git repository: %w", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("opening worktree: %w", err)
+	}
+	prefix, err := filepath.Rel(wt.Filesystem.Root(), rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("locating %s in the repository: %w", rootDir, err)
+	}
+	prefix = filepath.ToSlash(prefix)
+	if prefix == "." {
+		prefix = ""
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", ref, err)
+	}
+	since, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("reading commit %s: %w", ref, err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("resolving HEAD: %w", err)
+	}
+	current, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("reading HEAD: %w", err)
+	}
+	sinceTree, err := since.Tree()
+	if err != nil {
+		return nil, err
+	}
+	currentTree, err := current.Tree()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := object.DiffTree(sinceTree, currentTree)
+	if err != nil {
+		return nil, fmt.Errorf("diffing %s against HEAD: %w", ref, err)
+	}
+
+	seen := make(map[string]bool)
+	for _, change := range changes {
+		seen[change.From.Name] = true
+		seen[change.To.Name] = true
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return nil, fmt.Errorf("reading worktree status: %w", err)
+	}
+	for name, s := range status {
+		if s.Staging != git.Unmodified || s.Worktree != git.Unmodified {
+			seen[name] = true
+		}
+	}
+	delete(seen, "")
+
+	files := make([]string, 0, len(seen))
+	for name := range seen {
+		files = append(files, name)
+	}
+	files = filesUnder(files, prefix)
+	sort.Strings(files)
+	return files, nil
+}
@@ -0,0 +1,99 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestChangedSince(t *testing.T) {
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := commitFiles(t, repo, root, "Add service", map[string]string{
+		"svc/a.go":  "package svc\n",
+		"svc/b.go":  "package svc\n",
+		"README.md": "docs\n",
+	})
+	commitFiles(t, repo, root, "Change b", map[string]string{
+		"svc/b.go":  "package svc\n\nfunc B() {}\n",
+		"svc/c.go":  "package svc\n",
+		"README.md": "more docs\n",
+	})
+	if err := os.WriteFile(filepath.Join(root, "svc", "d.go"), []byte("package svc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := ChangedSince(root, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"README.md", "svc/b.go", "svc/c.go", "svc/d.go"}; !slices.Equal(changed, want) {
+		t.Errorf("ChangedSince() = %q, want %q", changed, want)
+	}
+
+	// A project in a subdirectory sees its own files, relative to it
+	changed, err = ChangedSince(filepath.Join(root, "svc"), "master~1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"b.go", "c.go", "d.go"}; !slices.Equal(changed, want) {
+		t.Errorf("ChangedSince() in svc = %q, want %q", changed, want)
+	}
+
+	if _, err := ChangedSince(root, "no-such-branch"); err == nil {
+		t.Error("ChangedSince() of an unknown ref succeeded")
+	}
+}
+
+func TestBuildSince(t *testing.T) {
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := commitFiles(t, repo, root, "Add modules", map[string]string{
+		".gitignore": ".gcq/\n",
+		"keep.py":    "def kept():\n    pass\n",
+		"edit.py":    "def before():\n    pass\n",
+		"gone.py":    "def removed():\n    pass\n",
+	})
+	provider := &mockProvider{}
+	if _, err := BuildIndexWithStats(root, provider, BuildOptions{}); err != nil {
+		t.Fatalf("full build: %v", err)
+	}
+
+	if err := os.Remove(filepath.Join(root, "gone.py")); err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, repo, root, "Edit modules", map[string]string{
+		"edit.py": "def after():\n    pass\n",
+		"new.py":  "def added():\n    pass\n",
+	})
+
+	stats, err := BuildIndexWithStats(root, provider, BuildOptions{Since: base})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if stats.Changed != 3 || stats.Reused != 1 {
+		t.Errorf("stats = %+v, want 3 changed files and 1 reused unit", stats)
+	}
+
+	units, err := LoadUnits(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, u := range units {
+		names = append(names, u.Name)
+	}
+	slices.Sort(names)
+	if want := []string{"added", "after", "kept"}; !slices.Equal(names, want) {
+		t.Errorf("units after update = %q, want %q", names, want)
+	}
+}
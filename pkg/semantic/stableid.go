@@ -0,0 +1,270 @@
+package semantic
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// unitIDsFile holds the UnitIDMap of a project. Like the metrics history it
+// sits in the semantic cache directory, since IDs don't depend on the model.
+const unitIDsFile = "unit_ids.json"
+
+// maxUnitIDChanges is the number of changes kept; older ones are dropped
+const maxUnitIDChanges = 5000
+
+// stableIDPrefix marks stable IDs, which are not unit URIs
+const stableIDPrefix = "sid:"
+
+// Kinds of UnitIDChange
+const (
+	UnitMoved        = "moved"
+	UnitRenamed      = "renamed"
+	UnitMovedRenamed = "moved_renamed"
+)
+
+// UnitIDChange records a unit whose URI changed between two builds while
+// its content stayed the same
+type UnitIDChange struct {
+	OldID string `json:"old_id"`
+	NewID string `json:"new_id"`
+	// StableID is the unit's stable ID after the change
+	StableID string `json:"stable_id,omitempty"`
+	// Kind is UnitMoved, UnitRenamed or UnitMovedRenamed
+	Kind string    `json:"kind"`
+	Time time.Time `json:"time"`
+}
+
+// UnitIDMap maps the URIs of units that moved or were renamed to their
+// current URIs, oldest change first
+type UnitIDMap struct {
+	Changes []UnitIDChange `json:"changes"`
+}
+
+// WithStableIDs enables stable IDs: units get a StableID and ContentHash,
+// and Save records units that moved or were renamed since the previous
+// build in the project's UnitIDMap
+func (b *Builder) WithStableIDs(enabled bool) *Builder {
+	b.stableIDs = enabled
+	return b
+}
+
+// UnitIDChanges returns the units Save found moved or renamed
+func (b *Builder) UnitIDChanges() []UnitIDChange {
+	return b.idChanges
+}
+
+// setStableID sets the ContentHash of unit from its body in lines, when its
+// end line is known, and its StableID from its type, signature and body.
+// Whitespace and blank lines are ignored, so reindenting a unit keeps it.
+func setStableID(unit *CodeUnit, lines []string) {
+	if unit.EndLine > unit.LineNumber && unit.EndLine <= len(lines) {
+		unit.ContentHash = hashLines(lines[unit.LineNumber:unit.EndLine])
+	}
+	signature := unit.Signature
+	if signature == "" {
+		signature = unit.Name
+	}
+	unit.StableID = stableIDPrefix + hashLines([]string{unit.Language, unit.Type, signature, unit.ContentHash})
+}
+
+// hashLines returns a short hash of lines with whitespace collapsed and
+// blank lines dropped, or "" when all are blank
+func hashLines(lines []string) string {
+	h := sha256.New()
+	empty := true
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		empty = false
+		h.Write([]byte(strings.Join(fields, " ")))
+		h.Write([]byte{'\n'})
+	}
+	if empty {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// DiffUnitIDs finds the units of previous whose URI is gone from current
+// but whose content is: a unit with the same stable ID moved, and one with
+// the same type and body renamed. Each current unit matches at most once.
+func DiffUnitIDs(previous, current []*CodeUnit, now time.Time) []UnitIDChange {
+	currentIDs := make(map[string]bool, len(current))
+	for _, u := range current {
+		currentIDs[u.ID] = true
+	}
+	previousIDs := make(map[string]bool, len(previous))
+	for _, u := range previous {
+		previousIDs[u.ID] = true
+	}
+
+	// Candidates are units whose URI is new in this build
+	byStableID := make(map[string][]*CodeUnit)
+	byContent := make(map[string][]*CodeUnit)
+	for _, u := range current {
+		if previousIDs[u.ID] || u.Type == UnitTypeChunk {
+			continue
+		}
+		if u.StableID != "" {
+			byStableID[u.StableID] = append(byStableID[u.StableID], u)
+		}
+		if u.ContentHash != "" {
+			key := u.Type + "\x00" + u.ContentHash
+			byContent[key] = append(byContent[key], u)
+		}
+	}
+
+	used := make(map[string]bool)
+	take := func(candidates []*CodeUnit) *CodeUnit {
+		for _, c := range candidates {
+			if !used[c.ID] {
+				used[c.ID] = true
+				return c
+			}
+		}
+		return nil
+	}
+
+	var changes []UnitIDChange
+	for _, old := range previous {
+		if currentIDs[old.ID] || old.Type == UnitTypeChunk {
+			continue
+		}
+		var match *CodeUnit
+		if old.StableID != "" {
+			match = take(byStableID[old.StableID])
+		}
+		if match == nil && old.ContentHash != "" {
+			match = take(byContent[old.Type+"\x00"+old.ContentHash])
+		}
+		if match == nil {
+			continue
+		}
+
+		kind := UnitMovedRenamed
+		switch {
+		case match.Name == old.Name:
+			kind = UnitMoved
+		case match.FilePath == old.FilePath:
+			kind = UnitRenamed
+		}
+		changes = append(changes, UnitIDChange{OldID: old.ID, NewID: match.ID, StableID: match.StableID, Kind: kind, Time: now})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].OldID < changes[j].OldID })
+	return changes
+}
+
+// merge adds changes to the map. Earlier changes that led to a unit that
+// changed again now lead to its new URI, and a unit moved back to an old
+// URI no longer maps away from it.
+func (m *UnitIDMap) merge(changes []UnitIDChange) {
+	if len(changes) == 0 {
+		return
+	}
+	next := make(map[string]UnitIDChange, len(changes))
+	for _, c := range changes {
+		next[c.OldID] = c
+	}
+
+	kept := m.Changes[:0]
+	for _, c := range m.Changes {
+		if _, ok := next[c.NewID]; ok {
+			c.NewID, c.StableID = next[c.NewID].NewID, next[c.NewID].StableID
+		}
+		if _, ok := next[c.OldID]; ok || c.OldID == c.NewID {
+			// Superseded by this build's change, or back where it started
+			continue
+		}
+		kept = append(kept, c)
+	}
+	m.Changes = append(kept, changes...)
+	if len(m.Changes) > maxUnitIDChanges {
+		m.Changes = m.Changes[len(m.Changes)-maxUnitIDChanges:]
+	}
+}
+
+// Resolve returns the current URI of a unit that moved or was renamed
+func (m *UnitIDMap) Resolve(id string) (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	for i := len(m.Changes) - 1; i >= 0; i-- {
+		if m.Changes[i].OldID == id {
+			return m.Changes[i].NewID, true
+		}
+	}
+	return "", false
+}
+
+// IsStableID reports whether s is a stable ID rather than a unit URI or name
+func IsStableID(s string) bool {
+	return strings.HasPrefix(s, stableIDPrefix)
+}
+
+// LoadUnitIDMap returns the ID map of rootDir. A project built without
+// stable IDs has an empty map.
+func LoadUnitIDMap(rootDir string) (*UnitIDMap, error) {
+	return loadUnitIDMap(semanticCacheDir(rootDir))
+}
+
+func loadUnitIDMap(cacheDir string) (*UnitIDMap, error) {
+	data, err := os.ReadFile(filepath.Join(cacheDir, unitIDsFile))
+	if os.IsNotExist(err) {
+		return &UnitIDMap{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading unit ID map: %w", err)
+	}
+	var m UnitIDMap
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parsing unit ID map: %w", err)
+	}
+	return &m, nil
+}
+
+// recordUnitIDChanges compares the units of the active index in cacheDir,
+// if any, with units and adds the moved and renamed ones to the ID map
+func recordUnitIDChanges(cacheDir string, units []*CodeUnit) ([]UnitIDChange, error) {
+	previousIndex, _, err := loadIndexDir(activeIndexDir(cacheDir))
+	if err != nil {
+		// First build: nothing to compare with
+		return nil, nil
+	}
+	var previous []*CodeUnit
+	previousIndex.IterVectors(func(_ string, _ []float32, metadata types.EmbeddingUnit) bool {
+		if metadata.Unit != nil {
+			previous = append(previous, metadata.Unit)
+		}
+		return true
+	})
+
+	changes := DiffUnitIDs(previous, units, time.Now())
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	m, err := loadUnitIDMap(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+	m.merge(changes)
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding unit ID map: %w", err)
+	}
+	err = replaceFile(filepath.Join(cacheDir, unitIDsFile), func(tmp string) error {
+		return os.WriteFile(tmp, data, 0644)
+	})
+	return changes, err
+}
@@ -0,0 +1,151 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSetStableID(t *testing.T) {
+	lines := []string{"def parse(text):", "    words = text.split()", "", "    return words"}
+	a := &CodeUnit{Name: "parse", Type: "function", Language: "python", Signature: "def parse(text)", LineNumber: 1, EndLine: 4}
+	setStableID(a, lines)
+	if !IsStableID(a.StableID) || a.ContentHash == "" {
+		t.Fatalf("stable ID not set: %+v", a)
+	}
+
+	// Moved and reindented: same IDs
+	moved := []string{"", "  def parse(text):", "      words  = text.split()", "      return words"}
+	b := &CodeUnit{Name: "parse", Type: "function", Language: "python", Signature: "def parse(text)", LineNumber: 2, EndLine: 4, FilePath: "other.py"}
+	setStableID(b, moved)
+	if b.StableID != a.StableID || b.ContentHash != a.ContentHash {
+		t.Errorf("moved unit got %s/%s, want %s/%s", b.StableID, b.ContentHash, a.StableID, a.ContentHash)
+	}
+
+	// Renamed: same body, new stable ID
+	c := &CodeUnit{Name: "tokenize", Type: "function", Language: "python", Signature: "def tokenize(text)", LineNumber: 1, EndLine: 4}
+	setStableID(c, append([]string{"def tokenize(text):"}, lines[1:]...))
+	if c.ContentHash != a.ContentHash || c.StableID == a.StableID {
+		t.Errorf("renamed unit got %s/%s", c.StableID, c.ContentHash)
+	}
+}
+
+func TestDiffUnitIDs(t *testing.T) {
+	previous := []*CodeUnit{
+		{ID: "py://a.py#parse", Name: "parse", Type: "function", FilePath: "a.py", StableID: "sid:1", ContentHash: "h1"},
+		{ID: "py://a.py#load", Name: "load", Type: "function", FilePath: "a.py", StableID: "sid:2", ContentHash: "h2"},
+		{ID: "py://a.py#save", Name: "save", Type: "function", FilePath: "a.py", StableID: "sid:3", ContentHash: "h3"},
+		{ID: "py://a.py#keep", Name: "keep", Type: "function", FilePath: "a.py", StableID: "sid:4", ContentHash: "h4"},
+		{ID: "py://a.py#gone", Name: "gone", Type: "function", FilePath: "a.py", StableID: "sid:5", ContentHash: "h5"},
+	}
+	current := []*CodeUnit{
+		{ID: "py://b.py#parse", Name: "parse", Type: "function", FilePath: "b.py", StableID: "sid:1", ContentHash: "h1"},
+		{ID: "py://a.py#read", Name: "read", Type: "function", FilePath: "a.py", StableID: "sid:6", ContentHash: "h2"},
+		{ID: "py://b.py#store", Name: "store", Type: "function", FilePath: "b.py", StableID: "sid:7", ContentHash: "h3"},
+		{ID: "py://a.py#keep", Name: "keep", Type: "function", FilePath: "a.py", StableID: "sid:8", ContentHash: "h9"},
+	}
+
+	changes := DiffUnitIDs(previous, current, time.Now())
+	want := map[string][2]string{
+		"py://a.py#parse": {"py://b.py#parse", UnitMoved},
+		"py://a.py#load":  {"py://a.py#read", UnitRenamed},
+		"py://a.py#save":  {"py://b.py#store", UnitMovedRenamed},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %+v", changes)
+	}
+	for _, c := range changes {
+		if w, ok := want[c.OldID]; !ok || c.NewID != w[0] || c.Kind != w[1] {
+			t.Errorf("change %+v, want %v", c, w)
+		}
+	}
+}
+
+func TestUnitIDMapMerge(t *testing.T) {
+	m := &UnitIDMap{}
+	m.merge([]UnitIDChange{{OldID: "py://a.py#f", NewID: "py://b.py#f"}})
+	m.merge([]UnitIDChange{{OldID: "py://b.py#f", NewID: "py://c.py#f"}})
+
+	for _, old := range []string{"py://a.py#f", "py://b.py#f"} {
+		if got, ok := m.Resolve(old); !ok || got != "py://c.py#f" {
+			t.Errorf("Resolve(%s) = %q, %v", old, got, ok)
+		}
+	}
+
+	// Moving back to the first file drops the mapping away from it
+	m.merge([]UnitIDChange{{OldID: "py://c.py#f", NewID: "py://a.py#f"}})
+	if got, ok := m.Resolve("py://a.py#f"); ok {
+		t.Errorf("Resolve(a.py#f) = %q after moving back", got)
+	}
+	if got, _ := m.Resolve("py://b.py#f"); got != "py://a.py#f" {
+		t.Errorf("Resolve(b.py#f) = %q, want a.py#f", got)
+	}
+}
+
+func TestBuildRecordsMovedUnits(t *testing.T) {
+	tmpDir := t.TempDir()
+	src := "def helper():\n    return 41\n\n\ndef answer():\n    \"\"\"The answer.\"\"\"\n    value = helper()\n    return value + 1\n"
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	build := func() *Builder {
+		builder, err := NewBuilder(tmpDir, &mockProvider{})
+		if err != nil {
+			t.Fatalf("NewBuilder failed: %v", err)
+		}
+		builder.WithStableIDs(true)
+		if _, _, err := builder.Build(); err != nil {
+			t.Fatalf("Build failed: %v", err)
+		}
+		if err := builder.Save(); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		return builder
+	}
+
+	write("app.py", src)
+	build()
+
+	// Move answer to another file and rename helper
+	write("app.py", "def compute():\n    return 41\n")
+	write("answers.py", "from app import compute\n\n\ndef answer():\n    \"\"\"The answer.\"\"\"\n    value = helper()\n    return value + 1\n")
+	builder := build()
+
+	if got := len(builder.UnitIDChanges()); got != 2 {
+		t.Errorf("UnitIDChanges = %+v, want 2 changes", builder.UnitIDChanges())
+	}
+	ids, err := LoadUnitIDMap(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadUnitIDMap failed: %v", err)
+	}
+	if got, _ := ids.Resolve("py://app.py#answer"); got != "py://answers.py#answer" {
+		t.Errorf("answer resolves to %q", got)
+	}
+	if got, _ := ids.Resolve("py://app.py#helper"); got != "py://app.py#compute" {
+		t.Errorf("helper resolves to %q", got)
+	}
+
+	graph, err := LoadCallerGraph(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadCallerGraph failed: %v", err)
+	}
+	if got := graph.Find("py://app.py#answer", ""); len(got) != 1 || got[0].FilePath != "answers.py" {
+		t.Errorf("Find(old URI) = %+v", got)
+	}
+	if got := graph.Find(answerStableID(t, graph), ""); len(got) != 1 {
+		t.Errorf("Find(stable ID) = %+v", got)
+	}
+}
+
+// answerStableID returns the stable ID of answer in g
+func answerStableID(t *testing.T, g *CallerGraph) string {
+	t.Helper()
+	units := g.Find("answer", "")
+	if len(units) != 1 || units[0].StableID == "" {
+		t.Fatalf("answer has no stable ID: %+v", units)
+	}
+	return units[0].StableID
+}
@@ -0,0 +1,38 @@
+package semantic
+
+import (
+	"strings"
+
+	"github.com/l3aro/go-context-query/pkg/extractor"
+)
+
+// TagsMetadataKey is the metadata key gcq:tag comments are stored under,
+// as a comma separated list, so --meta tags=billing finds the units tagged
+// billing
+const TagsMetadataKey = "tags"
+
+// tagUnits stores the gcq:tag tags of a file's units in their metadata.
+// Chunk and todo units take the tags of the unit they belong to, or the
+// file's tags when they belong to none.
+func tagUnits(d *extractor.Directives, units, derived []*CodeUnit) {
+	byID := make(map[string][]string, len(units))
+	for _, unit := range units {
+		tags := d.UnitTags(unit.Name)
+		byID[unit.ID] = tags
+		setTags(unit, tags)
+	}
+	for _, unit := range derived {
+		tags, ok := byID[unit.Parent]
+		if !ok {
+			tags = d.FileTags
+		}
+		setTags(unit, tags)
+	}
+}
+
+// setTags stores tags in unit's metadata, when there are any
+func setTags(unit *CodeUnit, tags []string) {
+	if len(tags) > 0 {
+		SetMetadata(unit, TagsMetadataKey, strings.Join(tags, ","))
+	}
+}
@@ -0,0 +1,47 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuilderExtractDirectives(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"pay.py":     "# gcq:tag billing\n\n\ndef charge():\n    return 1\n\n\n# gcq:ignore-next\ndef debug_dump():\n    pass\n\n\n# gcq:tag refunds\ndef refund():\n    return 0\n",
+		"scratch.py": "# gcq:ignore-file\n\n\ndef experiment():\n    pass\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(src), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	builder, err := NewBuilder(tmpDir, &mockProvider{})
+	if err != nil {
+		t.Fatalf("NewBuilder failed: %v", err)
+	}
+	scanned, err := builder.Scan()
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	units, err := builder.Extract(scanned)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	found := map[string]*CodeUnit{}
+	for _, u := range units {
+		found[u.Name] = u
+	}
+	if found["debug_dump"] != nil || found["experiment"] != nil {
+		t.Errorf("ignored units were indexed: %v", found)
+	}
+	want := map[string]string{"charge": "billing", "refund": "billing,refunds"}
+	for name, tags := range want {
+		if u := found[name]; u == nil || u.Metadata[TagsMetadataKey] != tags {
+			t.Errorf("%s = %+v, want tags %s", name, u, tags)
+		}
+	}
+}
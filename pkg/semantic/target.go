@@ -0,0 +1,132 @@
+package semantic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/l3aro/go-context-query/internal/scanner"
+	"github.com/l3aro/go-context-query/pkg/index"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// WithTargets makes Build update the active index with only the given
+// files, relative to the project root, and the files defining the given
+// units, by unit URI, without scanning the project. Of a target unit's
+// file only the unit itself, with its body chunks, is replaced.
+func (b *Builder) WithTargets(files, units []string) *Builder {
+	b.targets = make(map[string]bool, len(files))
+	for _, f := range files {
+		b.targets[filepath.ToSlash(filepath.Clean(f))] = true
+	}
+	b.targetUnits = nil
+	if len(units) > 0 {
+		b.targetUnits = make(map[string]bool, len(units))
+		for _, id := range units {
+			b.targetUnits[id] = true
+		}
+	}
+	return b
+}
+
+// targetFiles resolves the targets of WithTargets against the active index
+// to the files to extract, and marks them changed
+func (b *Builder) targetFiles(previous *index.VectorIndex) ([]scanner.FileInfo, error) {
+	paths := make(map[string]bool, len(b.targets))
+	for path := range b.targets {
+		paths[path] = true
+	}
+	for id := range b.targetUnits {
+		_, metadata, ok := previous.Get(id)
+		if !ok || metadata.Unit == nil {
+			return nil, fmt.Errorf("unit %s is not in the index", id)
+		}
+		paths[filepath.ToSlash(metadata.Unit.FilePath)] = true
+	}
+
+	b.changed = make(map[string]bool, len(paths))
+	files := make([]scanner.FileInfo, 0, len(paths))
+	for path := range paths {
+		fullPath := filepath.Join(b.rootDir, filepath.FromSlash(path))
+		info, err := os.Stat(fullPath)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("%s is a directory", path)
+		}
+		lang := scanner.DetectLanguage(filepath.Ext(path))
+		if lang == "" {
+			return nil, fmt.Errorf("%s is not in a supported language", path)
+		}
+		b.changed[path] = true
+		files = append(files, scanner.FileInfo{Path: path, FullPath: fullPath, Language: lang, Size: info.Size()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// targetUnitsOf keeps the freshly extracted units Build replaces in the
+// active index: every unit of a target file, or a target unit and its body
+// chunks. The call graph of a targeted build covers the target files only,
+// so calls and callers in other files are carried over from the units'
+// previous copies, and callee summaries are attached again across the
+// whole index.
+func (b *Builder) targetUnitsOf(previous *index.VectorIndex, units []*CodeUnit) ([]*CodeUnit, error) {
+	if b.targetUnits != nil {
+		kept := units[:0]
+		found := make(map[string]bool, len(b.targetUnits))
+		for _, unit := range units {
+			switch {
+			case b.targets[filepath.ToSlash(unit.FilePath)]:
+			case b.targetUnits[unit.ID]:
+				found[unit.ID] = true
+			case b.targetUnits[unit.Parent]:
+			default:
+				continue
+			}
+			kept = append(kept, unit)
+		}
+		for id := range b.targetUnits {
+			if !found[id] {
+				return nil, fmt.Errorf("unit %s is no longer defined", id)
+			}
+		}
+		units = kept
+	}
+
+	others := func(refs []string) []string {
+		var kept []string
+		for _, ref := range refs {
+			file, _, _ := strings.Cut(ref, ":")
+			if !b.changed[file] {
+				kept = append(kept, ref)
+			}
+		}
+		return kept
+	}
+	fresh := make(map[string]bool, len(units))
+	for _, unit := range units {
+		fresh[unit.ID] = true
+		if _, metadata, ok := previous.Get(unit.ID); ok && metadata.Unit != nil {
+			unit.Calls = append(unit.Calls, others(metadata.Unit.Calls)...)
+			unit.CalledBy = append(unit.CalledBy, others(metadata.Unit.CalledBy)...)
+		}
+	}
+
+	if b.graph.Callees > 0 {
+		all := append([]*CodeUnit(nil), units...)
+		previous.Iterate(func(id string, metadata types.EmbeddingUnit) bool {
+			if metadata.Unit != nil && !fresh[id] {
+				// A copy, so kept units keep the summaries they were embedded with
+				unit := *metadata.Unit
+				all = append(all, &unit)
+			}
+			return true
+		})
+		AttachCalleeSummaries(all, b.graph)
+	}
+	return units, nil
+}
@@ -0,0 +1,86 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuildTargets(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("app.py", "from helpers import helper\n\ndef run():\n    helper()\n")
+	write("helpers.py", "def helper():\n    pass\n\ndef other():\n    pass\n")
+	provider := &mockProvider{}
+
+	if _, err := BuildIndexWithStats(root, provider, BuildOptions{Files: []string{"app.py"}}); err == nil {
+		t.Error("targeted build without an index succeeded")
+	}
+	if _, err := BuildIndexWithStats(root, provider, BuildOptions{}); err != nil {
+		t.Fatalf("full build: %v", err)
+	}
+
+	// Only the target file is extracted; edits elsewhere wait for a warm
+	write("app.py", "from helpers import helper\n\ndef run():\n    \"\"\"Run it.\"\"\"\n    helper()\n")
+	write("helpers.py", "def renamed():\n    pass\n\ndef other():\n    pass\n")
+	stats, err := BuildIndexWithStats(root, provider, BuildOptions{Files: []string{"app.py"}})
+	if err != nil {
+		t.Fatalf("file build: %v", err)
+	}
+	if stats.Changed != 1 || stats.Reused != 2 {
+		t.Errorf("file build stats = %+v, want 1 changed file and 2 reused units", stats)
+	}
+	units, err := LoadUnits(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, u := range units {
+		names = append(names, u.Name)
+		if u.Name == "run" {
+			if !strings.Contains(u.Docstring, "Run it.") {
+				t.Errorf("run docstring = %q, want the edited one", u.Docstring)
+			}
+			if !slices.ContainsFunc(u.Calls, func(c string) bool { return strings.HasPrefix(c, "helpers.py:") }) {
+				t.Errorf("run calls = %q, want the call into helpers.py kept", u.Calls)
+			}
+		}
+	}
+	slices.Sort(names)
+	if want := []string{"helper", "other", "run"}; !slices.Equal(names, want) {
+		t.Errorf("units after file build = %q, want %q", names, want)
+	}
+
+	// A target unit is replaced alone; it must still be defined
+	write("helpers.py", "def helper():\n    pass\n\ndef other():\n    \"\"\"Other.\"\"\"\n")
+	stats, err = BuildIndexWithStats(root, provider, BuildOptions{Units: []string{"py://helpers.py#other"}})
+	if err != nil {
+		t.Fatalf("unit build: %v", err)
+	}
+	if stats.Reused != 2 {
+		t.Errorf("unit build stats = %+v, want 2 reused units", stats)
+	}
+	if _, err := BuildIndexWithStats(root, provider, BuildOptions{Units: []string{"py://helpers.py#missing"}}); err == nil {
+		t.Error("build of a unit not in the index succeeded")
+	}
+	write("helpers.py", "def helper():\n    pass\n")
+	if _, err := BuildIndexWithStats(root, provider, BuildOptions{Units: []string{"py://helpers.py#other"}}); err == nil {
+		t.Error("build of a unit no longer defined succeeded")
+	}
+
+	// The next warm extracts the targets again
+	stats, err = BuildIndexWithStats(root, provider, BuildOptions{})
+	if err != nil {
+		t.Fatalf("warm: %v", err)
+	}
+	if stats.Changed != 2 {
+		t.Errorf("warm stats = %+v, want the 2 targeted files changed", stats)
+	}
+}
@@ -0,0 +1,87 @@
+package semantic
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// DefaultTemplateKey is the templates entry used for languages without their own template.
+const DefaultTemplateKey = "default"
+
+// templateFuncs are the helper functions available to embedding text templates.
+var templateFuncs = template.FuncMap{
+	// join joins a list with ", "
+	"join": func(items []string) string {
+		return strings.Join(items, ", ")
+	},
+	// truncate cuts s to at most n bytes, appending "..." when shortened
+	"truncate": func(n int, s string) string {
+		if len(s) <= n {
+			return s
+		}
+		return types.TruncateUTF8(s, n) + "..."
+	},
+	// title upper-cases the first letter of s
+	"title": func(s string) string {
+		r, size := utf8.DecodeRuneInString(s)
+		if r == utf8.RuneError {
+			return s
+		}
+		return string(unicode.ToUpper(r)) + s[size:]
+	},
+}
+
+// EmbeddingTemplates holds parsed text/template templates for building
+// embedding text, keyed by language with DefaultTemplateKey as the fallback.
+// Templates are executed against the CodeUnit, so they can reference fields
+// such as .Name, .Type, .Signature, .Docstring, .Calls, .CalledBy,
+// .CalleeSummaries, .Dependencies, .DependencyVersions, .CFGSummary,
+// .DFGSummary and .Metadata.
+type EmbeddingTemplates map[string]*template.Template
+
+// ParseEmbeddingTemplates parses template sources keyed by language.
+func ParseEmbeddingTemplates(sources map[string]string) (EmbeddingTemplates, error) {
+	if len(sources) == 0 {
+		return nil, nil
+	}
+
+	templates := make(EmbeddingTemplates, len(sources))
+	for lang, src := range sources {
+		tmpl, err := template.New(lang).Funcs(templateFuncs).Parse(src)
+		if err != nil {
+			return nil, fmt.Errorf("parsing embedding template for %s: %w", lang, err)
+		}
+		templates[lang] = tmpl
+	}
+
+	return templates, nil
+}
+
+// Text builds the embedding text for unit using the template for its language,
+// falling back to the default template and then to EmbeddingText.
+func (t EmbeddingTemplates) Text(unit *CodeUnit) (string, error) {
+	return t.TextWithLimits(unit, DefaultEmbeddingLimits())
+}
+
+// TextWithLimits is like Text but falls back to EmbeddingTextWithLimits
+func (t EmbeddingTemplates) TextWithLimits(unit *CodeUnit, limits EmbeddingLimits) (string, error) {
+	tmpl, ok := t[unit.Language]
+	if !ok {
+		tmpl, ok = t[DefaultTemplateKey]
+	}
+	if !ok {
+		return EmbeddingTextWithLimits(unit, limits), nil
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, unit); err != nil {
+		return "", fmt.Errorf("executing embedding template for %s: %w", unit.Name, err)
+	}
+
+	return strings.TrimSpace(sb.String()), nil
+}
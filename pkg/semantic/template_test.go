@@ -0,0 +1,99 @@
+package semantic
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseEmbeddingTemplatesEmpty(t *testing.T) {
+	templates, err := ParseEmbeddingTemplates(nil)
+	if err != nil {
+		t.Fatalf("ParseEmbeddingTemplates failed: %v", err)
+	}
+
+	unit := &CodeUnit{Name: "run", Type: "function", Signature: "def run()"}
+	text, err := templates.Text(unit)
+	if err != nil {
+		t.Fatalf("Text failed: %v", err)
+	}
+	if text != EmbeddingText(unit) {
+		t.Errorf("Expected built-in embedding text without templates, got %q", text)
+	}
+}
+
+func TestParseEmbeddingTemplatesInvalid(t *testing.T) {
+	_, err := ParseEmbeddingTemplates(map[string]string{"go": "{{.Name"})
+	if err == nil {
+		t.Fatal("Expected error for malformed template")
+	}
+	if !strings.Contains(err.Error(), "go") {
+		t.Errorf("Expected error to name the language, got %v", err)
+	}
+}
+
+func TestEmbeddingTemplatesPerLanguage(t *testing.T) {
+	templates, err := ParseEmbeddingTemplates(map[string]string{
+		DefaultTemplateKey: "{{.Name}}",
+		"go":               "{{title .Type}} {{.Name}}\nCalls: {{join .Calls}}\nDocs: {{truncate 5 .Docstring}}",
+	})
+	if err != nil {
+		t.Fatalf("ParseEmbeddingTemplates failed: %v", err)
+	}
+
+	goUnit := &CodeUnit{
+		Name:      "Add",
+		Type:      "method",
+		Language:  "go",
+		Docstring: "Add inserts a vector",
+		Calls:     []string{"index.go:grow", "index.go:norm"},
+	}
+	text, err := templates.Text(goUnit)
+	if err != nil {
+		t.Fatalf("Text failed: %v", err)
+	}
+	want := "Method Add\nCalls: index.go:grow, index.go:norm\nDocs: Add i..."
+	if text != want {
+		t.Errorf("Text = %q, want %q", text, want)
+	}
+
+	pyUnit := &CodeUnit{Name: "run", Language: "python"}
+	text, err = templates.Text(pyUnit)
+	if err != nil {
+		t.Fatalf("Text failed: %v", err)
+	}
+	if text != "run" {
+		t.Errorf("Expected default template for python, got %q", text)
+	}
+}
+
+func TestBuilderEmbedUsesTemplates(t *testing.T) {
+	var seen []string
+	provider := &mockProvider{
+		embedFn: func(texts []string) ([][]float32, error) {
+			seen = append(seen, texts...)
+			out := make([][]float32, len(texts))
+			for i := range texts {
+				out[i] = []float32{0.1, 0.2, 0.3}
+			}
+			return out, nil
+		},
+	}
+
+	builder, err := NewBuilder(t.TempDir(), provider)
+	if err != nil {
+		t.Fatalf("NewBuilder failed: %v", err)
+	}
+
+	templates, err := ParseEmbeddingTemplates(map[string]string{DefaultTemplateKey: "unit {{.Name}}"})
+	if err != nil {
+		t.Fatalf("ParseEmbeddingTemplates failed: %v", err)
+	}
+	builder.WithEmbeddingTemplates(templates)
+
+	if _, err := builder.Embed([]*CodeUnit{{Name: "templated_unit_xyz"}}); err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(seen) != 1 || seen[0] != "unit templated_unit_xyz" {
+		t.Errorf("Expected templated text to be embedded, got %v", seen)
+	}
+}
@@ -0,0 +1,186 @@
+package semantic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+
+	"github.com/l3aro/go-context-query/internal/scanner"
+	"github.com/l3aro/go-context-query/pkg/extractor"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// UnitTypeTodo is the type of units holding a TODO, FIXME or HACK comment
+const UnitTypeTodo = "todo"
+
+// todoContextLines is the number of lines kept above and below a TODO
+const todoContextLines = 2
+
+// todoPattern matches a TODO, FIXME or HACK marker with an optional owner,
+// written as TODO(alice) or TODO @alice, and the text after it
+var todoPattern = regexp.MustCompile(`\b(TODO|FIXME|HACK)\b(?:\s*\(@?([^)]*)\)|\s+@([\w.-]+))?\s*[:\-]?\s*(.*)`)
+
+// Todo is a TODO, FIXME or HACK comment
+type Todo struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	// Marker is TODO, FIXME or HACK
+	Marker string `json:"marker"`
+	// Owner is the name in TODO(owner) or TODO @owner, if any
+	Owner string `json:"owner,omitempty"`
+	Text  string `json:"text"`
+	// Context holds the lines around the comment
+	Context string `json:"context,omitempty"`
+	// ContextStart is the line Context starts on
+	ContextStart int `json:"context_start,omitempty"`
+}
+
+// WithTodos enables indexing TODO, FIXME and HACK comments as todo units
+func (b *Builder) WithTodos(enabled bool) *Builder {
+	b.todos = enabled
+	return b
+}
+
+// derivedUnit reports whether units of type t are body chunks, TODO
+// comments, commits or doc sections rather than code definitions. Unit
+// counts, metrics and the call graph leave them out.
+func derivedUnit(t string) bool {
+	return t == UnitTypeChunk || t == UnitTypeTodo || t == UnitTypeCommit || t == UnitTypeDoc
+}
+
+// ScanTodos returns the TODO, FIXME and HACK comments of every file under
+// rootDir that a parser supports, by file and line
+func ScanTodos(rootDir string) ([]Todo, error) {
+	files, err := scanner.New(scanner.DefaultOptions()).Scan(rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("scanning %s: %w", rootDir, err)
+	}
+	registry := extractor.GetLanguageRegistry()
+	var todos []Todo
+	for _, f := range files {
+		source, err := os.ReadFile(f.FullPath)
+		if err != nil {
+			continue
+		}
+		todos = append(todos, fileTodos(registry, f.FullPath, filepath.ToSlash(f.Path), source)...)
+	}
+	sort.SliceStable(todos, func(i, j int) bool {
+		if todos[i].File != todos[j].File {
+			return todos[i].File < todos[j].File
+		}
+		return todos[i].Line < todos[j].Line
+	})
+	return todos, nil
+}
+
+// fileTodos parses a source file and returns the TODOs in its comments.
+// It returns nil for files no parser supports.
+func fileTodos(registry *extractor.LanguageRegistry, filePath, relPath string, source []byte) []Todo {
+	parser, err := registry.GetParser(filePath)
+	if err != nil {
+		return nil
+	}
+	source = extractor.ScriptSource(filePath, source)
+	tree := parser.Parse(nil, source)
+	if tree == nil {
+		return nil
+	}
+	defer tree.Close()
+
+	lines := strings.Split(string(source), "\n")
+	var todos []Todo
+	var walk func(n *sitter.Node)
+	walk = func(n *sitter.Node) {
+		if strings.Contains(n.Type(), "comment") {
+			start := int(n.StartPoint().Row) + 1
+			for i, text := range strings.Split(n.Content(source), "\n") {
+				if todo, ok := parseTodo(text); ok {
+					todo.File = relPath
+					todo.Line = start + i
+					todo.ContextStart, todo.Context = todoContext(lines, todo.Line)
+					todos = append(todos, todo)
+				}
+			}
+			return
+		}
+		for i := 0; i < int(n.ChildCount()); i++ {
+			walk(n.Child(i))
+		}
+	}
+	walk(tree.RootNode())
+	return todos
+}
+
+// parseTodo reads the marker, owner and text of a comment line
+func parseTodo(line string) (Todo, bool) {
+	m := todoPattern.FindStringSubmatch(line)
+	if m == nil {
+		return Todo{}, false
+	}
+	owner := strings.TrimSpace(m[2])
+	if owner == "" {
+		owner = m[3]
+	}
+	text := strings.TrimSpace(m[4])
+	for _, suffix := range []string{"*/", "-->", "#}"} {
+		text = strings.TrimSpace(strings.TrimSuffix(text, suffix))
+	}
+	return Todo{Marker: m[1], Owner: owner, Text: text}, true
+}
+
+// todoContext returns the first line and the text of the lines around
+// line, counted from 1
+func todoContext(lines []string, line int) (int, string) {
+	start := max(line-todoContextLines, 1)
+	end := min(line+todoContextLines, len(lines))
+	for end > line && strings.TrimSpace(lines[end-1]) == "" {
+		end--
+	}
+	return start, strings.Join(lines[start-1:end], "\n")
+}
+
+// todoUnits returns a todo unit for each TODO in a file, with the innermost
+// unit around it as its parent
+func todoUnits(todos []Todo, lang string, units []*CodeUnit) []*CodeUnit {
+	result := make([]*CodeUnit, 0, len(todos))
+	for _, todo := range todos {
+		name := todo.Marker
+		if todo.Owner != "" {
+			name += "(" + todo.Owner + ")"
+		}
+		unit := &CodeUnit{
+			ID:         types.NewUnitURI(lang, todo.File, fmt.Sprintf("%s@%d", strings.ToLower(todo.Marker), todo.Line)).String(),
+			Language:   lang,
+			Name:       name,
+			Type:       UnitTypeTodo,
+			FilePath:   todo.File,
+			LineNumber: todo.Line,
+			EndLine:    todo.Line,
+			Signature:  strings.TrimSpace(name + ": " + todo.Text),
+			Docstring:  todo.Text,
+			Code:       todo.Context,
+		}
+		if todo.Owner != "" {
+			SetMetadata(unit, "owner", todo.Owner)
+		}
+		var parent *CodeUnit
+		for _, u := range units {
+			if derivedUnit(u.Type) || u.LineNumber > todo.Line || max(u.EndLine, u.LineNumber) < todo.Line {
+				continue
+			}
+			if parent == nil || u.LineNumber > parent.LineNumber {
+				parent = u
+			}
+		}
+		if parent != nil {
+			unit.Parent = parent.ID
+		}
+		result = append(result, unit)
+	}
+	return result
+}
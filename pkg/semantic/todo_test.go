@@ -0,0 +1,111 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const todoSource = `# TODO(alice): split this module
+def charge(card):
+    # FIXME @bob handle declined cards
+    msg = "TODO not a comment"
+    return 1  # HACK: retry twice
+
+
+def refund():
+    """TODO in a docstring is ignored"""
+    return 0
+`
+
+func TestParseTodo(t *testing.T) {
+	tests := []struct {
+		line string
+		want Todo
+		ok   bool
+	}{
+		{"// TODO(alice): split this", Todo{Marker: "TODO", Owner: "alice", Text: "split this"}, true},
+		{"# FIXME @bob.smith handle errors", Todo{Marker: "FIXME", Owner: "bob.smith", Text: "handle errors"}, true},
+		{"/* HACK - sleep */", Todo{Marker: "HACK", Text: "sleep"}, true},
+		{"// TODO", Todo{Marker: "TODO"}, true},
+		{"// todo lowercase", Todo{}, false},
+		{"// TODOS are plural", Todo{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseTodo(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseTodo(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestScanTodos(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pay.py"), []byte(todoSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	todos, err := ScanTodos(dir)
+	if err != nil {
+		t.Fatalf("ScanTodos failed: %v", err)
+	}
+	if len(todos) != 3 {
+		t.Fatalf("todos = %+v, want 3", todos)
+	}
+	fixme := todos[1]
+	if fixme.File != "pay.py" || fixme.Line != 3 || fixme.Marker != "FIXME" || fixme.Owner != "bob" {
+		t.Errorf("fixme = %+v", fixme)
+	}
+	if fixme.ContextStart != 1 || fixme.Context == "" {
+		t.Errorf("fixme context starts at %d: %q", fixme.ContextStart, fixme.Context)
+	}
+	if todos[2].Line != 5 || todos[2].Marker != "HACK" {
+		t.Errorf("trailing comment = %+v", todos[2])
+	}
+}
+
+func TestBuilderExtractTodos(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "pay.py"), []byte(todoSource), 0644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	builder, err := NewBuilder(tmpDir, &mockProvider{})
+	if err != nil {
+		t.Fatalf("NewBuilder failed: %v", err)
+	}
+	builder.WithTodos(true)
+
+	files, err := builder.Scan()
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	units, err := builder.Extract(files)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	var charge *CodeUnit
+	todos := map[int]*CodeUnit{}
+	for _, u := range units {
+		switch {
+		case u.Type == UnitTypeTodo:
+			todos[u.LineNumber] = u
+		case u.Name == "charge":
+			charge = u
+		}
+	}
+	if charge == nil || len(todos) != 3 {
+		t.Fatalf("charge = %v, todos = %v", charge, todos)
+	}
+	fixme := todos[3]
+	if fixme.Name != "FIXME(bob)" || fixme.Metadata["owner"] != "bob" || fixme.Parent != charge.ID {
+		t.Errorf("fixme = %+v", fixme)
+	}
+	if todos[1].Parent != "" {
+		t.Errorf("module level TODO has parent %q", todos[1].Parent)
+	}
+	if m := ComputeMetrics(units); m.Units != len(units)-3 {
+		t.Errorf("metrics counted %d units, want %d", m.Units, len(units)-3)
+	}
+}
@@ -0,0 +1,275 @@
+package semantic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/l3aro/go-context-query/internal/scanner"
+	"github.com/l3aro/go-context-query/pkg/cache"
+	"github.com/l3aro/go-context-query/pkg/index"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// WithChangedFiles makes Build update the active index with only the given
+// files, relative to the project root, instead of the files whose content
+// hash changed. Files no longer present drop out.
+func (b *Builder) WithChangedFiles(files []string) *Builder {
+	b.changed = make(map[string]bool, len(files))
+	for _, f := range files {
+		b.changed[filepath.ToSlash(f)] = true
+	}
+	return b
+}
+
+// WithFullBuild makes Build extract and embed every file rather than
+// update the active index
+func (b *Builder) WithFullBuild(full bool) *Builder {
+	b.full = full
+	return b
+}
+
+// Reused returns the number of units Build kept from the active index
+// without extracting or embedding them again
+func (b *Builder) Reused() int {
+	return b.reused
+}
+
+// Changed returns the files an update of the active index extracted, or
+// nil when Build indexed every file
+func (b *Builder) Changed() []string {
+	if b.changed == nil {
+		return nil
+	}
+	files := make([]string, 0, len(b.changed))
+	for f := range b.changed {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// unchanged reports whether Build skips extracting the file at relPath
+// because it is updating the active index and the file did not change
+func (b *Builder) unchanged(relPath string) bool {
+	return b.changed != nil && !b.changed[filepath.ToSlash(relPath)]
+}
+
+// indexToUpdate returns the active index when Build can update it, or nil
+// to index every file: with WithFullBuild, or when there is no complete
+// index built with the warm model and the same settings
+func (b *Builder) indexToUpdate() *index.VectorIndex {
+	if b.full {
+		b.changed = nil
+		return nil
+	}
+	previous, metadata, err := loadIndexDir(activeIndexDir(b.cacheDir))
+	var reason string
+	switch {
+	case errors.Is(err, index.ErrFormat):
+		reason = "The index was written by another version of gcq and can't be read"
+	case err != nil || metadata.WarmModel != b.embedProvider.Config().Model || metadata.Remaining > 0:
+		// Nothing to say on a first build
+		if b.changed != nil && b.targets == nil {
+			reason = "No complete index built with this model to update"
+		}
+	case metadata.Settings != b.settingsHash():
+		if b.targets == nil {
+			reason = "Index settings changed"
+		}
+	case b.changed == nil && metadata.Files == nil:
+		// Built before file hashes were recorded
+	default:
+		b.previousFiles = metadata.Files
+		b.previousDegradations = metadata.Degradations
+		b.reusable = make(map[string][]float32)
+		previous.IterVectors(func(_ string, vector []float32, unit types.EmbeddingUnit) bool {
+			if unit.Unit != nil && unit.Unit.TextHash != "" {
+				b.reusable[unit.Unit.TextHash] = vector
+			}
+			return true
+		})
+		return previous
+	}
+	if reason != "" {
+		fmt.Printf("%s; indexing every file\n", reason)
+	}
+	b.changed = nil
+	return nil
+}
+
+// unitIDScheme numbers the ways unit IDs have been built; bumping it
+// rebuilds indexes rather than mixing IDs of both. Scheme 2 qualifies Go
+// methods by their receiver type.
+const unitIDScheme = 2
+
+// settingsHash identifies the settings that shape units and their
+// embedding text; an index built with other settings is not updated
+func (b *Builder) settingsHash() string {
+	templates := make(map[string]string, len(b.templates))
+	for lang, t := range b.templates {
+		if t != nil && t.Tree != nil {
+			templates[lang] = t.Tree.Root.String()
+		}
+	}
+	var enrichers []string
+	for _, e := range b.activeEnrichers() {
+		enrichers = append(enrichers, e.Name())
+	}
+	data, err := json.Marshal(struct {
+		Templates    map[string]string
+		Limits       EmbeddingLimits
+		Graph        GraphContext
+		Chunks       ChunkOptions
+		StableIDs    bool
+		Todos        bool
+		Docs         bool
+		Dependencies DependencyFilter
+		Enrichers    []string
+		StopSymbols  map[string][]string
+		UnitIDs      int
+	}{templates, b.limits, b.graph, b.chunks, b.stableIDs, b.todos, b.docs, b.depFilter, enrichers, b.stopSymbols, unitIDScheme})
+	if err != nil {
+		return ""
+	}
+	return cache.HashBytes(data)
+}
+
+// hashFiles records the hash of each file Extract indexes: its content
+// folded with the dependency manifests, the call graph edges touching it
+// and, with graph context, the content of the files it calls. When
+// updating the active index without WithChangedFiles or WithTargets, it
+// marks the files whose hash changed; with graph context, the files their
+// units call are extracted again too, so callee summaries can be attached.
+func (b *Builder) hashFiles(languageFiles map[string][]string, files []scanner.FileInfo, callsMap map[string][]string, manifests string) {
+	content := make(map[string]string)
+	read := func(fullPath, relPath string) {
+		data, err := os.ReadFile(fullPath)
+		if err != nil {
+			return
+		}
+		content[filepath.ToSlash(relPath)] = cache.HashBytes(data)
+	}
+	for _, paths := range languageFiles {
+		for _, fullPath := range paths {
+			if _, err := b.extractor.GetExtractor(fullPath); err != nil {
+				continue
+			}
+			relPath, err := filepath.Rel(b.rootDir, fullPath)
+			if err != nil {
+				continue
+			}
+			read(fullPath, relPath)
+		}
+	}
+	if b.docs {
+		for _, f := range files {
+			if IsDocLanguage(f.Language) {
+				read(f.FullPath, f.Path)
+			}
+		}
+	}
+
+	edges := make(map[string][]string)
+	callees := make(map[string]map[string]bool)
+	for caller, calls := range callsMap {
+		callerFile, _, _ := strings.Cut(caller, ":")
+		for _, callee := range calls {
+			calleeFile, _, _ := strings.Cut(callee, ":")
+			edge := caller + ">" + callee
+			edges[callerFile] = append(edges[callerFile], edge)
+			if calleeFile == callerFile {
+				continue
+			}
+			edges[calleeFile] = append(edges[calleeFile], edge)
+			if callees[callerFile] == nil {
+				callees[callerFile] = make(map[string]bool)
+			}
+			callees[callerFile][calleeFile] = true
+		}
+	}
+
+	// A targeted build resolves calls among the target files only, so
+	// their hashes are dropped and the next warm extracts them again
+	if b.targets != nil {
+		b.fileHashes = make(map[string]string, len(b.previousFiles))
+		for path, h := range b.previousFiles {
+			if !b.changed[path] {
+				b.fileHashes[path] = h
+			}
+		}
+		return
+	}
+
+	b.fileHashes = make(map[string]string, len(content))
+	for path, h := range content {
+		parts := edges[path]
+		if b.graph.Callees > 0 {
+			for callee := range callees[path] {
+				parts = append(parts, callee+"="+content[callee])
+			}
+		}
+		if len(parts) > 0 || manifests != "" {
+			sort.Strings(parts)
+			h = cache.HashString(h + "\n" + strings.Join(parts, "\n") + "\n" + manifests)
+		}
+		b.fileHashes[path] = h
+	}
+
+	if b.changed == nil {
+		if b.previousFiles == nil {
+			return
+		}
+		b.changed = make(map[string]bool)
+		for path, h := range b.fileHashes {
+			if b.previousFiles[path] != h {
+				b.changed[path] = true
+			}
+		}
+	}
+	if b.graph.Callees > 0 {
+		for _, path := range b.Changed() {
+			for callee := range callees[path] {
+				b.changed[callee] = true
+			}
+		}
+	}
+}
+
+// mergeUnchanged appends the units of previous that belong to indexed,
+// unchanged files, and their vectors, to the freshly embedded units.
+// Units extracted again win over their previous copies; commit units are
+// made anew by every build but a targeted one, which keeps every unit
+// outside its targets.
+func (b *Builder) mergeUnchanged(previous *index.VectorIndex, units []*CodeUnit, embeddings [][]float32) ([]*CodeUnit, [][]float32) {
+	fresh := make(map[string]bool, len(units))
+	for _, unit := range units {
+		fresh[unit.ID] = true
+	}
+
+	b.reused = 0
+	previous.IterVectors(func(id string, vector []float32, metadata types.EmbeddingUnit) bool {
+		unit := metadata.Unit
+		if unit == nil || fresh[id] {
+			return true
+		}
+		path := filepath.ToSlash(unit.FilePath)
+		switch {
+		case b.targets == nil:
+			if unit.Type == UnitTypeCommit || b.fileHashes[path] == "" || b.changed[path] {
+				return true
+			}
+		case b.targetUnits[unit.Parent] || b.targets[path]:
+			return true
+		}
+		units = append(units, unit)
+		embeddings = append(embeddings, append([]float32(nil), vector...))
+		b.reused++
+		return true
+	})
+	return units, embeddings
+}
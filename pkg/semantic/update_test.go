@@ -0,0 +1,94 @@
+package semantic
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestBuildUpdate(t *testing.T) {
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		"keep.py": "def kept():\n    pass\n",
+		"edit.py": "def before():\n    pass\n",
+		"gone.py": "def removed():\n    pass\n",
+	} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var embedded int
+	provider := &mockProvider{embedFn: func(texts []string) ([][]float32, error) {
+		embedded += len(texts)
+		embeddings := make([][]float32, len(texts))
+		for i := range texts {
+			embeddings[i] = []float32{1.0, 2.0, float32(embedded + i)}
+		}
+		return embeddings, nil
+	}}
+	if _, err := BuildIndexWithStats(root, provider, BuildOptions{}); err != nil {
+		t.Fatalf("full build: %v", err)
+	}
+
+	// Nothing changed: every unit is kept
+	stats, err := BuildIndexWithStats(root, provider, BuildOptions{})
+	if err != nil {
+		t.Fatalf("unchanged rebuild: %v", err)
+	}
+	if stats.Changed != 0 || stats.Reused != 3 {
+		t.Errorf("unchanged rebuild stats = %+v, want 0 changed files and 3 reused units", stats)
+	}
+
+	if err := os.Remove(filepath.Join(root, "gone.py")); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"edit.py": "def after():\n    pass\n",
+		"new.py":  "def added():\n    pass\n",
+	} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	embedded = 0
+	stats, err = BuildIndexWithStats(root, provider, BuildOptions{})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if stats.Changed != 2 || stats.Reused != 1 || embedded != 2 {
+		t.Errorf("update stats = %+v with %d embedded, want 2 changed files, 1 reused unit and 2 embedded", stats, embedded)
+	}
+	units, err := LoadUnits(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, u := range units {
+		names = append(names, u.Name)
+		if u.TextHash == "" {
+			t.Errorf("unit %s has no text hash", u.Name)
+		}
+	}
+	slices.Sort(names)
+	if want := []string{"added", "after", "kept"}; !slices.Equal(names, want) {
+		t.Errorf("units after update = %q, want %q", names, want)
+	}
+
+	// Other settings and Full index every file
+	for _, tt := range []struct {
+		name string
+		opts BuildOptions
+	}{
+		{"settings", BuildOptions{Todos: true}},
+		{"full", BuildOptions{Todos: true, Full: true}},
+	} {
+		stats, err := BuildIndexWithStats(root, provider, tt.opts)
+		if err != nil {
+			t.Fatalf("%s rebuild: %v", tt.name, err)
+		}
+		if stats.Changed != 0 || stats.Reused != 0 {
+			t.Errorf("%s rebuild stats = %+v, want every file indexed", tt.name, stats)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package symbols
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Scoring constants, as in fzf: every matched character scores scoreMatch,
+// gaps between matches cost scoreGapStart then scoreGapExtension per
+// character, and matches at word starts, camelCase humps and digits after
+// letters earn bonuses. The bonus of the first pattern character counts
+// double.
+const (
+	scoreMatch        = 16
+	scoreGapStart     = -3
+	scoreGapExtension = -1
+
+	bonusBoundary            = scoreMatch / 2
+	bonusNonWord             = scoreMatch / 2
+	bonusCamel123            = bonusBoundary + scoreGapExtension
+	bonusConsecutive         = -(scoreGapStart + scoreGapExtension)
+	bonusFirstCharMultiplier = 2
+)
+
+type charClass int
+
+const (
+	charNonWord charClass = iota
+	charLower
+	charUpper
+	charNumber
+)
+
+func classOf(r rune) charClass {
+	switch {
+	case unicode.IsLower(r):
+		return charLower
+	case unicode.IsUpper(r):
+		return charUpper
+	case unicode.IsDigit(r):
+		return charNumber
+	case unicode.IsLetter(r):
+		return charLower
+	}
+	return charNonWord
+}
+
+// bonusFor returns the bonus of matching a character of class cur that
+// follows one of class prev
+func bonusFor(prev, cur charClass) int {
+	if prev == charNonWord && cur != charNonWord {
+		return bonusBoundary
+	}
+	if prev == charLower && cur == charUpper || prev != charNumber && cur == charNumber {
+		return bonusCamel123
+	}
+	if cur == charNonWord {
+		return bonusNonWord
+	}
+	return 0
+}
+
+// Score matches pattern against text the way fzf's default algorithm does:
+// the pattern characters must appear in text in order, and among the
+// occurrences the shortest one ending at the first full match is scored.
+// Matching ignores case unless pattern has an upper case letter. It returns
+// the score and the rune offsets of the matched characters, or false when
+// text doesn't contain the pattern.
+func Score(pattern, text string) (int, []int, bool) {
+	if pattern == "" {
+		return 0, nil, true
+	}
+	caseSensitive := false
+	for _, r := range pattern {
+		if unicode.IsUpper(r) {
+			caseSensitive = true
+			break
+		}
+	}
+	fold := func(r rune) rune {
+		if caseSensitive {
+			return r
+		}
+		return unicode.ToLower(r)
+	}
+
+	pat := []rune(pattern)
+	for i, r := range pat {
+		pat[i] = fold(r)
+	}
+	runes := make([]rune, 0, utf8.RuneCountInString(text))
+	for _, r := range text {
+		runes = append(runes, r)
+	}
+
+	// Forward: find where the first full match ends
+	start, end, pi := -1, -1, 0
+	for i, r := range runes {
+		if fold(r) != pat[pi] {
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+		pi++
+		if pi == len(pat) {
+			end = i + 1
+			break
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	// Backward: shrink the match to the shortest one ending there
+	pi = len(pat) - 1
+	for i := end - 1; i >= start; i-- {
+		if fold(runes[i]) != pat[pi] {
+			continue
+		}
+		pi--
+		if pi < 0 {
+			start = i
+			break
+		}
+	}
+
+	score, positions := scoreRange(runes, pat, start, end, fold)
+	return score, positions, true
+}
+
+// scoreRange scores the match of pat in runes[start:end]
+func scoreRange(runes, pat []rune, start, end int, fold func(rune) rune) (int, []int) {
+	positions := make([]int, 0, len(pat))
+	score, pi, consecutive, firstBonus := 0, 0, 0, 0
+	inGap := false
+	prevClass := charNonWord
+	if start > 0 {
+		prevClass = classOf(runes[start-1])
+	}
+
+	for i := start; i < end; i++ {
+		class := classOf(runes[i])
+		if pi < len(pat) && fold(runes[i]) == pat[pi] {
+			positions = append(positions, i)
+			score += scoreMatch
+			bonus := bonusFor(prevClass, class)
+			if consecutive == 0 {
+				firstBonus = bonus
+			} else {
+				// A run of matches keeps the bonus of its first character
+				if bonus >= bonusBoundary && bonus > firstBonus {
+					firstBonus = bonus
+				}
+				bonus = max(bonus, firstBonus, bonusConsecutive)
+			}
+			if pi == 0 {
+				score += bonus * bonusFirstCharMultiplier
+			} else {
+				score += bonus
+			}
+			inGap = false
+			consecutive++
+			pi++
+		} else {
+			if inGap {
+				score += scoreGapExtension
+			} else {
+				score += scoreGapStart
+			}
+			inGap = true
+			consecutive = 0
+			firstBonus = 0
+		}
+		prevClass = class
+	}
+	return score, positions
+}
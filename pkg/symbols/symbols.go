@@ -0,0 +1,139 @@
+// Package symbols lists the functions, classes and methods of a project
+// and finds them by fuzzy name matching. It works from extraction alone,
+// so it needs no embeddings or semantic index.
+package symbols
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/l3aro/go-context-query/internal/scanner"
+	"github.com/l3aro/go-context-query/pkg/extractor"
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+// Kinds of Symbol
+const (
+	KindFunction  = "function"
+	KindMethod    = "method"
+	KindClass     = "class"
+	KindInterface = "interface"
+	KindTrait     = "trait"
+	KindStruct    = "struct"
+)
+
+// DefaultLimit is the number of matches returned when no limit is given
+const DefaultLimit = 50
+
+// Symbol is a named definition in a source file
+type Symbol struct {
+	// Name is the symbol's name; methods are qualified with their
+	// class (Class.method)
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// File is relative to the project root
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// Match is a symbol matching a pattern
+type Match struct {
+	Symbol
+	Score int `json:"score"`
+	// Positions are the rune offsets in Name of the matched characters
+	Positions []int `json:"positions,omitempty"`
+}
+
+// FromModule returns the symbols of a module extracted from file
+func FromModule(file string, m *types.ModuleInfo) []Symbol {
+	var syms []Symbol
+	add := func(name, kind string, line int) {
+		if name != "" {
+			syms = append(syms, Symbol{Name: name, Kind: kind, File: file, Line: line})
+		}
+	}
+	methods := func(owner string, ms []types.Method) {
+		for _, method := range ms {
+			add(owner+"."+method.Name, KindMethod, method.LineNumber)
+		}
+	}
+
+	for _, fn := range m.Functions {
+		kind := KindFunction
+		if fn.IsMethod {
+			kind = KindMethod
+		}
+		add(fn.Name, kind, fn.LineNumber)
+	}
+	for _, cls := range m.Classes {
+		add(cls.Name, KindClass, cls.LineNumber)
+		methods(cls.Name, cls.Methods)
+	}
+	for _, iface := range m.Interfaces {
+		add(iface.Name, KindInterface, iface.LineNumber)
+		methods(iface.Name, iface.Methods)
+	}
+	for _, trait := range m.Traits {
+		add(trait.Name, KindTrait, trait.LineNumber)
+		methods(trait.Name, trait.Methods)
+	}
+	for _, st := range m.Structs {
+		add(st.Name, KindStruct, st.LineNumber)
+	}
+	return syms
+}
+
+// Collect extracts the symbols of every supported file under rootDir.
+// Files that fail to parse are skipped.
+func Collect(rootDir string) ([]Symbol, error) {
+	files, err := scanner.New(scanner.DefaultOptions()).Scan(rootDir)
+	if err != nil {
+		return nil, fmt.Errorf("scanning %s: %w", rootDir, err)
+	}
+
+	var syms []Symbol
+	for _, f := range files {
+		if f.Language == "" {
+			continue
+		}
+		moduleInfo, err := extractor.ExtractFile(f.FullPath)
+		if err != nil {
+			continue
+		}
+		syms = append(syms, FromModule(filepath.ToSlash(f.Path), moduleInfo)...)
+	}
+	return syms, nil
+}
+
+// Search returns the symbols whose name matches pattern, best first: by
+// score, then shorter names, then by file and line. limit <= 0 returns all
+// matches.
+func Search(syms []Symbol, pattern string, limit int) []Match {
+	var matches []Match
+	for _, s := range syms {
+		score, positions, ok := Score(pattern, s.Name)
+		if !ok {
+			continue
+		}
+		matches = append(matches, Match{Symbol: s, Score: score, Positions: positions})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if len(a.Name) != len(b.Name) {
+			return len(a.Name) < len(b.Name)
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
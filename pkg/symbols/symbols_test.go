@@ -0,0 +1,115 @@
+package symbols
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/l3aro/go-context-query/pkg/types"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		pattern, text string
+		ok            bool
+		positions     []int
+	}{
+		{"pc", "parseConfig", true, []int{0, 5}},
+		{"cfg", "loadConfig", true, []int{4, 7, 9}},
+		{"xyz", "parseConfig", false, nil},
+		{"PC", "parseconfig", false, nil},
+		{"pc", "ParseConfig", true, []int{0, 5}},
+		{"ab", "xaab", true, []int{2, 3}},
+	}
+	for _, tt := range tests {
+		_, positions, ok := Score(tt.pattern, tt.text)
+		if ok != tt.ok || !slices.Equal(positions, tt.positions) {
+			t.Errorf("Score(%q, %q) = %v, %v; want %v, %v", tt.pattern, tt.text, positions, ok, tt.positions, tt.ok)
+		}
+	}
+}
+
+func TestScoreRanking(t *testing.T) {
+	// Each pair is (better, worse) for the pattern
+	tests := []struct {
+		pattern, better, worse string
+	}{
+		{"parse", "parse", "compare_sets"},
+		{"pc", "parseConfig", "topic"},
+		{"sv", "Index.Save", "isValid"},
+		{"load", "loadUnits", "reloaded"},
+	}
+	for _, tt := range tests {
+		b, _, okB := Score(tt.pattern, tt.better)
+		w, _, okW := Score(tt.pattern, tt.worse)
+		if !okB || !okW || b <= w {
+			t.Errorf("%q: %q scored %d, %q scored %d", tt.pattern, tt.better, b, tt.worse, w)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	syms := []Symbol{
+		{Name: "reloaded", Kind: KindFunction, File: "b.py", Line: 3},
+		{Name: "Loader.load", Kind: KindMethod, File: "a.py", Line: 9},
+		{Name: "load", Kind: KindFunction, File: "a.py", Line: 1},
+		{Name: "save", Kind: KindFunction, File: "a.py", Line: 5},
+	}
+	matches := Search(syms, "load", 0)
+	var names []string
+	for _, m := range matches {
+		names = append(names, m.Name)
+	}
+	if want := []string{"load", "Loader.load", "reloaded"}; !slices.Equal(names, want) {
+		t.Errorf("Search = %v, want %v", names, want)
+	}
+	if got := Search(syms, "load", 1); len(got) != 1 {
+		t.Errorf("limit 1 returned %d matches", len(got))
+	}
+}
+
+func TestFromModule(t *testing.T) {
+	m := &types.ModuleInfo{
+		Functions: []types.Function{{Name: "main", LineNumber: 1}, {Name: "Close", LineNumber: 4, IsMethod: true}},
+		Classes:   []types.Class{{Name: "Parser", LineNumber: 7, Methods: []types.Method{{Name: "parse", LineNumber: 8}}}},
+	}
+	want := []Symbol{
+		{Name: "main", Kind: KindFunction, File: "m.py", Line: 1},
+		{Name: "Close", Kind: KindMethod, File: "m.py", Line: 4},
+		{Name: "Parser", Kind: KindClass, File: "m.py", Line: 7},
+		{Name: "Parser.parse", Kind: KindMethod, File: "m.py", Line: 8},
+	}
+	if got := FromModule("m.py", m); !slices.Equal(got, want) {
+		t.Errorf("FromModule = %+v", got)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	dir := t.TempDir()
+	src := "class Parser:\n    def parse(self, text):\n        return text\n\n\ndef tokenize(text):\n    return text.split()\n"
+	if err := os.MkdirAll(filepath.Join(dir, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "pkg", "parser.py"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	syms, err := Collect(dir)
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	matches := Search(syms, "prs", 0)
+	if len(matches) == 0 || matches[0].File != "pkg/parser.py" {
+		t.Fatalf("Search(prs) = %+v", matches)
+	}
+	found := false
+	for _, s := range syms {
+		if s.Name == "tokenize" && s.Line == 6 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("tokenize missing from %+v", syms)
+	}
+}
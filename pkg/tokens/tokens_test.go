@@ -0,0 +1,33 @@
+package tokens
+
+import "testing"
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"hello world", 2},
+		{"parseConfig", 2},
+		{"parse_config", 3},
+		{"internationalization", 4},
+		{"1234567", 3},
+		{"func main() {\n\treturn\n}", 9},
+		{"if x != nil {", 5},
+		{"日本語", 3},
+	}
+	for _, tt := range tests {
+		if got := Count(tt.text); got != tt.want {
+			t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCountGrowsWithText(t *testing.T) {
+	short := Count("def load(path):\n    return open(path).read()\n")
+	long := Count("def load(path):\n    return open(path).read()\n\n\ndef save(path, data):\n    open(path, 'w').write(data)\n")
+	if short <= 0 || long <= short {
+		t.Errorf("Count: short %d, long %d", short, long)
+	}
+}
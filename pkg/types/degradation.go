@@ -0,0 +1,57 @@
+package types
+
+// Degraded features reported in Degradation.Feature
+const (
+	// DegradedCFG: control flow summaries could not be extracted for some
+	// functions, so their units lack complexity metrics
+	DegradedCFG = "cfg"
+	// DegradedCallGraph: the call graph could not be built for a language,
+	// or files changed since it was built
+	DegradedCallGraph = "call_graph"
+	// DegradedProviderFallback: embeddings came from a fallback provider or
+	// model rather than the configured one
+	DegradedProviderFallback = "provider_fallback"
+	// DegradedDimension: the index and the query embeddings have different
+	// dimensions
+	DegradedDimension = "dimension_mismatch"
+	// DegradedSemanticIndex: the semantic index was unavailable and a
+	// coarser index was searched instead
+	DegradedSemanticIndex = "semantic_index"
+	// DegradedPartialIndex: a budgeted build left units out of the index
+	DegradedPartialIndex = "partial_index"
+	// DegradedStaleIndex: files changed since they were indexed
+	DegradedStaleIndex = "stale_index"
+	// DegradedBlame: git blame was asked for but the project is not a git
+	// repository, or some result files are not committed
+	DegradedBlame = "blame"
+	// DegradedHistory: commit messages or pull requests could not be
+	// indexed
+	DegradedHistory = "history"
+	// DegradedSearchBudget: a search ran out of its time budget and
+	// returned the best results found before it did
+	DegradedSearchBudget = "search_budget"
+)
+
+// Degradation notes a feature that was skipped or fell back while a result
+// was produced, so callers can tell a partial result from a complete one
+type Degradation struct {
+	Feature string `json:"feature"`
+	Reason  string `json:"reason"`
+	// Count is how many units or files were affected, when known
+	Count int `json:"count,omitempty"`
+}
+
+// Degradations collects the degradations of one result
+type Degradations []Degradation
+
+// Add records a degradation. Repeats of the same feature and reason are
+// merged, adding up their counts.
+func (ds *Degradations) Add(feature, reason string, count int) {
+	for i := range *ds {
+		if d := &(*ds)[i]; d.Feature == feature && d.Reason == reason {
+			d.Count += count
+			return
+		}
+	}
+	*ds = append(*ds, Degradation{Feature: feature, Reason: reason, Count: count})
+}
@@ -0,0 +1,21 @@
+package types
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDegradationsAdd(t *testing.T) {
+	var ds Degradations
+	ds.Add(DegradedCFG, "control flow extraction failed", 2)
+	ds.Add(DegradedProviderFallback, "local runtime unreachable", 0)
+	ds.Add(DegradedCFG, "control flow extraction failed", 3)
+
+	want := Degradations{
+		{Feature: DegradedCFG, Reason: "control flow extraction failed", Count: 5},
+		{Feature: DegradedProviderFallback, Reason: "local runtime unreachable"},
+	}
+	if !slices.Equal(ds, want) {
+		t.Errorf("degradations = %+v, want %+v", ds, want)
+	}
+}
@@ -0,0 +1,50 @@
+package types
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// testDirs are directory names whose files are tests in every language
+var testDirs = map[string]bool{
+	"test":      true,
+	"tests":     true,
+	"__tests__": true,
+	"spec":      true,
+	"testdata":  true,
+}
+
+// IsTestFile reports whether path is a test file by the naming conventions
+// of its language: _test.go for Go, test_*.py, *_test.py and conftest.py for
+// pytest, *.test.ts and *.spec.ts (and the JavaScript equivalents), *Test.java
+// and friends for JVM and .NET languages, *_spec.rb for RSpec, and any file
+// under a test, tests, __tests__, spec or testdata directory. path should be
+// relative to the project root, so directories above the project don't count.
+func IsTestFile(path string) bool {
+	slashed := filepath.ToSlash(path)
+	dirs := strings.Split(slashed, "/")
+	base := dirs[len(dirs)-1]
+	for _, dir := range dirs[:len(dirs)-1] {
+		if testDirs[dir] {
+			return true
+		}
+	}
+
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	switch ext {
+	case ".go":
+		return strings.HasSuffix(stem, "_test")
+	case ".py":
+		return strings.HasPrefix(stem, "test_") || strings.HasSuffix(stem, "_test") || stem == "conftest"
+	case ".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs", ".vue", ".svelte":
+		return strings.HasSuffix(stem, ".test") || strings.HasSuffix(stem, ".spec")
+	case ".java", ".kt", ".kts", ".cs", ".swift", ".php", ".scala":
+		return strings.HasSuffix(stem, "Test") || strings.HasSuffix(stem, "Tests") || strings.HasSuffix(stem, "IT")
+	case ".rb":
+		return strings.HasSuffix(stem, "_spec") || strings.HasSuffix(stem, "_test")
+	case ".rs", ".c", ".cc", ".cpp", ".cxx", ".h", ".hpp":
+		return strings.HasSuffix(stem, "_test") || strings.HasPrefix(stem, "test_")
+	}
+	return false
+}
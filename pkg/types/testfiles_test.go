@@ -0,0 +1,34 @@
+package types
+
+import "testing"
+
+func TestIsTestFile(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"pkg/index/index_test.go", true},
+		{"pkg/index/index.go", false},
+		{"app/test_models.py", true},
+		{"app/models_test.py", true},
+		{"conftest.py", true},
+		{"app/testing.py", false},
+		{"src/app.spec.ts", true},
+		{"src/app.test.jsx", true},
+		{"src/app.ts", false},
+		{"src/latest.ts", false},
+		{"src/main/java/com/acme/UserServiceTest.java", true},
+		{"src/main/java/com/acme/UserService.java", false},
+		{"src/test/java/com/acme/Fixtures.java", true},
+		{"spec/models/user_spec.rb", true},
+		{"lib/user.rb", false},
+		{"tests/integration.rs", true},
+		{"src/__tests__/helpers.js", true},
+		{"Api.Tests/ControllerTests.cs", true},
+	}
+	for _, tt := range tests {
+		if got := IsTestFile(tt.path); got != tt.want {
+			t.Errorf("IsTestFile(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package types
+
+import "unicode/utf8"
+
+// Tree-sitter reports positions as byte offsets, and its Point.Column is a
+// byte offset within the line. The helpers below convert those offsets to
+// text safely for UTF-8 sources, where a byte offset can fall inside a
+// multibyte character and a byte column differs from the character column.
+
+// NodeText returns content[start:end] as a string. Offsets outside content
+// yield "". Offsets that fall inside a multibyte character are moved to the
+// nearest rune boundary that keeps the slice within [start, end].
+func NodeText(content []byte, start, end uint32) string {
+	if start > end || end > uint32(len(content)) {
+		return ""
+	}
+
+	s, e := int(start), int(end)
+	for s < e && !utf8.RuneStart(content[s]) {
+		s++
+	}
+	for e > s && e < len(content) && !utf8.RuneStart(content[e]) {
+		e--
+	}
+
+	return string(content[s:e])
+}
+
+// RuneColumn converts a byte column within a line to a 0-based character
+// column. byteOffset is the absolute byte offset of the position in content
+// and byteColumn its byte offset from the start of the line, as reported by
+// tree-sitter's StartByte and StartPoint().Column.
+func RuneColumn(content []byte, byteOffset, byteColumn uint32) int {
+	if byteOffset > uint32(len(content)) || byteColumn > byteOffset {
+		return int(byteColumn)
+	}
+	lineStart := byteOffset - byteColumn
+	return utf8.RuneCount(content[lineStart:byteOffset])
+}
+
+// ByteToRuneColumn converts a 0-based byte column in line to a 0-based character column.
+func ByteToRuneColumn(line string, byteCol int) int {
+	if byteCol <= 0 {
+		return 0
+	}
+	if byteCol > len(line) {
+		byteCol = len(line)
+	}
+	return utf8.RuneCountInString(line[:byteCol])
+}
+
+// TruncateUTF8 shortens s to at most maxBytes bytes without splitting a
+// multibyte character. Strings already within the limit are returned as is.
+func TruncateUTF8(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	if maxBytes <= 0 {
+		return ""
+	}
+
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
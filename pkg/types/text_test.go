@@ -0,0 +1,74 @@
+package types
+
+import "testing"
+
+func TestNodeText(t *testing.T) {
+	content := []byte(`name = "héllo 世界"`)
+
+	tests := []struct {
+		start, end uint32
+		want       string
+	}{
+		{0, 4, "name"},
+		{8, 14, "héllo"},
+		{15, 21, "世界"},
+		// Offsets inside a multibyte character snap to rune boundaries
+		{16, 21, "界"},
+		{15, 20, "世"},
+		{0, 100, ""},
+		{5, 4, ""},
+	}
+
+	for _, tt := range tests {
+		if got := NodeText(content, tt.start, tt.end); got != tt.want {
+			t.Errorf("NodeText(%d, %d) = %q, want %q", tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestRuneColumn(t *testing.T) {
+	content := []byte("x = 1\nπ = \"ü\"; y = 2\n")
+
+	// "y" is at byte column 11 on the second line but character column 9
+	lineStart := uint32(6)
+	byteCol := uint32(11)
+	if got := RuneColumn(content, lineStart+byteCol, byteCol); got != 9 {
+		t.Errorf("RuneColumn = %d, want 9", got)
+	}
+
+	// ASCII lines are unchanged
+	if got := RuneColumn(content, 4, 4); got != 4 {
+		t.Errorf("RuneColumn on ASCII = %d, want 4", got)
+	}
+}
+
+func TestByteToRuneColumn(t *testing.T) {
+	line := "// 日本語 TODO"
+	if got := ByteToRuneColumn(line, 13); got != 7 {
+		t.Errorf("ByteToRuneColumn = %d, want 7", got)
+	}
+	if got := ByteToRuneColumn(line, 100); got != 11 {
+		t.Errorf("ByteToRuneColumn past end = %d, want 11", got)
+	}
+}
+
+func TestTruncateUTF8(t *testing.T) {
+	tests := []struct {
+		in   string
+		max  int
+		want string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 3, "hel"},
+		{"héllo", 2, "h"},
+		{"日本語", 4, "日"},
+		{"日本語", 6, "日本"},
+		{"日本語", 0, ""},
+	}
+
+	for _, tt := range tests {
+		if got := TruncateUTF8(tt.in, tt.max); got != tt.want {
+			t.Errorf("TruncateUTF8(%q, %d) = %q, want %q", tt.in, tt.max, got, tt.want)
+		}
+	}
+}
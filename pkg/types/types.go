@@ -2,11 +2,16 @@
 // It includes types for functions, classes, imports, call graphs, and module information.
 package types
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
-// Function represents a function definition
+// Function represents a function definition. TypeParams holds the generic
+// type parameter list with its constraints, such as "[T any]".
 type Function struct {
 	Name       string   `json:"name"`
+	TypeParams string   `json:"type_params,omitempty"`
 	Params     string   `json:"params"`
 	ReturnType string   `json:"return_type"`
 	Docstring  string   `json:"docstring"`
@@ -15,6 +20,9 @@ type Function struct {
 	IsAsync    bool     `json:"is_async"`
 	Decorators []string `json:"decorators"`
 	NestedIn   string   `json:"nested_in"`
+	// Receiver is the type a Go method is declared on, without pointer
+	// and type parameters: "Store" for func (s *Store[K]) Get()
+	Receiver string `json:"receiver,omitempty"`
 }
 
 // Method represents a class method (alias for Function with IsMethod=true)
@@ -23,10 +31,12 @@ type Method = Function
 // Class represents a class definition
 type Class struct {
 	Name          string   `json:"name"`
+	TypeParams    string   `json:"type_params,omitempty"`
 	QualifiedName string   `json:"qualified_name"`
 	Bases         []string `json:"bases"`
 	Docstring     string   `json:"docstring"`
 	Methods       []Method `json:"methods"`
+	Fields        []Field  `json:"fields,omitempty"`
 	Decorators    []string `json:"decorators"`
 	LineNumber    int      `json:"line_number"`
 }
@@ -34,6 +44,7 @@ type Class struct {
 // Interface represents an interface definition (e.g., Go interfaces, TypeScript interfaces)
 type Interface struct {
 	Name       string   `json:"name"`
+	TypeParams string   `json:"type_params,omitempty"`
 	Bases      []string `json:"bases,omitempty"`
 	Docstring  string   `json:"docstring"`
 	Methods    []Method `json:"methods"`
@@ -65,14 +76,28 @@ type Enum struct {
 	LineNumber int      `json:"line_number"`
 }
 
-// Struct represents a struct definition (e.g., Go structs, C structs)
+// Struct represents a struct definition (e.g., Go structs, C structs).
+// Fields holds each field declaration as written; FieldInfo, filled in for
+// Go, breaks them into names, types and tags.
 type Struct struct {
 	Name       string   `json:"name"`
+	TypeParams string   `json:"type_params,omitempty"`
 	Docstring  string   `json:"docstring"`
 	Fields     []string `json:"fields,omitempty"`
+	FieldInfo  []Field  `json:"field_info,omitempty"`
 	LineNumber int      `json:"line_number"`
 }
 
+// Field represents a struct field. Tag holds a Go struct tag without its
+// quotes, such as json:"name"; an embedded field is named after its type.
+type Field struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Tag      string `json:"tag,omitempty"`
+	Doc      string `json:"doc,omitempty"`
+	Embedded bool   `json:"embedded,omitempty"`
+}
+
 // Import represents an import statement
 type Import struct {
 	Module     string   `json:"module"`
@@ -88,6 +113,11 @@ type CallGraphEdge struct {
 	SourceFunc string `json:"src_func"`
 	DestFile   string `json:"dst_file"`
 	DestFunc   string `json:"dst_func"`
+	// Line is the line of the call site, when known
+	Line int `json:"line,omitempty"`
+	// Args holds the source text of the call's arguments, each cut to a
+	// bounded length
+	Args []string `json:"args,omitempty"`
 }
 
 // CallGraph represents the call graph of a module
@@ -130,6 +160,71 @@ type CompactModuleInfo struct {
 type EmbeddingUnit struct {
 	L1Data ModuleInfo      `json:"l1_data"`
 	L2Data []CallGraphEdge `json:"l2_data"`
+	// Unit is the complete code unit, set by the semantic builder so a loaded
+	// index yields fully populated results without re-extraction
+	Unit *CodeUnit `json:"unit,omitempty"`
+	// ModTime is the modification time of the file a daemon file unit was
+	// extracted from, so a refresh can tell whether the file changed since
+	ModTime time.Time `json:"mod_time,omitzero"`
+}
+
+// CodeUnit represents a single unit of code ready for embedding.
+// It combines L1 (local) and L2 (cross-file) data.
+type CodeUnit struct {
+	// ID is the canonical unit URI, e.g. "py://pkg/mod.py#Class.method"
+	ID string `json:"id"`
+	// Name is the name of the function/method/class
+	Name string `json:"name"`
+	// Type is the type of unit (function, method, class)
+	Type string `json:"type"`
+	// Language is the source language of the unit (python, go, ...)
+	Language string `json:"language,omitempty"`
+	// FilePath is the path to the file containing this unit
+	FilePath string `json:"file_path"`
+	// LineNumber is the line where this unit is defined
+	LineNumber int `json:"line_number"`
+	// Signature is the function signature
+	Signature string `json:"signature"`
+	// Docstring is the docstring/comment
+	Docstring string `json:"docstring"`
+	// Calls is the list of functions this unit calls (forward)
+	Calls []string `json:"calls"`
+	// CalledBy is the list of functions that call this unit (backward)
+	CalledBy []string `json:"called_by"`
+	// CFGSummary is an optional control flow graph summary (complexity, blocks)
+	CFGSummary string `json:"cfg_summary,omitempty"`
+	// DFGSummary is an optional data flow graph summary (variables, edges)
+	DFGSummary string `json:"dfg_summary,omitempty"`
+	// Dependencies is a list of significant imported modules/packages
+	Dependencies []string `json:"dependencies,omitempty"`
+	// CalleeSummaries holds one-line docstring summaries of direct callees
+	// ("name: summary") when graph-augmented embeddings are enabled
+	CalleeSummaries []string `json:"callee_summaries,omitempty"`
+	// DependencyVersions maps dependencies to the version declared in the
+	// project's go.mod, package.json or pyproject.toml, when known
+	DependencyVersions map[string]string `json:"dependency_versions,omitempty"`
+	// IsTest marks units defined in test files (see IsTestFile); default
+	// searches leave them out
+	IsTest bool `json:"is_test,omitempty"`
+	// EndLine is the last line of the unit's body, when known
+	EndLine int `json:"end_line,omitempty"`
+	// Parent is the ID of the function or method a body chunk belongs to
+	Parent string `json:"parent,omitempty"`
+	// Code is the source of a body chunk, lines LineNumber to EndLine, or
+	// the lines around the comment of a todo unit
+	Code string `json:"code,omitempty"`
+	// StableID identifies the unit by its type, signature and body rather
+	// than its path, so it survives moving the unit to another file
+	StableID string `json:"stable_id,omitempty"`
+	// ContentHash is a hash of the unit's body without its definition line,
+	// which survives renames
+	ContentHash string `json:"content_hash,omitempty"`
+	// TextHash is a hash of the unit's embedding text, which lets a
+	// rebuild reuse its vector
+	TextHash string `json:"text_hash,omitempty"`
+	// Metadata holds key/value annotations attached by enrichers after
+	// extraction, such as ticket IDs or feature flags a unit references
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 // Config holds application configuration
@@ -173,7 +268,7 @@ func (m ModuleInfo) ToCompact() CompactModuleInfo {
 	// Convert functions to signature strings
 	funcs := make([]string, 0, len(m.Functions))
 	for _, fn := range m.Functions {
-		sig := fn.Name + "(" + fn.Params + ")"
+		sig := fn.Name + fn.TypeParams + "(" + fn.Params + ")"
 		if fn.ReturnType != "" {
 			sig += " -> " + fn.ReturnType
 		}
@@ -200,6 +295,13 @@ func (m ModuleInfo) ToCompact() CompactModuleInfo {
 		if len(methods) > 0 {
 			classMap["methods"] = methods
 		}
+		if len(cls.Fields) > 0 {
+			fields := make([]string, len(cls.Fields))
+			for i, f := range cls.Fields {
+				fields[i] = f.Name + " " + f.Type
+			}
+			classMap["fields"] = fields
+		}
 		classes[cls.Name] = classMap
 	}
 
@@ -223,8 +325,5 @@ func (m ModuleInfo) ToCompact() CompactModuleInfo {
 
 // truncateToLen truncates a string to the specified length
 func truncateToLen(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen]
+	return TruncateUTF8(s, maxLen)
 }
@@ -0,0 +1,131 @@
+package types
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// UnitURI is the canonical address of a code unit, written as
+// scheme://path#symbol, e.g. "py://pkg/mod.py#Class.method" or
+// "go://pkg/index#VectorIndex.Add".
+//
+// Path is slash-separated. Go symbols are addressed by package directory,
+// since names are unique within a package; all other languages use the file
+// path. A URI without a symbol addresses the whole file.
+type UnitURI struct {
+	// Scheme is the short language identifier (py, go, ts, ...)
+	Scheme string
+	// Path is the file path, or the package directory for Go symbols
+	Path string
+	// Symbol is the qualified symbol name (e.g. Class.method), empty for files
+	Symbol string
+}
+
+// uriSchemes maps language identifiers to their URI scheme.
+var uriSchemes = map[string]string{
+	"python":     "py",
+	"go":         "go",
+	"typescript": "ts",
+	"javascript": "js",
+	"java":       "java",
+	"rust":       "rs",
+	"c":          "c",
+	"cpp":        "cpp",
+	"ruby":       "rb",
+	"php":        "php",
+	"swift":      "swift",
+	"kotlin":     "kt",
+	"csharp":     "cs",
+}
+
+// SchemeForLanguage returns the URI scheme for a language identifier.
+// Unknown languages use the language name itself, and an empty language "file".
+func SchemeForLanguage(lang string) string {
+	if scheme, ok := uriSchemes[lang]; ok {
+		return scheme
+	}
+	if lang == "" {
+		return "file"
+	}
+	return lang
+}
+
+// LanguageForScheme returns the language identifier for a URI scheme.
+func LanguageForScheme(scheme string) string {
+	for lang, s := range uriSchemes {
+		if s == scheme {
+			return lang
+		}
+	}
+	return scheme
+}
+
+// NewUnitURI builds the canonical URI for a symbol in filePath.
+// An empty symbol addresses the file itself.
+func NewUnitURI(lang, filePath, symbol string) UnitURI {
+	p := filepath.ToSlash(filePath)
+	if lang == "go" && symbol != "" {
+		p = path.Dir(p)
+	}
+	return UnitURI{
+		Scheme: SchemeForLanguage(lang),
+		Path:   p,
+		Symbol: symbol,
+	}
+}
+
+// String formats the URI as scheme://path#symbol.
+func (u UnitURI) String() string {
+	s := u.Scheme + "://" + u.Path
+	if u.Symbol != "" {
+		s += "#" + u.Symbol
+	}
+	return s
+}
+
+// Language returns the language identifier for the URI scheme.
+func (u UnitURI) Language() string {
+	return LanguageForScheme(u.Scheme)
+}
+
+// IsUnitURI reports whether s is written in the scheme://path form.
+func IsUnitURI(s string) bool {
+	i := strings.Index(s, "://")
+	return i > 0 && !strings.ContainsAny(s[:i], `/\.`)
+}
+
+// ParseUnitURI parses a canonical unit URI. For compatibility with indexes
+// written before URIs were introduced, it also accepts the legacy
+// "path:name" form and bare paths, which yield an empty scheme.
+func ParseUnitURI(s string) (UnitURI, error) {
+	if s == "" {
+		return UnitURI{}, fmt.Errorf("empty unit URI")
+	}
+
+	if IsUnitURI(s) {
+		i := strings.Index(s, "://")
+		u := UnitURI{Scheme: s[:i]}
+		rest := s[i+3:]
+		if j := strings.LastIndex(rest, "#"); j >= 0 {
+			u.Path, u.Symbol = rest[:j], rest[j+1:]
+		} else {
+			u.Path = rest
+		}
+		if u.Path == "" {
+			return UnitURI{}, fmt.Errorf("unit URI has no path: %s", s)
+		}
+		return u, nil
+	}
+
+	// Legacy "path:name"; skip a Windows drive letter such as C:\
+	start := 0
+	if len(s) >= 2 && s[1] == ':' && filepath.VolumeName(s) != "" {
+		start = 2
+	}
+	if i := strings.LastIndex(s[start:], ":"); i >= 0 {
+		return UnitURI{Path: s[:start+i], Symbol: s[start+i+1:]}, nil
+	}
+	return UnitURI{Path: s}, nil
+}
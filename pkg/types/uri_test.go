@@ -0,0 +1,66 @@
+package types
+
+import "testing"
+
+func TestNewUnitURI(t *testing.T) {
+	tests := []struct {
+		lang, file, symbol string
+		want               string
+	}{
+		{"python", "pkg/mod.py", "Class.method", "py://pkg/mod.py#Class.method"},
+		{"go", "pkg/index/index.go", "VectorIndex.Add", "go://pkg/index#VectorIndex.Add"},
+		{"go", "pkg/index/index.go", "", "go://pkg/index/index.go"},
+		{"typescript", "src/app.ts", "main", "ts://src/app.ts#main"},
+		{"", "README", "", "file://README"},
+	}
+
+	for _, tt := range tests {
+		if got := NewUnitURI(tt.lang, tt.file, tt.symbol).String(); got != tt.want {
+			t.Errorf("NewUnitURI(%q, %q, %q) = %q, want %q", tt.lang, tt.file, tt.symbol, got, tt.want)
+		}
+	}
+}
+
+func TestParseUnitURI(t *testing.T) {
+	tests := []struct {
+		in   string
+		want UnitURI
+	}{
+		{"py://pkg/mod.py#Class.method", UnitURI{Scheme: "py", Path: "pkg/mod.py", Symbol: "Class.method"}},
+		{"go://pkg/index#VectorIndex.Add", UnitURI{Scheme: "go", Path: "pkg/index", Symbol: "VectorIndex.Add"}},
+		{"py:///abs/mod.py", UnitURI{Scheme: "py", Path: "/abs/mod.py"}},
+		{"pkg/mod.py:run", UnitURI{Path: "pkg/mod.py", Symbol: "run"}},
+		{"/abs/mod.py", UnitURI{Path: "/abs/mod.py"}},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseUnitURI(tt.in)
+		if err != nil {
+			t.Fatalf("ParseUnitURI(%q) error: %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseUnitURI(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := ParseUnitURI(""); err == nil {
+		t.Error("expected error for empty URI")
+	}
+	if _, err := ParseUnitURI("py://#main"); err == nil {
+		t.Error("expected error for URI without path")
+	}
+}
+
+func TestUnitURI_RoundTrip(t *testing.T) {
+	u := NewUnitURI("rust", "src/lib.rs", "Parser::parse")
+	parsed, err := ParseUnitURI(u.String())
+	if err != nil {
+		t.Fatalf("ParseUnitURI error: %v", err)
+	}
+	if parsed != u {
+		t.Errorf("round trip = %+v, want %+v", parsed, u)
+	}
+	if parsed.Language() != "rust" {
+		t.Errorf("Language() = %q, want rust", parsed.Language())
+	}
+}